@@ -27,8 +27,10 @@ func main() {
 		log.Fatalf("load config: %v", err)
 	}
 
-	// 初始化日志
-	lg, err := logger.New(cfg.App.Env, cfg.Log.Level, cfg.Log.Encoding, "migrate", cfg.App.Version)
+	// 初始化日志（脱敏规则已在 config.Load 中校验）
+	redactRules, _ := logger.ParseRedactRules(cfg.Log.RedactRules)
+	lg, err := logger.New(cfg.App.Env, cfg.Log.Level, cfg.Log.Encoding, "migrate", cfg.App.Version,
+		logger.WithRedactRules(redactRules), logger.WithHashSalt(cfg.Log.RedactSalt))
 	if err != nil {
 		log.Fatalf("init logger: %v", err)
 	}
@@ -2,43 +2,58 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
 	"github.com/MorseWayne/spike_shop/internal/api"
+	"github.com/MorseWayne/spike_shop/internal/breaker"
 	"github.com/MorseWayne/spike_shop/internal/cache"
 	"github.com/MorseWayne/spike_shop/internal/config"
 	"github.com/MorseWayne/spike_shop/internal/database"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/health"
+	"github.com/MorseWayne/spike_shop/internal/idgen"
+	"github.com/MorseWayne/spike_shop/internal/journal"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/lifecycle"
 	"github.com/MorseWayne/spike_shop/internal/limiter"
 	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
 	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/notify"
+	"github.com/MorseWayne/spike_shop/internal/payment"
 	"github.com/MorseWayne/spike_shop/internal/repo"
 	"github.com/MorseWayne/spike_shop/internal/router"
 	"github.com/MorseWayne/spike_shop/internal/service"
+	"github.com/MorseWayne/spike_shop/internal/storage"
 )
 
-// initConfigAndLogger 初始化配置和日志器
-func initConfigAndLogger() (*config.Config, *zap.Logger, error) {
-	cfg, err := config.Load()
+// initConfigAndLogger 按命令行参数加载配置并初始化日志器，返回的日志级别随配置热更新调整
+func initConfigAndLogger(opts config.Options) (*config.Config, *zap.Logger, zap.AtomicLevel, error) {
+	logLevel := zap.NewAtomicLevel()
+	cfg, err := config.LoadWithOptions(opts)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid configuration: %v", err)
+		return nil, nil, logLevel, fmt.Errorf("invalid configuration: %v", err)
 	}
 
-	// init logger
-	lg, err := logger.New(cfg.App.Env, cfg.Log.Level, cfg.Log.Encoding, cfg.App.Name, cfg.App.Version)
+	// init logger（脱敏规则已在 config.Load 中校验）
+	redactRules, _ := logger.ParseRedactRules(cfg.Log.RedactRules)
+	lg, err := logger.New(cfg.App.Env, cfg.Log.Level, cfg.Log.Encoding, cfg.App.Name, cfg.App.Version,
+		logger.WithRedactRules(redactRules), logger.WithHashSalt(cfg.Log.RedactSalt), logger.WithAtomicLevel(logLevel))
 	if err != nil {
-		return nil, nil, fmt.Errorf("init logger: %v", err)
+		return nil, nil, logLevel, fmt.Errorf("init logger: %v", err)
 	}
 
-	return cfg, lg, nil
+	return cfg, lg, logLevel, nil
 }
 
 // initDatabase 初始化数据库连接并执行迁移
@@ -69,15 +84,16 @@ func initCache(cfg *config.Config, lg *zap.Logger) cache.Cache {
 	if cfg.Cache.Enabled {
 		switch cfg.Cache.Type {
 		case "redis":
-			redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
-			redisCache, err := cache.NewRedisCache(redisAddr, cfg.Redis.Password, cfg.Redis.DB)
+			redisAddr := redisAddrs(cfg)
+			redisCache, err := cache.NewRedisCacheWithClient(newRedisClient(cfg, cfg.Redis.CacheDB))
 			if err != nil {
 				lg.Sugar().Warnw("failed to connect to Redis, falling back to memory cache", "error", err)
 				cacheInstance = cache.NewMemoryCache()
 				lg.Sugar().Infow("cache enabled", "type", "memory (fallback)", "ttl", cfg.Cache.TTL)
 			} else {
+				redisCache.SetKeyPrefix(cfg.Redis.KeyPrefix)
 				cacheInstance = redisCache
-				lg.Sugar().Infow("cache enabled", "type", "redis", "addr", redisAddr, "ttl", cfg.Cache.TTL)
+				lg.Sugar().Infow("cache enabled", "type", "redis", "mode", cfg.Redis.Mode, "addr", redisAddr, "ttl", cfg.Cache.TTL)
 			}
 		case "memory":
 			cacheInstance = cache.NewMemoryCache()
@@ -94,35 +110,317 @@ func initCache(cfg *config.Config, lg *zap.Logger) cache.Cache {
 	return cacheInstance
 }
 
+// initCacheInvalidation 为进程内缓存启用跨实例失效广播，Redis 不可用时返回原缓存，各实例只能依赖TTL过期
+func initCacheInvalidation(bgCtx context.Context, cfg *config.Config, cacheInstance cache.Cache, checker *health.Checker, lm *lifecycle.Manager, lg *zap.Logger) cache.Cache {
+	client := newRedisClient(cfg, cfg.Redis.CacheDB)
+	ctx, cancel := context.WithTimeout(bgCtx, 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		lg.Sugar().Warnw("failed to connect to Redis for cache invalidation, local caches expire by TTL only", "error", err)
+		client.Close()
+		return cacheInstance
+	}
+
+	invalidating := cache.NewInvalidatingCache(cacheInstance, client, lg)
+	invalidating.SetKeyPrefix(cfg.Redis.KeyPrefix)
+	invalidating.Start(bgCtx)
+	checker.Register("cache_invalidation", false, func(ctx context.Context) error { return client.Ping(ctx).Err() })
+	lm.OnShutdown(func() { client.Close() })
+	lg.Sugar().Infow("cache invalidation broadcast enabled", "channel", cache.CacheInvalidationChannel)
+	return invalidating
+}
+
 // initDependencies 初始化应用依赖（仓储、服务、处理器）
-func initDependencies(cfg *config.Config, db *database.DB, cacheInstance cache.Cache, lg *zap.Logger) *router.Dependencies {
+// bgCtx 控制后台任务（如预告期调度器）的生命周期，服务退出时取消
+// newRedisClient 按 REDIS_MODE 创建连接到指定数据库编号的 Redis 客户端，集群模式忽略 db
+func newRedisClient(cfg *config.Config, db int) redis.UniversalClient {
+	return cache.NewRedisClient(cache.RedisClientOptions{
+		Mode:             cfg.Redis.Mode,
+		Addr:             fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Addrs:            cfg.Redis.Addrs,
+		MasterName:       cfg.Redis.MasterName,
+		Password:         cfg.Redis.Password,
+		SentinelPassword: cfg.Redis.SentinelPassword,
+		DB:               db,
+	})
+}
+
+// redisAddrs 返回用于日志的 Redis 地址：单节点模式为 Host:Port，集群与哨兵模式为 REDIS_ADDRS
+func redisAddrs(cfg *config.Config) string {
+	if cfg.Redis.Mode == cache.RedisModeStandalone {
+		return fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
+	}
+	return strings.Join(cfg.Redis.Addrs, ",")
+}
+
+// newSpikeServiceConfig 将配置中的秒杀参数映射为秒杀服务配置，未暴露的参数（如等级权益）沿用默认值
+func newSpikeServiceConfig(cfg *config.Config) *service.SpikeServiceConfig {
+	spikeConfig := service.DefaultSpikeServiceConfig()
+	spikeConfig.OrderExpireTime = cfg.Spike.OrderExpireTime
+	spikeConfig.OrderExtension = cfg.Spike.OrderExtension
+	spikeConfig.GlobalRateLimit = cfg.Spike.GlobalRateLimit
+	spikeConfig.UserRateLimit = cfg.Spike.UserRateLimit
+	spikeConfig.RateLimitWindow = cfg.Spike.RateLimitWindow
+	spikeConfig.StockWarmupEnabled = cfg.Spike.StockWarmupEnabled
+	spikeConfig.StockWarmupTime = cfg.Spike.StockWarmupTime
+	spikeConfig.StockCacheTTL = cfg.Spike.StockCacheTTL
+	spikeConfig.StockTTLBuffer = cfg.Spike.StockTTLBuffer
+	spikeConfig.UserMarkTTL = cfg.Spike.UserMarkTTL
+	spikeConfig.IdempotencyTTL = cfg.Spike.IdempotencyTTL
+	spikeConfig.MaxRetryAttempts = cfg.Spike.MaxRetryAttempts
+	spikeConfig.RetryInterval = cfg.Spike.RetryInterval
+	spikeConfig.PreviewScanInterval = cfg.Spike.PreviewScanInterval
+	spikeConfig.OrderDetailJoin = cfg.Spike.OrderDetailJoin
+	spikeConfig.DegradedMode = cfg.SpikeDegraded.Mode
+	spikeConfig.DegradedMaxConcurrency = cfg.SpikeDegraded.MaxConcurrency
+	spikeConfig.Ownership.HideForeign = cfg.Authz.HideForeignResources
+	return spikeConfig
+}
+
+// newGlobalLimiterConfig 秒杀全局限流配置（令牌桶，突发容量等于速率）
+func newGlobalLimiterConfig(cfg *config.Config) *limiter.Config {
+	return &limiter.Config{
+		Rate:      cfg.Spike.GlobalRateLimit,
+		Window:    cfg.Spike.RateLimitWindow,
+		Burst:     cfg.Spike.GlobalRateLimit,
+		KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:global"),
+	}
+}
+
+// newLoginGuardConfig 将配置中的登录锁定参数映射为登录防护配置，未暴露的参数沿用默认值
+func newLoginGuardConfig(cfg *config.Config) service.LoginGuardConfig {
+	guardConfig := service.DefaultLoginGuardConfig()
+	guardConfig.MaxFailures = cfg.Login.MaxFailures
+	guardConfig.IPMaxFailures = cfg.Login.IPMaxFailures
+	guardConfig.FailureWindow = cfg.Login.FailureWindow
+	guardConfig.LockoutBase = cfg.Login.LockoutBase
+	guardConfig.LockoutMax = cfg.Login.LockoutMax
+	guardConfig.LockoutReset = cfg.Login.LockoutReset
+	return guardConfig
+}
+
+// newUserLimiterConfig 秒杀单用户限流配置（滑动窗口）
+func newUserLimiterConfig(cfg *config.Config) *limiter.Config {
+	return &limiter.Config{
+		Rate:      cfg.Spike.UserRateLimit,
+		Window:    cfg.Spike.RateLimitWindow,
+		Burst:     cfg.Spike.UserRateLimit * 2,
+		KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:user"),
+	}
+}
+
+// newTierLimiters 按用户等级构建单用户限流器（配额 = 基础配额 × 等级倍数），倍数不大于 1 或创建失败的等级沿用默认单用户限流器
+func newTierLimiters(client redis.UniversalClient, userConfig *limiter.Config, policies map[domain.UserTier]service.TierPolicy, lg *zap.Logger) map[domain.UserTier]limiter.Limiter {
+	tierLimiters := make(map[domain.UserTier]limiter.Limiter)
+	for tier, policy := range policies {
+		if policy.RateLimitMultiplier <= 1 {
+			continue
+		}
+		tierConfig := *userConfig
+		tierConfig.Rate *= policy.RateLimitMultiplier
+		tierConfig.Burst *= policy.RateLimitMultiplier
+		tierConfig.KeyPrefix = fmt.Sprintf("%s:%s", userConfig.KeyPrefix, tier)
+		tierLimiter, err := limiter.NewSlidingWindowLimiter(client, &tierConfig)
+		if err != nil {
+			lg.Sugar().Warnw("failed to create tier limiter, falling back to default user limiter", "tier", tier, "error", err)
+			continue
+		}
+		tierLimiters[tier] = tierLimiter
+	}
+	return tierLimiters
+}
+
+// newResilientPublisherConfig 将 MQ_PUBLISH_* 配置映射为发布容错配置
+func newResilientPublisherConfig(cfg *config.Config) mq.ResilientPublisherConfig {
+	return mq.ResilientPublisherConfig{
+		MaxRetries:     cfg.MQ.PublishRetries,
+		InitialBackoff: cfg.MQ.PublishBackoff,
+		MaxBackoff:     cfg.MQ.PublishMaxBackoff,
+		BufferSize:     cfg.MQ.PublishBufferSize,
+		ReplayInterval: cfg.MQ.PublishReplayInterval,
+		Breaker: breaker.Config{
+			FailureThreshold: cfg.MQ.PublishBreakerFailures,
+			OpenTimeout:      cfg.MQ.PublishBreakerOpenTimeout,
+		},
+	}
+}
+
+// newNotifyDispatcher 按配置注册通知渠道，未配置的渠道不注册
+func newNotifyDispatcher(cfg *config.Config) *notify.Dispatcher {
+	dispatcher := notify.NewDispatcher()
+	policy := func(retries int) notify.RetryPolicy {
+		return notify.RetryPolicy{MaxRetries: retries, Backoff: cfg.Notify.RetryBackoff, MaxBackoff: 5 * time.Second}
+	}
+	if cfg.Notify.SMTPHost != "" {
+		dispatcher.Register(notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     cfg.Notify.SMTPHost,
+			Port:     cfg.Notify.SMTPPort,
+			Username: cfg.Notify.SMTPUsername,
+			Password: cfg.Notify.SMTPPassword,
+			From:     cfg.Notify.SMTPFrom,
+			Timeout:  cfg.Notify.Timeout,
+		}), policy(cfg.Notify.EmailRetries))
+	}
+	if cfg.Notify.SMSWebhookURL != "" {
+		dispatcher.Register(notify.NewWebhookNotifier(notify.ChannelSMS, cfg.Notify.SMSWebhookURL,
+			cfg.Notify.WebhookSecret, cfg.Notify.Timeout), policy(cfg.Notify.SMSRetries))
+	}
+	if cfg.Notify.PushWebhookURL != "" {
+		dispatcher.Register(notify.NewWebhookNotifier(notify.ChannelPush, cfg.Notify.PushWebhookURL,
+			cfg.Notify.WebhookSecret, cfg.Notify.Timeout), policy(cfg.Notify.PushRetries))
+	}
+	return dispatcher
+}
+
+func initDependencies(bgCtx context.Context, cfg *config.Config, watcher *config.Watcher, db *database.DB, cacheInstance cache.Cache, checker *health.Checker, lm *lifecycle.Manager, lg *zap.Logger) *router.Dependencies {
 	// 初始化依赖注入链：仓储 -> 服务 -> API处理器
 	userRepo := repo.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, lg)
-	jwtService := service.NewJWTService(cfg, lg)
+	// 高频接口的用户信息短TTL缓存，角色、状态、等级变更时由用户服务失效
+	var userCacheBackend cache.Cache
+	if cfg.Cache.Enabled {
+		userCacheBackend = cacheInstance
+	}
+	userCache := service.NewUserCache(userRepo, userCacheBackend, cfg.Cache.UserTTL)
+	userOpts := []service.UserServiceOption{
+		service.WithUserCacheInvalidation(userCache),
+		service.WithTOTP(repo.NewUserTOTPRepository(db.DB), cfg.Login.TOTPIssuer),
+	}
+	if len(cfg.Login.TOTPEncryptionKey) > 0 {
+		totpCipher, err := service.NewTOTPSecretCipher(cfg.Login.TOTPEncryptionKey)
+		if err != nil {
+			lg.Sugar().Fatalw("failed to create totp cipher", "error", err)
+		}
+		userOpts = append(userOpts, service.WithTOTPSecretCipher(totpCipher))
+	} else {
+		lg.Sugar().Warnw("totp secrets stored unencrypted - set TOTP_ENCRYPTION_KEY")
+	}
+	// 登录失败计数需多实例共享，仅在 Redis 缓存可用时启用锁定与可疑登录审计
+	if redisCache, ok := cacheInstance.(*cache.RedisCache); ok {
+		userOpts = append(userOpts, service.WithLoginGuard(service.NewLoginGuard(redisCache, newLoginGuardConfig(cfg))))
+	} else {
+		lg.Sugar().Warnw("login lockout disabled - Redis cache required")
+	}
+	userService := service.NewUserService(userRepo, lg, userOpts...)
+	jwtService := service.NewJWTService(cfg, lg, service.WithTokenUserRepository(userRepo))
 	userHandler := api.NewUserHandler(userService, jwtService, lg)
 
+	// 客服代操作
+	impersonationAuditRepo := repo.NewImpersonationAuditRepository(db.DB)
+	impersonationService := service.NewImpersonationService(userRepo, impersonationAuditRepo, jwtService, lg)
+	impersonationHandler := api.NewImpersonationHandler(impersonationService, lg)
+
+	// 服务器时间（客户端倒计时校准）
+	timeHandler := api.NewTimeHandler()
+	metaHandler := api.NewMetaHandler()
+
+	// 库存与秒杀仓储的单次调用超时；配置只读副本时 GET 请求中的只读查询读副本
+	repoOpts := []repo.Option{repo.WithQueryTimeout(cfg.Database.QueryTimeout), repo.WithReadReplicas(db)}
+	db.StartReplicaMonitor(bgCtx, cfg.Database.ReplicaCheckInterval)
+
 	// 商品和库存相关
 	baseProductRepo := repo.NewProductRepository(db.DB)
-	baseInventoryRepo := repo.NewInventoryRepository(db.DB)
+	baseInventoryRepo := repo.NewInventoryRepository(db.DB, repoOpts...)
+
+	// 商品与库存缓存：进程内缓存在变更后通过 Redis 广播失效，Redis 缓存本身即为各实例共享
+	productCache := cacheInstance
+	if _, shared := cacheInstance.(*cache.RedisCache); cfg.Cache.Enabled && cfg.Cache.InvalidationEnabled && !shared {
+		productCache = initCacheInvalidation(bgCtx, cfg, cacheInstance, checker, lm, lg)
+	}
 
 	// 可选缓存装饰器
 	var productRepo repo.ProductRepository
 	var inventoryRepo repo.InventoryRepository
 
 	if cfg.Cache.Enabled {
-		productRepo = repo.NewCachedProductRepository(baseProductRepo, cacheInstance, cfg.Cache.TTL)
-		inventoryRepo = repo.NewCachedInventoryRepository(baseInventoryRepo, cacheInstance, cfg.Cache.TTL)
+		productRepo = repo.NewCachedProductRepository(baseProductRepo, productCache, cfg.Cache.TTL)
+		inventoryRepo = repo.NewCachedInventoryRepository(baseInventoryRepo, productCache, cfg.Cache.TTL)
 	} else {
 		productRepo = baseProductRepo
 		inventoryRepo = baseInventoryRepo
 	}
 
+	// 库存可用性检查（购物车、商品页高频调用）使用短TTL缓存
+	var inventoryOpts []service.InventoryServiceOption
+	if cfg.Cache.Enabled {
+		inventoryOpts = append(inventoryOpts, service.WithAvailabilityCache(productCache, cfg.Cache.AvailabilityTTL))
+	}
+	// 商品停售开关：停售标记存放在缓存中，预留库存与参与秒杀在热路径上检查
+	var stopSellService *service.StopSellService
+	if cfg.Cache.Enabled {
+		stopSellFlags := cache.NewStopSellFlags(cacheInstance)
+		stopSellService = service.NewStopSellService(productRepo, stopSellFlags, repo.NewSpikeEventRepository(db.DB, repoOpts...), lg)
+		inventoryOpts = append(inventoryOpts, service.WithStopSell(stopSellFlags))
+	}
+	// 库存预留记录：预留带有效期，过期未消费或释放的预留由后台任务归还
+	inventoryOpts = append(inventoryOpts, service.WithReservations(
+		repo.NewInventoryReservationRepository(db.DB), cfg.InventoryReservation.TTL, cfg.InventoryReservation.MaxTTL))
+	// 库存变动流水：每次预留、释放、消费、调整由库存仓储在同一事务中写入
+	inventoryOpts = append(inventoryOpts, service.WithStockMovements(repo.NewStockMovementRepository(db.DB, repoOpts...)))
+	// 并发冲突重试：乐观锁版本冲突、死锁与锁等待超时按退避重试，减少返回给用户的 409
+	inventoryOpts = append(inventoryOpts, service.WithConflictRetry(service.ConflictRetryPolicy{
+		MaxRetries: cfg.InventoryConflict.MaxRetries,
+		Backoff:    cfg.InventoryConflict.Backoff,
+		MaxBackoff: cfg.InventoryConflict.MaxBackoff,
+	}))
+
 	productService := service.NewProductService(productRepo, inventoryRepo)
-	inventoryService := service.NewInventoryService(inventoryRepo, productRepo)
+	inventoryService := service.NewInventoryService(inventoryRepo, productRepo, inventoryOpts...)
+	service.NewReservationReleaser(inventoryService, cfg.InventoryReservation.ReleaseInterval,
+		cfg.InventoryReservation.ReleaseBatchSize, lg).Start(bgCtx)
 	productHandler := api.NewProductHandler(productService, lg)
+	if stopSellService != nil {
+		productHandler.SetStopSellService(stopSellService)
+	}
+
+	// 商品详情聚合：商品与库存变更由缓存仓储清除聚合缓存
+	var productDetailCache cache.Cache
+	if cfg.Cache.Enabled {
+		productDetailCache = productCache
+	}
+	productHandler.SetDetailService(service.NewProductDetailService(
+		productRepo, inventoryRepo, repo.NewSpikeEventRepository(db.DB, repoOpts...),
+		productDetailCache, cfg.Cache.ProductDetailTTL, lg))
 	inventoryHandler := api.NewInventoryHandler(inventoryService, lg)
 
+	// 商品规格（SKU）：独立定价与库存，秒杀活动可指定规格
+	variantRepo := repo.NewProductVariantRepository(db.DB, repoOpts...)
+	productHandler.SetVariantService(service.NewProductVariantService(variantRepo, productRepo, lg))
+
+	// 订单号生成器：秒杀参与、秒杀消息补发与购物车结算共用，同一节点编号只能有一个生成器
+	orderNos, err := idgen.NewSnowflake(cfg.App.WorkerID)
+	if err != nil {
+		lg.Sugar().Fatalw("failed to create order no generator", "error", err)
+	}
+	// 支付渠道：普通订单与秒杀订单共用；未配置回调密钥时沙箱使用进程内随机密钥
+	if cfg.Payment.WebhookSecret == "" {
+		lg.Sugar().Warnw("payment webhook secret not set, using a per-process random secret - set PAYMENT_WEBHOOK_SECRET")
+	}
+	paymentSandbox := payment.NewSandbox(cfg.Payment.WebhookSecret)
+
+	// 购物车与普通订单：结算时预留库存，支付后消费，取消或过期时释放；秒杀订单支付后由消费者创建对应的普通订单
+	orderOpts := []service.OrderServiceOption{service.WithOrderNoGenerator(orderNos), service.WithOrderVariants(variantRepo)}
+	if cfg.Cache.Enabled {
+		orderOpts = append(orderOpts, service.WithOrderStopSell(cache.NewStopSellFlags(cacheInstance)))
+	}
+	orderService := service.NewOrderService(repo.NewOrderRepository(db.DB, repoOpts...), repo.NewCartRepository(db.DB, repoOpts...),
+		productRepo, inventoryRepo, paymentSandbox, cfg.Payment.Currency,
+		service.OwnershipPolicy{HideForeign: cfg.Authz.HideForeignResources},
+		&service.OrderServiceConfig{PaymentTimeout: cfg.Order.PaymentTimeout, CartMaxItems: cfg.Order.CartMaxItems}, lg, orderOpts...)
+	if cfg.OrderExpiry.Enabled {
+		service.NewOrderExpirer(orderService, cfg.OrderExpiry.ScanInterval, cfg.OrderExpiry.BatchSize, lg).Start(bgCtx)
+	}
+	orderHandler := api.NewOrderHandler(orderService, lg)
+
+	// 后台任务队列：预热全部活动、批量导入与报表生成在固定数量的工作协程中执行
+	adminJobQueue := service.NewAdminJobQueue(&service.AdminJobQueueConfig{
+		Workers:   cfg.AdminJobs.Workers,
+		QueueSize: cfg.AdminJobs.QueueSize,
+		Retain:    cfg.AdminJobs.Retain,
+		Timeout:   cfg.AdminJobs.Timeout,
+	}, lg)
+	adminJobQueue.Start(bgCtx)
+	inventoryHandler.SetJobQueue(adminJobQueue)
+
 	// 秒杀相关组件初始化
 	var spikeHandler *api.SpikeHandler
 	var spikeRoutesConfig *router.SpikeRoutesConfig
@@ -130,12 +428,7 @@ func initDependencies(cfg *config.Config, db *database.DB, cacheInstance cache.C
 	// 检查是否启用了秒杀功能（基于Redis缓存是否可用）
 	if cfg.Cache.Enabled && cfg.Cache.Type == "redis" {
 		// 创建Redis连接用于秒杀功能
-		redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
-		redisClient := redis.NewClient(&redis.Options{
-			Addr:     redisAddr,
-			Password: cfg.Redis.Password,
-			DB:       cfg.Redis.DB,
-		})
+		redisClient := newRedisClient(cfg, cfg.Redis.SpikeDB)
 
 		// 测试Redis连接
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -146,98 +439,565 @@ func initDependencies(cfg *config.Config, db *database.DB, cacheInstance cache.C
 		} else {
 			// 初始化秒杀缓存
 			spikeCache := cache.NewSpikeCache(redisClient)
+			spikeCache.SetKeyPrefix(cfg.Redis.KeyPrefix)
 
-			// 初始化限流器配置
-			globalLimiterConfig := &limiter.Config{
-				Rate:      1000,
-				Window:    time.Minute,
-				Burst:     1000,
-				KeyPrefix: "limit:global",
+			// 限流器可使用独立的数据库编号
+			limiterClient := redisClient
+			if cfg.Redis.LimiterDB != cfg.Redis.SpikeDB {
+				limiterClient = newRedisClient(cfg, cfg.Redis.LimiterDB)
 			}
-			userLimiterConfig := &limiter.Config{
-				Rate:      5,
-				Window:    time.Minute,
-				Burst:     10,
-				KeyPrefix: "limit:user",
+			checker.Register("redis", true, func(ctx context.Context) error { return redisClient.Ping(ctx).Err() })
+			if limiterClient != redisClient {
+				checker.Register("redis_limiter", false, func(ctx context.Context) error { return limiterClient.Ping(ctx).Err() })
 			}
+			closeRedis := func() {
+				if limiterClient != redisClient {
+					limiterClient.Close()
+				}
+				redisClient.Close()
+			}
+
+			// 初始化限流器配置
+			userLimiterConfig := newUserLimiterConfig(cfg)
 			apiLimiterConfig := &limiter.Config{
 				Rate:      100,
 				Window:    time.Minute,
 				Burst:     200,
-				KeyPrefix: "limit:api",
+				KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:api"),
 			}
 
 			// 初始化限流器
-			globalLimiter, err := limiter.NewTokenBucketLimiter(redisClient, globalLimiterConfig)
+			// 秒杀全局与单用户限流器支持配置热更新时整体替换
+			globalBase, err := limiter.NewTokenBucketLimiter(limiterClient, newGlobalLimiterConfig(cfg))
 			if err != nil {
 				lg.Sugar().Warnw("failed to create global limiter", "error", err)
-				redisClient.Close()
+				closeRedis()
 				return &router.Dependencies{
-					UserHandler:      userHandler,
-					ProductHandler:   productHandler,
-					InventoryHandler: inventoryHandler,
-					JWTService:       jwtService,
+					UserHandler:          userHandler,
+					ProductHandler:       productHandler,
+					InventoryHandler:     inventoryHandler,
+					OrderHandler:         orderHandler,
+					ImpersonationHandler: impersonationHandler,
+					TimeHandler:          timeHandler,
+					MetaHandler:          metaHandler,
+					JWTService:           jwtService,
 				}
 			}
 
-			userLimiter, err := limiter.NewSlidingWindowLimiter(redisClient, userLimiterConfig)
+			userBase, err := limiter.NewSlidingWindowLimiter(limiterClient, userLimiterConfig)
 			if err != nil {
 				lg.Sugar().Warnw("failed to create user limiter", "error", err)
-				redisClient.Close()
+				closeRedis()
 				return &router.Dependencies{
-					UserHandler:      userHandler,
-					ProductHandler:   productHandler,
-					InventoryHandler: inventoryHandler,
-					JWTService:       jwtService,
+					UserHandler:          userHandler,
+					ProductHandler:       productHandler,
+					InventoryHandler:     inventoryHandler,
+					OrderHandler:         orderHandler,
+					ImpersonationHandler: impersonationHandler,
+					TimeHandler:          timeHandler,
+					MetaHandler:          metaHandler,
+					JWTService:           jwtService,
 				}
 			}
 
-			apiLimiter, err := limiter.NewFixedWindowLimiter(redisClient, apiLimiterConfig)
+			globalLimiter := limiter.NewReloadable(globalBase)
+			userLimiter := limiter.NewReloadable(userBase)
+
+			apiLimiter, err := limiter.NewFixedWindowLimiter(limiterClient, apiLimiterConfig)
 			if err != nil {
 				lg.Sugar().Warnw("failed to create API limiter", "error", err)
-				redisClient.Close()
+				closeRedis()
 				return &router.Dependencies{
-					UserHandler:      userHandler,
-					ProductHandler:   productHandler,
-					InventoryHandler: inventoryHandler,
-					JWTService:       jwtService,
+					UserHandler:          userHandler,
+					ProductHandler:       productHandler,
+					InventoryHandler:     inventoryHandler,
+					OrderHandler:         orderHandler,
+					ImpersonationHandler: impersonationHandler,
+					TimeHandler:          timeHandler,
+					MetaHandler:          metaHandler,
+					JWTService:           jwtService,
 				}
 			}
 
-			// 初始化MQ组件（可选，如果配置了RabbitMQ）
-			var spikeProducer *mq.SpikeProducer
-			// TODO: 这里可以根据配置初始化RabbitMQ组件
-			// mqConfig := &mq.RabbitMQConfig{...}
-			// spikeProducer = mq.NewSpikeProducer(mqConfig, lg)
+			// 匿名只读接口按 IP 限流，SPIKE_ANONYMOUS_RATE_LIMIT=0 时不开放
+			var anonymousLimiter limiter.Limiter
+			if cfg.Spike.AnonymousRateLimit > 0 {
+				anonymousLimiter, err = limiter.NewFixedWindowLimiter(limiterClient, &limiter.Config{
+					Rate:      cfg.Spike.AnonymousRateLimit,
+					Window:    cfg.Spike.RateLimitWindow,
+					Burst:     cfg.Spike.AnonymousRateLimit,
+					KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:anon"),
+				})
+				if err != nil {
+					lg.Sugar().Warnw("failed to create anonymous limiter, anonymous spike routes disabled", "error", err)
+					anonymousLimiter = nil
+				}
+			}
 
 			// 初始化秒杀仓储
-			spikeEventRepo := repo.NewSpikeEventRepository(db.DB)
-			spikeOrderRepo := repo.NewSpikeOrderRepository(db.DB)
+			spikeEventRepo := repo.NewSpikeEventRepository(db.DB, repoOpts...)
+			spikeOrderRepo := repo.NewSpikeOrderRepository(db.DB, repoOpts...)
+			orderEventRepo := repo.NewOrderEventRepository(db.DB)
+			abandonedRepo := repo.NewAbandonedCheckoutRepository(db.DB)
+			spikeCampaignRepo := repo.NewSpikeCampaignRepository(db.DB)
+			spikeQuotaRepo := repo.NewSpikeQuotaRepository(db.DB, repoOpts...)
+			// 单用户每日秒杀成功次数上限：规则存于数据库，次数在 Redis 中按自然日计数
+			dailyQuota := service.NewDailyQuota(spikeQuotaRepo, spikeCache, lg)
+
+			// 库存不变量检查：对账任务定期检查进行中活动，库存变更路径在变更后即时检查
+			invariantChecker := service.NewStockInvariantChecker(spikeEventRepo, spikeCache, inventoryRepo, &service.StockInvariantConfig{
+				Interval:          cfg.StockInvariant.Interval,
+				FreezeOnViolation: cfg.StockInvariant.FreezeOnViolation,
+				FreezeTTL:         cfg.StockInvariant.FreezeTTL,
+			}, lg)
+			invariantChecker.Start(bgCtx)
+
+			// 初始化MQ组件：消息处理服务由 RabbitMQ 与 Redis Streams 消费者共用
+			spikeMessages := service.NewSpikeMessageService(spikeEventRepo, spikeOrderRepo, inventoryRepo, orderEventRepo, spikeCache, lg)
+			spikeMessages.SetTransactionDB(db.DB)
+			spikeMessages.SetInvariantChecker(invariantChecker)
+			// 参与成功时预先生成订单号，消费者为升级前的消息补发；支付后为秒杀订单创建普通订单
+			spikeMessages.SetOrderNoGenerator(orderNos)
+			spikeMessages.SetOrderCreator(orderService)
+			spikeMessages.SetDailyQuota(dailyQuota)
+			spikeMessages.SetVariantStock(variantRepo)
+			// 订单取消或过期后才到达的支付原路退回
+			spikeMessages.SetPaymentRefunder(paymentSandbox)
+			// 通知渠道：均未配置时通知消费者只记录日志
+			var notifications mq.NotificationSender
+			if dispatcher := newNotifyDispatcher(cfg); !dispatcher.Empty() {
+				notifications = service.NewNotificationService(dispatcher,
+					repo.NewNotificationDeliveryRepository(db.DB, repoOpts...), userRepo, lg)
+			}
+			var spikeProducer mq.SpikePublisher
+			// 上线检查与状态快照使用的消费者状态与队列深度来源
+			var busConsumer *mq.SpikeConsumer
+			var busQueues api.QueueInspector
+			switch cfg.MQ.Type {
+			case "redis":
+				// 使用 Redis Streams 作为内部事件总线，生产与消费都在本进程内完成
+				streamConfig := mq.DefaultRedisStreamConfig()
+				streamConfig.MaxLen = cfg.MQ.StreamMaxLen
+				streamConfig.ClaimMinIdle = cfg.MQ.StreamClaimIdle
+				streamConfig.MaxDeliveries = int64(cfg.MQ.StreamMaxDeliveries)
+				streamConfig.KeyPrefix = cfg.Redis.KeyPrefix
+				streamProducer := mq.NewRedisStreamProducer(redisClient, streamConfig, lg)
+				spikeProducer = streamProducer
+
+				spikeMessages.SetNotificationPublisher(streamProducer)
+				spikeMessages.SetAbandonedCheckoutTracking(abandonedRepo, streamProducer)
+				spikeConsumer := mq.NewSpikeConsumer(nil, spikeMessages, lg)
+				spikeConsumer.SetNotificationSender(notifications)
+				if err := spikeConsumer.StartStreamConsumers(bgCtx, redisClient, streamConfig); err != nil {
+					lg.Sugar().Warnw("failed to start redis stream consumers", "error", err)
+				}
+				busConsumer = spikeConsumer
+				busQueues = streamProducer
+				// 排空阶段停止拉取新消息，等待进行中的消息处理完成
+				lm.OnDrain(func() { _ = spikeConsumer.StopConsumers() })
+			default:
+				// 默认使用 RabbitMQ，消费者在本进程内启动
+				cm, rabbitProducer, err := connectRabbitMQ(bgCtx, cfg, lg)
+				if err != nil {
+					lg.Sugar().Warnw("failed to initialize RabbitMQ, spike features disabled", "error", err)
+					closeRedis()
+					return &router.Dependencies{
+						UserHandler:          userHandler,
+						ProductHandler:       productHandler,
+						InventoryHandler:     inventoryHandler,
+						OrderHandler:         orderHandler,
+						ImpersonationHandler: impersonationHandler,
+						TimeHandler:          timeHandler,
+						MetaHandler:          metaHandler,
+						JWTService:           jwtService,
+					}
+				}
+				spikeProducer = rabbitProducer
+				checker.Register("rabbitmq", true, cm.Ping)
+
+				spikeMessages.SetNotificationPublisher(rabbitProducer)
+				spikeMessages.SetAbandonedCheckoutTracking(abandonedRepo, rabbitProducer)
+				// 订单过期延时消息依赖 RabbitMQ 的消息 TTL 与死信路由，Redis Streams 只依赖过期任务扫描
+				if cfg.OrderExpiry.DelayedMessages {
+					spikeMessages.SetExpiryScheduler(rabbitProducer)
+				}
+				spikeConsumer := mq.NewSpikeConsumer(cm, spikeMessages, lg)
+				spikeConsumer.SetOrderBatch(cfg.MQ.OrderBatchSize, cfg.MQ.OrderBatchWait)
+				spikeConsumer.SetNotificationSender(notifications)
+				if err := spikeConsumer.StartConsumers(bgCtx); err != nil {
+					lg.Sugar().Warnw("failed to start rabbitmq consumers", "error", err)
+				}
+				busConsumer = spikeConsumer
+				busQueues = rabbitProducer
+				// 排空阶段停止拉取新消息；HTTP 服务器关闭后再关闭生产者与连接，排空期间仍可发布消息
+				lm.OnDrain(func() { _ = spikeConsumer.StopConsumers() })
+				lm.OnShutdown(func() {
+					if err := rabbitProducer.Close(); err != nil {
+						lg.Sugar().Warnw("failed to close rabbitmq producer", "error", err)
+					}
+					if err := cm.Close(); err != nil {
+						lg.Sugar().Warnw("failed to close rabbitmq connection", "error", err)
+					}
+				})
+			}
+			// 发件箱中继自带持久化重试，使用未包装的发布者；其余发布方经容错层发布，
+			// 消息队列短暂抖动时重试或写入本地缓冲补发，而不是直接回滚库存并让秒杀请求失败
+			rawProducer := spikeProducer
+			resilientProducer := mq.NewResilientPublisher(rawProducer, newResilientPublisherConfig(cfg), lg)
+			resilientProducer.Start(bgCtx)
+			// 关闭回调逆序执行：先补发缓冲消息，再关闭底层生产者
+			lm.OnShutdown(func() { resilientProducer.Flush(5 * time.Second) })
+			spikeProducer = resilientProducer
 
 			// 初始化秒杀服务
+			spikeServiceConfig := newSpikeServiceConfig(cfg)
 			spikeService := service.NewSpikeService(
 				spikeEventRepo,
 				spikeOrderRepo,
 				productRepo,
 				inventoryRepo,
 				userRepo,
+				orderEventRepo,
 				spikeCache,
 				spikeProducer,
 				globalLimiter,
 				userLimiter,
-				service.DefaultSpikeServiceConfig(),
+				spikeServiceConfig,
 				lg,
 			)
+			spikeService.SetUserCache(userCache)
+			spikeService.SetOrderNoGenerator(orderNos)
+			if len(cfg.Database.ReplicaAddrs) > 0 {
+				spikeService.SetReplicationLag(db)
+			}
+			// 库存缓存熔断：Redis 连续失败后按 SPIKE_DEGRADED_MODE 降级参与，避免整条秒杀链路硬失败
+			if cfg.SpikeDegraded.Mode != service.DegradedModeOff {
+				spikeService.SetCacheBreaker(breaker.New("spike_cache", breaker.Config{
+					FailureThreshold: cfg.SpikeDegraded.BreakerFailures,
+					OpenTimeout:      cfg.SpikeDegraded.BreakerOpenTimeout,
+				}))
+			}
+
+			// 消息发件箱：订单创建与取消消息与业务数据一起落库，由中继发布到消息队列，失败按指数退避重试
+			if cfg.Outbox.Enabled {
+				outboxConfig := service.DefaultOutboxRelayConfig()
+				outboxConfig.Interval = cfg.Outbox.Interval
+				outboxConfig.BatchSize = cfg.Outbox.BatchSize
+				outboxConfig.MaxAttempts = cfg.Outbox.MaxAttempts
+				outboxConfig.RetryBackoff = cfg.Outbox.RetryBackoff
+				outboxConfig.Retention = cfg.Outbox.Retention
+				outboxRelay := service.NewOutboxRelay(repo.NewOutboxRepository(db.DB), rawProducer, outboxConfig, lg)
+				spikeService.SetOutbox(outboxRelay)
+				outboxRelay.Start(bgCtx)
+			}
+
+			// 按用户等级构建单用户限流器（配额 = 基础配额 × 等级倍数）
+			tierLimiters := make(map[domain.UserTier]limiter.Limiter)
+			tierReloadables := make(map[domain.UserTier]*limiter.Reloadable)
+			for tier, tierLimiter := range newTierLimiters(limiterClient, userLimiterConfig, spikeServiceConfig.TierPolicies, lg) {
+				tierReloadables[tier] = limiter.NewReloadable(tierLimiter)
+				tierLimiters[tier] = tierReloadables[tier]
+			}
+			spikeService.SetTierLimiters(tierLimiters)
+
+			// 配置热更新：限流阈值变化后以新配置重建限流器，缓存过期时间变化后更新秒杀服务
+			watcher.Subscribe(func(c *config.Config) {
+				if l, err := limiter.NewTokenBucketLimiter(limiterClient, newGlobalLimiterConfig(c)); err != nil {
+					lg.Sugar().Warnw("failed to rebuild global limiter, keeping previous limits", "error", err)
+				} else {
+					globalLimiter.Swap(l)
+				}
+				reloadedUserConfig := newUserLimiterConfig(c)
+				if l, err := limiter.NewSlidingWindowLimiter(limiterClient, reloadedUserConfig); err != nil {
+					lg.Sugar().Warnw("failed to rebuild user limiter, keeping previous limits", "error", err)
+				} else {
+					userLimiter.Swap(l)
+				}
+				for tier, tierLimiter := range newTierLimiters(limiterClient, reloadedUserConfig, spikeServiceConfig.TierPolicies, lg) {
+					if r, ok := tierReloadables[tier]; ok {
+						r.Swap(tierLimiter)
+					}
+				}
+				spikeService.UpdateCacheTTLs(c.Spike.StockCacheTTL, c.Spike.UserMarkTTL)
+			})
+
+			// 活动级限流：速率取活动配置的 qps_limit，窗口固定为 1 秒
+			if eventLimiter, err := limiter.NewFixedWindowLimiter(limiterClient, &limiter.Config{
+				Window:    time.Second,
+				KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:event"),
+			}); err != nil {
+				lg.Sugar().Warnw("failed to create event limiter, per-event qps limits disabled", "error", err)
+			} else {
+				spikeService.SetEventLimiter(eventLimiter)
+			}
+
+			// 参与令牌：预告期内预发放，活动开始后只处理携带有效令牌的请求
+			if cfg.SpikeToken.Enabled {
+				var tokenLimiter limiter.Limiter
+				if l, err := limiter.NewSlidingWindowLimiter(limiterClient, &limiter.Config{
+					Rate:      int64(cfg.SpikeToken.IssueRate),
+					Window:    time.Minute,
+					Burst:     int64(cfg.SpikeToken.IssueRate),
+					KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:token"),
+				}); err != nil {
+					lg.Sugar().Warnw("failed to create participation token limiter, issuance not rate limited", "error", err)
+				} else {
+					tokenLimiter = l
+				}
+				spikeService.SetParticipationTokens(
+					service.NewParticipationTokenSigner(cfg.SpikeToken.Secret),
+					tokenLimiter,
+					int64(cfg.SpikeToken.IssueMultiplier),
+				)
+			}
+
+			// 防刷挑战：开启 challenge_required 的活动只处理携带已完成挑战的请求
+			var challengeLimiter limiter.Limiter
+			if l, err := limiter.NewSlidingWindowLimiter(limiterClient, &limiter.Config{
+				Rate:      int64(cfg.SpikeChallenge.IssueRate),
+				Window:    time.Minute,
+				Burst:     int64(cfg.SpikeChallenge.IssueRate),
+				KeyPrefix: keys.WithPrefix(cfg.Redis.KeyPrefix, "limit:challenge"),
+			}); err != nil {
+				lg.Sugar().Warnw("failed to create challenge limiter, issuance not rate limited", "error", err)
+			} else {
+				challengeLimiter = l
+			}
+			spikeService.SetChallenges(spikeCache, challengeLimiter, cfg.SpikeChallenge.Difficulty, cfg.SpikeChallenge.TTL)
+
+			// 等候室：排队模式的活动先入队，由放行器按活动的每秒放行人数放行
+			spikeService.SetWaitingRoom(spikeCache)
+			service.NewWaitingRoomDispatcher(spikeCache, cfg.SpikeWaitingRoom.DispatchInterval, lg).Start(bgCtx)
+
+			// 参与日志：记录每次成功的下单，消息丢失时可用 cmd/journal-replay 回放
+			if cfg.Journal.Enabled {
+				journalWriter, err := journal.NewFileWriter(cfg.Journal.Dir, "", cfg.Journal.Fsync)
+				if err != nil {
+					lg.Sugar().Warnw("failed to init participation journal, journaling disabled", "error", err)
+				} else {
+					spikeService.SetJournal(journalWriter)
+					go func() {
+						<-bgCtx.Done()
+						if err := journalWriter.Close(); err != nil {
+							lg.Sugar().Errorw("failed to close participation journal", "error", err)
+						}
+					}()
+				}
+			}
+
+			// 启动库存守护：Redis 故障切换导致库存键丢失时冻结活动并按数据库重建
+			stockGuardianConfig := service.DefaultStockGuardianConfig()
+			stockGuardianConfig.StockCacheTTL = spikeServiceConfig.StockCacheTTL
+			stockGuardianConfig.StockTTLBuffer = spikeServiceConfig.StockTTLBuffer
+			stockGuardian := service.NewStockGuardian(spikeEventRepo, spikeOrderRepo, spikeCache, stockGuardianConfig, lg)
+			spikeService.SetStockGuardian(stockGuardian)
+			spikeService.SetInvariantChecker(invariantChecker)
+			spikeService.SetCampaignQuota(service.NewCampaignQuota(spikeCampaignRepo, spikeCache, lg))
+			spikeService.SetDailyQuota(dailyQuota)
+			if stopSellService != nil {
+				spikeService.SetStopSellChecker(cache.NewStopSellFlags(cacheInstance))
+				stopSellService.SetEventFreezer(spikeCache)
+			}
+			stockGuardian.Start(bgCtx)
+
+			// 库存对账：比对 Redis 库存、活动已售数量与订单，发现偏差记录日志并按配置修复
+			stockReconcilerConfig := service.DefaultStockReconcilerConfig()
+			stockReconcilerConfig.Interval = cfg.StockReconcile.Interval
+			stockReconcilerConfig.Repair = cfg.StockReconcile.Repair
+			stockReconcilerConfig.Tolerance = cfg.StockReconcile.Tolerance
+			stockReconcilerConfig.GracePeriod = cfg.StockReconcile.GracePeriod
+			stockReconcilerConfig.StockCacheTTL = spikeServiceConfig.StockCacheTTL
+			stockReconcilerConfig.StockTTLBuffer = spikeServiceConfig.StockTTLBuffer
+			stockReconciler := service.NewStockReconciler(spikeEventRepo, spikeOrderRepo, spikeCache, stockReconcilerConfig, lg)
+			if cfg.StockReconcile.Enabled {
+				stockReconciler.Start(bgCtx)
+			}
+
+			// 启动支付提醒：待支付订单过期前按配置的档位推送提醒
+			if cfg.PaymentReminder.Enabled {
+				if spikeProducer == nil {
+					lg.Sugar().Warnw("message bus not configured, payment reminders disabled")
+				} else {
+					service.NewPaymentReminder(spikeOrderRepo, spikeCache, spikeProducer, &service.PaymentReminderConfig{
+						Offsets:      cfg.PaymentReminder.Offsets,
+						ScanInterval: cfg.PaymentReminder.ScanInterval,
+					}, lg).Start(bgCtx)
+				}
+			}
+
+			// 启动订单过期任务：超过支付期限的待支付订单标记为已过期，由消费者恢复库存
+			if cfg.OrderExpiry.Enabled {
+				if spikeProducer == nil {
+					lg.Sugar().Warnw("message bus not configured, order expiry disabled")
+				} else {
+					service.NewSpikeOrderExpirer(spikeOrderRepo, spikeEventRepo, spikeProducer,
+						cfg.OrderExpiry.ScanInterval, cfg.OrderExpiry.BatchSize, lg).Start(bgCtx)
+				}
+			}
+
+			// 启动预告期调度器：活动进入预告期时预热库存与活动缓存，并为进行中活动续期库存键
+			spikeScheduler := service.NewSpikeScheduler(spikeEventRepo, spikeService, spikeServiceConfig.PreviewScanInterval, lg)
+			spikeScheduler.SetStockTTLRefresher(service.NewStockTTLRefresher(spikeEventRepo, spikeCache,
+				spikeServiceConfig.StockCacheTTL, spikeServiceConfig.StockTTLBuffer, lg), cfg.Spike.StockTTLRefresh)
+			spikeScheduler.Start(bgCtx)
+
+			// 启动库存定时预热：活动开始前 StockWarmupTime 内自动预热库存，多实例间以分布式锁互斥
+			if spikeServiceConfig.StockWarmupEnabled {
+				service.NewStockWarmupScheduler(spikeEventRepo, spikeService, spikeCache,
+					spikeServiceConfig.StockWarmupTime, spikeServiceConfig.PreviewScanInterval, lg).Start(bgCtx)
+			}
 
 			// 初始化秒杀处理器
 			spikeHandler = api.NewSpikeHandler(spikeService, lg)
+			spikeHandler.SetAdminJobQueue(adminJobQueue)
+			// 活动分享链接：签名链接到达与经链接参与计入分享归因
+			shareAttributionRepo := repo.NewSpikeShareAttributionRepository(db.DB)
+			shareService := service.NewSpikeShareService(spikeEventRepo, shareAttributionRepo,
+				service.NewShareLinkSigner(cfg.SpikeShare.Secret), cfg.SpikeShare.LinkBase, cfg.SpikeShare.LinkTTL, lg)
+			spikeService.SetShareAttribution(shareService)
+			spikeHandler.SetShareService(shareService)
+			// 初始化活动报表服务（本地文件存储 + 签名下载地址）
+			reportStore, err := storage.NewLocalStorage(cfg.Storage.Dir)
+			if err != nil {
+				lg.Sugar().Warnw("failed to init report storage, report features disabled", "error", err)
+			} else {
+				reportSigner := storage.NewURLSigner(cfg.Storage.URLSecret, "/api/v1/reports/download", cfg.Storage.URLTTL)
+				spikeHandler.SetReportService(service.NewSpikeReportService(
+					spikeEventRepo, spikeOrderRepo, orderEventRepo, reportStore, reportSigner, lg,
+					service.WithShareAttribution(shareAttributionRepo),
+					service.WithReportJobQueue(adminJobQueue)))
+			}
+			// 活动结算：结束的活动按结算日汇总收入与手续费，管理员查询并标记打款
+			settlementService := service.NewSpikeSettlementService(
+				repo.NewSpikeSettlementRepository(db.DB), cfg.Settlement.FeeRate, lg)
+			spikeHandler.SetSettlementService(settlementService)
+			if cfg.Settlement.Enabled {
+				service.NewSettlementWorker(spikeEventRepo, settlementService, &service.SettlementWorkerConfig{
+					Interval: cfg.Settlement.Interval,
+					Delay:    cfg.Settlement.Delay,
+					Lookback: cfg.Settlement.Lookback,
+				}, lg).Start(bgCtx)
+			}
+			// 订单支付：扣款成功后经消息总线更新订单，未配置消息总线时不启用支付
+			if spikeProducer != nil {
+				spikeHandler.SetPaymentService(service.NewSpikePaymentService(paymentSandbox, spikeOrderRepo, orderEventRepo,
+					spikeProducer, cfg.Payment.Currency, spikeServiceConfig.Ownership, lg))
+				// 沙箱模拟支付只在显式开启时开放，沙箱意图只保存在进程内存中，仅适用于单实例联调
+				if cfg.Payment.SandboxAuthorize {
+					spikeHandler.SetPaymentSandbox(paymentSandbox)
+				}
+			}
+			// 弃单召回：未配置消息总线时只能查询与记录召回活动，不发送通知
+			var recoveryNotifier mq.NotificationPublisher
+			if spikeProducer != nil {
+				recoveryNotifier = spikeProducer
+			}
+			spikeHandler.SetAbandonedCheckoutService(service.NewAbandonedCheckoutService(abandonedRepo, recoveryNotifier, lg))
+			// 活动补货：与弃单召回共用通知发布者，未配置消息总线时不广播补货通知
+			spikeHandler.SetRestockService(service.NewSpikeRestockService(
+				spikeEventRepo, spikeCache, recoveryNotifier, spikeServiceConfig.StockCacheTTL, lg))
+			// 活动状态流转：按时间激活与结束活动，支持管理员暂停与恢复，状态变更时广播通知
+			// 草稿活动经排期与审批后，由状态流转任务在发布时间发布
+			publicationRepo := repo.NewSpikeEventPublicationRepository(db.DB, repoOpts...)
+			eventLifecycle := service.NewSpikeEventLifecycle(spikeEventRepo, spikeCache, recoveryNotifier, cfg.Spike.LifecycleInterval, lg)
+			eventLifecycle.SetPublications(publicationRepo)
+			eventLifecycle.Start(bgCtx)
+			spikeHandler.SetLifecycleService(eventLifecycle)
+			spikeHandler.SetPublishingService(service.NewSpikeEventPublishingService(spikeEventRepo, publicationRepo, lg))
+			spikeHandler.SetCampaignService(service.NewSpikeCampaignService(
+				spikeCampaignRepo, spikeEventRepo, spikeCache, spikeServiceConfig.StockCacheTTL, lg))
+			spikeHandler.SetQuotaService(service.NewSpikeQuotaService(spikeQuotaRepo, dailyQuota, lg))
+			spikeHandler.SetEventAdminService(service.NewSpikeEventAdminService(spikeEventRepo, productRepo, lg,
+				service.WithImportVariants(variantRepo)))
+			spikeHandler.SetDashboardService(service.NewSpikeDashboardService(
+				repo.NewSpikeDashboardRepository(db.DB, repoOpts...), cacheInstance, cfg.Spike.DashboardCacheTTL, lg))
+			// 库存长轮询：进程内单个订阅连接接收库存变更通知
+			stockChanges := cache.NewStockChangeSubscriber(redisClient)
+			stockChanges.SetKeyPrefix(cfg.Redis.KeyPrefix)
+			stockWatcher := service.NewStockWatcher(stockChanges, spikeCache, spikeEventRepo, lg)
+			stockWatcher.Start(bgCtx)
+			spikeHandler.SetStockWatcher(stockWatcher)
+			var publicCatalog service.PublicSpikeCatalog
+			if anonymousLimiter != nil {
+				publicCatalog = service.NewPublicSpikeCatalog(spikeService, cacheInstance, cfg.Spike.PublicCacheTTL, lg)
+				spikeHandler.SetPublicCatalog(publicCatalog)
+			}
+			// 活动资源清理：活动结束并过宽限期后删除其 Redis 键、结束库存等待请求并删除缓存视图
+			var cleanupWorker *service.EventCleanupWorker
+			if cfg.Cleanup.Enabled {
+				cleanupWorker = service.NewEventCleanupWorker(spikeEventRepo, spikeOrderRepo, spikeCache, &service.EventCleanupConfig{
+					Interval:    cfg.Cleanup.Interval,
+					GracePeriod: cfg.Cleanup.GracePeriod,
+					Lookback:    cfg.Cleanup.Lookback,
+				}, lg)
+				cleanupWorker.SetWaiterReleaser(stockWatcher)
+				if publicCatalog != nil {
+					cleanupWorker.SetViewEvictor(publicCatalog)
+				}
+				cleanupWorker.Start(bgCtx)
+			}
+			// 活动与订单归档：早已结束的活动与终态订单迁移到归档表
+			var archiver *service.SpikeArchiver
+			if cfg.Archive.Enabled {
+				archiver = service.NewSpikeArchiver(repo.NewSpikeArchiveRepository(db.DB, repoOpts...), &service.SpikeArchiveConfig{
+					Interval:  cfg.Archive.Interval,
+					After:     cfg.Archive.After,
+					BatchSize: cfg.Archive.BatchSize,
+				}, lg)
+				archiver.Start(bgCtx)
+			}
+			// 活动上线检查
+			preflight := service.NewSpikePreflight(spikeEventRepo, spikeOrderRepo, spikeCache, &service.SpikePreflightConfig{
+				GlobalRateLimit:      spikeServiceConfig.GlobalRateLimit,
+				UserRateLimit:        spikeServiceConfig.UserRateLimit,
+				RateLimitWindow:      spikeServiceConfig.RateLimitWindow,
+				MaxLimiterHeadroom:   service.DefaultSpikePreflightConfig().MaxLimiterHeadroom,
+				MaxDBPoolUtilization: service.DefaultSpikePreflightConfig().MaxDBPoolUtilization,
+			}, lg)
+			preflight.SetDBStats(db.DB)
+			if busConsumer != nil {
+				preflight.SetConsumers(busConsumer)
+				preflight.SetQueues(busQueues)
+			}
+			spikeHandler.SetPreflight(preflight)
+			spikeHandler.SetStockReconciler(stockReconciler)
+
+			statusSources := &api.StatusSources{
+				Limiters: []api.LimiterProbe{
+					{Name: "spike", Limiter: globalLimiter},
+					{Name: "user", Limiter: userLimiter},
+					{Name: "api", Limiter: apiLimiter},
+				},
+				Invariants: invariantChecker,
+			}
+			if busConsumer != nil {
+				statusSources.Consumers = busConsumer
+				statusSources.Queues = busQueues
+			}
+			if cleanupWorker != nil {
+				statusSources.Cleanup = cleanupWorker
+			}
+			if archiver != nil {
+				statusSources.Archive = archiver
+			}
+			spikeHandler.SetStatusSources(statusSources)
+
+			// 按 Idempotency-Key 缓存参与、取消与支付的响应，多实例共享
+			idempotencyStore := cache.NewIdempotencyStore(redisClient)
+			idempotencyStore.SetKeyPrefix(cfg.Redis.KeyPrefix)
+			idempotencyConfig := middleware.DefaultIdempotencyConfig()
+			idempotencyConfig.Store = idempotencyStore
+			idempotencyConfig.CacheTTL = cfg.Spike.IdempotencyTTL
 
-			// 配置秒杀路由（暂时使用空的中间件函数，后续完善）
+			// 配置秒杀路由
 			spikeRoutesConfig = &router.SpikeRoutesConfig{
-				JWTMiddleware:   func(c *gin.Context) { c.Next() }, // TODO: 实现JWT认证中间件
-				AdminMiddleware: func(c *gin.Context) { c.Next() }, // TODO: 实现管理员权限中间件
-				SpikeLimiter:    globalLimiter,                     // 秒杀专用限流器
-				APILimiter:      apiLimiter,                        // API通用限流器
+				JWTMiddleware:    middleware.GinAuth(jwtService, lg),                  // JWT认证中间件
+				AdminMiddleware:  middleware.GinRequireAdmin(lg),                      // 管理员权限中间件
+				SpikeLimiter:     globalLimiter,                                       // 秒杀专用限流器
+				APILimiter:       apiLimiter,                                          // API通用限流器
+				DrainGuard:       middleware.GinDrainGuard(lm, time.Second),           // 排空时拒绝参与秒杀
+				AnonymousLimiter: anonymousLimiter,                                    // 匿名只读接口限流器
+				Idempotency:      middleware.IdempotencyMiddleware(idempotencyConfig), // 幂等响应缓存
 			}
 
 			lg.Sugar().Infow("spike features initialized successfully")
@@ -247,17 +1007,104 @@ func initDependencies(cfg *config.Config, db *database.DB, cacheInstance cache.C
 	}
 
 	return &router.Dependencies{
-		UserHandler:       userHandler,
-		ProductHandler:    productHandler,
-		InventoryHandler:  inventoryHandler,
-		SpikeHandler:      spikeHandler,
-		JWTService:        jwtService,
-		SpikeRoutesConfig: spikeRoutesConfig,
+		UserHandler:          userHandler,
+		ProductHandler:       productHandler,
+		InventoryHandler:     inventoryHandler,
+		OrderHandler:         orderHandler,
+		ImpersonationHandler: impersonationHandler,
+		TimeHandler:          timeHandler,
+		MetaHandler:          metaHandler,
+		SpikeHandler:         spikeHandler,
+		JWTService:           jwtService,
+		SpikeRoutesConfig:    spikeRoutesConfig,
+	}
+}
+
+// initHealthChecker 创建就绪检查器并注册数据库与缓存，Redis、RabbitMQ 在初始化成功后由 initDependencies 注册
+func initHealthChecker(cfg *config.Config, db *database.DB, cacheInstance cache.Cache, lm *lifecycle.Manager) *health.Checker {
+	checker := health.NewChecker(health.DefaultTimeout)
+	checker.Register("mysql", true, db.PingContext)
+	// 缓存不可用时回源数据库，不影响就绪
+	if cfg.Cache.Enabled {
+		checker.Register("cache", false, cacheInstance.Ping)
 	}
+	checker.SetDraining(lm.IsDraining)
+	return checker
+}
+
+// initDebugCapture 初始化请求/响应调试捕获，正文按日志脱敏规则处理
+func initDebugCapture(cfg *config.Config, deps *router.Dependencies, lg *zap.Logger) {
+	redactRules, _ := logger.ParseRedactRules(cfg.Log.RedactRules)
+	capture := middleware.NewDebugCapture(middleware.DebugCaptureConfig{
+		DebugCaptureSettings: middleware.DebugCaptureSettings{
+			Enabled:      cfg.DebugCapture.Enabled,
+			Routes:       cfg.DebugCapture.Routes,
+			SampleRate:   cfg.DebugCapture.SampleRate,
+			OnlyFailures: cfg.DebugCapture.OnlyFailures,
+		},
+		MaxBodyBytes: cfg.DebugCapture.MaxBodyBytes,
+		BufferSize:   cfg.DebugCapture.BufferSize,
+		RedactRules:  logger.DefaultRedactRules(cfg.App.Env).Merge(redactRules),
+	})
+	deps.DebugCapture = capture
+	deps.DebugHandler = api.NewDebugHandler(capture, lg)
+}
+
+// initAdminAudit 初始化管理员写操作审计，变更数据与请求体按日志脱敏规则处理
+func initAdminAudit(cfg *config.Config, db *database.DB, deps *router.Dependencies, lg *zap.Logger) {
+	if !cfg.AdminAudit.Enabled {
+		return
+	}
+	redactRules, _ := logger.ParseRedactRules(cfg.Log.RedactRules)
+	auditService := service.NewAdminAuditService(repo.NewAdminAuditRepository(db.DB), lg)
+	deps.AdminAudit = middleware.AdminAudit(middleware.AdminAuditConfig{
+		Recorder:        auditService,
+		MaxPayloadBytes: cfg.AdminAudit.MaxPayloadBytes,
+		RedactRules:     logger.DefaultRedactRules(cfg.App.Env).Merge(redactRules),
+	})
+	deps.AdminAuditHandler = api.NewAdminAuditHandler(auditService, lg)
+	if deps.SpikeRoutesConfig != nil {
+		deps.SpikeRoutesConfig.AdminAudit = deps.AdminAudit
+	}
+}
+
+// connectRabbitMQ 连接 RabbitMQ、声明秒杀交换机与队列并创建生产者，失败时关闭已建立的连接
+func connectRabbitMQ(ctx context.Context, cfg *config.Config, lg *zap.Logger) (*mq.ConnectionManager, *mq.SpikeProducer, error) {
+	mqConfig := mq.DefaultConfig()
+	mqConfig.Host = cfg.RabbitMQ.Host
+	mqConfig.Port = cfg.RabbitMQ.Port
+	mqConfig.Username = cfg.RabbitMQ.User
+	mqConfig.Password = cfg.RabbitMQ.Password
+	mqConfig.VHost = cfg.RabbitMQ.VHost
+	mqConfig.ConnectionTimeout = cfg.RabbitMQ.ConnectTimeout
+	mqConfig.Producer.EnableConfirm = cfg.RabbitMQ.PublishConfirm
+	if err := mqConfig.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid rabbitmq config: %w", err)
+	}
+
+	cm := mq.NewConnectionManager(mqConfig, lg)
+	connectCtx, cancel := context.WithTimeout(ctx, cfg.RabbitMQ.ConnectTimeout)
+	defer cancel()
+	if err := cm.Connect(connectCtx); err != nil {
+		return nil, nil, err
+	}
+
+	producer, err := mq.NewSpikeProducer(cm, mqConfig.Producer, lg)
+	if err == nil {
+		err = producer.SetupInfrastructure(connectCtx)
+		if err != nil {
+			_ = producer.Close()
+		}
+	}
+	if err != nil {
+		_ = cm.Close()
+		return nil, nil, fmt.Errorf("failed to set up spike queues: %w", err)
+	}
+	return cm, producer, nil
 }
 
 // startServer 启动服务器并处理优雅关闭
-func startServer(cfg *config.Config, handler http.Handler, lg *zap.Logger) {
+func startServer(cfg *config.Config, handler http.Handler, lm *lifecycle.Manager, lg *zap.Logger) {
 	addr := fmt.Sprintf(":%d", cfg.App.Port)
 	lg.Sugar().Infow("server starting", "addr", addr)
 	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 5 * time.Second}
@@ -280,19 +1127,31 @@ func startServer(cfg *config.Config, handler http.Handler, lg *zap.Logger) {
 		lg.Sugar().Infow("shutdown signal received")
 	}
 
+	// 先排空：参与秒杀开始返回可重试的 503，消费者停止拉取新消息；
+	// 留出 DrainDelay 让负载均衡摘除本实例后再关闭 HTTP 服务器
+	lm.BeginDrain()
+	lg.Sugar().Infow("draining before shutdown", "delay", cfg.App.DrainDelay)
+	time.Sleep(cfg.App.DrainDelay)
+
 	// 优雅关闭
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		lg.Sugar().Errorw("server shutdown error", "err", err)
 	}
+	if err := lm.Wait(ctx); err != nil {
+		lg.Sugar().Errorw("drain hooks did not finish before shutdown timeout", "err", err)
+	}
+	lm.Shutdown()
 	lg.Sugar().Infow("server exited")
 }
 
 // main 为应用入口，协调各个组件的初始化和启动
 func main() {
-	// 1) 加载配置和初始化日志
-	cfg, lg, err := initConfigAndLogger()
+	// 1) 加载配置（命令行 -config/-profile/-set > 环境变量 > 配置文件）和初始化日志
+	configOpts := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	cfg, lg, logLevel, err := initConfigAndLogger(*configOpts)
 	if err != nil {
 		log.Fatalf("failed to initialize config and logger: %v", err)
 	}
@@ -311,13 +1170,28 @@ func main() {
 	// 3) 初始化缓存
 	cacheInstance := initCache(cfg, lg)
 
-	// 4) 初始化应用依赖（仓储、服务、处理器）
-	deps := initDependencies(cfg, db, cacheInstance, lg)
+	// 4) 初始化应用依赖（仓储、服务、处理器），后台任务随服务退出而停止
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	lm := lifecycle.NewManager()
+	// 配置热更新：配置文件变化后重新加载，日志级别、秒杀限流与缓存过期时间无需重启即可生效
+	watcher := config.NewWatcher(*configOpts, cfg, lg)
+	watcher.Subscribe(func(c *config.Config) {
+		if err := logLevel.UnmarshalText([]byte(c.Log.Level)); err != nil {
+			lg.Sugar().Warnw("invalid reloaded log level", "level", c.Log.Level, "error", err)
+		}
+	})
+	watcher.Start(bgCtx, cfg.App.ReloadInterval)
+	checker := initHealthChecker(cfg, db, cacheInstance, lm)
+	deps := initDependencies(bgCtx, cfg, watcher, db, cacheInstance, checker, lm, lg)
+	deps.HealthHandler = api.NewHealthHandler(checker, cfg.App.Version)
+	initDebugCapture(cfg, deps, lg)
+	initAdminAudit(cfg, db, deps, lg)
 
 	// 5) 设置路由和中间件
 	r := router.New()
 	handler := r.Setup(cfg, deps, lg)
 
 	// 6) 启动 HTTP 服务器
-	startServer(cfg, handler, lg)
+	startServer(cfg, handler, lm, lg)
 }
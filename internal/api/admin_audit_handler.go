@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// AdminAuditHandler 提供管理员操作审计的查询接口
+type AdminAuditHandler struct {
+	auditService service.AdminAuditService
+	logger       *zap.Logger
+}
+
+// NewAdminAuditHandler 创建管理员操作审计处理器
+func NewAdminAuditHandler(auditService service.AdminAuditService, logger *zap.Logger) *AdminAuditHandler {
+	return &AdminAuditHandler{auditService: auditService, logger: logger}
+}
+
+// ListAuditLogs 查询管理员操作审计
+// @Summary 查询管理员操作审计
+// @Description 分页查询管理员写操作的审计记录（操作者、路由、对象、变更前后数据、响应状态与请求ID），按操作时间倒序
+// @Tags 管理员
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页大小(1-100)"
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1" default(true)
+// @Param actor query string false "操作者，如 admin:1"
+// @Param action query string false "操作，如 POST /api/v1/admin/spike/events/:id/pause"
+// @Param entity_type query string false "对象类型，如 spike_event"
+// @Param entity_id query string false "对象ID"
+// @Param request_id query string false "请求ID"
+// @Param from query string false "操作时间起(RFC3339)"
+// @Param to query string false "操作时间止(RFC3339，不含)"
+// @Success 200 {object} resp.Response[domain.AdminAuditListResponse] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Router /api/v1/admin/audit-logs [get]
+// @Security Bearer
+func (h *AdminAuditHandler) ListAuditLogs(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	req, fields := parseAdminAuditListRequest(c)
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, reqID, traceID)
+		return
+	}
+
+	result, err := h.auditService.ListAuditLogs(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("查询管理员操作审计失败", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"查询操作审计失败", reqID, traceID)
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", result, reqID, traceID)
+}
+
+// parseAdminAuditListRequest 解析审计查询参数，参数错误时返回各字段的错误
+func parseAdminAuditListRequest(c *gin.Context) (*domain.AdminAuditListRequest, []resp.FieldError) {
+	req := &domain.AdminAuditListRequest{
+		Page:       1,
+		PageSize:   20,
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		RequestID:  c.Query("request_id"),
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		req.PageSize = pageSize
+	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
+
+	var fields []resp.FieldError
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "from", Message: "from 必须为 RFC3339 时间"})
+		} else {
+			req.From = &from
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "to", Message: "to 必须为 RFC3339 时间"})
+		} else {
+			req.To = &to
+		}
+	}
+
+	return req, fields
+}
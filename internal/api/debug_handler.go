@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// DebugHandler 提供请求/响应调试捕获的管理接口
+type DebugHandler struct {
+	capture *middleware.DebugCapture
+	logger  *zap.Logger
+}
+
+// NewDebugHandler 创建调试捕获管理处理器
+func NewDebugHandler(capture *middleware.DebugCapture, logger *zap.Logger) *DebugHandler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &DebugHandler{capture: capture, logger: logger}
+}
+
+// DebugCaptureListResponse 调试捕获记录响应
+type DebugCaptureListResponse struct {
+	Settings middleware.DebugCaptureSettings `json:"settings"`
+	Entries  []middleware.DebugCaptureEntry  `json:"entries"`
+}
+
+// ListCaptures 查询调试捕获记录
+// @Summary 查询调试捕获记录
+// @Description 按时间倒序返回当前实例捕获的脱敏请求/响应体，多实例部署时需逐个实例查询
+// @Tags 管理员
+// @Produce json
+// @Param route query string false "路由模板，如 /api/v1/spike/participate"
+// @Param limit query int false "返回条数(1-200)，默认50"
+// @Success 200 {object} resp.Response[DebugCaptureListResponse] "成功"
+// @Router /api/v1/admin/debug/captures [get]
+// @Security Bearer
+func (h *DebugHandler) ListCaptures(c *gin.Context) {
+	limit := 50
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	data := &DebugCaptureListResponse{
+		Settings: h.capture.Settings(),
+		Entries:  h.capture.Entries(c.Query("route"), limit),
+	}
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", data,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// ClearCaptures 清空调试捕获记录
+// @Summary 清空调试捕获记录
+// @Tags 管理员
+// @Produce json
+// @Success 200 {object} resp.Response[any] "成功"
+// @Router /api/v1/admin/debug/captures [delete]
+// @Security Bearer
+func (h *DebugHandler) ClearCaptures(c *gin.Context) {
+	h.capture.Clear()
+	resp.WriteJSON[any](c.Writer, http.StatusOK, resp.CodeOK, "调试捕获记录已清空", nil,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// GetSettings 查询调试捕获设置
+// @Summary 查询调试捕获设置
+// @Tags 管理员
+// @Produce json
+// @Success 200 {object} resp.Response[middleware.DebugCaptureSettings] "成功"
+// @Router /api/v1/admin/debug/capture-settings [get]
+// @Security Bearer
+func (h *DebugHandler) GetSettings(c *gin.Context) {
+	settings := h.capture.Settings()
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", &settings,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// UpdateSettings 更新调试捕获设置
+// @Summary 更新调试捕获设置
+// @Description 运行时开关调试捕获、调整路由与采样率，仅作用于当前实例，重启后恢复为配置值
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param request body middleware.DebugCaptureSettings true "捕获设置"
+// @Success 200 {object} resp.Response[middleware.DebugCaptureSettings] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Router /api/v1/admin/debug/capture-settings [put]
+// @Security Bearer
+func (h *DebugHandler) UpdateSettings(c *gin.Context) {
+	var settings middleware.DebugCaptureSettings
+	if !bindJSON(c, &settings, h.logger) {
+		return
+	}
+
+	h.capture.UpdateSettings(settings)
+	settings = h.capture.Settings()
+	h.logger.Info("调试捕获设置已更新",
+		zap.Bool("enabled", settings.Enabled),
+		zap.Strings("routes", settings.Routes),
+		zap.Float64("sample_rate", settings.SampleRate),
+		zap.Int64("admin_id", c.GetInt64("user_id")))
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", &settings,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// domainErrorStatus 将领域错误分类映射为 HTTP 状态码，无法识别的错误返回 0
+func domainErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrConflict), errors.Is(err, domain.ErrVersionConflict),
+		errors.Is(err, domain.ErrInsufficientStock):
+		return http.StatusConflict
+	default:
+		return 0
+	}
+}
+
+// writeDomainError 按领域错误分类写出错误响应，返回是否已写出；
+// 未分类的错误返回 false，由调用方记录日志并按内部错误处理。
+// 响应消息取具体错误的描述，不包含上层包装的上下文
+func writeDomainError(c *gin.Context, err error) bool {
+	status := domainErrorStatus(err)
+	if status == 0 {
+		return false
+	}
+
+	message := err.Error()
+	var typed domain.Error
+	if errors.As(err, &typed) {
+		message = typed.Error()
+	}
+	resp.Error(c.Writer, status, resp.CodeInvalidParam, message, c.GetString("request_id"), c.GetString("trace_id"))
+	return true
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func TestWriteDomainError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		err     error
+		written bool
+		status  int
+		message string
+	}{
+		{name: "资源不存在", err: fmt.Errorf("failed to get inventory: %w", domain.NewNotFoundError("inventory not found")),
+			written: true, status: http.StatusNotFound, message: "inventory not found"},
+		{name: "具体资源不存在", err: fmt.Errorf("%w: id 7", domain.ErrSpikeEventNotFound),
+			written: true, status: http.StatusNotFound, message: "秒杀活动不存在"},
+		{name: "无权访问", err: domain.ErrForbidden, written: true, status: http.StatusForbidden, message: "无权访问该资源"},
+		{name: "参数无效", err: domain.NewInvalidArgumentError("ttl_seconds must not be negative"),
+			written: true, status: http.StatusBadRequest, message: "ttl_seconds must not be negative"},
+		{name: "资源冲突", err: domain.NewConflictError("SKU already exists"),
+			written: true, status: http.StatusConflict, message: "SKU already exists"},
+		{name: "版本冲突", err: fmt.Errorf("failed to update inventory: %w", domain.NewVersionConflictError("inventory version conflict or record not found")),
+			written: true, status: http.StatusConflict, message: "inventory version conflict or record not found"},
+		{name: "库存不足", err: domain.NewInsufficientStockError("insufficient stock to reserve"),
+			written: true, status: http.StatusConflict, message: "insufficient stock to reserve"},
+		{name: "未分类错误", err: errors.New("connection refused"), written: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			if got := writeDomainError(c, tt.err); got != tt.written {
+				t.Fatalf("writeDomainError() = %v, want %v", got, tt.written)
+			}
+			if !tt.written {
+				return
+			}
+			if w.Code != tt.status {
+				t.Errorf("writeDomainError() status = %d, want %d", w.Code, tt.status)
+			}
+			var body struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if body.Message != tt.message {
+				t.Errorf("writeDomainError() message = %q, want %q", body.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/health"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// ReadinessChecker 探测外部依赖（由 health.Checker 实现）
+type ReadinessChecker interface {
+	Check(ctx context.Context) *health.Report
+}
+
+// HealthHandler 提供存活与就绪探针
+type HealthHandler struct {
+	checker   ReadinessChecker
+	version   string
+	startedAt time.Time
+}
+
+// NewHealthHandler 创建探针处理器
+func NewHealthHandler(checker ReadinessChecker, version string) *HealthHandler {
+	return &HealthHandler{checker: checker, version: version, startedAt: time.Now()}
+}
+
+// LivenessResponse 存活探针响应
+type LivenessResponse struct {
+	Status   string `json:"status"`
+	Version  string `json:"version"`
+	UptimeMs int64  `json:"uptime_ms"`
+}
+
+// Liveness 存活探针
+// @Summary 存活探针
+// @Description 只反映进程是否存活，不探测外部依赖，避免依赖故障导致实例被反复重启
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} resp.Response[LivenessResponse] "成功"
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	data := &LivenessResponse{
+		Status:   health.StatusOK,
+		Version:  h.version,
+		UptimeMs: time.Since(h.startedAt).Milliseconds(),
+	}
+	c.Header("Cache-Control", "no-store")
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", data,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// Readiness 就绪探针
+// @Summary 就绪探针
+// @Description 主动探测 MySQL、Redis、RabbitMQ 并返回各依赖的状态与耗时；关键依赖不可用或实例正在关闭时返回 503
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} resp.Response[health.Report] "就绪（status 为 ok 或 degraded）"
+// @Failure 503 {object} resp.Response[health.Report] "未就绪"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+
+	c.Header("Cache-Control", "no-store")
+	if !report.Ready() {
+		resp.WriteJSON(c.Writer, http.StatusServiceUnavailable, resp.CodeUnavailable, "service not ready", report,
+			c.GetString("request_id"), c.GetString("trace_id"))
+		return
+	}
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", report,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
@@ -0,0 +1,79 @@
+// Package api 提供客服代操作相关的HTTP API处理器。
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// ImpersonationHandler 客服代操作HTTP处理器
+type ImpersonationHandler struct {
+	impersonationService service.ImpersonationService
+	logger               *zap.Logger
+}
+
+// NewImpersonationHandler 创建客服代操作处理器实例
+func NewImpersonationHandler(impersonationService service.ImpersonationService, logger *zap.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+		logger:               logger,
+	}
+}
+
+// Impersonate 申请代操作指定用户的令牌（管理员专用）
+// POST /api/v1/admin/users/impersonate?user_id=123
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	admin := middleware.UserFromContext(c.Request.Context())
+	if admin == nil {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam, "authentication required", reqID, traceID)
+		return
+	}
+
+	userIDStr := c.Query("user_id")
+	if userIDStr == "" {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "user_id is required", reqID, traceID)
+		return
+	}
+
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil || userID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid user_id", reqID, traceID)
+		return
+	}
+
+	var req domain.ImpersonateRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	result, err := h.impersonationService.Impersonate(admin, userID, req.Reason, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImpersonationReasonRequired):
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "reason is required", reqID, traceID)
+		case errors.Is(err, service.ErrCannotImpersonateSelf), errors.Is(err, service.ErrCannotImpersonateAdmin):
+			resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam, err.Error(), reqID, traceID)
+		case errors.Is(err, service.ErrUserNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, traceID)
+		case errors.Is(err, service.ErrUserInactive):
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "user is inactive", reqID, traceID)
+		default:
+			h.logger.Error("impersonate failed", zap.String("request_id", reqID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "impersonate failed", reqID, traceID)
+		}
+		return
+	}
+
+	resp.OK(c.Writer, result, reqID, traceID)
+}
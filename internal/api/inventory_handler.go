@@ -2,12 +2,19 @@
 package api
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
@@ -20,6 +27,7 @@ import (
 type InventoryHandler struct {
 	inventoryService service.InventoryService
 	logger           *zap.Logger
+	jobQueue         service.AdminJobSubmitter // 后台任务队列，可为空，为空时不支持异步批量调整
 }
 
 // NewInventoryHandler 创建库存处理器实例
@@ -30,176 +38,142 @@ func NewInventoryHandler(inventoryService service.InventoryService, logger *zap.
 	}
 }
 
+// SetJobQueue 设置后台任务队列，批量调整库存可通过 async=true 在后台执行
+func (h *InventoryHandler) SetJobQueue(jobQueue service.AdminJobSubmitter) {
+	h.jobQueue = jobQueue
+}
+
 // CreateInventory 创建库存记录
 // POST /api/v1/inventory
 // 需要管理员权限
-func (h *InventoryHandler) CreateInventory(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) CreateInventory(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.CreateInventoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateCreateInventoryRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层创建库存
-	inventory, err := h.inventoryService.CreateInventory(&req)
+	inventory, err := h.inventoryService.CreateInventory(c.Request.Context(), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, "")
-			return
-		}
-		if strings.Contains(err.Error(), "already exists") {
-			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "inventory already exists for this product", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("create inventory failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "create inventory failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "create inventory failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, inventory, reqID, "")
+	resp.OK(c.Writer, inventory, reqID, traceID)
 }
 
 // GetInventory 获取库存详情
 // GET /api/v1/inventory/{id}
-func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
-
-	// 从URL路径中提取库存ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, "")
-		return
-	}
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	idStr := parts[4] // /api/v1/inventory/{id}
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	// 从路径参数中提取库存ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, traceID)
 		return
 	}
 
 	// 调用服务层获取库存
-	inventory, err := h.inventoryService.GetInventory(id)
+	inventory, err := h.inventoryService.GetInventory(c.Request.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "inventory not found", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("get inventory failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get inventory failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get inventory failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, inventory, reqID, "")
+	resp.OK(c.Writer, inventory, reqID, traceID)
 }
 
 // GetInventoryByProductID 根据商品ID获取库存
 // GET /api/v1/products/{product_id}/inventory
-func (h *InventoryHandler) GetInventoryByProductID(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
-
-	// 从URL路径中提取商品ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
-		return
-	}
+func (h *InventoryHandler) GetInventoryByProductID(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	productIDStr := parts[4] // /api/v1/products/{product_id}/inventory
-	productID, err := strconv.ParseInt(productIDStr, 10, 64)
+	// 从路径参数中提取商品ID
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
 		return
 	}
 
 	// 调用服务层获取库存
-	inventory, err := h.inventoryService.GetInventoryByProductID(productID)
+	inventory, err := h.inventoryService.GetInventoryByProductID(c.Request.Context(), productID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "inventory not found", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("get inventory by product ID failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get inventory failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get inventory failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, inventory, reqID, "")
+	resp.OK(c.Writer, inventory, reqID, traceID)
 }
 
 // UpdateInventory 更新库存
 // PUT /api/v1/inventory/{id}
 // 需要管理员权限
-func (h *InventoryHandler) UpdateInventory(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
-
-	// 从URL路径中提取库存ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, "")
-		return
-	}
+func (h *InventoryHandler) UpdateInventory(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	idStr := parts[4] // /api/v1/inventory/{id}
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	// 从路径参数中提取库存ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, traceID)
 		return
 	}
 
 	// 解析请求体
 	var req domain.UpdateInventoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
+	middleware.SetAuditEntity(c, domain.AdminAuditEntityInventory, id)
+	if middleware.AuditEnabled(c) {
+		if before, err := h.inventoryService.GetInventory(c.Request.Context(), id); err == nil {
+			middleware.SetAuditBefore(c, before)
+		}
+	}
+
 	// 调用服务层更新库存
-	inventory, err := h.inventoryService.UpdateInventory(id, &req)
+	inventory, err := h.inventoryService.UpdateInventory(c.Request.Context(), id, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "inventory not found", reqID, "")
-			return
-		}
-		if strings.Contains(err.Error(), "version conflict") {
-			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "inventory has been modified by another request", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("update inventory failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "update inventory failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "update inventory failed", reqID, traceID)
 		return
 	}
+	middleware.SetAuditAfter(c, inventory)
 
-	resp.OK(w, inventory, reqID, "")
+	resp.OK(c.Writer, inventory, reqID, traceID)
 }
 
 // ListInventories 获取库存列表
-// GET /api/v1/inventory?page=1&page_size=20&product_id=1&low_stock=true&min_stock=10&max_stock=100&sort_by=stock&sort_order=asc
-func (h *InventoryHandler) ListInventories(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+// GET /api/v1/inventory?page=1&page_size=20&include_total=false&product_id=1&low_stock=true&min_stock=10&max_stock=100&sort_by=stock&sort_order=asc
+func (h *InventoryHandler) ListInventories(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析查询参数
 	req := &domain.InventoryListRequest{}
-	query := r.URL.Query()
+	query := c.Request.URL.Query()
 
 	// 分页参数
 	if pageStr := query.Get("page"); pageStr != "" {
@@ -221,6 +195,7 @@ func (h *InventoryHandler) ListInventories(w http.ResponseWriter, r *http.Reques
 	} else {
 		req.PageSize = 20
 	}
+	req.SkipTotal = skipTotal(query.Get("include_total"))
 
 	// 过滤参数
 	if productIDStr := query.Get("product_id"); productIDStr != "" {
@@ -257,269 +232,291 @@ func (h *InventoryHandler) ListInventories(w http.ResponseWriter, r *http.Reques
 	}
 
 	// 调用服务层获取库存列表
-	result, err := h.inventoryService.ListInventories(req)
+	result, err := h.inventoryService.ListInventories(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Error("list inventories failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "list inventories failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "list inventories failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, result, reqID, "")
+	resp.OK(c.Writer, result, reqID, traceID)
 }
 
 // GetLowStockAlerts 获取低库存警告
 // GET /api/v1/inventory/alerts/low-stock
 // 需要管理员权限
-func (h *InventoryHandler) GetLowStockAlerts(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) GetLowStockAlerts(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 调用服务层获取低库存警告
-	alerts, err := h.inventoryService.GetLowStockAlerts()
+	alerts, err := h.inventoryService.GetLowStockAlerts(c.Request.Context())
 	if err != nil {
 		h.logger.Error("get low stock alerts failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get low stock alerts failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get low stock alerts failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, &alerts, reqID, "")
+	resp.OK(c.Writer, &alerts, reqID, traceID)
 }
 
-// AdjustStock 调整库存
-// POST /api/v1/products/{product_id}/inventory/adjust
+// ListStockMovements 获取库存变动流水
+// GET /api/v1/admin/inventory/{id}/movements?page=1&page_size=20&include_total=false&type=adjust&reference=xxx
 // 需要管理员权限
-func (h *InventoryHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) ListStockMovements(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	// 从URL路径中提取商品ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+	// 从路径参数中提取库存ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid inventory ID", reqID, traceID)
 		return
 	}
 
-	productIDStr := parts[4] // /api/v1/products/{product_id}/inventory/adjust
-	productID, err := strconv.ParseInt(productIDStr, 10, 64)
+	req, fields := parseStockMovementListRequest(c)
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, reqID, traceID)
+		return
+	}
+
+	// 调用服务层获取变动流水
+	result, err := h.inventoryService.ListStockMovements(c.Request.Context(), id, req)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+		if writeDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("list stock movements failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "list stock movements failed", reqID, traceID)
+		return
+	}
+
+	resp.OK(c.Writer, result, reqID, traceID)
+}
+
+// parseStockMovementListRequest 解析库存变动流水查询参数，参数错误时返回各字段的错误
+func parseStockMovementListRequest(c *gin.Context) (*domain.StockMovementListRequest, []resp.FieldError) {
+	req := &domain.StockMovementListRequest{
+		Page:      1,
+		PageSize:  20,
+		Reference: c.Query("reference"),
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		req.PageSize = pageSize
+	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
+
+	var fields []resp.FieldError
+	if v := c.Query("type"); v != "" {
+		movementType, err := domain.ParseStockMovementType(v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "type", Message: err.Error()})
+		} else {
+			req.Type = &movementType
+		}
+	}
+
+	return req, fields
+}
+
+// AdjustStock 调整库存
+// POST /api/v1/products/{product_id}/inventory/adjust
+// 需要管理员权限
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	// 从路径参数中提取商品ID
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
 		return
 	}
 
 	// 解析请求体
 	var req domain.StockAdjustmentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
-	// 基本验证
-	if err := h.validateStockAdjustmentRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
-		return
+	// 审计记录调整前后的库存
+	middleware.SetAuditEntity(c, domain.AdminAuditEntityProduct, productID)
+	auditEnabled := middleware.AuditEnabled(c)
+	if auditEnabled {
+		if before, err := h.inventoryService.GetInventoryByProductID(c.Request.Context(), productID); err == nil {
+			middleware.SetAuditBefore(c, before)
+		}
 	}
 
 	// 调用服务层调整库存
-	err = h.inventoryService.AdjustStock(productID, &req)
+	err = h.inventoryService.AdjustStock(stockOperatorContext(c, domain.AdminActor), productID, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, "")
-			return
-		}
-		if strings.Contains(err.Error(), "negative stock") {
-			resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "adjustment would result in negative stock", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("adjust stock failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "adjust stock failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "adjust stock failed", reqID, traceID)
 		return
 	}
+	if auditEnabled {
+		if after, err := h.inventoryService.GetInventoryByProductID(c.Request.Context(), productID); err == nil {
+			middleware.SetAuditAfter(c, after)
+		}
+	}
 
 	result := map[string]interface{}{"adjusted": true}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // ReserveStock 预留库存
 // POST /api/v1/inventory/reserve
-func (h *InventoryHandler) ReserveStock(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) ReserveStock(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.ReserveStockRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateReserveStockRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层预留库存
-	err := h.inventoryService.ReserveStock(&req)
+	reservation, err := h.inventoryService.ReserveStock(stockOperatorContext(c, domain.UserActor), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, "")
-			return
-		}
-		if strings.Contains(err.Error(), "not available") {
-			resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "product is not available for sale", reqID, "")
+		if errors.Is(err, domain.ErrProductStopSell) {
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam, "product sale is stopped", reqID, traceID)
 			return
 		}
-		if strings.Contains(err.Error(), "insufficient stock") {
-			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "insufficient stock", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("reserve stock failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "reserve stock failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "reserve stock failed", reqID, traceID)
 		return
 	}
 
+	// 启用预留记录时返回预留ID与过期时间，调用方凭预留ID消费或释放
 	result := map[string]interface{}{"reserved": true}
-	resp.OK(w, &result, reqID, "")
+	if reservation != nil {
+		result["reservation"] = reservation
+	}
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // ReleaseStock 释放库存
 // POST /api/v1/inventory/release
-func (h *InventoryHandler) ReleaseStock(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) ReleaseStock(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.ReleaseStockRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateReleaseStockRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层释放库存
-	err := h.inventoryService.ReleaseStock(&req)
+	err := h.inventoryService.ReleaseStock(stockOperatorContext(c, domain.UserActor), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "insufficient reserved stock") {
-			resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "insufficient reserved stock", reqID, "")
+		if h.writeReservationError(c, err) {
+			return
+		}
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("release stock failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "release stock failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "release stock failed", reqID, traceID)
 		return
 	}
 
 	result := map[string]interface{}{"released": true}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // ConsumeStock 消费库存
 // POST /api/v1/inventory/consume
-func (h *InventoryHandler) ConsumeStock(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) ConsumeStock(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.ConsumeStockRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateConsumeStockRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层消费库存
-	err := h.inventoryService.ConsumeStock(&req)
+	err := h.inventoryService.ConsumeStock(stockOperatorContext(c, domain.UserActor), &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "insufficient reserved stock") {
-			resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "insufficient reserved stock", reqID, "")
+		if h.writeReservationError(c, err) {
+			return
+		}
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("consume stock failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "consume stock failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "consume stock failed", reqID, traceID)
 		return
 	}
 
 	result := map[string]interface{}{"consumed": true}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // GetInventoryStats 获取库存统计信息
 // GET /api/v1/inventory/stats
 // 需要管理员权限
-func (h *InventoryHandler) GetInventoryStats(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) GetInventoryStats(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 调用服务层获取统计信息
-	stats, err := h.inventoryService.GetInventoryStats()
+	stats, err := h.inventoryService.GetInventoryStats(c.Request.Context())
 	if err != nil {
 		h.logger.Error("get inventory stats failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get inventory stats failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get inventory stats failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, stats, reqID, "")
+	resp.OK(c.Writer, stats, reqID, traceID)
 }
 
 // CheckStockAvailability 检查库存可用性
 // GET /api/v1/products/{product_id}/inventory/check?quantity=10
-func (h *InventoryHandler) CheckStockAvailability(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *InventoryHandler) CheckStockAvailability(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	// 从URL路径中提取商品ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
-		return
-	}
-
-	productIDStr := parts[4] // /api/v1/products/{product_id}/inventory/check
-	productID, err := strconv.ParseInt(productIDStr, 10, 64)
+	// 从路径参数中提取商品ID
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
 		return
 	}
 
 	// 从查询参数获取数量
-	quantityStr := r.URL.Query().Get("quantity")
+	quantityStr := c.Query("quantity")
 	if quantityStr == "" {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "quantity is required", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "quantity is required", reqID, traceID)
 		return
 	}
 
 	quantity, err := strconv.Atoi(quantityStr)
 	if err != nil || quantity <= 0 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid quantity", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid quantity", reqID, traceID)
 		return
 	}
 
 	// 调用服务层检查库存可用性
-	available, err := h.inventoryService.CheckStockAvailability(productID, quantity)
+	available, err := h.inventoryService.CheckStockAvailability(c.Request.Context(), productID, quantity)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "inventory not found", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("check stock availability failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "check stock availability failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "check stock availability failed", reqID, traceID)
 		return
 	}
 
@@ -528,83 +525,263 @@ func (h *InventoryHandler) CheckStockAvailability(w http.ResponseWriter, r *http
 		"quantity":   quantity,
 		"available":  available,
 	}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
-// 验证函数
+// BatchCheckStockAvailability 批量检查库存可用性（购物车多商品结算前使用）
+// POST /api/v1/products/availability
+func (h *InventoryHandler) BatchCheckStockAvailability(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-func (h *InventoryHandler) validateCreateInventoryRequest(req *domain.CreateInventoryRequest) error {
-	if req.ProductID <= 0 {
-		return errors.New("product_id is required")
+	// 解析请求体
+	var req domain.BatchStockCheckRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
 	}
 
-	if req.Stock < 0 {
-		return errors.New("stock cannot be negative")
+	// 调用服务层批量检查
+	result, err := h.inventoryService.BatchCheckStockAvailability(c.Request.Context(), req.Items)
+	if err != nil {
+		h.logger.Error("batch check stock availability failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "check stock availability failed", reqID, traceID)
+		return
 	}
 
-	if req.ReorderPoint < 0 {
-		return errors.New("reorder_point cannot be negative")
-	}
+	resp.OK(c.Writer, result, reqID, traceID)
+}
 
-	if req.MaxStock <= 0 {
-		return errors.New("max_stock must be greater than 0")
-	}
+// maxBulkAdjustRows 单次批量库存调整允许的最大行数
+const maxBulkAdjustRows = 1000
 
-	if req.Stock > req.MaxStock {
-		return errors.New("stock cannot exceed max_stock")
+// BulkAdjustStock 批量调整库存
+// POST /api/v1/admin/inventory/bulk-adjust?mode=partial|atomic&dry_run=false&async=false
+// 需要管理员权限；请求体为 JSON（{"items":[{"sku","delta","reason"}]}）或 text/csv（列顺序 sku,delta,reason，表头可选）
+// mode=partial（默认）逐行生效，mode=atomic 任一行失败时全部不生效；dry_run=true 只返回逐行校验结果
+// async=true 时提交后台任务并返回 202，结果通过 GET /api/v1/admin/spike/jobs/{id} 查询
+func (h *InventoryHandler) BulkAdjustStock(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	var opts domain.BulkStockAdjustmentOptions
+	var fields []resp.FieldError
+	mode, err := domain.ParseBulkStockAdjustmentMode(c.Query("mode"))
+	if err != nil {
+		fields = append(fields, resp.FieldError{Field: "mode", Message: err.Error()})
+	}
+	opts.Mode = mode
+	if v := c.Query("dry_run"); v != "" {
+		if opts.DryRun, err = strconv.ParseBool(v); err != nil {
+			fields = append(fields, resp.FieldError{Field: "dry_run", Message: "must be true or false"})
+		}
+	}
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, reqID, traceID)
+		return
 	}
 
-	return nil
-}
+	items, err := h.parseBulkAdjustItems(c.Request)
+	if err != nil {
+		h.logger.Warn("invalid bulk adjustment body", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, traceID)
+		return
+	}
+	if len(items) == 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "items cannot be empty", reqID, traceID)
+		return
+	}
+	if len(items) > maxBulkAdjustRows {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, fmt.Sprintf("too many items, max %d", maxBulkAdjustRows), reqID, traceID)
+		return
+	}
 
-func (h *InventoryHandler) validateStockAdjustmentRequest(req *domain.StockAdjustmentRequest) error {
-	if req.Quantity == 0 {
-		return errors.New("quantity cannot be zero")
+	// 审计日志：记录操作人与每行调整结果
+	var operatorID int64
+	if user := middleware.UserFromContext(c.Request.Context()); user != nil {
+		operatorID = user.ID
 	}
 
-	if req.Reason == "" {
-		return errors.New("reason is required")
+	middleware.SetAuditEntity(c, domain.AdminAuditEntityInventory, nil)
+	if async, _ := strconv.ParseBool(c.Query("async")); async {
+		// 后台执行的结果记录在任务中，审计只记录提交的调整行
+		middleware.SetAuditAfter(c, items)
+		h.submitBulkAdjust(c, operatorID, items, opts)
+		return
 	}
 
-	if req.Type != "in" && req.Type != "out" {
-		return errors.New("type must be 'in' or 'out'")
+	report, err := h.inventoryService.BulkAdjustStock(stockOperatorContext(c, domain.AdminActor), items, opts)
+	if err != nil {
+		h.logger.Error("bulk adjust stock failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "bulk adjust stock failed", reqID, traceID)
+		return
 	}
+	h.logBulkAdjust(reqID, operatorID, report)
+	middleware.SetAuditAfter(c, report)
 
-	return nil
+	resp.OK(c.Writer, report, reqID, traceID)
 }
 
-func (h *InventoryHandler) validateReserveStockRequest(req *domain.ReserveStockRequest) error {
-	if req.ProductID <= 0 {
-		return errors.New("product_id is required")
+// bulkAdjustJobChunk 后台批量调整每次提交给库存服务的行数，每处理完一段上报一次进度
+const bulkAdjustJobChunk = 100
+
+// submitBulkAdjust 将批量调整提交为后台任务，分段执行并合并各段结果
+// atomic 模式与 dry_run 不分段：dry_run 按全部行累计校验负库存，分段会在每段重新累计
+func (h *InventoryHandler) submitBulkAdjust(c *gin.Context, operatorID int64, items []domain.BulkStockAdjustmentItem, opts domain.BulkStockAdjustmentOptions) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+	if h.jobQueue == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError, "admin job queue not enabled", reqID, traceID)
+		return
+	}
+
+	job, err := h.jobQueue.Submit(domain.AdminJobKindBulkAdjust, fmt.Sprintf("%d rows", len(items)), domain.AdminActor(operatorID),
+		func(ctx context.Context, progress service.AdminJobProgress) (any, error) {
+			ctx = domain.WithStockOperator(ctx, domain.AdminActor(operatorID))
+			report := &domain.BulkStockAdjustmentReport{Mode: opts.Mode, DryRun: opts.DryRun, Total: len(items)}
+			chunkSize := bulkAdjustJobChunk
+			if opts.Mode == domain.BulkStockAdjustmentAtomic || opts.DryRun {
+				chunkSize = len(items)
+			}
+			for start := 0; start < len(items); start += chunkSize {
+				if err := ctx.Err(); err != nil {
+					return report, err
+				}
+				chunk, err := h.inventoryService.BulkAdjustStock(ctx, items[start:min(start+chunkSize, len(items))], opts)
+				if err != nil {
+					return report, err
+				}
+				for _, row := range chunk.Rows {
+					row.Row += start
+				}
+				report.Succeeded += chunk.Succeeded
+				report.Failed += chunk.Failed
+				report.RolledBack = report.RolledBack || chunk.RolledBack
+				report.Rows = append(report.Rows, chunk.Rows...)
+				progress(len(report.Rows), len(items))
+			}
+			h.logBulkAdjust(reqID, operatorID, report)
+			return report, nil
+		})
+	if errors.Is(err, domain.ErrAdminJobQueueFull) {
+		c.Header("Retry-After", "5")
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError, err.Error(), reqID, traceID)
+		return
 	}
-
-	if req.Quantity <= 0 {
-		return errors.New("quantity must be greater than 0")
+	if err != nil {
+		h.logger.Error("submit bulk adjustment job failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "submit bulk adjustment job failed", reqID, traceID)
+		return
 	}
 
-	return nil
+	resp.WriteJSON(c.Writer, http.StatusAccepted, resp.CodeOK, "accepted", job, reqID, traceID)
 }
 
-func (h *InventoryHandler) validateReleaseStockRequest(req *domain.ReleaseStockRequest) error {
-	if req.ProductID <= 0 {
-		return errors.New("product_id is required")
+// logBulkAdjust 记录批量调整的审计日志
+func (h *InventoryHandler) logBulkAdjust(reqID string, operatorID int64, report *domain.BulkStockAdjustmentReport) {
+	h.logger.Info("bulk stock adjustment",
+		zap.Bool("audit", true),
+		zap.String("request_id", reqID),
+		zap.Int64("operator_id", operatorID),
+		zap.String("mode", string(report.Mode)),
+		zap.Bool("dry_run", report.DryRun),
+		zap.Bool("rolled_back", report.RolledBack),
+		zap.Int("total", report.Total),
+		zap.Int("succeeded", report.Succeeded),
+		zap.Int("failed", report.Failed),
+		zap.Any("rows", report.Rows))
+}
+
+// ExportInventory 导出全部库存的当前快照
+// GET /api/v1/admin/inventory/export?format=csv|json
+// 需要管理员权限；CSV 首列为 sku，盘点后可按 sku,delta,reason 整理为批量调整文件
+func (h *InventoryHandler) ExportInventory(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	format := c.DefaultQuery("format", domain.InventoryExportFormatCSV)
+	if format != domain.InventoryExportFormatCSV && format != domain.InventoryExportFormatJSON {
+		resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "format", Message: "must be json or csv"}}, reqID, traceID)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == domain.InventoryExportFormatJSON {
+		contentType = "application/json; charset=utf-8"
 	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="inventory_`+time.Now().Format("20060102150405")+`.`+format+`"`)
+	c.Status(http.StatusOK)
 
-	if req.Quantity <= 0 {
-		return errors.New("quantity must be greater than 0")
+	count, err := h.inventoryService.ExportInventory(c.Request.Context(), format, c.Writer)
+	if err != nil {
+		// 响应头已写出，只能记录错误，客户端收到的文件不完整
+		h.logger.Error("export inventory failed", zap.String("request_id", reqID), zap.Int("rows", count), zap.Error(err))
+		return
 	}
+	h.logger.Info("inventory exported",
+		zap.Bool("audit", true),
+		zap.String("request_id", reqID),
+		zap.String("operator", domain.AdminActor(c.GetInt64("user_id"))),
+		zap.String("format", format),
+		zap.Int("rows", count))
+}
 
-	return nil
+// stockOperatorContext 在请求 context 中记录库存变动的操作者，写入库存变动流水
+func stockOperatorContext(c *gin.Context, actor func(int64) string) context.Context {
+	return domain.WithStockOperator(c.Request.Context(), actor(c.GetInt64("user_id")))
 }
 
-func (h *InventoryHandler) validateConsumeStockRequest(req *domain.ConsumeStockRequest) error {
-	if req.ProductID <= 0 {
-		return errors.New("product_id is required")
+// parseBulkAdjustItems 按 Content-Type 解析 CSV 或 JSON 格式的批量调整请求体
+func (h *InventoryHandler) parseBulkAdjustItems(r *http.Request) ([]domain.BulkStockAdjustmentItem, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "text/csv" {
+		var req domain.BulkStockAdjustmentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, errors.New("invalid request body")
+		}
+		return req.Items, nil
 	}
 
-	if req.Quantity <= 0 {
-		return errors.New("quantity must be greater than 0")
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var items []domain.BulkStockAdjustmentItem
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		// 首行为表头时跳过
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "sku") {
+			continue
+		}
+		if len(items) >= maxBulkAdjustRows {
+			return nil, fmt.Errorf("too many items, max %d", maxBulkAdjustRows)
+		}
+
+		delta, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid delta on csv line %d", line)
+		}
+		items = append(items, domain.BulkStockAdjustmentItem{
+			SKU:    strings.TrimSpace(record[0]),
+			Delta:  delta,
+			Reason: strings.TrimSpace(record[2]),
+		})
 	}
+	return items, nil
+}
 
-	return nil
+// writeReservationError 将按预留ID消费或释放的错误映射为响应，非预留错误返回 false
+func (h *InventoryHandler) writeReservationError(c *gin.Context, err error) bool {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+	switch {
+	case errors.Is(err, domain.ErrReservationNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "reservation not found", reqID, traceID)
+	case errors.Is(err, domain.ErrReservationNotActive):
+		resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam, "reservation already consumed, released or expired", reqID, traceID)
+	default:
+		return false
+	}
+	return true
 }
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// MetaHandler 提供客户端构建表单与筛选项所需的元数据
+type MetaHandler struct{}
+
+// NewMetaHandler 创建元数据处理器
+func NewMetaHandler() *MetaHandler {
+	return &MetaHandler{}
+}
+
+// EnumsResponse 枚举列表响应
+type EnumsResponse struct {
+	Enums map[string][]domain.EnumValue `json:"enums"` // 键为枚举名称，如 spike_order_status
+}
+
+// ListEnums 获取枚举取值
+// @Summary 获取枚举取值
+// @Description 返回订单状态、活动状态、下单渠道等枚举的全部取值与说明，列表接口的状态筛选只接受这些取值
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} resp.Response[EnumsResponse] "成功"
+// @Router /api/v1/meta/enums [get]
+func (h *MetaHandler) ListEnums(c *gin.Context) {
+	// 枚举只随版本发布变化，允许客户端与中间层短时缓存
+	c.Header("Cache-Control", "public, max-age=300")
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", &EnumsResponse{Enums: domain.Enums()},
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
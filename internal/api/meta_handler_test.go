@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func TestMetaHandler_ListEnums(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/meta/enums", NewMetaHandler().ListEnums)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/meta/enums", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ListEnums() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Data EnumsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	orderStatuses := body.Data.Enums["spike_order_status"]
+	if len(orderStatuses) != 4 || orderStatuses[0].Value != string(domain.SpikeOrderStatusPending) || orderStatuses[0].Label == "" {
+		t.Errorf("ListEnums() spike_order_status = %+v, want 4 labelled values starting with pending", orderStatuses)
+	}
+	for _, name := range []string{"spike_event_status", "spike_order_channel", "spike_settlement_status", "product_status"} {
+		if len(body.Data.Enums[name]) == 0 {
+			t.Errorf("ListEnums() missing enum %s", name)
+		}
+	}
+}
+
+func TestSpikeOrderStatus_JSON(t *testing.T) {
+	if _, err := json.Marshal(&domain.SpikeOrder{Status: "shipped"}); err == nil {
+		t.Error("Marshal() with undefined status error = nil, want error")
+	}
+
+	var order domain.SpikeOrder
+	if err := json.Unmarshal([]byte(`{"status":"paid"}`), &order); err != nil || order.Status != domain.SpikeOrderStatusPaid {
+		t.Errorf("Unmarshal(paid) = %q, %v", order.Status, err)
+	}
+	if err := json.Unmarshal([]byte(`{"status":"shipped"}`), &order); err == nil {
+		t.Error("Unmarshal(shipped) error = nil, want error")
+	}
+}
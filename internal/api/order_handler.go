@@ -0,0 +1,449 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// OrderHandler 购物车与普通订单相关的HTTP处理器
+type OrderHandler struct {
+	orderService service.OrderService
+	logger       *zap.Logger
+}
+
+// NewOrderHandler 创建普通订单处理器实例
+func NewOrderHandler(orderService service.OrderService, logger *zap.Logger) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		logger:       logger,
+	}
+}
+
+// PayOrderResult 支付普通订单的结果
+type PayOrderResult struct {
+	Order  *domain.Order   `json:"order"`
+	Intent *payment.Intent `json:"intent"`
+}
+
+// GetCart 获取购物车
+// @Summary 获取购物车
+// @Description 返回购物车商品及当前售价；已下架的商品 available 为 false，不计入合计
+// @Tags 订单
+// @Produce json
+// @Success 200 {object} resp.Response[domain.Cart] "成功"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Router /api/v1/cart [get]
+// @Security Bearer
+func (h *OrderHandler) GetCart(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+
+	cart, err := h.orderService.GetCart(c.Request.Context(), user.ID)
+	h.writeCart(c, cart, err, "获取购物车失败")
+}
+
+// AddCartItem 加入购物车
+// @Summary 加入购物车
+// @Description 商品已在购物车中时累加数量；已下架或停售的商品不能加入
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param request body domain.AddCartItemRequest true "加入购物车请求"
+// @Success 200 {object} resp.Response[domain.Cart] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误或商品已下架"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "商品不存在"
+// @Failure 409 {object} resp.Response[any] "购物车商品种类已达上限或商品已停售"
+// @Router /api/v1/cart/items [post]
+// @Security Bearer
+func (h *OrderHandler) AddCartItem(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+
+	var req domain.AddCartItemRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	cart, err := h.orderService.AddCartItem(c.Request.Context(), user.ID, &req)
+	h.writeCart(c, cart, err, "加入购物车失败")
+}
+
+// UpdateCartItem 修改购物车商品数量
+// @Summary 修改购物车商品数量
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param product_id path int true "商品ID"
+// @Param request body domain.UpdateCartItemRequest true "修改数量请求"
+// @Success 200 {object} resp.Response[domain.Cart] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "购物车中没有该商品"
+// @Router /api/v1/cart/items/{product_id} [put]
+// @Security Bearer
+func (h *OrderHandler) UpdateCartItem(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+	productID, ok := h.parseID(c, "product_id", "无效的商品ID")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateCartItemRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	cart, err := h.orderService.UpdateCartItem(c.Request.Context(), user.ID, productID, &req)
+	h.writeCart(c, cart, err, "修改购物车失败")
+}
+
+// RemoveCartItem 从购物车移除商品
+// @Summary 从购物车移除商品
+// @Tags 订单
+// @Produce json
+// @Param product_id path int true "商品ID"
+// @Success 200 {object} resp.Response[domain.Cart] "成功"
+// @Failure 400 {object} resp.Response[any] "无效的商品ID"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "购物车中没有该商品"
+// @Router /api/v1/cart/items/{product_id} [delete]
+// @Security Bearer
+func (h *OrderHandler) RemoveCartItem(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+	productID, ok := h.parseID(c, "product_id", "无效的商品ID")
+	if !ok {
+		return
+	}
+
+	cart, err := h.orderService.RemoveCartItem(c.Request.Context(), user.ID, productID)
+	h.writeCart(c, cart, err, "移除购物车商品失败")
+}
+
+// Checkout 结算购物车
+// @Summary 结算购物车
+// @Description 按当前售价生成待支付订单并预留库存，product_ids 为空时结算购物车中的全部商品；
+// @Description 任一商品已下架、停售或库存不足时整单失败。订单须在 ORDER_PAYMENT_TIMEOUT 内支付，否则过期并释放库存
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param request body domain.CheckoutRequest false "结算请求"
+// @Success 201 {object} resp.Response[domain.Order] "成功"
+// @Failure 400 {object} resp.Response[any] "购物车为空或商品已下架"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "所选商品不在购物车中"
+// @Failure 409 {object} resp.Response[any] "库存不足或商品已停售"
+// @Router /api/v1/orders/checkout [post]
+// @Security Bearer
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+
+	var req domain.CheckoutRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	order, err := h.orderService.Checkout(c.Request.Context(), user.ID, &req)
+	if err != nil {
+		h.writeOrderError(c, err, "结算失败", logger.UserID(user.ID))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusCreated, resp.CodeOK, "success", order,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// ListOrders 查询我的订单
+// @Summary 查询我的订单
+// @Description 分页查询当前用户的普通订单，包括购物车结算与秒杀支付后生成的订单，不含明细
+// @Tags 订单
+// @Produce json
+// @Param status query string false "订单状态" Enums(pending, paid, cancelled, expired)
+// @Param source query string false "订单来源" Enums(cart, spike)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页大小" default(20)
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1，以 has_more 判断是否还有下一页" default(true)
+// @Success 200 {object} resp.Response[domain.OrderListResponse] "成功"
+// @Failure 400 {object} resp.Response[resp.FieldErrors] "筛选参数取值无效"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Router /api/v1/orders [get]
+// @Security Bearer
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+
+	req, fields := parseOrderListRequest(c)
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, c.GetString("request_id"), c.GetString("trace_id"))
+		return
+	}
+	req.UserID = &user.ID
+
+	h.listOrders(c, req)
+}
+
+// ListAdminOrders 跨用户查询普通订单（管理员接口）
+// @Summary 查询普通订单（管理员）
+// @Tags 管理员
+// @Produce json
+// @Param user_id query int false "用户ID"
+// @Param status query string false "订单状态" Enums(pending, paid, cancelled, expired)
+// @Param source query string false "订单来源" Enums(cart, spike)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页大小" default(20)
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1，以 has_more 判断是否还有下一页" default(true)
+// @Success 200 {object} resp.Response[domain.OrderListResponse] "成功"
+// @Failure 400 {object} resp.Response[resp.FieldErrors] "筛选参数取值无效"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Router /api/v1/admin/orders [get]
+// @Security Bearer
+func (h *OrderHandler) ListAdminOrders(c *gin.Context) {
+	req, fields := parseOrderListRequest(c)
+	if v := c.Query("user_id"); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || userID <= 0 {
+			fields = append(fields, resp.FieldError{Field: "user_id", Message: "必须为正整数"})
+		} else {
+			req.UserID = &userID
+		}
+	}
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, c.GetString("request_id"), c.GetString("trace_id"))
+		return
+	}
+
+	h.listOrders(c, req)
+}
+
+// GetOrder 获取订单详情
+// @Summary 获取订单详情
+// @Description 返回订单及明细；管理员可查看任意订单
+// @Tags 订单
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} resp.Response[domain.Order] "成功"
+// @Failure 400 {object} resp.Response[any] "无效的订单ID"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 403 {object} resp.Response[any] "无权访问该订单"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Router /api/v1/orders/{id} [get]
+// @Security Bearer
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+	orderID, ok := h.parseID(c, "id", "无效的订单ID")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.GetOrder(c.Request.Context(), orderID, user.ID, user.IsAdmin())
+	if err != nil {
+		h.writeOrderError(c, err, "获取订单失败", zap.Int64("order_id", orderID))
+		return
+	}
+
+	resp.OK(c.Writer, order, c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// PayOrder 支付订单
+// @Summary 支付订单
+// @Description 凭客户端在支付渠道取得的支付凭证一步完成扣款，扣款成功后订单变为已支付并消费预留库存
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param id path int true "订单ID"
+// @Param request body domain.PayOrderRequest true "支付请求"
+// @Success 200 {object} resp.Response[PayOrderResult] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误或不支持的支付方式"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 402 {object} resp.Response[payment.Intent] "支付被拒绝"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Failure 409 {object} resp.Response[any] "订单当前状态不允许支付"
+// @Router /api/v1/orders/{id}/pay [post]
+// @Security Bearer
+func (h *OrderHandler) PayOrder(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+	orderID, ok := h.parseID(c, "id", "无效的订单ID")
+	if !ok {
+		return
+	}
+
+	var req domain.PayOrderRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+	order, intent, err := h.orderService.PayOrder(c.Request.Context(), orderID, user.ID, user.IsAdmin(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOrderPaymentDeclined):
+			h.logger.Info("订单支付被拒绝", zap.Int64("order_id", orderID), logger.UserID(user.ID))
+			resp.WriteJSON(c.Writer, http.StatusPaymentRequired, resp.CodeInvalidParam, err.Error(), intent, reqID, traceID)
+		case errors.Is(err, domain.ErrPaymentMethodUnsupported), errors.Is(err, payment.ErrInvalidToken):
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, traceID)
+		default:
+			h.writeOrderError(c, err, "支付失败", zap.Int64("order_id", orderID))
+		}
+		return
+	}
+
+	resp.OK(c.Writer, &PayOrderResult{Order: order, Intent: intent}, reqID, traceID)
+}
+
+// CancelOrder 取消订单
+// @Summary 取消订单
+// @Description 取消待支付订单并释放预留库存
+// @Tags 订单
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} resp.Response[domain.Order] "成功"
+// @Failure 400 {object} resp.Response[any] "无效的订单ID"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Failure 409 {object} resp.Response[any] "订单当前状态不允许取消"
+// @Router /api/v1/orders/{id}/cancel [post]
+// @Security Bearer
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	user := h.requireUser(c)
+	if user == nil {
+		return
+	}
+	orderID, ok := h.parseID(c, "id", "无效的订单ID")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(c.Request.Context(), orderID, user.ID, user.IsAdmin())
+	if err != nil {
+		h.writeOrderError(c, err, "取消订单失败", zap.Int64("order_id", orderID))
+		return
+	}
+
+	resp.OK(c.Writer, order, c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// listOrders 执行订单列表查询并写出响应
+func (h *OrderHandler) listOrders(c *gin.Context, req *domain.OrderListRequest) {
+	orders, err := h.orderService.ListOrders(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("查询订单失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取订单列表失败", c.GetString("request_id"), c.GetString("trace_id"))
+		return
+	}
+
+	resp.OK(c.Writer, orders, c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// parseOrderListRequest 解析订单列表的分页与过滤参数，返回取值无效的字段
+func parseOrderListRequest(c *gin.Context) (*domain.OrderListRequest, []resp.FieldError) {
+	req := &domain.OrderListRequest{
+		Page:     1,
+		PageSize: 20,
+	}
+	var fields []resp.FieldError
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		req.PageSize = pageSize
+	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
+
+	if v := c.Query("status"); v != "" {
+		status, err := domain.ParseOrderStatus(v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "status", Message: err.Error()})
+		} else {
+			req.Status = &status
+		}
+	}
+	if v := c.Query("source"); v != "" {
+		source, err := domain.ParseOrderSource(v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "source", Message: err.Error()})
+		} else {
+			req.Source = &source
+		}
+	}
+
+	return req, fields
+}
+
+// requireUser 返回当前登录用户，未登录时写出 401 并返回 nil
+func (h *OrderHandler) requireUser(c *gin.Context) *domain.User {
+	user := middleware.UserFromContext(c.Request.Context())
+	if user == nil {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", c.GetString("request_id"), c.GetString("trace_id"))
+	}
+	return user
+}
+
+// parseID 解析路径中的正整数ID，无效时写出 400
+func (h *OrderHandler) parseID(c *gin.Context, param, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil || id <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			message, c.GetString("request_id"), c.GetString("trace_id"))
+		return 0, false
+	}
+	return id, true
+}
+
+// writeCart 写出购物车或购物车操作的错误
+func (h *OrderHandler) writeCart(c *gin.Context, cart *domain.Cart, err error, failure string) {
+	if err != nil {
+		h.writeOrderError(c, err, failure)
+		return
+	}
+	resp.OK(c.Writer, cart, c.GetString("request_id"), c.GetString("trace_id"))
+}
+
+// writeOrderError 写出购物车与订单操作的错误：领域错误按分类映射，停售返回 409，其余记录日志后返回 500
+func (h *OrderHandler) writeOrderError(c *gin.Context, err error, failure string, fields ...zap.Field) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+	if errors.Is(err, domain.ErrProductStopSell) {
+		resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam, "商品已停售", reqID, traceID)
+		return
+	}
+	if writeDomainError(c, err) {
+		return
+	}
+
+	h.logger.Error(failure, append(fields, zap.String("request_id", reqID), zap.Error(err))...)
+	resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, failure, reqID, traceID)
+}
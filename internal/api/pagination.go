@@ -0,0 +1,31 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// skipTotal 解析列表查询参数 include_total，仅在显式传入 false 时跳过总数统计
+func skipTotal(includeTotal string) bool {
+	include, err := strconv.ParseBool(includeTotal)
+	return err == nil && !include
+}
+
+// includeDeleted 解析管理端列表查询参数 include_deleted，仅在显式传入 true 时包含已软删除的记录
+func includeDeleted(v string) bool {
+	include, err := strconv.ParseBool(v)
+	return err == nil && include
+}
+
+// parseCursor 解析列表查询参数 cursor（上一页响应的 next_cursor），为空时返回 nil；
+// 键集游标只适用于按 created_at 排序的列表，keyset 为 false 时返回 domain.ErrInvalidCursor
+func parseCursor(v string, keyset bool) (*domain.ListCursor, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if !keyset {
+		return nil, domain.ErrInvalidCursor
+	}
+	return domain.DecodeListCursor(v)
+}
@@ -2,12 +2,11 @@
 package api
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
@@ -19,6 +18,9 @@ import (
 // ProductHandler 商品相关的HTTP处理器
 type ProductHandler struct {
 	productService service.ProductService
+	detailService  service.ProductDetailService  // 商品详情聚合服务，可为空
+	stopSell       *service.StopSellService      // 商品停售开关，可为空
+	variantService service.ProductVariantService // 商品规格服务，可为空
 	logger         *zap.Logger
 }
 
@@ -33,171 +35,225 @@ func NewProductHandler(productService service.ProductService, logger *zap.Logger
 // CreateProduct 创建商品
 // POST /api/v1/products
 // 需要管理员权限
-func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.CreateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateCreateProductRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层创建商品
 	product, err := h.productService.CreateProduct(&req)
 	if err != nil {
-		if strings.Contains(err.Error(), "SKU already exists") {
-			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "SKU already exists", reqID, "")
+		if writeDomainError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidProductStatus) {
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, traceID)
 			return
 		}
 
 		h.logger.Error("create product failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "create product failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "create product failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, product, reqID, "")
+	resp.OK(c.Writer, product, reqID, traceID)
 }
 
 // GetProduct 获取商品详情
 // GET /api/v1/products/{id}
-func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
-
-	// 从URL路径中提取商品ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
-		return
-	}
+func (h *ProductHandler) GetProduct(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	idStr := parts[4] // /api/v1/products/{id}
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	// 从路径参数中提取商品ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
 		return
 	}
 
 	// 调用服务层获取商品
 	product, err := h.productService.GetProduct(id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("get product failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get product failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get product failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, product, reqID, "")
+	resp.OK(c.Writer, product, reqID, traceID)
 }
 
-// UpdateProduct 更新商品
-// PUT /api/v1/products/{id}
+// SetDetailService 设置商品详情聚合服务，未设置时聚合接口返回 503
+func (h *ProductHandler) SetDetailService(detailService service.ProductDetailService) {
+	h.detailService = detailService
+}
+
+// GetProductFull 获取商品详情聚合数据（商品 + 库存可用性 + 当前或即将开始的秒杀活动）
+// GET /api/v1/products/{id}/full
+func (h *ProductHandler) GetProductFull(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	if h.detailService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError, "product detail service not enabled", reqID, traceID)
+		return
+	}
+
+	// 从路径参数中提取商品ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
+		return
+	}
+
+	detail, err := h.detailService.GetProductDetail(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, traceID)
+			return
+		}
+
+		h.logger.Error("get product detail failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get product detail failed", reqID, traceID)
+		return
+	}
+
+	resp.OK(c.Writer, detail, reqID, traceID)
+}
+
+// SetStopSellService 设置商品停售开关服务，未设置时停售接口返回 503
+func (h *ProductHandler) SetStopSellService(stopSell *service.StopSellService) {
+	h.stopSell = stopSell
+}
+
+// SetStopSell 停售或恢复销售商品，停售立即阻止预留库存与参与秒杀，并暂停商品未结束的秒杀活动
+// PUT /api/v1/admin/products/{id}/stop-sell
 // 需要管理员权限
-func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
-
-	// 从URL路径中提取商品ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+func (h *ProductHandler) SetStopSell(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	if h.stopSell == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError, "stop-sell not enabled", reqID, traceID)
+		return
+	}
+
+	// 从路径参数中提取商品ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
+		return
+	}
+
+	var req domain.StopSellRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	var operatorID int64
+	if user := middleware.UserFromContext(c.Request.Context()); user != nil {
+		operatorID = user.ID
+	}
+
+	result, err := h.stopSell.SetStopSell(c.Request.Context(), id, &req, operatorID)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, traceID)
+			return
+		}
+
+		h.logger.Error("set stop-sell failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "set stop-sell failed", reqID, traceID)
 		return
 	}
 
-	idStr := parts[4] // /api/v1/products/{id}
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	resp.OK(c.Writer, result, reqID, traceID)
+}
+
+// UpdateProduct 更新商品
+// PUT /api/v1/products/{id}
+// 需要管理员权限
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	// 从路径参数中提取商品ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
 		return
 	}
 
 	// 解析请求体
 	var req domain.UpdateProductRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层更新商品
 	product, err := h.productService.UpdateProduct(id, &req)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, "")
+		if writeDomainError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidProductStatus) {
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, traceID)
+			return
+		}
+		if errors.Is(err, domain.ErrProductStatusTransition) {
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam, err.Error(), reqID, traceID)
 			return
 		}
 
 		h.logger.Error("update product failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "update product failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "update product failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, product, reqID, "")
+	resp.OK(c.Writer, product, reqID, traceID)
 }
 
 // DeleteProduct 删除商品
 // DELETE /api/v1/products/{id}
 // 需要管理员权限
-func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
-
-	// 从URL路径中提取商品ID
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 5 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
-		return
-	}
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	idStr := parts[4] // /api/v1/products/{id}
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	// 从路径参数中提取商品ID
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
 		return
 	}
 
 	// 调用服务层删除商品
-	err = h.productService.DeleteProduct(id)
+	err = h.productService.DeleteProduct(c.Request.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "product not found", reqID, "")
-			return
-		}
-		if strings.Contains(err.Error(), "existing stock") {
-			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "cannot delete product with existing stock", reqID, "")
+		if writeDomainError(c, err) {
 			return
 		}
 
 		h.logger.Error("delete product failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "delete product failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "delete product failed", reqID, traceID)
 		return
 	}
 
 	result := map[string]interface{}{"deleted": true}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // ListProducts 获取商品列表
-// GET /api/v1/products?page=1&page_size=20&status=active&category_id=1&brand=Apple&keyword=iPhone&sort_by=price&sort_order=asc
-func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+// GET /api/v1/products?page=1&page_size=20&include_total=false&status=active&category_id=1&brand=Apple&keyword=iPhone&sort_by=price&sort_order=asc
+func (h *ProductHandler) ListProducts(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析查询参数
 	req := &domain.ProductListRequest{}
-	query := r.URL.Query()
+	query := c.Request.URL.Query()
 
 	// 分页参数
 	if pageStr := query.Get("page"); pageStr != "" {
@@ -219,10 +275,15 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	} else {
 		req.PageSize = 20
 	}
+	req.SkipTotal = skipTotal(query.Get("include_total"))
 
 	// 过滤参数
 	if status := query.Get("status"); status != "" {
-		productStatus := domain.ProductStatus(status)
+		productStatus, err := domain.ParseProductStatus(status)
+		if err != nil {
+			resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "status", Message: err.Error()}}, reqID, traceID)
+			return
+		}
 		req.Status = &productStatus
 	}
 
@@ -253,23 +314,23 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	result, err := h.productService.ListProducts(req)
 	if err != nil {
 		h.logger.Error("list products failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "list products failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "list products failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, result, reqID, "")
+	resp.OK(c.Writer, result, reqID, traceID)
 }
 
 // SearchProducts 搜索商品
 // GET /api/v1/products/search?keyword=iPhone&page=1&page_size=20
-func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析查询参数
-	query := r.URL.Query()
+	query := c.Request.URL.Query()
 	keyword := query.Get("keyword")
 	if keyword == "" {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "keyword is required", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "keyword is required", reqID, traceID)
 		return
 	}
 
@@ -292,91 +353,50 @@ func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request)
 	result, err := h.productService.SearchProducts(keyword, page, pageSize)
 	if err != nil {
 		h.logger.Error("search products failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "search products failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "search products failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, result, reqID, "")
+	resp.OK(c.Writer, result, reqID, traceID)
 }
 
 // GetProductsWithInventory 获取带库存信息的商品列表
 // POST /api/v1/products/with-inventory
-func (h *ProductHandler) GetProductsWithInventory(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *ProductHandler) GetProductsWithInventory(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体（商品ID列表）
 	var req struct {
-		ProductIDs []int64 `json:"product_ids"`
+		ProductIDs []int64 `json:"product_ids" binding:"required,min=1,max=100"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	if len(req.ProductIDs) == 0 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "product_ids is required", reqID, "")
-		return
-	}
-
-	if len(req.ProductIDs) > 100 {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "too many product IDs (max 100)", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层获取带库存信息的商品
-	result, err := h.productService.GetProductsWithInventory(req.ProductIDs)
+	result, err := h.productService.GetProductsWithInventory(c.Request.Context(), req.ProductIDs)
 	if err != nil {
 		h.logger.Error("get products with inventory failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get products with inventory failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get products with inventory failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // GetProductStats 获取商品统计信息
 // GET /api/v1/products/stats
 // 需要管理员权限
-func (h *ProductHandler) GetProductStats(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *ProductHandler) GetProductStats(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 调用服务层获取统计信息
-	stats, err := h.productService.GetProductStats()
+	stats, err := h.productService.GetProductStats(c.Request.Context())
 	if err != nil {
 		h.logger.Error("get product stats failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get product stats failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get product stats failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, stats, reqID, "")
-}
-
-// validateCreateProductRequest 验证创建商品请求
-func (h *ProductHandler) validateCreateProductRequest(req *domain.CreateProductRequest) error {
-	if req.Name == "" {
-		return errors.New("name is required")
-	}
-
-	if len(req.Name) > 255 {
-		return errors.New("name too long (max 255 characters)")
-	}
-
-	if req.Price <= 0 {
-		return errors.New("price must be greater than 0")
-	}
-
-	if req.SKU == "" {
-		return errors.New("SKU is required")
-	}
-
-	if len(req.SKU) > 100 {
-		return errors.New("SKU too long (max 100 characters)")
-	}
-
-	if req.Weight != nil && *req.Weight < 0 {
-		return errors.New("weight cannot be negative")
-	}
-
-	return nil
+	resp.OK(c.Writer, stats, reqID, traceID)
 }
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/service"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+func TestProductHandler_GetProduct(t *testing.T) {
+	productRepo := &mocks.ProductRepositoryMock{
+		GetByIDFunc: func(id int64) (*domain.Product, error) {
+			if id != 42 {
+				return nil, nil
+			}
+			return &domain.Product{ID: 42, Name: "iPhone"}, nil
+		},
+	}
+	handler := NewProductHandler(service.NewProductService(productRepo, nil), zap.NewNop())
+
+	router := testutil.NewGinEngine(0)
+	router.GET("/products/:id", handler.GetProduct)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "存在的商品", path: "/products/42", status: http.StatusOK},
+		{name: "不存在的商品", path: "/products/7", status: http.StatusNotFound},
+		{name: "非法商品ID", path: "/products/abc", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testutil.DoJSON(t, router, http.MethodGet, tt.path, nil)
+			if w.Code != tt.status {
+				t.Fatalf("GetProduct(%s) status = %d, want %d", tt.path, w.Code, tt.status)
+			}
+
+			body := testutil.DecodeResponse[domain.Product](t, w)
+			if body.RequestID != testutil.TestRequestID || body.TraceID != testutil.TestTraceID {
+				t.Errorf("request_id/trace_id = %q/%q, want %s/%s", body.RequestID, body.TraceID, testutil.TestRequestID, testutil.TestTraceID)
+			}
+			if tt.status == http.StatusOK && (body.Data == nil || body.Data.ID != 42) {
+				t.Errorf("GetProduct() data = %+v, want product 42", body.Data)
+			}
+		})
+	}
+}
+
+// fakeVariantService 只实现列出与创建规格，其余方法返回 nil
+type fakeVariantService struct {
+	service.ProductVariantService
+	variants []*domain.ProductVariant
+}
+
+func (f *fakeVariantService) ListVariants(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
+	if productID != 1 {
+		return nil, service.ErrProductNotFound
+	}
+	return f.variants, nil
+}
+
+func (f *fakeVariantService) CreateVariant(ctx context.Context, productID int64, req *domain.CreateProductVariantRequest) (*domain.ProductVariant, error) {
+	variant := &domain.ProductVariant{ID: int64(len(f.variants) + 1), ProductID: productID, SKU: req.SKU, Name: req.Name, Price: req.Price, Active: true}
+	f.variants = append(f.variants, variant)
+	return variant, nil
+}
+
+func TestProductHandler_Variants(t *testing.T) {
+	handler := NewProductHandler(service.NewProductService(&mocks.ProductRepositoryMock{}, nil), zap.NewNop())
+	router := testutil.NewGinEngine(0)
+	router.GET("/products/:id/variants", handler.ListVariants)
+	router.POST("/products/:id/variants", handler.CreateVariant)
+
+	// 未设置规格服务时返回 503
+	if w := testutil.DoJSON(t, router, http.MethodGet, "/products/1/variants", nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("ListVariants() without service status = %d, want 503", w.Code)
+	}
+
+	handler.SetVariantService(&fakeVariantService{})
+	w := testutil.DoJSON(t, router, http.MethodPost, "/products/1/variants", map[string]any{"sku": "TS-RED", "name": "红色", "price": 99})
+	if w.Code != http.StatusOK {
+		t.Fatalf("CreateVariant() status = %d, body %s", w.Code, w.Body.String())
+	}
+	if w := testutil.DoJSON(t, router, http.MethodPost, "/products/1/variants", map[string]any{"name": "缺少SKU"}); w.Code != http.StatusBadRequest {
+		t.Errorf("CreateVariant() invalid body status = %d, want 400", w.Code)
+	}
+
+	w = testutil.DoJSON(t, router, http.MethodGet, "/products/1/variants", nil)
+	body := testutil.DecodeResponse[[]*domain.ProductVariant](t, w)
+	if w.Code != http.StatusOK || body.Data == nil || len(*body.Data) != 1 || (*body.Data)[0].SKU != "TS-RED" {
+		t.Errorf("ListVariants() = %d %s, want the created variant", w.Code, w.Body.String())
+	}
+	if w := testutil.DoJSON(t, router, http.MethodGet, "/products/7/variants", nil); w.Code != http.StatusNotFound {
+		t.Errorf("ListVariants() unknown product status = %d, want 404", w.Code)
+	}
+}
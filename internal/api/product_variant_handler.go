@@ -0,0 +1,155 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetVariantService 设置商品规格服务，未设置时规格接口返回 503
+func (h *ProductHandler) SetVariantService(variantService service.ProductVariantService) {
+	h.variantService = variantService
+}
+
+// ListVariants 获取商品的全部规格
+// GET /api/v1/products/{id}/variants
+func (h *ProductHandler) ListVariants(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	productID, ok := h.variantProductID(c)
+	if !ok {
+		return
+	}
+
+	variants, err := h.variantService.ListVariants(c.Request.Context(), productID)
+	if err != nil {
+		h.writeVariantError(c, "list product variants failed", err)
+		return
+	}
+
+	resp.OK(c.Writer, &variants, reqID, traceID)
+}
+
+// CreateVariant 为商品创建规格
+// POST /api/v1/admin/products/{id}/variants
+// 需要管理员权限
+func (h *ProductHandler) CreateVariant(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	productID, ok := h.variantProductID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateProductVariantRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	variant, err := h.variantService.CreateVariant(c.Request.Context(), productID, &req)
+	if err != nil {
+		h.writeVariantError(c, "create product variant failed", err)
+		return
+	}
+
+	resp.OK(c.Writer, variant, reqID, traceID)
+}
+
+// UpdateVariant 更新商品规格
+// PUT /api/v1/admin/products/{id}/variants/{variant_id}
+// 需要管理员权限
+func (h *ProductHandler) UpdateVariant(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	productID, variantID, ok := h.variantIDs(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateProductVariantRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	variant, err := h.variantService.UpdateVariant(c.Request.Context(), productID, variantID, &req)
+	if err != nil {
+		h.writeVariantError(c, "update product variant failed", err)
+		return
+	}
+
+	resp.OK(c.Writer, variant, reqID, traceID)
+}
+
+// AdjustVariantStock 调整商品规格库存
+// POST /api/v1/admin/products/{id}/variants/{variant_id}/stock/adjust
+// 需要管理员权限
+func (h *ProductHandler) AdjustVariantStock(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	productID, variantID, ok := h.variantIDs(c)
+	if !ok {
+		return
+	}
+
+	var req domain.AdjustVariantStockRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	variant, err := h.variantService.AdjustStock(c.Request.Context(), productID, variantID, &req)
+	if err != nil {
+		h.writeVariantError(c, "adjust variant stock failed", err)
+		return
+	}
+
+	resp.OK(c.Writer, variant, reqID, traceID)
+}
+
+// variantProductID 检查规格服务已启用并解析路径中的商品ID，失败时已写出响应
+func (h *ProductHandler) variantProductID(c *gin.Context) (int64, bool) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	if h.variantService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError, "product variant service not enabled", reqID, traceID)
+		return 0, false
+	}
+
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid product ID", reqID, traceID)
+		return 0, false
+	}
+	return productID, true
+}
+
+// variantIDs 解析路径中的商品ID与规格ID，失败时已写出响应
+func (h *ProductHandler) variantIDs(c *gin.Context) (productID, variantID int64, ok bool) {
+	if productID, ok = h.variantProductID(c); !ok {
+		return 0, 0, false
+	}
+
+	variantID, err := strconv.ParseInt(c.Param("variant_id"), 10, 64)
+	if err != nil || variantID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid variant ID",
+			c.GetString("request_id"), c.GetString("trace_id"))
+		return 0, 0, false
+	}
+	return productID, variantID, true
+}
+
+// writeVariantError 按业务错误分类写出响应，其余错误记录日志并返回 500
+func (h *ProductHandler) writeVariantError(c *gin.Context, message string, err error) {
+	if writeDomainError(c, err) {
+		return
+	}
+
+	reqID := c.GetString("request_id")
+	h.logger.Error(message, zap.String("request_id", reqID), zap.Error(err))
+	resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, message, reqID, c.GetString("trace_id"))
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetAbandonedCheckoutService 设置弃单召回服务，未设置时弃单接口返回 503
+func (h *SpikeHandler) SetAbandonedCheckoutService(abandonedService service.AbandonedCheckoutService) {
+	h.abandonedService = abandonedService
+}
+
+// ListAbandonedCheckouts 查询弃单
+// @Summary 查询弃单
+// @Description 分页查询过期未支付的订单及召回转化情况，summary 为相同过滤条件下的汇总
+// @Tags 管理员
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页大小(1-100)"
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1" default(true)
+// @Param spike_event_id query int false "活动ID"
+// @Param campaign_id query string false "召回活动ID"
+// @Param recovered query bool false "是否已转化"
+// @Param from query string false "过期时间起(RFC3339)"
+// @Param to query string false "过期时间止(RFC3339)"
+// @Success 200 {object} resp.Response{data=domain.AbandonedCheckoutListResponse}
+// @Failure 400 {object} resp.Response
+// @Router /api/v1/admin/spike/abandoned-checkouts [get]
+// @Security Bearer
+func (h *SpikeHandler) ListAbandonedCheckouts(c *gin.Context) {
+	if h.abandonedService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"弃单召回服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	req, msg := parseAbandonedCheckoutListRequest(c)
+	if msg != "" {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			msg, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	result, err := h.abandonedService.ListAbandonedCheckouts(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("查询弃单失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"查询弃单失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// StartRecoveryCampaign 为弃单发起召回
+// @Summary 发起弃单召回
+// @Description 记录召回活动与优惠券并通知用户；用户下单时携带 recovery_campaign_id 即可计入转化
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param id path int true "弃单ID"
+// @Param request body domain.RecoveryCampaignRequest true "召回请求"
+// @Success 200 {object} resp.Response{data=domain.AbandonedCheckout}
+// @Failure 400 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Router /api/v1/admin/spike/abandoned-checkouts/{id}/recovery [post]
+// @Security Bearer
+func (h *SpikeHandler) StartRecoveryCampaign(c *gin.Context) {
+	if h.abandonedService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"弃单召回服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	checkoutID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || checkoutID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的弃单ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.RecoveryCampaignRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	checkout, err := h.abandonedService.StartRecovery(c.Request.Context(), checkoutID, &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrAbandonedCheckoutNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"弃单记录不存在", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		h.logger.Error("发起弃单召回失败", zap.Int64("abandoned_checkout_id", checkoutID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"发起召回失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", checkout,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// parseAbandonedCheckoutListRequest 解析弃单查询参数，参数错误时返回提示信息
+func parseAbandonedCheckoutListRequest(c *gin.Context) (*domain.AbandonedCheckoutListRequest, string) {
+	req := &domain.AbandonedCheckoutListRequest{
+		Page:     1,
+		PageSize: 20,
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		req.PageSize = pageSize
+	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
+
+	if v := c.Query("spike_event_id"); v != "" {
+		eventID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || eventID <= 0 {
+			return nil, "无效的活动ID"
+		}
+		req.SpikeEventID = &eventID
+	}
+	if v := c.Query("campaign_id"); v != "" {
+		req.CampaignID = &v
+	}
+	if v := c.Query("recovered"); v != "" {
+		recovered, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, "recovered 必须为布尔值"
+		}
+		req.Recovered = &recovered
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, "from 必须为 RFC3339 时间"
+		}
+		req.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, "to 必须为 RFC3339 时间"
+		}
+		req.To = &to
+	}
+
+	return req, ""
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetAdminJobQueue 设置后台任务队列，未设置时预热全部活动与任务查询接口返回 503
+func (h *SpikeHandler) SetAdminJobQueue(jobQueue *service.AdminJobQueue) {
+	h.jobQueue = jobQueue
+}
+
+// WarmupAllStock 预热全部活动库存
+// @Summary 预热全部活动库存
+// @Description 提交后台任务，预热全部进行中与预告期活动的库存；返回 202 与任务信息，通过任务查询接口获取进度与结果
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param request body domain.WarmupAllRequest false "预热选项"
+// @Success 202 {object} resp.Response{data=domain.AdminJob}
+// @Failure 400 {object} resp.Response
+// @Failure 503 {object} resp.Response "后台任务队列未启用或已满"
+// @Router /api/v1/admin/spike/events/warmup-all [post]
+// @Security Bearer
+func (h *SpikeHandler) WarmupAllStock(c *gin.Context) {
+	if !h.requireAdminJobQueue(c) {
+		return
+	}
+
+	var req domain.WarmupAllRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req, h.logger) {
+			return
+		}
+	}
+
+	job, err := h.jobQueue.Submit(domain.AdminJobKindWarmupAll, "", domain.AdminActor(h.getCurrentUserID(c)),
+		func(ctx context.Context, progress service.AdminJobProgress) (any, error) {
+			return h.spikeService.WarmupAllStock(ctx, req.Force, progress)
+		})
+	if err != nil {
+		h.writeAdminJobSubmitError(c, err)
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusAccepted, resp.CodeOK, "success", job,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// ListAdminJobs 查询后台任务
+// @Summary 查询后台任务
+// @Description 返回本实例排队中、执行中与最近结束的后台任务，最新提交的在前
+// @Tags 管理员
+// @Produce json
+// @Success 200 {object} resp.Response{data=domain.AdminJobListResponse}
+// @Failure 503 {object} resp.Response
+// @Router /api/v1/admin/spike/jobs [get]
+// @Security Bearer
+func (h *SpikeHandler) ListAdminJobs(c *gin.Context) {
+	if !h.requireAdminJobQueue(c) {
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success",
+		&domain.AdminJobListResponse{Items: h.jobQueue.List()},
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetAdminJob 查询后台任务状态
+// @Summary 查询后台任务状态
+// @Description 返回任务状态（queued/running/done/failed）、进度与结果
+// @Tags 管理员
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} resp.Response{data=domain.AdminJob}
+// @Failure 400 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Failure 503 {object} resp.Response
+// @Router /api/v1/admin/spike/jobs/{id} [get]
+// @Security Bearer
+func (h *SpikeHandler) GetAdminJob(c *gin.Context) {
+	if !h.requireAdminJobQueue(c) {
+		return
+	}
+
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || jobID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的任务ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	job, err := h.jobQueue.Get(jobID)
+	if err != nil {
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", job,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// requireAdminJobQueue 后台任务队列未启用时写入 503 响应
+func (h *SpikeHandler) requireAdminJobQueue(c *gin.Context) bool {
+	if h.jobQueue == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"后台任务队列未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
+
+// writeAdminJobSubmitError 提交后台任务失败时写入响应，队列已满时返回 503 提示稍后重试
+func (h *SpikeHandler) writeAdminJobSubmitError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrAdminJobQueueFull) {
+		c.Header("Retry-After", "5")
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	h.logger.Error("提交后台任务失败", zap.Error(err))
+	resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+		"提交后台任务失败", h.getRequestID(c), h.getTraceID(c))
+}
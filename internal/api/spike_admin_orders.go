@@ -0,0 +1,166 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// ListAdminSpikeOrders 跨用户查询秒杀订单（管理员接口）
+// @Summary 查询秒杀订单（管理员）
+// @Description 跨用户分页查询秒杀订单，支持按活动、用户、状态与创建时间过滤；format=csv 时按相同过滤条件导出全部匹配订单（忽略分页参数，最多 100000 行，截断时响应头 X-Export-Truncated 为 true）
+// @Tags 管理员
+// @Produce json
+// @Produce text/csv
+// @Param spike_event_id query int false "秒杀活动ID"
+// @Param user_id query int false "用户ID"
+// @Param status query string false "订单状态" Enums(pending, paid, cancelled, expired)
+// @Param from query string false "创建时间起（含），RFC3339"
+// @Param to query string false "创建时间止（不含），RFC3339"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页大小" default(20)
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1，以 has_more 判断是否还有下一页" default(true)
+// @Param include_deleted query bool false "是否包含已软删除的订单" default(false)
+// @Param cursor query string false "分页游标，取上一页的 next_cursor；按 created_at 排序时可用，设置后忽略 page 且不统计总数；导出时忽略"
+// @Param sort_by query string false "排序字段" Enums(created_at, total_amount)
+// @Param sort_order query string false "排序方向" Enums(asc, desc) default(desc)
+// @Param format query string false "返回格式" Enums(json, csv) default(json)
+// @Success 200 {object} resp.Response[domain.SpikeOrderListResponse] "成功"
+// @Failure 400 {object} resp.Response[resp.FieldErrors] "筛选参数取值无效"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 500 {object} resp.Response[any] "服务器内部错误"
+// @Router /api/v1/admin/spike/orders [get]
+// @Security Bearer
+func (h *SpikeHandler) ListAdminSpikeOrders(c *gin.Context) {
+	req, fields := parseAdminSpikeOrderListRequest(c)
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+	case "csv":
+		h.exportAdminSpikeOrders(c, req)
+		return
+	default:
+		resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "format", Message: "取值必须为 json 或 csv"}},
+			h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	orders, err := h.spikeService.ListSpikeOrders(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("查询秒杀订单失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取订单列表失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", orders,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// exportAdminSpikeOrders 以 CSV 附件流式写出匹配的订单；
+// 响应头在写出第一行前已发送，导出中途失败只能记录日志并中断响应
+func (h *SpikeHandler) exportAdminSpikeOrders(c *gin.Context, req *domain.SpikeOrderListRequest) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="spike_orders_`+time.Now().Format("20060102150405")+`.csv"`)
+	// 是否截断在写完后才知道，以 trailer 告知；不支持 trailer 的客户端按行数自行判断
+	c.Header("Trailer", "X-Export-Truncated")
+	c.Status(http.StatusOK)
+
+	truncated, err := h.spikeService.ExportSpikeOrders(c.Request.Context(), req, c.Writer)
+	if err != nil {
+		h.logger.Error("导出秒杀订单失败", zap.Error(err))
+		return
+	}
+	c.Writer.Header().Set("X-Export-Truncated", strconv.FormatBool(truncated))
+	h.logger.Info("spike orders exported",
+		zap.Bool("audit", true),
+		zap.String("request_id", h.getRequestID(c)),
+		zap.String("operator", domain.AdminActor(h.getCurrentUserID(c))),
+		zap.Bool("truncated", truncated))
+}
+
+// parseAdminSpikeOrderListRequest 解析管理员订单查询的过滤与分页参数，非法取值以字段错误返回
+func parseAdminSpikeOrderListRequest(c *gin.Context) (*domain.SpikeOrderListRequest, []resp.FieldError) {
+	req := &domain.SpikeOrderListRequest{
+		Page:     1,
+		PageSize: 20,
+	}
+	var fields []resp.FieldError
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		req.PageSize = pageSize
+	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
+	req.IncludeDeleted = includeDeleted(c.Query("include_deleted"))
+
+	if v := c.Query("spike_event_id"); v != "" {
+		eventID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || eventID <= 0 {
+			fields = append(fields, resp.FieldError{Field: "spike_event_id", Message: "必须为正整数"})
+		} else {
+			req.SpikeEventID = &eventID
+		}
+	}
+	if v := c.Query("user_id"); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || userID <= 0 {
+			fields = append(fields, resp.FieldError{Field: "user_id", Message: "必须为正整数"})
+		} else {
+			req.UserID = &userID
+		}
+	}
+	if v := c.Query("status"); v != "" {
+		status, err := domain.ParseSpikeOrderStatus(v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "status", Message: err.Error()})
+		} else {
+			req.Status = &status
+		}
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "from", Message: "必须为 RFC3339 时间"})
+		} else {
+			req.From = &from
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "to", Message: "必须为 RFC3339 时间"})
+		} else {
+			req.To = &to
+		}
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		fields = append(fields, resp.FieldError{Field: "to", Message: "必须晚于 from"})
+	}
+
+	if sortBy := c.Query("sort_by"); sortBy != "" {
+		req.SortBy = &sortBy
+	}
+	if sortOrder := c.Query("sort_order"); sortOrder != "" {
+		req.SortOrder = &sortOrder
+	}
+	if cursor, err := parseCursor(c.Query("cursor"), req.KeysetOrdered()); err != nil {
+		fields = append(fields, resp.FieldError{Field: "cursor", Message: err.Error()})
+	} else {
+		req.After = cursor
+	}
+
+	return req, fields
+}
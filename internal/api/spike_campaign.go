@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetCampaignService 设置秒杀专场服务，未设置时专场接口返回 503
+func (h *SpikeHandler) SetCampaignService(campaignService service.SpikeCampaignService) {
+	h.campaignService = campaignService
+}
+
+// CreateSpikeCampaign 创建秒杀专场
+// @Summary 创建秒杀专场
+// @Description 创建将多个秒杀活动归为一组的专场；max_purchases_per_user 大于 0 时限制单用户在专场内所有活动中的购买次数
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateSpikeCampaignRequest true "创建专场请求"
+// @Success 200 {object} resp.Response{data=domain.SpikeCampaign}
+// @Failure 400 {object} resp.Response
+// @Router /api/v1/admin/spike/campaigns [post]
+// @Security Bearer
+func (h *SpikeHandler) CreateSpikeCampaign(c *gin.Context) {
+	if !h.requireCampaignService(c) {
+		return
+	}
+
+	var req domain.CreateSpikeCampaignRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	campaign, err := h.campaignService.CreateCampaign(c.Request.Context(), &req)
+	if err != nil {
+		h.writeCampaignError(c, err, "创建专场失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", campaign,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetSpikeCampaign 获取秒杀专场
+// @Summary 获取秒杀专场
+// @Tags 管理员
+// @Produce json
+// @Param id path int true "专场ID"
+// @Success 200 {object} resp.Response{data=domain.SpikeCampaign}
+// @Failure 404 {object} resp.Response
+// @Router /api/v1/admin/spike/campaigns/{id} [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeCampaign(c *gin.Context) {
+	if !h.requireCampaignService(c) {
+		return
+	}
+
+	campaignID, ok := h.parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.campaignService.GetCampaign(c.Request.Context(), campaignID)
+	if err != nil {
+		h.writeCampaignError(c, err, "获取专场失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", campaign,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// AssignSpikeCampaignEvents 将活动加入专场
+// @Summary 将活动加入专场
+// @Description 活动已属于其他专场时改为属于本专场；任一活动不存在时不做修改
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param id path int true "专场ID"
+// @Param request body domain.AssignSpikeCampaignEventsRequest true "活动ID列表"
+// @Success 200 {object} resp.Response{data=domain.AssignSpikeCampaignEventsResult}
+// @Failure 400 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Router /api/v1/admin/spike/campaigns/{id}/events [put]
+// @Security Bearer
+func (h *SpikeHandler) AssignSpikeCampaignEvents(c *gin.Context) {
+	if !h.requireCampaignService(c) {
+		return
+	}
+
+	campaignID, ok := h.parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.AssignSpikeCampaignEventsRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	assigned, err := h.campaignService.AssignEvents(c.Request.Context(), campaignID, &req)
+	if err != nil {
+		h.writeCampaignError(c, err, "加入专场失败")
+		return
+	}
+
+	result := &domain.AssignSpikeCampaignEventsResult{SpikeCampaignID: campaignID, Assigned: assigned}
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetSpikeCampaignReport 获取秒杀专场报表
+// @Summary 获取秒杀专场报表
+// @Description 汇总专场内各活动的待支付与已支付订单，unique_buyers 为专场去重后的下单用户数
+// @Tags 管理员
+// @Produce json
+// @Param id path int true "专场ID"
+// @Success 200 {object} resp.Response{data=domain.SpikeCampaignReport}
+// @Failure 404 {object} resp.Response
+// @Router /api/v1/admin/spike/campaigns/{id}/report [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeCampaignReport(c *gin.Context) {
+	if !h.requireCampaignService(c) {
+		return
+	}
+
+	campaignID, ok := h.parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.campaignService.GetCampaignReport(c.Request.Context(), campaignID)
+	if err != nil {
+		h.writeCampaignError(c, err, "获取专场报表失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", report,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// requireCampaignService 专场服务未启用时返回 503
+func (h *SpikeHandler) requireCampaignService(c *gin.Context) bool {
+	if h.campaignService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"秒杀专场服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
+
+// parseCampaignID 解析路径中的专场ID，无效时写入 400 响应
+func (h *SpikeHandler) parseCampaignID(c *gin.Context) (int64, bool) {
+	campaignID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || campaignID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的专场ID", h.getRequestID(c), h.getTraceID(c))
+		return 0, false
+	}
+	return campaignID, true
+}
+
+// writeCampaignError 将专场服务错误映射为响应
+func (h *SpikeHandler) writeCampaignError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrSpikeCampaignNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"秒杀专场不存在", h.getRequestID(c), h.getTraceID(c))
+	case errors.Is(err, domain.ErrSpikeEventNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+	case errors.Is(err, domain.ErrSpikeCampaignInvalidPeriod):
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			msg, h.getRequestID(c), h.getTraceID(c))
+	}
+}
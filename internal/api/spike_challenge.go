@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// HeaderChallengeToken 参与秒杀时携带防刷挑战令牌的请求头
+const HeaderChallengeToken = "X-Challenge-Token"
+
+// IssueSpikeChallenge 领取秒杀防刷挑战
+// @Summary 领取防刷挑战
+// @Description 开启防刷挑战的活动参与前需领取工作量证明挑战：寻找 nonce 使 SHA-256(challenge_id + nonce) 的前 difficulty 个二进制位为 0，
+// @Description 再以 "<challenge_id>.<nonce>" 作为 challenge_token（或请求头 X-Challenge-Token）参与秒杀。挑战与用户、活动绑定，只能使用一次
+// @Tags 秒杀
+// @Produce json
+// @Param spike_event_id query int true "秒杀活动ID"
+// @Success 200 {object} resp.Response[domain.SpikeChallenge] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误或活动未开启防刷挑战"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动已结束"
+// @Failure 429 {object} resp.Response[any] "领取过于频繁"
+// @Security BearerAuth
+// @Router /api/v1/spike/captcha [get]
+func (h *SpikeHandler) IssueSpikeChallenge(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Query("spike_event_id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	challenge, err := h.spikeService.IssueChallenge(c.Request.Context(), eventID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSpikeEventNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrChallengeDisabled):
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrChallengeNotIssuable):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrChallengeRateLimited):
+			resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		default:
+			h.logger.Error("签发防刷挑战失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"系统繁忙，请稍后重试", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", challenge,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetDashboardService 设置秒杀总览服务，未设置时总览接口返回 503
+func (h *SpikeHandler) SetDashboardService(dashboardService service.SpikeDashboardService) {
+	h.dashboardService = dashboardService
+}
+
+// GetSpikeDashboard 获取跨活动的秒杀总览
+// @Summary 获取秒杀总览
+// @Description 汇总全部活动的活动数、订单状态分布、支付转化率、营收与热销活动；结果短时缓存，generated_at 为统计时间
+// @Tags 管理员
+// @Produce json
+// @Success 200 {object} resp.Response{data=domain.SpikeDashboard}
+// @Failure 503 {object} resp.Response
+// @Router /api/v1/admin/spike/dashboard [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeDashboard(c *gin.Context) {
+	if h.dashboardService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"秒杀总览服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(c.Request.Context())
+	if err != nil {
+		h.logger.Error("获取秒杀总览失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取秒杀总览失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", dashboard,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// maxEventImportBodyBytes 活动导入请求体上限
+const maxEventImportBodyBytes = 2 << 20
+
+// SetEventAdminService 设置活动批量导入导出服务，未设置时导入导出接口返回 503
+func (h *SpikeHandler) SetEventAdminService(eventAdminService service.SpikeEventAdminService) {
+	h.eventAdminService = eventAdminService
+}
+
+// ImportSpikeEvents 批量导入秒杀活动（管理员接口）
+// @Summary 批量导入秒杀活动
+// @Description 请求体为 JSON 数组或带表头的 CSV，字段与导出格式相同，时间为 RFC3339；格式由 format 参数指定，
+// @Description 未指定时 Content-Type 为 text/csv 按 CSV 解析，否则按 JSON 解析。单次最多 500 个活动，
+// @Description 逐行校验后任一行不通过则整批不创建并返回所有行的错误；dry_run=true 时只校验不创建
+// @Tags 管理员
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param format query string false "导入格式" Enums(json, csv)
+// @Param dry_run query bool false "是否只校验不创建" default(false)
+// @Success 200 {object} resp.Response[domain.SpikeEventImportResult] "校验结果，存在错误时 created 为 0"
+// @Failure 400 {object} resp.Response[any] "文件无法解析或超过行数上限"
+// @Failure 413 {object} resp.Response[any] "请求体过大"
+// @Failure 503 {object} resp.Response[any] "导入未启用"
+// @Router /api/v1/admin/spike/events/import [post]
+// @Security Bearer
+func (h *SpikeHandler) ImportSpikeEvents(c *gin.Context) {
+	if !h.requireEventAdminService(c) {
+		return
+	}
+
+	var fields []resp.FieldError
+	format := c.Query("format")
+	switch format {
+	case domain.SpikeEventFileFormatJSON, domain.SpikeEventFileFormatCSV:
+	case "":
+		format = domain.SpikeEventFileFormatJSON
+		if mediaType, _, _ := mime.ParseMediaType(c.ContentType()); mediaType == "text/csv" {
+			format = domain.SpikeEventFileFormatCSV
+		}
+	default:
+		fields = append(fields, resp.FieldError{Field: "format", Message: "取值必须为 json 或 csv"})
+	}
+	var dryRun bool
+	if raw := c.Query("dry_run"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "dry_run", Message: "必须为 true 或 false"})
+		}
+		dryRun = v
+	}
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxEventImportBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			resp.Error(c.Writer, http.StatusRequestEntityTooLarge, resp.CodeInvalidParam,
+				"导入文件过大", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"请求体无效", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	result, err := h.eventAdminService.ImportEvents(c.Request.Context(), bytes.NewReader(body), format, dryRun)
+	if err != nil {
+		if writeDomainError(c, err) {
+			return
+		}
+		h.logger.Error("导入秒杀活动失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"导入秒杀活动失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	middleware.SetAuditEntity(c, domain.AdminAuditEntitySpikeEvent, nil)
+	middleware.SetAuditAfter(c, result)
+	if result.Created > 0 {
+		h.logger.Info("spike events imported",
+			zap.Bool("audit", true),
+			zap.String("request_id", h.getRequestID(c)),
+			zap.String("operator", domain.AdminActor(h.getCurrentUserID(c))),
+			zap.String("format", format),
+			zap.Int64s("event_ids", result.EventIDs))
+	}
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// ExportSpikeEvents 批量导出秒杀活动（管理员接口）
+// @Summary 批量导出秒杀活动
+// @Description 按过滤条件导出活动为附件，格式与导入相同，修改后可直接重新导入；
+// @Description 最多 10000 个活动，截断时响应 trailer X-Export-Truncated 为 true
+// @Tags 管理员
+// @Produce json
+// @Produce text/csv
+// @Param product_id query int false "商品ID"
+// @Param status query string false "活动状态" Enums(pending, active, paused, ended, cancelled)
+// @Param format query string false "导出格式" Enums(json, csv) default(json)
+// @Param include_deleted query bool false "是否包含已软删除的活动" default(false)
+// @Success 200 {file} file "活动文件"
+// @Failure 400 {object} resp.Response[resp.FieldErrors] "筛选参数取值无效"
+// @Failure 503 {object} resp.Response[any] "导出未启用"
+// @Router /api/v1/admin/spike/events/export [get]
+// @Security Bearer
+func (h *SpikeHandler) ExportSpikeEvents(c *gin.Context) {
+	if !h.requireEventAdminService(c) {
+		return
+	}
+
+	req := &domain.SpikeEventListRequest{IncludeDeleted: includeDeleted(c.Query("include_deleted"))}
+	var fields []resp.FieldError
+	format := c.DefaultQuery("format", domain.SpikeEventFileFormatJSON)
+	if format != domain.SpikeEventFileFormatJSON && format != domain.SpikeEventFileFormatCSV {
+		fields = append(fields, resp.FieldError{Field: "format", Message: "取值必须为 json 或 csv"})
+	}
+	if v := c.Query("product_id"); v != "" {
+		productID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || productID <= 0 {
+			fields = append(fields, resp.FieldError{Field: "product_id", Message: "必须为正整数"})
+		} else {
+			req.ProductID = &productID
+		}
+	}
+	if v := c.Query("status"); v != "" {
+		status, err := domain.ParseSpikeEventStatus(v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "status", Message: err.Error()})
+		} else {
+			req.Status = &status
+		}
+	}
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == domain.SpikeEventFileFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="spike_events_`+time.Now().Format("20060102150405")+`.`+format+`"`)
+	c.Header("Trailer", "X-Export-Truncated")
+	c.Status(http.StatusOK)
+
+	truncated, err := h.eventAdminService.ExportEvents(c.Request.Context(), req, format, c.Writer)
+	if err != nil {
+		h.logger.Error("导出秒杀活动失败", zap.Error(err))
+		return
+	}
+	c.Writer.Header().Set("X-Export-Truncated", strconv.FormatBool(truncated))
+	h.logger.Info("spike events exported",
+		zap.Bool("audit", true),
+		zap.String("request_id", h.getRequestID(c)),
+		zap.String("operator", domain.AdminActor(h.getCurrentUserID(c))),
+		zap.String("format", format),
+		zap.Bool("truncated", truncated))
+}
+
+// requireEventAdminService 检查活动批量导入导出服务是否启用，未启用时写出 503
+func (h *SpikeHandler) requireEventAdminService(c *gin.Context) bool {
+	if h.eventAdminService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"活动批量导入导出未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
@@ -3,14 +3,21 @@ package api
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/idgen"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/payment"
 	"github.com/MorseWayne/spike_shop/internal/resp"
 	"github.com/MorseWayne/spike_shop/internal/service"
 )
@@ -20,17 +27,65 @@ type SpikeServiceInterface interface {
 	ParticipateSpike(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error)
 	GetSpikeEventDetail(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error)
 	GetUserSpikeOrders(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error)
+	GetUserSpikeOrdersWithEvents(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderWithEventListResponse, error)
 	GetSpikeOrderDetail(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error)
+	GetSpikeOrderDetailByNo(ctx context.Context, orderNo string, userID int64) (*domain.SpikeOrderWithDetails, error)
 	CancelSpikeOrder(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error
+	ExtendSpikeOrder(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error)
+	GetSpikeOrderTimeline(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error)
+	LookupSpikeOrder(ctx context.Context, req *domain.SpikeOrderLookupRequest) (*domain.SpikeOrderLookupResponse, error)
+	ListSpikeOrders(ctx context.Context, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error)
+	ExportSpikeOrders(ctx context.Context, req *domain.SpikeOrderListRequest, w io.Writer) (bool, error)
 	GetActiveEvents(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventListResponse, error)
-	WarmupStock(ctx context.Context, eventID int64) error
+	WarmupStock(ctx context.Context, eventID int64, force bool) error
+	WarmupAllStock(ctx context.Context, force bool, progress service.AdminJobProgress) (*domain.WarmupAllResult, error)
 	GetSpikeStats(ctx context.Context, eventID int64) (*service.SpikeStats, error)
+	PlanCapacity(ctx context.Context, eventID, perUserLimit int64) (*service.CapacityPlan, error)
+	IssueParticipationToken(ctx context.Context, eventID, userID int64) (*domain.ParticipationToken, error)
+	IssueChallenge(ctx context.Context, eventID, userID int64) (*domain.SpikeChallenge, error)
+	GetQueueStatus(ctx context.Context, token string, userID int64) (*domain.SpikeQueueStatus, error)
 }
 
 // SpikeHandler 秒杀API处理器
 type SpikeHandler struct {
-	spikeService SpikeServiceInterface
-	logger       *zap.Logger
+	spikeService  SpikeServiceInterface
+	statusSources *StatusSources             // 系统状态快照数据来源，可为空
+	reportService service.SpikeReportService // 活动报表服务，可为空
+	// 弃单召回服务，可为空
+	abandonedService service.AbandonedCheckoutService
+	// 活动补货服务，可为空
+	restockService service.SpikeRestockService
+	// 活动状态管理服务（暂停与恢复），可为空
+	lifecycleService service.SpikeEventLifecycleService
+	// 草稿活动排期与审批服务，可为空
+	publishingService service.SpikeEventPublishingService
+	// 秒杀专场服务，可为空
+	campaignService service.SpikeCampaignService
+	// 每日配额管理服务，可为空
+	quotaService service.SpikeQuotaService
+	// 库存长轮询，可为空
+	stockWatcher *service.StockWatcher
+	// 匿名只读活动服务，可为空
+	publicCatalog service.PublicSpikeCatalog
+	// 活动分享链接服务，可为空
+	shareService service.SpikeShareService
+	// 后台任务队列，可为空
+	jobQueue *service.AdminJobQueue
+	// 订单支付服务，可为空
+	paymentService service.SpikePaymentService
+	// 沙箱支付渠道，仅使用沙箱渠道时设置，用于模拟用户完成支付
+	paymentSandbox *payment.Sandbox
+	// 活动上线检查，可为空
+	preflight SpikePreflightRunner
+	// 活动结算服务，可为空
+	settlementService service.SpikeSettlementService
+	// 库存对账器，可为空
+	stockReconciler StockReconcileRunner
+	// 活动批量导入导出服务，可为空
+	eventAdminService service.SpikeEventAdminService
+	// 秒杀总览服务，可为空
+	dashboardService service.SpikeDashboardService
+	logger           *zap.Logger
 }
 
 // NewSpikeHandler 创建秒杀API处理器
@@ -45,26 +100,47 @@ func NewSpikeHandler(spikeService SpikeServiceInterface, logger *zap.Logger) *Sp
 	}
 }
 
+// HeaderClientChannel 客户端声明下单渠道（app/web/api）的请求头
+const HeaderClientChannel = "X-Client-Channel"
+
+// participationInvalid 参与请求参数错误的响应数据，在参与失败的原因码与重试提示之外携带字段级错误
+type participationInvalid struct {
+	*domain.SpikeParticipationResponse
+	Errors []resp.FieldError `json:"errors,omitempty"`
+}
+
+// writeInvalidParticipation 写出参与请求参数错误，与其他参与失败一样携带原因码 invalid_request 与重试提示
+func writeInvalidParticipation(c *gin.Context, fields []resp.FieldError) {
+	message := "请求参数格式错误"
+	if len(fields) > 0 {
+		message = "请求参数校验失败"
+	}
+	resp.WriteJSON(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, message, &participationInvalid{
+		SpikeParticipationResponse: domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInvalidRequest, message),
+		Errors:                     fields,
+	}, c.GetString("request_id"), c.GetString("trace_id"))
+}
+
 // ParticipateSpike 参与秒杀
 // @Summary 参与秒杀
-// @Description 用户参与秒杀活动
+// @Description 用户参与秒杀活动。参与失败时 data.success=false，data.code 为失败原因码，
+// @Description data.retryable 表示能否原样重试，data.retry_after_ms 为建议的最短等待毫秒数：
+// @Description rate_limited、stock_recovering、internal_error 可重试；not_started 在 retry_after_ms（即 seconds_to_start）后可重试；
+// @Description sold_out、insufficient_stock、already_participated、event_unavailable、campaign_quota_exceeded、daily_quota_exceeded、purchase_limit_exceeded、stop_sell、token_required、invalid_request 不可重试
 // @Tags 秒杀
 // @Accept json
 // @Produce json
 // @Param request body domain.SpikeParticipationRequest true "秒杀参与请求"
 // @Success 200 {object} resp.Response[domain.SpikeParticipationResponse] "成功"
-// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 400 {object} resp.Response[domain.SpikeParticipationResponse] "请求参数错误（不可重试），data.errors 为字段级错误"
 // @Failure 401 {object} resp.Response[any] "未授权"
-// @Failure 429 {object} resp.Response[any] "请求过于频繁"
-// @Failure 500 {object} resp.Response[any] "服务器内部错误"
+// @Failure 429 {object} resp.Response[domain.SpikeParticipationResponse] "请求过于频繁（可重试，等待 retry_after_ms）"
+// @Failure 500 {object} resp.Response[domain.SpikeParticipationResponse] "服务器内部错误（可重试）"
 // @Router /api/v1/spike/participate [post]
 // @Security Bearer
 func (h *SpikeHandler) ParticipateSpike(c *gin.Context) {
 	var req domain.SpikeParticipationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("参数绑定失败", zap.Error(err))
-		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
-			"请求参数格式错误", h.getRequestID(c), h.getTraceID(c))
+	if !bindJSONWith(c, &req, h.logger, false, writeInvalidParticipation) {
 		return
 	}
 
@@ -76,19 +152,43 @@ func (h *SpikeHandler) ParticipateSpike(c *gin.Context) {
 		return
 	}
 
+	// 用户等级来自访问令牌，决定限流配额、提前参与窗口和消息优先级
+	req.UserTier = domain.UserTier(c.GetString("user_tier")).OrDefault()
+
+	// 参与令牌可放在请求体或请求头中
+	if req.ParticipationToken == "" {
+		req.ParticipationToken = c.GetHeader(HeaderParticipationToken)
+	}
+	if req.ChallengeToken == "" {
+		req.ChallengeToken = c.GetHeader(HeaderChallengeToken)
+	}
+
+	// 客户端信息随订单落库，渠道可放在请求体或请求头中
+	if req.Channel == "" {
+		req.Channel = domain.SpikeOrderChannel(c.GetHeader(HeaderClientChannel))
+	}
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+	if len(req.UserAgent) > domain.MaxUserAgentLength {
+		req.UserAgent = req.UserAgent[:domain.MaxUserAgentLength]
+	}
+
 	// 记录请求日志
 	h.logger.Info("处理秒杀参与请求",
-		zap.Int64("user_id", userID),
+		logger.UserID(userID),
+		zap.String("user_tier", string(req.UserTier)),
 		zap.Int64("spike_event_id", req.SpikeEventID),
 		zap.Int64("quantity", req.Quantity),
-		zap.String("idempotency_key", req.IdempotencyKey))
+		zap.String("channel", string(req.Channel)),
+		logger.IdempotencyKey(req.IdempotencyKey))
 
 	// 调用服务层
 	result, err := h.spikeService.ParticipateSpike(c.Request.Context(), &req, userID)
 	if err != nil {
 		h.logger.Error("秒杀参与失败", zap.Error(err))
-		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
-			"系统繁忙，请稍后重试", h.getRequestID(c), h.getTraceID(c))
+		resp.WriteJSON(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "系统繁忙，请稍后重试",
+			domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"),
+			h.getRequestID(c), h.getTraceID(c))
 		return
 	}
 
@@ -122,9 +222,12 @@ func (h *SpikeHandler) GetSpikeEventDetail(c *gin.Context) {
 	// 调用服务层
 	eventDetail, err := h.spikeService.GetSpikeEventDetail(c.Request.Context(), eventID)
 	if err != nil {
+		if writeDomainError(c, err) {
+			return
+		}
 		h.logger.Error("获取秒杀活动详情失败", zap.Int64("event_id", eventID), zap.Error(err))
-		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
-			"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取秒杀活动详情失败", h.getRequestID(c), h.getTraceID(c))
 		return
 	}
 
@@ -140,6 +243,8 @@ func (h *SpikeHandler) GetSpikeEventDetail(c *gin.Context) {
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页大小" default(20)
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1，以 has_more 判断是否还有下一页" default(true)
+// @Param cursor query string false "分页游标，取上一页的 next_cursor；按 created_at 排序时可用，设置后忽略 page 且不统计总数"
 // @Param sort_by query string false "排序字段" Enums(start_at, created_at, spike_price)
 // @Param sort_order query string false "排序方向" Enums(asc, desc) default(desc)
 // @Success 200 {object} resp.Response[domain.SpikeEventListResponse] "成功"
@@ -164,6 +269,7 @@ func (h *SpikeHandler) GetActiveEvents(c *gin.Context) {
 			req.PageSize = pageSize
 		}
 	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
 
 	if sortBy := c.Query("sort_by"); sortBy != "" {
 		req.SortBy = &sortBy
@@ -173,6 +279,14 @@ func (h *SpikeHandler) GetActiveEvents(c *gin.Context) {
 		req.SortOrder = &sortOrder
 	}
 
+	cursor, err := parseCursor(c.Query("cursor"), req.KeysetOrdered())
+	if err != nil {
+		resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "cursor", Message: err.Error()}},
+			h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	req.After = cursor
+
 	// 调用服务层
 	events, err := h.spikeService.GetActiveEvents(c.Request.Context(), req)
 	if err != nil {
@@ -194,10 +308,14 @@ func (h *SpikeHandler) GetActiveEvents(c *gin.Context) {
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页大小" default(20)
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1，以 has_more 判断是否还有下一页" default(true)
 // @Param status query string false "订单状态" Enums(pending, paid, cancelled, expired)
+// @Param with_event query bool false "是否附带所属活动摘要（名称、时间、状态）" default(false)
+// @Param cursor query string false "分页游标，取上一页的 next_cursor；按 created_at 排序时可用，设置后忽略 page 且不统计总数"
 // @Param sort_by query string false "排序字段" Enums(created_at, total_amount)
 // @Param sort_order query string false "排序方向" Enums(asc, desc) default(desc)
 // @Success 200 {object} resp.Response[domain.SpikeOrderListResponse] "成功"
+// @Failure 400 {object} resp.Response[resp.FieldErrors] "筛选参数取值无效"
 // @Failure 401 {object} resp.Response[any] "未授权"
 // @Failure 500 {object} resp.Response[any] "服务器内部错误"
 // @Router /api/v1/spike/orders [get]
@@ -228,9 +346,15 @@ func (h *SpikeHandler) GetUserSpikeOrders(c *gin.Context) {
 			req.PageSize = pageSize
 		}
 	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
 
 	if status := c.Query("status"); status != "" {
-		orderStatus := domain.SpikeOrderStatus(status)
+		orderStatus, err := domain.ParseSpikeOrderStatus(status)
+		if err != nil {
+			resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "status", Message: err.Error()}},
+				h.getRequestID(c), h.getTraceID(c))
+			return
+		}
 		req.Status = &orderStatus
 	}
 
@@ -242,10 +366,32 @@ func (h *SpikeHandler) GetUserSpikeOrders(c *gin.Context) {
 		req.SortOrder = &sortOrder
 	}
 
+	cursor, err := parseCursor(c.Query("cursor"), req.KeysetOrdered())
+	if err != nil {
+		resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "cursor", Message: err.Error()}},
+			h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	req.After = cursor
+
+	// 附带活动摘要时一次 JOIN 查询，避免客户端逐个查询活动
+	if withEvent, _ := strconv.ParseBool(c.Query("with_event")); withEvent {
+		orders, err := h.spikeService.GetUserSpikeOrdersWithEvents(c.Request.Context(), userID, req)
+		if err != nil {
+			h.logger.Error("获取用户秒杀订单失败", logger.UserID(userID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"获取订单列表失败", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", orders,
+			h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
 	// 调用服务层
 	orders, err := h.spikeService.GetUserSpikeOrders(c.Request.Context(), userID, req)
 	if err != nil {
-		h.logger.Error("获取用户秒杀订单失败", zap.Int64("user_id", userID), zap.Error(err))
+		h.logger.Error("获取用户秒杀订单失败", logger.UserID(userID), zap.Error(err))
 		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
 			"获取订单列表失败", h.getRequestID(c), h.getTraceID(c))
 		return
@@ -293,15 +439,58 @@ func (h *SpikeHandler) GetSpikeOrderDetail(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("获取秒杀订单详情失败",
 			zap.Int64("order_id", orderID),
-			zap.Int64("user_id", userID),
+			logger.UserID(userID),
 			zap.Error(err))
 
-		if err.Error() == "订单不属于当前用户" {
-			resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
-				"无权限访问该订单", h.getRequestID(c), h.getTraceID(c))
-		} else {
-			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
-				"订单不存在", h.getRequestID(c), h.getTraceID(c))
+		if !h.writeOrderAccessError(c, err) {
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"获取订单失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", orderDetail,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetSpikeOrderDetailByNo 按订单号获取秒杀订单详情
+// @Summary 按订单号获取秒杀订单详情
+// @Description 按参与成功时返回的订单号获取订单详情；订单异步落库，落库前返回 404，客户端可稍后重试
+// @Tags 秒杀
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Success 200 {object} resp.Response[domain.SpikeOrderWithDetails] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 403 {object} resp.Response[any] "无权限访问"
+// @Failure 404 {object} resp.Response[any] "订单不存在或尚未落库"
+// @Failure 500 {object} resp.Response[any] "服务器内部错误"
+// @Router /api/v1/spike/orders/no/{order_no} [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeOrderDetailByNo(c *gin.Context) {
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	orderNo := c.Param("order_no")
+	if !idgen.ValidOrderNo(orderNo) {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的订单号", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	orderDetail, err := h.spikeService.GetSpikeOrderDetailByNo(c.Request.Context(), orderNo, userID)
+	if err != nil {
+		if !h.writeOrderAccessError(c, err) {
+			h.logger.Error("按订单号获取秒杀订单详情失败",
+				zap.String("order_no", orderNo),
+				logger.UserID(userID),
+				zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"获取订单失败", h.getRequestID(c), h.getTraceID(c))
 		}
 		return
 	}
@@ -346,25 +535,27 @@ func (h *SpikeHandler) CancelSpikeOrder(c *gin.Context) {
 
 	// 解析请求体
 	var req domain.CancelSpikeOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("参数绑定失败", zap.Error(err))
-		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
-			"请求参数格式错误", h.getRequestID(c), h.getTraceID(c))
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
+	// 客服代操作时记录真实操作者
+	if impersonatorID := h.getImpersonatorID(c); impersonatorID > 0 {
+		req.Actor = domain.AdminActor(impersonatorID)
+	}
+
 	// 调用服务层
 	err = h.spikeService.CancelSpikeOrder(c.Request.Context(), orderID, userID, &req)
 	if err != nil {
 		h.logger.Error("取消秒杀订单失败",
 			zap.Int64("order_id", orderID),
-			zap.Int64("user_id", userID),
+			logger.UserID(userID),
 			zap.Error(err))
 
-		if err.Error() == "订单不属于当前用户" {
-			resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
-				"无权限操作该订单", h.getRequestID(c), h.getTraceID(c))
-		} else if err.Error() == "订单当前状态不允许取消" {
+		if h.writeOrderAccessError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrSpikeOrderNotCancellable) {
 			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
 				"订单当前状态不允许取消", h.getRequestID(c), h.getTraceID(c))
 		} else {
@@ -378,6 +569,191 @@ func (h *SpikeHandler) CancelSpikeOrder(c *gin.Context) {
 		h.getRequestID(c), h.getTraceID(c))
 }
 
+// ExtendSpikeOrder 延长秒杀订单支付时间
+// @Summary 延长秒杀订单支付时间
+// @Description 待支付订单可在过期前申请一次延长支付时间，延长记录写入订单时间线
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} resp.Response[domain.ExtendSpikeOrderResponse] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 403 {object} resp.Response[any] "无权限访问"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Failure 409 {object} resp.Response[any] "订单已延长过或不可延长"
+// @Failure 500 {object} resp.Response[any] "服务器内部错误"
+// @Router /api/v1/spike/orders/{id}/extend [post]
+// @Security Bearer
+func (h *SpikeHandler) ExtendSpikeOrder(c *gin.Context) {
+	// 获取用户ID
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 解析订单ID
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的订单ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 客服代操作时记录真实操作者
+	var actor string
+	if impersonatorID := h.getImpersonatorID(c); impersonatorID > 0 {
+		actor = domain.AdminActor(impersonatorID)
+	}
+
+	result, err := h.spikeService.ExtendSpikeOrder(c.Request.Context(), orderID, userID, actor)
+	if err != nil {
+		h.logger.Warn("延长秒杀订单支付时间失败",
+			zap.Int64("order_id", orderID),
+			logger.UserID(userID),
+			zap.Error(err))
+
+		if h.writeOrderAccessError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrSpikeOrderAlreadyExtended), errors.Is(err, domain.ErrSpikeOrderNotExtendable):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		default:
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"延长支付时间失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "支付时间已延长", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetSpikeOrderTimeline 获取秒杀订单时间线
+// @Summary 获取秒杀订单时间线
+// @Description 按时间顺序返回订单的全部状态流转记录，管理员（客服）可查看任意订单
+// @Tags 秒杀
+// @Accept json
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 200 {object} resp.Response[domain.SpikeOrderTimeline] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 403 {object} resp.Response[any] "无权限访问"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Router /api/v1/spike/orders/{id}/timeline [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeOrderTimeline(c *gin.Context) {
+	// 获取用户ID
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 解析订单ID
+	orderIDStr := c.Param("id")
+	orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+	if err != nil || orderID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的订单ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 调用服务层
+	timeline, err := h.spikeService.GetSpikeOrderTimeline(c.Request.Context(), orderID, userID, h.isAdmin(c))
+	if err != nil {
+		if h.writeOrderAccessError(c, err) {
+			return
+		}
+		h.logger.Error("获取秒杀订单时间线失败",
+			zap.Int64("order_id", orderID),
+			logger.UserID(userID),
+			zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取订单时间线失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", timeline,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// LookupSpikeOrder 按订单号、幂等键或支付流水号查找订单
+// @Summary 按订单号、幂等键或支付流水号查找订单
+// @Description 客服只掌握订单号、客户端幂等键或支付渠道交易ID时精确查找订单，返回订单及其时间线；每次查找都记录审计日志
+// @Tags 管理员
+// @Produce json
+// @Param order_no query string false "订单号"
+// @Param idempotency_key query string false "客户端幂等键"
+// @Param payment_ref query string false "支付渠道交易ID"
+// @Success 200 {object} resp.Response[domain.SpikeOrderLookupResponse] "成功"
+// @Failure 400 {object} resp.Response[resp.FieldErrors] "未提供查找条件"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Router /api/v1/admin/spike/orders/lookup [get]
+// @Security Bearer
+func (h *SpikeHandler) LookupSpikeOrder(c *gin.Context) {
+	req := &domain.SpikeOrderLookupRequest{
+		OrderNo:        strings.TrimSpace(c.Query("order_no")),
+		IdempotencyKey: strings.TrimSpace(c.Query("idempotency_key")),
+		PaymentRef:     strings.TrimSpace(c.Query("payment_ref")),
+	}
+	var fields []resp.FieldError
+	if req.OrderNo == "" && req.IdempotencyKey == "" && req.PaymentRef == "" {
+		fields = append(fields, resp.FieldError{Field: "idempotency_key", Message: "order_no、idempotency_key 与 payment_ref 至少提供一项"})
+	}
+	if req.OrderNo != "" && !idgen.ValidOrderNo(req.OrderNo) {
+		fields = append(fields, resp.FieldError{Field: "order_no", Message: "订单号格式无效"})
+	}
+	if len(req.IdempotencyKey) > 64 {
+		fields = append(fields, resp.FieldError{Field: "idempotency_key", Message: "长度不能超过 64"})
+	}
+	if len(req.PaymentRef) > 64 {
+		fields = append(fields, resp.FieldError{Field: "payment_ref", Message: "长度不能超过 64"})
+	}
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	result, err := h.spikeService.LookupSpikeOrder(c.Request.Context(), req)
+	h.logOrderLookup(c, req, result, err)
+	if err != nil {
+		if !h.writeOrderAccessError(c, err) {
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"查找订单失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// logOrderLookup 记录订单查找的审计日志，未找到与查找失败同样记录
+func (h *SpikeHandler) logOrderLookup(c *gin.Context, req *domain.SpikeOrderLookupRequest, result *domain.SpikeOrderLookupResponse, err error) {
+	fields := []zap.Field{
+		zap.Bool("audit", true),
+		zap.String("request_id", h.getRequestID(c)),
+		zap.String("operator", domain.AdminActor(h.getCurrentUserID(c))),
+		zap.String("order_no", req.OrderNo),
+		zap.String("idempotency_key", req.IdempotencyKey),
+		zap.String("payment_ref", req.PaymentRef),
+		zap.Bool("found", result != nil),
+	}
+	if result != nil {
+		fields = append(fields, zap.Int64("spike_order_id", result.Order.ID))
+	}
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		fields = append(fields, zap.Error(err))
+	}
+	h.logger.Info("spike order lookup", fields...)
+}
+
 // GetSpikeStats 获取秒杀统计信息
 // @Summary 获取秒杀统计信息
 // @Description 获取指定秒杀活动的统计信息，包含库存、订单等数据
@@ -403,9 +779,12 @@ func (h *SpikeHandler) GetSpikeStats(c *gin.Context) {
 	// 调用服务层
 	stats, err := h.spikeService.GetSpikeStats(c.Request.Context(), eventID)
 	if err != nil {
+		if writeDomainError(c, err) {
+			return
+		}
 		h.logger.Error("获取秒杀统计信息失败", zap.Int64("event_id", eventID), zap.Error(err))
-		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
-			"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取秒杀统计信息失败", h.getRequestID(c), h.getTraceID(c))
 		return
 	}
 
@@ -415,16 +794,18 @@ func (h *SpikeHandler) GetSpikeStats(c *gin.Context) {
 
 // WarmupStock 预热库存（管理员接口）
 // @Summary 预热库存
-// @Description 预热指定秒杀活动的库存到Redis缓存中
+// @Description 按数据库剩余库存（扣除待支付订单）预热指定秒杀活动的库存到Redis缓存中；活动进行中且库存已预热时需指定 force=true 才会覆盖
 // @Tags 秒杀管理
 // @Accept json
 // @Produce json
 // @Param id path int true "秒杀活动ID"
+// @Param force query bool false "强制覆盖进行中活动的已有库存"
 // @Success 200 {object} resp.Response[any] "成功"
 // @Failure 400 {object} resp.Response[any] "请求参数错误"
 // @Failure 401 {object} resp.Response[any] "未授权"
 // @Failure 403 {object} resp.Response[any] "权限不足"
 // @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动进行中且库存已预热"
 // @Failure 500 {object} resp.Response[any] "服务器内部错误"
 // @Router /api/v1/admin/spike/events/{id}/warmup [post]
 // @Security Bearer
@@ -445,8 +826,29 @@ func (h *SpikeHandler) WarmupStock(c *gin.Context) {
 		return
 	}
 
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的force参数", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 审计记录预热前后的库存统计
+	middleware.SetAuditEntity(c, domain.AdminAuditEntitySpikeEvent, eventID)
+	auditEnabled := middleware.AuditEnabled(c)
+	if auditEnabled {
+		if before, err := h.spikeService.GetSpikeStats(c.Request.Context(), eventID); err == nil {
+			middleware.SetAuditBefore(c, before)
+		}
+	}
+
 	// 调用服务层
-	err = h.spikeService.WarmupStock(c.Request.Context(), eventID)
+	err = h.spikeService.WarmupStock(c.Request.Context(), eventID, force)
+	if errors.Is(err, domain.ErrSpikeStockAlreadyWarmed) {
+		resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+		return
+	}
 	if err != nil {
 		h.logger.Error("预热库存失败", zap.Int64("event_id", eventID), zap.Error(err))
 		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
@@ -455,10 +857,67 @@ func (h *SpikeHandler) WarmupStock(c *gin.Context) {
 	}
 
 	h.logger.Info("库存预热成功", zap.Int64("event_id", eventID))
+	if auditEnabled {
+		if after, err := h.spikeService.GetSpikeStats(c.Request.Context(), eventID); err == nil {
+			middleware.SetAuditAfter(c, after)
+		}
+	}
 	resp.WriteJSON[any](c.Writer, http.StatusOK, resp.CodeOK, "库存预热成功", nil,
 		h.getRequestID(c), h.getTraceID(c))
 }
 
+// PlanCapacity 获取秒杀活动容量规划建议（管理员接口）
+// @Summary 容量规划建议
+// @Description 根据活动库存、单用户限购及相似历史活动的成交速率，给出限流、消费者并发和数据库连接池的建议配置
+// @Tags 秒杀管理
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param per_user_limit query int false "单用户限购数量，默认1"
+// @Success 200 {object} resp.Response[service.CapacityPlan] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 500 {object} resp.Response[any] "服务器内部错误"
+// @Router /api/v1/admin/spike/events/{id}/capacity-plan [get]
+// @Security Bearer
+func (h *SpikeHandler) PlanCapacity(c *gin.Context) {
+	// 解析活动ID
+	eventIDStr := c.Param("id")
+	eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	perUserLimit := int64(1)
+	if v := c.Query("per_user_limit"); v != "" {
+		perUserLimit, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || perUserLimit <= 0 {
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				"无效的限购数量", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+	}
+
+	// 调用服务层
+	plan, err := h.spikeService.PlanCapacity(c.Request.Context(), eventID, perUserLimit)
+	if err != nil {
+		if writeDomainError(c, err) {
+			return
+		}
+		h.logger.Error("生成容量规划失败", zap.Int64("event_id", eventID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"生成容量规划失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", plan,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
 // 辅助方法
 
 // getCurrentUserID 获取当前用户ID
@@ -471,6 +930,16 @@ func (h *SpikeHandler) getCurrentUserID(c *gin.Context) int64 {
 	return 0
 }
 
+// getImpersonatorID 获取代操作发起者（客服）ID，非代操作请求返回0
+func (h *SpikeHandler) getImpersonatorID(c *gin.Context) int64 {
+	if impersonatorID, exists := c.Get("impersonator_id"); exists {
+		if id, ok := impersonatorID.(int64); ok {
+			return id
+		}
+	}
+	return 0
+}
+
 // isAdmin 检查是否为管理员
 func (h *SpikeHandler) isAdmin(c *gin.Context) bool {
 	if role, exists := c.Get("user_role"); exists {
@@ -481,6 +950,21 @@ func (h *SpikeHandler) isAdmin(c *gin.Context) bool {
 	return false
 }
 
+// writeOrderAccessError 将服务层的所有权校验错误统一映射为 404 / 403，返回是否已写出响应
+func (h *SpikeHandler) writeOrderAccessError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"订单不存在", h.getRequestID(c), h.getTraceID(c))
+	case errors.Is(err, domain.ErrForbidden):
+		resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
+			"无权限访问该订单", h.getRequestID(c), h.getTraceID(c))
+	default:
+		return false
+	}
+	return true
+}
+
 // getRequestID 获取请求ID
 func (h *SpikeHandler) getRequestID(c *gin.Context) string {
 	if requestID, exists := c.Get("request_id"); exists {
@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,19 +18,34 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/resp"
 	"github.com/MorseWayne/spike_shop/internal/service"
+	"github.com/MorseWayne/spike_shop/internal/storage"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
 )
 
 // MockSpikeService for testing
 type MockSpikeService struct {
-	participateFunc     func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error)
-	getEventDetailFunc  func(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error)
-	getActiveEventsFunc func(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventListResponse, error)
-	getUserOrdersFunc   func(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error)
-	getOrderDetailFunc  func(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error)
-	cancelOrderFunc     func(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error
-	getSpikeStatsFunc   func(ctx context.Context, eventID int64) (*service.SpikeStats, error)
-	warmupStockFunc     func(ctx context.Context, eventID int64) error
+	participateFunc      func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error)
+	getEventDetailFunc   func(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error)
+	getActiveEventsFunc  func(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventListResponse, error)
+	getUserOrdersFunc    func(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error)
+	getOrdersEventsFunc  func(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderWithEventListResponse, error)
+	getOrderDetailFunc   func(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error)
+	cancelOrderFunc      func(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error
+	extendOrderFunc      func(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error)
+	getOrderTimelineFunc func(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error)
+	lookupOrderFunc      func(ctx context.Context, req *domain.SpikeOrderLookupRequest) (*domain.SpikeOrderLookupResponse, error)
+	listOrdersFunc       func(ctx context.Context, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error)
+	exportOrdersFunc     func(ctx context.Context, req *domain.SpikeOrderListRequest, w io.Writer) (bool, error)
+	getSpikeStatsFunc    func(ctx context.Context, eventID int64) (*service.SpikeStats, error)
+	warmupStockFunc      func(ctx context.Context, eventID int64, force bool) error
+	planCapacityFunc     func(ctx context.Context, eventID, perUserLimit int64) (*service.CapacityPlan, error)
+	issueTokenFunc       func(ctx context.Context, eventID, userID int64) (*domain.ParticipationToken, error)
+	issueChallengeFunc   func(ctx context.Context, eventID, userID int64) (*domain.SpikeChallenge, error)
+	queueStatusFunc      func(ctx context.Context, token string, userID int64) (*domain.SpikeQueueStatus, error)
 }
 
 func (m *MockSpikeService) ParticipateSpike(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
@@ -74,6 +94,23 @@ func (m *MockSpikeService) GetUserSpikeOrders(ctx context.Context, userID int64,
 	}, nil
 }
 
+func (m *MockSpikeService) GetUserSpikeOrdersWithEvents(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderWithEventListResponse, error) {
+	if m.getOrdersEventsFunc != nil {
+		return m.getOrdersEventsFunc(ctx, userID, req)
+	}
+	return &domain.SpikeOrderWithEventListResponse{
+		Orders: []*domain.SpikeOrderWithEvent{
+			{
+				SpikeOrder: &domain.SpikeOrder{ID: 1, UserID: userID, SpikeEventID: 1, Status: domain.SpikeOrderStatusPending},
+				SpikeEvent: &domain.SpikeEventSummary{ID: 1, Name: "Test Event 1", Status: domain.SpikeEventStatusActive},
+			},
+		},
+		Total:    1,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
 func (m *MockSpikeService) GetSpikeOrderDetail(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error) {
 	if m.getOrderDetailFunc != nil {
 		return m.getOrderDetailFunc(ctx, orderID, userID)
@@ -85,6 +122,13 @@ func (m *MockSpikeService) GetSpikeOrderDetail(ctx context.Context, orderID, use
 	}, nil
 }
 
+func (m *MockSpikeService) GetSpikeOrderDetailByNo(ctx context.Context, orderNo string, userID int64) (*domain.SpikeOrderWithDetails, error) {
+	if orderNo != "1001" {
+		return nil, domain.ErrSpikeOrderNotFound
+	}
+	return &domain.SpikeOrderWithDetails{SpikeOrder: &domain.SpikeOrder{ID: 7, OrderNo: orderNo, UserID: userID}}, nil
+}
+
 func (m *MockSpikeService) CancelSpikeOrder(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error {
 	if m.cancelOrderFunc != nil {
 		return m.cancelOrderFunc(ctx, orderID, userID, req)
@@ -92,6 +136,47 @@ func (m *MockSpikeService) CancelSpikeOrder(ctx context.Context, orderID, userID
 	return nil
 }
 
+func (m *MockSpikeService) ExtendSpikeOrder(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error) {
+	if m.extendOrderFunc != nil {
+		return m.extendOrderFunc(ctx, orderID, userID, actor)
+	}
+	return &domain.ExtendSpikeOrderResponse{SpikeOrderID: orderID, ExpireAt: time.Now().Add(10 * time.Minute), ExpiresInSeconds: 600}, nil
+}
+
+func (m *MockSpikeService) GetSpikeOrderTimeline(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error) {
+	if m.getOrderTimelineFunc != nil {
+		return m.getOrderTimelineFunc(ctx, orderID, userID, isAdmin)
+	}
+	return &domain.SpikeOrderTimeline{
+		SpikeOrderID: orderID,
+		Status:       domain.SpikeOrderStatusPending,
+		Events: []*domain.OrderEvent{
+			{ID: 1, SpikeOrderID: orderID, EventType: domain.OrderEventCreated, Actor: domain.UserActor(userID)},
+		},
+	}, nil
+}
+
+func (m *MockSpikeService) LookupSpikeOrder(ctx context.Context, req *domain.SpikeOrderLookupRequest) (*domain.SpikeOrderLookupResponse, error) {
+	if m.lookupOrderFunc != nil {
+		return m.lookupOrderFunc(ctx, req)
+	}
+	return nil, domain.ErrSpikeOrderNotFound
+}
+
+func (m *MockSpikeService) ListSpikeOrders(ctx context.Context, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error) {
+	if m.listOrdersFunc != nil {
+		return m.listOrdersFunc(ctx, req)
+	}
+	return &domain.SpikeOrderListResponse{Orders: []*domain.SpikeOrder{}, Page: req.Page, PageSize: req.PageSize}, nil
+}
+
+func (m *MockSpikeService) ExportSpikeOrders(ctx context.Context, req *domain.SpikeOrderListRequest, w io.Writer) (bool, error) {
+	if m.exportOrdersFunc != nil {
+		return m.exportOrdersFunc(ctx, req, w)
+	}
+	return false, nil
+}
+
 func (m *MockSpikeService) GetSpikeStats(ctx context.Context, eventID int64) (*service.SpikeStats, error) {
 	if m.getSpikeStatsFunc != nil {
 		return m.getSpikeStatsFunc(ctx, eventID)
@@ -108,17 +193,47 @@ func (m *MockSpikeService) GetSpikeStats(ctx context.Context, eventID int64) (*s
 	}, nil
 }
 
-func (m *MockSpikeService) WarmupStock(ctx context.Context, eventID int64) error {
+func (m *MockSpikeService) WarmupAllStock(ctx context.Context, force bool, progress service.AdminJobProgress) (*domain.WarmupAllResult, error) {
+	return &domain.WarmupAllResult{}, nil
+}
+
+func (m *MockSpikeService) WarmupStock(ctx context.Context, eventID int64, force bool) error {
 	if m.warmupStockFunc != nil {
-		return m.warmupStockFunc(ctx, eventID)
+		return m.warmupStockFunc(ctx, eventID, force)
 	}
 	return nil
 }
 
+func (m *MockSpikeService) PlanCapacity(ctx context.Context, eventID, perUserLimit int64) (*service.CapacityPlan, error) {
+	if m.planCapacityFunc != nil {
+		return m.planCapacityFunc(ctx, eventID, perUserLimit)
+	}
+	return &service.CapacityPlan{EventID: eventID, PerUserLimit: perUserLimit}, nil
+}
+
+func (m *MockSpikeService) IssueParticipationToken(ctx context.Context, eventID, userID int64) (*domain.ParticipationToken, error) {
+	if m.issueTokenFunc != nil {
+		return m.issueTokenFunc(ctx, eventID, userID)
+	}
+	return &domain.ParticipationToken{SpikeEventID: eventID, Token: "token"}, nil
+}
+
+func (m *MockSpikeService) IssueChallenge(ctx context.Context, eventID, userID int64) (*domain.SpikeChallenge, error) {
+	if m.issueChallengeFunc != nil {
+		return m.issueChallengeFunc(ctx, eventID, userID)
+	}
+	return &domain.SpikeChallenge{ChallengeID: "c", SpikeEventID: eventID}, nil
+}
+
+func (m *MockSpikeService) GetQueueStatus(ctx context.Context, token string, userID int64) (*domain.SpikeQueueStatus, error) {
+	if m.queueStatusFunc != nil {
+		return m.queueStatusFunc(ctx, token, userID)
+	}
+	return &domain.SpikeQueueStatus{QueueToken: token}, nil
+}
+
 func setupTestRouter() *gin.Engine {
-	gin.SetMode(gin.TestMode)
-	router := gin.New()
-	return router
+	return testutil.NewGinEngine(0)
 }
 
 func TestSpikeHandler_HealthCheck(t *testing.T) {
@@ -154,107 +269,1139 @@ func TestSpikeHandler_HealthCheck(t *testing.T) {
 	}
 }
 
-func TestSpikeHandler_ParticipateSpike(t *testing.T) {
+func TestSpikeHandler_PlanCapacity(t *testing.T) {
 	tests := []struct {
-		name        string
-		userID      int64
-		requestBody interface{}
-		mockFunc    func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error)
-		wantStatus  int
-		wantSuccess bool
+		name          string
+		eventID       string
+		query         string
+		mockFunc      func(ctx context.Context, eventID, perUserLimit int64) (*service.CapacityPlan, error)
+		wantStatus    int
+		wantUserLimit int64
 	}{
 		{
-			name:   "successful participation",
-			userID: 123,
-			requestBody: map[string]interface{}{
-				"spike_event_id":  1,
-				"quantity":        1,
-				"idempotency_key": "test_key_1",
-			},
-			mockFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
-				return &domain.SpikeParticipationResponse{
-					Success: true,
-					Message: "秒杀成功",
-				}, nil
-			},
-			wantStatus:  http.StatusOK,
-			wantSuccess: true,
+			name:          "default per-user limit",
+			eventID:       "1",
+			wantStatus:    http.StatusOK,
+			wantUserLimit: 1,
 		},
 		{
-			name:   "sold out",
-			userID: 123,
-			requestBody: map[string]interface{}{
-				"spike_event_id":  1,
-				"quantity":        1,
-				"idempotency_key": "test_key_2",
-			},
-			mockFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
-				return &domain.SpikeParticipationResponse{
-					Success: false,
-					Message: "商品已售罄",
-				}, nil
-			},
-			wantStatus:  http.StatusOK,
-			wantSuccess: false,
+			name:          "custom per-user limit",
+			eventID:       "1",
+			query:         "?per_user_limit=3",
+			wantStatus:    http.StatusOK,
+			wantUserLimit: 3,
 		},
 		{
-			name:   "invalid request body",
-			userID: 123,
-			requestBody: map[string]interface{}{
-				"invalid_field": "invalid_value",
-			},
+			name:       "invalid per-user limit",
+			eventID:    "1",
+			query:      "?per_user_limit=0",
 			wantStatus: http.StatusBadRequest,
 		},
 		{
-			name:   "unauthorized user",
-			userID: 0,
-			requestBody: map[string]interface{}{
-				"spike_event_id":  1,
-				"quantity":        1,
-				"idempotency_key": "test_key_3",
+			name:       "invalid event ID",
+			eventID:    "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "event not found",
+			eventID: "999",
+			mockFunc: func(ctx context.Context, eventID, perUserLimit int64) (*service.CapacityPlan, error) {
+				return nil, domain.ErrSpikeEventNotFound
 			},
-			wantStatus: http.StatusUnauthorized,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "repository failure",
+			eventID: "1",
+			mockFunc: func(ctx context.Context, eventID, perUserLimit int64) (*service.CapacityPlan, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSpikeService{planCapacityFunc: tt.mockFunc}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.GET("/events/:id/capacity-plan", handler.PlanCapacity)
+
+			req := httptest.NewRequest("GET", "/events/"+tt.eventID+"/capacity-plan"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("PlanCapacity() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Data service.CapacityPlan `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("PlanCapacity() failed to parse response: %v", err)
+				}
+				if response.Data.PerUserLimit != tt.wantUserLimit {
+					t.Errorf("PlanCapacity() per_user_limit = %d, want %d", response.Data.PerUserLimit, tt.wantUserLimit)
+				}
+			}
+		})
+	}
+}
+
+type fakeConsumerStats map[string]mq.ConsumerStats
+
+func (f fakeConsumerStats) GetConsumerStats() map[string]mq.ConsumerStats { return f }
+
+type fakeQueueInspector struct {
+	info *mq.QueueInfo
+	err  error
+}
+
+func (f *fakeQueueInspector) GetQueueInfo(ctx context.Context, queueName string) (*mq.QueueInfo, error) {
+	return f.info, f.err
+}
+
+type fakeLimiter struct {
+	info    *limiter.LimitInfo
+	lastKey string
+}
+
+func (f *fakeLimiter) Allow(ctx context.Context, key string) (*limiter.LimitResult, error) {
+	return &limiter.LimitResult{Allowed: true}, nil
+}
+
+func (f *fakeLimiter) AllowN(ctx context.Context, key string, n int64) (*limiter.LimitResult, error) {
+	return &limiter.LimitResult{Allowed: true}, nil
+}
+
+func (f *fakeLimiter) Reset(ctx context.Context, key string) error { return nil }
+
+func (f *fakeLimiter) GetInfo(ctx context.Context, key string) (*limiter.LimitInfo, error) {
+	f.lastKey = key
+	return f.info, nil
+}
+
+func TestSpikeHandler_SystemStatus(t *testing.T) {
+	spikeLimiter := &fakeLimiter{info: &limiter.LimitInfo{Limit: 100, Remaining: 25, Window: time.Minute}}
+	handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+	handler.SetStatusSources(&StatusSources{
+		Consumers: fakeConsumerStats{
+			"order": {QueueName: mq.SpikeOrderQueue, ProcessedCount: 10, RetriedCount: 2},
+		},
+		Queues: &fakeQueueInspector{
+			info: &mq.QueueInfo{Name: mq.SpikeDLXQueue, Messages: 3},
+		},
+		Limiters: []LimiterProbe{
+			{Name: "spike", Limiter: spikeLimiter, Key: "spike:default"},
+		},
+	})
+
+	router := setupTestRouter()
+	router.GET("/status", handler.SystemStatus)
+
+	req := httptest.NewRequest("GET", "/status?limiter_key=spike:user:42", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("SystemStatus() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Data SystemStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("SystemStatus() failed to parse response: %v", err)
+	}
+
+	if got := response.Data.Consumers["order"].RetriedCount; got != 2 {
+		t.Errorf("SystemStatus() order retried_count = %d, want 2", got)
+	}
+	if response.Data.DLQ == nil || response.Data.DLQ.Messages != 3 {
+		t.Errorf("SystemStatus() dlq = %+v, want 3 messages", response.Data.DLQ)
+	}
+	ls := response.Data.Limiters["spike"]
+	if ls == nil || ls.Utilization != 0.75 {
+		t.Errorf("SystemStatus() spike limiter = %+v, want utilization 0.75", ls)
+	}
+	if spikeLimiter.lastKey != "spike:user:42" {
+		t.Errorf("SystemStatus() probed key = %q, want spike:user:42", spikeLimiter.lastKey)
+	}
+}
+
+func TestSpikeHandler_SystemStatus_DLQError(t *testing.T) {
+	handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+	handler.SetStatusSources(&StatusSources{
+		Queues: &fakeQueueInspector{err: fmt.Errorf("channel closed")},
+	})
+
+	router := setupTestRouter()
+	router.GET("/status", handler.SystemStatus)
+
+	req := httptest.NewRequest("GET", "/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("SystemStatus() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Data SystemStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("SystemStatus() failed to parse response: %v", err)
+	}
+	if response.Data.DLQ != nil || len(response.Data.Errors) != 1 {
+		t.Errorf("SystemStatus() = %+v, want dlq omitted and one error", response.Data)
+	}
+}
+
+type fakeReportService struct {
+	report     *domain.SpikeReport
+	err        error
+	regenerate bool
+	content    string
+	openErr    error
+}
+
+func (f *fakeReportService) RequestReport(ctx context.Context, eventID int64, regenerate bool) (*domain.SpikeReport, error) {
+	f.regenerate = regenerate
+	return f.report, f.err
+}
+
+func (f *fakeReportService) OpenReport(ctx context.Context, key, expires, sig string) (io.ReadCloser, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	return io.NopCloser(strings.NewReader(f.content)), nil
+}
+
+func TestSpikeHandler_WarmupAllStock(t *testing.T) {
+	handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+	router := setupTestRouter()
+	router.POST("/events/warmup-all", handler.WarmupAllStock)
+	router.GET("/jobs/:id", handler.GetAdminJob)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/events/warmup-all", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("WarmupAllStock() without queue status = %d, want 503", w.Code)
+	}
+
+	// 队列未启动，任务保持排队状态
+	handler.SetAdminJobQueue(service.NewAdminJobQueue(nil, nil))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/events/warmup-all", strings.NewReader(`{"force":true}`)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("WarmupAllStock() status = %d, want 202", w.Code)
+	}
+	var response struct {
+		Data domain.AdminJob `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("WarmupAllStock() failed to parse response: %v", err)
+	}
+	if response.Data.Kind != domain.AdminJobKindWarmupAll || response.Data.Status != domain.AdminJobStatusQueued {
+		t.Errorf("WarmupAllStock() job = %+v, want queued warmup_all", response.Data)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/jobs/%d", response.Data.ID), nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GetAdminJob() status = %d, want 200", w.Code)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/jobs/999", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GetAdminJob(missing) status = %d, want 404", w.Code)
+	}
+}
+
+func TestSpikeHandler_LookupSpikeOrder(t *testing.T) {
+	mockService := &MockSpikeService{
+		lookupOrderFunc: func(ctx context.Context, req *domain.SpikeOrderLookupRequest) (*domain.SpikeOrderLookupResponse, error) {
+			if req.PaymentRef != "sb_pi_1" {
+				return nil, domain.ErrSpikeOrderNotFound
+			}
+			return &domain.SpikeOrderLookupResponse{
+				Order:    &domain.SpikeOrder{ID: 7, PaymentRef: req.PaymentRef},
+				Timeline: &domain.SpikeOrderTimeline{SpikeOrderID: 7},
+			}, nil
+		},
+	}
+	handler := NewSpikeHandler(mockService, zap.NewNop())
+	router := setupTestRouter()
+	router.GET("/orders/lookup", handler.LookupSpikeOrder)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "missing criteria", query: "", wantStatus: http.StatusBadRequest},
+		{name: "key too long", query: "?idempotency_key=" + strings.Repeat("k", 65), wantStatus: http.StatusBadRequest},
+		{name: "invalid order no", query: "?order_no=abc", wantStatus: http.StatusBadRequest},
+		{name: "found by payment ref", query: "?payment_ref=sb_pi_1", wantStatus: http.StatusOK},
+		{name: "not found", query: "?idempotency_key=missing", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/orders/lookup"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("LookupSpikeOrder() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_GetSpikeOrderDetailByNo(t *testing.T) {
+	handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+	router := setupTestRouter()
+	router.GET("/orders/no/:order_no", func(c *gin.Context) {
+		c.Set("user_id", int64(123))
+		handler.GetSpikeOrderDetailByNo(c)
+	})
+
+	tests := []struct {
+		name       string
+		orderNo    string
+		wantStatus int
+	}{
+		{name: "found", orderNo: "1001", wantStatus: http.StatusOK},
+		{name: "not persisted yet", orderNo: "1002", wantStatus: http.StatusNotFound},
+		{name: "invalid order no", orderNo: "abc", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/orders/no/"+tt.orderNo, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetSpikeOrderDetailByNo() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_ListAdminSpikeOrders(t *testing.T) {
+	var gotReq *domain.SpikeOrderListRequest
+	mockService := &MockSpikeService{
+		listOrdersFunc: func(ctx context.Context, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error) {
+			gotReq = req
+			return &domain.SpikeOrderListResponse{Orders: []*domain.SpikeOrder{}, Page: req.Page, PageSize: req.PageSize}, nil
 		},
+		exportOrdersFunc: func(ctx context.Context, req *domain.SpikeOrderListRequest, w io.Writer) (bool, error) {
+			gotReq = req
+			_, err := io.WriteString(w, "id\n1\n")
+			return true, err
+		},
+	}
+	handler := NewSpikeHandler(mockService, zap.NewNop())
+	router := setupTestRouter()
+	router.GET("/orders", handler.ListAdminSpikeOrders)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantCSV    bool
+	}{
+		{name: "no filters", query: "", wantStatus: http.StatusOK},
+		{name: "all filters", query: "?spike_event_id=7&user_id=3&status=paid&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z", wantStatus: http.StatusOK},
+		{name: "invalid user", query: "?user_id=abc", wantStatus: http.StatusBadRequest},
+		{name: "invalid status", query: "?status=unknown", wantStatus: http.StatusBadRequest},
+		{name: "invalid time", query: "?from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "empty range", query: "?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "invalid format", query: "?format=xml", wantStatus: http.StatusBadRequest},
+		{name: "csv export", query: "?spike_event_id=7&format=csv", wantStatus: http.StatusOK, wantCSV: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotReq = nil
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/orders"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ListAdminSpikeOrders() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if gotReq != nil {
+					t.Error("ListAdminSpikeOrders() called service with invalid filters")
+				}
+				return
+			}
+			if tt.wantCSV {
+				if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Errorf("ListAdminSpikeOrders() Content-Type = %q, want text/csv", ct)
+				}
+				if got := w.Result().Trailer.Get("X-Export-Truncated"); got != "true" {
+					t.Errorf("ListAdminSpikeOrders() X-Export-Truncated = %q, want true", got)
+				}
+			}
+			if tt.name == "all filters" && (gotReq.SpikeEventID == nil || *gotReq.SpikeEventID != 7 || gotReq.UserID == nil ||
+				*gotReq.UserID != 3 || gotReq.Status == nil || gotReq.From == nil || gotReq.To == nil) {
+				t.Errorf("ListAdminSpikeOrders() request = %+v, want all filters set", gotReq)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_GetSpikeReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventID    string
+		query      string
+		svc        *fakeReportService
+		wantStatus int
+	}{
+		{
+			name:       "report ready",
+			eventID:    "1",
+			svc:        &fakeReportService{report: &domain.SpikeReport{SpikeEventID: 1, Status: domain.SpikeReportStatusReady, URL: "/api/v1/reports/download?key=k"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "generation started",
+			eventID:    "1",
+			query:      "?regenerate=true",
+			svc:        &fakeReportService{report: &domain.SpikeReport{SpikeEventID: 1, Status: domain.SpikeReportStatusGenerating}},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "event not finished",
+			eventID:    "1",
+			svc:        &fakeReportService{err: service.ErrSpikeEventNotFinished},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "event not found",
+			eventID:    "999",
+			svc:        &fakeReportService{err: domain.ErrSpikeEventNotFound},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid event ID",
+			eventID:    "abc",
+			svc:        &fakeReportService{},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+			handler.SetReportService(tt.svc)
+
+			router := setupTestRouter()
+			router.GET("/events/:id/report", handler.GetSpikeReport)
+
+			req := httptest.NewRequest("GET", "/events/"+tt.eventID+"/report"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetSpikeReport() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.query != "" && !tt.svc.regenerate {
+				t.Errorf("GetSpikeReport() regenerate flag not passed to service")
+			}
+		})
+	}
+}
+
+type fakeStockReconciler struct {
+	report  *service.StockReconcileReport
+	err     error
+	eventID int64
+	repair  bool
+}
+
+func (f *fakeStockReconciler) RunOnce(ctx context.Context, repair bool) (*service.StockReconcileReport, error) {
+	f.repair = repair
+	return f.report, f.err
+}
+
+func (f *fakeStockReconciler) ReconcileEvent(ctx context.Context, eventID int64, repair bool) (*service.StockReconcileReport, error) {
+	f.eventID, f.repair = eventID, repair
+	return f.report, f.err
+}
+
+func TestSpikeHandler_ReconcileStock(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		svc         *fakeStockReconciler
+		wantStatus  int
+		wantEventID int64
+		wantRepair  bool
+	}{
+		{
+			name:       "all active events",
+			svc:        &fakeStockReconciler{report: &service.StockReconcileReport{Checked: 2}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "single event with repair",
+			query:       "?event_id=7&repair=true",
+			svc:         &fakeStockReconciler{report: &service.StockReconcileReport{Checked: 1, Repair: true}},
+			wantStatus:  http.StatusOK,
+			wantEventID: 7,
+			wantRepair:  true,
+		},
+		{
+			name:        "event not found",
+			query:       "?event_id=999",
+			svc:         &fakeStockReconciler{err: domain.ErrSpikeEventNotFound},
+			wantStatus:  http.StatusNotFound,
+			wantEventID: 999,
+		},
+		{
+			name:       "invalid params",
+			query:      "?event_id=abc&repair=maybe",
+			svc:        &fakeStockReconciler{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "reconciler disabled",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+			if tt.svc != nil {
+				handler.SetStockReconciler(tt.svc)
+			}
+
+			router := setupTestRouter()
+			router.POST("/stock/reconcile", handler.ReconcileStock)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/stock/reconcile"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ReconcileStock() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.svc != nil && (tt.svc.eventID != tt.wantEventID || tt.svc.repair != tt.wantRepair) {
+				t.Errorf("ReconcileStock() passed event_id=%d repair=%v, want %d %v",
+					tt.svc.eventID, tt.svc.repair, tt.wantEventID, tt.wantRepair)
+			}
+		})
+	}
+}
+
+type fakeAbandonedCheckoutService struct {
+	listReq    *domain.AbandonedCheckoutListRequest
+	recoverErr error
+}
+
+func (f *fakeAbandonedCheckoutService) ListAbandonedCheckouts(ctx context.Context, req *domain.AbandonedCheckoutListRequest) (*domain.AbandonedCheckoutListResponse, error) {
+	f.listReq = req
+	return &domain.AbandonedCheckoutListResponse{Items: []*domain.AbandonedCheckout{}, Page: req.Page, PageSize: req.PageSize, Summary: &domain.AbandonedCheckoutSummary{}}, nil
+}
+
+func (f *fakeAbandonedCheckoutService) StartRecovery(ctx context.Context, checkoutID int64, req *domain.RecoveryCampaignRequest) (*domain.AbandonedCheckout, error) {
+	if f.recoverErr != nil {
+		return nil, f.recoverErr
+	}
+	return &domain.AbandonedCheckout{ID: checkoutID, CampaignID: req.CampaignID, CouponCode: req.CouponCode}, nil
+}
+
+func TestSpikeHandler_ListAbandonedCheckouts(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		svc        *fakeAbandonedCheckoutService
+		wantStatus int
+	}{
+		{
+			name:       "with filters",
+			query:      "?spike_event_id=7&recovered=false&from=2024-01-01T00:00:00Z",
+			svc:        &fakeAbandonedCheckoutService{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid time",
+			query:      "?from=yesterday",
+			svc:        &fakeAbandonedCheckoutService{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "service disabled",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+			if tt.svc != nil {
+				handler.SetAbandonedCheckoutService(tt.svc)
+			}
+
+			router := setupTestRouter()
+			router.GET("/abandoned-checkouts", handler.ListAbandonedCheckouts)
+
+			req := httptest.NewRequest("GET", "/abandoned-checkouts"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ListAbandonedCheckouts() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				got := tt.svc.listReq
+				if got.SpikeEventID == nil || *got.SpikeEventID != 7 || got.Recovered == nil || *got.Recovered || got.From == nil {
+					t.Errorf("ListAbandonedCheckouts() filters not passed to service: %+v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_StartRecoveryCampaign(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkoutID string
+		body       string
+		svc        *fakeAbandonedCheckoutService
+		wantStatus int
+	}{
+		{
+			name:       "recovery started",
+			checkoutID: "1",
+			body:       `{"campaign_id":"winback-01","coupon_code":"SAVE10"}`,
+			svc:        &fakeAbandonedCheckoutService{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing campaign",
+			checkoutID: "1",
+			body:       `{"coupon_code":"SAVE10"}`,
+			svc:        &fakeAbandonedCheckoutService{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "checkout not found",
+			checkoutID: "999",
+			body:       `{"campaign_id":"winback-01"}`,
+			svc:        &fakeAbandonedCheckoutService{recoverErr: domain.ErrAbandonedCheckoutNotFound},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+			handler.SetAbandonedCheckoutService(tt.svc)
+
+			router := setupTestRouter()
+			router.POST("/abandoned-checkouts/:id/recovery", handler.StartRecoveryCampaign)
+
+			req := httptest.NewRequest("POST", "/abandoned-checkouts/"+tt.checkoutID+"/recovery", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("StartRecoveryCampaign() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_DownloadReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		svc        *fakeReportService
+		wantStatus int
+	}{
+		{
+			name:       "valid signature",
+			svc:        &fakeReportService{content: "section,summary\n"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "expired url",
+			svc:        &fakeReportService{openErr: storage.ErrURLExpired},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing report",
+			svc:        &fakeReportService{openErr: service.ErrReportNotFound},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+			handler.SetReportService(tt.svc)
+
+			router := setupTestRouter()
+			router.GET("/reports/download", handler.DownloadReport)
+
+			req := httptest.NewRequest("GET", "/reports/download?key=reports/spike_events/1.csv&expires=1&sig=x", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("DownloadReport() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="spike_event_1.csv"` {
+					t.Errorf("DownloadReport() Content-Disposition = %q", got)
+				}
+				if w.Body.String() != tt.svc.content {
+					t.Errorf("DownloadReport() body = %q, want %q", w.Body.String(), tt.svc.content)
+				}
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_ParticipateSpike(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      int64
+		requestBody interface{}
+		mockFunc    func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error)
+		wantStatus  int
+		wantSuccess bool
+	}{
+		{
+			name:   "successful participation",
+			userID: 123,
+			requestBody: map[string]interface{}{
+				"spike_event_id":  1,
+				"quantity":        1,
+				"idempotency_key": "test_key_1",
+			},
+			mockFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+				return &domain.SpikeParticipationResponse{
+					Success: true,
+					Message: "秒杀成功",
+				}, nil
+			},
+			wantStatus:  http.StatusOK,
+			wantSuccess: true,
+		},
+		{
+			name:   "sold out",
+			userID: 123,
+			requestBody: map[string]interface{}{
+				"spike_event_id":  1,
+				"quantity":        1,
+				"idempotency_key": "test_key_2",
+			},
+			mockFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+				return &domain.SpikeParticipationResponse{
+					Success: false,
+					Message: "商品已售罄",
+				}, nil
+			},
+			wantStatus:  http.StatusOK,
+			wantSuccess: false,
+		},
+		{
+			name:   "service error",
+			userID: 123,
+			requestBody: map[string]interface{}{
+				"spike_event_id":  1,
+				"quantity":        1,
+				"idempotency_key": "test_key_4",
+			},
+			mockFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+				return nil, errors.New("redis down")
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "invalid request body",
+			userID: 123,
+			requestBody: map[string]interface{}{
+				"invalid_field": "invalid_value",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "unauthorized user",
+			userID: 0,
+			requestBody: map[string]interface{}{
+				"spike_event_id":  1,
+				"quantity":        1,
+				"idempotency_key": "test_key_3",
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSpikeService{
+				participateFunc: tt.mockFunc,
+			}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.POST("/participate", func(c *gin.Context) {
+				// 模拟用户认证中间件
+				if tt.userID > 0 {
+					c.Set("user_id", tt.userID)
+				}
+				handler.ParticipateSpike(c)
+			})
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest("POST", "/participate", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ParticipateSpike() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var response map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Errorf("ParticipateSpike() failed to parse response: %v", err)
+				}
+
+				if data, ok := response["data"].(map[string]interface{}); ok {
+					if success, ok := data["success"].(bool); ok && success != tt.wantSuccess {
+						t.Errorf("ParticipateSpike() success = %v, want %v", success, tt.wantSuccess)
+					}
+				}
+			}
+
+			// 处理器直接返回的失败同样携带重试提示：参数错误不可重试，内部错误可重试
+			if tt.wantStatus == http.StatusBadRequest || tt.wantStatus == http.StatusInternalServerError {
+				var response struct {
+					Data *domain.SpikeParticipationResponse `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.Data == nil {
+					t.Fatalf("ParticipateSpike() failure payload missing: %s", w.Body.String())
+				}
+				if wantRetryable := tt.wantStatus == http.StatusInternalServerError; response.Data.Retryable != wantRetryable {
+					t.Errorf("ParticipateSpike() retryable = %v, want %v", response.Data.Retryable, wantRetryable)
+				}
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_ParticipateSpike_FieldErrors(t *testing.T) {
+	handler := NewSpikeHandler(&MockSpikeService{}, zap.NewNop())
+	router := setupTestRouter()
+	router.POST("/participate", func(c *gin.Context) {
+		c.Set("user_id", int64(123))
+		handler.ParticipateSpike(c)
+	})
+
+	body := `{"spike_event_id": 0, "quantity": 11, "challenge_token": "` + strings.Repeat("x", 129) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/participate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("ParticipateSpike() status = %d, want 400", w.Code)
+	}
+	var response struct {
+		Data struct {
+			domain.SpikeParticipationResponse
+			Errors []resp.FieldError `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("ParticipateSpike() failed to parse response: %v", err)
+	}
+	if response.Data.Code != domain.SpikeParticipationCodeInvalidRequest || response.Data.Retryable {
+		t.Errorf("ParticipateSpike() failure = %+v, want non-retryable invalid_request", response.Data.SpikeParticipationResponse)
+	}
+
+	want := map[string]string{
+		"spike_event_id":  "不能为空",
+		"quantity":        "不能大于 10",
+		"idempotency_key": "不能为空",
+		"challenge_token": "长度不能大于 128",
+	}
+	got := make(map[string]string, len(response.Data.Errors))
+	for _, e := range response.Data.Errors {
+		got[e.Field] = e.Message
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParticipateSpike() field errors = %v, want %v", got, want)
+	}
+}
+
+func TestSpikeHandler_ParticipateSpike_UserTier(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     string
+		bodyTier string
+		wantTier domain.UserTier
+	}{
+		{name: "tier from token", tier: "gold", wantTier: domain.UserTierGold},
+		{name: "missing tier defaults to regular", wantTier: domain.UserTierRegular},
+		{name: "client supplied tier ignored", tier: "silver", bodyTier: "platinum", wantTier: domain.UserTierSilver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTier domain.UserTier
+			mockService := &MockSpikeService{
+				participateFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+					gotTier = req.UserTier
+					return &domain.SpikeParticipationResponse{Success: true}, nil
+				},
+			}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.POST("/participate", func(c *gin.Context) {
+				c.Set("user_id", int64(123))
+				if tt.tier != "" {
+					c.Set("user_tier", tt.tier)
+				}
+				handler.ParticipateSpike(c)
+			})
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"spike_event_id":  1,
+				"quantity":        1,
+				"idempotency_key": "tier_key",
+				"user_tier":       tt.bodyTier,
+			})
+			req := httptest.NewRequest("POST", "/participate", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("ParticipateSpike() status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if gotTier != tt.wantTier {
+				t.Errorf("ParticipateSpike() tier = %q, want %q", gotTier, tt.wantTier)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_ParticipateSpike_TokenHeader(t *testing.T) {
+	var gotToken string
+	mockService := &MockSpikeService{
+		participateFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+			gotToken = req.ParticipationToken
+			return &domain.SpikeParticipationResponse{Success: true}, nil
+		},
+	}
+	handler := NewSpikeHandler(mockService, zap.NewNop())
+
+	router := setupTestRouter()
+	router.POST("/participate", func(c *gin.Context) {
+		c.Set("user_id", int64(123))
+		handler.ParticipateSpike(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"spike_event_id":  1,
+		"quantity":        1,
+		"idempotency_key": "token_key",
+	})
+	req := httptest.NewRequest("POST", "/participate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderParticipationToken, "1.123.1700000000.sig")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("ParticipateSpike() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if gotToken != "1.123.1700000000.sig" {
+		t.Errorf("ParticipateSpike() token = %q, want header value", gotToken)
+	}
+}
+
+func TestSpikeHandler_ParticipateSpike_ClientMetadata(t *testing.T) {
+	var got *domain.SpikeParticipationRequest
+	mockService := &MockSpikeService{
+		participateFunc: func(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+			got = req
+			return &domain.SpikeParticipationResponse{Success: true}, nil
+		},
+	}
+	handler := NewSpikeHandler(mockService, zap.NewNop())
+
+	router := setupTestRouter()
+	router.POST("/participate", func(c *gin.Context) {
+		c.Set("user_id", int64(123))
+		handler.ParticipateSpike(c)
+	})
+
+	// 请求体中的 client_ip 被忽略，以连接地址为准
+	body, _ := json.Marshal(map[string]interface{}{
+		"spike_event_id":  1,
+		"quantity":        1,
+		"idempotency_key": "client_key",
+		"client_ip":       "1.1.1.1",
+	})
+	req := httptest.NewRequest("POST", "/participate", bytes.NewBuffer(body))
+	req.RemoteAddr = "10.0.0.8:52000"
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", strings.Repeat("a", domain.MaxUserAgentLength+10))
+	req.Header.Set(HeaderClientChannel, "app")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("ParticipateSpike() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got.Channel != domain.SpikeOrderChannelApp || got.ClientIP != "10.0.0.8" || len(got.UserAgent) != domain.MaxUserAgentLength {
+		t.Errorf("ParticipateSpike() client = %q %q %d", got.Channel, got.ClientIP, len(got.UserAgent))
+	}
+}
+
+func TestSpikeHandler_IssueParticipationToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventID    string
+		userID     int64
+		err        error
+		wantStatus int
+	}{
+		{name: "issued", eventID: "1", userID: 123, wantStatus: http.StatusOK},
+		{name: "invalid event id", eventID: "abc", userID: 123, wantStatus: http.StatusBadRequest},
+		{name: "unauthenticated", eventID: "1", wantStatus: http.StatusUnauthorized},
+		{name: "event not found", eventID: "1", userID: 123, err: domain.ErrSpikeEventNotFound, wantStatus: http.StatusNotFound},
+		{name: "token flow disabled", eventID: "1", userID: 123, err: service.ErrParticipationTokenDisabled, wantStatus: http.StatusBadRequest},
+		{name: "exhausted", eventID: "1", userID: 123, err: service.ErrParticipationTokenExhausted, wantStatus: http.StatusConflict},
+		{name: "rate limited", eventID: "1", userID: 123, err: service.ErrParticipationTokenRateLimited, wantStatus: http.StatusTooManyRequests},
+		{name: "internal error", eventID: "1", userID: 123, err: fmt.Errorf("redis down"), wantStatus: http.StatusInternalServerError},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &MockSpikeService{
-				participateFunc: tt.mockFunc,
+				issueTokenFunc: func(ctx context.Context, eventID, userID int64) (*domain.ParticipationToken, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.ParticipationToken{SpikeEventID: eventID, Token: "t"}, nil
+				},
 			}
 			handler := NewSpikeHandler(mockService, zap.NewNop())
 
 			router := setupTestRouter()
-			router.POST("/participate", func(c *gin.Context) {
-				// 模拟用户认证中间件
-				if tt.userID > 0 {
+			router.POST("/events/:id/participation-token", func(c *gin.Context) {
+				if tt.userID != 0 {
 					c.Set("user_id", tt.userID)
 				}
-				handler.ParticipateSpike(c)
+				handler.IssueParticipationToken(c)
 			})
 
-			body, _ := json.Marshal(tt.requestBody)
-			req := httptest.NewRequest("POST", "/participate", bytes.NewBuffer(body))
-			req.Header.Set("Content-Type", "application/json")
+			req := httptest.NewRequest("POST", "/events/"+tt.eventID+"/participation-token", nil)
 			w := httptest.NewRecorder()
-
 			router.ServeHTTP(w, req)
 
 			if w.Code != tt.wantStatus {
-				t.Errorf("ParticipateSpike() status = %d, want %d", w.Code, tt.wantStatus)
+				t.Errorf("IssueParticipationToken() status = %d, want %d", w.Code, tt.wantStatus)
 			}
+		})
+	}
+}
 
-			if tt.wantStatus == http.StatusOK {
-				var response map[string]interface{}
-				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-					t.Errorf("ParticipateSpike() failed to parse response: %v", err)
+func TestSpikeHandler_IssueSpikeChallenge(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		userID     int64
+		err        error
+		wantStatus int
+	}{
+		{name: "issued", query: "spike_event_id=1", userID: 123, wantStatus: http.StatusOK},
+		{name: "missing event id", userID: 123, wantStatus: http.StatusBadRequest},
+		{name: "unauthenticated", query: "spike_event_id=1", wantStatus: http.StatusUnauthorized},
+		{name: "challenge disabled", query: "spike_event_id=1", userID: 123, err: service.ErrChallengeDisabled, wantStatus: http.StatusBadRequest},
+		{name: "event finished", query: "spike_event_id=1", userID: 123, err: service.ErrChallengeNotIssuable, wantStatus: http.StatusConflict},
+		{name: "rate limited", query: "spike_event_id=1", userID: 123, err: service.ErrChallengeRateLimited, wantStatus: http.StatusTooManyRequests},
+		{name: "internal error", query: "spike_event_id=1", userID: 123, err: fmt.Errorf("redis down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSpikeService{
+				issueChallengeFunc: func(ctx context.Context, eventID, userID int64) (*domain.SpikeChallenge, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.SpikeChallenge{ChallengeID: "c", SpikeEventID: eventID}, nil
+				},
+			}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.GET("/captcha", func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("user_id", tt.userID)
 				}
+				handler.IssueSpikeChallenge(c)
+			})
 
-				if data, ok := response["data"].(map[string]interface{}); ok {
-					if success, ok := data["success"].(bool); ok && success != tt.wantSuccess {
-						t.Errorf("ParticipateSpike() success = %v, want %v", success, tt.wantSuccess)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/captcha?"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("IssueSpikeChallenge() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_GetQueueStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int64
+		err        error
+		wantStatus int
+	}{
+		{name: "found", userID: 123, wantStatus: http.StatusOK},
+		{name: "unauthenticated", wantStatus: http.StatusUnauthorized},
+		{name: "not found", userID: 123, err: service.ErrQueueTicketNotFound, wantStatus: http.StatusNotFound},
+		{name: "internal error", userID: 123, err: fmt.Errorf("redis down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSpikeService{
+				queueStatusFunc: func(ctx context.Context, token string, userID int64) (*domain.SpikeQueueStatus, error) {
+					if tt.err != nil {
+						return nil, tt.err
 					}
+					return &domain.SpikeQueueStatus{QueueToken: token, Admitted: true}, nil
+				},
+			}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.GET("/queue/:token", func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("user_id", tt.userID)
 				}
+				handler.GetQueueStatus(c)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/queue/1.abc", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetQueueStatus() status = %d, want %d", w.Code, tt.wantStatus)
 			}
 		})
 	}
@@ -291,6 +1438,14 @@ func TestSpikeHandler_GetSpikeEventDetail(t *testing.T) {
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name:    "repository failure",
+			eventID: "1",
+			mockFunc: func(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
 	}
 
 	for _, tt := range tests {
@@ -480,6 +1635,12 @@ func TestSpikeHandler_GetUserSpikeOrders(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "invalid status filter",
+			userID:     123,
+			query:      "?status=shipped",
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -510,6 +1671,39 @@ func TestSpikeHandler_GetUserSpikeOrders(t *testing.T) {
 	}
 }
 
+func TestSpikeHandler_GetUserSpikeOrders_WithEvent(t *testing.T) {
+	mockService := &MockSpikeService{
+		getUserOrdersFunc: func(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error) {
+			t.Error("GetUserSpikeOrders() called for with_event=true")
+			return nil, errors.New("unexpected call")
+		},
+	}
+	handler := NewSpikeHandler(mockService, zap.NewNop())
+
+	router := setupTestRouter()
+	router.GET("/orders", func(c *gin.Context) {
+		c.Set("user_id", int64(123))
+		handler.GetUserSpikeOrders(c)
+	})
+
+	req := httptest.NewRequest("GET", "/orders?with_event=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body struct {
+		Data domain.SpikeOrderWithEventListResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Data.Orders) != 1 || body.Data.Orders[0].SpikeEvent == nil || body.Data.Orders[0].SpikeEvent.Name != "Test Event 1" {
+		t.Errorf("orders = %+v, want one order with event summary", body.Data.Orders)
+	}
+}
+
 func TestSpikeHandler_CancelSpikeOrder(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -553,7 +1747,19 @@ func TestSpikeHandler_CancelSpikeOrder(t *testing.T) {
 			mockFunc: func(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error {
 				return domain.ErrSpikeOrderNotFound
 			},
-			wantStatus: http.StatusInternalServerError,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "not owner",
+			userID:  456,
+			orderID: "1",
+			requestBody: map[string]interface{}{
+				"reason": "test",
+			},
+			mockFunc: func(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error {
+				return domain.ErrForbidden
+			},
+			wantStatus: http.StatusForbidden,
 		},
 		{
 			name:    "unauthorized user",
@@ -596,19 +1802,194 @@ func TestSpikeHandler_CancelSpikeOrder(t *testing.T) {
 	}
 }
 
+func TestSpikeHandler_GetSpikeOrderTimeline(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int64
+		userRole   string
+		orderID    string
+		mockFunc   func(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error)
+		wantStatus int
+	}{
+		{
+			name:       "owner views timeline",
+			userID:     123,
+			orderID:    "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "admin views other user's timeline",
+			userID:   1,
+			userRole: "admin",
+			orderID:  "1",
+			mockFunc: func(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error) {
+				if !isAdmin {
+					t.Errorf("GetSpikeOrderTimeline() isAdmin = false, want true")
+				}
+				return &domain.SpikeOrderTimeline{SpikeOrderID: orderID, Events: []*domain.OrderEvent{}}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "not owner",
+			userID:  456,
+			orderID: "1",
+			mockFunc: func(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error) {
+				return nil, domain.ErrForbidden
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:    "order not found",
+			userID:  123,
+			orderID: "1",
+			mockFunc: func(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error) {
+				return nil, domain.ErrSpikeOrderNotFound
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "repository failure",
+			userID:  123,
+			orderID: "1",
+			mockFunc: func(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error) {
+				return nil, errors.New("failed to get order events: connection refused")
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "invalid order ID",
+			userID:     123,
+			orderID:    "invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unauthenticated user",
+			userID:     0,
+			orderID:    "1",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSpikeService{
+				getOrderTimelineFunc: tt.mockFunc,
+			}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.GET("/orders/:id/timeline", func(c *gin.Context) {
+				// 模拟用户认证中间件
+				if tt.userID > 0 {
+					c.Set("user_id", tt.userID)
+				}
+				if tt.userRole != "" {
+					c.Set("user_role", tt.userRole)
+				}
+				handler.GetSpikeOrderTimeline(c)
+			})
+
+			req := httptest.NewRequest("GET", "/orders/"+tt.orderID+"/timeline", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetSpikeOrderTimeline() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpikeHandler_ExtendSpikeOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int64
+		orderID    string
+		mockFunc   func(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error)
+		wantStatus int
+	}{
+		{
+			name:       "successful extension",
+			userID:     123,
+			orderID:    "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "already extended",
+			userID:  123,
+			orderID: "1",
+			mockFunc: func(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error) {
+				return nil, domain.ErrSpikeOrderAlreadyExtended
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:    "not owner",
+			userID:  456,
+			orderID: "1",
+			mockFunc: func(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error) {
+				return nil, domain.ErrForbidden
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid order ID",
+			userID:     123,
+			orderID:    "invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unauthenticated user",
+			userID:     0,
+			orderID:    "1",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockSpikeService{
+				extendOrderFunc: tt.mockFunc,
+			}
+			handler := NewSpikeHandler(mockService, zap.NewNop())
+
+			router := setupTestRouter()
+			router.POST("/orders/:id/extend", func(c *gin.Context) {
+				// 模拟用户认证中间件
+				if tt.userID > 0 {
+					c.Set("user_id", tt.userID)
+				}
+				handler.ExtendSpikeOrder(c)
+			})
+
+			req := httptest.NewRequest("POST", "/orders/"+tt.orderID+"/extend", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ExtendSpikeOrder() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
 func TestSpikeHandler_WarmupStock(t *testing.T) {
 	tests := []struct {
 		name       string
 		userRole   string
 		eventID    string
-		mockFunc   func(ctx context.Context, eventID int64) error
+		query      string
+		mockFunc   func(ctx context.Context, eventID int64, force bool) error
 		wantStatus int
 	}{
 		{
 			name:     "admin user",
 			userRole: "admin",
 			eventID:  "1",
-			mockFunc: func(ctx context.Context, eventID int64) error {
+			mockFunc: func(ctx context.Context, eventID int64, force bool) error {
 				return nil
 			},
 			wantStatus: http.StatusOK,
@@ -629,11 +2010,40 @@ func TestSpikeHandler_WarmupStock(t *testing.T) {
 			name:     "warmup failed",
 			userRole: "admin",
 			eventID:  "1",
-			mockFunc: func(ctx context.Context, eventID int64) error {
+			mockFunc: func(ctx context.Context, eventID int64, force bool) error {
 				return domain.ErrSpikeEventNotFound
 			},
 			wantStatus: http.StatusInternalServerError,
 		},
+		{
+			name:     "already warmed active event",
+			userRole: "admin",
+			eventID:  "1",
+			mockFunc: func(ctx context.Context, eventID int64, force bool) error {
+				return domain.ErrSpikeStockAlreadyWarmed
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:     "force warmup",
+			userRole: "admin",
+			eventID:  "1",
+			query:    "?force=true",
+			mockFunc: func(ctx context.Context, eventID int64, force bool) error {
+				if !force {
+					return domain.ErrSpikeStockAlreadyWarmed
+				}
+				return nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid force",
+			userRole:   "admin",
+			eventID:    "1",
+			query:      "?force=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -654,7 +2064,7 @@ func TestSpikeHandler_WarmupStock(t *testing.T) {
 				handler.WarmupStock(c)
 			})
 
-			req := httptest.NewRequest("POST", "/admin/events/"+tt.eventID+"/warmup", nil)
+			req := httptest.NewRequest("POST", "/admin/events/"+tt.eventID+"/warmup"+tt.query, nil)
 			w := httptest.NewRecorder()
 
 			router.ServeHTTP(w, req)
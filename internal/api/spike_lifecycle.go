@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetLifecycleService 设置活动状态管理服务，未设置时暂停与恢复接口返回 503
+func (h *SpikeHandler) SetLifecycleService(lifecycleService service.SpikeEventLifecycleService) {
+	h.lifecycleService = lifecycleService
+}
+
+// PauseSpikeEvent 暂停秒杀活动（管理员接口）
+// @Summary 暂停秒杀活动
+// @Description 暂停未结束的待开始或进行中活动，暂停期间参与请求被拒绝，到开始时间也不会自动激活；到结束时间仍会自动结束
+// @Tags 秒杀管理
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param request body domain.SpikeEventStatusChangeRequest false "操作原因"
+// @Success 200 {object} resp.Response[domain.SpikeEvent] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动当前状态不允许暂停"
+// @Router /api/v1/admin/spike/events/{id}/pause [post]
+// @Security Bearer
+func (h *SpikeHandler) PauseSpikeEvent(c *gin.Context) {
+	h.changeEventStatus(c, "活动已暂停", func(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error) {
+		return h.lifecycleService.PauseEvent(ctx, eventID, req, operatorID)
+	})
+}
+
+// ResumeSpikeEvent 恢复秒杀活动（管理员接口）
+// @Summary 恢复秒杀活动
+// @Description 恢复已暂停且未到结束时间的活动：未到开始时间恢复为待开始，否则恢复为进行中
+// @Tags 秒杀管理
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param request body domain.SpikeEventStatusChangeRequest false "操作原因"
+// @Success 200 {object} resp.Response[domain.SpikeEvent] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动当前状态不允许恢复"
+// @Router /api/v1/admin/spike/events/{id}/resume [post]
+// @Security Bearer
+func (h *SpikeHandler) ResumeSpikeEvent(c *gin.Context) {
+	h.changeEventStatus(c, "活动已恢复", func(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error) {
+		return h.lifecycleService.ResumeEvent(ctx, eventID, req, operatorID)
+	})
+}
+
+// changeEventStatus 解析参数并执行暂停或恢复，请求体可省略
+func (h *SpikeHandler) changeEventStatus(c *gin.Context, successMessage string,
+	change func(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error)) {
+	if h.lifecycleService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"活动状态管理未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	if !h.isAdmin(c) {
+		resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
+			"权限不足", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.SpikeEventStatusChangeRequest
+	if !bindOptionalJSON(c, &req, h.logger) {
+		return
+	}
+
+	middleware.SetAuditEntity(c, domain.AdminAuditEntitySpikeEvent, eventID)
+	if middleware.AuditEnabled(c) {
+		if before, err := h.lifecycleService.GetEvent(c.Request.Context(), eventID); err == nil {
+			middleware.SetAuditBefore(c, before)
+		}
+	}
+
+	event, err := change(c.Request.Context(), eventID, &req, h.getCurrentUserID(c))
+	if err != nil {
+		if !writeDomainError(c, err) {
+			h.logger.Error("变更活动状态失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"变更活动状态失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+	middleware.SetAuditAfter(c, event)
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, successMessage, event,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// HeaderPaymentSignature 支付回调签名请求头
+const HeaderPaymentSignature = "X-Payment-Signature"
+
+// maxWebhookBodyBytes 支付回调请求体上限
+const maxWebhookBodyBytes = 64 << 10
+
+// SetPaymentService 设置订单支付服务，未设置时发起支付与支付回调接口返回 503
+func (h *SpikeHandler) SetPaymentService(paymentService service.SpikePaymentService) {
+	h.paymentService = paymentService
+}
+
+// SetPaymentSandbox 设置沙箱支付渠道，未设置时模拟支付接口返回 404；
+// 只应在开启 PAYMENT_SANDBOX_AUTHORIZE_ENABLED 的开发与联调环境设置
+func (h *SpikeHandler) SetPaymentSandbox(sandbox *payment.Sandbox) {
+	h.paymentSandbox = sandbox
+}
+
+// CreatePaymentIntent 发起订单支付
+// @Summary 发起订单支付
+// @Description 为未过期的待支付订单创建支付意图；用户在支付渠道完成授权后，渠道回调触发扣款，扣款成功后订单变为已支付
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "订单ID"
+// @Success 201 {object} resp.Response[payment.Intent] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Failure 409 {object} resp.Response[any] "订单当前状态不允许支付"
+// @Failure 503 {object} resp.Response[any] "支付未启用"
+// @Router /api/v1/spike/orders/{id}/payment-intent [post]
+// @Security Bearer
+func (h *SpikeHandler) CreatePaymentIntent(c *gin.Context) {
+	if !h.requirePaymentService(c) {
+		return
+	}
+
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的订单ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	intent, err := h.paymentService.CreatePaymentIntent(c.Request.Context(), orderID, userID, h.isAdmin(c))
+	if err != nil {
+		h.logger.Warn("发起订单支付失败",
+			zap.Int64("order_id", orderID),
+			logger.UserID(userID),
+			zap.Error(err))
+
+		if h.writeOrderAccessError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrSpikeOrderNotPayable) {
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"发起支付失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusCreated, resp.CodeOK, "success", intent,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// PaySpikeOrder 支付订单
+// @Summary 支付订单
+// @Description 凭客户端在支付渠道取得的支付凭证一步完成扣款，适用于支持一步扣款的渠道；
+// @Description 扣款成功后订单异步变为已支付。渠道不支持一步扣款时使用发起订单支付接口
+// @Tags 秒杀
+// @Accept json
+// @Produce json
+// @Param id path int true "订单ID"
+// @Param request body domain.PaySpikeOrderRequest true "支付请求"
+// @Success 200 {object} resp.Response[payment.Intent] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误或不支持的支付方式"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 402 {object} resp.Response[payment.Intent] "支付被拒绝"
+// @Failure 404 {object} resp.Response[any] "订单不存在"
+// @Failure 409 {object} resp.Response[any] "订单当前状态不允许支付"
+// @Failure 503 {object} resp.Response[any] "支付未启用"
+// @Router /api/v1/spike/orders/{id}/pay [post]
+// @Security Bearer
+func (h *SpikeHandler) PaySpikeOrder(c *gin.Context) {
+	if !h.requirePaymentService(c) {
+		return
+	}
+
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的订单ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.PaySpikeOrderRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	intent, err := h.paymentService.PayOrder(c.Request.Context(), orderID, userID, h.isAdmin(c), &req)
+	if err != nil {
+		h.logger.Warn("支付订单失败",
+			zap.Int64("order_id", orderID),
+			logger.UserID(userID),
+			zap.Error(err))
+
+		if h.writeOrderAccessError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrSpikeOrderPaymentDeclined):
+			resp.WriteJSON(c.Writer, http.StatusPaymentRequired, resp.CodeInvalidParam, err.Error(), intent,
+				h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, domain.ErrPaymentMethodUnsupported), errors.Is(err, payment.ErrInvalidToken):
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, domain.ErrSpikeOrderNotPayable):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		default:
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"支付失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", intent,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// HandlePaymentWebhook 支付渠道回调
+// @Summary 支付渠道回调
+// @Description 由支付渠道调用，请求体为回调事件，X-Payment-Signature 为请求体签名；处理失败返回 5xx 由渠道重试
+// @Tags 支付
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "回调签名"
+// @Success 200 {object} resp.Response[any] "成功"
+// @Failure 401 {object} resp.Response[any] "签名无效"
+// @Failure 503 {object} resp.Response[any] "支付未启用"
+// @Router /api/v1/payments/webhook [post]
+func (h *SpikeHandler) HandlePaymentWebhook(c *gin.Context) {
+	if !h.requirePaymentService(c) {
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"回调请求体无效", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 签名无效返回 401；其它失败返回 500，由渠道重试
+	if err := h.paymentService.HandleWebhook(c.Request.Context(), payload, c.GetHeader(HeaderPaymentSignature)); err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			h.logger.Warn("支付回调签名无效", zap.String("client_ip", c.ClientIP()))
+			resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+				"回调签名无效", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		h.logger.Error("处理支付回调失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"处理支付回调失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON[any](c.Writer, http.StatusOK, resp.CodeOK, "success", nil,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// AuthorizeSandboxPayment 模拟完成支付
+// @Summary 模拟完成支付（沙箱）
+// @Description 仅在开启 PAYMENT_SANDBOX_AUTHORIZE_ENABLED 时可用：模拟用户在支付渠道完成授权，并把签名回调投递给回调处理流程。
+// @Description 只能授权自己订单的支付意图（管理员不受限制）。金额以 .01 结尾时授权被拒绝，以 .02 结尾时扣款失败，其它金额支付成功
+// @Tags 支付
+// @Produce json
+// @Param id path string true "支付意图ID"
+// @Success 200 {object} resp.Response[payment.WebhookEvent] "成功"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 404 {object} resp.Response[any] "支付意图不存在或未开启沙箱模拟支付"
+// @Failure 409 {object} resp.Response[any] "支付意图已授权"
+// @Router /api/v1/payments/sandbox/intents/{id}/authorize [post]
+// @Security Bearer
+func (h *SpikeHandler) AuthorizeSandboxPayment(c *gin.Context) {
+	if h.paymentSandbox == nil {
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"未开启沙箱模拟支付", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	if !h.requirePaymentService(c) {
+		return
+	}
+
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	// 沙箱只允许创建意图时的付款用户授权，管理员可代为模拟
+	payerID := userID
+	if h.isAdmin(c) {
+		payerID = 0
+	}
+
+	event, err := h.paymentSandbox.Authorize(c.Request.Context(), c.Param("id"), payerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, payment.ErrIntentNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"支付意图不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, payment.ErrInvalidState):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				"支付意图已授权", h.getRequestID(c), h.getTraceID(c))
+		default:
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"模拟支付失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	payload, signature, err := h.paymentSandbox.SignWebhook(event)
+	if err != nil {
+		h.logger.Error("签名沙箱支付回调失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"模拟支付失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	if err := h.paymentService.HandleWebhook(c.Request.Context(), payload, signature); err != nil {
+		h.logger.Error("处理沙箱支付回调失败", zap.String("intent_id", event.Intent.ID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"处理支付回调失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", event,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// requirePaymentService 订单支付服务未启用时写入 503 响应
+func (h *SpikeHandler) requirePaymentService(c *gin.Context) bool {
+	if h.paymentService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"支付未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
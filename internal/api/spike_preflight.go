@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// SpikePreflightRunner 执行活动上线检查（由 service.SpikePreflight 实现）
+type SpikePreflightRunner interface {
+	Run(ctx context.Context, eventID, expectedQPS int64) (*domain.PreflightReport, error)
+}
+
+// SetPreflight 设置活动上线检查，未设置时上线检查接口返回 503
+func (h *SpikeHandler) SetPreflight(preflight SpikePreflightRunner) {
+	h.preflight = preflight
+}
+
+// RunPreflight 活动上线检查（管理员接口）
+// @Summary 活动上线检查
+// @Description 自动执行上线前检查：活动时间、库存预热、活动缓存、限流配置与预期 QPS、消息消费者、死信队列、数据库连接池余量；
+// @Description 返回逐项 pass/fail/skip 清单，任一项 fail 时 passed 为 false
+// @Tags 秒杀管理
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param request body domain.PreflightRequest false "预期流量"
+// @Success 200 {object} resp.Response[domain.PreflightReport] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 503 {object} resp.Response[any] "上线检查未启用"
+// @Router /api/v1/admin/spike/events/{id}/preflight [post]
+// @Security Bearer
+func (h *SpikeHandler) RunPreflight(c *gin.Context) {
+	if h.preflight == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"上线检查未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.PreflightRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req, h.logger) {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), statusProbeTimeout)
+	defer cancel()
+
+	report, err := h.preflight.Run(ctx, eventID, req.ExpectedQPS)
+	if err != nil {
+		if errors.Is(err, domain.ErrSpikeEventNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		h.logger.Error("活动上线检查失败", zap.Int64("event_id", eventID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"上线检查失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", report,
+		h.getRequestID(c), h.getTraceID(c))
+}
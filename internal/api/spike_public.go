@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetPublicCatalog 设置匿名只读活动服务，未设置时匿名接口返回 503
+func (h *SpikeHandler) SetPublicCatalog(catalog service.PublicSpikeCatalog) {
+	h.publicCatalog = catalog
+}
+
+// ListPublicEvents 匿名获取活跃秒杀活动列表
+// @Summary 匿名获取秒杀活动列表
+// @Description 面向营销页的只读接口，无需登录；按 IP 严格限流，只读缓存，每页固定 20 条且不统计总数，以 has_more 判断是否还有下一页，最多 10 页
+// @Tags 秒杀
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Success 200 {object} resp.Response[domain.PublicSpikeEventListResponse] "成功"
+// @Failure 429 {object} resp.Response[any] "请求过于频繁"
+// @Failure 500 {object} resp.Response[any] "服务器内部错误"
+// @Router /api/v1/public/spike/events [get]
+func (h *SpikeHandler) ListPublicEvents(c *gin.Context) {
+	if h.publicCatalog == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeUnavailable,
+			"匿名活动接口未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	events, err := h.publicCatalog.ListEvents(c.Request.Context(), page)
+	if err != nil {
+		h.logger.Error("匿名获取秒杀活动列表失败", zap.Int("page", page), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"获取活动列表失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", events,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetPublicEvent 匿名获取秒杀活动详情
+// @Summary 匿名获取秒杀活动详情
+// @Description 面向营销页的只读接口，无需登录；按 IP 严格限流，只读缓存，库存只返回档位（0-10）与售罄标记
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Success 200 {object} resp.Response[domain.PublicSpikeEventDetail] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 429 {object} resp.Response[any] "请求过于频繁"
+// @Router /api/v1/public/spike/events/{id} [get]
+func (h *SpikeHandler) GetPublicEvent(c *gin.Context) {
+	if h.publicCatalog == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeUnavailable,
+			"匿名活动接口未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	event, err := h.publicCatalog.GetEvent(c.Request.Context(), eventID)
+	if err != nil {
+		h.logger.Warn("匿名获取秒杀活动详情失败", zap.Int64("event_id", eventID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", event,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetPublishingService 设置草稿活动排期与审批服务，未设置时相关接口返回 503
+func (h *SpikeHandler) SetPublishingService(publishingService service.SpikeEventPublishingService) {
+	h.publishingService = publishingService
+}
+
+// ListDraftSpikeEvents 列出草稿与待发布活动（管理员接口）
+// @Summary 草稿与待发布活动列表
+// @Description 列出尚未发布、只对管理员可见的活动，待发布活动附带发布计划（发布时间、排期人与审批人）
+// @Tags 秒杀管理
+// @Produce json
+// @Param status query string false "状态过滤" Enums(draft, scheduled)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页大小" default(20)
+// @Param cursor query string false "分页游标，取上一页的 next_cursor，设置后忽略 page"
+// @Success 200 {object} resp.Response[domain.SpikeEventDraftListResponse] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Router /api/v1/admin/spike/events/drafts [get]
+// @Security Bearer
+func (h *SpikeHandler) ListDraftSpikeEvents(c *gin.Context) {
+	if !h.checkPublishingAccess(c) {
+		return
+	}
+
+	req := &domain.SpikeEventListRequest{Page: 1, PageSize: 20}
+	if pageStr := c.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			req.Page = page
+		}
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil && pageSize > 0 && pageSize <= 100 {
+			req.PageSize = pageSize
+		}
+	}
+	if v := c.Query("status"); v != "" {
+		status, err := domain.ParseSpikeEventStatus(v)
+		if err != nil {
+			resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "status", Message: err.Error()}},
+				h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		req.Status = &status
+	}
+	cursor, err := parseCursor(c.Query("cursor"), true)
+	if err != nil {
+		resp.InvalidFields(c.Writer, []resp.FieldError{{Field: "cursor", Message: err.Error()}},
+			h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+	req.After = cursor
+
+	drafts, err := h.publishingService.ListDrafts(c.Request.Context(), req)
+	if err != nil {
+		if !writeDomainError(c, err) {
+			h.logger.Error("获取草稿活动列表失败", zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"获取草稿活动列表失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.OK(c.Writer, drafts, h.getRequestID(c), h.getTraceID(c))
+}
+
+// ScheduleSpikeEvent 为草稿活动设定发布时间（管理员接口）
+// @Summary 排期草稿活动
+// @Description 为草稿设定发布时间，活动进入待发布状态；须由另一名管理员审批后，才会在发布时间自动发布为待开始。
+// @Description 发布时间需早于活动结束时间，已过去的时间表示审批后立即发布
+// @Tags 秒杀管理
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param request body domain.ScheduleSpikeEventRequest true "发布时间"
+// @Success 200 {object} resp.Response[domain.SpikeEventDraft] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动不是草稿"
+// @Router /api/v1/admin/spike/events/{id}/schedule [post]
+// @Security Bearer
+func (h *SpikeHandler) ScheduleSpikeEvent(c *gin.Context) {
+	var req domain.ScheduleSpikeEventRequest
+	h.changePublication(c, "活动已排期，等待审批", &req, func(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error) {
+		return h.publishingService.Schedule(ctx, eventID, &req, operatorID)
+	})
+}
+
+// ApproveSpikeEvent 审批待发布活动（管理员接口）
+// @Summary 审批活动发布计划
+// @Description 审批待发布活动的发布计划，审批人不能是设定发布时间的管理员；审批后活动在发布时间自动发布
+// @Tags 秒杀管理
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Success 200 {object} resp.Response[domain.SpikeEventDraft] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动不是待发布、已审批或审批人与排期人相同"
+// @Router /api/v1/admin/spike/events/{id}/approve [post]
+// @Security Bearer
+func (h *SpikeHandler) ApproveSpikeEvent(c *gin.Context) {
+	h.changePublication(c, "发布计划已审批", nil, func(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error) {
+		return h.publishingService.Approve(ctx, eventID, operatorID)
+	})
+}
+
+// UnscheduleSpikeEvent 撤回待发布活动的发布计划（管理员接口）
+// @Summary 撤回活动发布计划
+// @Description 撤回待发布活动的发布计划（含已审批的），活动回到草稿状态
+// @Tags 秒杀管理
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Success 200 {object} resp.Response[domain.SpikeEventDraft] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动不是待发布"
+// @Router /api/v1/admin/spike/events/{id}/unschedule [post]
+// @Security Bearer
+func (h *SpikeHandler) UnscheduleSpikeEvent(c *gin.Context) {
+	h.changePublication(c, "发布计划已撤回", nil, func(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error) {
+		return h.publishingService.Unschedule(ctx, eventID, operatorID)
+	})
+}
+
+// checkPublishingAccess 检查服务已启用且当前用户为管理员，不满足时写出错误响应并返回 false
+func (h *SpikeHandler) checkPublishingAccess(c *gin.Context) bool {
+	if h.publishingService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"活动排期未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	if !h.isAdmin(c) {
+		resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
+			"权限不足", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
+
+// changePublication 解析参数并执行排期、审批或撤回；req 不为空时绑定请求体
+func (h *SpikeHandler) changePublication(c *gin.Context, successMessage string, req any,
+	change func(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error)) {
+	if !h.checkPublishingAccess(c) {
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	if req != nil && !bindJSON(c, req, h.logger) {
+		return
+	}
+
+	middleware.SetAuditEntity(c, domain.AdminAuditEntitySpikeEvent, eventID)
+	draft, err := change(c.Request.Context(), eventID, h.getCurrentUserID(c))
+	if err != nil {
+		if !writeDomainError(c, err) {
+			h.logger.Error("变更活动发布计划失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"变更活动发布计划失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+	middleware.SetAuditAfter(c, draft)
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, successMessage, draft,
+		h.getRequestID(c), h.getTraceID(c))
+}
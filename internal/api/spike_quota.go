@@ -0,0 +1,203 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetQuotaService 设置每日配额管理服务，未设置时配额接口返回 503
+func (h *SpikeHandler) SetQuotaService(quotaService service.SpikeQuotaService) {
+	h.quotaService = quotaService
+}
+
+// GetSpikeQuotaRules 获取每日配额规则
+// @Summary 获取每日配额规则
+// @Description 返回全体用户的默认每日秒杀成功次数上限与按用户覆盖的上限，max_daily_wins 为 0 表示不限制
+// @Tags 管理员
+// @Produce json
+// @Success 200 {object} resp.Response{data=domain.SpikeQuotaRules}
+// @Router /api/v1/admin/spike/quotas [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeQuotaRules(c *gin.Context) {
+	if !h.requireQuotaService(c) {
+		return
+	}
+
+	rules, err := h.quotaService.GetRules(c.Request.Context())
+	if err != nil {
+		h.writeQuotaError(c, err, "获取配额规则失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", rules,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// SetSpikeQuotaDefault 设置默认每日配额
+// @Summary 设置默认每日配额
+// @Description 设置全体用户每个自然日最多秒杀成功次数（跨所有活动），0 表示不限制；本实例立即生效，其他实例 30 秒内生效
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param request body domain.SetSpikeUserQuotaRequest true "每日上限"
+// @Success 200 {object} resp.Response{data=domain.SpikeQuotaRules}
+// @Failure 400 {object} resp.Response
+// @Router /api/v1/admin/spike/quotas/default [put]
+// @Security Bearer
+func (h *SpikeHandler) SetSpikeQuotaDefault(c *gin.Context) {
+	if !h.requireQuotaService(c) {
+		return
+	}
+
+	var req domain.SetSpikeUserQuotaRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	rules, err := h.quotaService.SetDefault(c.Request.Context(), &req)
+	if err != nil {
+		h.writeQuotaError(c, err, "设置默认配额失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", rules,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetSpikeUserQuota 获取用户当日配额使用情况
+// @Summary 获取用户当日配额使用情况
+// @Description 返回用户生效的每日上限、是否为单独设置的规则以及当日已使用次数，remaining 为 -1 表示不限制
+// @Tags 管理员
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} resp.Response{data=domain.SpikeUserQuotaUsage}
+// @Failure 400 {object} resp.Response
+// @Router /api/v1/admin/spike/quotas/users/{id} [get]
+// @Security Bearer
+func (h *SpikeHandler) GetSpikeUserQuota(c *gin.Context) {
+	if !h.requireQuotaService(c) {
+		return
+	}
+
+	userID, ok := h.parseQuotaUserID(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.quotaService.GetUserUsage(c.Request.Context(), userID)
+	if err != nil {
+		h.writeQuotaError(c, err, "获取用户配额失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", usage,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// SetSpikeUserQuota 为用户单独设置每日配额
+// @Summary 为用户单独设置每日配额
+// @Description 覆盖该用户的默认每日上限，0 表示该用户不限制
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body domain.SetSpikeUserQuotaRequest true "每日上限"
+// @Success 200 {object} resp.Response{data=domain.SpikeUserQuotaUsage}
+// @Failure 400 {object} resp.Response
+// @Router /api/v1/admin/spike/quotas/users/{id} [put]
+// @Security Bearer
+func (h *SpikeHandler) SetSpikeUserQuota(c *gin.Context) {
+	if !h.requireQuotaService(c) {
+		return
+	}
+
+	userID, ok := h.parseQuotaUserID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetSpikeUserQuotaRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	usage, err := h.quotaService.SetUserQuota(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.writeQuotaError(c, err, "设置用户配额失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", usage,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// DeleteSpikeUserQuota 删除用户单独设置的每日配额
+// @Summary 删除用户单独设置的每日配额
+// @Description 删除后该用户恢复使用默认每日上限，当日已使用次数保留
+// @Tags 管理员
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Router /api/v1/admin/spike/quotas/users/{id} [delete]
+// @Security Bearer
+func (h *SpikeHandler) DeleteSpikeUserQuota(c *gin.Context) {
+	if !h.requireQuotaService(c) {
+		return
+	}
+
+	userID, ok := h.parseQuotaUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.quotaService.DeleteUserQuota(c.Request.Context(), userID); err != nil {
+		h.writeQuotaError(c, err, "删除用户配额失败")
+		return
+	}
+
+	resp.WriteJSON[any](c.Writer, http.StatusOK, resp.CodeOK, "success", nil,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// requireQuotaService 配额管理服务未启用时返回 503
+func (h *SpikeHandler) requireQuotaService(c *gin.Context) bool {
+	if h.quotaService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"每日配额服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
+
+// parseQuotaUserID 解析路径中的用户ID，无效时写入 400 响应
+func (h *SpikeHandler) parseQuotaUserID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的用户ID", h.getRequestID(c), h.getTraceID(c))
+		return 0, false
+	}
+	return userID, true
+}
+
+// writeQuotaError 将配额服务错误映射为响应
+func (h *SpikeHandler) writeQuotaError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrSpikeUserQuotaNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"用户没有单独设置的配额", h.getRequestID(c), h.getTraceID(c))
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			msg, h.getRequestID(c), h.getTraceID(c))
+	}
+}
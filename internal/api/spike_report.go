@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+	"github.com/MorseWayne/spike_shop/internal/storage"
+)
+
+// SetReportService 设置活动报表服务，未设置时报表接口返回 503
+func (h *SpikeHandler) SetReportService(reportService service.SpikeReportService) {
+	h.reportService = reportService
+}
+
+// GetSpikeReport 获取秒杀活动报表
+// @Summary 获取活动报表下载地址
+// @Description 已生成时返回带签名的下载地址；未生成时触发异步生成并返回 202，客户端可轮询本接口
+// @Tags 管理员
+// @Produce json
+// @Param id path int true "活动ID"
+// @Param regenerate query bool false "忽略已有报表重新生成"
+// @Success 200 {object} resp.Response{data=domain.SpikeReport}
+// @Success 202 {object} resp.Response{data=domain.SpikeReport}
+// @Failure 400 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Failure 409 {object} resp.Response
+// @Failure 503 {object} resp.Response "后台任务队列已满"
+// @Router /api/v1/admin/spike/events/{id}/report [get]
+func (h *SpikeHandler) GetSpikeReport(c *gin.Context) {
+	if h.reportService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"报表服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	// 解析活动ID
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	regenerate, _ := strconv.ParseBool(c.Query("regenerate"))
+
+	report, err := h.reportService.RequestReport(c.Request.Context(), eventID, regenerate)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSpikeEventNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrSpikeEventNotFinished):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				"活动尚未结束，无法生成报表", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, domain.ErrAdminJobQueueFull):
+			h.writeAdminJobSubmitError(c, err)
+		default:
+			h.logger.Error("获取活动报表失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"获取活动报表失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if report.Status != domain.SpikeReportStatusReady {
+		status = http.StatusAccepted
+	}
+	resp.WriteJSON(c.Writer, status, resp.CodeOK, "success", report,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// DownloadReport 通过签名地址下载报表文件
+// @Summary 下载报表
+// @Description 校验下载地址签名与有效期后返回 CSV 文件，无需登录
+// @Tags 报表
+// @Produce text/csv
+// @Param key query string true "文件key"
+// @Param expires query int true "过期时间戳"
+// @Param sig query string true "签名"
+// @Success 200 {file} file
+// @Failure 403 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Router /api/v1/reports/download [get]
+func (h *SpikeHandler) DownloadReport(c *gin.Context) {
+	if h.reportService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"报表服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	key := c.Query("key")
+	rc, err := h.reportService.OpenReport(c.Request.Context(), key, c.Query("expires"), c.Query("sig"))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvalidSignature), errors.Is(err, storage.ErrURLExpired):
+			resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
+				"下载地址无效或已过期", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrReportNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"报表不存在", h.getRequestID(c), h.getTraceID(c))
+		default:
+			h.logger.Error("打开报表失败", zap.String("key", key), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"下载报表失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+	defer rc.Close()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+reportFilename(key)+`"`)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, rc); err != nil {
+		h.logger.Warn("写出报表失败", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// reportFilename 由存储 key 生成下载文件名，如 reports/spike_events/7.csv -> spike_event_7.csv
+func reportFilename(key string) string {
+	return "spike_event_" + path.Base(key)
+}
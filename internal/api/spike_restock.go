@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetRestockService 设置活动补货服务，未设置时补货接口返回 503
+func (h *SpikeHandler) SetRestockService(restockService service.SpikeRestockService) {
+	h.restockService = restockService
+}
+
+// TopUpStock 活动进行中补充库存（管理员接口）
+// @Summary 补充活动库存
+// @Description 原子性增加活动总库存与 Redis 剩余库存，清除售罄标记并广播补货通知；remaining_stock 为 -1 表示库存尚未预热
+// @Tags 秒杀管理
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param request body domain.SpikeStockTopUpRequest true "补货请求"
+// @Success 200 {object} resp.Response[domain.SpikeStockTopUpResult] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动已结束或库存正在调整"
+// @Router /api/v1/admin/spike/events/{id}/stock [post]
+// @Security Bearer
+func (h *SpikeHandler) TopUpStock(c *gin.Context) {
+	if h.restockService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"补货服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	if !h.isAdmin(c) {
+		resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam,
+			"权限不足", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.SpikeStockTopUpRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	middleware.SetAuditEntity(c, domain.AdminAuditEntitySpikeEvent, eventID)
+	result, err := h.restockService.TopUpStock(c.Request.Context(), eventID, &req, h.getCurrentUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSpikeEventNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, domain.ErrSpikeEventNotRestockable), errors.Is(err, domain.ErrSpikeStockBusy):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		default:
+			h.logger.Error("补充活动库存失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"补充库存失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	middleware.SetAuditBefore(c, stockBeforeTopUp(result))
+	middleware.SetAuditAfter(c, result)
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "库存已补充", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// stockBeforeTopUp 由补货结果推算补货前的库存，用于审计记录
+func stockBeforeTopUp(result *domain.SpikeStockTopUpResult) *domain.SpikeStockTopUpResult {
+	before := &domain.SpikeStockTopUpResult{
+		SpikeEventID:   result.SpikeEventID,
+		SpikeStock:     result.SpikeStock - result.Delta,
+		RemainingStock: result.RemainingStock,
+	}
+	// 库存尚未预热时保持 -1
+	if result.RemainingStock >= 0 {
+		before.RemainingStock = result.RemainingStock - result.Delta
+	}
+	return before
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetSettlementService 设置活动结算服务，未设置时结算接口返回 503
+func (h *SpikeHandler) SetSettlementService(settlementService service.SpikeSettlementService) {
+	h.settlementService = settlementService
+}
+
+// ListSettlements 查询活动结算
+// @Summary 查询活动结算
+// @Description 分页查询活动按结算日汇总的收入、退款、手续费与应打款金额
+// @Tags 管理员
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页大小(1-100)"
+// @Param include_total query bool false "是否统计总数，为 false 时 total 返回 -1" default(true)
+// @Param spike_event_id query int false "活动ID"
+// @Param status query string false "打款状态(pending/paid_out)"
+// @Param from query string false "结算日起(YYYY-MM-DD)"
+// @Param to query string false "结算日止(YYYY-MM-DD，不含)"
+// @Success 200 {object} resp.Response{data=domain.SpikeSettlementListResponse}
+// @Failure 400 {object} resp.Response
+// @Router /api/v1/admin/spike/settlements [get]
+// @Security Bearer
+func (h *SpikeHandler) ListSettlements(c *gin.Context) {
+	if !h.requireSettlementService(c) {
+		return
+	}
+
+	req, fields := parseSettlementListRequest(c)
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	result, err := h.settlementService.ListSettlements(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("查询结算失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"查询结算失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", result,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// MarkSettlementPaidOut 标记结算已打款
+// @Summary 标记结算已打款
+// @Description 将待打款的结算标记为已打款并记录打款流水号，已打款的结算不再被结算任务覆盖
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param id path int true "结算ID"
+// @Param request body domain.MarkSettlementPaidOutRequest false "打款信息"
+// @Success 200 {object} resp.Response{data=domain.SpikeSettlement}
+// @Failure 400 {object} resp.Response
+// @Failure 404 {object} resp.Response
+// @Failure 409 {object} resp.Response
+// @Router /api/v1/admin/spike/settlements/{id}/payout [post]
+// @Security Bearer
+func (h *SpikeHandler) MarkSettlementPaidOut(c *gin.Context) {
+	if !h.requireSettlementService(c) {
+		return
+	}
+
+	settlementID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || settlementID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的结算ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.MarkSettlementPaidOutRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req, h.logger) {
+			return
+		}
+	}
+
+	settlement, err := h.settlementService.MarkPaidOut(c.Request.Context(), settlementID, h.getCurrentUserID(c), req.PayoutReference)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSettlementNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"结算记录不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, domain.ErrSettlementAlreadyPaidOut):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		default:
+			h.logger.Error("标记结算打款失败", zap.Int64("settlement_id", settlementID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"标记结算打款失败", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", settlement,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// requireSettlementService 结算服务未启用时写入 503 响应
+func (h *SpikeHandler) requireSettlementService(c *gin.Context) bool {
+	if h.settlementService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"活动结算服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
+
+// parseSettlementListRequest 解析结算查询参数，参数错误时返回各字段的错误
+func parseSettlementListRequest(c *gin.Context) (*domain.SpikeSettlementListRequest, []resp.FieldError) {
+	req := &domain.SpikeSettlementListRequest{
+		Page:     1,
+		PageSize: 20,
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
+		req.PageSize = pageSize
+	}
+	req.SkipTotal = skipTotal(c.Query("include_total"))
+
+	var fields []resp.FieldError
+	if v := c.Query("spike_event_id"); v != "" {
+		eventID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || eventID <= 0 {
+			fields = append(fields, resp.FieldError{Field: "spike_event_id", Message: "无效的活动ID"})
+		} else {
+			req.SpikeEventID = &eventID
+		}
+	}
+	if v := c.Query("status"); v != "" {
+		status, err := domain.ParseSpikeSettlementStatus(v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "status", Message: err.Error()})
+		} else {
+			req.Status = &status
+		}
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "from", Message: "from 必须为 YYYY-MM-DD 日期"})
+		} else {
+			req.From = &from
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "to", Message: "to 必须为 YYYY-MM-DD 日期"})
+		} else {
+			req.To = &to
+		}
+	}
+
+	return req, fields
+}
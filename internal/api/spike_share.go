@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// resolveShareLinkRequest 分享链接到达请求
+type resolveShareLinkRequest struct {
+	Token string `json:"token" binding:"required,max=256"`
+}
+
+// SetShareService 设置活动分享链接服务，未设置时分享接口返回 503
+func (h *SpikeHandler) SetShareService(shareService service.SpikeShareService) {
+	h.shareService = shareService
+}
+
+// CreateShareLink 生成活动分享链接
+// @Summary 生成活动分享链接
+// @Description 生成带 HMAC 签名的活动分享链接（活动ID + 推广渠道 + 过期时间），有效期不超过活动结束时间
+// @Tags 秒杀
+// @Accept json
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param request body domain.CreateShareLinkRequest false "推广渠道"
+// @Success 200 {object} resp.Response[domain.SpikeShareLink] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "活动已结束"
+// @Router /api/v1/spike/events/{id}/share-links [post]
+// @Security Bearer
+func (h *SpikeHandler) CreateShareLink(c *gin.Context) {
+	if !h.requireShareService(c) {
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var req domain.CreateShareLinkRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req, h.logger) {
+			return
+		}
+	}
+
+	link, err := h.shareService.CreateLink(c.Request.Context(), eventID, req.Campaign)
+	if err != nil {
+		h.writeShareError(c, err, "生成分享链接失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", link,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// ResolveShareLink 分享链接到达
+// @Summary 分享链接到达
+// @Description 校验分享令牌并记录一次到达，返回活动ID与推广渠道；参与秒杀时将令牌作为 share_token 传入即可计入参与归因
+// @Tags 秒杀
+// @Accept json
+// @Produce json
+// @Param request body resolveShareLinkRequest true "分享令牌"
+// @Success 200 {object} resp.Response[domain.SpikeShareVisit] "成功"
+// @Failure 400 {object} resp.Response[any] "分享链接无效"
+// @Failure 410 {object} resp.Response[any] "分享链接已过期"
+// @Router /api/v1/spike/share-links/visits [post]
+func (h *SpikeHandler) ResolveShareLink(c *gin.Context) {
+	if !h.requireShareService(c) {
+		return
+	}
+
+	var req resolveShareLinkRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	visit, err := h.shareService.ResolveLink(c.Request.Context(), req.Token)
+	if err != nil {
+		h.writeShareError(c, err, "校验分享链接失败")
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", visit,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// GetShareAttribution 获取活动分享归因统计（管理员接口）
+// @Summary 获取活动分享归因
+// @Description 按推广渠道返回经分享链接到达的访问数与成功参与数
+// @Tags 秒杀管理
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Success 200 {object} resp.Response[[]domain.SpikeShareAttribution] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Router /api/v1/admin/spike/events/{id}/share-attribution [get]
+// @Security Bearer
+func (h *SpikeHandler) GetShareAttribution(c *gin.Context) {
+	if !h.requireShareService(c) {
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	attributions, err := h.shareService.ListAttribution(c.Request.Context(), eventID)
+	if err != nil {
+		h.writeShareError(c, err, "获取分享归因失败")
+		return
+	}
+	if attributions == nil {
+		attributions = []*domain.SpikeShareAttribution{}
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", &attributions,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// requireShareService 分享服务未启用时写入 503 响应
+func (h *SpikeHandler) requireShareService(c *gin.Context) bool {
+	if h.shareService == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"分享链接服务未启用", h.getRequestID(c), h.getTraceID(c))
+		return false
+	}
+	return true
+}
+
+// writeShareError 将分享服务错误映射为响应
+func (h *SpikeHandler) writeShareError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrSpikeEventNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+			"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+	case errors.Is(err, domain.ErrShareCampaignInvalid), errors.Is(err, domain.ErrShareLinkInvalid):
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+	case errors.Is(err, domain.ErrShareLinkExpired):
+		resp.Error(c.Writer, http.StatusGone, resp.CodeInvalidParam,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+	case errors.Is(err, service.ErrShareEventFinished):
+		resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+			err.Error(), h.getRequestID(c), h.getTraceID(c))
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			msg, h.getRequestID(c), h.getTraceID(c))
+	}
+}
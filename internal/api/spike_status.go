@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// statusProbeTimeout 单次状态快照中外部依赖查询的超时时间
+const statusProbeTimeout = 2 * time.Second
+
+// ConsumerStatsProvider 提供消费者统计信息（由 mq.SpikeConsumer 实现）
+type ConsumerStatsProvider interface {
+	GetConsumerStats() map[string]mq.ConsumerStats
+}
+
+// QueueInspector 提供队列深度查询（由 mq.SpikeQueueManager 实现）
+type QueueInspector interface {
+	GetQueueInfo(ctx context.Context, queueName string) (*mq.QueueInfo, error)
+}
+
+// InvariantStatsProvider 提供库存不变量违反计数（由 service.StockInvariantChecker 实现）
+type InvariantStatsProvider interface {
+	Violations() map[string]int64
+}
+
+// CleanupStatsProvider 提供活动资源清理统计（由 service.EventCleanupWorker 实现）
+type CleanupStatsProvider interface {
+	Stats() service.EventCleanupStats
+}
+
+// ArchiveStatsProvider 提供活动与订单归档统计（由 service.SpikeArchiver 实现）
+type ArchiveStatsProvider interface {
+	Stats() service.SpikeArchiveStats
+}
+
+// LimiterProbe 描述一个需要在状态快照中展示的限流器
+type LimiterProbe struct {
+	Name    string          // 展示名称，如 spike、api
+	Limiter limiter.Limiter // 限流器实例
+	Key     string          // 默认探测的限流Key，可被请求参数 limiter_key 覆盖
+}
+
+// StatusSources 系统状态快照的数据来源，各字段均可为空
+type StatusSources struct {
+	Consumers  ConsumerStatsProvider
+	Queues     QueueInspector
+	Limiters   []LimiterProbe
+	Invariants InvariantStatsProvider
+	Cleanup    CleanupStatsProvider
+	Archive    ArchiveStatsProvider
+}
+
+// SystemStatus 系统状态快照
+type SystemStatus struct {
+	Service             string                      `json:"service"`
+	Timestamp           int64                       `json:"timestamp"`
+	Consumers           map[string]mq.ConsumerStats `json:"consumers,omitempty"`
+	DLQ                 *mq.QueueInfo               `json:"dlq,omitempty"`
+	Limiters            map[string]*LimiterStatus   `json:"limiters,omitempty"`
+	InvariantViolations map[string]int64            `json:"invariant_violations,omitempty"` // 各库存不变量的违反次数，非零即需告警
+	Cleanup             *service.EventCleanupStats  `json:"cleanup,omitempty"`              // 已结束活动的资源清理统计
+	Archive             *service.SpikeArchiveStats  `json:"archive,omitempty"`              // 活动与订单归档统计
+	Errors              []string                    `json:"errors,omitempty"`               // 采集过程中出现的非致命错误
+}
+
+// LimiterStatus 限流器当前状态
+type LimiterStatus struct {
+	Key         string        `json:"key,omitempty"`
+	Limit       int64         `json:"limit"`
+	Remaining   int64         `json:"remaining"`
+	Window      time.Duration `json:"window"`
+	ResetTime   time.Time     `json:"reset_time"`
+	Utilization float64       `json:"utilization"` // 已用配额占比，0~1
+}
+
+// SetStatusSources 设置系统状态快照的数据来源
+func (h *SpikeHandler) SetStatusSources(sources *StatusSources) {
+	h.statusSources = sources
+}
+
+// SystemStatus 获取系统状态快照
+// @Summary 获取系统状态快照
+// @Description 汇总消费者速率与重试次数、死信队列深度、限流器使用率及活动资源清理统计（管理员）
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Param limiter_key query string false "探测的限流Key，如 spike:user:42"
+// @Success 200 {object} resp.Response[SystemStatus] "成功"
+// @Failure 401 {object} resp.Response[any] "未授权"
+// @Failure 403 {object} resp.Response[any] "权限不足"
+// @Security BearerAuth
+// @Router /api/v1/admin/spike/status [get]
+func (h *SpikeHandler) SystemStatus(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), statusProbeTimeout)
+	defer cancel()
+
+	status := h.collectStatus(ctx, c.Query("limiter_key"))
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "ok", status,
+		h.getRequestID(c), h.getTraceID(c))
+}
+
+// collectStatus 采集系统状态，单项失败只记录错误，不影响其他项
+func (h *SpikeHandler) collectStatus(ctx context.Context, limiterKey string) *SystemStatus {
+	status := &SystemStatus{
+		Service:   "spike-service",
+		Timestamp: time.Now().Unix(),
+	}
+
+	sources := h.statusSources
+	if sources == nil {
+		return status
+	}
+
+	if sources.Consumers != nil {
+		status.Consumers = sources.Consumers.GetConsumerStats()
+	}
+
+	if sources.Queues != nil {
+		info, err := sources.Queues.GetQueueInfo(ctx, mq.SpikeDLXQueue)
+		if err != nil {
+			h.logger.Warn("获取死信队列信息失败", zap.Error(err))
+			status.Errors = append(status.Errors, "dlq: "+err.Error())
+		} else {
+			status.DLQ = info
+		}
+	}
+
+	if len(sources.Limiters) > 0 {
+		status.Limiters = make(map[string]*LimiterStatus, len(sources.Limiters))
+		for _, probe := range sources.Limiters {
+			if probe.Limiter == nil {
+				continue
+			}
+
+			key := probe.Key
+			if limiterKey != "" {
+				key = limiterKey
+			}
+
+			info, err := probe.Limiter.GetInfo(ctx, key)
+			if err != nil {
+				h.logger.Warn("获取限流器信息失败",
+					zap.String("limiter", probe.Name),
+					zap.Error(err))
+				status.Errors = append(status.Errors, "limiter "+probe.Name+": "+err.Error())
+				continue
+			}
+
+			status.Limiters[probe.Name] = newLimiterStatus(key, info)
+		}
+	}
+
+	if sources.Invariants != nil {
+		status.InvariantViolations = sources.Invariants.Violations()
+	}
+
+	if sources.Cleanup != nil {
+		stats := sources.Cleanup.Stats()
+		status.Cleanup = &stats
+	}
+
+	if sources.Archive != nil {
+		stats := sources.Archive.Stats()
+		status.Archive = &stats
+	}
+
+	return status
+}
+
+// newLimiterStatus 根据限流信息计算使用率
+func newLimiterStatus(key string, info *limiter.LimitInfo) *LimiterStatus {
+	ls := &LimiterStatus{
+		Key:       key,
+		Limit:     info.Limit,
+		Remaining: info.Remaining,
+		Window:    info.Window,
+		ResetTime: info.ResetTime,
+	}
+
+	if info.Limit > 0 {
+		used := info.Limit - info.Remaining
+		if used < 0 {
+			used = 0
+		}
+		ls.Utilization = float64(used) / float64(info.Limit)
+	}
+
+	return ls
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// StockReconcileRunner 执行库存对账（由 service.StockReconciler 实现）
+type StockReconcileRunner interface {
+	RunOnce(ctx context.Context, repair bool) (*service.StockReconcileReport, error)
+	ReconcileEvent(ctx context.Context, eventID int64, repair bool) (*service.StockReconcileReport, error)
+}
+
+// SetStockReconciler 设置库存对账器，未设置时对账接口返回 503
+func (h *SpikeHandler) SetStockReconciler(reconciler StockReconcileRunner) {
+	h.stockReconciler = reconciler
+}
+
+// ReconcileStock 立即执行库存对账（管理员接口）
+// @Summary 库存对账
+// @Description 比对 Redis 剩余库存、活动已售数量与待支付、已支付订单的购买数量，返回发现的偏差；
+// @Description 指定 event_id 时只对账该活动，否则对账所有进行中的活动。repair=true 时冻结有偏差的活动，
+// @Description 等待在途消息落库后以订单为准修复仍然存在的偏差，修复期间该活动暂停参与
+// @Tags 秒杀管理
+// @Produce json
+// @Param event_id query int false "秒杀活动ID"
+// @Param repair query bool false "是否修复偏差"
+// @Success 200 {object} resp.Response[service.StockReconcileReport] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 503 {object} resp.Response[any] "库存对账未启用"
+// @Router /api/v1/admin/spike/stock/reconcile [post]
+// @Security Bearer
+func (h *SpikeHandler) ReconcileStock(c *gin.Context) {
+	if h.stockReconciler == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"库存对账未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var fields []resp.FieldError
+	var eventID int64
+	if raw := c.Query("event_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			fields = append(fields, resp.FieldError{Field: "event_id", Message: "必须为正整数"})
+		}
+		eventID = id
+	}
+	var repair bool
+	if raw := c.Query("repair"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			fields = append(fields, resp.FieldError{Field: "repair", Message: "必须为 true 或 false"})
+		}
+		repair = v
+	}
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	var report *service.StockReconcileReport
+	var err error
+	if eventID > 0 {
+		report, err = h.stockReconciler.ReconcileEvent(c.Request.Context(), eventID, repair)
+	} else {
+		report, err = h.stockReconciler.RunOnce(c.Request.Context(), repair)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrSpikeEventNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		h.logger.Error("库存对账失败", zap.Int64("event_id", eventID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"库存对账失败", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	h.logger.Info("管理员执行库存对账",
+		zap.Int64("operator_id", h.getCurrentUserID(c)),
+		zap.Int64("event_id", eventID),
+		zap.Bool("repair", repair),
+		zap.Int("discrepancies", len(report.Discrepancies)))
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", report,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// SetStockWatcher 设置库存长轮询，未设置时长轮询接口返回 503
+func (h *SpikeHandler) SetStockWatcher(watcher *service.StockWatcher) {
+	h.stockWatcher = watcher
+}
+
+// WaitStockStatus 长轮询活动库存状态
+// @Summary 长轮询库存状态
+// @Description 挂起请求直到库存档位（每档 10%）或售罄状态变化，超时返回当前状态（changed 为 false）；可携带上次看到的 bucket 与 sold_out，不一致时立即返回
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Param timeout query string false "等待时长，如 25s，最长 30s" default(25s)
+// @Param bucket query int false "上次看到的库存档位"
+// @Param sold_out query bool false "上次看到的售罄状态"
+// @Success 200 {object} resp.Response[domain.SpikeStockStatus] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Router /api/v1/spike/events/{id}/stock/wait [get]
+func (h *SpikeHandler) WaitStockStatus(c *gin.Context) {
+	if h.stockWatcher == nil {
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeInternalError,
+			"库存长轮询未启用", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	timeout := service.DefaultStockWaitTimeout
+	if v := c.Query("timeout"); v != "" {
+		timeout, err = time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				"无效的等待时长", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+	}
+
+	// 客户端上次看到的状态，只有同时携带 bucket 与 sold_out 时生效
+	var since *domain.SpikeStockStatus
+	if bucketParam, soldOutParam := c.Query("bucket"), c.Query("sold_out"); bucketParam != "" && soldOutParam != "" {
+		bucket, bucketErr := strconv.Atoi(bucketParam)
+		soldOut, soldOutErr := strconv.ParseBool(soldOutParam)
+		if bucketErr != nil || soldOutErr != nil || bucket < 0 || bucket > domain.SpikeStockBuckets {
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				"无效的库存状态", h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		since = &domain.SpikeStockStatus{Bucket: bucket, SoldOut: soldOut}
+	}
+
+	status, err := h.stockWatcher.Wait(c.Request.Context(), eventID, since, timeout)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSpikeEventNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, context.Canceled):
+			// 客户端已断开，无需响应
+		default:
+			h.logger.Error("等待库存变化失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"系统繁忙，请稍后重试", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", status,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// HeaderParticipationToken 参与秒杀时携带参与令牌的请求头
+const HeaderParticipationToken = "X-Participation-Token"
+
+// IssueParticipationToken 领取秒杀参与令牌
+// @Summary 领取参与令牌
+// @Description 预告期开始后领取活动参与令牌，开启令牌流程的活动开始后只处理携带有效令牌的参与请求；重复领取返回相同令牌
+// @Tags 秒杀
+// @Produce json
+// @Param id path int true "秒杀活动ID"
+// @Success 200 {object} resp.Response[domain.ParticipationToken] "成功"
+// @Failure 400 {object} resp.Response[any] "请求参数错误或活动未开启令牌流程"
+// @Failure 404 {object} resp.Response[any] "活动不存在"
+// @Failure 409 {object} resp.Response[any] "不在发放时间内或名额已发完"
+// @Failure 429 {object} resp.Response[any] "领取过于频繁"
+// @Security BearerAuth
+// @Router /api/v1/spike/events/{id}/participation-token [post]
+func (h *SpikeHandler) IssueParticipationToken(c *gin.Context) {
+	eventID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || eventID <= 0 {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"无效的活动ID", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	token, err := h.spikeService.IssueParticipationToken(c.Request.Context(), eventID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSpikeEventNotFound):
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				"秒杀活动不存在", h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrParticipationTokenDisabled):
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrParticipationTokenNotIssuable),
+			errors.Is(err, service.ErrParticipationTokenExhausted):
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		case errors.Is(err, service.ErrParticipationTokenRateLimited):
+			resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+		default:
+			h.logger.Error("发放参与令牌失败", zap.Int64("event_id", eventID), zap.Error(err))
+			resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+				"系统繁忙，请稍后重试", h.getRequestID(c), h.getTraceID(c))
+		}
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", token,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+	"github.com/MorseWayne/spike_shop/internal/service"
+)
+
+// GetQueueStatus 查询等候室排队状态
+// @Summary 查询排队状态
+// @Description 排队模式的活动参与请求返回 code 为 queued 及 queue_token，客户端据此轮询排队位置；
+// @Description admitted 为 true 后以新的幂等键携带 queue_token 再次参与秒杀
+// @Tags 秒杀
+// @Produce json
+// @Param token path string true "排队令牌"
+// @Success 200 {object} resp.Response[domain.SpikeQueueStatus] "成功"
+// @Failure 401 {object} resp.Response[any] "用户未登录"
+// @Failure 404 {object} resp.Response[any] "排队令牌不存在或已过期"
+// @Security BearerAuth
+// @Router /api/v1/spike/queue/{token} [get]
+func (h *SpikeHandler) GetQueueStatus(c *gin.Context) {
+	userID := h.getCurrentUserID(c)
+	if userID == 0 {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam,
+			"用户未登录", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	status, err := h.spikeService.GetQueueStatus(c.Request.Context(), c.Param("token"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrQueueTicketNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam,
+				err.Error(), h.getRequestID(c), h.getTraceID(c))
+			return
+		}
+		h.logger.Error("查询排队状态失败", zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError,
+			"系统繁忙，请稍后重试", h.getRequestID(c), h.getTraceID(c))
+		return
+	}
+
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", status,
+		h.getRequestID(c), h.getTraceID(c))
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// TimeHandler 提供服务器时间，供客户端校准倒计时
+type TimeHandler struct {
+	startedAt time.Time // 进程启动时间，携带单调时钟读数
+}
+
+// NewTimeHandler 创建服务器时间处理器
+func NewTimeHandler() *TimeHandler {
+	return &TimeHandler{startedAt: time.Now()}
+}
+
+// ServerTimeResponse 服务器时间响应
+type ServerTimeResponse struct {
+	ServerTime time.Time `json:"server_time"`         // 服务器墙上时间
+	UnixMs     int64     `json:"unix_ms"`             // 服务器时间的毫秒时间戳
+	UptimeMs   int64     `json:"uptime_ms"`           // 基于单调时钟的进程运行时长，不受系统校时影响，可用于检测服务器时钟跳变
+	ClientTs   int64     `json:"client_ts,omitempty"` // 回显请求参数 client_ts，便于客户端结合往返时延计算时钟偏差
+}
+
+// GetServerTime 获取服务器时间
+// @Summary 获取服务器时间
+// @Description 返回服务器当前时间与单调时钟提示，客户端可用 offset = unix_ms - (client_ts + rtt/2) 校准本地倒计时
+// @Tags 系统
+// @Produce json
+// @Param client_ts query int false "客户端发起请求时的毫秒时间戳"
+// @Success 200 {object} resp.Response[ServerTimeResponse] "成功"
+// @Router /api/v1/time [get]
+func (h *TimeHandler) GetServerTime(c *gin.Context) {
+	now := time.Now()
+	data := &ServerTimeResponse{
+		ServerTime: now,
+		UnixMs:     now.UnixMilli(),
+		UptimeMs:   now.Sub(h.startedAt).Milliseconds(),
+	}
+	if clientTs, err := strconv.ParseInt(c.Query("client_ts"), 10, 64); err == nil && clientTs > 0 {
+		data.ClientTs = clientTs
+	}
+
+	// 时间响应不可被任何中间层缓存
+	c.Header("Cache-Control", "no-store")
+	resp.WriteJSON(c.Writer, http.StatusOK, resp.CodeOK, "success", data,
+		c.GetString("request_id"), c.GetString("trace_id"))
+}
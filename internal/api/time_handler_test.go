@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeHandler_GetServerTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewTimeHandler()
+
+	router := gin.New()
+	router.GET("/time", handler.GetServerTime)
+
+	before := time.Now().UnixMilli()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/time?client_ts=1700000000000", nil)
+	router.ServeHTTP(w, req)
+	after := time.Now().UnixMilli()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetServerTime() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("GetServerTime() Cache-Control = %q, want no-store", got)
+	}
+
+	var body struct {
+		Data ServerTimeResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if body.Data.UnixMs < before || body.Data.UnixMs > after {
+		t.Errorf("GetServerTime() unix_ms = %d, want within [%d, %d]", body.Data.UnixMs, before, after)
+	}
+	if body.Data.ClientTs != 1700000000000 {
+		t.Errorf("GetServerTime() client_ts = %d, want echoed value", body.Data.ClientTs)
+	}
+	if body.Data.UptimeMs < 0 {
+		t.Errorf("GetServerTime() uptime_ms = %d, want >= 0", body.Data.UptimeMs)
+	}
+}
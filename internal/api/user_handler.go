@@ -3,11 +3,13 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
+	"math"
 	"net/http"
 	"strconv"
 
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
@@ -34,21 +36,12 @@ func NewUserHandler(userService service.UserService, jwtService service.JWTServi
 
 // Register 处理用户注册请求
 // POST /api/v1/auth/register
-func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *UserHandler) Register(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateRegisterRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
@@ -57,12 +50,12 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// 根据不同的错误类型返回不同的HTTP状态码
 		if errors.Is(err, service.ErrUserExists) {
-			resp.Error(w, http.StatusConflict, resp.CodeInvalidParam, "username or email already exists", reqID, "")
+			resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam, "username or email already exists", reqID, traceID)
 			return
 		}
 
 		h.logger.Error("register failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "register failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "register failed", reqID, traceID)
 		return
 	}
 
@@ -76,44 +69,49 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		"created_at": user.CreatedAt,
 	}
 
-	resp.OK(w, &userResp, reqID, "")
+	resp.OK(c.Writer, &userResp, reqID, traceID)
 }
 
 // Login 处理用户登录请求
 // POST /api/v1/auth/login
-func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *UserHandler) Login(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 基本验证
-	if err := h.validateLoginRequest(&req); err != nil {
-		h.logger.Warn("validation failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, err.Error(), reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层进行登录
-	user, err := h.userService.Login(&req)
+	user, err := h.userService.Login(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
 		// 根据不同的错误类型返回不同的HTTP状态码
 		if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrInvalidCredentials) {
-			resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, "invalid username or password", reqID, "")
+			resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam, "invalid username or password", reqID, traceID)
+			return
+		}
+		var locked *service.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(locked.RetryAfter.Seconds())), 10))
+			resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam, "too many failed login attempts, try again later", reqID, traceID)
+			return
+		}
+		if errors.Is(err, service.ErrOTPRequired) {
+			resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam, "otp code required", reqID, traceID)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidOTP) {
+			resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam, "invalid otp code", reqID, traceID)
 			return
 		}
 		if errors.Is(err, service.ErrUserInactive) {
-			resp.Error(w, http.StatusForbidden, resp.CodeInvalidParam, "user is inactive", reqID, "")
+			resp.Error(c.Writer, http.StatusForbidden, resp.CodeInvalidParam, "user is inactive", reqID, traceID)
 			return
 		}
 
 		h.logger.Error("login failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "login failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "login failed", reqID, traceID)
 		return
 	}
 
@@ -121,7 +119,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	tokenPair, err := h.jwtService.GenerateTokenPair(user)
 	if err != nil {
 		h.logger.Error("failed to generate tokens", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "token generation failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "token generation failed", reqID, traceID)
 		return
 	}
 
@@ -139,20 +137,20 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		RefreshToken: tokenPair.RefreshToken,
 	}
 
-	resp.OK(w, &loginResp, reqID, "")
+	resp.OK(c.Writer, &loginResp, reqID, traceID)
 }
 
 // GetProfile 获取当前用户信息
 // GET /api/v1/users/profile
-// 需要认证：使用AuthMiddleware保护
-func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+// 需要认证：使用GinAuth保护
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 从JWT中获取当前用户信息
-	user := middleware.UserFromContext(r.Context())
+	user := middleware.UserFromContext(c.Request.Context())
 	if user == nil {
 		h.logger.Error("user not found in context", zap.String("request_id", reqID))
-		resp.Error(w, http.StatusUnauthorized, resp.CodeInternalError, "authentication required", reqID, "")
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInternalError, "authentication required", reqID, traceID)
 		return
 	}
 
@@ -160,12 +158,12 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	fullUser, err := h.userService.GetUserByID(user.ID)
 	if err != nil {
 		if errors.Is(err, service.ErrUserNotFound) {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, "")
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, traceID)
 			return
 		}
 
 		h.logger.Error("get profile failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "get profile failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "get profile failed", reqID, traceID)
 		return
 	}
 
@@ -180,19 +178,17 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		"updated_at": fullUser.UpdatedAt,
 	}
 
-	resp.OK(w, &userResp, reqID, "")
+	resp.OK(c.Writer, &userResp, reqID, traceID)
 }
 
 // RefreshToken 刷新访问令牌
 // POST /api/v1/auth/refresh
-func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析请求体
 	var req domain.RefreshTokenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
@@ -201,87 +197,115 @@ func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// 根据错误类型返回不同的响应
 		if errors.Is(err, service.ErrTokenExpired) {
-			resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, "refresh token expired", reqID, "")
+			resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam, "refresh token expired", reqID, traceID)
 			return
 		}
 		if errors.Is(err, service.ErrInvalidToken) {
-			resp.Error(w, http.StatusUnauthorized, resp.CodeInvalidParam, "invalid refresh token", reqID, "")
+			resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInvalidParam, "invalid refresh token", reqID, traceID)
 			return
 		}
 
 		h.logger.Error("refresh token failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "refresh token failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "refresh token failed", reqID, traceID)
 		return
 	}
 
 	// 返回新的令牌对
-	resp.OK(w, tokenPair, reqID, "")
+	resp.OK(c.Writer, tokenPair, reqID, traceID)
 }
 
-// validateRegisterRequest 验证注册请求
-func (h *UserHandler) validateRegisterRequest(req *domain.RegisterRequest) error {
-	if len(req.Username) < 3 || len(req.Username) > 32 {
-		return errors.New("username must be between 3 and 32 characters")
-	}
+// EnrollTOTP 申请绑定 TOTP 两步验证
+// POST /api/v1/users/2fa/totp/enroll
+// 需要认证；返回的密钥只展示一次，重复申请会替换尚未确认的密钥
+func (h *UserHandler) EnrollTOTP(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
-	if len(req.Password) < 6 || len(req.Password) > 72 {
-		return errors.New("password must be between 6 and 72 characters")
+	user := middleware.UserFromContext(c.Request.Context())
+	if user == nil {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInternalError, "authentication required", reqID, traceID)
+		return
 	}
 
-	if req.Email == "" {
-		return errors.New("email is required")
+	enrollment, err := h.userService.EnrollTOTP(c.Request.Context(), user.ID)
+	if err != nil {
+		h.writeTOTPError(c, "enroll totp", err)
+		return
 	}
 
-	// 简单的邮箱格式验证
-	if !isValidEmail(req.Email) {
-		return errors.New("invalid email format")
-	}
+	resp.OK(c.Writer, enrollment, reqID, traceID)
+}
+
+// EnableTOTP 提交验证器生成的验证码确认绑定，之后登录需要验证码
+// POST /api/v1/users/2fa/totp/enable
+func (h *UserHandler) EnableTOTP(c *gin.Context) {
+	h.changeTOTP(c, "enable totp", "two-factor authentication enabled", h.userService.EnableTOTP)
+}
 
-	return nil
+// DisableTOTP 提交当前验证码关闭两步验证
+// POST /api/v1/users/2fa/totp/disable
+func (h *UserHandler) DisableTOTP(c *gin.Context) {
+	h.changeTOTP(c, "disable totp", "two-factor authentication disabled", h.userService.DisableTOTP)
 }
 
-// validateLoginRequest 验证登录请求
-func (h *UserHandler) validateLoginRequest(req *domain.LoginRequest) error {
-	if req.Username == "" {
-		return errors.New("username is required")
+// changeTOTP 解析验证码并执行启用或关闭
+func (h *UserHandler) changeTOTP(c *gin.Context, op, successMessage string, change func(ctx context.Context, userID int64, code string) error) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	user := middleware.UserFromContext(c.Request.Context())
+	if user == nil {
+		resp.Error(c.Writer, http.StatusUnauthorized, resp.CodeInternalError, "authentication required", reqID, traceID)
+		return
 	}
 
-	if req.Password == "" {
-		return errors.New("password is required")
+	var req domain.TOTPCodeRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
 	}
 
-	return nil
-}
+	if err := change(c.Request.Context(), user.ID, req.Code); err != nil {
+		h.writeTOTPError(c, op, err)
+		return
+	}
 
-// isValidEmail 简单的邮箱格式验证
-func isValidEmail(email string) bool {
-	// 这是一个简化的邮箱验证，生产环境建议使用更严格的验证
-	return len(email) > 0 &&
-		len(email) <= 254 &&
-		containsChar(email, '@') &&
-		containsChar(email, '.')
+	result := map[string]interface{}{
+		"message": successMessage,
+	}
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
-// containsChar 检查字符串是否包含指定字符
-func containsChar(s string, c rune) bool {
-	for _, char := range s {
-		if char == c {
-			return true
-		}
+// writeTOTPError 将两步验证相关的业务错误映射为HTTP响应
+func (h *UserHandler) writeTOTPError(c *gin.Context, op string, err error) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	var locked *service.LoginLockedError
+	switch {
+	case errors.Is(err, service.ErrTOTPUnavailable):
+		resp.Error(c.Writer, http.StatusServiceUnavailable, resp.CodeUnavailable, "two-factor authentication is not available", reqID, traceID)
+	case errors.Is(err, service.ErrUserNotFound):
+		resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, traceID)
+	case errors.Is(err, service.ErrInvalidOTP):
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid otp code", reqID, traceID)
+	case errors.As(err, &locked):
+		c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(locked.RetryAfter.Seconds())), 10))
+		resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam, "too many failed attempts, try again later", reqID, traceID)
+	case errors.Is(err, service.ErrTOTPAlreadyEnabled), errors.Is(err, service.ErrTOTPNotEnrolled), errors.Is(err, service.ErrTOTPNotEnabled):
+		resp.Error(c.Writer, http.StatusConflict, resp.CodeInvalidParam, err.Error(), reqID, traceID)
+	default:
+		h.logger.Error(op+" failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, op+" failed", reqID, traceID)
 	}
-	return false
 }
 
 // ---- 管理员专用API处理器 ----
 
 // ListUsers 获取用户列表（管理员专用）
 // GET /api/v1/admin/users?page=1&page_size=20
-func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 解析查询参数
-	pageStr := r.URL.Query().Get("page")
-	pageSizeStr := r.URL.Query().Get("page_size")
+	pageStr := c.Query("page")
+	pageSizeStr := c.Query("page_size")
 
 	page := 1
 	pageSize := 20
@@ -302,54 +326,46 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	result, err := h.userService.ListUsers(page, pageSize)
 	if err != nil {
 		h.logger.Error("list users failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "list users failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "list users failed", reqID, traceID)
 		return
 	}
 
-	resp.OK(w, result, reqID, "")
+	resp.OK(c.Writer, result, reqID, traceID)
 }
 
 // UpdateUserRole 更新用户角色（管理员专用）
 // PUT /api/v1/admin/users/{user_id}/role
-func (h *UserHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 从URL路径中提取用户ID（这里简化处理，实际应用中建议使用路由库）
-	userIDStr := r.URL.Query().Get("user_id")
+	userIDStr := c.Query("user_id")
 	if userIDStr == "" {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "user_id is required", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "user_id is required", reqID, traceID)
 		return
 	}
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid user_id", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid user_id", reqID, traceID)
 		return
 	}
 
 	// 解析请求体
 	var req domain.UpdateUserRoleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
-		return
-	}
-
-	// 验证角色值
-	if req.Role != domain.UserRoleUser && req.Role != domain.UserRoleAdmin {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid role", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层更新用户角色
 	if err := h.userService.UpdateUserRole(userID, req.Role); err != nil {
 		if errors.Is(err, service.ErrUserNotFound) {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, "")
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, traceID)
 			return
 		}
 
 		h.logger.Error("update user role failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "update user role failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "update user role failed", reqID, traceID)
 		return
 	}
 
@@ -357,44 +373,42 @@ func (h *UserHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 	result := map[string]interface{}{
 		"message": "user role updated successfully",
 	}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
 
 // UpdateUserStatus 更新用户状态（管理员专用）
 // PUT /api/v1/admin/users/{user_id}/status
-func (h *UserHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
-	reqID := middleware.RequestIDFromContext(r.Context())
+func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
 
 	// 从URL路径中提取用户ID
-	userIDStr := r.URL.Query().Get("user_id")
+	userIDStr := c.Query("user_id")
 	if userIDStr == "" {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "user_id is required", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "user_id is required", reqID, traceID)
 		return
 	}
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid user_id", reqID, "")
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid user_id", reqID, traceID)
 		return
 	}
 
 	// 解析请求体
 	var req domain.UpdateUserStatusRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Warn("invalid request body", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusBadRequest, resp.CodeInvalidParam, "invalid request body", reqID, "")
+	if !bindJSON(c, &req, h.logger) {
 		return
 	}
 
 	// 调用服务层更新用户状态
 	if err := h.userService.UpdateUserStatus(userID, req.IsActive); err != nil {
 		if errors.Is(err, service.ErrUserNotFound) {
-			resp.Error(w, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, "")
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, traceID)
 			return
 		}
 
 		h.logger.Error("update user status failed", zap.String("request_id", reqID), zap.Error(err))
-		resp.Error(w, http.StatusInternalServerError, resp.CodeInternalError, "update user status failed", reqID, "")
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "update user status failed", reqID, traceID)
 		return
 	}
 
@@ -402,5 +416,53 @@ func (h *UserHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
 	result := map[string]interface{}{
 		"message": "user status updated successfully",
 	}
-	resp.OK(w, &result, reqID, "")
+	resp.OK(c.Writer, &result, reqID, traceID)
+}
+
+// UpdateUserTier 更新用户等级（管理员专用）
+// PUT /api/v1/admin/users/tier?user_id={user_id}
+func (h *UserHandler) UpdateUserTier(c *gin.Context) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+
+	userIDStr := c.Query("user_id")
+	if userIDStr == "" {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "user_id is required", reqID, traceID)
+		return
+	}
+
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid user_id", reqID, traceID)
+		return
+	}
+
+	// 解析请求体
+	var req domain.UpdateUserTierRequest
+	if !bindJSON(c, &req, h.logger) {
+		return
+	}
+
+	// 验证等级值
+	if !req.Tier.IsValid() {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "invalid tier", reqID, traceID)
+		return
+	}
+
+	// 调用服务层更新用户等级
+	if err := h.userService.UpdateUserTier(userID, req.Tier); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			resp.Error(c.Writer, http.StatusNotFound, resp.CodeInvalidParam, "user not found", reqID, traceID)
+			return
+		}
+
+		h.logger.Error("update user tier failed", zap.String("request_id", reqID), zap.Error(err))
+		resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "update user tier failed", reqID, traceID)
+		return
+	}
+
+	// 返回成功响应
+	result := map[string]interface{}{
+		"message": "user tier updated successfully",
+	}
+	resp.OK(c.Writer, &result, reqID, traceID)
 }
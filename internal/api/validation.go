@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+var registerTagNameOnce sync.Once
+
+// registerJSONTagName 让校验错误中的字段名取 json 标签，与请求体中的字段名一致
+func registerJSONTagName() {
+	registerTagNameOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(jsonFieldName)
+	})
+}
+
+// jsonFieldName 返回结构体字段的 json 名称，未设置 json 标签时使用字段名
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// bindJSON 解析 JSON 请求体并按请求结构体的 binding 标签校验。
+// 请求体无法解析时写出 400 错误；校验不通过时写出字段级错误（字段名取 json 标签，嵌套字段形如 items[0].quantity）。
+// 失败时返回 false，调用方直接返回即可
+func bindJSON(c *gin.Context, req any, logger *zap.Logger) bool {
+	return bindJSONWith(c, req, logger, false, writeInvalidRequest)
+}
+
+// bindOptionalJSON 与 bindJSON 相同，但允许请求体为空，此时 req 保持零值
+func bindOptionalJSON(c *gin.Context, req any, logger *zap.Logger) bool {
+	return bindJSONWith(c, req, logger, true, writeInvalidRequest)
+}
+
+// bindJSONWith 解析并校验请求体，失败时由 writeInvalid 写出响应；fields 为空表示请求体无法解析
+func bindJSONWith(c *gin.Context, req any, logger *zap.Logger, optional bool,
+	writeInvalid func(c *gin.Context, fields []resp.FieldError)) bool {
+	registerJSONTagName()
+
+	err := c.ShouldBindJSON(req)
+	if err == nil || (optional && errors.Is(err, io.EOF)) {
+		return true
+	}
+	logger.Warn("请求参数校验失败", zap.String("request_id", c.GetString("request_id")), zap.Error(err))
+
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		writeInvalid(c, fieldErrors(req, invalid))
+		return false
+	}
+	writeInvalid(c, nil)
+	return false
+}
+
+// writeInvalidRequest 写出请求参数错误：有字段级错误时逐字段返回，否则返回请求体格式错误
+func writeInvalidRequest(c *gin.Context, fields []resp.FieldError) {
+	reqID, traceID := c.GetString("request_id"), c.GetString("trace_id")
+	if len(fields) > 0 {
+		resp.InvalidFields(c.Writer, fields, reqID, traceID)
+		return
+	}
+	resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam, "请求参数格式错误", reqID, traceID)
+}
+
+// fieldErrors 将校验错误转换为字段级错误响应
+func fieldErrors(req any, invalid validator.ValidationErrors) []resp.FieldError {
+	fields := make([]resp.FieldError, 0, len(invalid))
+	for _, e := range invalid {
+		// Namespace 以请求结构体类型名开头，去掉后即为请求体中的字段路径
+		_, field, _ := strings.Cut(e.Namespace(), ".")
+		fields = append(fields, resp.FieldError{Field: field, Message: fieldErrorMessage(req, e)})
+	}
+	return fields
+}
+
+// fieldErrorMessage 返回单个字段校验错误的描述
+func fieldErrorMessage(req any, e validator.FieldError) string {
+	param := e.Param()
+	switch e.Tag() {
+	case "required", "required_without":
+		return "不能为空"
+	case "email":
+		return "必须为有效的邮箱地址"
+	case "oneof":
+		return "取值必须为 " + strings.ReplaceAll(param, " ", "、") + " 之一"
+	case "gt":
+		return "必须大于 " + param
+	case "gte":
+		return "不能小于 " + param
+	case "lt":
+		return "必须小于 " + param
+	case "lte":
+		return "不能大于 " + param
+	case "ne":
+		return "不能为 " + param
+	case "ltefield":
+		return "不能大于 " + siblingFieldName(req, param)
+	case "min", "max":
+		word := map[string]string{"min": "小于", "max": "大于"}[e.Tag()]
+		switch e.Kind() {
+		case reflect.String:
+			return "长度不能" + word + " " + param
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return "数量不能" + word + " " + param
+		}
+		return "不能" + word + " " + param
+	}
+	return "取值无效"
+}
+
+// siblingFieldName 返回跨字段校验引用的请求字段的 json 名称
+func siblingFieldName(req any, name string) string {
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		if f, ok := t.FieldByName(name); ok {
+			return jsonFieldName(f)
+		}
+	}
+	return name
+}
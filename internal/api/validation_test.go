@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		req    any
+		body   string
+		status int
+		fields map[string]string
+	}{
+		{name: "通过校验", req: &domain.CreateInventoryRequest{},
+			body: `{"product_id": 1, "stock": 10, "max_stock": 100}`, status: http.StatusOK},
+		{name: "请求体无法解析", req: &domain.CreateInventoryRequest{},
+			body: `{"product_id": "x"}`, status: http.StatusBadRequest},
+		{name: "字段错误", req: &domain.CreateInventoryRequest{},
+			body: `{"stock": 200, "max_stock": 100}`, status: http.StatusBadRequest,
+			fields: map[string]string{"product_id": "不能为空", "stock": "不能大于 max_stock"}},
+		{name: "嵌套字段", req: &domain.BatchStockCheckRequest{},
+			body: `{"items": [{"product_id": 1, "quantity": 1}, {"product_id": 2}]}`, status: http.StatusBadRequest,
+			fields: map[string]string{"items[1].quantity": "不能为空"}},
+		{name: "枚举与长度", req: &domain.StockAdjustmentRequest{},
+			body: `{"quantity": 0, "reason": "盘点", "type": "move"}`, status: http.StatusBadRequest,
+			fields: map[string]string{"quantity": "不能为 0", "type": "取值必须为 in、out 之一"}},
+		{name: "按预留ID释放无需商品", req: &domain.ReleaseStockRequest{},
+			body: `{"reservation_id": "r-1"}`, status: http.StatusOK},
+		{name: "未指定预留ID时商品必填", req: &domain.ReleaseStockRequest{},
+			body: `{"quantity": 1}`, status: http.StatusBadRequest,
+			fields: map[string]string{"product_id": "不能为空"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/", func(c *gin.Context) {
+				req := reflect.New(reflect.TypeOf(tt.req).Elem()).Interface()
+				if bindJSON(c, req, zap.NewNop()) {
+					c.Status(http.StatusOK)
+				}
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.fields == nil {
+				return
+			}
+
+			var body resp.Response[resp.FieldErrors]
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			got := make(map[string]string)
+			for _, f := range body.Data.Errors {
+				got[f.Field] = f.Message
+			}
+			if !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
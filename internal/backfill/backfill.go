@@ -0,0 +1,67 @@
+// Package backfill 提供历史数据回填的基础设施：可插拔的任务、按主键分批处理、
+// 批次间限速、基于 backfill_progress 表的断点续跑以及 dry-run 模式。
+// 新增字段或表结构调整（如 tenant_id、金额改为整数分）需要回填历史数据时，
+// 只需实现 Job 并在 init 中 Register，即可通过 cmd/backfill 执行。
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BatchResult 表示一个批次的处理结果
+type BatchResult struct {
+	LastID int64 // 本批次处理到的最大主键ID，作为下一批次的游标
+	Rows   int64 // 本批次实际处理（更新/插入）的行数
+	Done   bool  // 是否已无待处理数据
+}
+
+// Job 定义一个回填任务。
+// Process 必须只处理主键大于 afterID 的最多 batchSize 行，并只通过 tx 写入数据，
+// 这样 dry-run 模式回滚事务即可不留下任何修改。
+type Job interface {
+	Name() string
+	Description() string
+	Process(ctx context.Context, tx *sql.Tx, afterID int64, batchSize int) (*BatchResult, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Job)
+)
+
+// Register 注册回填任务，名称重复时 panic（属于编程错误）
+func Register(job Job) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[job.Name()]; exists {
+		panic(fmt.Sprintf("backfill: job %q already registered", job.Name()))
+	}
+	registry[job.Name()] = job
+}
+
+// Lookup 按名称查找回填任务
+func Lookup(name string) (Job, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	job, ok := registry[name]
+	return job, ok
+}
+
+// Jobs 返回按名称排序的全部已注册任务
+func Jobs() []Job {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	jobs := make([]Job, 0, len(registry))
+	for _, job := range registry {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name() < jobs[j].Name() })
+	return jobs
+}
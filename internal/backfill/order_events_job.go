@@ -0,0 +1,83 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func init() {
+	Register(&orderCreatedEventsJob{})
+}
+
+// orderCreatedEventsJob 为 order_events 表上线前创建的秒杀订单补写 created 事件，
+// 使历史订单的时间线同样从创建开始
+type orderCreatedEventsJob struct{}
+
+func (j *orderCreatedEventsJob) Name() string {
+	return "order_events_created"
+}
+
+func (j *orderCreatedEventsJob) Description() string {
+	return "为缺少 created 事件的历史秒杀订单补写订单创建事件"
+}
+
+// Process 按订单ID分批扫描，只为尚无 created 事件的订单插入事件，重复执行是安全的
+func (j *orderCreatedEventsJob) Process(ctx context.Context, tx *sql.Tx, afterID int64, batchSize int) (*BatchResult, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, created_at
+		FROM spike_orders
+		WHERE id > ?
+		ORDER BY id ASC
+		LIMIT ?
+	`, afterID, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spike orders: %w", err)
+	}
+
+	type orderRow struct {
+		id      int64
+		userID  int64
+		created sql.NullTime
+	}
+	var orders []orderRow
+	for rows.Next() {
+		var o orderRow
+		if err := rows.Scan(&o.id, &o.userID, &o.created); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan spike order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to iterate spike orders: %w", err)
+	}
+	rows.Close()
+
+	result := &BatchResult{LastID: afterID, Done: len(orders) < batchSize}
+	for _, o := range orders {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO order_events (spike_order_id, event_type, from_status, to_status, actor, remark, created_at)
+			SELECT ?, ?, '', ?, ?, 'backfill', ?
+			FROM DUAL
+			WHERE NOT EXISTS (
+				SELECT 1 FROM order_events WHERE spike_order_id = ? AND event_type = ?
+			)
+		`, o.id, domain.OrderEventCreated, domain.SpikeOrderStatusPending, domain.UserActor(o.userID), o.created,
+			o.id, domain.OrderEventCreated)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert created event for order %d: %w", o.id, err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		result.Rows += affected
+		result.LastID = o.id
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func TestOrderCreatedEventsJob_Process(t *testing.T) {
+	db, mock := newMockDB(t)
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, user_id, created_at FROM spike_orders").
+		WithArgs(int64(10), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).
+			AddRow(int64(11), int64(7), created).
+			AddRow(int64(13), int64(8), created))
+	// 订单 11 补写事件，订单 13 已有 created 事件不重复插入
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(int64(11), domain.OrderEventCreated, domain.SpikeOrderStatusPending, domain.UserActor(7), created,
+			int64(11), domain.OrderEventCreated).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(int64(13), domain.OrderEventCreated, domain.SpikeOrderStatusPending, domain.UserActor(8), created,
+			int64(13), domain.OrderEventCreated).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := (&orderCreatedEventsJob{}).Process(context.Background(), tx, 10, 2)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	// 满批说明可能还有数据，游标推进到本批最大ID
+	if result.Rows != 1 || result.LastID != 13 || result.Done {
+		t.Errorf("Process() = %+v, want 1 row, last id 13, not done", result)
+	}
+}
+
+func TestOrderCreatedEventsJob_ProcessErrors(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	// 不足一批说明已处理完
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, user_id, created_at FROM spike_orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}))
+	mock.ExpectQuery("SELECT id, user_id, created_at FROM spike_orders").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectQuery("SELECT id, user_id, created_at FROM spike_orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(int64(1), int64(7), nil))
+	mock.ExpectExec("INSERT INTO order_events").WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	defer tx.Rollback()
+	job := &orderCreatedEventsJob{}
+	ctx := context.Background()
+
+	result, err := job.Process(ctx, tx, 5, 100)
+	if err != nil || !result.Done || result.LastID != 5 || result.Rows != 0 {
+		t.Errorf("Process() on empty batch = %+v, %v, want done at cursor 5", result, err)
+	}
+	if _, err := job.Process(ctx, tx, 5, 100); err == nil || !strings.Contains(err.Error(), "failed to query spike orders") {
+		t.Errorf("Process() query failure error = %v", err)
+	}
+	if _, err := job.Process(ctx, tx, 0, 100); err == nil || !strings.Contains(err.Error(), "order 1") {
+		t.Errorf("Process() insert failure error = %v", err)
+	}
+}
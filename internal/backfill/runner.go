@@ -0,0 +1,225 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// Options 控制回填执行方式
+type Options struct {
+	BatchSize     int           // 每批处理行数
+	BatchInterval time.Duration // 批次之间的最小间隔，用于限速，0 表示不限速
+	MaxBatches    int           // 本次最多执行的批次数，0 表示直到完成
+	DryRun        bool          // 只演练：每批事务回滚且不写进度
+	Reset         bool          // 忽略已有进度从头开始
+}
+
+// DefaultOptions 返回默认回填选项
+func DefaultOptions() *Options {
+	return &Options{
+		BatchSize:     500,
+		BatchInterval: 200 * time.Millisecond,
+	}
+}
+
+// Summary 表示一次回填执行的汇总
+type Summary struct {
+	JobName   string
+	Batches   int
+	Rows      int64
+	LastID    int64
+	Completed bool
+	DryRun    bool
+	Elapsed   time.Duration
+}
+
+// Runner 负责分批执行回填任务并记录进度
+type Runner struct {
+	db           *sql.DB
+	progressRepo repo.BackfillProgressRepository
+	logger       *zap.Logger
+}
+
+// NewRunner 创建回填执行器
+func NewRunner(db *sql.DB, progressRepo repo.BackfillProgressRepository, logger *zap.Logger) *Runner {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Runner{
+		db:           db,
+		progressRepo: progressRepo,
+		logger:       logger,
+	}
+}
+
+// Run 执行回填任务；ctx 取消时在当前批次结束后停止，已提交批次的进度会保留以便续跑
+func (r *Runner) Run(ctx context.Context, job Job, opts *Options) (*Summary, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if opts.BatchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be > 0, got %d", opts.BatchSize)
+	}
+
+	progress, err := r.loadProgress(job.Name(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{JobName: job.Name(), LastID: progress.LastID, DryRun: opts.DryRun}
+	if progress.IsCompleted() {
+		summary.Completed = true
+		r.logger.Info("回填任务已完成，跳过（使用 reset 可重跑）", zap.String("job", job.Name()))
+		return summary, nil
+	}
+
+	lg := r.logger.With(zap.String("job", job.Name()), zap.Bool("dry_run", opts.DryRun))
+	lg.Info("开始回填", zap.Int64("resume_after_id", progress.LastID), zap.Int("batch_size", opts.BatchSize))
+
+	start := time.Now()
+	defer func() { summary.Elapsed = time.Since(start) }()
+
+	var ticker *time.Ticker
+	if opts.BatchInterval > 0 {
+		ticker = time.NewTicker(opts.BatchInterval)
+		defer ticker.Stop()
+	}
+
+	for opts.MaxBatches == 0 || summary.Batches < opts.MaxBatches {
+		if err := ctx.Err(); err != nil {
+			lg.Warn("回填被中断，可稍后续跑", zap.Int64("last_id", summary.LastID))
+			return summary, nil
+		}
+
+		result, err := r.runBatch(ctx, job, summary.LastID, opts)
+		if err != nil {
+			r.markFailed(progress, err, opts)
+			return summary, fmt.Errorf("backfill %s batch after id %d: %w", job.Name(), summary.LastID, err)
+		}
+
+		summary.Batches++
+		summary.Rows += result.Rows
+		if result.LastID > summary.LastID {
+			summary.LastID = result.LastID
+		}
+		summary.Completed = result.Done
+
+		progress.LastID = summary.LastID
+		progress.ProcessedCount += result.Rows
+		progress.Status = domain.BackfillStatusRunning
+		progress.LastError = ""
+		if result.Done {
+			now := time.Now()
+			progress.Status = domain.BackfillStatusCompleted
+			progress.CompletedAt = &now
+		}
+		if !opts.DryRun {
+			if err := r.progressRepo.Save(progress); err != nil {
+				return summary, err
+			}
+		}
+
+		lg.Info("回填批次完成",
+			zap.Int("batch", summary.Batches),
+			zap.Int64("rows", result.Rows),
+			zap.Int64("last_id", summary.LastID))
+
+		if result.Done {
+			break
+		}
+
+		if ticker != nil {
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+			}
+		}
+	}
+
+	lg.Info("回填结束",
+		zap.Int("batches", summary.Batches),
+		zap.Int64("rows", summary.Rows),
+		zap.Bool("completed", summary.Completed))
+
+	return summary, nil
+}
+
+// loadProgress 读取或初始化任务进度
+func (r *Runner) loadProgress(jobName string, opts *Options) (*domain.BackfillProgress, error) {
+	if opts.Reset && !opts.DryRun {
+		if err := r.progressRepo.Delete(jobName); err != nil {
+			return nil, err
+		}
+	}
+
+	var progress *domain.BackfillProgress
+	if !opts.Reset {
+		p, err := r.progressRepo.Get(jobName)
+		if err != nil {
+			return nil, err
+		}
+		progress = p
+	}
+
+	if progress == nil {
+		progress = &domain.BackfillProgress{
+			JobName: jobName,
+			Status:  domain.BackfillStatusRunning,
+		}
+	}
+
+	return progress, nil
+}
+
+// runBatch 在事务中执行一个批次，dry-run 时回滚
+func (r *Runner) runBatch(ctx context.Context, job Job, afterID int64, opts *Options) (*BatchResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := job.Process(ctx, tx, afterID, opts.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// markFailed 记录失败状态，保留游标以便修复后续跑
+func (r *Runner) markFailed(progress *domain.BackfillProgress, cause error, opts *Options) {
+	if opts.DryRun {
+		return
+	}
+
+	progress.Status = domain.BackfillStatusFailed
+	progress.LastError = truncate(cause.Error(), 1024)
+	if err := r.progressRepo.Save(progress); err != nil {
+		r.logger.Error("保存回填失败状态失败", zap.String("job", progress.JobName), zap.Error(err))
+	}
+}
+
+// truncate 按字符截断，避免截断多字节字符
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,209 @@
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+)
+
+// idsJob 按主键顺序处理内存中的ID，记录每批的起始游标；failAfter 非零时在该游标之后的批次返回错误
+type idsJob struct {
+	ids       []int64
+	failAfter int64
+	afterIDs  []int64
+}
+
+func (j *idsJob) Name() string        { return "test_ids" }
+func (j *idsJob) Description() string { return "test job" }
+
+func (j *idsJob) Process(ctx context.Context, tx *sql.Tx, afterID int64, batchSize int) (*BatchResult, error) {
+	j.afterIDs = append(j.afterIDs, afterID)
+	if j.failAfter != 0 && afterID == j.failAfter {
+		return nil, errors.New("deadlock found")
+	}
+	result := &BatchResult{LastID: afterID}
+	for _, id := range j.ids {
+		if id <= afterID {
+			continue
+		}
+		if result.Rows == int64(batchSize) {
+			return result, nil
+		}
+		result.LastID = id
+		result.Rows++
+	}
+	result.Done = true
+	return result, nil
+}
+
+// newProgressRepo 返回内存保存进度的仓储模拟，saved 记录每次保存时的进度快照
+func newProgressRepo(initial *domain.BackfillProgress) (*mocks.BackfillProgressRepositoryMock, *[]domain.BackfillProgress) {
+	var saved []domain.BackfillProgress
+	current := initial
+	return &mocks.BackfillProgressRepositoryMock{
+		GetFunc: func(jobName string) (*domain.BackfillProgress, error) {
+			if current == nil {
+				return nil, nil
+			}
+			copied := *current
+			return &copied, nil
+		},
+		SaveFunc: func(progress *domain.BackfillProgress) error {
+			saved = append(saved, *progress)
+			copied := *progress
+			current = &copied
+			return nil
+		},
+		DeleteFunc: func(jobName string) error {
+			current = nil
+			return nil
+		},
+	}, &saved
+}
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet sql expectations: %v", err)
+		}
+		_ = db.Close()
+	})
+	return db, mock
+}
+
+func TestRunner_RunInBatches(t *testing.T) {
+	db, mock := newMockDB(t)
+	for i := 0; i < 3; i++ {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+	}
+	progressRepo, saved := newProgressRepo(nil)
+	job := &idsJob{ids: []int64{2, 4, 5, 8, 9, 11, 12}}
+
+	summary, err := NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 3})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if summary.Batches != 3 || summary.Rows != 7 || summary.LastID != 12 || !summary.Completed {
+		t.Errorf("summary = %+v, want 3 batches, 7 rows, last id 12, completed", summary)
+	}
+	if want := []int64{0, 5, 11}; !slices.Equal(job.afterIDs, want) {
+		t.Errorf("batch cursors = %v, want %v", job.afterIDs, want)
+	}
+	// 每批提交后保存进度，最后一批标记完成
+	if len(*saved) != 3 {
+		t.Fatalf("progress saved %d times, want 3", len(*saved))
+	}
+	if p := (*saved)[1]; p.LastID != 11 || p.ProcessedCount != 6 || p.Status != domain.BackfillStatusRunning {
+		t.Errorf("second checkpoint = %+v", p)
+	}
+	if p := (*saved)[2]; p.Status != domain.BackfillStatusCompleted || p.CompletedAt == nil {
+		t.Errorf("final checkpoint = %+v, want completed", p)
+	}
+}
+
+func TestRunner_ResumeFromCheckpoint(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	progressRepo, saved := newProgressRepo(&domain.BackfillProgress{
+		JobName: "test_ids", LastID: 5, ProcessedCount: 3, Status: domain.BackfillStatusFailed, LastError: "deadlock",
+	})
+	job := &idsJob{ids: []int64{2, 4, 5, 8, 9}}
+
+	summary, err := NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []int64{5}; !slices.Equal(job.afterIDs, want) {
+		t.Errorf("batch cursors = %v, want resume after 5", job.afterIDs)
+	}
+	if summary.Rows != 2 || summary.LastID != 9 {
+		t.Errorf("summary = %+v, want 2 rows up to id 9", summary)
+	}
+	if p := (*saved)[0]; p.ProcessedCount != 5 || p.LastError != "" || p.Status != domain.BackfillStatusCompleted {
+		t.Errorf("checkpoint = %+v, want cumulative count 5 and error cleared", p)
+	}
+
+	// 已完成的任务直接跳过，不开启事务
+	job.afterIDs = nil
+	summary, err = NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 10})
+	if err != nil || !summary.Completed || summary.Batches != 0 || len(job.afterIDs) != 0 {
+		t.Errorf("Run() on completed job = %+v, %v, want skipped", summary, err)
+	}
+
+	// reset 删除进度后从头开始
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	job.afterIDs = nil
+	if _, err := NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 10, Reset: true}); err != nil {
+		t.Fatalf("Run(reset) error = %v", err)
+	}
+	if want := []int64{0}; !slices.Equal(job.afterIDs, want) {
+		t.Errorf("batch cursors after reset = %v, want %v", job.afterIDs, want)
+	}
+	if len(progressRepo.DeleteCalls()) != 1 {
+		t.Errorf("Delete() calls = %d, want 1", len(progressRepo.DeleteCalls()))
+	}
+}
+
+func TestRunner_JobError(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	progressRepo, saved := newProgressRepo(nil)
+	job := &idsJob{ids: []int64{1, 2, 3, 4}, failAfter: 2}
+
+	summary, err := NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 2})
+	if err == nil || !strings.Contains(err.Error(), "batch after id 2") || !strings.Contains(err.Error(), "deadlock found") {
+		t.Fatalf("Run() error = %v, want batch error after id 2", err)
+	}
+	if summary.Batches != 1 || summary.LastID != 2 || summary.Completed {
+		t.Errorf("summary = %+v, want first batch kept", summary)
+	}
+	// 失败状态保留已提交批次的游标，修复后从该处续跑
+	last := (*saved)[len(*saved)-1]
+	if last.Status != domain.BackfillStatusFailed || last.LastID != 2 || last.LastError != "deadlock found" {
+		t.Errorf("failed checkpoint = %+v", last)
+	}
+}
+
+func TestRunner_DryRun(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	progressRepo, _ := newProgressRepo(nil)
+	job := &idsJob{ids: []int64{1, 2, 3}}
+
+	summary, err := NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 2, DryRun: true})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !summary.DryRun || summary.Rows != 3 || !summary.Completed {
+		t.Errorf("summary = %+v, want dry run over 3 rows", summary)
+	}
+	if len(progressRepo.SaveCalls()) != 0 {
+		t.Errorf("dry run saved progress %d times", len(progressRepo.SaveCalls()))
+	}
+
+	if _, err := NewRunner(db, progressRepo, nil).Run(context.Background(), job, &Options{BatchSize: 0}); err == nil {
+		t.Error("Run() with zero batch size error = nil")
+	}
+}
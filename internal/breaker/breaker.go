@@ -0,0 +1,196 @@
+// Package breaker 提供熔断器：依赖连续失败达到阈值后熔断一段时间，期间调用方直接走降级逻辑，
+// 冷却结束后放行少量探测请求，探测成功即恢复。
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen 熔断器处于熔断状态，调用未执行
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed   State = iota // 正常放行
+	StateOpen                  // 熔断中，拒绝调用
+	StateHalfOpen              // 冷却结束，放行有限的探测调用
+)
+
+// String 返回状态名称，用于日志与指标
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config 熔断器配置
+type Config struct {
+	FailureThreshold int           // 连续失败多少次后熔断
+	OpenTimeout      time.Duration // 熔断持续多久后进入半开状态
+	HalfOpenProbes   int           // 半开状态同时放行的探测调用数
+}
+
+// DefaultConfig 默认配置：连续失败 5 次熔断 10 秒，半开时放行 1 个探测调用
+func DefaultConfig() Config {
+	return Config{FailureThreshold: 5, OpenTimeout: 10 * time.Second, HalfOpenProbes: 1}
+}
+
+// Breaker 熔断器，可并发使用
+type Breaker struct {
+	name string
+	cfg  Config
+	now  func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int       // 关闭状态下的连续失败次数
+	openedAt time.Time // 最近一次熔断的时间
+	probes   int       // 半开状态下尚未返回结果的探测调用数
+
+	onStateChange func(name string, from, to State)
+}
+
+// New 创建熔断器，name 用于日志区分被保护的依赖；非法配置项取默认值
+func New(name string, cfg Config) *Breaker {
+	def := DefaultConfig()
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = def.FailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = def.OpenTimeout
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = def.HalfOpenProbes
+	}
+	return &Breaker{name: name, cfg: cfg, now: time.Now}
+}
+
+// Name 返回熔断器保护的依赖名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// OnStateChange 设置状态变化回调，回调在锁外同步执行，不应阻塞
+func (b *Breaker) OnStateChange(fn func(name string, from, to State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onStateChange = fn
+}
+
+// State 返回当前状态；熔断已到期但尚未有调用探测时返回 StateHalfOpen。只读取状态，不占用探测名额
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.cfg.OpenTimeout)) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow 判断是否放行一次调用，放行后调用方必须以调用结果调用一次 Record。
+// 熔断中返回 ErrOpen；半开状态下超出探测名额的调用同样返回 ErrOpen
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case StateOpen:
+		if b.now().Before(b.openedAt.Add(b.cfg.OpenTimeout)) {
+			b.mu.Unlock()
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+		b.probes = 0
+		fallthrough
+	case StateHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			to := b.state
+			b.mu.Unlock()
+			b.notify(from, to)
+			return ErrOpen
+		}
+		b.probes++
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return nil
+}
+
+// Record 记录一次已放行调用的结果；调用方取消（context.Canceled）既不计为成功也不计为失败
+func (b *Breaker) Record(err error) {
+	canceled := errors.Is(err, context.Canceled)
+
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case StateClosed:
+		switch {
+		case canceled:
+		case err == nil:
+			b.failures = 0
+		default:
+			b.failures++
+			if b.failures >= b.cfg.FailureThreshold {
+				b.trip()
+			}
+		}
+	case StateHalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+		switch {
+		case canceled:
+		case err == nil:
+			b.state = StateClosed
+			b.failures = 0
+		default:
+			b.trip()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// Do 在熔断器保护下执行 fn，熔断中直接返回 ErrOpen
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.Record(err)
+	return err
+}
+
+// trip 进入熔断状态，调用方持有锁
+func (b *Breaker) trip() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.failures = 0
+	b.probes = 0
+}
+
+// notify 状态变化时执行回调
+func (b *Breaker) notify(from, to State) {
+	if from == to {
+		return
+	}
+	b.mu.Lock()
+	fn := b.onStateChange
+	b.mu.Unlock()
+	if fn != nil {
+		fn(b.name, from, to)
+	}
+}
@@ -0,0 +1,63 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker_TripsAndRecovers(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := New("test", Config{FailureThreshold: 2, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	var transitions []State
+	b.OnStateChange(func(name string, from, to State) { transitions = append(transitions, to) })
+
+	failure := errors.New("boom")
+	_ = b.Do(func() error { return failure })
+	_ = b.Do(func() error { return context.Canceled }) // 调用方取消不计为失败
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s after one failure, want closed", b.State())
+	}
+	_ = b.Do(func() error { return failure })
+	if b.State() != StateOpen {
+		t.Fatalf("state = %s after threshold, want open", b.State())
+	}
+	if err := b.Do(func() error { t.Fatal("call executed while open"); return nil }); !errors.Is(err, ErrOpen) {
+		t.Errorf("Do() while open error = %v, want ErrOpen", err)
+	}
+
+	// 冷却结束后只放行一个探测调用，探测失败重新熔断
+	now = now.Add(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("second probe Allow() error = %v, want ErrOpen", err)
+	}
+	b.Record(failure)
+	if b.State() != StateOpen {
+		t.Fatalf("state = %s after failed probe, want open", b.State())
+	}
+
+	now = now.Add(time.Second)
+	if err := b.Do(func() error { return nil }); err != nil {
+		t.Fatalf("successful probe error = %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Errorf("state = %s after successful probe, want closed", b.State())
+	}
+
+	want := []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transitions = %v, want %v", transitions, want)
+			break
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -25,14 +26,15 @@ type CacheConfig struct {
 	TTL     time.Duration
 }
 
-// MemoryCache 内存缓存实现（用于开发和测试）
+// MemoryCache 内存缓存实现（用于开发和测试），并发安全
 type MemoryCache struct {
+	mu   sync.Mutex
 	data map[string]*memoryCacheItem
 }
 
 type memoryCacheItem struct {
 	value      []byte
-	expiration time.Time
+	expiration time.Time // 零值表示不过期
 }
 
 // NewMemoryCache 创建内存缓存实例
@@ -44,13 +46,16 @@ func NewMemoryCache() *MemoryCache {
 
 // Get 获取缓存值
 func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	item, exists := m.data[key]
 	if !exists {
 		return fmt.Errorf("key not found")
 	}
 
 	// 检查是否过期
-	if time.Now().After(item.expiration) {
+	if item.expired() {
 		delete(m.data, key)
 		return fmt.Errorf("key expired")
 	}
@@ -58,23 +63,33 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 	return json.Unmarshal(item.value, dest)
 }
 
-// Set 设置缓存值
+// Set 设置缓存值，expiration 为 0 时不过期（与 Redis 一致）
 func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
 
-	m.data[key] = &memoryCacheItem{
-		value:      data,
-		expiration: time.Now().Add(expiration),
+	item := &memoryCacheItem{value: data}
+	if expiration > 0 {
+		item.expiration = time.Now().Add(expiration)
 	}
+	m.mu.Lock()
+	m.data[key] = item
+	m.mu.Unlock()
 
 	return nil
 }
 
+func (i *memoryCacheItem) expired() bool {
+	return !i.expiration.IsZero() && time.Now().After(i.expiration)
+}
+
 // Del 删除缓存值
 func (m *MemoryCache) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for _, key := range keys {
 		delete(m.data, key)
 	}
@@ -83,32 +98,48 @@ func (m *MemoryCache) Del(ctx context.Context, keys ...string) error {
 
 // Exists 检查键是否存在
 func (m *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.existsLocked(key), nil
+}
+
+// existsLocked 判断键是否存在并清除已过期的键，调用方须持有锁
+func (m *MemoryCache) existsLocked(key string) bool {
 	item, exists := m.data[key]
 	if !exists {
-		return false, nil
+		return false
 	}
 
 	// 检查是否过期
-	if time.Now().After(item.expiration) {
+	if item.expired() {
 		delete(m.data, key)
-		return false, nil
+		return false
 	}
 
-	return true, nil
+	return true
 }
 
-// SetNX 仅当键不存在时设置
+// SetNX 仅当键不存在时设置，判断与写入在同一把锁内完成
 func (m *MemoryCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
-	exists, err := m.Exists(ctx, key)
+	data, err := json.Marshal(value)
 	if err != nil {
 		return false, err
 	}
 
-	if exists {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.existsLocked(key) {
 		return false, nil
 	}
 
-	return true, m.Set(ctx, key, value, expiration)
+	item := &memoryCacheItem{value: data}
+	if expiration > 0 {
+		item.expiration = time.Now().Add(expiration)
+	}
+	m.data[key] = item
+	return true, nil
 }
 
 // Ping 检查连接
@@ -118,6 +149,9 @@ func (m *MemoryCache) Ping(ctx context.Context) error {
 
 // Close 关闭缓存
 func (m *MemoryCache) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.data = make(map[string]*memoryCacheItem)
 	return nil
 }
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// IdempotencyKeyNamespace 接口幂等记录Key: idempotency:response:{user_id}:{path}:{key}
+const IdempotencyKeyNamespace = "idempotency:response"
+
+// IdempotencyRecord 接口幂等记录：处理中时只有请求摘要，完成后保存响应快照用于重放
+type IdempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"` // 请求方法、路径与请求体的摘要
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// IdempotencyStore 基于 Redis 的接口幂等记录存储，多实例共享
+type IdempotencyStore struct {
+	client redis.Cmdable
+	prefix string // 环境键前缀，为空时不加前缀
+}
+
+// NewIdempotencyStore 创建接口幂等记录存储
+func NewIdempotencyStore(client redis.Cmdable) *IdempotencyStore {
+	return &IdempotencyStore{client: client}
+}
+
+// SetKeyPrefix 设置环境键前缀
+func (s *IdempotencyStore) SetKeyPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// Key 构造幂等记录键，同一幂等键按用户与请求路径隔离
+func (s *IdempotencyStore) Key(userID int64, path, idempotencyKey string) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(IdempotencyKeyNamespace, userID, path, idempotencyKey))
+}
+
+// Begin 以处理中状态占用幂等键，ttl 为处理超时时间；键已存在时返回 false 与已有记录
+func (s *IdempotencyStore) Begin(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) (bool, *IdempotencyRecord, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	ok, err := s.client.SetNX(ctx, key, data, ttl).Result()
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if ok {
+		return true, nil, nil
+	}
+
+	raw, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// 已有记录恰好过期，按处理中返回，由客户端稍后重试
+		return false, &IdempotencyRecord{Fingerprint: record.Fingerprint}, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	var existing IdempotencyRecord
+	if err := json.Unmarshal(raw, &existing); err != nil {
+		return false, nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+	return false, &existing, nil
+}
+
+// Complete 保存已完成请求的响应快照，ttl 内的重试直接重放
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	if err := s.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Release 删除幂等记录，处理失败后允许客户端以同一幂等键重试
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// CacheInvalidationChannel 缓存失效广播频道（加环境前缀后使用）
+const CacheInvalidationChannel = "cache:invalidate"
+
+// invalidationMessage 失效广播消息
+type invalidationMessage struct {
+	Origin string   `json:"origin"` // 发布实例标识，实例忽略自己发布的消息
+	Keys   []string `json:"keys"`
+}
+
+// InvalidatingCache 为进程内缓存增加跨实例失效：Del 删除本地键后通过 Redis 发布/订阅广播，
+// 其他实例收到后删除各自的本地键。其余操作直接委托给被装饰的缓存。
+// 广播至多送达一次，订阅断开期间错过的失效由缓存TTL兜底
+type InvalidatingCache struct {
+	Cache
+	client  redis.UniversalClient
+	channel string
+	origin  string
+	logger  *zap.Logger
+}
+
+// NewInvalidatingCache 创建跨实例失效的缓存装饰器，调用 Start 后才会接收其他实例的失效广播
+func NewInvalidatingCache(local Cache, client redis.UniversalClient, logger *zap.Logger) *InvalidatingCache {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &InvalidatingCache{
+		Cache:   local,
+		client:  client,
+		channel: CacheInvalidationChannel,
+		origin:  uuid.New().String(),
+		logger:  logger,
+	}
+}
+
+// SetKeyPrefix 设置环境键前缀，只与同一前缀的实例互相广播；须在 Start 之前调用
+func (c *InvalidatingCache) SetKeyPrefix(prefix string) {
+	c.channel = keys.WithPrefix(prefix, CacheInvalidationChannel)
+}
+
+// Del 删除本地键并广播给其他实例。广播失败只记录日志，不影响本地删除的结果
+func (c *InvalidatingCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := c.Cache.Del(ctx, keys...)
+
+	payload, _ := json.Marshal(invalidationMessage{Origin: c.origin, Keys: keys})
+	pubErr := c.client.Publish(ctx, c.channel, payload).Err()
+	metrics.CacheInvalidations.Inc("published", metrics.Result(pubErr))
+	if pubErr != nil {
+		c.logger.Warn("广播缓存失效失败，其他实例将在TTL后过期", zap.Strings("keys", keys), zap.Error(pubErr))
+	}
+	return err
+}
+
+// Start 订阅其他实例的失效广播，ctx 取消时退出；订阅连接断开时由客户端自动重连
+func (c *InvalidatingCache) Start(ctx context.Context) {
+	pubsub := c.client.Subscribe(ctx, c.channel)
+	go func() {
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				c.handle(ctx, msg.Payload)
+			}
+		}
+	}()
+}
+
+// handle 删除其他实例广播的键，忽略自己发布的与无法解析的消息
+func (c *InvalidatingCache) handle(ctx context.Context, payload string) {
+	var msg invalidationMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil || len(msg.Keys) == 0 {
+		metrics.CacheInvalidations.Inc("received", metrics.ResultError)
+		return
+	}
+	if msg.Origin == c.origin {
+		return
+	}
+	err := c.Cache.Del(ctx, msg.Keys...)
+	metrics.CacheInvalidations.Inc("received", metrics.Result(err))
+	if err != nil {
+		c.logger.Warn("处理缓存失效广播失败", zap.Strings("keys", msg.Keys), zap.Error(err))
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInvalidatingCache_Handle(t *testing.T) {
+	ctx := context.Background()
+	local := NewMemoryCache()
+	c := NewInvalidatingCache(local, redis.NewClient(&redis.Options{Addr: "localhost:0"}), nil)
+
+	_ = local.Set(ctx, "product:id:1", 1, time.Minute)
+	_ = local.Set(ctx, "product:id:2", 2, time.Minute)
+
+	// 自己发布的消息不重复删除
+	own, _ := json.Marshal(invalidationMessage{Origin: c.origin, Keys: []string{"product:id:1"}})
+	c.handle(ctx, string(own))
+	if ok, _ := local.Exists(ctx, "product:id:1"); !ok {
+		t.Error("handle() deleted key from own broadcast")
+	}
+
+	other, _ := json.Marshal(invalidationMessage{Origin: "other", Keys: []string{"product:id:1", "product:id:2"}})
+	c.handle(ctx, string(other))
+	for _, key := range []string{"product:id:1", "product:id:2"} {
+		if ok, _ := local.Exists(ctx, key); ok {
+			t.Errorf("handle() kept %s after broadcast from other instance", key)
+		}
+	}
+
+	c.handle(ctx, "not json")
+}
+
+func TestInvalidatingCache_Broadcast(t *testing.T) {
+	// 注意：此测试需要运行Redis实例
+	if testing.Short() {
+		t.Skip("Skipping Redis test in short mode")
+	}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("Skipping Redis test, cannot connect: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	localA, localB := NewMemoryCache(), NewMemoryCache()
+	a := NewInvalidatingCache(localA, client, nil)
+	b := NewInvalidatingCache(localB, client, nil)
+	a.SetKeyPrefix("test")
+	b.SetKeyPrefix("test")
+	b.Start(ctx)
+	// 等待订阅生效
+	time.Sleep(100 * time.Millisecond)
+
+	_ = localA.Set(ctx, "inventory:product:1", 10, time.Minute)
+	_ = localB.Set(ctx, "inventory:product:1", 10, time.Minute)
+	if err := a.Del(ctx, "inventory:product:1"); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+
+	if ok, _ := localA.Exists(ctx, "inventory:product:1"); ok {
+		t.Error("Del() kept local key")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		ok, _ := localB.Exists(ctx, "inventory:product:1")
+		if !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("other instance kept key after broadcast")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -4,45 +4,53 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
 )
 
+// ErrProductionKeyPrefix 环境前缀指向生产环境时拒绝执行破坏性操作
+var ErrProductionKeyPrefix = errors.New("refusing destructive cache operation on production key prefix")
+
 // RedisCache Redis缓存实现
 type RedisCache struct {
 	client redis.Cmdable // 使用接口，支持单实例和集群
+	prefix string        // 环境键前缀，为空时不加前缀
+}
+
+// SetKeyPrefix 设置环境键前缀，之后所有键都以 prefix: 开头
+func (r *RedisCache) SetKeyPrefix(prefix string) {
+	r.prefix = prefix
+}
+
+// key 为调用方传入的键加上环境前缀
+func (r *RedisCache) key(k string) string {
+	return keys.WithPrefix(r.prefix, k)
 }
 
-// NewRedisCache 创建Redis缓存实例
+// NewRedisCache 创建连接单节点 Redis 的缓存实例
 func NewRedisCache(addr, password string, db int) (*RedisCache, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,     // Redis地址
-		Password: password, // 密码
-		DB:       db,       // 数据库编号
-
-		// 连接池配置
-		PoolSize:     10, // 连接池大小
-		MinIdleConns: 5,  // 最小空闲连接数
-		MaxIdleConns: 10, // 最大空闲连接数
-
-		// 超时配置
-		DialTimeout:  5 * time.Second, // 连接超时
-		ReadTimeout:  3 * time.Second, // 读超时
-		WriteTimeout: 3 * time.Second, // 写超时
-
-		// 重试配置
-		MaxRetries:      3,                      // 最大重试次数
-		MinRetryBackoff: 8 * time.Millisecond,   // 最小重试间隔
-		MaxRetryBackoff: 512 * time.Millisecond, // 最大重试间隔
-	})
+	return NewRedisCacheWithClient(NewRedisClient(RedisClientOptions{
+		Mode:     RedisModeStandalone,
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	}))
+}
 
+// NewRedisCacheWithClient 使用已创建的客户端（单节点、集群或哨兵）创建缓存实例，连接失败时关闭客户端并返回错误
+func NewRedisCacheWithClient(client redis.UniversalClient) (*RedisCache, error) {
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -51,7 +59,7 @@ func NewRedisCache(addr, password string, db int) (*RedisCache, error) {
 
 // Get 获取缓存值
 func (r *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
-	val, err := r.client.Get(ctx, key).Result()
+	val, err := r.client.Get(ctx, r.key(key)).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return fmt.Errorf("key not found")
@@ -69,7 +77,7 @@ func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, exp
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
 
-	err = r.client.Set(ctx, key, data, expiration).Err()
+	err = r.client.Set(ctx, r.key(key), data, expiration).Err()
 	if err != nil {
 		return fmt.Errorf("failed to set key %s: %w", key, err)
 	}
@@ -83,17 +91,33 @@ func (r *RedisCache) Del(ctx context.Context, keys ...string) error {
 		return nil
 	}
 
-	err := r.client.Del(ctx, keys...).Err()
-	if err != nil {
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = r.key(k)
+	}
+	if err := r.del(ctx, prefixed); err != nil {
 		return fmt.Errorf("failed to delete keys: %w", err)
 	}
 
 	return nil
 }
 
+// del 删除已加前缀的键。Redis Cluster 拒绝跨槽位的多键 DEL，集群模式下改为逐键删除的管道
+func (r *RedisCache) del(ctx context.Context, keys []string) error {
+	if _, ok := r.client.(*redis.ClusterClient); !ok || len(keys) == 1 {
+		return r.client.Del(ctx, keys...).Err()
+	}
+	pipe := r.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
 // Exists 检查键是否存在
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
-	count, err := r.client.Exists(ctx, key).Result()
+	count, err := r.client.Exists(ctx, r.key(key)).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to check key existence: %w", err)
 	}
@@ -108,7 +132,7 @@ func (r *RedisCache) SetNX(ctx context.Context, key string, value interface{}, e
 		return false, fmt.Errorf("failed to marshal value: %w", err)
 	}
 
-	success, err := r.client.SetNX(ctx, key, data, expiration).Result()
+	success, err := r.client.SetNX(ctx, r.key(key), data, expiration).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to set key %s: %w", key, err)
 	}
@@ -126,7 +150,7 @@ func (r *RedisCache) Close() error {
 	if wrapper, ok := r.client.(*RedisClientWrapper); ok {
 		return wrapper.Close()
 	}
-	if client, ok := r.client.(*redis.Client); ok {
+	if client, ok := r.client.(redis.UniversalClient); ok {
 		return client.Close()
 	}
 	return nil
@@ -136,35 +160,80 @@ func (r *RedisCache) Close() error {
 
 // Incr 原子递增
 func (r *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
-	return r.client.Incr(ctx, key).Result()
+	return r.client.Incr(ctx, r.key(key)).Result()
 }
 
 // IncrBy 原子递增指定值
 func (r *RedisCache) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
-	return r.client.IncrBy(ctx, key, value).Result()
+	return r.client.IncrBy(ctx, r.key(key), value).Result()
 }
 
 // Expire 设置过期时间
 func (r *RedisCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
-	return r.client.Expire(ctx, key, expiration).Err()
+	return r.client.Expire(ctx, r.key(key), expiration).Err()
 }
 
 // TTL 获取剩余过期时间
 func (r *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
-	return r.client.TTL(ctx, key).Result()
+	return r.client.TTL(ctx, r.key(key)).Result()
 }
 
-// Keys 根据模式获取键列表（谨慎使用，可能影响性能）
+// Keys 根据模式获取键列表（谨慎使用，可能影响性能），只匹配当前前缀下的键，返回的键不含前缀
 func (r *RedisCache) Keys(ctx context.Context, pattern string) ([]string, error) {
-	return r.client.Keys(ctx, pattern).Result()
+	found, err := r.client.Keys(ctx, r.key(pattern)).Result()
+	if err != nil || r.prefix == "" {
+		return found, err
+	}
+	for i, k := range found {
+		found[i] = strings.TrimPrefix(k, r.prefix+keys.Separator)
+	}
+	return found, nil
 }
 
-// FlushDB 清空当前数据库（谨慎使用）
+// FlushDB 清空缓存（谨慎使用）。
+// 前缀指向生产环境时拒绝执行；设置了其他前缀时只删除该前缀下的键，避免误删共用同一数据库的其他环境。
 func (r *RedisCache) FlushDB(ctx context.Context) error {
-	return r.client.FlushDB(ctx).Err()
+	if keys.IsProductionPrefix(r.prefix) {
+		return ErrProductionKeyPrefix
+	}
+	// 集群模式下 FLUSHDB 与 SCAN 只作用于单个节点，需要在每个主节点上分别执行
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return r.flushNode(ctx, node)
+		})
+	}
+	return r.flushNode(ctx, r.client)
+}
+
+// flushNode 清空单个节点：未设置前缀时清空数据库，否则只删除该前缀下的键
+func (r *RedisCache) flushNode(ctx context.Context, node redis.Cmdable) error {
+	if r.prefix == "" {
+		return node.FlushDB(ctx).Err()
+	}
+
+	iter := node.Scan(ctx, 0, r.key("*"), 1000).Iterator()
+	var batch []string
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == 1000 {
+			if err := r.del(ctx, batch); err != nil {
+				return fmt.Errorf("failed to delete prefixed keys: %w", err)
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan prefixed keys: %w", err)
+	}
+	if len(batch) > 0 {
+		if err := r.del(ctx, batch); err != nil {
+			return fmt.Errorf("failed to delete prefixed keys: %w", err)
+		}
+	}
+	return nil
 }
 
-// Pipeline 管道操作，提高批量操作性能
+// Pipeline 管道操作，提高批量操作性能；管道中的命令不会自动加环境前缀
 func (r *RedisCache) Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) error {
 	pipe := r.client.Pipeline()
 
@@ -178,44 +247,24 @@ func (r *RedisCache) Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner)
 
 // Lua脚本支持，用于原子操作
 func (r *RedisCache) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
-	return r.client.EvalSha(ctx, sha1, keys, args...).Result()
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = r.key(k)
+	}
+	return r.client.EvalSha(ctx, sha1, prefixed, args...).Result()
 }
 
 func (r *RedisCache) ScriptLoad(ctx context.Context, script string) (string, error) {
 	return r.client.ScriptLoad(ctx, script).Result()
 }
 
-// 集群模式支持（如果使用Redis集群）
+// NewRedisClusterCache 创建连接 Redis Cluster 的缓存实例
 func NewRedisClusterCache(addrs []string, password string) (*RedisCache, error) {
-	client := redis.NewClusterClient(&redis.ClusterOptions{
+	return NewRedisCacheWithClient(NewRedisClient(RedisClientOptions{
+		Mode:     RedisModeCluster,
 		Addrs:    addrs,
 		Password: password,
-
-		// 连接池配置
-		PoolSize:     10,
-		MinIdleConns: 5,
-		MaxIdleConns: 10,
-
-		// 超时配置
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-
-		// 重试配置
-		MaxRetries:      3,
-		MinRetryBackoff: 8 * time.Millisecond,
-		MaxRetryBackoff: 512 * time.Millisecond,
-	})
-
-	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis cluster: %w", err)
-	}
-
-	return &RedisCache{client: client}, nil
+	}))
 }
 
 // RedisClientWrapper 包装Redis客户端，提供统一的Close方法
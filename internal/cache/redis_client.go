@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 部署模式
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeCluster    = "cluster"
+	RedisModeSentinel   = "sentinel"
+)
+
+// RedisClientOptions Redis 连接配置
+type RedisClientOptions struct {
+	Mode             string   // standalone、cluster 或 sentinel，为空时按 standalone 处理
+	Addr             string   // standalone 模式的地址
+	Addrs            []string // cluster 模式的种子节点，或 sentinel 模式的哨兵地址
+	MasterName       string   // sentinel 模式监控的主节点名称
+	Password         string
+	SentinelPassword string // 哨兵自身的密码，可为空
+	DB               int    // 数据库编号，cluster 模式忽略
+}
+
+// NewRedisClient 按部署模式创建 Redis 客户端：standalone 与 sentinel 返回 *redis.Client（sentinel 模式下
+// 主节点切换后自动重连新主节点），cluster 返回 *redis.ClusterClient。不会立即建立连接，调用方按需 Ping
+func NewRedisClient(opts RedisClientOptions) redis.UniversalClient {
+	universal := &redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		Password:         opts.Password,
+		SentinelPassword: opts.SentinelPassword,
+		DB:               opts.DB,
+
+		// 连接池配置
+		PoolSize:     10,
+		MinIdleConns: 5,
+		MaxIdleConns: 10,
+
+		// 超时配置
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+
+		// 重试配置
+		MaxRetries:      3,
+		MinRetryBackoff: 8 * time.Millisecond,
+		MaxRetryBackoff: 512 * time.Millisecond,
+	}
+
+	switch opts.Mode {
+	case RedisModeCluster:
+		return redis.NewClusterClient(universal.Cluster())
+	case RedisModeSentinel:
+		return redis.NewFailoverClient(universal.Failover())
+	default:
+		universal.Addrs = []string{opts.Addr}
+		return redis.NewClient(universal.Simple())
+	}
+}
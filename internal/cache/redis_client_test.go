@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewRedisClient_Mode(t *testing.T) {
+	standalone := NewRedisClient(RedisClientOptions{Addr: "localhost:6379", DB: 2})
+	defer standalone.Close()
+	if client, ok := standalone.(*redis.Client); !ok || client.Options().Addr != "localhost:6379" || client.Options().DB != 2 {
+		t.Errorf("standalone client = %T, want *redis.Client on localhost:6379 db 2", standalone)
+	}
+
+	cluster := NewRedisClient(RedisClientOptions{Mode: RedisModeCluster, Addrs: []string{"redis-1:6379"}, DB: 2})
+	defer cluster.Close()
+	if _, ok := cluster.(*redis.ClusterClient); !ok {
+		t.Errorf("cluster client = %T, want *redis.ClusterClient", cluster)
+	}
+
+	sentinel := NewRedisClient(RedisClientOptions{Mode: RedisModeSentinel, Addrs: []string{"sentinel-1:26379"}, MasterName: "mymaster"})
+	defer sentinel.Close()
+	if client, ok := sentinel.(*redis.Client); !ok || client.Options().Addr != "FailoverClient" {
+		t.Errorf("sentinel client = %T, want failover *redis.Client", sentinel)
+	}
+}
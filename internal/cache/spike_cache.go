@@ -3,15 +3,20 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
 )
 
 // SpikeCache 秒杀缓存服务
 type SpikeCache struct {
 	client redis.Cmdable
+	prefix string // 环境键前缀，为空时不加前缀
 }
 
 // NewSpikeCache 创建秒杀缓存实例
@@ -21,42 +26,95 @@ func NewSpikeCache(client redis.Cmdable) *SpikeCache {
 	}
 }
 
-// Redis Key 模板常量
+// SetKeyPrefix 设置环境键前缀，库存、去重、冻结等键以及库存变更频道都以 prefix: 开头
+func (s *SpikeCache) SetKeyPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// Redis Key 命名空间，各段由 keys.Redis 编码拼接。
+// 同一活动的库存、售罄、去重、冻结等键以活动ID为哈希标签（如 spike:stock:{42}、spike:user:7:{42}），
+// 在 Redis Cluster 中落在同一槽位，预减、恢复与补充库存的多键 Lua 脚本才能执行
 const (
 	// 秒杀活动库存Key: spike:stock:{event_id}
-	SpikeStockKeyTemplate = "spike:stock:%d"
+	SpikeStockKeyNamespace = "spike:stock"
 
 	// 秒杀活动售罄标记Key: spike:sold_out:{event_id}
-	SpikeSoldOutKeyTemplate = "spike:sold_out:%d"
+	SpikeSoldOutKeyNamespace = "spike:sold_out"
 
 	// 用户参与秒杀去重Key: spike:user:{user_id}:{event_id}
-	SpikeUserKeyTemplate = "spike:user:%d:%d"
+	SpikeUserKeyNamespace = "spike:user"
 
 	// 秒杀活动信息缓存Key: spike:event:{event_id}
-	SpikeEventKeyTemplate = "spike:event:%d"
+	SpikeEventKeyNamespace = "spike:event"
 
 	// 幂等键缓存Key: spike:idempotency:{key}
-	SpikeIdempotencyKeyTemplate = "spike:idempotency:%s"
+	SpikeIdempotencyKeyNamespace = "spike:idempotency"
+
+	// 已发放参与令牌的用户集合Key: spike:token:issued:{event_id}
+	SpikeTokenIssuedKeyNamespace = "spike:token:issued"
+
+	// 活动冻结标记Key（库存恢复期间暂停参与）: spike:frozen:{event_id}
+	SpikeFrozenKeyNamespace = "spike:frozen"
+
+	// 库存恢复锁Key: spike:rewarm_lock:{event_id}
+	SpikeRewarmLockKeyNamespace = "spike:rewarm_lock"
+
+	// 支付提醒去重Key: spike:reminder:{order_id}:{offset_seconds}
+	SpikePaymentReminderKeyNamespace = "spike:reminder"
+
+	// 专场内用户购买次数Key（Hash，field 为用户ID）: spike:campaign:purchases:{campaign_id}
+	SpikeCampaignPurchasesKeyNamespace = "spike:campaign:purchases"
+
+	// 用户当日秒杀成功次数Key: spike:quota:daily:{date}:{user_id}，date 格式为 YYYYMMDD
+	SpikeDailyQuotaKeyNamespace = "spike:quota:daily"
+
+	// 库存变更通知频道（Pub/Sub，消息为变更后的库存）: spike:stock:changed:{event_id}
+	SpikeStockChangedChannelNamespace = "spike:stock:changed"
+)
+
+// 预减库存失败原因，与 Lua 脚本返回的消息一致
+const (
+	DecrementReasonSoldOut           = "sold_out"
+	DecrementReasonDuplicateUser     = "duplicate_user"
+	DecrementReasonStockNotFound     = "stock_not_found"
+	DecrementReasonInsufficientStock = "insufficient_stock"
+	DecrementReasonFrozen            = "frozen"
+	DecrementReasonUserLimit         = "user_limit"
 )
 
 // Lua脚本：原子性预减库存
 const luaDecrementStock = `
 -- KEYS[1]: 库存key (spike:stock:{event_id})
 -- KEYS[2]: 售罄标记key (spike:sold_out:{event_id})
--- KEYS[3]: 用户去重key (spike:user:{user_id}:{event_id})
+-- KEYS[3]: 用户去重key (spike:user:{user_id}:{event_id})，值为用户已预减的件数
+-- KEYS[4]: 活动冻结标记key (spike:frozen:{event_id})
 -- ARGV[1]: 减少的数量
 -- ARGV[2]: 用户去重TTL（秒）
 -- ARGV[3]: 售罄标记TTL（秒）
+-- ARGV[4]: 库存变更通知频道
+-- ARGV[5]: 单用户累计件数上限，0 表示每个用户只能参与一次
+
+-- 库存恢复期间暂停参与
+if redis.call('EXISTS', KEYS[4]) == 1 then
+    return {-5, 'frozen'}  -- 活动已冻结
+end
 
 -- 检查是否已售罄
 if redis.call('EXISTS', KEYS[2]) == 1 then
     return {-1, 'sold_out'}  -- 商品已售罄
 end
 
--- 检查用户是否已经参与
-if redis.call('EXISTS', KEYS[3]) == 1 then
+local decrement = tonumber(ARGV[1])
+
+-- 检查用户已购件数
+local purchased = tonumber(redis.call('GET', KEYS[3]) or '0')
+local max_per_user = tonumber(ARGV[5])
+if purchased > 0 and max_per_user <= 0 then
     return {-2, 'duplicate_user'}  -- 用户重复参与
 end
+if max_per_user > 0 and purchased + decrement > max_per_user then
+    return {-6, 'user_limit'}  -- 超过单用户累计上限
+end
 
 -- 获取当前库存
 local current_stock = redis.call('GET', KEYS[1])
@@ -65,52 +123,38 @@ if current_stock == false then
 end
 
 current_stock = tonumber(current_stock)
-local decrement = tonumber(ARGV[1])
 
 -- 检查库存是否足够
 if current_stock < decrement then
     -- 库存不足，设置售罄标记
     redis.call('SETEX', KEYS[2], tonumber(ARGV[3]), '1')
+    redis.call('PUBLISH', ARGV[4], current_stock)
     return {-4, 'insufficient_stock'}
 end
 
 -- 减少库存
 local new_stock = redis.call('DECRBY', KEYS[1], decrement)
 
--- 设置用户去重标记
-redis.call('SETEX', KEYS[3], tonumber(ARGV[2]), '1')
+-- 累加用户已购件数
+redis.call('INCRBY', KEYS[3], decrement)
+redis.call('EXPIRE', KEYS[3], tonumber(ARGV[2]))
 
 -- 如果库存为0，设置售罄标记
 if new_stock <= 0 then
     redis.call('SETEX', KEYS[2], tonumber(ARGV[3]), '1')
 end
 
+redis.call('PUBLISH', ARGV[4], new_stock)
 return {new_stock, 'success'}
 `
 
-// Lua脚本：批量检查库存状态
-const luaCheckStockBatch = `
--- KEYS: 多个库存key
--- 返回: 每个key对应的库存数量，不存在返回-1
-
-local result = {}
-for i = 1, #KEYS do
-    local stock = redis.call('GET', KEYS[i])
-    if stock == false then
-        result[i] = -1
-    else
-        result[i] = tonumber(stock)
-    end
-end
-return result
-`
-
 // Lua脚本：恢复库存（用于订单取消/过期）
 const luaRestoreStock = `
 -- KEYS[1]: 库存key
 -- KEYS[2]: 售罄标记key
 -- KEYS[3]: 用户去重key
 -- ARGV[1]: 恢复的数量
+-- ARGV[2]: 库存变更通知频道
 
 -- 增加库存
 local new_stock = redis.call('INCRBY', KEYS[1], tonumber(ARGV[1]))
@@ -118,38 +162,221 @@ local new_stock = redis.call('INCRBY', KEYS[1], tonumber(ARGV[1]))
 -- 删除售罄标记（如果存在）
 redis.call('DEL', KEYS[2])
 
--- 删除用户去重标记（如果存在）
-redis.call('DEL', KEYS[3])
+-- 扣回用户已购件数，归零时删除用户去重标记
+if redis.call('EXISTS', KEYS[3]) == 1 and redis.call('DECRBY', KEYS[3], tonumber(ARGV[1])) <= 0 then
+    redis.call('DEL', KEYS[3])
+end
+
+redis.call('PUBLISH', ARGV[2], new_stock)
+return new_stock
+`
+
+// Lua脚本：占用用户购买件数（分桶模式在扣减分桶前调用）
+const luaClaimUserQuantity = `
+-- KEYS[1]: 用户去重key (spike:user:{user_id}:{event_id})
+-- ARGV[1]: 购买数量
+-- ARGV[2]: 单用户累计件数上限，0 表示每个用户只能参与一次
+-- ARGV[3]: 用户去重TTL（秒）
+
+local purchased = tonumber(redis.call('GET', KEYS[1]) or '0')
+local max_per_user = tonumber(ARGV[2])
+if purchased > 0 and max_per_user <= 0 then
+    return -2  -- 用户重复参与
+end
+if max_per_user > 0 and purchased + tonumber(ARGV[1]) > max_per_user then
+    return -6  -- 超过单用户累计上限
+end
+
+redis.call('INCRBY', KEYS[1], tonumber(ARGV[1]))
+redis.call('EXPIRE', KEYS[1], tonumber(ARGV[3]))
+return 1
+`
+
+// Lua脚本：归还用户购买件数（用于分桶预减失败、订单取消/过期）
+const luaReleaseUserQuantity = `
+-- KEYS[1]: 用户去重key
+-- ARGV[1]: 归还的数量
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return 0
+end
+if redis.call('DECRBY', KEYS[1], tonumber(ARGV[1])) <= 0 then
+    redis.call('DEL', KEYS[1])
+end
+return 1
+`
 
+// Lua脚本：活动进行中补充库存
+const luaTopUpStock = `
+-- KEYS[1]: 库存key
+-- KEYS[2]: 售罄标记key
+-- ARGV[1]: 增加的数量
+-- ARGV[2]: 库存变更通知频道
+
+-- 库存尚未预热时不创建，留给预热或库存守护按数据库重建
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return -1
+end
+
+local new_stock = redis.call('INCRBY', KEYS[1], tonumber(ARGV[1]))
+redis.call('DEL', KEYS[2])
+redis.call('PUBLISH', ARGV[2], new_stock)
 return new_stock
 `
 
+// Lua脚本：释放库存恢复锁（仅持有者可释放）
+const luaReleaseLock = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// Lua脚本：发放参与令牌名额
+const luaReserveToken = `
+-- KEYS[1]: 已发放用户集合key (spike:token:issued:{event_id})
+-- ARGV[1]: 用户ID
+-- ARGV[2]: 发放上限
+-- ARGV[3]: 集合TTL（秒）
+
+-- 已领取过的用户直接返回成功，不重复占用名额
+if redis.call('SISMEMBER', KEYS[1], ARGV[1]) == 1 then
+    return 1
+end
+
+if redis.call('SCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+    return 0  -- 名额已发完
+end
+
+redis.call('SADD', KEYS[1], ARGV[1])
+if tonumber(ARGV[3]) > 0 then
+    redis.call('EXPIRE', KEYS[1], tonumber(ARGV[3]))
+end
+return 1
+`
+
+// Lua脚本：占用专场购买次数
+const luaReserveCampaignQuota = `
+-- KEYS[1]: 专场购买次数key (spike:campaign:purchases:{campaign_id})
+-- ARGV[1]: 用户ID
+-- ARGV[2]: 单用户购买次数上限
+-- ARGV[3]: key TTL（秒）
+
+local used = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+if used >= tonumber(ARGV[2]) then
+    return 0  -- 已达到上限
+end
+
+redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+if tonumber(ARGV[3]) > 0 then
+    redis.call('EXPIRE', KEYS[1], tonumber(ARGV[3]))
+end
+return 1
+`
+
+// Lua脚本：归还专场购买次数（用于预减库存失败、订单取消/过期）
+const luaReleaseCampaignQuota = `
+-- KEYS[1]: 专场购买次数key
+-- ARGV[1]: 用户ID
+
+if redis.call('HINCRBY', KEYS[1], ARGV[1], -1) <= 0 then
+    redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return 1
+`
+
+// Lua脚本：占用用户当日秒杀成功次数
+const luaReserveDailyQuota = `
+-- KEYS[1]: 用户当日次数key (spike:quota:daily:{date}:{user_id})
+-- ARGV[1]: 当日次数上限
+-- ARGV[2]: key TTL（秒）
+
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+if used >= tonumber(ARGV[1]) then
+    return 0  -- 已达到上限
+end
+
+redis.call('INCR', KEYS[1])
+if tonumber(ARGV[2]) > 0 then
+    redis.call('EXPIRE', KEYS[1], tonumber(ARGV[2]))
+end
+return 1
+`
+
+// Lua脚本：归还用户当日秒杀成功次数（用于预减库存失败、落库时库存不足）
+const luaReleaseDailyQuota = `
+-- KEYS[1]: 用户当日次数key
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return 0
+end
+if redis.call('DECR', KEYS[1]) <= 0 then
+    redis.call('DEL', KEYS[1])
+end
+return 1
+`
+
 // DecrementStockResult 预减库存结果
 type DecrementStockResult struct {
 	Success        bool   `json:"success"`
 	RemainingStock int64  `json:"remaining_stock"`
 	Message        string `json:"message"`
+	Reason         string `json:"reason,omitempty"` // 失败原因，见 DecrementReason* 常量
 }
 
 // 生成Redis Key的辅助函数
 func (s *SpikeCache) getStockKey(eventID int64) string {
-	return fmt.Sprintf(SpikeStockKeyTemplate, eventID)
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeStockKeyNamespace, keys.HashTag(eventID)))
 }
 
 func (s *SpikeCache) getSoldOutKey(eventID int64) string {
-	return fmt.Sprintf(SpikeSoldOutKeyTemplate, eventID)
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeSoldOutKeyNamespace, keys.HashTag(eventID)))
 }
 
 func (s *SpikeCache) getUserKey(userID, eventID int64) string {
-	return fmt.Sprintf(SpikeUserKeyTemplate, userID, eventID)
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeUserKeyNamespace, userID, keys.HashTag(eventID)))
 }
 
 func (s *SpikeCache) getEventKey(eventID int64) string {
-	return fmt.Sprintf(SpikeEventKeyTemplate, eventID)
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeEventKeyNamespace, keys.HashTag(eventID)))
 }
 
+// getIdempotencyKey 的 key 由调用方用 keys.Redis 构造（如 processed:{idempotency_key}），此处只加命名空间前缀
 func (s *SpikeCache) getIdempotencyKey(key string) string {
-	return fmt.Sprintf(SpikeIdempotencyKeyTemplate, key)
+	return keys.WithPrefix(s.prefix, SpikeIdempotencyKeyNamespace+keys.Separator+key)
+}
+
+func (s *SpikeCache) getTokenIssuedKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeTokenIssuedKeyNamespace, keys.HashTag(eventID)))
+}
+
+func (s *SpikeCache) getFrozenKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeFrozenKeyNamespace, keys.HashTag(eventID)))
+}
+
+func (s *SpikeCache) getRewarmLockKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeRewarmLockKeyNamespace, keys.HashTag(eventID)))
+}
+
+func (s *SpikeCache) getPaymentReminderKey(orderID int64, offset time.Duration) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikePaymentReminderKeyNamespace, orderID, int64(offset/time.Second)))
+}
+
+// StockChangedChannel 返回活动的库存变更通知频道（不含环境前缀）
+func StockChangedChannel(eventID int64) string {
+	return keys.Redis(SpikeStockChangedChannelNamespace, eventID)
+}
+
+func (s *SpikeCache) getStockChangedChannel(eventID int64) string {
+	return keys.WithPrefix(s.prefix, StockChangedChannel(eventID))
+}
+
+func (s *SpikeCache) getCampaignPurchasesKey(campaignID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeCampaignPurchasesKeyNamespace, campaignID))
+}
+
+func (s *SpikeCache) getDailyQuotaKey(date string, userID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeDailyQuotaKeyNamespace, date, userID))
 }
 
 // InitStock 初始化秒杀活动库存
@@ -184,6 +411,88 @@ func (s *SpikeCache) GetStock(ctx context.Context, eventID int64) (int64, error)
 	return stock, nil
 }
 
+// GetStockTTL 获取库存键的剩余过期时间；exists 为 false 表示库存键不存在，
+// ttl 为负数表示库存键未设置过期时间
+func (s *SpikeCache) GetStockTTL(ctx context.Context, eventID int64) (ttl time.Duration, exists bool, err error) {
+	key := s.getStockKey(eventID)
+	buckets, err := s.stockBuckets(ctx, eventID)
+	if err != nil {
+		return 0, false, err
+	}
+	if buckets > 1 {
+		// 分桶与布局键同时写入，以布局键的过期时间为准
+		key = s.getStockBucketsKey(eventID)
+	}
+	ttl, err = s.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get stock ttl: %w", err)
+	}
+	// PTTL 对不存在的键返回 -2，对未设置过期时间的键返回 -1
+	if ttl == -2 {
+		return 0, false, nil
+	}
+	return ttl, true, nil
+}
+
+// ExtendStockTTL 将库存键（分桶模式下为布局键与各分桶）、售罄标记与活动信息缓存的过期时间统一设置为 ttl，不存在的键忽略
+func (s *SpikeCache) ExtendStockTTL(ctx context.Context, eventID int64, ttl time.Duration) error {
+	buckets, err := s.stockBuckets(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	pipe := s.client.Pipeline()
+	pipe.Expire(ctx, s.getStockKey(eventID), ttl)
+	if buckets > 1 {
+		for _, key := range s.bucketKeys(eventID, buckets) {
+			pipe.Expire(ctx, key, ttl)
+		}
+	}
+	pipe.Expire(ctx, s.getSoldOutKey(eventID), ttl)
+	pipe.Expire(ctx, s.getEventKey(eventID), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to extend stock ttl: %w", err)
+	}
+	return nil
+}
+
+// PurgeEvent 删除已结束活动的库存（含分桶）、售罄标记、活动信息缓存、令牌计数、冻结标记、重新预热锁
+// 以及 userIDs 的参与标记，返回实际删除的键数。逐键删除，兼容键分布在不同槽位的 Redis Cluster
+func (s *SpikeCache) PurgeEvent(ctx context.Context, eventID int64, userIDs []int64) (int64, error) {
+	eventKeys := []string{
+		s.getStockKey(eventID),
+		s.getSoldOutKey(eventID),
+		s.getEventKey(eventID),
+		s.getTokenIssuedKey(eventID),
+		s.getFrozenKey(eventID),
+		s.getRewarmLockKey(eventID),
+	}
+	buckets, err := s.stockBuckets(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if buckets > 1 {
+		eventKeys = append(eventKeys, s.bucketKeys(eventID, buckets)...)
+	}
+	for _, userID := range userIDs {
+		eventKeys = append(eventKeys, s.getUserKey(userID, eventID))
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(eventKeys))
+	for i, key := range eventKeys {
+		cmds[i] = pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to purge spike event keys: %w", err)
+	}
+
+	var deleted int64
+	for _, cmd := range cmds {
+		deleted += cmd.Val()
+	}
+	return deleted, nil
+}
+
 // IsSoldOut 检查是否已售罄
 func (s *SpikeCache) IsSoldOut(ctx context.Context, eventID int64) (bool, error) {
 	key := s.getSoldOutKey(eventID)
@@ -196,7 +505,7 @@ func (s *SpikeCache) IsSoldOut(ctx context.Context, eventID int64) (bool, error)
 	return result.Val() > 0, nil
 }
 
-// IsUserParticipated 检查用户是否已参与
+// IsUserParticipated 检查用户是否已参与（已预减件数大于 0）
 func (s *SpikeCache) IsUserParticipated(ctx context.Context, userID, eventID int64) (bool, error) {
 	key := s.getUserKey(userID, eventID)
 
@@ -208,16 +517,42 @@ func (s *SpikeCache) IsUserParticipated(ctx context.Context, userID, eventID int
 	return result.Val() > 0, nil
 }
 
-// DecrementStock 原子性预减库存（核心方法）
-func (s *SpikeCache) DecrementStock(ctx context.Context, eventID, userID, quantity int64, userTTL, soldOutTTL time.Duration) (*DecrementStockResult, error) {
+// GetUserPurchased 获取用户在活动中已预减的件数，未参与时返回 0
+func (s *SpikeCache) GetUserPurchased(ctx context.Context, userID, eventID int64) (int64, error) {
+	purchased, err := s.client.Get(ctx, s.getUserKey(userID, eventID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user purchased quantity: %w", err)
+	}
+	return purchased, nil
+}
+
+// eval 执行 Lua 脚本并按脚本名记录耗时，redis.Nil 不计为失败
+func (s *SpikeCache) eval(ctx context.Context, name, script string, keys []string, args ...interface{}) *redis.Cmd {
+	start := time.Now()
+	cmd := s.client.Eval(ctx, script, keys, args...)
+	err := cmd.Err()
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	metrics.RedisScriptDuration.Observe(metrics.Since(start), name, metrics.Result(err))
+	return cmd
+}
+
+// DecrementStock 原子性预减库存（核心方法）。用户去重键记录用户已预减的件数：
+// maxPerUser 大于 0 时用户可多次参与，累计件数不超过 maxPerUser；为 0 时每个用户只能参与一次
+func (s *SpikeCache) DecrementStock(ctx context.Context, eventID, userID, quantity, maxPerUser int64, userTTL, soldOutTTL time.Duration) (*DecrementStockResult, error) {
 	stockKey := s.getStockKey(eventID)
 	soldOutKey := s.getSoldOutKey(eventID)
 	userKey := s.getUserKey(userID, eventID)
+	frozenKey := s.getFrozenKey(eventID)
 
 	// 执行Lua脚本
-	result := s.client.Eval(ctx, luaDecrementStock,
-		[]string{stockKey, soldOutKey, userKey},
-		quantity, int(userTTL.Seconds()), int(soldOutTTL.Seconds()))
+	result := s.eval(ctx, "decrement_stock", luaDecrementStock,
+		[]string{stockKey, soldOutKey, userKey, frozenKey},
+		quantity, int(userTTL.Seconds()), int(soldOutTTL.Seconds()), s.getStockChangedChannel(eventID), maxPerUser)
 
 	if result.Err() != nil {
 		return nil, fmt.Errorf("failed to execute decrement stock script: %w", result.Err())
@@ -234,7 +569,7 @@ func (s *SpikeCache) DecrementStock(ctx context.Context, eventID, userID, quanti
 		return nil, fmt.Errorf("unexpected stock value type")
 	}
 
-	_, ok = values[1].(string)
+	reason, ok := values[1].(string)
 	if !ok {
 		return nil, fmt.Errorf("unexpected message type")
 	}
@@ -245,24 +580,42 @@ func (s *SpikeCache) DecrementStock(ctx context.Context, eventID, userID, quanti
 			Success:        false,
 			RemainingStock: 0,
 			Message:        "商品已售罄",
+			Reason:         reason,
 		}, nil
 	case -2:
 		return &DecrementStockResult{
 			Success:        false,
 			RemainingStock: 0,
 			Message:        "用户重复参与",
+			Reason:         reason,
 		}, nil
 	case -3:
 		return &DecrementStockResult{
 			Success:        false,
 			RemainingStock: 0,
 			Message:        "库存信息不存在",
+			Reason:         reason,
 		}, nil
 	case -4:
 		return &DecrementStockResult{
 			Success:        false,
 			RemainingStock: 0,
 			Message:        "库存不足",
+			Reason:         reason,
+		}, nil
+	case -5:
+		return &DecrementStockResult{
+			Success:        false,
+			RemainingStock: 0,
+			Message:        "活动库存恢复中，请稍后重试",
+			Reason:         reason,
+		}, nil
+	case -6:
+		return &DecrementStockResult{
+			Success:        false,
+			RemainingStock: 0,
+			Message:        "超过单用户购买上限",
+			Reason:         reason,
 		}, nil
 	default:
 		return &DecrementStockResult{
@@ -273,15 +626,91 @@ func (s *SpikeCache) DecrementStock(ctx context.Context, eventID, userID, quanti
 	}
 }
 
-// RestoreStock 恢复库存（用于订单取消/过期）
+// FreezeEvent 冻结活动，冻结期间预减库存直接失败；ttl 用于兜底，避免恢复流程异常退出后永久冻结
+func (s *SpikeCache) FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.getFrozenKey(eventID), "1", ttl).Err(); err != nil {
+		return fmt.Errorf("failed to freeze spike event: %w", err)
+	}
+	return nil
+}
+
+// UnfreezeEvent 解除活动冻结
+func (s *SpikeCache) UnfreezeEvent(ctx context.Context, eventID int64) error {
+	if err := s.client.Del(ctx, s.getFrozenKey(eventID)).Err(); err != nil {
+		return fmt.Errorf("failed to unfreeze spike event: %w", err)
+	}
+	return nil
+}
+
+// IsEventFrozen 检查活动是否处于冻结状态
+func (s *SpikeCache) IsEventFrozen(ctx context.Context, eventID int64) (bool, error) {
+	result := s.client.Exists(ctx, s.getFrozenKey(eventID))
+	if result.Err() != nil {
+		return false, fmt.Errorf("failed to check frozen status: %w", result.Err())
+	}
+	return result.Val() > 0, nil
+}
+
+// AcquireRewarmLock 获取库存恢复锁，owner 用于释放时校验持有者
+func (s *SpikeCache) AcquireRewarmLock(ctx context.Context, eventID int64, owner string, ttl time.Duration) (bool, error) {
+	ok, err := s.client.SetNX(ctx, s.getRewarmLockKey(eventID), owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire rewarm lock: %w", err)
+	}
+	return ok, nil
+}
+
+// ReleaseRewarmLock 释放库存恢复锁，仅当仍由 owner 持有时删除
+func (s *SpikeCache) ReleaseRewarmLock(ctx context.Context, eventID int64, owner string) error {
+	if err := s.eval(ctx, "release_lock", luaReleaseLock, []string{s.getRewarmLockKey(eventID)}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release rewarm lock: %w", err)
+	}
+	return nil
+}
+
+// MarkPaymentReminderSent 标记订单在指定提醒档位已发送，首次标记返回 true，重复标记返回 false
+func (s *SpikeCache) MarkPaymentReminderSent(ctx context.Context, orderID int64, offset, ttl time.Duration) (bool, error) {
+	ok, err := s.client.SetNX(ctx, s.getPaymentReminderKey(orderID, offset), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark payment reminder: %w", err)
+	}
+	return ok, nil
+}
+
+// ReserveParticipationToken 为用户占用一个参与令牌名额，已占用过的用户直接返回 true，名额用完返回 false
+func (s *SpikeCache) ReserveParticipationToken(ctx context.Context, eventID, userID, maxTokens int64, ttl time.Duration) (bool, error) {
+	key := s.getTokenIssuedKey(eventID)
+
+	result := s.eval(ctx, "reserve_token", luaReserveToken, []string{key}, userID, maxTokens, int(ttl.Seconds()))
+	if result.Err() != nil {
+		return false, fmt.Errorf("failed to execute reserve token script: %w", result.Err())
+	}
+
+	issued, ok := result.Val().(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected script result type")
+	}
+
+	return issued == 1, nil
+}
+
+// RestoreStock 恢复库存（用于订单取消/过期）并扣回用户已购件数，分桶模式下恢复到用户的起始分桶
 func (s *SpikeCache) RestoreStock(ctx context.Context, eventID, userID, quantity int64) (int64, error) {
+	buckets, err := s.stockBuckets(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if buckets > 1 {
+		return s.restoreBucket(ctx, eventID, userID, quantity, buckets)
+	}
+
 	stockKey := s.getStockKey(eventID)
 	soldOutKey := s.getSoldOutKey(eventID)
 	userKey := s.getUserKey(userID, eventID)
 
-	result := s.client.Eval(ctx, luaRestoreStock,
+	result := s.eval(ctx, "restore_stock", luaRestoreStock,
 		[]string{stockKey, soldOutKey, userKey},
-		quantity)
+		quantity, s.getStockChangedChannel(eventID))
 
 	if result.Err() != nil {
 		return 0, fmt.Errorf("failed to execute restore stock script: %w", result.Err())
@@ -295,32 +724,124 @@ func (s *SpikeCache) RestoreStock(ctx context.Context, eventID, userID, quantity
 	return newStock, nil
 }
 
-// BatchCheckStock 批量检查多个活动的库存状态
-func (s *SpikeCache) BatchCheckStock(ctx context.Context, eventIDs []int64) (map[int64]int64, error) {
-	if len(eventIDs) == 0 {
-		return make(map[int64]int64), nil
+// TopUpStock 为进行中的活动补充库存并清除售罄标记，与预减库存在同一 Redis 上原子执行；
+// 库存键不存在时不做修改并返回 -1。分桶模式下补充量均分到各分桶
+func (s *SpikeCache) TopUpStock(ctx context.Context, eventID, delta int64) (int64, error) {
+	buckets, err := s.stockBuckets(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if buckets > 1 {
+		return s.topUpBuckets(ctx, eventID, delta, buckets)
 	}
 
-	keys := make([]string, len(eventIDs))
-	for i, eventID := range eventIDs {
-		keys[i] = s.getStockKey(eventID)
+	result := s.eval(ctx, "top_up_stock", luaTopUpStock,
+		[]string{s.getStockKey(eventID), s.getSoldOutKey(eventID)},
+		delta, s.getStockChangedChannel(eventID))
+	if result.Err() != nil {
+		return 0, fmt.Errorf("failed to execute top up stock script: %w", result.Err())
 	}
 
-	result := s.client.Eval(ctx, luaCheckStockBatch, keys)
+	newStock, ok := result.Val().(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected script result type")
+	}
+
+	return newStock, nil
+}
+
+// ReserveCampaignQuota 占用用户在专场内的一次购买次数，已达到 max 时返回 false；
+// ttl 应覆盖专场结束后订单仍可能被取消的时间，到期后计数整体清除
+func (s *SpikeCache) ReserveCampaignQuota(ctx context.Context, campaignID, userID, max int64, ttl time.Duration) (bool, error) {
+	result := s.eval(ctx, "reserve_campaign_quota", luaReserveCampaignQuota,
+		[]string{s.getCampaignPurchasesKey(campaignID)},
+		userID, max, int64(ttl/time.Second))
 	if result.Err() != nil {
-		return nil, fmt.Errorf("failed to execute batch check stock script: %w", result.Err())
+		return false, fmt.Errorf("failed to execute reserve campaign quota script: %w", result.Err())
 	}
 
-	values, ok := result.Val().([]interface{})
+	reserved, ok := result.Val().(int64)
 	if !ok {
-		return nil, fmt.Errorf("unexpected script result format")
+		return false, fmt.Errorf("unexpected script result type")
+	}
+
+	return reserved == 1, nil
+}
+
+// ReleaseCampaignQuota 归还用户在专场内的一次购买次数
+func (s *SpikeCache) ReleaseCampaignQuota(ctx context.Context, campaignID, userID int64) error {
+	result := s.eval(ctx, "release_campaign_quota", luaReleaseCampaignQuota,
+		[]string{s.getCampaignPurchasesKey(campaignID)},
+		userID)
+	if result.Err() != nil {
+		return fmt.Errorf("failed to execute release campaign quota script: %w", result.Err())
+	}
+	return nil
+}
+
+// ReserveDailyQuota 占用用户在 date 当日的一次秒杀成功次数，已达到 max 时返回 false；
+// ttl 应覆盖当日剩余时间及落库失败时归还次数的窗口
+func (s *SpikeCache) ReserveDailyQuota(ctx context.Context, date string, userID, max int64, ttl time.Duration) (bool, error) {
+	result := s.eval(ctx, "reserve_daily_quota", luaReserveDailyQuota,
+		[]string{s.getDailyQuotaKey(date, userID)},
+		max, int64(ttl/time.Second))
+	if result.Err() != nil {
+		return false, fmt.Errorf("failed to execute reserve daily quota script: %w", result.Err())
+	}
+
+	reserved, ok := result.Val().(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected script result type")
+	}
+
+	return reserved == 1, nil
+}
+
+// ReleaseDailyQuota 归还用户在 date 当日的一次秒杀成功次数，计数已过期时忽略
+func (s *SpikeCache) ReleaseDailyQuota(ctx context.Context, date string, userID int64) error {
+	result := s.eval(ctx, "release_daily_quota", luaReleaseDailyQuota,
+		[]string{s.getDailyQuotaKey(date, userID)})
+	if result.Err() != nil {
+		return fmt.Errorf("failed to execute release daily quota script: %w", result.Err())
+	}
+	return nil
+}
+
+// GetDailyQuotaUsage 获取用户在 date 当日已使用的秒杀成功次数
+func (s *SpikeCache) GetDailyQuotaUsage(ctx context.Context, date string, userID int64) (int64, error) {
+	used, err := s.client.Get(ctx, s.getDailyQuotaKey(date, userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get daily quota usage: %w", err)
+	}
+	return used, nil
+}
+
+// BatchCheckStock 批量检查多个活动的库存状态
+func (s *SpikeCache) BatchCheckStock(ctx context.Context, eventIDs []int64) (map[int64]int64, error) {
+	if len(eventIDs) == 0 {
+		return make(map[int64]int64), nil
+	}
+
+	// 不同活动的库存键分布在不同槽位，用管道代替多键脚本以兼容 Redis Cluster
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(eventIDs))
+	for i, eventID := range eventIDs {
+		cmds[i] = pipe.Get(ctx, s.getStockKey(eventID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to batch check stock: %w", err)
 	}
 
 	stockMap := make(map[int64]int64)
-	for i, value := range values {
-		stock, ok := value.(int64)
-		if !ok {
-			return nil, fmt.Errorf("unexpected stock value type at index %d", i)
+	for i, cmd := range cmds {
+		stock, err := cmd.Int64()
+		if errors.Is(err, redis.Nil) {
+			stock = -1
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse stock of event %d: %w", eventIDs[i], err)
 		}
 		stockMap[eventIDs[i]] = stock
 	}
@@ -418,19 +939,29 @@ func (s *SpikeCache) GetEventInfo(ctx context.Context, eventID int64, dest inter
 	return result.Scan(dest)
 }
 
-// WarmupStock 预热库存（在秒杀开始前调用）
+// InvalidateEventInfo 删除缓存的秒杀活动信息，下次读取时从数据库重新加载
+func (s *SpikeCache) InvalidateEventInfo(ctx context.Context, eventID int64) error {
+	if err := s.client.Del(ctx, s.getEventKey(eventID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate event info: %w", err)
+	}
+	return nil
+}
+
+// WarmupStock 预热库存（在秒杀开始前调用），以单键模式预热
 func (s *SpikeCache) WarmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error {
 	// 预热库存
 	if err := s.InitStock(ctx, eventID, stock, ttl); err != nil {
 		return fmt.Errorf("failed to warmup stock: %w", err)
 	}
 
-	// 清除可能存在的售罄标记
-	soldOutKey := s.getSoldOutKey(eventID)
-	if err := s.client.Del(ctx, soldOutKey).Err(); err != nil {
+	// 清除可能存在的售罄标记与分桶布局，残留的分桶键随过期时间清除
+	if err := s.client.Del(ctx, s.getSoldOutKey(eventID), s.getStockBucketsKey(eventID)).Err(); err != nil {
 		return fmt.Errorf("failed to clear sold out flag: %w", err)
 	}
 
+	// 通知等待库存变更的请求，失败不影响预热
+	s.client.Publish(ctx, s.getStockChangedChannel(eventID), stock)
+
 	return nil
 }
 
@@ -449,6 +980,7 @@ func (s *SpikeCache) GetStockInfo(ctx context.Context, eventID int64) (*StockInf
 	pipe := s.client.Pipeline()
 	stockCmd := pipe.Get(ctx, stockKey)
 	soldOutCmd := pipe.Exists(ctx, soldOutKey)
+	bucketsCmd := pipe.Get(ctx, s.getStockBucketsKey(eventID))
 
 	_, err := pipe.Exec(ctx)
 	if err != nil && err != redis.Nil {
@@ -457,8 +989,17 @@ func (s *SpikeCache) GetStockInfo(ctx context.Context, eventID int64) (*StockInf
 
 	info := &StockInfo{}
 
-	// 处理库存信息
-	if stockCmd.Err() == redis.Nil {
+	// 处理库存信息，分桶模式下汇总各分桶，任一分桶丢失视为库存不存在
+	if buckets, err := bucketsCmd.Int(); err == nil && buckets > 1 {
+		total, complete, err := s.sumBuckets(ctx, eventID, buckets)
+		if err != nil {
+			return nil, err
+		}
+		info.Stock, info.Exists = total, complete
+		if !complete {
+			info.Stock = -1
+		}
+	} else if stockCmd.Err() == redis.Nil {
 		info.Stock = -1
 		info.Exists = false
 	} else if stockCmd.Err() != nil {
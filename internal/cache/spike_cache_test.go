@@ -6,143 +6,39 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 )
 
 // Helper函数
 func GetSpikeStockKey(eventID int64) string {
-	return fmt.Sprintf("spike:stock:%d", eventID)
+	return fmt.Sprintf("spike:stock:{%d}", eventID)
 }
 
 func GetSpikeSoldOutKey(eventID int64) string {
-	return fmt.Sprintf("spike:sold_out:%d", eventID)
+	return fmt.Sprintf("spike:sold_out:{%d}", eventID)
 }
 
 func GetSpikeUserKey(userID, eventID int64) string {
-	return fmt.Sprintf("spike:user:%d:%d", userID, eventID)
+	return fmt.Sprintf("spike:user:%d:{%d}", userID, eventID)
 }
 
 func GetSpikeEventKey(eventID int64) string {
-	return fmt.Sprintf("spike:event:%d", eventID)
+	return fmt.Sprintf("spike:event:{%d}", eventID)
 }
 
-// SimpleMockClient 简化的Mock Redis客户端，只实现必要的方法
-type SimpleMockClient struct {
-	data map[string]interface{}
+// newTestSpikeCache 基于 miniredis 创建秒杀缓存，Lua 脚本在内存实例中真实执行
+func newTestSpikeCache(t *testing.T) (*redis.Client, *SpikeCache) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client, NewSpikeCache(client)
 }
 
-func NewSimpleMockClient() *SimpleMockClient {
-	return &SimpleMockClient{
-		data: make(map[string]interface{}),
-	}
-}
-
-func (m *SimpleMockClient) Get(ctx context.Context, key string) *redis.StringCmd {
-	cmd := redis.NewStringCmd(ctx, "get", key)
-	if val, exists := m.data[key]; exists {
-		if str, ok := val.(string); ok {
-			cmd.SetVal(str)
-		}
-	} else {
-		cmd.SetErr(redis.Nil)
-	}
-	return cmd
-}
-
-func (m *SimpleMockClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
-	m.data[key] = value
-	cmd := redis.NewStatusCmd(ctx, "set", key, value)
-	cmd.SetVal("OK")
-	return cmd
-}
-
-func (m *SimpleMockClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
-	cmd := redis.NewBoolCmd(ctx, "setnx", key, value)
-	_, exists := m.data[key]
-	if !exists {
-		m.data[key] = value
-		cmd.SetVal(true)
-	} else {
-		cmd.SetVal(false)
-	}
-	return cmd
-}
-
-func (m *SimpleMockClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
-	count := int64(0)
-	for _, key := range keys {
-		if _, exists := m.data[key]; exists {
-			delete(m.data, key)
-			count++
-		}
-	}
-	cmd := redis.NewIntCmd(ctx, "del")
-	cmd.SetVal(count)
-	return cmd
-}
-
-func (m *SimpleMockClient) Exists(ctx context.Context, keys ...string) *redis.IntCmd {
-	count := int64(0)
-	for _, key := range keys {
-		if _, exists := m.data[key]; exists {
-			count++
-		}
-	}
-	cmd := redis.NewIntCmd(ctx, "exists")
-	cmd.SetVal(count)
-	return cmd
-}
-
-func (m *SimpleMockClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
-	cmd := redis.NewCmd(ctx, "eval", script)
-	// 模拟不同脚本的返回值
-	if len(args) > 0 {
-		// 默认返回成功结果 [剩余库存, 成功标志, 消息]
-		result := []interface{}{int64(99), int64(1), "操作成功"}
-		cmd.SetVal(result)
-	} else {
-		// 批量检查结果
-		result := []interface{}{int64(100), int64(0)} // [库存, 售罄标记]
-		cmd.SetVal(result)
-	}
-	return cmd
-}
-
-// 实现Pipeline（简化版）
-func (m *SimpleMockClient) Pipeline() redis.Pipeliner {
-	return &MockPipeliner{}
-}
-
-// MockPipeliner 简化的Pipeline实现
-type MockPipeliner struct{}
-
-func (p *MockPipeliner) Get(ctx context.Context, key string) *redis.StringCmd {
-	cmd := redis.NewStringCmd(ctx, "get", key)
-	cmd.SetVal("1") // 默认值
-	return cmd
-}
-
-func (p *MockPipeliner) Exec(ctx context.Context) ([]redis.Cmder, error) {
-	return []redis.Cmder{}, nil
-}
-
-func (p *MockPipeliner) Len() int { return 0 }
-
-// 为了满足接口要求而添加的空方法（仅为了编译通过）
-func (m *SimpleMockClient) Pipeline() redis.Pipeliner { return &MockPipeliner{} }
-func (m *SimpleMockClient) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
-	return nil, nil
-}
-func (m *SimpleMockClient) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
-	return nil, nil
-}
-func (m *SimpleMockClient) TxPipeline() redis.Pipeliner { return &MockPipeliner{} }
-
 // 测试实际需要的函数
 func TestSpikeCache_WarmupStock(t *testing.T) {
-	// 使用简化的测试实现
-	client := NewSimpleMockClient()
-	spikeCache := &SpikeCache{client: client}
+	_, spikeCache := newTestSpikeCache(t)
 
 	err := spikeCache.WarmupStock(context.Background(), 1, 100, time.Hour)
 	if err != nil {
@@ -151,8 +47,7 @@ func TestSpikeCache_WarmupStock(t *testing.T) {
 }
 
 func TestSpikeCache_GetStockInfo(t *testing.T) {
-	client := NewSimpleMockClient()
-	spikeCache := &SpikeCache{client: client}
+	client, spikeCache := newTestSpikeCache(t)
 
 	// 预设库存数据
 	client.Set(context.Background(), GetSpikeStockKey(1), "100", time.Hour)
@@ -168,11 +63,11 @@ func TestSpikeCache_GetStockInfo(t *testing.T) {
 }
 
 func TestSpikeCache_IsSoldOut(t *testing.T) {
-	client := NewSimpleMockClient()
-	spikeCache := &SpikeCache{client: client}
+	_, spikeCache := newTestSpikeCache(t)
+	ctx := context.Background()
 
 	// 测试未售罄的情况
-	soldOut, err := spikeCache.IsSoldOut(context.Background(), 1)
+	soldOut, err := spikeCache.IsSoldOut(ctx, 1)
 	if err != nil {
 		t.Errorf("IsSoldOut() error = %v", err)
 	}
@@ -180,29 +75,31 @@ func TestSpikeCache_IsSoldOut(t *testing.T) {
 	if soldOut {
 		t.Errorf("IsSoldOut() = %v, want false", soldOut)
 	}
-}
 
-func TestSpikeCache_MarkSoldOut(t *testing.T) {
-	client := NewSimpleMockClient()
-	spikeCache := &SpikeCache{client: client}
-
-	err := spikeCache.MarkSoldOut(context.Background(), 1, time.Hour)
-	if err != nil {
-		t.Errorf("MarkSoldOut() error = %v", err)
+	// 预减掉最后一件库存后标记售罄
+	if err := spikeCache.WarmupStock(ctx, 1, 1, time.Hour); err != nil {
+		t.Fatalf("WarmupStock() error = %v", err)
+	}
+	result, err := spikeCache.DecrementStock(ctx, 1, 7, 1, 0, time.Hour, time.Hour)
+	if err != nil || !result.Success {
+		t.Fatalf("DecrementStock() = %+v, %v, want success", result, err)
+	}
+	if soldOut, _ := spikeCache.IsSoldOut(ctx, 1); !soldOut {
+		t.Error("IsSoldOut() = false after last unit, want true")
 	}
 }
 
-func TestSpikeCache_IsMessageProcessed(t *testing.T) {
-	client := NewSimpleMockClient()
-	spikeCache := &SpikeCache{client: client}
+func TestSpikeCache_SetIdempotencyKey(t *testing.T) {
+	_, spikeCache := newTestSpikeCache(t)
+	ctx := context.Background()
 
-	// 第一次检查，应该返回false（未处理）
-	processed, err := spikeCache.IsMessageProcessed(context.Background(), "msg_123")
-	if err != nil {
-		t.Errorf("IsMessageProcessed() error = %v", err)
+	// 第一次设置成功，重复设置返回false
+	set, err := spikeCache.SetIdempotencyKey(ctx, "msg_123", "1", time.Hour)
+	if err != nil || !set {
+		t.Errorf("SetIdempotencyKey() first call = %v, %v, want true", set, err)
 	}
-
-	if processed {
-		t.Errorf("IsMessageProcessed() first call should return false")
+	set, err = spikeCache.SetIdempotencyKey(ctx, "msg_123", "1", time.Hour)
+	if err != nil || set {
+		t.Errorf("SetIdempotencyKey() second call = %v, %v, want false", set, err)
 	}
 }
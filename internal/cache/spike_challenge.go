@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// 防刷挑战Key（值为挑战绑定的活动、用户与难度，一次性使用）: spike:challenge:{challenge_id}
+const SpikeChallengeKeyNamespace = "spike:challenge"
+
+func (s *SpikeCache) getChallengeKey(challengeID string) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeChallengeKeyNamespace, challengeID))
+}
+
+// SaveChallenge 保存新签发的挑战，ttl 内未使用自动失效
+func (s *SpikeCache) SaveChallenge(ctx context.Context, challengeID, value string, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.getChallengeKey(challengeID), value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save challenge: %w", err)
+	}
+	return nil
+}
+
+// ConsumeChallenge 原子地取出并删除挑战，挑战不存在、已过期或已被使用时返回 false
+func (s *SpikeCache) ConsumeChallenge(ctx context.Context, challengeID string) (string, bool, error) {
+	value, err := s.client.GetDel(ctx, s.getChallengeKey(challengeID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to consume challenge: %w", err)
+	}
+	return value, true, nil
+}
@@ -0,0 +1,330 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// 分桶库存：超大活动的单个库存键会成为热点，分桶模式把库存拆到 N 个子键上，
+// 不同用户从各自的起始分桶开始扣减，使请求分散到 Redis Cluster 的不同节点。
+// 分桶数写在布局键中，冷路径（查询、恢复、补充、清理）据此判断活动是否分桶。
+// 分桶键不带哈希标签，在 Redis Cluster 中按整个键分散到不同槽位；布局键与活动其他键同槽
+const (
+	// 分桶库存Key（不带哈希标签）: spike:stock:bucket:event_id:bucket
+	SpikeStockBucketKeyNamespace = "spike:stock:bucket"
+
+	// 库存分桶布局Key（值为分桶数）: spike:stock:buckets:{event_id}
+	SpikeStockBucketsKeyNamespace = "spike:stock:buckets"
+
+	// MaxStockBuckets 单个活动的最大分桶数
+	MaxStockBuckets = 64
+)
+
+// Lua脚本：在单个分桶上预减库存
+const luaDecrementBucket = `
+-- KEYS[1]: 分桶库存key (spike:stock:bucket:{event_id}:{bucket})
+-- ARGV[1]: 减少的数量
+-- ARGV[2]: 库存变更通知频道
+
+local current_stock = redis.call('GET', KEYS[1])
+if current_stock == false then
+    return {-3, 0}  -- 分桶不存在
+end
+
+current_stock = tonumber(current_stock)
+local decrement = tonumber(ARGV[1])
+if current_stock < decrement then
+    return {-4, current_stock}  -- 分桶库存不足
+end
+
+local new_stock = redis.call('DECRBY', KEYS[1], decrement)
+-- 通知只用于唤醒等待者，等待者会重新查询总库存
+redis.call('PUBLISH', ARGV[2], new_stock)
+return {0, new_stock}
+`
+
+// Lua脚本：为已存在的分桶增加库存
+const luaIncrementBucket = `
+-- KEYS[1]: 分桶库存key
+-- ARGV[1]: 增加的数量
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return -1
+end
+return redis.call('INCRBY', KEYS[1], tonumber(ARGV[1]))
+`
+
+func (s *SpikeCache) getStockBucketKey(eventID int64, bucket int) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeStockBucketKeyNamespace, eventID, bucket))
+}
+
+func (s *SpikeCache) getStockBucketsKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeStockBucketsKeyNamespace, keys.HashTag(eventID)))
+}
+
+// homeBucket 用户的起始分桶，同一用户的扣减与恢复落在同一分桶
+func homeBucket(userID int64, buckets int) int {
+	bucket := int(userID % int64(buckets))
+	if bucket < 0 {
+		bucket += buckets
+	}
+	return bucket
+}
+
+// splitStock 把 total 尽量均分到 buckets 个分桶，余数分给前面的分桶
+func splitStock(total int64, buckets int) []int64 {
+	parts := make([]int64, buckets)
+	base, rest := total/int64(buckets), total%int64(buckets)
+	for i := range parts {
+		parts[i] = base
+		if int64(i) < rest {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// stockBuckets 读取活动的分桶数，未分桶时返回 0
+func (s *SpikeCache) stockBuckets(ctx context.Context, eventID int64) (int, error) {
+	buckets, err := s.client.Get(ctx, s.getStockBucketsKey(eventID)).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stock buckets: %w", err)
+	}
+	return buckets, nil
+}
+
+// WarmupStockBuckets 以分桶模式预热库存：stock 均分到 buckets 个分桶并写入布局键，
+// 同时删除单键库存与售罄标记。buckets 不大于 1 时等同于 WarmupStock
+func (s *SpikeCache) WarmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error {
+	if buckets <= 1 {
+		return s.WarmupStock(ctx, eventID, stock, ttl)
+	}
+	if buckets > MaxStockBuckets {
+		return fmt.Errorf("stock buckets %d exceeds max %d", buckets, MaxStockBuckets)
+	}
+
+	// 先写分桶再写布局键，冷路径看到布局键时分桶已全部就绪
+	pipe := s.client.Pipeline()
+	for i, part := range splitStock(stock, buckets) {
+		pipe.Set(ctx, s.getStockBucketKey(eventID, i), part, ttl)
+	}
+	pipe.Set(ctx, s.getStockBucketsKey(eventID), buckets, ttl)
+	pipe.Del(ctx, s.getStockKey(eventID))
+	pipe.Del(ctx, s.getSoldOutKey(eventID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to warmup stock buckets: %w", err)
+	}
+
+	// 通知等待库存变更的请求，失败不影响预热
+	s.client.Publish(ctx, s.getStockChangedChannel(eventID), stock)
+	return nil
+}
+
+// DecrementStockBuckets 在分桶模式下预减库存：先在用户去重键上占用购买件数，再从用户的起始分桶开始
+// 依次尝试各分桶；所有分桶都不足时归还占用的件数，分桶全部为 0 时设置售罄标记。
+// 单个分桶不足以满足购买数量时不会跨分桶合并扣减。maxPerUser 的含义同 DecrementStock，
+// buckets 不大于 1 时等同于 DecrementStock
+func (s *SpikeCache) DecrementStockBuckets(ctx context.Context, eventID, userID, quantity, maxPerUser int64, buckets int, userTTL, soldOutTTL time.Duration) (*DecrementStockResult, error) {
+	if buckets <= 1 {
+		return s.DecrementStock(ctx, eventID, userID, quantity, maxPerUser, userTTL, soldOutTTL)
+	}
+
+	userKey := s.getUserKey(userID, eventID)
+	soldOutKey := s.getSoldOutKey(eventID)
+
+	pipe := s.client.Pipeline()
+	frozenCmd := pipe.Exists(ctx, s.getFrozenKey(eventID))
+	soldOutCmd := pipe.Exists(ctx, soldOutKey)
+	claimCmd := pipe.Eval(ctx, luaClaimUserQuantity, []string{userKey}, quantity, maxPerUser, int(userTTL.Seconds()))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to claim user participation: %w", err)
+	}
+	claim, err := claimCmd.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected claim script result: %w", err)
+	}
+	claimed := claim == 1
+	release := func() error {
+		if !claimed {
+			return nil
+		}
+		if err := s.eval(ctx, "release_user_quantity", luaReleaseUserQuantity, []string{userKey}, quantity).Err(); err != nil {
+			return fmt.Errorf("failed to release user participation: %w", err)
+		}
+		return nil
+	}
+
+	switch {
+	case frozenCmd.Val() > 0:
+		if err := release(); err != nil {
+			return nil, err
+		}
+		return &DecrementStockResult{Message: "活动库存恢复中，请稍后重试", Reason: DecrementReasonFrozen}, nil
+	case soldOutCmd.Val() > 0:
+		if err := release(); err != nil {
+			return nil, err
+		}
+		return &DecrementStockResult{Message: "商品已售罄", Reason: DecrementReasonSoldOut}, nil
+	case claim == -6:
+		return &DecrementStockResult{Message: "超过单用户购买上限", Reason: DecrementReasonUserLimit}, nil
+	case !claimed:
+		return &DecrementStockResult{Message: "用户重复参与", Reason: DecrementReasonDuplicateUser}, nil
+	}
+
+	channel := s.getStockChangedChannel(eventID)
+	start := homeBucket(userID, buckets)
+	drained := true
+	for i := 0; i < buckets; i++ {
+		bucket := (start + i) % buckets
+		result := s.eval(ctx, "decrement_bucket", luaDecrementBucket,
+			[]string{s.getStockBucketKey(eventID, bucket)}, quantity, channel)
+		values, err := result.Slice()
+		if err != nil {
+			_ = release()
+			return nil, fmt.Errorf("failed to execute decrement bucket script: %w", err)
+		}
+		if len(values) != 2 {
+			_ = release()
+			return nil, fmt.Errorf("unexpected script result format")
+		}
+		code, _ := values[0].(int64)
+		stock, _ := values[1].(int64)
+
+		switch code {
+		case 0:
+			return &DecrementStockResult{Success: true, RemainingStock: stock, Message: "预减库存成功"}, nil
+		case -3:
+			if err := release(); err != nil {
+				return nil, err
+			}
+			return &DecrementStockResult{Message: "库存信息不存在", Reason: DecrementReasonStockNotFound}, nil
+		}
+		if stock > 0 {
+			drained = false
+		}
+	}
+
+	if drained {
+		if err := s.markBucketsSoldOut(ctx, eventID, buckets, soldOutTTL); err != nil {
+			_ = release()
+			return nil, err
+		}
+	}
+	if err := release(); err != nil {
+		return nil, err
+	}
+	return &DecrementStockResult{Message: "库存不足", Reason: DecrementReasonInsufficientStock}, nil
+}
+
+// markBucketsSoldOut 设置售罄标记后重新检查各分桶，期间有库存恢复时撤销标记。
+// 恢复库存先加分桶再删标记，因此两者交错时标记不会残留
+func (s *SpikeCache) markBucketsSoldOut(ctx context.Context, eventID int64, buckets int, ttl time.Duration) error {
+	soldOutKey := s.getSoldOutKey(eventID)
+	if err := s.client.Set(ctx, soldOutKey, "1", ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set sold out flag: %w", err)
+	}
+	total, _, err := s.sumBuckets(ctx, eventID, buckets)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		if err := s.client.Del(ctx, soldOutKey).Err(); err != nil {
+			return fmt.Errorf("failed to clear sold out flag: %w", err)
+		}
+	}
+	return nil
+}
+
+// sumBuckets 汇总各分桶库存，任一分桶不存在时 complete 为 false
+func (s *SpikeCache) sumBuckets(ctx context.Context, eventID int64, buckets int) (total int64, complete bool, err error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, buckets)
+	for i := range cmds {
+		cmds[i] = pipe.Get(ctx, s.getStockBucketKey(eventID, i))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, false, fmt.Errorf("failed to get stock buckets: %w", err)
+	}
+
+	complete = true
+	for _, cmd := range cmds {
+		stock, err := cmd.Int64()
+		if errors.Is(err, redis.Nil) {
+			complete = false
+			continue
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to parse bucket stock: %w", err)
+		}
+		total += stock
+	}
+	return total, complete, nil
+}
+
+// restoreBucket 把库存恢复到用户的起始分桶，清除售罄标记并扣回用户已购件数，返回恢复后的总库存
+func (s *SpikeCache) restoreBucket(ctx context.Context, eventID, userID, quantity int64, buckets int) (int64, error) {
+	bucketKey := s.getStockBucketKey(eventID, homeBucket(userID, buckets))
+	if err := s.client.IncrBy(ctx, bucketKey, quantity).Err(); err != nil {
+		return 0, fmt.Errorf("failed to restore bucket stock: %w", err)
+	}
+
+	pipe := s.client.Pipeline()
+	pipe.Del(ctx, s.getSoldOutKey(eventID))
+	pipe.Eval(ctx, luaReleaseUserQuantity, []string{s.getUserKey(userID, eventID)}, quantity)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to clear restore flags: %w", err)
+	}
+
+	total, _, err := s.sumBuckets(ctx, eventID, buckets)
+	if err != nil {
+		return 0, err
+	}
+	s.client.Publish(ctx, s.getStockChangedChannel(eventID), total)
+	return total, nil
+}
+
+// topUpBuckets 把补充的库存均分到已存在的分桶，任一分桶不存在时返回 -1，留给预热或库存守护按数据库重建
+func (s *SpikeCache) topUpBuckets(ctx context.Context, eventID, delta int64, buckets int) (int64, error) {
+	for i, part := range splitStock(delta, buckets) {
+		if part == 0 {
+			continue
+		}
+		result := s.eval(ctx, "increment_bucket", luaIncrementBucket,
+			[]string{s.getStockBucketKey(eventID, i)}, part)
+		stock, err := result.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("failed to execute increment bucket script: %w", err)
+		}
+		if stock < 0 {
+			return -1, nil
+		}
+	}
+
+	if err := s.client.Del(ctx, s.getSoldOutKey(eventID)).Err(); err != nil {
+		return 0, fmt.Errorf("failed to clear sold out flag: %w", err)
+	}
+	total, _, err := s.sumBuckets(ctx, eventID, buckets)
+	if err != nil {
+		return 0, err
+	}
+	s.client.Publish(ctx, s.getStockChangedChannel(eventID), total)
+	return total, nil
+}
+
+// bucketKeys 返回活动的布局键与全部分桶键
+func (s *SpikeCache) bucketKeys(eventID int64, buckets int) []string {
+	result := []string{s.getStockBucketsKey(eventID)}
+	for i := 0; i < buckets; i++ {
+		result = append(result, s.getStockBucketKey(eventID, i))
+	}
+	return result
+}
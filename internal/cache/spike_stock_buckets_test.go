@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSplitStock(t *testing.T) {
+	tests := []struct {
+		total   int64
+		buckets int
+		want    []int64
+	}{
+		{100, 4, []int64{25, 25, 25, 25}},
+		{10, 4, []int64{3, 3, 2, 2}},
+		{2, 4, []int64{1, 1, 0, 0}},
+		{0, 2, []int64{0, 0}},
+	}
+	for _, tt := range tests {
+		got := splitStock(tt.total, tt.buckets)
+		if len(got) != len(tt.want) {
+			t.Fatalf("splitStock(%d, %d) = %v, want %v", tt.total, tt.buckets, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("splitStock(%d, %d) = %v, want %v", tt.total, tt.buckets, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if got := homeBucket(-3, 4); got != 1 {
+		t.Errorf("homeBucket(-3, 4) = %d, want 1", got)
+	}
+}
+
+func TestSpikeCache_StockBuckets(t *testing.T) {
+	// 注意：此测试需要运行Redis实例
+	if testing.Short() {
+		t.Skip("Skipping Redis test in short mode")
+	}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Skipping Redis test, cannot connect: %v", err)
+	}
+
+	c := NewSpikeCache(client)
+	c.SetKeyPrefix("test")
+	const eventID = 987654
+	defer c.PurgeEvent(ctx, eventID, []int64{1, 2, 3, 4, 5})
+
+	if err := c.WarmupStockBuckets(ctx, eventID, 5, 4, time.Minute); err != nil {
+		t.Fatalf("WarmupStockBuckets() error = %v", err)
+	}
+	info, err := c.GetStockInfo(ctx, eventID)
+	if err != nil || !info.Exists || info.Stock != 5 {
+		t.Fatalf("GetStockInfo() = %+v, %v, want 5 in stock", info, err)
+	}
+
+	// 5 件分到 4 个分桶为 [2 1 1 1]，各用户从自己的起始分桶扣减
+	for userID := int64(1); userID <= 4; userID++ {
+		result, err := c.DecrementStockBuckets(ctx, eventID, userID, 1, 0, 4, time.Minute, time.Minute)
+		if err != nil || !result.Success {
+			t.Fatalf("DecrementStockBuckets(user %d) = %+v, %v, want success", userID, result, err)
+		}
+	}
+	result, err := c.DecrementStockBuckets(ctx, eventID, 1, 1, 0, 4, time.Minute, time.Minute)
+	if err != nil || result.Reason != DecrementReasonDuplicateUser {
+		t.Fatalf("DecrementStockBuckets(duplicate) = %+v, %v, want duplicate", result, err)
+	}
+	if result, _ := c.DecrementStockBuckets(ctx, eventID, 5, 2, 0, 4, time.Minute, time.Minute); result.Reason != DecrementReasonInsufficientStock {
+		t.Fatalf("DecrementStockBuckets(quantity 2) = %+v, want insufficient", result)
+	}
+	if participated, _ := c.IsUserParticipated(ctx, 5, eventID); participated {
+		t.Error("user mark kept after insufficient stock")
+	}
+	if result, _ := c.DecrementStockBuckets(ctx, eventID, 5, 1, 0, 4, time.Minute, time.Minute); !result.Success {
+		t.Fatalf("DecrementStockBuckets(last item) = %+v, want success", result)
+	}
+
+	if result, _ := c.DecrementStockBuckets(ctx, eventID, 6, 1, 0, 4, time.Minute, time.Minute); result.Reason != DecrementReasonInsufficientStock {
+		t.Fatalf("DecrementStockBuckets(drained) = %+v, want insufficient", result)
+	}
+	if info, _ := c.GetStockInfo(ctx, eventID); info.Stock != 0 || !info.SoldOut {
+		t.Errorf("GetStockInfo() after drain = %+v, want 0 and sold out", info)
+	}
+
+	stock, err := c.RestoreStock(ctx, eventID, 2, 1)
+	if err != nil || stock != 1 {
+		t.Fatalf("RestoreStock() = %d, %v, want 1", stock, err)
+	}
+	if info, _ := c.GetStockInfo(ctx, eventID); info.Stock != 1 || info.SoldOut {
+		t.Errorf("GetStockInfo() after restore = %+v, want 1 and not sold out", info)
+	}
+	if stock, err := c.TopUpStock(ctx, eventID, 6); err != nil || stock != 7 {
+		t.Errorf("TopUpStock() = %d, %v, want 7", stock, err)
+	}
+
+	// 切回单键模式后分桶不再参与统计
+	if err := c.WarmupStock(ctx, eventID, 3, time.Minute); err != nil {
+		t.Fatalf("WarmupStock() error = %v", err)
+	}
+	if info, _ := c.GetStockInfo(ctx, eventID); info.Stock != 3 {
+		t.Errorf("GetStockInfo() after single warmup = %+v, want 3", info)
+	}
+}
+
+func TestSpikeCache_UserQuantityLimit(t *testing.T) {
+	// 注意：此测试需要运行Redis实例
+	if testing.Short() {
+		t.Skip("Skipping Redis test in short mode")
+	}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Skipping Redis test, cannot connect: %v", err)
+	}
+
+	c := NewSpikeCache(client)
+	c.SetKeyPrefix("test")
+	const userID = 1
+	for _, buckets := range []int{1, 4} {
+		eventID := int64(987700 + buckets)
+		defer c.PurgeEvent(ctx, eventID, []int64{userID})
+		if err := c.WarmupStockBuckets(ctx, eventID, 20, buckets, time.Minute); err != nil {
+			t.Fatalf("WarmupStockBuckets(%d) error = %v", buckets, err)
+		}
+
+		// 上限 3 件：可分两次购买 2+1 件，之后再买 1 件超过上限
+		for _, quantity := range []int64{2, 1} {
+			if result, err := c.DecrementStockBuckets(ctx, eventID, userID, quantity, 3, buckets, time.Minute, time.Minute); err != nil || !result.Success {
+				t.Fatalf("buckets %d: DecrementStockBuckets(%d) = %+v, %v, want success", buckets, quantity, result, err)
+			}
+		}
+		if result, _ := c.DecrementStockBuckets(ctx, eventID, userID, 1, 3, buckets, time.Minute, time.Minute); result.Reason != DecrementReasonUserLimit {
+			t.Fatalf("buckets %d: DecrementStockBuckets(over limit) = %+v, want user limit", buckets, result)
+		}
+		if purchased, _ := c.GetUserPurchased(ctx, userID, eventID); purchased != 3 {
+			t.Errorf("buckets %d: GetUserPurchased() = %d, want 3", buckets, purchased)
+		}
+
+		// 取消 2 件后归还已购件数，可以再次购买
+		if _, err := c.RestoreStock(ctx, eventID, userID, 2); err != nil {
+			t.Fatalf("buckets %d: RestoreStock() error = %v", buckets, err)
+		}
+		if purchased, _ := c.GetUserPurchased(ctx, userID, eventID); purchased != 1 {
+			t.Errorf("buckets %d: GetUserPurchased() after restore = %d, want 1", buckets, purchased)
+		}
+		if result, _ := c.DecrementStockBuckets(ctx, eventID, userID, 2, 3, buckets, time.Minute, time.Minute); !result.Success {
+			t.Errorf("buckets %d: DecrementStockBuckets(after restore) = %+v, want success", buckets, result)
+		}
+
+		// 未配置上限时保持每个用户只能参与一次
+		if result, _ := c.DecrementStockBuckets(ctx, eventID, userID, 1, 0, buckets, time.Minute, time.Minute); result.Reason != DecrementReasonDuplicateUser {
+			t.Errorf("buckets %d: DecrementStockBuckets(no limit) = %+v, want duplicate", buckets, result)
+		}
+	}
+}
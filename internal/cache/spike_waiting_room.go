@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// 虚拟等候室：排队模式的活动中，参与请求先按到达顺序领取递增的排队序号，
+// 放行器按活动的每秒放行人数推进已放行序号，序号不大于已放行序号的用户可携带排队令牌再次参与。
+// 同一活动的排队键带相同的哈希标签，脚本在 Redis Cluster 中落在同一槽位
+const (
+	// 排队序号Key（值为最后一个入队序号）: spike:queue:seq:{event_id}
+	SpikeQueueSeqKeyNamespace = "spike:queue:seq"
+
+	// 已放行序号Key: spike:queue:admitted:{event_id}
+	SpikeQueueAdmittedKeyNamespace = "spike:queue:admitted"
+
+	// 上次放行时间Key（毫秒时间戳）: spike:queue:clock:{event_id}
+	SpikeQueueClockKeyNamespace = "spike:queue:clock"
+
+	// 用户排队令牌Key: spike:queue:user:{event_id}:user_id
+	SpikeQueueUserKeyNamespace = "spike:queue:user"
+
+	// 排队票据Key（值为 user_id:seq）: spike:queue:ticket:{event_id}:token
+	SpikeQueueTicketKeyNamespace = "spike:queue:ticket"
+
+	// 排队中的活动（Hash，字段为活动ID，值为每秒放行人数）: spike:queue:events
+	SpikeQueueEventsKeyNamespace = "spike:queue:events"
+)
+
+// Lua脚本：入队，用户已在队列中时返回原令牌
+const luaEnqueueWaitingRoom = `
+-- KEYS[1]: 排队序号key
+-- KEYS[2]: 用户排队令牌key
+-- KEYS[3]: 新令牌的票据key
+-- ARGV[1]: 新令牌
+-- ARGV[2]: 用户ID
+-- ARGV[3]: 过期时间（毫秒）
+
+local existing = redis.call('GET', KEYS[2])
+if existing then
+    return existing
+end
+
+local seq = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ARGV[3])
+redis.call('SET', KEYS[3], ARGV[2] .. ':' .. seq, 'PX', ARGV[3])
+return ARGV[1]
+`
+
+// Lua脚本：按距上次放行的时长推进已放行序号，多个实例同时调用时总放行速率不变
+const luaAdmitWaitingRoom = `
+-- KEYS[1]: 排队序号key
+-- KEYS[2]: 已放行序号key
+-- KEYS[3]: 上次放行时间key
+-- ARGV[1]: 每秒放行人数
+-- ARGV[2]: 当前时间（毫秒）
+
+local seq = redis.call('GET', KEYS[1])
+if seq == false then
+    return -1  -- 队列已过期
+end
+seq = tonumber(seq)
+local ttl = redis.call('PTTL', KEYS[1])
+local admitted = tonumber(redis.call('GET', KEYS[2]) or '0')
+local rate = tonumber(ARGV[1])
+local now = tonumber(ARGV[2])
+
+local last = redis.call('GET', KEYS[3])
+-- 队列空闲时重置放行时钟，避免空闲期累积的配额在下一波请求时一次性放行
+if last == false or admitted >= seq then
+    redis.call('SET', KEYS[3], now, 'PX', ttl)
+    return seq - admitted
+end
+last = tonumber(last)
+
+local quota = math.floor((now - last) * rate / 1000)
+if quota <= 0 then
+    return seq - admitted
+end
+local next_admitted = math.min(admitted + quota, seq)
+redis.call('SET', KEYS[2], next_admitted, 'PX', ttl)
+-- 只推进实际用掉的时长，保留不足一人的余量
+redis.call('SET', KEYS[3], last + math.floor(quota * 1000 / rate), 'PX', ttl)
+return seq - next_admitted
+`
+
+// WaitingRoomTicket 排队票据
+type WaitingRoomTicket struct {
+	UserID   int64
+	Position int64 // 排队位置，1 表示下一个放行，0 表示已放行
+}
+
+func (s *SpikeCache) getQueueSeqKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeQueueSeqKeyNamespace, keys.HashTag(eventID)))
+}
+
+func (s *SpikeCache) getQueueAdmittedKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeQueueAdmittedKeyNamespace, keys.HashTag(eventID)))
+}
+
+func (s *SpikeCache) getQueueClockKey(eventID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeQueueClockKeyNamespace, keys.HashTag(eventID)))
+}
+
+func (s *SpikeCache) getQueueUserKey(eventID, userID int64) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeQueueUserKeyNamespace, keys.HashTag(eventID), userID))
+}
+
+func (s *SpikeCache) getQueueTicketKey(eventID int64, token string) string {
+	return keys.WithPrefix(s.prefix, keys.Redis(SpikeQueueTicketKeyNamespace, keys.HashTag(eventID), token))
+}
+
+func (s *SpikeCache) getQueueEventsKey() string {
+	return keys.WithPrefix(s.prefix, SpikeQueueEventsKeyNamespace)
+}
+
+// EnqueueWaitingRoom 用户进入活动等候室并返回排队令牌；用户已在队列中时返回原令牌，不重复排队。
+// rate 为活动每秒放行人数，随入队登记到排队活动列表供放行器使用；ttl 后排队数据自动失效
+func (s *SpikeCache) EnqueueWaitingRoom(ctx context.Context, eventID, userID int64, token string, rate int64, ttl time.Duration) (string, error) {
+	result, err := s.client.Eval(ctx, luaEnqueueWaitingRoom,
+		[]string{s.getQueueSeqKey(eventID), s.getQueueUserKey(eventID, userID), s.getQueueTicketKey(eventID, token)},
+		token, userID, ttl.Milliseconds()).Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to enqueue waiting room: %w", err)
+	}
+	if err := s.client.HSet(ctx, s.getQueueEventsKey(), strconv.FormatInt(eventID, 10), rate).Err(); err != nil {
+		return "", fmt.Errorf("failed to register waiting room event: %w", err)
+	}
+	return result, nil
+}
+
+// GetWaitingRoomTicket 查询排队票据与当前位置，票据不存在或已过期时返回 false
+func (s *SpikeCache) GetWaitingRoomTicket(ctx context.Context, eventID int64, token string) (*WaitingRoomTicket, bool, error) {
+	pipe := s.client.Pipeline()
+	ticketCmd := pipe.Get(ctx, s.getQueueTicketKey(eventID, token))
+	admittedCmd := pipe.Get(ctx, s.getQueueAdmittedKey(eventID))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, false, fmt.Errorf("failed to get waiting room ticket: %w", err)
+	}
+
+	value, err := ticketCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get waiting room ticket: %w", err)
+	}
+	userPart, seqPart, ok := strings.Cut(value, ":")
+	userID, err1 := strconv.ParseInt(userPart, 10, 64)
+	seq, err2 := strconv.ParseInt(seqPart, 10, 64)
+	if !ok || err1 != nil || err2 != nil {
+		return nil, false, fmt.Errorf("malformed waiting room ticket %q", value)
+	}
+
+	admitted, err := admittedCmd.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, false, fmt.Errorf("failed to get waiting room admitted seq: %w", err)
+	}
+	return &WaitingRoomTicket{UserID: userID, Position: max(seq-admitted, 0)}, true, nil
+}
+
+// WaitingRoomEvents 返回排队中的活动及其每秒放行人数
+func (s *SpikeCache) WaitingRoomEvents(ctx context.Context) (map[int64]int64, error) {
+	fields, err := s.client.HGetAll(ctx, s.getQueueEventsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waiting room events: %w", err)
+	}
+	events := make(map[int64]int64, len(fields))
+	for field, value := range fields {
+		eventID, err1 := strconv.ParseInt(field, 10, 64)
+		rate, err2 := strconv.ParseInt(value, 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		events[eventID] = rate
+	}
+	return events, nil
+}
+
+// AdmitWaitingRoom 按每秒放行人数推进活动的已放行序号，返回仍在等待的人数；
+// 队列已过期时从排队活动列表中移除并返回 false
+func (s *SpikeCache) AdmitWaitingRoom(ctx context.Context, eventID, rate int64, now time.Time) (int64, bool, error) {
+	waiting, err := s.client.Eval(ctx, luaAdmitWaitingRoom,
+		[]string{s.getQueueSeqKey(eventID), s.getQueueAdmittedKey(eventID), s.getQueueClockKey(eventID)},
+		rate, now.UnixMilli()).Int64()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to admit waiting room: %w", err)
+	}
+	if waiting < 0 {
+		if err := s.client.HDel(ctx, s.getQueueEventsKey(), strconv.FormatInt(eventID, 10)).Err(); err != nil {
+			return 0, false, fmt.Errorf("failed to unregister waiting room event: %w", err)
+		}
+		return 0, false, nil
+	}
+	return waiting, true, nil
+}
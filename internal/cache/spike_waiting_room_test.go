@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSpikeCache_WaitingRoom(t *testing.T) {
+	// 注意：此测试需要运行Redis实例
+	if testing.Short() {
+		t.Skip("Skipping Redis test in short mode")
+	}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Skipping Redis test, cannot connect: %v", err)
+	}
+
+	c := NewSpikeCache(client)
+	c.SetKeyPrefix("test")
+	const eventID = 987655
+	defer func() {
+		client.Del(ctx, c.getQueueSeqKey(eventID), c.getQueueAdmittedKey(eventID), c.getQueueClockKey(eventID),
+			c.getQueueUserKey(eventID, 1), c.getQueueUserKey(eventID, 2),
+			c.getQueueTicketKey(eventID, "a"), c.getQueueTicketKey(eventID, "b"))
+		client.HDel(ctx, c.getQueueEventsKey(), "987655")
+	}()
+
+	for userID, token := range map[int64]string{1: "a", 2: "b"} {
+		if got, err := c.EnqueueWaitingRoom(ctx, eventID, userID, token, 2, time.Minute); err != nil || got != token {
+			t.Fatalf("EnqueueWaitingRoom(user %d) = %q, %v, want %q", userID, got, err, token)
+		}
+	}
+	if got, _ := c.EnqueueWaitingRoom(ctx, eventID, 1, "c", 2, time.Minute); got != "a" {
+		t.Errorf("EnqueueWaitingRoom(requeue) = %q, want original token", got)
+	}
+
+	// 首次放行只启动放行时钟，1 秒后按每秒 2 人放行全部用户
+	start := time.Now()
+	if waiting, active, err := c.AdmitWaitingRoom(ctx, eventID, 2, start); err != nil || !active || waiting != 2 {
+		t.Fatalf("AdmitWaitingRoom(start) = %d, %v, %v, want 2 waiting", waiting, active, err)
+	}
+	if waiting, _, err := c.AdmitWaitingRoom(ctx, eventID, 2, start.Add(500*time.Millisecond)); err != nil || waiting != 1 {
+		t.Fatalf("AdmitWaitingRoom(+500ms) = %d, %v, want 1 waiting", waiting, err)
+	}
+	ticket, found, err := c.GetWaitingRoomTicket(ctx, eventID, "b")
+	if err != nil || !found || ticket.UserID != 2 || ticket.Position != 1 {
+		t.Fatalf("GetWaitingRoomTicket(b) = %+v, %v, %v, want user 2 at position 1", ticket, found, err)
+	}
+	if waiting, _, err := c.AdmitWaitingRoom(ctx, eventID, 2, start.Add(time.Second)); err != nil || waiting != 0 {
+		t.Fatalf("AdmitWaitingRoom(+1s) = %d, %v, want none waiting", waiting, err)
+	}
+	if _, found, _ := c.GetWaitingRoomTicket(ctx, eventID, "missing"); found {
+		t.Errorf("GetWaitingRoomTicket(missing) found, want not found")
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// StockChange 一次库存变更通知
+type StockChange struct {
+	EventID int64
+	Stock   int64 // 变更后的 Redis 剩余库存
+}
+
+// StockChangeSubscriber 订阅全部活动的库存变更通知。
+// 每个进程只持有一个模式订阅连接，由调用方在进程内分发给等待中的请求。
+type StockChangeSubscriber struct {
+	client redis.UniversalClient
+	prefix string // 环境键前缀，须与发布方 SpikeCache 一致
+}
+
+// NewStockChangeSubscriber 创建库存变更订阅
+func NewStockChangeSubscriber(client redis.UniversalClient) *StockChangeSubscriber {
+	return &StockChangeSubscriber{client: client}
+}
+
+// SetKeyPrefix 设置环境键前缀，只接收该前缀下的库存变更
+func (s *StockChangeSubscriber) SetKeyPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// Subscribe 订阅库存变更，ctx 取消时关闭订阅与返回的通道；无法解析的消息被忽略
+func (s *StockChangeSubscriber) Subscribe(ctx context.Context) <-chan StockChange {
+	channelPrefix := keys.WithPrefix(s.prefix, SpikeStockChangedChannelNamespace) + keys.Separator
+	pubsub := s.client.PSubscribe(ctx, channelPrefix+"*")
+	out := make(chan StockChange, 256)
+
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				eventID, err := strconv.ParseInt(strings.TrimPrefix(msg.Channel, channelPrefix), 10, 64)
+				if err != nil {
+					continue
+				}
+				stock, err := strconv.ParseInt(msg.Payload, 10, 64)
+				if err != nil {
+					continue
+				}
+				select {
+				case out <- StockChange{EventID: eventID, Stock: stock}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -1,27 +1,59 @@
-// Package config 负责应用配置的加载（环境变量优先）、默认值设定与启动前校验。
-// 约定：环境变量优先于 .env 文件；非法配置会在启动阶段直接失败并给出清晰错误。
+// Package config 负责应用配置的加载（命令行 > 环境变量 > 配置文件）、默认值设定、启动前校验与运行期热更新。
+// 约定：环境变量优先于 .env 文件；非法配置会在启动阶段直接失败并给出清晰错误，热更新时则保留原配置。
 package config
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
+	"net"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/MorseWayne/spike_shop/internal/idgen"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/logger"
 )
 
-// Config 表示应用运行时配置，来源于环境变量（若存在 .env 会被优先装载，但不会覆盖已存在的环境变量）。
+// Config 表示应用运行时配置，来源于命令行覆盖、环境变量与配置文件（.env.<APP_ENV> 与 .env，不会覆盖已存在的环境变量）。
 // 建议的环境变量（与默认值）：
 //   - APP_NAME=spike-server
-//   - APP_ENV=dev|test|prod（默认 dev）
+//   - APP_ENV=dev|staging|test|prod（默认 dev，同时决定加载的 .env.<APP_ENV>）
+//   - CONFIG_RELOAD_INTERVAL（默认 5s，配置文件变化检测间隔，0 表示不热更新）
 //   - APP_PORT（默认 8080）
-//   - REQUEST_TIMEOUT_MS（默认 5000）
+//   - REQUEST_TIMEOUT_MS（默认 5000，写请求的默认处理超时）
+//   - SHUTDOWN_TIMEOUT_MS（默认 5000）、SHUTDOWN_DRAIN_DELAY_MS（默认 2000，关闭 HTTP 服务器前的排空等待）
+//   - APP_WORKER_ID（默认 0，订单号生成器节点编号 0..1023，多实例部署时每个实例必须不同）
 //   - LOG_LEVEL=debug|info|warn|error（默认 info）
 //   - LOG_ENCODING=json|console（默认 json）
+//   - LOG_REDACT_RULES（CSV，形如 field:drop|mask|hash，覆盖默认脱敏规则）
+//   - LOG_REDACT_SALT（hash 脱敏盐值）
 //   - CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS（CSV）
+//   - HTTP_CORS_ENABLED（默认 true）、CORS_ALLOW_CREDENTIALS（默认 false）、CORS_MAX_AGE（默认 10m）
+//   - MYSQL_QUERY_TIMEOUT（默认 3s，库存与秒杀仓储单次调用超时，0 表示不单独限制）
+//   - MYSQL_REPLICA_ADDRS（CSV，只读副本 host:port，账号与库名同主库；默认空，不做读写分离）、
+//     MYSQL_REPLICA_MAX_LAG（默认 5s，复制延迟超过时不再读该副本）、MYSQL_REPLICA_CHECK_INTERVAL（默认 5s）
+//   - HTTP_SECURITY_HEADERS_ENABLED（默认 true）、HTTP_HSTS_MAX_AGE（默认 4320h，0 表示不返回 HSTS）
+//   - HTTP_COMPRESSION_ENABLED（默认 true）、HTTP_COMPRESSION_MIN_SIZE（默认 1024 字节）、HTTP_ETAG_ENABLED（默认 true）
+//   - HTTP_REQUEST_LIMIT_METHODS（CSV，默认 POST，处理超时、请求体上限与慢请求日志作用的请求方法）、
+//     HTTP_MAX_BODY_BYTES（默认 1048576，0 表示不限制）、HTTP_SLOW_REQUEST_THRESHOLD（默认 1s，0 表示不记录）、
+//     HTTP_ROUTE_TIMEOUTS / HTTP_ROUTE_MAX_BODY_BYTES（CSV，形如 /api/v1/admin/spike/events/import=60s，按路由模板覆盖默认值）
+//   - HTTP_TRUSTED_PROXIES（CSV，可信反向代理的 IP 或 CIDR；默认空，不信任 X-Forwarded-For 等头，客户端 IP 取连接地址）
+//   - LOGIN_MAX_FAILURES（默认 5）、LOGIN_IP_MAX_FAILURES（默认 50）：LOGIN_FAILURE_WINDOW（默认 15m）内账号或来源 IP
+//     登录失败达到次数即锁定，0 表示不锁定；锁定时长自 LOGIN_LOCKOUT_BASE（默认 1m）起每次翻倍，不超过 LOGIN_LOCKOUT_MAX（默认 24h），
+//     LOGIN_LOCKOUT_RESET（默认 24h）内无新锁定则回到初始时长；需要 CACHE_TYPE=redis
+//   - TOTP_ISSUER（默认 APP_NAME，两步验证在验证器中显示的服务名）
+//   - TOTP_ENCRYPTION_KEY（Base64 编码的 32 字节密钥，以 AES-256-GCM 加密保存两步验证密钥；默认空，明文保存，prod 环境必须设置）
+//   - CACHE_INVALIDATION_ENABLED（默认 true，进程内缓存通过 Redis 发布/订阅跨实例失效）
+//   - REDIS_KEY_PREFIX（默认空，按环境隔离键空间，如 staging；prod/production 开头的前缀仅允许 APP_ENV=prod）
+//   - REDIS_CACHE_DB、REDIS_LIMITER_DB、REDIS_SPIKE_DB（默认与 REDIS_DB 相同，按逻辑存储拆分 DB）
+//   - REDIS_MODE=standalone|cluster|sentinel（默认 standalone）、REDIS_ADDRS（CSV，集群种子节点或哨兵地址）、
+//     REDIS_MASTER_NAME、REDIS_SENTINEL_PASSWORD（哨兵模式）
+//   - SPIKE_ORDER_EXPIRE_TIME（默认 30m）、SPIKE_GLOBAL_RATE_LIMIT（默认 1000）、SPIKE_USER_RATE_LIMIT（默认 5）等秒杀服务参数，见 Spike
+//   - STOCK_RECONCILE_ENABLED（默认 true）、STOCK_RECONCILE_INTERVAL（默认 5m）、STOCK_RECONCILE_REPAIR（默认 false，仅记录偏差）
 type Config struct {
 	App struct {
 		Name            string
@@ -30,27 +62,77 @@ type Config struct {
 		RequestTimeout  time.Duration
 		Version         string
 		ShutdownTimeout time.Duration
+		DrainDelay      time.Duration // 收到退出信号后先排空（拒绝参与秒杀、停止拉取消息）的时长，再关闭 HTTP 服务器
+		WorkerID        int64         // 订单号生成器的节点编号，多实例部署（含日志回放工具）时每个进程必须不同
+		ReloadInterval  time.Duration // 配置文件变化检测间隔，变化后热更新 Tunables；0 表示不检测
 	}
 	Log struct {
-		Level    string
-		Encoding string
+		Level       string
+		Encoding    string
+		RedactRules []string // 形如 field:action 的脱敏规则
+		RedactSalt  string
 	}
 	CORS struct {
 		AllowedOrigins []string
 		AllowedMethods []string
 		AllowedHeaders []string
 	}
+	HTTP struct {
+		CORSEnabled          bool          // 是否启用 CORS 中间件，来源白名单等见 CORS
+		CORSAllowCredentials bool          // 是否允许跨域携带凭证（Cookie、Authorization）
+		CORSMaxAge           time.Duration // 预检结果缓存时长
+		SecurityHeaders      bool          // 是否返回 HSTS、nosniff、X-Frame-Options 等安全响应头
+		HSTSMaxAge           time.Duration // HSTS max-age，0 表示不返回 HSTS
+		MetricsEnabled       bool          // 是否记录请求指标并在 /metrics 暴露 Prometheus 指标
+		CompressionEnabled   bool          // 是否按 Accept-Encoding 对响应做 gzip 压缩
+		CompressionMinSize   int           // 响应体达到该字节数才压缩
+		ETagEnabled          bool          // 是否为 GET 请求的 JSON 响应生成 ETag 并处理 If-None-Match
+		// RequestLimitMethods 处理超时（默认取 App.RequestTimeout）、请求体上限与慢请求日志作用的请求方法
+		RequestLimitMethods  []string
+		MaxBodyBytes         int64                    // 请求体上限（字节），0 表示不限制
+		SlowRequestThreshold time.Duration            // 处理耗时达到该值时记录慢请求日志，0 表示不记录
+		RouteTimeouts        map[string]time.Duration // 按路由模板覆盖处理超时，0 表示该路由不限制
+		RouteMaxBodyBytes    map[string]int64         // 按路由模板覆盖请求体上限，0 表示该路由不限制
+		// TrustedProxies 可信反向代理的 IP 或 CIDR，只有来自这些地址的请求才按 X-Forwarded-For 解析客户端 IP；
+		// 为空时不信任任何代理，避免客户端伪造请求头绕过按 IP 的限流与登录锁定
+		TrustedProxies []string
+	}
 	Database struct {
 		Host     string
 		Port     int
 		User     string
 		Password string
 		DBName   string
+		// QueryTimeout 库存、秒杀活动与秒杀订单仓储单次调用的超时时间，0 表示只受请求 ctx 约束
+		QueryTimeout time.Duration
+		// ReplicaAddrs 只读副本地址（host:port），为空时所有查询走主库
+		ReplicaAddrs []string
+		// ReplicaMaxLag 副本复制延迟超过该值或复制中断时，读请求回退到其他副本或主库
+		ReplicaMaxLag time.Duration
+		// ReplicaCheckInterval 副本连通性与复制延迟的检查间隔
+		ReplicaCheckInterval time.Duration
 	}
 	JWT struct {
 		Secret          string
 		AccessTokenTTL  time.Duration
 		RefreshTokenTTL time.Duration
+		// ImpersonationTokenTTL 客服代操作令牌有效期，应明显短于普通访问令牌
+		ImpersonationTokenTTL time.Duration
+	}
+	Login struct {
+		MaxFailures   int           // 窗口内同一账号登录失败达到该次数即锁定，0 表示不按账号锁定
+		IPMaxFailures int           // 窗口内同一来源 IP 登录失败达到该次数即锁定，0 表示不按 IP 锁定
+		FailureWindow time.Duration // 失败次数统计窗口
+		LockoutBase   time.Duration // 首次锁定时长，之后每次锁定翻倍
+		LockoutMax    time.Duration // 锁定时长上限
+		LockoutReset  time.Duration // 累计锁定次数的保留时长，期间无新锁定则锁定时长回到 LockoutBase
+		TOTPIssuer    string        // 两步验证在验证器中显示的服务名
+		// TOTPEncryptionKey 加密保存两步验证密钥的 32 字节 AES-256 密钥，为空时明文保存
+		TOTPEncryptionKey []byte
+	}
+	Authz struct {
+		// HideForeignResources 访问他人订单时返回 404 而非 403，避免通过遍历ID探测订单是否存在
+		HideForeignResources bool
 	}
 	Migrations struct {
 		Dir string
@@ -59,61 +141,542 @@ type Config struct {
 		Enabled bool
 		TTL     time.Duration
 		Type    string // "memory" 或 "redis"
+		// 库存可用性检查的缓存时长，库存变动时主动失效；0 表示不缓存
+		AvailabilityTTL time.Duration
+		// 商品详情聚合（商品 + 库存 + 秒杀活动）的缓存时长；0 表示不缓存
+		ProductDetailTTL time.Duration
+		// 用户信息缓存时长（订单详情等高频接口），角色、状态变更时主动失效；0 表示不缓存
+		UserTTL time.Duration
+		// 进程内缓存的商品与库存变更是否通过 Redis 发布/订阅广播给其他实例；CACHE_TYPE=redis 时缓存已共享，无需广播
+		InvalidationEnabled bool
 	}
 	Redis struct {
 		Host     string
 		Port     int
 		Password string
 		DB       int
+		// Mode 部署模式：standalone 连接 Host:Port；cluster 以 Addrs 为种子节点连接 Redis Cluster，只能使用 0 号数据库；
+		// sentinel 通过 Addrs 中的哨兵发现 MasterName 的主节点并自动故障切换
+		Mode             string
+		Addrs            []string
+		MasterName       string
+		SentinelPassword string
+		// KeyPrefix 环境键前缀（如 staging），所有键以 prefix: 开头，使多个环境共用同一 Redis 时互不覆盖；
+		// 首段为 prod/production 时视为生产环境，拒绝清空缓存等破坏性操作
+		KeyPrefix string
+		CacheDB   int // 通用缓存（商品、库存、用户等）使用的数据库编号，默认同 DB
+		LimiterDB int // 限流器使用的数据库编号，默认同 DB
+		SpikeDB   int // 秒杀库存、去重标记与消息 Stream 使用的数据库编号，默认同 DB
+	}
+	Storage struct {
+		Dir       string        // 本地文件存储根目录
+		URLSecret string        // 下载链接签名密钥，为空时使用 JWT_SECRET
+		URLTTL    time.Duration // 下载链接有效期
+	}
+	Spike struct {
+		OrderExpireTime     time.Duration // 待支付订单的过期时间
+		OrderExtension      time.Duration // 用户可申请一次延长支付时间的时长，0 表示不允许延长
+		GlobalRateLimit     int64         // 全局参与限流：每个窗口允许的请求数
+		UserRateLimit       int64         // 单用户参与限流：每个窗口允许的请求数（等级倍数在此基础上计算）
+		RateLimitWindow     time.Duration // 限流窗口
+		StockWarmupEnabled  bool          // 是否在活动开始前预热库存
+		StockWarmupTime     time.Duration // 提前多久预热库存
+		StockCacheTTL       time.Duration // 库存与活动缓存的最短过期时间，长活动按结束时间 + StockTTLBuffer 计算
+		StockTTLBuffer      time.Duration // 库存键在活动结束后额外保留的时长
+		StockTTLRefresh     time.Duration // 进行中活动库存键 TTL 检查与续期的间隔
+		UserMarkTTL         time.Duration // 用户参与标记的过期时间
+		IdempotencyTTL      time.Duration // 幂等键的保留时间
+		MaxRetryAttempts    int           // 失败操作的最大重试次数
+		RetryInterval       time.Duration // 重试间隔
+		PreviewScanInterval time.Duration // 预告期活动扫描间隔
+		LifecycleInterval   time.Duration // 活动状态流转（按时间激活与结束）扫描间隔
+		OrderDetailJoin     bool          // 订单详情使用单次 JOIN 查询
+		// 匿名只读接口（营销页活动列表/详情）
+		AnonymousRateLimit int64         // 单个 IP 每个窗口允许的匿名请求数，0 表示不开放匿名接口
+		PublicCacheTTL     time.Duration // 匿名只读视图的缓存有效期
+		DashboardCacheTTL  time.Duration // 管理后台秒杀总览的缓存有效期
+	}
+	SpikeToken struct {
+		Enabled         bool   // 是否启用参与令牌流程（仅对设置了预告期的活动生效）
+		Secret          string // 令牌签名密钥，为空时使用 JWT_SECRET
+		IssueMultiplier int    // 每个活动可发放令牌数 = 剩余库存 × 倍数
+		IssueRate       int    // 单用户每分钟最多领取次数
+	}
+	SpikeChallenge struct {
+		Difficulty int           // 工作量证明挑战要求的哈希前导零位数（仅对开启 challenge_required 的活动生效）
+		TTL        time.Duration // 挑战有效期
+		IssueRate  int           // 单用户每分钟最多领取次数
+	}
+	SpikeWaitingRoom struct {
+		DispatchInterval time.Duration // 等候室放行器的执行间隔（仅对设置了 waiting_room_rate 的活动生效）
+	}
+	SpikeDegraded struct {
+		Mode               string        // 库存缓存不可用时的降级策略：off（直接失败）、queue（返回排队提示）、db（数据库悲观扣减）
+		MaxConcurrency     int           // db 模式下单实例同时走数据库扣减的请求数上限，超出时返回排队提示
+		BreakerFailures    int           // 库存缓存连续失败多少次后熔断
+		BreakerOpenTimeout time.Duration // 熔断持续多久后放行探测请求
+	}
+	SpikeShare struct {
+		Secret   string        // 分享链接签名密钥，为空时使用 JWT_SECRET
+		LinkBase string        // 分享落地页路径前缀，链接形如 <LinkBase>/<event_id>?share=<token>
+		LinkTTL  time.Duration // 分享链接最长有效期，0 表示到活动结束为止
+	}
+	Payment struct {
+		Provider      string // 支付渠道，目前仅支持 sandbox（按金额分位确定结果的沙箱）
+		WebhookSecret string // 支付回调签名密钥，生产环境必须设置且不能与 JWT_SECRET 相同；其它环境为空时使用进程内随机密钥
+		Currency      string // 支付币种
+		// SandboxAuthorize 是否开放沙箱模拟支付接口，任何登录用户都能借此完成自己订单的支付，
+		// 只用于单实例的开发与联调环境，生产环境不能开启
+		SandboxAuthorize bool
+	}
+	Journal struct {
+		Enabled bool   // 是否记录秒杀参与日志，用于消息丢失后的灾难恢复
+		Dir     string // 日志文件目录
+		Fsync   bool   // 每条记录写入后是否 fsync
+	}
+	MQ struct {
+		Type                string        // "rabbitmq" 或 "redis"（使用 Redis Streams，适合没有独立消息中间件的部署）
+		StreamMaxLen        int64         // 每个 Stream 保留的近似最大消息数
+		StreamClaimIdle     time.Duration // 未确认消息空闲超过该时长后由其他消费者认领重试
+		StreamMaxDeliveries int           // 单条消息最多投递次数，超过后转入死信 Stream
+
+		// 发布容错：失败按指数退避重试，连续失败后熔断，重试耗尽或熔断期间写入本地缓冲由后台补发
+		PublishRetries            int           // 单次发布失败后的重试次数
+		PublishBackoff            time.Duration // 首次重试等待时间，之后按指数翻倍
+		PublishMaxBackoff         time.Duration // 重试等待时间上限
+		PublishBufferSize         int           // 本地缓冲容量，0 表示不缓冲，重试耗尽直接失败
+		PublishReplayInterval     time.Duration // 缓冲消息补发间隔
+		PublishBreakerFailures    int           // 连续失败多少次后熔断
+		PublishBreakerOpenTimeout time.Duration // 熔断持续时间，到期后放行探测发布
+
+		// 订单消息批量落库（仅 RabbitMQ）：每个工作器累积多条下单消息后合并到一个事务中落库
+		OrderBatchSize int           // 每批最多消息数，1 表示逐条处理
+		OrderBatchWait time.Duration // 自首条消息起最多等待多久凑批
+	}
+	RabbitMQ struct {
+		Host           string
+		Port           int
+		User           string
+		Password       string
+		VHost          string
+		ConnectTimeout time.Duration // 建立连接的超时时间
+		PublishConfirm bool          // 是否等待 Broker 确认消息已持久化
+	}
+	Notify struct {
+		// 邮件渠道，SMTPHost 为空时不启用
+		SMTPHost     string
+		SMTPPort     int
+		SMTPUsername string // 为空时不认证
+		SMTPPassword string
+		SMTPFrom     string
+
+		// 短信与推送渠道把通知 POST 给下游网关，URL 为空时不启用
+		SMSWebhookURL  string
+		PushWebhookURL string
+		WebhookSecret  string        // 请求体 HMAC-SHA256 签名密钥，为空时不签名
+		Timeout        time.Duration // 单次发送的超时时间
+
+		// 各渠道首次发送失败后的重试次数，等待时间从 RetryBackoff 起按指数翻倍
+		EmailRetries int
+		SMSRetries   int
+		PushRetries  int
+		RetryBackoff time.Duration
+	}
+	PaymentReminder struct {
+		Enabled      bool            // 是否在待支付订单过期前发送支付提醒
+		Offsets      []time.Duration // 过期前多久提醒，如 10m,2m，每个档位每个订单只提醒一次
+		ScanInterval time.Duration   // 扫描即将过期订单的间隔
+	}
+	OrderExpiry struct {
+		Enabled      bool          // 是否定时将超过支付期限的待支付订单标记为已过期并恢复库存
+		ScanInterval time.Duration // 扫描过期订单的间隔
+		BatchSize    int           // 每次查询最多处理的订单数
+		// DelayedMessages 使用 RabbitMQ 时订单落库后发布在过期时间投递的延时过期消息，扫描任务作为兜底
+		DelayedMessages bool
+	}
+	Order struct {
+		PaymentTimeout time.Duration // 购物车结算后的支付期限，过期任务沿用 ORDER_EXPIRY_* 的开关与扫描参数
+		CartMaxItems   int           // 购物车商品种类上限
+	}
+	Outbox struct {
+		Enabled      bool          // 是否先把订单创建与取消消息写入发件箱，再由中继发布到消息队列
+		Interval     time.Duration // 中继轮询间隔，写入消息时会立即唤醒
+		BatchSize    int           // 每批领取的消息数
+		MaxAttempts  int           // 最大发布次数，超过后标记为 failed 等待人工处理
+		RetryBackoff time.Duration // 首次重试间隔，之后每次翻倍
+		Retention    time.Duration // 已发布消息的保留时长
+	}
+	Settlement struct {
+		Enabled  bool          // 是否定时结算已结束的活动
+		Interval time.Duration // 结算任务执行间隔
+		Delay    time.Duration // 活动结束后等待多久再结算，应覆盖订单支付期限
+		Lookback time.Duration // 对结束多久以内的活动重复结算，覆盖迟到的支付与退款
+		FeeRate  float64       // 平台手续费率，按收入扣除退款后的金额计算
+	}
+	AdminJobs struct {
+		Workers   int           // 同时执行的后台任务数（预热全部活动、批量导入、报表生成）
+		QueueSize int           // 排队任务上限，超出时拒绝提交
+		Retain    int           // 保留多少个已结束的任务供查询
+		Timeout   time.Duration // 单个任务的执行超时
+	}
+	Cleanup struct {
+		Enabled     bool          // 是否定时清理已结束活动的 Redis 键、库存等待请求与缓存视图
+		Interval    time.Duration // 清理任务执行间隔
+		GracePeriod time.Duration // 活动结束后等待多久再清理，应覆盖订单支付期限
+		Lookback    time.Duration // 只清理结束多久以内的活动
+	}
+	Archive struct {
+		Enabled   bool          // 是否定时把早已结束的活动与终态订单迁移到归档表
+		Interval  time.Duration // 归档任务执行间隔
+		After     time.Duration // 订单创建、活动结束多久之后归档
+		BatchSize int           // 每个事务归档的最大行数
+	}
+	InventoryReservation struct {
+		TTL              time.Duration // 预留默认有效期，过期未消费或释放的预留归还到可用库存
+		MaxTTL           time.Duration // 调用方可指定的最长有效期
+		ReleaseInterval  time.Duration // 扫描过期预留的间隔
+		ReleaseBatchSize int           // 每次扫描最多处理的预留数
+	}
+	InventoryConflict struct {
+		MaxRetries int           // 库存变更遇到并发冲突（版本冲突、死锁、锁等待超时）后的重试次数，0 表示不重试
+		Backoff    time.Duration // 首次重试前的等待时间，之后按指数翻倍
+		MaxBackoff time.Duration // 重试等待时间上限
+	}
+	StockInvariant struct {
+		Interval          time.Duration // 对账任务检查进行中活动库存不变量的间隔
+		FreezeOnViolation bool          // 发现不变量被破坏时是否冻结活动，暂停参与等待人工处理
+		FreezeTTL         time.Duration // 冻结标记的过期时间
+	}
+	StockReconcile struct {
+		Enabled     bool          // 是否启动定时对账 Redis 库存、活动已售数量与订单
+		Interval    time.Duration // 定时对账间隔
+		Repair      bool          // 定时对账发现偏差时是否自动修复，默认只记录
+		Tolerance   int64         // 允许的偏差绝对值，超过才记为偏差
+		GracePeriod time.Duration // 修复前冻结活动等待在途消息落库的时间
+	}
+	DebugCapture struct {
+		Enabled      bool     // 启动时是否开启请求/响应体捕获，运行时可由管理员接口切换
+		Routes       []string // 需要捕获的路由模板，如 /api/v1/spike/participate
+		SampleRate   float64  // 采样率 (0, 1]
+		OnlyFailures bool     // 仅留存状态码 >= 400 的请求
+		MaxBodyBytes int      // 单个请求/响应体最多留存的字节数
+		BufferSize   int      // 环形缓冲区容量
+	}
+	AdminAudit struct {
+		Enabled         bool // 是否将管理员写操作记录到审计表
+		MaxPayloadBytes int  // 变更前后数据与请求体最多留存的字节数
 	}
 }
 
 // Load reads configuration from the environment (optionally loading a .env file if present),
 // applies defaults, and validates the result.
 func Load() (*Config, error) {
-	// Load .env if present. Environment variables that are already set will NOT be overridden.
-	_ = godotenv.Load()
+	return LoadWithOptions(Options{})
+}
+
+// LoadWithOptions 按 命令行覆盖 > 环境变量 > 配置文件 > 默认值 的优先级加载配置并校验，
+// 配置文件依次为 opts.Files、.env.<profile>、.env，见 Options
+func LoadWithOptions(opts Options) (*Config, error) {
+	l, err := newLayers(opts)
+	if err != nil {
+		return nil, err
+	}
+	return l.build()
+}
 
+// build 从合并后的配置来源构建配置并校验
+func (l *layers) build() (*Config, error) {
 	c := &Config{}
 
 	// Defaults
-	c.App.Name = getEnv("APP_NAME", "spike-server")
-	c.App.Env = getEnv("APP_ENV", "dev")
-	c.App.Port = getEnvAsInt("APP_PORT", 8080)
-	c.App.RequestTimeout = getEnvAsDurationMs("REQUEST_TIMEOUT_MS", 5000)
-	c.App.ShutdownTimeout = getEnvAsDurationMs("SHUTDOWN_TIMEOUT_MS", 5000)
-	c.App.Version = getEnv("APP_VERSION", "0.1.0")
-
-	c.Log.Level = strings.ToLower(getEnv("LOG_LEVEL", "debug"))
-	c.Log.Encoding = strings.ToLower(getEnv("LOG_ENCODING", "console"))
-
-	c.CORS.AllowedOrigins = getEnvAsCSV("CORS_ALLOWED_ORIGINS", []string{"*"})
-	c.CORS.AllowedMethods = getEnvAsCSV("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
-	c.CORS.AllowedHeaders = getEnvAsCSV("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type"})
-
-	c.Database.Host = getEnv("MYSQL_HOST", "localhost")
-	c.Database.Port = getEnvAsInt("MYSQL_PORT", 3306)
-	c.Database.User = getEnv("MYSQL_USER", "spike")
-	c.Database.Password = getEnv("MYSQL_PASSWORD", "spike")
-	c.Database.DBName = getEnv("MYSQL_DB", "spike")
-
-	c.JWT.Secret = getEnv("JWT_SECRET", "change_me_in_production")
-	c.JWT.AccessTokenTTL = getEnvAsDuration("ACCESS_TOKEN_TTL", "15m")
-	c.JWT.RefreshTokenTTL = getEnvAsDuration("REFRESH_TOKEN_TTL", "168h")
+	c.App.Name = l.getEnv("APP_NAME", "spike-server")
+	c.App.Env = l.getEnv("APP_ENV", "dev")
+	c.App.Port = l.getEnvAsInt("APP_PORT", 8080)
+	c.App.RequestTimeout = l.getEnvAsDurationMs("REQUEST_TIMEOUT_MS", 5000)
+	c.App.ShutdownTimeout = l.getEnvAsDurationMs("SHUTDOWN_TIMEOUT_MS", 5000)
+	c.App.DrainDelay = l.getEnvAsDurationMs("SHUTDOWN_DRAIN_DELAY_MS", 2000)
+	c.App.Version = l.getEnv("APP_VERSION", "0.1.0")
+	c.App.WorkerID = int64(l.getEnvAsInt("APP_WORKER_ID", 0))
+	c.App.ReloadInterval = l.getEnvAsDuration("CONFIG_RELOAD_INTERVAL", "5s")
+
+	c.Log.Level = strings.ToLower(l.getEnv("LOG_LEVEL", "debug"))
+	c.Log.Encoding = strings.ToLower(l.getEnv("LOG_ENCODING", "console"))
+	c.Log.RedactRules = l.getEnvAsCSV("LOG_REDACT_RULES", nil)
+	c.Log.RedactSalt = l.getEnv("LOG_REDACT_SALT", "")
+
+	c.CORS.AllowedOrigins = l.getEnvAsCSV("CORS_ALLOWED_ORIGINS", []string{"*"})
+	c.CORS.AllowedMethods = l.getEnvAsCSV("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
+	c.CORS.AllowedHeaders = l.getEnvAsCSV("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "Idempotency-Key"})
+
+	c.HTTP.CORSEnabled = l.getEnvAsBool("HTTP_CORS_ENABLED", true)
+	c.HTTP.CORSAllowCredentials = l.getEnvAsBool("CORS_ALLOW_CREDENTIALS", false)
+	c.HTTP.CORSMaxAge = l.getEnvAsDuration("CORS_MAX_AGE", "10m")
+	c.HTTP.SecurityHeaders = l.getEnvAsBool("HTTP_SECURITY_HEADERS_ENABLED", true)
+	c.HTTP.HSTSMaxAge = l.getEnvAsDuration("HTTP_HSTS_MAX_AGE", "4320h")
+	c.HTTP.MetricsEnabled = l.getEnvAsBool("HTTP_METRICS_ENABLED", true)
+	c.HTTP.CompressionEnabled = l.getEnvAsBool("HTTP_COMPRESSION_ENABLED", true)
+	c.HTTP.CompressionMinSize = l.getEnvAsInt("HTTP_COMPRESSION_MIN_SIZE", 1024)
+	c.HTTP.ETagEnabled = l.getEnvAsBool("HTTP_ETAG_ENABLED", true)
+	c.HTTP.RequestLimitMethods = l.getEnvAsCSV("HTTP_REQUEST_LIMIT_METHODS", []string{"POST"})
+	c.HTTP.MaxBodyBytes = int64(l.getEnvAsInt("HTTP_MAX_BODY_BYTES", 1<<20))
+	c.HTTP.SlowRequestThreshold = l.getEnvAsDuration("HTTP_SLOW_REQUEST_THRESHOLD", "1s")
+	// 活动导入上传的文件较大、逐行写库耗时较长，默认放宽
+	routeTimeouts, err := parseRouteValues(l.getEnvAsCSV("HTTP_ROUTE_TIMEOUTS",
+		[]string{"/api/v1/admin/spike/events/import=60s"}), time.ParseDuration)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP_ROUTE_TIMEOUTS: %w", err)
+	}
+	c.HTTP.RouteTimeouts = routeTimeouts
+	routeMaxBodyBytes, err := parseRouteValues(l.getEnvAsCSV("HTTP_ROUTE_MAX_BODY_BYTES",
+		[]string{"/api/v1/admin/spike/events/import=2097152"}), func(v string) (int64, error) {
+		return strconv.ParseInt(v, 10, 64)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("HTTP_ROUTE_MAX_BODY_BYTES: %w", err)
+	}
+	c.HTTP.RouteMaxBodyBytes = routeMaxBodyBytes
+	c.HTTP.TrustedProxies = l.getEnvAsCSV("HTTP_TRUSTED_PROXIES", nil)
+
+	c.Database.Host = l.getEnv("MYSQL_HOST", "localhost")
+	c.Database.Port = l.getEnvAsInt("MYSQL_PORT", 3306)
+	c.Database.User = l.getEnv("MYSQL_USER", "spike")
+	c.Database.Password = l.getEnv("MYSQL_PASSWORD", "spike")
+	c.Database.DBName = l.getEnv("MYSQL_DB", "spike")
+	c.Database.QueryTimeout = l.getEnvAsDuration("MYSQL_QUERY_TIMEOUT", "3s")
+	c.Database.ReplicaAddrs = l.getEnvAsCSV("MYSQL_REPLICA_ADDRS", nil)
+	c.Database.ReplicaMaxLag = l.getEnvAsDuration("MYSQL_REPLICA_MAX_LAG", "5s")
+	c.Database.ReplicaCheckInterval = l.getEnvAsDuration("MYSQL_REPLICA_CHECK_INTERVAL", "5s")
+
+	c.JWT.Secret = l.getEnv("JWT_SECRET", "change_me_in_production")
+	c.JWT.AccessTokenTTL = l.getEnvAsDuration("ACCESS_TOKEN_TTL", "15m")
+	c.JWT.RefreshTokenTTL = l.getEnvAsDuration("REFRESH_TOKEN_TTL", "168h")
+	c.JWT.ImpersonationTokenTTL = l.getEnvAsDuration("IMPERSONATION_TOKEN_TTL", "10m")
+
+	// 登录失败锁定与两步验证
+	c.Login.MaxFailures = l.getEnvAsInt("LOGIN_MAX_FAILURES", 5)
+	c.Login.IPMaxFailures = l.getEnvAsInt("LOGIN_IP_MAX_FAILURES", 50)
+	c.Login.FailureWindow = l.getEnvAsDuration("LOGIN_FAILURE_WINDOW", "15m")
+	c.Login.LockoutBase = l.getEnvAsDuration("LOGIN_LOCKOUT_BASE", "1m")
+	c.Login.LockoutMax = l.getEnvAsDuration("LOGIN_LOCKOUT_MAX", "24h")
+	c.Login.LockoutReset = l.getEnvAsDuration("LOGIN_LOCKOUT_RESET", "24h")
+	c.Login.TOTPIssuer = l.getEnv("TOTP_ISSUER", c.App.Name)
+	totpKey, err := base64.StdEncoding.DecodeString(l.getEnv("TOTP_ENCRYPTION_KEY", ""))
+	if err != nil {
+		return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY: %w", err)
+	}
+	c.Login.TOTPEncryptionKey = totpKey
 
 	// 数据库迁移配置
-	c.Migrations.Dir = getEnv("MIGRATIONS_DIR", "migrations")
+	c.Migrations.Dir = l.getEnv("MIGRATIONS_DIR", "migrations")
 
 	// 缓存配置
-	c.Cache.Enabled = getEnvAsBool("CACHE_ENABLED", true)
-	c.Cache.TTL = getEnvAsDuration("CACHE_TTL", "5m")
-	c.Cache.Type = getEnv("CACHE_TYPE", "memory")
+	c.Cache.Enabled = l.getEnvAsBool("CACHE_ENABLED", true)
+	c.Cache.TTL = l.getEnvAsDuration("CACHE_TTL", "5m")
+	c.Cache.Type = l.getEnv("CACHE_TYPE", "memory")
+	c.Cache.AvailabilityTTL = l.getEnvAsDuration("CACHE_AVAILABILITY_TTL", "2s")
+	c.Cache.ProductDetailTTL = l.getEnvAsDuration("CACHE_PRODUCT_DETAIL_TTL", "30s")
+	c.Cache.UserTTL = l.getEnvAsDuration("CACHE_USER_TTL", "30s")
+	c.Cache.InvalidationEnabled = l.getEnvAsBool("CACHE_INVALIDATION_ENABLED", true)
 
 	// Redis配置
-	c.Redis.Host = getEnv("REDIS_HOST", "localhost")
-	c.Redis.Port = getEnvAsInt("REDIS_PORT", 6379)
-	c.Redis.Password = getEnv("REDIS_PASSWORD", "")
-	c.Redis.DB = getEnvAsInt("REDIS_DB", 0)
+	c.Redis.Host = l.getEnv("REDIS_HOST", "localhost")
+	c.Redis.Port = l.getEnvAsInt("REDIS_PORT", 6379)
+	c.Redis.Password = l.getEnv("REDIS_PASSWORD", "")
+	c.Redis.DB = l.getEnvAsInt("REDIS_DB", 0)
+	c.Redis.Mode = strings.ToLower(l.getEnv("REDIS_MODE", "standalone"))
+	c.Redis.Addrs = l.getEnvAsCSV("REDIS_ADDRS", nil)
+	c.Redis.MasterName = l.getEnv("REDIS_MASTER_NAME", "")
+	c.Redis.SentinelPassword = l.getEnv("REDIS_SENTINEL_PASSWORD", "")
+	c.Redis.KeyPrefix = l.getEnv("REDIS_KEY_PREFIX", "")
+	c.Redis.CacheDB = l.getEnvAsInt("REDIS_CACHE_DB", c.Redis.DB)
+	c.Redis.LimiterDB = l.getEnvAsInt("REDIS_LIMITER_DB", c.Redis.DB)
+	c.Redis.SpikeDB = l.getEnvAsInt("REDIS_SPIKE_DB", c.Redis.DB)
+
+	// 文件存储配置（报表导出等）
+	c.Storage.Dir = l.getEnv("STORAGE_DIR", "data/storage")
+	c.Storage.URLSecret = l.getEnv("STORAGE_URL_SECRET", c.JWT.Secret)
+	c.Storage.URLTTL = l.getEnvAsDuration("STORAGE_URL_TTL", "15m")
+
+	// 资源访问控制配置
+	c.Authz.HideForeignResources = l.getEnvAsBool("AUTHZ_HIDE_FOREIGN_RESOURCES", false)
+
+	// 秒杀服务配置
+	c.Spike.OrderExpireTime = l.getEnvAsDuration("SPIKE_ORDER_EXPIRE_TIME", "30m")
+	c.Spike.OrderExtension = l.getEnvAsDuration("SPIKE_ORDER_EXTENSION", "10m")
+	c.Spike.GlobalRateLimit = int64(l.getEnvAsInt("SPIKE_GLOBAL_RATE_LIMIT", 1000))
+	c.Spike.UserRateLimit = int64(l.getEnvAsInt("SPIKE_USER_RATE_LIMIT", 5))
+	c.Spike.RateLimitWindow = l.getEnvAsDuration("SPIKE_RATE_LIMIT_WINDOW", "1m")
+	c.Spike.StockWarmupEnabled = l.getEnvAsBool("SPIKE_STOCK_WARMUP_ENABLED", true)
+	c.Spike.StockWarmupTime = l.getEnvAsDuration("SPIKE_STOCK_WARMUP_TIME", "5m")
+	c.Spike.StockCacheTTL = l.getEnvAsDuration("SPIKE_STOCK_CACHE_TTL", "2h")
+	c.Spike.StockTTLBuffer = l.getEnvAsDuration("SPIKE_STOCK_TTL_BUFFER", "30m")
+	c.Spike.StockTTLRefresh = l.getEnvAsDuration("SPIKE_STOCK_TTL_REFRESH_INTERVAL", "1m")
+	c.Spike.UserMarkTTL = l.getEnvAsDuration("SPIKE_USER_MARK_TTL", "24h")
+	c.Spike.IdempotencyTTL = l.getEnvAsDuration("SPIKE_IDEMPOTENCY_TTL", "24h")
+	c.Spike.MaxRetryAttempts = l.getEnvAsInt("SPIKE_MAX_RETRY_ATTEMPTS", 3)
+	c.Spike.RetryInterval = l.getEnvAsDuration("SPIKE_RETRY_INTERVAL", "1s")
+	c.Spike.PreviewScanInterval = l.getEnvAsDuration("SPIKE_PREVIEW_SCAN_INTERVAL", "10s")
+	c.Spike.LifecycleInterval = l.getEnvAsDuration("SPIKE_LIFECYCLE_INTERVAL", "1s")
+	c.Spike.OrderDetailJoin = l.getEnvAsBool("SPIKE_ORDER_DETAIL_JOIN", false)
+	c.Spike.AnonymousRateLimit = int64(l.getEnvAsInt("SPIKE_ANONYMOUS_RATE_LIMIT", 30))
+	c.Spike.PublicCacheTTL = l.getEnvAsDuration("SPIKE_PUBLIC_CACHE_TTL", "5s")
+	c.Spike.DashboardCacheTTL = l.getEnvAsDuration("SPIKE_DASHBOARD_CACHE_TTL", "15s")
+
+	// 秒杀参与令牌配置
+	c.SpikeToken.Enabled = l.getEnvAsBool("SPIKE_TOKEN_ENABLED", false)
+	c.SpikeToken.Secret = l.getEnv("SPIKE_TOKEN_SECRET", c.JWT.Secret)
+	c.SpikeToken.IssueMultiplier = l.getEnvAsInt("SPIKE_TOKEN_ISSUE_MULTIPLIER", 2)
+	c.SpikeToken.IssueRate = l.getEnvAsInt("SPIKE_TOKEN_ISSUE_RATE", 5)
+
+	// 参与前防刷挑战
+	c.SpikeChallenge.Difficulty = l.getEnvAsInt("SPIKE_CHALLENGE_DIFFICULTY", 18)
+	c.SpikeChallenge.TTL = l.getEnvAsDuration("SPIKE_CHALLENGE_TTL", "2m")
+	c.SpikeChallenge.IssueRate = l.getEnvAsInt("SPIKE_CHALLENGE_ISSUE_RATE", 10)
+
+	// 等候室
+	c.SpikeWaitingRoom.DispatchInterval = l.getEnvAsDuration("SPIKE_WAITING_ROOM_DISPATCH_INTERVAL", "200ms")
+
+	// 库存缓存不可用时的降级参与
+	c.SpikeDegraded.Mode = l.getEnv("SPIKE_DEGRADED_MODE", "queue")
+	c.SpikeDegraded.MaxConcurrency = l.getEnvAsInt("SPIKE_DEGRADED_MAX_CONCURRENCY", 8)
+	c.SpikeDegraded.BreakerFailures = l.getEnvAsInt("SPIKE_CACHE_BREAKER_FAILURES", 5)
+	c.SpikeDegraded.BreakerOpenTimeout = l.getEnvAsDuration("SPIKE_CACHE_BREAKER_OPEN_TIMEOUT", "10s")
+
+	// 活动分享链接
+	c.SpikeShare.Secret = l.getEnv("SPIKE_SHARE_SECRET", c.JWT.Secret)
+	c.SpikeShare.LinkBase = l.getEnv("SPIKE_SHARE_LINK_BASE", "/spike/events")
+	c.SpikeShare.LinkTTL = l.getEnvAsDuration("SPIKE_SHARE_LINK_TTL", "72h")
+
+	// 支付渠道
+	c.Payment.Provider = l.getEnv("PAYMENT_PROVIDER", "sandbox")
+	c.Payment.WebhookSecret = l.getEnv("PAYMENT_WEBHOOK_SECRET", "")
+	c.Payment.Currency = l.getEnv("PAYMENT_CURRENCY", "CNY")
+	c.Payment.SandboxAuthorize = l.getEnvAsBool("PAYMENT_SANDBOX_AUTHORIZE_ENABLED", false)
+
+	// 秒杀参与日志配置
+	c.Journal.Enabled = l.getEnvAsBool("JOURNAL_ENABLED", false)
+	c.Journal.Dir = l.getEnv("JOURNAL_DIR", "data/journal")
+	c.Journal.Fsync = l.getEnvAsBool("JOURNAL_FSYNC", false)
+
+	// 消息队列配置
+	c.MQ.Type = strings.ToLower(l.getEnv("MQ_TYPE", "rabbitmq"))
+	c.MQ.StreamMaxLen = int64(l.getEnvAsInt("MQ_STREAM_MAXLEN", 100000))
+	c.MQ.StreamClaimIdle = l.getEnvAsDuration("MQ_STREAM_CLAIM_IDLE", "30s")
+	c.MQ.StreamMaxDeliveries = l.getEnvAsInt("MQ_STREAM_MAX_DELIVERIES", 5)
+	c.MQ.PublishRetries = l.getEnvAsInt("MQ_PUBLISH_RETRIES", 2)
+	c.MQ.PublishBackoff = l.getEnvAsDuration("MQ_PUBLISH_BACKOFF", "50ms")
+	c.MQ.PublishMaxBackoff = l.getEnvAsDuration("MQ_PUBLISH_MAX_BACKOFF", "1s")
+	c.MQ.PublishBufferSize = l.getEnvAsInt("MQ_PUBLISH_BUFFER_SIZE", 1000)
+	c.MQ.PublishReplayInterval = l.getEnvAsDuration("MQ_PUBLISH_REPLAY_INTERVAL", "1s")
+	c.MQ.PublishBreakerFailures = l.getEnvAsInt("MQ_PUBLISH_BREAKER_FAILURES", 5)
+	c.MQ.PublishBreakerOpenTimeout = l.getEnvAsDuration("MQ_PUBLISH_BREAKER_OPEN_TIMEOUT", "10s")
+	c.MQ.OrderBatchSize = l.getEnvAsInt("MQ_ORDER_BATCH_SIZE", 1)
+	c.MQ.OrderBatchWait = l.getEnvAsDuration("MQ_ORDER_BATCH_WAIT", "20ms")
+
+	// 通知渠道，均未配置时通知消息只记录日志
+	c.Notify.SMTPHost = l.getEnv("NOTIFY_SMTP_HOST", "")
+	c.Notify.SMTPPort = l.getEnvAsInt("NOTIFY_SMTP_PORT", 587)
+	c.Notify.SMTPUsername = l.getEnv("NOTIFY_SMTP_USERNAME", "")
+	c.Notify.SMTPPassword = l.getEnv("NOTIFY_SMTP_PASSWORD", "")
+	c.Notify.SMTPFrom = l.getEnv("NOTIFY_SMTP_FROM", "")
+	c.Notify.SMSWebhookURL = l.getEnv("NOTIFY_SMS_WEBHOOK_URL", "")
+	c.Notify.PushWebhookURL = l.getEnv("NOTIFY_PUSH_WEBHOOK_URL", "")
+	c.Notify.WebhookSecret = l.getEnv("NOTIFY_WEBHOOK_SECRET", "")
+	c.Notify.Timeout = l.getEnvAsDuration("NOTIFY_TIMEOUT", "5s")
+	c.Notify.EmailRetries = l.getEnvAsInt("NOTIFY_EMAIL_RETRIES", 2)
+	c.Notify.SMSRetries = l.getEnvAsInt("NOTIFY_SMS_RETRIES", 1)
+	c.Notify.PushRetries = l.getEnvAsInt("NOTIFY_PUSH_RETRIES", 3)
+	c.Notify.RetryBackoff = l.getEnvAsDuration("NOTIFY_RETRY_BACKOFF", "200ms")
+
+	// RabbitMQ 配置（MQ_TYPE=rabbitmq 时使用），交换机与队列在启动时按固定拓扑声明
+	c.RabbitMQ.Host = l.getEnv("RABBITMQ_HOST", "localhost")
+	c.RabbitMQ.Port = l.getEnvAsInt("RABBITMQ_AMQP_PORT", 5672)
+	c.RabbitMQ.User = l.getEnv("RABBITMQ_USER", "guest")
+	c.RabbitMQ.Password = l.getEnv("RABBITMQ_PASSWORD", "guest")
+	c.RabbitMQ.VHost = l.getEnv("RABBITMQ_VHOST", "/")
+	c.RabbitMQ.ConnectTimeout = l.getEnvAsDuration("RABBITMQ_CONNECT_TIMEOUT", "10s")
+	c.RabbitMQ.PublishConfirm = l.getEnvAsBool("RABBITMQ_PUBLISH_CONFIRM", true)
+
+	// 支付提醒配置
+	c.PaymentReminder.Enabled = l.getEnvAsBool("PAYMENT_REMINDER_ENABLED", true)
+	c.PaymentReminder.Offsets = l.getEnvAsDurationCSV("PAYMENT_REMINDER_OFFSETS", []time.Duration{10 * time.Minute, 2 * time.Minute})
+	c.PaymentReminder.ScanInterval = l.getEnvAsDuration("PAYMENT_REMINDER_INTERVAL", "30s")
+
+	// 订单过期配置
+	c.OrderExpiry.Enabled = l.getEnvAsBool("ORDER_EXPIRY_ENABLED", true)
+	c.OrderExpiry.ScanInterval = l.getEnvAsDuration("ORDER_EXPIRY_INTERVAL", "30s")
+	c.OrderExpiry.BatchSize = l.getEnvAsInt("ORDER_EXPIRY_BATCH", 100)
+	c.OrderExpiry.DelayedMessages = l.getEnvAsBool("ORDER_EXPIRY_DELAYED_MESSAGES", true)
+
+	// 普通订单配置
+	c.Order.PaymentTimeout = l.getEnvAsDuration("ORDER_PAYMENT_TIMEOUT", "30m")
+	c.Order.CartMaxItems = l.getEnvAsInt("ORDER_CART_MAX_ITEMS", 100)
+
+	// 消息发件箱配置
+	c.Outbox.Enabled = l.getEnvAsBool("OUTBOX_ENABLED", true)
+	c.Outbox.Interval = l.getEnvAsDuration("OUTBOX_RELAY_INTERVAL", "1s")
+	c.Outbox.BatchSize = l.getEnvAsInt("OUTBOX_BATCH", 100)
+	c.Outbox.MaxAttempts = l.getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10)
+	c.Outbox.RetryBackoff = l.getEnvAsDuration("OUTBOX_RETRY_BACKOFF", "1s")
+	c.Outbox.Retention = l.getEnvAsDuration("OUTBOX_RETENTION", "168h")
+
+	// 活动结算配置
+	c.Settlement.Enabled = l.getEnvAsBool("SETTLEMENT_ENABLED", true)
+	c.Settlement.Interval = l.getEnvAsDuration("SETTLEMENT_INTERVAL", "10m")
+	c.Settlement.Delay = l.getEnvAsDuration("SETTLEMENT_DELAY", "1h")
+	c.Settlement.Lookback = l.getEnvAsDuration("SETTLEMENT_LOOKBACK", "72h")
+	c.Settlement.FeeRate = l.getEnvAsFloat("SETTLEMENT_FEE_RATE", 0)
+
+	// 后台任务队列配置
+	c.AdminJobs.Workers = l.getEnvAsInt("ADMIN_JOB_WORKERS", 2)
+	c.AdminJobs.QueueSize = l.getEnvAsInt("ADMIN_JOB_QUEUE_SIZE", 32)
+	c.AdminJobs.Retain = l.getEnvAsInt("ADMIN_JOB_RETAIN", 100)
+	c.AdminJobs.Timeout = l.getEnvAsDuration("ADMIN_JOB_TIMEOUT", "10m")
+
+	// 活动资源清理配置
+	c.Cleanup.Enabled = l.getEnvAsBool("SPIKE_CLEANUP_ENABLED", true)
+	c.Cleanup.Interval = l.getEnvAsDuration("SPIKE_CLEANUP_INTERVAL", "10m")
+	c.Cleanup.GracePeriod = l.getEnvAsDuration("SPIKE_CLEANUP_GRACE_PERIOD", "1h")
+	c.Cleanup.Lookback = l.getEnvAsDuration("SPIKE_CLEANUP_LOOKBACK", "24h")
+
+	c.Archive.Enabled = l.getEnvAsBool("SPIKE_ARCHIVE_ENABLED", false)
+	c.Archive.Interval = l.getEnvAsDuration("SPIKE_ARCHIVE_INTERVAL", "1h")
+	c.Archive.After = l.getEnvAsDuration("SPIKE_ARCHIVE_AFTER", "2160h")
+	c.Archive.BatchSize = l.getEnvAsInt("SPIKE_ARCHIVE_BATCH_SIZE", 500)
+
+	// 库存预留配置
+	c.InventoryReservation.TTL = l.getEnvAsDuration("INVENTORY_RESERVATION_TTL", "15m")
+	c.InventoryReservation.MaxTTL = l.getEnvAsDuration("INVENTORY_RESERVATION_MAX_TTL", "2h")
+	c.InventoryReservation.ReleaseInterval = l.getEnvAsDuration("INVENTORY_RESERVATION_RELEASE_INTERVAL", "30s")
+	c.InventoryReservation.ReleaseBatchSize = l.getEnvAsInt("INVENTORY_RESERVATION_RELEASE_BATCH", 100)
+
+	// 库存并发冲突重试配置
+	c.InventoryConflict.MaxRetries = l.getEnvAsInt("INVENTORY_CONFLICT_RETRIES", 3)
+	c.InventoryConflict.Backoff = l.getEnvAsDuration("INVENTORY_CONFLICT_BACKOFF", "10ms")
+	c.InventoryConflict.MaxBackoff = l.getEnvAsDuration("INVENTORY_CONFLICT_MAX_BACKOFF", "200ms")
+
+	// 库存不变量检查配置
+	c.StockInvariant.Interval = l.getEnvAsDuration("STOCK_INVARIANT_INTERVAL", "1m")
+	c.StockInvariant.FreezeOnViolation = l.getEnvAsBool("STOCK_INVARIANT_FREEZE", false)
+	c.StockInvariant.FreezeTTL = l.getEnvAsDuration("STOCK_INVARIANT_FREEZE_TTL", "10m")
+
+	// 库存对账配置
+	c.StockReconcile.Enabled = l.getEnvAsBool("STOCK_RECONCILE_ENABLED", true)
+	c.StockReconcile.Interval = l.getEnvAsDuration("STOCK_RECONCILE_INTERVAL", "5m")
+	c.StockReconcile.Repair = l.getEnvAsBool("STOCK_RECONCILE_REPAIR", false)
+	c.StockReconcile.Tolerance = int64(l.getEnvAsInt("STOCK_RECONCILE_TOLERANCE", 0))
+	c.StockReconcile.GracePeriod = l.getEnvAsDuration("STOCK_RECONCILE_GRACE_PERIOD", "3s")
+
+	// 调试捕获配置
+	c.DebugCapture.Enabled = l.getEnvAsBool("DEBUG_CAPTURE_ENABLED", false)
+	c.DebugCapture.Routes = l.getEnvAsCSV("DEBUG_CAPTURE_ROUTES", nil)
+	c.DebugCapture.SampleRate = l.getEnvAsFloat("DEBUG_CAPTURE_SAMPLE_RATE", 1)
+	c.DebugCapture.OnlyFailures = l.getEnvAsBool("DEBUG_CAPTURE_ONLY_FAILURES", true)
+	c.DebugCapture.MaxBodyBytes = l.getEnvAsInt("DEBUG_CAPTURE_MAX_BODY_BYTES", 4096)
+	c.DebugCapture.BufferSize = l.getEnvAsInt("DEBUG_CAPTURE_BUFFER_SIZE", 200)
+
+	c.AdminAudit.Enabled = l.getEnvAsBool("ADMIN_AUDIT_ENABLED", true)
+	c.AdminAudit.MaxPayloadBytes = l.getEnvAsInt("ADMIN_AUDIT_MAX_PAYLOAD_BYTES", 16384)
 
 	if err := validate(c); err != nil {
 		return nil, err
@@ -126,8 +689,36 @@ func validate(c *Config) error {
 
 	errs = append(errs, validateApp(c)...)
 	errs = append(errs, validateLog(c)...)
+	errs = append(errs, validateHTTP(c)...)
 	errs = append(errs, validateDatabase(c)...)
 	errs = append(errs, validateJWT(c)...)
+	errs = append(errs, validateLogin(c)...)
+	errs = append(errs, validateRedis(c)...)
+	errs = append(errs, validateStorage(c)...)
+	errs = append(errs, validateSpike(c)...)
+	errs = append(errs, validateSpikeToken(c)...)
+	errs = append(errs, validateSpikeChallenge(c)...)
+	errs = append(errs, validateSpikeWaitingRoom(c)...)
+	errs = append(errs, validateSpikeDegraded(c)...)
+	errs = append(errs, validateSpikeShare(c)...)
+	errs = append(errs, validatePayment(c)...)
+	errs = append(errs, validateJournal(c)...)
+	errs = append(errs, validateMQ(c)...)
+	errs = append(errs, validateNotify(c)...)
+	errs = append(errs, validatePaymentReminder(c)...)
+	errs = append(errs, validateOrderExpiry(c)...)
+	errs = append(errs, validateOrder(c)...)
+	errs = append(errs, validateOutbox(c)...)
+	errs = append(errs, validateSettlement(c)...)
+	errs = append(errs, validateAdminJobs(c)...)
+	errs = append(errs, validateCleanup(c)...)
+	errs = append(errs, validateArchive(c)...)
+	errs = append(errs, validateInventoryReservation(c)...)
+	errs = append(errs, validateInventoryConflict(c)...)
+	errs = append(errs, validateStockInvariant(c)...)
+	errs = append(errs, validateStockReconcile(c)...)
+	errs = append(errs, validateDebugCapture(c)...)
+	errs = append(errs, validateAdminAudit(c)...)
 
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
@@ -139,10 +730,10 @@ func validateApp(c *Config) []string {
 	var errs []string
 
 	switch c.App.Env {
-	case "dev", "test", "prod":
+	case "dev", "staging", "test", "prod":
 		// ok
 	default:
-		errs = append(errs, fmt.Sprintf("APP_ENV must be one of dev|test|prod, got %q", c.App.Env))
+		errs = append(errs, fmt.Sprintf("APP_ENV must be one of dev|staging|test|prod, got %q", c.App.Env))
 	}
 
 	if c.App.Port < 1 || c.App.Port > 65535 {
@@ -153,6 +744,18 @@ func validateApp(c *Config) []string {
 		errs = append(errs, fmt.Sprintf("REQUEST_TIMEOUT_MS must be > 0, got %s", c.App.RequestTimeout))
 	}
 
+	if c.App.DrainDelay < 0 {
+		errs = append(errs, fmt.Sprintf("SHUTDOWN_DRAIN_DELAY_MS must be >= 0, got %s", c.App.DrainDelay))
+	}
+
+	if c.App.WorkerID < 0 || c.App.WorkerID > idgen.MaxWorkerID {
+		errs = append(errs, fmt.Sprintf("APP_WORKER_ID must be in range 0..%d, got %d", idgen.MaxWorkerID, c.App.WorkerID))
+	}
+
+	if c.App.ReloadInterval < 0 {
+		errs = append(errs, fmt.Sprintf("CONFIG_RELOAD_INTERVAL must be >= 0, got %s", c.App.ReloadInterval))
+	}
+
 	return errs
 }
 
@@ -173,6 +776,53 @@ func validateLog(c *Config) []string {
 		errs = append(errs, fmt.Sprintf("LOG_ENCODING must be one of json|console, got %q", c.Log.Encoding))
 	}
 
+	if _, err := logger.ParseRedactRules(c.Log.RedactRules); err != nil {
+		errs = append(errs, fmt.Sprintf("LOG_REDACT_RULES: %v", err))
+	}
+
+	return errs
+}
+
+func validateHTTP(c *Config) []string {
+	var errs []string
+
+	// 携带凭证时回显请求来源，"*" 会使任意站点都能带着用户凭证调用接口
+	if c.HTTP.CORSEnabled && c.HTTP.CORSAllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		errs = append(errs, "CORS_ALLOWED_ORIGINS must list explicit origins when CORS_ALLOW_CREDENTIALS=true")
+	}
+	if c.HTTP.CORSMaxAge < 0 {
+		errs = append(errs, fmt.Sprintf("CORS_MAX_AGE must be >= 0, got %s", c.HTTP.CORSMaxAge))
+	}
+	if c.HTTP.HSTSMaxAge < 0 {
+		errs = append(errs, fmt.Sprintf("HTTP_HSTS_MAX_AGE must be >= 0, got %s", c.HTTP.HSTSMaxAge))
+	}
+	if c.HTTP.CompressionMinSize < 0 {
+		errs = append(errs, fmt.Sprintf("HTTP_COMPRESSION_MIN_SIZE must be >= 0, got %d", c.HTTP.CompressionMinSize))
+	}
+	if c.HTTP.MaxBodyBytes < 0 {
+		errs = append(errs, fmt.Sprintf("HTTP_MAX_BODY_BYTES must be >= 0, got %d", c.HTTP.MaxBodyBytes))
+	}
+	if c.HTTP.SlowRequestThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("HTTP_SLOW_REQUEST_THRESHOLD must be >= 0, got %s", c.HTTP.SlowRequestThreshold))
+	}
+	for route, d := range c.HTTP.RouteTimeouts {
+		if d < 0 {
+			errs = append(errs, fmt.Sprintf("HTTP_ROUTE_TIMEOUTS entry %q must be >= 0, got %s", route, d))
+		}
+	}
+	for route, n := range c.HTTP.RouteMaxBodyBytes {
+		if n < 0 {
+			errs = append(errs, fmt.Sprintf("HTTP_ROUTE_MAX_BODY_BYTES entry %q must be >= 0, got %d", route, n))
+		}
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Sprintf("HTTP_TRUSTED_PROXIES entry %q must be an IP or CIDR", proxy))
+			}
+		}
+	}
+
 	return errs
 }
 
@@ -191,6 +841,22 @@ func validateDatabase(c *Config) []string {
 	if strings.TrimSpace(c.Database.DBName) == "" {
 		errs = append(errs, "MYSQL_DB cannot be empty")
 	}
+	if c.Database.QueryTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("MYSQL_QUERY_TIMEOUT must be >= 0, got %s", c.Database.QueryTimeout))
+	}
+	for _, addr := range c.Database.ReplicaAddrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fmt.Sprintf("MYSQL_REPLICA_ADDRS entry %q must be host:port", addr))
+		}
+	}
+	if len(c.Database.ReplicaAddrs) > 0 {
+		if c.Database.ReplicaMaxLag <= 0 {
+			errs = append(errs, fmt.Sprintf("MYSQL_REPLICA_MAX_LAG must be > 0, got %s", c.Database.ReplicaMaxLag))
+		}
+		if c.Database.ReplicaCheckInterval <= 0 {
+			errs = append(errs, fmt.Sprintf("MYSQL_REPLICA_CHECK_INTERVAL must be > 0, got %s", c.Database.ReplicaCheckInterval))
+		}
+	}
 
 	return errs
 }
@@ -209,19 +875,677 @@ func validateJWT(c *Config) []string {
 	if c.JWT.RefreshTokenTTL <= 0 {
 		errs = append(errs, fmt.Sprintf("REFRESH_TOKEN_TTL must be > 0, got %s", c.JWT.RefreshTokenTTL))
 	}
+	if c.JWT.ImpersonationTokenTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("IMPERSONATION_TOKEN_TTL must be > 0, got %s", c.JWT.ImpersonationTokenTTL))
+	}
+
+	return errs
+}
+
+func validateLogin(c *Config) []string {
+	var errs []string
+
+	if c.Login.MaxFailures < 0 {
+		errs = append(errs, fmt.Sprintf("LOGIN_MAX_FAILURES must be >= 0, got %d", c.Login.MaxFailures))
+	}
+	if c.Login.IPMaxFailures < 0 {
+		errs = append(errs, fmt.Sprintf("LOGIN_IP_MAX_FAILURES must be >= 0, got %d", c.Login.IPMaxFailures))
+	}
+	if c.Login.MaxFailures > 0 || c.Login.IPMaxFailures > 0 {
+		if c.Login.FailureWindow <= 0 {
+			errs = append(errs, fmt.Sprintf("LOGIN_FAILURE_WINDOW must be > 0, got %s", c.Login.FailureWindow))
+		}
+		if c.Login.LockoutBase <= 0 {
+			errs = append(errs, fmt.Sprintf("LOGIN_LOCKOUT_BASE must be > 0, got %s", c.Login.LockoutBase))
+		}
+		if c.Login.LockoutMax < c.Login.LockoutBase {
+			errs = append(errs, fmt.Sprintf("LOGIN_LOCKOUT_MAX must be >= LOGIN_LOCKOUT_BASE, got %s", c.Login.LockoutMax))
+		}
+		if c.Login.LockoutReset <= 0 {
+			errs = append(errs, fmt.Sprintf("LOGIN_LOCKOUT_RESET must be > 0, got %s", c.Login.LockoutReset))
+		}
+	}
+	if strings.TrimSpace(c.Login.TOTPIssuer) == "" {
+		errs = append(errs, "TOTP_ISSUER must not be empty")
+	}
+	switch len(c.Login.TOTPEncryptionKey) {
+	case 0:
+		if c.App.Env == "prod" {
+			errs = append(errs, "TOTP_ENCRYPTION_KEY must be set in production")
+		}
+	case 32:
+	default:
+		errs = append(errs, fmt.Sprintf("TOTP_ENCRYPTION_KEY must decode to 32 bytes, got %d", len(c.Login.TOTPEncryptionKey)))
+	}
+
+	return errs
+}
+
+func validateRedis(c *Config) []string {
+	var errs []string
+
+	dbs := []struct {
+		name string
+		db   int
+	}{
+		{"REDIS_DB", c.Redis.DB},
+		{"REDIS_CACHE_DB", c.Redis.CacheDB},
+		{"REDIS_LIMITER_DB", c.Redis.LimiterDB},
+		{"REDIS_SPIKE_DB", c.Redis.SpikeDB},
+	}
+	for _, d := range dbs {
+		if d.db < 0 || d.db > 15 {
+			errs = append(errs, fmt.Sprintf("%s must be in range 0..15, got %d", d.name, d.db))
+		}
+	}
+	if err := keys.ValidatePrefix(c.Redis.KeyPrefix); err != nil {
+		errs = append(errs, fmt.Sprintf("REDIS_KEY_PREFIX: %v", err))
+	}
+
+	switch c.Redis.Mode {
+	case "standalone":
+	case "cluster":
+		if len(c.Redis.Addrs) == 0 {
+			errs = append(errs, "REDIS_ADDRS is required when REDIS_MODE=cluster")
+		}
+		// Redis Cluster 只有 0 号数据库，按逻辑存储拆分 DB 不可用
+		for _, d := range dbs {
+			if d.db != 0 {
+				errs = append(errs, fmt.Sprintf("%s must be 0 when REDIS_MODE=cluster, got %d", d.name, d.db))
+			}
+		}
+	case "sentinel":
+		if len(c.Redis.Addrs) == 0 {
+			errs = append(errs, "REDIS_ADDRS is required when REDIS_MODE=sentinel")
+		}
+		if c.Redis.MasterName == "" {
+			errs = append(errs, "REDIS_MASTER_NAME is required when REDIS_MODE=sentinel")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("REDIS_MODE must be one of standalone|cluster|sentinel, got %q", c.Redis.Mode))
+	}
+	// 非生产环境使用生产前缀会覆盖线上库存等键
+	if c.App.Env != "prod" && keys.IsProductionPrefix(c.Redis.KeyPrefix) {
+		errs = append(errs, fmt.Sprintf("REDIS_KEY_PREFIX %q is reserved for APP_ENV=prod, got APP_ENV=%s", c.Redis.KeyPrefix, c.App.Env))
+	}
+
+	return errs
+}
+
+func validateStorage(c *Config) []string {
+	var errs []string
+
+	if strings.TrimSpace(c.Storage.Dir) == "" {
+		errs = append(errs, "STORAGE_DIR cannot be empty")
+	}
+	if c.Storage.URLTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("STORAGE_URL_TTL must be > 0, got %s", c.Storage.URLTTL))
+	}
+
+	return errs
+}
+
+func validateSpike(c *Config) []string {
+	var errs []string
+
+	if c.Spike.OrderExpireTime <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_ORDER_EXPIRE_TIME must be > 0, got %s", c.Spike.OrderExpireTime))
+	}
+	if c.Spike.OrderExtension < 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_ORDER_EXTENSION must be >= 0, got %s", c.Spike.OrderExtension))
+	}
+	if c.Spike.GlobalRateLimit < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_GLOBAL_RATE_LIMIT must be >= 1, got %d", c.Spike.GlobalRateLimit))
+	}
+	if c.Spike.UserRateLimit < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_USER_RATE_LIMIT must be >= 1, got %d", c.Spike.UserRateLimit))
+	}
+	if c.Spike.UserRateLimit > c.Spike.GlobalRateLimit {
+		errs = append(errs, fmt.Sprintf("SPIKE_USER_RATE_LIMIT must be <= SPIKE_GLOBAL_RATE_LIMIT, got %d", c.Spike.UserRateLimit))
+	}
+	if c.Spike.RateLimitWindow <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_RATE_LIMIT_WINDOW must be > 0, got %s", c.Spike.RateLimitWindow))
+	}
+	if c.Spike.StockWarmupEnabled && c.Spike.StockWarmupTime <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_STOCK_WARMUP_TIME must be > 0 when SPIKE_STOCK_WARMUP_ENABLED=true, got %s", c.Spike.StockWarmupTime))
+	}
+	// 库存键在活动进行中过期会导致参与请求误判为未预热
+	if c.Spike.StockCacheTTL < c.Spike.StockWarmupTime {
+		errs = append(errs, fmt.Sprintf("SPIKE_STOCK_CACHE_TTL must be >= SPIKE_STOCK_WARMUP_TIME, got %s", c.Spike.StockCacheTTL))
+	}
+	if c.Spike.StockTTLBuffer < 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_STOCK_TTL_BUFFER must be >= 0, got %s", c.Spike.StockTTLBuffer))
+	}
+	if c.Spike.StockTTLRefresh <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_STOCK_TTL_REFRESH_INTERVAL must be > 0, got %s", c.Spike.StockTTLRefresh))
+	}
+	if c.Spike.UserMarkTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_USER_MARK_TTL must be > 0, got %s", c.Spike.UserMarkTTL))
+	}
+	// 幂等键先于订单过期会使支付前的重试重复下单
+	if c.Spike.IdempotencyTTL < c.Spike.OrderExpireTime+c.Spike.OrderExtension {
+		errs = append(errs, fmt.Sprintf("SPIKE_IDEMPOTENCY_TTL must cover SPIKE_ORDER_EXPIRE_TIME + SPIKE_ORDER_EXTENSION, got %s", c.Spike.IdempotencyTTL))
+	}
+	if c.Spike.MaxRetryAttempts < 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_MAX_RETRY_ATTEMPTS must be >= 0, got %d", c.Spike.MaxRetryAttempts))
+	}
+	if c.Spike.RetryInterval < 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_RETRY_INTERVAL must be >= 0, got %s", c.Spike.RetryInterval))
+	}
+	if c.Spike.PreviewScanInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_PREVIEW_SCAN_INTERVAL must be > 0, got %s", c.Spike.PreviewScanInterval))
+	}
+	if c.Spike.LifecycleInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_LIFECYCLE_INTERVAL must be > 0, got %s", c.Spike.LifecycleInterval))
+	}
+	if c.Spike.AnonymousRateLimit < 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_ANONYMOUS_RATE_LIMIT must be >= 0, got %d", c.Spike.AnonymousRateLimit))
+	}
+	if c.Spike.AnonymousRateLimit > 0 && c.Spike.PublicCacheTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_PUBLIC_CACHE_TTL must be > 0 when anonymous access is enabled, got %s", c.Spike.PublicCacheTTL))
+	}
+	if c.Spike.DashboardCacheTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_DASHBOARD_CACHE_TTL must be > 0, got %s", c.Spike.DashboardCacheTTL))
+	}
+
+	return errs
+}
+
+func validateSpikeToken(c *Config) []string {
+	var errs []string
+
+	if !c.SpikeToken.Enabled {
+		return errs
+	}
+	if strings.TrimSpace(c.SpikeToken.Secret) == "" {
+		errs = append(errs, "SPIKE_TOKEN_SECRET cannot be empty when SPIKE_TOKEN_ENABLED=true")
+	}
+	if c.SpikeToken.IssueMultiplier < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_TOKEN_ISSUE_MULTIPLIER must be >= 1, got %d", c.SpikeToken.IssueMultiplier))
+	}
+	if c.SpikeToken.IssueRate < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_TOKEN_ISSUE_RATE must be >= 1, got %d", c.SpikeToken.IssueRate))
+	}
+
+	return errs
+}
+
+func validateSpikeChallenge(c *Config) []string {
+	var errs []string
+
+	if c.SpikeChallenge.Difficulty < 1 || c.SpikeChallenge.Difficulty > 32 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CHALLENGE_DIFFICULTY must be between 1 and 32, got %d", c.SpikeChallenge.Difficulty))
+	}
+	if c.SpikeChallenge.TTL <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CHALLENGE_TTL must be > 0, got %s", c.SpikeChallenge.TTL))
+	}
+	if c.SpikeChallenge.IssueRate < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CHALLENGE_ISSUE_RATE must be >= 1, got %d", c.SpikeChallenge.IssueRate))
+	}
+
+	return errs
+}
+
+func validateSpikeWaitingRoom(c *Config) []string {
+	var errs []string
+
+	if c.SpikeWaitingRoom.DispatchInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_WAITING_ROOM_DISPATCH_INTERVAL must be > 0, got %s", c.SpikeWaitingRoom.DispatchInterval))
+	}
+
+	return errs
+}
+
+func validateSpikeDegraded(c *Config) []string {
+	var errs []string
+
+	switch c.SpikeDegraded.Mode {
+	case "off", "queue", "db":
+	default:
+		errs = append(errs, fmt.Sprintf("SPIKE_DEGRADED_MODE must be one of off, queue, db, got %q", c.SpikeDegraded.Mode))
+	}
+	if c.SpikeDegraded.Mode == "off" {
+		return errs
+	}
+	if c.SpikeDegraded.MaxConcurrency < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_DEGRADED_MAX_CONCURRENCY must be >= 1, got %d", c.SpikeDegraded.MaxConcurrency))
+	}
+	if c.SpikeDegraded.BreakerFailures < 1 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CACHE_BREAKER_FAILURES must be >= 1, got %d", c.SpikeDegraded.BreakerFailures))
+	}
+	if c.SpikeDegraded.BreakerOpenTimeout <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CACHE_BREAKER_OPEN_TIMEOUT must be > 0, got %s", c.SpikeDegraded.BreakerOpenTimeout))
+	}
+
+	return errs
+}
+
+func validateSpikeShare(c *Config) []string {
+	var errs []string
+
+	if strings.TrimSpace(c.SpikeShare.Secret) == "" {
+		errs = append(errs, "SPIKE_SHARE_SECRET cannot be empty")
+	}
+	if c.SpikeShare.LinkTTL < 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_SHARE_LINK_TTL must be >= 0, got %s", c.SpikeShare.LinkTTL))
+	}
+
+	return errs
+}
+
+func validatePayment(c *Config) []string {
+	var errs []string
+
+	if c.Payment.Provider != "sandbox" {
+		errs = append(errs, fmt.Sprintf("PAYMENT_PROVIDER must be sandbox, got %q", c.Payment.Provider))
+	}
+	switch {
+	case strings.TrimSpace(c.Payment.WebhookSecret) == "":
+		if c.App.Env == "prod" {
+			errs = append(errs, "PAYMENT_WEBHOOK_SECRET must be set in production")
+		}
+	case c.Payment.WebhookSecret == c.JWT.Secret:
+		errs = append(errs, "PAYMENT_WEBHOOK_SECRET must differ from JWT_SECRET")
+	}
+	if c.Payment.SandboxAuthorize && c.App.Env == "prod" {
+		errs = append(errs, "PAYMENT_SANDBOX_AUTHORIZE_ENABLED must not be enabled in production")
+	}
+	if strings.TrimSpace(c.Payment.Currency) == "" {
+		errs = append(errs, "PAYMENT_CURRENCY cannot be empty")
+	}
+
+	return errs
+}
+
+func validateJournal(c *Config) []string {
+	var errs []string
+
+	if c.Journal.Enabled && strings.TrimSpace(c.Journal.Dir) == "" {
+		errs = append(errs, "JOURNAL_DIR cannot be empty when JOURNAL_ENABLED=true")
+	}
+
+	return errs
+}
+
+func validateMQ(c *Config) []string {
+	var errs []string
+
+	switch c.MQ.Type {
+	case "rabbitmq":
+		if c.RabbitMQ.Host == "" {
+			errs = append(errs, "RABBITMQ_HOST is required when MQ_TYPE=rabbitmq")
+		}
+		if c.RabbitMQ.Port <= 0 || c.RabbitMQ.Port > 65535 {
+			errs = append(errs, fmt.Sprintf("RABBITMQ_AMQP_PORT must be between 1 and 65535, got %d", c.RabbitMQ.Port))
+		}
+		if c.RabbitMQ.User == "" {
+			errs = append(errs, "RABBITMQ_USER is required when MQ_TYPE=rabbitmq")
+		}
+		if !strings.HasPrefix(c.RabbitMQ.VHost, "/") {
+			errs = append(errs, fmt.Sprintf("RABBITMQ_VHOST must start with '/', got %q", c.RabbitMQ.VHost))
+		}
+		if c.RabbitMQ.ConnectTimeout <= 0 {
+			errs = append(errs, fmt.Sprintf("RABBITMQ_CONNECT_TIMEOUT must be > 0, got %s", c.RabbitMQ.ConnectTimeout))
+		}
+	case "redis":
+		if c.MQ.StreamMaxLen < 1 {
+			errs = append(errs, fmt.Sprintf("MQ_STREAM_MAXLEN must be >= 1, got %d", c.MQ.StreamMaxLen))
+		}
+		if c.MQ.StreamClaimIdle <= 0 {
+			errs = append(errs, fmt.Sprintf("MQ_STREAM_CLAIM_IDLE must be > 0, got %s", c.MQ.StreamClaimIdle))
+		}
+		if c.MQ.StreamMaxDeliveries < 1 {
+			errs = append(errs, fmt.Sprintf("MQ_STREAM_MAX_DELIVERIES must be >= 1, got %d", c.MQ.StreamMaxDeliveries))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("MQ_TYPE must be one of rabbitmq|redis, got %q", c.MQ.Type))
+	}
+
+	if c.MQ.PublishRetries < 0 {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_RETRIES must be >= 0, got %d", c.MQ.PublishRetries))
+	}
+	if c.MQ.PublishBackoff <= 0 {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_BACKOFF must be > 0, got %s", c.MQ.PublishBackoff))
+	}
+	if c.MQ.PublishMaxBackoff < c.MQ.PublishBackoff {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_MAX_BACKOFF must be >= MQ_PUBLISH_BACKOFF, got %s", c.MQ.PublishMaxBackoff))
+	}
+	if c.MQ.PublishBufferSize < 0 {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_BUFFER_SIZE must be >= 0, got %d", c.MQ.PublishBufferSize))
+	}
+	if c.MQ.PublishReplayInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_REPLAY_INTERVAL must be > 0, got %s", c.MQ.PublishReplayInterval))
+	}
+	if c.MQ.PublishBreakerFailures < 1 {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_BREAKER_FAILURES must be >= 1, got %d", c.MQ.PublishBreakerFailures))
+	}
+	if c.MQ.PublishBreakerOpenTimeout <= 0 {
+		errs = append(errs, fmt.Sprintf("MQ_PUBLISH_BREAKER_OPEN_TIMEOUT must be > 0, got %s", c.MQ.PublishBreakerOpenTimeout))
+	}
+	if c.MQ.OrderBatchSize < 1 || c.MQ.OrderBatchSize > 500 {
+		errs = append(errs, fmt.Sprintf("MQ_ORDER_BATCH_SIZE must be between 1 and 500, got %d", c.MQ.OrderBatchSize))
+	}
+	if c.MQ.OrderBatchSize > 1 && (c.MQ.OrderBatchWait <= 0 || c.MQ.OrderBatchWait > time.Second) {
+		errs = append(errs, fmt.Sprintf("MQ_ORDER_BATCH_WAIT must be > 0 and <= 1s when batching, got %s", c.MQ.OrderBatchWait))
+	}
 
 	return errs
 }
 
-func getEnv(key, def string) string {
-	if v, ok := os.LookupEnv(key); ok && strings.TrimSpace(v) != "" {
+func validateNotify(c *Config) []string {
+	var errs []string
+
+	if c.Notify.SMTPHost != "" {
+		if c.Notify.SMTPPort < 1 || c.Notify.SMTPPort > 65535 {
+			errs = append(errs, fmt.Sprintf("NOTIFY_SMTP_PORT must be between 1 and 65535, got %d", c.Notify.SMTPPort))
+		}
+		if c.Notify.SMTPFrom == "" {
+			errs = append(errs, "NOTIFY_SMTP_FROM is required when NOTIFY_SMTP_HOST is set")
+		}
+	}
+	for _, webhook := range []struct{ name, url string }{
+		{"NOTIFY_SMS_WEBHOOK_URL", c.Notify.SMSWebhookURL},
+		{"NOTIFY_PUSH_WEBHOOK_URL", c.Notify.PushWebhookURL},
+	} {
+		if webhook.url == "" {
+			continue
+		}
+		if u, err := url.Parse(webhook.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("%s must be an http(s) URL, got %q", webhook.name, webhook.url))
+		}
+	}
+	if c.Notify.Timeout <= 0 {
+		errs = append(errs, fmt.Sprintf("NOTIFY_TIMEOUT must be > 0, got %s", c.Notify.Timeout))
+	}
+	if c.Notify.EmailRetries < 0 || c.Notify.SMSRetries < 0 || c.Notify.PushRetries < 0 {
+		errs = append(errs, fmt.Sprintf("NOTIFY_EMAIL_RETRIES, NOTIFY_SMS_RETRIES and NOTIFY_PUSH_RETRIES must be >= 0, got %d, %d, %d",
+			c.Notify.EmailRetries, c.Notify.SMSRetries, c.Notify.PushRetries))
+	}
+	if c.Notify.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Sprintf("NOTIFY_RETRY_BACKOFF must be > 0, got %s", c.Notify.RetryBackoff))
+	}
+
+	return errs
+}
+
+func validatePaymentReminder(c *Config) []string {
+	var errs []string
+
+	if !c.PaymentReminder.Enabled {
+		return errs
+	}
+	if len(c.PaymentReminder.Offsets) == 0 {
+		errs = append(errs, "PAYMENT_REMINDER_OFFSETS cannot be empty when PAYMENT_REMINDER_ENABLED=true")
+	}
+	for _, offset := range c.PaymentReminder.Offsets {
+		if offset <= 0 {
+			errs = append(errs, fmt.Sprintf("PAYMENT_REMINDER_OFFSETS entries must be > 0, got %s", offset))
+		}
+	}
+	if c.PaymentReminder.ScanInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("PAYMENT_REMINDER_INTERVAL must be > 0, got %s", c.PaymentReminder.ScanInterval))
+	}
+
+	return errs
+}
+
+func validateOrderExpiry(c *Config) []string {
+	var errs []string
+
+	if !c.OrderExpiry.Enabled {
+		return errs
+	}
+	if c.OrderExpiry.ScanInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("ORDER_EXPIRY_INTERVAL must be > 0, got %s", c.OrderExpiry.ScanInterval))
+	}
+	if c.OrderExpiry.BatchSize <= 0 {
+		errs = append(errs, fmt.Sprintf("ORDER_EXPIRY_BATCH must be > 0, got %d", c.OrderExpiry.BatchSize))
+	}
+
+	return errs
+}
+
+func validateOrder(c *Config) []string {
+	var errs []string
+
+	if c.Order.PaymentTimeout <= 0 {
+		errs = append(errs, fmt.Sprintf("ORDER_PAYMENT_TIMEOUT must be > 0, got %s", c.Order.PaymentTimeout))
+	}
+	if c.Order.CartMaxItems <= 0 || c.Order.CartMaxItems > 1000 {
+		errs = append(errs, fmt.Sprintf("ORDER_CART_MAX_ITEMS must be between 1 and 1000, got %d", c.Order.CartMaxItems))
+	}
+
+	return errs
+}
+
+func validateOutbox(c *Config) []string {
+	var errs []string
+
+	if !c.Outbox.Enabled {
+		return errs
+	}
+	if c.Outbox.Interval <= 0 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_RELAY_INTERVAL must be > 0, got %s", c.Outbox.Interval))
+	}
+	if c.Outbox.BatchSize <= 0 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_BATCH must be > 0, got %d", c.Outbox.BatchSize))
+	}
+	if c.Outbox.MaxAttempts <= 0 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_MAX_ATTEMPTS must be > 0, got %d", c.Outbox.MaxAttempts))
+	}
+	if c.Outbox.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_RETRY_BACKOFF must be > 0, got %s", c.Outbox.RetryBackoff))
+	}
+	if c.Outbox.Retention < 0 {
+		errs = append(errs, fmt.Sprintf("OUTBOX_RETENTION must be >= 0, got %s", c.Outbox.Retention))
+	}
+
+	return errs
+}
+
+func validateSettlement(c *Config) []string {
+	var errs []string
+
+	if c.Settlement.FeeRate < 0 || c.Settlement.FeeRate >= 1 {
+		errs = append(errs, fmt.Sprintf("SETTLEMENT_FEE_RATE must be in [0, 1), got %g", c.Settlement.FeeRate))
+	}
+	if !c.Settlement.Enabled {
+		return errs
+	}
+	if c.Settlement.Interval <= 0 {
+		errs = append(errs, fmt.Sprintf("SETTLEMENT_INTERVAL must be > 0, got %s", c.Settlement.Interval))
+	}
+	// 待支付订单在活动结束后仍可能完成支付，过早结算会在后续轮次中被改写
+	if c.Settlement.Delay < c.Spike.OrderExpireTime+c.Spike.OrderExtension {
+		errs = append(errs, fmt.Sprintf("SETTLEMENT_DELAY must cover SPIKE_ORDER_EXPIRE_TIME + SPIKE_ORDER_EXTENSION, got %s", c.Settlement.Delay))
+	}
+	if c.Settlement.Lookback <= 0 {
+		errs = append(errs, fmt.Sprintf("SETTLEMENT_LOOKBACK must be > 0, got %s", c.Settlement.Lookback))
+	}
+
+	return errs
+}
+
+func validateAdminJobs(c *Config) []string {
+	var errs []string
+
+	if c.AdminJobs.Workers <= 0 {
+		errs = append(errs, fmt.Sprintf("ADMIN_JOB_WORKERS must be > 0, got %d", c.AdminJobs.Workers))
+	}
+	if c.AdminJobs.QueueSize <= 0 {
+		errs = append(errs, fmt.Sprintf("ADMIN_JOB_QUEUE_SIZE must be > 0, got %d", c.AdminJobs.QueueSize))
+	}
+	if c.AdminJobs.Retain < 0 {
+		errs = append(errs, fmt.Sprintf("ADMIN_JOB_RETAIN must be >= 0, got %d", c.AdminJobs.Retain))
+	}
+	if c.AdminJobs.Timeout <= 0 {
+		errs = append(errs, fmt.Sprintf("ADMIN_JOB_TIMEOUT must be > 0, got %s", c.AdminJobs.Timeout))
+	}
+
+	return errs
+}
+
+func validateCleanup(c *Config) []string {
+	var errs []string
+
+	if !c.Cleanup.Enabled {
+		return errs
+	}
+	if c.Cleanup.Interval <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CLEANUP_INTERVAL must be > 0, got %s", c.Cleanup.Interval))
+	}
+	// 待支付订单过期时会归还库存，过早清理会让归还重建已删除的库存键
+	if c.Cleanup.GracePeriod < c.Spike.OrderExpireTime+c.Spike.OrderExtension {
+		errs = append(errs, fmt.Sprintf("SPIKE_CLEANUP_GRACE_PERIOD must cover SPIKE_ORDER_EXPIRE_TIME + SPIKE_ORDER_EXTENSION, got %s", c.Cleanup.GracePeriod))
+	}
+	if c.Cleanup.Lookback <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_CLEANUP_LOOKBACK must be > 0, got %s", c.Cleanup.Lookback))
+	}
+
+	return errs
+}
+
+func validateArchive(c *Config) []string {
+	var errs []string
+
+	if !c.Archive.Enabled {
+		return errs
+	}
+	if c.Archive.Interval <= 0 {
+		errs = append(errs, fmt.Sprintf("SPIKE_ARCHIVE_INTERVAL must be > 0, got %s", c.Archive.Interval))
+	}
+	// 归档只处理终态订单，保留期还应覆盖对账与售后查询所需的时间，这里只保证不早于订单可能变化的期限
+	if c.Archive.After < c.Spike.OrderExpireTime+c.Spike.OrderExtension {
+		errs = append(errs, fmt.Sprintf("SPIKE_ARCHIVE_AFTER must cover SPIKE_ORDER_EXPIRE_TIME + SPIKE_ORDER_EXTENSION, got %s", c.Archive.After))
+	}
+	if c.Archive.BatchSize <= 0 || c.Archive.BatchSize > 10000 {
+		errs = append(errs, fmt.Sprintf("SPIKE_ARCHIVE_BATCH_SIZE must be between 1 and 10000, got %d", c.Archive.BatchSize))
+	}
+
+	return errs
+}
+
+func validateInventoryReservation(c *Config) []string {
+	var errs []string
+
+	if c.InventoryReservation.TTL <= 0 {
+		errs = append(errs, fmt.Sprintf("INVENTORY_RESERVATION_TTL must be > 0, got %s", c.InventoryReservation.TTL))
+	}
+	if c.InventoryReservation.MaxTTL < c.InventoryReservation.TTL {
+		errs = append(errs, fmt.Sprintf("INVENTORY_RESERVATION_MAX_TTL must be >= INVENTORY_RESERVATION_TTL, got %s", c.InventoryReservation.MaxTTL))
+	}
+	if c.InventoryReservation.ReleaseInterval <= 0 {
+		errs = append(errs, fmt.Sprintf("INVENTORY_RESERVATION_RELEASE_INTERVAL must be > 0, got %s", c.InventoryReservation.ReleaseInterval))
+	}
+	if c.InventoryReservation.ReleaseBatchSize <= 0 {
+		errs = append(errs, fmt.Sprintf("INVENTORY_RESERVATION_RELEASE_BATCH must be > 0, got %d", c.InventoryReservation.ReleaseBatchSize))
+	}
+
+	return errs
+}
+
+func validateInventoryConflict(c *Config) []string {
+	var errs []string
+
+	if c.InventoryConflict.MaxRetries < 0 || c.InventoryConflict.MaxRetries > 10 {
+		errs = append(errs, fmt.Sprintf("INVENTORY_CONFLICT_RETRIES must be between 0 and 10, got %d", c.InventoryConflict.MaxRetries))
+	}
+	if c.InventoryConflict.MaxRetries > 0 {
+		if c.InventoryConflict.Backoff <= 0 {
+			errs = append(errs, fmt.Sprintf("INVENTORY_CONFLICT_BACKOFF must be > 0, got %s", c.InventoryConflict.Backoff))
+		}
+		if c.InventoryConflict.MaxBackoff < c.InventoryConflict.Backoff {
+			errs = append(errs, fmt.Sprintf("INVENTORY_CONFLICT_MAX_BACKOFF must be >= INVENTORY_CONFLICT_BACKOFF, got %s", c.InventoryConflict.MaxBackoff))
+		}
+	}
+
+	return errs
+}
+
+func validateStockInvariant(c *Config) []string {
+	var errs []string
+
+	if c.StockInvariant.Interval <= 0 {
+		errs = append(errs, fmt.Sprintf("STOCK_INVARIANT_INTERVAL must be > 0, got %s", c.StockInvariant.Interval))
+	}
+	if c.StockInvariant.FreezeOnViolation && c.StockInvariant.FreezeTTL <= 0 {
+		errs = append(errs, fmt.Sprintf("STOCK_INVARIANT_FREEZE_TTL must be > 0 when STOCK_INVARIANT_FREEZE=true, got %s", c.StockInvariant.FreezeTTL))
+	}
+
+	return errs
+}
+
+func validateStockReconcile(c *Config) []string {
+	var errs []string
+
+	// 管理员接口不依赖定时任务，因此无论是否启用都校验容差与等待时间
+	if c.StockReconcile.Enabled && c.StockReconcile.Interval <= 0 {
+		errs = append(errs, fmt.Sprintf("STOCK_RECONCILE_INTERVAL must be > 0 when STOCK_RECONCILE_ENABLED=true, got %s", c.StockReconcile.Interval))
+	}
+	if c.StockReconcile.Tolerance < 0 {
+		errs = append(errs, fmt.Sprintf("STOCK_RECONCILE_TOLERANCE must be >= 0, got %d", c.StockReconcile.Tolerance))
+	}
+	if c.StockReconcile.GracePeriod < 0 {
+		errs = append(errs, fmt.Sprintf("STOCK_RECONCILE_GRACE_PERIOD must be >= 0, got %s", c.StockReconcile.GracePeriod))
+	}
+
+	return errs
+}
+
+func validateDebugCapture(c *Config) []string {
+	var errs []string
+
+	// 运行时可由管理员开启，因此无论是否启用都校验容量参数
+	if c.DebugCapture.SampleRate <= 0 || c.DebugCapture.SampleRate > 1 {
+		errs = append(errs, fmt.Sprintf("DEBUG_CAPTURE_SAMPLE_RATE must be in (0, 1], got %v", c.DebugCapture.SampleRate))
+	}
+	if c.DebugCapture.MaxBodyBytes <= 0 {
+		errs = append(errs, fmt.Sprintf("DEBUG_CAPTURE_MAX_BODY_BYTES must be > 0, got %d", c.DebugCapture.MaxBodyBytes))
+	}
+	if c.DebugCapture.BufferSize <= 0 {
+		errs = append(errs, fmt.Sprintf("DEBUG_CAPTURE_BUFFER_SIZE must be > 0, got %d", c.DebugCapture.BufferSize))
+	}
+
+	return errs
+}
+
+func validateAdminAudit(c *Config) []string {
+	var errs []string
+
+	if c.AdminAudit.Enabled && c.AdminAudit.MaxPayloadBytes <= 0 {
+		errs = append(errs, fmt.Sprintf("ADMIN_AUDIT_MAX_PAYLOAD_BYTES must be > 0, got %d", c.AdminAudit.MaxPayloadBytes))
+	}
+
+	return errs
+}
+
+// parseRouteValues 解析形如 route=value 的按路由配置项
+func parseRouteValues[T any](entries []string, parse func(string) (T, error)) (map[string]T, error) {
+	values := make(map[string]T, len(entries))
+	for _, entry := range entries {
+		route, raw, ok := strings.Cut(entry, "=")
+		route, raw = strings.TrimSpace(route), strings.TrimSpace(raw)
+		if !ok || !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("entry %q must be /route=value", entry)
+		}
+		v, err := parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", entry, err)
+		}
+		values[route] = v
+	}
+	return values, nil
+}
+
+func (l *layers) getEnv(key, def string) string {
+	if v, ok := l.lookup(key); ok && strings.TrimSpace(v) != "" {
 		return v
 	}
 	return def
 }
 
-func getEnvAsInt(key string, def int) int {
-	if v, ok := os.LookupEnv(key); ok {
+func (l *layers) getEnvAsInt(key string, def int) int {
+	if v, ok := l.lookup(key); ok {
 		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
 			return i
 		}
@@ -229,13 +1553,13 @@ func getEnvAsInt(key string, def int) int {
 	return def
 }
 
-func getEnvAsDurationMs(key string, defMs int) time.Duration {
-	ms := getEnvAsInt(key, defMs)
+func (l *layers) getEnvAsDurationMs(key string, defMs int) time.Duration {
+	ms := l.getEnvAsInt(key, defMs)
 	return time.Duration(ms) * time.Millisecond
 }
 
-func getEnvAsDuration(key, def string) time.Duration {
-	v := getEnv(key, def)
+func (l *layers) getEnvAsDuration(key, def string) time.Duration {
+	v := l.getEnv(key, def)
 	if d, err := time.ParseDuration(v); err == nil {
 		return d
 	}
@@ -245,8 +1569,8 @@ func getEnvAsDuration(key, def string) time.Duration {
 	return 15 * time.Minute // fallback
 }
 
-func getEnvAsCSV(key string, def []string) []string {
-	v, ok := os.LookupEnv(key)
+func (l *layers) getEnvAsCSV(key string, def []string) []string {
+	v, ok := l.lookup(key)
 	if !ok || strings.TrimSpace(v) == "" {
 		return def
 	}
@@ -265,8 +1589,37 @@ func getEnvAsCSV(key string, def []string) []string {
 	return out
 }
 
-func getEnvAsBool(key string, def bool) bool {
-	v, ok := os.LookupEnv(key)
+// getEnvAsDurationCSV 解析逗号分隔的时长列表，任一项无法解析时使用默认值
+func (l *layers) getEnvAsDurationCSV(key string, def []time.Duration) []time.Duration {
+	parts := l.getEnvAsCSV(key, nil)
+	if len(parts) == 0 {
+		return def
+	}
+	out := make([]time.Duration, 0, len(parts))
+	for _, p := range parts {
+		d, err := time.ParseDuration(p)
+		if err != nil {
+			return def
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func (l *layers) getEnvAsFloat(key string, def float64) float64 {
+	v, ok := l.lookup(key)
+	if !ok || strings.TrimSpace(v) == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func (l *layers) getEnvAsBool(key string, def bool) bool {
+	v, ok := l.lookup(key)
 	if !ok || strings.TrimSpace(v) == "" {
 		return def
 	}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func withEnv(key, value string, fn func()) {
@@ -46,3 +48,400 @@ func TestLoad_InvalidPort_ShouldError(t *testing.T) {
 		}
 	})
 }
+
+func TestLoad_InvalidWorkerID_ShouldError(t *testing.T) {
+	withEnv("APP_WORKER_ID", "1024", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for out-of-range APP_WORKER_ID")
+		}
+	})
+}
+
+func TestLoad_NegativeStockReconcileTolerance_ShouldError(t *testing.T) {
+	withEnv("STOCK_RECONCILE_TOLERANCE", "-1", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for negative STOCK_RECONCILE_TOLERANCE")
+		}
+	})
+}
+
+func TestLoad_NegativeQueryTimeout_ShouldError(t *testing.T) {
+	withEnv("MYSQL_QUERY_TIMEOUT", "-1s", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for negative MYSQL_QUERY_TIMEOUT")
+		}
+	})
+}
+
+func TestLoad_InvalidMQType_ShouldError(t *testing.T) {
+	withEnv("MQ_TYPE", "kafka", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for invalid MQ_TYPE")
+		}
+	})
+}
+
+func TestLoad_InvalidRabbitMQVHost_ShouldError(t *testing.T) {
+	withEnv("RABBITMQ_VHOST", "spike", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for RABBITMQ_VHOST without leading slash")
+		}
+	})
+}
+
+func TestLoad_InvalidOrderBatch_ShouldError(t *testing.T) {
+	withEnv("MQ_ORDER_BATCH_SIZE", "0", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for MQ_ORDER_BATCH_SIZE below 1")
+		}
+	})
+	withEnv("MQ_ORDER_BATCH_SIZE", "50", func() {
+		withEnv("MQ_ORDER_BATCH_WAIT", "0s", func() {
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for MQ_ORDER_BATCH_WAIT of 0 when batching")
+			}
+		})
+	})
+}
+
+func TestLoad_InvalidNotify_ShouldError(t *testing.T) {
+	withEnv("NOTIFY_SMTP_HOST", "smtp.example.com", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for NOTIFY_SMTP_HOST without NOTIFY_SMTP_FROM")
+		}
+	})
+	withEnv("NOTIFY_SMS_WEBHOOK_URL", "sms.example.com/send", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for NOTIFY_SMS_WEBHOOK_URL without scheme")
+		}
+	})
+}
+
+func TestLoad_InvalidInventoryConflict_ShouldError(t *testing.T) {
+	withEnv("INVENTORY_CONFLICT_RETRIES", "-1", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for negative INVENTORY_CONFLICT_RETRIES")
+		}
+	})
+	withEnv("INVENTORY_CONFLICT_MAX_BACKOFF", "1ms", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for INVENTORY_CONFLICT_MAX_BACKOFF below INVENTORY_CONFLICT_BACKOFF")
+		}
+	})
+}
+
+func TestLoad_PaymentReminderOffsets(t *testing.T) {
+	withEnv("PAYMENT_REMINDER_OFFSETS", "15m, 5m", func() {
+		c, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []time.Duration{15 * time.Minute, 5 * time.Minute}
+		if len(c.PaymentReminder.Offsets) != len(want) || c.PaymentReminder.Offsets[0] != want[0] || c.PaymentReminder.Offsets[1] != want[1] {
+			t.Fatalf("offsets = %v, want %v", c.PaymentReminder.Offsets, want)
+		}
+	})
+}
+
+func TestLoad_CORSCredentialsWithWildcard_ShouldError(t *testing.T) {
+	withEnv("CORS_ALLOWED_ORIGINS", "*", func() {
+		withEnv("CORS_ALLOW_CREDENTIALS", "true", func() {
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for credentials with wildcard origin")
+			}
+		})
+	})
+}
+
+func TestLoad_RouteRequestLimits(t *testing.T) {
+	withEnv("HTTP_ROUTE_TIMEOUTS", "/api/v1/spike/participate=2s, /api/v1/admin/spike/events/import=0s", func() {
+		c, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.HTTP.RouteTimeouts["/api/v1/spike/participate"] != 2*time.Second || len(c.HTTP.RouteTimeouts) != 2 {
+			t.Fatalf("route timeouts = %v, want participate 2s and import unlimited", c.HTTP.RouteTimeouts)
+		}
+	})
+	for _, v := range []string{"participate=2s", "/api/v1/spike/participate=fast"} {
+		withEnv("HTTP_ROUTE_TIMEOUTS", v, func() {
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for HTTP_ROUTE_TIMEOUTS=%q", v)
+			}
+		})
+	}
+	withEnv("HTTP_ROUTE_MAX_BODY_BYTES", "/api/v1/spike/participate=-1", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for negative route body limit")
+		}
+	})
+}
+
+func TestLoad_LoginLockout(t *testing.T) {
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Login.MaxFailures != 5 || c.Login.LockoutBase != time.Minute || c.Login.TOTPIssuer != c.App.Name {
+		t.Fatalf("login = %+v, want default lockout and issuer %s", c.Login, c.App.Name)
+	}
+	withEnv("LOGIN_LOCKOUT_MAX", "30s", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for LOGIN_LOCKOUT_MAX below LOGIN_LOCKOUT_BASE")
+		}
+	})
+	withEnv("LOGIN_MAX_FAILURES", "0", func() {
+		withEnv("LOGIN_IP_MAX_FAILURES", "0", func() {
+			withEnv("LOGIN_LOCKOUT_BASE", "0s", func() {
+				if _, err := Load(); err != nil {
+					t.Fatalf("lockout disabled should not validate durations: %v", err)
+				}
+			})
+		})
+	})
+}
+
+func TestLoad_TrustedProxiesAndTOTPKey(t *testing.T) {
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.HTTP.TrustedProxies) != 0 || len(c.Login.TOTPEncryptionKey) != 0 {
+		t.Fatalf("trusted proxies = %v, totp key = %d bytes, want none by default", c.HTTP.TrustedProxies, len(c.Login.TOTPEncryptionKey))
+	}
+	withEnv("HTTP_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10", func() {
+		c, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(c.HTTP.TrustedProxies) != 2 {
+			t.Fatalf("trusted proxies = %v, want 2 entries", c.HTTP.TrustedProxies)
+		}
+	})
+	withEnv("HTTP_TRUSTED_PROXIES", "proxy.internal", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for non-IP trusted proxy")
+		}
+	})
+	withEnv("TOTP_ENCRYPTION_KEY", "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=", func() {
+		c, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(c.Login.TOTPEncryptionKey) != 32 {
+			t.Fatalf("totp key = %d bytes, want 32", len(c.Login.TOTPEncryptionKey))
+		}
+	})
+	for _, v := range []string{"not base64!", "c2hvcnQ="} {
+		withEnv("TOTP_ENCRYPTION_KEY", v, func() {
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for TOTP_ENCRYPTION_KEY=%q", v)
+			}
+		})
+	}
+}
+
+func TestLoad_SpikeOverrides(t *testing.T) {
+	withEnv("SPIKE_ORDER_EXPIRE_TIME", "15m", func() {
+		withEnv("SPIKE_USER_RATE_LIMIT", "3", func() {
+			c, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Spike.OrderExpireTime != 15*time.Minute || c.Spike.UserRateLimit != 3 {
+				t.Fatalf("spike = %+v, want order expire 15m and user rate limit 3", c.Spike)
+			}
+		})
+	})
+}
+
+func TestLoad_InvalidSpike_ShouldError(t *testing.T) {
+	withEnv("SPIKE_USER_RATE_LIMIT", "2000", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for user rate limit above global rate limit")
+		}
+	})
+	withEnv("SPIKE_IDEMPOTENCY_TTL", "10m", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for idempotency TTL shorter than order expire time")
+		}
+	})
+}
+
+func TestLoad_InvalidSpikeChallenge_ShouldError(t *testing.T) {
+	withEnv("SPIKE_CHALLENGE_DIFFICULTY", "40", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for challenge difficulty above 32")
+		}
+	})
+	withEnv("SPIKE_CHALLENGE_TTL", "0s", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for non-positive challenge TTL")
+		}
+	})
+}
+
+func TestLoad_InvalidSpikeDegraded_ShouldError(t *testing.T) {
+	withEnv("SPIKE_DEGRADED_MODE", "fallback", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for unknown degraded mode")
+		}
+	})
+	withEnv("SPIKE_DEGRADED_MAX_CONCURRENCY", "0", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for non-positive degraded concurrency")
+		}
+	})
+}
+
+func TestLoad_RedisKeyPrefix(t *testing.T) {
+	withEnv("REDIS_KEY_PREFIX", "prod", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for production key prefix outside APP_ENV=prod")
+		}
+	})
+	withEnv("REDIS_KEY_PREFIX", "staging", func() {
+		withEnv("REDIS_SPIKE_DB", "2", func() {
+			c, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Redis.KeyPrefix != "staging" || c.Redis.SpikeDB != 2 || c.Redis.CacheDB != c.Redis.DB {
+				t.Fatalf("redis = %+v, want prefix staging, spike db 2 and cache db defaulting to REDIS_DB", c.Redis)
+			}
+		})
+	})
+	withEnv("REDIS_LIMITER_DB", "16", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for out-of-range limiter DB")
+		}
+	})
+}
+
+func TestLoad_RedisMode(t *testing.T) {
+	withEnv("REDIS_MODE", "Cluster", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for cluster mode without REDIS_ADDRS")
+		}
+		withEnv("REDIS_ADDRS", "redis-1:6379, redis-2:6379", func() {
+			c, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Redis.Mode != "cluster" || len(c.Redis.Addrs) != 2 || c.Redis.Addrs[1] != "redis-2:6379" {
+				t.Fatalf("redis = %+v, want cluster with 2 addrs", c.Redis)
+			}
+			withEnv("REDIS_SPIKE_DB", "2", func() {
+				if _, err := Load(); err == nil {
+					t.Fatalf("expected error for non-zero DB in cluster mode")
+				}
+			})
+		})
+	})
+	withEnv("REDIS_MODE", "sentinel", func() {
+		withEnv("REDIS_ADDRS", "sentinel-1:26379", func() {
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for sentinel mode without REDIS_MASTER_NAME")
+			}
+			withEnv("REDIS_MASTER_NAME", "mymaster", func() {
+				if _, err := Load(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			})
+		})
+	})
+	withEnv("REDIS_MODE", "replica", func() {
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected error for unknown redis mode")
+		}
+	})
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestLoadWithOptions_Layers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, filepath.Join(dir, ".env"), "APP_ENV=staging\nSPIKE_USER_RATE_LIMIT=3\nSPIKE_GLOBAL_RATE_LIMIT=300\nAPP_PORT=8081\n")
+	writeConfigFile(t, filepath.Join(dir, ".env.staging"), "SPIKE_USER_RATE_LIMIT=7\nSPIKE_GLOBAL_RATE_LIMIT=700\n")
+	extra := filepath.Join(dir, "extra.env")
+	writeConfigFile(t, extra, "SPIKE_GLOBAL_RATE_LIMIT=900\n")
+
+	c, err := LoadWithOptions(Options{Dir: dir, Files: []string{extra}, Overrides: map[string]string{"APP_PORT": "9090"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 命令行 > 额外配置文件 > .env.<profile> > .env
+	if c.App.Env != "staging" || c.App.Port != 9090 || c.Spike.GlobalRateLimit != 900 || c.Spike.UserRateLimit != 7 {
+		t.Fatalf("env=%s port=%d global=%d user=%d, want staging/9090/900/7",
+			c.App.Env, c.App.Port, c.Spike.GlobalRateLimit, c.Spike.UserRateLimit)
+	}
+	withEnv("SPIKE_USER_RATE_LIMIT", "4", func() {
+		c, err := LoadWithOptions(Options{Dir: dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Spike.UserRateLimit != 4 {
+			t.Fatalf("user rate limit = %d, want environment value 4", c.Spike.UserRateLimit)
+		}
+	})
+	if _, err := LoadWithOptions(Options{Dir: dir, Files: []string{filepath.Join(dir, "missing.env")}}); err == nil {
+		t.Fatalf("expected error for missing explicit config file")
+	}
+}
+
+func TestWatcher_ReloadAppliesTunablesOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	writeConfigFile(t, path, "SPIKE_USER_RATE_LIMIT=5\nAPP_PORT=8081\n")
+	opts := Options{Dir: dir}
+	initial, err := LoadWithOptions(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := NewWatcher(opts, initial, nil)
+	var notified *Config
+	w.Subscribe(func(c *Config) { notified = c })
+
+	writeConfigFile(t, path, "SPIKE_USER_RATE_LIMIT=8\nAPP_PORT=9090\n")
+	if changed, err := w.Reload(); err != nil || !changed {
+		t.Fatalf("Reload() = %v, %v, want changed", changed, err)
+	}
+	if notified == nil || notified.Spike.UserRateLimit != 8 {
+		t.Fatalf("subscriber got %+v, want user rate limit 8", notified)
+	}
+	if got := w.Current(); got.Spike.UserRateLimit != 8 || got.App.Port != 8081 {
+		t.Errorf("current user=%d port=%d, want 8 and unchanged port 8081", got.Spike.UserRateLimit, got.App.Port)
+	}
+
+	// 校验失败时保留原配置
+	writeConfigFile(t, path, "SPIKE_USER_RATE_LIMIT=0\n")
+	if _, err := w.Reload(); err == nil {
+		t.Fatalf("expected error for invalid reloaded config")
+	}
+	if got := w.Current().Spike.UserRateLimit; got != 8 {
+		t.Errorf("user rate limit after failed reload = %d, want 8", got)
+	}
+}
+
+func TestLoad_PaymentWebhookSecret(t *testing.T) {
+	withEnv("JWT_SECRET", "shared-secret", func() {
+		withEnv("PAYMENT_WEBHOOK_SECRET", "shared-secret", func() {
+			if _, err := Load(); err == nil {
+				t.Fatal("expected error when PAYMENT_WEBHOOK_SECRET equals JWT_SECRET")
+			}
+		})
+	})
+	_ = os.Unsetenv("PAYMENT_WEBHOOK_SECRET")
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Payment.WebhookSecret != "" || c.Payment.SandboxAuthorize {
+		t.Fatalf("payment defaults = %+v, want empty secret and sandbox authorize disabled", c.Payment)
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Options 配置来源选项。优先级从高到低：Overrides（命令行）> 环境变量 > Files > .env.<profile> > .env > 默认值
+type Options struct {
+	Files     []string          // 额外的 dotenv 格式配置文件，按顺序加载，先出现的优先；文件不存在时报错
+	Profile   string            // 环境 profile（dev|staging|test|prod），等价于设置 APP_ENV，优先级同命令行覆盖
+	Overrides map[string]string // 命令行覆盖的键值
+	Dir       string            // .env 与 .env.<profile> 所在目录，为空时为当前目录
+}
+
+// RegisterFlags 在 fs 上注册 -config、-profile 与 -set 参数，解析后返回的 Options 可直接传给 LoadWithOptions
+func RegisterFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{}
+	fs.Var(filesFlag{opts}, "config", "Additional dotenv config file, repeatable; earlier files take precedence")
+	fs.StringVar(&opts.Profile, "profile", "", "Environment profile (dev|staging|test|prod), loads .env.<profile> and sets APP_ENV")
+	fs.Var(overridesFlag{opts}, "set", "Override a config key as KEY=VALUE, repeatable; takes precedence over environment variables")
+	return opts
+}
+
+// filesFlag 可重复的 -config 参数
+type filesFlag struct{ opts *Options }
+
+func (f filesFlag) String() string {
+	if f.opts == nil {
+		return ""
+	}
+	return strings.Join(f.opts.Files, ",")
+}
+
+func (f filesFlag) Set(path string) error {
+	f.opts.Files = append(f.opts.Files, path)
+	return nil
+}
+
+// overridesFlag 可重复的 -set KEY=VALUE 参数
+type overridesFlag struct{ opts *Options }
+
+func (f overridesFlag) String() string {
+	if f.opts == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(f.opts.Overrides))
+	for key, value := range f.opts.Overrides {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f overridesFlag) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", pair)
+	}
+	if f.opts.Overrides == nil {
+		f.opts.Overrides = make(map[string]string)
+	}
+	f.opts.Overrides[key] = value
+	return nil
+}
+
+// layers 合并后的配置来源
+type layers struct {
+	overrides map[string]string
+	files     map[string]string // 各配置文件合并后的键值，高优先级文件的键不会被覆盖
+	paths     []string          // 参与合并的配置文件（含不存在的可选文件），热更新时检测其变化
+}
+
+// newLayers 读取配置文件并确定 profile：profile 取自 APP_ENV（命令行 > 环境变量 > 额外配置文件 > .env），缺省为 dev
+func newLayers(opts Options) (*layers, error) {
+	l := &layers{overrides: maps.Clone(opts.Overrides), files: make(map[string]string)}
+	if opts.Profile != "" {
+		if l.overrides == nil {
+			l.overrides = make(map[string]string)
+		}
+		l.overrides["APP_ENV"] = opts.Profile
+	}
+
+	for _, path := range opts.Files {
+		if err := l.merge(path, true); err != nil {
+			return nil, err
+		}
+	}
+
+	base := filepath.Join(opts.Dir, ".env")
+	baseValues, err := readEnvFile(base, false)
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := l.lookup("APP_ENV")
+	if !ok || strings.TrimSpace(profile) == "" {
+		profile = baseValues["APP_ENV"]
+	}
+	if strings.TrimSpace(profile) == "" {
+		profile = "dev"
+	}
+	if err := l.merge(filepath.Join(opts.Dir, ".env."+filepath.Base(profile)), false); err != nil {
+		return nil, err
+	}
+
+	l.paths = append(l.paths, base)
+	for key, value := range baseValues {
+		if _, exists := l.files[key]; !exists {
+			l.files[key] = value
+		}
+	}
+	return l, nil
+}
+
+// merge 合并一个配置文件，已存在的键保留原值；required 为 false 时文件不存在不报错
+func (l *layers) merge(path string, required bool) error {
+	l.paths = append(l.paths, path)
+	values, err := readEnvFile(path, required)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		if _, exists := l.files[key]; !exists {
+			l.files[key] = value
+		}
+	}
+	return nil
+}
+
+// lookup 按优先级查找配置项
+func (l *layers) lookup(key string) (string, bool) {
+	if v, ok := l.overrides[key]; ok {
+		return v, true
+	}
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	v, ok := l.files[key]
+	return v, ok
+}
+
+// readEnvFile 读取 dotenv 格式文件；required 为 false 时文件不存在返回空结果
+func readEnvFile(path string, required bool) (map[string]string, error) {
+	values, err := godotenv.Read(path)
+	if err != nil {
+		if !required && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return values, nil
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Tunables 运行期可热更新的配置项，其余配置项变化需要重启才能生效
+type Tunables struct {
+	LogLevel        string        // LOG_LEVEL
+	GlobalRateLimit int64         // SPIKE_GLOBAL_RATE_LIMIT
+	UserRateLimit   int64         // SPIKE_USER_RATE_LIMIT
+	RateLimitWindow time.Duration // SPIKE_RATE_LIMIT_WINDOW
+	StockCacheTTL   time.Duration // SPIKE_STOCK_CACHE_TTL
+	UserMarkTTL     time.Duration // SPIKE_USER_MARK_TTL
+}
+
+// Tunables 返回配置中可热更新的部分
+func (c *Config) Tunables() Tunables {
+	return Tunables{
+		LogLevel:        c.Log.Level,
+		GlobalRateLimit: c.Spike.GlobalRateLimit,
+		UserRateLimit:   c.Spike.UserRateLimit,
+		RateLimitWindow: c.Spike.RateLimitWindow,
+		StockCacheTTL:   c.Spike.StockCacheTTL,
+		UserMarkTTL:     c.Spike.UserMarkTTL,
+	}
+}
+
+// setTunables 用 t 覆盖配置中可热更新的部分
+func (c *Config) setTunables(t Tunables) {
+	c.Log.Level = t.LogLevel
+	c.Spike.GlobalRateLimit = t.GlobalRateLimit
+	c.Spike.UserRateLimit = t.UserRateLimit
+	c.Spike.RateLimitWindow = t.RateLimitWindow
+	c.Spike.StockCacheTTL = t.StockCacheTTL
+	c.Spike.UserMarkTTL = t.UserMarkTTL
+}
+
+// fileStamp 配置文件的修改时间与大小，文件不存在时为零值
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher 配置热更新：定期检测配置文件变化，以相同的 Options 重新加载并校验，
+// 只应用 Tunables 的变化并通知订阅者；校验失败时保留原配置。
+// 环境变量与命令行覆盖在进程运行期间不变，热更新只对来自配置文件的配置项生效
+type Watcher struct {
+	opts   Options
+	logger *zap.Logger
+
+	current atomic.Pointer[Config]
+
+	reloadMu sync.Mutex // 串行化重新加载
+	stamps   map[string]fileStamp
+
+	subMu       sync.RWMutex
+	subscribers []func(c *Config)
+}
+
+// NewWatcher 创建配置热更新器，initial 为以 opts 加载的启动配置
+func NewWatcher(opts Options, initial *Config, logger *zap.Logger) *Watcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	w := &Watcher{opts: opts, logger: logger}
+	w.current.Store(initial)
+	if l, err := newLayers(opts); err == nil {
+		w.stamps = statFiles(l.paths)
+	}
+	return w
+}
+
+// Current 返回当前生效的配置，调用方不应修改
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Subscribe 注册 Tunables 变化回调，回调以新的生效配置同步执行，不应阻塞
+func (w *Watcher) Subscribe(fn func(c *Config)) {
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Start 每隔 interval 检测一次配置文件变化，ctx 取消后退出；interval 非正时不检测
+func (w *Watcher) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !w.filesChanged() {
+					continue
+				}
+				if _, err := w.Reload(); err != nil {
+					w.logger.Error("配置文件已变化但重新加载失败，保留原配置", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Reload 立即重新加载配置，Tunables 有变化时应用并通知订阅者，返回是否有变化；
+// 加载或校验失败时返回错误并保留原配置
+func (w *Watcher) Reload() (bool, error) {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	l, err := newLayers(w.opts)
+	if err != nil {
+		return false, err
+	}
+	w.stamps = statFiles(l.paths)
+	next, err := l.build()
+	if err != nil {
+		return false, err
+	}
+
+	current := w.Current()
+	oldTunables, newTunables := current.Tunables(), next.Tunables()
+
+	// 比较 Tunables 以外的配置项，变化只记录日志，不生效
+	restarted := *next
+	restarted.setTunables(oldTunables)
+	if !reflect.DeepEqual(&restarted, current) {
+		w.logger.Warn("配置文件中存在需要重启才能生效的变更，已忽略")
+	}
+	if oldTunables == newTunables {
+		return false, nil
+	}
+
+	applied := *current
+	applied.setTunables(newTunables)
+	w.current.Store(&applied)
+	w.logger.Info("配置已热更新",
+		zap.Any("from", oldTunables),
+		zap.Any("to", newTunables))
+
+	w.subMu.RLock()
+	subscribers := append([]func(c *Config){}, w.subscribers...)
+	w.subMu.RUnlock()
+	for _, fn := range subscribers {
+		fn(&applied)
+	}
+	return true, nil
+}
+
+// filesChanged 检测配置文件自上次加载以来是否变化（含新建与删除）
+func (w *Watcher) filesChanged() bool {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+	for path, stamp := range w.stamps {
+		if !statFile(path).equal(stamp) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+func statFiles(paths []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		stamps[path] = statFile(path)
+	}
+	return stamps
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
@@ -2,13 +2,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-
-	// 使用下划线导入是Go语言的特殊语法，表示只执行包的初始化函数但不使用包中的标识符
-	// MySQL驱动需要在程序启动时注册自己，而我们不需要直接调用它的函数
-	// 后续通过sql.Open("mysql", dsn)时，database/sql包会自动查找已注册的mysql驱动
-	_ "github.com/go-sql-driver/mysql"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	// MySQL驱动在导入时注册自己，迁移连接通过sql.Open("mysql", dsn)使用；
+	// 主连接池直接使用驱动的连接器，以便包装后记录语句耗时
+	gomysql "github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -18,32 +22,26 @@ import (
 	"github.com/MorseWayne/spike_shop/internal/config"
 )
 
-// DB 封装数据库连接
+// DB 封装数据库连接，内嵌的 *sql.DB 为主库；配置了只读副本时由 Reader 选择读连接
 type DB struct {
 	*sql.DB
 	logger *zap.Logger
 	dsn    string
+
+	replicas      []*replica
+	replicaMaxLag time.Duration
+	next          atomic.Uint64 // 轮询选择副本的计数
 }
 
-// New 创建数据库连接
+// New 创建数据库连接，只读副本连接失败不影响启动，由副本检查恢复
 func New(cfg *config.Config, logger *zap.Logger) (*DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=true&loc=Local",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.DBName,
-	)
+	dsn := buildDSN(cfg, net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port)))
 
-	sqlDB, err := sql.Open("mysql", dsn)
+	sqlDB, err := openPool(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	// 配置连接池
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(10)
-
 	// 测试连接
 	if err := sqlDB.Ping(); err != nil {
 		_ = sqlDB.Close()
@@ -56,7 +54,51 @@ func New(cfg *config.Config, logger *zap.Logger) (*DB, error) {
 		zap.String("database", cfg.Database.DBName),
 	)
 
-	return &DB{DB: sqlDB, logger: logger, dsn: dsn}, nil
+	db := &DB{DB: sqlDB, logger: logger, dsn: dsn, replicaMaxLag: cfg.Database.ReplicaMaxLag}
+	for _, addr := range cfg.Database.ReplicaAddrs {
+		replicaDB, err := openPool(buildDSN(cfg, addr))
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("open replica %s: %w", addr, err)
+		}
+		r := &replica{addr: addr, db: replicaDB}
+		db.replicas = append(db.replicas, r)
+		db.checkReplica(context.Background(), r)
+	}
+
+	return db, nil
+}
+
+// buildDSN 按配置的账号与库名构造指定地址的 DSN
+func buildDSN(cfg *config.Config, addr string) string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=true&loc=Local",
+		cfg.Database.User,
+		cfg.Database.Password,
+		addr,
+		cfg.Database.DBName,
+	)
+}
+
+// openPool 创建记录语句耗时的连接池
+func openPool(dsn string) (*sql.DB, error) {
+	connector, err := gomysql.MySQLDriver{}.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB := sql.OpenDB(&instrumentedConnector{Connector: connector})
+
+	// 配置连接池
+	sqlDB.SetMaxOpenConns(25)
+	sqlDB.SetMaxIdleConns(10)
+	return sqlDB, nil
+}
+
+// Close 关闭主库与全部只读副本的连接
+func (db *DB) Close() error {
+	for _, r := range db.replicas {
+		_ = r.db.Close()
+	}
+	return db.DB.Close()
 }
 
 // RunMigrations 使用 go-migrate 执行数据库迁移
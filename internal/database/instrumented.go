@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// instrumentedConnector 包装驱动连接器，为每条语句记录耗时指标。
+// 查询只计到驱动返回结果集为止，不包含调用方逐行读取的时间
+type instrumentedConnector struct {
+	driver.Connector
+}
+
+// Connect 建立连接并包装
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn}, nil
+}
+
+// instrumentedConn 转发驱动连接的可选接口；带参数的语句驱动会返回 driver.ErrSkip，
+// 改走 PrepareContext，由 instrumentedStmt 计时
+type instrumentedConn struct {
+	driver.Conn
+}
+
+var (
+	_ driver.ConnPrepareContext = (*instrumentedConn)(nil)
+	_ driver.ConnBeginTx        = (*instrumentedConn)(nil)
+	_ driver.QueryerContext     = (*instrumentedConn)(nil)
+	_ driver.ExecerContext      = (*instrumentedConn)(nil)
+	_ driver.Pinger             = (*instrumentedConn)(nil)
+	_ driver.SessionResetter    = (*instrumentedConn)(nil)
+	_ driver.Validator          = (*instrumentedConn)(nil)
+	_ driver.NamedValueChecker  = (*instrumentedConn)(nil)
+)
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, operation: queryOperation(query)}, nil
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	observeQuery(queryOperation(query), start, err)
+	return rows, err
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := e.ExecContext(ctx, query, args)
+	observeQuery(queryOperation(query), start, err)
+	return result, err
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// instrumentedStmt 为预处理语句的执行计时
+type instrumentedStmt struct {
+	driver.Stmt
+	operation string
+}
+
+var (
+	_ driver.StmtQueryContext  = (*instrumentedStmt)(nil)
+	_ driver.StmtExecContext   = (*instrumentedStmt)(nil)
+	_ driver.NamedValueChecker = (*instrumentedStmt)(nil)
+)
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValuesToValues(args))
+	}
+	observeQuery(s.operation, start, err)
+	return rows, err
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var (
+		result driver.Result
+		err    error
+	)
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = e.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedValuesToValues(args))
+	}
+	observeQuery(s.operation, start, err)
+	return result, err
+}
+
+func (s *instrumentedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// observeQuery 记录语句耗时；driver.ErrSkip 表示驱动改走预处理路径，不计入
+func observeQuery(operation string, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	metrics.DBQueryDuration.Observe(metrics.Since(start), operation, metrics.Result(err))
+}
+
+// queryOperation 取语句首个关键字作为指标标签，未识别的归为 other
+func queryOperation(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexFunc(query, unicode.IsSpace); i >= 0 {
+		query = query[:i]
+	}
+	switch op := strings.ToLower(query); op {
+	case "select", "insert", "update", "delete", "replace":
+		return op
+	default:
+		return "other"
+	}
+}
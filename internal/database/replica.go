@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// replicaCheckTimeout 单个副本一次检查（探活与查询复制延迟）的超时时间
+const replicaCheckTimeout = 2 * time.Second
+
+var (
+	errNotReplica         = errors.New("replication is not configured on this server")
+	errReplicationStopped = errors.New("replication is stopped")
+)
+
+// replica 只读副本连接及最近一次检查结果
+type replica struct {
+	addr    string
+	db      *sql.DB
+	healthy atomic.Bool
+	lag     atomic.Int64 // 复制延迟（纳秒）
+}
+
+type replicaReadsKey struct{}
+
+// WithReplicaReads 标记 ctx 中的查询可以读只读副本（容忍复制延迟），未标记的查询始终读主库
+func WithReplicaReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaReadsKey{}, true)
+}
+
+// WithPrimaryReads 标记 ctx 中的查询必须读主库，覆盖上游的 WithReplicaReads
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaReadsKey{}, false)
+}
+
+// ReplicaReadsAllowed 判断 ctx 中的查询是否可以读只读副本
+func ReplicaReadsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(replicaReadsKey{}).(bool)
+	return allowed
+}
+
+// Reader 返回本次查询使用的连接：ctx 允许读副本时轮询选择连通且复制延迟不超过上限的副本，
+// 未配置副本、副本均不可用或 ctx 未允许时返回主库
+func (db *DB) Reader(ctx context.Context) *sql.DB {
+	if len(db.replicas) == 0 || !ReplicaReadsAllowed(ctx) {
+		return db.DB
+	}
+	start := db.next.Add(1)
+	for i := range db.replicas {
+		r := db.replicas[(start+uint64(i))%uint64(len(db.replicas))]
+		if db.usable(r) {
+			return r.db
+		}
+	}
+	return db.DB
+}
+
+// ReplicationLag 返回 ctx 中的查询可能读到的最大复制延迟；ctx 的查询读主库时 fromReplica 为 false
+func (db *DB) ReplicationLag(ctx context.Context) (lag time.Duration, fromReplica bool) {
+	if !ReplicaReadsAllowed(ctx) {
+		return 0, false
+	}
+	for _, r := range db.replicas {
+		if db.usable(r) {
+			fromReplica = true
+			lag = max(lag, time.Duration(r.lag.Load()))
+		}
+	}
+	return lag, fromReplica
+}
+
+// usable 判断副本是否可读
+func (db *DB) usable(r *replica) bool {
+	return r.healthy.Load() && time.Duration(r.lag.Load()) <= db.replicaMaxLag
+}
+
+// StartReplicaMonitor 按 interval 检查各副本的连通性与复制延迟，ctx 取消时停止；未配置副本时不启动
+func (db *DB) StartReplicaMonitor(ctx context.Context, interval time.Duration) {
+	if len(db.replicas) == 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, r := range db.replicas {
+					db.checkReplica(ctx, r)
+				}
+			}
+		}
+	}()
+}
+
+// checkReplica 检查单个副本并更新状态，可用性变化时记录日志
+func (db *DB) checkReplica(ctx context.Context, r *replica) {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	lag, err := replicationLag(ctx, r.db)
+	wasUsable := db.usable(r)
+	r.healthy.Store(err == nil)
+	if err == nil {
+		r.lag.Store(int64(lag))
+	}
+
+	switch usable := db.usable(r); {
+	case wasUsable && !usable:
+		db.logger.Warn("replica removed from read pool",
+			zap.String("replica", r.addr), zap.Duration("lag", lag), zap.Error(err))
+	case !wasUsable && usable:
+		db.logger.Info("replica added to read pool",
+			zap.String("replica", r.addr), zap.Duration("lag", lag))
+	case !usable && err != nil && ctx.Err() == nil:
+		db.logger.Debug("replica still unavailable", zap.String("replica", r.addr), zap.Error(err))
+	}
+}
+
+// replicationLag 查询副本的复制延迟，复制未配置或已中断时返回错误
+func replicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		// MySQL 8.0.22 之前只支持旧语法
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, fmt.Errorf("query replica status: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("read replica status columns: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, fmt.Errorf("read replica status: %w", err)
+		}
+		return 0, errNotReplica
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, fmt.Errorf("scan replica status: %w", err)
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		// 复制线程未运行时该列为 NULL
+		if !values[i].Valid {
+			return 0, errReplicationStopped
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse replication lag %q: %w", values[i].String, err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errors.New("replication lag column not found")
+}
@@ -0,0 +1,71 @@
+// Package domain 定义弃单（过期未支付订单）召回相关的领域模型。
+package domain
+
+import "time"
+
+// ErrAbandonedCheckoutNotFound 弃单记录不存在
+var ErrAbandonedCheckoutNotFound = NewNotFoundError("弃单记录不存在")
+
+// AbandonedCheckout 表示一笔过期未支付的秒杀订单及其召回转化情况
+type AbandonedCheckout struct {
+	ID           int64     `json:"id"`
+	SpikeOrderID int64     `json:"spike_order_id"`
+	SpikeEventID int64     `json:"spike_event_id"`
+	UserID       int64     `json:"user_id"`
+	ProductID    int64     `json:"product_id"`
+	Quantity     int64     `json:"quantity"`
+	TotalAmount  float64   `json:"total_amount"`
+	ExpiredAt    time.Time `json:"expired_at"`
+
+	// 召回与转化跟踪
+	CampaignID       string     `json:"campaign_id"`        // 召回活动ID，为空表示尚未召回
+	CouponCode       string     `json:"coupon_code"`        // 召回发放的优惠券码
+	ContactedAt      *time.Time `json:"contacted_at"`       // 召回通知发送时间
+	RecoveredOrderID *int64     `json:"recovered_order_id"` // 召回后转化的秒杀订单ID
+	RecoveredAt      *time.Time `json:"recovered_at"`       // 转化时间
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsRecovered 判断弃单是否已通过召回转化
+func (a *AbandonedCheckout) IsRecovered() bool {
+	return a.RecoveredOrderID != nil
+}
+
+// AbandonedCheckoutListRequest 表示弃单查询请求
+type AbandonedCheckoutListRequest struct {
+	Page         int        `json:"page"`           // 页码，从1开始
+	PageSize     int        `json:"page_size"`      // 每页大小
+	SpikeEventID *int64     `json:"spike_event_id"` // 活动过滤
+	CampaignID   *string    `json:"campaign_id"`    // 召回活动过滤
+	Recovered    *bool      `json:"recovered"`      // 是否已转化
+	From         *time.Time `json:"from"`           // 过期时间起（含）
+	To           *time.Time `json:"to"`             // 过期时间止（不含）
+	SkipTotal    bool       `json:"-"`              // 跳过总数统计（include_total=false）
+}
+
+// AbandonedCheckoutSummary 表示弃单召回汇总，与列表使用相同的过滤条件
+type AbandonedCheckoutSummary struct {
+	Abandoned      int64   `json:"abandoned"`       // 弃单数
+	AbandonedValue float64 `json:"abandoned_value"` // 弃单金额
+	Contacted      int64   `json:"contacted"`       // 已召回数
+	Recovered      int64   `json:"recovered"`       // 已转化数
+	ConversionRate float64 `json:"conversion_rate"` // 转化率 = 已转化 / 已召回
+}
+
+// AbandonedCheckoutListResponse 表示弃单查询响应
+type AbandonedCheckoutListResponse struct {
+	Items    []*AbandonedCheckout      `json:"items"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"page_size"`
+	Summary  *AbandonedCheckoutSummary `json:"summary"`
+	PageInfo
+}
+
+// RecoveryCampaignRequest 表示为弃单发起召回的请求
+type RecoveryCampaignRequest struct {
+	CampaignID string `json:"campaign_id" binding:"required,min=1,max=64"`
+	CouponCode string `json:"coupon_code" binding:"max=64"`
+	Message    string `json:"message" binding:"max=255"` // 通知内容，为空时使用默认文案
+}
@@ -0,0 +1,52 @@
+// Package domain 定义管理员操作审计相关的领域模型。
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 审计记录中的操作对象类型，未由处理器指定时取路由中第一个路径参数前的资源名
+const (
+	AdminAuditEntityProduct    = "product"
+	AdminAuditEntityInventory  = "inventory"
+	AdminAuditEntitySpikeEvent = "spike_event"
+)
+
+// AdminAuditLog 表示一次管理员写操作的审计记录
+type AdminAuditLog struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`       // 操作者，如 admin:1
+	Action     string          `json:"action"`      // 请求方法与路由模板，如 POST /api/v1/admin/spike/events/:id/pause
+	EntityType string          `json:"entity_type"` // 操作对象类型
+	EntityID   string          `json:"entity_id"`   // 操作对象ID，批量操作为空
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"` // 处理器未提供变更后数据时为脱敏后的请求体
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	ClientIP   string          `json:"client_ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AdminAuditListRequest 表示审计记录查询请求
+type AdminAuditListRequest struct {
+	Page       int        `json:"page"`        // 页码，从1开始
+	PageSize   int        `json:"page_size"`   // 每页大小
+	Actor      string     `json:"actor"`       // 操作者过滤
+	Action     string     `json:"action"`      // 操作过滤（精确匹配）
+	EntityType string     `json:"entity_type"` // 对象类型过滤
+	EntityID   string     `json:"entity_id"`   // 对象ID过滤
+	RequestID  string     `json:"request_id"`  // 请求ID过滤
+	From       *time.Time `json:"from"`        // 操作时间起（含）
+	To         *time.Time `json:"to"`          // 操作时间止（不含）
+	SkipTotal  bool       `json:"-"`           // 跳过总数统计（include_total=false）
+}
+
+// AdminAuditListResponse 表示审计记录查询响应
+type AdminAuditListResponse struct {
+	Items    []*AdminAuditLog `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	PageInfo
+}
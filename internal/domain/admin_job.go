@@ -0,0 +1,73 @@
+// Package domain 定义管理后台异步任务相关的领域模型。
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAdminJobNotFound 后台任务不存在或已过保留期
+	ErrAdminJobNotFound = NewNotFoundError("后台任务不存在")
+	// ErrAdminJobQueueFull 后台任务队列已满，需稍后重试
+	ErrAdminJobQueueFull = errors.New("后台任务队列已满")
+)
+
+// AdminJobKind 后台任务类型
+type AdminJobKind string
+
+const (
+	AdminJobKindWarmupAll  AdminJobKind = "warmup_all"  // 预热全部进行中与预告期活动的库存
+	AdminJobKindBulkAdjust AdminJobKind = "bulk_adjust" // 批量调整库存（CSV / JSON 导入）
+	AdminJobKindReport     AdminJobKind = "report"      // 生成活动报表
+)
+
+// AdminJobStatus 后台任务状态
+type AdminJobStatus string
+
+const (
+	AdminJobStatusQueued  AdminJobStatus = "queued"  // 排队中
+	AdminJobStatusRunning AdminJobStatus = "running" // 执行中
+	AdminJobStatusDone    AdminJobStatus = "done"    // 执行成功
+	AdminJobStatusFailed  AdminJobStatus = "failed"  // 执行失败
+)
+
+// IsFinished 判断任务是否已结束
+func (s AdminJobStatus) IsFinished() bool {
+	return s == AdminJobStatusDone || s == AdminJobStatusFailed
+}
+
+// AdminJob 表示一个在后台受控执行的管理操作
+type AdminJob struct {
+	ID         int64          `json:"id"`
+	Kind       AdminJobKind   `json:"kind"`
+	Target     string         `json:"target,omitempty"` // 操作对象，如 spike_event:7
+	Status     AdminJobStatus `json:"status"`
+	Done       int            `json:"done"`  // 已处理条数
+	Total      int            `json:"total"` // 总条数，开始执行前为 0
+	Result     any            `json:"result,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	CreatedBy  string         `json:"created_by,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// AdminJobListResponse 后台任务列表响应
+type AdminJobListResponse struct {
+	Items []*AdminJob `json:"items"`
+}
+
+// WarmupAllRequest 预热全部活动库存请求
+type WarmupAllRequest struct {
+	Force bool `json:"force"` // 是否覆盖进行中活动已存在的库存键
+}
+
+// WarmupAllResult 预热全部活动库存的结果
+type WarmupAllResult struct {
+	Total   int      `json:"total"`
+	Warmed  int      `json:"warmed"`
+	Skipped int      `json:"skipped"` // 进行中且库存已预热、未强制覆盖的活动
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
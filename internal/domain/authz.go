@@ -0,0 +1,8 @@
+// Package domain 定义资源访问控制相关的通用错误。
+package domain
+
+import "errors"
+
+// ErrForbidden 资源存在但不属于当前用户，处理器统一映射为 403；
+// 资源不存在时返回 ErrNotFound（见 errors.go）
+var ErrForbidden = errors.New("无权访问该资源")
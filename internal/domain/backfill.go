@@ -0,0 +1,30 @@
+// Package domain 定义数据回填任务进度相关的领域模型。
+package domain
+
+import "time"
+
+// BackfillStatus 定义回填任务状态类型
+type BackfillStatus string
+
+const (
+	BackfillStatusRunning   BackfillStatus = "running"   // 进行中（含中断后待续跑）
+	BackfillStatusCompleted BackfillStatus = "completed" // 已完成
+	BackfillStatusFailed    BackfillStatus = "failed"    // 失败
+)
+
+// BackfillProgress 表示某个回填任务的处理进度
+type BackfillProgress struct {
+	JobName        string         `json:"job_name"`
+	LastID         int64          `json:"last_id"`         // 已处理到的最大主键ID，续跑时从其后开始
+	ProcessedCount int64          `json:"processed_count"` // 累计处理行数
+	Status         BackfillStatus `json:"status"`
+	LastError      string         `json:"last_error"`
+	StartedAt      time.Time      `json:"started_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	CompletedAt    *time.Time     `json:"completed_at"`
+}
+
+// IsCompleted 判断任务是否已完成
+func (p *BackfillProgress) IsCompleted() bool {
+	return p.Status == BackfillStatusCompleted
+}
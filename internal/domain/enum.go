@@ -0,0 +1,218 @@
+// Package domain 定义状态等枚举类型的取值校验、JSON 编解码与取值列表。
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidEnum 枚举取值无效，具体错误为 *InvalidEnumError
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// InvalidEnumError 描述无效的枚举取值及允许的取值
+type InvalidEnumError struct {
+	Enum    string   // 枚举名称，如 spike_order_status
+	Value   string   // 收到的取值
+	Allowed []string // 允许的取值
+}
+
+func (e *InvalidEnumError) Error() string {
+	return fmt.Sprintf("invalid %s %q, allowed: %s", e.Enum, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// Is 使无效取值错误可以按 ErrInvalidEnum 匹配
+func (e *InvalidEnumError) Is(target error) bool { return target == ErrInvalidEnum }
+
+// EnumValue 枚举取值及其说明，供客户端构建表单与筛选项
+type EnumValue struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// enumEntry 枚举的一个取值
+type enumEntry[T ~string] struct {
+	value T
+	label string
+}
+
+// enum 按声明顺序记录一个枚举类型的全部取值
+type enum[T ~string] struct {
+	name    string
+	entries []enumEntry[T]
+}
+
+func (e enum[T]) valid(v T) bool {
+	for _, entry := range e.entries {
+		if entry.value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (e enum[T]) parse(s string) (T, error) {
+	v := T(s)
+	if !e.valid(v) {
+		return "", &InvalidEnumError{Enum: e.name, Value: s, Allowed: e.allowed()}
+	}
+	return v, nil
+}
+
+func (e enum[T]) allowed() []string {
+	allowed := make([]string, len(e.entries))
+	for i, entry := range e.entries {
+		allowed[i] = string(entry.value)
+	}
+	return allowed
+}
+
+func (e enum[T]) values() []EnumValue {
+	values := make([]EnumValue, len(e.entries))
+	for i, entry := range e.entries {
+		values[i] = EnumValue{Value: string(entry.value), Label: entry.label}
+	}
+	return values
+}
+
+// marshal 编码为 JSON 字符串，零值编码为空字符串，其它无效取值返回错误，避免向客户端输出未定义的状态
+func (e enum[T]) marshal(v T) ([]byte, error) {
+	if v != "" && !e.valid(v) {
+		return nil, &InvalidEnumError{Enum: e.name, Value: string(v), Allowed: e.allowed()}
+	}
+	return json.Marshal(string(v))
+}
+
+// unmarshal 解码 JSON 字符串，空字符串解码为零值，其它无效取值返回错误
+func (e enum[T]) unmarshal(data []byte, v *T) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s must be a string: %w", e.name, err)
+	}
+	if s == "" {
+		*v = ""
+		return nil
+	}
+	parsed, err := e.parse(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+var spikeEventStatusEnum = enum[SpikeEventStatus]{name: "spike_event_status", entries: []enumEntry[SpikeEventStatus]{
+	{SpikeEventStatusDraft, "草稿"},
+	{SpikeEventStatusScheduled, "待发布"},
+	{SpikeEventStatusPending, "待开始"},
+	{SpikeEventStatusActive, "进行中"},
+	{SpikeEventStatusPaused, "已暂停"},
+	{SpikeEventStatusEnded, "已结束"},
+	{SpikeEventStatusCancelled, "已取消"},
+}}
+
+var spikeOrderStatusEnum = enum[SpikeOrderStatus]{name: "spike_order_status", entries: []enumEntry[SpikeOrderStatus]{
+	{SpikeOrderStatusPending, "待支付"},
+	{SpikeOrderStatusPaid, "已支付"},
+	{SpikeOrderStatusCancelled, "已取消"},
+	{SpikeOrderStatusExpired, "已过期"},
+}}
+
+var spikeOrderChannelEnum = enum[SpikeOrderChannel]{name: "spike_order_channel", entries: []enumEntry[SpikeOrderChannel]{
+	{SpikeOrderChannelApp, "移动应用"},
+	{SpikeOrderChannelWeb, "网页"},
+	{SpikeOrderChannelAPI, "开放接口"},
+}}
+
+var spikeSettlementStatusEnum = enum[SpikeSettlementStatus]{name: "spike_settlement_status", entries: []enumEntry[SpikeSettlementStatus]{
+	{SpikeSettlementStatusPending, "待打款"},
+	{SpikeSettlementStatusPaidOut, "已打款"},
+}}
+
+var orderStatusEnum = enum[OrderStatus]{name: "order_status", entries: []enumEntry[OrderStatus]{
+	{OrderStatusPending, "待支付"},
+	{OrderStatusPaid, "已支付"},
+	{OrderStatusCancelled, "已取消"},
+	{OrderStatusExpired, "已过期"},
+}}
+
+var orderSourceEnum = enum[OrderSource]{name: "order_source", entries: []enumEntry[OrderSource]{
+	{OrderSourceCart, "购物车结算"},
+	{OrderSourceSpike, "秒杀"},
+}}
+
+var productStatusEnum = enum[ProductStatus]{name: "product_status", entries: []enumEntry[ProductStatus]{
+	{ProductStatusDraft, "草稿"},
+	{ProductStatusActive, "正常销售"},
+	{ProductStatusInactive, "暂停销售"},
+	{ProductStatusPaused, "临时暂停"},
+	{ProductStatusDiscontinued, "已停产"},
+	{ProductStatusDeleted, "已删除"},
+}}
+
+// Enums 返回客户端可用的全部枚举及其取值，键为枚举名称
+func Enums() map[string][]EnumValue {
+	return map[string][]EnumValue{
+		spikeEventStatusEnum.name:      spikeEventStatusEnum.values(),
+		spikeOrderStatusEnum.name:      spikeOrderStatusEnum.values(),
+		spikeOrderChannelEnum.name:     spikeOrderChannelEnum.values(),
+		spikeSettlementStatusEnum.name: spikeSettlementStatusEnum.values(),
+		productStatusEnum.name:         productStatusEnum.values(),
+		orderStatusEnum.name:           orderStatusEnum.values(),
+		orderSourceEnum.name:           orderSourceEnum.values(),
+	}
+}
+
+// IsValid 判断是否为已定义的活动状态
+func (s SpikeEventStatus) IsValid() bool { return spikeEventStatusEnum.valid(s) }
+
+// ParseSpikeEventStatus 解析活动状态，无效取值返回 *InvalidEnumError
+func ParseSpikeEventStatus(s string) (SpikeEventStatus, error) { return spikeEventStatusEnum.parse(s) }
+
+// MarshalJSON 编码活动状态，未定义的状态返回错误
+func (s SpikeEventStatus) MarshalJSON() ([]byte, error) { return spikeEventStatusEnum.marshal(s) }
+
+// UnmarshalJSON 解码活动状态，未定义的状态返回错误
+func (s *SpikeEventStatus) UnmarshalJSON(data []byte) error {
+	return spikeEventStatusEnum.unmarshal(data, s)
+}
+
+// IsValid 判断是否为已定义的订单状态
+func (s SpikeOrderStatus) IsValid() bool { return spikeOrderStatusEnum.valid(s) }
+
+// ParseSpikeOrderStatus 解析订单状态，无效取值返回 *InvalidEnumError
+func ParseSpikeOrderStatus(s string) (SpikeOrderStatus, error) { return spikeOrderStatusEnum.parse(s) }
+
+// MarshalJSON 编码订单状态，未定义的状态返回错误
+func (s SpikeOrderStatus) MarshalJSON() ([]byte, error) { return spikeOrderStatusEnum.marshal(s) }
+
+// UnmarshalJSON 解码订单状态，未定义的状态返回错误
+func (s *SpikeOrderStatus) UnmarshalJSON(data []byte) error {
+	return spikeOrderStatusEnum.unmarshal(data, s)
+}
+
+// ParseSpikeOrderChannel 解析下单渠道，无效取值返回 *InvalidEnumError
+func ParseSpikeOrderChannel(s string) (SpikeOrderChannel, error) {
+	return spikeOrderChannelEnum.parse(s)
+}
+
+// ParseSpikeSettlementStatus 解析结算打款状态，无效取值返回 *InvalidEnumError
+func ParseSpikeSettlementStatus(s string) (SpikeSettlementStatus, error) {
+	return spikeSettlementStatusEnum.parse(s)
+}
+
+// ParseProductStatus 解析商品状态，无效取值返回 *InvalidEnumError
+func ParseProductStatus(s string) (ProductStatus, error) { return productStatusEnum.parse(s) }
+
+// ParseOrderStatus 解析普通订单状态，无效取值返回 *InvalidEnumError
+func ParseOrderStatus(s string) (OrderStatus, error) { return orderStatusEnum.parse(s) }
+
+// MarshalJSON 编码普通订单状态，未定义的状态返回错误
+func (s OrderStatus) MarshalJSON() ([]byte, error) { return orderStatusEnum.marshal(s) }
+
+// UnmarshalJSON 解码普通订单状态，未定义的状态返回错误
+func (s *OrderStatus) UnmarshalJSON(data []byte) error { return orderStatusEnum.unmarshal(data, s) }
+
+// ParseOrderSource 解析订单来源，无效取值返回 *InvalidEnumError
+func ParseOrderSource(s string) (OrderSource, error) { return orderSourceEnum.parse(s) }
@@ -0,0 +1,56 @@
+// Package domain 定义跨资源通用的业务错误分类。
+package domain
+
+import "errors"
+
+// 通用业务错误分类，仓储与服务层返回的具体错误均可按分类用 errors.Is 匹配，
+// 处理器据此统一映射 HTTP 状态码，不再依赖错误文本
+var (
+	// ErrNotFound 资源不存在，具体资源的不存在错误（如 ErrSpikeOrderNotFound）均满足 errors.Is(err, ErrNotFound)
+	ErrNotFound = errors.New("资源不存在")
+	// ErrConflict 资源已存在或当前状态不允许该操作
+	ErrConflict = errors.New("资源状态冲突")
+	// ErrVersionConflict 乐观锁版本冲突，资源已被其他请求修改
+	ErrVersionConflict = errors.New("资源已被其他请求修改")
+	// ErrInsufficientStock 可用库存不足
+	ErrInsufficientStock = errors.New("库存不足")
+	// ErrInvalidArgument 请求参数不满足业务规则
+	ErrInvalidArgument = errors.New("参数无效")
+)
+
+// Error 带分类的具体业务错误，Error() 返回具体描述，errors.Is 按所属分类匹配。
+// 使用值类型，相同分类与描述的错误彼此相等
+type Error struct {
+	kind error
+	msg  string
+}
+
+func (e Error) Error() string { return e.msg }
+
+// Is 使具体错误可以按所属分类匹配
+func (e Error) Is(target error) bool { return target == e.kind }
+
+// NewNotFoundError 创建具体资源的不存在错误
+func NewNotFoundError(msg string) error {
+	return Error{kind: ErrNotFound, msg: msg}
+}
+
+// NewConflictError 创建资源已存在或状态冲突错误
+func NewConflictError(msg string) error {
+	return Error{kind: ErrConflict, msg: msg}
+}
+
+// NewVersionConflictError 创建乐观锁版本冲突错误
+func NewVersionConflictError(msg string) error {
+	return Error{kind: ErrVersionConflict, msg: msg}
+}
+
+// NewInsufficientStockError 创建库存不足错误
+func NewInsufficientStockError(msg string) error {
+	return Error{kind: ErrInsufficientStock, msg: msg}
+}
+
+// NewInvalidArgumentError 创建参数不满足业务规则的错误
+func NewInvalidArgumentError(msg string) error {
+	return Error{kind: ErrInvalidArgument, msg: msg}
+}
@@ -0,0 +1,29 @@
+// Package domain 定义客服代操作（模拟用户）相关的领域模型。
+package domain
+
+import "time"
+
+// ImpersonationAudit 表示一次代操作令牌申请的审计记录
+type ImpersonationAudit struct {
+	ID           int64     `json:"id"`
+	AdminID      int64     `json:"admin_id"`
+	TargetUserID int64     `json:"target_user_id"`
+	Scope        string    `json:"scope"`
+	Reason       string    `json:"reason"`
+	ClientIP     string    `json:"client_ip"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ImpersonateRequest 表示申请代操作令牌请求
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"` // 代操作原因，建议填写工单号
+}
+
+// ImpersonateResponse 表示申请代操作令牌响应
+type ImpersonateResponse struct {
+	AccessToken  string    `json:"access_token"`
+	TargetUserID int64     `json:"target_user_id"`
+	Scope        string    `json:"scope"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
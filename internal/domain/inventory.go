@@ -75,8 +75,8 @@ func (i *Inventory) Restock(quantity int) error {
 
 // CreateInventoryRequest 表示创建库存请求
 type CreateInventoryRequest struct {
-	ProductID    int64 `json:"product_id" binding:"required"`
-	Stock        int   `json:"stock" binding:"min=0"`
+	ProductID    int64 `json:"product_id" binding:"required,gt=0"`
+	Stock        int   `json:"stock" binding:"min=0,ltefield=MaxStock"`
 	ReorderPoint int   `json:"reorder_point" binding:"min=0"`
 	MaxStock     int   `json:"max_stock" binding:"required,gt=0"`
 }
@@ -90,29 +90,180 @@ type UpdateInventoryRequest struct {
 
 // StockAdjustmentRequest 表示库存调整请求
 type StockAdjustmentRequest struct {
-	Quantity int    `json:"quantity" binding:"required"`          // 调整数量，正数为增加，负数为减少
+	Quantity int    `json:"quantity" binding:"ne=0"`              // 调整数量，正数为增加，负数为减少
 	Reason   string `json:"reason" binding:"required,min=1"`      // 调整原因
 	Type     string `json:"type" binding:"required,oneof=in out"` // 调整类型: in(入库) out(出库)
 }
 
+// BulkStockAdjustmentItem 表示批量库存调整中的一行
+type BulkStockAdjustmentItem struct {
+	SKU    string `json:"sku"`
+	Delta  int    `json:"delta"` // 调整量，正数入库、负数出库
+	Reason string `json:"reason"`
+}
+
+// BulkStockAdjustmentMode 表示批量库存调整的执行方式
+type BulkStockAdjustmentMode string
+
+const (
+	// BulkStockAdjustmentPartial 逐行生效，单行失败不影响其余行（默认）
+	BulkStockAdjustmentPartial BulkStockAdjustmentMode = "partial"
+	// BulkStockAdjustmentAtomic 全部行在一个事务中执行，任一行校验或执行失败时全部不生效
+	BulkStockAdjustmentAtomic BulkStockAdjustmentMode = "atomic"
+)
+
+// ParseBulkStockAdjustmentMode 解析批量库存调整的执行方式，空字符串表示默认的 partial
+func ParseBulkStockAdjustmentMode(s string) (BulkStockAdjustmentMode, error) {
+	switch mode := BulkStockAdjustmentMode(s); mode {
+	case "":
+		return BulkStockAdjustmentPartial, nil
+	case BulkStockAdjustmentPartial, BulkStockAdjustmentAtomic:
+		return mode, nil
+	default:
+		return "", NewInvalidArgumentError("mode must be partial or atomic")
+	}
+}
+
+// BulkStockAdjustmentOptions 表示批量库存调整的执行选项
+type BulkStockAdjustmentOptions struct {
+	Mode   BulkStockAdjustmentMode
+	DryRun bool // 只校验不执行，报告中 success 表示该行通过校验
+}
+
+// BulkStockAdjustmentRequest 表示批量库存调整请求（JSON 格式；CSV 格式的列为 sku,delta,reason）
+type BulkStockAdjustmentRequest struct {
+	Items []BulkStockAdjustmentItem `json:"items"`
+}
+
+// BulkStockAdjustmentRowResult 表示批量库存调整中一行的处理结果
+type BulkStockAdjustmentRowResult struct {
+	Row       int    `json:"row"` // 行号，从1开始（CSV 不含表头）
+	SKU       string `json:"sku"`
+	ProductID int64  `json:"product_id,omitempty"`
+	Delta     int    `json:"delta"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkStockAdjustmentReport 表示批量库存调整结果报告
+type BulkStockAdjustmentReport struct {
+	Mode       BulkStockAdjustmentMode         `json:"mode"`
+	DryRun     bool                            `json:"dry_run"`
+	Total      int                             `json:"total"`
+	Succeeded  int                             `json:"succeeded"`
+	Failed     int                             `json:"failed"`
+	RolledBack bool                            `json:"rolled_back"` // atomic 模式下有行失败，所有调整均未生效
+	Rows       []*BulkStockAdjustmentRowResult `json:"rows"`
+}
+
+// 库存快照导出格式
+const (
+	InventoryExportFormatCSV  = "csv"
+	InventoryExportFormatJSON = "json"
+)
+
+// InventorySnapshotColumns 库存快照 CSV 的列顺序，首列 sku 与批量调整一致，盘点后可据此填写调整量
+var InventorySnapshotColumns = []string{
+	"sku", "product_id", "product_name", "stock", "reserved_stock", "available_stock",
+	"sold_stock", "reorder_point", "max_stock", "updated_at",
+}
+
+// InventorySnapshotRow 表示库存快照导出中的一行
+type InventorySnapshotRow struct {
+	SKU            string    `json:"sku"`
+	ProductID      int64     `json:"product_id"`
+	ProductName    string    `json:"product_name"`
+	Stock          int       `json:"stock"`
+	ReservedStock  int       `json:"reserved_stock"`
+	AvailableStock int       `json:"available_stock"`
+	SoldStock      int       `json:"sold_stock"`
+	ReorderPoint   int       `json:"reorder_point"`
+	MaxStock       int       `json:"max_stock"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
 // ReserveStockRequest 表示预留库存请求
 type ReserveStockRequest struct {
-	ProductID int64 `json:"product_id" binding:"required"`
-	Quantity  int   `json:"quantity" binding:"required,gt=0"`
+	ProductID  int64 `json:"product_id" binding:"required,gt=0"`
+	Quantity   int   `json:"quantity" binding:"required,gt=0"`
+	TTLSeconds int   `json:"ttl_seconds,omitempty" binding:"min=0"` // 预留有效期（秒），0 表示使用默认有效期
 }
 
-// ReleaseStockRequest 表示释放库存请求
+// ReleaseStockRequest 表示释放库存请求。
+// 携带 reservation_id 时释放该预留（商品与数量以预留记录为准），否则按商品与数量释放
 type ReleaseStockRequest struct {
-	ProductID int64 `json:"product_id" binding:"required"`
-	Quantity  int   `json:"quantity" binding:"required,gt=0"`
+	ReservationID string `json:"reservation_id,omitempty"`
+	ProductID     int64  `json:"product_id" binding:"required_without=ReservationID,omitempty,gt=0"` // 按预留ID操作时以预留记录为准
+	Quantity      int    `json:"quantity" binding:"required_without=ReservationID,omitempty,gt=0"`
 }
 
-// ConsumeStockRequest 表示消费库存请求
+// ConsumeStockRequest 表示消费库存请求。
+// 携带 reservation_id 时消费该预留（商品与数量以预留记录为准），否则按商品与数量消费
 type ConsumeStockRequest struct {
-	ProductID int64 `json:"product_id" binding:"required"`
+	ReservationID string `json:"reservation_id,omitempty"`
+	ProductID     int64  `json:"product_id" binding:"required_without=ReservationID,omitempty,gt=0"` // 按预留ID操作时以预留记录为准
+	Quantity      int    `json:"quantity" binding:"required_without=ReservationID,omitempty,gt=0"`
+}
+
+// 库存预留相关错误
+var (
+	ErrReservationNotFound = NewNotFoundError("库存预留不存在")
+	// ErrReservationNotActive 预留已被消费、释放或已过期
+	ErrReservationNotActive = errors.New("库存预留已消费、释放或过期")
+)
+
+// ReservationStatus 定义库存预留状态类型
+type ReservationStatus string
+
+const (
+	ReservationStatusReserved ReservationStatus = "reserved" // 预留中
+	ReservationStatusConsumed ReservationStatus = "consumed" // 已消费
+	ReservationStatusReleased ReservationStatus = "released" // 已由调用方释放
+	ReservationStatusExpired  ReservationStatus = "expired"  // 过期后由释放任务归还
+)
+
+// InventoryReservation 表示一次带有效期的库存预留，调用方凭 ReservationID 消费或释放
+type InventoryReservation struct {
+	ID            int64             `json:"-"`
+	ReservationID string            `json:"reservation_id"`
+	ProductID     int64             `json:"product_id"`
+	Quantity      int               `json:"quantity"`
+	Status        ReservationStatus `json:"status"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// IsActive 判断预留是否仍可消费或释放
+func (r *InventoryReservation) IsActive() bool {
+	return r.Status == ReservationStatusReserved
+}
+
+// StockCheckItem 表示一项库存可用性检查
+type StockCheckItem struct {
+	ProductID int64 `json:"product_id" binding:"required,gt=0"`
 	Quantity  int   `json:"quantity" binding:"required,gt=0"`
 }
 
+// BatchStockCheckRequest 表示批量库存可用性检查请求（如购物车结算前）
+type BatchStockCheckRequest struct {
+	Items []StockCheckItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// StockCheckResult 表示单项库存可用性检查结果
+type StockCheckResult struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+	Available bool  `json:"available"`
+	Found     bool  `json:"found"` // 商品是否存在库存记录
+}
+
+// BatchStockCheckResponse 表示批量库存可用性检查响应
+type BatchStockCheckResponse struct {
+	Items        []*StockCheckResult `json:"items"`
+	AllAvailable bool                `json:"all_available"` // 所有项均可用
+}
+
 // InventoryListRequest 表示库存列表查询请求
 type InventoryListRequest struct {
 	Page      int     `json:"page"`       // 页码，从1开始
@@ -123,23 +274,14 @@ type InventoryListRequest struct {
 	MaxStock  *int    `json:"max_stock"`  // 最大库存过滤
 	SortBy    *string `json:"sort_by"`    // 排序字段: stock, updated_at
 	SortOrder *string `json:"sort_order"` // 排序顺序: asc, desc
+	SkipTotal bool    `json:"-"`          // 跳过总数统计（include_total=false）
 }
 
 // InventoryListResponse 表示库存列表查询响应
 type InventoryListResponse struct {
 	Inventories []*Inventory `json:"inventories"` // 库存列表
-	Total       int64        `json:"total"`       // 总库存记录数
+	Total       int64        `json:"total"`       // 总库存记录数，跳过统计时为 -1
 	Page        int          `json:"page"`        // 当前页码
 	PageSize    int          `json:"page_size"`   // 每页大小
-}
-
-// StockMovement 表示库存变动记录
-type StockMovement struct {
-	ID        int64     `json:"id"`
-	ProductID int64     `json:"product_id"`
-	Type      string    `json:"type"`     // 变动类型: in, out, reserve, release, consume
-	Quantity  int       `json:"quantity"` // 变动数量
-	Reason    string    `json:"reason"`   // 变动原因
-	UserID    *int64    `json:"user_id"`  // 操作用户ID
-	CreatedAt time.Time `json:"created_at"`
+	PageInfo
 }
@@ -0,0 +1,27 @@
+// Package domain 定义用户通知投递记录相关的领域模型。
+package domain
+
+import "time"
+
+// NotificationDeliveryStatus 通知在单个渠道上的投递状态
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliverySent    NotificationDeliveryStatus = "sent"    // 已发送
+	NotificationDeliveryFailed  NotificationDeliveryStatus = "failed"  // 按渠道重试策略重试后仍失败
+	NotificationDeliverySkipped NotificationDeliveryStatus = "skipped" // 渠道未配置、通知已过期等原因未发送
+)
+
+// NotificationDelivery 一条通知消息在一个渠道上的投递记录，同一消息与渠道只有一条记录
+type NotificationDelivery struct {
+	ID        int64                      `json:"id"`
+	MessageID string                     `json:"message_id"`
+	UserID    int64                      `json:"user_id"`
+	Channel   string                     `json:"channel"`
+	Type      string                     `json:"type"`
+	Status    NotificationDeliveryStatus `json:"status"`
+	Attempts  int                        `json:"attempts"` // 累计发送次数，消息重新投递时累加
+	LastError string                     `json:"last_error,omitempty"`
+	CreatedAt time.Time                  `json:"created_at"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
@@ -0,0 +1,199 @@
+// Package domain 定义普通订单与购物车相关的业务领域模型。
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// 常用错误
+var (
+	ErrOrderNotFound    = NewNotFoundError("订单不存在")
+	ErrCartItemNotFound = NewNotFoundError("购物车中没有该商品")
+	// ErrCartEmpty 购物车为空或所选商品不在购物车中，无法结算
+	ErrCartEmpty = NewInvalidArgumentError("购物车中没有可结算的商品")
+	// ErrCartFull 购物车商品种类已达上限
+	ErrCartFull = NewConflictError("购物车商品种类已达上限")
+	// ErrOrderNotCancellable 订单不是待支付状态，不能取消
+	ErrOrderNotCancellable = NewConflictError("订单当前状态不允许取消")
+	// ErrOrderNotPayable 订单不是未过期的待支付状态，不能支付
+	ErrOrderNotPayable = NewConflictError("订单当前状态不允许支付")
+	// ErrOrderPaymentDeclined 支付渠道拒绝授权或扣款
+	ErrOrderPaymentDeclined = errors.New("支付被拒绝")
+)
+
+// OrderStatus 定义普通订单状态类型
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"   // 待支付
+	OrderStatusPaid      OrderStatus = "paid"      // 已支付
+	OrderStatusCancelled OrderStatus = "cancelled" // 已取消
+	OrderStatusExpired   OrderStatus = "expired"   // 超过支付期限未支付
+)
+
+// OrderSource 定义订单来源
+type OrderSource string
+
+const (
+	OrderSourceCart  OrderSource = "cart"  // 购物车结算
+	OrderSourceSpike OrderSource = "spike" // 秒杀订单支付后创建
+)
+
+// Order 表示普通订单。购物车结算的订单下单时预留库存，支付后消费、取消或过期时释放；
+// 秒杀来源的订单在秒杀订单支付后创建，直接为已支付状态，库存已由秒杀流程扣减
+type Order struct {
+	ID           int64        `json:"id"`
+	OrderNo      string       `json:"order_no"`
+	UserID       int64        `json:"user_id"`
+	Source       OrderSource  `json:"source"`
+	SpikeOrderID *int64       `json:"spike_order_id,omitempty"` // 来源秒杀订单ID，购物车订单为空
+	Status       OrderStatus  `json:"status"`
+	TotalAmount  float64      `json:"total_amount"`
+	ItemCount    int          `json:"item_count"` // 商品总件数
+	PaymentRef   string       `json:"payment_ref,omitempty"`
+	ExpireAt     *time.Time   `json:"expire_at,omitempty"` // 支付期限，秒杀来源的订单为空
+	PaidAt       *time.Time   `json:"paid_at,omitempty"`
+	CancelledAt  *time.Time   `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Items        []*OrderItem `json:"items,omitempty"` // 订单明细，列表查询不返回
+}
+
+// CanPay 判断订单是否可以支付：待支付且未超过支付期限
+func (o *Order) CanPay() bool {
+	return o.Status == OrderStatusPending && (o.ExpireAt == nil || time.Now().Before(*o.ExpireAt))
+}
+
+// OrderItem 表示订单明细，商品名称、SKU 与单价为下单时的快照
+type OrderItem struct {
+	ID          int64   `json:"id"`
+	OrderID     int64   `json:"order_id"`
+	ProductID   int64   `json:"product_id"`
+	VariantID   *int64  `json:"variant_id,omitempty"` // 商品规格，为空表示未区分规格
+	ProductName string  `json:"product_name"`
+	SKU         string  `json:"sku"`
+	UnitPrice   float64 `json:"unit_price"`
+	Quantity    int     `json:"quantity"`
+	Subtotal    float64 `json:"subtotal"`
+}
+
+// NewOrderItem 按商品快照创建订单明细，小计按分四舍五入
+func NewOrderItem(product *Product, unitPrice float64, quantity int) *OrderItem {
+	return &OrderItem{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		SKU:         product.SKU,
+		UnitPrice:   unitPrice,
+		Quantity:    quantity,
+		Subtotal:    roundCents(unitPrice * float64(quantity)),
+	}
+}
+
+// SetItems 设置订单明细并汇总总金额与总件数
+func (o *Order) SetItems(items []*OrderItem) {
+	o.Items = items
+	o.TotalAmount, o.ItemCount = 0, 0
+	for _, item := range items {
+		o.TotalAmount += item.Subtotal
+		o.ItemCount += item.Quantity
+	}
+	o.TotalAmount = roundCents(o.TotalAmount)
+}
+
+// roundCents 金额按分四舍五入
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// CartItem 表示购物车条目，每个用户每个商品一条
+type CartItem struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	ProductID int64     `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CartLine 表示购物车中一个商品的当前价格与可售状态
+type CartLine struct {
+	ProductID   int64   `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	SKU         string  `json:"sku"`
+	UnitPrice   float64 `json:"unit_price"` // 当前售价，结算时以结算时的售价为准
+	Quantity    int     `json:"quantity"`
+	Subtotal    float64 `json:"subtotal"`
+	Available   bool    `json:"available"` // 商品是否仍可售，不可售的商品结算时被拒绝
+}
+
+// Cart 表示用户购物车
+type Cart struct {
+	Lines       []*CartLine `json:"lines"`
+	TotalAmount float64     `json:"total_amount"` // 可售商品的金额合计
+	ItemCount   int         `json:"item_count"`   // 可售商品的总件数
+}
+
+// NewCartLine 按商品当前售价创建购物车行，商品已删除时 product 为空，该行不可售
+func NewCartLine(item *CartItem, product *Product) *CartLine {
+	line := &CartLine{ProductID: item.ProductID, Quantity: item.Quantity}
+	if product != nil {
+		line.ProductName = product.Name
+		line.SKU = product.SKU
+		line.UnitPrice = product.Price
+		line.Subtotal = roundCents(product.Price * float64(item.Quantity))
+		line.Available = product.IsAvailable()
+	}
+	return line
+}
+
+// AddLine 追加购物车行，可售商品计入金额合计与总件数
+func (c *Cart) AddLine(line *CartLine) {
+	c.Lines = append(c.Lines, line)
+	if line.Available {
+		c.TotalAmount = roundCents(c.TotalAmount + line.Subtotal)
+		c.ItemCount += line.Quantity
+	}
+}
+
+// AddCartItemRequest 表示加入购物车请求，商品已在购物车中时累加数量
+type AddCartItemRequest struct {
+	ProductID int64 `json:"product_id" binding:"required,gt=0"`
+	Quantity  int   `json:"quantity" binding:"required,gt=0,lte=999"`
+}
+
+// UpdateCartItemRequest 表示修改购物车商品数量请求
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0,lte=999"`
+}
+
+// CheckoutRequest 表示购物车结算请求，ProductIDs 为空时结算购物车中的全部商品
+type CheckoutRequest struct {
+	ProductIDs []int64 `json:"product_ids" binding:"omitempty,max=100,unique,dive,gt=0"`
+}
+
+// PayOrderRequest 表示支付普通订单请求
+type PayOrderRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required"`       // 支付渠道名称，须与服务配置的渠道一致
+	PaymentInfo   string `json:"payment_info" binding:"required,max=512"` // 客户端在渠道侧取得的支付凭证
+}
+
+// OrderListRequest 表示普通订单列表查询请求
+type OrderListRequest struct {
+	Page      int          `json:"page"`      // 页码，从1开始
+	PageSize  int          `json:"page_size"` // 每页大小
+	UserID    *int64       `json:"user_id"`   // 用户ID过滤，用户查询时固定为当前用户
+	Status    *OrderStatus `json:"status"`    // 状态过滤
+	Source    *OrderSource `json:"source"`    // 来源过滤
+	SkipTotal bool         `json:"-"`         // 跳过总数统计（include_total=false）
+}
+
+// OrderListResponse 表示普通订单列表查询响应
+type OrderListResponse struct {
+	Orders   []*Order `json:"orders"`    // 订单列表，不含明细
+	Total    int64    `json:"total"`     // 总订单数，跳过统计时为 -1
+	Page     int      `json:"page"`      // 当前页码
+	PageSize int      `json:"page_size"` // 每页大小
+	PageInfo
+}
@@ -0,0 +1,62 @@
+// Package domain 定义秒杀订单事件（时间线）相关的领域模型。
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// OrderEventType 定义订单事件类型
+type OrderEventType string
+
+const (
+	OrderEventCreated        OrderEventType = "created"         // 订单创建
+	OrderEventPaymentStarted OrderEventType = "payment_started" // 发起支付
+	OrderEventPaid           OrderEventType = "paid"            // 支付完成
+	OrderEventCancelled      OrderEventType = "cancelled"       // 订单取消
+	OrderEventExpired        OrderEventType = "expired"         // 订单过期
+	OrderEventRefunded       OrderEventType = "refunded"        // 订单退款
+	OrderEventExtended       OrderEventType = "extended"        // 延长支付时间
+)
+
+// ActorSystem 表示由系统（消费者、定时任务）触发的事件
+const ActorSystem = "system"
+
+// OrderEvent 表示订单的一次状态流转记录
+type OrderEvent struct {
+	ID           int64          `json:"id"`
+	SpikeOrderID int64          `json:"spike_order_id"`
+	EventType    OrderEventType `json:"event_type"`
+	FromStatus   string         `json:"from_status"`
+	ToStatus     string         `json:"to_status"`
+	Actor        string         `json:"actor"`
+	Remark       string         `json:"remark"`
+	TraceID      string         `json:"trace_id"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// UserActor 生成用户操作者标识
+func UserActor(userID int64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// AdminActor 生成管理员操作者标识
+func AdminActor(userID int64) string {
+	return fmt.Sprintf("admin:%d", userID)
+}
+
+// SpikeOrderTimeline 表示秒杀订单时间线
+type SpikeOrderTimeline struct {
+	SpikeOrderID int64            `json:"spike_order_id"`
+	Status       SpikeOrderStatus `json:"status"`
+	Events       []*OrderEvent    `json:"events"`
+	// Client 下单客户端信息，仅管理员可见
+	Client *SpikeOrderClient `json:"client,omitempty"`
+}
+
+// SpikeOrderClient 表示秒杀订单参与时的客户端信息
+type SpikeOrderClient struct {
+	ClientIP  string            `json:"client_ip"`
+	UserAgent string            `json:"user_agent"`
+	Channel   SpikeOrderChannel `json:"channel"`
+}
@@ -0,0 +1,44 @@
+// Package domain 定义事务性发件箱相关的领域模型。
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxStatus 发件箱消息发布状态
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"   // 待发布（含等待重试）
+	OutboxStatusPublished OutboxStatus = "published" // 已发布
+	OutboxStatusFailed    OutboxStatus = "failed"    // 超过最大重试次数，等待人工处理
+)
+
+// OutboxMessage 待可靠投递的消息，与业务数据在同一事务中写入，由中继任务发布到消息队列
+type OutboxMessage struct {
+	ID            int64           `json:"id"`
+	MessageType   string          `json:"message_type"`
+	Payload       json.RawMessage `json:"payload"`
+	TraceID       string          `json:"trace_id"`
+	Status        OutboxStatus    `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewOutboxMessage 序列化消息数据，创建待立即发布的发件箱消息
+func NewOutboxMessage(messageType string, data interface{}, traceID string) (*OutboxMessage, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxMessage{
+		MessageType: messageType,
+		Payload:     payload,
+		TraceID:     traceID,
+		Status:      OutboxStatusPending,
+	}, nil
+}
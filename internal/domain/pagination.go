@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TotalNotCounted 请求跳过总数统计（include_total=false）时响应中 total 的取值
+const TotalNotCounted int64 = -1
+
+// PageInfo 列表响应统一携带的分页元数据
+type PageInfo struct {
+	HasMore    bool   `json:"has_more"`              // 是否还有下一页
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，原样回传即可获取下一页；没有下一页时为空
+}
+
+// ErrInvalidCursor 分页游标无法解析，或与排序方式不匹配
+var ErrInvalidCursor = NewInvalidArgumentError("无效的分页游标")
+
+// ListCursor 键集分页游标，指向上一页最后一行的 (created_at, id)。
+// 下一页只取排序位置在其之后的行，深翻页不再随偏移量变慢，翻页期间插入的新行也不会造成重复或遗漏
+type ListCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// Encode 将游标编码为不透明字符串，客户端应原样回传
+func (c ListCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+// DecodeListCursor 解析 Encode 生成的游标，格式无效时返回 ErrInvalidCursor
+func DecodeListCursor(s string) (*ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var nanos, id int64
+	if n, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil || n != 2 || id <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &ListCursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// LimitWithLookahead 返回查询应取的行数：跳过总数统计时多取一行，用于判断是否还有下一页
+func LimitWithLookahead(pageSize int, skipTotal bool) int {
+	if skipTotal {
+		return pageSize + 1
+	}
+	return pageSize
+}
+
+// Paginate 截取当前页并计算分页元数据。
+// total 为 TotalNotCounted 时 items 应按 LimitWithLookahead 多取一行，据多取的行判断是否还有下一页；
+// 否则按总数判断。偏移分页的游标即下一页页码。
+func Paginate[T any](items []T, total int64, page, pageSize int) ([]T, PageInfo) {
+	var hasMore bool
+	if total == TotalNotCounted {
+		hasMore = len(items) > pageSize
+		if hasMore {
+			items = items[:pageSize]
+		}
+	} else {
+		hasMore = int64(page)*int64(pageSize) < total
+	}
+
+	info := PageInfo{HasMore: hasMore}
+	if hasMore {
+		info.NextCursor = strconv.Itoa(page + 1)
+	}
+	return items, info
+}
+
+// PaginateWithCursor 同 Paginate；keyset 为 true（列表按 created_at、id 排序）时，
+// 下一页游标改为当前页最后一行的键集游标，客户端以 cursor 参数回传
+func PaginateWithCursor[T any](items []T, total int64, page, pageSize int, keyset bool, key func(T) ListCursor) ([]T, PageInfo) {
+	items, info := Paginate(items, total, page, pageSize)
+	if keyset && info.HasMore && len(items) > 0 {
+		info.NextCursor = key(items[len(items)-1]).Encode()
+	}
+	return items, info
+}
@@ -2,6 +2,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
@@ -9,11 +10,43 @@ import (
 type ProductStatus string
 
 const (
-	ProductStatusActive   ProductStatus = "active"   // 正常销售
-	ProductStatusInactive ProductStatus = "inactive" // 暂停销售
-	ProductStatusDeleted  ProductStatus = "deleted"  // 已删除
+	ProductStatusDraft        ProductStatus = "draft"        // 草稿，尚未上架
+	ProductStatusActive       ProductStatus = "active"       // 正常销售
+	ProductStatusInactive     ProductStatus = "inactive"     // 暂停销售
+	ProductStatusPaused       ProductStatus = "paused"       // 临时暂停，可恢复销售
+	ProductStatusDiscontinued ProductStatus = "discontinued" // 已停产，不可恢复销售
+	ProductStatusDeleted      ProductStatus = "deleted"      // 已删除
 )
 
+// 商品状态与停售相关错误
+var (
+	ErrInvalidProductStatus    = errors.New("invalid product status")
+	ErrProductStatusTransition = errors.New("product status transition not allowed")
+	ErrProductStopSell         = errors.New("product sale is stopped")
+)
+
+// IsValid 判断是否为已定义的商品状态
+func (s ProductStatus) IsValid() bool {
+	return productStatusEnum.valid(s)
+}
+
+// CanTransitionTo 判断能否切换到目标状态：已删除为终态，已停产只能删除，其余状态之间可自由切换
+func (s ProductStatus) CanTransitionTo(next ProductStatus) bool {
+	if !next.IsValid() {
+		return false
+	}
+	if s == next {
+		return true
+	}
+	switch s {
+	case ProductStatusDeleted:
+		return false
+	case ProductStatusDiscontinued:
+		return next == ProductStatusDeleted
+	}
+	return true
+}
+
 // Product 表示商品领域模型
 type Product struct {
 	ID          int64         `json:"id"`
@@ -43,8 +76,10 @@ type CreateProductRequest struct {
 	CategoryID  *int64   `json:"category_id"`
 	Brand       string   `json:"brand"`
 	SKU         string   `json:"sku" binding:"required,min=1,max=100"`
-	Weight      *float64 `json:"weight"`
+	Weight      *float64 `json:"weight" binding:"omitempty,gte=0"`
 	ImageURL    string   `json:"image_url"`
+	// Status 初始状态，只能是 active 或 draft，为空时为 active
+	Status *ProductStatus `json:"status,omitempty"`
 }
 
 // UpdateProductRequest 表示更新商品请求
@@ -59,6 +94,19 @@ type UpdateProductRequest struct {
 	ImageURL    *string        `json:"image_url"`
 }
 
+// StopSellRequest 表示商品停售开关请求
+type StopSellRequest struct {
+	Stopped bool   `json:"stopped"`                            // true 停售，false 恢复销售
+	Reason  string `json:"reason,omitempty" binding:"max=255"` // 停售原因，记录在日志中
+}
+
+// StopSellResult 表示商品停售开关的执行结果
+type StopSellResult struct {
+	ProductID      int64   `json:"product_id"`
+	Stopped        bool    `json:"stopped"`
+	AffectedEvents []int64 `json:"affected_events"` // 随之暂停或恢复的秒杀活动
+}
+
 // ProductListRequest 表示商品列表查询请求
 type ProductListRequest struct {
 	Page       int            `json:"page"`        // 页码，从1开始
@@ -69,14 +117,16 @@ type ProductListRequest struct {
 	Keyword    *string        `json:"keyword"`     // 关键词搜索
 	SortBy     *string        `json:"sort_by"`     // 排序字段: price, created_at, name
 	SortOrder  *string        `json:"sort_order"`  // 排序顺序: asc, desc
+	SkipTotal  bool           `json:"-"`           // 跳过总数统计（include_total=false）
 }
 
 // ProductListResponse 表示商品列表查询响应
 type ProductListResponse struct {
 	Products []*Product `json:"products"`  // 商品列表
-	Total    int64      `json:"total"`     // 总商品数
+	Total    int64      `json:"total"`     // 总商品数，跳过统计时为 -1
 	Page     int        `json:"page"`      // 当前页码
 	PageSize int        `json:"page_size"` // 每页大小
+	PageInfo
 }
 
 // ProductWithInventory 表示带库存信息的商品
@@ -84,3 +134,24 @@ type ProductWithInventory struct {
 	*Product
 	Inventory *Inventory `json:"inventory"`
 }
+
+// 商品详情中秒杀活动的状态
+const (
+	ProductSpikeStateActive   = "active"   // 进行中
+	ProductSpikeStateUpcoming = "upcoming" // 即将开始（已对外可见）
+)
+
+// ProductDetail 表示商品详情页聚合数据：商品、库存可用性与当前或即将开始的秒杀活动
+type ProductDetail struct {
+	Product        *Product          `json:"product"`
+	Available      bool              `json:"available"`       // 是否有可售库存
+	AvailableStock int               `json:"available_stock"` // 可售库存（库存 - 预留）
+	Spike          *ProductSpikeInfo `json:"spike,omitempty"` // 无进行中或即将开始的活动时省略
+}
+
+// ProductSpikeInfo 表示商品详情中的秒杀活动摘要
+type ProductSpikeInfo struct {
+	Event          *SpikeEvent `json:"event"`
+	State          string      `json:"state"`           // active 或 upcoming
+	RemainingStock int64       `json:"remaining_stock"` // 按已落库销量计算，可能略滞后
+}
@@ -0,0 +1,58 @@
+// Package domain 定义商品规格（SKU）相关的领域模型。
+package domain
+
+import "time"
+
+// 商品规格相关错误
+var (
+	ErrProductVariantNotFound = NewNotFoundError("商品规格不存在")
+	// ErrProductVariantMismatch 规格不属于指定的商品
+	ErrProductVariantMismatch = NewInvalidArgumentError("商品规格不属于该商品")
+)
+
+// ProductVariant 表示商品规格：同一商品下按尺码、颜色等属性区分的 SKU，独立定价并独立管理库存
+type ProductVariant struct {
+	ID         int64             `json:"id"`
+	ProductID  int64             `json:"product_id"`
+	SKU        string            `json:"sku"`
+	Name       string            `json:"name"`                 // 规格名称，如 红色 / XL
+	Attributes map[string]string `json:"attributes,omitempty"` // 规格属性，如 {"color":"red","size":"XL"}
+	Price      float64           `json:"price"`
+	Stock      int               `json:"stock"`      // 当前可售库存
+	SoldStock  int               `json:"sold_stock"` // 已售库存
+	Active     bool              `json:"active"`     // 停用的规格不能被新的秒杀活动引用
+	Version    int               `json:"version"`    // 乐观锁版本号
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// DisplayName 返回订单明细中展示的商品名称：商品名称后附规格名称
+func (v *ProductVariant) DisplayName(productName string) string {
+	if productName == "" {
+		return v.Name
+	}
+	return productName + " " + v.Name
+}
+
+// CreateProductVariantRequest 表示创建商品规格请求
+type CreateProductVariantRequest struct {
+	SKU        string            `json:"sku" binding:"required,min=1,max=100"`
+	Name       string            `json:"name" binding:"required,min=1,max=255"`
+	Attributes map[string]string `json:"attributes"`
+	Price      float64           `json:"price" binding:"required,gt=0"`
+	Stock      int               `json:"stock" binding:"gte=0"`
+}
+
+// UpdateProductVariantRequest 表示更新商品规格请求，库存通过调整接口修改
+type UpdateProductVariantRequest struct {
+	Name       *string            `json:"name" binding:"omitempty,min=1,max=255"`
+	Attributes *map[string]string `json:"attributes"`
+	Price      *float64           `json:"price" binding:"omitempty,gt=0"`
+	Active     *bool              `json:"active"`
+}
+
+// AdjustVariantStockRequest 表示调整商品规格库存请求
+type AdjustVariantStockRequest struct {
+	Quantity int    `json:"quantity" binding:"required,ne=0"` // 正数为入库，负数为出库
+	Reason   string `json:"reason" binding:"max=255"`
+}
@@ -0,0 +1,78 @@
+// Package domain 定义秒杀专场（多个秒杀活动的分组）相关的领域模型。
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// 秒杀专场相关错误
+var (
+	ErrSpikeCampaignNotFound = NewNotFoundError("秒杀专场不存在")
+	// ErrSpikeCampaignInvalidPeriod 专场结束时间不晚于开始时间
+	ErrSpikeCampaignInvalidPeriod = errors.New("专场结束时间必须晚于开始时间")
+)
+
+// SpikeCampaign 表示秒杀专场：将多个秒杀活动归为一组，
+// 支持专场维度的报表和跨活动的单用户购买次数上限（如"本专场所有活动限购 1 次"）
+type SpikeCampaign struct {
+	ID                  int64     `json:"id"`
+	Name                string    `json:"name"`
+	Description         string    `json:"description"`
+	MaxPurchasesPerUser int64     `json:"max_purchases_per_user"` // 单用户在专场内的最大购买次数，0 表示不限制
+	StartAt             time.Time `json:"start_at"`
+	EndAt               time.Time `json:"end_at"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// HasQuota 判断专场是否设置了跨活动购买次数上限
+func (c *SpikeCampaign) HasQuota() bool {
+	return c.MaxPurchasesPerUser > 0
+}
+
+// CreateSpikeCampaignRequest 表示创建秒杀专场请求
+type CreateSpikeCampaignRequest struct {
+	Name                string    `json:"name" binding:"required,min=1,max=255"`
+	Description         string    `json:"description" binding:"max=1000"`
+	MaxPurchasesPerUser int64     `json:"max_purchases_per_user" binding:"min=0,max=100"`
+	StartAt             time.Time `json:"start_at" binding:"required"`
+	EndAt               time.Time `json:"end_at" binding:"required"`
+}
+
+// AssignSpikeCampaignEventsRequest 表示将秒杀活动加入专场的请求
+type AssignSpikeCampaignEventsRequest struct {
+	EventIDs []int64 `json:"event_ids" binding:"required,min=1,max=100"`
+}
+
+// AssignSpikeCampaignEventsResult 表示将活动加入专场的结果
+type AssignSpikeCampaignEventsResult struct {
+	SpikeCampaignID int64 `json:"spike_campaign_id"`
+	Assigned        int64 `json:"assigned"` // 实际更新的活动数（已属于本专场的活动不计入）
+}
+
+// SpikeCampaignEventStats 表示专场内单个活动的销售统计，只统计待支付和已支付订单
+type SpikeCampaignEventStats struct {
+	SpikeEventID int64   `json:"spike_event_id"`
+	Name         string  `json:"name"`
+	SpikeStock   int64   `json:"spike_stock"`
+	SoldCount    int64   `json:"sold_count"`
+	Orders       int64   `json:"orders"`        // 订单数
+	PaidOrders   int64   `json:"paid_orders"`   // 已支付订单数
+	PaidQuantity int64   `json:"paid_quantity"` // 已支付件数
+	Revenue      float64 `json:"revenue"`       // 已支付金额
+	Buyers       int64   `json:"buyers"`        // 下单用户数
+}
+
+// SpikeCampaignReport 表示秒杀专场报表
+type SpikeCampaignReport struct {
+	Campaign     *SpikeCampaign             `json:"campaign"`
+	Events       []*SpikeCampaignEventStats `json:"events"`
+	SpikeStock   int64                      `json:"spike_stock"`
+	SoldCount    int64                      `json:"sold_count"`
+	Orders       int64                      `json:"orders"`
+	PaidOrders   int64                      `json:"paid_orders"`
+	PaidQuantity int64                      `json:"paid_quantity"`
+	Revenue      float64                    `json:"revenue"`
+	UniqueBuyers int64                      `json:"unique_buyers"` // 专场去重后的下单用户数（同一用户在多个活动下单只计一次）
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// SpikeChallengeAlgorithmSHA256PoW 基于 SHA-256 的工作量证明挑战
+const SpikeChallengeAlgorithmSHA256PoW = "sha256-pow"
+
+// SpikeChallenge 表示参与秒杀前需要完成的防刷挑战。
+// 客户端寻找 nonce 使 SHA-256(challenge_id + nonce) 的前 difficulty 个二进制位为 0，
+// 再以 "<challenge_id>.<nonce>" 作为挑战令牌随参与请求提交；挑战与用户、活动绑定，只能使用一次
+type SpikeChallenge struct {
+	ChallengeID  string    `json:"challenge_id"`
+	SpikeEventID int64     `json:"spike_event_id"`
+	Algorithm    string    `json:"algorithm"`
+	Difficulty   int       `json:"difficulty"` // 哈希需要的前导零位数
+	ExpiresAt    time.Time `json:"expires_at"`
+}
@@ -0,0 +1,38 @@
+// Package domain 定义管理后台秒杀总览相关的领域模型。
+package domain
+
+import "time"
+
+// SpikeDashboardOrderStats 表示某一状态的秒杀订单汇总
+type SpikeDashboardOrderStats struct {
+	Status   SpikeOrderStatus `json:"status"`
+	Orders   int64            `json:"orders"`   // 订单数
+	Quantity int64            `json:"quantity"` // 件数
+	Amount   float64          `json:"amount"`   // 订单金额
+}
+
+// SpikeDashboardEvent 表示总览中按已售数量排名的活动
+type SpikeDashboardEvent struct {
+	SpikeEventID int64            `json:"spike_event_id"`
+	Name         string           `json:"name"`
+	Status       SpikeEventStatus `json:"status"`
+	SpikeStock   int64            `json:"spike_stock"`
+	SoldCount    int64            `json:"sold_count"`
+	SellThrough  float64          `json:"sell_through"` // 已售数量占秒杀库存的百分比
+	PaidOrders   int64            `json:"paid_orders"`  // 已支付订单数
+	Revenue      float64          `json:"revenue"`      // 已支付金额
+}
+
+// SpikeDashboard 表示跨活动的秒杀总览，数据按短时缓存，GeneratedAt 为统计时间
+type SpikeDashboard struct {
+	TotalEvents    int64                       `json:"total_events"`
+	ActiveEvents   int64                       `json:"active_events"`    // 进行中的活动数
+	EventsByStatus map[SpikeEventStatus]int64  `json:"events_by_status"` // 各状态活动数，未出现的状态为 0
+	TotalOrders    int64                       `json:"total_orders"`
+	OrdersByStatus []*SpikeDashboardOrderStats `json:"orders_by_status"` // 按待支付、已支付、已取消、已过期排列
+	PaidQuantity   int64                       `json:"paid_quantity"`
+	Revenue        float64                     `json:"revenue"`         // 已支付金额
+	ConversionRate float64                     `json:"conversion_rate"` // 已支付订单占全部订单的百分比
+	TopEvents      []*SpikeDashboardEvent      `json:"top_events"`      // 按已售数量降序
+	GeneratedAt    time.Time                   `json:"generated_at"`
+}
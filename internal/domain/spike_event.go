@@ -3,39 +3,72 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
 // 常用错误
 var (
-	ErrSpikeEventNotFound = errors.New("秒杀活动不存在")
+	ErrSpikeEventNotFound = NewNotFoundError("秒杀活动不存在")
+	// ErrSpikeEventNotRestockable 活动已结束或已取消，不能补充库存
+	ErrSpikeEventNotRestockable = errors.New("活动已结束或已取消，不能补充库存")
+	// ErrSpikeStockBusy 活动库存正在恢复或补充，暂不能调整
+	ErrSpikeStockBusy = errors.New("活动库存正在调整，请稍后重试")
+	// ErrSpikeStockAlreadyWarmed 进行中活动的库存键已存在，重新预热会覆盖已扣减的库存
+	ErrSpikeStockAlreadyWarmed = errors.New("活动进行中且库存已预热，如需覆盖请强制预热")
+	// ErrSpikeEventNotPausable 只有未结束的待开始或进行中活动可以暂停
+	ErrSpikeEventNotPausable = NewConflictError("只有未结束的待开始或进行中活动可以暂停")
+	// ErrSpikeEventNotResumable 只有未结束的已暂停活动可以恢复
+	ErrSpikeEventNotResumable = NewConflictError("只有未结束的已暂停活动可以恢复")
+	// ErrSpikeEventStatusChanged 活动状态已被并发修改
+	ErrSpikeEventStatusChanged = NewConflictError("活动状态已变化，请刷新后重试")
+	// ErrSpikeEventNotDraft 只有草稿活动可以设定发布时间
+	ErrSpikeEventNotDraft = NewConflictError("只有草稿活动可以设定发布时间")
+	// ErrSpikeEventNotScheduled 只有待发布活动可以审批或撤回
+	ErrSpikeEventNotScheduled = NewConflictError("只有待发布活动可以审批或撤回")
+	// ErrSpikeEventSelfApproval 设定发布时间的管理员不能审批自己的发布计划
+	ErrSpikeEventSelfApproval = NewConflictError("排期与审批须由不同管理员完成")
+	// ErrSpikeEventAlreadyApproved 发布计划已被审批
+	ErrSpikeEventAlreadyApproved = NewConflictError("发布计划已审批")
 )
 
 // SpikeEventStatus 定义秒杀活动状态类型
 type SpikeEventStatus string
 
 const (
+	SpikeEventStatusDraft     SpikeEventStatus = "draft"     // 草稿，只对管理员可见
+	SpikeEventStatusScheduled SpikeEventStatus = "scheduled" // 待发布，审批通过后到发布时间自动转为待开始
 	SpikeEventStatusPending   SpikeEventStatus = "pending"   // 待开始
 	SpikeEventStatusActive    SpikeEventStatus = "active"    // 进行中
+	SpikeEventStatusPaused    SpikeEventStatus = "paused"    // 已暂停，管理员恢复前不可参与
 	SpikeEventStatusEnded     SpikeEventStatus = "ended"     // 已结束
 	SpikeEventStatusCancelled SpikeEventStatus = "cancelled" // 已取消
 )
 
 // SpikeEvent 表示秒杀活动领域模型
 type SpikeEvent struct {
-	ID            int64            `json:"id"`
-	ProductID     int64            `json:"product_id"`
-	Name          string           `json:"name"`
-	Description   string           `json:"description"`
-	SpikePrice    float64          `json:"spike_price"`
-	OriginalPrice float64          `json:"original_price"`
-	SpikeStock    int64            `json:"spike_stock"`
-	SoldCount     int64            `json:"sold_count"`
-	StartAt       time.Time        `json:"start_at"`
-	EndAt         time.Time        `json:"end_at"`
-	Status        SpikeEventStatus `json:"status"`
-	CreatedAt     time.Time        `json:"created_at"`
-	UpdatedAt     time.Time        `json:"updated_at"`
+	ID                int64            `json:"id"`
+	ProductID         int64            `json:"product_id"`
+	VariantID         *int64           `json:"variant_id,omitempty"` // 秒杀的商品规格，为空表示按商品整体库存秒杀
+	Name              string           `json:"name"`
+	Description       string           `json:"description"`
+	SpikePrice        float64          `json:"spike_price"`
+	OriginalPrice     float64          `json:"original_price"`
+	SpikeStock        int64            `json:"spike_stock"`
+	SoldCount         int64            `json:"sold_count"`
+	PreviewStartAt    *time.Time       `json:"preview_start_at,omitempty"`  // 预告开始时间，为空表示无预告期
+	SpikeCampaignID   *int64           `json:"spike_campaign_id,omitempty"` // 所属秒杀专场，为空表示不属于任何专场
+	MaxPerUser        int64            `json:"max_per_user"`                // 单用户在本活动中最多购买的件数，可分多次购买；0 表示使用默认上限且只能参与一次
+	QPSLimit          int64            `json:"qps_limit"`                   // 本活动参与请求的每秒上限，0 表示不单独限流
+	StockBuckets      int              `json:"stock_buckets"`               // Redis 库存分桶数，0 或 1 表示单键库存
+	ChallengeRequired bool             `json:"challenge_required"`          // 参与请求是否必须携带并消耗一个有效的防刷挑战
+	WaitingRoomRate   int64            `json:"waiting_room_rate"`           // 排队模式每秒放行人数，0 表示不排队
+	StartAt           time.Time        `json:"start_at"`
+	EndAt             time.Time        `json:"end_at"`
+	Status            SpikeEventStatus `json:"status"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	DeletedAt         *time.Time       `json:"deleted_at,omitempty"` // 软删除时间，未删除为空；只有 IncludeDeleted 查询会返回已删除的活动
 }
 
 // IsActive 判断秒杀活动是否正在进行
@@ -46,11 +79,60 @@ func (s *SpikeEvent) IsActive() bool {
 		now.Before(s.EndAt)
 }
 
+// IsOpenFor 判断活动对享有提前参与窗口的用户是否已开放：
+// earlyAccess 为 0 时等价于 IsActive，否则允许在开始前 earlyAccess 时间内参与
+func (s *SpikeEvent) IsOpenFor(earlyAccess time.Duration) bool {
+	now := time.Now()
+	return s.Status == SpikeEventStatusActive &&
+		now.After(s.StartAt.Add(-earlyAccess)) &&
+		now.Before(s.EndAt)
+}
+
+// IsUnpublished 判断活动是否尚未发布（草稿或待发布），未发布的活动只对管理员可见
+func (s *SpikeEvent) IsUnpublished() bool {
+	return s.Status == SpikeEventStatusDraft || s.Status == SpikeEventStatusScheduled
+}
+
+// IsVisible 判断活动详情是否对外可见：未发布的活动不可见，设置了预告时间的活动在预告开始前不可见
+func (s *SpikeEvent) IsVisible() bool {
+	if s.IsUnpublished() {
+		return false
+	}
+	return s.PreviewStartAt == nil || !time.Now().Before(*s.PreviewStartAt)
+}
+
+// IsInPreview 判断活动是否处于预告期（预告已开始但活动尚未开始）
+func (s *SpikeEvent) IsInPreview() bool {
+	if s.PreviewStartAt == nil {
+		return false
+	}
+	if s.Status != SpikeEventStatusPending && s.Status != SpikeEventStatusActive {
+		return false
+	}
+	now := time.Now()
+	return !now.Before(*s.PreviewStartAt) && now.Before(s.StartAt)
+}
+
+// SecondsToStart 返回距离可参与时间的秒数（向上取整），已开始时返回 0；
+// earlyAccess 为用户享有的提前参与窗口
+func (s *SpikeEvent) SecondsToStart(earlyAccess time.Duration) int64 {
+	remaining := time.Until(s.StartAt.Add(-earlyAccess))
+	if remaining <= 0 {
+		return 0
+	}
+	return int64((remaining + time.Second - 1) / time.Second)
+}
+
 // IsAvailable 判断秒杀活动是否可参与（有库存且活动中）
 func (s *SpikeEvent) IsAvailable() bool {
 	return s.IsActive() && s.SoldCount < s.SpikeStock
 }
 
+// Cursor 返回指向本活动的键集分页游标
+func (s *SpikeEvent) Cursor() ListCursor {
+	return ListCursor{CreatedAt: s.CreatedAt, ID: s.ID}
+}
+
 // GetRemainingStock 获取剩余库存
 func (s *SpikeEvent) GetRemainingStock() int64 {
 	remaining := s.SpikeStock - s.SoldCount
@@ -60,6 +142,26 @@ func (s *SpikeEvent) GetRemainingStock() int64 {
 	return remaining
 }
 
+// MaxSpikeQuantity 单次参与的购买件数上限，活动可通过 MaxPerUser 配置更低的单用户上限
+const MaxSpikeQuantity = 10
+
+// PurchaseLimit 返回单用户在本活动中最多购买的件数
+func (s *SpikeEvent) PurchaseLimit() int64 {
+	if s.MaxPerUser > 0 && s.MaxPerUser < MaxSpikeQuantity {
+		return s.MaxPerUser
+	}
+	return MaxSpikeQuantity
+}
+
+// UserQuantityLimit 返回单用户在本活动中可多次参与累计购买的件数。
+// 未配置 MaxPerUser 时返回 0，表示每个用户只能参与一次
+func (s *SpikeEvent) UserQuantityLimit() int64 {
+	if s.MaxPerUser <= 0 {
+		return 0
+	}
+	return s.PurchaseLimit()
+}
+
 // GetDiscountPercentage 获取折扣百分比
 func (s *SpikeEvent) GetDiscountPercentage() float64 {
 	if s.OriginalPrice <= 0 {
@@ -79,51 +181,252 @@ func (s *SpikeEvent) CanEnd() bool {
 		(time.Now().After(s.EndAt) || s.SoldCount >= s.SpikeStock)
 }
 
+// IsFinished 判断活动是否已结束，已结束、已取消或已过结束时间
+func (s *SpikeEvent) IsFinished() bool {
+	if s.Status == SpikeEventStatusEnded || s.Status == SpikeEventStatusCancelled {
+		return true
+	}
+	return time.Now().After(s.EndAt)
+}
+
+// ScheduledStatus 返回按活动时间应流转到的状态：待开始的活动到开始时间后进入进行中，
+// 未结束的活动（含已暂停）到结束时间后进入已结束；无需流转时返回 false
+func (s *SpikeEvent) ScheduledStatus(now time.Time) (SpikeEventStatus, bool) {
+	switch s.Status {
+	case SpikeEventStatusPending, SpikeEventStatusActive, SpikeEventStatusPaused:
+		if !now.Before(s.EndAt) {
+			return SpikeEventStatusEnded, true
+		}
+		if s.Status == SpikeEventStatusPending && !now.Before(s.StartAt) {
+			return SpikeEventStatusActive, true
+		}
+	}
+	return "", false
+}
+
+// CanPause 判断活动是否可以暂停
+func (s *SpikeEvent) CanPause(now time.Time) bool {
+	return (s.Status == SpikeEventStatusPending || s.Status == SpikeEventStatusActive) && now.Before(s.EndAt)
+}
+
+// ResumeStatus 返回已暂停活动恢复后的状态：未到开始时间恢复为待开始，否则恢复为进行中；
+// 活动未暂停或已过结束时间时返回 false
+func (s *SpikeEvent) ResumeStatus(now time.Time) (SpikeEventStatus, bool) {
+	if s.Status != SpikeEventStatusPaused || !now.Before(s.EndAt) {
+		return "", false
+	}
+	if now.Before(s.StartAt) {
+		return SpikeEventStatusPending, true
+	}
+	return SpikeEventStatusActive, true
+}
+
+// StockKeyTTL 计算库存及活动缓存键的过期时间：覆盖到活动结束后再保留 buffer，且不短于 minTTL，
+// 避免持续时间超过固定 TTL 的活动在进行中丢失库存键
+func (s *SpikeEvent) StockKeyTTL(now time.Time, minTTL, buffer time.Duration) time.Duration {
+	return max(s.EndAt.Sub(now)+buffer, minTTL)
+}
+
 // CreateSpikeEventRequest 表示创建秒杀活动请求
 type CreateSpikeEventRequest struct {
-	ProductID     int64   `json:"product_id" binding:"required,gt=0"`
-	Name          string  `json:"name" binding:"required,min=1,max=255"`
-	Description   string  `json:"description"`
-	SpikePrice    float64 `json:"spike_price" binding:"required,gt=0"`
-	OriginalPrice float64 `json:"original_price" binding:"required,gt=0"`
-	SpikeStock    int64   `json:"spike_stock" binding:"required,gt=0"`
-	StartAt       string  `json:"start_at" binding:"required"`
-	EndAt         string  `json:"end_at" binding:"required"`
+	ProductID         int64   `json:"product_id" binding:"required,gt=0"`
+	VariantID         *int64  `json:"variant_id" binding:"omitempty,gt=0"` // 可选，秒杀的商品规格，须属于 product_id 且已启用
+	Name              string  `json:"name" binding:"required,min=1,max=255"`
+	Description       string  `json:"description"`
+	SpikePrice        float64 `json:"spike_price" binding:"required,gt=0"`
+	OriginalPrice     float64 `json:"original_price" binding:"required,gt=0"`
+	SpikeStock        int64   `json:"spike_stock" binding:"required,gt=0"`
+	PreviewStartAt    *string `json:"preview_start_at"`                     // 可选，需早于 start_at
+	MaxPerUser        int64   `json:"max_per_user" binding:"gte=0,lte=10"`  // 0 表示使用默认上限
+	QPSLimit          int64   `json:"qps_limit" binding:"gte=0"`            // 0 表示不单独限流
+	StockBuckets      int     `json:"stock_buckets" binding:"gte=0,lte=64"` // 0 或 1 表示单键库存
+	ChallengeRequired bool    `json:"challenge_required"`                   // 参与前是否需要通过防刷挑战
+	WaitingRoomRate   int64   `json:"waiting_room_rate" binding:"gte=0"`    // 排队模式每秒放行人数，0 表示不排队
+	StartAt           string  `json:"start_at" binding:"required"`
+	EndAt             string  `json:"end_at" binding:"required"`
+	Draft             bool    `json:"draft"` // 是否创建为草稿，草稿须排期并经另一名管理员审批后才会发布
 }
 
 // UpdateSpikeEventRequest 表示更新秒杀活动请求
 type UpdateSpikeEventRequest struct {
-	Name          *string           `json:"name"`
-	Description   *string           `json:"description"`
-	SpikePrice    *float64          `json:"spike_price"`
-	OriginalPrice *float64          `json:"original_price"`
-	SpikeStock    *int64            `json:"spike_stock"`
-	StartAt       *string           `json:"start_at"`
-	EndAt         *string           `json:"end_at"`
-	Status        *SpikeEventStatus `json:"status"`
+	Name              *string           `json:"name"`
+	Description       *string           `json:"description"`
+	SpikePrice        *float64          `json:"spike_price"`
+	OriginalPrice     *float64          `json:"original_price"`
+	SpikeStock        *int64            `json:"spike_stock"`
+	PreviewStartAt    *string           `json:"preview_start_at"`
+	MaxPerUser        *int64            `json:"max_per_user" binding:"omitempty,gte=0,lte=10"`
+	QPSLimit          *int64            `json:"qps_limit" binding:"omitempty,gte=0"`
+	StockBuckets      *int              `json:"stock_buckets" binding:"omitempty,gte=0,lte=64"`
+	ChallengeRequired *bool             `json:"challenge_required"`
+	WaitingRoomRate   *int64            `json:"waiting_room_rate" binding:"omitempty,gte=0"`
+	StartAt           *string           `json:"start_at"`
+	EndAt             *string           `json:"end_at"`
+	Status            *SpikeEventStatus `json:"status"`
 }
 
 // SpikeEventListRequest 表示秒杀活动列表查询请求
 type SpikeEventListRequest struct {
-	Page      int               `json:"page"`       // 页码，从1开始
-	PageSize  int               `json:"page_size"`  // 每页大小
-	ProductID *int64            `json:"product_id"` // 商品ID过滤
-	Status    *SpikeEventStatus `json:"status"`     // 状态过滤
-	Active    *bool             `json:"active"`     // 是否只查询活跃的活动
-	SortBy    *string           `json:"sort_by"`    // 排序字段: start_at, created_at, spike_price
-	SortOrder *string           `json:"sort_order"` // 排序顺序: asc, desc
+	Page           int               `json:"page"`       // 页码，从1开始
+	PageSize       int               `json:"page_size"`  // 每页大小
+	ProductID      *int64            `json:"product_id"` // 商品ID过滤
+	Status         *SpikeEventStatus `json:"status"`     // 状态过滤
+	Active         *bool             `json:"active"`     // 是否只查询活跃的活动
+	SortBy         *string           `json:"sort_by"`    // 排序字段: start_at, created_at, spike_price
+	SortOrder      *string           `json:"sort_order"` // 排序顺序: asc, desc
+	SkipTotal      bool              `json:"-"`          // 跳过总数统计（include_total=false）
+	IncludeDeleted bool              `json:"-"`          // 包含已软删除的活动（include_deleted=true），仅管理端使用
+	Unpublished    bool              `json:"-"`          // 只查询草稿与待发布的活动，仅管理端草稿列表使用
+	After          *ListCursor       `json:"-"`          // 键集分页游标（cursor），设置后忽略 page 且不统计总数
+}
+
+// KeysetOrdered 判断列表是否按 created_at、id 排序，只有此时可使用键集分页游标
+func (r *SpikeEventListRequest) KeysetOrdered() bool {
+	return r.SortBy == nil || *r.SortBy == "" || *r.SortBy == "created_at"
+}
+
+// Descending 判断列表是否按降序排序（默认）
+func (r *SpikeEventListRequest) Descending() bool {
+	return r.SortOrder == nil || !strings.EqualFold(*r.SortOrder, "asc")
 }
 
 // SpikeEventListResponse 表示秒杀活动列表查询响应
 type SpikeEventListResponse struct {
 	Events   []*SpikeEvent `json:"events"`    // 秒杀活动列表
-	Total    int64         `json:"total"`     // 总活动数
+	Total    int64         `json:"total"`     // 总活动数，跳过统计时为 -1
 	Page     int           `json:"page"`      // 当前页码
 	PageSize int           `json:"page_size"` // 每页大小
+	PageInfo
+}
+
+// SpikeEventSummary 表示秒杀活动摘要，随订单列表返回
+type SpikeEventSummary struct {
+	ID        int64            `json:"id"`
+	ProductID int64            `json:"product_id"`
+	Name      string           `json:"name"`
+	StartAt   time.Time        `json:"start_at"`
+	EndAt     time.Time        `json:"end_at"`
+	Status    SpikeEventStatus `json:"status"`
 }
 
 // SpikeEventWithProduct 表示带商品信息的秒杀活动
 type SpikeEventWithProduct struct {
 	*SpikeEvent
-	Product *Product `json:"product"`
+	Product        *Product  `json:"product"`
+	InPreview      bool      `json:"in_preview"`       // 是否处于预告期
+	SecondsToStart int64     `json:"seconds_to_start"` // 距离开始的秒数，用于客户端倒计时，已开始为 0
+	ServerTime     time.Time `json:"server_time"`      // 生成响应时的服务器时间
+	StartsInMs     int64     `json:"starts_in_ms"`     // 以 server_time 为基准距离开始的毫秒数，已开始为 0
+}
+
+// SpikeEventStatusChangeRequest 表示管理员暂停或恢复活动的请求
+type SpikeEventStatusChangeRequest struct {
+	Reason string `json:"reason" binding:"max=255"` // 操作原因，用于审计日志与通知
+}
+
+// SpikeStockTopUpRequest 表示活动进行中补充库存的请求
+type SpikeStockTopUpRequest struct {
+	Delta  int64  `json:"delta" binding:"required,gt=0,lte=1000000"` // 增加的库存数量
+	Reason string `json:"reason" binding:"max=255"`                  // 补货原因，用于审计日志与通知
+}
+
+// SpikeStockTopUpResult 表示补充库存的结果
+type SpikeStockTopUpResult struct {
+	SpikeEventID   int64 `json:"spike_event_id"`
+	Delta          int64 `json:"delta"`
+	SpikeStock     int64 `json:"spike_stock"`     // 补充后的活动总库存
+	RemainingStock int64 `json:"remaining_stock"` // 补充后 Redis 中的剩余库存，-1 表示库存尚未预热
+}
+
+// SpikeStockBuckets 库存档位数，每档对应总库存的 10%
+const SpikeStockBuckets = 10
+
+// SpikeStockBucket 计算剩余库存所在档位：0 表示无库存，1-10 按总库存的 10% 向上取整
+func SpikeStockBucket(remaining, total int64) int {
+	if remaining <= 0 {
+		return 0
+	}
+	if total <= 0 || remaining >= total {
+		return SpikeStockBuckets
+	}
+	return int((remaining*SpikeStockBuckets + total - 1) / total)
+}
+
+// SpikeStockStatus 表示活动库存状态，用于库存长轮询
+type SpikeStockStatus struct {
+	SpikeEventID   int64 `json:"spike_event_id"`
+	RemainingStock int64 `json:"remaining_stock"` // Redis 剩余库存，-1 表示库存尚未预热
+	Bucket         int   `json:"bucket"`          // 剩余库存档位 0-10
+	SoldOut        bool  `json:"sold_out"`
+	Changed        bool  `json:"changed"` // 档位或售罄状态是否已变化，超时返回时为 false
+}
+
+// SameState 判断两个库存状态的档位与售罄标记是否一致
+func (s *SpikeStockStatus) SameState(other *SpikeStockStatus) bool {
+	return s.Bucket == other.Bucket && s.SoldOut == other.SoldOut
+}
+
+// PublicSpikeEvent 表示面向匿名用户的秒杀活动只读视图，
+// 不包含已售数量、所属专场等运营字段，也不包含任何与用户相关的信息
+type PublicSpikeEvent struct {
+	ID             int64            `json:"id"`
+	ProductID      int64            `json:"product_id"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description"`
+	SpikePrice     float64          `json:"spike_price"`
+	OriginalPrice  float64          `json:"original_price"`
+	PreviewStartAt *time.Time       `json:"preview_start_at,omitempty"`
+	StartAt        time.Time        `json:"start_at"`
+	EndAt          time.Time        `json:"end_at"`
+	Status         SpikeEventStatus `json:"status"`
+}
+
+// NewPublicSpikeEvent 从秒杀活动构建匿名只读视图
+func NewPublicSpikeEvent(event *SpikeEvent) *PublicSpikeEvent {
+	return &PublicSpikeEvent{
+		ID:             event.ID,
+		ProductID:      event.ProductID,
+		Name:           event.Name,
+		Description:    event.Description,
+		SpikePrice:     event.SpikePrice,
+		OriginalPrice:  event.OriginalPrice,
+		PreviewStartAt: event.PreviewStartAt,
+		StartAt:        event.StartAt,
+		EndAt:          event.EndAt,
+		Status:         event.Status,
+	}
+}
+
+// PublicSpikeEventDetail 表示面向匿名用户的秒杀活动详情，库存只给出档位与售罄标记
+type PublicSpikeEventDetail struct {
+	*PublicSpikeEvent
+	ProductName     string `json:"product_name"`
+	ProductImageURL string `json:"product_image_url,omitempty"`
+	InPreview       bool   `json:"in_preview"`
+	StockBucket     int    `json:"stock_bucket"` // 剩余库存档位 0-10
+	SoldOut         bool   `json:"sold_out"`
+}
+
+// NewPublicSpikeEventDetail 从活动详情构建匿名只读视图
+func NewPublicSpikeEventDetail(detail *SpikeEventWithProduct) *PublicSpikeEventDetail {
+	remaining := detail.SpikeStock - detail.SoldCount
+	view := &PublicSpikeEventDetail{
+		PublicSpikeEvent: NewPublicSpikeEvent(detail.SpikeEvent),
+		InPreview:        detail.InPreview,
+		StockBucket:      SpikeStockBucket(remaining, detail.SpikeStock),
+		SoldOut:          remaining <= 0,
+	}
+	if detail.Product != nil {
+		view.ProductName = detail.Product.Name
+		view.ProductImageURL = detail.Product.ImageURL
+	}
+	return view
+}
+
+// PublicSpikeEventListResponse 表示面向匿名用户的秒杀活动列表，不统计总数
+type PublicSpikeEventListResponse struct {
+	Events   []*PublicSpikeEvent `json:"events"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	PageInfo
 }
@@ -0,0 +1,36 @@
+package domain
+
+// 批量导入/导出秒杀活动的文件格式
+const (
+	SpikeEventFileFormatJSON = "json"
+	SpikeEventFileFormatCSV  = "csv"
+)
+
+// MaxSpikeEventImportRows 单次导入的最大活动数，超出时整批拒绝
+const MaxSpikeEventImportRows = 500
+
+// SpikeEventImportColumns 导入/导出 CSV 的列，与 CreateSpikeEventRequest 的 JSON 字段同名，
+// 时间使用 RFC3339 格式，导出的文件修改后可直接重新导入
+var SpikeEventImportColumns = []string{
+	"product_id", "variant_id", "name", "description", "spike_price", "original_price", "spike_stock",
+	"preview_start_at", "max_per_user", "qps_limit", "stock_buckets", "challenge_required", "waiting_room_rate", "start_at", "end_at",
+	"draft",
+}
+
+// SpikeEventImportError 表示导入文件中某一行的校验错误
+type SpikeEventImportError struct {
+	Row     int    `json:"row"`   // 行号，从 1 开始，不含 CSV 表头
+	Field   string `json:"field"` // 出错的字段，整行错误时为空
+	Message string `json:"message"`
+}
+
+// SpikeEventImportResult 表示批量导入秒杀活动的结果。
+// 任一行校验失败时整批不创建，Errors 列出所有错误
+type SpikeEventImportResult struct {
+	DryRun   bool                    `json:"dry_run"`
+	Total    int                     `json:"total"`               // 文件中的活动数
+	Valid    int                     `json:"valid"`               // 通过校验的活动数
+	Created  int                     `json:"created"`             // 实际创建的活动数，试运行或存在错误时为 0
+	EventIDs []int64                 `json:"event_ids,omitempty"` // 创建的活动ID，与文件中的行顺序一致
+	Errors   []SpikeEventImportError `json:"errors,omitempty"`
+}
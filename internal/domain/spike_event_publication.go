@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// SpikeEventPublication 表示草稿活动的发布计划：一名管理员设定发布时间，
+// 另一名管理员审批后，状态流转任务在发布时间到达时把活动置为待开始
+type SpikeEventPublication struct {
+	SpikeEventID int64      `json:"spike_event_id"`
+	PublishAt    time.Time  `json:"publish_at"`
+	ScheduledBy  int64      `json:"scheduled_by"`          // 设定发布时间的管理员ID
+	ApprovedBy   *int64     `json:"approved_by,omitempty"` // 审批的管理员ID，未审批为空
+	ApprovedAt   *time.Time `json:"approved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsApproved 判断发布计划是否已审批
+func (p *SpikeEventPublication) IsApproved() bool {
+	return p.ApprovedBy != nil
+}
+
+// ScheduleSpikeEventRequest 表示为草稿活动设定发布时间的请求
+type ScheduleSpikeEventRequest struct {
+	PublishAt string `json:"publish_at" binding:"required"` // RFC3339 格式，需早于活动结束时间；已过去的时间表示审批后立即发布
+}
+
+// SpikeEventDraft 表示管理端草稿列表中的活动，待发布的活动附带发布计划
+type SpikeEventDraft struct {
+	*SpikeEvent
+	Publication *SpikeEventPublication `json:"publication,omitempty"`
+}
+
+// SpikeEventDraftListResponse 表示草稿与待发布活动列表响应
+type SpikeEventDraftListResponse struct {
+	Events   []*SpikeEventDraft `json:"events"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	PageInfo
+}
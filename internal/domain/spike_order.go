@@ -3,12 +3,27 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
 // 常用错误
 var (
-	ErrSpikeOrderNotFound = errors.New("秒杀订单不存在")
+	ErrSpikeOrderNotFound = NewNotFoundError("秒杀订单不存在")
+	// ErrSpikeOrderDuplicate 只允许参与一次的活动中用户已有订单，由数据库唯一约束检出
+	ErrSpikeOrderDuplicate = NewConflictError("用户已在该活动下单")
+	// ErrSpikeOrderNotExtendable 订单不是未过期的待支付状态，不能延长支付时间
+	ErrSpikeOrderNotExtendable = errors.New("订单当前状态不允许延长支付时间")
+	// ErrSpikeOrderAlreadyExtended 订单已延长过支付时间
+	ErrSpikeOrderAlreadyExtended = errors.New("订单已延长过支付时间")
+	// ErrSpikeOrderNotCancellable 订单不是待支付或已过期状态，不能取消
+	ErrSpikeOrderNotCancellable = errors.New("订单当前状态不允许取消")
+	// ErrSpikeOrderNotPayable 订单不是未过期的待支付状态，不能发起支付
+	ErrSpikeOrderNotPayable = errors.New("订单当前状态不允许支付")
+	// ErrPaymentMethodUnsupported 支付方式不是当前支付渠道，或渠道不支持一步扣款
+	ErrPaymentMethodUnsupported = errors.New("不支持的支付方式")
+	// ErrSpikeOrderPaymentDeclined 支付渠道拒绝授权或扣款
+	ErrSpikeOrderPaymentDeclined = errors.New("支付被拒绝")
 )
 
 // SpikeOrderStatus 定义秒杀订单状态类型
@@ -21,9 +36,35 @@ const (
 	SpikeOrderStatusExpired   SpikeOrderStatus = "expired"   // 已过期
 )
 
+// SpikeOrderChannel 定义下单渠道
+type SpikeOrderChannel string
+
+const (
+	SpikeOrderChannelApp SpikeOrderChannel = "app" // 移动应用
+	SpikeOrderChannelWeb SpikeOrderChannel = "web" // 网页
+	SpikeOrderChannelAPI SpikeOrderChannel = "api" // 开放接口或未声明渠道的客户端
+)
+
+// MaxUserAgentLength 订单记录的 User-Agent 最大长度，超出部分截断
+const MaxUserAgentLength = 512
+
+// IsValid 判断下单渠道是否有效
+func (c SpikeOrderChannel) IsValid() bool {
+	return spikeOrderChannelEnum.valid(c)
+}
+
+// OrDefault 未声明渠道的请求视为 api
+func (c SpikeOrderChannel) OrDefault() SpikeOrderChannel {
+	if c == "" {
+		return SpikeOrderChannelAPI
+	}
+	return c
+}
+
 // SpikeOrder 表示秒杀订单领域模型
 type SpikeOrder struct {
 	ID             int64            `json:"id"`
+	OrderNo        string           `json:"order_no"` // 对外展示的订单号，参与成功时预先生成
 	SpikeEventID   int64            `json:"spike_event_id"`
 	UserID         int64            `json:"user_id"`
 	OrderID        *int64           `json:"order_id"`
@@ -34,9 +75,25 @@ type SpikeOrder struct {
 	IdempotencyKey string           `json:"idempotency_key"`
 	ExpireAt       *time.Time       `json:"expire_at"`
 	PaidAt         *time.Time       `json:"paid_at"`
+	PaymentRef     string           `json:"payment_ref,omitempty"` // 支付渠道交易ID，未支付或历史订单为空
 	CancelledAt    *time.Time       `json:"cancelled_at"`
 	CreatedAt      time.Time        `json:"created_at"`
 	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      *time.Time       `json:"deleted_at,omitempty"` // 软删除时间，未删除为空；只有 IncludeDeleted 查询会返回已删除的订单
+
+	// 参与时的客户端信息，历史订单为空；只在管理端视图（订单时间线、活动报表）中展示
+	ClientIP  string            `json:"-"`
+	UserAgent string            `json:"-"`
+	Channel   SpikeOrderChannel `json:"-"`
+
+	// SinglePurchase 订单所属活动只允许每个用户参与一次（未配置 MaxPerUser），
+	// 落库时由唯一约束保证同一用户在该活动下只有一笔订单；只在创建时使用，查询不回填
+	SinglePurchase bool `json:"-"`
+}
+
+// Cursor 返回指向本订单的键集分页游标
+func (s *SpikeOrder) Cursor() ListCursor {
+	return ListCursor{CreatedAt: s.CreatedAt, ID: s.ID}
 }
 
 // IsPending 判断订单是否为待支付状态
@@ -96,32 +153,70 @@ type CreateSpikeOrderRequest struct {
 
 // PaySpikeOrderRequest 表示支付秒杀订单请求
 type PaySpikeOrderRequest struct {
-	PaymentMethod string `json:"payment_method" binding:"required"`
-	PaymentInfo   string `json:"payment_info"`
+	PaymentMethod string `json:"payment_method" binding:"required"`       // 支付渠道名称，须与服务配置的渠道一致
+	PaymentInfo   string `json:"payment_info" binding:"required,max=512"` // 客户端在渠道侧取得的支付凭证
 }
 
 // CancelSpikeOrderRequest 表示取消秒杀订单请求
 type CancelSpikeOrderRequest struct {
 	Reason string `json:"reason"`
+	Actor  string `json:"-"` // 操作者标识，由处理器根据身份填充，为空时视为用户本人
+}
+
+// ExtendSpikeOrderResponse 表示延长支付时间的结果
+type ExtendSpikeOrderResponse struct {
+	SpikeOrderID     int64     `json:"spike_order_id"`
+	ExpireAt         time.Time `json:"expire_at"`          // 延长后的过期时间
+	ExpiresInSeconds int64     `json:"expires_in_seconds"` // 延长后距离过期的剩余秒数
+}
+
+// SpikeOrderLookupRequest 表示客服按订单号、客户端幂等键或支付流水号精确查找订单的请求，至少提供一项；
+// 同时提供多项时须指向同一订单
+type SpikeOrderLookupRequest struct {
+	OrderNo        string `json:"order_no,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	PaymentRef     string `json:"payment_ref,omitempty"`
+}
+
+// SpikeOrderLookupResponse 表示订单查找结果
+type SpikeOrderLookupResponse struct {
+	Order    *SpikeOrder         `json:"order"`
+	Timeline *SpikeOrderTimeline `json:"timeline"`
 }
 
 // SpikeOrderListRequest 表示秒杀订单列表查询请求
 type SpikeOrderListRequest struct {
-	Page         int               `json:"page"`           // 页码，从1开始
-	PageSize     int               `json:"page_size"`      // 每页大小
-	UserID       *int64            `json:"user_id"`        // 用户ID过滤
-	SpikeEventID *int64            `json:"spike_event_id"` // 秒杀活动ID过滤
-	Status       *SpikeOrderStatus `json:"status"`         // 状态过滤
-	SortBy       *string           `json:"sort_by"`        // 排序字段: created_at, total_amount
-	SortOrder    *string           `json:"sort_order"`     // 排序顺序: asc, desc
+	Page           int               `json:"page"`           // 页码，从1开始
+	PageSize       int               `json:"page_size"`      // 每页大小
+	UserID         *int64            `json:"user_id"`        // 用户ID过滤
+	SpikeEventID   *int64            `json:"spike_event_id"` // 秒杀活动ID过滤
+	Status         *SpikeOrderStatus `json:"status"`         // 状态过滤
+	From           *time.Time        `json:"from"`           // 创建时间起（含）
+	To             *time.Time        `json:"to"`             // 创建时间止（不含）
+	SortBy         *string           `json:"sort_by"`        // 排序字段: created_at, total_amount
+	SortOrder      *string           `json:"sort_order"`     // 排序顺序: asc, desc
+	SkipTotal      bool              `json:"-"`              // 跳过总数统计（include_total=false）
+	IncludeDeleted bool              `json:"-"`              // 包含已软删除的订单（include_deleted=true），仅管理端使用
+	After          *ListCursor       `json:"-"`              // 键集分页游标（cursor），设置后忽略 page 且不统计总数
+}
+
+// KeysetOrdered 判断列表是否按 created_at、id 排序，只有此时可使用键集分页游标
+func (r *SpikeOrderListRequest) KeysetOrdered() bool {
+	return r.SortBy == nil || *r.SortBy == "" || *r.SortBy == "created_at"
+}
+
+// Descending 判断列表是否按降序排序（默认）
+func (r *SpikeOrderListRequest) Descending() bool {
+	return r.SortOrder == nil || !strings.EqualFold(*r.SortOrder, "asc")
 }
 
 // SpikeOrderListResponse 表示秒杀订单列表查询响应
 type SpikeOrderListResponse struct {
 	Orders   []*SpikeOrder `json:"orders"`    // 秒杀订单列表
-	Total    int64         `json:"total"`     // 总订单数
+	Total    int64         `json:"total"`     // 总订单数，跳过统计时为 -1
 	Page     int           `json:"page"`      // 当前页码
 	PageSize int           `json:"page_size"` // 每页大小
+	PageInfo
 }
 
 // SpikeOrderWithDetails 表示带详细信息的秒杀订单
@@ -129,20 +224,115 @@ type SpikeOrderWithDetails struct {
 	*SpikeOrder
 	SpikeEvent *SpikeEvent `json:"spike_event"`
 	User       *User       `json:"user"`
+	// ExpiresInSeconds 待支付订单距离过期的剩余秒数，其他状态不返回
+	ExpiresInSeconds *int64 `json:"expires_in_seconds,omitempty"`
+}
+
+// SpikeOrderWithEvent 表示附带活动摘要的秒杀订单，用于订单列表
+type SpikeOrderWithEvent struct {
+	*SpikeOrder
+	SpikeEvent *SpikeEventSummary `json:"spike_event"`
+}
+
+// SpikeOrderWithEventListResponse 表示附带活动摘要的秒杀订单列表查询响应
+type SpikeOrderWithEventListResponse struct {
+	Orders   []*SpikeOrderWithEvent `json:"orders"`    // 秒杀订单列表
+	Total    int64                  `json:"total"`     // 总订单数，跳过统计时为 -1
+	Page     int                    `json:"page"`      // 当前页码
+	PageSize int                    `json:"page_size"` // 每页大小
+	PageInfo
+}
+
+// SpikeOrderWindow 表示活动下订单的数量与下单时间范围，没有订单时 Orders 为 0 且时间为零值
+type SpikeOrderWindow struct {
+	Orders  int64
+	FirstAt time.Time // 最早下单时间
+	LastAt  time.Time // 最晚下单时间
 }
 
 // SpikeParticipationRequest 表示参与秒杀请求
 type SpikeParticipationRequest struct {
-	SpikeEventID   int64  `json:"spike_event_id" binding:"required,gt=0"`
-	Quantity       int64  `json:"quantity" binding:"required,gt=0,lte=10"`
-	IdempotencyKey string `json:"idempotency_key" binding:"required,min=1,max=64"`
+	SpikeEventID   int64    `json:"spike_event_id" binding:"required,gt=0"`
+	Quantity       int64    `json:"quantity" binding:"required,gt=0,lte=10"`
+	IdempotencyKey string   `json:"idempotency_key" binding:"required,min=1,max=64"`
+	UserTier       UserTier `json:"-"` // 由处理器根据访问令牌填充，不接受客户端传入
+	// 预发放的参与令牌，活动开启令牌流程时必填；也可通过请求头 X-Participation-Token 传入
+	ParticipationToken string `json:"participation_token,omitempty"`
+	// 防刷挑战令牌（"<challenge_id>.<nonce>"），活动要求挑战时必填；也可通过请求头 X-Challenge-Token 传入
+	ChallengeToken string `json:"challenge_token,omitempty" binding:"max=128"`
+	// 排队令牌，排队模式的活动放行后携带此令牌再次参与
+	QueueToken string `json:"queue_token,omitempty" binding:"max=128"`
+	// 下单渠道（app/web/api），也可通过请求头 X-Client-Channel 传入，缺省为 api
+	Channel SpikeOrderChannel `json:"channel,omitempty"`
+	// 客户端 IP 与 User-Agent 由处理器根据请求填充，不接受客户端传入
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+	// 召回活动ID，用户通过弃单召回通知下单时传入，用于转化跟踪
+	RecoveryCampaignID string `json:"recovery_campaign_id,omitempty" binding:"max=64"`
+	// 分享令牌，用户经分享链接到达时原样传入，用于分享归因
+	ShareToken string `json:"share_token,omitempty" binding:"max=256"`
 }
 
 // SpikeParticipationResponse 表示参与秒杀响应
 type SpikeParticipationResponse struct {
-	Success     bool        `json:"success"`
-	Message     string      `json:"message"`
-	SpikeOrder  *SpikeOrder `json:"spike_order,omitempty"`
-	QueueToken  string      `json:"queue_token,omitempty"`  // 排队令牌
-	QueueLength int64       `json:"queue_length,omitempty"` // 排队长度
+	Success        bool        `json:"success"`
+	Code           string      `json:"code,omitempty"` // 失败原因码，如 not_started
+	Message        string      `json:"message"`
+	OrderNo        string      `json:"order_no,omitempty"` // 成功时预先生成的订单号，订单异步落库后可据此查询
+	SpikeOrder     *SpikeOrder `json:"spike_order,omitempty"`
+	QueueToken     string      `json:"queue_token,omitempty"`      // 排队令牌
+	QueueLength    int64       `json:"queue_length,omitempty"`     // 排队长度
+	SecondsToStart int64       `json:"seconds_to_start,omitempty"` // 活动未开始时距离可参与的秒数
+	// 失败时客户端是否可以原样重试，以及建议的最短等待毫秒数（0 表示可立即重试或不应重试）
+	Retryable    bool  `json:"retryable"`
+	RetryAfterMs int64 `json:"retry_after_ms"`
+}
+
+// 参与秒杀失败原因码
+const (
+	SpikeParticipationCodeNotStarted          = "not_started"             // 活动未开始（含预告期）
+	SpikeParticipationCodeTokenRequired       = "token_required"          // 缺少或携带了无效的参与令牌
+	SpikeParticipationCodeChallengeRequired   = "challenge_required"      // 缺少或携带了无效的防刷挑战令牌，需重新领取挑战
+	SpikeParticipationCodeQueued              = "queued"                  // 已进入等候室排队，放行后携带排队令牌再次参与
+	SpikeParticipationCodeStockRecovering     = "stock_recovering"        // 库存恢复中（如 Redis 故障切换后），稍后重试
+	SpikeParticipationCodeBusy                = "busy"                    // 缓存不可用时的降级排队，稍后重试
+	SpikeParticipationCodeCampaignQuota       = "campaign_quota_exceeded" // 已达到专场内跨活动的购买次数上限
+	SpikeParticipationCodeDailyQuota          = "daily_quota_exceeded"    // 已达到当日跨活动的秒杀成功次数上限
+	SpikeParticipationCodePurchaseLimit       = "purchase_limit_exceeded" // 购买数量超过活动的单用户购买上限
+	SpikeParticipationCodeStopSell            = "stop_sell"               // 商品已被管理员停售
+	SpikeParticipationCodeRateLimited         = "rate_limited"            // 请求过于频繁
+	SpikeParticipationCodeInvalidRequest      = "invalid_request"         // 请求参数错误
+	SpikeParticipationCodeEventUnavailable    = "event_unavailable"       // 活动不存在、已结束、已取消或已暂停
+	SpikeParticipationCodeSoldOut             = "sold_out"                // 已售罄
+	SpikeParticipationCodeInsufficientStock   = "insufficient_stock"      // 剩余库存不足购买数量
+	SpikeParticipationCodeAlreadyParticipated = "already_participated"    // 用户已参与过该活动
+	SpikeParticipationCodeInternalError       = "internal_error"          // 系统内部错误
+)
+
+// spikeParticipationRetryAfter 可重试失败原因码的默认等待时长，未列出的原因码不可重试
+var spikeParticipationRetryAfter = map[string]time.Duration{
+	SpikeParticipationCodeRateLimited:     time.Second,
+	SpikeParticipationCodeStockRecovering: time.Second,
+	SpikeParticipationCodeInternalError:   time.Second,
+	SpikeParticipationCodeNotStarted:      0, // 实际等待时长取 SecondsToStart
+	SpikeParticipationCodeQueued:          time.Second,
+	SpikeParticipationCodeBusy:            2 * time.Second,
+}
+
+// NewSpikeParticipationFailure 创建参与失败响应，并按原因码填充重试提示
+func NewSpikeParticipationFailure(code, message string) *SpikeParticipationResponse {
+	r := &SpikeParticipationResponse{Code: code, Message: message}
+	if retryAfter, ok := spikeParticipationRetryAfter[code]; ok {
+		r.Retryable = true
+		r.RetryAfterMs = retryAfter.Milliseconds()
+	}
+	return r
+}
+
+// WithRetryAfter 以更精确的等待时长（如限流器给出的重置时间）覆盖可重试失败的默认等待时长
+func (r *SpikeParticipationResponse) WithRetryAfter(retryAfter time.Duration) *SpikeParticipationResponse {
+	if r.Retryable && retryAfter > 0 {
+		r.RetryAfterMs = retryAfter.Milliseconds()
+	}
+	return r
 }
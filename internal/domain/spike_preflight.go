@@ -0,0 +1,46 @@
+// Package domain 定义秒杀活动上线前自动检查的结果模型。
+package domain
+
+import "time"
+
+// PreflightCheckStatus 上线检查项结果
+type PreflightCheckStatus string
+
+const (
+	PreflightCheckPass PreflightCheckStatus = "pass" // 通过
+	PreflightCheckFail PreflightCheckStatus = "fail" // 未通过，上线前需处理
+	PreflightCheckSkip PreflightCheckStatus = "skip" // 本实例未配置相应数据来源，无法检查
+)
+
+// 上线检查项名称
+const (
+	PreflightEventSchedule   = "event_schedule"   // 活动未结束、未取消
+	PreflightStockWarmed     = "stock_warmed"     // Redis 库存已预热且与数据库剩余库存一致
+	PreflightEventCached     = "event_cached"     // 活动信息已缓存，参与热路径不回源数据库
+	PreflightLimiterCapacity = "limiter_capacity" // 限流配置与预期 QPS 匹配
+	PreflightConsumers       = "consumers"        // 消息消费者均在运行
+	PreflightDLQEmpty        = "dlq_empty"        // 死信队列为空
+	PreflightDBPoolHeadroom  = "db_pool_headroom" // 数据库连接池有余量
+)
+
+// PreflightRequest 上线检查请求
+type PreflightRequest struct {
+	// ExpectedQPS 预期参与请求峰值 QPS，为 0 时只校验限流配置有效，不与预期流量比较
+	ExpectedQPS int64 `json:"expected_qps" binding:"gte=0"`
+}
+
+// PreflightCheck 单个检查项的结果
+type PreflightCheck struct {
+	Name   string               `json:"name"`
+	Status PreflightCheckStatus `json:"status"`
+	Detail string               `json:"detail"`
+}
+
+// PreflightReport 上线检查清单，任一检查项未通过时 Passed 为 false
+type PreflightReport struct {
+	SpikeEventID int64             `json:"spike_event_id"`
+	ExpectedQPS  int64             `json:"expected_qps"`
+	Passed       bool              `json:"passed"`
+	Checks       []*PreflightCheck `json:"checks"`
+	CheckedAt    time.Time         `json:"checked_at"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// SpikeQuotaDefaultUserID 全体用户默认配额规则使用的用户ID
+const SpikeQuotaDefaultUserID int64 = 0
+
+// ErrSpikeUserQuotaNotFound 用户没有单独的配额规则
+var ErrSpikeUserQuotaNotFound = NewNotFoundError("用户配额规则不存在")
+
+// SpikeUserQuota 表示单用户每日秒杀成功次数上限规则，跨所有秒杀活动生效。
+// UserID 为 SpikeQuotaDefaultUserID 的规则是全体用户的默认上限，其余规则覆盖对应用户的默认上限
+type SpikeUserQuota struct {
+	UserID       int64     `json:"user_id"`
+	MaxDailyWins int64     `json:"max_daily_wins"` // 每个自然日最多秒杀成功次数，0 表示不限制
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsDefault 判断是否为全体用户的默认规则
+func (q *SpikeUserQuota) IsDefault() bool {
+	return q.UserID == SpikeQuotaDefaultUserID
+}
+
+// SetSpikeUserQuotaRequest 表示设置每日配额的请求
+type SetSpikeUserQuotaRequest struct {
+	MaxDailyWins int64 `json:"max_daily_wins" binding:"min=0,max=1000"`
+}
+
+// SpikeQuotaRules 表示当前生效的配额规则
+type SpikeQuotaRules struct {
+	Default   *SpikeUserQuota   `json:"default"`
+	Overrides []*SpikeUserQuota `json:"overrides"` // 按用户覆盖的规则，按用户ID排序
+}
+
+// SpikeUserQuotaUsage 表示用户当日的配额使用情况
+type SpikeUserQuotaUsage struct {
+	UserID       int64  `json:"user_id"`
+	Date         string `json:"date"`           // 统计日期（服务端时区），格式 YYYYMMDD
+	MaxDailyWins int64  `json:"max_daily_wins"` // 生效的上限，0 表示不限制
+	Override     bool   `json:"override"`       // 是否为用户单独设置的规则
+	Used         int64  `json:"used"`
+	Remaining    int64  `json:"remaining"` // 不限制时为 -1
+}
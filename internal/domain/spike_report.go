@@ -0,0 +1,23 @@
+// Package domain 定义秒杀活动报表相关的领域模型。
+package domain
+
+import "time"
+
+// SpikeReportStatus 定义报表生成状态
+type SpikeReportStatus string
+
+const (
+	SpikeReportStatusGenerating SpikeReportStatus = "generating" // 生成中
+	SpikeReportStatusReady      SpikeReportStatus = "ready"      // 可下载
+	SpikeReportStatusFailed     SpikeReportStatus = "failed"     // 生成失败，再次请求会重新生成
+)
+
+// SpikeReport 表示秒杀活动报表的状态及下载信息
+type SpikeReport struct {
+	SpikeEventID int64             `json:"spike_event_id"`
+	Status       SpikeReportStatus `json:"status"`
+	URL          string            `json:"url,omitempty"`        // 带签名的下载地址，仅 ready 时返回
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"` // 下载地址过期时间
+	Error        string            `json:"error,omitempty"`      // 上次生成失败原因
+	JobID        int64             `json:"job_id,omitempty"`     // 生成报表的后台任务ID，使用后台任务队列时返回
+}
@@ -0,0 +1,80 @@
+// Package domain 定义秒杀活动结算相关的领域模型。
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSettlementNotFound 结算记录不存在
+	ErrSettlementNotFound = NewNotFoundError("结算记录不存在")
+	// ErrSettlementAlreadyPaidOut 结算已打款，不能重复标记
+	ErrSettlementAlreadyPaidOut = errors.New("结算已打款")
+)
+
+// SpikeSettlementStatus 结算打款状态
+type SpikeSettlementStatus string
+
+const (
+	SpikeSettlementStatusPending SpikeSettlementStatus = "pending"  // 待打款
+	SpikeSettlementStatusPaidOut SpikeSettlementStatus = "paid_out" // 已打款
+)
+
+// IsValid 判断结算状态是否有效
+func (s SpikeSettlementStatus) IsValid() bool {
+	return spikeSettlementStatusEnum.valid(s)
+}
+
+// SpikeSettlement 表示一个活动在一个结算日内的收入结算
+type SpikeSettlement struct {
+	ID              int64                 `json:"id"`
+	SpikeEventID    int64                 `json:"spike_event_id"`
+	SettleDate      time.Time             `json:"settle_date"`
+	OrderCount      int64                 `json:"order_count"`
+	Quantity        int64                 `json:"quantity"`
+	GrossAmount     float64               `json:"gross_amount"`  // 已支付订单金额
+	RefundAmount    float64               `json:"refund_amount"` // 退款金额
+	FeeAmount       float64               `json:"fee_amount"`    // 平台手续费
+	NetAmount       float64               `json:"net_amount"`    // 应打款金额
+	Status          SpikeSettlementStatus `json:"status"`
+	PaidOutAt       *time.Time            `json:"paid_out_at"`
+	PaidOutBy       string                `json:"paid_out_by"`
+	PayoutReference string                `json:"payout_reference"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// SpikeSettlementDaily 表示活动在一个结算日内的收入与退款汇总，由订单数据聚合得到
+type SpikeSettlementDaily struct {
+	SettleDate   time.Time
+	OrderCount   int64
+	Quantity     int64
+	GrossAmount  float64
+	RefundAmount float64
+}
+
+// SpikeSettlementListRequest 表示结算查询请求
+type SpikeSettlementListRequest struct {
+	Page         int                    `json:"page"`           // 页码，从1开始
+	PageSize     int                    `json:"page_size"`      // 每页大小
+	SpikeEventID *int64                 `json:"spike_event_id"` // 活动过滤
+	Status       *SpikeSettlementStatus `json:"status"`         // 打款状态过滤
+	From         *time.Time             `json:"from"`           // 结算日起（含）
+	To           *time.Time             `json:"to"`             // 结算日止（不含）
+	SkipTotal    bool                   `json:"-"`              // 跳过总数统计（include_total=false）
+}
+
+// SpikeSettlementListResponse 表示结算查询响应
+type SpikeSettlementListResponse struct {
+	Items    []*SpikeSettlement `json:"items"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	PageInfo
+}
+
+// MarkSettlementPaidOutRequest 表示标记结算已打款的请求
+type MarkSettlementPaidOutRequest struct {
+	PayoutReference string `json:"payout_reference" binding:"max=128"` // 打款流水号
+}
@@ -0,0 +1,58 @@
+// Package domain 定义秒杀活动分享链接及其归因统计相关的领域模型。
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// 分享链接相关错误
+var (
+	// ErrShareLinkInvalid 分享链接签名或格式无效
+	ErrShareLinkInvalid = errors.New("分享链接无效")
+	// ErrShareLinkExpired 分享链接已过期
+	ErrShareLinkExpired = errors.New("分享链接已过期")
+	// ErrShareCampaignInvalid 推广渠道标识不合法
+	ErrShareCampaignInvalid = errors.New("推广渠道标识只能包含字母、数字、下划线和连字符，且不超过 64 个字符")
+)
+
+// shareCampaignPattern 推广渠道标识：出现在链接与令牌中，限制为 URL 安全且不含分隔符的字符
+var shareCampaignPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{0,64}$`)
+
+// ValidateShareCampaign 校验推广渠道标识，空字符串表示未指定渠道
+func ValidateShareCampaign(campaign string) error {
+	if !shareCampaignPattern.MatchString(campaign) {
+		return ErrShareCampaignInvalid
+	}
+	return nil
+}
+
+// CreateShareLinkRequest 表示生成分享链接请求
+type CreateShareLinkRequest struct {
+	Campaign string `json:"campaign" binding:"max=64"` // 推广渠道标识，如 wechat_moments，可为空
+}
+
+// SpikeShareLink 表示签名后的活动分享链接
+type SpikeShareLink struct {
+	SpikeEventID int64     `json:"spike_event_id"`
+	Campaign     string    `json:"campaign,omitempty"`
+	Token        string    `json:"token"` // 签名令牌，到达后通过分享链接校验接口换取活动信息并记录访问
+	URL          string    `json:"url"`   // 可直接分享的落地页地址，携带 share 参数
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// SpikeShareVisit 表示校验通过的分享链接到达
+type SpikeShareVisit struct {
+	SpikeEventID int64     `json:"spike_event_id"`
+	Campaign     string    `json:"campaign,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// SpikeShareAttribution 表示活动在某个推广渠道下的分享归因统计
+type SpikeShareAttribution struct {
+	SpikeEventID int64  `json:"spike_event_id"`
+	Campaign     string `json:"campaign"`
+	Visits       int64  `json:"visits"`       // 经分享链接到达的访问数
+	Participants int64  `json:"participants"` // 携带分享链接成功参与的次数
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// ParticipationToken 表示预发放的秒杀参与令牌。
+// 开启令牌流程的活动在开始后只处理携带有效令牌的参与请求，
+// 令牌总量按库存封顶，从而在开始前就确定首秒请求量的上限。
+type ParticipationToken struct {
+	SpikeEventID int64     `json:"spike_event_id"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
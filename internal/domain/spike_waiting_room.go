@@ -0,0 +1,11 @@
+package domain
+
+// SpikeQueueStatus 表示等候室中的排队状态
+type SpikeQueueStatus struct {
+	QueueToken   string `json:"queue_token"`
+	SpikeEventID int64  `json:"spike_event_id"`
+	Position     int64  `json:"position"` // 排队位置，1 表示下一个放行，0 表示已放行
+	Admitted     bool   `json:"admitted"` // 已放行，可携带排队令牌参与秒杀
+	// 按活动放行速率估算的剩余等待秒数
+	EstimatedWaitSeconds int64 `json:"estimated_wait_seconds"`
+}
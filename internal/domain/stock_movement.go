@@ -0,0 +1,90 @@
+// Package domain 定义库存变动流水相关的领域模型。
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// StockMovementType 定义库存变动类型
+type StockMovementType string
+
+const (
+	StockMovementReserve StockMovementType = "reserve" // 预留
+	StockMovementRelease StockMovementType = "release" // 释放预留
+	StockMovementConsume StockMovementType = "consume" // 消费预留（出库）
+	StockMovementAdjust  StockMovementType = "adjust"  // 调整（入库或出库）
+)
+
+// StockMovement 表示一条库存变动流水，与库存变更在同一事务中写入
+type StockMovement struct {
+	ID        int64             `json:"id"`
+	ProductID int64             `json:"product_id"`
+	Type      StockMovementType `json:"type"`
+	Quantity  int               `json:"quantity"`  // 变动数量，调整为带符号的调整量（正数入库、负数出库），其余为正数
+	Reason    string            `json:"reason"`    // 变动原因
+	Operator  string            `json:"operator"`  // 操作者，如 admin:1、user:2、system
+	Reference string            `json:"reference"` // 关联单据，如秒杀订单号、库存预留ID
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// StockMovementListRequest 表示库存变动流水查询请求
+type StockMovementListRequest struct {
+	Page      int                `json:"page"`       // 页码，从1开始
+	PageSize  int                `json:"page_size"`  // 每页大小
+	ProductID int64              `json:"product_id"` // 商品ID
+	Type      *StockMovementType `json:"type"`       // 变动类型过滤
+	Reference string             `json:"reference"`  // 关联单据过滤
+	SkipTotal bool               `json:"-"`          // 跳过总数统计（include_total=false）
+}
+
+// StockMovementListResponse 表示库存变动流水查询响应
+type StockMovementListResponse struct {
+	Items    []*StockMovement `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	PageInfo
+}
+
+// ParseStockMovementType 解析库存变动类型
+func ParseStockMovementType(s string) (StockMovementType, error) {
+	switch t := StockMovementType(s); t {
+	case StockMovementReserve, StockMovementRelease, StockMovementConsume, StockMovementAdjust:
+		return t, nil
+	}
+	return "", NewInvalidArgumentError("type 取值必须为 reserve、release、consume、adjust 之一")
+}
+
+// StockMovementSource 表示库存变动的来源，由调用方随 context 传给库存仓储写入流水
+type StockMovementSource struct {
+	Operator  string // 操作者，为空时记为 system
+	Reference string // 关联单据
+	Reason    string // 变动原因，调整库存时以调整请求中的原因为准
+}
+
+type stockMovementSourceKey struct{}
+
+// WithStockOperator 在 context 中记录库存变动的操作者，保留已记录的关联单据与原因
+func WithStockOperator(ctx context.Context, operator string) context.Context {
+	source := StockMovementSourceFrom(ctx)
+	source.Operator = operator
+	return context.WithValue(ctx, stockMovementSourceKey{}, source)
+}
+
+// WithStockReference 在 context 中记录库存变动的关联单据与原因，保留已记录的操作者
+func WithStockReference(ctx context.Context, reference, reason string) context.Context {
+	source := StockMovementSourceFrom(ctx)
+	source.Reference = reference
+	source.Reason = reason
+	return context.WithValue(ctx, stockMovementSourceKey{}, source)
+}
+
+// StockMovementSourceFrom 读取 context 中的库存变动来源，未记录操作者时为 system
+func StockMovementSourceFrom(ctx context.Context) StockMovementSource {
+	source, _ := ctx.Value(stockMovementSourceKey{}).(StockMovementSource)
+	if source.Operator == "" {
+		source.Operator = ActorSystem
+	}
+	return source
+}
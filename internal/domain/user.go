@@ -14,6 +14,33 @@ const (
 	UserRoleAdmin UserRole = "admin" // 管理员
 )
 
+// UserTier 定义用户等级（会员体系），等级策略见 TierPolicy
+type UserTier string
+
+const (
+	UserTierRegular  UserTier = "regular"  // 普通会员
+	UserTierSilver   UserTier = "silver"   // 白银会员
+	UserTierGold     UserTier = "gold"     // 黄金会员
+	UserTierPlatinum UserTier = "platinum" // 铂金会员
+)
+
+// IsValid 判断等级取值是否合法
+func (t UserTier) IsValid() bool {
+	switch t {
+	case UserTierRegular, UserTierSilver, UserTierGold, UserTierPlatinum:
+		return true
+	}
+	return false
+}
+
+// OrDefault 空等级（如旧令牌未携带等级）视为普通会员
+func (t UserTier) OrDefault() UserTier {
+	if t == "" {
+		return UserTierRegular
+	}
+	return t
+}
+
 // User 表示用户领域模型
 // 包含用户的基本信息和业务规则
 type User struct {
@@ -22,6 +49,7 @@ type User struct {
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"` // JSON序列化时忽略密码哈希
 	Role         UserRole  `json:"role"`
+	Tier         UserTier  `json:"tier"`
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -35,7 +63,7 @@ func (u *User) IsAdmin() bool {
 // RegisterRequest 表示用户注册请求
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=32"`
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email" binding:"required,email,max=254"`
 	Password string `json:"password" binding:"required,min=6,max=72"`
 }
 
@@ -43,6 +71,7 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	OTPCode  string `json:"otp_code"` // 已启用两步验证的账号必填，6 位动态验证码
 }
 
 // LoginResponse 表示登录成功的响应
@@ -71,11 +100,17 @@ type UserListResponse struct {
 	Total    int64   `json:"total"`     // 总用户数
 	Page     int     `json:"page"`      // 当前页码
 	PageSize int     `json:"page_size"` // 每页大小
+	PageInfo
 }
 
 // UpdateUserRoleRequest 表示更新用户角色请求
 type UpdateUserRoleRequest struct {
-	Role UserRole `json:"role" binding:"required"`
+	Role UserRole `json:"role" binding:"required,oneof=user admin"`
+}
+
+// UpdateUserTierRequest 表示更新用户等级请求
+type UpdateUserTierRequest struct {
+	Tier UserTier `json:"tier" binding:"required"`
 }
 
 // UpdateUserStatusRequest 表示更新用户状态请求
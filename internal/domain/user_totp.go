@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// UserTOTP 表示用户的 TOTP 两步验证绑定。
+// 申请绑定后 EnabledAt 为空，用户用验证器生成的验证码确认后才启用，启用后登录需同时提交验证码
+type UserTOTP struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"-"`          // Base32 编码的共享密钥
+	EnabledAt    *time.Time `json:"enabled_at"` // 启用时间，为空表示待确认
+	LastUsedStep int64      `json:"-"`          // 最近一次验证通过的时间步，同一验证码不能重复使用
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsEnabled 判断两步验证是否已启用
+func (t *UserTOTP) IsEnabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// TOTPEnrollment 表示申请绑定两步验证的响应，密钥只在此时返回一次
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`      // Base32 密钥，供无法扫码时手动输入
+	OTPAuthURL string `json:"otpauth_url"` // otpauth:// 地址，客户端生成二维码供验证器扫描
+}
+
+// TOTPCodeRequest 表示提交两步验证码的请求（确认绑定或关闭两步验证）
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
@@ -0,0 +1,156 @@
+// Package health 探测 MySQL、Redis、RabbitMQ 等外部依赖的可用性，供就绪检查使用。
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 依赖与整体状态取值
+const (
+	StatusUp       = "up"       // 依赖可用
+	StatusDown     = "down"     // 依赖不可用或探测超时
+	StatusOK       = "ok"       // 全部依赖可用
+	StatusDegraded = "degraded" // 仅非关键依赖不可用，仍可接收流量
+	StatusFailed   = "failed"   // 关键依赖不可用，应摘除流量
+	StatusDraining = "draining" // 实例正在关闭，应摘除流量
+)
+
+// DefaultTimeout 单个依赖探测的默认超时时间
+const DefaultTimeout = 2 * time.Second
+
+// PingFunc 探测一个依赖，返回 nil 表示可用
+type PingFunc func(ctx context.Context) error
+
+// DependencyStatus 单个依赖的探测结果
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`   // 关键依赖不可用时实例不就绪
+	LatencyMs float64 `json:"latency_ms"` // 探测耗时（毫秒）
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 一次就绪检查的结果
+type Report struct {
+	Status       string             `json:"status"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// Ready 实例是否可以接收流量
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK || r.Status == StatusDegraded
+}
+
+type dependency struct {
+	name     string
+	critical bool
+	ping     PingFunc
+}
+
+// Checker 并发探测已注册的依赖。依赖在启动阶段按实际初始化结果注册，
+// 未启用的组件（如未配置 RabbitMQ）不会出现在结果中
+type Checker struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	deps     []dependency
+	draining func() bool
+}
+
+// NewChecker 创建依赖检查器，timeout 为单个依赖的探测超时，非正数时使用 DefaultTimeout
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register 注册依赖，critical 为 true 时该依赖不可用会使实例不就绪
+func (c *Checker) Register(name string, critical bool, ping PingFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deps = append(c.deps, dependency{name: name, critical: critical, ping: ping})
+}
+
+// SetDraining 设置排空状态来源，排空期间就绪检查直接失败，使负载均衡尽快摘除实例；可为空
+func (c *Checker) SetDraining(draining func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = draining
+}
+
+// Check 并发探测所有依赖，结果按注册顺序排列
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mu.RLock()
+	deps := c.deps
+	draining := c.draining
+	c.mu.RUnlock()
+
+	report := &Report{
+		Status:       StatusOK,
+		CheckedAt:    time.Now(),
+		Dependencies: make([]DependencyStatus, len(deps)),
+	}
+
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Dependencies[i] = c.probe(ctx, dep)
+		}()
+	}
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		if dep.Status == StatusUp {
+			continue
+		}
+		if dep.Critical {
+			report.Status = StatusFailed
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	if draining != nil && draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+// probe 在超时内探测单个依赖；探测函数不响应 ctx 时也按超时返回
+func (c *Checker) probe(ctx context.Context, dep dependency) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- dep.ping(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := DependencyStatus{
+		Name:      dep.name,
+		Status:    StatusUp,
+		Critical:  dep.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		if errors.Is(err, context.DeadlineExceeded) {
+			status.Error = "timeout"
+		} else {
+			status.Error = err.Error()
+		}
+	}
+	return status
+}
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestChecker_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		register   func(c *Checker)
+		wantStatus string
+		wantReady  bool
+	}{
+		{
+			name: "all dependencies up",
+			register: func(c *Checker) {
+				c.Register("mysql", true, up)
+				c.Register("redis", true, up)
+			},
+			wantStatus: StatusOK,
+			wantReady:  true,
+		},
+		{
+			name: "non-critical dependency down",
+			register: func(c *Checker) {
+				c.Register("mysql", true, up)
+				c.Register("cache", false, down)
+			},
+			wantStatus: StatusDegraded,
+			wantReady:  true,
+		},
+		{
+			name: "critical dependency down",
+			register: func(c *Checker) {
+				c.Register("cache", false, down)
+				c.Register("mysql", true, down)
+			},
+			wantStatus: StatusFailed,
+			wantReady:  false,
+		},
+		{
+			name: "draining",
+			register: func(c *Checker) {
+				c.Register("mysql", true, up)
+				c.SetDraining(func() bool { return true })
+			},
+			wantStatus: StatusDraining,
+			wantReady:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(time.Second)
+			tt.register(c)
+
+			report := c.Check(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Check() status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			if report.Ready() != tt.wantReady {
+				t.Errorf("Check() ready = %v, want %v", report.Ready(), tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestChecker_Check_TimeoutAndOrder(t *testing.T) {
+	c := NewChecker(20 * time.Millisecond)
+	c.Register("mysql", true, up)
+	// 不响应 ctx 的探测也应按超时返回
+	block := make(chan struct{})
+	defer close(block)
+	c.Register("rabbitmq", true, func(context.Context) error { <-block; return nil })
+
+	report := c.Check(context.Background())
+	if len(report.Dependencies) != 2 {
+		t.Fatalf("Check() dependencies = %d, want 2", len(report.Dependencies))
+	}
+	if report.Dependencies[0].Name != "mysql" || report.Dependencies[0].Status != StatusUp {
+		t.Errorf("Check() dependencies[0] = %+v, want mysql up", report.Dependencies[0])
+	}
+	rabbit := report.Dependencies[1]
+	if rabbit.Name != "rabbitmq" || rabbit.Status != StatusDown || rabbit.Error != "timeout" {
+		t.Errorf("Check() dependencies[1] = %+v, want rabbitmq down with timeout", rabbit)
+	}
+	if report.Status != StatusFailed {
+		t.Errorf("Check() status = %q, want %q", report.Status, StatusFailed)
+	}
+}
@@ -0,0 +1,145 @@
+// Package idgen 生成全局唯一、趋势递增的业务编号（如秒杀订单号），不依赖数据库自增ID，
+// 不暴露订单量，分库分表后仍然唯一。
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 雪花ID布局：1 位符号位 + 41 位毫秒时间戳 + 10 位节点编号 + 12 位毫秒内序号
+const (
+	workerBits   = 10
+	sequenceBits = 12
+
+	// MaxWorkerID 节点编号上限
+	MaxWorkerID = 1<<workerBits - 1
+
+	maxSequence = 1<<sequenceBits - 1
+	workerShift = sequenceBits
+	timeShift   = workerBits + sequenceBits
+
+	// maxBackwardWait 时钟小幅回拨时原地等待追平的最长时长，超过则返回错误
+	maxBackwardWait = 5 * time.Millisecond
+)
+
+// Epoch 时间戳起点，41 位毫秒时间戳可用到 2093 年
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// LegacyOrderNoPrefix 历史订单回填订单号的前缀，回填值为 L + 自增ID
+const LegacyOrderNoPrefix = "L"
+
+// ErrClockMovedBackwards 系统时钟回拨超过容忍范围，继续生成可能产生重复编号
+var ErrClockMovedBackwards = errors.New("clock moved backwards")
+
+// Snowflake 雪花算法编号生成器，并发安全。多实例部署时每个实例的节点编号必须不同
+type Snowflake struct {
+	mu       sync.Mutex
+	workerID int64
+	lastMs   int64
+	sequence int64
+	now      func() time.Time
+}
+
+// NewSnowflake 创建节点编号为 workerID 的生成器，workerID 取值 0..MaxWorkerID
+func NewSnowflake(workerID int64) (*Snowflake, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("worker id must be in range 0..%d, got %d", MaxWorkerID, workerID)
+	}
+	return &Snowflake{workerID: workerID, now: time.Now}, nil
+}
+
+var (
+	defaultOnce sync.Once
+	defaultGen  *Snowflake
+)
+
+// Default 返回进程内共享的节点编号为 0 的生成器，仅适用于单实例部署与测试
+func Default() *Snowflake {
+	defaultOnce.Do(func() {
+		defaultGen, _ = NewSnowflake(0)
+	})
+	return defaultGen
+}
+
+// NextID 生成下一个编号
+func (s *Snowflake) NextID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := s.elapsedMs()
+	if ms < s.lastMs {
+		// 小幅回拨（如 NTP 校时）等待追平，回拨过大时拒绝生成
+		wait := time.Duration(s.lastMs-ms) * time.Millisecond
+		if wait > maxBackwardWait {
+			return 0, fmt.Errorf("%w by %s", ErrClockMovedBackwards, wait)
+		}
+		time.Sleep(wait)
+		ms = s.waitAfter(s.lastMs - 1)
+	}
+
+	if ms == s.lastMs {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			// 当前毫秒序号用尽，等待下一毫秒
+			ms = s.waitAfter(s.lastMs)
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMs = ms
+
+	return ms<<timeShift | s.workerID<<workerShift | s.sequence, nil
+}
+
+// NextOrderNo 生成下一个订单号（十进制编号字符串）
+func (s *Snowflake) NextOrderNo() (string, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// elapsedMs 返回距 Epoch 的毫秒数
+func (s *Snowflake) elapsedMs() int64 {
+	return s.now().Sub(Epoch).Milliseconds()
+}
+
+// waitAfter 自旋等待直到时间戳大于 ms
+func (s *Snowflake) waitAfter(ms int64) int64 {
+	now := s.elapsedMs()
+	for now <= ms {
+		time.Sleep(100 * time.Microsecond)
+		now = s.elapsedMs()
+	}
+	return now
+}
+
+// Parse 拆解编号，返回生成时间、节点编号与毫秒内序号
+func Parse(id int64) (time.Time, int64, int64) {
+	ms := id >> timeShift
+	workerID := id >> workerShift & MaxWorkerID
+	sequence := id & maxSequence
+	return Epoch.Add(time.Duration(ms) * time.Millisecond), workerID, sequence
+}
+
+// ValidOrderNo 判断字符串是否为合法格式的订单号（历史订单的 L 前缀编号也视为合法）
+func ValidOrderNo(orderNo string) bool {
+	digits := orderNo
+	if len(digits) > 1 && digits[0] == LegacyOrderNoPrefix[0] {
+		digits = digits[1:]
+	}
+	if digits == "" || len(digits) > 19 {
+		return false
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
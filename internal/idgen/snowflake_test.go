@@ -0,0 +1,92 @@
+package idgen
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSnowflake_InvalidWorkerID(t *testing.T) {
+	for _, workerID := range []int64{-1, MaxWorkerID + 1} {
+		if _, err := NewSnowflake(workerID); err == nil {
+			t.Errorf("NewSnowflake(%d) error = nil, want error", workerID)
+		}
+	}
+}
+
+func TestSnowflake_NextID_UniqueAndIncreasing(t *testing.T) {
+	gen, err := NewSnowflake(7)
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	const workers, perWorker = 8, 2000
+	ids := make(chan int64, workers*perWorker)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var last int64
+			for range perWorker {
+				id, err := gen.NextID()
+				if err != nil {
+					t.Errorf("NextID() error = %v", err)
+					return
+				}
+				if id <= last {
+					t.Errorf("NextID() = %d, want > %d", id, last)
+				}
+				last = id
+				ids <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]struct{}, workers*perWorker)
+	for id := range ids {
+		if _, ok := seen[id]; ok {
+			t.Fatalf("NextID() returned duplicate id %d", id)
+		}
+		seen[id] = struct{}{}
+		if _, workerID, _ := Parse(id); workerID != 7 {
+			t.Fatalf("Parse(%d) worker = %d, want 7", id, workerID)
+		}
+	}
+}
+
+func TestSnowflake_NextID_ClockMovedBackwards(t *testing.T) {
+	gen, _ := NewSnowflake(1)
+	now := time.Now()
+	gen.now = func() time.Time { return now }
+	if _, err := gen.NextID(); err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+
+	now = now.Add(-time.Second)
+	if _, err := gen.NextID(); !errors.Is(err, ErrClockMovedBackwards) {
+		t.Fatalf("NextID() error = %v, want ErrClockMovedBackwards", err)
+	}
+}
+
+func TestValidOrderNo(t *testing.T) {
+	tests := []struct {
+		orderNo string
+		want    bool
+	}{
+		{"123456789012345678", true},
+		{"L42", true},
+		{"", false},
+		{"L", false},
+		{"12a4", false},
+		{"12345678901234567890", false},
+	}
+	for _, tt := range tests {
+		if got := ValidOrderNo(tt.orderNo); got != tt.want {
+			t.Errorf("ValidOrderNo(%q) = %v, want %v", tt.orderNo, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package integration 包含依赖真实 MySQL、Redis 与 RabbitMQ 的端到端测试，
+// 测试文件带 integration 构建标签，默认的 go test ./... 不会运行：
+//
+//	make test-integration
+//
+// 测试启动时通过 docker 命令拉起三个临时容器并执行全部迁移，结束后删除容器；
+// 也可通过 INTEGRATION_MYSQL_ADDR、INTEGRATION_REDIS_ADDR、INTEGRATION_RABBITMQ_ADDR
+// 指向已有的服务（host:port）。既没有 docker 也没有配置地址时跳过全部测试。
+package integration
@@ -0,0 +1,212 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/config"
+	"github.com/MorseWayne/spike_shop/internal/database"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// 容器内服务的账号，与 docker run 传入的环境变量一致
+const (
+	mysqlPassword  = "root"
+	mysqlDatabase  = "spike_shop"
+	rabbitUser     = "spike"
+	rabbitPassword = "spike"
+
+	// readyTimeout 等待容器内服务就绪的最长时间
+	readyTimeout = 90 * time.Second
+)
+
+// env 测试包共享的外部依赖，由 TestMain 初始化
+var env struct {
+	db     *database.DB
+	redis  redis.UniversalClient
+	mqConf *mq.Config
+	logger *zap.Logger
+}
+
+// containers TestMain 启动的容器ID，测试结束后删除
+var containers []string
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	mysqlAddr, redisAddr, rabbitAddr, err := serviceAddrs()
+	defer removeContainers()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+		return 1
+	}
+	if mysqlAddr == "" {
+		fmt.Println("integration: docker not available and INTEGRATION_*_ADDR not set, skipping")
+		return 0
+	}
+
+	env.logger = zap.NewNop()
+	if err := connect(mysqlAddr, redisAddr, rabbitAddr); err != nil {
+		fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+		return 1
+	}
+	defer env.db.Close()
+	defer env.redis.Close()
+
+	return m.Run()
+}
+
+// serviceAddrs 返回三个服务的地址：优先使用环境变量，否则启动容器；两者都不可用时返回空地址
+func serviceAddrs() (mysqlAddr, redisAddr, rabbitAddr string, err error) {
+	mysqlAddr = os.Getenv("INTEGRATION_MYSQL_ADDR")
+	redisAddr = os.Getenv("INTEGRATION_REDIS_ADDR")
+	rabbitAddr = os.Getenv("INTEGRATION_RABBITMQ_ADDR")
+	if mysqlAddr != "" && redisAddr != "" && rabbitAddr != "" {
+		return mysqlAddr, redisAddr, rabbitAddr, nil
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", "", "", nil
+	}
+	if err := exec.Command("docker", "info").Run(); err != nil {
+		return "", "", "", nil
+	}
+
+	if mysqlAddr == "" {
+		if mysqlAddr, err = startContainer("mysql:8.0", "3306/tcp",
+			"-e", "MYSQL_ROOT_PASSWORD="+mysqlPassword, "-e", "MYSQL_DATABASE="+mysqlDatabase); err != nil {
+			return "", "", "", err
+		}
+	}
+	if redisAddr == "" {
+		if redisAddr, err = startContainer("redis:7", "6379/tcp"); err != nil {
+			return "", "", "", err
+		}
+	}
+	if rabbitAddr == "" {
+		// guest 账号只允许本机连接，经端口映射访问需要另建账号
+		if rabbitAddr, err = startContainer("rabbitmq:3", "5672/tcp",
+			"-e", "RABBITMQ_DEFAULT_USER="+rabbitUser, "-e", "RABBITMQ_DEFAULT_PASS="+rabbitPassword); err != nil {
+			return "", "", "", err
+		}
+	}
+	return mysqlAddr, redisAddr, rabbitAddr, nil
+}
+
+// startContainer 以随机主机端口启动容器，返回映射到 port 的主机地址
+func startContainer(image, port string, args ...string) (string, error) {
+	runArgs := append([]string{"run", "-d", "--rm", "-P"}, args...)
+	out, err := exec.Command("docker", append(runArgs, image)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to start %s: %w", image, err)
+	}
+	id := strings.TrimSpace(string(out))
+	containers = append(containers, id)
+
+	out, err = exec.Command("docker", "port", id, port).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s port: %w", image, err)
+	}
+	// 输出形如 "0.0.0.0:49153"，同时映射 IPv6 时有多行，取第一行的端口
+	line := strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0]
+	_, hostPort, err := net.SplitHostPort(line)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s port %q: %w", image, line, err)
+	}
+	return net.JoinHostPort("127.0.0.1", hostPort), nil
+}
+
+func removeContainers() {
+	for _, id := range containers {
+		_ = exec.Command("docker", "rm", "-f", id).Run()
+	}
+}
+
+// connect 等待各服务就绪，执行迁移并保存连接
+func connect(mysqlAddr, redisAddr, rabbitAddr string) error {
+	host, port, err := splitAddr(mysqlAddr)
+	if err != nil {
+		return err
+	}
+	cfg := &config.Config{}
+	cfg.Database.Host = host
+	cfg.Database.Port = port
+	cfg.Database.User = "root"
+	cfg.Database.Password = mysqlPassword
+	cfg.Database.DBName = mysqlDatabase
+
+	// MySQL 容器首次启动要初始化数据目录，端口可连通后仍需等待一段时间
+	if err := waitFor("mysql", func() (err error) {
+		env.db, err = database.New(cfg, env.logger)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := env.db.RunMigrations("../../migrations"); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	env.redis = cache.NewRedisClient(cache.RedisClientOptions{Addr: redisAddr})
+	if err := waitFor("redis", func() error {
+		return env.redis.Ping(context.Background()).Err()
+	}); err != nil {
+		return err
+	}
+
+	if host, port, err = splitAddr(rabbitAddr); err != nil {
+		return err
+	}
+	env.mqConf = mq.DefaultConfig()
+	env.mqConf.Host = host
+	env.mqConf.Port = port
+	env.mqConf.Username = rabbitUser
+	env.mqConf.Password = rabbitPassword
+	return waitFor("rabbitmq", func() error {
+		cm := mq.NewConnectionManager(env.mqConf, env.logger)
+		if err := cm.Connect(context.Background()); err != nil {
+			return err
+		}
+		return cm.Close()
+	})
+}
+
+// waitFor 每秒重试 fn 直到成功或超过 readyTimeout
+func waitFor(name string, fn func() error) error {
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s not ready after %s: %w", name, readyTimeout, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func splitAddr(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in %q: %w", addr, err)
+	}
+	return host, port, nil
+}
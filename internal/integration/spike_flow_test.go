@@ -0,0 +1,232 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/api"
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"github.com/MorseWayne/spike_shop/internal/service"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// persistTimeout 等待消费者把秒杀订单落库的最长时间
+const persistTimeout = 15 * time.Second
+
+// spikeStack 一次测试使用的完整秒杀链路：HTTP 处理器 → 秒杀服务 → RabbitMQ → 消费者 → MySQL
+type spikeStack struct {
+	events      repo.SpikeEventRepository
+	orders      repo.SpikeOrderRepository
+	users       repo.UserRepository
+	products    repo.ProductRepository
+	inventories repo.InventoryRepository
+	service     *service.SpikeService
+	handler     *api.SpikeHandler
+}
+
+// newSpikeStack 按生产环境的装配方式连接真实依赖，测试结束时停止消费者并关闭连接
+func newSpikeStack(t *testing.T) *spikeStack {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	s := &spikeStack{
+		events:      repo.NewSpikeEventRepository(env.db.DB),
+		orders:      repo.NewSpikeOrderRepository(env.db.DB),
+		users:       repo.NewUserRepository(env.db),
+		products:    repo.NewProductRepository(env.db.DB),
+		inventories: repo.NewInventoryRepository(env.db.DB),
+	}
+	orderEvents := repo.NewOrderEventRepository(env.db.DB)
+
+	// 每个测试使用独立的键前缀，互不影响 Redis 中的库存与去重记录
+	keyPrefix := fmt.Sprintf("it-%d", time.Now().UnixNano())
+	spikeCache := cache.NewSpikeCache(env.redis)
+	spikeCache.SetKeyPrefix(keyPrefix)
+	globalLimiter, err := limiter.NewTokenBucketLimiter(env.redis, &limiter.Config{
+		Rate: 10000, Window: time.Second, Burst: 10000, KeyPrefix: keyPrefix + ":global",
+	})
+	if err != nil {
+		t.Fatalf("failed to create global limiter: %v", err)
+	}
+	userLimiter, err := limiter.NewTokenBucketLimiter(env.redis, &limiter.Config{
+		Rate: 100, Window: time.Second, Burst: 100, KeyPrefix: keyPrefix + ":user",
+	})
+	if err != nil {
+		t.Fatalf("failed to create user limiter: %v", err)
+	}
+
+	cm := mq.NewConnectionManager(env.mqConf, env.logger)
+	if err := cm.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect rabbitmq: %v", err)
+	}
+	t.Cleanup(func() { _ = cm.Close() })
+	producer, err := mq.NewSpikeProducer(cm, env.mqConf.Producer, env.logger)
+	if err != nil {
+		t.Fatalf("failed to create spike producer: %v", err)
+	}
+	t.Cleanup(func() { _ = producer.Close() })
+	if err := producer.SetupInfrastructure(ctx); err != nil {
+		t.Fatalf("failed to set up spike queues: %v", err)
+	}
+
+	messages := service.NewSpikeMessageService(s.events, s.orders, s.inventories, orderEvents, spikeCache, env.logger)
+	messages.SetTransactionDB(env.db.DB)
+	consumer := mq.NewSpikeConsumer(cm, messages, env.logger)
+	if err := consumer.StartConsumers(ctx); err != nil {
+		t.Fatalf("failed to start spike consumers: %v", err)
+	}
+	t.Cleanup(func() { _ = consumer.StopConsumers() })
+
+	s.service = service.NewSpikeService(s.events, s.orders, s.products, s.inventories, s.users, orderEvents,
+		spikeCache, producer, globalLimiter, userLimiter, service.DefaultSpikeServiceConfig(), env.logger)
+	s.handler = api.NewSpikeHandler(s.service, env.logger)
+	return s
+}
+
+// seedEvent 写入商品、库存与进行中的活动并预热 Redis 库存
+func (s *spikeStack) seedEvent(t *testing.T, spikeStock int64, inventory int) (*domain.Product, *domain.SpikeEvent) {
+	t.Helper()
+	suffix := time.Now().UnixNano()
+	product := testutil.NewProductBuilder().WithName(fmt.Sprintf("集成测试商品-%d", suffix)).WithSKU(fmt.Sprintf("IT-%d", suffix)).Build()
+	testutil.SeedProducts(t, s.products, product)
+	testutil.SeedInventories(t, s.inventories, testutil.NewInventoryBuilder().ForProduct(product.ID).WithStock(inventory).Build())
+
+	event := testutil.NewSpikeEventBuilder().WithName(fmt.Sprintf("集成测试活动-%d", suffix)).
+		ForProduct(product.ID).WithStock(spikeStock).WithLimits(1, 0).Active().Build()
+	testutil.SeedSpikeEvents(t, s.events, event)
+	if err := s.service.WarmupStock(context.Background(), event.ID, true); err != nil {
+		t.Fatalf("failed to warm up stock: %v", err)
+	}
+	return product, event
+}
+
+// seedUser 写入一个已激活的普通用户
+func (s *spikeStack) seedUser(t *testing.T) *domain.User {
+	t.Helper()
+	name := fmt.Sprintf("it%d", time.Now().UnixNano())
+	user := testutil.NewUserBuilder().WithUsername(name).Build()
+	testutil.SeedUsers(t, s.users, user)
+	return user
+}
+
+// participate 以 userID 的身份通过 HTTP 处理器参与秒杀
+func (s *spikeStack) participate(t *testing.T, userID, eventID int64) *domain.SpikeParticipationResponse {
+	t.Helper()
+	engine := testutil.NewGinEngine(userID)
+	engine.POST("/api/v1/spike/participate", s.handler.ParticipateSpike)
+	w := testutil.DoJSON(t, engine, http.MethodPost, "/api/v1/spike/participate", map[string]any{
+		"spike_event_id":  eventID,
+		"quantity":        1,
+		"idempotency_key": fmt.Sprintf("it-%d-%d", userID, eventID),
+	})
+	body := testutil.DecodeResponse[domain.SpikeParticipationResponse](t, w)
+	if body.Data == nil {
+		t.Fatalf("participate response has no data: %d %s", w.Code, w.Body.String())
+	}
+	return body.Data
+}
+
+// waitForOrder 轮询直到消费者把用户在活动中的订单落库
+func (s *spikeStack) waitForOrder(t *testing.T, userID, eventID int64) *domain.SpikeOrder {
+	t.Helper()
+	deadline := time.Now().Add(persistTimeout)
+	for {
+		order, err := s.orders.GetByUserAndEvent(context.Background(), userID, eventID)
+		if err == nil && order != nil {
+			return order
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("spike order for user %d event %d not persisted within %s: %v", userID, eventID, persistTimeout, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestSpikeFlow_ParticipateConsumePersist(t *testing.T) {
+	s := newSpikeStack(t)
+	product, event := s.seedEvent(t, 10, 100)
+	user := s.seedUser(t)
+
+	result := s.participate(t, user.ID, event.ID)
+	if !result.Success {
+		t.Fatalf("participate = %+v, want success", result)
+	}
+
+	order := s.waitForOrder(t, user.ID, event.ID)
+	if order.Quantity != 1 || order.SpikeEventID != event.ID {
+		t.Errorf("persisted order = %+v, want quantity 1 in event %d", order, event.ID)
+	}
+
+	stored, err := s.events.GetByID(context.Background(), event.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.SoldCount != 1 {
+		t.Errorf("event sold count = %d, want 1", stored.SoldCount)
+	}
+	inventory, err := s.inventories.GetByProductID(context.Background(), product.ID)
+	if err != nil {
+		t.Fatalf("GetByProductID() error = %v", err)
+	}
+	if inventory.SoldStock != 1 {
+		t.Errorf("inventory sold stock = %d, want 1", inventory.SoldStock)
+	}
+
+	// 同一用户再次参与被去重拒绝
+	if again := s.participate(t, user.ID, event.ID); again.Success {
+		t.Errorf("second participate = %+v, want rejection", again)
+	}
+}
+
+func TestSpikeFlow_ConcurrentParticipantsDoNotOversell(t *testing.T) {
+	const spikeStock, participants = 5, 20
+	s := newSpikeStack(t)
+	_, event := s.seedEvent(t, spikeStock, 100)
+	users := make([]*domain.User, participants)
+	for i := range users {
+		users[i] = s.seedUser(t)
+	}
+
+	var (
+		mu      sync.Mutex
+		winners []int64
+		wg      sync.WaitGroup
+	)
+	for _, user := range users {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			if s.participate(t, userID, event.ID).Success {
+				mu.Lock()
+				winners = append(winners, userID)
+				mu.Unlock()
+			}
+		}(user.ID)
+	}
+	wg.Wait()
+
+	if len(winners) != spikeStock {
+		t.Fatalf("successful participants = %d, want %d", len(winners), spikeStock)
+	}
+	for _, userID := range winners {
+		s.waitForOrder(t, userID, event.ID)
+	}
+	stored, err := s.events.GetByID(context.Background(), event.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.SoldCount != spikeStock {
+		t.Errorf("event sold count = %d, want %d", stored.SoldCount, spikeStock)
+	}
+}
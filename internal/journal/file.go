@@ -0,0 +1,152 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileExt 日志文件扩展名，每行一条 JSON 记录
+const fileExt = ".jsonl"
+
+// FileWriter 按天滚动的追加写日志文件。
+// 文件名包含实例标识，多实例可共享同一目录而互不干扰
+type FileWriter struct {
+	dir      string
+	instance string
+	fsync    bool // 每条记录写入后是否 fsync，开启更安全但吞吐更低
+
+	mu      sync.Mutex
+	file    *os.File
+	fileDay string
+}
+
+// NewFileWriter 创建日志文件写入器，instance 为空时使用 主机名-进程号
+func NewFileWriter(dir, instance string, fsync bool) (*FileWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal dir: %w", err)
+	}
+	if instance == "" {
+		host, _ := os.Hostname()
+		instance = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+
+	return &FileWriter{dir: dir, instance: instance, fsync: fsync}, nil
+}
+
+// Append 追加一条记录
+func (w *FileWriter) Append(ctx context.Context, entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.rotate(time.Now()); err != nil {
+		return err
+	}
+	if _, err := w.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if w.fsync {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync journal file: %w", err)
+		}
+	}
+	return nil
+}
+
+// rotate 跨天时切换到新文件，调用方需持有锁
+func (w *FileWriter) rotate(now time.Time) error {
+	day := now.Format("20060102")
+	if w.file != nil && w.fileDay == day {
+		return nil
+	}
+	if w.file != nil {
+		w.file.Close()
+	}
+
+	name := filepath.Join(w.dir, fmt.Sprintf("journal-%s-%s%s", day, w.instance, fileExt))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+	w.file = f
+	w.fileDay = day
+	return nil
+}
+
+// Close 关闭当前日志文件
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Sync()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file = nil
+	return err
+}
+
+// Files 返回目录下的全部日志文件，按文件名（即日期）排序
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal dir: %w", err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "journal-") || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadFile 逐条读取日志文件。
+// 进程崩溃可能留下写了一半的末行，该行无法解析时跳过并计入 corrupt
+func ReadFile(path string, fn func(entry *Entry) error) (corrupt int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			corrupt++
+			continue
+		}
+		if err := fn(&entry); err != nil {
+			return corrupt, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return corrupt, fmt.Errorf("failed to scan journal file: %w", err)
+	}
+	return corrupt, nil
+}
@@ -0,0 +1,30 @@
+// Package journal 提供秒杀参与的预写日志（write-ahead journal）。
+// 每次 Redis 预减库存成功后追加一条记录，若消息队列丢失了下单消息，
+// 可通过 cmd/journal-replay 按幂等键去重回放日志，在数据库中重建订单。
+package journal
+
+import (
+	"context"
+	"time"
+)
+
+// Entry 表示一次成功的库存预减
+type Entry struct {
+	OrderNo        string    `json:"order_no,omitempty"` // 参与时预先生成的订单号，旧日志为空
+	SpikeEventID   int64     `json:"spike_event_id"`
+	UserID         int64     `json:"user_id"`
+	ProductID      int64     `json:"product_id"`
+	Quantity       int64     `json:"quantity"`
+	SpikePrice     float64   `json:"spike_price"`
+	TotalAmount    float64   `json:"total_amount"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	ExpireAt       time.Time `json:"expire_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	TraceID        string    `json:"trace_id,omitempty"`
+}
+
+// Writer 追加写入日志
+type Writer interface {
+	Append(ctx context.Context, entry *Entry) error
+	Close() error
+}
@@ -0,0 +1,132 @@
+package journal
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func TestFileWriter_AppendAndRead(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewFileWriter(dir, "test", true)
+	if err != nil {
+		t.Fatalf("NewFileWriter() error = %v", err)
+	}
+
+	for _, key := range []string{"k1", "k2"} {
+		if err := w.Append(context.Background(), &Entry{SpikeEventID: 1, UserID: 2, Quantity: 1, IdempotencyKey: key}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	files, err := Files(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Files() = %v, %v, want one file", files, err)
+	}
+
+	// 模拟进程崩溃留下的半行
+	f, _ := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0o644)
+	f.WriteString(`{"spike_event_id":1,"idempo`)
+	f.Close()
+
+	var keys []string
+	corrupt, err := ReadFile(files[0], func(entry *Entry) error {
+		keys = append(keys, entry.IdempotencyKey)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "k1" || keys[1] != "k2" {
+		t.Errorf("ReadFile() keys = %v, want [k1 k2]", keys)
+	}
+	if corrupt != 1 {
+		t.Errorf("ReadFile() corrupt = %d, want 1", corrupt)
+	}
+}
+
+type fakeOrderStore struct {
+	byKey map[string]*domain.SpikeOrder
+}
+
+func (f *fakeOrderStore) GetByIdempotencyKey(ctx context.Context, key string) (*domain.SpikeOrder, error) {
+	return f.byKey[key], nil
+}
+
+func (f *fakeOrderStore) Create(ctx context.Context, order *domain.SpikeOrder) error {
+	order.ID = int64(len(f.byKey) + 1)
+	f.byKey[order.IdempotencyKey] = order
+	return nil
+}
+
+type fakeEventStore struct {
+	event *domain.SpikeEvent
+}
+
+func (f *fakeEventStore) GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	return f.event, nil
+}
+
+func (f *fakeEventStore) UpdateSoldCount(ctx context.Context, id int64, count int64) error {
+	f.event.SoldCount = count
+	return nil
+}
+
+type fakeInventoryStore struct {
+	consumed int
+}
+
+func (f *fakeInventoryStore) ConsumeStock(ctx context.Context, productID int64, quantity int) error {
+	f.consumed += quantity
+	return nil
+}
+
+func TestReplayer_ReplayFiles(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := NewFileWriter(dir, "test", false)
+	expireAt := time.Now().Add(30 * time.Minute)
+	for _, key := range []string{"existing", "lost", "lost"} {
+		w.Append(context.Background(), &Entry{SpikeEventID: 1, UserID: 2, ProductID: 3, Quantity: 2, IdempotencyKey: key, ExpireAt: expireAt})
+	}
+	w.Close()
+	files, _ := Files(dir)
+
+	orders := &fakeOrderStore{byKey: map[string]*domain.SpikeOrder{"existing": {ID: 100}}}
+	events := &fakeEventStore{event: &domain.SpikeEvent{ID: 1, SpikeStock: 10, SoldCount: 2}}
+	inventory := &fakeInventoryStore{}
+	replayer := NewReplayer(orders, events, inventory, nil)
+
+	// dry-run 不写入
+	summary, err := replayer.ReplayFiles(context.Background(), files, true)
+	if err != nil {
+		t.Fatalf("ReplayFiles(dry-run) error = %v", err)
+	}
+	if summary.Rebuilt != 2 || summary.Skipped != 1 || len(orders.byKey) != 1 {
+		t.Errorf("ReplayFiles(dry-run) summary = %+v, orders = %d", summary, len(orders.byKey))
+	}
+
+	summary, err = replayer.ReplayFiles(context.Background(), files, false)
+	if err != nil {
+		t.Fatalf("ReplayFiles() error = %v", err)
+	}
+	// 同一幂等键的重复记录只重建一次
+	if summary.Total != 3 || summary.Rebuilt != 1 || summary.Skipped != 2 {
+		t.Errorf("ReplayFiles() summary = %+v, want total 3, rebuilt 1, skipped 2", summary)
+	}
+	lost := orders.byKey["lost"]
+	if lost == nil || lost.Status != domain.SpikeOrderStatusPending || lost.ExpireAt == nil || !lost.ExpireAt.Equal(expireAt) {
+		t.Errorf("rebuilt order = %+v, want pending with original expire_at", lost)
+	}
+	if events.event.SoldCount != 4 {
+		t.Errorf("sold count = %d, want 4", events.event.SoldCount)
+	}
+	if inventory.consumed != 2 {
+		t.Errorf("consumed inventory = %d, want 2", inventory.consumed)
+	}
+}
@@ -0,0 +1,167 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/idgen"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+// OrderStore 回放所需的秒杀订单操作（由 repo.SpikeOrderRepository 实现）
+type OrderStore interface {
+	GetByIdempotencyKey(ctx context.Context, key string) (*domain.SpikeOrder, error)
+	Create(ctx context.Context, order *domain.SpikeOrder) error
+}
+
+// EventStore 回放所需的秒杀活动操作（由 repo.SpikeEventRepository 实现）
+type EventStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	UpdateSoldCount(ctx context.Context, id int64, count int64) error
+}
+
+// InventoryStore 回放所需的库存操作（由 repo.InventoryRepository 实现）
+type InventoryStore interface {
+	ConsumeStock(ctx context.Context, productID int64, quantity int) error
+}
+
+// ReplaySummary 回放结果汇总
+type ReplaySummary struct {
+	Total     int // 读取的记录数
+	Skipped   int // 订单已存在而跳过的记录数
+	Rebuilt   int // 重建的订单数
+	Failed    int // 重建失败的记录数
+	Corrupted int // 无法解析的记录数
+}
+
+// Replayer 将日志回放到数据库，按幂等键去重
+type Replayer struct {
+	orders    OrderStore
+	events    EventStore
+	inventory InventoryStore
+	orderNos  *idgen.Snowflake // 为旧日志（无订单号）补发订单号，为空时使用 idgen.Default()
+	logger    *zap.Logger
+}
+
+// NewReplayer 创建日志回放器
+func NewReplayer(orders OrderStore, events EventStore, inventory InventoryStore, logger *zap.Logger) *Replayer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Replayer{
+		orders:    orders,
+		events:    events,
+		inventory: inventory,
+		logger:    logger,
+	}
+}
+
+// SetOrderNoGenerator 设置订单号生成器，节点编号应与在线服务实例互不相同
+func (r *Replayer) SetOrderNoGenerator(gen *idgen.Snowflake) {
+	r.orderNos = gen
+}
+
+// ReplayFiles 依次回放日志文件；dryRun 时只统计需要重建的订单，不写数据库
+func (r *Replayer) ReplayFiles(ctx context.Context, files []string, dryRun bool) (*ReplaySummary, error) {
+	summary := &ReplaySummary{}
+	for _, file := range files {
+		corrupt, err := ReadFile(file, func(entry *Entry) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			summary.Total++
+			rebuilt, err := r.ReplayEntry(ctx, entry, dryRun)
+			switch {
+			case err != nil:
+				summary.Failed++
+				r.logger.Error("回放日志记录失败",
+					zap.String("file", file),
+					zap.Int64("spike_event_id", entry.SpikeEventID),
+					logger.IdempotencyKey(entry.IdempotencyKey),
+					zap.Error(err))
+			case rebuilt:
+				summary.Rebuilt++
+			default:
+				summary.Skipped++
+			}
+			return nil
+		})
+		summary.Corrupted += corrupt
+		if err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// ReplayEntry 回放单条记录：订单已存在时跳过，否则按下单消息的处理方式重建订单。
+// 返回是否重建（dryRun 时表示需要重建）
+func (r *Replayer) ReplayEntry(ctx context.Context, entry *Entry, dryRun bool) (bool, error) {
+	if entry.IdempotencyKey == "" {
+		return false, fmt.Errorf("journal entry has no idempotency key")
+	}
+
+	existing, err := r.orders.GetByIdempotencyKey(ctx, entry.IdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+
+	event, err := r.events.GetByID(ctx, entry.SpikeEventID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get spike event: %w", err)
+	}
+	if err := r.events.UpdateSoldCount(ctx, event.ID, event.SoldCount+entry.Quantity); err != nil {
+		return false, fmt.Errorf("failed to update sold count: %w", err)
+	}
+
+	orderNo := entry.OrderNo
+	if orderNo == "" {
+		gen := r.orderNos
+		if gen == nil {
+			gen = idgen.Default()
+		}
+		if orderNo, err = gen.NextOrderNo(); err != nil {
+			return false, fmt.Errorf("failed to generate order no: %w", err)
+		}
+	}
+
+	expireAt := entry.ExpireAt
+	order := &domain.SpikeOrder{
+		OrderNo:        orderNo,
+		SpikeEventID:   entry.SpikeEventID,
+		UserID:         entry.UserID,
+		Quantity:       entry.Quantity,
+		SpikePrice:     entry.SpikePrice,
+		TotalAmount:    entry.TotalAmount,
+		Status:         domain.SpikeOrderStatusPending,
+		IdempotencyKey: entry.IdempotencyKey,
+		ExpireAt:       &expireAt,
+		CreatedAt:      entry.CreatedAt,
+		SinglePurchase: event.UserQuantityLimit() == 0,
+	}
+	if err := r.orders.Create(ctx, order); err != nil {
+		return false, fmt.Errorf("failed to create spike order: %w", err)
+	}
+
+	stockCtx := domain.WithStockReference(ctx, order.OrderNo, "日志回放重建订单")
+	if err := r.inventory.ConsumeStock(stockCtx, entry.ProductID, int(entry.Quantity)); err != nil {
+		return false, fmt.Errorf("failed to consume inventory: %w", err)
+	}
+
+	r.logger.Info("已从日志重建秒杀订单",
+		zap.Int64("spike_order_id", order.ID),
+		zap.Int64("spike_event_id", entry.SpikeEventID),
+		logger.UserID(entry.UserID),
+		logger.IdempotencyKey(entry.IdempotencyKey))
+	return true, nil
+}
@@ -0,0 +1,198 @@
+// Package keys 统一构造幂等键与 Redis 键，避免各处用 fmt.Sprintf 或 string(rune(...)) 临时拼接。
+//
+// 键由若干段以 ':' 连接而成：整数按十进制输出，字符串段中 [A-Za-z0-9_.-] 以外的字符（包括 ':'）
+// 按 %XX 转义，空串编码为单独的 '%'，因此不同的段序列不会拼出同一个键。
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxIdempotencyKeyLength 幂等键最大长度，与请求体 idempotency_key 的校验规则一致
+const MaxIdempotencyKeyLength = 64
+
+// Separator 键的段分隔符
+const Separator = ":"
+
+// 幂等键校验错误
+var (
+	ErrEmptyKey    = errors.New("幂等键不能为空")
+	ErrKeyTooLong  = fmt.Errorf("幂等键长度不能超过%d", MaxIdempotencyKeyLength)
+	ErrInvalidChar = errors.New("幂等键只能包含字母、数字以及 _ - . : %")
+)
+
+// ValidateIdempotencyKey 校验客户端或服务端生成的幂等键：非空、不超过最大长度、
+// 仅含安全字符以及分隔符 ':' 和转义符 '%'
+func ValidateIdempotencyKey(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		return ErrKeyTooLong
+	}
+	for i := 0; i < len(key); i++ {
+		if !isSafe(key[i]) && key[i] != ':' && key[i] != '%' {
+			return fmt.Errorf("%w: 第%d个字符 %q", ErrInvalidChar, i+1, key[i])
+		}
+	}
+	return nil
+}
+
+// Idempotency 构造服务端幂等键 scope:part1:part2...，结果总能通过 ValidateIdempotencyKey；
+// 超过最大长度时以 scope 加各段的 SHA-256 摘要代替。scope 必须是非空的安全字符串，否则 panic。
+func Idempotency(scope string, parts ...any) string {
+	mustScope(scope)
+	key := join(scope, parts)
+	if len(key) <= MaxIdempotencyKeyLength {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	digest := hex.EncodeToString(sum[:])
+	return scope + Separator + digest[:MaxIdempotencyKeyLength-len(scope)-len(Separator)]
+}
+
+// Redis 构造 Redis 键 namespace:part1:part2...，namespace 是形如 "spike:stock" 的字面量前缀，
+// 必须非空且只含安全字符与 ':'，否则 panic。
+func Redis(namespace string, parts ...any) string {
+	if namespace == "" || strings.HasPrefix(namespace, Separator) || strings.HasSuffix(namespace, Separator) {
+		panic(fmt.Sprintf("keys: invalid namespace %q", namespace))
+	}
+	for _, scope := range strings.Split(namespace, Separator) {
+		mustScope(scope)
+	}
+	return join(namespace, parts)
+}
+
+// ValidatePrefix 校验环境键前缀：可为空；非空时规则同 Redis 的 namespace
+func ValidatePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if strings.HasPrefix(prefix, Separator) || strings.HasSuffix(prefix, Separator) {
+		return fmt.Errorf("keys: invalid prefix %q", prefix)
+	}
+	for _, scope := range strings.Split(prefix, Separator) {
+		if scope == "" {
+			return fmt.Errorf("keys: invalid prefix %q", prefix)
+		}
+		for i := 0; i < len(scope); i++ {
+			if !isSafe(scope[i]) {
+				return fmt.Errorf("keys: invalid prefix %q", prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// WithPrefix 为已构造的键加上环境前缀 prefix:key，prefix 为空时原样返回。
+// 前缀用于让多个环境（如预发与生产）共用同一 Redis 时互不覆盖。
+func WithPrefix(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + Separator + key
+}
+
+// IsProductionPrefix 判断环境前缀是否指向生产环境：首段为 prod 或 production（不区分大小写）
+func IsProductionPrefix(prefix string) bool {
+	first, _, _ := strings.Cut(prefix, Separator)
+	return strings.EqualFold(first, "prod") || strings.EqualFold(first, "production")
+}
+
+// hashTag 已编码的哈希标签段，拼接时原样输出
+type hashTag string
+
+// HashTag 把 v 编码为 Redis Cluster 哈希标签段 {v}。Cluster 只按首个 {...} 内的内容计算槽位，
+// 带相同标签的键落在同一槽位，可以在同一个 Lua 脚本或事务中操作。普通段中的 '{' '}' 会被转义，
+// 因此只有 HashTag 产生的段才构成标签
+func HashTag(v any) any {
+	return hashTag("{" + Segment(v) + "}")
+}
+
+// Segment 将单个值编码为键的一段
+func Segment(v any) string {
+	switch x := v.(type) {
+	case hashTag:
+		return string(x)
+	case int:
+		return strconv.Itoa(x)
+	case int32:
+		return strconv.FormatInt(int64(x), 10)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case uint:
+		return strconv.FormatUint(uint64(x), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(x), 10)
+	case uint64:
+		return strconv.FormatUint(x, 10)
+	case string:
+		return escape(x)
+	case fmt.Stringer:
+		return escape(x.String())
+	default:
+		return escape(fmt.Sprint(x))
+	}
+}
+
+func join(prefix string, parts []any) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for _, p := range parts {
+		b.WriteString(Separator)
+		b.WriteString(Segment(p))
+	}
+	return b.String()
+}
+
+// emptySegment 空串段的编码。转义产生的 '%' 后总跟两位十六进制数，单独的 '%' 不会与任何非空串的编码相同
+const emptySegment = "%"
+
+// escape 对安全字符集之外的字节做 %XX 转义，空串编码为 emptySegment 以免产生空段
+func escape(s string) string {
+	if s == "" {
+		return emptySegment
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if !isSafe(s[i]) {
+			n++
+		}
+	}
+	if n == 0 {
+		return s
+	}
+	const hexDigits = "0123456789ABCDEF"
+	buf := make([]byte, 0, len(s)+2*n)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isSafe(c) {
+			buf = append(buf, c)
+			continue
+		}
+		buf = append(buf, '%', hexDigits[c>>4], hexDigits[c&0x0f])
+	}
+	return string(buf)
+}
+
+func mustScope(scope string) {
+	if scope == "" {
+		panic("keys: empty scope")
+	}
+	for i := 0; i < len(scope); i++ {
+		if !isSafe(scope[i]) {
+			panic(fmt.Sprintf("keys: invalid scope %q", scope))
+		}
+	}
+}
+
+// isSafe 段内无需转义的字符：字母、数字、'_'、'-'、'.'
+func isSafe(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.'
+}
@@ -0,0 +1,134 @@
+package keys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	cases := []struct {
+		key  string
+		want error
+	}{
+		{"test_key_1", nil},
+		{"cancel:42:1700000000", nil},
+		{"3f2b-9a.c_D", nil},
+		{"", ErrEmptyKey},
+		{strings.Repeat("a", MaxIdempotencyKeyLength), nil},
+		{strings.Repeat("a", MaxIdempotencyKeyLength+1), ErrKeyTooLong},
+		{"has space", ErrInvalidChar},
+		{"semi;colon", ErrInvalidChar},
+		{"中文", ErrInvalidChar},
+	}
+	for _, tc := range cases {
+		err := ValidateIdempotencyKey(tc.key)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("ValidateIdempotencyKey(%q) = %v, want %v", tc.key, err, tc.want)
+		}
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	if got := Idempotency("cancel", int64(42), 1700000000); got != "cancel:42:1700000000" {
+		t.Fatalf("got %q", got)
+	}
+	// 两位数不能像 string(rune('0'+i)) 那样变成 ':' 或 ';'
+	if got := Idempotency("concurrent_test", 12); got != "concurrent_test:12" {
+		t.Fatalf("got %q", got)
+	}
+
+	long := Idempotency("auto", strings.Repeat("x", 100))
+	if len(long) != MaxIdempotencyKeyLength || !strings.HasPrefix(long, "auto:") {
+		t.Fatalf("long key not folded: %q", long)
+	}
+	if long != Idempotency("auto", strings.Repeat("x", 100)) {
+		t.Fatal("folded key is not deterministic")
+	}
+	for _, k := range []string{long, Idempotency("s", "a b", "c:d")} {
+		if err := ValidateIdempotencyKey(k); err != nil {
+			t.Errorf("generated key %q invalid: %v", k, err)
+		}
+	}
+}
+
+func TestRedis_NoCollisions(t *testing.T) {
+	if got := Redis("spike:stock", int64(7)); got != "spike:stock:7" {
+		t.Fatalf("got %q", got)
+	}
+	seen := map[string][]any{}
+	inputs := [][]any{
+		{"a:b", "c"},
+		{"a", "b:c"},
+		{"a%3Ab", "c"},
+		{"", "a"},
+		{"a", ""},
+		{"\x00", "a"},
+		{"a", "\x00"},
+		{"%", "a"},
+		{1, 23},
+		{12, 3},
+	}
+	for _, parts := range inputs {
+		k := Redis("ns", parts...)
+		if prev, ok := seen[k]; ok {
+			t.Fatalf("%v and %v both map to %q", prev, parts, k)
+		}
+		seen[k] = parts
+	}
+}
+
+// 空串与单个 NUL 字节曾都编码为 "%00"
+func TestSegment_EmptyDistinctFromNUL(t *testing.T) {
+	if empty, nul := Segment(""), Segment("\x00"); empty == nul {
+		t.Fatalf("empty segment and NUL both encode to %q", empty)
+	}
+	if got := Redis("ns", "", "a"); got != "ns:%:a" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestHashTag(t *testing.T) {
+	if got := Redis("spike:user", int64(3), HashTag(int64(7))); got != "spike:user:3:{7}" {
+		t.Fatalf("got %q", got)
+	}
+	// 普通段中的花括号被转义，不会与哈希标签混淆
+	if got := Redis("ns", "{7}"); got != "ns:%7B7%7D" {
+		t.Errorf("got %q", got)
+	}
+	if got := Redis("ns", HashTag("a:b")); got != "ns:{a%3Ab}" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRedis_InvalidNamespacePanics(t *testing.T) {
+	for _, ns := range []string{"", ":spike", "spike:", "spike::stock", "spike stock"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Redis(%q) did not panic", ns)
+				}
+			}()
+			Redis(ns, 1)
+		}()
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	if got := WithPrefix("", "spike:stock:7"); got != "spike:stock:7" {
+		t.Errorf("WithPrefix(\"\") = %q", got)
+	}
+	if got := WithPrefix("staging", "spike:stock:7"); got != "staging:spike:stock:7" {
+		t.Errorf("WithPrefix(\"staging\") = %q", got)
+	}
+	for _, prefix := range []string{":staging", "staging:", "a::b", "stag ing"} {
+		if ValidatePrefix(prefix) == nil {
+			t.Errorf("ValidatePrefix(%q) = nil, want error", prefix)
+		}
+	}
+	for prefix, want := range map[string]bool{"prod": true, "PROD:eu": true, "production": true, "staging": false, "": false, "prod-test": false} {
+		if got := IsProductionPrefix(prefix); got != want {
+			t.Errorf("IsProductionPrefix(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package lifecycle 管理进程级的生命周期阶段：关闭前的"排空"（drain）信号与最终的关闭回调。
+// 收到退出信号后先进入排空阶段，各组件据此停止接收新工作但完成进行中的工作，随后再关闭 HTTP 服务器，
+// 最后释放消息队列连接等外部资源。
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Manager 广播排空信号并执行各组件注册的排空回调。
+type Manager struct {
+	mu       sync.Mutex
+	draining chan struct{}
+	started  bool
+	hooks    []func()
+	wg       sync.WaitGroup
+
+	closers  []func()
+	shutdown bool
+}
+
+// NewManager 创建生命周期管理器
+func NewManager() *Manager {
+	return &Manager{draining: make(chan struct{})}
+}
+
+// Draining 返回排空信号通道，进入排空阶段后关闭
+func (m *Manager) Draining() <-chan struct{} {
+	return m.draining
+}
+
+// IsDraining 是否已进入排空阶段
+func (m *Manager) IsDraining() bool {
+	select {
+	case <-m.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// OnDrain 注册排空回调，回调应阻塞到组件的进行中工作完成为止；
+// 已处于排空阶段时立即在后台执行。
+func (m *Manager) OnDrain(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		m.run(fn)
+		return
+	}
+	m.hooks = append(m.hooks, fn)
+}
+
+// BeginDrain 进入排空阶段并并发执行所有回调，重复调用无副作用
+func (m *Manager) BeginDrain() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+	close(m.draining)
+	for _, fn := range m.hooks {
+		m.run(fn)
+	}
+	m.hooks = nil
+}
+
+// Wait 等待所有排空回调执行完毕，ctx 先结束时返回其错误
+func (m *Manager) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnShutdown 注册关闭回调，在 HTTP 服务器关闭、排空回调完成后执行，用于释放连接等外部资源
+func (m *Manager) OnShutdown(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, fn)
+}
+
+// Shutdown 按注册的逆序依次执行关闭回调，后初始化的组件先关闭；重复调用无副作用
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	if m.shutdown {
+		m.mu.Unlock()
+		return
+	}
+	m.shutdown = true
+	closers := m.closers
+	m.closers = nil
+	m.mu.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i]()
+	}
+}
+
+// run 在后台执行回调，需持有 mu
+func (m *Manager) run(fn func()) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		fn()
+	}()
+}
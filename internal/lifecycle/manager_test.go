@@ -0,0 +1,73 @@
+package lifecycle
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManager_BeginDrain(t *testing.T) {
+	m := NewManager()
+	if m.IsDraining() {
+		t.Fatal("new manager should not be draining")
+	}
+
+	var calls int32
+	release := make(chan struct{})
+	m.OnDrain(func() {
+		atomic.AddInt32(&calls, 1)
+		<-release
+	})
+
+	m.BeginDrain()
+	m.BeginDrain()
+	select {
+	case <-m.Draining():
+	default:
+		t.Fatal("Draining() should be closed after BeginDrain")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.Wait(ctx); err == nil {
+		t.Fatal("Wait() should time out while hook is running")
+	}
+
+	close(release)
+	if err := m.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("hook calls = %d, want 1", got)
+	}
+}
+
+func TestManager_OnDrainAfterBegin(t *testing.T) {
+	m := NewManager()
+	m.BeginDrain()
+
+	done := make(chan struct{})
+	m.OnDrain(func() { close(done) })
+	if err := m.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	select {
+	case <-done:
+	default:
+		t.Fatal("hook registered after BeginDrain should run")
+	}
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	m := NewManager()
+	var order []int
+	m.OnShutdown(func() { order = append(order, 1) })
+	m.OnShutdown(func() { order = append(order, 2) })
+
+	m.Shutdown()
+	m.Shutdown()
+	if len(order) != 2 || order[0] != 2 || order[1] != 1 {
+		t.Fatalf("shutdown order = %v, want [2 1]", order)
+	}
+}
@@ -5,23 +5,24 @@ import (
 	"context"
 	"fmt"
 	"time"
-
-	"github.com/redis/go-redis/v9"
 )
 
 // FixedWindowLimiter 固定窗口限流器
 type FixedWindowLimiter struct {
-	client    redis.Cmdable
+	client    RedisClient
 	config    *Config
 	keyPrefix string
 }
 
 // NewFixedWindowLimiter 创建固定窗口限流器
 func NewFixedWindowLimiter(redisClient interface{}, config *Config) (*FixedWindowLimiter, error) {
-	client, ok := redisClient.(redis.Cmdable)
+	client, ok := redisClient.(RedisClient)
 	if !ok {
 		return nil, fmt.Errorf("invalid redis client type")
 	}
+	if config == nil {
+		return nil, fmt.Errorf("limiter config is required")
+	}
 
 	if config.KeyPrefix == "" {
 		config.KeyPrefix = "limiter:fw"
@@ -71,9 +72,10 @@ else
 end
 `
 
-// getKey 生成Redis key
+// getKey 生成Redis key。脚本在 key 后拼接窗口起点得到计数键，以 key 为哈希标签
+// 使这些未在 KEYS 中声明的计数键在 Redis Cluster 中与 KEYS[1] 落在同一槽位
 func (fw *FixedWindowLimiter) getKey(key string) string {
-	return fmt.Sprintf("%s:%s", fw.keyPrefix, key)
+	return fmt.Sprintf("%s:{%s}", fw.keyPrefix, key)
 }
 
 // Allow 检查是否允许请求通过
@@ -83,12 +85,18 @@ func (fw *FixedWindowLimiter) Allow(ctx context.Context, key string) (*LimitResu
 
 // AllowN 检查是否允许N个请求通过
 func (fw *FixedWindowLimiter) AllowN(ctx context.Context, key string, n int64) (*LimitResult, error) {
+	return fw.AllowWithRate(ctx, key, fw.config.Rate, n)
+}
+
+// AllowWithRate 以调用方指定的窗口内限制数量检查是否允许N个请求通过，
+// 用于同一限流器下各 key 速率不同的场景（如按活动配置的参与 QPS）
+func (fw *FixedWindowLimiter) AllowWithRate(ctx context.Context, key string, rate, n int64) (*LimitResult, error) {
 	redisKey := fw.getKey(key)
 	now := time.Now().Unix()
 
 	result := fw.client.Eval(ctx, fixedWindowScript,
 		[]string{redisKey},
-		fw.config.Rate,                    // 限制数量
+		rate,                              // 限制数量
 		int64(fw.config.Window.Seconds()), // 时间窗口
 		n,                                 // 请求数量
 		now,                               // 当前时间
@@ -4,6 +4,8 @@ package limiter
 import (
 	"context"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // LimitResult 限流结果
@@ -29,6 +31,15 @@ type Limiter interface {
 	GetInfo(ctx context.Context, key string) (*LimitInfo, error)
 }
 
+// RedisClient 限流器依赖的 Redis 命令（由 *redis.Client 实现）
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	HMGet(ctx context.Context, key string, fields ...string) *redis.SliceCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
 // LimitInfo 限流信息
 type LimitInfo struct {
 	Limit     int64         `json:"limit"`      // 限流阈值
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
 	"github.com/MorseWayne/spike_shop/internal/resp"
 	"github.com/gin-gonic/gin"
 )
@@ -159,13 +161,17 @@ func defaultErrorHandler(c *gin.Context, err error) {
 	resp.Error(c.Writer, http.StatusInternalServerError, resp.CodeInternalError, "限流服务异常", requestID, traceID)
 }
 
-// defaultOnLimitReached 默认限流回调
+// defaultOnLimitReached 默认限流回调，响应标记为可重试，等待时长取限流器给出的重置时间
 func defaultOnLimitReached(c *gin.Context, result *LimitResult) {
 	requestID := c.GetString("request_id")
 	traceID := c.GetString("trace_id")
 
-	resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
-		"请求过于频繁，请稍后重试", requestID, traceID)
+	retryAfter := result.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	resp.RetryableError(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
+		"请求过于频繁，请稍后重试", true, retryAfter, requestID, traceID)
 }
 
 // SpikeRateLimitMiddleware 秒杀专用限流中间件
@@ -180,11 +186,17 @@ func SpikeRateLimitMiddleware(limiter Limiter) gin.HandlerFunc {
 			}
 			return fmt.Sprintf("spike:ip:%s", c.ClientIP())
 		},
+		// 限流存储不可用时放行，由秒杀服务的缓存熔断与降级并发上限兜底
+		ErrorHandler: func(c *gin.Context, err error) {},
 		OnLimitReached: func(c *gin.Context, result *LimitResult) {
 			requestID := c.GetString("request_id")
 			traceID := c.GetString("trace_id")
-			resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
-				"秒杀请求过于频繁", requestID, traceID)
+			metrics.SpikeParticipations.Inc(metrics.OutcomeRateLimited)
+			// 与参与接口的失败响应一致，携带可重试提示
+			failure := domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeRateLimited, "秒杀请求过于频繁").
+				WithRetryAfter(result.RetryAfter)
+			resp.WriteJSON(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
+				"秒杀请求过于频繁", failure, requestID, traceID)
 		},
 		Headers: DefaultHeaderConfig(),
 	}
@@ -222,6 +234,20 @@ func APIRateLimitMiddleware(limiter Limiter) gin.HandlerFunc {
 	return RateLimitMiddleware(config)
 }
 
+// AnonymousRateLimitMiddleware 匿名只读接口限流中间件，
+// 只按客户端 IP 计数（所有匿名接口共享配额），即使请求携带了登录态也不按用户放宽
+func AnonymousRateLimitMiddleware(limiter Limiter) gin.HandlerFunc {
+	config := &MiddlewareConfig{
+		Limiter: limiter,
+		KeyGenerator: func(c *gin.Context) string {
+			return fmt.Sprintf("anon:ip:%s", c.ClientIP())
+		},
+		Headers: DefaultHeaderConfig(),
+	}
+
+	return RateLimitMiddleware(config)
+}
+
 // MultiLevelRateLimitMiddleware 多级限流中间件
 func MultiLevelRateLimitMiddleware(globalLimiter, userLimiter Limiter) gin.HandlerFunc {
 	// 创建多重限流器
@@ -0,0 +1,47 @@
+package limiter
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Reloadable 可在运行期替换的限流器，配置热更新时以新配置重建限流器后调用 Swap，调用方持有的引用保持不变
+type Reloadable struct {
+	current atomic.Pointer[Limiter]
+}
+
+// NewReloadable 创建可替换的限流器
+func NewReloadable(l Limiter) *Reloadable {
+	r := &Reloadable{}
+	r.Swap(l)
+	return r
+}
+
+// Swap 替换当前限流器
+func (r *Reloadable) Swap(l Limiter) {
+	r.current.Store(&l)
+}
+
+func (r *Reloadable) load() Limiter {
+	return *r.current.Load()
+}
+
+// Allow 检查是否允许请求通过
+func (r *Reloadable) Allow(ctx context.Context, key string) (*LimitResult, error) {
+	return r.load().Allow(ctx, key)
+}
+
+// AllowN 检查是否允许N个请求通过
+func (r *Reloadable) AllowN(ctx context.Context, key string, n int64) (*LimitResult, error) {
+	return r.load().AllowN(ctx, key, n)
+}
+
+// Reset 重置限流状态
+func (r *Reloadable) Reset(ctx context.Context, key string) error {
+	return r.load().Reset(ctx, key)
+}
+
+// GetInfo 获取限流信息
+func (r *Reloadable) GetInfo(ctx context.Context, key string) (*LimitInfo, error) {
+	return r.load().GetInfo(ctx, key)
+}
@@ -5,23 +5,24 @@ import (
 	"context"
 	"fmt"
 	"time"
-
-	"github.com/redis/go-redis/v9"
 )
 
 // SlidingWindowLimiter 滑动窗口限流器
 type SlidingWindowLimiter struct {
-	client    redis.Cmdable
+	client    RedisClient
 	config    *Config
 	keyPrefix string
 }
 
 // NewSlidingWindowLimiter 创建滑动窗口限流器
 func NewSlidingWindowLimiter(redisClient interface{}, config *Config) (*SlidingWindowLimiter, error) {
-	client, ok := redisClient.(redis.Cmdable)
+	client, ok := redisClient.(RedisClient)
 	if !ok {
 		return nil, fmt.Errorf("invalid redis client type")
 	}
+	if config == nil {
+		return nil, fmt.Errorf("limiter config is required")
+	}
 
 	if config.KeyPrefix == "" {
 		config.KeyPrefix = "limiter:sw"
@@ -108,9 +109,9 @@ else
 end
 `
 
-// getKey 生成Redis key
+// getKey 生成Redis key，以 key 为哈希标签使脚本拼出的各子窗口键在 Redis Cluster 中同槽
 func (sw *SlidingWindowLimiter) getKey(key string) string {
-	return fmt.Sprintf("%s:%s", sw.keyPrefix, key)
+	return fmt.Sprintf("%s:{%s}", sw.keyPrefix, key)
 }
 
 // Allow 检查是否允许请求通过
@@ -5,23 +5,24 @@ import (
 	"context"
 	"fmt"
 	"time"
-
-	"github.com/redis/go-redis/v9"
 )
 
 // TokenBucketLimiter 令牌桶限流器
 type TokenBucketLimiter struct {
-	client    redis.Cmdable
+	client    RedisClient
 	config    *Config
 	keyPrefix string
 }
 
 // NewTokenBucketLimiter 创建令牌桶限流器
 func NewTokenBucketLimiter(redisClient interface{}, config *Config) (*TokenBucketLimiter, error) {
-	client, ok := redisClient.(redis.Cmdable)
+	client, ok := redisClient.(RedisClient)
 	if !ok {
 		return nil, fmt.Errorf("invalid redis client type")
 	}
+	if config == nil {
+		return nil, fmt.Errorf("limiter config is required")
+	}
 
 	if config.KeyPrefix == "" {
 		config.KeyPrefix = "limiter:tb"
@@ -98,6 +99,9 @@ func (tb *TokenBucketLimiter) Allow(ctx context.Context, key string) (*LimitResu
 
 // AllowN 检查是否允许N个请求通过
 func (tb *TokenBucketLimiter) AllowN(ctx context.Context, key string, n int64) (*LimitResult, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid token count: %d", n)
+	}
 	redisKey := tb.getKey(key)
 	now := time.Now().Unix()
 
@@ -124,9 +128,10 @@ func (tb *TokenBucketLimiter) AllowN(ctx context.Context, key string, n int64) (
 	retryAfter := time.Duration(values[2].(int64)) * time.Second
 
 	return &LimitResult{
-		Allowed:    allowed,
-		Remaining:  remaining,
-		RetryAfter: retryAfter,
+		Allowed:       allowed,
+		Remaining:     remaining,
+		RetryAfter:    retryAfter,
+		TotalRequests: n, // 令牌桶不统计窗口内请求数，返回本次请求的令牌数
 	}, nil
 }
 
@@ -1,127 +1,106 @@
-package limiter
+package limiter_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
 )
 
-// MockRedisClient for limiter testing
-type MockRedisClient struct {
-	data      map[string]interface{}
-	scriptSHA map[string]string
+// fakeBucketClient 在内存中执行令牌桶脚本的扣减逻辑（不模拟令牌补充）
+type fakeBucketClient struct {
+	*mocks.RedisClientMock
+
+	mu     sync.Mutex
+	tokens map[string]int64
 }
 
-func NewMockRedisClient() *MockRedisClient {
-	return &MockRedisClient{
-		data:      make(map[string]interface{}),
-		scriptSHA: make(map[string]string),
+func newFakeBucketClient() *fakeBucketClient {
+	c := &fakeBucketClient{
+		RedisClientMock: &mocks.RedisClientMock{},
+		tokens:          make(map[string]int64),
 	}
+	c.EvalFunc = c.eval
+	c.DelFunc = c.del
+	c.HMGetFunc = c.hmGet
+	return c
 }
 
-func (m *MockRedisClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
-	cmd := redis.NewCmd(ctx, "evalsha", sha1)
-
-	// 模拟令牌桶脚本逻辑
-	if len(args) >= 5 {
-		capacity := args[0].(string)
-		tokensRequested := args[3].(string)
-
-		// 简化逻辑：如果请求的令牌数 <= 容量，则允许
-		if tokensRequested == "1" && capacity == "10" {
-			// 模拟成功的情况
-			cmd.SetVal([]interface{}{
-				int64(1), // allowed
-				int64(9), // remaining
-				int64(0), // retry_after
-				int64(1), // total_requests
-			})
-		} else {
-			// 模拟失败的情况
-			cmd.SetVal([]interface{}{
-				int64(0), // not allowed
-				int64(0), // remaining
-				int64(1), // retry_after (seconds)
-				int64(1), // total_requests
-			})
-		}
+func (c *fakeBucketClient) eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	capacity, requested := args[0].(int64), args[3].(int64)
+	tokens, ok := c.tokens[keys[0]]
+	if !ok {
+		tokens = capacity
 	}
 
+	cmd := redis.NewCmd(ctx)
+	if tokens >= requested {
+		c.tokens[keys[0]] = tokens - requested
+		cmd.SetVal([]interface{}{int64(1), tokens - requested, int64(0)})
+		return cmd
+	}
+	c.tokens[keys[0]] = tokens
+	cmd.SetVal([]interface{}{int64(0), tokens, int64(1)})
 	return cmd
 }
 
-func (m *MockRedisClient) ScriptLoad(ctx context.Context, script string) *redis.StringCmd {
-	cmd := redis.NewStringCmd(ctx, "script", "load", script)
-	sha := "mock_sha1_" + string(rune(len(m.scriptSHA)))
-	m.scriptSHA[sha] = script
-	cmd.SetVal(sha)
-	return cmd
-}
+func (c *fakeBucketClient) del(ctx context.Context, keys ...string) *redis.IntCmd {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-func (m *MockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
 	count := int64(0)
 	for _, key := range keys {
-		if _, exists := m.data[key]; exists {
-			delete(m.data, key)
+		if _, exists := c.tokens[key]; exists {
+			delete(c.tokens, key)
 			count++
 		}
 	}
-	cmd := redis.NewIntCmd(ctx, "del")
+	cmd := redis.NewIntCmd(ctx)
 	cmd.SetVal(count)
 	return cmd
 }
 
-func (m *MockRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
-	cmd := redis.NewStatusCmd(ctx, "ping")
-	cmd.SetVal("PONG")
+func (c *fakeBucketClient) hmGet(ctx context.Context, key string, fields ...string) *redis.SliceCmd {
+	cmd := redis.NewSliceCmd(ctx)
+	cmd.SetVal(make([]interface{}, len(fields)))
 	return cmd
 }
 
-// Implement minimal required methods for redis.Cmdable interface
-func (m *MockRedisClient) Pipeline() redis.Pipeliner { return nil }
-func (m *MockRedisClient) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
-	return nil, nil
-}
-func (m *MockRedisClient) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
-	return nil, nil
-}
-func (m *MockRedisClient) TxPipeline() redis.Pipeliner                        { return nil }
-func (m *MockRedisClient) Command(ctx context.Context) *redis.CommandsInfoCmd { return nil }
-func (m *MockRedisClient) CommandList(ctx context.Context, filter *redis.FilterBy) *redis.StringSliceCmd {
-	return nil
-}
-func (m *MockRedisClient) CommandGetKeys(ctx context.Context, commands ...interface{}) *redis.StringSliceCmd {
-	return nil
-}
-func (m *MockRedisClient) CommandGetKeysAndFlags(ctx context.Context, commands ...interface{}) *redis.KeyFlagsCmd {
-	return nil
-}
-func (m *MockRedisClient) ClientGetName(ctx context.Context) *redis.StringCmd             { return nil }
-func (m *MockRedisClient) Echo(ctx context.Context, message interface{}) *redis.StringCmd { return nil }
-func (m *MockRedisClient) Hello(ctx context.Context, ver int, username, password, clientName string) *redis.MapStringInterfaceCmd {
-	return nil
-}
-func (m *MockRedisClient) Quit(ctx context.Context) *redis.StatusCmd              { return nil }
-func (m *MockRedisClient) Select(ctx context.Context, index int) *redis.StatusCmd { return nil }
-func (m *MockRedisClient) SwapDB(ctx context.Context, index1, index2 int) *redis.StatusCmd {
-	return nil
+func newTestTokenBucket(t *testing.T, client limiter.RedisClient, burst int64) *limiter.TokenBucketLimiter {
+	t.Helper()
+	tb, err := limiter.NewTokenBucketLimiter(client, &limiter.Config{
+		Rate:      burst,
+		Window:    time.Minute,
+		Burst:     burst,
+		KeyPrefix: "test:tb",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+	return tb
 }
-func (m *MockRedisClient) ClientID(ctx context.Context) *redis.IntCmd { return nil }
 
 func TestNewTokenBucketLimiter(t *testing.T) {
-	client := NewMockRedisClient()
-
 	tests := []struct {
 		name       string
-		config     *Config
+		client     interface{}
+		config     *limiter.Config
 		wantErr    bool
 		wantPrefix string
 	}{
 		{
-			name: "valid config",
-			config: &Config{
+			name:   "valid config",
+			client: newFakeBucketClient(),
+			config: &limiter.Config{
 				Rate:      10,
 				Window:    time.Minute,
 				Burst:     20,
@@ -131,8 +110,9 @@ func TestNewTokenBucketLimiter(t *testing.T) {
 			wantPrefix: "test:tb",
 		},
 		{
-			name: "empty key prefix",
-			config: &Config{
+			name:   "empty key prefix",
+			client: newFakeBucketClient(),
+			config: &limiter.Config{
 				Rate:   10,
 				Window: time.Minute,
 				Burst:  20,
@@ -142,14 +122,21 @@ func TestNewTokenBucketLimiter(t *testing.T) {
 		},
 		{
 			name:    "nil config",
+			client:  newFakeBucketClient(),
 			config:  nil,
 			wantErr: true,
 		},
+		{
+			name:    "invalid client",
+			client:  "redis://localhost:6379",
+			config:  &limiter.Config{Rate: 10, Window: time.Minute, Burst: 20},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			limiter, err := NewTokenBucketLimiter(client, tt.config)
+			tb, err := limiter.NewTokenBucketLimiter(tt.client, tt.config)
 
 			if tt.wantErr && err == nil {
 				t.Errorf("NewTokenBucketLimiter() expected error but got none")
@@ -158,9 +145,13 @@ func TestNewTokenBucketLimiter(t *testing.T) {
 				t.Errorf("NewTokenBucketLimiter() unexpected error = %v", err)
 			}
 
-			if !tt.wantErr && limiter != nil {
-				if limiter.keyPrefix != tt.wantPrefix {
-					t.Errorf("NewTokenBucketLimiter() keyPrefix = %v, want %v", limiter.keyPrefix, tt.wantPrefix)
+			if !tt.wantErr && tb != nil {
+				client := tt.client.(*fakeBucketClient)
+				if _, err := tb.Allow(context.Background(), "user:1"); err != nil {
+					t.Fatalf("Allow() unexpected error = %v", err)
+				}
+				if key := client.EvalCalls()[0].Keys[0]; key != tt.wantPrefix+":user:1" {
+					t.Errorf("NewTokenBucketLimiter() key = %v, want prefix %v", key, tt.wantPrefix)
 				}
 			}
 		})
@@ -168,37 +159,23 @@ func TestNewTokenBucketLimiter(t *testing.T) {
 }
 
 func TestTokenBucketLimiter_Allow(t *testing.T) {
-	client := NewMockRedisClient()
-	config := &Config{
-		Rate:      10,
-		Window:    time.Minute,
-		Burst:     10,
-		KeyPrefix: "test:tb",
-	}
-
-	limiter, err := NewTokenBucketLimiter(client, config)
-	if err != nil {
-		t.Fatalf("Failed to create limiter: %v", err)
-	}
+	tb := newTestTokenBucket(t, newFakeBucketClient(), 10)
 
 	tests := []struct {
 		name        string
 		key         string
-		setupFunc   func()
 		wantAllowed bool
 		wantErr     bool
 	}{
 		{
 			name:        "first request allowed",
 			key:         "user:123",
-			setupFunc:   nil,
 			wantAllowed: true,
 			wantErr:     false,
 		},
 		{
 			name:        "another user allowed",
 			key:         "user:456",
-			setupFunc:   nil,
 			wantAllowed: true,
 			wantErr:     false,
 		},
@@ -206,11 +183,7 @@ func TestTokenBucketLimiter_Allow(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setupFunc != nil {
-				tt.setupFunc()
-			}
-
-			result, err := limiter.Allow(context.Background(), tt.key)
+			result, err := tb.Allow(context.Background(), tt.key)
 
 			if tt.wantErr && err == nil {
 				t.Errorf("Allow() expected error but got none")
@@ -244,18 +217,7 @@ func TestTokenBucketLimiter_Allow(t *testing.T) {
 }
 
 func TestTokenBucketLimiter_AllowN(t *testing.T) {
-	client := NewMockRedisClient()
-	config := &Config{
-		Rate:      10,
-		Window:    time.Minute,
-		Burst:     10,
-		KeyPrefix: "test:tb",
-	}
-
-	limiter, err := NewTokenBucketLimiter(client, config)
-	if err != nil {
-		t.Fatalf("Failed to create limiter: %v", err)
-	}
+	tb := newTestTokenBucket(t, newFakeBucketClient(), 10)
 
 	tests := []struct {
 		name        string
@@ -295,7 +257,7 @@ func TestTokenBucketLimiter_AllowN(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := limiter.AllowN(context.Background(), tt.key, tt.n)
+			result, err := tb.AllowN(context.Background(), tt.key, tt.n)
 
 			if tt.wantErr && err == nil {
 				t.Errorf("AllowN() expected error but got none")
@@ -308,41 +270,55 @@ func TestTokenBucketLimiter_AllowN(t *testing.T) {
 				if result.Allowed != tt.wantAllowed {
 					t.Errorf("AllowN() allowed = %v, want %v", result.Allowed, tt.wantAllowed)
 				}
+				if !result.Allowed && result.RetryAfter <= 0 {
+					t.Errorf("AllowN() retry_after should be positive when not allowed")
+				}
 			}
 		})
 	}
 }
 
-func TestTokenBucketLimiter_Reset(t *testing.T) {
-	client := NewMockRedisClient()
-	config := &Config{
-		Rate:      10,
-		Window:    time.Minute,
-		Burst:     10,
-		KeyPrefix: "test:tb",
+func TestTokenBucketLimiter_ScriptError(t *testing.T) {
+	client := &mocks.RedisClientMock{
+		EvalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+			cmd := redis.NewCmd(ctx)
+			cmd.SetErr(errors.New("connection refused"))
+			return cmd
+		},
 	}
+	tb := newTestTokenBucket(t, client, 10)
 
-	limiter, err := NewTokenBucketLimiter(client, config)
-	if err != nil {
-		t.Fatalf("Failed to create limiter: %v", err)
+	if _, err := tb.Allow(context.Background(), "user:123"); err == nil {
+		t.Error("Allow() expected script error but got none")
 	}
+}
+
+func TestTokenBucketLimiter_Reset(t *testing.T) {
+	client := newFakeBucketClient()
+	tb := newTestTokenBucket(t, client, 1)
 
 	key := "user:123"
 
-	// 先发起几个请求
-	_, err = limiter.Allow(context.Background(), key)
+	// 先耗尽令牌
+	result, err := tb.Allow(context.Background(), key)
 	if err != nil {
 		t.Fatalf("Failed to make initial request: %v", err)
 	}
+	if result, _ = tb.Allow(context.Background(), key); result.Allowed {
+		t.Fatalf("Allow() should be denied after the bucket is exhausted")
+	}
 
 	// 重置限流状态
-	err = limiter.Reset(context.Background(), key)
+	err = tb.Reset(context.Background(), key)
 	if err != nil {
 		t.Errorf("Reset() unexpected error = %v", err)
 	}
+	if calls := client.DelCalls(); len(calls) != 1 || calls[0].Keys[0] != "test:tb:"+key {
+		t.Errorf("Reset() Del calls = %+v", calls)
+	}
 
 	// 重置后应该能正常请求
-	result, err := limiter.Allow(context.Background(), key)
+	result, err = tb.Allow(context.Background(), key)
 	if err != nil {
 		t.Errorf("Allow() after Reset() unexpected error = %v", err)
 	}
@@ -353,22 +329,19 @@ func TestTokenBucketLimiter_Reset(t *testing.T) {
 }
 
 func TestTokenBucketLimiter_GetInfo(t *testing.T) {
-	client := NewMockRedisClient()
-	config := &Config{
+	config := &limiter.Config{
 		Rate:      10,
 		Window:    time.Minute,
 		Burst:     10,
 		KeyPrefix: "test:tb",
 	}
 
-	limiter, err := NewTokenBucketLimiter(client, config)
+	tb, err := limiter.NewTokenBucketLimiter(newFakeBucketClient(), config)
 	if err != nil {
 		t.Fatalf("Failed to create limiter: %v", err)
 	}
 
-	key := "user:123"
-
-	info, err := limiter.GetInfo(context.Background(), key)
+	info, err := tb.GetInfo(context.Background(), "user:123")
 	if err != nil {
 		t.Errorf("GetInfo() unexpected error = %v", err)
 	}
@@ -394,18 +367,7 @@ func TestTokenBucketLimiter_GetInfo(t *testing.T) {
 
 // 测试限流效果
 func TestTokenBucketLimiter_RateLimiting(t *testing.T) {
-	client := NewMockRedisClient()
-	config := &Config{
-		Rate:      5, // 每分钟5个请求
-		Window:    time.Minute,
-		Burst:     5, // 突发容量5
-		KeyPrefix: "test:tb",
-	}
-
-	limiter, err := NewTokenBucketLimiter(client, config)
-	if err != nil {
-		t.Fatalf("Failed to create limiter: %v", err)
-	}
+	tb := newTestTokenBucket(t, newFakeBucketClient(), 5) // 突发容量5
 
 	key := "user:rate_test"
 
@@ -414,7 +376,7 @@ func TestTokenBucketLimiter_RateLimiting(t *testing.T) {
 	deniedCount := 0
 
 	for i := 0; i < 10; i++ {
-		result, err := limiter.Allow(context.Background(), key)
+		result, err := tb.Allow(context.Background(), key)
 		if err != nil {
 			t.Errorf("Request %d failed: %v", i, err)
 			continue
@@ -427,44 +389,29 @@ func TestTokenBucketLimiter_RateLimiting(t *testing.T) {
 		}
 	}
 
-	t.Logf("Rate limiting test: %d allowed, %d denied", allowedCount, deniedCount)
-
-	// 在Mock环境中，我们的逻辑比较简化，这里主要验证功能调用正常
-	if allowedCount+deniedCount != 10 {
-		t.Errorf("Total requests should be 10, got %d", allowedCount+deniedCount)
+	if allowedCount != 5 || deniedCount != 5 {
+		t.Errorf("Rate limiting: %d allowed, %d denied, want 5 and 5", allowedCount, deniedCount)
 	}
 }
 
 // 测试并发安全
 func TestTokenBucketLimiter_Concurrent(t *testing.T) {
-	client := NewMockRedisClient()
-	config := &Config{
-		Rate:      10,
-		Window:    time.Minute,
-		Burst:     10,
-		KeyPrefix: "test:tb",
-	}
-
-	limiter, err := NewTokenBucketLimiter(client, config)
-	if err != nil {
-		t.Fatalf("Failed to create limiter: %v", err)
-	}
+	tb := newTestTokenBucket(t, newFakeBucketClient(), 10)
 
 	const concurrency = 20
-	results := make(chan *LimitResult, concurrency)
-	errors := make(chan error, concurrency)
+	results := make(chan *limiter.LimitResult, concurrency)
+	errs := make(chan error, concurrency)
 
 	// 并发请求
 	for i := 0; i < concurrency; i++ {
-		go func(index int) {
-			key := "user:concurrent_test"
-			result, err := limiter.Allow(context.Background(), key)
+		go func() {
+			result, err := tb.Allow(context.Background(), "user:concurrent_test")
 			if err != nil {
-				errors <- err
+				errs <- err
 			} else {
 				results <- result
 			}
-		}(i)
+		}()
 	}
 
 	// 收集结果
@@ -475,12 +422,14 @@ func TestTokenBucketLimiter_Concurrent(t *testing.T) {
 			if result.Allowed {
 				allowedCount++
 			}
-		case err := <-errors:
+		case err := <-errs:
 			t.Errorf("Concurrent request error: %v", err)
 		case <-time.After(5 * time.Second):
 			t.Fatal("Test timeout")
 		}
 	}
 
-	t.Logf("Concurrent test: %d/%d requests allowed", allowedCount, concurrency)
+	if allowedCount != 10 {
+		t.Errorf("Concurrent test: %d/%d requests allowed, want 10", allowedCount, concurrency)
+	}
 }
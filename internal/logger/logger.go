@@ -10,11 +10,50 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// Option 定制 New 的可选行为
+type Option func(*options)
+
+type options struct {
+	redactRules RedactRules
+	hashSalt    string
+	level       *zap.AtomicLevel
+}
+
+// WithRedactRules 追加脱敏规则，同名字段覆盖默认规则
+func WithRedactRules(rules RedactRules) Option {
+	return func(o *options) {
+		o.redactRules = rules
+	}
+}
+
+// WithHashSalt 设置 hash 脱敏使用的盐值
+func WithHashSalt(salt string) Option {
+	return func(o *options) {
+		o.hashSalt = salt
+	}
+}
+
+// WithAtomicLevel 使用调用方持有的日志级别，New 按 level 参数设置其初始值，之后可在运行期调整
+func WithAtomicLevel(level zap.AtomicLevel) Option {
+	return func(o *options) {
+		o.level = &level
+	}
+}
+
 // New 根据 env/level/encoding 构建 *zap.Logger。
 // - env: dev|test|prod（dev 使用 DevelopmentConfig，prod 使用 ProductionConfig）
 // - level: debug|info|warn|error
 // - encoding: json|console（生产建议 json）
-func New(env, level, encoding, serviceName, version string) (*zap.Logger, error) {
+// 字段在编码前会经过脱敏：DefaultRedactRules(env) 与 WithRedactRules 合并后生效。
+func New(env, level, encoding, serviceName, version string, opts ...Option) (*zap.Logger, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	hashSalt.Store(o.hashSalt)
+	hashIdentifiers.Store(env == "prod")
+	rules := DefaultRedactRules(env).Merge(o.redactRules)
+
 	var cfg zap.Config
 	if env == "prod" {
 		cfg = zap.NewProductionConfig()
@@ -35,6 +74,10 @@ func New(env, level, encoding, serviceName, version string) (*zap.Logger, error)
 	default:
 		cfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
 	}
+	if o.level != nil {
+		o.level.SetLevel(cfg.Level.Level())
+		cfg.Level = *o.level
+	}
 
 	// Encoding
 	if encoding == "console" {
@@ -55,7 +98,10 @@ func New(env, level, encoding, serviceName, version string) (*zap.Logger, error)
 	cfg.EncoderConfig.MessageKey = "msg"
 	cfg.EncoderConfig.CallerKey = "caller"
 
-	lg, err := cfg.Build(zap.AddCaller(), zap.AddCallerSkip(1))
+	lg, err := cfg.Build(zap.AddCaller(), zap.AddCallerSkip(1),
+		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return NewRedactCore(core, rules)
+		}))
 	if err != nil {
 		return nil, fmt.Errorf("build logger: %w", err)
 	}
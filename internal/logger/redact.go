@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactAction 表示对敏感字段的处理方式
+type RedactAction string
+
+const (
+	RedactDrop RedactAction = "drop" // 直接丢弃字段
+	RedactMask RedactAction = "mask" // 替换为固定掩码
+	RedactHash RedactAction = "hash" // 替换为加盐哈希，便于关联同一值但不可还原
+)
+
+const (
+	maskedValue = "***"
+	hashPrefix  = "sha256:"
+)
+
+// RedactRules 字段名（zap field key）到处理方式的映射，仅作用于顶层字段
+type RedactRules map[string]RedactAction
+
+// 标识类字段辅助函数的行为，由 New 按环境设置
+var (
+	hashIdentifiers atomic.Bool
+	hashSalt        atomic.Value // string
+)
+
+// DefaultRedactRules 返回按环境区分的默认脱敏规则。
+// 所有环境都会丢弃密码、掩码令牌；prod 额外哈希用户ID与幂等键并丢弃完整请求体。
+func DefaultRedactRules(env string) RedactRules {
+	rules := RedactRules{
+		"password":      RedactDrop,
+		"token":         RedactMask,
+		"access_token":  RedactMask,
+		"refresh_token": RedactMask,
+		"authorization": RedactMask,
+	}
+	if env == "prod" {
+		rules["user_id"] = RedactHash
+		rules["target_user_id"] = RedactHash
+		rules["idempotency_key"] = RedactHash
+		rules["payload"] = RedactDrop
+		rules["body"] = RedactDrop
+	}
+	return rules
+}
+
+// ParseRedactRules 解析形如 "field:action" 的规则列表，action 为 drop|mask|hash
+func ParseRedactRules(specs []string) (RedactRules, error) {
+	rules := make(RedactRules, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		field, action, ok := strings.Cut(spec, ":")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid redact rule %q, want field:action", spec)
+		}
+		switch a := RedactAction(strings.ToLower(strings.TrimSpace(action))); a {
+		case RedactDrop, RedactMask, RedactHash:
+			rules[field] = a
+		default:
+			return nil, fmt.Errorf("invalid redact action %q in rule %q, want drop|mask|hash", action, spec)
+		}
+	}
+	return rules, nil
+}
+
+// Merge 返回合并后的规则，other 中的同名字段覆盖当前规则
+func (r RedactRules) Merge(other RedactRules) RedactRules {
+	merged := make(RedactRules, len(r)+len(other))
+	for k, v := range r {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
+
+// RedactJSON 对 JSON 文本按规则处理任意层级的同名字段，用于调试时留存请求/响应体。
+// 非法 JSON 返回 ok=false，调用方不应原样留存
+func (r RedactRules) RedactJSON(data []byte) (redacted []byte, ok bool) {
+	// UseNumber 保留数字原文，避免大整数ID变成科学计数法后哈希结果与日志不一致
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	out, err := json.Marshal(r.redactValue(v))
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// redactValue 递归处理 JSON 值中的对象字段
+func (r RedactRules) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, field := range val {
+			action, ok := r[key]
+			if !ok {
+				val[key] = r.redactValue(field)
+				continue
+			}
+			switch action {
+			case RedactDrop:
+				delete(val, key)
+			case RedactMask:
+				val[key] = maskedValue
+			case RedactHash:
+				val[key] = Hash(fmt.Sprint(field))
+			}
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = r.redactValue(val[i])
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// Hash 返回带前缀的加盐 SHA-256 摘要（截断为16位十六进制），相同输入得到相同结果
+func Hash(value string) string {
+	salt, _ := hashSalt.Load().(string)
+	sum := sha256.Sum256([]byte(salt + value))
+	return hashPrefix + hex.EncodeToString(sum[:8])
+}
+
+// UserID 记录用户ID；prod 环境下默认输出哈希值
+func UserID(id int64) zap.Field {
+	if hashIdentifiers.Load() {
+		return zap.String("user_id", Hash(fmt.Sprintf("%d", id)))
+	}
+	return zap.Int64("user_id", id)
+}
+
+// IdempotencyKey 记录幂等键；prod 环境下默认输出哈希值
+func IdempotencyKey(key string) zap.Field {
+	if hashIdentifiers.Load() {
+		return zap.String("idempotency_key", Hash(key))
+	}
+	return zap.String("idempotency_key", key)
+}
+
+// redactCore 在编码前按规则处理字段的 zapcore.Core 包装
+type redactCore struct {
+	zapcore.Core
+	rules RedactRules
+}
+
+// NewRedactCore 包装 core，使写入的字段在编码前按规则被丢弃、掩码或哈希
+func NewRedactCore(core zapcore.Core, rules RedactRules) zapcore.Core {
+	if len(rules) == 0 {
+		return core
+	}
+	return &redactCore{Core: core, rules: rules}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact(fields)), rules: c.rules}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redact(fields))
+}
+
+// redact 返回处理后的字段副本，不修改调用方的切片
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		action, ok := c.rules[f.Key]
+		if !ok {
+			out = append(out, f)
+			continue
+		}
+		switch action {
+		case RedactDrop:
+			// 丢弃
+		case RedactMask:
+			out = append(out, zap.String(f.Key, maskedValue))
+		case RedactHash:
+			value := fieldString(f)
+			if !strings.HasPrefix(value, hashPrefix) {
+				value = Hash(value)
+			}
+			out = append(out, zap.String(f.Key, value))
+		default:
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// fieldString 将任意类型的字段值转换为字符串，用于哈希
+func fieldString(f zapcore.Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return fmt.Sprint(enc.Fields[f.Key])
+}
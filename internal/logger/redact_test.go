@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseRedactRules(t *testing.T) {
+	rules, err := ParseRedactRules([]string{"payload:drop", " user_id : HASH ", ""})
+	if err != nil {
+		t.Fatalf("ParseRedactRules() error = %v", err)
+	}
+	if rules["payload"] != RedactDrop || rules["user_id"] != RedactHash {
+		t.Errorf("ParseRedactRules() = %v", rules)
+	}
+
+	for _, spec := range []string{"payload", ":mask", "payload:encrypt"} {
+		if _, err := ParseRedactRules([]string{spec}); err == nil {
+			t.Errorf("ParseRedactRules(%q) expected error", spec)
+		}
+	}
+}
+
+func TestRedactCore(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	lg := zap.New(NewRedactCore(core, RedactRules{
+		"password":        RedactDrop,
+		"token":           RedactMask,
+		"user_id":         RedactHash,
+		"idempotency_key": RedactHash,
+	}))
+
+	lg.With(zap.String("token", "secret")).Info("login",
+		zap.String("password", "p@ss"),
+		zap.Int64("user_id", 42),
+		zap.String("idempotency_key", Hash("abc")),
+		zap.String("username", "alice"))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+
+	if _, ok := fields["password"]; ok {
+		t.Error("password should be dropped")
+	}
+	if fields["token"] != maskedValue {
+		t.Errorf("token = %v, want masked", fields["token"])
+	}
+	if fields["user_id"] != Hash("42") {
+		t.Errorf("user_id = %v, want %v", fields["user_id"], Hash("42"))
+	}
+	if fields["idempotency_key"] != Hash("abc") {
+		t.Errorf("idempotency_key = %v, should not be hashed twice", fields["idempotency_key"])
+	}
+	if fields["username"] != "alice" {
+		t.Errorf("username = %v, want untouched", fields["username"])
+	}
+}
+
+func TestUserID_HashesInProduction(t *testing.T) {
+	defer hashIdentifiers.Store(false)
+
+	hashIdentifiers.Store(false)
+	if f := UserID(7); f.Integer != 7 {
+		t.Errorf("UserID() in dev = %+v, want raw id", f)
+	}
+
+	hashIdentifiers.Store(true)
+	if f := UserID(7); !strings.HasPrefix(f.String, hashPrefix) {
+		t.Errorf("UserID() in prod = %+v, want hashed", f)
+	}
+}
+
+func TestRedactRules_RedactJSON(t *testing.T) {
+	rules := RedactRules{"password": RedactDrop, "token": RedactMask, "user_id": RedactHash}
+
+	out, ok := rules.RedactJSON([]byte(`{"username":"alice","password":"secret","data":{"token":"abc","user_id":1234567890,"items":[{"token":"x"}]}}`))
+	if !ok {
+		t.Fatal("RedactJSON() ok = false, want true")
+	}
+	got := string(out)
+	if strings.Contains(got, "secret") || strings.Contains(got, `"abc"`) || strings.Contains(got, `"x"`) {
+		t.Errorf("RedactJSON() leaked sensitive value: %s", got)
+	}
+	if !strings.Contains(got, `"user_id":"`+Hash("1234567890")+`"`) {
+		t.Errorf("RedactJSON() user_id not hashed: %s", got)
+	}
+	if !strings.Contains(got, `"username":"alice"`) {
+		t.Errorf("RedactJSON() dropped non-sensitive field: %s", got)
+	}
+
+	if _, ok := rules.RedactJSON([]byte("not json")); ok {
+		t.Error("RedactJSON() ok = true for invalid JSON")
+	}
+}
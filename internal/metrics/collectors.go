@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"time"
+)
+
+// 秒杀参与结果
+const (
+	OutcomeSuccess     = "success"
+	OutcomeSoldOut     = "sold_out"
+	OutcomeRateLimited = "rate_limited"
+	OutcomeQueued      = "queued"   // 进入等候室排队
+	OutcomeRejected    = "rejected" // 重复参与、活动未开始等业务拒绝
+	OutcomeError       = "error"
+)
+
+// 操作结果
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// 库存冲突重试的最终结果
+const (
+	ConflictRecovered = "recovered" // 重试后成功
+	ConflictExhausted = "exhausted" // 重试耗尽或请求结束时仍冲突
+	ConflictFailed    = "failed"    // 重试时遇到冲突以外的错误
+)
+
+var (
+	// HTTPRequestDuration HTTP 请求耗时，route 为路由模板
+	HTTPRequestDuration = DefaultRegistry.NewHistogramVec(
+		"spike_http_request_duration_seconds", "HTTP request latency by route.",
+		nil, "method", "route", "status")
+
+	// SpikeParticipations 秒杀参与结果计数
+	SpikeParticipations = DefaultRegistry.NewCounterVec(
+		"spike_participations_total", "Spike participation attempts by outcome.",
+		"outcome")
+
+	// RedisScriptDuration Redis Lua 脚本耗时
+	RedisScriptDuration = DefaultRegistry.NewHistogramVec(
+		"spike_redis_script_duration_seconds", "Redis Lua script latency by script and result.",
+		nil, "script", "result")
+
+	// MQPublished 消息发布结果计数
+	MQPublished = DefaultRegistry.NewCounterVec(
+		"spike_mq_published_total", "Messages published by message type and result.",
+		"type", "result")
+
+	// MQPublishFallback 发布失败降级计数，action 为 buffered、replayed、rejected 或 dropped
+	MQPublishFallback = DefaultRegistry.NewCounterVec(
+		"spike_mq_publish_fallback_total", "Failed publishes handled by the local fallback buffer by message type and action.",
+		"type", "action")
+
+	// MQConsumed 消息消费结果计数
+	MQConsumed = DefaultRegistry.NewCounterVec(
+		"spike_mq_consumed_total", "Messages consumed by queue and result.",
+		"queue", "result")
+
+	// DBQueryDuration 数据库语句耗时，operation 为语句首个关键字
+	DBQueryDuration = DefaultRegistry.NewHistogramVec(
+		"spike_db_query_duration_seconds", "Database statement latency by operation and result.",
+		nil, "operation", "result")
+
+	// StockReconcileDiscrepancies 库存对账发现的偏差计数，kind 为 sold_count 或 redis_stock
+	StockReconcileDiscrepancies = DefaultRegistry.NewCounterVec(
+		"spike_stock_reconcile_discrepancies_total", "Stock discrepancies found by reconciliation by kind.",
+		"kind")
+
+	// StockReconcileRepairs 库存对账修复结果计数
+	StockReconcileRepairs = DefaultRegistry.NewCounterVec(
+		"spike_stock_reconcile_repairs_total", "Stock discrepancy repairs by kind and result.",
+		"kind", "result")
+
+	// InventoryConflictRetries 库存变更因并发冲突（版本冲突、死锁、锁等待超时）发起的重试次数
+	InventoryConflictRetries = DefaultRegistry.NewCounterVec(
+		"spike_inventory_conflict_retries_total", "Inventory update retries caused by concurrent conflicts by operation.",
+		"operation")
+
+	// InventoryConflicts 发生过冲突的库存变更的最终结果，result 为 recovered、exhausted 或 failed
+	InventoryConflicts = DefaultRegistry.NewCounterVec(
+		"spike_inventory_conflicts_total", "Inventory updates that hit a concurrent conflict by operation and final result.",
+		"operation", "result")
+
+	// CacheInvalidations 跨实例缓存失效广播计数，direction 为 published 或 received
+	CacheInvalidations = DefaultRegistry.NewCounterVec(
+		"spike_cache_invalidations_total", "Cross-instance cache invalidation broadcasts by direction and result.",
+		"direction", "result")
+)
+
+// Result 按错误返回操作结果标签
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
+
+// Since 返回自 start 起经过的秒数，用作直方图观测值
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
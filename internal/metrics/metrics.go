@@ -0,0 +1,333 @@
+// Package metrics 提供进程内的 Prometheus 指标：计数器与直方图，按 Prometheus 文本格式通过 /metrics 暴露。
+//
+// 指标在包级变量中集中定义（见 collectors.go），各层直接调用 Inc / Observe 记录，
+// 标签取值须是有限集合（路由模板、结果码等），不能使用用户ID、订单ID等无界取值。
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuckets 默认耗时直方图分桶（秒），覆盖 1ms 到 5s
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// labelSeparator 拼接标签取值作为子指标键，不会出现在合法的 UTF-8 标签值中
+const labelSeparator = "\xff"
+
+// collector 可按文本格式输出的指标
+type collector interface {
+	metricName() string
+	write(w *bufio.Writer)
+}
+
+// Registry 指标注册表
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry 创建指标注册表
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// DefaultRegistry 默认注册表，collectors.go 中的指标都注册在这里
+var DefaultRegistry = NewRegistry()
+
+// register 注册指标，重名时 panic（指标在初始化阶段定义，重名属于编程错误）
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[c.metricName()]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", c.metricName()))
+	}
+	r.collectors[c.metricName()] = c
+}
+
+// Write 按名称顺序以 Prometheus 文本格式输出全部指标
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, len(names))
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler 返回暴露指标的 HTTP 处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.Write(w)
+	})
+}
+
+// desc 指标名称、说明与标签名
+type desc struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+func (d *desc) metricName() string { return d.name }
+
+// key 校验标签个数并拼接子指标键
+func (d *desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, labelSeparator)
+}
+
+// writeHeader 输出 HELP 与 TYPE 行
+func (d *desc) writeHeader(w *bufio.Writer, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, typ)
+}
+
+// labels 格式化标签对，extra 为追加的标签（如直方图的 le）
+func (d *desc) labels(labelValues []string, extra ...string) string {
+	if len(d.labelNames) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range d.labelNames {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(labelValues[i]))
+		b.WriteByte('"')
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extra[i])
+		b.WriteString(`="`)
+		b.WriteString(extra[i+1])
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	desc
+	mu     sync.RWMutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	bits        atomic.Uint64 // float64 的位表示
+}
+
+// NewCounterVec 在注册表中创建计数器
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, labelNames: labelNames}, values: make(map[string]*counterValue)}
+	r.register(c)
+	return c
+}
+
+// Inc 计数加一
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加 delta，delta 不能为负
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+	v := c.child(labelValues)
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Value 返回当前计数，主要用于测试
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	return math.Float64frombits(c.child(labelValues).bits.Load())
+}
+
+func (c *CounterVec) child(labelValues []string) *counterValue {
+	key := c.key(labelValues)
+	c.mu.RLock()
+	v, ok := c.values[key]
+	c.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok = c.values[key]; !ok {
+		v = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = v
+	}
+	return v
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w, "counter")
+	for _, key := range c.sortedKeys() {
+		c.mu.RLock()
+		v := c.values[key]
+		c.mu.RUnlock()
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labels(v.labelValues), formatFloat(math.Float64frombits(v.bits.Load())))
+	}
+}
+
+func (c *CounterVec) sortedKeys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.RWMutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	mu          sync.Mutex
+	counts      []uint64 // 各分桶（非累计）计数，最后一个为 +Inf
+	sum         float64
+	count       uint64
+}
+
+// NewHistogramVec 在注册表中创建直方图，buckets 须升序，为空时使用 DefaultBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: histogram %s buckets must be sorted", name))
+	}
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, labelNames: labelNames},
+		buckets: append([]float64(nil), buckets...),
+		values:  make(map[string]*histogramValue),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	v := h.child(labelValues)
+	i := sort.SearchFloat64s(h.buckets, value) // 第一个 >= value 的分桶
+	v.mu.Lock()
+	v.counts[i]++
+	v.sum += value
+	v.count++
+	v.mu.Unlock()
+}
+
+// Count 返回观测次数，主要用于测试
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	v := h.child(labelValues)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.count
+}
+
+func (h *HistogramVec) child(labelValues []string) *histogramValue {
+	key := h.key(labelValues)
+	h.mu.RLock()
+	v, ok := h.values[key]
+	h.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if v, ok = h.values[key]; !ok {
+		v = &histogramValue{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)+1),
+		}
+		h.values[key] = v
+	}
+	return v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w, "histogram")
+
+	h.mu.RLock()
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	h.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		h.mu.RLock()
+		v := h.values[key]
+		h.mu.RUnlock()
+
+		v.mu.Lock()
+		counts := append([]uint64(nil), v.counts...)
+		sum, count := v.sum, v.count
+		v.mu.Unlock()
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labels(v.labelValues, "le", formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labels(v.labelValues, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labels(v.labelValues), formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labels(v.labelValues), count)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpReplacer.Replace(s) }
+func escapeLabelValue(s string) string { return labelReplacer.Replace(s) }
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounterVec("test_requests_total", "Requests.\nSecond line.", "outcome")
+	histogram := r.NewHistogramVec("test_duration_seconds", "Duration.", []float64{0.1, 1}, "route")
+
+	counter.Inc("success")
+	counter.Add(2, "success")
+	counter.Inc(`sold"out`)
+	histogram.Observe(0.05, "/api/v1/spike/participate")
+	histogram.Observe(0.5, "/api/v1/spike/participate")
+	histogram.Observe(3, "/api/v1/spike/participate")
+
+	if got := counter.Value("success"); got != 3 {
+		t.Errorf("counter value = %v, want 3", got)
+	}
+	if got := histogram.Count("/api/v1/spike/participate"); got != 3 {
+		t.Errorf("histogram count = %d, want 3", got)
+	}
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+
+	want := `# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/api/v1/spike/participate",le="0.1"} 1
+test_duration_seconds_bucket{route="/api/v1/spike/participate",le="1"} 2
+test_duration_seconds_bucket{route="/api/v1/spike/participate",le="+Inf"} 3
+test_duration_seconds_sum{route="/api/v1/spike/participate"} 3.55
+test_duration_seconds_count{route="/api/v1/spike/participate"} 3
+# HELP test_requests_total Requests.\nSecond line.
+# TYPE test_requests_total counter
+test_requests_total{outcome="sold\"out"} 1
+test_requests_total{outcome="success"} 3
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("exposition mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_DuplicateMetricPanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Test.")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate metric should panic")
+		}
+	}()
+	r.NewCounterVec("test_total", "Test.")
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+// gin 上下文中保存处理器提供的审计信息的键
+const (
+	ginKeyAdminAudit       = "admin_audit"
+	ginKeyAuditEntityType  = "audit_entity_type"
+	ginKeyAuditEntityID    = "audit_entity_id"
+	ginKeyAuditBeforeValue = "audit_before"
+	ginKeyAuditAfterValue  = "audit_after"
+)
+
+// AdminAuditRecorder 写入管理员操作审计（由 service.AdminAuditService 实现）
+type AdminAuditRecorder interface {
+	Record(ctx context.Context, entry *domain.AdminAuditLog)
+}
+
+// AdminAuditConfig 表示管理员操作审计中间件配置
+type AdminAuditConfig struct {
+	Recorder        AdminAuditRecorder
+	MaxPayloadBytes int                // 变更前后数据与请求体最多留存的字节数，超出时以占位文本代替
+	RedactRules     logger.RedactRules // 请求体与变更数据的脱敏规则
+}
+
+// AdminAudit 返回管理员操作审计中间件，需注册在管理员权限中间件之后。
+// 对写请求（非 GET/HEAD/OPTIONS）在处理完成后记录操作者、路由、对象、响应状态与请求ID；
+// 处理器可通过 SetAuditEntity、SetAuditBefore、SetAuditAfter 提供对象与变更前后数据，
+// 未提供变更后数据时记录脱敏后的请求体
+func AdminAudit(cfg AdminAuditConfig) gin.HandlerFunc {
+	if cfg.MaxPayloadBytes <= 0 {
+		cfg.MaxPayloadBytes = 16 * 1024
+	}
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		c.Set(ginKeyAdminAudit, true)
+		requestBody := peekRequestBody(c, cfg.MaxPayloadBytes)
+
+		c.Next()
+
+		entityType, entityID := auditEntityFromRoute(c)
+		if v, ok := c.Get(ginKeyAuditEntityType); ok {
+			entityType, entityID = v.(string), c.GetString(ginKeyAuditEntityID)
+		}
+
+		entry := &domain.AdminAuditLog{
+			Actor:      domain.AdminActor(c.GetInt64("user_id")),
+			Action:     c.Request.Method + " " + c.FullPath(),
+			EntityType: entityType,
+			EntityID:   entityID,
+			StatusCode: c.Writer.Status(),
+			RequestID:  ginRequestID(c),
+			ClientIP:   c.ClientIP(),
+		}
+		if v, ok := c.Get(ginKeyAuditBeforeValue); ok {
+			entry.Before = auditPayload(marshalAuditValue(v), cfg)
+		}
+		if v, ok := c.Get(ginKeyAuditAfterValue); ok {
+			entry.After = auditPayload(marshalAuditValue(v), cfg)
+		} else {
+			entry.After = auditPayload(requestBody, cfg)
+		}
+
+		// 响应已写出，客户端断开不应导致审计丢失
+		cfg.Recorder.Record(context.WithoutCancel(c.Request.Context()), entry)
+	}
+}
+
+// AuditEnabled 判断当前请求是否会被记录审计，处理器据此决定是否读取变更前数据
+func AuditEnabled(c *gin.Context) bool {
+	return c.GetBool(ginKeyAdminAudit)
+}
+
+// SetAuditEntity 指定审计记录的操作对象，未指定时取路由中第一个路径参数及其前面的资源名
+func SetAuditEntity(c *gin.Context, entityType string, entityID any) {
+	c.Set(ginKeyAuditEntityType, entityType)
+	if entityID == nil {
+		c.Set(ginKeyAuditEntityID, "")
+		return
+	}
+	c.Set(ginKeyAuditEntityID, fmt.Sprint(entityID))
+}
+
+// SetAuditBefore 记录操作对象变更前的数据
+func SetAuditBefore(c *gin.Context, before any) {
+	c.Set(ginKeyAuditBeforeValue, before)
+}
+
+// SetAuditAfter 记录操作对象变更后的数据，替代默认记录的请求体
+func SetAuditAfter(c *gin.Context, after any) {
+	c.Set(ginKeyAuditAfterValue, after)
+}
+
+// auditEntityFromRoute 从路由模板推断操作对象：如 /api/v1/admin/products/:id/stop-sell 为 products 与 id 参数值
+func auditEntityFromRoute(c *gin.Context) (entityType, entityID string) {
+	segments := strings.Split(strings.Trim(c.FullPath(), "/"), "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		if i > 0 {
+			entityType = segments[i-1]
+		}
+		return entityType, c.Param(segment[1:])
+	}
+	return "", ""
+}
+
+// marshalAuditValue 序列化处理器提供的数据，无法序列化时返回 nil
+func marshalAuditValue(v any) []byte {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// auditPayload 按脱敏规则处理留存的数据，超长或非 JSON 的数据以 JSON 字符串形式的占位文本代替
+func auditPayload(data []byte, cfg AdminAuditConfig) json.RawMessage {
+	redacted, placeholder := redactBody(data, cfg.MaxPayloadBytes, cfg.RedactRules)
+	if placeholder != "" {
+		quoted, _ := json.Marshal(placeholder)
+		return quoted
+	}
+	return redacted
+}
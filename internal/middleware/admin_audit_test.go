@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+// memAuditRecorder 审计记录内存实现
+type memAuditRecorder struct {
+	entries []*domain.AdminAuditLog
+}
+
+func (r *memAuditRecorder) Record(ctx context.Context, entry *domain.AdminAuditLog) {
+	r.entries = append(r.entries, entry)
+}
+
+func setupAdminAuditRouter(recorder *memAuditRecorder, maxPayloadBytes int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+		c.Set("request_id", "req-1")
+		c.Next()
+	})
+	r.Use(AdminAudit(AdminAuditConfig{
+		Recorder:        recorder,
+		MaxPayloadBytes: maxPayloadBytes,
+		RedactRules:     logger.DefaultRedactRules("dev"),
+	}))
+	r.PUT("/admin/users/:id/password", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		if !strings.Contains(string(body), "alice") {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.POST("/admin/spike/events/:id/pause", func(c *gin.Context) {
+		if !AuditEnabled(c) {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		SetAuditEntity(c, domain.AdminAuditEntitySpikeEvent, 42)
+		SetAuditBefore(c, map[string]string{"status": "active"})
+		SetAuditAfter(c, map[string]string{"status": "paused"})
+		c.Status(http.StatusOK)
+	})
+	r.GET("/admin/spike/events/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestAdminAudit_RecordsRequestBodyAndRouteEntity(t *testing.T) {
+	recorder := &memAuditRecorder{}
+	r := setupAdminAuditRouter(recorder, 1024)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/3/password", strings.NewReader(`{"username":"alice","password":"secret"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want body forwarded to handler", w.Code)
+	}
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.Actor != "admin:7" || entry.Action != "PUT /admin/users/:id/password" ||
+		entry.EntityType != "users" || entry.EntityID != "3" ||
+		entry.StatusCode != http.StatusOK || entry.RequestID != "req-1" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Before != nil {
+		t.Errorf("Before = %s, want nil", entry.Before)
+	}
+	if after := string(entry.After); strings.Contains(after, "secret") || !strings.Contains(after, "alice") {
+		t.Errorf("After = %s, want redacted request body", after)
+	}
+}
+
+func TestAdminAudit_HandlerProvidedChange(t *testing.T) {
+	recorder := &memAuditRecorder{}
+	r := setupAdminAuditRouter(recorder, 1024)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/admin/spike/events/42/pause", strings.NewReader(`{"reason":"x"}`)))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/admin/spike/events/42", nil))
+
+	if len(recorder.entries) != 1 {
+		t.Fatalf("entries = %d, want 1 (reads not audited)", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.EntityType != domain.AdminAuditEntitySpikeEvent || entry.EntityID != "42" {
+		t.Errorf("entity = %s/%s", entry.EntityType, entry.EntityID)
+	}
+	if string(entry.Before) != `{"status":"active"}` || string(entry.After) != `{"status":"paused"}` {
+		t.Errorf("before = %s, after = %s", entry.Before, entry.After)
+	}
+}
+
+func TestAdminAudit_OversizedBodyReplacedWithPlaceholder(t *testing.T) {
+	recorder := &memAuditRecorder{}
+	r := setupAdminAuditRouter(recorder, 16)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/3/password", strings.NewReader(`{"username":"alice","note":"long enough"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want full body forwarded to handler", w.Code)
+	}
+
+	if len(recorder.entries) != 1 || string(recorder.entries[0].After) != `"[body truncated]"` {
+		t.Errorf("entries = %+v, want truncated placeholder", recorder.entries)
+	}
+}
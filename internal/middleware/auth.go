@@ -74,6 +74,7 @@ func AuthMiddleware(jwtService service.JWTService, logger *zap.Logger) func(http
 				ID:       claims.UserID,
 				Username: claims.Username,
 				Role:     claims.Role,
+				Tier:     claims.Tier.OrDefault(),
 				IsActive: true, // 从有效令牌假设用户是活跃的
 			}
 
@@ -177,6 +178,7 @@ func OptionalAuth(jwtService service.JWTService, logger *zap.Logger) func(http.H
 				ID:       claims.UserID,
 				Username: claims.Username,
 				Role:     claims.Role,
+				Tier:     claims.Tier.OrDefault(),
 				IsActive: true,
 			}
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -35,6 +36,7 @@ func (m *MockJWTService) GenerateTokenPair(user *domain.User) (*service.TokenPai
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Tier:     user.Tier,
 		Type:     "access",
 	}
 	m.validTokens[accessToken] = claims
@@ -43,6 +45,7 @@ func (m *MockJWTService) GenerateTokenPair(user *domain.User) (*service.TokenPai
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Tier:     user.Tier,
 		Type:     "refresh",
 	}
 	m.validTokens[refreshToken] = refreshClaims
@@ -102,6 +105,19 @@ func (m *MockJWTService) RefreshTokenPair(refreshToken string) (*service.TokenPa
 	return m.GenerateTokenPair(user)
 }
 
+func (m *MockJWTService) GenerateImpersonationToken(admin, target *domain.User) (string, time.Time, error) {
+	token := "mock_impersonation_token_" + target.Username
+	m.validTokens[token] = &service.Claims{
+		UserID:         target.ID,
+		Username:       target.Username,
+		Role:           target.Role,
+		Type:           "access",
+		ImpersonatorID: admin.ID,
+		Scope:          service.ScopeSupport,
+	}
+	return token, time.Now().Add(10 * time.Minute), nil
+}
+
 func (m *MockJWTService) AddExpiredToken(token string) {
 	m.expiredTokens[token] = true
 }
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionConfig 表示响应压缩配置。
+type CompressionConfig struct {
+	// MinSize 响应体达到该字节数才压缩，过小的响应压缩后反而更大；0 表示总是压缩
+	MinSize int
+}
+
+// gzipWriters 复用 gzip 编码器，避免每个请求重新分配压缩字典
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// GinCompression 按 Accept-Encoding 对 JSON、文本与 CSV 响应做 gzip 压缩。
+// 响应体先缓冲到 MinSize，超过后切换为流式压缩，大文件下载不会整体驻留内存；
+// 已设置 Content-Encoding 的响应原样输出。
+func GinCompression(cfg CompressionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &gzipWriter{ResponseWriter: original, minSize: cfg.MinSize, status: original.Status()}
+		c.Writer = writer
+		defer func() { c.Writer = original }()
+
+		c.Next()
+		writer.finish()
+	}
+}
+
+// acceptsGzip 判断 Accept-Encoding 是否接受 gzip（q=0 表示明确拒绝）
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible 判断内容类型是否值得压缩，图片等已压缩格式不再压缩
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/javascript"
+}
+
+// gzipWriter 推迟写出状态码，响应体达到 minSize 后再决定是否压缩
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize int
+	status  int
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	direct  bool // 已决定不压缩，后续写入直接透传
+	written bool // 处理器已设置状态码或写入数据
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	w.status = code
+	w.written = true
+}
+
+func (w *gzipWriter) Written() bool {
+	return w.written || w.ResponseWriter.Written()
+}
+
+// WriteHeaderNow gin 在写出响应头时调用，此时才能确定是否压缩
+func (w *gzipWriter) WriteHeaderNow() {}
+
+func (w *gzipWriter) Status() int {
+	if w.gz == nil && !w.direct {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	switch {
+	case w.gz != nil:
+		return w.gz.Write(data)
+	case w.direct:
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.written = true
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式响应（如逐行输出的 CSV）需要把已压缩的数据立即发送给客户端
+func (w *gzipWriter) Flush() {
+	if w.gz == nil && !w.direct {
+		_ = w.start()
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start 写出响应头并输出已缓冲的数据，满足条件时此后的写入经 gzip 编码
+func (w *gzipWriter) start() error {
+	h := w.ResponseWriter.Header()
+	if h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) ||
+		w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		w.direct = true
+		w.ResponseWriter.WriteHeader(w.status)
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+		return err
+	}
+
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.gz = gzipWriters.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	_, err := w.gz.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish 未达到压缩阈值的响应原样写出，已压缩的响应写出 gzip 尾部并归还编码器；
+// 处理器未写入任何内容时不改动底层响应，由 gin 按原状态码输出（如未匹配路由的 404）
+func (w *gzipWriter) finish() {
+	switch {
+	case w.gz != nil:
+		_ = w.gz.Close()
+		w.gz.Reset(nil)
+		gzipWriters.Put(w.gz)
+	case !w.direct && w.written:
+		w.ResponseWriter.WriteHeader(w.status)
+		if w.buf.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		}
+	}
+}
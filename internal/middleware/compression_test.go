@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// setupCompressionRouter /items 返回 n 个商品名，request_id 取自查询参数以模拟每次请求不同的链路标识
+func setupCompressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinCompression(CompressionConfig{MinSize: 256}), GinETag())
+	r.GET("/items", func(c *gin.Context) {
+		items := strings.Split(strings.Repeat("商品,", len(c.Query("n"))), ",")
+		resp.OK(c.Writer, &items, c.Query("req"), "")
+	})
+	r.POST("/items", func(c *gin.Context) {
+		resp.OK(c.Writer, &struct{}{}, "", "")
+	})
+	r.GET("/export", func(c *gin.Context) {
+		c.Header("Content-Type", "text/csv")
+		c.String(http.StatusOK, strings.Repeat("id,name\n", 100))
+	})
+	return r
+}
+
+func doRequest(r http.Handler, method, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGinCompression(t *testing.T) {
+	r := setupCompressionRouter()
+	large := "/items?n=" + strings.Repeat("x", 100)
+
+	w := doRequest(r, http.MethodGet, large, map[string]string{"Accept-Encoding": "br, gzip;q=0.8"})
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil || !strings.Contains(string(body), `"data":["商品"`) {
+		t.Errorf("decompressed body = %q, %v", body, err)
+	}
+
+	// 小响应、未声明支持 gzip 或明确拒绝 gzip 时不压缩
+	for name, tc := range map[string]struct {
+		target, acceptEncoding string
+	}{
+		"small":     {"/items?n=x", "gzip"},
+		"no gzip":   {large, "br"},
+		"q=0":       {large, "gzip;q=0"},
+		"no header": {large, ""},
+	} {
+		w := doRequest(r, http.MethodGet, tc.target, map[string]string{"Accept-Encoding": tc.acceptEncoding})
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("%s: Content-Encoding = %q, want none", name, got)
+		}
+		if !strings.Contains(w.Body.String(), `"code":0`) {
+			t.Errorf("%s: body = %q, want plain JSON", name, w.Body.String())
+		}
+	}
+
+	w = doRequest(r, http.MethodGet, "/export", map[string]string{"Accept-Encoding": "gzip"})
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("ETag") != "" {
+		t.Errorf("CSV export headers = %v, want gzip without ETag", w.Header())
+	}
+
+	// 未匹配路由保持 gin 默认的 404 响应
+	w = doRequest(r, http.MethodGet, "/missing", map[string]string{"Accept-Encoding": "gzip"})
+	if w.Code != http.StatusNotFound || w.Body.String() != "404 page not found" {
+		t.Errorf("unmatched route = %d %q, want gin 404", w.Code, w.Body.String())
+	}
+}
+
+func TestGinETag(t *testing.T) {
+	r := setupCompressionRouter()
+
+	first := doRequest(r, http.MethodGet, "/items?n=xx&req=a", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("first response = %d ETag %q, want 200 with weak ETag", first.Code, etag)
+	}
+
+	// request_id 不同但结果集相同，ETag 不变
+	second := doRequest(r, http.MethodGet, "/items?n=xx&req=b", map[string]string{"If-None-Match": etag})
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 || second.Header().Get("ETag") != etag {
+		t.Errorf("revalidation = %d %q ETag %q, want empty 304", second.Code, second.Body.String(), second.Header().Get("ETag"))
+	}
+
+	// 压缩后的响应使用同一个 ETag
+	gzipped := doRequest(r, http.MethodGet, "/items?n=xx&req=c", map[string]string{"If-None-Match": etag, "Accept-Encoding": "gzip"})
+	if gzipped.Code != http.StatusNotModified || gzipped.Header().Get("Content-Encoding") != "" {
+		t.Errorf("gzip revalidation = %d encoding %q, want uncompressed 304", gzipped.Code, gzipped.Header().Get("Content-Encoding"))
+	}
+
+	changed := doRequest(r, http.MethodGet, "/items?n=xxx", map[string]string{"If-None-Match": etag})
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Errorf("changed result = %d ETag %q, want 200 with new ETag", changed.Code, changed.Header().Get("ETag"))
+	}
+
+	if post := doRequest(r, http.MethodPost, "/items", nil); post.Header().Get("ETag") != "" {
+		t.Errorf("POST ETag = %q, want none", post.Header().Get("ETag"))
+	}
+}
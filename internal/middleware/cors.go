@@ -2,31 +2,30 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
-// CORSConfig 表示允许的跨域配置，均为白名单列表（大小写不敏感由调用方保证）。
+// CORSConfig 表示允许的跨域配置，均为白名单列表（来源比较大小写不敏感）。
+// AllowedOrigins 包含 "*" 表示允许任意来源；启用 AllowCredentials 时不会返回 "*"，
+// 而是回显请求来源，因此携带凭证时应配置明确的来源白名单。
 type CORSConfig struct {
-	AllowedOrigins []string
-	AllowedMethods []string
-	AllowedHeaders []string
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // 预检结果缓存时长，0 表示不返回 Access-Control-Max-Age
 }
 
 // CORS 根据配置设置 CORS 响应头，并处理预检（OPTIONS）请求。
 func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
-	allowedOrigins := strings.Join(cfg.AllowedOrigins, ", ")
-	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
-	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
-
+	policy := newCORSPolicy(cfg)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", allowedOrigins)
-			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
-			w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
-			w.Header().Set("Vary", "Origin")
-			w.Header().Set("Vary", "Access-Control-Request-Method")
-			w.Header().Set("Vary", "Access-Control-Request-Headers")
-
+			policy.apply(w.Header(), r)
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusNoContent)
 				return
@@ -35,3 +34,80 @@ func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// GinCORS 是 CORS 的 Gin 版本。
+func GinCORS(cfg CORSConfig) gin.HandlerFunc {
+	policy := newCORSPolicy(cfg)
+	return func(c *gin.Context) {
+		policy.apply(c.Writer.Header(), c.Request)
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// corsPolicy 预先计算好的 CORS 响应头
+type corsPolicy struct {
+	anyOrigin        bool
+	origins          map[string]struct{}
+	allowedMethods   string
+	allowedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:          make(map[string]struct{}, len(cfg.AllowedOrigins)),
+		allowedMethods:   strings.Join(cfg.AllowedMethods, ", "),
+		allowedHeaders:   strings.Join(cfg.AllowedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		p.origins[strings.ToLower(origin)] = struct{}{}
+	}
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge / time.Second))
+	}
+	return p
+}
+
+// apply 为允许的来源写入 CORS 响应头，不允许的来源不写入，由浏览器拦截
+func (p *corsPolicy) apply(h http.Header, r *http.Request) {
+	h.Add("Vary", "Origin")
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+
+	_, listed := p.origins[strings.ToLower(origin)]
+	switch {
+	case p.anyOrigin && !p.allowCredentials:
+		h.Set("Access-Control-Allow-Origin", "*")
+	case listed || p.anyOrigin:
+		h.Set("Access-Control-Allow-Origin", origin)
+	default:
+		return
+	}
+
+	if p.allowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if r.Method == http.MethodOptions {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", p.allowedMethods)
+		h.Set("Access-Control-Allow-Headers", p.allowedHeaders)
+		if p.maxAge != "" {
+			h.Set("Access-Control-Max-Age", p.maxAge)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+// 未能留存的请求/响应体占位
+const (
+	debugBodyOmitted   = "[non-JSON body omitted]"
+	debugBodyTruncated = "[body truncated]"
+)
+
+// DebugCaptureSettings 表示可由管理员在运行时调整的调试捕获设置。
+type DebugCaptureSettings struct {
+	Enabled      bool     `json:"enabled"`
+	Routes       []string `json:"routes"`                            // 需要捕获的路由模板（如 /api/v1/spike/participate），为空时不捕获
+	SampleRate   float64  `json:"sample_rate" binding:"gte=0,lte=1"` // 采样率，取值 (0, 1]
+	OnlyFailures bool     `json:"only_failures"`                     // 仅留存状态码 >= 400 的请求
+}
+
+// DebugCaptureConfig 表示调试捕获配置。
+type DebugCaptureConfig struct {
+	DebugCaptureSettings
+	MaxBodyBytes int                // 单个请求/响应体最多读取的字节数，超出时不留存正文
+	BufferSize   int                // 环形缓冲区容量，写满后覆盖最旧记录
+	RedactRules  logger.RedactRules // 正文与查询参数的脱敏规则
+}
+
+// DebugCaptureEntry 表示一次被捕获的请求。
+type DebugCaptureEntry struct {
+	ID           int64     `json:"id"`
+	CapturedAt   time.Time `json:"captured_at"`
+	Method       string    `json:"method"`
+	Route        string    `json:"route"`
+	Path         string    `json:"path"`
+	Query        string    `json:"query,omitempty"`
+	Status       int       `json:"status"`
+	LatencyMs    int64     `json:"latency_ms"`
+	RequestID    string    `json:"request_id,omitempty"`
+	TraceID      string    `json:"trace_id,omitempty"`
+	UserID       int64     `json:"user_id,omitempty"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+}
+
+// DebugCapture 按路由采样捕获脱敏后的请求/响应体，存入内存环形缓冲区，
+// 供活动期间排查客户端对接问题。缓冲区为单实例内存，多实例部署时需逐个实例查询。
+type DebugCapture struct {
+	maxBodyBytes int
+	rules        logger.RedactRules
+
+	mu       sync.RWMutex
+	settings DebugCaptureSettings
+	routes   map[string]struct{}
+	entries  []DebugCaptureEntry
+	next     int // 下一条写入位置
+	count    int
+	seq      int64
+}
+
+// NewDebugCapture 创建调试捕获器
+func NewDebugCapture(cfg DebugCaptureConfig) *DebugCapture {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 4096
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 200
+	}
+	d := &DebugCapture{
+		maxBodyBytes: cfg.MaxBodyBytes,
+		rules:        cfg.RedactRules,
+		entries:      make([]DebugCaptureEntry, cfg.BufferSize),
+	}
+	d.UpdateSettings(cfg.DebugCaptureSettings)
+	return d
+}
+
+// Settings 返回当前设置
+func (d *DebugCapture) Settings() DebugCaptureSettings {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	settings := d.settings
+	settings.Routes = append([]string(nil), d.settings.Routes...)
+	return settings
+}
+
+// UpdateSettings 更新设置，采样率不在 (0, 1] 内时按 1 处理
+func (d *DebugCapture) UpdateSettings(settings DebugCaptureSettings) {
+	if settings.SampleRate <= 0 || settings.SampleRate > 1 {
+		settings.SampleRate = 1
+	}
+	routes := make(map[string]struct{}, len(settings.Routes))
+	for _, route := range settings.Routes {
+		if route = strings.TrimSpace(route); route != "" {
+			routes[route] = struct{}{}
+		}
+	}
+	settings.Routes = append([]string(nil), settings.Routes...)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.settings = settings
+	d.routes = routes
+}
+
+// Entries 按时间倒序返回捕获记录，route 为空时返回全部路由，limit <= 0 时不限制条数
+func (d *DebugCapture) Entries(route string, limit int) []DebugCaptureEntry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result := make([]DebugCaptureEntry, 0, d.count)
+	for i := 1; i <= d.count; i++ {
+		entry := d.entries[(d.next-i+len(d.entries))%len(d.entries)]
+		if route != "" && entry.Route != route {
+			continue
+		}
+		result = append(result, entry)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// Clear 清空捕获记录
+func (d *DebugCapture) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.entries)
+	d.next = 0
+	d.count = 0
+}
+
+// Middleware 返回捕获中间件，需注册在 GinRequestContext 之后
+func (d *DebugCapture) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		onlyFailures, ok := d.shouldCapture(c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		requestBody := peekRequestBody(c, d.maxBodyBytes)
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: d.maxBodyBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		status := writer.Status()
+		if onlyFailures && status < 400 {
+			return
+		}
+		d.add(DebugCaptureEntry{
+			CapturedAt:   start,
+			Method:       c.Request.Method,
+			Route:        c.FullPath(),
+			Path:         c.Request.URL.Path,
+			Query:        d.sanitizeQuery(c.Request.URL.RawQuery),
+			Status:       status,
+			LatencyMs:    time.Since(start).Milliseconds(),
+			RequestID:    c.GetString("request_id"),
+			TraceID:      c.GetString("trace_id"),
+			UserID:       c.GetInt64("user_id"),
+			RequestBody:  d.sanitizeBody(requestBody),
+			ResponseBody: d.sanitizeBody(writer.captured()),
+		})
+	}
+}
+
+// shouldCapture 判断路由是否启用捕获并按采样率抽样
+func (d *DebugCapture) shouldCapture(route string) (onlyFailures bool, ok bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if !d.settings.Enabled || route == "" {
+		return false, false
+	}
+	if _, listed := d.routes[route]; !listed {
+		return false, false
+	}
+	if d.settings.SampleRate < 1 && rand.Float64() >= d.settings.SampleRate {
+		return false, false
+	}
+	return d.settings.OnlyFailures, true
+}
+
+// peekRequestBody 读取最多 limit+1 字节的请求体，并把已读部分放回供后续处理器读取
+func peekRequestBody(c *gin.Context, limit int) []byte {
+	if c.Request.Body == nil {
+		return nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+	c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), c.Request.Body), Closer: c.Request.Body}
+	if err != nil {
+		return nil
+	}
+	return buf
+}
+
+// sanitizeBody 超长或非 JSON 的正文不留存，JSON 正文按脱敏规则处理
+func (d *DebugCapture) sanitizeBody(body []byte) string {
+	redacted, placeholder := redactBody(body, d.maxBodyBytes, d.rules)
+	if placeholder != "" {
+		return placeholder
+	}
+	return string(redacted)
+}
+
+// redactBody 按脱敏规则处理 JSON 正文；超过 limit 字节或非 JSON 的正文返回占位文本，空正文两者均为空
+func redactBody(body []byte, limit int, rules logger.RedactRules) (redacted []byte, placeholder string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ""
+	}
+	if len(body) > limit {
+		return nil, debugBodyTruncated
+	}
+	redacted, ok := rules.RedactJSON(body)
+	if !ok {
+		return nil, debugBodyOmitted
+	}
+	return redacted, ""
+}
+
+// sanitizeQuery 对查询参数中命中脱敏规则的参数做掩码处理
+func (d *DebugCapture) sanitizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	for key := range values {
+		switch d.rules[key] {
+		case logger.RedactDrop:
+			values.Del(key)
+		case logger.RedactMask:
+			values.Set(key, "***")
+		case logger.RedactHash:
+			values.Set(key, logger.Hash(values.Get(key)))
+		}
+	}
+	return values.Encode()
+}
+
+// add 写入环形缓冲区
+func (d *DebugCapture) add(entry DebugCaptureEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seq++
+	entry.ID = d.seq
+	d.entries[d.next] = entry
+	d.next = (d.next + 1) % len(d.entries)
+	if d.count < len(d.entries) {
+		d.count++
+	}
+}
+
+// captureWriter 在写出响应的同时保留最多 limit+1 字节的响应体
+type captureWriter struct {
+	gin.ResponseWriter
+	limit int
+	buf   bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) keep(data []byte) {
+	if remaining := w.limit + 1 - w.buf.Len(); remaining > 0 {
+		w.buf.Write(data[:min(len(data), remaining)])
+	}
+}
+
+func (w *captureWriter) captured() []byte {
+	return w.buf.Bytes()
+}
+
+// readCloser 组合读取器与原请求体的 Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+func setupDebugCaptureRouter(capture *DebugCapture) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(capture.Middleware())
+	r.POST("/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		if !strings.Contains(string(body), "alice") {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "body not forwarded"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "invalid credentials", "token": "t-123"})
+	})
+	r.GET("/ok", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	return r
+}
+
+func TestDebugCapture_CapturesSanitizedFailures(t *testing.T) {
+	capture := NewDebugCapture(DebugCaptureConfig{
+		DebugCaptureSettings: DebugCaptureSettings{
+			Enabled:      true,
+			Routes:       []string{"/login", "/ok"},
+			OnlyFailures: true,
+		},
+		BufferSize:  2,
+		RedactRules: logger.DefaultRedactRules("dev"),
+	})
+	r := setupDebugCaptureRouter(capture)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/login?token=abc", strings.NewReader(`{"username":"alice","password":"secret"}`))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	entries := capture.Entries("", 0)
+	if len(entries) != 2 {
+		t.Fatalf("Entries() len = %d, want 2 (ring buffer, successes skipped)", len(entries))
+	}
+	entry := entries[0]
+	if entry.ID != 3 || entry.Route != "/login" || entry.Status != http.StatusUnauthorized {
+		t.Errorf("latest entry = %+v", entry)
+	}
+	if strings.Contains(entry.RequestBody, "secret") || !strings.Contains(entry.RequestBody, "alice") {
+		t.Errorf("RequestBody = %s, want password dropped", entry.RequestBody)
+	}
+	if strings.Contains(entry.ResponseBody, "t-123") || strings.Contains(entry.Query, "abc") {
+		t.Errorf("token leaked: response %s, query %s", entry.ResponseBody, entry.Query)
+	}
+}
+
+func TestDebugCapture_DisabledAtRuntime(t *testing.T) {
+	capture := NewDebugCapture(DebugCaptureConfig{
+		DebugCaptureSettings: DebugCaptureSettings{Enabled: true, Routes: []string{"/login"}},
+	})
+	r := setupDebugCaptureRouter(capture)
+
+	settings := capture.Settings()
+	settings.Enabled = false
+	capture.UpdateSettings(settings)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"alice"}`))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if entries := capture.Entries("", 0); len(entries) != 0 {
+		t.Errorf("Entries() len = %d, want 0 when disabled", len(entries))
+	}
+}
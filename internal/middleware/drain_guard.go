@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/lifecycle"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// GinDrainGuard 在实例进入排空阶段后拒绝新请求，返回 503、Retry-After 与可重试提示，
+// 使客户端或网关在 HTTP 服务器真正关闭前就转向其他实例重试。
+func GinDrainGuard(manager *lifecycle.Manager, retryAfter time.Duration) gin.HandlerFunc {
+	retryAfter = max(retryAfter, time.Second)
+	seconds := strconv.Itoa(int(retryAfter / time.Second))
+	return func(c *gin.Context) {
+		if manager == nil || !manager.IsDraining() {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", seconds)
+		c.Header("Connection", "close")
+		resp.RetryableError(c.Writer, resp.HTTPStatusFromCode(resp.CodeUnavailable), resp.CodeUnavailable,
+			"服务实例正在关闭，请稍后重试", true, retryAfter, c.GetString("request_id"), c.GetString("trace_id"))
+		c.Abort()
+	}
+}
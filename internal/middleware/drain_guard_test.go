@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/lifecycle"
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// decodeRetryHint 解析失败响应中的重试提示
+func decodeRetryHint(t *testing.T, w *httptest.ResponseRecorder) resp.RetryHint {
+	t.Helper()
+	var body resp.Response[resp.RetryHint]
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Data == nil {
+		t.Fatalf("decode retry hint from %s: %v", w.Body, err)
+	}
+	return *body.Data
+}
+
+func TestGinDrainGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := lifecycle.NewManager()
+	r := gin.New()
+	r.POST("/participate", GinDrainGuard(m, 2*time.Second), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/participate", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status before drain = %d, want 200", w.Code)
+	}
+
+	m.BeginDrain()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/participate", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status while draining = %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if hint := decodeRetryHint(t, w); !hint.Retryable || hint.RetryAfterMs != 2000 {
+		t.Errorf("retry hint = %+v, want retryable after 2000ms", hint)
+	}
+}
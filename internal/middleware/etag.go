@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinETag 为 GET 请求的 200 JSON 响应生成 ETag，请求的 If-None-Match 命中时返回 304 且不带响应体。
+// 统一响应中的 request_id、trace_id、timestamp 每次请求都不同，ETag 只对 code 与 data 计算哈希，
+// 结果集不变时客户端即可复用本地缓存。ETag 为弱校验器，压缩前后的响应视为同一内容。
+func GinETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &etagWriter{ResponseWriter: original, status: original.Status()}
+		c.Writer = writer
+		defer func() { c.Writer = original }()
+
+		c.Next()
+		writer.finish(c.GetHeader("If-None-Match"))
+	}
+}
+
+// etagWriter 缓冲 JSON 响应体以计算 ETag，其余响应直接透传
+type etagWriter struct {
+	gin.ResponseWriter
+	status  int
+	buf     bytes.Buffer
+	direct  bool // 不是 JSON 响应，写入直接透传
+	written bool // 处理器已设置状态码或写入数据
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	w.status = code
+	w.written = true
+}
+
+func (w *etagWriter) WriteHeaderNow() {}
+
+func (w *etagWriter) Written() bool {
+	return w.written || w.ResponseWriter.Written()
+}
+
+func (w *etagWriter) Status() int {
+	if w.direct {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.direct {
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if w.status != http.StatusOK || mediaType != "application/json" {
+		// 文件下载等非 JSON 响应不缓冲，避免大响应整体驻留内存
+		w.direct = true
+		w.ResponseWriter.WriteHeader(w.status)
+		if _, err := w.ResponseWriter.Write(w.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		w.buf.Reset()
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// finish 写出缓冲的 JSON 响应，If-None-Match 命中时改为 304
+func (w *etagWriter) finish(ifNoneMatch string) {
+	if w.direct || !w.written {
+		return
+	}
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+		return
+	}
+
+	etag := responseETag(w.buf.Bytes())
+	w.Header().Set("ETag", etag)
+	if etagMatches(ifNoneMatch, etag) {
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Length")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+// responseETag 对统一响应的 code 与 data 计算弱 ETag，无法解析为统一响应时对整个响应体计算
+func responseETag(body []byte) string {
+	var envelope struct {
+		Code json.RawMessage `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	h := sha256.New()
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Code != nil {
+		h.Write(envelope.Code)
+		h.Write([]byte{0})
+		h.Write(envelope.Data)
+	} else {
+		h.Write(body)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches 按弱比较判断 If-None-Match 是否包含 etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func setupGinAuthRouter(mockJWT *MockJWTService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinAuth(mockJWT, zap.NewNop()))
+
+	identity := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":         c.GetInt64("user_id"),
+			"impersonator_id": ImpersonatorIDFromContext(c.Request.Context()),
+		})
+	}
+	r.GET("/api/v1/spike/orders", identity)
+	r.GET("/api/v1/spike/orders/:id", identity)
+	r.GET("/api/v1/users/profile", identity)
+	r.GET("/api/v1/admin/users", identity)
+	r.POST("/api/v1/spike/participate", identity)
+	r.POST("/api/v1/spike/orders/:id/cancel", identity)
+	return r
+}
+
+func TestGinAuth_Success(t *testing.T) {
+	mockJWT := NewMockJWTService()
+	user := &domain.User{ID: 1, Username: "testuser", Role: domain.UserRoleUser}
+	tokenPair, _ := mockJWT.GenerateTokenPair(user)
+
+	r := setupGinAuthRouter(mockJWT)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/spike/participate", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenPair.AccessToken)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if body := rr.Body.String(); body != `{"impersonator_id":0,"user_id":1}` {
+		t.Errorf("Unexpected identity: %s", body)
+	}
+}
+
+func TestGinAuth_MissingToken(t *testing.T) {
+	r := setupGinAuthRouter(NewMockJWTService())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/spike/orders", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestGinAuth_UserTier(t *testing.T) {
+	mockJWT := NewMockJWTService()
+	gold, _ := mockJWT.GenerateTokenPair(&domain.User{ID: 1, Username: "golduser", Role: domain.UserRoleUser, Tier: domain.UserTierGold})
+	legacy, _ := mockJWT.GenerateTokenPair(&domain.User{ID: 2, Username: "legacyuser", Role: domain.UserRoleUser})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinAuth(mockJWT, zap.NewNop()))
+	r.GET("/tier", func(c *gin.Context) {
+		c.String(http.StatusOK, "%s/%s", c.GetString("user_tier"), UserFromContext(c.Request.Context()).Tier)
+	})
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token: gold.AccessToken, want: "gold/gold"},
+		{token: legacy.AccessToken, want: "regular/regular"}, // 旧令牌未携带等级
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/tier", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Body.String() != tt.want {
+			t.Errorf("Expected tier %s, got %s", tt.want, rr.Body.String())
+		}
+	}
+}
+
+func TestGinAuth_Impersonation(t *testing.T) {
+	mockJWT := NewMockJWTService()
+	admin := &domain.User{ID: 1, Username: "admin", Role: domain.UserRoleAdmin}
+	target := &domain.User{ID: 42, Username: "customer", Role: domain.UserRoleUser}
+	token, _, _ := mockJWT.GenerateImpersonationToken(admin, target)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"read orders allowed", http.MethodGet, "/api/v1/spike/orders", http.StatusOK},
+		{"read order detail allowed", http.MethodGet, "/api/v1/spike/orders/7", http.StatusOK},
+		{"read profile denied", http.MethodGet, "/api/v1/users/profile", http.StatusForbidden},
+		{"admin read denied", http.MethodGet, "/api/v1/admin/users", http.StatusForbidden},
+		{"cancel order allowed", http.MethodPost, "/api/v1/spike/orders/1/cancel", http.StatusOK},
+		{"participate denied", http.MethodPost, "/api/v1/spike/participate", http.StatusForbidden},
+	}
+
+	r := setupGinAuthRouter(mockJWT)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if body := rr.Body.String(); body != `{"impersonator_id":1,"user_id":42}` {
+					t.Errorf("Unexpected identity: %s", body)
+				}
+			}
+		})
+	}
+}
+
+func TestGinRequireAdmin(t *testing.T) {
+	mockJWT := NewMockJWTService()
+	admin := &domain.User{ID: 1, Username: "admin", Role: domain.UserRoleAdmin}
+	user := &domain.User{ID: 2, Username: "user", Role: domain.UserRoleUser}
+	adminTokens, _ := mockJWT.GenerateTokenPair(admin)
+	userTokens, _ := mockJWT.GenerateTokenPair(user)
+	impersonationToken, _, _ := mockJWT.GenerateImpersonationToken(admin, user)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", GinAuth(mockJWT, zap.NewNop()), GinRequireAdmin(zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"admin", adminTokens.AccessToken, http.StatusOK},
+		{"regular user", userTokens.AccessToken, http.StatusForbidden},
+		{"impersonating admin acts as target user", impersonationToken, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
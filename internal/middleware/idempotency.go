@@ -2,16 +2,48 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/keys"
 	"github.com/MorseWayne/spike_shop/internal/resp"
 )
 
+// 幂等键请求头
+const (
+	HeaderIdempotencyKey       = "Idempotency-Key"
+	HeaderLegacyIdempotencyKey = "X-Idempotency-Key" // 兼容旧客户端
+	HeaderIdempotentReplayed   = "Idempotent-Replayed"
+)
+
+// 幂等检查错误
+var (
+	ErrIdempotencyInProgress = errors.New("相同幂等键的请求正在处理中")
+	ErrIdempotencyKeyReused  = errors.New("幂等键已用于不同的请求")
+)
+
+// maxIdempotentResponseBytes 可缓存的响应体上限，超出时不缓存，重试会再次执行
+const maxIdempotentResponseBytes = 64 << 10
+
+// IdempotencyStore 存取接口幂等记录（由 cache.IdempotencyStore 实现）
+type IdempotencyStore interface {
+	Key(userID int64, path, idempotencyKey string) string
+	Begin(ctx context.Context, key string, record *cache.IdempotencyRecord, ttl time.Duration) (bool, *cache.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record *cache.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
 // IdempotencyConfig 幂等性中间件配置
 type IdempotencyConfig struct {
 	// 幂等键头名称
@@ -23,6 +55,12 @@ type IdempotencyConfig struct {
 	// 缓存TTL
 	CacheTTL time.Duration
 
+	// 处理中状态的最长保留时间，超过后视为处理失败，允许以同一幂等键重试
+	LockTTL time.Duration
+
+	// 幂等记录存储，为空时只把幂等键写入上下文，不缓存响应
+	Store IdempotencyStore
+
 	// 错误处理函数
 	ErrorHandler func(*gin.Context, error)
 }
@@ -30,19 +68,27 @@ type IdempotencyConfig struct {
 // DefaultIdempotencyConfig 默认幂等性配置
 func DefaultIdempotencyConfig() *IdempotencyConfig {
 	return &IdempotencyConfig{
-		IdempotencyKeyHeader: "X-Idempotency-Key",
+		IdempotencyKeyHeader: HeaderIdempotencyKey,
 		SkipMethods:          []string{"GET", "HEAD", "OPTIONS"},
 		CacheTTL:             24 * time.Hour,
+		LockTTL:              30 * time.Second,
 		ErrorHandler:         defaultIdempotencyErrorHandler,
 	}
 }
 
-// IdempotencyMiddleware 幂等性中间件
+// IdempotencyMiddleware 幂等性中间件。
+// 客户端通过 Idempotency-Key（或旧的 X-Idempotency-Key）头提供幂等键，配置了存储时：
+// 首个请求正常处理并缓存响应，同一用户对同一路径以相同幂等键的重试直接重放缓存的响应；
+// 前一个请求仍在处理时返回 409，幂等键被用于不同请求体时返回 422；
+// 5xx 响应不缓存，客户端可用同一幂等键重试。未提供幂等键时按请求内容生成，只写入上下文。
 func IdempotencyMiddleware(config ...*IdempotencyConfig) gin.HandlerFunc {
 	cfg := DefaultIdempotencyConfig()
 	if len(config) > 0 && config[0] != nil {
 		cfg = config[0]
 	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaultIdempotencyErrorHandler
+	}
 
 	return func(c *gin.Context) {
 		// 检查是否需要跳过
@@ -57,18 +103,112 @@ func IdempotencyMiddleware(config ...*IdempotencyConfig) gin.HandlerFunc {
 		// 获取幂等键
 		idempotencyKey := c.GetHeader(cfg.IdempotencyKeyHeader)
 		if idempotencyKey == "" {
-			// 自动生成幂等键（基于请求内容）
-			idempotencyKey = generateIdempotencyKey(c)
+			idempotencyKey = c.GetHeader(HeaderLegacyIdempotencyKey)
+		}
+		if idempotencyKey == "" {
+			// 自动生成幂等键（基于请求内容），不缓存响应
+			c.Set("idempotency_key", generateIdempotencyKey(c))
+			c.Next()
+			return
+		}
+		if err := keys.ValidateIdempotencyKey(idempotencyKey); err != nil {
+			resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+				err.Error(), getRequestID(c), getTraceID(c))
+			c.Abort()
+			return
 		}
 
 		// 设置幂等键到上下文
 		c.Set("idempotency_key", idempotencyKey)
+		if cfg.Store == nil {
+			c.Next()
+			return
+		}
+		handleIdempotent(c, cfg, idempotencyKey)
+	}
+}
 
-		// TODO: 这里可以集成Redis缓存来存储幂等键
-		// 目前只是将幂等键设置到上下文中，实际的幂等性检查在业务层处理
+// handleIdempotent 占用幂等键后执行请求并缓存响应，已有记录时重放或拒绝
+func handleIdempotent(c *gin.Context, cfg *IdempotencyConfig, idempotencyKey string) {
+	fingerprint, err := requestFingerprint(c)
+	if err != nil {
+		resp.Error(c.Writer, http.StatusBadRequest, resp.CodeInvalidParam,
+			"读取请求体失败", getRequestID(c), getTraceID(c))
+		c.Abort()
+		return
+	}
 
+	// 客户端断开后仍需写回记录，否则处理中状态要等 LockTTL 过期
+	ctx := context.WithoutCancel(c.Request.Context())
+	storeKey := cfg.Store.Key(c.GetInt64("user_id"), c.Request.URL.Path, idempotencyKey)
+	reserved, existing, err := cfg.Store.Begin(ctx, storeKey, &cache.IdempotencyRecord{Fingerprint: fingerprint}, cfg.LockTTL)
+	if err != nil {
+		// 存储不可用时放行，由业务层的幂等检查兜底
 		c.Next()
+		return
+	}
+	if !reserved {
+		switch {
+		case existing.Fingerprint != fingerprint:
+			cfg.ErrorHandler(c, ErrIdempotencyKeyReused)
+			c.Abort()
+		case !existing.Completed:
+			cfg.ErrorHandler(c, ErrIdempotencyInProgress)
+			c.Abort()
+		default:
+			replayResponse(c, existing)
+		}
+		return
+	}
+
+	writer := &captureWriter{ResponseWriter: c.Writer, limit: maxIdempotentResponseBytes}
+	c.Writer = writer
+	c.Next()
+
+	body := writer.captured()
+	status := writer.Status()
+	if status >= http.StatusInternalServerError || len(body) > maxIdempotentResponseBytes {
+		_ = cfg.Store.Release(ctx, storeKey)
+		return
+	}
+	if err := cfg.Store.Complete(ctx, storeKey, &cache.IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Status:      status,
+		ContentType: writer.Header().Get("Content-Type"),
+		Body:        body,
+	}, cfg.CacheTTL); err != nil {
+		_ = cfg.Store.Release(ctx, storeKey)
+	}
+}
+
+// replayResponse 重放已缓存的响应
+func replayResponse(c *gin.Context, record *cache.IdempotencyRecord) {
+	c.Header(HeaderIdempotentReplayed, "true")
+	if record.ContentType != "" {
+		c.Header("Content-Type", record.ContentType)
+	}
+	c.Status(record.Status)
+	_, _ = c.Writer.Write(record.Body)
+	c.Abort()
+}
+
+// requestFingerprint 计算请求方法、路径与请求体的摘要，并把请求体放回供后续处理器读取
+func requestFingerprint(c *gin.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 	}
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // generateIdempotencyKey 生成幂等键
@@ -87,16 +227,23 @@ func generateIdempotencyKey(c *gin.Context) string {
 		time.Now().Unix()/60) // 按分钟取整，同一分钟内的请求有相同的幂等键
 
 	hash := md5.Sum([]byte(content))
-	return fmt.Sprintf("auto_%x", hash)[:16] // 取前16位
+	return keys.Idempotency("auto", hex.EncodeToString(hash[:8]))
 }
 
-// defaultIdempotencyErrorHandler 默认幂等性错误处理器
+// defaultIdempotencyErrorHandler 默认幂等性错误处理器，
+// 幂等键冲突与复用都不能原样重发，响应标记为不可重试
 func defaultIdempotencyErrorHandler(c *gin.Context, err error) {
 	requestID := getRequestID(c)
 	traceID := getTraceID(c)
 
-	resp.Error(c.Writer, http.StatusTooManyRequests, resp.CodeInvalidParam,
-		"重复请求", requestID, traceID)
+	if errors.Is(err, ErrIdempotencyKeyReused) {
+		resp.RetryableError(c.Writer, http.StatusUnprocessableEntity, resp.CodeInvalidParam,
+			err.Error(), false, 0, requestID, traceID)
+		return
+	}
+	c.Header("Retry-After", "1")
+	resp.RetryableError(c.Writer, http.StatusConflict, resp.CodeInvalidParam,
+		err.Error(), false, 0, requestID, traceID)
 }
 
 // getRequestID 获取请求ID
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*cache.IdempotencyRecord
+}
+
+func (s *fakeIdempotencyStore) Key(userID int64, path, idempotencyKey string) string {
+	return fmt.Sprintf("%d:%s:%s", userID, path, idempotencyKey)
+}
+
+func (s *fakeIdempotencyStore) Begin(ctx context.Context, key string, record *cache.IdempotencyRecord, ttl time.Duration) (bool, *cache.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[key]; ok {
+		return false, existing, nil
+	}
+	s.records[key] = record
+	return true, nil, nil
+}
+
+func (s *fakeIdempotencyStore) Complete(ctx context.Context, key string, record *cache.IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func TestIdempotencyMiddleware_ReplaysCachedResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeIdempotencyStore{records: make(map[string]*cache.IdempotencyRecord)}
+	cfg := DefaultIdempotencyConfig()
+	cfg.Store = store
+
+	calls := 0
+	status := http.StatusOK
+	r := gin.New()
+	r.POST("/orders/:id/pay", IdempotencyMiddleware(cfg), func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"call": calls})
+	})
+
+	do := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders/1/pay", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := do("pay-1", `{"a":1}`)
+	retry := do("pay-1", `{"a":1}`)
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("replayed response = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("%s header missing on replay", HeaderIdempotentReplayed)
+	}
+
+	// 相同幂等键、不同请求体
+	if w := do("pay-1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want 422", w.Code)
+	} else if hint := decodeRetryHint(t, w); hint.Retryable {
+		t.Errorf("reused key retry hint = %+v, want not retryable", hint)
+	}
+
+	// 前一个请求仍在处理中
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/orders/1/pay", strings.NewReader(`{}`))
+	fingerprint, _ := requestFingerprint(c)
+	store.records[store.Key(0, "/orders/1/pay", "pay-2")] = &cache.IdempotencyRecord{Fingerprint: fingerprint}
+	if w := do("pay-2", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("in-progress status = %d, want 409", w.Code)
+	} else if hint := decodeRetryHint(t, w); hint.Retryable {
+		t.Errorf("in-progress retry hint = %+v, want not retryable", hint)
+	}
+
+	// 5xx 不缓存，同一幂等键可重试
+	status = http.StatusInternalServerError
+	do("pay-3", `{}`)
+	status = http.StatusOK
+	if w := do("pay-3", `{}`); w.Code != http.StatusOK || w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Errorf("retry after 5xx = %d replayed=%q, want fresh 200", w.Code, w.Header().Get(HeaderIdempotentReplayed))
+	}
+
+	// 未提供幂等键时不缓存
+	before := calls
+	do("", `{}`)
+	do("", `{}`)
+	if calls != before+2 {
+		t.Errorf("handler calls without key = %d, want %d", calls-before, 2)
+	}
+
+	if w := do("bad key!", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid key status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// unmatchedRoute 未匹配任何路由的请求（404 等）统一记为该路由，避免按原始路径产生无界标签
+const unmatchedRoute = "unmatched"
+
+// GinMetrics 按方法、路由模板与状态码记录 HTTP 请求耗时
+func GinMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.HTTPRequestDuration.Observe(metrics.Since(start),
+			c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+	}
+}
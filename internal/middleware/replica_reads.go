@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/database"
+)
+
+// GinReplicaReads 允许 GET、HEAD 请求中的只读查询读只读副本。
+// 写请求中的查询始终读主库，保证读改写与读到本次请求的写入不受复制延迟影响
+func GinReplicaReads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+			c.Request = c.Request.WithContext(database.WithReplicaReads(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
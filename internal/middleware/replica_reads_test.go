@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/database"
+)
+
+func TestGinReplicaReads_OnlyReadRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinReplicaReads())
+	var allowed bool
+	handler := func(c *gin.Context) {
+		allowed = database.ReplicaReadsAllowed(c.Request.Context())
+		c.Status(http.StatusOK)
+	}
+	r.GET("/items", handler)
+	r.POST("/items", handler)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	if !allowed {
+		t.Error("GET request should allow replica reads")
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/items", nil))
+	if allowed {
+		t.Error("POST request should read from primary")
+	}
+}
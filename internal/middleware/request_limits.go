@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// RequestLimitsConfig 表示请求处理超时、请求体大小与慢请求日志配置。
+// 路由以 gin 路由模板（如 /api/v1/admin/spike/events/import）为键覆盖默认值，覆盖值为 0 表示该路由不限制。
+type RequestLimitsConfig struct {
+	Methods           []string                 // 生效的请求方法，为空时只作用于 POST
+	Timeout           time.Duration            // 默认处理超时，0 表示不限制
+	MaxBodyBytes      int64                    // 默认请求体上限（字节），0 表示不限制
+	SlowThreshold     time.Duration            // 处理耗时达到该值时记录慢请求日志，0 表示不记录
+	RouteTimeouts     map[string]time.Duration // 按路由覆盖处理超时
+	RouteMaxBodyBytes map[string]int64         // 按路由覆盖请求体上限
+}
+
+// requestTimeoutRetryAfter 处理超时响应建议的重试等待时长
+const requestTimeoutRetryAfter = time.Second
+
+// GinRequestLimits 限制写请求占用处理协程的时间与内存：
+//   - 为请求 ctx 设置截止时间，并把连接读截止时间设为同一时刻，慢速上传请求体的客户端读到超时即被中断；
+//     处理器未写出响应就超时的请求统一返回 504
+//   - Content-Length 超过上限的请求直接返回 413，未声明长度的请求体读取超过上限时报错
+//   - 处理耗时达到阈值时记录慢请求日志
+//
+// 超时响应标记为可重试，请求体过大的响应原样重发仍会被拒绝，标记为不可重试。
+//
+// 截止时间依赖处理器向下游传递 ctx，本身不会中断正在执行的处理器。
+func GinRequestLimits(cfg RequestLimitsConfig, logger *zap.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	methods := make(map[string]struct{}, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+			methods[method] = struct{}{}
+		}
+	}
+	if len(methods) == 0 {
+		methods[http.MethodPost] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := methods[c.Request.Method]; !ok {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+
+		maxBody := cfg.MaxBodyBytes
+		if v, ok := cfg.RouteMaxBodyBytes[route]; ok {
+			maxBody = v
+		}
+		if maxBody > 0 {
+			if c.Request.ContentLength > maxBody {
+				c.Header("Connection", "close")
+				resp.RetryableError(c.Writer, http.StatusRequestEntityTooLarge, resp.CodeInvalidParam,
+					"请求体过大", false, 0, c.GetString("request_id"), c.GetString("trace_id"))
+				c.Abort()
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+		}
+
+		timeout := cfg.Timeout
+		if v, ok := cfg.RouteTimeouts[route]; ok {
+			timeout = v
+		}
+		var ctx context.Context
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+			// 测试用的 ResponseRecorder 不支持设置读截止时间，忽略该错误
+			deadline, _ := ctx.Deadline()
+			_ = http.NewResponseController(c.Writer).SetReadDeadline(deadline)
+		}
+
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start)
+
+		timedOut := ctx != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+		if timedOut && !c.Writer.Written() {
+			resp.RetryableError(c.Writer, resp.HTTPStatusFromCode(resp.CodeTimeout), resp.CodeTimeout,
+				"请求处理超时", true, requestTimeoutRetryAfter, c.GetString("request_id"), c.GetString("trace_id"))
+		}
+		if cfg.SlowThreshold > 0 && elapsed >= cfg.SlowThreshold {
+			logger.Warn("慢请求",
+				zap.String("method", c.Request.Method),
+				zap.String("route", route),
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("status", c.Writer.Status()),
+				zap.Duration("duration", elapsed),
+				zap.Bool("timed_out", timedOut),
+				zap.String("request_id", c.GetString("request_id")))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGinRequestLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zap.WarnLevel)
+	r := gin.New()
+	r.Use(GinRequestLimits(RequestLimitsConfig{
+		Timeout:           20 * time.Millisecond,
+		MaxBodyBytes:      8,
+		SlowThreshold:     10 * time.Millisecond,
+		RouteMaxBodyBytes: map[string]int64{"/import": 64},
+		RouteTimeouts:     map[string]time.Duration{"/import": 0},
+	}, zap.New(core)))
+
+	read := func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	wait := func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	}
+	r.POST("/orders", read)
+	r.POST("/import", read)
+	r.POST("/slow", wait)
+	r.GET("/slow", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name      string
+		req       *http.Request
+		status    int
+		retryable bool
+	}{
+		{"body within limit", httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("{}")), http.StatusOK, false},
+		{"content-length over limit", httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(strings.Repeat("x", 9))), http.StatusRequestEntityTooLarge, false},
+		{"route override", httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(strings.Repeat("x", 32))), http.StatusOK, false},
+		{"handler times out", httptest.NewRequest(http.MethodPost, "/slow", nil), http.StatusGatewayTimeout, true},
+		{"get not limited", httptest.NewRequest(http.MethodGet, "/slow", nil), http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, tt.req)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if w.Code == http.StatusOK {
+				return
+			}
+			if hint := decodeRetryHint(t, w); hint.Retryable != tt.retryable || (hint.Retryable && hint.RetryAfterMs <= 0) {
+				t.Errorf("retry hint = %+v, want retryable=%v", hint, tt.retryable)
+			}
+		})
+	}
+
+	// 未声明长度的请求体在读取超过上限时报错
+	req := httptest.NewRequest(http.MethodPost, "/orders", io.NopCloser(strings.NewReader(strings.Repeat("x", 9))))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("chunked body over limit status = %d, want 400", w.Code)
+	}
+
+	slow := logs.FilterMessage("慢请求").All()
+	if len(slow) != 1 || slow[0].ContextMap()["route"] != "/slow" || slow[0].ContextMap()["timed_out"] != true {
+		t.Errorf("slow request logs = %+v, want one timed out /slow entry", slow)
+	}
+}
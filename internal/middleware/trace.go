@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/MorseWayne/spike_shop/internal/tracing"
+)
+
+// GinRequestContext 为每个请求确定请求ID与追踪ID：
+// 1) 请求ID优先读取 X-Request-ID，为空则生成 UUID；
+// 2) 追踪ID优先读取 traceparent / X-Trace-ID，均不存在时使用请求ID；
+// 3) 写入 gin 上下文键 request_id/trace_id、请求上下文以及响应头。
+func GinRequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid := strings.TrimSpace(c.GetHeader(HeaderRequestID))
+		if rid == "" {
+			rid = uuid.New().String()
+		}
+		traceID := tracing.FromHeaders(c.Request.Header, rid)
+
+		c.Set("request_id", rid)
+		c.Set("trace_id", traceID)
+		c.Header(HeaderRequestID, rid)
+		c.Header(tracing.HeaderTraceID, traceID)
+
+		ctx := withRequestID(c.Request.Context(), rid)
+		c.Request = c.Request.WithContext(tracing.WithTraceID(ctx, traceID))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/tracing"
+)
+
+func TestGinRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotTrace, gotCtxTrace, gotRequestID string
+	r := gin.New()
+	r.Use(GinRequestContext())
+	r.GET("/ping", func(c *gin.Context) {
+		gotTrace = c.GetString("trace_id")
+		gotCtxTrace = tracing.TraceIDFromContext(c.Request.Context())
+		gotRequestID = RequestIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("propagates traceparent", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		req.Header.Set(HeaderRequestID, "req-1")
+		req.Header.Set(tracing.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		want := "4bf92f3577b34da6a3ce929d0e0e4736"
+		if gotTrace != want || gotCtxTrace != want {
+			t.Errorf("trace_id = %q / ctx %q, want %q", gotTrace, gotCtxTrace, want)
+		}
+		if gotRequestID != "req-1" {
+			t.Errorf("request_id = %q, want req-1", gotRequestID)
+		}
+		if w.Header().Get(tracing.HeaderTraceID) != want {
+			t.Errorf("response %s = %q, want %q", tracing.HeaderTraceID, w.Header().Get(tracing.HeaderTraceID), want)
+		}
+	})
+
+	t.Run("falls back to request id", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if gotRequestID == "" || gotTrace != gotRequestID {
+			t.Errorf("trace_id = %q, want generated request id %q", gotTrace, gotRequestID)
+		}
+		if w.Header().Get(HeaderRequestID) != gotRequestID {
+			t.Errorf("response %s = %q, want %q", HeaderRequestID, w.Header().Get(HeaderRequestID), gotRequestID)
+		}
+	})
+}
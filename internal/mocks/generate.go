@@ -0,0 +1,25 @@
+// Package mocks 存放由 moq 根据接口生成的模拟实现，生成文件请勿手工修改。
+// 接口变更后执行 go generate ./internal/mocks ./internal/service 重新生成（需先 go install github.com/matryer/moq@v0.6.0），
+// 未重新生成时生成文件中的接口断言会使编译失败，避免模拟实现与接口悄悄脱节。
+package mocks
+
+//go:generate moq -rm -pkg mocks -out spike_event_repository.go ../repo SpikeEventRepository
+//go:generate moq -rm -pkg mocks -out spike_order_repository.go ../repo SpikeOrderRepository
+//go:generate moq -rm -pkg mocks -out user_repository.go ../repo UserRepository
+//go:generate moq -rm -pkg mocks -out product_repository.go ../repo ProductRepository
+//go:generate moq -rm -pkg mocks -out inventory_repository.go ../repo InventoryRepository
+//go:generate moq -rm -pkg mocks -out order_event_repository.go ../repo OrderEventRepository
+//go:generate moq -rm -pkg mocks -out order_repository.go ../repo OrderRepository
+//go:generate moq -rm -pkg mocks -out cart_repository.go ../repo CartRepository
+//go:generate moq -rm -pkg mocks -out product_variant_repository.go ../repo ProductVariantRepository
+//go:generate moq -rm -pkg mocks -out spike_campaign_repository.go ../repo SpikeCampaignRepository
+//go:generate moq -rm -pkg mocks -out inventory_reservation_repository.go ../repo InventoryReservationRepository
+//go:generate moq -rm -pkg mocks -out spike_event_publication_repository.go ../repo SpikeEventPublicationRepository
+//go:generate moq -rm -pkg mocks -out spike_quota_repository.go ../repo SpikeQuotaRepository
+//go:generate moq -rm -pkg mocks -out user_totp_repository.go ../repo UserTOTPRepository
+//go:generate moq -rm -pkg mocks -out abandoned_checkout_repository.go ../repo AbandonedCheckoutRepository
+//go:generate moq -rm -pkg mocks -out spike_publisher.go ../mq SpikePublisher
+//go:generate moq -rm -pkg mocks -out notification_publisher.go ../mq NotificationPublisher
+//go:generate moq -rm -pkg mocks -out limiter.go ../limiter Limiter
+//go:generate moq -rm -pkg mocks -out limiter_redis_client.go ../limiter RedisClient
+//go:generate moq -rm -pkg mocks -out backfill_progress_repository.go ../repo BackfillProgressRepository
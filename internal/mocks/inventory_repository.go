@@ -0,0 +1,845 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"sync"
+)
+
+// Ensure, that InventoryRepositoryMock does implement repo.InventoryRepository.
+// If this is not the case, regenerate this file with moq.
+var _ repo.InventoryRepository = &InventoryRepositoryMock{}
+
+// InventoryRepositoryMock is a mock implementation of repo.InventoryRepository.
+//
+//	func TestSomethingThatUsesInventoryRepository(t *testing.T) {
+//
+//		// make and configure a mocked repo.InventoryRepository
+//		mockedInventoryRepository := &InventoryRepositoryMock{
+//			AdjustStockFunc: func(ctx context.Context, productID int64, quantity int, reason string) error {
+//				panic("mock out the AdjustStock method")
+//			},
+//			BatchUpdateStockFunc: func(ctx context.Context, updates []repo.StockUpdate) error {
+//				panic("mock out the BatchUpdateStock method")
+//			},
+//			ConsumeStockFunc: func(ctx context.Context, productID int64, quantity int) error {
+//				panic("mock out the ConsumeStock method")
+//			},
+//			CountFunc: func(ctx context.Context) (int64, error) {
+//				panic("mock out the Count method")
+//			},
+//			CreateFunc: func(ctx context.Context, inventory *domain.Inventory) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(ctx context.Context, id int64) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetByIDFunc: func(ctx context.Context, id int64) (*domain.Inventory, error) {
+//				panic("mock out the GetByID method")
+//			},
+//			GetByProductIDFunc: func(ctx context.Context, productID int64) (*domain.Inventory, error) {
+//				panic("mock out the GetByProductID method")
+//			},
+//			GetByProductIDsFunc: func(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error) {
+//				panic("mock out the GetByProductIDs method")
+//			},
+//			GetLowStockProductsFunc: func(ctx context.Context) ([]*domain.Inventory, error) {
+//				panic("mock out the GetLowStockProducts method")
+//			},
+//			GetTotalStockValueFunc: func(ctx context.Context) (float64, error) {
+//				panic("mock out the GetTotalStockValue method")
+//			},
+//			ListFunc: func(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
+//				panic("mock out the List method")
+//			},
+//			ReleaseStockFunc: func(ctx context.Context, productID int64, quantity int) error {
+//				panic("mock out the ReleaseStock method")
+//			},
+//			ReserveStockFunc: func(ctx context.Context, productID int64, quantity int) error {
+//				panic("mock out the ReserveStock method")
+//			},
+//			UpdateFunc: func(ctx context.Context, inventory *domain.Inventory) error {
+//				panic("mock out the Update method")
+//			},
+//			UpdateWithVersionFunc: func(ctx context.Context, inventory *domain.Inventory) error {
+//				panic("mock out the UpdateWithVersion method")
+//			},
+//		}
+//
+//		// use mockedInventoryRepository in code that requires repo.InventoryRepository
+//		// and then make assertions.
+//
+//	}
+type InventoryRepositoryMock struct {
+	// AdjustStockFunc mocks the AdjustStock method.
+	AdjustStockFunc func(ctx context.Context, productID int64, quantity int, reason string) error
+
+	// BatchUpdateStockFunc mocks the BatchUpdateStock method.
+	BatchUpdateStockFunc func(ctx context.Context, updates []repo.StockUpdate) error
+
+	// ConsumeStockFunc mocks the ConsumeStock method.
+	ConsumeStockFunc func(ctx context.Context, productID int64, quantity int) error
+
+	// CountFunc mocks the Count method.
+	CountFunc func(ctx context.Context) (int64, error)
+
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, inventory *domain.Inventory) error
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, id int64) error
+
+	// GetByIDFunc mocks the GetByID method.
+	GetByIDFunc func(ctx context.Context, id int64) (*domain.Inventory, error)
+
+	// GetByProductIDFunc mocks the GetByProductID method.
+	GetByProductIDFunc func(ctx context.Context, productID int64) (*domain.Inventory, error)
+
+	// GetByProductIDsFunc mocks the GetByProductIDs method.
+	GetByProductIDsFunc func(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error)
+
+	// GetLowStockProductsFunc mocks the GetLowStockProducts method.
+	GetLowStockProductsFunc func(ctx context.Context) ([]*domain.Inventory, error)
+
+	// GetTotalStockValueFunc mocks the GetTotalStockValue method.
+	GetTotalStockValueFunc func(ctx context.Context) (float64, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error)
+
+	// ReleaseStockFunc mocks the ReleaseStock method.
+	ReleaseStockFunc func(ctx context.Context, productID int64, quantity int) error
+
+	// ReserveStockFunc mocks the ReserveStock method.
+	ReserveStockFunc func(ctx context.Context, productID int64, quantity int) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(ctx context.Context, inventory *domain.Inventory) error
+
+	// UpdateWithVersionFunc mocks the UpdateWithVersion method.
+	UpdateWithVersionFunc func(ctx context.Context, inventory *domain.Inventory) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AdjustStock holds details about calls to the AdjustStock method.
+		AdjustStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ProductID is the productID argument value.
+			ProductID int64
+			// Quantity is the quantity argument value.
+			Quantity int
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// BatchUpdateStock holds details about calls to the BatchUpdateStock method.
+		BatchUpdateStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Updates is the updates argument value.
+			Updates []repo.StockUpdate
+		}
+		// ConsumeStock holds details about calls to the ConsumeStock method.
+		ConsumeStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ProductID is the productID argument value.
+			ProductID int64
+			// Quantity is the quantity argument value.
+			Quantity int
+		}
+		// Count holds details about calls to the Count method.
+		Count []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Inventory is the inventory argument value.
+			Inventory *domain.Inventory
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetByID holds details about calls to the GetByID method.
+		GetByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetByProductID holds details about calls to the GetByProductID method.
+		GetByProductID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ProductID is the productID argument value.
+			ProductID int64
+		}
+		// GetByProductIDs holds details about calls to the GetByProductIDs method.
+		GetByProductIDs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ProductIDs is the productIDs argument value.
+			ProductIDs []int64
+		}
+		// GetLowStockProducts holds details about calls to the GetLowStockProducts method.
+		GetLowStockProducts []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetTotalStockValue holds details about calls to the GetTotalStockValue method.
+		GetTotalStockValue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req *domain.InventoryListRequest
+		}
+		// ReleaseStock holds details about calls to the ReleaseStock method.
+		ReleaseStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ProductID is the productID argument value.
+			ProductID int64
+			// Quantity is the quantity argument value.
+			Quantity int
+		}
+		// ReserveStock holds details about calls to the ReserveStock method.
+		ReserveStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ProductID is the productID argument value.
+			ProductID int64
+			// Quantity is the quantity argument value.
+			Quantity int
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Inventory is the inventory argument value.
+			Inventory *domain.Inventory
+		}
+		// UpdateWithVersion holds details about calls to the UpdateWithVersion method.
+		UpdateWithVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Inventory is the inventory argument value.
+			Inventory *domain.Inventory
+		}
+	}
+	lockAdjustStock         sync.RWMutex
+	lockBatchUpdateStock    sync.RWMutex
+	lockConsumeStock        sync.RWMutex
+	lockCount               sync.RWMutex
+	lockCreate              sync.RWMutex
+	lockDelete              sync.RWMutex
+	lockGetByID             sync.RWMutex
+	lockGetByProductID      sync.RWMutex
+	lockGetByProductIDs     sync.RWMutex
+	lockGetLowStockProducts sync.RWMutex
+	lockGetTotalStockValue  sync.RWMutex
+	lockList                sync.RWMutex
+	lockReleaseStock        sync.RWMutex
+	lockReserveStock        sync.RWMutex
+	lockUpdate              sync.RWMutex
+	lockUpdateWithVersion   sync.RWMutex
+}
+
+// AdjustStock calls AdjustStockFunc.
+func (mock *InventoryRepositoryMock) AdjustStock(ctx context.Context, productID int64, quantity int, reason string) error {
+	if mock.AdjustStockFunc == nil {
+		panic("InventoryRepositoryMock.AdjustStockFunc: method is nil but InventoryRepository.AdjustStock was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+		Reason    string
+	}{
+		Ctx:       ctx,
+		ProductID: productID,
+		Quantity:  quantity,
+		Reason:    reason,
+	}
+	mock.lockAdjustStock.Lock()
+	mock.calls.AdjustStock = append(mock.calls.AdjustStock, callInfo)
+	mock.lockAdjustStock.Unlock()
+	return mock.AdjustStockFunc(ctx, productID, quantity, reason)
+}
+
+// AdjustStockCalls gets all the calls that were made to AdjustStock.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.AdjustStockCalls())
+func (mock *InventoryRepositoryMock) AdjustStockCalls() []struct {
+	Ctx       context.Context
+	ProductID int64
+	Quantity  int
+	Reason    string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+		Reason    string
+	}
+	mock.lockAdjustStock.RLock()
+	calls = mock.calls.AdjustStock
+	mock.lockAdjustStock.RUnlock()
+	return calls
+}
+
+// BatchUpdateStock calls BatchUpdateStockFunc.
+func (mock *InventoryRepositoryMock) BatchUpdateStock(ctx context.Context, updates []repo.StockUpdate) error {
+	if mock.BatchUpdateStockFunc == nil {
+		panic("InventoryRepositoryMock.BatchUpdateStockFunc: method is nil but InventoryRepository.BatchUpdateStock was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Updates []repo.StockUpdate
+	}{
+		Ctx:     ctx,
+		Updates: updates,
+	}
+	mock.lockBatchUpdateStock.Lock()
+	mock.calls.BatchUpdateStock = append(mock.calls.BatchUpdateStock, callInfo)
+	mock.lockBatchUpdateStock.Unlock()
+	return mock.BatchUpdateStockFunc(ctx, updates)
+}
+
+// BatchUpdateStockCalls gets all the calls that were made to BatchUpdateStock.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.BatchUpdateStockCalls())
+func (mock *InventoryRepositoryMock) BatchUpdateStockCalls() []struct {
+	Ctx     context.Context
+	Updates []repo.StockUpdate
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Updates []repo.StockUpdate
+	}
+	mock.lockBatchUpdateStock.RLock()
+	calls = mock.calls.BatchUpdateStock
+	mock.lockBatchUpdateStock.RUnlock()
+	return calls
+}
+
+// ConsumeStock calls ConsumeStockFunc.
+func (mock *InventoryRepositoryMock) ConsumeStock(ctx context.Context, productID int64, quantity int) error {
+	if mock.ConsumeStockFunc == nil {
+		panic("InventoryRepositoryMock.ConsumeStockFunc: method is nil but InventoryRepository.ConsumeStock was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+	}{
+		Ctx:       ctx,
+		ProductID: productID,
+		Quantity:  quantity,
+	}
+	mock.lockConsumeStock.Lock()
+	mock.calls.ConsumeStock = append(mock.calls.ConsumeStock, callInfo)
+	mock.lockConsumeStock.Unlock()
+	return mock.ConsumeStockFunc(ctx, productID, quantity)
+}
+
+// ConsumeStockCalls gets all the calls that were made to ConsumeStock.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.ConsumeStockCalls())
+func (mock *InventoryRepositoryMock) ConsumeStockCalls() []struct {
+	Ctx       context.Context
+	ProductID int64
+	Quantity  int
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+	}
+	mock.lockConsumeStock.RLock()
+	calls = mock.calls.ConsumeStock
+	mock.lockConsumeStock.RUnlock()
+	return calls
+}
+
+// Count calls CountFunc.
+func (mock *InventoryRepositoryMock) Count(ctx context.Context) (int64, error) {
+	if mock.CountFunc == nil {
+		panic("InventoryRepositoryMock.CountFunc: method is nil but InventoryRepository.Count was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockCount.Lock()
+	mock.calls.Count = append(mock.calls.Count, callInfo)
+	mock.lockCount.Unlock()
+	return mock.CountFunc(ctx)
+}
+
+// CountCalls gets all the calls that were made to Count.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.CountCalls())
+func (mock *InventoryRepositoryMock) CountCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCount.RLock()
+	calls = mock.calls.Count
+	mock.lockCount.RUnlock()
+	return calls
+}
+
+// Create calls CreateFunc.
+func (mock *InventoryRepositoryMock) Create(ctx context.Context, inventory *domain.Inventory) error {
+	if mock.CreateFunc == nil {
+		panic("InventoryRepositoryMock.CreateFunc: method is nil but InventoryRepository.Create was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		Inventory *domain.Inventory
+	}{
+		Ctx:       ctx,
+		Inventory: inventory,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, inventory)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.CreateCalls())
+func (mock *InventoryRepositoryMock) CreateCalls() []struct {
+	Ctx       context.Context
+	Inventory *domain.Inventory
+} {
+	var calls []struct {
+		Ctx       context.Context
+		Inventory *domain.Inventory
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *InventoryRepositoryMock) Delete(ctx context.Context, id int64) error {
+	if mock.DeleteFunc == nil {
+		panic("InventoryRepositoryMock.DeleteFunc: method is nil but InventoryRepository.Delete was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.DeleteCalls())
+func (mock *InventoryRepositoryMock) DeleteCalls() []struct {
+	Ctx context.Context
+	ID  int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetByID calls GetByIDFunc.
+func (mock *InventoryRepositoryMock) GetByID(ctx context.Context, id int64) (*domain.Inventory, error) {
+	if mock.GetByIDFunc == nil {
+		panic("InventoryRepositoryMock.GetByIDFunc: method is nil but InventoryRepository.GetByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  int64
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetByID.Lock()
+	mock.calls.GetByID = append(mock.calls.GetByID, callInfo)
+	mock.lockGetByID.Unlock()
+	return mock.GetByIDFunc(ctx, id)
+}
+
+// GetByIDCalls gets all the calls that were made to GetByID.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.GetByIDCalls())
+func (mock *InventoryRepositoryMock) GetByIDCalls() []struct {
+	Ctx context.Context
+	ID  int64
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  int64
+	}
+	mock.lockGetByID.RLock()
+	calls = mock.calls.GetByID
+	mock.lockGetByID.RUnlock()
+	return calls
+}
+
+// GetByProductID calls GetByProductIDFunc.
+func (mock *InventoryRepositoryMock) GetByProductID(ctx context.Context, productID int64) (*domain.Inventory, error) {
+	if mock.GetByProductIDFunc == nil {
+		panic("InventoryRepositoryMock.GetByProductIDFunc: method is nil but InventoryRepository.GetByProductID was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ProductID int64
+	}{
+		Ctx:       ctx,
+		ProductID: productID,
+	}
+	mock.lockGetByProductID.Lock()
+	mock.calls.GetByProductID = append(mock.calls.GetByProductID, callInfo)
+	mock.lockGetByProductID.Unlock()
+	return mock.GetByProductIDFunc(ctx, productID)
+}
+
+// GetByProductIDCalls gets all the calls that were made to GetByProductID.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.GetByProductIDCalls())
+func (mock *InventoryRepositoryMock) GetByProductIDCalls() []struct {
+	Ctx       context.Context
+	ProductID int64
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ProductID int64
+	}
+	mock.lockGetByProductID.RLock()
+	calls = mock.calls.GetByProductID
+	mock.lockGetByProductID.RUnlock()
+	return calls
+}
+
+// GetByProductIDs calls GetByProductIDsFunc.
+func (mock *InventoryRepositoryMock) GetByProductIDs(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error) {
+	if mock.GetByProductIDsFunc == nil {
+		panic("InventoryRepositoryMock.GetByProductIDsFunc: method is nil but InventoryRepository.GetByProductIDs was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		ProductIDs []int64
+	}{
+		Ctx:        ctx,
+		ProductIDs: productIDs,
+	}
+	mock.lockGetByProductIDs.Lock()
+	mock.calls.GetByProductIDs = append(mock.calls.GetByProductIDs, callInfo)
+	mock.lockGetByProductIDs.Unlock()
+	return mock.GetByProductIDsFunc(ctx, productIDs)
+}
+
+// GetByProductIDsCalls gets all the calls that were made to GetByProductIDs.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.GetByProductIDsCalls())
+func (mock *InventoryRepositoryMock) GetByProductIDsCalls() []struct {
+	Ctx        context.Context
+	ProductIDs []int64
+} {
+	var calls []struct {
+		Ctx        context.Context
+		ProductIDs []int64
+	}
+	mock.lockGetByProductIDs.RLock()
+	calls = mock.calls.GetByProductIDs
+	mock.lockGetByProductIDs.RUnlock()
+	return calls
+}
+
+// GetLowStockProducts calls GetLowStockProductsFunc.
+func (mock *InventoryRepositoryMock) GetLowStockProducts(ctx context.Context) ([]*domain.Inventory, error) {
+	if mock.GetLowStockProductsFunc == nil {
+		panic("InventoryRepositoryMock.GetLowStockProductsFunc: method is nil but InventoryRepository.GetLowStockProducts was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetLowStockProducts.Lock()
+	mock.calls.GetLowStockProducts = append(mock.calls.GetLowStockProducts, callInfo)
+	mock.lockGetLowStockProducts.Unlock()
+	return mock.GetLowStockProductsFunc(ctx)
+}
+
+// GetLowStockProductsCalls gets all the calls that were made to GetLowStockProducts.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.GetLowStockProductsCalls())
+func (mock *InventoryRepositoryMock) GetLowStockProductsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetLowStockProducts.RLock()
+	calls = mock.calls.GetLowStockProducts
+	mock.lockGetLowStockProducts.RUnlock()
+	return calls
+}
+
+// GetTotalStockValue calls GetTotalStockValueFunc.
+func (mock *InventoryRepositoryMock) GetTotalStockValue(ctx context.Context) (float64, error) {
+	if mock.GetTotalStockValueFunc == nil {
+		panic("InventoryRepositoryMock.GetTotalStockValueFunc: method is nil but InventoryRepository.GetTotalStockValue was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetTotalStockValue.Lock()
+	mock.calls.GetTotalStockValue = append(mock.calls.GetTotalStockValue, callInfo)
+	mock.lockGetTotalStockValue.Unlock()
+	return mock.GetTotalStockValueFunc(ctx)
+}
+
+// GetTotalStockValueCalls gets all the calls that were made to GetTotalStockValue.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.GetTotalStockValueCalls())
+func (mock *InventoryRepositoryMock) GetTotalStockValueCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetTotalStockValue.RLock()
+	calls = mock.calls.GetTotalStockValue
+	mock.lockGetTotalStockValue.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *InventoryRepositoryMock) List(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
+	if mock.ListFunc == nil {
+		panic("InventoryRepositoryMock.ListFunc: method is nil but InventoryRepository.List was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req *domain.InventoryListRequest
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(ctx, req)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.ListCalls())
+func (mock *InventoryRepositoryMock) ListCalls() []struct {
+	Ctx context.Context
+	Req *domain.InventoryListRequest
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req *domain.InventoryListRequest
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
+	return calls
+}
+
+// ReleaseStock calls ReleaseStockFunc.
+func (mock *InventoryRepositoryMock) ReleaseStock(ctx context.Context, productID int64, quantity int) error {
+	if mock.ReleaseStockFunc == nil {
+		panic("InventoryRepositoryMock.ReleaseStockFunc: method is nil but InventoryRepository.ReleaseStock was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+	}{
+		Ctx:       ctx,
+		ProductID: productID,
+		Quantity:  quantity,
+	}
+	mock.lockReleaseStock.Lock()
+	mock.calls.ReleaseStock = append(mock.calls.ReleaseStock, callInfo)
+	mock.lockReleaseStock.Unlock()
+	return mock.ReleaseStockFunc(ctx, productID, quantity)
+}
+
+// ReleaseStockCalls gets all the calls that were made to ReleaseStock.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.ReleaseStockCalls())
+func (mock *InventoryRepositoryMock) ReleaseStockCalls() []struct {
+	Ctx       context.Context
+	ProductID int64
+	Quantity  int
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+	}
+	mock.lockReleaseStock.RLock()
+	calls = mock.calls.ReleaseStock
+	mock.lockReleaseStock.RUnlock()
+	return calls
+}
+
+// ReserveStock calls ReserveStockFunc.
+func (mock *InventoryRepositoryMock) ReserveStock(ctx context.Context, productID int64, quantity int) error {
+	if mock.ReserveStockFunc == nil {
+		panic("InventoryRepositoryMock.ReserveStockFunc: method is nil but InventoryRepository.ReserveStock was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+	}{
+		Ctx:       ctx,
+		ProductID: productID,
+		Quantity:  quantity,
+	}
+	mock.lockReserveStock.Lock()
+	mock.calls.ReserveStock = append(mock.calls.ReserveStock, callInfo)
+	mock.lockReserveStock.Unlock()
+	return mock.ReserveStockFunc(ctx, productID, quantity)
+}
+
+// ReserveStockCalls gets all the calls that were made to ReserveStock.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.ReserveStockCalls())
+func (mock *InventoryRepositoryMock) ReserveStockCalls() []struct {
+	Ctx       context.Context
+	ProductID int64
+	Quantity  int
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ProductID int64
+		Quantity  int
+	}
+	mock.lockReserveStock.RLock()
+	calls = mock.calls.ReserveStock
+	mock.lockReserveStock.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *InventoryRepositoryMock) Update(ctx context.Context, inventory *domain.Inventory) error {
+	if mock.UpdateFunc == nil {
+		panic("InventoryRepositoryMock.UpdateFunc: method is nil but InventoryRepository.Update was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		Inventory *domain.Inventory
+	}{
+		Ctx:       ctx,
+		Inventory: inventory,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	return mock.UpdateFunc(ctx, inventory)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.UpdateCalls())
+func (mock *InventoryRepositoryMock) UpdateCalls() []struct {
+	Ctx       context.Context
+	Inventory *domain.Inventory
+} {
+	var calls []struct {
+		Ctx       context.Context
+		Inventory *domain.Inventory
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// UpdateWithVersion calls UpdateWithVersionFunc.
+func (mock *InventoryRepositoryMock) UpdateWithVersion(ctx context.Context, inventory *domain.Inventory) error {
+	if mock.UpdateWithVersionFunc == nil {
+		panic("InventoryRepositoryMock.UpdateWithVersionFunc: method is nil but InventoryRepository.UpdateWithVersion was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		Inventory *domain.Inventory
+	}{
+		Ctx:       ctx,
+		Inventory: inventory,
+	}
+	mock.lockUpdateWithVersion.Lock()
+	mock.calls.UpdateWithVersion = append(mock.calls.UpdateWithVersion, callInfo)
+	mock.lockUpdateWithVersion.Unlock()
+	return mock.UpdateWithVersionFunc(ctx, inventory)
+}
+
+// UpdateWithVersionCalls gets all the calls that were made to UpdateWithVersion.
+// Check the length with:
+//
+//	len(mockedInventoryRepository.UpdateWithVersionCalls())
+func (mock *InventoryRepositoryMock) UpdateWithVersionCalls() []struct {
+	Ctx       context.Context
+	Inventory *domain.Inventory
+} {
+	var calls []struct {
+		Ctx       context.Context
+		Inventory *domain.Inventory
+	}
+	mock.lockUpdateWithVersion.RLock()
+	calls = mock.calls.UpdateWithVersion
+	mock.lockUpdateWithVersion.RUnlock()
+	return calls
+}
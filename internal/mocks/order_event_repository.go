@@ -0,0 +1,164 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"sync"
+)
+
+// Ensure, that OrderEventRepositoryMock does implement repo.OrderEventRepository.
+// If this is not the case, regenerate this file with moq.
+var _ repo.OrderEventRepository = &OrderEventRepositoryMock{}
+
+// OrderEventRepositoryMock is a mock implementation of repo.OrderEventRepository.
+//
+//	func TestSomethingThatUsesOrderEventRepository(t *testing.T) {
+//
+//		// make and configure a mocked repo.OrderEventRepository
+//		mockedOrderEventRepository := &OrderEventRepositoryMock{
+//			CreateFunc: func(event *domain.OrderEvent) error {
+//				panic("mock out the Create method")
+//			},
+//			ListBySpikeEventIDFunc: func(spikeEventID int64) ([]*domain.OrderEvent, error) {
+//				panic("mock out the ListBySpikeEventID method")
+//			},
+//			ListBySpikeOrderIDFunc: func(spikeOrderID int64) ([]*domain.OrderEvent, error) {
+//				panic("mock out the ListBySpikeOrderID method")
+//			},
+//		}
+//
+//		// use mockedOrderEventRepository in code that requires repo.OrderEventRepository
+//		// and then make assertions.
+//
+//	}
+type OrderEventRepositoryMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(event *domain.OrderEvent) error
+
+	// ListBySpikeEventIDFunc mocks the ListBySpikeEventID method.
+	ListBySpikeEventIDFunc func(spikeEventID int64) ([]*domain.OrderEvent, error)
+
+	// ListBySpikeOrderIDFunc mocks the ListBySpikeOrderID method.
+	ListBySpikeOrderIDFunc func(spikeOrderID int64) ([]*domain.OrderEvent, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Event is the event argument value.
+			Event *domain.OrderEvent
+		}
+		// ListBySpikeEventID holds details about calls to the ListBySpikeEventID method.
+		ListBySpikeEventID []struct {
+			// SpikeEventID is the spikeEventID argument value.
+			SpikeEventID int64
+		}
+		// ListBySpikeOrderID holds details about calls to the ListBySpikeOrderID method.
+		ListBySpikeOrderID []struct {
+			// SpikeOrderID is the spikeOrderID argument value.
+			SpikeOrderID int64
+		}
+	}
+	lockCreate             sync.RWMutex
+	lockListBySpikeEventID sync.RWMutex
+	lockListBySpikeOrderID sync.RWMutex
+}
+
+// Create calls CreateFunc.
+func (mock *OrderEventRepositoryMock) Create(event *domain.OrderEvent) error {
+	if mock.CreateFunc == nil {
+		panic("OrderEventRepositoryMock.CreateFunc: method is nil but OrderEventRepository.Create was just called")
+	}
+	callInfo := struct {
+		Event *domain.OrderEvent
+	}{
+		Event: event,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(event)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedOrderEventRepository.CreateCalls())
+func (mock *OrderEventRepositoryMock) CreateCalls() []struct {
+	Event *domain.OrderEvent
+} {
+	var calls []struct {
+		Event *domain.OrderEvent
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// ListBySpikeEventID calls ListBySpikeEventIDFunc.
+func (mock *OrderEventRepositoryMock) ListBySpikeEventID(spikeEventID int64) ([]*domain.OrderEvent, error) {
+	if mock.ListBySpikeEventIDFunc == nil {
+		panic("OrderEventRepositoryMock.ListBySpikeEventIDFunc: method is nil but OrderEventRepository.ListBySpikeEventID was just called")
+	}
+	callInfo := struct {
+		SpikeEventID int64
+	}{
+		SpikeEventID: spikeEventID,
+	}
+	mock.lockListBySpikeEventID.Lock()
+	mock.calls.ListBySpikeEventID = append(mock.calls.ListBySpikeEventID, callInfo)
+	mock.lockListBySpikeEventID.Unlock()
+	return mock.ListBySpikeEventIDFunc(spikeEventID)
+}
+
+// ListBySpikeEventIDCalls gets all the calls that were made to ListBySpikeEventID.
+// Check the length with:
+//
+//	len(mockedOrderEventRepository.ListBySpikeEventIDCalls())
+func (mock *OrderEventRepositoryMock) ListBySpikeEventIDCalls() []struct {
+	SpikeEventID int64
+} {
+	var calls []struct {
+		SpikeEventID int64
+	}
+	mock.lockListBySpikeEventID.RLock()
+	calls = mock.calls.ListBySpikeEventID
+	mock.lockListBySpikeEventID.RUnlock()
+	return calls
+}
+
+// ListBySpikeOrderID calls ListBySpikeOrderIDFunc.
+func (mock *OrderEventRepositoryMock) ListBySpikeOrderID(spikeOrderID int64) ([]*domain.OrderEvent, error) {
+	if mock.ListBySpikeOrderIDFunc == nil {
+		panic("OrderEventRepositoryMock.ListBySpikeOrderIDFunc: method is nil but OrderEventRepository.ListBySpikeOrderID was just called")
+	}
+	callInfo := struct {
+		SpikeOrderID int64
+	}{
+		SpikeOrderID: spikeOrderID,
+	}
+	mock.lockListBySpikeOrderID.Lock()
+	mock.calls.ListBySpikeOrderID = append(mock.calls.ListBySpikeOrderID, callInfo)
+	mock.lockListBySpikeOrderID.Unlock()
+	return mock.ListBySpikeOrderIDFunc(spikeOrderID)
+}
+
+// ListBySpikeOrderIDCalls gets all the calls that were made to ListBySpikeOrderID.
+// Check the length with:
+//
+//	len(mockedOrderEventRepository.ListBySpikeOrderIDCalls())
+func (mock *OrderEventRepositoryMock) ListBySpikeOrderIDCalls() []struct {
+	SpikeOrderID int64
+} {
+	var calls []struct {
+		SpikeOrderID int64
+	}
+	mock.lockListBySpikeOrderID.RLock()
+	calls = mock.calls.ListBySpikeOrderID
+	mock.lockListBySpikeOrderID.RUnlock()
+	return calls
+}
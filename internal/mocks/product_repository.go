@@ -0,0 +1,421 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"sync"
+)
+
+// Ensure, that ProductRepositoryMock does implement repo.ProductRepository.
+// If this is not the case, regenerate this file with moq.
+var _ repo.ProductRepository = &ProductRepositoryMock{}
+
+// ProductRepositoryMock is a mock implementation of repo.ProductRepository.
+//
+//	func TestSomethingThatUsesProductRepository(t *testing.T) {
+//
+//		// make and configure a mocked repo.ProductRepository
+//		mockedProductRepository := &ProductRepositoryMock{
+//			CountFunc: func() (int64, error) {
+//				panic("mock out the Count method")
+//			},
+//			CountByStatusFunc: func(status domain.ProductStatus) (int64, error) {
+//				panic("mock out the CountByStatus method")
+//			},
+//			CreateFunc: func(product *domain.Product) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(id int64) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetByIDFunc: func(id int64) (*domain.Product, error) {
+//				panic("mock out the GetByID method")
+//			},
+//			GetByIDsFunc: func(ids []int64) ([]*domain.Product, error) {
+//				panic("mock out the GetByIDs method")
+//			},
+//			GetBySKUFunc: func(sku string) (*domain.Product, error) {
+//				panic("mock out the GetBySKU method")
+//			},
+//			ListFunc: func(req *domain.ProductListRequest) ([]*domain.Product, int64, error) {
+//				panic("mock out the List method")
+//			},
+//			UpdateFunc: func(product *domain.Product) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedProductRepository in code that requires repo.ProductRepository
+//		// and then make assertions.
+//
+//	}
+type ProductRepositoryMock struct {
+	// CountFunc mocks the Count method.
+	CountFunc func() (int64, error)
+
+	// CountByStatusFunc mocks the CountByStatus method.
+	CountByStatusFunc func(status domain.ProductStatus) (int64, error)
+
+	// CreateFunc mocks the Create method.
+	CreateFunc func(product *domain.Product) error
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(id int64) error
+
+	// GetByIDFunc mocks the GetByID method.
+	GetByIDFunc func(id int64) (*domain.Product, error)
+
+	// GetByIDsFunc mocks the GetByIDs method.
+	GetByIDsFunc func(ids []int64) ([]*domain.Product, error)
+
+	// GetBySKUFunc mocks the GetBySKU method.
+	GetBySKUFunc func(sku string) (*domain.Product, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(req *domain.ProductListRequest) ([]*domain.Product, int64, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(product *domain.Product) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Count holds details about calls to the Count method.
+		Count []struct {
+		}
+		// CountByStatus holds details about calls to the CountByStatus method.
+		CountByStatus []struct {
+			// Status is the status argument value.
+			Status domain.ProductStatus
+		}
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Product is the product argument value.
+			Product *domain.Product
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetByID holds details about calls to the GetByID method.
+		GetByID []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetByIDs holds details about calls to the GetByIDs method.
+		GetByIDs []struct {
+			// Ids is the ids argument value.
+			Ids []int64
+		}
+		// GetBySKU holds details about calls to the GetBySKU method.
+		GetBySKU []struct {
+			// Sku is the sku argument value.
+			Sku string
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// Req is the req argument value.
+			Req *domain.ProductListRequest
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Product is the product argument value.
+			Product *domain.Product
+		}
+	}
+	lockCount         sync.RWMutex
+	lockCountByStatus sync.RWMutex
+	lockCreate        sync.RWMutex
+	lockDelete        sync.RWMutex
+	lockGetByID       sync.RWMutex
+	lockGetByIDs      sync.RWMutex
+	lockGetBySKU      sync.RWMutex
+	lockList          sync.RWMutex
+	lockUpdate        sync.RWMutex
+}
+
+// Count calls CountFunc.
+func (mock *ProductRepositoryMock) Count() (int64, error) {
+	if mock.CountFunc == nil {
+		panic("ProductRepositoryMock.CountFunc: method is nil but ProductRepository.Count was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockCount.Lock()
+	mock.calls.Count = append(mock.calls.Count, callInfo)
+	mock.lockCount.Unlock()
+	return mock.CountFunc()
+}
+
+// CountCalls gets all the calls that were made to Count.
+// Check the length with:
+//
+//	len(mockedProductRepository.CountCalls())
+func (mock *ProductRepositoryMock) CountCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockCount.RLock()
+	calls = mock.calls.Count
+	mock.lockCount.RUnlock()
+	return calls
+}
+
+// CountByStatus calls CountByStatusFunc.
+func (mock *ProductRepositoryMock) CountByStatus(status domain.ProductStatus) (int64, error) {
+	if mock.CountByStatusFunc == nil {
+		panic("ProductRepositoryMock.CountByStatusFunc: method is nil but ProductRepository.CountByStatus was just called")
+	}
+	callInfo := struct {
+		Status domain.ProductStatus
+	}{
+		Status: status,
+	}
+	mock.lockCountByStatus.Lock()
+	mock.calls.CountByStatus = append(mock.calls.CountByStatus, callInfo)
+	mock.lockCountByStatus.Unlock()
+	return mock.CountByStatusFunc(status)
+}
+
+// CountByStatusCalls gets all the calls that were made to CountByStatus.
+// Check the length with:
+//
+//	len(mockedProductRepository.CountByStatusCalls())
+func (mock *ProductRepositoryMock) CountByStatusCalls() []struct {
+	Status domain.ProductStatus
+} {
+	var calls []struct {
+		Status domain.ProductStatus
+	}
+	mock.lockCountByStatus.RLock()
+	calls = mock.calls.CountByStatus
+	mock.lockCountByStatus.RUnlock()
+	return calls
+}
+
+// Create calls CreateFunc.
+func (mock *ProductRepositoryMock) Create(product *domain.Product) error {
+	if mock.CreateFunc == nil {
+		panic("ProductRepositoryMock.CreateFunc: method is nil but ProductRepository.Create was just called")
+	}
+	callInfo := struct {
+		Product *domain.Product
+	}{
+		Product: product,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(product)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedProductRepository.CreateCalls())
+func (mock *ProductRepositoryMock) CreateCalls() []struct {
+	Product *domain.Product
+} {
+	var calls []struct {
+		Product *domain.Product
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *ProductRepositoryMock) Delete(id int64) error {
+	if mock.DeleteFunc == nil {
+		panic("ProductRepositoryMock.DeleteFunc: method is nil but ProductRepository.Delete was just called")
+	}
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedProductRepository.DeleteCalls())
+func (mock *ProductRepositoryMock) DeleteCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetByID calls GetByIDFunc.
+func (mock *ProductRepositoryMock) GetByID(id int64) (*domain.Product, error) {
+	if mock.GetByIDFunc == nil {
+		panic("ProductRepositoryMock.GetByIDFunc: method is nil but ProductRepository.GetByID was just called")
+	}
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockGetByID.Lock()
+	mock.calls.GetByID = append(mock.calls.GetByID, callInfo)
+	mock.lockGetByID.Unlock()
+	return mock.GetByIDFunc(id)
+}
+
+// GetByIDCalls gets all the calls that were made to GetByID.
+// Check the length with:
+//
+//	len(mockedProductRepository.GetByIDCalls())
+func (mock *ProductRepositoryMock) GetByIDCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockGetByID.RLock()
+	calls = mock.calls.GetByID
+	mock.lockGetByID.RUnlock()
+	return calls
+}
+
+// GetByIDs calls GetByIDsFunc.
+func (mock *ProductRepositoryMock) GetByIDs(ids []int64) ([]*domain.Product, error) {
+	if mock.GetByIDsFunc == nil {
+		panic("ProductRepositoryMock.GetByIDsFunc: method is nil but ProductRepository.GetByIDs was just called")
+	}
+	callInfo := struct {
+		Ids []int64
+	}{
+		Ids: ids,
+	}
+	mock.lockGetByIDs.Lock()
+	mock.calls.GetByIDs = append(mock.calls.GetByIDs, callInfo)
+	mock.lockGetByIDs.Unlock()
+	return mock.GetByIDsFunc(ids)
+}
+
+// GetByIDsCalls gets all the calls that were made to GetByIDs.
+// Check the length with:
+//
+//	len(mockedProductRepository.GetByIDsCalls())
+func (mock *ProductRepositoryMock) GetByIDsCalls() []struct {
+	Ids []int64
+} {
+	var calls []struct {
+		Ids []int64
+	}
+	mock.lockGetByIDs.RLock()
+	calls = mock.calls.GetByIDs
+	mock.lockGetByIDs.RUnlock()
+	return calls
+}
+
+// GetBySKU calls GetBySKUFunc.
+func (mock *ProductRepositoryMock) GetBySKU(sku string) (*domain.Product, error) {
+	if mock.GetBySKUFunc == nil {
+		panic("ProductRepositoryMock.GetBySKUFunc: method is nil but ProductRepository.GetBySKU was just called")
+	}
+	callInfo := struct {
+		Sku string
+	}{
+		Sku: sku,
+	}
+	mock.lockGetBySKU.Lock()
+	mock.calls.GetBySKU = append(mock.calls.GetBySKU, callInfo)
+	mock.lockGetBySKU.Unlock()
+	return mock.GetBySKUFunc(sku)
+}
+
+// GetBySKUCalls gets all the calls that were made to GetBySKU.
+// Check the length with:
+//
+//	len(mockedProductRepository.GetBySKUCalls())
+func (mock *ProductRepositoryMock) GetBySKUCalls() []struct {
+	Sku string
+} {
+	var calls []struct {
+		Sku string
+	}
+	mock.lockGetBySKU.RLock()
+	calls = mock.calls.GetBySKU
+	mock.lockGetBySKU.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *ProductRepositoryMock) List(req *domain.ProductListRequest) ([]*domain.Product, int64, error) {
+	if mock.ListFunc == nil {
+		panic("ProductRepositoryMock.ListFunc: method is nil but ProductRepository.List was just called")
+	}
+	callInfo := struct {
+		Req *domain.ProductListRequest
+	}{
+		Req: req,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(req)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedProductRepository.ListCalls())
+func (mock *ProductRepositoryMock) ListCalls() []struct {
+	Req *domain.ProductListRequest
+} {
+	var calls []struct {
+		Req *domain.ProductListRequest
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *ProductRepositoryMock) Update(product *domain.Product) error {
+	if mock.UpdateFunc == nil {
+		panic("ProductRepositoryMock.UpdateFunc: method is nil but ProductRepository.Update was just called")
+	}
+	callInfo := struct {
+		Product *domain.Product
+	}{
+		Product: product,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	return mock.UpdateFunc(product)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedProductRepository.UpdateCalls())
+func (mock *ProductRepositoryMock) UpdateCalls() []struct {
+	Product *domain.Product
+} {
+	var calls []struct {
+		Product *domain.Product
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
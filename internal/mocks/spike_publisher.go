@@ -0,0 +1,424 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"sync"
+)
+
+// Ensure, that SpikePublisherMock does implement mq.SpikePublisher.
+// If this is not the case, regenerate this file with moq.
+var _ mq.SpikePublisher = &SpikePublisherMock{}
+
+// SpikePublisherMock is a mock implementation of mq.SpikePublisher.
+//
+//	func TestSomethingThatUsesSpikePublisher(t *testing.T) {
+//
+//		// make and configure a mocked mq.SpikePublisher
+//		mockedSpikePublisher := &SpikePublisherMock{
+//			PublishNotificationFunc: func(ctx context.Context, data *mq.NotificationData, traceID string) error {
+//				panic("mock out the PublishNotification method")
+//			},
+//			PublishSpikeOrderAbandonedFunc: func(ctx context.Context, data *mq.SpikeOrderAbandonedData, traceID string) error {
+//				panic("mock out the PublishSpikeOrderAbandoned method")
+//			},
+//			PublishSpikeOrderCancelledFunc: func(ctx context.Context, data *mq.SpikeOrderCancelledData, traceID string) error {
+//				panic("mock out the PublishSpikeOrderCancelled method")
+//			},
+//			PublishSpikeOrderCreatedFunc: func(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error {
+//				panic("mock out the PublishSpikeOrderCreated method")
+//			},
+//			PublishSpikeOrderExpiredFunc: func(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error {
+//				panic("mock out the PublishSpikeOrderExpired method")
+//			},
+//			PublishSpikeOrderPaidFunc: func(ctx context.Context, data *mq.SpikeOrderPaidData, traceID string) error {
+//				panic("mock out the PublishSpikeOrderPaid method")
+//			},
+//			PublishStockRestoreFunc: func(ctx context.Context, data *mq.StockRestoreData, traceID string) error {
+//				panic("mock out the PublishStockRestore method")
+//			},
+//		}
+//
+//		// use mockedSpikePublisher in code that requires mq.SpikePublisher
+//		// and then make assertions.
+//
+//	}
+type SpikePublisherMock struct {
+	// PublishNotificationFunc mocks the PublishNotification method.
+	PublishNotificationFunc func(ctx context.Context, data *mq.NotificationData, traceID string) error
+
+	// PublishSpikeOrderAbandonedFunc mocks the PublishSpikeOrderAbandoned method.
+	PublishSpikeOrderAbandonedFunc func(ctx context.Context, data *mq.SpikeOrderAbandonedData, traceID string) error
+
+	// PublishSpikeOrderCancelledFunc mocks the PublishSpikeOrderCancelled method.
+	PublishSpikeOrderCancelledFunc func(ctx context.Context, data *mq.SpikeOrderCancelledData, traceID string) error
+
+	// PublishSpikeOrderCreatedFunc mocks the PublishSpikeOrderCreated method.
+	PublishSpikeOrderCreatedFunc func(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error
+
+	// PublishSpikeOrderExpiredFunc mocks the PublishSpikeOrderExpired method.
+	PublishSpikeOrderExpiredFunc func(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error
+
+	// PublishSpikeOrderPaidFunc mocks the PublishSpikeOrderPaid method.
+	PublishSpikeOrderPaidFunc func(ctx context.Context, data *mq.SpikeOrderPaidData, traceID string) error
+
+	// PublishStockRestoreFunc mocks the PublishStockRestore method.
+	PublishStockRestoreFunc func(ctx context.Context, data *mq.StockRestoreData, traceID string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// PublishNotification holds details about calls to the PublishNotification method.
+		PublishNotification []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.NotificationData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+		// PublishSpikeOrderAbandoned holds details about calls to the PublishSpikeOrderAbandoned method.
+		PublishSpikeOrderAbandoned []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.SpikeOrderAbandonedData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+		// PublishSpikeOrderCancelled holds details about calls to the PublishSpikeOrderCancelled method.
+		PublishSpikeOrderCancelled []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.SpikeOrderCancelledData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+		// PublishSpikeOrderCreated holds details about calls to the PublishSpikeOrderCreated method.
+		PublishSpikeOrderCreated []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.SpikeOrderCreatedData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+		// PublishSpikeOrderExpired holds details about calls to the PublishSpikeOrderExpired method.
+		PublishSpikeOrderExpired []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.SpikeOrderExpiredData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+		// PublishSpikeOrderPaid holds details about calls to the PublishSpikeOrderPaid method.
+		PublishSpikeOrderPaid []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.SpikeOrderPaidData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+		// PublishStockRestore holds details about calls to the PublishStockRestore method.
+		PublishStockRestore []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Data is the data argument value.
+			Data *mq.StockRestoreData
+			// TraceID is the traceID argument value.
+			TraceID string
+		}
+	}
+	lockPublishNotification        sync.RWMutex
+	lockPublishSpikeOrderAbandoned sync.RWMutex
+	lockPublishSpikeOrderCancelled sync.RWMutex
+	lockPublishSpikeOrderCreated   sync.RWMutex
+	lockPublishSpikeOrderExpired   sync.RWMutex
+	lockPublishSpikeOrderPaid      sync.RWMutex
+	lockPublishStockRestore        sync.RWMutex
+}
+
+// PublishNotification calls PublishNotificationFunc.
+func (mock *SpikePublisherMock) PublishNotification(ctx context.Context, data *mq.NotificationData, traceID string) error {
+	if mock.PublishNotificationFunc == nil {
+		panic("SpikePublisherMock.PublishNotificationFunc: method is nil but SpikePublisher.PublishNotification was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.NotificationData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishNotification.Lock()
+	mock.calls.PublishNotification = append(mock.calls.PublishNotification, callInfo)
+	mock.lockPublishNotification.Unlock()
+	return mock.PublishNotificationFunc(ctx, data, traceID)
+}
+
+// PublishNotificationCalls gets all the calls that were made to PublishNotification.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishNotificationCalls())
+func (mock *SpikePublisherMock) PublishNotificationCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.NotificationData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.NotificationData
+		TraceID string
+	}
+	mock.lockPublishNotification.RLock()
+	calls = mock.calls.PublishNotification
+	mock.lockPublishNotification.RUnlock()
+	return calls
+}
+
+// PublishSpikeOrderAbandoned calls PublishSpikeOrderAbandonedFunc.
+func (mock *SpikePublisherMock) PublishSpikeOrderAbandoned(ctx context.Context, data *mq.SpikeOrderAbandonedData, traceID string) error {
+	if mock.PublishSpikeOrderAbandonedFunc == nil {
+		panic("SpikePublisherMock.PublishSpikeOrderAbandonedFunc: method is nil but SpikePublisher.PublishSpikeOrderAbandoned was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderAbandonedData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishSpikeOrderAbandoned.Lock()
+	mock.calls.PublishSpikeOrderAbandoned = append(mock.calls.PublishSpikeOrderAbandoned, callInfo)
+	mock.lockPublishSpikeOrderAbandoned.Unlock()
+	return mock.PublishSpikeOrderAbandonedFunc(ctx, data, traceID)
+}
+
+// PublishSpikeOrderAbandonedCalls gets all the calls that were made to PublishSpikeOrderAbandoned.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishSpikeOrderAbandonedCalls())
+func (mock *SpikePublisherMock) PublishSpikeOrderAbandonedCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.SpikeOrderAbandonedData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderAbandonedData
+		TraceID string
+	}
+	mock.lockPublishSpikeOrderAbandoned.RLock()
+	calls = mock.calls.PublishSpikeOrderAbandoned
+	mock.lockPublishSpikeOrderAbandoned.RUnlock()
+	return calls
+}
+
+// PublishSpikeOrderCancelled calls PublishSpikeOrderCancelledFunc.
+func (mock *SpikePublisherMock) PublishSpikeOrderCancelled(ctx context.Context, data *mq.SpikeOrderCancelledData, traceID string) error {
+	if mock.PublishSpikeOrderCancelledFunc == nil {
+		panic("SpikePublisherMock.PublishSpikeOrderCancelledFunc: method is nil but SpikePublisher.PublishSpikeOrderCancelled was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderCancelledData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishSpikeOrderCancelled.Lock()
+	mock.calls.PublishSpikeOrderCancelled = append(mock.calls.PublishSpikeOrderCancelled, callInfo)
+	mock.lockPublishSpikeOrderCancelled.Unlock()
+	return mock.PublishSpikeOrderCancelledFunc(ctx, data, traceID)
+}
+
+// PublishSpikeOrderCancelledCalls gets all the calls that were made to PublishSpikeOrderCancelled.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishSpikeOrderCancelledCalls())
+func (mock *SpikePublisherMock) PublishSpikeOrderCancelledCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.SpikeOrderCancelledData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderCancelledData
+		TraceID string
+	}
+	mock.lockPublishSpikeOrderCancelled.RLock()
+	calls = mock.calls.PublishSpikeOrderCancelled
+	mock.lockPublishSpikeOrderCancelled.RUnlock()
+	return calls
+}
+
+// PublishSpikeOrderCreated calls PublishSpikeOrderCreatedFunc.
+func (mock *SpikePublisherMock) PublishSpikeOrderCreated(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error {
+	if mock.PublishSpikeOrderCreatedFunc == nil {
+		panic("SpikePublisherMock.PublishSpikeOrderCreatedFunc: method is nil but SpikePublisher.PublishSpikeOrderCreated was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderCreatedData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishSpikeOrderCreated.Lock()
+	mock.calls.PublishSpikeOrderCreated = append(mock.calls.PublishSpikeOrderCreated, callInfo)
+	mock.lockPublishSpikeOrderCreated.Unlock()
+	return mock.PublishSpikeOrderCreatedFunc(ctx, data, traceID)
+}
+
+// PublishSpikeOrderCreatedCalls gets all the calls that were made to PublishSpikeOrderCreated.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishSpikeOrderCreatedCalls())
+func (mock *SpikePublisherMock) PublishSpikeOrderCreatedCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.SpikeOrderCreatedData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderCreatedData
+		TraceID string
+	}
+	mock.lockPublishSpikeOrderCreated.RLock()
+	calls = mock.calls.PublishSpikeOrderCreated
+	mock.lockPublishSpikeOrderCreated.RUnlock()
+	return calls
+}
+
+// PublishSpikeOrderExpired calls PublishSpikeOrderExpiredFunc.
+func (mock *SpikePublisherMock) PublishSpikeOrderExpired(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error {
+	if mock.PublishSpikeOrderExpiredFunc == nil {
+		panic("SpikePublisherMock.PublishSpikeOrderExpiredFunc: method is nil but SpikePublisher.PublishSpikeOrderExpired was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderExpiredData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishSpikeOrderExpired.Lock()
+	mock.calls.PublishSpikeOrderExpired = append(mock.calls.PublishSpikeOrderExpired, callInfo)
+	mock.lockPublishSpikeOrderExpired.Unlock()
+	return mock.PublishSpikeOrderExpiredFunc(ctx, data, traceID)
+}
+
+// PublishSpikeOrderExpiredCalls gets all the calls that were made to PublishSpikeOrderExpired.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishSpikeOrderExpiredCalls())
+func (mock *SpikePublisherMock) PublishSpikeOrderExpiredCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.SpikeOrderExpiredData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderExpiredData
+		TraceID string
+	}
+	mock.lockPublishSpikeOrderExpired.RLock()
+	calls = mock.calls.PublishSpikeOrderExpired
+	mock.lockPublishSpikeOrderExpired.RUnlock()
+	return calls
+}
+
+// PublishSpikeOrderPaid calls PublishSpikeOrderPaidFunc.
+func (mock *SpikePublisherMock) PublishSpikeOrderPaid(ctx context.Context, data *mq.SpikeOrderPaidData, traceID string) error {
+	if mock.PublishSpikeOrderPaidFunc == nil {
+		panic("SpikePublisherMock.PublishSpikeOrderPaidFunc: method is nil but SpikePublisher.PublishSpikeOrderPaid was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderPaidData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishSpikeOrderPaid.Lock()
+	mock.calls.PublishSpikeOrderPaid = append(mock.calls.PublishSpikeOrderPaid, callInfo)
+	mock.lockPublishSpikeOrderPaid.Unlock()
+	return mock.PublishSpikeOrderPaidFunc(ctx, data, traceID)
+}
+
+// PublishSpikeOrderPaidCalls gets all the calls that were made to PublishSpikeOrderPaid.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishSpikeOrderPaidCalls())
+func (mock *SpikePublisherMock) PublishSpikeOrderPaidCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.SpikeOrderPaidData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.SpikeOrderPaidData
+		TraceID string
+	}
+	mock.lockPublishSpikeOrderPaid.RLock()
+	calls = mock.calls.PublishSpikeOrderPaid
+	mock.lockPublishSpikeOrderPaid.RUnlock()
+	return calls
+}
+
+// PublishStockRestore calls PublishStockRestoreFunc.
+func (mock *SpikePublisherMock) PublishStockRestore(ctx context.Context, data *mq.StockRestoreData, traceID string) error {
+	if mock.PublishStockRestoreFunc == nil {
+		panic("SpikePublisherMock.PublishStockRestoreFunc: method is nil but SpikePublisher.PublishStockRestore was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Data    *mq.StockRestoreData
+		TraceID string
+	}{
+		Ctx:     ctx,
+		Data:    data,
+		TraceID: traceID,
+	}
+	mock.lockPublishStockRestore.Lock()
+	mock.calls.PublishStockRestore = append(mock.calls.PublishStockRestore, callInfo)
+	mock.lockPublishStockRestore.Unlock()
+	return mock.PublishStockRestoreFunc(ctx, data, traceID)
+}
+
+// PublishStockRestoreCalls gets all the calls that were made to PublishStockRestore.
+// Check the length with:
+//
+//	len(mockedSpikePublisher.PublishStockRestoreCalls())
+func (mock *SpikePublisherMock) PublishStockRestoreCalls() []struct {
+	Ctx     context.Context
+	Data    *mq.StockRestoreData
+	TraceID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Data    *mq.StockRestoreData
+		TraceID string
+	}
+	mock.lockPublishStockRestore.RLock()
+	calls = mock.calls.PublishStockRestore
+	mock.lockPublishStockRestore.RUnlock()
+	return calls
+}
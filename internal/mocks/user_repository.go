@@ -0,0 +1,496 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"sync"
+)
+
+// Ensure, that UserRepositoryMock does implement repo.UserRepository.
+// If this is not the case, regenerate this file with moq.
+var _ repo.UserRepository = &UserRepositoryMock{}
+
+// UserRepositoryMock is a mock implementation of repo.UserRepository.
+//
+//	func TestSomethingThatUsesUserRepository(t *testing.T) {
+//
+//		// make and configure a mocked repo.UserRepository
+//		mockedUserRepository := &UserRepositoryMock{
+//			CreateFunc: func(user *domain.User) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(id int64) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetByEmailFunc: func(email string) (*domain.User, error) {
+//				panic("mock out the GetByEmail method")
+//			},
+//			GetByIDFunc: func(id int64) (*domain.User, error) {
+//				panic("mock out the GetByID method")
+//			},
+//			GetByUsernameFunc: func(username string) (*domain.User, error) {
+//				panic("mock out the GetByUsername method")
+//			},
+//			ListUsersFunc: func(offset int, limit int) ([]*domain.User, int64, error) {
+//				panic("mock out the ListUsers method")
+//			},
+//			UpdateFunc: func(user *domain.User) error {
+//				panic("mock out the Update method")
+//			},
+//			UpdateUserRoleFunc: func(userID int64, role domain.UserRole) error {
+//				panic("mock out the UpdateUserRole method")
+//			},
+//			UpdateUserStatusFunc: func(userID int64, isActive bool) error {
+//				panic("mock out the UpdateUserStatus method")
+//			},
+//			UpdateUserTierFunc: func(userID int64, tier domain.UserTier) error {
+//				panic("mock out the UpdateUserTier method")
+//			},
+//		}
+//
+//		// use mockedUserRepository in code that requires repo.UserRepository
+//		// and then make assertions.
+//
+//	}
+type UserRepositoryMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(user *domain.User) error
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(id int64) error
+
+	// GetByEmailFunc mocks the GetByEmail method.
+	GetByEmailFunc func(email string) (*domain.User, error)
+
+	// GetByIDFunc mocks the GetByID method.
+	GetByIDFunc func(id int64) (*domain.User, error)
+
+	// GetByUsernameFunc mocks the GetByUsername method.
+	GetByUsernameFunc func(username string) (*domain.User, error)
+
+	// ListUsersFunc mocks the ListUsers method.
+	ListUsersFunc func(offset int, limit int) ([]*domain.User, int64, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(user *domain.User) error
+
+	// UpdateUserRoleFunc mocks the UpdateUserRole method.
+	UpdateUserRoleFunc func(userID int64, role domain.UserRole) error
+
+	// UpdateUserStatusFunc mocks the UpdateUserStatus method.
+	UpdateUserStatusFunc func(userID int64, isActive bool) error
+
+	// UpdateUserTierFunc mocks the UpdateUserTier method.
+	UpdateUserTierFunc func(userID int64, tier domain.UserTier) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// User is the user argument value.
+			User *domain.User
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetByEmail holds details about calls to the GetByEmail method.
+		GetByEmail []struct {
+			// Email is the email argument value.
+			Email string
+		}
+		// GetByID holds details about calls to the GetByID method.
+		GetByID []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetByUsername holds details about calls to the GetByUsername method.
+		GetByUsername []struct {
+			// Username is the username argument value.
+			Username string
+		}
+		// ListUsers holds details about calls to the ListUsers method.
+		ListUsers []struct {
+			// Offset is the offset argument value.
+			Offset int
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// User is the user argument value.
+			User *domain.User
+		}
+		// UpdateUserRole holds details about calls to the UpdateUserRole method.
+		UpdateUserRole []struct {
+			// UserID is the userID argument value.
+			UserID int64
+			// Role is the role argument value.
+			Role domain.UserRole
+		}
+		// UpdateUserStatus holds details about calls to the UpdateUserStatus method.
+		UpdateUserStatus []struct {
+			// UserID is the userID argument value.
+			UserID int64
+			// IsActive is the isActive argument value.
+			IsActive bool
+		}
+		// UpdateUserTier holds details about calls to the UpdateUserTier method.
+		UpdateUserTier []struct {
+			// UserID is the userID argument value.
+			UserID int64
+			// Tier is the tier argument value.
+			Tier domain.UserTier
+		}
+	}
+	lockCreate           sync.RWMutex
+	lockDelete           sync.RWMutex
+	lockGetByEmail       sync.RWMutex
+	lockGetByID          sync.RWMutex
+	lockGetByUsername    sync.RWMutex
+	lockListUsers        sync.RWMutex
+	lockUpdate           sync.RWMutex
+	lockUpdateUserRole   sync.RWMutex
+	lockUpdateUserStatus sync.RWMutex
+	lockUpdateUserTier   sync.RWMutex
+}
+
+// Create calls CreateFunc.
+func (mock *UserRepositoryMock) Create(user *domain.User) error {
+	if mock.CreateFunc == nil {
+		panic("UserRepositoryMock.CreateFunc: method is nil but UserRepository.Create was just called")
+	}
+	callInfo := struct {
+		User *domain.User
+	}{
+		User: user,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(user)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedUserRepository.CreateCalls())
+func (mock *UserRepositoryMock) CreateCalls() []struct {
+	User *domain.User
+} {
+	var calls []struct {
+		User *domain.User
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *UserRepositoryMock) Delete(id int64) error {
+	if mock.DeleteFunc == nil {
+		panic("UserRepositoryMock.DeleteFunc: method is nil but UserRepository.Delete was just called")
+	}
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedUserRepository.DeleteCalls())
+func (mock *UserRepositoryMock) DeleteCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetByEmail calls GetByEmailFunc.
+func (mock *UserRepositoryMock) GetByEmail(email string) (*domain.User, error) {
+	if mock.GetByEmailFunc == nil {
+		panic("UserRepositoryMock.GetByEmailFunc: method is nil but UserRepository.GetByEmail was just called")
+	}
+	callInfo := struct {
+		Email string
+	}{
+		Email: email,
+	}
+	mock.lockGetByEmail.Lock()
+	mock.calls.GetByEmail = append(mock.calls.GetByEmail, callInfo)
+	mock.lockGetByEmail.Unlock()
+	return mock.GetByEmailFunc(email)
+}
+
+// GetByEmailCalls gets all the calls that were made to GetByEmail.
+// Check the length with:
+//
+//	len(mockedUserRepository.GetByEmailCalls())
+func (mock *UserRepositoryMock) GetByEmailCalls() []struct {
+	Email string
+} {
+	var calls []struct {
+		Email string
+	}
+	mock.lockGetByEmail.RLock()
+	calls = mock.calls.GetByEmail
+	mock.lockGetByEmail.RUnlock()
+	return calls
+}
+
+// GetByID calls GetByIDFunc.
+func (mock *UserRepositoryMock) GetByID(id int64) (*domain.User, error) {
+	if mock.GetByIDFunc == nil {
+		panic("UserRepositoryMock.GetByIDFunc: method is nil but UserRepository.GetByID was just called")
+	}
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockGetByID.Lock()
+	mock.calls.GetByID = append(mock.calls.GetByID, callInfo)
+	mock.lockGetByID.Unlock()
+	return mock.GetByIDFunc(id)
+}
+
+// GetByIDCalls gets all the calls that were made to GetByID.
+// Check the length with:
+//
+//	len(mockedUserRepository.GetByIDCalls())
+func (mock *UserRepositoryMock) GetByIDCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockGetByID.RLock()
+	calls = mock.calls.GetByID
+	mock.lockGetByID.RUnlock()
+	return calls
+}
+
+// GetByUsername calls GetByUsernameFunc.
+func (mock *UserRepositoryMock) GetByUsername(username string) (*domain.User, error) {
+	if mock.GetByUsernameFunc == nil {
+		panic("UserRepositoryMock.GetByUsernameFunc: method is nil but UserRepository.GetByUsername was just called")
+	}
+	callInfo := struct {
+		Username string
+	}{
+		Username: username,
+	}
+	mock.lockGetByUsername.Lock()
+	mock.calls.GetByUsername = append(mock.calls.GetByUsername, callInfo)
+	mock.lockGetByUsername.Unlock()
+	return mock.GetByUsernameFunc(username)
+}
+
+// GetByUsernameCalls gets all the calls that were made to GetByUsername.
+// Check the length with:
+//
+//	len(mockedUserRepository.GetByUsernameCalls())
+func (mock *UserRepositoryMock) GetByUsernameCalls() []struct {
+	Username string
+} {
+	var calls []struct {
+		Username string
+	}
+	mock.lockGetByUsername.RLock()
+	calls = mock.calls.GetByUsername
+	mock.lockGetByUsername.RUnlock()
+	return calls
+}
+
+// ListUsers calls ListUsersFunc.
+func (mock *UserRepositoryMock) ListUsers(offset int, limit int) ([]*domain.User, int64, error) {
+	if mock.ListUsersFunc == nil {
+		panic("UserRepositoryMock.ListUsersFunc: method is nil but UserRepository.ListUsers was just called")
+	}
+	callInfo := struct {
+		Offset int
+		Limit  int
+	}{
+		Offset: offset,
+		Limit:  limit,
+	}
+	mock.lockListUsers.Lock()
+	mock.calls.ListUsers = append(mock.calls.ListUsers, callInfo)
+	mock.lockListUsers.Unlock()
+	return mock.ListUsersFunc(offset, limit)
+}
+
+// ListUsersCalls gets all the calls that were made to ListUsers.
+// Check the length with:
+//
+//	len(mockedUserRepository.ListUsersCalls())
+func (mock *UserRepositoryMock) ListUsersCalls() []struct {
+	Offset int
+	Limit  int
+} {
+	var calls []struct {
+		Offset int
+		Limit  int
+	}
+	mock.lockListUsers.RLock()
+	calls = mock.calls.ListUsers
+	mock.lockListUsers.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *UserRepositoryMock) Update(user *domain.User) error {
+	if mock.UpdateFunc == nil {
+		panic("UserRepositoryMock.UpdateFunc: method is nil but UserRepository.Update was just called")
+	}
+	callInfo := struct {
+		User *domain.User
+	}{
+		User: user,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	return mock.UpdateFunc(user)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedUserRepository.UpdateCalls())
+func (mock *UserRepositoryMock) UpdateCalls() []struct {
+	User *domain.User
+} {
+	var calls []struct {
+		User *domain.User
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// UpdateUserRole calls UpdateUserRoleFunc.
+func (mock *UserRepositoryMock) UpdateUserRole(userID int64, role domain.UserRole) error {
+	if mock.UpdateUserRoleFunc == nil {
+		panic("UserRepositoryMock.UpdateUserRoleFunc: method is nil but UserRepository.UpdateUserRole was just called")
+	}
+	callInfo := struct {
+		UserID int64
+		Role   domain.UserRole
+	}{
+		UserID: userID,
+		Role:   role,
+	}
+	mock.lockUpdateUserRole.Lock()
+	mock.calls.UpdateUserRole = append(mock.calls.UpdateUserRole, callInfo)
+	mock.lockUpdateUserRole.Unlock()
+	return mock.UpdateUserRoleFunc(userID, role)
+}
+
+// UpdateUserRoleCalls gets all the calls that were made to UpdateUserRole.
+// Check the length with:
+//
+//	len(mockedUserRepository.UpdateUserRoleCalls())
+func (mock *UserRepositoryMock) UpdateUserRoleCalls() []struct {
+	UserID int64
+	Role   domain.UserRole
+} {
+	var calls []struct {
+		UserID int64
+		Role   domain.UserRole
+	}
+	mock.lockUpdateUserRole.RLock()
+	calls = mock.calls.UpdateUserRole
+	mock.lockUpdateUserRole.RUnlock()
+	return calls
+}
+
+// UpdateUserStatus calls UpdateUserStatusFunc.
+func (mock *UserRepositoryMock) UpdateUserStatus(userID int64, isActive bool) error {
+	if mock.UpdateUserStatusFunc == nil {
+		panic("UserRepositoryMock.UpdateUserStatusFunc: method is nil but UserRepository.UpdateUserStatus was just called")
+	}
+	callInfo := struct {
+		UserID   int64
+		IsActive bool
+	}{
+		UserID:   userID,
+		IsActive: isActive,
+	}
+	mock.lockUpdateUserStatus.Lock()
+	mock.calls.UpdateUserStatus = append(mock.calls.UpdateUserStatus, callInfo)
+	mock.lockUpdateUserStatus.Unlock()
+	return mock.UpdateUserStatusFunc(userID, isActive)
+}
+
+// UpdateUserStatusCalls gets all the calls that were made to UpdateUserStatus.
+// Check the length with:
+//
+//	len(mockedUserRepository.UpdateUserStatusCalls())
+func (mock *UserRepositoryMock) UpdateUserStatusCalls() []struct {
+	UserID   int64
+	IsActive bool
+} {
+	var calls []struct {
+		UserID   int64
+		IsActive bool
+	}
+	mock.lockUpdateUserStatus.RLock()
+	calls = mock.calls.UpdateUserStatus
+	mock.lockUpdateUserStatus.RUnlock()
+	return calls
+}
+
+// UpdateUserTier calls UpdateUserTierFunc.
+func (mock *UserRepositoryMock) UpdateUserTier(userID int64, tier domain.UserTier) error {
+	if mock.UpdateUserTierFunc == nil {
+		panic("UserRepositoryMock.UpdateUserTierFunc: method is nil but UserRepository.UpdateUserTier was just called")
+	}
+	callInfo := struct {
+		UserID int64
+		Tier   domain.UserTier
+	}{
+		UserID: userID,
+		Tier:   tier,
+	}
+	mock.lockUpdateUserTier.Lock()
+	mock.calls.UpdateUserTier = append(mock.calls.UpdateUserTier, callInfo)
+	mock.lockUpdateUserTier.Unlock()
+	return mock.UpdateUserTierFunc(userID, tier)
+}
+
+// UpdateUserTierCalls gets all the calls that were made to UpdateUserTier.
+// Check the length with:
+//
+//	len(mockedUserRepository.UpdateUserTierCalls())
+func (mock *UserRepositoryMock) UpdateUserTierCalls() []struct {
+	UserID int64
+	Tier   domain.UserTier
+} {
+	var calls []struct {
+		UserID int64
+		Tier   domain.UserTier
+	}
+	mock.lockUpdateUserTier.RLock()
+	calls = mock.calls.UpdateUserTier
+	mock.lockUpdateUserTier.RUnlock()
+	return calls
+}
@@ -91,6 +91,11 @@ type ConsumerConfig struct {
 
 	// 并发消费
 	ConcurrentConsumers int `mapstructure:"concurrent_consumers" json:"concurrent_consumers"`
+
+	// 批量消费：设置批量处理函数且 BatchSize 大于 1 时，每个工作器累积最多 BatchSize 条消息
+	// 或自首条消息起等待 BatchWait 后一次处理
+	BatchSize int           `mapstructure:"batch_size" json:"batch_size"`
+	BatchWait time.Duration `mapstructure:"batch_wait" json:"batch_wait"`
 }
 
 // ExchangeConfig 交换机配置
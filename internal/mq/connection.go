@@ -95,12 +95,17 @@ func (cm *ConnectionManager) Connect(ctx context.Context) error {
 		return fmt.Errorf("connection is already in progress or connected")
 	}
 
-	cm.logger.Info("连接RabbitMQ", zap.String("url", cm.config.GetConnectionURL()))
+	// 连接地址包含密码，只记录主机与虚拟主机
+	cm.logger.Info("连接RabbitMQ",
+		zap.String("host", cm.config.Host),
+		zap.Int("port", cm.config.Port),
+		zap.String("vhost", cm.config.VHost))
 
 	// 创建连接配置
 	connConfig := amqp.Config{
 		Heartbeat: cm.config.HeartbeatInterval,
 		Locale:    "en_US",
+		Dial:      amqp.DefaultDial(cm.config.ConnectionTimeout),
 	}
 
 	// 设置TLS配置
@@ -319,6 +324,7 @@ func (cm *ConnectionManager) connectInternal(ctx context.Context) error {
 	connConfig := amqp.Config{
 		Heartbeat: cm.config.HeartbeatInterval,
 		Locale:    "en_US",
+		Dial:      amqp.DefaultDial(cm.config.ConnectionTimeout),
 	}
 
 	if cm.config.UseTLS {
@@ -366,6 +372,17 @@ func (cm *ConnectionManager) healthCheck() {
 	}
 }
 
+// Ping 主动探测连接是否可用，供就绪检查使用。amqp 建通道不支持 ctx，超时由调用方控制
+func (cm *ConnectionManager) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !cm.IsConnected() {
+		return fmt.Errorf("connection state is %s", cm.GetState())
+	}
+	return cm.pingConnection()
+}
+
 // pingConnection 测试连接
 func (cm *ConnectionManager) pingConnection() error {
 	cm.connMutex.RLock()
@@ -16,6 +16,9 @@ import (
 // MessageHandler 消息处理函数
 type MessageHandler func(ctx context.Context, delivery amqp.Delivery) error
 
+// BatchMessageHandler 批量消息处理函数，返回与 deliveries 一一对应的处理结果
+type BatchMessageHandler func(ctx context.Context, deliveries []amqp.Delivery) []error
+
 // Consumer RabbitMQ消费者
 type Consumer struct {
 	cm      *ConnectionManager
@@ -23,6 +26,9 @@ type Consumer struct {
 	logger  *zap.Logger
 	handler MessageHandler
 
+	// 批量处理函数，可为空
+	batchHandler BatchMessageHandler
+
 	// 消费配置
 	queueName   string
 	consumerTag string
@@ -53,6 +59,7 @@ type Consumer struct {
 	processedCount int64
 	failedCount    int64
 	retriedCount   int64
+	startedAt      int64 // 开始消费的时间（UnixNano），用于计算消息速率
 }
 
 // ConsumerWorker 消费者工作器
@@ -110,6 +117,17 @@ func (c *Consumer) SetHandler(handler MessageHandler) {
 	c.handler = handler
 }
 
+// SetBatchHandler 设置批量消息处理函数，配置的 BatchSize 大于 1 时生效。
+// 批量处理成功的消息逐条确认，不可重试的失败直接拒绝，其余失败的消息交给 SetHandler 设置的处理函数逐条重试
+func (c *Consumer) SetBatchHandler(handler BatchMessageHandler) {
+	c.batchHandler = handler
+}
+
+// batchEnabled 检查是否按批处理消息
+func (c *Consumer) batchEnabled() bool {
+	return c.batchHandler != nil && c.config.BatchSize > 1 && !c.autoAck
+}
+
 // StartConsuming 开始消费消息
 func (c *Consumer) StartConsuming(ctx context.Context, queueName string) error {
 	if !atomic.CompareAndSwapInt32(&c.running, 0, 1) {
@@ -123,6 +141,7 @@ func (c *Consumer) StartConsuming(ctx context.Context, queueName string) error {
 
 	c.queueName = queueName
 	c.consumerTag = fmt.Sprintf("consumer-%s-%d", queueName, time.Now().Unix())
+	atomic.StoreInt64(&c.startedAt, time.Now().UnixNano())
 
 	c.logger.Info("开始消费消息",
 		zap.String("queue", queueName),
@@ -165,8 +184,12 @@ func (c *Consumer) createWorker(ctx context.Context, id int) (*ConsumerWorker, e
 		return nil, fmt.Errorf("failed to get channel: %w", err)
 	}
 
-	// 设置QoS
-	if err := ch.Qos(c.config.PrefetchCount, c.config.PrefetchSize, false); err != nil {
+	// 设置QoS，批量消费时预取数不能小于批大小，否则攒不满一批
+	prefetch := c.config.PrefetchCount
+	if c.batchEnabled() {
+		prefetch = max(prefetch, c.config.BatchSize)
+	}
+	if err := ch.Qos(prefetch, c.config.PrefetchSize, false); err != nil {
 		c.cm.ReturnChannel(ch)
 		return nil, fmt.Errorf("failed to set QoS: %w", err)
 	}
@@ -224,6 +247,11 @@ func (w *ConsumerWorker) run() {
 		zap.Int("worker_id", w.id),
 		zap.String("queue", w.consumer.queueName))
 
+	if w.consumer.batchEnabled() {
+		w.runBatch()
+		return
+	}
+
 	for {
 		select {
 		case delivery, ok := <-w.delivery:
@@ -241,6 +269,95 @@ func (w *ConsumerWorker) run() {
 	}
 }
 
+// runBatch 批量消费：累积到批大小或自首条消息起等待 BatchWait 后处理一批。
+// 停止时未处理的消息不确认，通道关闭后由 Broker 重新投递
+func (w *ConsumerWorker) runBatch() {
+	size, wait := w.consumer.config.BatchSize, w.consumer.config.BatchWait
+	batch := make([]amqp.Delivery, 0, size)
+	timer := time.NewTimer(wait)
+	timer.Stop()
+	defer timer.Stop()
+
+	var deadline <-chan time.Time
+	flush := func() {
+		timer.Stop()
+		deadline = nil
+		w.processBatch(batch)
+		batch = make([]amqp.Delivery, 0, size)
+	}
+
+	for {
+		select {
+		case delivery, ok := <-w.delivery:
+			if !ok {
+				w.consumer.logger.Info("消费通道关闭", zap.Int("worker_id", w.id))
+				return
+			}
+
+			batch = append(batch, delivery)
+			if len(batch) == 1 {
+				timer.Reset(wait)
+				deadline = timer.C
+			}
+			if len(batch) >= size {
+				flush()
+			}
+
+		case <-deadline:
+			flush()
+
+		case <-w.ctx.Done():
+			w.consumer.logger.Info("消费者工作器停止",
+				zap.Int("worker_id", w.id),
+				zap.Int("unprocessed", len(batch)))
+			return
+		}
+	}
+}
+
+// processBatch 批量处理消息并逐条确认，可重试的失败逐条交给 processMessage 重试
+func (w *ConsumerWorker) processBatch(batch []amqp.Delivery) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(w.ctx, w.consumer.config.ConsumeTimeout)
+	errs := w.consumer.batchHandler(ctx, batch)
+	cancel()
+
+	if len(errs) != len(batch) {
+		err := fmt.Errorf("batch handler returned %d results for %d messages", len(errs), len(batch))
+		errs = make([]error, len(batch))
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+
+	var retried int
+	for i, delivery := range batch {
+		var nonRetryable *NonRetryableError
+		switch err := errs[i]; {
+		case err == nil:
+			w.ack(delivery)
+			atomic.AddInt64(&w.consumer.processedCount, 1)
+		case w.ctx.Err() != nil:
+			// 工作器停止中，不确认，通道关闭后由 Broker 重新投递
+		case errors.As(err, &nonRetryable):
+			w.consumer.logger.Error("消息处理失败",
+				zap.Error(err),
+				zap.String("message_id", delivery.MessageId))
+			atomic.AddInt64(&w.consumer.failedCount, 1)
+			w.reject(delivery)
+		default:
+			retried++
+			w.processMessage(delivery)
+		}
+	}
+
+	w.consumer.logger.Debug("批量消息处理完成",
+		zap.Int("worker_id", w.id),
+		zap.Int("batch_size", len(batch)),
+		zap.Int("retried", retried),
+		zap.Duration("duration", time.Since(start)))
+}
+
 // processMessage 处理消息
 func (w *ConsumerWorker) processMessage(delivery amqp.Delivery) {
 	start := time.Now()
@@ -269,13 +386,7 @@ retryLoop:
 		err = w.consumer.handler(ctx, delivery)
 		if err == nil {
 			// 处理成功
-			if !w.consumer.autoAck {
-				if ackErr := delivery.Ack(false); ackErr != nil {
-					w.consumer.logger.Error("消息确认失败",
-						zap.Error(ackErr),
-						zap.String("message_id", delivery.MessageId))
-				}
-			}
+			w.ack(delivery)
 			atomic.AddInt64(&w.consumer.processedCount, 1)
 			return
 		}
@@ -305,7 +416,23 @@ retryLoop:
 
 	// 最终处理失败
 	atomic.AddInt64(&w.consumer.failedCount, 1)
+	w.reject(delivery)
+}
 
+// ack 确认消息
+func (w *ConsumerWorker) ack(delivery amqp.Delivery) {
+	if w.consumer.autoAck {
+		return
+	}
+	if ackErr := delivery.Ack(false); ackErr != nil {
+		w.consumer.logger.Error("消息确认失败",
+			zap.Error(ackErr),
+			zap.String("message_id", delivery.MessageId))
+	}
+}
+
+// reject 拒绝处理失败的消息，启用死信队列时转入死信队列，否则直接丢弃
+func (w *ConsumerWorker) reject(delivery amqp.Delivery) {
 	if !w.consumer.autoAck {
 		if w.consumer.enableDLX {
 			// 发送到死信队列
@@ -355,7 +482,7 @@ func (c *Consumer) IsClosed() bool {
 
 // GetStats 获取统计信息
 func (c *Consumer) GetStats() ConsumerStats {
-	return ConsumerStats{
+	stats := ConsumerStats{
 		QueueName:           c.queueName,
 		ConsumerTag:         c.consumerTag,
 		ConcurrentConsumers: c.concurrentConsumers,
@@ -365,18 +492,31 @@ func (c *Consumer) GetStats() ConsumerStats {
 		Running:             c.IsRunning(),
 		Closed:              c.IsClosed(),
 	}
+
+	// 按启动以来的平均值计算消息速率
+	if startedAt := atomic.LoadInt64(&c.startedAt); startedAt > 0 {
+		uptime := time.Since(time.Unix(0, startedAt)).Seconds()
+		stats.UptimeSeconds = uptime
+		if uptime > 0 {
+			stats.MessagesPerSecond = float64(stats.ProcessedCount+stats.FailedCount) / uptime
+		}
+	}
+
+	return stats
 }
 
 // ConsumerStats 消费者统计信息
 type ConsumerStats struct {
-	QueueName           string `json:"queue_name"`
-	ConsumerTag         string `json:"consumer_tag"`
-	ConcurrentConsumers int    `json:"concurrent_consumers"`
-	ProcessedCount      int64  `json:"processed_count"`
-	FailedCount         int64  `json:"failed_count"`
-	RetriedCount        int64  `json:"retried_count"`
-	Running             bool   `json:"running"`
-	Closed              bool   `json:"closed"`
+	QueueName           string  `json:"queue_name"`
+	ConsumerTag         string  `json:"consumer_tag"`
+	ConcurrentConsumers int     `json:"concurrent_consumers"`
+	ProcessedCount      int64   `json:"processed_count"`
+	FailedCount         int64   `json:"failed_count"`
+	RetriedCount        int64   `json:"retried_count"`
+	UptimeSeconds       float64 `json:"uptime_seconds"`      // 开始消费至今的秒数
+	MessagesPerSecond   float64 `json:"messages_per_second"` // 平均消息处理速率（含失败）
+	Running             bool    `json:"running"`
+	Closed              bool    `json:"closed"`
 }
 
 // JSONMessageHandler 通用JSON消息处理器
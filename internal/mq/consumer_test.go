@@ -0,0 +1,82 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// recordingAcknowledger 按投递标签记录消息的确认结果
+type recordingAcknowledger struct {
+	mu      sync.Mutex
+	results map[uint64]string
+}
+
+func (a *recordingAcknowledger) record(tag uint64, result string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.results[tag] = result
+	return nil
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	return a.record(tag, "ack")
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	return a.record(tag, "nack")
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.record(tag, "reject")
+}
+
+func TestConsumerWorker_ProcessBatch(t *testing.T) {
+	consumer := NewConsumer(nil, &ConsumerConfig{
+		EnableRetry:      true,
+		MaxRetryAttempts: 1,
+		RetryInterval:    time.Millisecond,
+		EnableDLX:        true,
+		ConsumeTimeout:   time.Second,
+		BatchSize:        3,
+		BatchWait:        time.Millisecond,
+	}, nil)
+
+	// 批量处理：第一条成功，第二条不可重试，第三条可重试并在逐条处理时成功
+	var retried []uint64
+	consumer.SetBatchHandler(func(ctx context.Context, deliveries []amqp.Delivery) []error {
+		return []error{nil, &NonRetryableError{Err: errors.New("insufficient stock")}, errors.New("deadlock")}
+	})
+	consumer.SetHandler(func(ctx context.Context, delivery amqp.Delivery) error {
+		retried = append(retried, delivery.DeliveryTag)
+		return nil
+	})
+	if !consumer.batchEnabled() {
+		t.Fatal("batchEnabled() = false, want true with batch handler and size 3")
+	}
+
+	ack := &recordingAcknowledger{results: make(map[uint64]string)}
+	batch := make([]amqp.Delivery, 3)
+	for i := range batch {
+		batch[i] = amqp.Delivery{Acknowledger: ack, DeliveryTag: uint64(i + 1)}
+	}
+	worker := &ConsumerWorker{consumer: consumer, ctx: context.Background()}
+	worker.processBatch(batch)
+
+	want := map[uint64]string{1: "ack", 2: "nack", 3: "ack"}
+	for tag, result := range want {
+		if ack.results[tag] != result {
+			t.Errorf("delivery %d = %q, want %q", tag, ack.results[tag], result)
+		}
+	}
+	if len(retried) != 1 || retried[0] != 3 {
+		t.Errorf("retried deliveries = %v, want [3]", retried)
+	}
+	if stats := consumer.GetStats(); stats.ProcessedCount != 2 || stats.FailedCount != 1 {
+		t.Errorf("stats = %+v, want 2 processed and 1 failed", stats)
+	}
+}
@@ -0,0 +1,560 @@
+// Package mq 提供基于 Redis Streams 的消息总线实现，适用于没有部署 RabbitMQ/Kafka 的环境
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
+)
+
+// Stream 消息字段
+const (
+	streamFieldBody        = "body"
+	streamFieldMessageID   = "message_id"
+	streamFieldMessageType = "message_type"
+	streamFieldTraceID     = "trace_id"
+	streamFieldExpireAt    = "expire_at" // 消息过期时间（Unix 毫秒），过期后消费者直接确认不处理
+
+	// 死信 Stream 附加字段
+	streamFieldSourceStream = "source_stream"
+	streamFieldSourceID     = "source_id"
+	streamFieldError        = "error"
+	streamFieldDeliveries   = "deliveries"
+)
+
+// RedisStreamConfig Redis Streams 消息总线配置
+type RedisStreamConfig struct {
+	MaxLen           int64         // 每个 Stream 保留的近似最大消息数（XADD MAXLEN ~）
+	Group            string        // 消费组名称
+	Consumer         string        // 消费者名称，为空时使用 主机名-进程号
+	BatchSize        int64         // 每次读取/认领的最大消息数
+	BlockTimeout     time.Duration // XREADGROUP 阻塞等待时长
+	ClaimMinIdle     time.Duration // 待确认消息空闲超过该时长后被重新认领重试
+	ClaimInterval    time.Duration // 检查待确认消息的间隔
+	MaxDeliveries    int64         // 单条消息最多投递次数，超过后转入死信 Stream
+	DeadLetterStream string        // 死信 Stream 名称
+	HandleTimeout    time.Duration // 单条消息处理超时
+	KeyPrefix        string        // 环境键前缀，Stream 名称（含死信 Stream）以 prefix: 开头
+}
+
+// DefaultRedisStreamConfig 返回默认的 Redis Streams 配置
+func DefaultRedisStreamConfig() *RedisStreamConfig {
+	return &RedisStreamConfig{
+		MaxLen:           100000,
+		Group:            "spike-shop",
+		BatchSize:        10,
+		BlockTimeout:     2 * time.Second,
+		ClaimMinIdle:     30 * time.Second,
+		ClaimInterval:    5 * time.Second,
+		MaxDeliveries:    5,
+		DeadLetterStream: SpikeDLXQueue,
+		HandleTimeout:    30 * time.Second,
+	}
+}
+
+// consumerName 返回消费者名称，未配置时使用 主机名-进程号 保证多实例不冲突
+func (c *RedisStreamConfig) consumerName() string {
+	if c.Consumer != "" {
+		return c.Consumer
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// streamKey 返回加上环境前缀后的 Stream 键
+func (c *RedisStreamConfig) streamKey(stream string) string {
+	return keys.WithPrefix(c.KeyPrefix, stream)
+}
+
+// StreamForMessage 按与 RabbitMQ 队列绑定一致的规则返回消息所属的 Stream
+func StreamForMessage(msgType MessageType) string {
+	switch msgType {
+	case MessageTypeSpikeOrderCreated, MessageTypeSpikeOrderPaid:
+		return SpikeOrderStream
+	case MessageTypeSpikeOrderExpired, MessageTypeSpikeOrderCancelled, MessageTypeStockRestore:
+		return SpikeStockRestoreQueue
+	case MessageTypeNotification, MessageTypeOrderConfirmation:
+		return SpikeNotificationQueue
+	case MessageTypeSpikeOrderAbandoned:
+		return SpikeMarketingQueue
+	default:
+		return SpikeDLXQueue
+	}
+}
+
+// RedisStreamProducer 基于 Redis Streams 的秒杀消息生产者
+// Streams 不支持消息优先级，RabbitMQ 实现中的优先级在此被忽略
+type RedisStreamProducer struct {
+	client redis.Cmdable
+	config *RedisStreamConfig
+	logger *zap.Logger
+}
+
+// NewRedisStreamProducer 创建 Redis Streams 生产者
+func NewRedisStreamProducer(client redis.Cmdable, config *RedisStreamConfig, logger *zap.Logger) *RedisStreamProducer {
+	if config == nil {
+		config = DefaultRedisStreamConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &RedisStreamProducer{
+		client: client,
+		config: config,
+		logger: logger,
+	}
+}
+
+// PublishSpikeOrderCreated 发布秒杀订单创建消息
+func (p *RedisStreamProducer) PublishSpikeOrderCreated(ctx context.Context, data *SpikeOrderCreatedData, traceID string) error {
+	return p.publishMessage(ctx, CreateSpikeOrderCreatedMessage(data, traceID), nil)
+}
+
+// PublishSpikeOrderPaid 发布秒杀订单支付消息
+func (p *RedisStreamProducer) PublishSpikeOrderPaid(ctx context.Context, data *SpikeOrderPaidData, traceID string) error {
+	return p.publishMessage(ctx, CreateSpikeOrderPaidMessage(data, traceID), nil)
+}
+
+// PublishSpikeOrderExpired 发布秒杀订单过期消息
+func (p *RedisStreamProducer) PublishSpikeOrderExpired(ctx context.Context, data *SpikeOrderExpiredData, traceID string) error {
+	return p.publishMessage(ctx, CreateSpikeOrderExpiredMessage(data, traceID), nil)
+}
+
+// PublishSpikeOrderCancelled 发布秒杀订单取消消息
+func (p *RedisStreamProducer) PublishSpikeOrderCancelled(ctx context.Context, data *SpikeOrderCancelledData, traceID string) error {
+	return p.publishMessage(ctx, CreateSpikeOrderCancelledMessage(data, traceID), nil)
+}
+
+// PublishSpikeOrderAbandoned 发布弃单消息，供营销系统发起召回
+func (p *RedisStreamProducer) PublishSpikeOrderAbandoned(ctx context.Context, data *SpikeOrderAbandonedData, traceID string) error {
+	return p.publishMessage(ctx, CreateSpikeOrderAbandonedMessage(data, traceID), nil)
+}
+
+// PublishStockRestore 发布库存恢复消息
+func (p *RedisStreamProducer) PublishStockRestore(ctx context.Context, data *StockRestoreData, traceID string) error {
+	return p.publishMessage(ctx, CreateStockRestoreMessage(data, traceID), nil)
+}
+
+// PublishNotification 发布通知消息，设置了 ExpireAt 时消费者会丢弃过期的通知
+func (p *RedisStreamProducer) PublishNotification(ctx context.Context, data *NotificationData, traceID string) error {
+	return p.publishMessage(ctx, CreateNotificationMessage(data, traceID), data.ExpireAt)
+}
+
+// publishMessage 序列化消息并追加到目标 Stream
+func (p *RedisStreamProducer) publishMessage(ctx context.Context, message *SpikeMessage, expireAt *time.Time) (err error) {
+	defer func() { metrics.MQPublished.Inc(string(message.Type), metrics.Result(err)) }()
+
+	// 调用方未显式传入追踪ID时沿用上下文中的追踪ID
+	if message.TraceID == "" {
+		message.TraceID = tracing.TraceIDFromContext(ctx)
+	}
+
+	messageBytes, err := message.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+
+	values := map[string]interface{}{
+		streamFieldBody:        messageBytes,
+		streamFieldMessageID:   message.ID,
+		streamFieldMessageType: string(message.Type),
+		streamFieldTraceID:     message.TraceID,
+	}
+	if expireAt != nil {
+		values[streamFieldExpireAt] = expireAt.UnixMilli()
+	}
+
+	stream := p.config.streamKey(StreamForMessage(message.Type))
+	p.logger.Info("发布秒杀消息",
+		zap.String("message_id", message.ID),
+		zap.String("message_type", string(message.Type)),
+		zap.String("stream", stream),
+		zap.String("trace_id", message.TraceID))
+
+	if err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: p.config.MaxLen,
+		Approx: true,
+		Values: values,
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to publish message to stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+// GetQueueInfo 返回 Stream 的消息数与消费组内的消费者数；死信 Stream 没有消费组，消费者数为 0
+func (p *RedisStreamProducer) GetQueueInfo(ctx context.Context, queueName string) (*QueueInfo, error) {
+	stream := p.config.streamKey(queueName)
+	length, err := p.client.XLen(ctx, stream).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get length of stream %s: %w", stream, err)
+	}
+
+	info := &QueueInfo{Name: queueName, Messages: int(length)}
+	groups, err := p.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		// 尚未写入过消息的 Stream 不存在，视为空队列
+		if length == 0 {
+			return info, nil
+		}
+		return nil, fmt.Errorf("failed to get groups of stream %s: %w", stream, err)
+	}
+	for _, group := range groups {
+		if group.Name == p.config.Group {
+			info.Consumers = int(group.Consumers)
+		}
+	}
+	return info, nil
+}
+
+// RedisStreamConsumer 基于 Redis Streams 消费组的消费者：
+// 1) XREADGROUP 读取新消息，处理成功后 XACK；
+// 2) 可重试错误不确认，消息留在待确认列表，空闲超过 ClaimMinIdle 后由任一实例 XCLAIM 认领重试；
+// 3) 不可重试错误或投递次数达到 MaxDeliveries 时转入死信 Stream 并确认。
+type RedisStreamConsumer struct {
+	client   redis.Cmdable
+	stream   string
+	consumer string
+	config   *RedisStreamConfig
+	handler  MessageHandler
+	logger   *zap.Logger
+
+	processedCount    int64
+	failedCount       int64
+	deadLetteredCount int64
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running bool
+}
+
+// NewRedisStreamConsumer 创建 Redis Streams 消费者
+func NewRedisStreamConsumer(client redis.Cmdable, stream string, config *RedisStreamConfig, handler MessageHandler, logger *zap.Logger) *RedisStreamConsumer {
+	if config == nil {
+		config = DefaultRedisStreamConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	stream = config.streamKey(stream)
+	return &RedisStreamConsumer{
+		client:   client,
+		stream:   stream,
+		consumer: config.consumerName(),
+		config:   config,
+		handler:  handler,
+		logger:   logger.With(zap.String("stream", stream), zap.String("group", config.Group)),
+	}
+}
+
+// Start 创建消费组（已存在时忽略）并在后台开始消费，ctx 取消后停止
+func (c *RedisStreamConsumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return fmt.Errorf("consumer for stream %s is already running", c.stream)
+	}
+	if c.handler == nil {
+		return fmt.Errorf("message handler not set")
+	}
+	if err := c.ensureGroup(ctx); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	c.running = true
+
+	go c.run(runCtx)
+
+	c.logger.Info("Redis Stream 消费者启动", zap.String("consumer", c.consumer))
+	return nil
+}
+
+// Stop 停止拉取新消息，并等待已取到的消息处理完成（单条处理受 HandleTimeout 限制）
+func (c *RedisStreamConsumer) Stop() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = false
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+
+	cancel()
+	<-done
+	c.logger.Info("Redis Stream 消费者停止", zap.String("consumer", c.consumer))
+}
+
+// ensureGroup 创建消费组，Stream 不存在时一并创建
+func (c *RedisStreamConsumer) ensureGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.stream, c.config.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s on stream %s: %w", c.config.Group, c.stream, err)
+	}
+	return nil
+}
+
+// run 消费主循环：周期性认领超时的待确认消息，其余时间阻塞读取新消息
+func (c *RedisStreamConsumer) run(ctx context.Context) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.config.ClaimInterval)
+	defer ticker.Stop()
+
+	// 启动时先处理上次遗留的待确认消息
+	c.claimPending(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.claimPending(ctx)
+		default:
+		}
+
+		if err := c.readNew(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("读取 Stream 消息失败", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// readNew 读取尚未投递给本组的新消息
+func (c *RedisStreamConsumer) readNew(ctx context.Context) error {
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.config.Group,
+		Consumer: c.consumer,
+		Streams:  []string{c.stream, ">"},
+		Count:    c.config.BatchSize,
+		Block:    c.config.BlockTimeout,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 已取到的消息视为进行中，停止消费时仍处理完毕，避免等待 ClaimMinIdle 后才被重新认领
+	ctx = context.WithoutCancel(ctx)
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			c.process(ctx, msg, 1)
+		}
+	}
+	return nil
+}
+
+// claimPending 认领空闲超时的待确认消息：未超过最大投递次数的重新处理，否则转入死信
+func (c *RedisStreamConsumer) claimPending(ctx context.Context) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.stream,
+		Group:  c.config.Group,
+		Idle:   c.config.ClaimMinIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  c.config.BatchSize,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Error("查询待确认消息失败", zap.Error(err))
+		}
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(pending))
+	deliveries := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ID)
+		deliveries[p.ID] = p.RetryCount
+	}
+
+	// XCLAIM 带 MinIdle，多个实例同时认领时只有一个能成功
+	claimed, err := c.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   c.stream,
+		Group:    c.config.Group,
+		Consumer: c.consumer,
+		MinIdle:  c.config.ClaimMinIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Error("认领待确认消息失败", zap.Error(err))
+		}
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, msg := range claimed {
+		// 认领本身算一次投递
+		delivered := deliveries[msg.ID] + 1
+		if deliveries[msg.ID] >= c.config.MaxDeliveries {
+			c.deadLetter(ctx, msg, delivered, fmt.Errorf("exceeded max deliveries %d", c.config.MaxDeliveries))
+			continue
+		}
+		c.logger.Warn("重新投递待确认消息",
+			zap.String("stream_id", msg.ID),
+			zap.Int64("deliveries", delivered))
+		c.process(ctx, msg, delivered)
+	}
+}
+
+// process 处理单条消息并根据结果确认、保留待重试或转入死信
+func (c *RedisStreamConsumer) process(ctx context.Context, msg redis.XMessage, delivered int64) {
+	body, ok := streamString(msg.Values, streamFieldBody)
+	if !ok {
+		c.deadLetter(ctx, msg, delivered, &NonRetryableError{Err: fmt.Errorf("missing message body")})
+		return
+	}
+
+	if expireAt, ok := streamString(msg.Values, streamFieldExpireAt); ok {
+		if ms, err := strconv.ParseInt(expireAt, 10, 64); err == nil && time.Now().UnixMilli() > ms {
+			c.logger.Info("消息已过期，直接确认", zap.String("stream_id", msg.ID))
+			c.ack(ctx, msg.ID)
+			return
+		}
+	}
+
+	messageID, _ := streamString(msg.Values, streamFieldMessageID)
+	messageType, _ := streamString(msg.Values, streamFieldMessageType)
+	traceID, _ := streamString(msg.Values, streamFieldTraceID)
+
+	// 复用 RabbitMQ 消费者的处理函数，只填充处理函数会读取的字段
+	delivery := amqp.Delivery{
+		Body:        []byte(body),
+		MessageId:   messageID,
+		Type:        messageType,
+		Redelivered: delivered > 1,
+		Headers:     amqp.Table{tracing.AMQPHeaderTraceID: traceID},
+	}
+
+	handleCtx, cancel := context.WithTimeout(ctx, c.config.HandleTimeout)
+	err := c.handler(handleCtx, delivery)
+	cancel()
+
+	if err == nil {
+		c.ack(ctx, msg.ID)
+		atomic.AddInt64(&c.processedCount, 1)
+		return
+	}
+
+	atomic.AddInt64(&c.failedCount, 1)
+	c.logger.Error("消息处理失败",
+		zap.Error(err),
+		zap.String("stream_id", msg.ID),
+		zap.String("message_id", messageID),
+		zap.Int64("deliveries", delivered),
+		zap.Int64("max_deliveries", c.config.MaxDeliveries))
+
+	if IsNonRetryableError(err) || delivered >= c.config.MaxDeliveries {
+		c.deadLetter(ctx, msg, delivered, err)
+	}
+	// 可重试错误不确认，空闲超过 ClaimMinIdle 后被重新认领
+}
+
+// deadLetter 将消息写入死信 Stream 后确认原消息；写入失败时保留待确认以便下次重试
+func (c *RedisStreamConsumer) deadLetter(ctx context.Context, msg redis.XMessage, delivered int64, cause error) {
+	values := make(map[string]interface{}, len(msg.Values)+4)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	values[streamFieldSourceStream] = c.stream
+	values[streamFieldSourceID] = msg.ID
+	values[streamFieldError] = cause.Error()
+	values[streamFieldDeliveries] = delivered
+
+	if err := c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: c.config.streamKey(c.config.DeadLetterStream),
+		MaxLen: c.config.MaxLen,
+		Approx: true,
+		Values: values,
+	}).Err(); err != nil {
+		c.logger.Error("写入死信 Stream 失败", zap.String("stream_id", msg.ID), zap.Error(err))
+		return
+	}
+
+	c.ack(ctx, msg.ID)
+	atomic.AddInt64(&c.deadLetteredCount, 1)
+	c.logger.Warn("消息转入死信 Stream",
+		zap.String("stream_id", msg.ID),
+		zap.String("dead_letter_stream", c.config.streamKey(c.config.DeadLetterStream)),
+		zap.Int64("deliveries", delivered),
+		zap.Error(cause))
+}
+
+// ack 确认消息
+func (c *RedisStreamConsumer) ack(ctx context.Context, id string) {
+	if err := c.client.XAck(ctx, c.stream, c.config.Group, id).Err(); err != nil {
+		c.logger.Error("消息确认失败", zap.String("stream_id", id), zap.Error(err))
+	}
+}
+
+// streamString 读取 Stream 字段，go-redis 将字段值统一解析为字符串
+func streamString(values map[string]interface{}, key string) (string, bool) {
+	v, ok := values[key]
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// GetStats 获取消费者统计信息
+func (c *RedisStreamConsumer) GetStats() RedisStreamConsumerStats {
+	c.mu.Lock()
+	running := c.running
+	c.mu.Unlock()
+
+	return RedisStreamConsumerStats{
+		Stream:            c.stream,
+		Group:             c.config.Group,
+		Consumer:          c.consumer,
+		IsRunning:         running,
+		ProcessedCount:    atomic.LoadInt64(&c.processedCount),
+		FailedCount:       atomic.LoadInt64(&c.failedCount),
+		DeadLetteredCount: atomic.LoadInt64(&c.deadLetteredCount),
+	}
+}
+
+// RedisStreamConsumerStats Redis Streams 消费者统计信息
+type RedisStreamConsumerStats struct {
+	Stream            string `json:"stream"`
+	Group             string `json:"group"`
+	Consumer          string `json:"consumer"`
+	IsRunning         bool   `json:"is_running"`
+	ProcessedCount    int64  `json:"processed_count"`
+	FailedCount       int64  `json:"failed_count"`
+	DeadLetteredCount int64  `json:"dead_lettered_count"`
+}
@@ -0,0 +1,190 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisStream 基于 miniredis 创建生产者与通知 Stream 的消费者；
+// 认领不要求空闲时长，测试中直接调用 readNew 与 claimPending 驱动消费，不启动后台循环
+func newTestRedisStream(t *testing.T, handler MessageHandler) (*redis.Client, *RedisStreamProducer, *RedisStreamConsumer) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cfg := &RedisStreamConfig{
+		MaxLen:           1000,
+		Group:            "test",
+		Consumer:         "consumer-1",
+		BatchSize:        10,
+		BlockTimeout:     10 * time.Millisecond,
+		ClaimInterval:    time.Second,
+		MaxDeliveries:    2,
+		DeadLetterStream: "dead",
+		HandleTimeout:    time.Second,
+		KeyPrefix:        "test",
+	}
+	consumer := NewRedisStreamConsumer(client, SpikeNotificationQueue, cfg, handler, nil)
+	if err := consumer.ensureGroup(context.Background()); err != nil {
+		t.Fatalf("ensureGroup() error = %v", err)
+	}
+	return client, NewRedisStreamProducer(client, cfg, nil), consumer
+}
+
+// pendingCount 返回消费组内待确认的消息数
+func pendingCount(t *testing.T, client *redis.Client, c *RedisStreamConsumer) int64 {
+	t.Helper()
+	pending, err := client.XPending(context.Background(), c.stream, c.config.Group).Result()
+	if err != nil {
+		t.Fatalf("XPending() error = %v", err)
+	}
+	return pending.Count
+}
+
+func TestRedisStreamConsumer_AckOnSuccess(t *testing.T) {
+	ctx := context.Background()
+	var got []amqp.Delivery
+	client, producer, consumer := newTestRedisStream(t, func(ctx context.Context, delivery amqp.Delivery) error {
+		got = append(got, delivery)
+		return nil
+	})
+
+	if err := producer.PublishNotification(ctx, &NotificationData{UserID: 1, Type: "test"}, "trace-1"); err != nil {
+		t.Fatalf("PublishNotification() error = %v", err)
+	}
+	if err := consumer.readNew(ctx); err != nil {
+		t.Fatalf("readNew() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Type != string(MessageTypeNotification) || got[0].Redelivered {
+		t.Fatalf("handled deliveries = %+v, want one first delivery of a notification", got)
+	}
+	if got[0].MessageId == "" {
+		t.Error("delivery missing message id")
+	}
+	if n := pendingCount(t, client, consumer); n != 0 {
+		t.Errorf("pending = %d, want 0 after ack", n)
+	}
+	if stats := consumer.GetStats(); stats.ProcessedCount != 1 || stats.FailedCount != 0 {
+		t.Errorf("stats = %+v, want 1 processed", stats)
+	}
+}
+
+func TestRedisStreamConsumer_RedeliverByClaim(t *testing.T) {
+	ctx := context.Background()
+	var redelivered []bool
+	client, producer, consumer := newTestRedisStream(t, func(ctx context.Context, delivery amqp.Delivery) error {
+		redelivered = append(redelivered, delivery.Redelivered)
+		if len(redelivered) == 1 {
+			return errors.New("deadlock")
+		}
+		return nil
+	})
+
+	if err := producer.PublishNotification(ctx, &NotificationData{UserID: 1}, ""); err != nil {
+		t.Fatalf("PublishNotification() error = %v", err)
+	}
+	// 可重试错误不确认，消息留在待确认列表
+	if err := consumer.readNew(ctx); err != nil {
+		t.Fatalf("readNew() error = %v", err)
+	}
+	if n := pendingCount(t, client, consumer); n != 1 {
+		t.Fatalf("pending = %d, want 1 after retryable failure", n)
+	}
+
+	consumer.claimPending(ctx)
+	if len(redelivered) != 2 || redelivered[0] || !redelivered[1] {
+		t.Fatalf("redelivered flags = %v, want [false true]", redelivered)
+	}
+	if n := pendingCount(t, client, consumer); n != 0 {
+		t.Errorf("pending = %d, want 0 after successful redelivery", n)
+	}
+	if stats := consumer.GetStats(); stats.ProcessedCount != 1 || stats.FailedCount != 1 || stats.DeadLetteredCount != 0 {
+		t.Errorf("stats = %+v, want 1 processed and 1 failed", stats)
+	}
+}
+
+func TestRedisStreamConsumer_DeadLetterAfterMaxDeliveries(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	client, producer, consumer := newTestRedisStream(t, func(ctx context.Context, delivery amqp.Delivery) error {
+		calls++
+		return errors.New("downstream unavailable")
+	})
+
+	if err := producer.PublishNotification(ctx, &NotificationData{UserID: 1}, ""); err != nil {
+		t.Fatalf("PublishNotification() error = %v", err)
+	}
+	if err := consumer.readNew(ctx); err != nil {
+		t.Fatalf("readNew() error = %v", err)
+	}
+	// 第二次投递（MaxDeliveries=2）仍失败，转入死信并确认
+	consumer.claimPending(ctx)
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+	if n := pendingCount(t, client, consumer); n != 0 {
+		t.Errorf("pending = %d, want 0 after dead-lettering", n)
+	}
+
+	dead, err := client.XRange(ctx, consumer.config.streamKey("dead"), "-", "+").Result()
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("dead letter stream = %v, %v, want 1 message", dead, err)
+	}
+	values := dead[0].Values
+	if values[streamFieldSourceStream] != consumer.stream || values[streamFieldDeliveries] != "2" ||
+		values[streamFieldError] != "downstream unavailable" {
+		t.Errorf("dead letter values = %v", values)
+	}
+	if _, ok := values[streamFieldBody]; !ok {
+		t.Error("dead letter missing original body")
+	}
+	if stats := consumer.GetStats(); stats.DeadLetteredCount != 1 {
+		t.Errorf("stats = %+v, want 1 dead-lettered", stats)
+	}
+
+	// 不可重试错误首次投递即转入死信
+	consumer.handler = func(ctx context.Context, delivery amqp.Delivery) error {
+		return &NonRetryableError{Err: errors.New("malformed")}
+	}
+	if err := producer.PublishNotification(ctx, &NotificationData{UserID: 2}, ""); err != nil {
+		t.Fatalf("PublishNotification() error = %v", err)
+	}
+	if err := consumer.readNew(ctx); err != nil {
+		t.Fatalf("readNew() error = %v", err)
+	}
+	if n, _ := client.XLen(ctx, consumer.config.streamKey("dead")).Result(); n != 2 {
+		t.Errorf("dead letter stream length = %d, want 2 after non-retryable error", n)
+	}
+}
+
+func TestRedisStreamConsumer_DropsExpiredMessages(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	client, producer, consumer := newTestRedisStream(t, func(ctx context.Context, delivery amqp.Delivery) error {
+		calls++
+		return nil
+	})
+
+	expired := time.Now().Add(-time.Minute)
+	if err := producer.PublishNotification(ctx, &NotificationData{UserID: 1, ExpireAt: &expired}, ""); err != nil {
+		t.Fatalf("PublishNotification() error = %v", err)
+	}
+	if err := consumer.readNew(ctx); err != nil {
+		t.Fatalf("readNew() error = %v", err)
+	}
+
+	if calls != 0 {
+		t.Errorf("handler calls = %d, want expired message dropped", calls)
+	}
+	if n := pendingCount(t, client, consumer); n != 0 {
+		t.Errorf("pending = %d, want expired message acked", n)
+	}
+}
@@ -0,0 +1,286 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/breaker"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// 发布降级动作，用作 spike_mq_publish_fallback_total 的 action 标签
+const (
+	fallbackBuffered = "buffered" // 发布失败，写入本地缓冲
+	fallbackReplayed = "replayed" // 缓冲消息补发成功
+	fallbackRejected = "rejected" // 缓冲已满，发布失败返回给调用方
+	fallbackDropped  = "dropped"  // 关闭时仍未补发的消息被丢弃
+)
+
+// ResilientPublisherConfig 发布容错配置
+type ResilientPublisherConfig struct {
+	MaxRetries     int            // 单次发布失败后的重试次数
+	InitialBackoff time.Duration  // 首次重试等待时间，之后按指数翻倍
+	MaxBackoff     time.Duration  // 重试等待时间上限
+	BufferSize     int            // 本地缓冲容量，0 表示不缓冲，重试耗尽直接返回错误
+	ReplayInterval time.Duration  // 缓冲消息补发间隔
+	Breaker        breaker.Config // 消息队列熔断器配置
+}
+
+// DefaultResilientPublisherConfig 默认配置
+func DefaultResilientPublisherConfig() ResilientPublisherConfig {
+	return ResilientPublisherConfig{
+		MaxRetries:     2,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		BufferSize:     1000,
+		ReplayInterval: time.Second,
+		Breaker:        breaker.DefaultConfig(),
+	}
+}
+
+// bufferedMessage 等待补发的消息
+type bufferedMessage struct {
+	msgType  MessageType
+	traceID  string
+	publish  func(ctx context.Context) error
+	bufferAt time.Time
+}
+
+// ResilientPublisher 为 SpikePublisher 增加发布容错：失败按指数退避重试，连续失败后熔断，
+// 重试耗尽或熔断期间消息写入本地有界缓冲并由后台按序补发，避免消息队列短暂抖动导致秒杀请求失败。
+// 缓冲只在内存中，进程崩溃会丢失；需要持久化保证的消息应走发件箱（OutboxRelay 使用未包装的发布者）
+type ResilientPublisher struct {
+	next    SpikePublisher
+	config  ResilientPublisherConfig
+	breaker *breaker.Breaker
+	logger  *zap.Logger
+
+	buffer  chan *bufferedMessage
+	backlog atomic.Int64 // 已缓冲但尚未补发的消息数，含补发失败暂存的 head
+
+	replayMu sync.Mutex       // 串行化补发，保证缓冲消息按写入顺序发布
+	head     *bufferedMessage // 补发失败的队首消息，下次补发时最先发送
+}
+
+// NewResilientPublisher 创建带容错的发布者，非法配置项取默认值
+func NewResilientPublisher(next SpikePublisher, config ResilientPublisherConfig, logger *zap.Logger) *ResilientPublisher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	def := DefaultResilientPublisherConfig()
+	if config.MaxRetries < 0 {
+		config.MaxRetries = def.MaxRetries
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = def.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	if config.BufferSize < 0 {
+		config.BufferSize = def.BufferSize
+	}
+	if config.ReplayInterval <= 0 {
+		config.ReplayInterval = def.ReplayInterval
+	}
+
+	b := breaker.New("mq_publish", config.Breaker)
+	b.OnStateChange(func(name string, from, to breaker.State) {
+		logger.Warn("消息队列熔断器状态变化",
+			zap.String("breaker", name),
+			zap.String("from", from.String()),
+			zap.String("to", to.String()))
+	})
+	return &ResilientPublisher{
+		next:    next,
+		config:  config,
+		breaker: b,
+		logger:  logger,
+		buffer:  make(chan *bufferedMessage, config.BufferSize),
+	}
+}
+
+// Start 启动后台补发循环，ctx 取消后退出；剩余消息由 Flush 处理
+func (p *ResilientPublisher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.config.ReplayInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.replay(ctx)
+			}
+		}
+	}()
+}
+
+// Flush 关闭前在 timeout 内尽量补发缓冲中的消息，超时仍未发出的消息记录日志后丢弃。
+// 应在底层发布者关闭之前调用
+func (p *ResilientPublisher) Flush(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	p.replay(ctx)
+
+	p.replayMu.Lock()
+	defer p.replayMu.Unlock()
+	for msg := p.popLocked(); msg != nil; msg = p.popLocked() {
+		metrics.MQPublishFallback.Inc(string(msg.msgType), fallbackDropped)
+		p.logger.Error("关闭时缓冲消息未能补发，已丢弃",
+			zap.String("message_type", string(msg.msgType)),
+			zap.String("trace_id", msg.traceID),
+			zap.Time("buffered_at", msg.bufferAt))
+	}
+}
+
+// Backlog 返回等待补发的消息数
+func (p *ResilientPublisher) Backlog() int64 {
+	return p.backlog.Load()
+}
+
+// PublishSpikeOrderCreated 发布秒杀订单创建消息
+func (p *ResilientPublisher) PublishSpikeOrderCreated(ctx context.Context, data *SpikeOrderCreatedData, traceID string) error {
+	return p.publish(ctx, MessageTypeSpikeOrderCreated, traceID, func(ctx context.Context) error {
+		return p.next.PublishSpikeOrderCreated(ctx, data, traceID)
+	})
+}
+
+// PublishSpikeOrderPaid 发布秒杀订单支付消息
+func (p *ResilientPublisher) PublishSpikeOrderPaid(ctx context.Context, data *SpikeOrderPaidData, traceID string) error {
+	return p.publish(ctx, MessageTypeSpikeOrderPaid, traceID, func(ctx context.Context) error {
+		return p.next.PublishSpikeOrderPaid(ctx, data, traceID)
+	})
+}
+
+// PublishSpikeOrderExpired 发布秒杀订单过期消息
+func (p *ResilientPublisher) PublishSpikeOrderExpired(ctx context.Context, data *SpikeOrderExpiredData, traceID string) error {
+	return p.publish(ctx, MessageTypeSpikeOrderExpired, traceID, func(ctx context.Context) error {
+		return p.next.PublishSpikeOrderExpired(ctx, data, traceID)
+	})
+}
+
+// PublishSpikeOrderCancelled 发布秒杀订单取消消息
+func (p *ResilientPublisher) PublishSpikeOrderCancelled(ctx context.Context, data *SpikeOrderCancelledData, traceID string) error {
+	return p.publish(ctx, MessageTypeSpikeOrderCancelled, traceID, func(ctx context.Context) error {
+		return p.next.PublishSpikeOrderCancelled(ctx, data, traceID)
+	})
+}
+
+// PublishStockRestore 发布库存恢复消息
+func (p *ResilientPublisher) PublishStockRestore(ctx context.Context, data *StockRestoreData, traceID string) error {
+	return p.publish(ctx, MessageTypeStockRestore, traceID, func(ctx context.Context) error {
+		return p.next.PublishStockRestore(ctx, data, traceID)
+	})
+}
+
+// PublishNotification 发布通知消息
+func (p *ResilientPublisher) PublishNotification(ctx context.Context, data *NotificationData, traceID string) error {
+	return p.publish(ctx, MessageTypeNotification, traceID, func(ctx context.Context) error {
+		return p.next.PublishNotification(ctx, data, traceID)
+	})
+}
+
+// PublishSpikeOrderAbandoned 发布弃单消息
+func (p *ResilientPublisher) PublishSpikeOrderAbandoned(ctx context.Context, data *SpikeOrderAbandonedData, traceID string) error {
+	return p.publish(ctx, MessageTypeSpikeOrderAbandoned, traceID, func(ctx context.Context) error {
+		return p.next.PublishSpikeOrderAbandoned(ctx, data, traceID)
+	})
+}
+
+// publish 发布一条消息：已有积压时直接排在积压之后，保持发布顺序；否则在熔断器保护下重试，
+// 失败后写入本地缓冲并视为发布成功。调用方取消、缓冲已满或未启用缓冲时返回错误，由调用方回滚
+func (p *ResilientPublisher) publish(ctx context.Context, msgType MessageType, traceID string, fn func(ctx context.Context) error) error {
+	var err error
+	if p.backlog.Load() == 0 {
+		if err = p.publishWithRetry(ctx, fn); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+
+	msg := &bufferedMessage{msgType: msgType, traceID: traceID, publish: fn, bufferAt: time.Now()}
+	p.backlog.Add(1)
+	select {
+	case p.buffer <- msg:
+		metrics.MQPublishFallback.Inc(string(msgType), fallbackBuffered)
+		p.logger.Warn("消息发布失败，已写入本地缓冲等待补发",
+			zap.String("message_type", string(msgType)),
+			zap.String("trace_id", traceID),
+			zap.Int64("backlog", p.backlog.Load()),
+			zap.Error(err))
+		return nil
+	default:
+		p.backlog.Add(-1)
+	}
+
+	metrics.MQPublishFallback.Inc(string(msgType), fallbackRejected)
+	if err == nil {
+		err = errors.New("publish buffer is full")
+	}
+	return fmt.Errorf("failed to publish %s message: %w", msgType, err)
+}
+
+// publishWithRetry 在熔断器保护下发布，失败按指数退避重试；熔断中或调用方取消时立即返回
+func (p *ResilientPublisher) publishWithRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoff := p.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := p.breaker.Do(func() error { return fn(ctx) })
+		if err == nil || errors.Is(err, breaker.ErrOpen) || attempt >= p.config.MaxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, p.config.MaxBackoff)
+	}
+}
+
+// replay 按写入顺序补发缓冲消息，遇到失败即停止，失败的消息留在队首等待下次补发
+func (p *ResilientPublisher) replay(ctx context.Context) {
+	p.replayMu.Lock()
+	defer p.replayMu.Unlock()
+	for ctx.Err() == nil {
+		msg := p.popLocked()
+		if msg == nil {
+			return
+		}
+		if err := p.breaker.Do(func() error { return msg.publish(ctx) }); err != nil {
+			p.head = msg
+			p.backlog.Add(1)
+			if !errors.Is(err, breaker.ErrOpen) {
+				p.logger.Warn("补发缓冲消息失败",
+					zap.String("message_type", string(msg.msgType)),
+					zap.String("trace_id", msg.traceID),
+					zap.Error(err))
+			}
+			return
+		}
+		metrics.MQPublishFallback.Inc(string(msg.msgType), fallbackReplayed)
+	}
+}
+
+// popLocked 取出下一条待补发消息，调用方持有 replayMu
+func (p *ResilientPublisher) popLocked() *bufferedMessage {
+	msg := p.head
+	p.head = nil
+	if msg == nil {
+		select {
+		case msg = <-p.buffer:
+		default:
+			return nil
+		}
+	}
+	p.backlog.Add(-1)
+	return msg
+}
@@ -0,0 +1,120 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/breaker"
+)
+
+// flakyPublisher 前 failures 次发布失败，之后记录成功发布的订单号
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published []string
+}
+
+func (f *flakyPublisher) PublishSpikeOrderCreated(ctx context.Context, data *SpikeOrderCreatedData, traceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("broker unavailable")
+	}
+	f.published = append(f.published, data.OrderNo)
+	return nil
+}
+
+func (f *flakyPublisher) PublishSpikeOrderPaid(context.Context, *SpikeOrderPaidData, string) error {
+	return nil
+}
+
+func (f *flakyPublisher) PublishSpikeOrderExpired(context.Context, *SpikeOrderExpiredData, string) error {
+	return nil
+}
+
+func (f *flakyPublisher) PublishSpikeOrderCancelled(context.Context, *SpikeOrderCancelledData, string) error {
+	return nil
+}
+
+func (f *flakyPublisher) PublishStockRestore(context.Context, *StockRestoreData, string) error {
+	return nil
+}
+
+func (f *flakyPublisher) PublishNotification(context.Context, *NotificationData, string) error {
+	return nil
+}
+
+func (f *flakyPublisher) PublishSpikeOrderAbandoned(context.Context, *SpikeOrderAbandonedData, string) error {
+	return nil
+}
+
+func newTestResilientPublisher(next SpikePublisher, bufferSize int) *ResilientPublisher {
+	return NewResilientPublisher(next, ResilientPublisherConfig{
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		BufferSize:     bufferSize,
+		ReplayInterval: time.Hour,
+		Breaker:        breaker.Config{FailureThreshold: 2, OpenTimeout: time.Hour},
+	}, nil)
+}
+
+func TestResilientPublisher_RetriesThenBuffersInOrder(t *testing.T) {
+	ctx := context.Background()
+	next := &flakyPublisher{failures: 1}
+	p := newTestResilientPublisher(next, 2)
+
+	// 首次失败后重试成功
+	if err := p.PublishSpikeOrderCreated(ctx, &SpikeOrderCreatedData{OrderNo: "A"}, ""); err != nil {
+		t.Fatalf("publish A error = %v", err)
+	}
+
+	// 重试耗尽后熔断，消息写入缓冲；缓冲满后返回错误
+	next.failures = 2
+	for _, orderNo := range []string{"B", "C"} {
+		if err := p.PublishSpikeOrderCreated(ctx, &SpikeOrderCreatedData{OrderNo: orderNo}, ""); err != nil {
+			t.Fatalf("publish %s error = %v, want buffered", orderNo, err)
+		}
+	}
+	if err := p.PublishSpikeOrderCreated(ctx, &SpikeOrderCreatedData{OrderNo: "D"}, ""); err == nil {
+		t.Fatal("publish D with full buffer succeeded, want error")
+	}
+	if got := p.Backlog(); got != 2 {
+		t.Fatalf("backlog = %d, want 2", got)
+	}
+
+	// 熔断恢复后按写入顺序补发
+	p.breaker = breaker.New("mq_publish", breaker.Config{FailureThreshold: 2})
+	p.Flush(time.Second)
+	if got := p.Backlog(); got != 0 {
+		t.Errorf("backlog after flush = %d, want 0", got)
+	}
+	want := []string{"A", "B", "C"}
+	if len(next.published) != len(want) {
+		t.Fatalf("published = %v, want %v", next.published, want)
+	}
+	for i := range want {
+		if next.published[i] != want[i] {
+			t.Fatalf("published = %v, want %v", next.published, want)
+		}
+	}
+}
+
+func TestResilientPublisher_NoBufferReturnsError(t *testing.T) {
+	next := &flakyPublisher{failures: 5}
+	p := newTestResilientPublisher(next, 0)
+	if err := p.PublishSpikeOrderCreated(context.Background(), &SpikeOrderCreatedData{OrderNo: "A"}, ""); err == nil {
+		t.Fatal("publish without buffer succeeded, want error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p = newTestResilientPublisher(next, 10)
+	if err := p.PublishSpikeOrderCreated(ctx, &SpikeOrderCreatedData{OrderNo: "B"}, ""); err == nil || p.Backlog() != 0 {
+		t.Errorf("cancelled publish error = %v, backlog = %d, want error without buffering", err, p.Backlog())
+	}
+}
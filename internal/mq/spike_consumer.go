@@ -3,64 +3,96 @@ package mq
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
-	"github.com/MorseWayne/spike_shop/internal/cache"
-	"github.com/MorseWayne/spike_shop/internal/domain"
-	"github.com/MorseWayne/spike_shop/internal/repo"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
 )
 
-// SpikeConsumer 秒杀消息消费者
+// SpikeMessageUseCases 秒杀消息对应的业务用例（由 service.SpikeMessageService 实现）。
+// 返回 *NonRetryableError 时消息不再重试
+type SpikeMessageUseCases interface {
+	CreateSpikeOrderFromMessage(ctx context.Context, messageID, traceID string, data *SpikeOrderCreatedData) error
+	// CreateSpikeOrdersFromMessages 批量处理下单消息，返回与 messages 一一对应的处理结果
+	CreateSpikeOrdersFromMessages(ctx context.Context, messages []*SpikeOrderCreatedMessage) []error
+	MarkSpikeOrderPaidFromMessage(ctx context.Context, traceID string, data *SpikeOrderPaidData) error
+	ExpireSpikeOrderFromMessage(ctx context.Context, messageID, traceID string, data *SpikeOrderExpiredData) error
+	RestoreStockFromMessage(ctx context.Context, messageID string, data *StockRestoreData) error
+}
+
+// SpikeOrderCreatedMessage 批量处理时的一条下单消息
+type SpikeOrderCreatedMessage struct {
+	MessageID string
+	TraceID   string
+	Data      *SpikeOrderCreatedData
+}
+
+// SpikeConsumer 秒杀消息消费者：负责队列订阅、消息解析与按类型分发，业务处理交给 SpikeMessageUseCases
 type SpikeConsumer struct {
-	cm     *ConnectionManager
-	logger *zap.Logger
+	cm       *ConnectionManager
+	useCases SpikeMessageUseCases
+	logger   *zap.Logger
 
-	// 仓储层
-	spikeEventRepo repo.SpikeEventRepository
-	spikeOrderRepo repo.SpikeOrderRepository
-	inventoryRepo  repo.InventoryRepository
+	// 订单消息批量落库，orderBatchSize 不大于 1 时逐条处理
+	orderBatchSize int
+	orderBatchWait time.Duration
 
-	// 缓存层
-	spikeCache *cache.SpikeCache
+	// 通知发送，可为空，为空时只记录日志
+	notifications NotificationSender
 
 	// 消费者实例
-	consumers map[string]*Consumer
-
-	// 数据库连接
-	db *sql.DB
+	consumers       map[string]*Consumer
+	streamConsumers map[string]*RedisStreamConsumer
 }
 
 // NewSpikeConsumer 创建秒杀消息消费者
-func NewSpikeConsumer(
-	cm *ConnectionManager,
-	db *sql.DB,
-	spikeEventRepo repo.SpikeEventRepository,
-	spikeOrderRepo repo.SpikeOrderRepository,
-	inventoryRepo repo.InventoryRepository,
-	spikeCache *cache.SpikeCache,
-	logger *zap.Logger,
-) *SpikeConsumer {
+func NewSpikeConsumer(cm *ConnectionManager, useCases SpikeMessageUseCases, logger *zap.Logger) *SpikeConsumer {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
 
 	return &SpikeConsumer{
-		cm:             cm,
-		db:             db,
-		spikeEventRepo: spikeEventRepo,
-		spikeOrderRepo: spikeOrderRepo,
-		inventoryRepo:  inventoryRepo,
-		spikeCache:     spikeCache,
-		logger:         logger,
-		consumers:      make(map[string]*Consumer),
+		cm:              cm,
+		useCases:        useCases,
+		logger:          logger,
+		consumers:       make(map[string]*Consumer),
+		streamConsumers: make(map[string]*RedisStreamConsumer),
 	}
 }
 
+// SetOrderBatch 设置订单消息批量落库：每个工作器累积最多 size 条消息或等待 wait 后，
+// 将其中的下单消息合并到一个事务中落库。size 不大于 1 时逐条处理；只对 RabbitMQ 消费者生效
+func (sc *SpikeConsumer) SetOrderBatch(size int, wait time.Duration) {
+	sc.orderBatchSize = size
+	sc.orderBatchWait = wait
+}
+
+// NotificationSender 通过邮件、短信、推送等渠道发送通知（由 service.NotificationService 实现）
+type NotificationSender interface {
+	SendNotification(ctx context.Context, messageID, traceID string, data *NotificationData) error
+}
+
+// SetNotificationSender 设置通知发送，通知消息按 channels 路由到各渠道；未设置时只记录日志
+func (sc *SpikeConsumer) SetNotificationSender(sender NotificationSender) {
+	sc.notifications = sender
+}
+
+// NotificationPublisher 发布用户通知（由 SpikeProducer 实现）
+type NotificationPublisher interface {
+	PublishNotification(ctx context.Context, data *NotificationData, traceID string) error
+}
+
+// AbandonedCheckoutPublisher 发布弃单事件（由 SpikeProducer 与 RedisStreamProducer 实现）
+type AbandonedCheckoutPublisher interface {
+	PublishSpikeOrderAbandoned(ctx context.Context, data *SpikeOrderAbandonedData, traceID string) error
+}
+
 // StartConsumers 启动所有消费者
 func (sc *SpikeConsumer) StartConsumers(ctx context.Context) error {
 	// 启动秒杀订单消费者
@@ -82,6 +114,39 @@ func (sc *SpikeConsumer) StartConsumers(ctx context.Context) error {
 	return nil
 }
 
+// StartStreamConsumers 基于 Redis Streams 启动所有消费者（MQ_TYPE=redis 时替代 StartConsumers）
+func (sc *SpikeConsumer) StartStreamConsumers(ctx context.Context, client redis.Cmdable, config *RedisStreamConfig) error {
+	specs := []struct {
+		name    string
+		stream  string
+		handler MessageHandler
+	}{
+		{"order", SpikeOrderStream, sc.handleOrderMessage},
+		{"stock", SpikeStockRestoreQueue, sc.handleStockRestoreMessage},
+		{"notification", SpikeNotificationQueue, sc.handleNotificationMessage},
+	}
+
+	for _, spec := range specs {
+		consumer := NewRedisStreamConsumer(client, spec.stream, config, observeConsumed(spec.name, spec.handler), sc.logger)
+		if err := consumer.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start %s stream consumer: %w", spec.name, err)
+		}
+		sc.streamConsumers[spec.name] = consumer
+	}
+
+	sc.logger.Info("所有秒杀 Stream 消费者启动成功")
+	return nil
+}
+
+// observeConsumed 包装消息处理函数，按消费者名称记录处理结果
+func observeConsumed(queue string, handler MessageHandler) MessageHandler {
+	return func(ctx context.Context, delivery amqp.Delivery) error {
+		err := handler(ctx, delivery)
+		metrics.MQConsumed.Inc(queue, metrics.Result(err))
+		return err
+	}
+}
+
 // startOrderConsumer 启动订单消费者
 func (sc *SpikeConsumer) startOrderConsumer(ctx context.Context) error {
 	config := &ConsumerConfig{
@@ -95,16 +160,42 @@ func (sc *SpikeConsumer) startOrderConsumer(ctx context.Context) error {
 		DLXRoutingKey:       "failed.order",
 		ConsumeTimeout:      30 * time.Second,
 		ConcurrentConsumers: 2,
+		BatchSize:           sc.orderBatchSize,
+		BatchWait:           sc.orderBatchWait,
 	}
 
 	consumer := NewConsumer(sc.cm, config, sc.logger)
-	consumer.SetHandler(sc.handleOrderMessage)
+	consumer.SetHandler(observeConsumed("order", sc.handleOrderMessage))
+	consumer.SetBatchHandler(sc.handleOrderBatch)
 
 	if err := consumer.StartConsuming(ctx, SpikeOrderQueue); err != nil {
 		return err
 	}
-
 	sc.consumers["order"] = consumer
+
+	return sc.startLegacyOrderConsumer(ctx, config)
+}
+
+// startLegacyOrderConsumer 旧订单队列存在时继续消费其中的存量消息，队列已解除绑定，不会再有新消息进入
+func (sc *SpikeConsumer) startLegacyOrderConsumer(ctx context.Context, config *ConsumerConfig) error {
+	exists, err := queueExists(sc.cm, LegacySpikeOrderQueue)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	legacyConfig := *config
+	legacyConfig.ConcurrentConsumers = 1
+	consumer := NewConsumer(sc.cm, &legacyConfig, sc.logger)
+	consumer.SetHandler(observeConsumed("order", sc.handleOrderMessage))
+	consumer.SetBatchHandler(sc.handleOrderBatch)
+
+	if err := consumer.StartConsuming(ctx, LegacySpikeOrderQueue); err != nil {
+		return err
+	}
+	sc.consumers["order_legacy"] = consumer
 	return nil
 }
 
@@ -124,7 +215,7 @@ func (sc *SpikeConsumer) startStockRestoreConsumer(ctx context.Context) error {
 	}
 
 	consumer := NewConsumer(sc.cm, config, sc.logger)
-	consumer.SetHandler(sc.handleStockRestoreMessage)
+	consumer.SetHandler(observeConsumed("stock", sc.handleStockRestoreMessage))
 
 	if err := consumer.StartConsuming(ctx, SpikeStockRestoreQueue); err != nil {
 		return err
@@ -150,7 +241,7 @@ func (sc *SpikeConsumer) startNotificationConsumer(ctx context.Context) error {
 	}
 
 	consumer := NewConsumer(sc.cm, config, sc.logger)
-	consumer.SetHandler(sc.handleNotificationMessage)
+	consumer.SetHandler(observeConsumed("notification", sc.handleNotificationMessage))
 
 	if err := consumer.StartConsuming(ctx, SpikeNotificationQueue); err != nil {
 		return err
@@ -169,6 +260,8 @@ func (sc *SpikeConsumer) handleOrderMessage(ctx context.Context, delivery amqp.D
 		return &NonRetryableError{Err: fmt.Errorf("invalid message format: %w", err)}
 	}
 
+	ctx = withMessageTrace(ctx, &message, delivery)
+
 	sc.logger.Info("处理订单消息",
 		zap.String("message_id", message.ID),
 		zap.String("message_type", string(message.Type)),
@@ -186,103 +279,55 @@ func (sc *SpikeConsumer) handleOrderMessage(ctx context.Context, delivery amqp.D
 	}
 }
 
+// handleOrderBatch 批量处理订单消息：下单消息合并落库，其他消息逐条处理
+func (sc *SpikeConsumer) handleOrderBatch(ctx context.Context, deliveries []amqp.Delivery) []error {
+	errs := make([]error, len(deliveries))
+	var (
+		created []*SpikeOrderCreatedMessage
+		indexes []int
+	)
+	for i, delivery := range deliveries {
+		var message SpikeMessage
+		if err := message.FromJSON(delivery.Body); err != nil || message.Type != MessageTypeSpikeOrderCreated {
+			errs[i] = sc.handleOrderMessage(ctx, delivery)
+			metrics.MQConsumed.Inc("order", metrics.Result(errs[i]))
+			continue
+		}
+		// 只补全追踪ID，批内各消息的追踪ID随消息交给用例
+		withMessageTrace(ctx, &message, delivery)
+
+		var data SpikeOrderCreatedData
+		if err := message.GetDataAs(&data); err != nil {
+			errs[i] = &NonRetryableError{Err: fmt.Errorf("failed to parse spike order created data: %w", err)}
+			metrics.MQConsumed.Inc("order", metrics.Result(errs[i]))
+			continue
+		}
+		created = append(created, &SpikeOrderCreatedMessage{MessageID: message.ID, TraceID: message.TraceID, Data: &data})
+		indexes = append(indexes, i)
+	}
+
+	if len(created) > 0 {
+		sc.logger.Info("批量处理下单消息", zap.Int("count", len(created)))
+		results := sc.useCases.CreateSpikeOrdersFromMessages(ctx, created)
+		for j, i := range indexes {
+			if j < len(results) {
+				errs[i] = results[j]
+			} else {
+				errs[i] = fmt.Errorf("missing batch result for message %s", created[j].MessageID)
+			}
+			metrics.MQConsumed.Inc("order", metrics.Result(errs[i]))
+		}
+	}
+	return errs
+}
+
 // handleSpikeOrderCreated 处理秒杀订单创建消息
 func (sc *SpikeConsumer) handleSpikeOrderCreated(ctx context.Context, message *SpikeMessage) error {
 	var data SpikeOrderCreatedData
 	if err := message.GetDataAs(&data); err != nil {
 		return &NonRetryableError{Err: fmt.Errorf("failed to parse spike order created data: %w", err)}
 	}
-
-	// 幂等性检查
-	if err := sc.checkIdempotency(ctx, data.IdempotencyKey, message.ID); err != nil {
-		if err == ErrDuplicateMessage {
-			sc.logger.Info("重复消息，跳过处理",
-				zap.String("idempotency_key", data.IdempotencyKey),
-				zap.String("message_id", message.ID))
-			return nil // 重复消息，直接返回成功
-		}
-		return err
-	}
-
-	// 开始数据库事务
-	tx, err := sc.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// 验证秒杀活动是否有效
-	spikeEvent, err := sc.spikeEventRepo.GetByID(data.SpikeEventID)
-	if err != nil {
-		return fmt.Errorf("failed to get spike event: %w", err)
-	}
-
-	if !spikeEvent.IsActive() {
-		return &NonRetryableError{Err: fmt.Errorf("spike event %d is not active", data.SpikeEventID)}
-	}
-
-	// 检查是否有足够库存
-	if spikeEvent.SoldCount+data.Quantity > spikeEvent.SpikeStock {
-		sc.logger.Warn("库存不足，恢复Redis库存",
-			zap.Int64("spike_event_id", data.SpikeEventID),
-			zap.Int64("sold_count", spikeEvent.SoldCount),
-			zap.Int64("spike_stock", spikeEvent.SpikeStock),
-			zap.Int64("requested_quantity", data.Quantity))
-
-		// 恢复Redis库存
-		_, err := sc.spikeCache.RestoreStock(ctx, data.SpikeEventID, data.UserID, data.Quantity)
-		if err != nil {
-			sc.logger.Error("恢复Redis库存失败", zap.Error(err))
-		}
-
-		return &NonRetryableError{Err: fmt.Errorf("insufficient stock")}
-	}
-
-	// 更新秒杀活动已售数量
-	spikeEvent.SoldCount += data.Quantity
-	if err := sc.spikeEventRepo.UpdateSoldCount(spikeEvent.ID, spikeEvent.SoldCount); err != nil {
-		return fmt.Errorf("failed to update sold count: %w", err)
-	}
-
-	// 创建秒杀订单记录
-	spikeOrder := &domain.SpikeOrder{
-		SpikeEventID:   data.SpikeEventID,
-		UserID:         data.UserID,
-		Quantity:       data.Quantity,
-		SpikePrice:     data.SpikePrice,
-		TotalAmount:    data.TotalAmount,
-		Status:         domain.SpikeOrderStatusPending,
-		IdempotencyKey: data.IdempotencyKey,
-		ExpireAt:       &data.ExpireAt,
-		CreatedAt:      data.CreatedAt,
-	}
-
-	if err := sc.spikeOrderRepo.Create(spikeOrder); err != nil {
-		return fmt.Errorf("failed to create spike order: %w", err)
-	}
-
-	// 消费库存
-	if err := sc.inventoryRepo.ConsumeStock(data.ProductID, int(data.Quantity)); err != nil {
-		return fmt.Errorf("failed to consume inventory: %w", err)
-	}
-
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// 标记幂等键处理完成
-	if err := sc.markIdempotencyProcessed(ctx, data.IdempotencyKey, message.ID); err != nil {
-		sc.logger.Error("标记幂等键处理完成失败", zap.Error(err))
-	}
-
-	sc.logger.Info("秒杀订单创建成功",
-		zap.Int64("spike_order_id", spikeOrder.ID),
-		zap.Int64("spike_event_id", data.SpikeEventID),
-		zap.Int64("user_id", data.UserID),
-		zap.String("idempotency_key", data.IdempotencyKey))
-
-	return nil
+	return sc.useCases.CreateSpikeOrderFromMessage(ctx, message.ID, message.TraceID, &data)
 }
 
 // handleSpikeOrderPaid 处理秒杀订单支付消息
@@ -291,38 +336,7 @@ func (sc *SpikeConsumer) handleSpikeOrderPaid(ctx context.Context, message *Spik
 	if err := message.GetDataAs(&data); err != nil {
 		return &NonRetryableError{Err: fmt.Errorf("failed to parse spike order paid data: %w", err)}
 	}
-
-	// 开始数据库事务
-	tx, err := sc.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// 更新秒杀订单状态
-	if err := sc.spikeOrderRepo.UpdatePaymentInfo(data.SpikeOrderID, data.PaidAt); err != nil {
-		return fmt.Errorf("failed to update spike order payment info: %w", err)
-	}
-
-	// 关联普通订单ID
-	if data.OrderID > 0 {
-		if err := sc.spikeOrderRepo.UpdateOrderID(data.SpikeOrderID, data.OrderID); err != nil {
-			return fmt.Errorf("failed to update order id: %w", err)
-		}
-	}
-
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	sc.logger.Info("秒杀订单支付处理成功",
-		zap.Int64("spike_order_id", data.SpikeOrderID),
-		zap.Int64("order_id", data.OrderID),
-		zap.Int64("user_id", data.UserID),
-		zap.String("payment_method", data.PaymentMethod))
-
-	return nil
+	return sc.useCases.MarkSpikeOrderPaidFromMessage(ctx, message.TraceID, &data)
 }
 
 // handleStockRestoreMessage 处理库存恢复消息
@@ -334,6 +348,8 @@ func (sc *SpikeConsumer) handleStockRestoreMessage(ctx context.Context, delivery
 		return &NonRetryableError{Err: fmt.Errorf("invalid message format: %w", err)}
 	}
 
+	ctx = withMessageTrace(ctx, &message, delivery)
+
 	sc.logger.Info("处理库存恢复消息",
 		zap.String("message_id", message.ID),
 		zap.String("message_type", string(message.Type)),
@@ -359,17 +375,7 @@ func (sc *SpikeConsumer) handleSpikeOrderExpired(ctx context.Context, message *S
 	if err := message.GetDataAs(&data); err != nil {
 		return &NonRetryableError{Err: fmt.Errorf("failed to parse spike order expired data: %w", err)}
 	}
-
-	// 幂等性检查
-	if err := sc.checkIdempotency(ctx, data.IdempotencyKey, message.ID); err != nil {
-		if err == ErrDuplicateMessage {
-			return nil
-		}
-		return err
-	}
-
-	return sc.processStockRestore(ctx, data.SpikeEventID, data.UserID, data.ProductID,
-		data.Quantity, "order_expired", data.SpikeOrderID, data.IdempotencyKey, message.ID)
+	return sc.useCases.ExpireSpikeOrderFromMessage(ctx, message.ID, message.TraceID, &data)
 }
 
 // handleSpikeOrderCancelled 处理秒杀订单取消
@@ -378,17 +384,16 @@ func (sc *SpikeConsumer) handleSpikeOrderCancelled(ctx context.Context, message
 	if err := message.GetDataAs(&data); err != nil {
 		return &NonRetryableError{Err: fmt.Errorf("failed to parse spike order cancelled data: %w", err)}
 	}
-
-	// 幂等性检查
-	if err := sc.checkIdempotency(ctx, data.IdempotencyKey, message.ID); err != nil {
-		if err == ErrDuplicateMessage {
-			return nil
-		}
-		return err
-	}
-
-	return sc.processStockRestore(ctx, data.SpikeEventID, data.UserID, data.ProductID,
-		data.Quantity, data.Reason, data.SpikeOrderID, data.IdempotencyKey, message.ID)
+	return sc.useCases.RestoreStockFromMessage(ctx, message.ID, &StockRestoreData{
+		SpikeEventID:   data.SpikeEventID,
+		ProductID:      data.ProductID,
+		UserID:         data.UserID,
+		Quantity:       data.Quantity,
+		Reason:         data.Reason,
+		SourceOrderID:  data.SpikeOrderID,
+		IdempotencyKey: data.IdempotencyKey,
+		RestoreAt:      data.CancelledAt,
+	})
 }
 
 // handleStockRestore 处理库存恢复
@@ -397,78 +402,7 @@ func (sc *SpikeConsumer) handleStockRestore(ctx context.Context, message *SpikeM
 	if err := message.GetDataAs(&data); err != nil {
 		return &NonRetryableError{Err: fmt.Errorf("failed to parse stock restore data: %w", err)}
 	}
-
-	// 幂等性检查
-	if err := sc.checkIdempotency(ctx, data.IdempotencyKey, message.ID); err != nil {
-		if err == ErrDuplicateMessage {
-			return nil
-		}
-		return err
-	}
-
-	return sc.processStockRestore(ctx, data.SpikeEventID, data.UserID, data.ProductID,
-		data.Quantity, data.Reason, data.SourceOrderID, data.IdempotencyKey, message.ID)
-}
-
-// processStockRestore 处理库存恢复的通用方法
-func (sc *SpikeConsumer) processStockRestore(ctx context.Context, spikeEventID, userID, productID, quantity int64,
-	reason string, sourceOrderID int64, idempotencyKey, messageID string) error {
-
-	// 开始数据库事务
-	tx, err := sc.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// 恢复秒杀活动库存
-	spikeEvent, err := sc.spikeEventRepo.GetByID(spikeEventID)
-	if err != nil {
-		return fmt.Errorf("failed to get spike event: %w", err)
-	}
-
-	if spikeEvent.SoldCount >= quantity {
-		spikeEvent.SoldCount -= quantity
-		if err := sc.spikeEventRepo.UpdateSoldCount(spikeEvent.ID, spikeEvent.SoldCount); err != nil {
-			return fmt.Errorf("failed to update sold count: %w", err)
-		}
-	}
-
-	// 恢复商品库存
-	if err := sc.inventoryRepo.AdjustStock(productID, int(quantity), reason); err != nil {
-		return fmt.Errorf("failed to restore inventory: %w", err)
-	}
-
-	// 恢复Redis库存
-	restoredStock, err := sc.spikeCache.RestoreStock(ctx, spikeEventID, userID, quantity)
-	if err != nil {
-		sc.logger.Error("恢复Redis库存失败", zap.Error(err))
-		// Redis操作失败不影响数据库事务，只记录错误
-	} else {
-		sc.logger.Info("恢复Redis库存成功",
-			zap.Int64("spike_event_id", spikeEventID),
-			zap.Int64("restored_stock", restoredStock))
-	}
-
-	// 提交事务
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// 标记幂等键处理完成
-	if err := sc.markIdempotencyProcessed(ctx, idempotencyKey, messageID); err != nil {
-		sc.logger.Error("标记幂等键处理完成失败", zap.Error(err))
-	}
-
-	sc.logger.Info("库存恢复处理成功",
-		zap.Int64("spike_event_id", spikeEventID),
-		zap.Int64("product_id", productID),
-		zap.Int64("user_id", userID),
-		zap.Int64("quantity", quantity),
-		zap.String("reason", reason),
-		zap.Int64("source_order_id", sourceOrderID))
-
-	return nil
+	return sc.useCases.RestoreStockFromMessage(ctx, message.ID, &data)
 }
 
 // handleNotificationMessage 处理通知消息
@@ -480,6 +414,8 @@ func (sc *SpikeConsumer) handleNotificationMessage(ctx context.Context, delivery
 		return &NonRetryableError{Err: fmt.Errorf("invalid message format: %w", err)}
 	}
 
+	ctx = withMessageTrace(ctx, &message, delivery)
+
 	sc.logger.Info("处理通知消息",
 		zap.String("message_id", message.ID),
 		zap.String("message_type", string(message.Type)),
@@ -490,50 +426,30 @@ func (sc *SpikeConsumer) handleNotificationMessage(ctx context.Context, delivery
 		return &NonRetryableError{Err: fmt.Errorf("failed to parse notification data: %w", err)}
 	}
 
-	// 这里可以集成各种通知渠道（邮件、短信、推送等）
-	// 暂时只记录日志
+	if sc.notifications != nil {
+		return sc.notifications.SendNotification(ctx, message.ID, message.TraceID, &data)
+	}
+
+	// 未配置通知渠道时只记录日志
 	sc.logger.Info("发送通知",
-		zap.Int64("user_id", data.UserID),
+		zap.String("trace_id", message.TraceID),
+		logger.UserID(data.UserID),
 		zap.String("type", data.Type),
 		zap.String("title", data.Title),
 		zap.String("content", data.Content),
 		zap.String("priority", data.Priority),
 		zap.Strings("channels", data.Channels))
-
-	// TODO: 实际的通知发送逻辑
-	// - 邮件通知
-	// - 短信通知
-	// - App推送通知
-	// - 站内信通知
-
 	return nil
 }
 
-// ErrDuplicateMessage 重复消息错误
-var ErrDuplicateMessage = fmt.Errorf("duplicate message")
-
-// checkIdempotency 检查幂等性
-func (sc *SpikeConsumer) checkIdempotency(ctx context.Context, idempotencyKey, messageID string) error {
-	// 使用Redis检查幂等键
-	processed := fmt.Sprintf("processed:%s", idempotencyKey)
-	exists, err := sc.spikeCache.SetIdempotencyKey(ctx, processed, messageID, 24*time.Hour)
-	if err != nil {
-		return fmt.Errorf("failed to check idempotency: %w", err)
-	}
-
-	if !exists {
-		// 键已存在，说明消息已处理
-		return ErrDuplicateMessage
+// withMessageTrace 将消息的追踪ID写入上下文，消息体缺失时回退到消息头
+func withMessageTrace(ctx context.Context, message *SpikeMessage, delivery amqp.Delivery) context.Context {
+	if message.TraceID == "" {
+		if v, ok := delivery.Headers[tracing.AMQPHeaderTraceID].(string); ok {
+			message.TraceID = v
+		}
 	}
-
-	return nil
-}
-
-// markIdempotencyProcessed 标记幂等键处理完成
-func (sc *SpikeConsumer) markIdempotencyProcessed(ctx context.Context, idempotencyKey, messageID string) error {
-	completed := fmt.Sprintf("completed:%s", idempotencyKey)
-	_, err := sc.spikeCache.SetIdempotencyKey(ctx, completed, messageID, 24*time.Hour)
-	return err
+	return tracing.WithTraceID(ctx, message.TraceID)
 }
 
 // StopConsumers 停止所有消费者
@@ -547,6 +463,10 @@ func (sc *SpikeConsumer) StopConsumers() error {
 			sc.logger.Info("消费者停止成功", zap.String("consumer", name))
 		}
 	}
+	for name, consumer := range sc.streamConsumers {
+		consumer.Stop()
+		sc.logger.Info("Stream 消费者停止成功", zap.String("consumer", name))
+	}
 	return nil
 }
 
@@ -558,3 +478,12 @@ func (sc *SpikeConsumer) GetConsumerStats() map[string]ConsumerStats {
 	}
 	return stats
 }
+
+// GetStreamConsumerStats 获取所有 Redis Stream 消费者统计信息
+func (sc *SpikeConsumer) GetStreamConsumerStats() map[string]RedisStreamConsumerStats {
+	stats := make(map[string]RedisStreamConsumerStats)
+	for name, consumer := range sc.streamConsumers {
+		stats[name] = consumer.GetStats()
+	}
+	return stats
+}
@@ -16,6 +16,7 @@ const (
 	MessageTypeSpikeOrderPaid      MessageType = "spike_order_paid"      // 秒杀订单支付
 	MessageTypeSpikeOrderExpired   MessageType = "spike_order_expired"   // 秒杀订单过期
 	MessageTypeSpikeOrderCancelled MessageType = "spike_order_cancelled" // 秒杀订单取消
+	MessageTypeSpikeOrderAbandoned MessageType = "spike_order_abandoned" // 秒杀订单过期未支付（弃单），供营销召回
 
 	// 库存相关消息
 	MessageTypeStockRestore MessageType = "stock_restore" // 库存恢复
@@ -49,16 +50,27 @@ type SpikeMessage struct {
 
 // SpikeOrderCreatedData 秒杀订单创建消息数据
 type SpikeOrderCreatedData struct {
-	SpikeOrderID   int64     `json:"spike_order_id"`  // 秒杀订单ID
-	SpikeEventID   int64     `json:"spike_event_id"`  // 秒杀活动ID
-	UserID         int64     `json:"user_id"`         // 用户ID
-	ProductID      int64     `json:"product_id"`      // 商品ID
-	Quantity       int64     `json:"quantity"`        // 购买数量
-	SpikePrice     float64   `json:"spike_price"`     // 秒杀价格
-	TotalAmount    float64   `json:"total_amount"`    // 总金额
-	IdempotencyKey string    `json:"idempotency_key"` // 幂等键
-	ExpireAt       time.Time `json:"expire_at"`       // 过期时间
-	CreatedAt      time.Time `json:"created_at"`      // 创建时间
+	SpikeOrderID   int64     `json:"spike_order_id"`      // 秒杀订单ID
+	OrderNo        string    `json:"order_no,omitempty"`  // 参与时预先生成的订单号，升级前的消息为空
+	SpikeEventID   int64     `json:"spike_event_id"`      // 秒杀活动ID
+	UserID         int64     `json:"user_id"`             // 用户ID
+	ProductID      int64     `json:"product_id"`          // 商品ID
+	Quantity       int64     `json:"quantity"`            // 购买数量
+	SpikePrice     float64   `json:"spike_price"`         // 秒杀价格
+	TotalAmount    float64   `json:"total_amount"`        // 总金额
+	IdempotencyKey string    `json:"idempotency_key"`     // 幂等键
+	ExpireAt       time.Time `json:"expire_at"`           // 过期时间
+	CreatedAt      time.Time `json:"created_at"`          // 创建时间
+	UserTier       string    `json:"user_tier,omitempty"` // 用户等级
+	Priority       uint8     `json:"priority,omitempty"`  // 消息优先级，0 表示使用默认优先级
+	// RecoveryCampaignID 召回活动ID，订单创建后将对应弃单标记为已转化
+	RecoveryCampaignID string `json:"recovery_campaign_id,omitempty"`
+	// 参与时的客户端信息，随订单落库
+	ClientIP  string `json:"client_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+	// SoldCountReserved 缓存不可用时降级参与已在数据库中占用已售数量，落库时不再检查与累加
+	SoldCountReserved bool `json:"sold_count_reserved,omitempty"`
 }
 
 // SpikeOrderPaidData 秒杀订单支付消息数据
@@ -83,6 +95,18 @@ type SpikeOrderExpiredData struct {
 	IdempotencyKey string    `json:"idempotency_key"` // 幂等键
 }
 
+// SpikeOrderAbandonedData 弃单消息数据，营销系统据此发起召回活动
+type SpikeOrderAbandonedData struct {
+	AbandonedCheckoutID int64     `json:"abandoned_checkout_id"` // 弃单记录ID，发起召回时使用
+	SpikeOrderID        int64     `json:"spike_order_id"`        // 秒杀订单ID
+	SpikeEventID        int64     `json:"spike_event_id"`        // 秒杀活动ID
+	UserID              int64     `json:"user_id"`               // 用户ID
+	ProductID           int64     `json:"product_id"`            // 商品ID
+	Quantity            int64     `json:"quantity"`              // 购买数量
+	TotalAmount         float64   `json:"total_amount"`          // 订单金额
+	ExpiredAt           time.Time `json:"expired_at"`            // 过期时间
+}
+
 // SpikeOrderCancelledData 秒杀订单取消消息数据
 type SpikeOrderCancelledData struct {
 	SpikeOrderID   int64     `json:"spike_order_id"`  // 秒杀订单ID
@@ -166,6 +190,8 @@ func (m *SpikeMessage) GetRouterKey() string {
 		return "spike.order.expired"
 	case MessageTypeSpikeOrderCancelled:
 		return "spike.order.cancelled"
+	case MessageTypeSpikeOrderAbandoned:
+		return "marketing.order.abandoned"
 	case MessageTypeStockRestore:
 		return "spike.stock.restore"
 	case MessageTypeStockWarning:
@@ -287,6 +313,18 @@ func CreateSpikeOrderCancelledMessage(data *SpikeOrderCancelledData, traceID str
 		Build()
 }
 
+// CreateSpikeOrderAbandonedMessage 创建弃单消息
+func CreateSpikeOrderAbandonedMessage(data *SpikeOrderAbandonedData, traceID string) *SpikeMessage {
+	return NewSpikeMessageBuilder().
+		WithID(generateMessageID()).
+		WithType(MessageTypeSpikeOrderAbandoned).
+		WithTraceID(traceID).
+		WithData(data).
+		WithMetadata("user_id", data.UserID).
+		WithMetadata("spike_event_id", data.SpikeEventID).
+		Build()
+}
+
 // CreateStockRestoreMessage 创建库存恢复消息
 func CreateStockRestoreMessage(data *StockRestoreData, traceID string) *SpikeMessage {
 	return NewSpikeMessageBuilder().
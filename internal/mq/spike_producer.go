@@ -4,11 +4,26 @@ package mq
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
 )
 
+// SpikePublisher 秒杀业务消息发布接口，由 SpikeProducer（RabbitMQ）与 RedisStreamProducer（Redis Streams）实现
+type SpikePublisher interface {
+	PublishSpikeOrderCreated(ctx context.Context, data *SpikeOrderCreatedData, traceID string) error
+	PublishSpikeOrderPaid(ctx context.Context, data *SpikeOrderPaidData, traceID string) error
+	PublishSpikeOrderExpired(ctx context.Context, data *SpikeOrderExpiredData, traceID string) error
+	PublishSpikeOrderCancelled(ctx context.Context, data *SpikeOrderCancelledData, traceID string) error
+	PublishStockRestore(ctx context.Context, data *StockRestoreData, traceID string) error
+	PublishNotification(ctx context.Context, data *NotificationData, traceID string) error
+	PublishSpikeOrderAbandoned(ctx context.Context, data *SpikeOrderAbandonedData, traceID string) error
+}
+
 // SpikeProducer 秒杀消息生产者
 type SpikeProducer struct {
 	producer *Producer
@@ -39,6 +54,12 @@ func NewSpikeProducer(cm *ConnectionManager, config *ProducerConfig, logger *zap
 func (sp *SpikeProducer) PublishSpikeOrderCreated(ctx context.Context, data *SpikeOrderCreatedData, traceID string) error {
 	message := CreateSpikeOrderCreatedMessage(data, traceID)
 
+	// 高等级用户的订单消息优先被消费
+	priority := uint8(5) // 高优先级
+	if data.Priority > 0 {
+		priority = min(data.Priority, SpikeOrderMaxPriority)
+	}
+
 	return sp.publishMessage(ctx, message, SpikeExchange, &PublishOptions{
 		MessageID: message.ID,
 		Type:      string(message.Type),
@@ -49,8 +70,9 @@ func (sp *SpikeProducer) PublishSpikeOrderCreated(ctx context.Context, data *Spi
 			"spike-event-id":  data.SpikeEventID,
 			"user-id":         data.UserID,
 			"idempotency-key": data.IdempotencyKey,
+			"user-tier":       data.UserTier,
 		},
-		Priority: 5, // 高优先级
+		Priority: priority,
 	})
 }
 
@@ -112,6 +134,25 @@ func (sp *SpikeProducer) PublishSpikeOrderCancelled(ctx context.Context, data *S
 	})
 }
 
+// PublishSpikeOrderAbandoned 发布弃单消息，供营销系统发起召回
+func (sp *SpikeProducer) PublishSpikeOrderAbandoned(ctx context.Context, data *SpikeOrderAbandonedData, traceID string) error {
+	message := CreateSpikeOrderAbandonedMessage(data, traceID)
+
+	return sp.publishMessage(ctx, message, SpikeExchange, &PublishOptions{
+		MessageID: message.ID,
+		Type:      string(message.Type),
+		Timestamp: message.Timestamp,
+		Headers: map[string]interface{}{
+			"content-type":   "application/json",
+			"trace-id":       traceID,
+			"spike-event-id": data.SpikeEventID,
+			"spike-order-id": data.SpikeOrderID,
+			"user-id":        data.UserID,
+		},
+		Priority: 2, // 低优先级，营销召回不影响主流程
+	})
+}
+
 // PublishStockRestore 发布库存恢复消息
 func (sp *SpikeProducer) PublishStockRestore(ctx context.Context, data *StockRestoreData, traceID string) error {
 	message := CreateStockRestoreMessage(data, traceID)
@@ -173,25 +214,39 @@ func (sp *SpikeProducer) PublishNotification(ctx context.Context, data *Notifica
 	return sp.publishMessage(ctx, message, SpikeExchange, options)
 }
 
-// PublishDelayedMessage 发布延时消息
-func (sp *SpikeProducer) PublishDelayedMessage(ctx context.Context, message *SpikeMessage, delay time.Duration) error {
-	messageBytes, err := message.ToJSON()
-	if err != nil {
-		return fmt.Errorf("failed to serialize message: %w", err)
-	}
+// PublishSpikeOrderExpiredAt 发布在订单过期时间 data.ExpiredAt 投递的过期消息，
+// 到期后与 PublishSpikeOrderExpired 发布的消息一样进入库存恢复队列；已过期时立即投递
+func (sp *SpikeProducer) PublishSpikeOrderExpiredAt(ctx context.Context, data *SpikeOrderExpiredData, traceID string) error {
+	message := CreateSpikeOrderExpiredMessage(data, traceID)
 
-	options := &PublishOptions{
+	return sp.PublishDelayedMessage(ctx, message, time.Until(data.ExpiredAt), &PublishOptions{
 		MessageID: message.ID,
 		Type:      string(message.Type),
 		Timestamp: message.Timestamp,
 		Headers: map[string]interface{}{
-			"content-type": "application/json",
-			"trace-id":     message.TraceID,
-			"x-delay":      int64(delay / time.Millisecond), // 延时时间（毫秒）
+			"content-type":    "application/json",
+			"trace-id":        traceID,
+			"spike-event-id":  data.SpikeEventID,
+			"user-id":         data.UserID,
+			"idempotency-key": data.IdempotencyKey,
 		},
+		Priority: 6,
+	})
+}
+
+// PublishDelayedMessage 发布延时消息：消息以 delay 为 TTL 进入延时队列，到期后按原路由键投递到主交换机；
+// delay 不大于 0 时直接发布到主交换机
+func (sp *SpikeProducer) PublishDelayedMessage(ctx context.Context, message *SpikeMessage, delay time.Duration, options *PublishOptions) error {
+	if delay <= 0 {
+		return sp.publishMessage(ctx, message, SpikeExchange, options)
 	}
 
-	return sp.producer.Publish(ctx, SpikeDelayExchange, message.GetRouterKey(), messageBytes, options)
+	if options == nil {
+		options = &PublishOptions{MessageID: message.ID, Type: string(message.Type), Timestamp: message.Timestamp}
+	}
+	// 按毫秒向上取整，消息不早于预期时间投递
+	options.Expiration = strconv.FormatInt(int64((delay+time.Millisecond-1)/time.Millisecond), 10)
+	return sp.publishMessage(ctx, message, SpikeDelayExchange, options)
 }
 
 // PublishBatch 批量发布消息
@@ -209,7 +264,14 @@ func (sp *SpikeProducer) PublishBatch(ctx context.Context, messages []*SpikeMess
 }
 
 // publishMessage 发布消息的通用方法
-func (sp *SpikeProducer) publishMessage(ctx context.Context, message *SpikeMessage, exchange string, options *PublishOptions) error {
+func (sp *SpikeProducer) publishMessage(ctx context.Context, message *SpikeMessage, exchange string, options *PublishOptions) (err error) {
+	defer func() { metrics.MQPublished.Inc(string(message.Type), metrics.Result(err)) }()
+
+	// 调用方未显式传入追踪ID时沿用上下文中的追踪ID
+	if message.TraceID == "" {
+		message.TraceID = tracing.TraceIDFromContext(ctx)
+	}
+
 	messageBytes, err := message.ToJSON()
 	if err != nil {
 		return fmt.Errorf("failed to serialize message: %w", err)
@@ -231,6 +293,7 @@ func (sp *SpikeProducer) publishMessage(ctx context.Context, message *SpikeMessa
 	options.Headers["message-version"] = message.Version
 	options.Headers["message-source"] = message.Source
 	options.Headers["content-type"] = "application/json"
+	options.Headers[tracing.AMQPHeaderTraceID] = message.TraceID
 
 	// 记录发布日志
 	sp.logger.Info("发布秒杀消息",
@@ -268,54 +331,3 @@ func (sp *SpikeProducer) GetAllQueuesInfo(ctx context.Context) ([]*QueueInfo, er
 func (sp *SpikeProducer) Close() error {
 	return sp.producer.Close()
 }
-
-// SpikeProducerWithRetry 带重试的秒杀生产者包装器
-type SpikeProducerWithRetry struct {
-	*SpikeProducer
-	maxRetries    int
-	retryInterval time.Duration
-}
-
-// NewSpikeProducerWithRetry 创建带重试的秒杀生产者
-func NewSpikeProducerWithRetry(producer *SpikeProducer, maxRetries int, retryInterval time.Duration) *SpikeProducerWithRetry {
-	return &SpikeProducerWithRetry{
-		SpikeProducer: producer,
-		maxRetries:    maxRetries,
-		retryInterval: retryInterval,
-	}
-}
-
-// PublishWithRetry 带重试的消息发布
-func (spr *SpikeProducerWithRetry) PublishWithRetry(ctx context.Context, publishFunc func() error) error {
-	var lastErr error
-
-	for attempt := 0; attempt <= spr.maxRetries; attempt++ {
-		err := publishFunc()
-		if err == nil {
-			return nil
-		}
-
-		lastErr = err
-		spr.logger.Warn("消息发布失败，准备重试",
-			zap.Int("attempt", attempt+1),
-			zap.Int("max_retries", spr.maxRetries+1),
-			zap.Error(err))
-
-		if attempt < spr.maxRetries {
-			select {
-			case <-time.After(spr.retryInterval):
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
-	}
-
-	return fmt.Errorf("failed to publish message after %d attempts: %w", spr.maxRetries+1, lastErr)
-}
-
-// PublishSpikeOrderCreatedWithRetry 带重试的秒杀订单创建消息发布
-func (spr *SpikeProducerWithRetry) PublishSpikeOrderCreatedWithRetry(ctx context.Context, data *SpikeOrderCreatedData, traceID string) error {
-	return spr.PublishWithRetry(ctx, func() error {
-		return spr.PublishSpikeOrderCreated(ctx, data, traceID)
-	})
-}
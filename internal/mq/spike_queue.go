@@ -3,6 +3,7 @@ package mq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -12,16 +13,24 @@ import (
 // 秒杀相关的交换机和队列常量
 const (
 	// 交换机
-	SpikeExchange      = "spike.exchange"       // 秒杀主交换机
-	SpikeDelayExchange = "spike.delay.exchange" // 延时交换机
-	SpikeDLXExchange   = "spike.dlx.exchange"   // 死信交换机
+	SpikeExchange      = "spike.exchange"           // 秒杀主交换机
+	SpikeDelayExchange = "spike.delay.ttl.exchange" // 延时交换机，消息进入延时队列，按消息 TTL 到期后经死信路由回主交换机
+	SpikeDLXExchange   = "spike.dlx.exchange"       // 死信交换机
 
 	// 队列
-	SpikeOrderQueue        = "spike.order.queue"         // 秒杀订单队列
-	SpikeOrderDelayQueue   = "spike.order.delay.queue"   // 秒杀订单延时队列
-	SpikeStockRestoreQueue = "spike.stock.restore.queue" // 库存恢复队列
-	SpikeNotificationQueue = "spike.notification.queue"  // 通知队列
-	SpikeDLXQueue          = "spike.dlx.queue"           // 死信队列
+	SpikeOrderQueue        = "spike.order.priority.queue"  // 秒杀订单队列，按用户等级设置消息优先级
+	SpikeOrderDelayQueue   = "spike.order.delay.ttl.queue" // 秒杀订单延时队列，无消费者
+	SpikeStockRestoreQueue = "spike.stock.restore.queue"   // 库存恢复队列
+	SpikeNotificationQueue = "spike.notification.queue"    // 通知队列
+	SpikeMarketingQueue    = "spike.marketing.queue"       // 营销事件队列（弃单召回），由外部营销系统消费
+	SpikeDLXQueue          = "spike.dlx.queue"             // 死信队列
+
+	// LegacySpikeOrderQueue 启用优先级前的订单队列。已存在的队列无法修改 x-max-priority，
+	// 优先级队列改用新名称声明；旧队列在启动时解除绑定，消费者继续消费直到排空，之后可手动删除
+	LegacySpikeOrderQueue = "spike.order.queue"
+
+	// SpikeOrderStream Redis Streams 模式下的订单 Stream，沿用原订单队列名，不受优先级队列改名影响
+	SpikeOrderStream = "spike.order.queue"
 
 	// 路由键
 	SpikeOrderCreatedRoutingKey      = "spike.order.created"
@@ -31,6 +40,13 @@ const (
 	SpikeStockRestoreRoutingKey      = "spike.stock.restore"
 	SpikeNotificationRoutingKey      = "notification.send"
 	SpikeOrderConfirmationRoutingKey = "notification.order.confirmation"
+	SpikeOrderAbandonedRoutingKey    = "marketing.order.abandoned"
+
+	// SpikeDelayRoutingPattern 延时队列绑定的路由键，接收延时交换机上的所有消息
+	SpikeDelayRoutingPattern = "#"
+
+	// SpikeOrderMaxPriority 订单队列支持的最大消息优先级
+	SpikeOrderMaxPriority = 10
 )
 
 // SpikeQueueManager 秒杀队列管理器
@@ -74,10 +90,60 @@ func (qm *SpikeQueueManager) SetupQueues(ctx context.Context) error {
 		return fmt.Errorf("failed to bind queues: %w", err)
 	}
 
+	// 旧订单队列不再接收新消息
+	if err := qm.retireLegacyOrderQueue(ch); err != nil {
+		return fmt.Errorf("failed to retire legacy order queue: %w", err)
+	}
+
 	qm.logger.Info("秒杀队列设置完成")
 	return nil
 }
 
+// retireLegacyOrderQueue 解除旧订单队列与主交换机的绑定，旧队列不存在时跳过
+func (qm *SpikeQueueManager) retireLegacyOrderQueue(ch *amqp.Channel) error {
+	exists, err := qm.QueueExists(LegacySpikeOrderQueue)
+	if err != nil || !exists {
+		return err
+	}
+
+	for _, routingKey := range []string{SpikeOrderCreatedRoutingKey, SpikeOrderPaidRoutingKey} {
+		if err := ch.QueueUnbind(LegacySpikeOrderQueue, routingKey, SpikeExchange, nil); err != nil {
+			return fmt.Errorf("failed to unbind queue %s: %w", LegacySpikeOrderQueue, err)
+		}
+	}
+	qm.logger.Warn("旧订单队列已解除绑定，排空后可删除",
+		zap.String("queue", LegacySpikeOrderQueue),
+		zap.String("replacement", SpikeOrderQueue))
+	return nil
+}
+
+// QueueExists 检查队列是否存在。被动声明不存在的队列会关闭通道，因此使用独立通道而不是通道池
+func (qm *SpikeQueueManager) QueueExists(queueName string) (bool, error) {
+	return queueExists(qm.cm, queueName)
+}
+
+// queueExists 被动声明队列，返回 404 时队列不存在
+func queueExists(cm *ConnectionManager, queueName string) (bool, error) {
+	conn := cm.GetConnection()
+	if conn == nil || conn.IsClosed() {
+		return false, fmt.Errorf("connection is not available")
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+
+	if _, err := ch.QueueDeclarePassive(queueName, true, false, false, false, nil); err != nil {
+		var amqpErr *amqp.Error
+		if errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect queue %s: %w", queueName, err)
+	}
+	return true, nil
+}
+
 // declareExchanges 声明交换机
 func (qm *SpikeQueueManager) declareExchanges(ch *amqp.Channel) error {
 	exchanges := []struct {
@@ -99,13 +165,16 @@ func (qm *SpikeQueueManager) declareExchanges(ch *amqp.Channel) error {
 			args:       nil,
 		},
 		{
+			// 延时基于消息 TTL 与死信路由，不依赖 rabbitmq_delayed_message_exchange 插件。
+			// 此前按插件类型声明的 spike.delay.exchange 与队列级 TTL 的 spike.order.delay.queue 参数不同，
+			// 重新声明会失败，因此使用新名称；旧交换机与队列不再声明，其中的存量消息仍按原参数到期回到主交换机
 			name:       SpikeDelayExchange,
-			kind:       "x-delayed-message",
+			kind:       "topic",
 			durable:    true,
 			autoDelete: false,
 			internal:   false,
 			noWait:     false,
-			args:       amqp.Table{"x-delayed-type": "topic"},
+			args:       nil,
 		},
 		{
 			name:       SpikeDLXExchange,
@@ -157,6 +226,7 @@ func (qm *SpikeQueueManager) declareQueues(ch *amqp.Channel) error {
 				"x-dead-letter-exchange":    SpikeDLXExchange,
 				"x-dead-letter-routing-key": "failed.order",
 				"x-max-retries":             3,
+				"x-max-priority":            SpikeOrderMaxPriority, // 启用优先级队列，按用户等级排序消费
 			},
 		},
 		{
@@ -165,10 +235,11 @@ func (qm *SpikeQueueManager) declareQueues(ch *amqp.Channel) error {
 			autoDelete: false,
 			exclusive:  false,
 			noWait:     false,
+			// 不设置队列级 TTL 与死信路由键：消息按各自的 expiration 到期，
+			// 以发布时的路由键投递回主交换机（如 spike.order.expired 进入库存恢复队列）。
+			// 队列只在队首检查过期，延时应大致随发布顺序递增，秒杀订单统一的支付期限满足这一点
 			args: amqp.Table{
-				"x-message-ttl":             30 * 60 * 1000, // 30分钟TTL
-				"x-dead-letter-exchange":    SpikeExchange,
-				"x-dead-letter-routing-key": SpikeOrderExpiredRoutingKey,
+				"x-dead-letter-exchange": SpikeExchange,
 			},
 		},
 		{
@@ -193,6 +264,17 @@ func (qm *SpikeQueueManager) declareQueues(ch *amqp.Channel) error {
 				"x-dead-letter-routing-key": "failed.notification",
 			},
 		},
+		{
+			name:       SpikeMarketingQueue,
+			durable:    true,
+			autoDelete: false,
+			exclusive:  false,
+			noWait:     false,
+			args: amqp.Table{
+				"x-dead-letter-exchange":    SpikeDLXExchange,
+				"x-dead-letter-routing-key": "failed.marketing",
+			},
+		},
 		{
 			name:       SpikeDLXQueue,
 			durable:    true,
@@ -243,11 +325,14 @@ func (qm *SpikeQueueManager) bindQueues(ch *amqp.Channel) error {
 		{SpikeNotificationQueue, SpikeExchange, SpikeNotificationRoutingKey, false, nil},
 		{SpikeNotificationQueue, SpikeExchange, SpikeOrderConfirmationRoutingKey, false, nil},
 
+		// 绑定营销事件队列
+		{SpikeMarketingQueue, SpikeExchange, SpikeOrderAbandonedRoutingKey, false, nil},
+
 		// 绑定死信队列
 		{SpikeDLXQueue, SpikeDLXExchange, "failed.*", false, nil},
 
 		// 绑定延时队列
-		{SpikeOrderDelayQueue, SpikeDelayExchange, "delay.order.*", false, nil},
+		{SpikeOrderDelayQueue, SpikeDelayExchange, SpikeDelayRoutingPattern, false, nil},
 	}
 
 	for _, binding := range bindings {
@@ -305,6 +390,7 @@ func (qm *SpikeQueueManager) GetAllQueuesInfo(ctx context.Context) ([]*QueueInfo
 		SpikeOrderDelayQueue,
 		SpikeStockRestoreQueue,
 		SpikeNotificationQueue,
+		SpikeMarketingQueue,
 		SpikeDLXQueue,
 	}
 
@@ -0,0 +1,134 @@
+// Package notify 定义用户通知渠道抽象（邮件、短信、推送），提供 SMTP 邮件与 HTTP Webhook 实现，
+// 并由 Dispatcher 按渠道路由通知、按各渠道的重试策略重试发送。
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 通知渠道，与通知消息中的 channels 取值一致
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// 通知发送错误
+var (
+	ErrChannelNotConfigured = errors.New("notify: channel not configured")
+	ErrNoRecipient          = errors.New("notify: recipient has no address for channel")
+)
+
+// Message 一条待发送的通知
+type Message struct {
+	ID       string // 通知消息ID，Webhook 渠道作为幂等键透传给下游
+	UserID   int64
+	Email    string // 收件邮箱，邮件渠道必填
+	Type     string
+	Title    string
+	Content  string
+	Priority string
+	Data     map[string]interface{}
+}
+
+// Notifier 通知渠道接口，每个实现负责一个渠道
+type Notifier interface {
+	// Channel 返回渠道名称
+	Channel() string
+	// Send 发送一条通知，返回 *PermanentError 表示重试也不会成功
+	Send(ctx context.Context, msg *Message) error
+}
+
+// PermanentError 不可重试的发送失败，如收件地址缺失或被下游拒绝
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("permanent notify error: %v", e.Err)
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent 检查是否为不可重试的发送失败
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryPolicy 渠道重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 首次发送失败后的重试次数
+	Backoff    time.Duration // 首次重试等待时间，之后按指数翻倍
+	MaxBackoff time.Duration // 重试等待时间上限，0 表示不限制
+}
+
+// delay 返回第 retry 次重试前的等待时间（retry 从 1 开始）
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff << (retry - 1)
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// channel 已注册的渠道及其重试策略
+type channel struct {
+	notifier Notifier
+	policy   RetryPolicy
+}
+
+// Dispatcher 按渠道名称把通知路由到对应的 Notifier，并按渠道的重试策略重试
+type Dispatcher struct {
+	channels map[string]*channel
+}
+
+// NewDispatcher 创建通知分发器
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{channels: make(map[string]*channel)}
+}
+
+// Register 注册渠道，同名渠道后注册的覆盖先注册的
+func (d *Dispatcher) Register(notifier Notifier, policy RetryPolicy) {
+	d.channels[notifier.Channel()] = &channel{notifier: notifier, policy: policy}
+}
+
+// Has 检查渠道是否已注册
+func (d *Dispatcher) Has(name string) bool {
+	_, ok := d.channels[name]
+	return ok
+}
+
+// Empty 检查是否没有注册任何渠道
+func (d *Dispatcher) Empty() bool {
+	return len(d.channels) == 0
+}
+
+// Deliver 通过指定渠道发送通知，失败按渠道重试策略重试，返回实际发送次数与最后一次的错误。
+// 渠道未注册时返回 ErrChannelNotConfigured；不可重试的失败或 ctx 结束时不再重试
+func (d *Dispatcher) Deliver(ctx context.Context, name string, msg *Message) (attempts int, err error) {
+	ch, ok := d.channels[name]
+	if !ok {
+		return 0, ErrChannelNotConfigured
+	}
+
+	for {
+		attempts++
+		if err = ch.notifier.Send(ctx, msg); err == nil || IsPermanent(err) || attempts > ch.policy.MaxRetries {
+			return attempts, err
+		}
+
+		timer := time.NewTimer(ch.policy.delay(attempts))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, err
+		}
+	}
+}
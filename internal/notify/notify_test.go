@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	var (
+		status  = http.StatusOK
+		payload webhookPayload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get(WebhookSignatureHeader); got != Sign([]byte("secret"), body) {
+			t.Errorf("signature = %q, want HMAC of body", got)
+		}
+		if got := r.Header.Get("Idempotency-Key"); got != "msg-1" {
+			t.Errorf("Idempotency-Key = %q, want msg-1", got)
+		}
+		_ = json.Unmarshal(body, &payload)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(ChannelSMS, server.URL, "secret", time.Second)
+	msg := &Message{ID: "msg-1", UserID: 7, Type: "spike_order_created", Title: "秒杀成功", Content: "请尽快支付"}
+	if err := notifier.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if payload.Channel != ChannelSMS || payload.UserID != 7 || payload.Title != "秒杀成功" {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	// 下游拒绝的请求不可重试，服务端错误与限流可重试
+	status = http.StatusBadRequest
+	if err := notifier.Send(context.Background(), msg); !IsPermanent(err) {
+		t.Errorf("Send(400) error = %v, want permanent", err)
+	}
+	for _, status = range []int{http.StatusTooManyRequests, http.StatusBadGateway} {
+		if err := notifier.Send(context.Background(), msg); err == nil || IsPermanent(err) {
+			t.Errorf("Send(%d) error = %v, want retryable", status, err)
+		}
+	}
+}
+
+// scriptedNotifier 按顺序返回预设的发送结果
+type scriptedNotifier struct {
+	results []error
+	calls   int
+}
+
+func (n *scriptedNotifier) Channel() string { return ChannelPush }
+
+func (n *scriptedNotifier) Send(ctx context.Context, msg *Message) error {
+	n.calls++
+	if n.calls <= len(n.results) {
+		return n.results[n.calls-1]
+	}
+	return nil
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	ctx := context.Background()
+	transient := errors.New("gateway timeout")
+	policy := RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}
+
+	if _, err := NewDispatcher().Deliver(ctx, ChannelPush, &Message{}); !errors.Is(err, ErrChannelNotConfigured) {
+		t.Errorf("Deliver(unregistered) error = %v, want ErrChannelNotConfigured", err)
+	}
+
+	// 可重试的失败按策略重试，成功即停止
+	notifier := &scriptedNotifier{results: []error{transient, transient}}
+	dispatcher := NewDispatcher()
+	dispatcher.Register(notifier, policy)
+	if attempts, err := dispatcher.Deliver(ctx, ChannelPush, &Message{}); err != nil || attempts != 3 {
+		t.Errorf("Deliver() = %d, %v; want success on 3rd attempt", attempts, err)
+	}
+
+	// 重试次数耗尽后返回最后一次的错误
+	notifier = &scriptedNotifier{results: []error{transient, transient, transient, transient}}
+	dispatcher.Register(notifier, policy)
+	if attempts, err := dispatcher.Deliver(ctx, ChannelPush, &Message{}); !errors.Is(err, transient) || attempts != 3 {
+		t.Errorf("Deliver() = %d, %v; want 3 attempts ending with transient error", attempts, err)
+	}
+
+	// 不可重试的失败不再重试
+	notifier = &scriptedNotifier{results: []error{&PermanentError{Err: ErrNoRecipient}}}
+	dispatcher.Register(notifier, policy)
+	if attempts, err := dispatcher.Deliver(ctx, ChannelPush, &Message{}); !errors.Is(err, ErrNoRecipient) || attempts != 1 {
+		t.Errorf("Deliver() = %d, %v; want 1 attempt ending with ErrNoRecipient", attempts, err)
+	}
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig SMTP 邮件渠道配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 为空时不认证
+	Password string
+	From     string
+	Timeout  time.Duration // 连接与单封邮件发送的超时时间
+}
+
+// SMTPNotifier 通过 SMTP 发送纯文本邮件，服务器支持 STARTTLS 时加密连接
+type SMTPNotifier struct {
+	config SMTPConfig
+	auth   smtp.Auth
+}
+
+// NewSMTPNotifier 创建 SMTP 邮件渠道
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	n := &SMTPNotifier{config: config}
+	if config.Username != "" {
+		n.auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	return n
+}
+
+// Channel 返回渠道名称
+func (n *SMTPNotifier) Channel() string {
+	return ChannelEmail
+}
+
+// Send 发送邮件。用户没有邮箱或服务器返回 5xx 时不可重试
+func (n *SMTPNotifier) Send(ctx context.Context, msg *Message) error {
+	if msg.Email == "" {
+		return &PermanentError{Err: ErrNoRecipient}
+	}
+
+	if n.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.config.Timeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to dial smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if err := n.send(client, msg); err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+			return &PermanentError{Err: err}
+		}
+		return err
+	}
+	return client.Quit()
+}
+
+// send 在已建立的连接上完成加密、认证与投递
+func (n *SMTPNotifier) send(client *smtp.Client, msg *Message) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	if err := client.Rcpt(msg.Email); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %w", err)
+	}
+	if _, err := w.Write(n.buildMail(msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish mail: %w", err)
+	}
+	return nil
+}
+
+// buildMail 构造 UTF-8 纯文本邮件，标题按 RFC 2047 编码
+func (n *SMTPNotifier) buildMail(msg *Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.Email)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Content)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader Webhook 请求体的 HMAC-SHA256 签名头，值为十六进制编码
+const WebhookSignatureHeader = "X-Notify-Signature"
+
+// webhookPayload Webhook 渠道发送的请求体
+type webhookPayload struct {
+	ID       string                 `json:"id"`
+	Channel  string                 `json:"channel"`
+	UserID   int64                  `json:"user_id"`
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Content  string                 `json:"content"`
+	Priority string                 `json:"priority,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	SentAt   time.Time              `json:"sent_at"`
+}
+
+// WebhookNotifier 把通知以 JSON POST 给下游网关（短信网关、推送服务等），
+// 由网关按用户ID查找手机号或设备并完成投递
+type WebhookNotifier struct {
+	channel string
+	url     string
+	secret  []byte
+	client  *http.Client
+}
+
+// NewWebhookNotifier 创建 Webhook 渠道，secret 非空时对请求体签名
+func NewWebhookNotifier(channel, url, secret string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		channel: channel,
+		url:     url,
+		secret:  []byte(secret),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Channel 返回渠道名称
+func (n *WebhookNotifier) Channel() string {
+	return n.channel
+}
+
+// Send 发送通知。2xx 视为成功；除 408、429 外的 4xx 视为不可重试，其余失败可重试
+func (n *WebhookNotifier) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(&webhookPayload{
+		ID:       msg.ID,
+		Channel:  n.channel,
+		UserID:   msg.UserID,
+		Type:     msg.Type,
+		Title:    msg.Title,
+		Content:  msg.Content,
+		Priority: msg.Priority,
+		Data:     msg.Data,
+		SentAt:   time.Now(),
+	})
+	if err != nil {
+		return &PermanentError{Err: fmt.Errorf("failed to marshal webhook payload: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return &PermanentError{Err: fmt.Errorf("failed to create webhook request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", msg.ID)
+	if len(n.secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s webhook: %w", n.channel, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return &PermanentError{Err: fmt.Errorf("%s webhook rejected notification: status %d", n.channel, resp.StatusCode)}
+	default:
+		return fmt.Errorf("%s webhook failed: status %d", n.channel, resp.StatusCode)
+	}
+}
+
+// Sign 计算 Webhook 请求体的签名，供下游校验请求来源
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,97 @@
+// Package payment 定义支付渠道抽象（创建支付意图、扣款、退款、回调校验），
+// 并提供按金额确定结果的沙箱实现，供接入真实支付渠道前联调与端到端测试使用。
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 支付渠道错误
+var (
+	ErrIntentNotFound   = errors.New("payment: intent not found")
+	ErrInvalidAmount    = errors.New("payment: amount must be positive")
+	ErrInvalidState     = errors.New("payment: intent state does not allow this operation")
+	ErrRefundExceeds    = errors.New("payment: refund exceeds captured amount")
+	ErrInvalidSignature = errors.New("payment: invalid webhook signature")
+	ErrInvalidToken     = errors.New("payment: invalid payment token")
+)
+
+// IntentStatus 支付意图状态
+type IntentStatus string
+
+const (
+	IntentStatusCreated    IntentStatus = "created"    // 已创建，等待用户在支付渠道完成支付
+	IntentStatusAuthorized IntentStatus = "authorized" // 用户已授权，等待商户扣款
+	IntentStatusSucceeded  IntentStatus = "succeeded"  // 已扣款
+	IntentStatusFailed     IntentStatus = "failed"     // 授权或扣款失败
+	IntentStatusRefunded   IntentStatus = "refunded"   // 已全额退款
+)
+
+// IntentRequest 创建支付意图的请求
+type IntentRequest struct {
+	Reference string  // 商户侧业务单号，如 spike_order:42，随回调原样返回
+	Amount    float64 // 支付金额（元）
+	Currency  string
+	UserID    int64
+}
+
+// Intent 支付意图，一次支付从创建到扣款、退款的全过程
+type Intent struct {
+	ID             string       `json:"id"`
+	Provider       string       `json:"provider"`
+	Reference      string       `json:"reference"`
+	Amount         float64      `json:"amount"`
+	Currency       string       `json:"currency"`
+	Status         IntentStatus `json:"status"`
+	RefundedAmount float64      `json:"refunded_amount,omitempty"`
+	FailureReason  string       `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// Refund 退款结果
+type Refund struct {
+	ID       string  `json:"id"`
+	IntentID string  `json:"intent_id"`
+	Amount   float64 `json:"amount"`
+}
+
+// WebhookEventType 支付回调事件类型
+type WebhookEventType string
+
+const (
+	WebhookPaymentAuthorized WebhookEventType = "payment.authorized" // 用户已授权，商户应校验订单后扣款
+	WebhookPaymentSucceeded  WebhookEventType = "payment.succeeded"  // 已扣款
+	WebhookPaymentFailed     WebhookEventType = "payment.failed"     // 授权或扣款失败
+)
+
+// WebhookEvent 支付渠道推送的回调事件
+type WebhookEvent struct {
+	ID         string           `json:"id"`
+	Type       WebhookEventType `json:"type"`
+	Intent     *Intent          `json:"intent"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
+
+// Provider 支付渠道接口，真实渠道与沙箱实现同一接口，业务代码不感知具体渠道
+type Provider interface {
+	// Name 返回渠道名称，记录为订单的支付方式
+	Name() string
+	// CreateIntent 创建支付意图，用户随后在支付渠道完成授权
+	CreateIntent(ctx context.Context, req *IntentRequest) (*Intent, error)
+	// Capture 对已授权的支付意图扣款；扣款被拒绝时返回状态为 failed 的意图而不是错误
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	// Refund 对已扣款的支付意图退款，amount 不超过未退款金额
+	Refund(ctx context.Context, intentID string, amount float64) (*Refund, error)
+	// VerifyWebhook 校验回调签名并解析事件，签名无效时返回 ErrInvalidSignature
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// Charger 由支持一步扣款的渠道实现：客户端已在渠道侧取得支付凭证（如卡 token）时，
+// 服务端凭凭证同步完成授权与扣款，不经过回调。未实现该接口的渠道只能走支付意图加回调的流程
+type Charger interface {
+	// Charge 创建支付意图并凭支付凭证完成授权与扣款；被拒绝时返回状态为 failed 的意图而不是错误，
+	// 凭证无效时返回 ErrInvalidToken
+	Charge(ctx context.Context, req *IntentRequest, paymentToken string) (*Intent, error)
+}
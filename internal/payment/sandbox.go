@@ -0,0 +1,229 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// SandboxProviderName 沙箱渠道名称
+const SandboxProviderName = "sandbox"
+
+// 沙箱按金额的分位决定支付结果，便于在测试中构造各种结果
+const (
+	SandboxDeclineCents        = 1 // 金额以 .01 结尾：授权被拒绝
+	SandboxCaptureFailCents    = 2 // 金额以 .02 结尾：授权成功但扣款失败
+	sandboxDeclineReason       = "card_declined"
+	sandboxCaptureFailReason   = "insufficient_funds"
+	sandboxRefundAmountEpsilon = 1e-9
+)
+
+// Sandbox 进程内的沙箱支付渠道，结果只由金额决定，不访问外部服务。
+// Authorize 模拟用户在支付渠道完成支付，SignWebhook 生成与真实渠道格式一致的签名回调。
+// 支付意图只保存在进程内存中，重启后丢失，多实例部署时授权与扣款可能落到没有该意图的实例，
+// 只适用于单实例的开发与联调环境
+type Sandbox struct {
+	secret []byte
+
+	mu      sync.Mutex
+	intents map[string]*Intent
+	payers  map[string]int64 // 支付意图ID -> 创建意图时的付款用户ID
+}
+
+// NewSandbox 创建沙箱支付渠道，secret 用于回调签名；为空时使用进程内随机密钥，
+// 回调只能由本进程签发与校验
+func NewSandbox(secret string) *Sandbox {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("payment: failed to generate sandbox webhook secret: %v", err))
+		}
+	}
+	return &Sandbox{
+		secret:  key,
+		intents: make(map[string]*Intent),
+		payers:  make(map[string]int64),
+	}
+}
+
+// Name 返回渠道名称
+func (s *Sandbox) Name() string {
+	return SandboxProviderName
+}
+
+// CreateIntent 创建支付意图
+func (s *Sandbox) CreateIntent(ctx context.Context, req *IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent := &Intent{
+		ID:        s.nextID("sb_pi"),
+		Provider:  SandboxProviderName,
+		Reference: req.Reference,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Status:    IntentStatusCreated,
+		CreatedAt: time.Now(),
+	}
+	s.intents[intent.ID] = intent
+	s.payers[intent.ID] = req.UserID
+	return copyIntent(intent), nil
+}
+
+// Authorize 模拟用户完成支付：金额以 .01 结尾时授权被拒绝，否则进入已授权状态。
+// 只有创建意图时的付款用户可以授权，其他用户按意图不存在处理；payerID 为 0 时不校验（管理员代为模拟）。
+// 返回应推送给商户的回调事件
+func (s *Sandbox) Authorize(ctx context.Context, intentID string, payerID int64) (*WebhookEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent, ok := s.intents[intentID]
+	if !ok || (payerID != 0 && s.payers[intentID] != payerID) {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != IntentStatusCreated {
+		return nil, ErrInvalidState
+	}
+
+	eventType := WebhookPaymentAuthorized
+	intent.Status = IntentStatusAuthorized
+	if amountCents(intent.Amount) == SandboxDeclineCents {
+		eventType = WebhookPaymentFailed
+		intent.Status = IntentStatusFailed
+		intent.FailureReason = sandboxDeclineReason
+	}
+	return &WebhookEvent{
+		ID:         s.nextID("sb_evt"),
+		Type:       eventType,
+		Intent:     copyIntent(intent),
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// Capture 扣款：金额以 .02 结尾时扣款失败；已扣款的意图重复扣款直接返回
+func (s *Sandbox) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent, ok := s.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	switch intent.Status {
+	case IntentStatusSucceeded:
+		return copyIntent(intent), nil
+	case IntentStatusAuthorized:
+	default:
+		return nil, ErrInvalidState
+	}
+
+	intent.Status = IntentStatusSucceeded
+	if amountCents(intent.Amount) == SandboxCaptureFailCents {
+		intent.Status = IntentStatusFailed
+		intent.FailureReason = sandboxCaptureFailReason
+	}
+	return copyIntent(intent), nil
+}
+
+// Charge 一步完成授权与扣款，结果与 Authorize 加 Capture 相同；支付凭证只需非空
+func (s *Sandbox) Charge(ctx context.Context, req *IntentRequest, paymentToken string) (*Intent, error) {
+	if paymentToken == "" {
+		return nil, ErrInvalidToken
+	}
+	intent, err := s.CreateIntent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.Authorize(ctx, intent.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Intent.Status != IntentStatusAuthorized {
+		return event.Intent, nil
+	}
+	return s.Capture(ctx, intent.ID)
+}
+
+// Refund 退款，累计退款达到支付金额后意图变为已退款
+func (s *Sandbox) Refund(ctx context.Context, intentID string, amount float64) (*Refund, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intent, ok := s.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != IntentStatusSucceeded {
+		return nil, ErrInvalidState
+	}
+	if intent.RefundedAmount+amount > intent.Amount+sandboxRefundAmountEpsilon {
+		return nil, ErrRefundExceeds
+	}
+
+	intent.RefundedAmount += amount
+	if intent.RefundedAmount >= intent.Amount-sandboxRefundAmountEpsilon {
+		intent.Status = IntentStatusRefunded
+	}
+	return &Refund{ID: s.nextID("sb_re"), IntentID: intentID, Amount: amount}, nil
+}
+
+// SignWebhook 序列化回调事件并签名，返回请求体与签名头的值
+func (s *Sandbox) SignWebhook(event *WebhookEvent) ([]byte, string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+	return payload, s.signature(payload), nil
+}
+
+// VerifyWebhook 校验签名并解析回调事件
+func (s *Sandbox) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.signature(payload))) {
+		return nil, ErrInvalidSignature
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook event: %w", err)
+	}
+	if event.Intent == nil {
+		return nil, fmt.Errorf("webhook event %s has no intent", event.ID)
+	}
+	return &event, nil
+}
+
+func (s *Sandbox) signature(payload []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// nextID 生成带前缀的随机ID，ID 不可猜测，不能据此枚举其他用户的支付意图
+func (s *Sandbox) nextID(prefix string) string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("payment: failed to generate sandbox id: %v", err))
+	}
+	return prefix + "_" + hex.EncodeToString(b)
+}
+
+// amountCents 返回金额的分位
+func amountCents(amount float64) int64 {
+	return int64(math.Round(amount*100)) % 100
+}
+
+func copyIntent(intent *Intent) *Intent {
+	copied := *intent
+	return &copied
+}
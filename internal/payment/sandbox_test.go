@@ -0,0 +1,141 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSandbox_OutcomeByAmount(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		amount        float64
+		wantEvent     WebhookEventType
+		wantCaptured  IntentStatus
+		wantRejection string
+	}{
+		{name: "成功", amount: 99.00, wantEvent: WebhookPaymentAuthorized, wantCaptured: IntentStatusSucceeded},
+		{name: "授权被拒绝", amount: 99.01, wantEvent: WebhookPaymentFailed, wantRejection: sandboxDeclineReason},
+		{name: "扣款失败", amount: 99.02, wantEvent: WebhookPaymentAuthorized, wantCaptured: IntentStatusFailed, wantRejection: sandboxCaptureFailReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSandbox("secret")
+			intent, err := s.CreateIntent(ctx, &IntentRequest{Reference: "spike_order:1", Amount: tt.amount, Currency: "CNY"})
+			if err != nil || intent.Status != IntentStatusCreated {
+				t.Fatalf("CreateIntent() = %+v, %v", intent, err)
+			}
+
+			event, err := s.Authorize(ctx, intent.ID, 0)
+			if err != nil || event.Type != tt.wantEvent {
+				t.Fatalf("Authorize() = %+v, %v, want %s", event, err, tt.wantEvent)
+			}
+			if tt.wantEvent == WebhookPaymentFailed {
+				if event.Intent.FailureReason != tt.wantRejection {
+					t.Errorf("FailureReason = %q, want %q", event.Intent.FailureReason, tt.wantRejection)
+				}
+				return
+			}
+
+			captured, err := s.Capture(ctx, intent.ID)
+			if err != nil || captured.Status != tt.wantCaptured || captured.FailureReason != tt.wantRejection {
+				t.Errorf("Capture() = %+v, %v, want %s", captured, err, tt.wantCaptured)
+			}
+		})
+	}
+}
+
+func TestSandbox_CaptureAndRefund(t *testing.T) {
+	ctx := context.Background()
+	s := NewSandbox("secret")
+	intent, _ := s.CreateIntent(ctx, &IntentRequest{Amount: 10})
+
+	if _, err := s.Capture(ctx, intent.ID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Capture() before authorize error = %v, want ErrInvalidState", err)
+	}
+	if _, err := s.Authorize(ctx, intent.ID, 0); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	first, _ := s.Capture(ctx, intent.ID)
+	again, err := s.Capture(ctx, intent.ID)
+	if err != nil || again.Status != IntentStatusSucceeded || again.ID != first.ID {
+		t.Errorf("repeated Capture() = %+v, %v, want idempotent success", again, err)
+	}
+
+	if _, err := s.Refund(ctx, intent.ID, 4); err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if _, err := s.Refund(ctx, intent.ID, 7); !errors.Is(err, ErrRefundExceeds) {
+		t.Errorf("Refund() over captured error = %v, want ErrRefundExceeds", err)
+	}
+	if _, err := s.Refund(ctx, intent.ID, 6); err != nil {
+		t.Fatalf("Refund() remaining error = %v", err)
+	}
+	if _, err := s.Refund(ctx, intent.ID, 1); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Refund() after full refund error = %v, want ErrInvalidState", err)
+	}
+	if _, err := s.CreateIntent(ctx, &IntentRequest{Amount: 0}); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("CreateIntent(0) error = %v, want ErrInvalidAmount", err)
+	}
+}
+
+func TestSandbox_Webhook(t *testing.T) {
+	ctx := context.Background()
+	s := NewSandbox("secret")
+	intent, _ := s.CreateIntent(ctx, &IntentRequest{Reference: "spike_order:7", Amount: 10})
+	event, _ := s.Authorize(ctx, intent.ID, 0)
+
+	payload, signature, err := s.SignWebhook(event)
+	if err != nil {
+		t.Fatalf("SignWebhook() error = %v", err)
+	}
+	verified, err := s.VerifyWebhook(payload, signature)
+	if err != nil || verified.ID != event.ID || verified.Intent.Reference != "spike_order:7" {
+		t.Errorf("VerifyWebhook() = %+v, %v", verified, err)
+	}
+	if _, err := NewSandbox("other").VerifyWebhook(payload, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyWebhook() with other secret error = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := s.VerifyWebhook(append(payload, ' '), signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyWebhook() with tampered payload error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSandbox_Charge(t *testing.T) {
+	ctx := context.Background()
+	s := NewSandbox("secret")
+
+	if _, err := s.Charge(ctx, &IntentRequest{Amount: 10}, ""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Charge() without token error = %v, want ErrInvalidToken", err)
+	}
+	for amount, want := range map[float64]IntentStatus{10: IntentStatusSucceeded, 10.01: IntentStatusFailed, 10.02: IntentStatusFailed} {
+		intent, err := s.Charge(ctx, &IntentRequest{Amount: amount}, "tok")
+		if err != nil || intent.Status != want {
+			t.Errorf("Charge(%.2f) = %+v, %v, want %s", amount, intent, err, want)
+		}
+	}
+}
+
+func TestSandbox_AuthorizeByPayer(t *testing.T) {
+	ctx := context.Background()
+	s := NewSandbox("")
+	first, _ := s.CreateIntent(ctx, &IntentRequest{Amount: 10, UserID: 1})
+	second, _ := s.CreateIntent(ctx, &IntentRequest{Amount: 10, UserID: 1})
+	if first.ID == second.ID {
+		t.Fatalf("intent ids collide: %s", first.ID)
+	}
+
+	if _, err := s.Authorize(ctx, first.ID, 2); !errors.Is(err, ErrIntentNotFound) {
+		t.Errorf("Authorize() by other user error = %v, want ErrIntentNotFound", err)
+	}
+	event, err := s.Authorize(ctx, first.ID, 1)
+	if err != nil {
+		t.Fatalf("Authorize() by payer error = %v", err)
+	}
+	// 未配置密钥时使用随机密钥，其他实例签发的回调无法通过校验
+	payload, signature, _ := s.SignWebhook(event)
+	if _, err := NewSandbox("").VerifyWebhook(payload, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyWebhook() with other random secret error = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,266 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// AbandonedCheckoutRepository 定义弃单召回数据访问接口
+type AbandonedCheckoutRepository interface {
+	// Create 记录弃单，同一秒杀订单重复记录时返回已有记录的ID
+	Create(checkout *domain.AbandonedCheckout) error
+	GetByID(id int64) (*domain.AbandonedCheckout, error)
+	// List 分页查询；req.SkipTotal 为 true 时不统计总数（返回 domain.TotalNotCounted），并多取一行供调用方判断是否还有下一页
+	List(req *domain.AbandonedCheckoutListRequest) ([]*domain.AbandonedCheckout, int64, error)
+	// Summarize 按与 List 相同的过滤条件汇总弃单、召回与转化数量
+	Summarize(req *domain.AbandonedCheckoutListRequest) (*domain.AbandonedCheckoutSummary, error)
+	// MarkContacted 记录召回活动与发放的优惠券
+	MarkContacted(id int64, campaignID, couponCode string, contactedAt time.Time) error
+	// MarkRecovered 将用户在该召回活动下最近一笔未转化的弃单标记为已转化，返回是否有记录被标记
+	MarkRecovered(userID int64, campaignID string, recoveredOrderID int64, recoveredAt time.Time) (bool, error)
+}
+
+// abandonedCheckoutRepo 实现AbandonedCheckoutRepository接口
+type abandonedCheckoutRepo struct {
+	db *sql.DB
+}
+
+// NewAbandonedCheckoutRepository 创建弃单召回仓储实例
+func NewAbandonedCheckoutRepository(db *sql.DB) AbandonedCheckoutRepository {
+	return &abandonedCheckoutRepo{db: db}
+}
+
+const abandonedCheckoutColumns = `id, spike_order_id, spike_event_id, user_id, product_id, quantity, total_amount,
+	expired_at, campaign_id, coupon_code, contacted_at, recovered_order_id, recovered_at, created_at`
+
+// Create 记录弃单
+func (r *abandonedCheckoutRepo) Create(checkout *domain.AbandonedCheckout) error {
+	// 消息重复投递时不重复记录，LAST_INSERT_ID(id) 使重复时也能拿到已有记录的ID
+	query := `
+		INSERT INTO abandoned_checkouts (spike_order_id, spike_event_id, user_id, product_id, quantity,
+			total_amount, expired_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+	`
+
+	result, err := r.db.Exec(query,
+		checkout.SpikeOrderID,
+		checkout.SpikeEventID,
+		checkout.UserID,
+		checkout.ProductID,
+		checkout.Quantity,
+		checkout.TotalAmount,
+		checkout.ExpiredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create abandoned checkout: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	checkout.ID = id
+	return nil
+}
+
+// GetByID 根据ID获取弃单
+func (r *abandonedCheckoutRepo) GetByID(id int64) (*domain.AbandonedCheckout, error) {
+	query := `SELECT ` + abandonedCheckoutColumns + ` FROM abandoned_checkouts WHERE id = ?`
+
+	checkout, err := scanAbandonedCheckout(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAbandonedCheckoutNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get abandoned checkout: %w", err)
+	}
+	return checkout, nil
+}
+
+// List 分页查询弃单，按过期时间倒序
+func (r *abandonedCheckoutRepo) List(req *domain.AbandonedCheckoutListRequest) ([]*domain.AbandonedCheckout, int64, error) {
+	whereClause, args := abandonedCheckoutFilter(req)
+
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM abandoned_checkouts %s", whereClause)
+		if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count abandoned checkouts: %w", err)
+		}
+	}
+
+	// 分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM abandoned_checkouts %s
+		ORDER BY expired_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, abandonedCheckoutColumns, whereClause)
+
+	args = append(args, domain.LimitWithLookahead(req.PageSize, req.SkipTotal), offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query abandoned checkouts: %w", err)
+	}
+	defer rows.Close()
+
+	var checkouts []*domain.AbandonedCheckout
+	for rows.Next() {
+		checkout, err := scanAbandonedCheckout(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan abandoned checkout: %w", err)
+		}
+		checkouts = append(checkouts, checkout)
+	}
+
+	return checkouts, total, rows.Err()
+}
+
+// Summarize 汇总弃单召回转化情况
+func (r *abandonedCheckoutRepo) Summarize(req *domain.AbandonedCheckoutListRequest) (*domain.AbandonedCheckoutSummary, error) {
+	whereClause, args := abandonedCheckoutFilter(req)
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*),
+			COALESCE(SUM(total_amount), 0),
+			COALESCE(SUM(CASE WHEN campaign_id <> '' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN recovered_order_id IS NOT NULL THEN 1 ELSE 0 END), 0)
+		FROM abandoned_checkouts %s
+	`, whereClause)
+
+	summary := &domain.AbandonedCheckoutSummary{}
+	err := r.db.QueryRow(query, args...).Scan(
+		&summary.Abandoned,
+		&summary.AbandonedValue,
+		&summary.Contacted,
+		&summary.Recovered,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize abandoned checkouts: %w", err)
+	}
+
+	if summary.Contacted > 0 {
+		summary.ConversionRate = float64(summary.Recovered) / float64(summary.Contacted)
+	}
+	return summary, nil
+}
+
+// MarkContacted 记录召回活动
+func (r *abandonedCheckoutRepo) MarkContacted(id int64, campaignID, couponCode string, contactedAt time.Time) error {
+	query := `UPDATE abandoned_checkouts SET campaign_id = ?, coupon_code = ?, contacted_at = ? WHERE id = ?`
+
+	result, err := r.db.Exec(query, campaignID, couponCode, contactedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark abandoned checkout contacted: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrAbandonedCheckoutNotFound
+	}
+
+	return nil
+}
+
+// MarkRecovered 标记召回转化
+func (r *abandonedCheckoutRepo) MarkRecovered(userID int64, campaignID string, recoveredOrderID int64, recoveredAt time.Time) (bool, error) {
+	query := `
+		UPDATE abandoned_checkouts SET recovered_order_id = ?, recovered_at = ?
+		WHERE user_id = ? AND campaign_id = ? AND recovered_order_id IS NULL
+		ORDER BY expired_at DESC
+		LIMIT 1
+	`
+
+	result, err := r.db.Exec(query, recoveredOrderID, recoveredAt, userID, campaignID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark abandoned checkout recovered: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// abandonedCheckoutFilter 构建弃单查询的WHERE条件
+func abandonedCheckoutFilter(req *domain.AbandonedCheckoutListRequest) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if req.SpikeEventID != nil {
+		conditions = append(conditions, "spike_event_id = ?")
+		args = append(args, *req.SpikeEventID)
+	}
+	if req.CampaignID != nil {
+		conditions = append(conditions, "campaign_id = ?")
+		args = append(args, *req.CampaignID)
+	}
+	if req.Recovered != nil {
+		if *req.Recovered {
+			conditions = append(conditions, "recovered_order_id IS NOT NULL")
+		} else {
+			conditions = append(conditions, "recovered_order_id IS NULL")
+		}
+	}
+	if req.From != nil {
+		conditions = append(conditions, "expired_at >= ?")
+		args = append(args, *req.From)
+	}
+	if req.To != nil {
+		conditions = append(conditions, "expired_at < ?")
+		args = append(args, *req.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// abandonedCheckoutScanner 兼容 *sql.Row 与 *sql.Rows
+type abandonedCheckoutScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAbandonedCheckout 按 abandonedCheckoutColumns 的列顺序扫描一行
+func scanAbandonedCheckout(s abandonedCheckoutScanner) (*domain.AbandonedCheckout, error) {
+	checkout := &domain.AbandonedCheckout{}
+	err := s.Scan(
+		&checkout.ID,
+		&checkout.SpikeOrderID,
+		&checkout.SpikeEventID,
+		&checkout.UserID,
+		&checkout.ProductID,
+		&checkout.Quantity,
+		&checkout.TotalAmount,
+		&checkout.ExpiredAt,
+		&checkout.CampaignID,
+		&checkout.CouponCode,
+		&checkout.ContactedAt,
+		&checkout.RecoveredOrderID,
+		&checkout.RecoveredAt,
+		&checkout.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return checkout, nil
+}
@@ -0,0 +1,175 @@
+// Package repo 实现管理员操作审计数据访问层，负责与数据库的交互。
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// AdminAuditRepository 定义管理员操作审计数据访问接口
+type AdminAuditRepository interface {
+	// Create 写入审计记录
+	Create(log *domain.AdminAuditLog) error
+	// List 分页查询审计记录，按操作时间倒序
+	List(req *domain.AdminAuditListRequest) ([]*domain.AdminAuditLog, int64, error)
+}
+
+// adminAuditColumns 审计记录查询列，与 List 的扫描顺序一致
+const adminAuditColumns = `id, actor, action, entity_type, entity_id, before_data, after_data,
+	status_code, request_id, client_ip, created_at`
+
+// adminAuditRepo 实现AdminAuditRepository接口
+type adminAuditRepo struct {
+	db *sql.DB
+}
+
+// NewAdminAuditRepository 创建管理员操作审计仓储实例
+func NewAdminAuditRepository(db *sql.DB) AdminAuditRepository {
+	return &adminAuditRepo{db: db}
+}
+
+// Create 写入审计记录
+func (r *adminAuditRepo) Create(log *domain.AdminAuditLog) error {
+	query := `
+		INSERT INTO admin_audit_logs (actor, action, entity_type, entity_id, before_data, after_data,
+			status_code, request_id, client_ip)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		log.Actor,
+		log.Action,
+		log.EntityType,
+		log.EntityID,
+		nullJSON(log.Before),
+		nullJSON(log.After),
+		log.StatusCode,
+		log.RequestID,
+		log.ClientIP,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create admin audit log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	log.ID = id
+	return nil
+}
+
+// List 分页查询审计记录
+func (r *adminAuditRepo) List(req *domain.AdminAuditListRequest) ([]*domain.AdminAuditLog, int64, error) {
+	whereClause, args := adminAuditFilter(req)
+
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM admin_audit_logs %s", whereClause)
+		if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count admin audit logs: %w", err)
+		}
+	}
+
+	// 分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM admin_audit_logs %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, adminAuditColumns, whereClause)
+
+	args = append(args, domain.LimitWithLookahead(req.PageSize, req.SkipTotal), offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query admin audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*domain.AdminAuditLog
+	for rows.Next() {
+		var log domain.AdminAuditLog
+		var before, after []byte
+		err := rows.Scan(
+			&log.ID,
+			&log.Actor,
+			&log.Action,
+			&log.EntityType,
+			&log.EntityID,
+			&before,
+			&after,
+			&log.StatusCode,
+			&log.RequestID,
+			&log.ClientIP,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan admin audit log: %w", err)
+		}
+		log.Before = before
+		log.After = after
+		logs = append(logs, &log)
+	}
+
+	return logs, total, rows.Err()
+}
+
+// adminAuditFilter 根据查询条件构造 WHERE 子句
+func adminAuditFilter(req *domain.AdminAuditListRequest) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if req.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, req.Actor)
+	}
+	if req.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, req.Action)
+	}
+	if req.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, req.EntityType)
+	}
+	if req.EntityID != "" {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, req.EntityID)
+	}
+	if req.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, req.RequestID)
+	}
+	if req.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *req.From)
+	}
+	if req.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *req.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// nullJSON 空 JSON 写入 NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,96 @@
+// Package repo 实现数据回填进度的数据访问层，负责与数据库的交互。
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// BackfillProgressRepository 定义回填进度数据访问接口
+type BackfillProgressRepository interface {
+	// Get 获取任务进度，不存在时返回 nil, nil
+	Get(jobName string) (*domain.BackfillProgress, error)
+	// Save 新建或更新任务进度
+	Save(progress *domain.BackfillProgress) error
+	// Delete 删除任务进度，用于从头重跑
+	Delete(jobName string) error
+}
+
+// backfillProgressRepo 实现BackfillProgressRepository接口
+type backfillProgressRepo struct {
+	db *sql.DB
+}
+
+// NewBackfillProgressRepository 创建回填进度仓储实例
+func NewBackfillProgressRepository(db *sql.DB) BackfillProgressRepository {
+	return &backfillProgressRepo{db: db}
+}
+
+// Get 获取任务进度
+func (r *backfillProgressRepo) Get(jobName string) (*domain.BackfillProgress, error) {
+	query := `
+		SELECT job_name, last_id, processed_count, status, last_error, started_at, updated_at, completed_at
+		FROM backfill_progress
+		WHERE job_name = ?
+	`
+
+	progress := &domain.BackfillProgress{}
+	err := r.db.QueryRow(query, jobName).Scan(
+		&progress.JobName,
+		&progress.LastID,
+		&progress.ProcessedCount,
+		&progress.Status,
+		&progress.LastError,
+		&progress.StartedAt,
+		&progress.UpdatedAt,
+		&progress.CompletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill progress: %w", err)
+	}
+
+	return progress, nil
+}
+
+// Save 新建或更新任务进度
+func (r *backfillProgressRepo) Save(progress *domain.BackfillProgress) error {
+	query := `
+		INSERT INTO backfill_progress (job_name, last_id, processed_count, status, last_error, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			last_id = VALUES(last_id),
+			processed_count = VALUES(processed_count),
+			status = VALUES(status),
+			last_error = VALUES(last_error),
+			completed_at = VALUES(completed_at)
+	`
+
+	_, err := r.db.Exec(query,
+		progress.JobName,
+		progress.LastID,
+		progress.ProcessedCount,
+		progress.Status,
+		progress.LastError,
+		progress.CompletedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save backfill progress: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 删除任务进度
+func (r *backfillProgressRepo) Delete(jobName string) error {
+	_, err := r.db.Exec(`DELETE FROM backfill_progress WHERE job_name = ?`, jobName)
+	if err != nil {
+		return fmt.Errorf("failed to delete backfill progress: %w", err)
+	}
+	return nil
+}
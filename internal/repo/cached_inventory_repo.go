@@ -3,6 +3,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,23 +28,23 @@ func NewCachedInventoryRepository(repo InventoryRepository, cache cache.Cache, t
 }
 
 // Create 创建库存记录（清除相关缓存）
-func (r *CachedInventoryRepository) Create(inventory *domain.Inventory) error {
-	err := r.repo.Create(inventory)
+func (r *CachedInventoryRepository) Create(ctx context.Context, inventory *domain.Inventory) error {
+	err := r.repo.Create(ctx, inventory)
 	if err != nil {
 		return err
 	}
 
-	// 清除相关缓存
-	ctx := context.Background()
+	// 清除相关缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryCacheKey(inventory.ID))
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(inventory.ProductID))
+	r.cache.Del(ctx, ProductDetailCacheKey(inventory.ProductID))
 
 	return nil
 }
 
 // GetByID 根据ID获取库存（带缓存）
-func (r *CachedInventoryRepository) GetByID(id int64) (*domain.Inventory, error) {
-	ctx := context.Background()
+func (r *CachedInventoryRepository) GetByID(ctx context.Context, id int64) (*domain.Inventory, error) {
 	cacheKey := r.getInventoryCacheKey(id)
 
 	// 尝试从缓存获取
@@ -54,7 +55,7 @@ func (r *CachedInventoryRepository) GetByID(id int64) (*domain.Inventory, error)
 	}
 
 	// 缓存未命中，从数据库获取
-	result, err := r.repo.GetByID(id)
+	result, err := r.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -69,8 +70,7 @@ func (r *CachedInventoryRepository) GetByID(id int64) (*domain.Inventory, error)
 }
 
 // GetByProductID 根据商品ID获取库存（带缓存）
-func (r *CachedInventoryRepository) GetByProductID(productID int64) (*domain.Inventory, error) {
-	ctx := context.Background()
+func (r *CachedInventoryRepository) GetByProductID(ctx context.Context, productID int64) (*domain.Inventory, error) {
 	cacheKey := r.getInventoryProductCacheKey(productID)
 
 	// 尝试从缓存获取
@@ -81,7 +81,7 @@ func (r *CachedInventoryRepository) GetByProductID(productID int64) (*domain.Inv
 	}
 
 	// 缓存未命中，从数据库获取
-	result, err := r.repo.GetByProductID(productID)
+	result, err := r.repo.GetByProductID(ctx, productID)
 	if err != nil {
 		return nil, err
 	}
@@ -98,61 +98,70 @@ func (r *CachedInventoryRepository) GetByProductID(productID int64) (*domain.Inv
 }
 
 // Update 更新库存（清除相关缓存）
-func (r *CachedInventoryRepository) Update(inventory *domain.Inventory) error {
-	err := r.repo.Update(inventory)
+func (r *CachedInventoryRepository) Update(ctx context.Context, inventory *domain.Inventory) error {
+	err := r.repo.Update(ctx, inventory)
 	if err != nil {
 		return err
 	}
 
-	// 清除相关缓存
-	ctx := context.Background()
+	// 清除相关缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryCacheKey(inventory.ID))
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(inventory.ProductID))
+	r.cache.Del(ctx, ProductDetailCacheKey(inventory.ProductID))
 
 	return nil
 }
 
 // UpdateWithVersion 使用乐观锁更新库存（清除相关缓存）
-func (r *CachedInventoryRepository) UpdateWithVersion(inventory *domain.Inventory) error {
-	err := r.repo.UpdateWithVersion(inventory)
+func (r *CachedInventoryRepository) UpdateWithVersion(ctx context.Context, inventory *domain.Inventory) error {
+	err := r.repo.UpdateWithVersion(ctx, inventory)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		// 缓存中的版本可能已过期，清除后重试会读到最新版本
+		ctx = context.WithoutCancel(ctx)
+		r.cache.Del(ctx, r.getInventoryCacheKey(inventory.ID))
+		r.cache.Del(ctx, r.getInventoryProductCacheKey(inventory.ProductID))
+		return err
+	}
 	if err != nil {
 		return err
 	}
 
-	// 清除相关缓存
-	ctx := context.Background()
+	// 清除相关缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryCacheKey(inventory.ID))
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(inventory.ProductID))
+	r.cache.Del(ctx, ProductDetailCacheKey(inventory.ProductID))
 
 	return nil
 }
 
 // Delete 删除库存记录（清除相关缓存）
-func (r *CachedInventoryRepository) Delete(id int64) error {
+func (r *CachedInventoryRepository) Delete(ctx context.Context, id int64) error {
 	// 先获取库存信息以便清除商品缓存
-	inventory, err := r.repo.GetByID(id)
+	inventory, err := r.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	err = r.repo.Delete(id)
+	err = r.repo.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	// 清除相关缓存
-	ctx := context.Background()
+	// 清除相关缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryCacheKey(id))
 	if inventory != nil {
 		r.cache.Del(ctx, r.getInventoryProductCacheKey(inventory.ProductID))
+		r.cache.Del(ctx, ProductDetailCacheKey(inventory.ProductID))
 	}
 
 	return nil
 }
 
 // GetByProductIDs 批量获取库存（部分缓存）
-func (r *CachedInventoryRepository) GetByProductIDs(productIDs []int64) ([]*domain.Inventory, error) {
-	ctx := context.Background()
+func (r *CachedInventoryRepository) GetByProductIDs(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error) {
 	var cachedInventories []*domain.Inventory
 	var missingProductIDs []int64
 
@@ -173,7 +182,7 @@ func (r *CachedInventoryRepository) GetByProductIDs(productIDs []int64) ([]*doma
 	}
 
 	// 从数据库获取未缓存的数据
-	dbInventories, err := r.repo.GetByProductIDs(missingProductIDs)
+	dbInventories, err := r.repo.GetByProductIDs(ctx, missingProductIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -190,97 +199,102 @@ func (r *CachedInventoryRepository) GetByProductIDs(productIDs []int64) ([]*doma
 }
 
 // BatchUpdateStock 批量更新库存（清除相关缓存）
-func (r *CachedInventoryRepository) BatchUpdateStock(updates []StockUpdate) error {
-	err := r.repo.BatchUpdateStock(updates)
+func (r *CachedInventoryRepository) BatchUpdateStock(ctx context.Context, updates []StockUpdate) error {
+	err := r.repo.BatchUpdateStock(ctx, updates)
 	if err != nil {
 		return err
 	}
 
-	// 清除相关缓存
-	ctx := context.Background()
+	// 清除相关缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	for _, update := range updates {
 		r.cache.Del(ctx, r.getInventoryProductCacheKey(update.ProductID))
+		r.cache.Del(ctx, ProductDetailCacheKey(update.ProductID))
 	}
 
 	return nil
 }
 
 // List 获取库存列表（不缓存，因为参数组合太多）
-func (r *CachedInventoryRepository) List(req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
-	return r.repo.List(req)
+func (r *CachedInventoryRepository) List(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
+	return r.repo.List(ctx, req)
 }
 
 // GetLowStockProducts 获取低库存商品（不缓存）
-func (r *CachedInventoryRepository) GetLowStockProducts() ([]*domain.Inventory, error) {
-	return r.repo.GetLowStockProducts()
+func (r *CachedInventoryRepository) GetLowStockProducts(ctx context.Context) ([]*domain.Inventory, error) {
+	return r.repo.GetLowStockProducts(ctx)
 }
 
 // 库存操作方法（清除相关缓存）
 
 // ReserveStock 预留库存
-func (r *CachedInventoryRepository) ReserveStock(productID int64, quantity int) error {
-	err := r.repo.ReserveStock(productID, quantity)
+func (r *CachedInventoryRepository) ReserveStock(ctx context.Context, productID int64, quantity int) error {
+	err := r.repo.ReserveStock(ctx, productID, quantity)
 	if err != nil {
 		return err
 	}
 
-	// 清除缓存
-	ctx := context.Background()
+	// 清除缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(productID))
+	r.cache.Del(ctx, ProductDetailCacheKey(productID))
 
 	return nil
 }
 
 // ReleaseStock 释放预留库存
-func (r *CachedInventoryRepository) ReleaseStock(productID int64, quantity int) error {
-	err := r.repo.ReleaseStock(productID, quantity)
+func (r *CachedInventoryRepository) ReleaseStock(ctx context.Context, productID int64, quantity int) error {
+	err := r.repo.ReleaseStock(ctx, productID, quantity)
 	if err != nil {
 		return err
 	}
 
-	// 清除缓存
-	ctx := context.Background()
+	// 清除缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(productID))
+	r.cache.Del(ctx, ProductDetailCacheKey(productID))
 
 	return nil
 }
 
 // ConsumeStock 消费库存
-func (r *CachedInventoryRepository) ConsumeStock(productID int64, quantity int) error {
-	err := r.repo.ConsumeStock(productID, quantity)
+func (r *CachedInventoryRepository) ConsumeStock(ctx context.Context, productID int64, quantity int) error {
+	err := r.repo.ConsumeStock(ctx, productID, quantity)
 	if err != nil {
 		return err
 	}
 
-	// 清除缓存
-	ctx := context.Background()
+	// 清除缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(productID))
+	r.cache.Del(ctx, ProductDetailCacheKey(productID))
 
 	return nil
 }
 
 // AdjustStock 调整库存
-func (r *CachedInventoryRepository) AdjustStock(productID int64, quantity int, reason string) error {
-	err := r.repo.AdjustStock(productID, quantity, reason)
+func (r *CachedInventoryRepository) AdjustStock(ctx context.Context, productID int64, quantity int, reason string) error {
+	err := r.repo.AdjustStock(ctx, productID, quantity, reason)
 	if err != nil {
 		return err
 	}
 
-	// 清除缓存
-	ctx := context.Background()
+	// 清除缓存（数据已写入，不随请求取消）
+	ctx = context.WithoutCancel(ctx)
 	r.cache.Del(ctx, r.getInventoryProductCacheKey(productID))
+	r.cache.Del(ctx, ProductDetailCacheKey(productID))
 
 	return nil
 }
 
 // Count 获取库存记录总数（不缓存）
-func (r *CachedInventoryRepository) Count() (int64, error) {
-	return r.repo.Count()
+func (r *CachedInventoryRepository) Count(ctx context.Context) (int64, error) {
+	return r.repo.Count(ctx)
 }
 
 // GetTotalStockValue 获取总库存价值（不缓存）
-func (r *CachedInventoryRepository) GetTotalStockValue() (float64, error) {
-	return r.repo.GetTotalStockValue()
+func (r *CachedInventoryRepository) GetTotalStockValue(ctx context.Context) (float64, error) {
+	return r.repo.GetTotalStockValue(ctx)
 }
 
 // 缓存键生成方法
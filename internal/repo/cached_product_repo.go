@@ -109,6 +109,7 @@ func (r *CachedProductRepository) Update(product *domain.Product) error {
 	ctx := context.Background()
 	r.cache.Del(ctx, r.getProductCacheKey(product.ID))
 	r.cache.Del(ctx, r.getProductSKUCacheKey(product.SKU))
+	r.cache.Del(ctx, ProductDetailCacheKey(product.ID))
 
 	return nil
 }
@@ -129,6 +130,7 @@ func (r *CachedProductRepository) Delete(id int64) error {
 	// 清除相关缓存
 	ctx := context.Background()
 	r.cache.Del(ctx, r.getProductCacheKey(id))
+	r.cache.Del(ctx, ProductDetailCacheKey(id))
 	if product != nil {
 		r.cache.Del(ctx, r.getProductSKUCacheKey(product.SKU))
 	}
@@ -197,3 +199,8 @@ func (r *CachedProductRepository) getProductCacheKey(id int64) string {
 func (r *CachedProductRepository) getProductSKUCacheKey(sku string) string {
 	return fmt.Sprintf("product:sku:%s", sku)
 }
+
+// ProductDetailCacheKey 商品详情聚合缓存键，商品或库存变更时由缓存仓储一并清除
+func ProductDetailCacheKey(productID int64) string {
+	return fmt.Sprintf("product:detail:%d", productID)
+}
@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// CartRepository 定义购物车数据访问接口
+type CartRepository interface {
+	// ListByUser 获取用户购物车条目，按加入时间排序
+	ListByUser(ctx context.Context, userID int64) ([]*domain.CartItem, error)
+	// Add 加入购物车，商品已在购物车中时累加数量，返回累加后的数量
+	Add(ctx context.Context, userID, productID int64, quantity int) (int, error)
+	// UpdateQuantity 修改购物车商品数量，商品不在购物车中时返回 domain.ErrCartItemNotFound
+	UpdateQuantity(ctx context.Context, userID, productID int64, quantity int) error
+	// Remove 从购物车移除商品，不在购物车中的商品忽略，返回移除的条目数
+	Remove(ctx context.Context, userID int64, productIDs ...int64) (int64, error)
+	// Count 统计用户购物车中的商品种类数
+	Count(ctx context.Context, userID int64) (int, error)
+}
+
+// cartRepo 实现CartRepository接口
+type cartRepo struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewCartRepository 创建购物车仓储实例
+func NewCartRepository(db *sql.DB, opts ...Option) CartRepository {
+	return &cartRepo{db: db, queryTimeout: newQueryTimeout(opts)}
+}
+
+// ListByUser 获取用户购物车条目。购物车读后即写的场景多，始终读主库
+func (r *cartRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.CartItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, product_id, quantity, created_at, updated_at
+		FROM cart_items
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cart items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*domain.CartItem
+	for rows.Next() {
+		item := &domain.CartItem{}
+		if err := rows.Scan(&item.ID, &item.UserID, &item.ProductID, &item.Quantity, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cart items: %w", err)
+	}
+
+	return items, nil
+}
+
+// Add 加入购物车
+func (r *cartRepo) Add(ctx context.Context, userID, productID int64, quantity int) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO cart_items (user_id, product_id, quantity)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity)
+	`, userID, productID, quantity); err != nil {
+		return 0, fmt.Errorf("failed to add cart item: %w", err)
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT quantity FROM cart_items WHERE user_id = ? AND product_id = ?`,
+		userID, productID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get cart item quantity: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return total, nil
+}
+
+// UpdateQuantity 修改购物车商品数量
+func (r *cartRepo) UpdateQuantity(ctx context.Context, userID, productID int64, quantity int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE cart_items SET quantity = ? WHERE user_id = ? AND product_id = ?`,
+		quantity, userID, productID)
+	if err != nil {
+		return fmt.Errorf("failed to update cart item: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// 数量未变化时 MySQL 也返回 0 行受影响，需确认条目是否存在
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM cart_items WHERE user_id = ? AND product_id = ?)`,
+		userID, productID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check cart item: %w", err)
+	}
+	if !exists {
+		return domain.ErrCartItemNotFound
+	}
+	return nil
+}
+
+// Remove 从购物车移除商品
+func (r *cartRepo) Remove(ctx context.Context, userID int64, productIDs ...int64) (int64, error) {
+	if len(productIDs) == 0 {
+		return 0, nil
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	// 构建IN子句
+	placeholders := strings.Repeat("?,", len(productIDs)-1) + "?"
+	args := []interface{}{userID}
+	for _, id := range productIDs {
+		args = append(args, id)
+	}
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM cart_items WHERE user_id = ? AND product_id IN (%s)`, placeholders), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove cart items: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return removed, nil
+}
+
+// Count 统计用户购物车中的商品种类数
+func (r *cartRepo) Count(ctx context.Context, userID int64) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM cart_items WHERE user_id = ?`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cart items: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,96 @@
+// Package repo 实现客服代操作审计数据访问层，负责与数据库的交互。
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// ImpersonationAuditRepository 定义代操作审计数据访问接口
+type ImpersonationAuditRepository interface {
+	Create(audit *domain.ImpersonationAudit) error
+	ListByTargetUserID(userID int64, limit int) ([]*domain.ImpersonationAudit, error)
+}
+
+// impersonationAuditRepo 实现ImpersonationAuditRepository接口
+type impersonationAuditRepo struct {
+	db *sql.DB
+}
+
+// NewImpersonationAuditRepository 创建代操作审计仓储实例
+func NewImpersonationAuditRepository(db *sql.DB) ImpersonationAuditRepository {
+	return &impersonationAuditRepo{db: db}
+}
+
+// Create 写入审计记录
+func (r *impersonationAuditRepo) Create(audit *domain.ImpersonationAudit) error {
+	query := `
+		INSERT INTO impersonation_audits (admin_id, target_user_id, scope, reason, client_ip, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		audit.AdminID,
+		audit.TargetUserID,
+		audit.Scope,
+		audit.Reason,
+		audit.ClientIP,
+		audit.ExpiresAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create impersonation audit: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	audit.ID = id
+	return nil
+}
+
+// ListByTargetUserID 获取某用户最近被代操作的记录
+func (r *impersonationAuditRepo) ListByTargetUserID(userID int64, limit int) ([]*domain.ImpersonationAudit, error) {
+	query := `
+		SELECT id, admin_id, target_user_id, scope, reason, client_ip, expires_at, created_at
+		FROM impersonation_audits
+		WHERE target_user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list impersonation audits: %w", err)
+	}
+	defer rows.Close()
+
+	var audits []*domain.ImpersonationAudit
+	for rows.Next() {
+		audit := &domain.ImpersonationAudit{}
+		err := rows.Scan(
+			&audit.ID,
+			&audit.AdminID,
+			&audit.TargetUserID,
+			&audit.Scope,
+			&audit.Reason,
+			&audit.ClientIP,
+			&audit.ExpiresAt,
+			&audit.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan impersonation audit: %w", err)
+		}
+		audits = append(audits, audit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate impersonation audits: %w", err)
+	}
+
+	return audits, nil
+}
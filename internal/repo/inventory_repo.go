@@ -2,67 +2,93 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/go-sql-driver/mysql"
+
 	"github.com/MorseWayne/spike_shop/internal/domain"
 )
 
 // InventoryRepository 定义库存数据访问接口
 type InventoryRepository interface {
 	// 基本CRUD操作
-	Create(inventory *domain.Inventory) error
-	GetByID(id int64) (*domain.Inventory, error)
-	GetByProductID(productID int64) (*domain.Inventory, error)
-	Update(inventory *domain.Inventory) error
-	UpdateWithVersion(inventory *domain.Inventory) error // 乐观锁更新
-	Delete(id int64) error
+	Create(ctx context.Context, inventory *domain.Inventory) error
+	GetByID(ctx context.Context, id int64) (*domain.Inventory, error)
+	GetByProductID(ctx context.Context, productID int64) (*domain.Inventory, error)
+	Update(ctx context.Context, inventory *domain.Inventory) error
+	UpdateWithVersion(ctx context.Context, inventory *domain.Inventory) error // 乐观锁更新
+	Delete(ctx context.Context, id int64) error
 
 	// 批量操作
-	GetByProductIDs(productIDs []int64) ([]*domain.Inventory, error)
-	BatchUpdateStock(updates []StockUpdate) error
+	GetByProductIDs(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error)
+	BatchUpdateStock(ctx context.Context, updates []StockUpdate) error
 
 	// 查询操作
-	List(req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error)
-	GetLowStockProducts() ([]*domain.Inventory, error)
+	// List 分页查询；req.SkipTotal 为 true 时不统计总数（返回 domain.TotalNotCounted），并多取一行供调用方判断是否还有下一页
+	List(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error)
+	GetLowStockProducts(ctx context.Context) ([]*domain.Inventory, error)
 
 	// 库存操作
-	ReserveStock(productID int64, quantity int) error
-	ReleaseStock(productID int64, quantity int) error
-	ConsumeStock(productID int64, quantity int) error
-	AdjustStock(productID int64, quantity int, reason string) error
+	ReserveStock(ctx context.Context, productID int64, quantity int) error
+	ReleaseStock(ctx context.Context, productID int64, quantity int) error
+	ConsumeStock(ctx context.Context, productID int64, quantity int) error
+	AdjustStock(ctx context.Context, productID int64, quantity int, reason string) error
 
 	// 统计操作
-	Count() (int64, error)
-	GetTotalStockValue() (float64, error)
+	Count(ctx context.Context) (int64, error)
+	GetTotalStockValue(ctx context.Context) (float64, error)
 }
 
 // StockUpdate 表示批量库存更新项
 type StockUpdate struct {
 	ProductID int64
-	Quantity  int
+	Quantity  int    // adjust 时为调整量，正数入库、负数出库
 	Type      string // "reserve", "release", "consume", "adjust"
+	Reason    string // 调整原因
+}
+
+// StockUpdateError 表示批量更新中某一项失败，整个事务已回滚
+type StockUpdateError struct {
+	Index     int   // 失败项在 updates 中的下标
+	ProductID int64 // 失败项的商品ID
+	Err       error
+}
+
+func (e *StockUpdateError) Error() string {
+	return fmt.Sprintf("stock update %d (product %d) failed: %v", e.Index, e.ProductID, e.Err)
+}
+
+func (e *StockUpdateError) Unwrap() error {
+	return e.Err
 }
 
 // inventoryRepo 实现InventoryRepository接口
 type inventoryRepo struct {
 	db *sql.DB
+	queryTimeout
+	readRouting
 }
 
 // NewInventoryRepository 创建库存仓储实例
-func NewInventoryRepository(db *sql.DB) InventoryRepository {
-	return &inventoryRepo{db: db}
+func NewInventoryRepository(db *sql.DB, opts ...Option) InventoryRepository {
+	return &inventoryRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
 }
 
 // Create 创建库存记录
-func (r *inventoryRepo) Create(inventory *domain.Inventory) error {
+func (r *inventoryRepo) Create(ctx context.Context, inventory *domain.Inventory) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO inventory (product_id, stock, reserved_stock, sold_stock, reorder_point, max_stock)
 		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		inventory.ProductID,
 		inventory.Stock,
 		inventory.ReservedStock,
@@ -84,7 +110,10 @@ func (r *inventoryRepo) Create(inventory *domain.Inventory) error {
 }
 
 // GetByID 根据ID获取库存
-func (r *inventoryRepo) GetByID(id int64) (*domain.Inventory, error) {
+func (r *inventoryRepo) GetByID(ctx context.Context, id int64) (*domain.Inventory, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, stock, reserved_stock, sold_stock, reorder_point, max_stock, version, created_at, updated_at
 		FROM inventory 
@@ -92,7 +121,7 @@ func (r *inventoryRepo) GetByID(id int64) (*domain.Inventory, error) {
 	`
 
 	inventory := &domain.Inventory{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.reader(ctx).QueryRowContext(ctx, query, id).Scan(
 		&inventory.ID,
 		&inventory.ProductID,
 		&inventory.Stock,
@@ -116,7 +145,10 @@ func (r *inventoryRepo) GetByID(id int64) (*domain.Inventory, error) {
 }
 
 // GetByProductID 根据商品ID获取库存
-func (r *inventoryRepo) GetByProductID(productID int64) (*domain.Inventory, error) {
+func (r *inventoryRepo) GetByProductID(ctx context.Context, productID int64) (*domain.Inventory, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, stock, reserved_stock, sold_stock, reorder_point, max_stock, version, created_at, updated_at
 		FROM inventory 
@@ -124,7 +156,7 @@ func (r *inventoryRepo) GetByProductID(productID int64) (*domain.Inventory, erro
 	`
 
 	inventory := &domain.Inventory{}
-	err := r.db.QueryRow(query, productID).Scan(
+	err := r.reader(ctx).QueryRowContext(ctx, query, productID).Scan(
 		&inventory.ID,
 		&inventory.ProductID,
 		&inventory.Stock,
@@ -148,14 +180,17 @@ func (r *inventoryRepo) GetByProductID(productID int64) (*domain.Inventory, erro
 }
 
 // Update 更新库存
-func (r *inventoryRepo) Update(inventory *domain.Inventory) error {
+func (r *inventoryRepo) Update(ctx context.Context, inventory *domain.Inventory) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE inventory 
 		SET stock = ?, reserved_stock = ?, sold_stock = ?, reorder_point = ?, max_stock = ?, version = version + 1
 		WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		inventory.Stock,
 		inventory.ReservedStock,
 		inventory.SoldStock,
@@ -172,14 +207,17 @@ func (r *inventoryRepo) Update(inventory *domain.Inventory) error {
 }
 
 // UpdateWithVersion 使用乐观锁更新库存
-func (r *inventoryRepo) UpdateWithVersion(inventory *domain.Inventory) error {
+func (r *inventoryRepo) UpdateWithVersion(ctx context.Context, inventory *domain.Inventory) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE inventory 
 		SET stock = ?, reserved_stock = ?, sold_stock = ?, reorder_point = ?, max_stock = ?, version = version + 1
 		WHERE id = ? AND version = ?
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		inventory.Stock,
 		inventory.ReservedStock,
 		inventory.SoldStock,
@@ -190,6 +228,9 @@ func (r *inventoryRepo) UpdateWithVersion(inventory *domain.Inventory) error {
 	)
 
 	if err != nil {
+		if isLockConflict(err) {
+			return newLockConflictError(err)
+		}
 		return fmt.Errorf("failed to update inventory with version: %w", err)
 	}
 
@@ -199,7 +240,7 @@ func (r *inventoryRepo) UpdateWithVersion(inventory *domain.Inventory) error {
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("inventory version conflict or record not found")
+		return domain.NewVersionConflictError("inventory version conflict or record not found")
 	}
 
 	inventory.Version++
@@ -207,10 +248,13 @@ func (r *inventoryRepo) UpdateWithVersion(inventory *domain.Inventory) error {
 }
 
 // Delete 删除库存记录
-func (r *inventoryRepo) Delete(id int64) error {
+func (r *inventoryRepo) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM inventory WHERE id = ?`
 
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete inventory: %w", err)
 	}
@@ -219,7 +263,10 @@ func (r *inventoryRepo) Delete(id int64) error {
 }
 
 // GetByProductIDs 根据商品ID列表批量获取库存
-func (r *inventoryRepo) GetByProductIDs(productIDs []int64) ([]*domain.Inventory, error) {
+func (r *inventoryRepo) GetByProductIDs(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	if len(productIDs) == 0 {
 		return []*domain.Inventory{}, nil
 	}
@@ -238,7 +285,7 @@ func (r *inventoryRepo) GetByProductIDs(productIDs []int64) ([]*domain.Inventory
 		args[i] = id
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query inventories by product ids: %w", err)
 	}
@@ -269,53 +316,75 @@ func (r *inventoryRepo) GetByProductIDs(productIDs []int64) ([]*domain.Inventory
 }
 
 // BatchUpdateStock 批量更新库存
-func (r *inventoryRepo) BatchUpdateStock(updates []StockUpdate) error {
+func (r *inventoryRepo) BatchUpdateStock(ctx context.Context, updates []StockUpdate) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	if len(updates) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	for _, update := range updates {
+	for i, update := range updates {
 		switch update.Type {
 		case "reserve":
-			err = r.reserveStockInTx(tx, update.ProductID, update.Quantity)
+			err = r.reserveStockInTx(ctx, tx, update.ProductID, update.Quantity)
 		case "release":
-			err = r.releaseStockInTx(tx, update.ProductID, update.Quantity)
+			err = r.releaseStockInTx(ctx, tx, update.ProductID, update.Quantity)
 		case "consume":
-			err = r.consumeStockInTx(tx, update.ProductID, update.Quantity)
+			err = r.consumeStockInTx(ctx, tx, update.ProductID, update.Quantity)
+		case "adjust":
+			err = r.adjustStockInTx(ctx, tx, update.ProductID, update.Quantity)
 		default:
 			err = fmt.Errorf("unknown stock update type: %s", update.Type)
 		}
+		if err == nil {
+			err = insertStockMovementInTx(ctx, tx, update.ProductID, domain.StockMovementType(update.Type), update.Quantity, update.Reason)
+		}
 
 		if err != nil {
-			return err
+			// 锁冲突与具体某一项无关，整批重试即可
+			if isLockConflict(err) {
+				return newLockConflictError(err)
+			}
+			return &StockUpdateError{Index: i, ProductID: update.ProductID, Err: err}
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		if isLockConflict(err) {
+			return newLockConflictError(err)
+		}
+		return err
+	}
+	return nil
 }
 
 // List 获取库存列表
-func (r *inventoryRepo) List(req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
+func (r *inventoryRepo) List(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	// 构建查询条件
 	where, args := r.buildListWhereClause(req)
 
-	// 获取总数
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM inventory %s", where)
-	var total int64
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count inventories: %w", err)
+	// 获取总数，跳过统计时改为多取一行判断是否还有下一页
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM inventory %s", where)
+		if err := r.reader(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count inventories: %w", err)
+		}
 	}
 
 	// 构建排序和分页
 	orderBy := r.buildOrderClause(req)
-	limit := req.PageSize
+	limit := domain.LimitWithLookahead(req.PageSize, req.SkipTotal)
 	offset := (req.Page - 1) * req.PageSize
 
 	// 查询数据
@@ -325,7 +394,7 @@ func (r *inventoryRepo) List(req *domain.InventoryListRequest) ([]*domain.Invent
 	`, where, orderBy)
 
 	args = append(args, limit, offset)
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query inventories: %w", err)
 	}
@@ -356,7 +425,10 @@ func (r *inventoryRepo) List(req *domain.InventoryListRequest) ([]*domain.Invent
 }
 
 // GetLowStockProducts 获取低库存商品
-func (r *inventoryRepo) GetLowStockProducts() ([]*domain.Inventory, error) {
+func (r *inventoryRepo) GetLowStockProducts(ctx context.Context) ([]*domain.Inventory, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, stock, reserved_stock, sold_stock, reorder_point, max_stock, version, created_at, updated_at
 		FROM inventory 
@@ -364,7 +436,7 @@ func (r *inventoryRepo) GetLowStockProducts() ([]*domain.Inventory, error) {
 		ORDER BY stock ASC
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.reader(ctx).QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query low stock products: %w", err)
 	}
@@ -395,111 +467,75 @@ func (r *inventoryRepo) GetLowStockProducts() ([]*domain.Inventory, error) {
 }
 
 // ReserveStock 预留库存
-func (r *inventoryRepo) ReserveStock(productID int64, quantity int) error {
-	query := `
-		UPDATE inventory 
-		SET reserved_stock = reserved_stock + ?, version = version + 1
-		WHERE product_id = ? AND (stock - reserved_stock) >= ?
-	`
-
-	result, err := r.db.Exec(query, quantity, productID, quantity)
-	if err != nil {
-		return fmt.Errorf("failed to reserve stock: %w", err)
-	}
-
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-
-	if affected == 0 {
-		return fmt.Errorf("insufficient stock to reserve")
-	}
-
-	return nil
+func (r *inventoryRepo) ReserveStock(ctx context.Context, productID int64, quantity int) error {
+	return r.changeStock(ctx, func(tx *sql.Tx) error {
+		if err := r.reserveStockInTx(ctx, tx, productID, quantity); err != nil {
+			return err
+		}
+		return insertStockMovementInTx(ctx, tx, productID, domain.StockMovementReserve, quantity, "")
+	})
 }
 
 // ReleaseStock 释放预留库存
-func (r *inventoryRepo) ReleaseStock(productID int64, quantity int) error {
-	query := `
-		UPDATE inventory 
-		SET reserved_stock = reserved_stock - ?, version = version + 1
-		WHERE product_id = ? AND reserved_stock >= ?
-	`
-
-	result, err := r.db.Exec(query, quantity, productID, quantity)
-	if err != nil {
-		return fmt.Errorf("failed to release stock: %w", err)
-	}
-
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-
-	if affected == 0 {
-		return fmt.Errorf("insufficient reserved stock to release")
-	}
-
-	return nil
+func (r *inventoryRepo) ReleaseStock(ctx context.Context, productID int64, quantity int) error {
+	return r.changeStock(ctx, func(tx *sql.Tx) error {
+		if err := r.releaseStockInTx(ctx, tx, productID, quantity); err != nil {
+			return err
+		}
+		return insertStockMovementInTx(ctx, tx, productID, domain.StockMovementRelease, quantity, "")
+	})
 }
 
 // ConsumeStock 消费库存
-func (r *inventoryRepo) ConsumeStock(productID int64, quantity int) error {
-	query := `
-		UPDATE inventory 
-		SET stock = stock - ?, reserved_stock = reserved_stock - ?, sold_stock = sold_stock + ?, version = version + 1
-		WHERE product_id = ? AND reserved_stock >= ?
-	`
-
-	result, err := r.db.Exec(query, quantity, quantity, quantity, productID, quantity)
-	if err != nil {
-		return fmt.Errorf("failed to consume stock: %w", err)
-	}
-
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-
-	if affected == 0 {
-		return fmt.Errorf("insufficient reserved stock to consume")
-	}
+func (r *inventoryRepo) ConsumeStock(ctx context.Context, productID int64, quantity int) error {
+	return r.changeStock(ctx, func(tx *sql.Tx) error {
+		if err := r.consumeStockInTx(ctx, tx, productID, quantity); err != nil {
+			return err
+		}
+		return insertStockMovementInTx(ctx, tx, productID, domain.StockMovementConsume, quantity, "")
+	})
+}
 
-	return nil
+// AdjustStock 调整库存，reason 记入变动流水
+func (r *inventoryRepo) AdjustStock(ctx context.Context, productID int64, quantity int, reason string) error {
+	return r.changeStock(ctx, func(tx *sql.Tx) error {
+		if err := r.adjustStockInTx(ctx, tx, productID, quantity); err != nil {
+			return err
+		}
+		return insertStockMovementInTx(ctx, tx, productID, domain.StockMovementAdjust, quantity, reason)
+	})
 }
 
-// AdjustStock 调整库存
-func (r *inventoryRepo) AdjustStock(productID int64, quantity int, reason string) error {
-	query := `
-		UPDATE inventory 
-		SET stock = stock + ?, version = version + 1
-		WHERE product_id = ? AND stock + ? >= 0
-	`
+// changeStock 在事务中变更库存并写入变动流水，二者同时生效
+func (r *inventoryRepo) changeStock(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	result, err := r.db.Exec(query, quantity, productID, quantity)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to adjust stock: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
+	err = fn(tx)
+	if err == nil {
+		err = tx.Commit()
 	}
-
-	if affected == 0 {
-		return fmt.Errorf("stock adjustment would result in negative stock")
+	if isLockConflict(err) {
+		return newLockConflictError(err)
 	}
-
-	return nil
+	return err
 }
 
 // Count 获取库存记录总数
-func (r *inventoryRepo) Count() (int64, error) {
+func (r *inventoryRepo) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := "SELECT COUNT(*) FROM inventory"
 
 	var count int64
-	err := r.db.QueryRow(query).Scan(&count)
+	err := r.reader(ctx).QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count inventories: %w", err)
 	}
@@ -508,7 +544,10 @@ func (r *inventoryRepo) Count() (int64, error) {
 }
 
 // GetTotalStockValue 获取总库存价值
-func (r *inventoryRepo) GetTotalStockValue() (float64, error) {
+func (r *inventoryRepo) GetTotalStockValue(ctx context.Context) (float64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT COALESCE(SUM(i.stock * p.price), 0)
 		FROM inventory i
@@ -517,7 +556,7 @@ func (r *inventoryRepo) GetTotalStockValue() (float64, error) {
 	`
 
 	var value float64
-	err := r.db.QueryRow(query).Scan(&value)
+	err := r.reader(ctx).QueryRowContext(ctx, query).Scan(&value)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total stock value: %w", err)
 	}
@@ -526,14 +565,14 @@ func (r *inventoryRepo) GetTotalStockValue() (float64, error) {
 }
 
 // 事务内的库存操作方法
-func (r *inventoryRepo) reserveStockInTx(tx *sql.Tx, productID int64, quantity int) error {
+func (r *inventoryRepo) reserveStockInTx(ctx context.Context, tx *sql.Tx, productID int64, quantity int) error {
 	query := `
 		UPDATE inventory 
 		SET reserved_stock = reserved_stock + ?, version = version + 1
 		WHERE product_id = ? AND (stock - reserved_stock) >= ?
 	`
 
-	result, err := tx.Exec(query, quantity, productID, quantity)
+	result, err := tx.ExecContext(ctx, query, quantity, productID, quantity)
 	if err != nil {
 		return fmt.Errorf("failed to reserve stock in tx: %w", err)
 	}
@@ -544,20 +583,20 @@ func (r *inventoryRepo) reserveStockInTx(tx *sql.Tx, productID int64, quantity i
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("insufficient stock to reserve")
+		return domain.NewInsufficientStockError("insufficient stock to reserve")
 	}
 
 	return nil
 }
 
-func (r *inventoryRepo) releaseStockInTx(tx *sql.Tx, productID int64, quantity int) error {
+func (r *inventoryRepo) releaseStockInTx(ctx context.Context, tx *sql.Tx, productID int64, quantity int) error {
 	query := `
 		UPDATE inventory 
 		SET reserved_stock = reserved_stock - ?, version = version + 1
 		WHERE product_id = ? AND reserved_stock >= ?
 	`
 
-	result, err := tx.Exec(query, quantity, productID, quantity)
+	result, err := tx.ExecContext(ctx, query, quantity, productID, quantity)
 	if err != nil {
 		return fmt.Errorf("failed to release stock in tx: %w", err)
 	}
@@ -568,20 +607,20 @@ func (r *inventoryRepo) releaseStockInTx(tx *sql.Tx, productID int64, quantity i
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("insufficient reserved stock to release")
+		return domain.NewInvalidArgumentError("insufficient reserved stock to release")
 	}
 
 	return nil
 }
 
-func (r *inventoryRepo) consumeStockInTx(tx *sql.Tx, productID int64, quantity int) error {
+func (r *inventoryRepo) consumeStockInTx(ctx context.Context, tx *sql.Tx, productID int64, quantity int) error {
 	query := `
 		UPDATE inventory 
 		SET stock = stock - ?, reserved_stock = reserved_stock - ?, sold_stock = sold_stock + ?, version = version + 1
 		WHERE product_id = ? AND reserved_stock >= ?
 	`
 
-	result, err := tx.Exec(query, quantity, quantity, quantity, productID, quantity)
+	result, err := tx.ExecContext(ctx, query, quantity, quantity, quantity, productID, quantity)
 	if err != nil {
 		return fmt.Errorf("failed to consume stock in tx: %w", err)
 	}
@@ -592,12 +631,54 @@ func (r *inventoryRepo) consumeStockInTx(tx *sql.Tx, productID int64, quantity i
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("insufficient reserved stock to consume")
+		return domain.NewInvalidArgumentError("insufficient reserved stock to consume")
+	}
+
+	return nil
+}
+
+func (r *inventoryRepo) adjustStockInTx(ctx context.Context, tx *sql.Tx, productID int64, quantity int) error {
+	query := `
+		UPDATE inventory 
+		SET stock = stock + ?, version = version + 1
+		WHERE product_id = ? AND stock + ? >= 0
+	`
+
+	result, err := tx.ExecContext(ctx, query, quantity, productID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to adjust stock in tx: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.NewInvalidArgumentError("stock adjustment would result in negative stock")
 	}
 
 	return nil
 }
 
+// MySQL 锁冲突错误码，语句或事务已回滚，重试即可成功
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrLockDeadlock    = 1213
+)
+
+// isLockConflict 判断是否为并发库存变更之间的死锁或锁等待超时
+func isLockConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) &&
+		(mysqlErr.Number == mysqlErrLockDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout)
+}
+
+// newLockConflictError 把锁冲突转换为版本冲突，调用方与乐观锁冲突一样按 domain.ErrVersionConflict 重试
+func newLockConflictError(err error) error {
+	return domain.NewVersionConflictError(fmt.Sprintf("inventory lock conflict: %v", err))
+}
+
 // buildListWhereClause 构建查询条件子句
 func (r *inventoryRepo) buildListWhereClause(req *domain.InventoryListRequest) (string, []interface{}) {
 	var conditions []string
@@ -651,5 +732,6 @@ func (r *inventoryRepo) buildOrderClause(req *domain.InventoryListRequest) strin
 		}
 	}
 
-	return fmt.Sprintf("ORDER BY %s %s", sortBy, sortOrder)
+	// 以主键作为次级排序，排序字段相同的行在翻页时顺序稳定
+	return fmt.Sprintf("ORDER BY %s %s, id %s", sortBy, sortOrder, sortOrder)
 }
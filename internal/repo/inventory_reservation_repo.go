@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// InventoryReservationRepository 定义库存预留记录数据访问接口
+type InventoryReservationRepository interface {
+	Create(reservation *domain.InventoryReservation) error
+	GetByReservationID(reservationID string) (*domain.InventoryReservation, error)
+	// Transition 将预留从 from 状态改为 to，返回是否更新；并发处理同一预留时只有一方成功
+	Transition(reservationID string, from, to domain.ReservationStatus) (bool, error)
+	// ListExpired 按过期时间升序返回 now 之前已过期、仍处于预留中的记录
+	ListExpired(now time.Time, limit int) ([]*domain.InventoryReservation, error)
+}
+
+// inventoryReservationRepo 实现InventoryReservationRepository接口
+type inventoryReservationRepo struct {
+	db *sql.DB
+}
+
+// NewInventoryReservationRepository 创建库存预留记录仓储实例
+func NewInventoryReservationRepository(db *sql.DB) InventoryReservationRepository {
+	return &inventoryReservationRepo{db: db}
+}
+
+const inventoryReservationColumns = `id, reservation_id, product_id, quantity, status, expires_at, created_at, updated_at`
+
+// Create 创建库存预留记录
+func (r *inventoryReservationRepo) Create(reservation *domain.InventoryReservation) error {
+	query := `
+		INSERT INTO inventory_reservations (reservation_id, product_id, quantity, status, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		reservation.ReservationID,
+		reservation.ProductID,
+		reservation.Quantity,
+		reservation.Status,
+		reservation.ExpiresAt,
+		reservation.CreatedAt,
+		reservation.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create inventory reservation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	reservation.ID = id
+	return nil
+}
+
+// GetByReservationID 根据预留ID获取记录
+func (r *inventoryReservationRepo) GetByReservationID(reservationID string) (*domain.InventoryReservation, error) {
+	query := `SELECT ` + inventoryReservationColumns + ` FROM inventory_reservations WHERE reservation_id = ?`
+
+	reservation, err := scanInventoryReservation(r.db.QueryRow(query, reservationID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory reservation: %w", err)
+	}
+	return reservation, nil
+}
+
+// Transition 条件更新预留状态
+func (r *inventoryReservationRepo) Transition(reservationID string, from, to domain.ReservationStatus) (bool, error) {
+	query := `UPDATE inventory_reservations SET status = ?, updated_at = NOW() WHERE reservation_id = ? AND status = ?`
+
+	result, err := r.db.Exec(query, to, reservationID, from)
+	if err != nil {
+		return false, fmt.Errorf("failed to update inventory reservation status: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ListExpired 查询已过期的预留
+func (r *inventoryReservationRepo) ListExpired(now time.Time, limit int) ([]*domain.InventoryReservation, error) {
+	query := `SELECT ` + inventoryReservationColumns + `
+		FROM inventory_reservations
+		WHERE status = ? AND expires_at <= ?
+		ORDER BY expires_at ASC
+		LIMIT ?`
+
+	rows, err := r.db.Query(query, domain.ReservationStatusReserved, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired inventory reservations: %w", err)
+	}
+	defer rows.Close()
+
+	var reservations []*domain.InventoryReservation
+	for rows.Next() {
+		reservation, err := scanInventoryReservation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inventory reservation: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate inventory reservations: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// inventoryReservationScanner 兼容 *sql.Row 与 *sql.Rows
+type inventoryReservationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanInventoryReservation 按 inventoryReservationColumns 的列顺序扫描一行
+func scanInventoryReservation(s inventoryReservationScanner) (*domain.InventoryReservation, error) {
+	var reservation domain.InventoryReservation
+	if err := s.Scan(
+		&reservation.ID,
+		&reservation.ReservationID,
+		&reservation.ProductID,
+		&reservation.Quantity,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
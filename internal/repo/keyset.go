@@ -0,0 +1,14 @@
+package repo
+
+import "github.com/MorseWayne/spike_shop/internal/domain"
+
+// keysetCondition 构建键集分页条件：只取排序位置在游标之后的行。
+// 按 (created_at, id) 排序，降序时取更早的行，升序时取更晚的行；alias 为表别名前缀，单表查询时为空
+func keysetCondition(alias string, cursor *domain.ListCursor, descending bool) (string, []interface{}) {
+	op := ">"
+	if descending {
+		op = "<"
+	}
+	cond := "(" + alias + "created_at " + op + " ? OR (" + alias + "created_at = ? AND " + alias + "id " + op + " ?))"
+	return cond, []interface{}{cursor.CreatedAt, cursor.CreatedAt, cursor.ID}
+}
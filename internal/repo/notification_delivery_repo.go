@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// NotificationDeliveryRepository 定义用户通知投递记录的数据访问接口
+type NotificationDeliveryRepository interface {
+	// ListByMessage 获取通知消息在各渠道上的投递记录
+	ListByMessage(ctx context.Context, messageID string) ([]*domain.NotificationDelivery, error)
+	// Save 写入消息在渠道上的投递结果，记录已存在时覆盖状态与错误并累加发送次数
+	Save(ctx context.Context, delivery *domain.NotificationDelivery) error
+}
+
+// notificationDeliveryRepo 实现NotificationDeliveryRepository接口
+type notificationDeliveryRepo struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewNotificationDeliveryRepository 创建通知投递记录仓储实例
+func NewNotificationDeliveryRepository(db *sql.DB, opts ...Option) NotificationDeliveryRepository {
+	return &notificationDeliveryRepo{db: db, queryTimeout: newQueryTimeout(opts)}
+}
+
+// ListByMessage 获取通知消息的投递记录，用于重新投递时跳过已发送的渠道，始终读主库
+func (r *notificationDeliveryRepo) ListByMessage(ctx context.Context, messageID string) ([]*domain.NotificationDelivery, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, message_id, user_id, channel, type, status, attempts, last_error, created_at, updated_at
+		FROM notification_deliveries
+		WHERE message_id = ?
+		ORDER BY id
+	`, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.NotificationDelivery
+	for rows.Next() {
+		delivery := &domain.NotificationDelivery{}
+		if err := rows.Scan(&delivery.ID, &delivery.MessageID, &delivery.UserID, &delivery.Channel, &delivery.Type,
+			&delivery.Status, &delivery.Attempts, &delivery.LastError, &delivery.CreatedAt, &delivery.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Save 写入投递结果
+func (r *notificationDeliveryRepo) Save(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_deliveries (message_id, user_id, channel, type, status, attempts, last_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			status = VALUES(status),
+			attempts = attempts + VALUES(attempts),
+			last_error = VALUES(last_error)
+	`, delivery.MessageID, delivery.UserID, delivery.Channel, delivery.Type, delivery.Status,
+		delivery.Attempts, truncateRunes(delivery.LastError, 255)); err != nil {
+		return fmt.Errorf("failed to save notification delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Package repo 实现秒杀订单事件数据访问层，负责与数据库的交互。
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// OrderEventRepository 定义订单事件数据访问接口
+type OrderEventRepository interface {
+	Create(event *domain.OrderEvent) error
+	ListBySpikeOrderID(spikeOrderID int64) ([]*domain.OrderEvent, error)
+	// ListBySpikeEventID 按时间顺序获取某秒杀活动下全部订单的事件，用于报表统计
+	ListBySpikeEventID(spikeEventID int64) ([]*domain.OrderEvent, error)
+}
+
+// orderEventRepo 实现OrderEventRepository接口
+type orderEventRepo struct {
+	db *sql.DB
+}
+
+// NewOrderEventRepository 创建订单事件仓储实例
+func NewOrderEventRepository(db *sql.DB) OrderEventRepository {
+	return &orderEventRepo{db: db}
+}
+
+// Create 记录订单事件
+func (r *orderEventRepo) Create(event *domain.OrderEvent) error {
+	query := `
+		INSERT INTO order_events (spike_order_id, event_type, from_status, to_status, actor, remark, trace_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		event.SpikeOrderID,
+		event.EventType,
+		event.FromStatus,
+		event.ToStatus,
+		event.Actor,
+		event.Remark,
+		event.TraceID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create order event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	event.ID = id
+	return nil
+}
+
+// ListBySpikeOrderID 按时间顺序获取订单的全部事件
+func (r *orderEventRepo) ListBySpikeOrderID(spikeOrderID int64) ([]*domain.OrderEvent, error) {
+	query := `
+		SELECT id, spike_order_id, event_type, from_status, to_status, actor, remark, trace_id, created_at
+		FROM order_events
+		WHERE spike_order_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, spikeOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOrderEvents(rows)
+}
+
+// ListBySpikeEventID 按时间顺序获取某秒杀活动下全部订单的事件
+func (r *orderEventRepo) ListBySpikeEventID(spikeEventID int64) ([]*domain.OrderEvent, error) {
+	query := `
+		SELECT e.id, e.spike_order_id, e.event_type, e.from_status, e.to_status, e.actor, e.remark, e.trace_id, e.created_at
+		FROM order_events e
+		INNER JOIN spike_orders o ON o.id = e.spike_order_id
+		WHERE o.spike_event_id = ?
+		ORDER BY e.created_at ASC, e.id ASC
+	`
+
+	rows, err := r.db.Query(query, spikeEventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order events by spike event: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOrderEvents(rows)
+}
+
+// scanOrderEvents 扫描订单事件结果集
+func scanOrderEvents(rows *sql.Rows) ([]*domain.OrderEvent, error) {
+	var events []*domain.OrderEvent
+	for rows.Next() {
+		event := &domain.OrderEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.SpikeOrderID,
+			&event.EventType,
+			&event.FromStatus,
+			&event.ToStatus,
+			&event.Actor,
+			&event.Remark,
+			&event.TraceID,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate order events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,291 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// OrderRepository 定义普通订单数据访问接口
+type OrderRepository interface {
+	// Create 在同一事务中写入订单与明细，回填订单与明细ID。
+	// 订单号或来源秒杀订单已存在时不重复写入，返回 created=false 并把 order.ID 设为已有订单的ID
+	Create(ctx context.Context, order *domain.Order) (created bool, err error)
+	// GetByID 获取订单及其明细，不存在时返回 domain.ErrOrderNotFound
+	GetByID(ctx context.Context, id int64) (*domain.Order, error)
+	// List 分页查询订单，不含明细，按创建时间倒序
+	List(ctx context.Context, req *domain.OrderListRequest) ([]*domain.Order, int64, error)
+	// Transition 仅当订单当前为 from 状态时更新为 to，返回是否更新。
+	// 转为已支付时记录支付时间与支付渠道交易ID，转为已取消或已过期时记录取消时间
+	Transition(ctx context.Context, id int64, from, to domain.OrderStatus, at time.Time, paymentRef string) (bool, error)
+	// GetExpiredOrders 按支付期限升序获取 before 之前已过期的待支付订单，不含明细，最多 limit 条
+	GetExpiredOrders(ctx context.Context, before time.Time, limit int) ([]*domain.Order, error)
+}
+
+// orderRepo 实现OrderRepository接口
+type orderRepo struct {
+	db *sql.DB
+	queryTimeout
+	readRouting
+}
+
+// NewOrderRepository 创建普通订单仓储实例
+func NewOrderRepository(db *sql.DB, opts ...Option) OrderRepository {
+	return &orderRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
+}
+
+// orderColumns 订单查询列，与 scanOrder 的扫描顺序一致
+const orderColumns = `id, order_no, user_id, source, spike_order_id, status, total_amount, item_count,
+	payment_ref, expire_at, paid_at, cancelled_at, created_at, updated_at`
+
+// Create 创建订单。以 LAST_INSERT_ID(id) 处理唯一键冲突，冲突时取回已有订单ID
+func (r *orderRepo) Create(ctx context.Context, order *domain.Order) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO orders (order_no, user_id, source, spike_order_id, status, total_amount, item_count,
+			payment_ref, expire_at, paid_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+	`, order.OrderNo, order.UserID, order.Source, order.SpikeOrderID, order.Status, order.TotalAmount, order.ItemCount,
+		order.PaymentRef, order.ExpireAt, order.PaidAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create order: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	order.ID = id
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected != 1 {
+		return false, nil
+	}
+
+	for _, item := range order.Items {
+		item.OrderID = id
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO order_items (order_id, product_id, variant_id, product_name, sku, unit_price, quantity, subtotal)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, item.ProductID, item.VariantID, item.ProductName, item.SKU, item.UnitPrice, item.Quantity, item.Subtotal)
+		if err != nil {
+			return false, fmt.Errorf("failed to create order item: %w", err)
+		}
+		if item.ID, err = result.LastInsertId(); err != nil {
+			return false, fmt.Errorf("failed to get last insert id: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// GetByID 根据ID获取订单及其明细
+func (r *orderRepo) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	order, err := scanOrder(r.reader(ctx).QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order by id: %w", err)
+	}
+
+	rows, err := r.reader(ctx).QueryContext(ctx, `
+		SELECT id, order_id, product_id, variant_id, product_name, sku, unit_price, quantity, subtotal
+		FROM order_items
+		WHERE order_id = ?
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &domain.OrderItem{}
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.VariantID, &item.ProductName, &item.SKU,
+			&item.UnitPrice, &item.Quantity, &item.Subtotal); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		order.Items = append(order.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate order items: %w", err)
+	}
+
+	return order, nil
+}
+
+// List 分页查询订单
+func (r *orderRepo) List(ctx context.Context, req *domain.OrderListRequest) ([]*domain.Order, int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var conditions []string
+	var args []interface{}
+	if req.UserID != nil {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, *req.UserID)
+	}
+	if req.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, *req.Status)
+	}
+	if req.Source != nil {
+		conditions = append(conditions, "source = ?")
+		args = append(args, *req.Source)
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM orders %s", whereClause)
+		if err := r.reader(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+		}
+	}
+
+	// 分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM orders %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, orderColumns, whereClause)
+
+	args = append(args, domain.LimitWithLookahead(req.PageSize, req.SkipTotal), offset)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate orders: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// Transition 条件更新订单状态
+func (r *orderRepo) Transition(ctx context.Context, id int64, from, to domain.OrderStatus, at time.Time, paymentRef string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE orders SET status = ?, cancelled_at = ? WHERE id = ? AND status = ?`
+	args := []interface{}{to, at, id, from}
+	if to == domain.OrderStatusPaid {
+		query = `UPDATE orders SET status = ?, paid_at = ?, payment_ref = ? WHERE id = ? AND status = ?`
+		args = []interface{}{to, at, paymentRef, id, from}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update order status: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// GetExpiredOrders 获取已过期的待支付订单
+func (r *orderRepo) GetExpiredOrders(ctx context.Context, before time.Time, limit int) ([]*domain.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE status = ? AND expire_at IS NOT NULL AND expire_at < ?
+		ORDER BY expire_at ASC
+		LIMIT ?
+	`, domain.OrderStatusPending, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate expired orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// rowScanner 由 *sql.Row 与 *sql.Rows 实现
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanOrder 按 orderColumns 的顺序扫描一行订单
+func scanOrder(row rowScanner) (*domain.Order, error) {
+	order := &domain.Order{}
+	err := row.Scan(
+		&order.ID,
+		&order.OrderNo,
+		&order.UserID,
+		&order.Source,
+		&order.SpikeOrderID,
+		&order.Status,
+		&order.TotalAmount,
+		&order.ItemCount,
+		&order.PaymentRef,
+		&order.ExpireAt,
+		&order.PaidAt,
+		&order.CancelledAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
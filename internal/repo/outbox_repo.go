@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// OutboxRepository 定义消息发件箱数据访问接口
+type OutboxRepository interface {
+	// Create 写入待发布消息
+	Create(ctx context.Context, message *domain.OutboxMessage) error
+	// ClaimDue 领取到期的待发布消息，并把下次发布时间顺延 lease 作为租约，
+	// 租约内其他实例不会重复领取；实例在租约内崩溃时消息到期后被重新领取
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxMessage, error)
+	// MarkPublished 标记消息已发布
+	MarkPublished(id int64, publishedAt time.Time) error
+	// MarkRetry 记录发布失败，nextAttemptAt 后重试
+	MarkRetry(id int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	// MarkFailed 记录发布失败且不再重试
+	MarkFailed(id int64, attempts int, lastError string) error
+	// DeletePublishedBefore 删除早于 before 发布的消息，每次最多删除 limit 条，返回删除条数
+	DeletePublishedBefore(before time.Time, limit int) (int64, error)
+}
+
+// outboxRepo 实现OutboxRepository接口
+type outboxRepo struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository 创建消息发件箱仓储实例
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+// maxOutboxErrorLength last_error 列长度上限
+const maxOutboxErrorLength = 512
+
+// sqlExecer 可执行写语句的连接或事务
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertOutboxMessage 写入发件箱消息，供需要与业务数据在同一事务中写入的仓储复用
+func insertOutboxMessage(ctx context.Context, db sqlExecer, message *domain.OutboxMessage) error {
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO outbox_messages (message_type, payload, trace_id, status)
+		VALUES (?, ?, ?, ?)
+	`, message.MessageType, []byte(message.Payload), message.TraceID, domain.OutboxStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	message.ID = id
+	message.Status = domain.OutboxStatusPending
+	return nil
+}
+
+// Create 写入待发布消息
+func (r *outboxRepo) Create(ctx context.Context, message *domain.OutboxMessage) error {
+	return insertOutboxMessage(ctx, r.db, message)
+}
+
+// ClaimDue 领取到期的待发布消息
+func (r *outboxRepo) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxMessage, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED 使多个实例的中继各自领取不同的消息
+	rows, err := tx.Query(`
+		SELECT id, message_type, payload, trace_id, status, attempts, next_attempt_at, last_error, created_at
+		FROM outbox_messages
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY id ASC
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, domain.OutboxStatusPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due outbox messages: %w", err)
+	}
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		var (
+			message domain.OutboxMessage
+			payload []byte
+		)
+		if err := rows.Scan(&message.ID, &message.MessageType, &payload, &message.TraceID, &message.Status,
+			&message.Attempts, &message.NextAttemptAt, &message.LastError, &message.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		message.Payload = payload
+		messages = append(messages, &message)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(messages))
+	args := make([]interface{}, 0, len(messages)+1)
+	args = append(args, now.Add(lease))
+	for i, message := range messages {
+		placeholders[i] = "?"
+		args = append(args, message.ID)
+	}
+	query := fmt.Sprintf(`UPDATE outbox_messages SET next_attempt_at = ? WHERE id IN (%s)`, strings.Join(placeholders, ","))
+	if _, err := tx.Exec(query, args...); err != nil {
+		return nil, fmt.Errorf("failed to lease outbox messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkPublished 标记消息已发布
+func (r *outboxRepo) MarkPublished(id int64, publishedAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox_messages SET status = ?, attempts = attempts + 1, published_at = ?, last_error = ''
+		WHERE id = ?
+	`, domain.OutboxStatusPublished, publishedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message published: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry 记录发布失败，等待重试
+func (r *outboxRepo) MarkRetry(id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox_messages SET attempts = ?, next_attempt_at = ?, last_error = ?
+		WHERE id = ? AND status = ?
+	`, attempts, nextAttemptAt, truncateOutboxError(lastError), id, domain.OutboxStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to schedule outbox message retry: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 记录发布失败且不再重试
+func (r *outboxRepo) MarkFailed(id int64, attempts int, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox_messages SET status = ?, attempts = ?, last_error = ?
+		WHERE id = ? AND status = ?
+	`, domain.OutboxStatusFailed, attempts, truncateOutboxError(lastError), id, domain.OutboxStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+	return nil
+}
+
+// DeletePublishedBefore 删除早于 before 发布的消息
+func (r *outboxRepo) DeletePublishedBefore(before time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM outbox_messages WHERE status = ? AND published_at < ? LIMIT ?
+	`, domain.OutboxStatusPublished, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published outbox messages: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// truncateOutboxError 截断错误信息以适应 last_error 列长度（按字符截断）
+func truncateOutboxError(s string) string {
+	runes := []rune(s)
+	if len(runes) > maxOutboxErrorLength {
+		return string(runes[:maxOutboxErrorLength])
+	}
+	return s
+}
@@ -19,6 +19,7 @@ type ProductRepository interface {
 	Delete(id int64) error
 
 	// 查询操作
+	// List 分页查询；req.SkipTotal 为 true 时不统计总数（返回 domain.TotalNotCounted），并多取一行供调用方判断是否还有下一页
 	List(req *domain.ProductListRequest) ([]*domain.Product, int64, error)
 	GetByIDs(ids []int64) ([]*domain.Product, error)
 
@@ -180,17 +181,18 @@ func (r *productRepo) List(req *domain.ProductListRequest) ([]*domain.Product, i
 	// 构建查询条件
 	where, args := r.buildListWhereClause(req)
 
-	// 获取总数
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", where)
-	var total int64
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count products: %w", err)
+	// 获取总数，跳过统计时改为多取一行判断是否还有下一页
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", where)
+		if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count products: %w", err)
+		}
 	}
 
 	// 构建排序和分页
 	orderBy := r.buildOrderClause(req)
-	limit := req.PageSize
+	limit := domain.LimitWithLookahead(req.PageSize, req.SkipTotal)
 	offset := (req.Page - 1) * req.PageSize
 
 	// 查询数据
@@ -0,0 +1,239 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// mysqlErrDuplicateEntry MySQL 唯一键冲突错误码
+const mysqlErrDuplicateEntry = 1062
+
+// ProductVariantRepository 定义商品规格数据访问接口
+type ProductVariantRepository interface {
+	// Create 创建规格，SKU 已存在时返回 domain.ErrConflict 分类的错误
+	Create(ctx context.Context, variant *domain.ProductVariant) error
+	GetByID(ctx context.Context, id int64) (*domain.ProductVariant, error)
+	// GetByIDs 批量获取规格，不存在的ID不出现在结果中
+	GetByIDs(ctx context.Context, ids []int64) ([]*domain.ProductVariant, error)
+	// ListByProduct 获取商品的全部规格，按ID升序
+	ListByProduct(ctx context.Context, productID int64) ([]*domain.ProductVariant, error)
+	// Update 按版本号更新名称、属性、价格与启用状态，版本不一致时返回 domain.ErrVersionConflict 分类的错误
+	Update(ctx context.Context, variant *domain.ProductVariant) error
+
+	// AdjustStock 调整规格库存，调整后库存为负时返回 domain.ErrInsufficientStock 分类的错误
+	AdjustStock(ctx context.Context, id int64, quantity int) error
+	// ConsumeStock 扣减规格库存并累加已售数量，库存不足时返回 domain.ErrInsufficientStock 分类的错误
+	ConsumeStock(ctx context.Context, id int64, quantity int) error
+}
+
+// productVariantRepo 实现ProductVariantRepository接口
+type productVariantRepo struct {
+	db *sql.DB
+	queryTimeout
+	readRouting
+}
+
+// NewProductVariantRepository 创建商品规格仓储实例
+func NewProductVariantRepository(db *sql.DB, opts ...Option) ProductVariantRepository {
+	return &productVariantRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
+}
+
+const productVariantColumns = `id, product_id, sku, name, attributes, price, stock, sold_stock, active, version, created_at, updated_at`
+
+// Create 创建商品规格
+func (r *productVariantRepo) Create(ctx context.Context, variant *domain.ProductVariant) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	attributes, err := marshalVariantAttributes(variant.Attributes)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO product_variants (product_id, sku, name, attributes, price, stock, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, variant.ProductID, variant.SKU, variant.Name, attributes, variant.Price, variant.Stock, variant.Active)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return domain.NewConflictError("商品规格SKU已存在")
+		}
+		return fmt.Errorf("failed to create product variant: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	variant.ID = id
+	return nil
+}
+
+// GetByID 根据ID获取商品规格，始终读主库，供下单与库存调整前读取最新版本
+func (r *productVariantRepo) GetByID(ctx context.Context, id int64) (*domain.ProductVariant, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `SELECT `+productVariantColumns+` FROM product_variants WHERE id = ?`, id)
+	variant, err := scanProductVariant(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrProductVariantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product variant: %w", err)
+	}
+	return variant, nil
+}
+
+// GetByIDs 批量获取商品规格
+func (r *productVariantRepo) GetByIDs(ctx context.Context, ids []int64) ([]*domain.ProductVariant, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.Repeat("?,", len(ids)-1) + "?"
+	query := `SELECT ` + productVariantColumns + ` FROM product_variants WHERE id IN (` + placeholders + `) ORDER BY id`
+	return r.queryVariants(ctx, query, args...)
+}
+
+// ListByProduct 获取商品的全部规格
+func (r *productVariantRepo) ListByProduct(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + productVariantColumns + ` FROM product_variants WHERE product_id = ? ORDER BY id`
+	return r.queryVariants(ctx, query, productID)
+}
+
+func (r *productVariantRepo) queryVariants(ctx context.Context, query string, args ...interface{}) ([]*domain.ProductVariant, error) {
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []*domain.ProductVariant
+	for rows.Next() {
+		variant, err := scanProductVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product variant: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate product variants: %w", err)
+	}
+	return variants, nil
+}
+
+// Update 按版本号更新商品规格
+func (r *productVariantRepo) Update(ctx context.Context, variant *domain.ProductVariant) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	attributes, err := marshalVariantAttributes(variant.Attributes)
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE product_variants
+		SET name = ?, attributes = ?, price = ?, active = ?, version = version + 1
+		WHERE id = ? AND version = ?
+	`, variant.Name, attributes, variant.Price, variant.Active, variant.ID, variant.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update product variant: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.NewVersionConflictError("product variant version conflict or record not found")
+	}
+	variant.Version++
+	return nil
+}
+
+// AdjustStock 调整商品规格库存
+func (r *productVariantRepo) AdjustStock(ctx context.Context, id int64, quantity int) error {
+	return r.changeStock(ctx, `
+		UPDATE product_variants
+		SET stock = stock + ?, version = version + 1
+		WHERE id = ? AND stock + ? >= 0
+	`, "stock adjustment would result in negative variant stock", quantity, id, quantity)
+}
+
+// ConsumeStock 扣减商品规格库存并累加已售数量
+func (r *productVariantRepo) ConsumeStock(ctx context.Context, id int64, quantity int) error {
+	return r.changeStock(ctx, `
+		UPDATE product_variants
+		SET stock = stock - ?, sold_stock = sold_stock + ?, version = version + 1
+		WHERE id = ? AND stock >= ?
+	`, "insufficient variant stock to consume", quantity, quantity, id, quantity)
+}
+
+// changeStock 执行带库存条件的更新，未更新任何行时返回库存不足
+func (r *productVariantRepo) changeStock(ctx context.Context, query, insufficient string, args ...interface{}) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if isLockConflict(err) {
+		return newLockConflictError(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change variant stock: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.NewInsufficientStockError(insufficient)
+	}
+	return nil
+}
+
+// scanProductVariant 扫描一行规格记录，属性以 JSON 存储
+func scanProductVariant(row rowScanner) (*domain.ProductVariant, error) {
+	variant := &domain.ProductVariant{}
+	var attributes sql.NullString
+	if err := row.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &variant.Name, &attributes, &variant.Price,
+		&variant.Stock, &variant.SoldStock, &variant.Active, &variant.Version, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if attributes.Valid && attributes.String != "" {
+		if err := json.Unmarshal([]byte(attributes.String), &variant.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to decode variant attributes: %w", err)
+		}
+	}
+	return variant, nil
+}
+
+// marshalVariantAttributes 将规格属性编码为 JSON，没有属性时存为 NULL
+func marshalVariantAttributes(attributes map[string]string) (interface{}, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode variant attributes: %w", err)
+	}
+	return string(data), nil
+}
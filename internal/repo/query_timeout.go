@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultQueryTimeout 单次仓储调用的默认超时时间
+const DefaultQueryTimeout = 3 * time.Second
+
+// Option 仓储的可选配置
+type Option func(*options)
+
+// options 仓储可选配置的汇总，由各仓储按需取用
+type options struct {
+	timeout time.Duration
+	readers ReadReplicas
+}
+
+// WithQueryTimeout 设置单次仓储调用（含事务内的全部语句）的超时时间，0 表示只受调用方 ctx 约束
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// newOptions 按选项汇总配置，未设置超时时使用 DefaultQueryTimeout
+func newOptions(opts []Option) options {
+	o := options{timeout: DefaultQueryTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// queryTimeout 为仓储调用的 ctx 附加超时，调用方 ctx 的截止时间更早时以调用方为准
+type queryTimeout struct {
+	timeout time.Duration
+}
+
+// newQueryTimeout 按选项创建超时配置，未设置时使用 DefaultQueryTimeout
+func newQueryTimeout(opts []Option) queryTimeout {
+	return queryTimeout{timeout: newOptions(opts).timeout}
+}
+
+// withTimeout 返回带超时的 ctx，调用方须在读取完结果后调用 cancel
+func (q queryTimeout) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if q.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, q.timeout)
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ReadReplicas 为只读查询选择连接（由 database.DB 实现）
+type ReadReplicas interface {
+	// Reader 返回本次查询使用的连接，ctx 未允许读副本或副本不可用时返回主库
+	Reader(ctx context.Context) *sql.DB
+}
+
+// WithReadReplicas 让仓储的只读查询（详情、列表与统计）按 ctx 读只读副本，写操作与事务始终使用主库
+func WithReadReplicas(readers ReadReplicas) Option {
+	return func(o *options) {
+		o.readers = readers
+	}
+}
+
+// readRouting 为仓储的只读查询选择主库或只读副本
+type readRouting struct {
+	primary *sql.DB
+	readers ReadReplicas
+}
+
+// newReadRouting 按选项创建读路由，未设置 WithReadReplicas 时只读查询也使用主库
+func newReadRouting(primary *sql.DB, opts []Option) readRouting {
+	return readRouting{primary: primary, readers: newOptions(opts).readers}
+}
+
+// reader 返回只读查询使用的连接
+func (r readRouting) reader(ctx context.Context) *sql.DB {
+	if r.readers == nil {
+		return r.primary
+	}
+	return r.readers.Reader(ctx)
+}
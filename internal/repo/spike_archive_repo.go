@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeArchiveRepository 定义秒杀活动与订单归档接口：把早已结束的数据迁移到 *_archive 表后从主表删除。
+// 每批在一个事务中完成复制与删除，以 SKIP LOCKED 领取行，多个实例同时归档互不重复
+type SpikeArchiveRepository interface {
+	// ArchiveOrders 归档创建时间早于 before 的终态订单（已支付、已取消、已过期）及其时间线事件，
+	// 包含已软删除的订单，每批最多 limit 条，返回归档的订单数
+	ArchiveOrders(ctx context.Context, before time.Time, limit int) (int64, error)
+	// ArchiveEvents 归档结束时间早于 before 且已没有订单的已结束、已取消活动，
+	// 包含已软删除的活动，每批最多 limit 个，返回归档的活动数；活动的订单应先由 ArchiveOrders 归档
+	ArchiveEvents(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// spikeArchiveRepo 实现SpikeArchiveRepository接口
+type spikeArchiveRepo struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewSpikeArchiveRepository 创建秒杀归档仓储实例
+func NewSpikeArchiveRepository(db *sql.DB, opts ...Option) SpikeArchiveRepository {
+	return &spikeArchiveRepo{db: db, queryTimeout: newQueryTimeout(opts)}
+}
+
+// ArchiveOrders 归档一批终态订单。
+// 归档表与主表列顺序一致，以 SELECT * 追加归档时间复制；删除订单时时间线事件随外键级联删除
+func (r *spikeArchiveRepo) ArchiveOrders(ctx context.Context, before time.Time, limit int) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := lockArchiveIDs(ctx, tx, `
+		SELECT id FROM spike_orders
+		WHERE status IN (?, ?, ?) AND created_at < ?
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, domain.SpikeOrderStatusPaid, domain.SpikeOrderStatusCancelled, domain.SpikeOrderStatusExpired, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock spike orders: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	in, args := archiveInClause(ids)
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `INSERT INTO order_events_archive SELECT e.*, ? FROM order_events e WHERE e.spike_order_id IN `+in,
+		append([]interface{}{now}, args...)...); err != nil {
+		return 0, fmt.Errorf("failed to archive order events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO spike_orders_archive SELECT o.*, ? FROM spike_orders o WHERE o.id IN `+in,
+		append([]interface{}{now}, args...)...); err != nil {
+		return 0, fmt.Errorf("failed to archive spike orders: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM spike_orders WHERE id IN `+in, args...); err != nil {
+		return 0, fmt.Errorf("failed to delete archived spike orders: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int64(len(ids)), nil
+}
+
+// ArchiveEvents 归档一批已结束的活动，仍有订单的活动留待订单归档后再处理
+func (r *spikeArchiveRepo) ArchiveEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := lockArchiveIDs(ctx, tx, `
+		SELECT e.id FROM spike_events e
+		WHERE e.status IN (?, ?) AND e.end_at < ?
+			AND NOT EXISTS (SELECT 1 FROM spike_orders o WHERE o.spike_event_id = e.id)
+		ORDER BY e.id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, domain.SpikeEventStatusEnded, domain.SpikeEventStatusCancelled, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock spike events: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	in, args := archiveInClause(ids)
+	if _, err := tx.ExecContext(ctx, `INSERT INTO spike_events_archive SELECT e.*, ? FROM spike_events e WHERE e.id IN `+in,
+		append([]interface{}{time.Now()}, args...)...); err != nil {
+		return 0, fmt.Errorf("failed to archive spike events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM spike_events WHERE id IN `+in, args...); err != nil {
+		return 0, fmt.Errorf("failed to delete archived spike events: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int64(len(ids)), nil
+}
+
+// lockArchiveIDs 在事务中执行加锁查询，返回待归档的ID
+func lockArchiveIDs(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// archiveInClause 返回 IN 子句的占位符与参数
+func archiveInClause(ids []int64) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")", args
+}
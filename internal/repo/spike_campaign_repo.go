@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeCampaignRepository 定义秒杀专场数据访问接口
+type SpikeCampaignRepository interface {
+	Create(campaign *domain.SpikeCampaign) error
+	GetByID(id int64) (*domain.SpikeCampaign, error)
+	// AssignEvents 将活动加入专场（活动已属于其他专场时改为属于本专场），返回实际更新的活动数
+	AssignEvents(campaignID int64, eventIDs []int64) (int64, error)
+	// GetEventStats 按活动汇总专场内各活动的待支付与已支付订单
+	GetEventStats(campaignID int64) ([]*domain.SpikeCampaignEventStats, error)
+	// CountBuyers 统计专场内去重后的下单用户数
+	CountBuyers(campaignID int64) (int64, error)
+}
+
+// spikeCampaignRepo 实现SpikeCampaignRepository接口
+type spikeCampaignRepo struct {
+	db *sql.DB
+}
+
+// NewSpikeCampaignRepository 创建秒杀专场仓储实例
+func NewSpikeCampaignRepository(db *sql.DB) SpikeCampaignRepository {
+	return &spikeCampaignRepo{db: db}
+}
+
+// Create 创建秒杀专场
+func (r *spikeCampaignRepo) Create(campaign *domain.SpikeCampaign) error {
+	query := `
+		INSERT INTO spike_campaigns (name, description, max_purchases_per_user, start_at, end_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		campaign.Name,
+		campaign.Description,
+		campaign.MaxPurchasesPerUser,
+		campaign.StartAt,
+		campaign.EndAt,
+		campaign.CreatedAt,
+		campaign.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create spike campaign: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	campaign.ID = id
+	return nil
+}
+
+// GetByID 根据ID获取秒杀专场
+func (r *spikeCampaignRepo) GetByID(id int64) (*domain.SpikeCampaign, error) {
+	query := `
+		SELECT id, name, description, max_purchases_per_user, start_at, end_at, created_at, updated_at
+		FROM spike_campaigns
+		WHERE id = ?
+	`
+
+	var campaign domain.SpikeCampaign
+	var description sql.NullString
+	err := r.db.QueryRow(query, id).Scan(
+		&campaign.ID,
+		&campaign.Name,
+		&description,
+		&campaign.MaxPurchasesPerUser,
+		&campaign.StartAt,
+		&campaign.EndAt,
+		&campaign.CreatedAt,
+		&campaign.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSpikeCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike campaign: %w", err)
+	}
+
+	campaign.Description = description.String
+	return &campaign, nil
+}
+
+// AssignEvents 将活动加入专场
+func (r *spikeCampaignRepo) AssignEvents(campaignID int64, eventIDs []int64) (int64, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+
+	placeholders := make([]string, len(eventIDs))
+	args := make([]interface{}, 0, len(eventIDs)+1)
+	args = append(args, campaignID)
+	for i, id := range eventIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	query := fmt.Sprintf(`UPDATE spike_events SET spike_campaign_id = ?, updated_at = NOW() WHERE id IN (%s)`,
+		strings.Join(placeholders, ", "))
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign spike events to campaign: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return affected, nil
+}
+
+// GetEventStats 按活动汇总专场内各活动的订单，没有订单的活动也会返回
+func (r *spikeCampaignRepo) GetEventStats(campaignID int64) ([]*domain.SpikeCampaignEventStats, error) {
+	query := `
+		SELECT e.id, e.name, e.spike_stock, e.sold_count,
+			COUNT(o.id),
+			COALESCE(SUM(CASE WHEN o.status = 'paid' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN o.status = 'paid' THEN o.quantity ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN o.status = 'paid' THEN o.total_amount ELSE 0 END), 0),
+			COUNT(DISTINCT o.user_id)
+		FROM spike_events e
+		LEFT JOIN spike_orders o ON o.spike_event_id = e.id AND o.status IN ('pending', 'paid')
+		WHERE e.spike_campaign_id = ?
+		GROUP BY e.id, e.name, e.spike_stock, e.sold_count, e.start_at
+		ORDER BY e.start_at ASC, e.id ASC
+	`
+
+	rows, err := r.db.Query(query, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spike campaign stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*domain.SpikeCampaignEventStats
+	for rows.Next() {
+		var s domain.SpikeCampaignEventStats
+		if err := rows.Scan(
+			&s.SpikeEventID,
+			&s.Name,
+			&s.SpikeStock,
+			&s.SoldCount,
+			&s.Orders,
+			&s.PaidOrders,
+			&s.PaidQuantity,
+			&s.Revenue,
+			&s.Buyers,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan spike campaign stats: %w", err)
+		}
+		stats = append(stats, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate spike campaign stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CountBuyers 统计专场内去重后的下单用户数，只统计待支付和已支付订单
+func (r *spikeCampaignRepo) CountBuyers(campaignID int64) (int64, error) {
+	query := `
+		SELECT COUNT(DISTINCT o.user_id)
+		FROM spike_orders o
+		JOIN spike_events e ON e.id = o.spike_event_id
+		WHERE e.spike_campaign_id = ? AND o.status IN ('pending', 'paid')
+	`
+
+	var buyers int64
+	if err := r.db.QueryRow(query, campaignID).Scan(&buyers); err != nil {
+		return 0, fmt.Errorf("failed to count spike campaign buyers: %w", err)
+	}
+	return buyers, nil
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeDashboardRepository 定义管理后台秒杀总览的聚合查询接口
+type SpikeDashboardRepository interface {
+	// CountEventsByStatus 按状态统计秒杀活动数
+	CountEventsByStatus(ctx context.Context) (map[domain.SpikeEventStatus]int64, error)
+	// SumOrdersByStatus 按状态汇总秒杀订单的订单数、件数与金额
+	SumOrdersByStatus(ctx context.Context) ([]*domain.SpikeDashboardOrderStats, error)
+	// ListTopEvents 按已售数量降序返回前 limit 个活动及其已支付订单与金额
+	ListTopEvents(ctx context.Context, limit int) ([]*domain.SpikeDashboardEvent, error)
+}
+
+// spikeDashboardRepo 实现SpikeDashboardRepository接口
+type spikeDashboardRepo struct {
+	db *sql.DB
+	queryTimeout
+	readRouting
+}
+
+// NewSpikeDashboardRepository 创建秒杀总览仓储实例
+func NewSpikeDashboardRepository(db *sql.DB, opts ...Option) SpikeDashboardRepository {
+	return &spikeDashboardRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
+}
+
+// CountEventsByStatus 按状态统计未删除的秒杀活动数
+func (r *spikeDashboardRepo) CountEventsByStatus(ctx context.Context) (map[domain.SpikeEventStatus]int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.reader(ctx).QueryContext(ctx, `
+		SELECT status, COUNT(*)
+		FROM spike_events
+		WHERE deleted_at IS NULL
+		GROUP BY status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count spike events by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.SpikeEventStatus]int64)
+	for rows.Next() {
+		var (
+			status domain.SpikeEventStatus
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan spike event count: %w", err)
+		}
+		counts[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate spike event counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// SumOrdersByStatus 按状态汇总未删除的秒杀订单，只返回有订单的状态
+func (r *spikeDashboardRepo) SumOrdersByStatus(ctx context.Context) ([]*domain.SpikeDashboardOrderStats, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.reader(ctx).QueryContext(ctx, `
+		SELECT status, COUNT(*), COALESCE(SUM(quantity), 0), COALESCE(SUM(total_amount), 0)
+		FROM spike_orders
+		WHERE deleted_at IS NULL
+		GROUP BY status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spike orders by status: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*domain.SpikeDashboardOrderStats
+	for rows.Next() {
+		var s domain.SpikeDashboardOrderStats
+		if err := rows.Scan(&s.Status, &s.Orders, &s.Quantity, &s.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan spike order stats: %w", err)
+		}
+		stats = append(stats, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate spike order stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ListTopEvents 先按已售数量取前 limit 个活动，再只对这些活动汇总已支付订单
+func (r *spikeDashboardRepo) ListTopEvents(ctx context.Context, limit int) ([]*domain.SpikeDashboardEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.reader(ctx).QueryContext(ctx, `
+		SELECT e.id, e.name, e.status, e.spike_stock, e.sold_count,
+			COUNT(o.id),
+			COALESCE(SUM(o.total_amount), 0)
+		FROM (
+			SELECT id, name, status, spike_stock, sold_count
+			FROM spike_events
+			WHERE deleted_at IS NULL
+			ORDER BY sold_count DESC, id DESC
+			LIMIT ?
+		) e
+		LEFT JOIN spike_orders o ON o.spike_event_id = e.id AND o.status = 'paid' AND o.deleted_at IS NULL
+		GROUP BY e.id, e.name, e.status, e.spike_stock, e.sold_count
+		ORDER BY e.sold_count DESC, e.id DESC
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top spike events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.SpikeDashboardEvent
+	for rows.Next() {
+		var e domain.SpikeDashboardEvent
+		if err := rows.Scan(&e.SpikeEventID, &e.Name, &e.Status, &e.SpikeStock, &e.SoldCount,
+			&e.PaidOrders, &e.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan top spike event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate top spike events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeEventPublicationRepository 定义活动发布计划数据访问接口
+type SpikeEventPublicationRepository interface {
+	// Upsert 创建或覆盖活动的发布计划，覆盖时清空审批信息
+	Upsert(ctx context.Context, publication *domain.SpikeEventPublication) error
+	// GetByEventID 获取活动的发布计划，不存在时返回 nil
+	GetByEventID(ctx context.Context, eventID int64) (*domain.SpikeEventPublication, error)
+	// GetByEventIDs 批量获取发布计划，按活动ID索引
+	GetByEventIDs(ctx context.Context, eventIDs []int64) (map[int64]*domain.SpikeEventPublication, error)
+	// Approve 仅当计划未审批且审批人不是排期人时记录审批，返回是否更新
+	Approve(ctx context.Context, eventID, approverID int64, at time.Time) (bool, error)
+	// Delete 删除活动的发布计划
+	Delete(ctx context.Context, eventID int64) error
+	// GetDueEventIDs 获取已审批、发布时间已到且仍处于待发布状态的活动ID
+	GetDueEventIDs(ctx context.Context, now time.Time) ([]int64, error)
+}
+
+// spikeEventPublicationRepo 实现SpikeEventPublicationRepository接口
+type spikeEventPublicationRepo struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewSpikeEventPublicationRepository 创建活动发布计划仓储实例
+func NewSpikeEventPublicationRepository(db *sql.DB, opts ...Option) SpikeEventPublicationRepository {
+	return &spikeEventPublicationRepo{db: db, queryTimeout: newQueryTimeout(opts)}
+}
+
+// Upsert 创建或覆盖发布计划
+func (r *spikeEventPublicationRepo) Upsert(ctx context.Context, publication *domain.SpikeEventPublication) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO spike_event_publications (spike_event_id, publish_at, scheduled_by)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE publish_at = VALUES(publish_at), scheduled_by = VALUES(scheduled_by),
+			approved_by = NULL, approved_at = NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, publication.SpikeEventID, publication.PublishAt, publication.ScheduledBy); err != nil {
+		return fmt.Errorf("failed to upsert spike event publication: %w", err)
+	}
+	return nil
+}
+
+// GetByEventID 获取活动的发布计划
+func (r *spikeEventPublicationRepo) GetByEventID(ctx context.Context, eventID int64) (*domain.SpikeEventPublication, error) {
+	publications, err := r.GetByEventIDs(ctx, []int64{eventID})
+	if err != nil {
+		return nil, err
+	}
+	return publications[eventID], nil
+}
+
+// GetByEventIDs 批量获取发布计划
+func (r *spikeEventPublicationRepo) GetByEventIDs(ctx context.Context, eventIDs []int64) (map[int64]*domain.SpikeEventPublication, error) {
+	publications := make(map[int64]*domain.SpikeEventPublication, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return publications, nil
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	placeholders := make([]string, len(eventIDs))
+	args := make([]interface{}, len(eventIDs))
+	for i, id := range eventIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	query := fmt.Sprintf(`
+		SELECT spike_event_id, publish_at, scheduled_by, approved_by, approved_at, created_at, updated_at
+		FROM spike_event_publications
+		WHERE spike_event_id IN (%s)
+	`, strings.Join(placeholders, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spike event publications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p domain.SpikeEventPublication
+		if err := rows.Scan(&p.SpikeEventID, &p.PublishAt, &p.ScheduledBy, &p.ApprovedBy, &p.ApprovedAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan spike event publication: %w", err)
+		}
+		publications[p.SpikeEventID] = &p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate spike event publications: %w", err)
+	}
+	return publications, nil
+}
+
+// Approve 条件更新审批信息，并发审批时只有一个成功
+func (r *spikeEventPublicationRepo) Approve(ctx context.Context, eventID, approverID int64, at time.Time) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE spike_event_publications
+		SET approved_by = ?, approved_at = ?
+		WHERE spike_event_id = ? AND approved_by IS NULL AND scheduled_by <> ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, approverID, at, eventID, approverID)
+	if err != nil {
+		return false, fmt.Errorf("failed to approve spike event publication: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete 删除发布计划
+func (r *spikeEventPublicationRepo) Delete(ctx context.Context, eventID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM spike_event_publications WHERE spike_event_id = ?`, eventID); err != nil {
+		return fmt.Errorf("failed to delete spike event publication: %w", err)
+	}
+	return nil
+}
+
+// GetDueEventIDs 获取到发布时间的已审批活动，按发布时间先后排列
+func (r *spikeEventPublicationRepo) GetDueEventIDs(ctx context.Context, now time.Time) ([]int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT p.spike_event_id
+		FROM spike_event_publications p
+		JOIN spike_events e ON e.id = p.spike_event_id
+		WHERE e.status = ? AND e.deleted_at IS NULL
+			AND p.approved_by IS NOT NULL AND p.publish_at <= ?
+		ORDER BY p.publish_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.SpikeEventStatusScheduled, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due spike event publications: %w", err)
+	}
+	defer rows.Close()
+
+	var eventIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan spike event id: %w", err)
+		}
+		eventIDs = append(eventIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate due spike event publications: %w", err)
+	}
+	return eventIDs, nil
+}
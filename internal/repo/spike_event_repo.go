@@ -2,6 +2,7 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -13,53 +14,82 @@ import (
 // SpikeEventRepository 定义秒杀活动数据访问接口
 type SpikeEventRepository interface {
 	// 基本CRUD操作
-	Create(event *domain.SpikeEvent) error
-	GetByID(id int64) (*domain.SpikeEvent, error)
-	Update(event *domain.SpikeEvent) error
-	Delete(id int64) error
+	Create(ctx context.Context, event *domain.SpikeEvent) error
+	// CreateBatch 在同一事务中创建多个秒杀活动，任一失败时全部回滚
+	CreateBatch(ctx context.Context, events []*domain.SpikeEvent) error
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	Update(ctx context.Context, event *domain.SpikeEvent) error
+	// Delete 软删除活动；除 List 指定 IncludeDeleted 外，查询均不返回已删除的活动
+	Delete(ctx context.Context, id int64) error
 
 	// 查询操作
-	List(req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error)
-	GetByProductID(productID int64) ([]*domain.SpikeEvent, error)
-	GetActiveEvents() ([]*domain.SpikeEvent, error)
-	GetEventsByTimeRange(start, end time.Time) ([]*domain.SpikeEvent, error)
+	// List 分页查询；req.SkipTotal 为 true 时不统计总数（返回 domain.TotalNotCounted），并多取一行供调用方判断是否还有下一页
+	List(ctx context.Context, req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error)
+	GetByProductID(ctx context.Context, productID int64) ([]*domain.SpikeEvent, error)
+	GetActiveEvents(ctx context.Context) ([]*domain.SpikeEvent, error)
+	GetEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*domain.SpikeEvent, error)
+	// GetPreviewEvents 获取已进入预告期但尚未开始的活动
+	GetPreviewEvents(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error)
+	// GetUpcomingEvents 获取开始时间在 (now, until] 内的待开始活动
+	GetUpcomingEvents(ctx context.Context, now, until time.Time) ([]*domain.SpikeEvent, error)
+	// GetEventsDueForTransition 获取到开始时间仍待开始、或到结束时间仍未结束的活动
+	GetEventsDueForTransition(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error)
 
 	// 业务特定操作
-	UpdateSoldCount(id int64, count int64) error
-	UpdateStatus(id int64, status domain.SpikeEventStatus) error
-	GetCurrentActiveEventByProductID(productID int64) (*domain.SpikeEvent, error)
+	UpdateSoldCount(ctx context.Context, id int64, count int64) error
+	// AddSoldCount 原子性调整已售数量，调整后超出总库存或小于 0 时不更新并返回 false
+	AddSoldCount(ctx context.Context, id int64, delta int64) (bool, error)
+	// IncreaseStock 原子性增加未结束活动的总库存，活动已结束或已取消时返回 domain.ErrSpikeEventNotRestockable
+	IncreaseStock(ctx context.Context, id int64, delta int64) error
+	UpdateStatus(ctx context.Context, id int64, status domain.SpikeEventStatus) error
+	// TransitionStatus 仅当活动当前状态为 from 时更新为 to，返回是否更新；多实例并发流转时只有一个成功
+	TransitionStatus(ctx context.Context, id int64, from, to domain.SpikeEventStatus) (bool, error)
+	GetCurrentActiveEventByProductID(ctx context.Context, productID int64) (*domain.SpikeEvent, error)
 
 	// 统计操作
-	Count() (int64, error)
-	CountByStatus(status domain.SpikeEventStatus) (int64, error)
+	Count(ctx context.Context) (int64, error)
+	CountByStatus(ctx context.Context, status domain.SpikeEventStatus) (int64, error)
 }
 
 // spikeEventRepo 实现SpikeEventRepository接口
 type spikeEventRepo struct {
 	db *sql.DB
+	queryTimeout
+	readRouting
 }
 
 // NewSpikeEventRepository 创建秒杀活动仓储实例
-func NewSpikeEventRepository(db *sql.DB) SpikeEventRepository {
-	return &spikeEventRepo{db: db}
+func NewSpikeEventRepository(db *sql.DB, opts ...Option) SpikeEventRepository {
+	return &spikeEventRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
 }
 
 // Create 创建秒杀活动
-func (r *spikeEventRepo) Create(event *domain.SpikeEvent) error {
+func (r *spikeEventRepo) Create(ctx context.Context, event *domain.SpikeEvent) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		INSERT INTO spike_events (product_id, name, description, spike_price, original_price, 
-			spike_stock, sold_count, start_at, end_at, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO spike_events (product_id, variant_id, name, description, spike_price, original_price, 
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		event.ProductID,
+		event.VariantID,
 		event.Name,
 		event.Description,
 		event.SpikePrice,
 		event.OriginalPrice,
 		event.SpikeStock,
 		event.SoldCount,
+		event.PreviewStartAt,
+		event.SpikeCampaignID,
+		event.MaxPerUser,
+		event.QPSLimit,
+		event.StockBuckets,
+		event.ChallengeRequired,
+		event.WaitingRoomRate,
 		event.StartAt,
 		event.EndAt,
 		event.Status,
@@ -78,25 +108,96 @@ func (r *spikeEventRepo) Create(event *domain.SpikeEvent) error {
 	return nil
 }
 
+// CreateBatch 在同一事务中创建多个秒杀活动，成功后回填各活动ID
+func (r *spikeEventRepo) CreateBatch(ctx context.Context, events []*domain.SpikeEvent) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO spike_events (product_id, variant_id, name, description, spike_price, original_price, 
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare spike event insert: %w", err)
+	}
+	defer stmt.Close()
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		result, err := stmt.ExecContext(ctx,
+			event.ProductID,
+			event.VariantID,
+			event.Name,
+			event.Description,
+			event.SpikePrice,
+			event.OriginalPrice,
+			event.SpikeStock,
+			event.SoldCount,
+			event.PreviewStartAt,
+			event.SpikeCampaignID,
+			event.MaxPerUser,
+			event.QPSLimit,
+			event.StockBuckets,
+			event.ChallengeRequired,
+			event.WaitingRoomRate,
+			event.StartAt,
+			event.EndAt,
+			event.Status,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create spike event %d: %w", i+1, err)
+		}
+		if ids[i], err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for i, event := range events {
+		event.ID = ids[i]
+	}
+	return nil
+}
+
 // GetByID 根据ID获取秒杀活动
-func (r *spikeEventRepo) GetByID(id int64) (*domain.SpikeEvent, error) {
+func (r *spikeEventRepo) GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, product_id, name, description, spike_price, original_price,
-			spike_stock, sold_count, start_at, end_at, status, created_at, updated_at
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
 		FROM spike_events
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	event := &domain.SpikeEvent{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.reader(ctx).QueryRowContext(ctx, query, id).Scan(
 		&event.ID,
 		&event.ProductID,
+		&event.VariantID,
 		&event.Name,
 		&event.Description,
 		&event.SpikePrice,
 		&event.OriginalPrice,
 		&event.SpikeStock,
 		&event.SoldCount,
+		&event.PreviewStartAt,
+		&event.SpikeCampaignID,
+		&event.MaxPerUser,
+		&event.QPSLimit,
+		&event.StockBuckets,
+		&event.ChallengeRequired,
+		&event.WaitingRoomRate,
 		&event.StartAt,
 		&event.EndAt,
 		&event.Status,
@@ -106,7 +207,7 @@ func (r *spikeEventRepo) GetByID(id int64) (*domain.SpikeEvent, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("spike event with id %d not found", id)
+			return nil, fmt.Errorf("%w: id %d", domain.ErrSpikeEventNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get spike event by id: %w", err)
 	}
@@ -115,22 +216,33 @@ func (r *spikeEventRepo) GetByID(id int64) (*domain.SpikeEvent, error) {
 }
 
 // Update 更新秒杀活动
-func (r *spikeEventRepo) Update(event *domain.SpikeEvent) error {
+func (r *spikeEventRepo) Update(ctx context.Context, event *domain.SpikeEvent) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE spike_events 
-		SET product_id = ?, name = ?, description = ?, spike_price = ?, original_price = ?,
-			spike_stock = ?, sold_count = ?, start_at = ?, end_at = ?, status = ?
+		SET product_id = ?, variant_id = ?, name = ?, description = ?, spike_price = ?, original_price = ?,
+			spike_stock = ?, sold_count = ?, preview_start_at = ?, spike_campaign_id = ?, max_per_user = ?, qps_limit = ?, stock_buckets = ?, challenge_required = ?, waiting_room_rate = ?, start_at = ?, end_at = ?, status = ?
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		event.ProductID,
+		event.VariantID,
 		event.Name,
 		event.Description,
 		event.SpikePrice,
 		event.OriginalPrice,
 		event.SpikeStock,
 		event.SoldCount,
+		event.PreviewStartAt,
+		event.SpikeCampaignID,
+		event.MaxPerUser,
+		event.QPSLimit,
+		event.StockBuckets,
+		event.ChallengeRequired,
+		event.WaitingRoomRate,
 		event.StartAt,
 		event.EndAt,
 		event.Status,
@@ -147,17 +259,20 @@ func (r *spikeEventRepo) Update(event *domain.SpikeEvent) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike event with id %d not found", event.ID)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeEventNotFound, event.ID)
 	}
 
 	return nil
 }
 
-// Delete 删除秒杀活动
-func (r *spikeEventRepo) Delete(id int64) error {
-	query := `DELETE FROM spike_events WHERE id = ?`
+// Delete 软删除秒杀活动，已删除的活动默认不再出现在查询结果中，由归档任务迁移到归档表
+func (r *spikeEventRepo) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE spike_events SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete spike event: %w", err)
 	}
@@ -168,18 +283,25 @@ func (r *spikeEventRepo) Delete(id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike event with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeEventNotFound, id)
 	}
 
 	return nil
 }
 
 // List 分页查询秒杀活动列表
-func (r *spikeEventRepo) List(req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error) {
+func (r *spikeEventRepo) List(ctx context.Context, req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	// 构建WHERE条件
 	var conditions []string
 	var args []interface{}
 
+	if !req.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
 	if req.ProductID != nil {
 		conditions = append(conditions, "product_id = ?")
 		args = append(args, *req.ProductID)
@@ -190,20 +312,31 @@ func (r *spikeEventRepo) List(req *domain.SpikeEventListRequest) ([]*domain.Spik
 		args = append(args, *req.Status)
 	}
 
+	if req.Unpublished {
+		conditions = append(conditions, "status IN (?, ?)")
+		args = append(args, domain.SpikeEventStatusDraft, domain.SpikeEventStatusScheduled)
+	}
+
 	if req.Active != nil && *req.Active {
 		now := time.Now()
 		conditions = append(conditions, "status = ? AND start_at <= ? AND end_at > ?")
 		args = append(args, domain.SpikeEventStatusActive, now, now)
 	}
 
+	if req.After != nil {
+		cond, cursorArgs := keysetCondition("", req.After, req.Descending())
+		conditions = append(conditions, cond)
+		args = append(args, cursorArgs...)
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	// 构建排序
+	// 构建排序，使用游标时固定按 created_at 排序
 	sortBy := "created_at"
-	if req.SortBy != nil {
+	if req.SortBy != nil && req.After == nil {
 		switch *req.SortBy {
 		case "start_at", "spike_price", "created_at":
 			sortBy = *req.SortBy
@@ -211,16 +344,18 @@ func (r *spikeEventRepo) List(req *domain.SpikeEventListRequest) ([]*domain.Spik
 	}
 
 	sortOrder := "DESC"
-	if req.SortOrder != nil && strings.ToUpper(*req.SortOrder) == "ASC" {
+	if !req.Descending() {
 		sortOrder = "ASC"
 	}
 
-	// 查询总数
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spike_events %s", whereClause)
-	var total int64
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count spike events: %w", err)
+	// 查询总数，跳过统计或使用游标时改为多取一行判断是否还有下一页
+	total := domain.TotalNotCounted
+	skipTotal := req.SkipTotal || req.After != nil
+	if !skipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spike_events %s", whereClause)
+		if err := r.reader(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count spike events: %w", err)
+		}
 	}
 
 	// 分页参数
@@ -231,18 +366,21 @@ func (r *spikeEventRepo) List(req *domain.SpikeEventListRequest) ([]*domain.Spik
 		req.PageSize = 20
 	}
 	offset := (req.Page - 1) * req.PageSize
+	if req.After != nil {
+		offset = 0
+	}
 
 	// 查询数据
 	query := fmt.Sprintf(`
-		SELECT id, product_id, name, description, spike_price, original_price,
-			spike_stock, sold_count, start_at, end_at, status, created_at, updated_at
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at, deleted_at
 		FROM spike_events %s
-		ORDER BY %s %s
+		ORDER BY %s %s, id %s
 		LIMIT ? OFFSET ?
-	`, whereClause, sortBy, sortOrder)
+	`, whereClause, sortBy, sortOrder, sortOrder)
 
-	args = append(args, req.PageSize, offset)
-	rows, err := r.db.Query(query, args...)
+	args = append(args, domain.LimitWithLookahead(req.PageSize, skipTotal), offset)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query spike events: %w", err)
 	}
@@ -254,17 +392,26 @@ func (r *spikeEventRepo) List(req *domain.SpikeEventListRequest) ([]*domain.Spik
 		err := rows.Scan(
 			&event.ID,
 			&event.ProductID,
+			&event.VariantID,
 			&event.Name,
 			&event.Description,
 			&event.SpikePrice,
 			&event.OriginalPrice,
 			&event.SpikeStock,
 			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
 			&event.StartAt,
 			&event.EndAt,
 			&event.Status,
 			&event.CreatedAt,
 			&event.UpdatedAt,
+			&event.DeletedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan spike event: %w", err)
@@ -280,16 +427,19 @@ func (r *spikeEventRepo) List(req *domain.SpikeEventListRequest) ([]*domain.Spik
 }
 
 // GetByProductID 根据商品ID获取秒杀活动列表
-func (r *spikeEventRepo) GetByProductID(productID int64) ([]*domain.SpikeEvent, error) {
+func (r *spikeEventRepo) GetByProductID(ctx context.Context, productID int64) ([]*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, product_id, name, description, spike_price, original_price,
-			spike_stock, sold_count, start_at, end_at, status, created_at, updated_at
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
 		FROM spike_events
-		WHERE product_id = ?
+		WHERE product_id = ? AND deleted_at IS NULL
 		ORDER BY start_at DESC
 	`
 
-	rows, err := r.db.Query(query, productID)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, productID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query spike events by product id: %w", err)
 	}
@@ -301,12 +451,20 @@ func (r *spikeEventRepo) GetByProductID(productID int64) ([]*domain.SpikeEvent,
 		err := rows.Scan(
 			&event.ID,
 			&event.ProductID,
+			&event.VariantID,
 			&event.Name,
 			&event.Description,
 			&event.SpikePrice,
 			&event.OriginalPrice,
 			&event.SpikeStock,
 			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
 			&event.StartAt,
 			&event.EndAt,
 			&event.Status,
@@ -323,17 +481,20 @@ func (r *spikeEventRepo) GetByProductID(productID int64) ([]*domain.SpikeEvent,
 }
 
 // GetActiveEvents 获取当前活跃的秒杀活动
-func (r *spikeEventRepo) GetActiveEvents() ([]*domain.SpikeEvent, error) {
+func (r *spikeEventRepo) GetActiveEvents(ctx context.Context) ([]*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	now := time.Now()
 	query := `
-		SELECT id, product_id, name, description, spike_price, original_price,
-			spike_stock, sold_count, start_at, end_at, status, created_at, updated_at
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
 		FROM spike_events
-		WHERE status = ? AND start_at <= ? AND end_at > ?
+		WHERE status = ? AND start_at <= ? AND end_at > ? AND deleted_at IS NULL
 		ORDER BY start_at ASC
 	`
 
-	rows, err := r.db.Query(query, domain.SpikeEventStatusActive, now, now)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, domain.SpikeEventStatusActive, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active spike events: %w", err)
 	}
@@ -345,12 +506,20 @@ func (r *spikeEventRepo) GetActiveEvents() ([]*domain.SpikeEvent, error) {
 		err := rows.Scan(
 			&event.ID,
 			&event.ProductID,
+			&event.VariantID,
 			&event.Name,
 			&event.Description,
 			&event.SpikePrice,
 			&event.OriginalPrice,
 			&event.SpikeStock,
 			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
 			&event.StartAt,
 			&event.EndAt,
 			&event.Status,
@@ -367,16 +536,19 @@ func (r *spikeEventRepo) GetActiveEvents() ([]*domain.SpikeEvent, error) {
 }
 
 // GetEventsByTimeRange 根据时间范围获取秒杀活动
-func (r *spikeEventRepo) GetEventsByTimeRange(start, end time.Time) ([]*domain.SpikeEvent, error) {
+func (r *spikeEventRepo) GetEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, product_id, name, description, spike_price, original_price,
-			spike_stock, sold_count, start_at, end_at, status, created_at, updated_at
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
 		FROM spike_events
-		WHERE start_at < ? AND end_at > ?
+		WHERE start_at < ? AND end_at > ? AND deleted_at IS NULL
 		ORDER BY start_at ASC
 	`
 
-	rows, err := r.db.Query(query, end, start)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, end, start)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query spike events by time range: %w", err)
 	}
@@ -388,12 +560,184 @@ func (r *spikeEventRepo) GetEventsByTimeRange(start, end time.Time) ([]*domain.S
 		err := rows.Scan(
 			&event.ID,
 			&event.ProductID,
+			&event.VariantID,
+			&event.Name,
+			&event.Description,
+			&event.SpikePrice,
+			&event.OriginalPrice,
+			&event.SpikeStock,
+			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
+			&event.StartAt,
+			&event.EndAt,
+			&event.Status,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spike event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetPreviewEvents 获取已进入预告期但尚未开始的活动
+func (r *spikeEventRepo) GetPreviewEvents(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
+		FROM spike_events
+		WHERE preview_start_at IS NOT NULL AND preview_start_at <= ? AND start_at > ? AND deleted_at IS NULL
+			AND status IN (?, ?)
+		ORDER BY start_at ASC
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, now, now, domain.SpikeEventStatusPending, domain.SpikeEventStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query preview spike events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.SpikeEvent
+	for rows.Next() {
+		event := &domain.SpikeEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.ProductID,
+			&event.VariantID,
 			&event.Name,
 			&event.Description,
 			&event.SpikePrice,
 			&event.OriginalPrice,
 			&event.SpikeStock,
 			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
+			&event.StartAt,
+			&event.EndAt,
+			&event.Status,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spike event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetUpcomingEvents 获取开始时间在 (now, until] 内的待开始活动
+func (r *spikeEventRepo) GetUpcomingEvents(ctx context.Context, now, until time.Time) ([]*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
+		FROM spike_events
+		WHERE start_at > ? AND start_at <= ? AND status IN (?, ?) AND deleted_at IS NULL
+		ORDER BY start_at ASC
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, now, until, domain.SpikeEventStatusPending, domain.SpikeEventStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upcoming spike events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.SpikeEvent
+	for rows.Next() {
+		event := &domain.SpikeEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.ProductID,
+			&event.VariantID,
+			&event.Name,
+			&event.Description,
+			&event.SpikePrice,
+			&event.OriginalPrice,
+			&event.SpikeStock,
+			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
+			&event.StartAt,
+			&event.EndAt,
+			&event.Status,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spike event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetEventsDueForTransition 获取需要按时间流转状态的活动
+func (r *spikeEventRepo) GetEventsDueForTransition(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
+		FROM spike_events
+		WHERE ((status = ? AND start_at <= ?) OR (status IN (?, ?, ?) AND end_at <= ?)) AND deleted_at IS NULL
+		ORDER BY start_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.SpikeEventStatusPending, now,
+		domain.SpikeEventStatusPending, domain.SpikeEventStatusActive, domain.SpikeEventStatusPaused, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spike events due for transition: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.SpikeEvent
+	for rows.Next() {
+		event := &domain.SpikeEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.ProductID,
+			&event.VariantID,
+			&event.Name,
+			&event.Description,
+			&event.SpikePrice,
+			&event.OriginalPrice,
+			&event.SpikeStock,
+			&event.SoldCount,
+			&event.PreviewStartAt,
+			&event.SpikeCampaignID,
+			&event.MaxPerUser,
+			&event.QPSLimit,
+			&event.StockBuckets,
+			&event.ChallengeRequired,
+			&event.WaitingRoomRate,
 			&event.StartAt,
 			&event.EndAt,
 			&event.Status,
@@ -410,10 +754,13 @@ func (r *spikeEventRepo) GetEventsByTimeRange(start, end time.Time) ([]*domain.S
 }
 
 // UpdateSoldCount 更新已售数量
-func (r *spikeEventRepo) UpdateSoldCount(id int64, count int64) error {
+func (r *spikeEventRepo) UpdateSoldCount(ctx context.Context, id int64, count int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE spike_events SET sold_count = ? WHERE id = ?`
 
-	result, err := r.db.Exec(query, count, id)
+	result, err := r.db.ExecContext(ctx, query, count, id)
 	if err != nil {
 		return fmt.Errorf("failed to update sold count: %w", err)
 	}
@@ -424,17 +771,74 @@ func (r *spikeEventRepo) UpdateSoldCount(id int64, count int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike event with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeEventNotFound, id)
 	}
 
 	return nil
 }
 
+// AddSoldCount 原子性调整已售数量，缓存不可用时以数据库行锁代替 Redis 预减库存
+func (r *spikeEventRepo) AddSoldCount(ctx context.Context, id int64, delta int64) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE spike_events SET sold_count = sold_count + ?
+		WHERE id = ? AND sold_count + ? >= 0 AND sold_count + ? <= spike_stock
+	`
+
+	result, err := r.db.ExecContext(ctx, query, delta, id, delta, delta)
+	if err != nil {
+		return false, fmt.Errorf("failed to add sold count: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// IncreaseStock 增加活动总库存
+func (r *spikeEventRepo) IncreaseStock(ctx context.Context, id int64, delta int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE spike_events SET spike_stock = spike_stock + ? WHERE id = ? AND status IN (?, ?, ?)`
+
+	result, err := r.db.ExecContext(ctx, query, delta, id, domain.SpikeEventStatusPending, domain.SpikeEventStatusActive, domain.SpikeEventStatusPaused)
+	if err != nil {
+		return fmt.Errorf("failed to increase spike stock: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// 区分活动不存在与活动状态不允许补货
+	var status domain.SpikeEventStatus
+	err = r.db.QueryRowContext(ctx, `SELECT status FROM spike_events WHERE id = ?`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return domain.ErrSpikeEventNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get spike event status: %w", err)
+	}
+	return domain.ErrSpikeEventNotRestockable
+}
+
 // UpdateStatus 更新活动状态
-func (r *spikeEventRepo) UpdateStatus(id int64, status domain.SpikeEventStatus) error {
+func (r *spikeEventRepo) UpdateStatus(ctx context.Context, id int64, status domain.SpikeEventStatus) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE spike_events SET status = ? WHERE id = ?`
 
-	result, err := r.db.Exec(query, status, id)
+	result, err := r.db.ExecContext(ctx, query, status, id)
 	if err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
@@ -445,34 +849,62 @@ func (r *spikeEventRepo) UpdateStatus(id int64, status domain.SpikeEventStatus)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike event with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeEventNotFound, id)
 	}
 
 	return nil
 }
 
+// TransitionStatus 按当前状态条件更新活动状态
+func (r *spikeEventRepo) TransitionStatus(ctx context.Context, id int64, from, to domain.SpikeEventStatus) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE spike_events SET status = ? WHERE id = ? AND status = ?`, to, id, from)
+	if err != nil {
+		return false, fmt.Errorf("failed to transition status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // GetCurrentActiveEventByProductID 获取商品当前活跃的秒杀活动
-func (r *spikeEventRepo) GetCurrentActiveEventByProductID(productID int64) (*domain.SpikeEvent, error) {
+func (r *spikeEventRepo) GetCurrentActiveEventByProductID(ctx context.Context, productID int64) (*domain.SpikeEvent, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	now := time.Now()
 	query := `
-		SELECT id, product_id, name, description, spike_price, original_price,
-			spike_stock, sold_count, start_at, end_at, status, created_at, updated_at
+		SELECT id, product_id, variant_id, name, description, spike_price, original_price,
+			spike_stock, sold_count, preview_start_at, spike_campaign_id, max_per_user, qps_limit, stock_buckets, challenge_required, waiting_room_rate, start_at, end_at, status, created_at, updated_at
 		FROM spike_events
-		WHERE product_id = ? AND status = ? AND start_at <= ? AND end_at > ?
+		WHERE product_id = ? AND status = ? AND start_at <= ? AND end_at > ? AND deleted_at IS NULL
 		ORDER BY start_at DESC
 		LIMIT 1
 	`
 
 	event := &domain.SpikeEvent{}
-	err := r.db.QueryRow(query, productID, domain.SpikeEventStatusActive, now, now).Scan(
+	err := r.reader(ctx).QueryRowContext(ctx, query, productID, domain.SpikeEventStatusActive, now, now).Scan(
 		&event.ID,
 		&event.ProductID,
+		&event.VariantID,
 		&event.Name,
 		&event.Description,
 		&event.SpikePrice,
 		&event.OriginalPrice,
 		&event.SpikeStock,
 		&event.SoldCount,
+		&event.PreviewStartAt,
+		&event.SpikeCampaignID,
+		&event.MaxPerUser,
+		&event.QPSLimit,
+		&event.StockBuckets,
+		&event.ChallengeRequired,
+		&event.WaitingRoomRate,
 		&event.StartAt,
 		&event.EndAt,
 		&event.Status,
@@ -491,11 +923,14 @@ func (r *spikeEventRepo) GetCurrentActiveEventByProductID(productID int64) (*dom
 }
 
 // Count 统计秒杀活动总数
-func (r *spikeEventRepo) Count() (int64, error) {
-	query := `SELECT COUNT(*) FROM spike_events`
+func (r *spikeEventRepo) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM spike_events WHERE deleted_at IS NULL`
 
 	var count int64
-	err := r.db.QueryRow(query).Scan(&count)
+	err := r.reader(ctx).QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count spike events: %w", err)
 	}
@@ -504,11 +939,14 @@ func (r *spikeEventRepo) Count() (int64, error) {
 }
 
 // CountByStatus 根据状态统计秒杀活动数量
-func (r *spikeEventRepo) CountByStatus(status domain.SpikeEventStatus) (int64, error) {
-	query := `SELECT COUNT(*) FROM spike_events WHERE status = ?`
+func (r *spikeEventRepo) CountByStatus(ctx context.Context, status domain.SpikeEventStatus) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM spike_events WHERE status = ? AND deleted_at IS NULL`
 
 	var count int64
-	err := r.db.QueryRow(query, status).Scan(&count)
+	err := r.reader(ctx).QueryRowContext(ctx, query, status).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count spike events by status: %w", err)
 	}
@@ -2,72 +2,118 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
+
 	"github.com/MorseWayne/spike_shop/internal/domain"
 )
 
 // SpikeOrderRepository 定义秒杀订单数据访问接口
 type SpikeOrderRepository interface {
 	// 基本CRUD操作
-	Create(order *domain.SpikeOrder) error
-	GetByID(id int64) (*domain.SpikeOrder, error)
-	Update(order *domain.SpikeOrder) error
-	Delete(id int64) error
+	// Create 创建订单并回填ID；只允许参与一次的活动中用户已有订单时返回 domain.ErrSpikeOrderDuplicate
+	Create(ctx context.Context, order *domain.SpikeOrder) error
+	// CreateBatch 一条语句批量创建订单并回填ID，任一订单失败时全部不创建
+	CreateBatch(ctx context.Context, orders []*domain.SpikeOrder) error
+	GetByID(ctx context.Context, id int64) (*domain.SpikeOrder, error)
+	// GetDetailByID 一次 JOIN 查询订单及其活动、用户信息（不含密码哈希）
+	GetDetailByID(ctx context.Context, id int64) (*domain.SpikeOrderWithDetails, error)
+	Update(ctx context.Context, order *domain.SpikeOrder) error
+	// Delete 软删除订单；查询默认不返回已删除的订单（List 可指定 IncludeDeleted），
+	// 幂等键查询、过期订单扫描与参与计数、数量汇总仍包含已删除订单，以保证去重、库存归还与对账
+	Delete(ctx context.Context, id int64) error
 
 	// 查询操作
-	List(req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrder, int64, error)
-	GetByUserID(userID int64) ([]*domain.SpikeOrder, error)
-	GetBySpikeEventID(spikeEventID int64) ([]*domain.SpikeOrder, error)
-	GetByIdempotencyKey(key string) (*domain.SpikeOrder, error)
+	// List 分页查询；req.SkipTotal 为 true 时不统计总数（返回 domain.TotalNotCounted），并多取一行供调用方判断是否还有下一页
+	List(ctx context.Context, req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrder, int64, error)
+	// ListWithEvent 一次 JOIN 分页查询用户订单及所属活动摘要，分页语义同 List
+	ListWithEvent(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrderWithEvent, int64, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*domain.SpikeOrder, error)
+	GetBySpikeEventID(ctx context.Context, spikeEventID int64) ([]*domain.SpikeOrder, error)
+	GetByIdempotencyKey(ctx context.Context, key string) (*domain.SpikeOrder, error)
+	// GetByPaymentRef 按支付渠道交易ID精确查找订单，不存在时返回 nil, nil
+	GetByPaymentRef(ctx context.Context, paymentRef string) (*domain.SpikeOrder, error)
+	// GetByOrderNo 按订单号精确查找订单，不存在时返回 nil, nil
+	GetByOrderNo(ctx context.Context, orderNo string) (*domain.SpikeOrder, error)
 
 	// 业务特定操作
-	GetByUserAndEvent(userID, spikeEventID int64) (*domain.SpikeOrder, error)
-	UpdateStatus(id int64, status domain.SpikeOrderStatus) error
-	UpdateOrderID(id int64, orderID int64) error
-	UpdatePaymentInfo(id int64, paidAt time.Time) error
-	GetExpiredOrders(before time.Time) ([]*domain.SpikeOrder, error)
+	GetByUserAndEvent(ctx context.Context, userID, spikeEventID int64) (*domain.SpikeOrder, error)
+	UpdateStatus(ctx context.Context, id int64, status domain.SpikeOrderStatus) error
+	UpdateOrderID(ctx context.Context, id int64, orderID int64) error
+	// UpdatePaymentInfo 将订单标记为已支付并记录支付时间与支付渠道交易ID
+	UpdatePaymentInfo(ctx context.Context, id int64, paidAt time.Time, paymentRef string) error
+	// GetExpiredOrders 按过期时间升序获取 before 之前已过期的待支付订单，最多 limit 条
+	GetExpiredOrders(ctx context.Context, before time.Time, limit int) ([]*domain.SpikeOrder, error)
+	// MarkExpired 仅当订单仍为待支付且过期时间早于 before 时将其标记为已过期，返回是否更新
+	MarkExpired(ctx context.Context, id int64, before time.Time) (bool, error)
+	// ExtendExpireAt 在同一事务中延长待支付订单的过期时间并记录时间线事件，每个订单只能延长一次
+	ExtendExpireAt(ctx context.Context, id int64, extension time.Duration, event *domain.OrderEvent) (time.Time, error)
+	// CancelWithOutbox 在同一事务中取消订单、记录时间线事件并写入发件箱消息；
+	// 订单状态不允许取消时返回 domain.ErrSpikeOrderNotCancellable
+	CancelWithOutbox(ctx context.Context, id int64, event *domain.OrderEvent, message *domain.OutboxMessage) error
+	// GetPendingOrdersExpiringBetween 获取过期时间落在 [from, to) 内的待支付订单
+	GetPendingOrdersExpiringBetween(ctx context.Context, from, to time.Time) ([]*domain.SpikeOrder, error)
 
 	// 统计操作
-	Count() (int64, error)
-	CountByStatus(status domain.SpikeOrderStatus) (int64, error)
-	CountByUserAndEvent(userID, spikeEventID int64) (int64, error)
+	Count(ctx context.Context) (int64, error)
+	CountByStatus(ctx context.Context, status domain.SpikeOrderStatus) (int64, error)
+	CountByUserAndEvent(ctx context.Context, userID, spikeEventID int64) (int64, error)
+	// SumQuantityByEvent 统计活动下指定状态订单的购买数量之和
+	SumQuantityByEvent(ctx context.Context, spikeEventID int64, statuses ...domain.SpikeOrderStatus) (int64, error)
+	// GetOrderWindowByEvent 聚合统计活动下未删除订单的数量与最早、最晚下单时间，不加载订单明细
+	GetOrderWindowByEvent(ctx context.Context, spikeEventID int64) (*domain.SpikeOrderWindow, error)
 }
 
 // spikeOrderRepo 实现SpikeOrderRepository接口
 type spikeOrderRepo struct {
 	db *sql.DB
+	queryTimeout
+	readRouting
 }
 
 // NewSpikeOrderRepository 创建秒杀订单仓储实例
-func NewSpikeOrderRepository(db *sql.DB) SpikeOrderRepository {
-	return &spikeOrderRepo{db: db}
+func NewSpikeOrderRepository(db *sql.DB, opts ...Option) SpikeOrderRepository {
+	return &spikeOrderRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
 }
 
 // Create 创建秒杀订单
-func (r *spikeOrderRepo) Create(order *domain.SpikeOrder) error {
+func (r *spikeOrderRepo) Create(ctx context.Context, order *domain.SpikeOrder) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		INSERT INTO spike_orders (spike_event_id, user_id, order_id, quantity, spike_price, 
-			total_amount, status, idempotency_key, expire_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO spike_orders (order_no, spike_event_id, user_id, order_id, quantity, single_purchase, spike_price, 
+			total_amount, status, idempotency_key, expire_at, client_ip, user_agent, channel)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
+		order.OrderNo,
 		order.SpikeEventID,
 		order.UserID,
 		order.OrderID,
 		order.Quantity,
+		singlePurchase(order),
 		order.SpikePrice,
 		order.TotalAmount,
 		order.Status,
 		order.IdempotencyKey,
 		order.ExpireAt,
+		order.ClientIP,
+		order.UserAgent,
+		order.Channel,
 	)
 
 	if err != nil {
+		if isSinglePurchaseConflict(err) {
+			return domain.ErrSpikeOrderDuplicate
+		}
 		return fmt.Errorf("failed to create spike order: %w", err)
 	}
 
@@ -80,18 +126,99 @@ func (r *spikeOrderRepo) Create(order *domain.SpikeOrder) error {
 	return nil
 }
 
+// CreateBatch 批量创建秒杀订单。
+// 单条多行 INSERT 为已知行数的简单插入，InnoDB 为其分配连续的自增ID，LastInsertId 返回第一行的ID
+func (r *spikeOrderRepo) CreateBatch(ctx context.Context, orders []*domain.SpikeOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var query strings.Builder
+	query.WriteString(`
+		INSERT INTO spike_orders (order_no, spike_event_id, user_id, order_id, quantity, single_purchase, spike_price, 
+			total_amount, status, idempotency_key, expire_at, client_ip, user_agent, channel)
+		VALUES `)
+	args := make([]interface{}, 0, len(orders)*14)
+	for i, order := range orders {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			order.OrderNo,
+			order.SpikeEventID,
+			order.UserID,
+			order.OrderID,
+			order.Quantity,
+			singlePurchase(order),
+			order.SpikePrice,
+			order.TotalAmount,
+			order.Status,
+			order.IdempotencyKey,
+			order.ExpireAt,
+			order.ClientIP,
+			order.UserAgent,
+			order.Channel,
+		)
+	}
+
+	result, err := r.db.ExecContext(ctx, query.String(), args...)
+	if err != nil {
+		if isSinglePurchaseConflict(err) {
+			return domain.ErrSpikeOrderDuplicate
+		}
+		return fmt.Errorf("failed to create spike orders: %w", err)
+	}
+
+	firstID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	for i, order := range orders {
+		order.ID = firstID + int64(i)
+	}
+	return nil
+}
+
+// singlePurchaseKey 只允许参与一次的活动的用户活动唯一约束，single_purchase 为 NULL 的订单不受约束
+const singlePurchaseKey = "uk_user_spike_event_single"
+
+// singlePurchase 返回 single_purchase 列的值：只允许参与一次的活动为 1，其余为 NULL
+func singlePurchase(order *domain.SpikeOrder) interface{} {
+	if order.SinglePurchase {
+		return 1
+	}
+	return nil
+}
+
+// isSinglePurchaseConflict 判断插入是否违反了用户活动唯一约束
+func isSinglePurchaseConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry &&
+		strings.Contains(mysqlErr.Message, singlePurchaseKey)
+}
+
 // GetByID 根据ID获取秒杀订单
-func (r *spikeOrderRepo) GetByID(id int64) (*domain.SpikeOrder, error) {
+func (r *spikeOrderRepo) GetByID(ctx context.Context, id int64) (*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
-			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at,
+			client_ip, user_agent, channel, payment_ref
 		FROM spike_orders
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	order := &domain.SpikeOrder{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.reader(ctx).QueryRowContext(ctx, query, id).Scan(
 		&order.ID,
+		&order.OrderNo,
 		&order.SpikeEventID,
 		&order.UserID,
 		&order.OrderID,
@@ -105,11 +232,15 @@ func (r *spikeOrderRepo) GetByID(id int64) (*domain.SpikeOrder, error) {
 		&order.CancelledAt,
 		&order.CreatedAt,
 		&order.UpdatedAt,
+		&order.ClientIP,
+		&order.UserAgent,
+		&order.Channel,
+		&order.PaymentRef,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("spike order with id %d not found", id)
+			return nil, domain.ErrSpikeOrderNotFound
 		}
 		return nil, fmt.Errorf("failed to get spike order by id: %w", err)
 	}
@@ -117,8 +248,91 @@ func (r *spikeOrderRepo) GetByID(id int64) (*domain.SpikeOrder, error) {
 	return order, nil
 }
 
+// GetDetailByID 根据ID获取订单详情
+func (r *spikeOrderRepo) GetDetailByID(ctx context.Context, id int64) (*domain.SpikeOrderWithDetails, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT o.id, o.order_no, o.spike_event_id, o.user_id, o.order_id, o.quantity, o.spike_price, o.total_amount,
+			o.status, o.idempotency_key, o.expire_at, o.paid_at, o.cancelled_at, o.created_at, o.updated_at,
+			e.id, e.product_id, e.variant_id, e.name, e.description, e.spike_price, e.original_price,
+			e.spike_stock, e.sold_count, e.preview_start_at, e.spike_campaign_id, e.max_per_user, e.qps_limit, e.stock_buckets, e.challenge_required, e.waiting_room_rate, e.start_at, e.end_at, e.status, e.created_at, e.updated_at,
+			u.id, u.username, u.email, u.role, u.tier, u.is_active, u.created_at, u.updated_at
+		FROM spike_orders o
+		JOIN spike_events e ON e.id = o.spike_event_id
+		JOIN users u ON u.id = o.user_id
+		WHERE o.id = ? AND o.deleted_at IS NULL
+	`
+
+	order := &domain.SpikeOrder{}
+	event := &domain.SpikeEvent{}
+	user := &domain.User{}
+	err := r.reader(ctx).QueryRowContext(ctx, query, id).Scan(
+		&order.ID,
+		&order.OrderNo,
+		&order.SpikeEventID,
+		&order.UserID,
+		&order.OrderID,
+		&order.Quantity,
+		&order.SpikePrice,
+		&order.TotalAmount,
+		&order.Status,
+		&order.IdempotencyKey,
+		&order.ExpireAt,
+		&order.PaidAt,
+		&order.CancelledAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&event.ID,
+		&event.ProductID,
+		&event.VariantID,
+		&event.Name,
+		&event.Description,
+		&event.SpikePrice,
+		&event.OriginalPrice,
+		&event.SpikeStock,
+		&event.SoldCount,
+		&event.PreviewStartAt,
+		&event.SpikeCampaignID,
+		&event.MaxPerUser,
+		&event.QPSLimit,
+		&event.StockBuckets,
+		&event.ChallengeRequired,
+		&event.WaitingRoomRate,
+		&event.StartAt,
+		&event.EndAt,
+		&event.Status,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Role,
+		&user.Tier,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrSpikeOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get spike order detail: %w", err)
+	}
+
+	return &domain.SpikeOrderWithDetails{
+		SpikeOrder: order,
+		SpikeEvent: event,
+		User:       user,
+	}, nil
+}
+
 // Update 更新秒杀订单
-func (r *spikeOrderRepo) Update(order *domain.SpikeOrder) error {
+func (r *spikeOrderRepo) Update(ctx context.Context, order *domain.SpikeOrder) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE spike_orders 
 		SET spike_event_id = ?, user_id = ?, order_id = ?, quantity = ?, spike_price = ?,
@@ -126,7 +340,7 @@ func (r *spikeOrderRepo) Update(order *domain.SpikeOrder) error {
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		order.SpikeEventID,
 		order.UserID,
 		order.OrderID,
@@ -151,17 +365,20 @@ func (r *spikeOrderRepo) Update(order *domain.SpikeOrder) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike order with id %d not found", order.ID)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeOrderNotFound, order.ID)
 	}
 
 	return nil
 }
 
-// Delete 删除秒杀订单
-func (r *spikeOrderRepo) Delete(id int64) error {
-	query := `DELETE FROM spike_orders WHERE id = ?`
+// Delete 软删除秒杀订单，已删除的订单默认不再出现在查询结果中，由归档任务迁移到归档表
+func (r *spikeOrderRepo) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	result, err := r.db.Exec(query, id)
+	query := `UPDATE spike_orders SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to delete spike order: %w", err)
 	}
@@ -172,41 +389,139 @@ func (r *spikeOrderRepo) Delete(id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike order with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeOrderNotFound, id)
 	}
 
 	return nil
 }
 
 // List 分页查询秒杀订单列表
-func (r *spikeOrderRepo) List(req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrder, int64, error) {
-	// 构建WHERE条件
+func (r *spikeOrderRepo) List(ctx context.Context, req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrder, int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	whereClause, args, sortBy, sortOrder := spikeOrderListClauses(req, "")
+
+	// 查询总数，跳过统计或使用游标时改为多取一行判断是否还有下一页
+	total := domain.TotalNotCounted
+	skipTotal := req.SkipTotal || req.After != nil
+	if !skipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spike_orders %s", whereClause)
+		if err := r.reader(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count spike orders: %w", err)
+		}
+	}
+
+	// 分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	offset := (req.Page - 1) * req.PageSize
+	if req.After != nil {
+		offset = 0
+	}
+
+	// 查询数据
+	query := fmt.Sprintf(`
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at, deleted_at
+		FROM spike_orders %s
+		ORDER BY %s %s, id %s
+		LIMIT ? OFFSET ?
+	`, whereClause, sortBy, sortOrder, sortOrder)
+
+	args = append(args, domain.LimitWithLookahead(req.PageSize, skipTotal), offset)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query spike orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.SpikeOrder
+	for rows.Next() {
+		order := &domain.SpikeOrder{}
+		err := rows.Scan(
+			&order.ID,
+			&order.OrderNo,
+			&order.SpikeEventID,
+			&order.UserID,
+			&order.OrderID,
+			&order.Quantity,
+			&order.SpikePrice,
+			&order.TotalAmount,
+			&order.Status,
+			&order.IdempotencyKey,
+			&order.ExpireAt,
+			&order.PaidAt,
+			&order.CancelledAt,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.DeletedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan spike order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// spikeOrderListClauses 根据列表请求构建 WHERE 条件与排序，alias 为订单表别名前缀（如 "o."），单表查询时为空
+func spikeOrderListClauses(req *domain.SpikeOrderListRequest, alias string) (string, []interface{}, string, string) {
 	var conditions []string
 	var args []interface{}
 
+	if !req.IncludeDeleted {
+		conditions = append(conditions, alias+"deleted_at IS NULL")
+	}
+
 	if req.UserID != nil {
-		conditions = append(conditions, "user_id = ?")
+		conditions = append(conditions, alias+"user_id = ?")
 		args = append(args, *req.UserID)
 	}
 
 	if req.SpikeEventID != nil {
-		conditions = append(conditions, "spike_event_id = ?")
+		conditions = append(conditions, alias+"spike_event_id = ?")
 		args = append(args, *req.SpikeEventID)
 	}
 
 	if req.Status != nil {
-		conditions = append(conditions, "status = ?")
+		conditions = append(conditions, alias+"status = ?")
 		args = append(args, *req.Status)
 	}
 
+	if req.From != nil {
+		conditions = append(conditions, alias+"created_at >= ?")
+		args = append(args, *req.From)
+	}
+
+	if req.To != nil {
+		conditions = append(conditions, alias+"created_at < ?")
+		args = append(args, *req.To)
+	}
+
+	if req.After != nil {
+		cond, cursorArgs := keysetCondition(alias, req.After, req.Descending())
+		conditions = append(conditions, cond)
+		args = append(args, cursorArgs...)
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	// 构建排序
+	// 构建排序，使用游标时固定按 created_at 排序
 	sortBy := "created_at"
-	if req.SortBy != nil {
+	if req.SortBy != nil && req.After == nil {
 		switch *req.SortBy {
 		case "created_at", "total_amount":
 			sortBy = *req.SortBy
@@ -214,16 +529,29 @@ func (r *spikeOrderRepo) List(req *domain.SpikeOrderListRequest) ([]*domain.Spik
 	}
 
 	sortOrder := "DESC"
-	if req.SortOrder != nil && strings.ToUpper(*req.SortOrder) == "ASC" {
+	if !req.Descending() {
 		sortOrder = "ASC"
 	}
 
-	// 查询总数
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spike_orders %s", whereClause)
-	var total int64
-	err := r.db.QueryRow(countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count spike orders: %w", err)
+	return whereClause, args, alias + sortBy, sortOrder
+}
+
+// ListWithEvent 分页查询用户的秒杀订单，一次 JOIN 同时取回各订单所属活动的摘要
+func (r *spikeOrderRepo) ListWithEvent(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrderWithEvent, int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	req.UserID = &userID
+	whereClause, args, sortBy, sortOrder := spikeOrderListClauses(req, "o.")
+
+	// 查询总数，跳过统计或使用游标时改为多取一行判断是否还有下一页
+	total := domain.TotalNotCounted
+	skipTotal := req.SkipTotal || req.After != nil
+	if !skipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spike_orders o %s", whereClause)
+		if err := r.reader(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count spike orders: %w", err)
+		}
 	}
 
 	// 分页参数
@@ -234,28 +562,35 @@ func (r *spikeOrderRepo) List(req *domain.SpikeOrderListRequest) ([]*domain.Spik
 		req.PageSize = 20
 	}
 	offset := (req.Page - 1) * req.PageSize
+	if req.After != nil {
+		offset = 0
+	}
 
-	// 查询数据
 	query := fmt.Sprintf(`
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
-			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
-		FROM spike_orders %s
-		ORDER BY %s %s
+		SELECT o.id, o.order_no, o.spike_event_id, o.user_id, o.order_id, o.quantity, o.spike_price, o.total_amount,
+			o.status, o.idempotency_key, o.expire_at, o.paid_at, o.cancelled_at, o.created_at, o.updated_at,
+			e.id, e.product_id, e.name, e.start_at, e.end_at, e.status
+		FROM spike_orders o
+		JOIN spike_events e ON e.id = o.spike_event_id
+		%s
+		ORDER BY %s %s, o.id %s
 		LIMIT ? OFFSET ?
-	`, whereClause, sortBy, sortOrder)
+	`, whereClause, sortBy, sortOrder, sortOrder)
 
-	args = append(args, req.PageSize, offset)
-	rows, err := r.db.Query(query, args...)
+	args = append(args, domain.LimitWithLookahead(req.PageSize, skipTotal), offset)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query spike orders: %w", err)
+		return nil, 0, fmt.Errorf("failed to query spike orders with events: %w", err)
 	}
 	defer rows.Close()
 
-	var orders []*domain.SpikeOrder
+	var items []*domain.SpikeOrderWithEvent
 	for rows.Next() {
 		order := &domain.SpikeOrder{}
+		event := &domain.SpikeEventSummary{}
 		err := rows.Scan(
 			&order.ID,
+			&order.OrderNo,
 			&order.SpikeEventID,
 			&order.UserID,
 			&order.OrderID,
@@ -269,31 +604,40 @@ func (r *spikeOrderRepo) List(req *domain.SpikeOrderListRequest) ([]*domain.Spik
 			&order.CancelledAt,
 			&order.CreatedAt,
 			&order.UpdatedAt,
+			&event.ID,
+			&event.ProductID,
+			&event.Name,
+			&event.StartAt,
+			&event.EndAt,
+			&event.Status,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan spike order: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan spike order with event: %w", err)
 		}
-		orders = append(orders, order)
+		items = append(items, &domain.SpikeOrderWithEvent{SpikeOrder: order, SpikeEvent: event})
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	return orders, total, nil
+	return items, total, nil
 }
 
 // GetByUserID 根据用户ID获取秒杀订单列表
-func (r *spikeOrderRepo) GetByUserID(userID int64) ([]*domain.SpikeOrder, error) {
+func (r *spikeOrderRepo) GetByUserID(ctx context.Context, userID int64) ([]*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
 			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
 		FROM spike_orders
-		WHERE user_id = ?
+		WHERE user_id = ? AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query spike orders by user id: %w", err)
 	}
@@ -304,6 +648,7 @@ func (r *spikeOrderRepo) GetByUserID(userID int64) ([]*domain.SpikeOrder, error)
 		order := &domain.SpikeOrder{}
 		err := rows.Scan(
 			&order.ID,
+			&order.OrderNo,
 			&order.SpikeEventID,
 			&order.UserID,
 			&order.OrderID,
@@ -328,16 +673,20 @@ func (r *spikeOrderRepo) GetByUserID(userID int64) ([]*domain.SpikeOrder, error)
 }
 
 // GetBySpikeEventID 根据秒杀活动ID获取订单列表
-func (r *spikeOrderRepo) GetBySpikeEventID(spikeEventID int64) ([]*domain.SpikeOrder, error) {
+func (r *spikeOrderRepo) GetBySpikeEventID(ctx context.Context, spikeEventID int64) ([]*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
-			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at,
+			client_ip, user_agent, channel
 		FROM spike_orders
-		WHERE spike_event_id = ?
+		WHERE spike_event_id = ? AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, spikeEventID)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, spikeEventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query spike orders by event id: %w", err)
 	}
@@ -348,6 +697,7 @@ func (r *spikeOrderRepo) GetBySpikeEventID(spikeEventID int64) ([]*domain.SpikeO
 		order := &domain.SpikeOrder{}
 		err := rows.Scan(
 			&order.ID,
+			&order.OrderNo,
 			&order.SpikeEventID,
 			&order.UserID,
 			&order.OrderID,
@@ -361,6 +711,9 @@ func (r *spikeOrderRepo) GetBySpikeEventID(spikeEventID int64) ([]*domain.SpikeO
 			&order.CancelledAt,
 			&order.CreatedAt,
 			&order.UpdatedAt,
+			&order.ClientIP,
+			&order.UserAgent,
+			&order.Channel,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan spike order: %w", err)
@@ -372,17 +725,21 @@ func (r *spikeOrderRepo) GetBySpikeEventID(spikeEventID int64) ([]*domain.SpikeO
 }
 
 // GetByIdempotencyKey 根据幂等键获取秒杀订单
-func (r *spikeOrderRepo) GetByIdempotencyKey(key string) (*domain.SpikeOrder, error) {
+func (r *spikeOrderRepo) GetByIdempotencyKey(ctx context.Context, key string) (*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
 			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
 		FROM spike_orders
 		WHERE idempotency_key = ?
 	`
 
 	order := &domain.SpikeOrder{}
-	err := r.db.QueryRow(query, key).Scan(
+	err := r.db.QueryRowContext(ctx, query, key).Scan(
 		&order.ID,
+		&order.OrderNo,
 		&order.SpikeEventID,
 		&order.UserID,
 		&order.OrderID,
@@ -408,20 +765,67 @@ func (r *spikeOrderRepo) GetByIdempotencyKey(key string) (*domain.SpikeOrder, er
 	return order, nil
 }
 
+// GetByPaymentRef 根据支付渠道交易ID获取秒杀订单
+func (r *spikeOrderRepo) GetByPaymentRef(ctx context.Context, paymentRef string) (*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id
+		FROM spike_orders
+		WHERE payment_ref = ?
+		LIMIT 1
+	`
+
+	var id int64
+	if err := r.db.QueryRowContext(ctx, query, paymentRef).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get spike order by payment ref: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// GetByOrderNo 根据订单号获取秒杀订单
+func (r *spikeOrderRepo) GetByOrderNo(ctx context.Context, orderNo string) (*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id
+		FROM spike_orders
+		WHERE order_no = ?
+	`
+
+	var id int64
+	if err := r.db.QueryRowContext(ctx, query, orderNo).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get spike order by order no: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
 // GetByUserAndEvent 根据用户ID和活动ID获取秒杀订单
-func (r *spikeOrderRepo) GetByUserAndEvent(userID, spikeEventID int64) (*domain.SpikeOrder, error) {
+func (r *spikeOrderRepo) GetByUserAndEvent(ctx context.Context, userID, spikeEventID int64) (*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
 			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
 		FROM spike_orders
-		WHERE user_id = ? AND spike_event_id = ?
+		WHERE user_id = ? AND spike_event_id = ? AND deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
 
 	order := &domain.SpikeOrder{}
-	err := r.db.QueryRow(query, userID, spikeEventID).Scan(
+	err := r.db.QueryRowContext(ctx, query, userID, spikeEventID).Scan(
 		&order.ID,
+		&order.OrderNo,
 		&order.SpikeEventID,
 		&order.UserID,
 		&order.OrderID,
@@ -448,7 +852,10 @@ func (r *spikeOrderRepo) GetByUserAndEvent(userID, spikeEventID int64) (*domain.
 }
 
 // UpdateStatus 更新订单状态
-func (r *spikeOrderRepo) UpdateStatus(id int64, status domain.SpikeOrderStatus) error {
+func (r *spikeOrderRepo) UpdateStatus(ctx context.Context, id int64, status domain.SpikeOrderStatus) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var query string
 	var args []interface{}
 
@@ -461,7 +868,7 @@ func (r *spikeOrderRepo) UpdateStatus(id int64, status domain.SpikeOrderStatus)
 		args = []interface{}{status, id}
 	}
 
-	result, err := r.db.Exec(query, args...)
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
@@ -472,17 +879,20 @@ func (r *spikeOrderRepo) UpdateStatus(id int64, status domain.SpikeOrderStatus)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike order with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeOrderNotFound, id)
 	}
 
 	return nil
 }
 
 // UpdateOrderID 更新关联的普通订单ID
-func (r *spikeOrderRepo) UpdateOrderID(id int64, orderID int64) error {
+func (r *spikeOrderRepo) UpdateOrderID(ctx context.Context, id int64, orderID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE spike_orders SET order_id = ? WHERE id = ?`
 
-	result, err := r.db.Exec(query, orderID, id)
+	result, err := r.db.ExecContext(ctx, query, orderID, id)
 	if err != nil {
 		return fmt.Errorf("failed to update order id: %w", err)
 	}
@@ -493,17 +903,20 @@ func (r *spikeOrderRepo) UpdateOrderID(id int64, orderID int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike order with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeOrderNotFound, id)
 	}
 
 	return nil
 }
 
 // UpdatePaymentInfo 更新支付信息
-func (r *spikeOrderRepo) UpdatePaymentInfo(id int64, paidAt time.Time) error {
-	query := `UPDATE spike_orders SET status = ?, paid_at = ? WHERE id = ?`
+func (r *spikeOrderRepo) UpdatePaymentInfo(ctx context.Context, id int64, paidAt time.Time, paymentRef string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE spike_orders SET status = ?, paid_at = ?, payment_ref = ? WHERE id = ?`
 
-	result, err := r.db.Exec(query, domain.SpikeOrderStatusPaid, paidAt, id)
+	result, err := r.db.ExecContext(ctx, query, domain.SpikeOrderStatusPaid, paidAt, paymentRef, id)
 	if err != nil {
 		return fmt.Errorf("failed to update payment info: %w", err)
 	}
@@ -514,23 +927,27 @@ func (r *spikeOrderRepo) UpdatePaymentInfo(id int64, paidAt time.Time) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("spike order with id %d not found", id)
+		return fmt.Errorf("%w: id %d", domain.ErrSpikeOrderNotFound, id)
 	}
 
 	return nil
 }
 
 // GetExpiredOrders 获取过期的订单
-func (r *spikeOrderRepo) GetExpiredOrders(before time.Time) ([]*domain.SpikeOrder, error) {
+func (r *spikeOrderRepo) GetExpiredOrders(ctx context.Context, before time.Time, limit int) ([]*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
 			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
 		FROM spike_orders
 		WHERE status = ? AND expire_at IS NOT NULL AND expire_at < ?
 		ORDER BY expire_at ASC
+		LIMIT ?
 	`
 
-	rows, err := r.db.Query(query, domain.SpikeOrderStatusPending, before)
+	rows, err := r.db.QueryContext(ctx, query, domain.SpikeOrderStatusPending, before, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query expired orders: %w", err)
 	}
@@ -541,6 +958,7 @@ func (r *spikeOrderRepo) GetExpiredOrders(before time.Time) ([]*domain.SpikeOrde
 		order := &domain.SpikeOrder{}
 		err := rows.Scan(
 			&order.ID,
+			&order.OrderNo,
 			&order.SpikeEventID,
 			&order.UserID,
 			&order.OrderID,
@@ -564,12 +982,184 @@ func (r *spikeOrderRepo) GetExpiredOrders(before time.Time) ([]*domain.SpikeOrde
 	return orders, rows.Err()
 }
 
+// MarkExpired 将已过期的待支付订单标记为已过期；订单已支付、取消或延长了过期时间时不更新
+func (r *spikeOrderRepo) MarkExpired(ctx context.Context, id int64, before time.Time) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE spike_orders SET status = ?
+		WHERE id = ? AND status = ? AND expire_at IS NOT NULL AND expire_at < ?
+	`, domain.SpikeOrderStatusExpired, id, domain.SpikeOrderStatusPending, before)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark order expired: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ExtendExpireAt 延长订单过期时间。
+// 行锁下校验订单仍为未过期的待支付状态且未延长过，更新过期时间与延长标记，并在同一事务中写入时间线事件
+func (r *spikeOrderRepo) ExtendExpireAt(ctx context.Context, id int64, extension time.Duration, event *domain.OrderEvent) (time.Time, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		status     domain.SpikeOrderStatus
+		expireAt   sql.NullTime
+		extendedAt sql.NullTime
+	)
+	err = tx.QueryRowContext(ctx, `SELECT status, expire_at, extended_at FROM spike_orders WHERE id = ? FOR UPDATE`, id).
+		Scan(&status, &expireAt, &extendedAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, domain.ErrSpikeOrderNotFound
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to lock spike order: %w", err)
+	}
+
+	now := time.Now()
+	if status != domain.SpikeOrderStatusPending || !expireAt.Valid || !expireAt.Time.After(now) {
+		return time.Time{}, domain.ErrSpikeOrderNotExtendable
+	}
+	if extendedAt.Valid {
+		return time.Time{}, domain.ErrSpikeOrderAlreadyExtended
+	}
+
+	newExpireAt := expireAt.Time.Add(extension)
+	if _, err := tx.ExecContext(ctx, `UPDATE spike_orders SET expire_at = ?, extended_at = ? WHERE id = ?`, newExpireAt, now, id); err != nil {
+		return time.Time{}, fmt.Errorf("failed to extend spike order: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO order_events (spike_order_id, event_type, from_status, to_status, actor, remark, trace_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, event.EventType, event.FromStatus, event.ToStatus, event.Actor, event.Remark, event.TraceID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create order event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return newExpireAt, nil
+}
+
+// CancelWithOutbox 取消订单并写入发件箱消息。
+// 行锁下校验订单状态，避免与同时进行的支付互相覆盖；事务提交后消息由发件箱中继发布
+func (r *spikeOrderRepo) CancelWithOutbox(ctx context.Context, id int64, event *domain.OrderEvent, message *domain.OutboxMessage) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	order := &domain.SpikeOrder{ID: id}
+	err = tx.QueryRowContext(ctx, `SELECT status FROM spike_orders WHERE id = ? FOR UPDATE`, id).Scan(&order.Status)
+	if err == sql.ErrNoRows {
+		return domain.ErrSpikeOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock spike order: %w", err)
+	}
+	if !order.CanCancel() {
+		return domain.ErrSpikeOrderNotCancellable
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE spike_orders SET status = ?, cancelled_at = ? WHERE id = ?`,
+		domain.SpikeOrderStatusCancelled, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to cancel spike order: %w", err)
+	}
+
+	event.FromStatus = string(order.Status)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO order_events (spike_order_id, event_type, from_status, to_status, actor, remark, trace_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, event.EventType, event.FromStatus, event.ToStatus, event.Actor, event.Remark, event.TraceID)
+	if err != nil {
+		return fmt.Errorf("failed to create order event: %w", err)
+	}
+
+	if err := insertOutboxMessage(ctx, tx, message); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetPendingOrdersExpiringBetween 获取即将过期的待支付订单
+func (r *spikeOrderRepo) GetPendingOrdersExpiringBetween(ctx context.Context, from, to time.Time) ([]*domain.SpikeOrder, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, order_no, spike_event_id, user_id, order_id, quantity, spike_price, total_amount,
+			status, idempotency_key, expire_at, paid_at, cancelled_at, created_at, updated_at
+		FROM spike_orders
+		WHERE status = ? AND expire_at IS NOT NULL AND expire_at >= ? AND expire_at < ? AND deleted_at IS NULL
+		ORDER BY expire_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.SpikeOrderStatusPending, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expiring orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.SpikeOrder
+	for rows.Next() {
+		order := &domain.SpikeOrder{}
+		err := rows.Scan(
+			&order.ID,
+			&order.OrderNo,
+			&order.SpikeEventID,
+			&order.UserID,
+			&order.OrderID,
+			&order.Quantity,
+			&order.SpikePrice,
+			&order.TotalAmount,
+			&order.Status,
+			&order.IdempotencyKey,
+			&order.ExpireAt,
+			&order.PaidAt,
+			&order.CancelledAt,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expiring order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
 // Count 统计秒杀订单总数
-func (r *spikeOrderRepo) Count() (int64, error) {
-	query := `SELECT COUNT(*) FROM spike_orders`
+func (r *spikeOrderRepo) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM spike_orders WHERE deleted_at IS NULL`
 
 	var count int64
-	err := r.db.QueryRow(query).Scan(&count)
+	err := r.reader(ctx).QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count spike orders: %w", err)
 	}
@@ -578,11 +1168,14 @@ func (r *spikeOrderRepo) Count() (int64, error) {
 }
 
 // CountByStatus 根据状态统计秒杀订单数量
-func (r *spikeOrderRepo) CountByStatus(status domain.SpikeOrderStatus) (int64, error) {
-	query := `SELECT COUNT(*) FROM spike_orders WHERE status = ?`
+func (r *spikeOrderRepo) CountByStatus(ctx context.Context, status domain.SpikeOrderStatus) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM spike_orders WHERE status = ? AND deleted_at IS NULL`
 
 	var count int64
-	err := r.db.QueryRow(query, status).Scan(&count)
+	err := r.reader(ctx).QueryRowContext(ctx, query, status).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count spike orders by status: %w", err)
 	}
@@ -591,14 +1184,62 @@ func (r *spikeOrderRepo) CountByStatus(status domain.SpikeOrderStatus) (int64, e
 }
 
 // CountByUserAndEvent 统计用户在特定活动的订单数量
-func (r *spikeOrderRepo) CountByUserAndEvent(userID, spikeEventID int64) (int64, error) {
+func (r *spikeOrderRepo) CountByUserAndEvent(ctx context.Context, userID, spikeEventID int64) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT COUNT(*) FROM spike_orders WHERE user_id = ? AND spike_event_id = ?`
 
 	var count int64
-	err := r.db.QueryRow(query, userID, spikeEventID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, userID, spikeEventID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count spike orders by user and event: %w", err)
 	}
 
 	return count, nil
 }
+
+// SumQuantityByEvent 统计活动下指定状态订单的购买数量之和，未指定状态时统计全部订单
+func (r *spikeOrderRepo) SumQuantityByEvent(ctx context.Context, spikeEventID int64, statuses ...domain.SpikeOrderStatus) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM spike_orders WHERE spike_event_id = ?`
+	args := []interface{}{spikeEventID}
+	if len(statuses) > 0 {
+		placeholders := make([]string, len(statuses))
+		for i, status := range statuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		query += " AND status IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to sum spike order quantity by event: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetOrderWindowByEvent 聚合统计活动下未删除订单的数量与下单时间范围
+func (r *spikeOrderRepo) GetOrderWindowByEvent(ctx context.Context, spikeEventID int64) (*domain.SpikeOrderWindow, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT COUNT(*), MIN(created_at), MAX(created_at)
+		FROM spike_orders
+		WHERE spike_event_id = ? AND deleted_at IS NULL
+	`
+
+	window := &domain.SpikeOrderWindow{}
+	var firstAt, lastAt sql.NullTime
+	if err := r.reader(ctx).QueryRowContext(ctx, query, spikeEventID).Scan(&window.Orders, &firstAt, &lastAt); err != nil {
+		return nil, fmt.Errorf("failed to get spike order window by event: %w", err)
+	}
+	window.FirstAt, window.LastAt = firstAt.Time, lastAt.Time
+
+	return window, nil
+}
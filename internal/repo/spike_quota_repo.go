@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeQuotaRepository 定义秒杀用户每日配额规则的数据访问接口
+type SpikeQuotaRepository interface {
+	// List 获取全部配额规则（含默认规则），按用户ID排序
+	List(ctx context.Context) ([]*domain.SpikeUserQuota, error)
+	// Upsert 创建或更新用户的配额规则，userID 为 domain.SpikeQuotaDefaultUserID 时更新默认规则
+	Upsert(ctx context.Context, userID, maxDailyWins int64) error
+	// Delete 删除用户单独设置的配额规则，规则不存在时返回 domain.ErrSpikeUserQuotaNotFound
+	Delete(ctx context.Context, userID int64) error
+}
+
+// spikeQuotaRepo 实现SpikeQuotaRepository接口
+type spikeQuotaRepo struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewSpikeQuotaRepository 创建秒杀用户配额仓储实例
+func NewSpikeQuotaRepository(db *sql.DB, opts ...Option) SpikeQuotaRepository {
+	return &spikeQuotaRepo{db: db, queryTimeout: newQueryTimeout(opts)}
+}
+
+// List 获取全部配额规则。规则修改后需立即生效，始终读主库
+func (r *spikeQuotaRepo) List(ctx context.Context) ([]*domain.SpikeUserQuota, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, max_daily_wins, created_at, updated_at
+		FROM spike_user_quotas
+		ORDER BY user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spike user quotas: %w", err)
+	}
+	defer rows.Close()
+
+	var quotas []*domain.SpikeUserQuota
+	for rows.Next() {
+		quota := &domain.SpikeUserQuota{}
+		if err := rows.Scan(&quota.UserID, &quota.MaxDailyWins, &quota.CreatedAt, &quota.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan spike user quota: %w", err)
+		}
+		quotas = append(quotas, quota)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate spike user quotas: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// Upsert 创建或更新用户的配额规则
+func (r *spikeQuotaRepo) Upsert(ctx context.Context, userID, maxDailyWins int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO spike_user_quotas (user_id, max_daily_wins)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE max_daily_wins = VALUES(max_daily_wins)
+	`, userID, maxDailyWins); err != nil {
+		return fmt.Errorf("failed to upsert spike user quota: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除用户单独设置的配额规则，默认规则不可删除
+func (r *spikeQuotaRepo) Delete(ctx context.Context, userID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM spike_user_quotas WHERE user_id = ? AND user_id <> ?`,
+		userID, domain.SpikeQuotaDefaultUserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete spike user quota: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrSpikeUserQuotaNotFound
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeSettlementRepository 定义活动结算数据访问接口
+type SpikeSettlementRepository interface {
+	// AggregateDaily 按结算日汇总活动的已支付订单与退款，收入按支付日期、退款按退款事件日期归日
+	AggregateDaily(spikeEventID int64) ([]*domain.SpikeSettlementDaily, error)
+	// Upsert 写入活动在结算日的结算，已存在且待打款时覆盖金额，已打款时保持不变
+	Upsert(settlement *domain.SpikeSettlement) error
+	// GetByID 根据ID获取结算
+	GetByID(id int64) (*domain.SpikeSettlement, error)
+	// List 分页查询结算，按结算日倒序
+	List(req *domain.SpikeSettlementListRequest) ([]*domain.SpikeSettlement, int64, error)
+	// MarkPaidOut 将待打款的结算标记为已打款，结算不是待打款状态时返回 false
+	MarkPaidOut(id int64, actor, reference string, paidOutAt time.Time) (bool, error)
+}
+
+// spikeSettlementColumns 结算查询列，与 scanSpikeSettlement 的扫描顺序一致
+const spikeSettlementColumns = `id, spike_event_id, settle_date, order_count, quantity,
+	gross_amount, refund_amount, fee_amount, net_amount, status,
+	paid_out_at, paid_out_by, payout_reference, created_at, updated_at`
+
+// spikeSettlementRepo 实现SpikeSettlementRepository接口
+type spikeSettlementRepo struct {
+	db *sql.DB
+}
+
+// NewSpikeSettlementRepository 创建活动结算仓储实例
+func NewSpikeSettlementRepository(db *sql.DB) SpikeSettlementRepository {
+	return &spikeSettlementRepo{db: db}
+}
+
+// AggregateDaily 按结算日汇总已支付订单与退款
+func (r *spikeSettlementRepo) AggregateDaily(spikeEventID int64) ([]*domain.SpikeSettlementDaily, error) {
+	byDate := make(map[string]*domain.SpikeSettlementDaily)
+	day := func(date time.Time) *domain.SpikeSettlementDaily {
+		key := date.Format(time.DateOnly)
+		d, ok := byDate[key]
+		if !ok {
+			d = &domain.SpikeSettlementDaily{SettleDate: date}
+			byDate[key] = d
+		}
+		return d
+	}
+
+	paidQuery := `
+		SELECT DATE(paid_at), COUNT(*), COALESCE(SUM(quantity), 0), COALESCE(SUM(total_amount), 0)
+		FROM spike_orders
+		WHERE spike_event_id = ? AND status = ? AND paid_at IS NOT NULL
+		GROUP BY DATE(paid_at)
+	`
+	rows, err := r.db.Query(paidQuery, spikeEventID, domain.SpikeOrderStatusPaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate paid spike orders: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var date time.Time
+		var count, quantity int64
+		var gross float64
+		if err := rows.Scan(&date, &count, &quantity, &gross); err != nil {
+			return nil, fmt.Errorf("failed to scan paid spike order aggregate: %w", err)
+		}
+		d := day(date)
+		d.OrderCount, d.Quantity, d.GrossAmount = count, quantity, gross
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	refundQuery := `
+		SELECT DATE(e.created_at), COALESCE(SUM(o.total_amount), 0)
+		FROM order_events e
+		JOIN spike_orders o ON o.id = e.spike_order_id
+		WHERE o.spike_event_id = ? AND e.event_type = ?
+		GROUP BY DATE(e.created_at)
+	`
+	refundRows, err := r.db.Query(refundQuery, spikeEventID, domain.OrderEventRefunded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate spike order refunds: %w", err)
+	}
+	defer refundRows.Close()
+	for refundRows.Next() {
+		var date time.Time
+		var refund float64
+		if err := refundRows.Scan(&date, &refund); err != nil {
+			return nil, fmt.Errorf("failed to scan spike order refund aggregate: %w", err)
+		}
+		day(date).RefundAmount = refund
+	}
+	if err := refundRows.Err(); err != nil {
+		return nil, err
+	}
+
+	daily := make([]*domain.SpikeSettlementDaily, 0, len(byDate))
+	for _, d := range byDate {
+		daily = append(daily, d)
+	}
+	sort.Slice(daily, func(i, j int) bool { return daily[i].SettleDate.Before(daily[j].SettleDate) })
+	return daily, nil
+}
+
+// Upsert 写入结算，已打款的结算不被覆盖
+func (r *spikeSettlementRepo) Upsert(s *domain.SpikeSettlement) error {
+	query := `
+		INSERT INTO spike_settlements (spike_event_id, settle_date, order_count, quantity,
+			gross_amount, refund_amount, fee_amount, net_amount, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			order_count = IF(status = 'pending', VALUES(order_count), order_count),
+			quantity = IF(status = 'pending', VALUES(quantity), quantity),
+			gross_amount = IF(status = 'pending', VALUES(gross_amount), gross_amount),
+			refund_amount = IF(status = 'pending', VALUES(refund_amount), refund_amount),
+			fee_amount = IF(status = 'pending', VALUES(fee_amount), fee_amount),
+			net_amount = IF(status = 'pending', VALUES(net_amount), net_amount)
+	`
+
+	_, err := r.db.Exec(query, s.SpikeEventID, s.SettleDate.Format(time.DateOnly), s.OrderCount, s.Quantity,
+		s.GrossAmount, s.RefundAmount, s.FeeAmount, s.NetAmount, domain.SpikeSettlementStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to upsert spike settlement: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取结算
+func (r *spikeSettlementRepo) GetByID(id int64) (*domain.SpikeSettlement, error) {
+	query := `SELECT ` + spikeSettlementColumns + ` FROM spike_settlements WHERE id = ?`
+
+	settlement, err := scanSpikeSettlement(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSettlementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike settlement: %w", err)
+	}
+	return settlement, nil
+}
+
+// List 分页查询结算
+func (r *spikeSettlementRepo) List(req *domain.SpikeSettlementListRequest) ([]*domain.SpikeSettlement, int64, error) {
+	whereClause, args := spikeSettlementFilter(req)
+
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM spike_settlements %s", whereClause)
+		if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count spike settlements: %w", err)
+		}
+	}
+
+	// 分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM spike_settlements %s
+		ORDER BY settle_date DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, spikeSettlementColumns, whereClause)
+
+	args = append(args, domain.LimitWithLookahead(req.PageSize, req.SkipTotal), offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query spike settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*domain.SpikeSettlement
+	for rows.Next() {
+		settlement, err := scanSpikeSettlement(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan spike settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, total, rows.Err()
+}
+
+// MarkPaidOut 标记结算已打款
+func (r *spikeSettlementRepo) MarkPaidOut(id int64, actor, reference string, paidOutAt time.Time) (bool, error) {
+	query := `
+		UPDATE spike_settlements
+		SET status = ?, paid_out_at = ?, paid_out_by = ?, payout_reference = ?
+		WHERE id = ? AND status = ?
+	`
+
+	result, err := r.db.Exec(query, domain.SpikeSettlementStatusPaidOut, paidOutAt, actor, reference,
+		id, domain.SpikeSettlementStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark spike settlement paid out: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// spikeSettlementFilter 根据查询条件构造 WHERE 子句
+func spikeSettlementFilter(req *domain.SpikeSettlementListRequest) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if req.SpikeEventID != nil {
+		conditions = append(conditions, "spike_event_id = ?")
+		args = append(args, *req.SpikeEventID)
+	}
+	if req.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, *req.Status)
+	}
+	if req.From != nil {
+		conditions = append(conditions, "settle_date >= ?")
+		args = append(args, req.From.Format(time.DateOnly))
+	}
+	if req.To != nil {
+		conditions = append(conditions, "settle_date < ?")
+		args = append(args, req.To.Format(time.DateOnly))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// spikeSettlementScanner 兼容 *sql.Row 与 *sql.Rows
+type spikeSettlementScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSpikeSettlement 按 spikeSettlementColumns 的列顺序扫描一行
+func scanSpikeSettlement(s spikeSettlementScanner) (*domain.SpikeSettlement, error) {
+	var settlement domain.SpikeSettlement
+	var paidOutAt sql.NullTime
+	err := s.Scan(
+		&settlement.ID,
+		&settlement.SpikeEventID,
+		&settlement.SettleDate,
+		&settlement.OrderCount,
+		&settlement.Quantity,
+		&settlement.GrossAmount,
+		&settlement.RefundAmount,
+		&settlement.FeeAmount,
+		&settlement.NetAmount,
+		&settlement.Status,
+		&paidOutAt,
+		&settlement.PaidOutBy,
+		&settlement.PayoutReference,
+		&settlement.CreatedAt,
+		&settlement.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if paidOutAt.Valid {
+		settlement.PaidOutAt = &paidOutAt.Time
+	}
+	return &settlement, nil
+}
@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeShareAttributionRepository 定义分享链接归因数据访问接口
+type SpikeShareAttributionRepository interface {
+	// IncrementVisits 累加活动在推广渠道下的分享链接访问数
+	IncrementVisits(eventID int64, campaign string) error
+	// IncrementParticipants 累加活动在推广渠道下经分享链接成功参与的次数
+	IncrementParticipants(eventID int64, campaign string) error
+	// ListByEvent 按推广渠道列出活动的分享归因统计
+	ListByEvent(eventID int64) ([]*domain.SpikeShareAttribution, error)
+}
+
+// spikeShareAttributionRepo 实现SpikeShareAttributionRepository接口
+type spikeShareAttributionRepo struct {
+	db *sql.DB
+}
+
+// NewSpikeShareAttributionRepository 创建分享链接归因仓储实例
+func NewSpikeShareAttributionRepository(db *sql.DB) SpikeShareAttributionRepository {
+	return &spikeShareAttributionRepo{db: db}
+}
+
+// IncrementVisits 累加分享链接访问数
+func (r *spikeShareAttributionRepo) IncrementVisits(eventID int64, campaign string) error {
+	query := `
+		INSERT INTO spike_share_attributions (spike_event_id, campaign, visits)
+		VALUES (?, ?, 1)
+		ON DUPLICATE KEY UPDATE visits = visits + 1
+	`
+
+	if _, err := r.db.Exec(query, eventID, campaign); err != nil {
+		return fmt.Errorf("failed to increment share visits: %w", err)
+	}
+	return nil
+}
+
+// IncrementParticipants 累加经分享链接成功参与的次数
+func (r *spikeShareAttributionRepo) IncrementParticipants(eventID int64, campaign string) error {
+	query := `
+		INSERT INTO spike_share_attributions (spike_event_id, campaign, participants)
+		VALUES (?, ?, 1)
+		ON DUPLICATE KEY UPDATE participants = participants + 1
+	`
+
+	if _, err := r.db.Exec(query, eventID, campaign); err != nil {
+		return fmt.Errorf("failed to increment share participants: %w", err)
+	}
+	return nil
+}
+
+// ListByEvent 按推广渠道列出分享归因统计，访问数多的渠道在前
+func (r *spikeShareAttributionRepo) ListByEvent(eventID int64) ([]*domain.SpikeShareAttribution, error) {
+	query := `
+		SELECT spike_event_id, campaign, visits, participants
+		FROM spike_share_attributions
+		WHERE spike_event_id = ?
+		ORDER BY visits DESC, campaign ASC
+	`
+
+	rows, err := r.db.Query(query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share attributions: %w", err)
+	}
+	defer rows.Close()
+
+	var attributions []*domain.SpikeShareAttribution
+	for rows.Next() {
+		var a domain.SpikeShareAttribution
+		if err := rows.Scan(&a.SpikeEventID, &a.Campaign, &a.Visits, &a.Participants); err != nil {
+			return nil, fmt.Errorf("failed to scan share attribution: %w", err)
+		}
+		attributions = append(attributions, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate share attributions: %w", err)
+	}
+	return attributions, nil
+}
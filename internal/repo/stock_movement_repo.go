@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// StockMovementRepository 定义库存变动流水查询接口，流水由库存仓储在变更库存的事务中写入
+type StockMovementRepository interface {
+	// List 分页查询商品的库存变动流水，按变动时间倒序
+	List(ctx context.Context, req *domain.StockMovementListRequest) ([]*domain.StockMovement, int64, error)
+}
+
+// stockMovementRepo 实现StockMovementRepository接口
+type stockMovementRepo struct {
+	db *sql.DB
+	queryTimeout
+	readRouting
+}
+
+// NewStockMovementRepository 创建库存变动流水仓储实例
+func NewStockMovementRepository(db *sql.DB, opts ...Option) StockMovementRepository {
+	return &stockMovementRepo{db: db, queryTimeout: newQueryTimeout(opts), readRouting: newReadRouting(db, opts)}
+}
+
+// List 分页查询库存变动流水
+func (r *stockMovementRepo) List(ctx context.Context, req *domain.StockMovementListRequest) ([]*domain.StockMovement, int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	conditions := []string{"product_id = ?"}
+	args := []interface{}{req.ProductID}
+	if req.Type != nil {
+		conditions = append(conditions, "type = ?")
+		args = append(args, *req.Type)
+	}
+	if req.Reference != "" {
+		conditions = append(conditions, "reference = ?")
+		args = append(args, req.Reference)
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	total := domain.TotalNotCounted
+	if !req.SkipTotal {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM stock_movements %s", whereClause)
+		if err := r.reader(ctx).QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count stock movements: %w", err)
+		}
+	}
+
+	// 分页参数
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	query := fmt.Sprintf(`
+		SELECT id, product_id, type, quantity, reason, operator, reference, created_at
+		FROM stock_movements %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, whereClause)
+
+	args = append(args, domain.LimitWithLookahead(req.PageSize, req.SkipTotal), offset)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []*domain.StockMovement
+	for rows.Next() {
+		movement := &domain.StockMovement{}
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&movement.Type,
+			&movement.Quantity,
+			&movement.Reason,
+			&movement.Operator,
+			&movement.Reference,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
+
+// insertStockMovementInTx 在库存变更的事务中写入变动流水，操作者与关联单据取自 ctx；
+// reason 非空时优先于 ctx 中记录的原因
+func insertStockMovementInTx(ctx context.Context, tx *sql.Tx, productID int64, movementType domain.StockMovementType, quantity int, reason string) error {
+	source := domain.StockMovementSourceFrom(ctx)
+	if reason == "" {
+		reason = source.Reason
+	}
+
+	query := `
+		INSERT INTO stock_movements (product_id, type, quantity, reason, operator, reference)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, query, productID, movementType, quantity,
+		truncateRunes(reason, 255), source.Operator, source.Reference); err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return nil
+}
+
+// truncateRunes 按字符截断字符串，避免超出列宽
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
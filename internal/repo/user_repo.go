@@ -24,6 +24,7 @@ type UserRepository interface {
 	ListUsers(offset, limit int) ([]*domain.User, int64, error)
 	UpdateUserRole(userID int64, role domain.UserRole) error
 	UpdateUserStatus(userID int64, isActive bool) error
+	UpdateUserTier(userID int64, tier domain.UserTier) error
 }
 
 // userRepo 是 UserRepository 接口的数据库实现
@@ -39,9 +40,13 @@ func NewUserRepository(db *database.DB) UserRepository {
 // Create 创建新用户
 // 注意：这里不处理密码哈希，密码哈希应该在服务层处理
 func (r *userRepo) Create(user *domain.User) error {
+	if user.Tier == "" {
+		user.Tier = domain.UserTierRegular
+	}
+
 	query := `
-		INSERT INTO users (username, email, password_hash, role, is_active)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO users (username, email, password_hash, role, tier, is_active)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(query,
@@ -49,6 +54,7 @@ func (r *userRepo) Create(user *domain.User) error {
 		user.Email,
 		user.PasswordHash,
 		string(user.Role),
+		string(user.Tier),
 		user.IsActive,
 	)
 	if err != nil {
@@ -69,7 +75,7 @@ func (r *userRepo) Create(user *domain.User) error {
 func (r *userRepo) GetByID(id int64) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, tier, is_active, created_at, updated_at
 		FROM users WHERE id = ?
 	`
 
@@ -79,6 +85,7 @@ func (r *userRepo) GetByID(id int64) (*domain.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.Tier,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -97,7 +104,7 @@ func (r *userRepo) GetByID(id int64) (*domain.User, error) {
 func (r *userRepo) GetByUsername(username string) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, tier, is_active, created_at, updated_at
 		FROM users WHERE username = ?
 	`
 
@@ -107,6 +114,7 @@ func (r *userRepo) GetByUsername(username string) (*domain.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.Tier,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -125,7 +133,7 @@ func (r *userRepo) GetByUsername(username string) (*domain.User, error) {
 func (r *userRepo) GetByEmail(email string) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, tier, is_active, created_at, updated_at
 		FROM users WHERE email = ?
 	`
 
@@ -135,6 +143,7 @@ func (r *userRepo) GetByEmail(email string) (*domain.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.Tier,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -195,7 +204,7 @@ func (r *userRepo) ListUsers(offset, limit int) ([]*domain.User, int64, error) {
 
 	// 获取用户列表
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, tier, is_active, created_at, updated_at
 		FROM users 
 		ORDER BY created_at DESC 
 		LIMIT ? OFFSET ?
@@ -216,6 +225,7 @@ func (r *userRepo) ListUsers(offset, limit int) ([]*domain.User, int64, error) {
 			&user.Email,
 			&user.PasswordHash,
 			&user.Role,
+			&user.Tier,
 			&user.IsActive,
 			&user.CreatedAt,
 			&user.UpdatedAt,
@@ -248,7 +258,28 @@ func (r *userRepo) UpdateUserRole(userID int64, role domain.UserRole) error {
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("user not found")
+		return domain.NewNotFoundError("user not found")
+	}
+
+	return nil
+}
+
+// UpdateUserTier 更新用户等级（管理员专用）
+func (r *userRepo) UpdateUserTier(userID int64, tier domain.UserTier) error {
+	query := `UPDATE users SET tier = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+
+	result, err := r.db.Exec(query, string(tier), userID)
+	if err != nil {
+		return fmt.Errorf("update user tier: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.NewNotFoundError("user not found")
 	}
 
 	return nil
@@ -269,7 +300,7 @@ func (r *userRepo) UpdateUserStatus(userID int64, isActive bool) error {
 	}
 
 	if affected == 0 {
-		return fmt.Errorf("user not found")
+		return domain.NewNotFoundError("user not found")
 	}
 
 	return nil
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// UserTOTPRepository 定义用户两步验证数据访问接口
+type UserTOTPRepository interface {
+	// Get 获取用户的两步验证绑定，不存在时返回 nil
+	Get(ctx context.Context, userID int64) (*domain.UserTOTP, error)
+	// SavePending 写入待确认的密钥，覆盖未启用的旧密钥；已启用时不修改并返回 false
+	SavePending(ctx context.Context, userID int64, secret string) (bool, error)
+	// Enable 启用待确认的绑定并记录本次验证的时间步，返回是否更新
+	Enable(ctx context.Context, userID, step int64, at time.Time) (bool, error)
+	// MarkUsed 仅当时间步大于上次使用的时间步时记录，返回是否更新；并发提交同一验证码时只有一次成功
+	MarkUsed(ctx context.Context, userID, step int64) (bool, error)
+	// Delete 删除用户的两步验证绑定
+	Delete(ctx context.Context, userID int64) error
+}
+
+// userTOTPRepo 实现UserTOTPRepository接口
+type userTOTPRepo struct {
+	db *sql.DB
+	queryTimeout
+}
+
+// NewUserTOTPRepository 创建用户两步验证仓储实例
+func NewUserTOTPRepository(db *sql.DB, opts ...Option) UserTOTPRepository {
+	return &userTOTPRepo{db: db, queryTimeout: newQueryTimeout(opts)}
+}
+
+// Get 获取两步验证绑定
+func (r *userTOTPRepo) Get(ctx context.Context, userID int64) (*domain.UserTOTP, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at
+		FROM user_totp WHERE user_id = ?
+	`
+
+	var t domain.UserTOTP
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&t.UserID, &t.Secret, &t.EnabledAt, &t.LastUsedStep, &t.CreatedAt, &t.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user totp: %w", err)
+	}
+	return &t, nil
+}
+
+// SavePending 写入待确认密钥，已启用的绑定保持不变
+func (r *userTOTPRepo) SavePending(ctx context.Context, userID int64, secret string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_totp (user_id, secret) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE
+			last_used_step = IF(enabled_at IS NULL, 0, last_used_step),
+			secret = IF(enabled_at IS NULL, VALUES(secret), secret)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, secret); err != nil {
+		return false, fmt.Errorf("failed to save pending user totp: %w", err)
+	}
+	// 新密钥与已启用密钥碰撞的概率可忽略，回读密钥判断是否写入
+	current, err := r.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return current != nil && !current.IsEnabled() && current.Secret == secret, nil
+}
+
+// Enable 条件更新启用时间，并发确认时只有一个成功
+func (r *userTOTPRepo) Enable(ctx context.Context, userID, step int64, at time.Time) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE user_totp SET enabled_at = ?, last_used_step = ?
+		WHERE user_id = ? AND enabled_at IS NULL
+	`
+
+	return r.execAffected(ctx, "enable user totp", query, at, step, userID)
+}
+
+// MarkUsed 条件更新最近使用的时间步
+func (r *userTOTPRepo) MarkUsed(ctx context.Context, userID, step int64) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+
+	return r.execAffected(ctx, "mark user totp used", query, step, userID, step)
+}
+
+// Delete 删除两步验证绑定
+func (r *userTOTPRepo) Delete(ctx context.Context, userID int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete user totp: %w", err)
+	}
+	return nil
+}
+
+// execAffected 执行条件更新并返回是否有行被更新
+func (r *userTOTPRepo) execAffected(ctx context.Context, op, query string, args ...interface{}) (bool, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to %s: %w", op, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
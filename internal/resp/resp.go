@@ -15,6 +15,7 @@ const (
 	CodeInternalError Code = 10000
 	CodeInvalidParam  Code = 10001
 	CodeTimeout       Code = 10002
+	CodeUnavailable   Code = 10003 // 服务暂不可用（如实例正在关闭），客户端可稍后重试
 )
 
 // Response 为统一响应结构，包含业务码、信息、数据载荷与可选链路标识。
@@ -51,6 +52,37 @@ func Error(w http.ResponseWriter, status int, code Code, message, requestID, tra
 	WriteJSON[any](w, status, code, message, nil, requestID, traceID)
 }
 
+// FieldError 描述单个请求字段的校验错误。
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors 为字段级校验失败响应的数据载荷。
+type FieldErrors struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// InvalidFields 写入一个 code=CodeInvalidParam 的字段级校验失败响应，HTTP 状态为 400。
+func InvalidFields(w http.ResponseWriter, fields []FieldError, requestID, traceID string) {
+	WriteJSON(w, http.StatusBadRequest, CodeInvalidParam, "请求参数校验失败", &FieldErrors{Errors: fields}, requestID, traceID)
+}
+
+// RetryHint 为失败响应携带的重试提示：retryable 表示能否原样重试，retry_after_ms 为建议的最短等待毫秒数。
+type RetryHint struct {
+	Retryable    bool  `json:"retryable"`
+	RetryAfterMs int64 `json:"retry_after_ms"`
+}
+
+// RetryableError 写入一个携带重试提示的失败响应，retryAfter 只在 retryable 为 true 时写入。
+func RetryableError(w http.ResponseWriter, status int, code Code, message string, retryable bool, retryAfter time.Duration, requestID, traceID string) {
+	hint := &RetryHint{Retryable: retryable}
+	if retryable {
+		hint.RetryAfterMs = retryAfter.Milliseconds()
+	}
+	WriteJSON(w, status, code, message, hint, requestID, traceID)
+}
+
 // HTTPStatusFromCode 提供常见业务码到 HTTP 状态码的映射。
 func HTTPStatusFromCode(code Code) int {
 	switch code {
@@ -60,6 +92,8 @@ func HTTPStatusFromCode(code Code) int {
 		return http.StatusBadRequest
 	case CodeTimeout:
 		return http.StatusGatewayTimeout
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
@@ -10,17 +10,28 @@ import (
 
 	"github.com/MorseWayne/spike_shop/internal/api"
 	"github.com/MorseWayne/spike_shop/internal/config"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+	"github.com/MorseWayne/spike_shop/internal/middleware"
 	"github.com/MorseWayne/spike_shop/internal/service"
 )
 
 // Dependencies 包含路由设置所需的所有依赖
 type Dependencies struct {
-	UserHandler       *api.UserHandler
-	ProductHandler    *api.ProductHandler
-	InventoryHandler  *api.InventoryHandler
-	SpikeHandler      *api.SpikeHandler // 秒杀处理器
-	JWTService        service.JWTService
-	SpikeRoutesConfig *SpikeRoutesConfig // 秒杀路由配置
+	UserHandler          *api.UserHandler
+	ProductHandler       *api.ProductHandler
+	InventoryHandler     *api.InventoryHandler
+	OrderHandler         *api.OrderHandler         // 购物车与普通订单处理器，可为空
+	ImpersonationHandler *api.ImpersonationHandler // 客服代操作处理器
+	SpikeHandler         *api.SpikeHandler         // 秒杀处理器
+	TimeHandler          *api.TimeHandler          // 服务器时间处理器
+	HealthHandler        *api.HealthHandler        // 存活与就绪探针，可为空
+	MetaHandler          *api.MetaHandler          // 枚举等元数据处理器
+	DebugCapture         *middleware.DebugCapture  // 请求/响应调试捕获，可为空
+	DebugHandler         *api.DebugHandler         // 调试捕获管理处理器，可为空
+	AdminAudit           gin.HandlerFunc           // 管理员写操作审计中间件，可为空
+	AdminAuditHandler    *api.AdminAuditHandler    // 管理员操作审计查询处理器，可为空
+	JWTService           service.JWTService
+	SpikeRoutesConfig    *SpikeRoutesConfig // 秒杀路由配置
 }
 
 // Router 路由器接口
@@ -53,12 +64,24 @@ func (r *GinRouter) Setup(cfg *config.Config, deps *Dependencies, lg *zap.Logger
 	r.deps = deps
 	r.logger = lg
 
+	// 只信任配置的反向代理转发的 X-Forwarded-For，为空时客户端 IP 取连接地址；
+	// 按 IP 的限流、登录锁定与审计均依赖 ClientIP，默认信任所有代理时客户端可伪造请求头绕过
+	if err := r.engine.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		lg.Error("invalid trusted proxies, trusting none", zap.Error(err))
+		_ = r.engine.SetTrustedProxies(nil)
+	}
+
 	// 设置中间件
 	r.setupMiddleware(cfg)
 
 	// 设置路由
 	r.setupRoutes()
 
+	// Prometheus 指标（无需认证，应在网关层限制只对内网开放）
+	if cfg.HTTP.MetricsEnabled {
+		r.engine.GET("/metrics", gin.WrapH(metrics.DefaultRegistry.Handler()))
+	}
+
 	return r.engine
 }
 
@@ -67,87 +90,218 @@ func (r *GinRouter) setupMiddleware(cfg *config.Config) {
 	// 恢复中间件（从 panic 中恢复）
 	r.engine.Use(gin.Recovery())
 
+	// 请求ID与追踪ID中间件
+	r.engine.Use(middleware.GinRequestContext())
+
 	// 日志中间件
 	r.engine.Use(r.ginLogger())
 
+	// 请求耗时指标
+	if cfg.HTTP.MetricsEnabled {
+		r.engine.Use(middleware.GinMetrics())
+	}
+
+	// 写请求的处理超时、请求体上限与慢请求日志；需在压缩之前，以便设置连接读截止时间
+	r.engine.Use(middleware.GinRequestLimits(middleware.RequestLimitsConfig{
+		Methods:           cfg.HTTP.RequestLimitMethods,
+		Timeout:           cfg.App.RequestTimeout,
+		MaxBodyBytes:      cfg.HTTP.MaxBodyBytes,
+		SlowThreshold:     cfg.HTTP.SlowRequestThreshold,
+		RouteTimeouts:     cfg.HTTP.RouteTimeouts,
+		RouteMaxBodyBytes: cfg.HTTP.RouteMaxBodyBytes,
+	}, r.logger))
+
 	// CORS 中间件
-	r.engine.Use(r.corsMiddleware(cfg))
+	if cfg.HTTP.CORSEnabled {
+		r.engine.Use(middleware.GinCORS(middleware.CORSConfig{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			AllowCredentials: cfg.HTTP.CORSAllowCredentials,
+			MaxAge:           cfg.HTTP.CORSMaxAge,
+		}))
+	}
+
+	// 安全响应头
+	if cfg.HTTP.SecurityHeaders {
+		r.engine.Use(middleware.GinSecurityHeaders(middleware.SecurityHeadersConfig{
+			HSTSMaxAge: cfg.HTTP.HSTSMaxAge,
+		}))
+	}
+
+	// 响应压缩与 ETag：压缩在外层，304 响应没有响应体无需压缩
+	if cfg.HTTP.CompressionEnabled {
+		r.engine.Use(middleware.GinCompression(middleware.CompressionConfig{
+			MinSize: cfg.HTTP.CompressionMinSize,
+		}))
+	}
+	if cfg.HTTP.ETagEnabled {
+		r.engine.Use(middleware.GinETag())
+	}
+
+	// 读写分离：GET 请求中的只读查询读只读副本
+	if len(cfg.Database.ReplicaAddrs) > 0 {
+		r.engine.Use(middleware.GinReplicaReads())
+	}
+
+	// 请求/响应调试捕获（按路由采样，可由管理员接口开关）
+	if r.deps.DebugCapture != nil {
+		r.engine.Use(r.deps.DebugCapture.Middleware())
+	}
 }
 
 // setupRoutes 设置所有路由
 func (r *GinRouter) setupRoutes() {
-	// 健康检查
-	r.engine.GET("/healthz", r.healthCheck)
+	// 健康检查：/healthz 只反映进程存活，/readyz 探测外部依赖
+	if r.deps.HealthHandler != nil {
+		r.engine.GET("/healthz", r.deps.HealthHandler.Liveness)
+		r.engine.GET("/readyz", r.deps.HealthHandler.Readiness)
+	} else {
+		r.engine.GET("/healthz", r.healthCheck)
+	}
 
 	// API v1 路由组
 	v1 := r.engine.Group("/api/v1")
 	{
+		// 服务器时间（无需认证，供客户端校准倒计时）
+		if r.deps.TimeHandler != nil {
+			v1.GET("/time", r.deps.TimeHandler.GetServerTime)
+		}
+
+		// 枚举取值（无需认证，供客户端构建表单与筛选项）
+		if r.deps.MetaHandler != nil {
+			v1.GET("/meta/enums", r.deps.MetaHandler.ListEnums)
+		}
+
 		// 认证路由（无需认证）
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", r.wrapHandler(r.deps.UserHandler.Register))
-			auth.POST("/login", r.wrapHandler(r.deps.UserHandler.Login))
-			auth.POST("/refresh", r.wrapHandler(r.deps.UserHandler.RefreshToken))
+			auth.POST("/register", r.deps.UserHandler.Register)
+			auth.POST("/login", r.deps.UserHandler.Login)
+			auth.POST("/refresh", r.deps.UserHandler.RefreshToken)
 		}
 
 		// 用户路由（需要认证）
 		users := v1.Group("/users")
 		users.Use(r.authMiddleware())
 		{
-			users.GET("/profile", r.wrapHandler(r.deps.UserHandler.GetProfile))
+			users.GET("/profile", r.deps.UserHandler.GetProfile)
+			users.POST("/2fa/totp/enroll", r.deps.UserHandler.EnrollTOTP)
+			users.POST("/2fa/totp/enable", r.deps.UserHandler.EnableTOTP)
+			users.POST("/2fa/totp/disable", r.deps.UserHandler.DisableTOTP)
 		}
 
 		// 商品路由（公开）
 		products := v1.Group("/products")
 		{
-			products.GET("", r.wrapHandler(r.deps.ProductHandler.ListProducts))
-			products.GET("/search", r.wrapHandler(r.deps.ProductHandler.SearchProducts))
-			products.GET("/with-inventory", r.wrapHandler(r.deps.ProductHandler.GetProductsWithInventory))
-			products.GET("/:id", r.wrapHandler(r.deps.ProductHandler.GetProduct))
-			products.GET("/:id/inventory", r.wrapHandler(r.deps.InventoryHandler.GetInventoryByProductID))
-			products.GET("/:id/inventory/check", r.wrapHandler(r.deps.InventoryHandler.CheckStockAvailability))
+			products.GET("", r.deps.ProductHandler.ListProducts)
+			products.GET("/search", r.deps.ProductHandler.SearchProducts)
+			products.GET("/with-inventory", r.deps.ProductHandler.GetProductsWithInventory)
+			products.POST("/availability", r.deps.InventoryHandler.BatchCheckStockAvailability)
+			products.GET("/:id", r.deps.ProductHandler.GetProduct)
+			products.GET("/:id/full", r.deps.ProductHandler.GetProductFull)
+			products.GET("/:id/inventory", r.deps.InventoryHandler.GetInventoryByProductID)
+			products.GET("/:id/inventory/check", r.deps.InventoryHandler.CheckStockAvailability)
+			products.GET("/:id/variants", r.deps.ProductHandler.ListVariants)
 		}
 
 		// 库存路由（需要认证）
 		inventory := v1.Group("/inventory")
 		inventory.Use(r.authMiddleware())
 		{
-			inventory.GET("", r.wrapHandler(r.deps.InventoryHandler.ListInventories))
-			inventory.POST("/reserve", r.wrapHandler(r.deps.InventoryHandler.ReserveStock))
-			inventory.POST("/release", r.wrapHandler(r.deps.InventoryHandler.ReleaseStock))
-			inventory.POST("/consume", r.wrapHandler(r.deps.InventoryHandler.ConsumeStock))
+			inventory.GET("", r.deps.InventoryHandler.ListInventories)
+			inventory.POST("/reserve", r.deps.InventoryHandler.ReserveStock)
+			inventory.POST("/release", r.deps.InventoryHandler.ReleaseStock)
+			inventory.POST("/consume", r.deps.InventoryHandler.ConsumeStock)
+		}
+
+		// 购物车与普通订单路由（需要认证）
+		if r.deps.OrderHandler != nil {
+			cart := v1.Group("/cart")
+			cart.Use(r.authMiddleware())
+			{
+				cart.GET("", r.deps.OrderHandler.GetCart)
+				cart.POST("/items", r.deps.OrderHandler.AddCartItem)
+				cart.PUT("/items/:product_id", r.deps.OrderHandler.UpdateCartItem)
+				cart.DELETE("/items/:product_id", r.deps.OrderHandler.RemoveCartItem)
+			}
+
+			orders := v1.Group("/orders")
+			orders.Use(r.authMiddleware())
+			{
+				orders.POST("/checkout", r.deps.OrderHandler.Checkout)
+				orders.GET("", r.deps.OrderHandler.ListOrders)
+				orders.GET("/:id", r.deps.OrderHandler.GetOrder)
+				orders.POST("/:id/pay", r.deps.OrderHandler.PayOrder)
+				orders.POST("/:id/cancel", r.deps.OrderHandler.CancelOrder)
+			}
 		}
 
 		// 管理员路由（需要认证+管理员权限）
 		admin := v1.Group("/admin")
 		admin.Use(r.authMiddleware(), r.adminMiddleware())
+		if r.deps.AdminAudit != nil {
+			admin.Use(r.deps.AdminAudit)
+		}
 		{
 			// 用户管理
 			adminUsers := admin.Group("/users")
 			{
-				adminUsers.GET("", r.wrapHandler(r.deps.UserHandler.ListUsers))
-				adminUsers.PUT("/role", r.wrapHandler(r.deps.UserHandler.UpdateUserRole))
-				adminUsers.PUT("/status", r.wrapHandler(r.deps.UserHandler.UpdateUserStatus))
+				adminUsers.GET("", r.deps.UserHandler.ListUsers)
+				adminUsers.PUT("/role", r.deps.UserHandler.UpdateUserRole)
+				adminUsers.PUT("/status", r.deps.UserHandler.UpdateUserStatus)
+				adminUsers.PUT("/tier", r.deps.UserHandler.UpdateUserTier)
+				if r.deps.ImpersonationHandler != nil {
+					adminUsers.POST("/impersonate", r.deps.ImpersonationHandler.Impersonate)
+				}
 			}
 
 			// 商品管理
 			adminProducts := admin.Group("/products")
 			{
-				adminProducts.POST("", r.wrapHandler(r.deps.ProductHandler.CreateProduct))
-				adminProducts.PUT("/:id", r.wrapHandler(r.deps.ProductHandler.UpdateProduct))
-				adminProducts.DELETE("/:id", r.wrapHandler(r.deps.ProductHandler.DeleteProduct))
-				adminProducts.GET("/stats", r.wrapHandler(r.deps.ProductHandler.GetProductStats))
-				adminProducts.POST("/:id/inventory/adjust", r.wrapHandler(r.deps.InventoryHandler.AdjustStock))
+				adminProducts.POST("", r.deps.ProductHandler.CreateProduct)
+				adminProducts.PUT("/:id", r.deps.ProductHandler.UpdateProduct)
+				adminProducts.DELETE("/:id", r.deps.ProductHandler.DeleteProduct)
+				adminProducts.PUT("/:id/stop-sell", r.deps.ProductHandler.SetStopSell)
+				adminProducts.GET("/stats", r.deps.ProductHandler.GetProductStats)
+				adminProducts.POST("/:id/inventory/adjust", r.deps.InventoryHandler.AdjustStock)
+				adminProducts.POST("/:id/variants", r.deps.ProductHandler.CreateVariant)
+				adminProducts.PUT("/:id/variants/:variant_id", r.deps.ProductHandler.UpdateVariant)
+				adminProducts.POST("/:id/variants/:variant_id/stock/adjust", r.deps.ProductHandler.AdjustVariantStock)
 			}
 
 			// 库存管理
 			adminInventory := admin.Group("/inventory")
 			{
-				adminInventory.POST("", r.wrapHandler(r.deps.InventoryHandler.CreateInventory))
-				adminInventory.GET("/:id", r.wrapHandler(r.deps.InventoryHandler.GetInventory))
-				adminInventory.PUT("/:id", r.wrapHandler(r.deps.InventoryHandler.UpdateInventory))
-				adminInventory.GET("/alerts/low-stock", r.wrapHandler(r.deps.InventoryHandler.GetLowStockAlerts))
-				adminInventory.GET("/stats", r.wrapHandler(r.deps.InventoryHandler.GetInventoryStats))
+				adminInventory.POST("", r.deps.InventoryHandler.CreateInventory)
+				adminInventory.POST("/bulk-adjust", r.deps.InventoryHandler.BulkAdjustStock)
+				adminInventory.GET("/export", r.deps.InventoryHandler.ExportInventory)
+				adminInventory.GET("/:id", r.deps.InventoryHandler.GetInventory)
+				adminInventory.PUT("/:id", r.deps.InventoryHandler.UpdateInventory)
+				adminInventory.GET("/:id/movements", r.deps.InventoryHandler.ListStockMovements)
+				adminInventory.GET("/alerts/low-stock", r.deps.InventoryHandler.GetLowStockAlerts)
+				adminInventory.GET("/stats", r.deps.InventoryHandler.GetInventoryStats)
+			}
+
+			// 普通订单管理
+			if r.deps.OrderHandler != nil {
+				admin.GET("/orders", r.deps.OrderHandler.ListAdminOrders)
+			}
+
+			// 操作审计
+			if r.deps.AdminAuditHandler != nil {
+				admin.GET("/audit-logs", r.deps.AdminAuditHandler.ListAuditLogs)
+			}
+
+			// 调试捕获
+			if r.deps.DebugHandler != nil {
+				adminDebug := admin.Group("/debug")
+				{
+					adminDebug.GET("/captures", r.deps.DebugHandler.ListCaptures)
+					adminDebug.DELETE("/captures", r.deps.DebugHandler.ClearCaptures)
+					adminDebug.GET("/capture-settings", r.deps.DebugHandler.GetSettings)
+					adminDebug.PUT("/capture-settings", r.deps.DebugHandler.UpdateSettings)
+				}
 			}
 		}
 
@@ -166,11 +320,6 @@ func (r *GinRouter) healthCheck(c *gin.Context) {
 	})
 }
 
-// wrapHandler 将标准的 http.HandlerFunc 包装为 gin.HandlerFunc
-func (r *GinRouter) wrapHandler(handler func(http.ResponseWriter, *http.Request)) gin.HandlerFunc {
-	return gin.WrapF(handler)
-}
-
 // ginLogger 自定义 Gin 日志中间件
 func (r *GinRouter) ginLogger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
@@ -189,37 +338,19 @@ func (r *GinRouter) ginLogger() gin.HandlerFunc {
 	})
 }
 
-// corsMiddleware CORS 中间件
-func (r *GinRouter) corsMiddleware(cfg *config.Config) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	}
-}
-
 // authMiddleware 认证中间件
+// 未注入JWT服务时（如部分测试场景）直接放行
 func (r *GinRouter) authMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 这里需要实现 JWT 认证逻辑
-		// 由于现有的中间件是基于标准库的，我们需要适配
-		// 暂时直接通过，实际项目中需要实现 JWT 验证
-		c.Next()
+	if r.deps.JWTService == nil {
+		return func(c *gin.Context) { c.Next() }
 	}
+	return middleware.GinAuth(r.deps.JWTService, r.logger)
 }
 
 // adminMiddleware 管理员权限中间件
 func (r *GinRouter) adminMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 这里需要实现管理员权限检查逻辑
-		// 暂时直接通过，实际项目中需要验证用户角色
-		c.Next()
+	if r.deps.JWTService == nil {
+		return func(c *gin.Context) { c.Next() }
 	}
+	return middleware.GinRequireAdmin(r.logger)
 }
@@ -17,7 +17,26 @@ func RegisterSpikeRoutes(
 	adminMiddleware gin.HandlerFunc,
 	spikeLimiter limiter.Limiter,
 	apiLimiter limiter.Limiter,
+	drainGuard gin.HandlerFunc,
+	anonymousLimiter limiter.Limiter,
+	idempotency gin.HandlerFunc,
+	adminAudit gin.HandlerFunc,
 ) {
+	// 未配置幂等中间件时只把幂等键写入上下文，不缓存响应
+	if idempotency == nil {
+		idempotency = middleware.IdempotencyMiddleware()
+	}
+
+	// 参与秒杀的处理链；实例排空期间先于限流拒绝，避免消耗配额
+	participateHandlers := make([]gin.HandlerFunc, 0, 4)
+	if drainGuard != nil {
+		participateHandlers = append(participateHandlers, drainGuard)
+	}
+	participateHandlers = append(participateHandlers,
+		limiter.SpikeRateLimitMiddleware(spikeLimiter),
+		idempotency,
+		spikeHandler.ParticipateSpike)
+
 	// 秒杀API路由组
 	spikeGroup := r.Group("/spike")
 	{
@@ -41,6 +60,16 @@ func RegisterSpikeRoutes(
 			public.GET("/events/:id/stats",
 				limiter.APIRateLimitMiddleware(apiLimiter),
 				spikeHandler.GetSpikeStats)
+
+			// 长轮询库存状态（无法保持 SSE/WebSocket 的客户端使用）
+			public.GET("/events/:id/stock/wait",
+				limiter.APIRateLimitMiddleware(apiLimiter),
+				spikeHandler.WaitStockStatus)
+
+			// 分享链接到达（校验签名并记录访问归因）
+			public.POST("/share-links/visits",
+				limiter.APIRateLimitMiddleware(apiLimiter),
+				spikeHandler.ResolveShareLink)
 		}
 
 		// 需要用户认证的接口
@@ -48,10 +77,27 @@ func RegisterSpikeRoutes(
 		authenticated.Use(jwtMiddleware)
 		{
 			// 参与秒杀（重要接口，使用专门的秒杀限流）
-			authenticated.POST("/participate",
-				limiter.SpikeRateLimitMiddleware(spikeLimiter),
-				middleware.IdempotencyMiddleware(),
-				spikeHandler.ParticipateSpike)
+			authenticated.POST("/participate", participateHandlers...)
+
+			// 领取参与令牌（预告期开始后，单用户领取频率在服务层限制）
+			authenticated.POST("/events/:id/participation-token",
+				limiter.APIRateLimitMiddleware(apiLimiter),
+				spikeHandler.IssueParticipationToken)
+
+			// 领取防刷挑战（单用户领取频率在服务层限制）
+			authenticated.GET("/captcha",
+				limiter.APIRateLimitMiddleware(apiLimiter),
+				spikeHandler.IssueSpikeChallenge)
+
+			// 查询等候室排队状态
+			authenticated.GET("/queue/:token",
+				limiter.APIRateLimitMiddleware(apiLimiter),
+				spikeHandler.GetQueueStatus)
+
+			// 生成活动分享链接
+			authenticated.POST("/events/:id/share-links",
+				limiter.APIRateLimitMiddleware(apiLimiter),
+				spikeHandler.CreateShareLink)
 
 			// 用户订单相关
 			orders := authenticated.Group("/orders")
@@ -66,23 +112,217 @@ func RegisterSpikeRoutes(
 					limiter.APIRateLimitMiddleware(apiLimiter),
 					spikeHandler.GetSpikeOrderDetail)
 
+				// 按订单号获取秒杀订单详情
+				orders.GET("/no/:order_no",
+					limiter.APIRateLimitMiddleware(apiLimiter),
+					spikeHandler.GetSpikeOrderDetailByNo)
+
 				// 取消秒杀订单
 				orders.POST("/:id/cancel",
 					limiter.APIRateLimitMiddleware(apiLimiter),
-					middleware.IdempotencyMiddleware(),
+					idempotency,
 					spikeHandler.CancelSpikeOrder)
+
+				// 延长秒杀订单支付时间（每个订单一次）
+				orders.POST("/:id/extend",
+					limiter.APIRateLimitMiddleware(apiLimiter),
+					spikeHandler.ExtendSpikeOrder)
+
+				// 获取秒杀订单时间线
+				orders.GET("/:id/timeline",
+					limiter.APIRateLimitMiddleware(apiLimiter),
+					spikeHandler.GetSpikeOrderTimeline)
+
+				// 发起订单支付（创建支付意图）
+				orders.POST("/:id/payment-intent",
+					limiter.APIRateLimitMiddleware(apiLimiter),
+					spikeHandler.CreatePaymentIntent)
+
+				// 凭支付凭证一步完成支付
+				orders.POST("/:id/pay",
+					limiter.APIRateLimitMiddleware(apiLimiter),
+					idempotency,
+					spikeHandler.PaySpikeOrder)
 			}
 		}
 	}
 
+	// 支付渠道回调（凭请求体签名校验，无需认证，不限流以免丢失回调）
+	r.POST("/payments/webhook", spikeHandler.HandlePaymentWebhook)
+
+	// 沙箱渠道模拟用户完成支付（未开启 PAYMENT_SANDBOX_AUTHORIZE_ENABLED 时返回 404）
+	r.POST("/payments/sandbox/intents/:id/authorize",
+		jwtMiddleware,
+		limiter.APIRateLimitMiddleware(apiLimiter),
+		spikeHandler.AuthorizeSandboxPayment)
+
+	// 匿名只读接口（营销页使用）：按 IP 严格限流，只读缓存，不返回用户相关字段；
+	// 未配置匿名限流器时不注册，避免匿名流量绕过限流
+	if anonymousLimiter != nil {
+		anonymous := r.Group("/public/spike")
+		anonymous.Use(limiter.AnonymousRateLimitMiddleware(anonymousLimiter))
+		{
+			anonymous.GET("/events", spikeHandler.ListPublicEvents)
+			anonymous.GET("/events/:id", spikeHandler.GetPublicEvent)
+		}
+	}
+
+	// 报表下载（凭签名地址访问，无需认证）
+	r.GET("/reports/download",
+		limiter.APIRateLimitMiddleware(apiLimiter),
+		spikeHandler.DownloadReport)
+
 	// 管理员接口
 	adminGroup := r.Group("/admin/spike")
 	adminGroup.Use(jwtMiddleware, adminMiddleware)
+	if adminAudit != nil {
+		adminGroup.Use(adminAudit)
+	}
 	{
 		// 库存预热
 		adminGroup.POST("/events/:id/warmup",
 			limiter.APIRateLimitMiddleware(apiLimiter),
 			spikeHandler.WarmupStock)
+
+		// 活动上线检查（库存、缓存、限流、消费者、死信队列、连接池）
+		adminGroup.POST("/events/:id/preflight",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.RunPreflight)
+
+		// 预热全部进行中与预告期活动的库存（后台任务）
+		adminGroup.POST("/events/warmup-all",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.WarmupAllStock)
+
+		// 批量导入与导出活动（CSV 或 JSON）
+		adminGroup.POST("/events/import",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ImportSpikeEvents)
+		adminGroup.GET("/events/export",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ExportSpikeEvents)
+
+		// 后台任务状态与进度
+		adminGroup.GET("/jobs",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ListAdminJobs)
+		adminGroup.GET("/jobs/:id",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetAdminJob)
+
+		// 跨用户查询与导出订单
+		adminGroup.GET("/orders",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ListAdminSpikeOrders)
+
+		// 按幂等键或支付流水号查找订单（客服）
+		adminGroup.GET("/orders/lookup",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.LookupSpikeOrder)
+
+		// 活动进行中补充库存
+		adminGroup.POST("/events/:id/stock",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.TopUpStock)
+
+		// 库存对账（Redis 库存、活动已售数量与订单）
+		adminGroup.POST("/stock/reconcile",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ReconcileStock)
+
+		// 暂停与恢复活动
+		adminGroup.POST("/events/:id/pause",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.PauseSpikeEvent)
+		adminGroup.POST("/events/:id/resume",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ResumeSpikeEvent)
+
+		// 草稿活动排期、审批与撤回，审批通过的活动在发布时间自动发布
+		adminGroup.GET("/events/drafts",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ListDraftSpikeEvents)
+		adminGroup.POST("/events/:id/schedule",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ScheduleSpikeEvent)
+		adminGroup.POST("/events/:id/approve",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ApproveSpikeEvent)
+		adminGroup.POST("/events/:id/unschedule",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.UnscheduleSpikeEvent)
+
+		// 容量规划建议
+		adminGroup.GET("/events/:id/capacity-plan",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.PlanCapacity)
+
+		// 分享链接归因统计
+		adminGroup.GET("/events/:id/share-attribution",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetShareAttribution)
+
+		// 活动报表（异步生成，返回签名下载地址）
+		adminGroup.GET("/events/:id/report",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetSpikeReport)
+
+		// 秒杀专场（多活动分组、跨活动限购与专场报表）
+		adminGroup.POST("/campaigns",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.CreateSpikeCampaign)
+		adminGroup.GET("/campaigns/:id",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetSpikeCampaign)
+		adminGroup.PUT("/campaigns/:id/events",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.AssignSpikeCampaignEvents)
+		adminGroup.GET("/campaigns/:id/report",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetSpikeCampaignReport)
+
+		// 单用户每日秒杀成功次数上限（默认规则与按用户覆盖）
+		adminGroup.GET("/quotas",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetSpikeQuotaRules)
+		adminGroup.PUT("/quotas/default",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.SetSpikeQuotaDefault)
+		adminGroup.GET("/quotas/users/:id",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetSpikeUserQuota)
+		adminGroup.PUT("/quotas/users/:id",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.SetSpikeUserQuota)
+		adminGroup.DELETE("/quotas/users/:id",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.DeleteSpikeUserQuota)
+
+		// 弃单查询与召回（营销）
+		adminGroup.GET("/abandoned-checkouts",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ListAbandonedCheckouts)
+		adminGroup.POST("/abandoned-checkouts/:id/recovery",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.StartRecoveryCampaign)
+
+		// 活动结算查询与打款标记（财务）
+		adminGroup.GET("/settlements",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.ListSettlements)
+		adminGroup.POST("/settlements/:id/payout",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.MarkSettlementPaidOut)
+
+		// 跨活动的秒杀总览（活动数、订单状态分布、转化率、热销活动、营收）
+		adminGroup.GET("/dashboard",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.GetSpikeDashboard)
+
+		// 系统状态快照（消费者、死信队列、限流器）
+		adminGroup.GET("/status",
+			limiter.APIRateLimitMiddleware(apiLimiter),
+			spikeHandler.SystemStatus)
 	}
 }
 
@@ -99,6 +339,10 @@ func RegisterSpikeRoutesWithConfig(
 		config.AdminMiddleware,
 		config.SpikeLimiter,
 		config.APILimiter,
+		config.DrainGuard,
+		config.AnonymousLimiter,
+		config.Idempotency,
+		config.AdminAudit,
 	)
 }
 
@@ -108,4 +352,11 @@ type SpikeRoutesConfig struct {
 	AdminMiddleware gin.HandlerFunc // 管理员权限中间件
 	SpikeLimiter    limiter.Limiter // 秒杀专用限流器
 	APILimiter      limiter.Limiter // API通用限流器
+	DrainGuard      gin.HandlerFunc // 实例排空时拒绝参与秒杀，可为空
+	// AnonymousLimiter 匿名只读接口按 IP 限流，可为空，为空时不注册匿名接口
+	AnonymousLimiter limiter.Limiter
+	// Idempotency 参与秒杀、取消与支付订单使用的幂等中间件，可为空，为空时不缓存响应
+	Idempotency gin.HandlerFunc
+	// AdminAudit 管理员写操作审计中间件，可为空
+	AdminAudit gin.HandlerFunc
 }
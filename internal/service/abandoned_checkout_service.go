@@ -0,0 +1,117 @@
+// Package service 提供弃单查询与召回活动服务。
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// defaultRecoveryMessage 召回通知的默认文案
+const defaultRecoveryMessage = "您有一笔秒杀订单已过期，我们为您保留了专属优惠，欢迎再次参与"
+
+// AbandonedCheckoutService 定义弃单召回服务接口
+type AbandonedCheckoutService interface {
+	// ListAbandonedCheckouts 分页查询弃单，并返回相同过滤条件下的召回转化汇总
+	ListAbandonedCheckouts(ctx context.Context, req *domain.AbandonedCheckoutListRequest) (*domain.AbandonedCheckoutListResponse, error)
+	// StartRecovery 为弃单记录召回活动并向用户发送带优惠券的通知
+	StartRecovery(ctx context.Context, checkoutID int64, req *domain.RecoveryCampaignRequest) (*domain.AbandonedCheckout, error)
+}
+
+// abandonedCheckoutService 是AbandonedCheckoutService接口的实现
+type abandonedCheckoutService struct {
+	repo     repo.AbandonedCheckoutRepository
+	notifier mq.NotificationPublisher
+	logger   *zap.Logger
+}
+
+// NewAbandonedCheckoutService 创建弃单召回服务，notifier 为空时只记录召回活动不发送通知
+func NewAbandonedCheckoutService(abandonedRepo repo.AbandonedCheckoutRepository, notifier mq.NotificationPublisher, logger *zap.Logger) AbandonedCheckoutService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &abandonedCheckoutService{
+		repo:     abandonedRepo,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// ListAbandonedCheckouts 查询弃单
+func (s *abandonedCheckoutService) ListAbandonedCheckouts(ctx context.Context, req *domain.AbandonedCheckoutListRequest) (*domain.AbandonedCheckoutListResponse, error) {
+	items, total, err := s.repo.List(req)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*domain.AbandonedCheckout{}
+	}
+	items, pageInfo := domain.Paginate(items, total, req.Page, req.PageSize)
+
+	summary, err := s.repo.Summarize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AbandonedCheckoutListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Summary:  summary,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// StartRecovery 发起召回
+func (s *abandonedCheckoutService) StartRecovery(ctx context.Context, checkoutID int64, req *domain.RecoveryCampaignRequest) (*domain.AbandonedCheckout, error) {
+	checkout, err := s.repo.GetByID(checkoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.repo.MarkContacted(checkoutID, req.CampaignID, req.CouponCode, now); err != nil {
+		return nil, err
+	}
+	checkout.CampaignID = req.CampaignID
+	checkout.CouponCode = req.CouponCode
+	checkout.ContactedAt = &now
+
+	if s.notifier != nil {
+		content := req.Message
+		if content == "" {
+			content = defaultRecoveryMessage
+		}
+		err := s.notifier.PublishNotification(ctx, &mq.NotificationData{
+			UserID:  checkout.UserID,
+			Type:    "spike_order_recovery",
+			Title:   "专属优惠等你来拿",
+			Content: content,
+			Data: map[string]interface{}{
+				"abandoned_checkout_id": checkout.ID,
+				"spike_event_id":        checkout.SpikeEventID,
+				"campaign_id":           req.CampaignID,
+				"coupon_code":           req.CouponCode,
+			},
+			Priority: "normal",
+			Channels: []string{"push"},
+		}, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish recovery notification: %w", err)
+		}
+	}
+
+	s.logger.Info("弃单召回已发起",
+		zap.Int64("abandoned_checkout_id", checkoutID),
+		zap.Int64("spike_order_id", checkout.SpikeOrderID),
+		zap.String("campaign_id", req.CampaignID))
+
+	return checkout, nil
+}
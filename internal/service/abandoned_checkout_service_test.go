@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// newFakeAbandonedRepo 返回保存单条弃单的仓储模拟，MarkContacted 写回召回信息
+func newFakeAbandonedRepo(checkout *domain.AbandonedCheckout) *mocks.AbandonedCheckoutRepositoryMock {
+	return &mocks.AbandonedCheckoutRepositoryMock{
+		GetByIDFunc: func(id int64) (*domain.AbandonedCheckout, error) {
+			if id != checkout.ID {
+				return nil, domain.ErrAbandonedCheckoutNotFound
+			}
+			copied := *checkout
+			return &copied, nil
+		},
+		MarkContactedFunc: func(id int64, campaignID, couponCode string, contactedAt time.Time) error {
+			checkout.CampaignID, checkout.CouponCode, checkout.ContactedAt = campaignID, couponCode, &contactedAt
+			return nil
+		},
+	}
+}
+
+func TestAbandonedCheckoutService_StartRecovery(t *testing.T) {
+	ctx := context.Background()
+	checkout := &domain.AbandonedCheckout{ID: 3, SpikeOrderID: 30, SpikeEventID: 7, UserID: 1}
+	abandoned := newFakeAbandonedRepo(checkout)
+	notifier := &mocks.NotificationPublisherMock{
+		PublishNotificationFunc: func(ctx context.Context, data *mq.NotificationData, traceID string) error { return nil },
+	}
+	svc := NewAbandonedCheckoutService(abandoned, notifier, nil)
+
+	got, err := svc.StartRecovery(ctx, 3, &domain.RecoveryCampaignRequest{CampaignID: "spring", CouponCode: "SAVE10"})
+	if err != nil {
+		t.Fatalf("StartRecovery() error = %v", err)
+	}
+	if got.CampaignID != "spring" || got.CouponCode != "SAVE10" || got.ContactedAt == nil {
+		t.Errorf("StartRecovery() = %+v, want campaign, coupon and contact time", got)
+	}
+	if calls := abandoned.MarkContactedCalls(); len(calls) != 1 || calls[0].ID != 3 || calls[0].CampaignID != "spring" || calls[0].CouponCode != "SAVE10" {
+		t.Errorf("MarkContacted calls = %+v", calls)
+	}
+
+	calls := notifier.PublishNotificationCalls()
+	if len(calls) != 1 {
+		t.Fatalf("notifications = %d, want 1", len(calls))
+	}
+	data := calls[0].Data
+	if data.UserID != 1 || data.Type != "spike_order_recovery" || data.Content != defaultRecoveryMessage {
+		t.Errorf("notification = %+v, want default recovery message to user 1", data)
+	}
+	if data.Data["coupon_code"] != "SAVE10" || data.Data["campaign_id"] != "spring" || data.Data["spike_event_id"] != int64(7) {
+		t.Errorf("notification data = %v", data.Data)
+	}
+
+	// 自定义文案覆盖默认文案
+	if _, err := svc.StartRecovery(ctx, 3, &domain.RecoveryCampaignRequest{CampaignID: "spring", Message: "回来看看"}); err != nil {
+		t.Fatalf("StartRecovery(message) error = %v", err)
+	}
+	if content := notifier.PublishNotificationCalls()[1].Data.Content; content != "回来看看" {
+		t.Errorf("notification content = %q, want custom message", content)
+	}
+}
+
+func TestAbandonedCheckoutService_StartRecoveryErrors(t *testing.T) {
+	ctx := context.Background()
+	req := &domain.RecoveryCampaignRequest{CampaignID: "spring", CouponCode: "SAVE10"}
+
+	t.Run("checkout not found", func(t *testing.T) {
+		abandoned := newFakeAbandonedRepo(&domain.AbandonedCheckout{ID: 3})
+		notifier := &mocks.NotificationPublisherMock{}
+		svc := NewAbandonedCheckoutService(abandoned, notifier, nil)
+
+		if _, err := svc.StartRecovery(ctx, 4, req); !errors.Is(err, domain.ErrAbandonedCheckoutNotFound) {
+			t.Fatalf("StartRecovery() error = %v, want ErrAbandonedCheckoutNotFound", err)
+		}
+		if len(abandoned.MarkContactedCalls()) != 0 || len(notifier.PublishNotificationCalls()) != 0 {
+			t.Error("missing checkout should not be contacted")
+		}
+	})
+
+	t.Run("mark contacted fails", func(t *testing.T) {
+		abandoned := newFakeAbandonedRepo(&domain.AbandonedCheckout{ID: 3})
+		abandoned.MarkContactedFunc = func(id int64, campaignID, couponCode string, contactedAt time.Time) error {
+			return errors.New("connection refused")
+		}
+		notifier := &mocks.NotificationPublisherMock{}
+		svc := NewAbandonedCheckoutService(abandoned, notifier, nil)
+
+		if _, err := svc.StartRecovery(ctx, 3, req); err == nil {
+			t.Fatal("StartRecovery() error = nil, want repository error")
+		}
+		if len(notifier.PublishNotificationCalls()) != 0 {
+			t.Error("notification sent although contact was not recorded")
+		}
+	})
+
+	t.Run("notification fails", func(t *testing.T) {
+		abandoned := newFakeAbandonedRepo(&domain.AbandonedCheckout{ID: 3})
+		notifier := &mocks.NotificationPublisherMock{
+			PublishNotificationFunc: func(ctx context.Context, data *mq.NotificationData, traceID string) error {
+				return errors.New("broker unavailable")
+			},
+		}
+		svc := NewAbandonedCheckoutService(abandoned, notifier, nil)
+
+		// 召回活动已记录，通知失败时返回错误由调用方重试
+		if _, err := svc.StartRecovery(ctx, 3, req); err == nil {
+			t.Fatal("StartRecovery() error = nil, want notification error")
+		}
+		if len(abandoned.MarkContactedCalls()) != 1 {
+			t.Errorf("MarkContacted calls = %d, want 1", len(abandoned.MarkContactedCalls()))
+		}
+	})
+
+	t.Run("without notifier", func(t *testing.T) {
+		abandoned := newFakeAbandonedRepo(&domain.AbandonedCheckout{ID: 3})
+		svc := NewAbandonedCheckoutService(abandoned, nil, nil)
+
+		if _, err := svc.StartRecovery(ctx, 3, req); err != nil {
+			t.Fatalf("StartRecovery() error = %v", err)
+		}
+		if len(abandoned.MarkContactedCalls()) != 1 {
+			t.Error("contact not recorded without notifier")
+		}
+	})
+}
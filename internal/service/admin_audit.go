@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// AdminAuditService 定义管理员操作审计服务接口
+type AdminAuditService interface {
+	// Record 写入审计记录，写入失败只记录日志，不影响已完成的操作
+	Record(ctx context.Context, entry *domain.AdminAuditLog)
+	// ListAuditLogs 分页查询审计记录
+	ListAuditLogs(ctx context.Context, req *domain.AdminAuditListRequest) (*domain.AdminAuditListResponse, error)
+}
+
+// adminAuditService 是AdminAuditService接口的实现
+type adminAuditService struct {
+	repo   repo.AdminAuditRepository
+	logger *zap.Logger
+}
+
+// NewAdminAuditService 创建管理员操作审计服务
+func NewAdminAuditService(auditRepo repo.AdminAuditRepository, logger *zap.Logger) AdminAuditService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &adminAuditService{
+		repo:   auditRepo,
+		logger: logger,
+	}
+}
+
+// Record 写入审计记录
+func (s *adminAuditService) Record(ctx context.Context, entry *domain.AdminAuditLog) {
+	if err := s.repo.Create(entry); err != nil {
+		// 审计写入失败时把记录完整输出到日志，避免操作痕迹丢失
+		s.logger.Error("写入管理员操作审计失败",
+			zap.Bool("audit", true),
+			zap.String("operator", entry.Actor),
+			zap.String("action", entry.Action),
+			zap.String("entity_type", entry.EntityType),
+			zap.String("entity_id", entry.EntityID),
+			zap.ByteString("before", entry.Before),
+			zap.ByteString("after", entry.After),
+			zap.Int("status_code", entry.StatusCode),
+			zap.String("request_id", entry.RequestID),
+			zap.Error(err))
+	}
+}
+
+// ListAuditLogs 查询审计记录
+func (s *adminAuditService) ListAuditLogs(ctx context.Context, req *domain.AdminAuditListRequest) (*domain.AdminAuditListResponse, error) {
+	items, total, err := s.repo.List(req)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*domain.AdminAuditLog{}
+	}
+	items, pageInfo := domain.Paginate(items, total, req.Page, req.PageSize)
+
+	return &domain.AdminAuditListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: pageInfo,
+	}, nil
+}
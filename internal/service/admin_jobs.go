@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// AdminJobProgress 上报任务进度：已处理条数与总条数
+type AdminJobProgress func(done, total int)
+
+// AdminJobFunc 后台任务的执行函数，返回值作为任务结果
+type AdminJobFunc func(ctx context.Context, progress AdminJobProgress) (any, error)
+
+// AdminJobSubmitter 提交后台任务（由 AdminJobQueue 实现）
+type AdminJobSubmitter interface {
+	Submit(kind domain.AdminJobKind, target, createdBy string, fn AdminJobFunc) (*domain.AdminJob, error)
+}
+
+// AdminJobQueueConfig 后台任务队列配置
+type AdminJobQueueConfig struct {
+	Workers   int           // 同时执行的任务数
+	QueueSize int           // 排队任务上限，超出时拒绝提交
+	Retain    int           // 保留多少个已结束的任务供查询
+	Timeout   time.Duration // 单个任务的执行超时
+}
+
+// DefaultAdminJobQueueConfig 默认后台任务队列配置
+func DefaultAdminJobQueueConfig() *AdminJobQueueConfig {
+	return &AdminJobQueueConfig{
+		Workers:   2,
+		QueueSize: 32,
+		Retain:    100,
+		Timeout:   10 * time.Minute,
+	}
+}
+
+// adminJobTask 排队中的任务
+type adminJobTask struct {
+	id int64
+	fn AdminJobFunc
+}
+
+// AdminJobQueue 以固定数量的工作协程执行预热全部活动、批量导入、报表生成等耗时的管理操作，
+// 避免这些操作在 HTTP 处理器中同步执行、与秒杀热路径争抢数据库与 Redis。
+// 任务状态保存在本实例内存中，只能在提交任务的实例上查询
+type AdminJobQueue struct {
+	config *AdminJobQueueConfig
+	logger *zap.Logger
+	queue  chan *adminJobTask
+
+	mu       sync.Mutex
+	nextID   int64
+	jobs     map[int64]*domain.AdminJob
+	finished []int64 // 按结束顺序记录的任务，超过 Retain 时移除最早的
+}
+
+// NewAdminJobQueue 创建后台任务队列，调用 Start 后开始执行任务
+func NewAdminJobQueue(config *AdminJobQueueConfig, logger *zap.Logger) *AdminJobQueue {
+	if config == nil {
+		config = DefaultAdminJobQueueConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &AdminJobQueue{
+		config: config,
+		logger: logger,
+		queue:  make(chan *adminJobTask, config.QueueSize),
+		jobs:   make(map[int64]*domain.AdminJob),
+	}
+}
+
+// Start 启动工作协程，ctx 取消时退出；执行中的任务随 ctx 取消，排队中的任务不再执行
+func (q *AdminJobQueue) Start(ctx context.Context) {
+	for i := 0; i < q.config.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case task := <-q.queue:
+					q.run(ctx, task)
+				}
+			}
+		}()
+	}
+	q.logger.Info("后台任务队列已启动",
+		zap.Int("workers", q.config.Workers),
+		zap.Int("queue_size", q.config.QueueSize))
+}
+
+// Submit 提交任务，队列已满时返回 domain.ErrAdminJobQueueFull
+func (q *AdminJobQueue) Submit(kind domain.AdminJobKind, target, createdBy string, fn AdminJobFunc) (*domain.AdminJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	job := &domain.AdminJob{
+		ID:        q.nextID,
+		Kind:      kind,
+		Target:    target,
+		Status:    domain.AdminJobStatusQueued,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	select {
+	case q.queue <- &adminJobTask{id: job.ID, fn: fn}:
+	default:
+		return nil, domain.ErrAdminJobQueueFull
+	}
+	q.jobs[job.ID] = job
+
+	q.logger.Info("后台任务已提交",
+		zap.Int64("job_id", job.ID),
+		zap.String("kind", string(kind)),
+		zap.String("target", target),
+		zap.String("created_by", createdBy))
+	return copyAdminJob(job), nil
+}
+
+// Get 查询任务状态
+func (q *AdminJobQueue) Get(id int64) (*domain.AdminJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, domain.ErrAdminJobNotFound
+	}
+	return copyAdminJob(job), nil
+}
+
+// List 返回全部排队中、执行中与保留的已结束任务，最新提交的在前
+func (q *AdminJobQueue) List() []*domain.AdminJob {
+	q.mu.Lock()
+	jobs := make([]*domain.AdminJob, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, copyAdminJob(job))
+	}
+	q.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs
+}
+
+// run 执行单个任务并记录结果
+func (q *AdminJobQueue) run(ctx context.Context, task *adminJobTask) {
+	started := time.Now()
+	q.update(task.id, func(job *domain.AdminJob) {
+		job.Status = domain.AdminJobStatusRunning
+		job.StartedAt = &started
+	})
+
+	jobCtx, cancel := context.WithTimeout(ctx, q.config.Timeout)
+	defer cancel()
+	result, err := q.call(jobCtx, task)
+
+	finished := time.Now()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.jobs[task.id]
+	job.FinishedAt = &finished
+	job.Result = result
+	if err != nil {
+		job.Status = domain.AdminJobStatusFailed
+		job.Error = err.Error()
+		q.logger.Warn("后台任务失败", zap.Int64("job_id", job.ID), zap.String("kind", string(job.Kind)), zap.Error(err))
+	} else {
+		job.Status = domain.AdminJobStatusDone
+		q.logger.Info("后台任务完成",
+			zap.Int64("job_id", job.ID),
+			zap.String("kind", string(job.Kind)),
+			zap.Duration("elapsed", finished.Sub(started)))
+	}
+
+	q.finished = append(q.finished, job.ID)
+	for len(q.finished) > q.config.Retain {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+}
+
+// call 调用任务函数，任务 panic 时记为失败，避免工作协程退出
+func (q *AdminJobQueue) call(ctx context.Context, task *adminJobTask) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return task.fn(ctx, func(done, total int) {
+		q.update(task.id, func(job *domain.AdminJob) {
+			job.Done, job.Total = done, total
+		})
+	})
+}
+
+func (q *AdminJobQueue) update(id int64, fn func(job *domain.AdminJob)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if job, ok := q.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+func copyAdminJob(job *domain.AdminJob) *domain.AdminJob {
+	copied := *job
+	return &copied
+}
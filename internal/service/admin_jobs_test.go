@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// waitForJob 等待任务结束并返回最终状态
+func waitForJob(t *testing.T, q *AdminJobQueue, id int64) *domain.AdminJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%d) error = %v", id, err)
+		}
+		if job.Status.IsFinished() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %d did not finish", id)
+	return nil
+}
+
+func TestAdminJobQueue_RunsJobs(t *testing.T) {
+	q := NewAdminJobQueue(&AdminJobQueueConfig{Workers: 1, QueueSize: 4, Retain: 3, Timeout: time.Second}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 工作协程启动前提交的任务排队等待
+	release := make(chan struct{})
+	done, err := q.Submit(domain.AdminJobKindWarmupAll, "", "admin:1", func(ctx context.Context, progress AdminJobProgress) (any, error) {
+		<-release
+		progress(3, 3)
+		return "ok", nil
+	})
+	if err != nil || done.Status != domain.AdminJobStatusQueued || done.CreatedBy != "admin:1" {
+		t.Fatalf("Submit() = %+v, %v, want queued job", done, err)
+	}
+	failed, _ := q.Submit(domain.AdminJobKindReport, "spike_event:7", "", func(ctx context.Context, progress AdminJobProgress) (any, error) {
+		return nil, errors.New("boom")
+	})
+	panicked, _ := q.Submit(domain.AdminJobKindBulkAdjust, "", "", func(ctx context.Context, progress AdminJobProgress) (any, error) {
+		panic("bad row")
+	})
+
+	q.Start(ctx)
+	close(release)
+
+	if job := waitForJob(t, q, done.ID); job.Status != domain.AdminJobStatusDone || job.Result != "ok" ||
+		job.Done != 3 || job.Total != 3 || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Errorf("done job = %+v", job)
+	}
+	if job := waitForJob(t, q, failed.ID); job.Status != domain.AdminJobStatusFailed || job.Error != "boom" {
+		t.Errorf("failed job = %+v", job)
+	}
+	if job := waitForJob(t, q, panicked.ID); job.Status != domain.AdminJobStatusFailed {
+		t.Errorf("panicked job = %+v, want failed", job)
+	}
+
+	// 只保留最近结束的 Retain 个任务
+	last, _ := q.Submit(domain.AdminJobKindWarmupAll, "", "", func(ctx context.Context, progress AdminJobProgress) (any, error) {
+		return nil, nil
+	})
+	waitForJob(t, q, last.ID)
+	if _, err := q.Get(done.ID); !errors.Is(err, domain.ErrAdminJobNotFound) {
+		t.Errorf("Get(evicted) error = %v, want ErrAdminJobNotFound", err)
+	}
+	if jobs := q.List(); len(jobs) != 3 || jobs[0].ID != last.ID {
+		t.Errorf("List() = %d jobs, want 3 newest first", len(jobs))
+	}
+}
+
+func TestAdminJobQueue_QueueFull(t *testing.T) {
+	q := NewAdminJobQueue(&AdminJobQueueConfig{Workers: 1, QueueSize: 1, Retain: 10, Timeout: time.Second}, nil)
+	noop := func(ctx context.Context, progress AdminJobProgress) (any, error) { return nil, nil }
+
+	if _, err := q.Submit(domain.AdminJobKindWarmupAll, "", "", noop); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := q.Submit(domain.AdminJobKindWarmupAll, "", "", noop); !errors.Is(err, domain.ErrAdminJobQueueFull) {
+		t.Errorf("Submit() on full queue error = %v, want ErrAdminJobQueueFull", err)
+	}
+	if jobs := q.List(); len(jobs) != 1 {
+		t.Errorf("List() = %d jobs, rejected job must not be recorded", len(jobs))
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// inventoryExportPageSize 导出库存快照时每次读取的库存行数
+const inventoryExportPageSize = 500
+
+// ExportInventory 分页读取库存并逐页写出，内存占用不随商品数增长；
+// CSV 带 UTF-8 BOM 以便 Excel 正确识别中文，商品已删除的库存无法按 SKU 调整，不导出
+func (s *inventoryService) ExportInventory(ctx context.Context, format string, w io.Writer) (int, error) {
+	if format != domain.InventoryExportFormatCSV && format != domain.InventoryExportFormatJSON {
+		return 0, domain.NewInvalidArgumentError("format must be json or csv")
+	}
+
+	var cw *csv.Writer
+	if format == domain.InventoryExportFormatCSV {
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return 0, err
+		}
+		cw = csv.NewWriter(w)
+		if err := cw.Write(domain.InventorySnapshotColumns); err != nil {
+			return 0, err
+		}
+	} else if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+
+	// 按创建时间升序翻页，导出期间新建的库存排在末尾，不会打乱已读取的页
+	sortBy, sortOrder := "created_at", "asc"
+	req := &domain.InventoryListRequest{PageSize: inventoryExportPageSize, SortBy: &sortBy, SortOrder: &sortOrder, SkipTotal: true}
+	count := 0
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		req.Page = page
+		inventories, total, err := s.inventoryRepo.List(ctx, req)
+		if err != nil {
+			return count, fmt.Errorf("failed to list inventories: %w", err)
+		}
+		inventories, pageInfo := domain.Paginate(inventories, total, req.Page, req.PageSize)
+
+		rows, err := s.inventorySnapshotRows(inventories)
+		if err != nil {
+			return count, err
+		}
+		for _, row := range rows {
+			if cw != nil {
+				err = cw.Write(inventorySnapshotRecord(row))
+			} else {
+				err = writeJSONArrayElement(w, row, count == 0)
+			}
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+		if !pageInfo.HasMore {
+			break
+		}
+	}
+
+	if cw != nil {
+		cw.Flush()
+		return count, cw.Error()
+	}
+	_, err := io.WriteString(w, "]\n")
+	return count, err
+}
+
+// inventorySnapshotRows 为一页库存关联商品 SKU 与名称
+func (s *inventoryService) inventorySnapshotRows(inventories []*domain.Inventory) ([]*domain.InventorySnapshotRow, error) {
+	if len(inventories) == 0 {
+		return nil, nil
+	}
+	ids := make([]int64, len(inventories))
+	for i, inventory := range inventories {
+		ids[i] = inventory.ProductID
+	}
+	products, err := s.productRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+	productByID := make(map[int64]*domain.Product, len(products))
+	for _, product := range products {
+		productByID[product.ID] = product
+	}
+
+	rows := make([]*domain.InventorySnapshotRow, 0, len(inventories))
+	for _, inventory := range inventories {
+		product, ok := productByID[inventory.ProductID]
+		if !ok {
+			continue
+		}
+		rows = append(rows, &domain.InventorySnapshotRow{
+			SKU:            product.SKU,
+			ProductID:      product.ID,
+			ProductName:    product.Name,
+			Stock:          inventory.Stock,
+			ReservedStock:  inventory.ReservedStock,
+			AvailableStock: inventory.AvailableStock(),
+			SoldStock:      inventory.SoldStock,
+			ReorderPoint:   inventory.ReorderPoint,
+			MaxStock:       inventory.MaxStock,
+			UpdatedAt:      inventory.UpdatedAt,
+		})
+	}
+	return rows, nil
+}
+
+// inventorySnapshotRecord 按 domain.InventorySnapshotColumns 的列顺序转换一行
+func inventorySnapshotRecord(row *domain.InventorySnapshotRow) []string {
+	return []string{
+		row.SKU,
+		strconv.FormatInt(row.ProductID, 10),
+		row.ProductName,
+		strconv.Itoa(row.Stock),
+		strconv.Itoa(row.ReservedStock),
+		strconv.Itoa(row.AvailableStock),
+		strconv.Itoa(row.SoldStock),
+		strconv.Itoa(row.ReorderPoint),
+		strconv.Itoa(row.MaxStock),
+		row.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// writeJSONArrayElement 写出 JSON 数组中的一个元素，非首个元素前加逗号
+func writeJSONArrayElement(w io.Writer, v any, first bool) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !first {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(data)
+	return err
+}
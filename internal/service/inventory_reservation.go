@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ExpiredReservationReleaser 归还过期的库存预留（由 InventoryService 实现）
+type ExpiredReservationReleaser interface {
+	ReleaseExpiredReservations(ctx context.Context, limit int) (int, error)
+}
+
+// ReservationReleaser 定期归还过期未消费、未释放的库存预留，
+// 避免结算流程崩溃等原因遗留的预留永久占用库存
+type ReservationReleaser struct {
+	releaser  ExpiredReservationReleaser
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewReservationReleaser 创建过期预留释放任务，每次扫描最多处理 batchSize 条，处理满一批时立即继续
+func NewReservationReleaser(releaser ExpiredReservationReleaser, interval time.Duration, batchSize int, logger *zap.Logger) *ReservationReleaser {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &ReservationReleaser{
+		releaser:  releaser,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Start 异步启动释放任务，ctx 取消时退出
+func (r *ReservationReleaser) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		r.logger.Info("过期库存预留释放任务已启动", zap.Duration("interval", r.interval))
+		for {
+			select {
+			case <-ctx.Done():
+				r.logger.Info("过期库存预留释放任务已停止")
+				return
+			case <-ticker.C:
+				r.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 归还当前所有已过期的预留，返回归还的条数
+func (r *ReservationReleaser) RunOnce(ctx context.Context) int {
+	total := 0
+	for ctx.Err() == nil {
+		released, err := r.releaser.ReleaseExpiredReservations(ctx, r.batchSize)
+		total += released
+		if err != nil {
+			r.logger.Warn("归还过期库存预留失败", zap.Int("released", released), zap.Error(err))
+			break
+		}
+		// 不满一批说明已处理完
+		if released < r.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		r.logger.Info("已归还过期库存预留", zap.Int("count", total))
+	}
+	return total
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+)
+
+// fakeReservationRepo 在内存中保存库存预留记录
+type fakeReservationRepo struct {
+	*mocks.InventoryReservationRepositoryMock
+
+	mu           sync.Mutex
+	reservations map[string]*domain.InventoryReservation
+}
+
+func newFakeReservationRepo() *fakeReservationRepo {
+	f := &fakeReservationRepo{
+		InventoryReservationRepositoryMock: &mocks.InventoryReservationRepositoryMock{},
+		reservations:                       make(map[string]*domain.InventoryReservation),
+	}
+	f.CreateFunc = f.create
+	f.GetByReservationIDFunc = f.getByReservationID
+	f.TransitionFunc = f.transition
+	f.ListExpiredFunc = f.listExpired
+	return f
+}
+
+func (f *fakeReservationRepo) create(reservation *domain.InventoryReservation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation.ID = int64(len(f.reservations) + 1)
+	stored := *reservation
+	f.reservations[reservation.ReservationID] = &stored
+	return nil
+}
+
+func (f *fakeReservationRepo) getByReservationID(reservationID string) (*domain.InventoryReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation, ok := f.reservations[reservationID]
+	if !ok {
+		return nil, domain.ErrReservationNotFound
+	}
+	copied := *reservation
+	return &copied, nil
+}
+
+func (f *fakeReservationRepo) transition(reservationID string, from, to domain.ReservationStatus) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation, ok := f.reservations[reservationID]
+	if !ok || reservation.Status != from {
+		return false, nil
+	}
+	reservation.Status = to
+	return true, nil
+}
+
+func (f *fakeReservationRepo) listExpired(now time.Time, limit int) ([]*domain.InventoryReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var expired []*domain.InventoryReservation
+	for _, reservation := range f.reservations {
+		if reservation.IsActive() && !reservation.ExpiresAt.After(now) && len(expired) < limit {
+			copied := *reservation
+			expired = append(expired, &copied)
+		}
+	}
+	return expired, nil
+}
+
+func (f *fakeReservationRepo) expire(reservationID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reservations[reservationID].ExpiresAt = time.Now().Add(-time.Second)
+}
+
+func newReservationTestService(t *testing.T) (InventoryService, *fakeReservationRepo, *domain.Inventory) {
+	t.Helper()
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domain.Product{ID: 1, Name: "Test Product", Status: domain.ProductStatusActive}
+
+	inventoryRepo := newMockInventoryRepository()
+	inventory := &domain.Inventory{ID: 1, ProductID: 1, Stock: 100, MaxStock: 1000}
+	inventoryRepo.inventories[1] = inventory
+	inventoryRepo.productMap[1] = inventory
+
+	reservations := newFakeReservationRepo()
+	svc := NewInventoryService(inventoryRepo, productRepo, WithReservations(reservations, 15*time.Minute, time.Hour))
+	return svc, reservations, inventory
+}
+
+func TestInventoryService_ReservationLifecycle(t *testing.T) {
+	svc, _, inventory := newReservationTestService(t)
+
+	reservation, err := svc.ReserveStock(context.Background(), &domain.ReserveStockRequest{ProductID: 1, Quantity: 10})
+	if err != nil {
+		t.Fatalf("ReserveStock() error = %v", err)
+	}
+	if reservation == nil || reservation.ReservationID == "" || reservation.Status != domain.ReservationStatusReserved {
+		t.Fatalf("reservation = %+v, want an active reservation with an ID", reservation)
+	}
+	if ttl := time.Until(reservation.ExpiresAt); ttl < 14*time.Minute || ttl > 15*time.Minute {
+		t.Errorf("expires in %s, want default 15m", ttl)
+	}
+	if inventory.ReservedStock != 10 {
+		t.Errorf("reserved_stock = %d, want 10", inventory.ReservedStock)
+	}
+
+	// 按预留ID消费，商品与数量以预留记录为准
+	if err := svc.ConsumeStock(context.Background(), &domain.ConsumeStockRequest{ReservationID: reservation.ReservationID}); err != nil {
+		t.Fatalf("ConsumeStock() error = %v", err)
+	}
+	if inventory.ReservedStock != 0 || inventory.Stock != 90 || inventory.SoldStock != 10 {
+		t.Errorf("inventory = %+v after consume", inventory)
+	}
+
+	// 同一预留只能结束一次
+	err = svc.ReleaseStock(context.Background(), &domain.ReleaseStockRequest{ReservationID: reservation.ReservationID})
+	if !errors.Is(err, domain.ErrReservationNotActive) {
+		t.Errorf("release after consume: err = %v, want ErrReservationNotActive", err)
+	}
+	err = svc.ReleaseStock(context.Background(), &domain.ReleaseStockRequest{ReservationID: "missing"})
+	if !errors.Is(err, domain.ErrReservationNotFound) {
+		t.Errorf("unknown reservation: err = %v, want ErrReservationNotFound", err)
+	}
+
+	if _, err := svc.ReserveStock(context.Background(), &domain.ReserveStockRequest{ProductID: 1, Quantity: 1, TTLSeconds: 7200}); err == nil {
+		t.Error("ReserveStock() accepted ttl above maximum")
+	}
+}
+
+func TestInventoryService_ReleaseExpiredReservations(t *testing.T) {
+	svc, reservations, inventory := newReservationTestService(t)
+
+	expired, err := svc.ReserveStock(context.Background(), &domain.ReserveStockRequest{ProductID: 1, Quantity: 30})
+	if err != nil {
+		t.Fatalf("ReserveStock() error = %v", err)
+	}
+	live, err := svc.ReserveStock(context.Background(), &domain.ReserveStockRequest{ProductID: 1, Quantity: 5})
+	if err != nil {
+		t.Fatalf("ReserveStock() error = %v", err)
+	}
+	reservations.expire(expired.ReservationID)
+
+	// 已过期的预留不能再消费
+	err = svc.ConsumeStock(context.Background(), &domain.ConsumeStockRequest{ReservationID: expired.ReservationID})
+	if !errors.Is(err, domain.ErrReservationNotActive) {
+		t.Errorf("consume expired reservation: err = %v, want ErrReservationNotActive", err)
+	}
+
+	releaser := NewReservationReleaser(svc, time.Minute, 10, nil)
+	if released := releaser.RunOnce(context.Background()); released != 1 {
+		t.Errorf("RunOnce() released %d, want 1", released)
+	}
+	if inventory.ReservedStock != 5 {
+		t.Errorf("reserved_stock = %d, want 5 (only the live reservation)", inventory.ReservedStock)
+	}
+	if got, _ := reservations.GetByReservationID(expired.ReservationID); got.Status != domain.ReservationStatusExpired {
+		t.Errorf("status = %s, want expired", got.Status)
+	}
+	if got, _ := reservations.GetByReservationID(live.ReservationID); !got.IsActive() {
+		t.Errorf("live reservation status = %s, want reserved", got.Status)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// ConflictRetryPolicy 库存变更遇到并发冲突时的重试策略。
+// 冲突包括乐观锁版本冲突以及仓储转换为版本冲突的死锁与锁等待超时
+type ConflictRetryPolicy struct {
+	MaxRetries int           // 首次冲突后的重试次数，0 表示不重试
+	Backoff    time.Duration // 首次重试前的等待时间，之后按指数翻倍
+	MaxBackoff time.Duration // 重试等待时间上限，0 表示不限制
+}
+
+// delay 返回第 retry 次重试前的等待时间（retry 从 1 开始），在 [d/2, d] 内随机抖动，
+// 避免同时冲突的请求再次同时重试
+func (p ConflictRetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff << (retry - 1)
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// WithConflictRetry 库存变更遇到并发冲突时按策略重试，重试耗尽后返回最后一次的冲突错误。
+// 乐观锁更新在每次重试时重新读取库存并重新校验请求
+func WithConflictRetry(policy ConflictRetryPolicy) InventoryServiceOption {
+	return func(s *inventoryService) {
+		if policy.MaxRetries > 0 {
+			s.conflictRetry = policy
+		}
+	}
+}
+
+// retryOnConflict 执行 fn，返回版本冲突时按策略重试，operation 为指标中的操作名
+func (s *inventoryService) retryOnConflict(ctx context.Context, operation string, fn func() error) error {
+	err := fn()
+	if !errors.Is(err, domain.ErrVersionConflict) || s.conflictRetry.MaxRetries <= 0 {
+		return err
+	}
+
+	for retry := 1; retry <= s.conflictRetry.MaxRetries; retry++ {
+		timer := time.NewTimer(s.conflictRetry.delay(retry))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			metrics.InventoryConflicts.Inc(operation, metrics.ConflictExhausted)
+			return err
+		}
+
+		metrics.InventoryConflictRetries.Inc(operation)
+		err = fn()
+		switch {
+		case err == nil:
+			metrics.InventoryConflicts.Inc(operation, metrics.ConflictRecovered)
+			return nil
+		case !errors.Is(err, domain.ErrVersionConflict):
+			metrics.InventoryConflicts.Inc(operation, metrics.ConflictFailed)
+			return err
+		}
+	}
+
+	metrics.InventoryConflicts.Inc(operation, metrics.ConflictExhausted)
+	return err
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// conflictingInventoryRepo 前 conflicts 次写入返回版本冲突，模拟并发请求先一步修改了库存
+type conflictingInventoryRepo struct {
+	*mockInventoryRepository
+	conflicts int
+	writes    int
+	reads     int
+}
+
+func (r *conflictingInventoryRepo) conflict() error {
+	r.writes++
+	if r.writes <= r.conflicts {
+		return domain.NewVersionConflictError("inventory version conflict or record not found")
+	}
+	return nil
+}
+
+func (r *conflictingInventoryRepo) GetByID(ctx context.Context, id int64) (*domain.Inventory, error) {
+	r.reads++
+	inventory, err := r.mockInventoryRepository.GetByID(ctx, id)
+	if inventory == nil || err != nil {
+		return inventory, err
+	}
+	copied := *inventory
+	return &copied, nil
+}
+
+func (r *conflictingInventoryRepo) UpdateWithVersion(ctx context.Context, inventory *domain.Inventory) error {
+	if err := r.conflict(); err != nil {
+		// 并发请求预留了库存，重试时必须按最新的预留数重新校验
+		r.inventories[inventory.ID].ReservedStock += 5
+		return err
+	}
+	return r.mockInventoryRepository.UpdateWithVersion(ctx, inventory)
+}
+
+func (r *conflictingInventoryRepo) ReserveStock(ctx context.Context, productID int64, quantity int) error {
+	if err := r.conflict(); err != nil {
+		return err
+	}
+	return r.mockInventoryRepository.ReserveStock(ctx, productID, quantity)
+}
+
+func newConflictingInventoryService(conflicts int, opts ...InventoryServiceOption) (InventoryService, *conflictingInventoryRepo) {
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domain.Product{ID: 1, Name: "Test Product", SKU: "TEST-001", Price: 99.99, Status: domain.ProductStatusActive}
+
+	inventoryRepo := &conflictingInventoryRepo{mockInventoryRepository: newMockInventoryRepository(), conflicts: conflicts}
+	inventory := &domain.Inventory{ID: 1, ProductID: 1, Stock: 100, ReorderPoint: 10, MaxStock: 1000}
+	inventoryRepo.inventories[1] = inventory
+	inventoryRepo.productMap[1] = inventory
+
+	return NewInventoryService(inventoryRepo, productRepo, opts...), inventoryRepo
+}
+
+func TestInventoryService_ConflictRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := ConflictRetryPolicy{MaxRetries: 2, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	stock := 8
+
+	// 冲突后重新读取库存并重新校验，成功后返回最新的库存
+	recovered := metrics.InventoryConflicts.Value("update", metrics.ConflictRecovered)
+	svc, inventoryRepo := newConflictingInventoryService(1, WithConflictRetry(policy))
+	inventory, err := svc.UpdateInventory(ctx, 1, &domain.UpdateInventoryRequest{Stock: &stock})
+	if err != nil {
+		t.Fatalf("UpdateInventory() error = %v", err)
+	}
+	if inventory.Stock != 8 || inventory.ReservedStock != 5 || inventoryRepo.reads != 2 {
+		t.Errorf("UpdateInventory() = stock %d, reserved %d after %d reads; want 8, 5 after 2 reads",
+			inventory.Stock, inventory.ReservedStock, inventoryRepo.reads)
+	}
+	if got := metrics.InventoryConflicts.Value("update", metrics.ConflictRecovered) - recovered; got != 1 {
+		t.Errorf("recovered conflicts = %v, want 1", got)
+	}
+
+	// 重新校验不通过时返回校验错误，不再重试
+	stock = 3
+	svc, inventoryRepo = newConflictingInventoryService(1, WithConflictRetry(policy))
+	if _, err := svc.UpdateInventory(ctx, 1, &domain.UpdateInventoryRequest{Stock: &stock}); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("UpdateInventory() error = %v, want ErrInvalidArgument", err)
+	}
+	if inventoryRepo.writes != 1 {
+		t.Errorf("writes = %d, want 1", inventoryRepo.writes)
+	}
+
+	// 重试耗尽后返回冲突
+	svc, inventoryRepo = newConflictingInventoryService(10, WithConflictRetry(policy))
+	if _, err := svc.ReserveStock(ctx, &domain.ReserveStockRequest{ProductID: 1, Quantity: 1}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("ReserveStock() error = %v, want ErrVersionConflict", err)
+	}
+	if inventoryRepo.writes != 3 {
+		t.Errorf("writes = %d, want 3", inventoryRepo.writes)
+	}
+
+	// 未配置重试策略时冲突直接返回
+	svc, inventoryRepo = newConflictingInventoryService(1)
+	if _, err := svc.ReserveStock(ctx, &domain.ReserveStockRequest{ProductID: 1, Quantity: 1}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("ReserveStock() error = %v, want ErrVersionConflict", err)
+	}
+	if inventoryRepo.writes != 1 {
+		t.Errorf("writes = %d, want 1", inventoryRepo.writes)
+	}
+}
+
+func TestConflictRetryPolicy_Delay(t *testing.T) {
+	policy := ConflictRetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond}
+	for retry, limit := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 5: 25 * time.Millisecond} {
+		for range 20 {
+			if d := policy.delay(retry); d < limit/2 || d > limit {
+				t.Fatalf("delay(%d) = %s, want within [%s, %s]", retry, d, limit/2, limit)
+			}
+		}
+	}
+}
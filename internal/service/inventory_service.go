@@ -2,9 +2,16 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
 	"github.com/MorseWayne/spike_shop/internal/domain"
 	"github.com/MorseWayne/spike_shop/internal/repo"
 )
@@ -12,31 +19,42 @@ import (
 // InventoryService 定义库存业务逻辑接口
 type InventoryService interface {
 	// 库存管理
-	CreateInventory(req *domain.CreateInventoryRequest) (*domain.Inventory, error)
-	GetInventory(id int64) (*domain.Inventory, error)
-	GetInventoryByProductID(productID int64) (*domain.Inventory, error)
-	UpdateInventory(id int64, req *domain.UpdateInventoryRequest) (*domain.Inventory, error)
-	DeleteInventory(id int64) error
+	CreateInventory(ctx context.Context, req *domain.CreateInventoryRequest) (*domain.Inventory, error)
+	GetInventory(ctx context.Context, id int64) (*domain.Inventory, error)
+	GetInventoryByProductID(ctx context.Context, productID int64) (*domain.Inventory, error)
+	UpdateInventory(ctx context.Context, id int64, req *domain.UpdateInventoryRequest) (*domain.Inventory, error)
+	DeleteInventory(ctx context.Context, id int64) error
 
 	// 库存查询
-	ListInventories(req *domain.InventoryListRequest) (*domain.InventoryListResponse, error)
-	GetLowStockAlerts() ([]*LowStockAlert, error)
+	ListInventories(ctx context.Context, req *domain.InventoryListRequest) (*domain.InventoryListResponse, error)
+	GetLowStockAlerts(ctx context.Context) ([]*LowStockAlert, error)
+	// ListStockMovements 分页查询库存记录对应商品的库存变动流水，未启用流水查询时返回错误
+	ListStockMovements(ctx context.Context, inventoryID int64, req *domain.StockMovementListRequest) (*domain.StockMovementListResponse, error)
 
 	// 库存操作
-	AdjustStock(productID int64, req *domain.StockAdjustmentRequest) error
-	ReserveStock(req *domain.ReserveStockRequest) error
-	ReleaseStock(req *domain.ReleaseStockRequest) error
-	ConsumeStock(req *domain.ConsumeStockRequest) error
-	RestockProduct(productID int64, quantity int, reason string) error
+	AdjustStock(ctx context.Context, productID int64, req *domain.StockAdjustmentRequest) error
+	// ReserveStock 预留库存；启用预留记录时返回带有效期的预留，调用方凭预留ID消费或释放，否则返回 nil
+	ReserveStock(ctx context.Context, req *domain.ReserveStockRequest) (*domain.InventoryReservation, error)
+	ReleaseStock(ctx context.Context, req *domain.ReleaseStockRequest) error
+	ConsumeStock(ctx context.Context, req *domain.ConsumeStockRequest) error
+	RestockProduct(ctx context.Context, productID int64, quantity int, reason string) error
+	// BulkAdjustStock 按 SKU 批量调整库存，逐行返回处理结果；partial 模式单行失败不影响其余行，
+	// atomic 模式任一行失败时全部不生效，dry_run 只校验不执行
+	BulkAdjustStock(ctx context.Context, items []domain.BulkStockAdjustmentItem, opts domain.BulkStockAdjustmentOptions) (*domain.BulkStockAdjustmentReport, error)
+	// ExportInventory 按商品 SKU 导出全部库存的当前快照，返回导出的行数
+	ExportInventory(ctx context.Context, format string, w io.Writer) (int, error)
+	// ReleaseExpiredReservations 将最多 limit 条过期未处理的预留归还到可用库存，返回归还的条数
+	ReleaseExpiredReservations(ctx context.Context, limit int) (int, error)
 
 	// 批量操作
-	BatchReserveStock(requests []*domain.ReserveStockRequest) error
-	BatchReleaseStock(requests []*domain.ReleaseStockRequest) error
-	BatchConsumeStock(requests []*domain.ConsumeStockRequest) error
+	BatchReserveStock(ctx context.Context, requests []*domain.ReserveStockRequest) error
+	BatchReleaseStock(ctx context.Context, requests []*domain.ReleaseStockRequest) error
+	BatchConsumeStock(ctx context.Context, requests []*domain.ConsumeStockRequest) error
 
 	// 统计查询
-	GetInventoryStats() (*InventoryStats, error)
-	CheckStockAvailability(productID int64, quantity int) (bool, error)
+	GetInventoryStats(ctx context.Context) (*InventoryStats, error)
+	CheckStockAvailability(ctx context.Context, productID int64, quantity int) (bool, error)
+	BatchCheckStockAvailability(ctx context.Context, items []domain.StockCheckItem) (*domain.BatchStockCheckResponse, error)
 }
 
 // LowStockAlert 低库存警告
@@ -64,45 +82,110 @@ type InventoryStats struct {
 type inventoryService struct {
 	inventoryRepo repo.InventoryRepository
 	productRepo   repo.ProductRepository
+
+	// 可用库存短TTL缓存（可选），库存变动时主动失效
+	availabilityCache cache.Cache
+	availabilityTTL   time.Duration
+
+	// 库存预留记录（可选），未启用时预留没有有效期
+	reservations          repo.InventoryReservationRepository
+	reservationTTL        time.Duration
+	reservationMaxTTL     time.Duration
+	reservationIDProvider func() string
+
+	// 商品停售标记（可选）
+	stopSell StopSellChecker
+
+	// 库存变动流水查询（可选）
+	stockMovements repo.StockMovementRepository
+
+	// 并发冲突重试策略（可选），未设置时冲突直接返回
+	conflictRetry ConflictRetryPolicy
+}
+
+// InventoryServiceOption 库存服务可选配置
+type InventoryServiceOption func(*inventoryService)
+
+// WithAvailabilityCache 为可用性检查启用短TTL缓存。
+// 缓存的是可用库存数（库存 - 预留），任意数量的可用性判断都可由它直接得出；
+// 经本服务的库存变动会立即失效缓存，其他途径的变动最多滞后一个 TTL。
+func WithAvailabilityCache(c cache.Cache, ttl time.Duration) InventoryServiceOption {
+	return func(s *inventoryService) {
+		if c != nil && ttl > 0 {
+			s.availabilityCache = c
+			s.availabilityTTL = ttl
+		}
+	}
+}
+
+// WithReservations 为每次预留记录带有效期的预留：默认有效期 ttl，调用方可指定但不超过 maxTTL。
+// 预留ID返回给调用方用于消费或释放，过期未处理的预留由 ReleaseExpiredReservations 归还
+func WithReservations(reservations repo.InventoryReservationRepository, ttl, maxTTL time.Duration) InventoryServiceOption {
+	return func(s *inventoryService) {
+		if reservations != nil && ttl > 0 {
+			s.reservations = reservations
+			s.reservationTTL = ttl
+			s.reservationMaxTTL = max(maxTTL, ttl)
+		}
+	}
+}
+
+// WithStopSell 预留库存前检查商品停售标记，标记读取失败时拒绝预留
+func WithStopSell(checker StopSellChecker) InventoryServiceOption {
+	return func(s *inventoryService) {
+		s.stopSell = checker
+	}
+}
+
+// WithStockMovements 启用库存变动流水查询，流水本身由库存仓储在变更库存时写入
+func WithStockMovements(movements repo.StockMovementRepository) InventoryServiceOption {
+	return func(s *inventoryService) {
+		s.stockMovements = movements
+	}
 }
 
 // NewInventoryService 创建库存服务实例
-func NewInventoryService(inventoryRepo repo.InventoryRepository, productRepo repo.ProductRepository) InventoryService {
-	return &inventoryService{
-		inventoryRepo: inventoryRepo,
-		productRepo:   productRepo,
+func NewInventoryService(inventoryRepo repo.InventoryRepository, productRepo repo.ProductRepository, opts ...InventoryServiceOption) InventoryService {
+	s := &inventoryService{
+		inventoryRepo:         inventoryRepo,
+		productRepo:           productRepo,
+		reservationIDProvider: func() string { return uuid.New().String() },
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // CreateInventory 创建库存记录
-func (s *inventoryService) CreateInventory(req *domain.CreateInventoryRequest) (*domain.Inventory, error) {
+func (s *inventoryService) CreateInventory(ctx context.Context, req *domain.CreateInventoryRequest) (*domain.Inventory, error) {
 	// 验证商品是否存在
 	product, err := s.productRepo.GetByID(req.ProductID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 	if product == nil {
-		return nil, errors.New("product not found")
+		return nil, domain.NewNotFoundError("product not found")
 	}
 
 	// 检查是否已存在库存记录
-	existing, err := s.inventoryRepo.GetByProductID(req.ProductID)
+	existing, err := s.inventoryRepo.GetByProductID(ctx, req.ProductID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing inventory: %w", err)
 	}
 	if existing != nil {
-		return nil, errors.New("inventory already exists for this product")
+		return nil, domain.NewConflictError("inventory already exists for this product")
 	}
 
 	// 验证库存数据
 	if req.Stock < 0 {
-		return nil, errors.New("stock cannot be negative")
+		return nil, domain.NewInvalidArgumentError("stock cannot be negative")
 	}
 	if req.MaxStock <= 0 {
-		return nil, errors.New("max stock must be greater than 0")
+		return nil, domain.NewInvalidArgumentError("max stock must be greater than 0")
 	}
 	if req.Stock > req.MaxStock {
-		return nil, errors.New("stock cannot exceed max stock")
+		return nil, domain.NewInvalidArgumentError("stock cannot exceed max stock")
 	}
 
 	// 创建库存记录
@@ -116,7 +199,7 @@ func (s *inventoryService) CreateInventory(req *domain.CreateInventoryRequest) (
 		Version:       0,
 	}
 
-	err = s.inventoryRepo.Create(inventory)
+	err = s.inventoryRepo.Create(ctx, inventory)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create inventory: %w", err)
 	}
@@ -125,70 +208,86 @@ func (s *inventoryService) CreateInventory(req *domain.CreateInventoryRequest) (
 }
 
 // GetInventory 获取库存详情
-func (s *inventoryService) GetInventory(id int64) (*domain.Inventory, error) {
-	inventory, err := s.inventoryRepo.GetByID(id)
+func (s *inventoryService) GetInventory(ctx context.Context, id int64) (*domain.Inventory, error) {
+	inventory, err := s.inventoryRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inventory: %w", err)
 	}
 	if inventory == nil {
-		return nil, errors.New("inventory not found")
+		return nil, domain.NewNotFoundError("inventory not found")
 	}
 
 	return inventory, nil
 }
 
 // GetInventoryByProductID 根据商品ID获取库存
-func (s *inventoryService) GetInventoryByProductID(productID int64) (*domain.Inventory, error) {
-	inventory, err := s.inventoryRepo.GetByProductID(productID)
+func (s *inventoryService) GetInventoryByProductID(ctx context.Context, productID int64) (*domain.Inventory, error) {
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inventory by product ID: %w", err)
 	}
 	if inventory == nil {
-		return nil, errors.New("inventory not found")
+		return nil, domain.NewNotFoundError("inventory not found")
+	}
+
+	return inventory, nil
+}
+
+// UpdateInventory 更新库存，版本冲突时重新读取库存、重新校验后重试
+func (s *inventoryService) UpdateInventory(ctx context.Context, id int64, req *domain.UpdateInventoryRequest) (*domain.Inventory, error) {
+	var inventory *domain.Inventory
+	err := s.retryOnConflict(ctx, "update", func() error {
+		var err error
+		inventory, err = s.updateInventoryOnce(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.invalidateAvailability(ctx, inventory.ProductID)
 
 	return inventory, nil
 }
 
-// UpdateInventory 更新库存
-func (s *inventoryService) UpdateInventory(id int64, req *domain.UpdateInventoryRequest) (*domain.Inventory, error) {
+// updateInventoryOnce 读取库存、应用更新并以乐观锁保存
+func (s *inventoryService) updateInventoryOnce(ctx context.Context, id int64, req *domain.UpdateInventoryRequest) (*domain.Inventory, error) {
 	// 获取现有库存记录
-	inventory, err := s.inventoryRepo.GetByID(id)
+	inventory, err := s.inventoryRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inventory: %w", err)
 	}
 	if inventory == nil {
-		return nil, errors.New("inventory not found")
+		return nil, domain.NewNotFoundError("inventory not found")
 	}
 
 	// 更新字段
 	if req.Stock != nil {
 		if *req.Stock < 0 {
-			return nil, errors.New("stock cannot be negative")
+			return nil, domain.NewInvalidArgumentError("stock cannot be negative")
 		}
 		if *req.Stock < inventory.ReservedStock {
-			return nil, errors.New("stock cannot be less than reserved stock")
+			return nil, domain.NewInvalidArgumentError("stock cannot be less than reserved stock")
 		}
 		inventory.Stock = *req.Stock
 	}
 	if req.ReorderPoint != nil {
 		if *req.ReorderPoint < 0 {
-			return nil, errors.New("reorder point cannot be negative")
+			return nil, domain.NewInvalidArgumentError("reorder point cannot be negative")
 		}
 		inventory.ReorderPoint = *req.ReorderPoint
 	}
 	if req.MaxStock != nil {
 		if *req.MaxStock <= 0 {
-			return nil, errors.New("max stock must be greater than 0")
+			return nil, domain.NewInvalidArgumentError("max stock must be greater than 0")
 		}
 		if inventory.Stock > *req.MaxStock {
-			return nil, errors.New("current stock exceeds new max stock")
+			return nil, domain.NewInvalidArgumentError("current stock exceeds new max stock")
 		}
 		inventory.MaxStock = *req.MaxStock
 	}
 
 	// 保存更新
-	err = s.inventoryRepo.UpdateWithVersion(inventory)
+	err = s.inventoryRepo.UpdateWithVersion(ctx, inventory)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update inventory: %w", err)
 	}
@@ -197,26 +296,31 @@ func (s *inventoryService) UpdateInventory(id int64, req *domain.UpdateInventory
 }
 
 // DeleteInventory 删除库存记录
-func (s *inventoryService) DeleteInventory(id int64) error {
+func (s *inventoryService) DeleteInventory(ctx context.Context, id int64) error {
 	// 检查库存是否存在
-	inventory, err := s.inventoryRepo.GetByID(id)
+	inventory, err := s.inventoryRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get inventory: %w", err)
 	}
 	if inventory == nil {
-		return errors.New("inventory not found")
+		return domain.NewNotFoundError("inventory not found")
 	}
 
 	// 检查是否有剩余库存或预留库存
 	if inventory.Stock > 0 || inventory.ReservedStock > 0 {
-		return errors.New("cannot delete inventory with remaining stock")
+		return domain.NewConflictError("cannot delete inventory with remaining stock")
 	}
 
-	return s.inventoryRepo.Delete(id)
+	if err := s.inventoryRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateAvailability(ctx, inventory.ProductID)
+
+	return nil
 }
 
 // ListInventories 获取库存列表
-func (s *inventoryService) ListInventories(req *domain.InventoryListRequest) (*domain.InventoryListResponse, error) {
+func (s *inventoryService) ListInventories(ctx context.Context, req *domain.InventoryListRequest) (*domain.InventoryListResponse, error) {
 	// 设置默认值
 	if req.Page <= 0 {
 		req.Page = 1
@@ -229,23 +333,63 @@ func (s *inventoryService) ListInventories(req *domain.InventoryListRequest) (*d
 	}
 
 	// 查询库存列表
-	inventories, total, err := s.inventoryRepo.List(req)
+	inventories, total, err := s.inventoryRepo.List(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list inventories: %w", err)
 	}
+	inventories, pageInfo := domain.Paginate(inventories, total, req.Page, req.PageSize)
 
 	return &domain.InventoryListResponse{
 		Inventories: inventories,
 		Total:       total,
 		Page:        req.Page,
 		PageSize:    req.PageSize,
+		PageInfo:    pageInfo,
+	}, nil
+}
+
+// ListStockMovements 获取库存变动流水
+func (s *inventoryService) ListStockMovements(ctx context.Context, inventoryID int64, req *domain.StockMovementListRequest) (*domain.StockMovementListResponse, error) {
+	if s.stockMovements == nil {
+		return nil, errors.New("stock movements are not enabled")
+	}
+
+	inventory, err := s.GetInventory(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+	req.ProductID = inventory.ProductID
+
+	movements, total, err := s.stockMovements.List(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+	movements, pageInfo := domain.Paginate(movements, total, req.Page, req.PageSize)
+
+	return &domain.StockMovementListResponse{
+		Items:    movements,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // GetLowStockAlerts 获取低库存警告
-func (s *inventoryService) GetLowStockAlerts() ([]*LowStockAlert, error) {
+func (s *inventoryService) GetLowStockAlerts(ctx context.Context) ([]*LowStockAlert, error) {
 	// 获取低库存商品
-	lowStockInventories, err := s.inventoryRepo.GetLowStockProducts()
+	lowStockInventories, err := s.inventoryRepo.GetLowStockProducts(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get low stock products: %w", err)
 	}
@@ -295,7 +439,7 @@ func (s *inventoryService) GetLowStockAlerts() ([]*LowStockAlert, error) {
 }
 
 // AdjustStock 调整库存
-func (s *inventoryService) AdjustStock(productID int64, req *domain.StockAdjustmentRequest) error {
+func (s *inventoryService) AdjustStock(ctx context.Context, productID int64, req *domain.StockAdjustmentRequest) error {
 	// 验证商品存在
 	_, err := s.productRepo.GetByID(productID)
 	if err != nil {
@@ -310,88 +454,448 @@ func (s *inventoryService) AdjustStock(productID int64, req *domain.StockAdjustm
 	}
 
 	// 执行库存调整
-	err = s.inventoryRepo.AdjustStock(productID, req.Quantity, req.Reason)
+	err = s.retryOnConflict(ctx, "adjust", func() error {
+		return s.inventoryRepo.AdjustStock(ctx, productID, req.Quantity, req.Reason)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to adjust stock: %w", err)
 	}
+	s.invalidateAvailability(ctx, productID)
 
 	return nil
 }
 
 // ReserveStock 预留库存
-func (s *inventoryService) ReserveStock(req *domain.ReserveStockRequest) error {
+func (s *inventoryService) ReserveStock(ctx context.Context, req *domain.ReserveStockRequest) (*domain.InventoryReservation, error) {
+	ttl, err := s.reservationTTLFor(req.TTLSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证商品存在且可售
 	product, err := s.productRepo.GetByID(req.ProductID)
 	if err != nil {
-		return fmt.Errorf("failed to get product: %w", err)
+		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 	if product == nil {
-		return errors.New("product not found")
+		return nil, domain.NewNotFoundError("product not found")
 	}
 	if !product.IsAvailable() {
-		return errors.New("product is not available for sale")
+		return nil, domain.NewInvalidArgumentError("product is not available for sale")
+	}
+	if s.stopSell != nil {
+		stopped, err := s.stopSell.IsStopped(ctx, req.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if stopped {
+			return nil, domain.ErrProductStopSell
+		}
+	}
+
+	// 预留ID先于库存变更生成，作为库存变动流水的关联单据
+	var reservationID string
+	if s.reservations != nil {
+		reservationID = s.reservationIDProvider()
+		ctx = domain.WithStockReference(ctx, reservationID, "库存预留")
 	}
 
 	// 预留库存
-	err = s.inventoryRepo.ReserveStock(req.ProductID, req.Quantity)
+	err = s.retryOnConflict(ctx, "reserve", func() error {
+		return s.inventoryRepo.ReserveStock(ctx, req.ProductID, req.Quantity)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to reserve stock: %w", err)
+		return nil, fmt.Errorf("failed to reserve stock: %w", err)
 	}
+	s.invalidateAvailability(ctx, req.ProductID)
 
-	return nil
+	if s.reservations == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	reservation := &domain.InventoryReservation{
+		ReservationID: reservationID,
+		ProductID:     req.ProductID,
+		Quantity:      req.Quantity,
+		Status:        domain.ReservationStatusReserved,
+		ExpiresAt:     now.Add(ttl),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.reservations.Create(reservation); err != nil {
+		// 没有记录的预留无法过期释放，立即归还
+		ctx = domain.WithStockReference(ctx, reservationID, "预留记录写入失败，归还库存")
+		if releaseErr := s.inventoryRepo.ReleaseStock(ctx, req.ProductID, req.Quantity); releaseErr != nil {
+			return nil, fmt.Errorf("failed to record reservation: %w (release also failed: %v)", err, releaseErr)
+		}
+		s.invalidateAvailability(ctx, req.ProductID)
+		return nil, fmt.Errorf("failed to record reservation: %w", err)
+	}
+
+	return reservation, nil
 }
 
 // ReleaseStock 释放库存
-func (s *inventoryService) ReleaseStock(req *domain.ReleaseStockRequest) error {
-	err := s.inventoryRepo.ReleaseStock(req.ProductID, req.Quantity)
+func (s *inventoryService) ReleaseStock(ctx context.Context, req *domain.ReleaseStockRequest) error {
+	if req.ReservationID != "" {
+		return s.settleReservation(ctx, req.ReservationID, domain.ReservationStatusReleased)
+	}
+
+	err := s.retryOnConflict(ctx, "release", func() error {
+		return s.inventoryRepo.ReleaseStock(ctx, req.ProductID, req.Quantity)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to release stock: %w", err)
 	}
+	s.invalidateAvailability(ctx, req.ProductID)
 
 	return nil
 }
 
 // ConsumeStock 消费库存
-func (s *inventoryService) ConsumeStock(req *domain.ConsumeStockRequest) error {
-	err := s.inventoryRepo.ConsumeStock(req.ProductID, req.Quantity)
+func (s *inventoryService) ConsumeStock(ctx context.Context, req *domain.ConsumeStockRequest) error {
+	if req.ReservationID != "" {
+		return s.settleReservation(ctx, req.ReservationID, domain.ReservationStatusConsumed)
+	}
+
+	err := s.retryOnConflict(ctx, "consume", func() error {
+		return s.inventoryRepo.ConsumeStock(ctx, req.ProductID, req.Quantity)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to consume stock: %w", err)
 	}
+	s.invalidateAvailability(ctx, req.ProductID)
 
 	return nil
 }
 
+// ReleaseExpiredReservations 归还过期的预留，单条失败不影响其余记录
+func (s *inventoryService) ReleaseExpiredReservations(ctx context.Context, limit int) (int, error) {
+	if s.reservations == nil {
+		return 0, nil
+	}
+
+	expired, err := s.reservations.ListExpired(time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	var errs []error
+	for _, reservation := range expired {
+		err := s.settleReservation(ctx, reservation.ReservationID, domain.ReservationStatusExpired)
+		switch {
+		case err == nil:
+			released++
+		case errors.Is(err, domain.ErrReservationNotActive):
+			// 与调用方的消费或释放并发，已被对方处理
+		default:
+			errs = append(errs, fmt.Errorf("reservation %s: %w", reservation.ReservationID, err))
+		}
+	}
+	return released, errors.Join(errs...)
+}
+
+// settleReservation 结束一条预留：先以条件更新抢占状态，保证同一预留只被消费或释放一次，
+// 再调整库存；调整失败时恢复为预留中，留给调用方或释放任务重试
+func (s *inventoryService) settleReservation(ctx context.Context, reservationID string, to domain.ReservationStatus) error {
+	if s.reservations == nil {
+		return errors.New("inventory reservations are not enabled")
+	}
+
+	reservation, err := s.reservations.GetByReservationID(reservationID)
+	if err != nil {
+		return err
+	}
+	if !reservation.IsActive() {
+		return domain.ErrReservationNotActive
+	}
+	// 已过期的预留不能再消费，等待释放任务归还
+	if to == domain.ReservationStatusConsumed && !time.Now().Before(reservation.ExpiresAt) {
+		return domain.ErrReservationNotActive
+	}
+
+	ok, err := s.reservations.Transition(reservationID, domain.ReservationStatusReserved, to)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrReservationNotActive
+	}
+
+	reason := "预留消费"
+	switch to {
+	case domain.ReservationStatusReleased:
+		reason = "预留释放"
+	case domain.ReservationStatusExpired:
+		reason = "预留过期归还"
+	}
+	ctx = domain.WithStockReference(ctx, reservationID, reason)
+
+	if to == domain.ReservationStatusConsumed {
+		err = s.retryOnConflict(ctx, "consume", func() error {
+			return s.inventoryRepo.ConsumeStock(ctx, reservation.ProductID, reservation.Quantity)
+		})
+	} else {
+		err = s.retryOnConflict(ctx, "release", func() error {
+			return s.inventoryRepo.ReleaseStock(ctx, reservation.ProductID, reservation.Quantity)
+		})
+	}
+	if err != nil {
+		if _, revertErr := s.reservations.Transition(reservationID, to, domain.ReservationStatusReserved); revertErr != nil {
+			return fmt.Errorf("failed to settle reservation: %w (revert also failed: %v)", err, revertErr)
+		}
+		return fmt.Errorf("failed to settle reservation: %w", err)
+	}
+	s.invalidateAvailability(ctx, reservation.ProductID)
+
+	return nil
+}
+
+// reservationTTLFor 计算预留有效期，ttlSeconds 为 0 时使用默认有效期
+func (s *inventoryService) reservationTTLFor(ttlSeconds int) (time.Duration, error) {
+	if ttlSeconds < 0 {
+		return 0, domain.NewInvalidArgumentError("ttl_seconds must not be negative")
+	}
+	if ttlSeconds == 0 {
+		return s.reservationTTL, nil
+	}
+	ttl := time.Duration(ttlSeconds) * time.Second
+	if s.reservations != nil && ttl > s.reservationMaxTTL {
+		return 0, domain.NewInvalidArgumentError(fmt.Sprintf("ttl_seconds exceeds maximum %d", int64(s.reservationMaxTTL/time.Second)))
+	}
+	return ttl, nil
+}
+
 // RestockProduct 补充库存
-func (s *inventoryService) RestockProduct(productID int64, quantity int, reason string) error {
+func (s *inventoryService) RestockProduct(ctx context.Context, productID int64, quantity int, reason string) error {
 	if quantity <= 0 {
-		return errors.New("restock quantity must be positive")
+		return domain.NewInvalidArgumentError("restock quantity must be positive")
 	}
 
 	// 获取库存记录
-	inventory, err := s.inventoryRepo.GetByProductID(productID)
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
 	if err != nil {
 		return fmt.Errorf("failed to get inventory: %w", err)
 	}
 	if inventory == nil {
-		return errors.New("inventory not found")
+		return domain.NewNotFoundError("inventory not found")
 	}
 
 	// 检查是否超过最大库存限制
 	if inventory.Stock+quantity > inventory.MaxStock {
-		return fmt.Errorf("restock would exceed max stock limit (%d)", inventory.MaxStock)
+		return domain.NewInvalidArgumentError(fmt.Sprintf("restock would exceed max stock limit (%d)", inventory.MaxStock))
 	}
 
 	// 执行补货
-	err = s.inventoryRepo.AdjustStock(productID, quantity, reason)
+	err = s.retryOnConflict(ctx, "restock", func() error {
+		return s.inventoryRepo.AdjustStock(ctx, productID, quantity, reason)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to restock: %w", err)
 	}
+	s.invalidateAvailability(ctx, productID)
+
+	return nil
+}
+
+// bulkAdjustBatchSize 批量库存调整每个事务处理的行数
+const bulkAdjustBatchSize = 100
+
+// errBulkAdjustNotApplied atomic 模式下其他行失败、本行未执行时报告的原因
+const errBulkAdjustNotApplied = "not applied because another row failed"
+
+// BulkAdjustStock 批量调整库存。
+// 先逐行校验 SKU、调整量与原因，并按当前库存依次累计调整量，提前发现会导致负库存的行。
+// partial 模式按 bulkAdjustBatchSize 分批在事务中执行，某行执行失败时整批回滚，标记该行失败后重试其余行，
+// 因此报告中每行的结果与库存实际变动一致；atomic 模式只有全部行通过校验才执行，且全部行在一个事务中执行
+func (s *inventoryService) BulkAdjustStock(ctx context.Context, items []domain.BulkStockAdjustmentItem, opts domain.BulkStockAdjustmentOptions) (*domain.BulkStockAdjustmentReport, error) {
+	mode, err := domain.ParseBulkStockAdjustmentMode(string(opts.Mode))
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.BulkStockAdjustmentReport{
+		Mode:   mode,
+		DryRun: opts.DryRun,
+		Total:  len(items),
+		Rows:   make([]*domain.BulkStockAdjustmentRowResult, len(items)),
+	}
+
+	products := make(map[string]*domain.Product)
+	stocks := make(map[int64]int) // 按商品累计已通过校验的调整后的库存
+	var pending []int             // 通过校验、待执行的行下标
+	for i, item := range items {
+		row := &domain.BulkStockAdjustmentRowResult{Row: i + 1, SKU: item.SKU, Delta: item.Delta}
+		report.Rows[i] = row
+
+		inventory, err := s.validateBulkAdjustment(ctx, item, products)
+		if err != nil {
+			row.Error = err.Error()
+			continue
+		}
+		stock, seen := stocks[inventory.ProductID]
+		if !seen {
+			stock = inventory.Stock
+		}
+		if stock+item.Delta < 0 {
+			row.Error = "stock adjustment would result in negative stock"
+			continue
+		}
+		stocks[inventory.ProductID] = stock + item.Delta
+		row.ProductID = inventory.ProductID
+		pending = append(pending, i)
+	}
+
+	switch {
+	case opts.DryRun:
+		for _, i := range pending {
+			report.Rows[i].Success = true
+		}
+	case mode == domain.BulkStockAdjustmentAtomic:
+		applied := false
+		if len(pending) == len(items) {
+			if applied, err = s.applyBulkAdjustAtomic(ctx, items, report.Rows); err != nil {
+				return nil, err
+			}
+		}
+		if !applied {
+			report.RolledBack = true
+			for _, row := range report.Rows {
+				if row.Error == "" {
+					row.Error = errBulkAdjustNotApplied
+				}
+			}
+		}
+	default:
+		for start := 0; start < len(pending); start += bulkAdjustBatchSize {
+			batch := pending[start:min(start+bulkAdjustBatchSize, len(pending))]
+			if err := s.applyBulkAdjustBatch(ctx, items, report.Rows, batch); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, row := range report.Rows {
+		if row.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// validateBulkAdjustment 校验一行批量调整并返回该 SKU 的库存，products 缓存已查询的 SKU
+func (s *inventoryService) validateBulkAdjustment(ctx context.Context, item domain.BulkStockAdjustmentItem, products map[string]*domain.Product) (*domain.Inventory, error) {
+	if item.SKU == "" {
+		return nil, domain.NewInvalidArgumentError("sku is required")
+	}
+	if item.Delta == 0 {
+		return nil, domain.NewInvalidArgumentError("delta must not be zero")
+	}
+	if item.Reason == "" {
+		return nil, domain.NewInvalidArgumentError("reason is required")
+	}
+	if len(item.Reason) > 255 {
+		return nil, domain.NewInvalidArgumentError("reason must not exceed 255 characters")
+	}
+
+	product, cached := products[item.SKU]
+	if !cached {
+		var err error
+		product, err = s.productRepo.GetBySKU(item.SKU)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product: %w", err)
+		}
+		products[item.SKU] = product
+	}
+	if product == nil {
+		return nil, domain.NewNotFoundError("product not found")
+	}
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, product.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+	if inventory == nil {
+		return nil, domain.NewNotFoundError("inventory not found")
+	}
+	return inventory, nil
+}
+
+// bulkAdjustUpdates 将指定下标的行转换为库存调整
+func bulkAdjustUpdates(items []domain.BulkStockAdjustmentItem, rows []*domain.BulkStockAdjustmentRowResult, batch []int) []repo.StockUpdate {
+	updates := make([]repo.StockUpdate, len(batch))
+	for j, i := range batch {
+		updates[j] = repo.StockUpdate{
+			ProductID: rows[i].ProductID,
+			Quantity:  items[i].Delta,
+			Type:      "adjust",
+			Reason:    items[i].Reason,
+		}
+	}
+	return updates
+}
+
+// applyBulkAdjustAtomic 在一个事务中执行全部调整，某行执行失败时整体回滚并记录该行的错误，返回是否已生效
+func (s *inventoryService) applyBulkAdjustAtomic(ctx context.Context, items []domain.BulkStockAdjustmentItem, rows []*domain.BulkStockAdjustmentRowResult) (bool, error) {
+	all := make([]int, len(items))
+	for i := range all {
+		all[i] = i
+	}
+
+	err := s.retryOnConflict(ctx, "bulk_adjust", func() error {
+		return s.inventoryRepo.BatchUpdateStock(ctx, bulkAdjustUpdates(items, rows, all))
+	})
+	if err == nil {
+		productIDs := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			row.Success = true
+			productIDs = append(productIDs, row.ProductID)
+		}
+		s.invalidateAvailability(ctx, productIDs...)
+		return true, nil
+	}
+
+	var updateErr *repo.StockUpdateError
+	if !errors.As(err, &updateErr) || updateErr.Index < 0 || updateErr.Index >= len(rows) {
+		return false, fmt.Errorf("failed to adjust stock atomically: %w", err)
+	}
+	rows[updateErr.Index].Error = updateErr.Err.Error()
+	return false, nil
+}
+
+// applyBulkAdjustBatch 在一个事务中执行一批调整，失败行剔除后重试，直到剩余行全部成功
+func (s *inventoryService) applyBulkAdjustBatch(ctx context.Context, items []domain.BulkStockAdjustmentItem, rows []*domain.BulkStockAdjustmentRowResult, batch []int) error {
+	for len(batch) > 0 {
+		updates := bulkAdjustUpdates(items, rows, batch)
 
+		err := s.retryOnConflict(ctx, "bulk_adjust", func() error {
+			return s.inventoryRepo.BatchUpdateStock(ctx, updates)
+		})
+		if err == nil {
+			productIDs := make([]int64, 0, len(batch))
+			for _, i := range batch {
+				rows[i].Success = true
+				productIDs = append(productIDs, rows[i].ProductID)
+			}
+			s.invalidateAvailability(ctx, productIDs...)
+			return nil
+		}
+
+		var updateErr *repo.StockUpdateError
+		if !errors.As(err, &updateErr) || updateErr.Index < 0 || updateErr.Index >= len(batch) {
+			return fmt.Errorf("failed to adjust stock in batch: %w", err)
+		}
+		rows[batch[updateErr.Index]].Error = updateErr.Err.Error()
+		batch = append(batch[:updateErr.Index:updateErr.Index], batch[updateErr.Index+1:]...)
+	}
 	return nil
 }
 
 // BatchReserveStock 批量预留库存
-func (s *inventoryService) BatchReserveStock(requests []*domain.ReserveStockRequest) error {
+func (s *inventoryService) BatchReserveStock(ctx context.Context, requests []*domain.ReserveStockRequest) error {
 	var updates []repo.StockUpdate
 	for _, req := range requests {
 		updates = append(updates, repo.StockUpdate{
@@ -401,11 +905,11 @@ func (s *inventoryService) BatchReserveStock(requests []*domain.ReserveStockRequ
 		})
 	}
 
-	return s.inventoryRepo.BatchUpdateStock(updates)
+	return s.batchUpdateStock(ctx, updates)
 }
 
 // BatchReleaseStock 批量释放库存
-func (s *inventoryService) BatchReleaseStock(requests []*domain.ReleaseStockRequest) error {
+func (s *inventoryService) BatchReleaseStock(ctx context.Context, requests []*domain.ReleaseStockRequest) error {
 	var updates []repo.StockUpdate
 	for _, req := range requests {
 		updates = append(updates, repo.StockUpdate{
@@ -415,11 +919,11 @@ func (s *inventoryService) BatchReleaseStock(requests []*domain.ReleaseStockRequ
 		})
 	}
 
-	return s.inventoryRepo.BatchUpdateStock(updates)
+	return s.batchUpdateStock(ctx, updates)
 }
 
 // BatchConsumeStock 批量消费库存
-func (s *inventoryService) BatchConsumeStock(requests []*domain.ConsumeStockRequest) error {
+func (s *inventoryService) BatchConsumeStock(ctx context.Context, requests []*domain.ConsumeStockRequest) error {
 	var updates []repo.StockUpdate
 	for _, req := range requests {
 		updates = append(updates, repo.StockUpdate{
@@ -429,18 +933,36 @@ func (s *inventoryService) BatchConsumeStock(requests []*domain.ConsumeStockRequ
 		})
 	}
 
-	return s.inventoryRepo.BatchUpdateStock(updates)
+	return s.batchUpdateStock(ctx, updates)
+}
+
+// batchUpdateStock 批量更新库存并失效涉及商品的可用性缓存
+func (s *inventoryService) batchUpdateStock(ctx context.Context, updates []repo.StockUpdate) error {
+	err := s.retryOnConflict(ctx, "batch", func() error {
+		return s.inventoryRepo.BatchUpdateStock(ctx, updates)
+	})
+	if err != nil {
+		return err
+	}
+
+	productIDs := make([]int64, 0, len(updates))
+	for _, update := range updates {
+		productIDs = append(productIDs, update.ProductID)
+	}
+	s.invalidateAvailability(ctx, productIDs...)
+
+	return nil
 }
 
 // GetInventoryStats 获取库存统计信息
-func (s *inventoryService) GetInventoryStats() (*InventoryStats, error) {
+func (s *inventoryService) GetInventoryStats(ctx context.Context) (*InventoryStats, error) {
 	// 获取所有库存记录
 	req := &domain.InventoryListRequest{
 		Page:     1,
 		PageSize: 1000, // 简化处理，实际应该分页处理
 	}
 
-	inventories, total, err := s.inventoryRepo.List(req)
+	inventories, total, err := s.inventoryRepo.List(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inventories: %w", err)
 	}
@@ -461,7 +983,7 @@ func (s *inventoryService) GetInventoryStats() (*InventoryStats, error) {
 	}
 
 	// 获取总库存价值
-	totalValue, err := s.inventoryRepo.GetTotalStockValue()
+	totalValue, err := s.inventoryRepo.GetTotalStockValue(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total stock value: %w", err)
 	}
@@ -477,14 +999,103 @@ func (s *inventoryService) GetInventoryStats() (*InventoryStats, error) {
 }
 
 // CheckStockAvailability 检查库存可用性
-func (s *inventoryService) CheckStockAvailability(productID int64, quantity int) (bool, error) {
-	inventory, err := s.inventoryRepo.GetByProductID(productID)
+func (s *inventoryService) CheckStockAvailability(ctx context.Context, productID int64, quantity int) (bool, error) {
+	if available, ok := s.cachedAvailability(ctx, productID); ok {
+		return available >= quantity, nil
+	}
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
 	if err != nil {
 		return false, fmt.Errorf("failed to get inventory: %w", err)
 	}
 	if inventory == nil {
-		return false, errors.New("inventory not found")
+		return false, domain.NewNotFoundError("inventory not found")
 	}
+	s.cacheAvailability(ctx, inventory)
 
 	return inventory.CanReserve(quantity), nil
 }
+
+// BatchCheckStockAvailability 批量检查库存可用性，缓存未命中的商品合并为一次查询
+func (s *inventoryService) BatchCheckStockAvailability(ctx context.Context, items []domain.StockCheckItem) (*domain.BatchStockCheckResponse, error) {
+	availability := make(map[int64]int, len(items))
+	var missing []int64
+	for _, item := range items {
+		if _, seen := availability[item.ProductID]; seen || slices.Contains(missing, item.ProductID) {
+			continue
+		}
+		if available, ok := s.cachedAvailability(ctx, item.ProductID); ok {
+			availability[item.ProductID] = available
+		} else {
+			missing = append(missing, item.ProductID)
+		}
+	}
+
+	if len(missing) > 0 {
+		inventories, err := s.inventoryRepo.GetByProductIDs(ctx, missing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get inventories: %w", err)
+		}
+		for _, inventory := range inventories {
+			availability[inventory.ProductID] = inventory.AvailableStock()
+			s.cacheAvailability(ctx, inventory)
+		}
+	}
+
+	resp := &domain.BatchStockCheckResponse{
+		Items:        make([]*domain.StockCheckResult, 0, len(items)),
+		AllAvailable: true,
+	}
+	for _, item := range items {
+		available, found := availability[item.ProductID]
+		result := &domain.StockCheckResult{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Found:     found,
+			Available: found && available >= item.Quantity,
+		}
+		if !result.Available {
+			resp.AllAvailable = false
+		}
+		resp.Items = append(resp.Items, result)
+	}
+
+	return resp, nil
+}
+
+// availabilityCacheKey 可用库存缓存键
+func availabilityCacheKey(productID int64) string {
+	return fmt.Sprintf("inventory:available:%d", productID)
+}
+
+// cachedAvailability 读取缓存的可用库存数，未启用缓存或未命中时返回 false
+func (s *inventoryService) cachedAvailability(ctx context.Context, productID int64) (int, bool) {
+	if s.availabilityCache == nil {
+		return 0, false
+	}
+	var available int
+	if err := s.availabilityCache.Get(ctx, availabilityCacheKey(productID), &available); err != nil {
+		return 0, false
+	}
+	return available, true
+}
+
+// cacheAvailability 缓存可用库存数，写入失败只影响命中率
+func (s *inventoryService) cacheAvailability(ctx context.Context, inventory *domain.Inventory) {
+	if s.availabilityCache == nil {
+		return
+	}
+	_ = s.availabilityCache.Set(ctx, availabilityCacheKey(inventory.ProductID), inventory.AvailableStock(), s.availabilityTTL)
+}
+
+// invalidateAvailability 库存变动后失效可用性缓存
+func (s *inventoryService) invalidateAvailability(ctx context.Context, productIDs ...int64) {
+	if s.availabilityCache == nil || len(productIDs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(productIDs))
+	for _, productID := range productIDs {
+		keys = append(keys, availabilityCacheKey(productID))
+	}
+	_ = s.availabilityCache.Del(ctx, keys...)
+}
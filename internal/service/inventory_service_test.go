@@ -1,8 +1,15 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/MorseWayne/spike_shop/internal/cache"
 	"github.com/MorseWayne/spike_shop/internal/domain"
 )
 
@@ -74,7 +81,7 @@ func TestInventoryService_CreateInventory(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			inventory, err := service.CreateInventory(tt.req)
+			inventory, err := service.CreateInventory(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateInventory() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -156,7 +163,7 @@ func TestInventoryService_ReserveStock(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.ReserveStock(tt.req)
+			_, err := service.ReserveStock(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ReserveStock() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -207,7 +214,7 @@ func TestInventoryService_ReleaseStock(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.ReleaseStock(tt.req)
+			err := service.ReleaseStock(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ReleaseStock() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -258,7 +265,7 @@ func TestInventoryService_ConsumeStock(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.ConsumeStock(tt.req)
+			err := service.ConsumeStock(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ConsumeStock() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -330,7 +337,7 @@ func TestInventoryService_AdjustStock(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.AdjustStock(1, tt.req)
+			err := service.AdjustStock(context.Background(), 1, tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AdjustStock() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -338,6 +345,149 @@ func TestInventoryService_AdjustStock(t *testing.T) {
 	}
 }
 
+func TestInventoryService_BulkAdjustStock(t *testing.T) {
+	productRepo := newMockProductRepository()
+	inventoryRepo := newMockInventoryRepository()
+	service := NewInventoryService(inventoryRepo, productRepo)
+
+	for _, sku := range []string{"SKU-A", "SKU-B", "SKU-NOINV"} {
+		if err := productRepo.Create(&domain.Product{Name: sku, SKU: sku, Price: 10, Status: domain.ProductStatusActive}); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	for _, productID := range []int64{1, 2} {
+		if err := inventoryRepo.Create(context.Background(), &domain.Inventory{ProductID: productID, Stock: 10, MaxStock: 1000}); err != nil {
+			t.Fatalf("create inventory: %v", err)
+		}
+	}
+
+	report, err := service.BulkAdjustStock(context.Background(), []domain.BulkStockAdjustmentItem{
+		{SKU: "SKU-A", Delta: 5, Reason: "recount"},
+		{SKU: "SKU-B", Delta: -20, Reason: "damaged"}, // 库存不足，执行时失败
+		{SKU: "SKU-B", Delta: -3, Reason: "damaged"},
+		{SKU: "SKU-MISSING", Delta: 1, Reason: "recount"},
+		{SKU: "SKU-NOINV", Delta: 1, Reason: "recount"},
+		{SKU: "SKU-A", Delta: 0, Reason: "noop"},
+		{SKU: "SKU-A", Delta: 1},
+	}, domain.BulkStockAdjustmentOptions{})
+	if err != nil {
+		t.Fatalf("BulkAdjustStock() error = %v", err)
+	}
+	if report.Total != 7 || report.Succeeded != 2 || report.Failed != 5 {
+		t.Errorf("report = %d/%d/%d, want total 7, succeeded 2, failed 5", report.Total, report.Succeeded, report.Failed)
+	}
+
+	wantSuccess := []bool{true, false, true, false, false, false, false}
+	for i, row := range report.Rows {
+		if row.Row != i+1 || row.Success != wantSuccess[i] {
+			t.Errorf("row %d = %+v, want success %v", i+1, row, wantSuccess[i])
+		}
+		if !row.Success && row.Error == "" {
+			t.Errorf("row %d failed without error message", i+1)
+		}
+	}
+
+	if got := inventoryRepo.productMap[1].Stock; got != 15 {
+		t.Errorf("SKU-A stock = %d, want 15", got)
+	}
+	if got := inventoryRepo.productMap[2].Stock; got != 7 {
+		t.Errorf("SKU-B stock = %d, want 7", got)
+	}
+}
+
+func TestInventoryService_BulkAdjustStockAtomicAndDryRun(t *testing.T) {
+	productRepo := newMockProductRepository()
+	inventoryRepo := newMockInventoryRepository()
+	service := NewInventoryService(inventoryRepo, productRepo)
+	ctx := context.Background()
+
+	for i, sku := range []string{"SKU-A", "SKU-B"} {
+		if err := productRepo.Create(&domain.Product{Name: sku, SKU: sku, Price: 10, Status: domain.ProductStatusActive}); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		if err := inventoryRepo.Create(ctx, &domain.Inventory{ProductID: int64(i + 1), Stock: 10, MaxStock: 1000}); err != nil {
+			t.Fatalf("create inventory: %v", err)
+		}
+	}
+
+	// 同一 SKU 的调整量依次累计，第二行使 SKU-B 变为负库存
+	items := []domain.BulkStockAdjustmentItem{
+		{SKU: "SKU-A", Delta: 5, Reason: "recount"},
+		{SKU: "SKU-B", Delta: -6, Reason: "damaged"},
+		{SKU: "SKU-B", Delta: -6, Reason: "damaged"},
+	}
+	for _, opts := range []domain.BulkStockAdjustmentOptions{
+		{Mode: domain.BulkStockAdjustmentPartial, DryRun: true},
+		{Mode: domain.BulkStockAdjustmentAtomic},
+	} {
+		report, err := service.BulkAdjustStock(ctx, items, opts)
+		if err != nil {
+			t.Fatalf("BulkAdjustStock(%+v) error = %v", opts, err)
+		}
+		if report.Rows[2].Success || report.Rows[2].Error == "" {
+			t.Errorf("BulkAdjustStock(%+v) row 3 = %+v, want negative stock error", opts, report.Rows[2])
+		}
+		if opts.DryRun && (report.Succeeded != 2 || report.RolledBack) {
+			t.Errorf("dry run report = %+v, want 2 rows passing validation", report)
+		}
+		if opts.Mode == domain.BulkStockAdjustmentAtomic && (report.Succeeded != 0 || !report.RolledBack || report.Rows[0].Error == "") {
+			t.Errorf("atomic report = %+v, want all rows rolled back", report)
+		}
+		if inventoryRepo.productMap[1].Stock != 10 || inventoryRepo.productMap[2].Stock != 10 {
+			t.Fatalf("BulkAdjustStock(%+v) changed stock to %d/%d", opts,
+				inventoryRepo.productMap[1].Stock, inventoryRepo.productMap[2].Stock)
+		}
+	}
+
+	report, err := service.BulkAdjustStock(ctx, items[:2], domain.BulkStockAdjustmentOptions{Mode: domain.BulkStockAdjustmentAtomic})
+	if err != nil {
+		t.Fatalf("BulkAdjustStock() error = %v", err)
+	}
+	if report.Succeeded != 2 || report.RolledBack {
+		t.Errorf("atomic report = %+v, want all rows applied", report)
+	}
+	if inventoryRepo.productMap[1].Stock != 15 || inventoryRepo.productMap[2].Stock != 4 {
+		t.Errorf("stock = %d/%d, want 15/4", inventoryRepo.productMap[1].Stock, inventoryRepo.productMap[2].Stock)
+	}
+
+	if _, err := service.BulkAdjustStock(ctx, items, domain.BulkStockAdjustmentOptions{Mode: "all"}); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("BulkAdjustStock() with unknown mode error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestInventoryService_ExportInventory(t *testing.T) {
+	productRepo := newMockProductRepository()
+	inventoryRepo := newMockInventoryRepository()
+	service := NewInventoryService(inventoryRepo, productRepo)
+	ctx := context.Background()
+
+	if err := productRepo.Create(&domain.Product{Name: "耳机", SKU: "SKU-A", Price: 10, Status: domain.ProductStatusActive}); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if err := inventoryRepo.Create(ctx, &domain.Inventory{ProductID: 1, Stock: 10, ReservedStock: 3, MaxStock: 100}); err != nil {
+		t.Fatalf("create inventory: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := service.ExportInventory(ctx, domain.InventoryExportFormatCSV, &buf)
+	if err != nil || n != 1 {
+		t.Fatalf("ExportInventory() = %d, %v, want 1 row", n, err)
+	}
+	lines := strings.Split(strings.TrimPrefix(buf.String(), "\uFEFF"), "\n")
+	if lines[0] != strings.Join(domain.InventorySnapshotColumns, ",") || !strings.HasPrefix(lines[1], "SKU-A,1,耳机,10,3,7,") {
+		t.Errorf("ExportInventory() csv = %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := service.ExportInventory(ctx, domain.InventoryExportFormatJSON, &buf); err != nil {
+		t.Fatalf("ExportInventory() error = %v", err)
+	}
+	var rows []domain.InventorySnapshotRow
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil || len(rows) != 1 || rows[0].AvailableStock != 7 {
+		t.Errorf("ExportInventory() json = %s, err = %v", buf.String(), err)
+	}
+}
+
 func TestInventoryService_GetLowStockAlerts(t *testing.T) {
 	productRepo := newMockProductRepository()
 	inventoryRepo := newMockInventoryRepository()
@@ -378,7 +528,7 @@ func TestInventoryService_GetLowStockAlerts(t *testing.T) {
 	inventoryRepo.productMap[1] = lowStockInventory
 	inventoryRepo.productMap[2] = normalStockInventory
 
-	alerts, err := service.GetLowStockAlerts()
+	alerts, err := service.GetLowStockAlerts(context.Background())
 	if err != nil {
 		t.Errorf("GetLowStockAlerts() error = %v", err)
 		return
@@ -451,7 +601,7 @@ func TestInventoryService_CheckStockAvailability(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			available, err := service.CheckStockAvailability(tt.productID, tt.quantity)
+			available, err := service.CheckStockAvailability(context.Background(), tt.productID, tt.quantity)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CheckStockAvailability() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -462,3 +612,70 @@ func TestInventoryService_CheckStockAvailability(t *testing.T) {
 		})
 	}
 }
+
+func TestInventoryService_BatchCheckStockAvailability(t *testing.T) {
+	productRepo := newMockProductRepository()
+	inventoryRepo := newMockInventoryRepository()
+	service := NewInventoryService(inventoryRepo, productRepo)
+
+	for _, inv := range []*domain.Inventory{
+		{ID: 1, ProductID: 1, Stock: 100, ReservedStock: 20, MaxStock: 1000},
+		{ID: 2, ProductID: 2, Stock: 5, ReservedStock: 5, MaxStock: 1000},
+	} {
+		inventoryRepo.inventories[inv.ID] = inv
+		inventoryRepo.productMap[inv.ProductID] = inv
+	}
+
+	result, err := service.BatchCheckStockAvailability(context.Background(), []domain.StockCheckItem{
+		{ProductID: 1, Quantity: 80},
+		{ProductID: 2, Quantity: 1},
+		{ProductID: 999, Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("BatchCheckStockAvailability() error = %v", err)
+	}
+
+	if result.AllAvailable {
+		t.Errorf("BatchCheckStockAvailability() AllAvailable = true, want false")
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("BatchCheckStockAvailability() returned %d items, want 3", len(result.Items))
+	}
+	if !result.Items[0].Available || !result.Items[0].Found {
+		t.Errorf("product 1 = %+v, want available", result.Items[0])
+	}
+	if result.Items[1].Available || !result.Items[1].Found {
+		t.Errorf("product 2 = %+v, want found but unavailable", result.Items[1])
+	}
+	if result.Items[2].Available || result.Items[2].Found {
+		t.Errorf("product 999 = %+v, want not found", result.Items[2])
+	}
+}
+
+func TestInventoryService_AvailabilityCacheInvalidation(t *testing.T) {
+	productRepo := newMockProductRepository()
+	inventoryRepo := newMockInventoryRepository()
+	service := NewInventoryService(inventoryRepo, productRepo, WithAvailabilityCache(cache.NewMemoryCache(), time.Minute))
+
+	inventory := &domain.Inventory{ID: 1, ProductID: 1, Stock: 10, ReservedStock: 10, MaxStock: 100}
+	inventoryRepo.inventories[1] = inventory
+	inventoryRepo.productMap[1] = inventory
+
+	if available, _ := service.CheckStockAvailability(context.Background(), 1, 1); available {
+		t.Fatalf("CheckStockAvailability() = true before release, want false")
+	}
+
+	// 绕过服务修改库存：缓存未失效，仍返回缓存结果
+	inventory.ReservedStock = 5
+	if available, _ := service.CheckStockAvailability(context.Background(), 1, 1); available {
+		t.Fatalf("CheckStockAvailability() = true, want cached false")
+	}
+
+	// 经服务释放库存后缓存立即失效
+	if err := service.ReleaseStock(context.Background(), &domain.ReleaseStockRequest{ProductID: 1, Quantity: 5}); err != nil {
+		t.Fatalf("ReleaseStock() error = %v", err)
+	}
+	if available, _ := service.CheckStockAvailability(context.Background(), 1, 5); !available {
+		t.Errorf("CheckStockAvailability() = false after release, want true")
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/MorseWayne/spike_shop/internal/config"
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/repo"
 )
 
 // JWT相关错误定义
@@ -27,10 +29,22 @@ type Claims struct {
 	UserID   int64           `json:"user_id"`
 	Username string          `json:"username"`
 	Role     domain.UserRole `json:"role"`
-	Type     string          `json:"type"` // "access" 或 "refresh"
+	Tier     domain.UserTier `json:"tier,omitempty"` // 用户等级，旧令牌可能为空
+	Type     string          `json:"type"`           // "access" 或 "refresh"
+	// 代操作（客服模拟用户）相关声明，仅代操作令牌携带
+	ImpersonatorID int64  `json:"impersonator_id,omitempty"` // 发起代操作的管理员ID
+	Scope          string `json:"scope,omitempty"`           // 代操作权限范围
 	jwt.RegisteredClaims
 }
 
+// ScopeSupport 客服代操作范围：只读访问用户订单，并允许代为取消订单
+const ScopeSupport = "support"
+
+// IsImpersonation 判断令牌是否为代操作令牌
+func (c *Claims) IsImpersonation() bool {
+	return c.ImpersonatorID > 0
+}
+
 // TokenPair 表示访问令牌和刷新令牌对
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
@@ -43,20 +57,38 @@ type JWTService interface {
 	ValidateAccessToken(tokenString string) (*Claims, error)
 	ValidateRefreshToken(tokenString string) (*Claims, error)
 	RefreshTokenPair(refreshToken string) (*TokenPair, error)
+	GenerateImpersonationToken(admin, target *domain.User) (string, time.Time, error)
 }
 
 // jwtService 是JWTService接口的实现
 type jwtService struct {
 	config *config.Config
 	logger *zap.Logger
+
+	// 刷新令牌时重新加载用户，可为空，为空时沿用刷新令牌中的用户信息
+	userRepo repo.UserRepository
+}
+
+// JWTServiceOption JWT服务可选配置
+type JWTServiceOption func(*jwtService)
+
+// WithTokenUserRepository 刷新令牌时从数据库重新加载用户，使角色、等级与禁用状态的变更在刷新后生效
+func WithTokenUserRepository(userRepo repo.UserRepository) JWTServiceOption {
+	return func(s *jwtService) {
+		s.userRepo = userRepo
+	}
 }
 
 // NewJWTService 创建JWT服务实例
-func NewJWTService(cfg *config.Config, logger *zap.Logger) JWTService {
-	return &jwtService{
+func NewJWTService(cfg *config.Config, logger *zap.Logger, opts ...JWTServiceOption) JWTService {
+	s := &jwtService{
 		config: cfg,
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GenerateTokenPair 为用户生成访问令牌和刷新令牌对
@@ -70,6 +102,7 @@ func (s *jwtService) GenerateTokenPair(user *domain.User) (*TokenPair, error) {
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Tier:     user.Tier,
 		Type:     "access",
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   fmt.Sprintf("%d", user.ID),
@@ -92,6 +125,7 @@ func (s *jwtService) GenerateTokenPair(user *domain.User) (*TokenPair, error) {
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Tier:     user.Tier,
 		Type:     "refresh",
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   fmt.Sprintf("%d", user.ID),
@@ -110,7 +144,7 @@ func (s *jwtService) GenerateTokenPair(user *domain.User) (*TokenPair, error) {
 	}
 
 	s.logger.Info("token pair generated",
-		zap.Int64("user_id", user.ID),
+		logger.UserID(user.ID),
 		zap.String("username", user.Username),
 		zap.Duration("access_ttl", s.config.JWT.AccessTokenTTL),
 		zap.Duration("refresh_ttl", s.config.JWT.RefreshTokenTTL),
@@ -191,14 +225,9 @@ func (s *jwtService) RefreshTokenPair(refreshTokenString string) (*TokenPair, er
 		return nil, fmt.Errorf("validate refresh token: %w", err)
 	}
 
-	// 构建用户对象用于生成新令牌
-	// 注意：这里只使用令牌中的基本信息，实际生产环境可能需要从数据库重新获取用户信息
-	// 以确保用户状态（如是否被禁用）是最新的
-	user := &domain.User{
-		ID:       claims.UserID,
-		Username: claims.Username,
-		Role:     claims.Role,
-		IsActive: true, // 这里假设从令牌中的用户是活跃的
+	user, err := s.refreshUser(claims)
+	if err != nil {
+		return nil, err
 	}
 
 	// 生成新的令牌对
@@ -208,9 +237,66 @@ func (s *jwtService) RefreshTokenPair(refreshTokenString string) (*TokenPair, er
 	}
 
 	s.logger.Info("token pair refreshed",
-		zap.Int64("user_id", claims.UserID),
+		logger.UserID(claims.UserID),
 		zap.String("username", claims.Username),
 	)
 
 	return tokenPair, nil
 }
+
+// refreshUser 获取用于签发新令牌的用户：刷新令牌有效期较长，其中的角色与等级可能已过时，
+// 配置了用户仓储时从数据库重新加载，用户已删除或被禁用时拒绝刷新
+func (s *jwtService) refreshUser(claims *Claims) (*domain.User, error) {
+	if s.userRepo == nil {
+		return &domain.User{
+			ID:       claims.UserID,
+			Username: claims.Username,
+			Role:     claims.Role,
+			Tier:     claims.Tier,
+			IsActive: true,
+		}, nil
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if user == nil || !user.IsActive {
+		s.logger.Warn("refresh token rejected for missing or inactive user", logger.UserID(claims.UserID))
+		return nil, ErrInvalidToken
+	}
+	return user, nil
+}
+
+// GenerateImpersonationToken 为管理员生成代操作指定用户的访问令牌
+// 代操作令牌以目标用户身份访问，但携带发起者ID和权限范围，且不签发刷新令牌，到期后需重新申请
+func (s *jwtService) GenerateImpersonationToken(admin, target *domain.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.config.JWT.ImpersonationTokenTTL)
+
+	claims := &Claims{
+		UserID:         target.ID,
+		Username:       target.Username,
+		Role:           target.Role,
+		Tier:           target.Tier,
+		Type:           "access",
+		ImpersonatorID: admin.ID,
+		Scope:          ScopeSupport,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", target.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    s.config.App.Name,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(s.config.JWT.Secret))
+	if err != nil {
+		s.logger.Error("failed to sign impersonation token", zap.Error(err))
+		return "", time.Time{}, fmt.Errorf("sign impersonation token: %w", err)
+	}
+
+	return tokenString, expiresAt, nil
+}
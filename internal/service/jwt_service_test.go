@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -10,15 +11,16 @@ import (
 	"github.com/MorseWayne/spike_shop/internal/domain"
 )
 
-func createTestJWTService() JWTService {
+func createTestJWTService(opts ...JWTServiceOption) JWTService {
 	cfg := &config.Config{}
 	cfg.JWT.Secret = "test-secret-key"
 	cfg.JWT.AccessTokenTTL = 15 * time.Minute
 	cfg.JWT.RefreshTokenTTL = 24 * time.Hour
+	cfg.JWT.ImpersonationTokenTTL = 10 * time.Minute
 	cfg.App.Name = "test-service"
 
 	logger := zap.NewNop() // 无操作的logger，用于测试
-	return NewJWTService(cfg, logger)
+	return NewJWTService(cfg, logger, opts...)
 }
 
 func createTestUser() *domain.User {
@@ -167,6 +169,38 @@ func TestJWTService_RefreshTokenPair(t *testing.T) {
 	}
 }
 
+func TestJWTService_RefreshTokenPair_ReloadsUser(t *testing.T) {
+	userRepo := newFakeUserRepo()
+	user := &domain.User{Username: "testuser", Email: "test@example.com", Role: domain.UserRoleUser, Tier: domain.UserTierRegular, IsActive: true}
+	_ = userRepo.Create(user)
+	jwtService := createTestJWTService(WithTokenUserRepository(userRepo))
+
+	tokenPair, err := jwtService.GenerateTokenPair(user)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	// 签发刷新令牌后管理员调整了等级，刷新得到的令牌应携带新等级
+	_ = userRepo.UpdateUserTier(user.ID, domain.UserTierGold)
+	refreshed, err := jwtService.RefreshTokenPair(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokenPair failed: %v", err)
+	}
+	claims, err := jwtService.ValidateAccessToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if claims.Tier != domain.UserTierGold {
+		t.Errorf("Expected tier %s, got %s", domain.UserTierGold, claims.Tier)
+	}
+
+	// 被禁用的用户不能再刷新令牌
+	_ = userRepo.UpdateUserStatus(user.ID, false)
+	if _, err := jwtService.RefreshTokenPair(refreshed.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for inactive user, got %v", err)
+	}
+}
+
 func TestJWTService_RefreshTokenPair_InvalidRefreshToken(t *testing.T) {
 	jwtService := createTestJWTService()
 
@@ -225,3 +259,40 @@ func TestJWTService_TokenExpiration(t *testing.T) {
 		t.Errorf("Expected ErrTokenExpired, got %v", err)
 	}
 }
+
+func TestJWTService_GenerateImpersonationToken(t *testing.T) {
+	jwtService := createTestJWTService()
+	admin := &domain.User{ID: 1, Username: "admin", Role: domain.UserRoleAdmin, IsActive: true}
+	target := createTestUser()
+
+	token, expiresAt, err := jwtService.GenerateImpersonationToken(admin, target)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken failed: %v", err)
+	}
+
+	if !expiresAt.After(time.Now()) {
+		t.Errorf("Expected expiresAt in the future, got %v", expiresAt)
+	}
+
+	// 代操作令牌以目标用户身份通过访问令牌校验
+	claims, err := jwtService.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+
+	if claims.UserID != target.ID {
+		t.Errorf("Expected UserID %d, got %d", target.ID, claims.UserID)
+	}
+
+	if claims.Role != target.Role {
+		t.Errorf("Expected Role %s, got %s", target.Role, claims.Role)
+	}
+
+	if !claims.IsImpersonation() || claims.ImpersonatorID != admin.ID {
+		t.Errorf("Expected ImpersonatorID %d, got %d", admin.ID, claims.ImpersonatorID)
+	}
+
+	if claims.Scope != ScopeSupport {
+		t.Errorf("Expected Scope %s, got %s", ScopeSupport, claims.Scope)
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// 登录防护的 Redis 键命名空间
+const (
+	LoginFailuresKeyNamespace  = "login:failures"   // 窗口内失败次数: login:failures:{kind}:{subject}
+	LoginLockKeyNamespace      = "login:lock"       // 锁定标记，TTL 即剩余锁定时长: login:lock:{kind}:{subject}
+	LoginLockCountKeyNamespace = "login:lock_count" // 累计锁定次数，决定下一次锁定时长: login:lock_count:{kind}:{subject}
+	LoginKnownIPsKeyNamespace  = "login:known_ips"  // 用户最近成功登录的 IP: login:known_ips:{user_id}
+)
+
+// 登录防护按账号与来源 IP 分别计数与锁定
+const (
+	loginSubjectAccount = "account"
+	loginSubjectIP      = "ip"
+)
+
+// 可疑登录审计事件，记录在 audit=true 的 suspicious login 日志的 event 字段
+const (
+	loginAuditBlocked              = "login_blocked"          // 锁定期间仍在尝试登录
+	loginAuditAccountLocked        = "account_locked"         // 账号失败次数达到阈值被锁定
+	loginAuditIPLocked             = "ip_locked"              // 来源 IP 失败次数达到阈值被锁定
+	loginAuditInvalidOTP           = "invalid_otp"            // 已启用两步验证的账号验证码错误，登录时说明密码可能已泄露
+	loginAuditSuccessAfterFailures = "success_after_failures" // 连续失败后登录成功
+	loginAuditNewIP                = "new_ip"                 // 从最近未使用过的 IP 登录成功
+)
+
+// loginKnownIPsLimit 每个用户保留的最近登录 IP 数
+const loginKnownIPsLimit = 5
+
+// ErrLoginLocked 登录失败次数过多，账号或来源 IP 被临时锁定
+var ErrLoginLocked = errors.New("login temporarily locked")
+
+// LoginLockedError 携带剩余锁定时长的锁定错误，errors.Is(err, ErrLoginLocked) 为真
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginLocked, e.RetryAfter)
+}
+
+// Unwrap 使 errors.Is 能匹配 ErrLoginLocked
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+//go:generate moq -rm -out login_guard_moq_test.go . LoginAttemptStore
+
+// LoginAttemptStore 登录防护所需的计数与过期操作（由 cache.RedisCache 实现）
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// LoginGuardConfig 登录失败锁定参数
+type LoginGuardConfig struct {
+	MaxFailures   int           // 窗口内同一账号失败达到该次数即锁定，0 表示不按账号锁定
+	IPMaxFailures int           // 窗口内同一 IP 失败达到该次数即锁定，0 表示不按 IP 锁定
+	FailureWindow time.Duration // 失败次数的统计窗口，自首次失败起计
+	LockoutBase   time.Duration // 首次锁定时长，之后每次锁定翻倍
+	LockoutMax    time.Duration // 锁定时长上限
+	LockoutReset  time.Duration // 累计锁定次数的保留时长，期间无新锁定则锁定时长回到 LockoutBase
+	KnownIPsTTL   time.Duration // 最近登录 IP 的保留时长，用于识别陌生 IP 登录
+}
+
+// DefaultLoginGuardConfig 返回默认的登录锁定参数
+func DefaultLoginGuardConfig() LoginGuardConfig {
+	return LoginGuardConfig{
+		MaxFailures:   5,
+		IPMaxFailures: 50,
+		FailureWindow: 15 * time.Minute,
+		LockoutBase:   time.Minute,
+		LockoutMax:    24 * time.Hour,
+		LockoutReset:  24 * time.Hour,
+		KnownIPsTTL:   90 * 24 * time.Hour,
+	}
+}
+
+// LoginFailure 一次失败登录计数后的结果
+type LoginFailure struct {
+	Failures    int64         // 账号在窗口内的失败次数（含本次）
+	IPFailures  int64         // 来源 IP 在窗口内的失败次数（含本次）
+	AccountLock time.Duration // 本次失败触发的账号锁定时长，0 表示未锁定
+	IPLock      time.Duration // 本次失败触发的 IP 锁定时长，0 表示未锁定
+}
+
+// LoginSuccess 一次成功登录的记录结果
+type LoginSuccess struct {
+	PriorFailures int64 // 登录成功前账号在窗口内的失败次数
+	NewIP         bool  // 来源 IP 不在用户最近登录过的 IP 中（首次登录不算）
+}
+
+// LoginGuard 基于 Redis 的登录失败计数与指数退避锁定，多实例共享。
+// 账号与来源 IP 分别计数：撞库攻击通常用同一来源尝试大量账号，按 IP 锁定可以在单个账号达到阈值前拦截。
+// 第 n 次锁定时长为 LockoutBase * 2^(n-1)，不超过 LockoutMax。
+type LoginGuard struct {
+	store LoginAttemptStore // 键的环境前缀由存储（cache.RedisCache）统一添加
+	cfg   LoginGuardConfig
+}
+
+// NewLoginGuard 创建登录防护
+func NewLoginGuard(store LoginAttemptStore, cfg LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{store: store, cfg: cfg}
+}
+
+// Check 返回账号或来源 IP 中较长的剩余锁定时长，未锁定时返回 0
+func (g *LoginGuard) Check(ctx context.Context, account, ip string) (time.Duration, error) {
+	var remaining time.Duration
+	for _, subject := range g.subjects(account, ip) {
+		ttl, err := g.store.TTL(ctx, g.key(LoginLockKeyNamespace, subject[0], subject[1]))
+		if err != nil {
+			return 0, fmt.Errorf("failed to get login lock: %w", err)
+		}
+		remaining = max(remaining, ttl)
+	}
+	return remaining, nil
+}
+
+// RecordFailure 记录一次失败登录，达到阈值时锁定并清零失败计数
+func (g *LoginGuard) RecordFailure(ctx context.Context, account, ip string) (*LoginFailure, error) {
+	result := &LoginFailure{}
+	if account != "" && g.cfg.MaxFailures > 0 {
+		failures, lock, err := g.fail(ctx, loginSubjectAccount, account, g.cfg.MaxFailures)
+		if err != nil {
+			return nil, err
+		}
+		result.Failures, result.AccountLock = failures, lock
+	}
+	if ip != "" && g.cfg.IPMaxFailures > 0 {
+		failures, lock, err := g.fail(ctx, loginSubjectIP, ip, g.cfg.IPMaxFailures)
+		if err != nil {
+			return nil, err
+		}
+		result.IPFailures, result.IPLock = failures, lock
+	}
+	return result, nil
+}
+
+// RecordSuccess 清零账号的失败计数并记录来源 IP；IP 的失败计数保留，避免攻击者用自己的账号登录来重置
+func (g *LoginGuard) RecordSuccess(ctx context.Context, account string, userID int64, ip string) (*LoginSuccess, error) {
+	result := &LoginSuccess{}
+	failuresKey := g.key(LoginFailuresKeyNamespace, loginSubjectAccount, account)
+	var failures int64
+	if err := g.store.Get(ctx, failuresKey, &failures); err == nil {
+		result.PriorFailures = failures
+	}
+	if err := g.store.Del(ctx, failuresKey); err != nil {
+		return nil, fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	if ip == "" || g.cfg.KnownIPsTTL <= 0 {
+		return result, nil
+	}
+	knownKey := g.key(LoginKnownIPsKeyNamespace, userID)
+	var known []string
+	if err := g.store.Get(ctx, knownKey, &known); err != nil {
+		// 键不存在与读取失败都按无记录处理，不据此判定为陌生 IP
+		known = nil
+	}
+	result.NewIP = len(known) > 0 && !slices.Contains(known, ip)
+
+	known = slices.DeleteFunc(known, func(v string) bool { return v == ip })
+	known = append([]string{ip}, known...)
+	if len(known) > loginKnownIPsLimit {
+		known = known[:loginKnownIPsLimit]
+	}
+	if err := g.store.Set(ctx, knownKey, known, g.cfg.KnownIPsTTL); err != nil {
+		return nil, fmt.Errorf("failed to save known login ips: %w", err)
+	}
+	return result, nil
+}
+
+// fail 累加失败次数，达到阈值时按累计锁定次数计算锁定时长
+func (g *LoginGuard) fail(ctx context.Context, kind, subject string, threshold int) (int64, time.Duration, error) {
+	failuresKey := g.key(LoginFailuresKeyNamespace, kind, subject)
+	failures, err := g.store.Incr(ctx, failuresKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count login failure: %w", err)
+	}
+	if failures == 1 {
+		if err := g.store.Expire(ctx, failuresKey, g.cfg.FailureWindow); err != nil {
+			return 0, 0, fmt.Errorf("failed to set login failure window: %w", err)
+		}
+	}
+	if failures < int64(threshold) {
+		return failures, 0, nil
+	}
+
+	countKey := g.key(LoginLockCountKeyNamespace, kind, subject)
+	locks, err := g.store.Incr(ctx, countKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count login lock: %w", err)
+	}
+	if err := g.store.Expire(ctx, countKey, g.cfg.LockoutReset); err != nil {
+		return 0, 0, fmt.Errorf("failed to set login lock count ttl: %w", err)
+	}
+	lock := g.lockDuration(locks)
+	if err := g.store.Set(ctx, g.key(LoginLockKeyNamespace, kind, subject), locks, lock); err != nil {
+		return 0, 0, fmt.Errorf("failed to lock login: %w", err)
+	}
+	if err := g.store.Del(ctx, failuresKey); err != nil {
+		return 0, 0, fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return failures, lock, nil
+}
+
+// lockDuration 第 n 次锁定的时长
+func (g *LoginGuard) lockDuration(n int64) time.Duration {
+	lock := g.cfg.LockoutBase
+	for i := int64(1); i < n && lock < g.cfg.LockoutMax; i++ {
+		lock *= 2
+	}
+	if g.cfg.LockoutMax > 0 && lock > g.cfg.LockoutMax {
+		lock = g.cfg.LockoutMax
+	}
+	return lock
+}
+
+// subjects 返回需要检查锁定的 (类型, 标识) 对
+func (g *LoginGuard) subjects(account, ip string) [][2]string {
+	var subjects [][2]string
+	if account != "" {
+		subjects = append(subjects, [2]string{loginSubjectAccount, account})
+	}
+	if ip != "" {
+		subjects = append(subjects, [2]string{loginSubjectIP, ip})
+	}
+	return subjects
+}
+
+// key 构造登录防护键
+func (g *LoginGuard) key(namespace string, parts ...any) string {
+	return keys.Redis(namespace, parts...)
+}
+
+// loginAccount 登录防护的账号标识：已注册用户按用户ID计数，用户名与邮箱登录共用同一计数；
+// 不存在的账号按规范化后的登录名计数，使其与已注册账号的锁定表现一致，不泄露账号是否存在
+func loginAccount(identifier string, userID int64) string {
+	if userID > 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(identifier))
+}
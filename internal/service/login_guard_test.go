@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+)
+
+// fakeLoginAttemptStore 内存实现的 LoginAttemptStore，记录每个键的 TTL 而不真正过期
+type fakeLoginAttemptStore struct {
+	*LoginAttemptStoreMock
+
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newFakeLoginAttemptStore() *fakeLoginAttemptStore {
+	f := &fakeLoginAttemptStore{
+		LoginAttemptStoreMock: &LoginAttemptStoreMock{},
+		values:                map[string][]byte{},
+		ttls:                  map[string]time.Duration{},
+	}
+	f.GetFunc = f.get
+	f.SetFunc = f.set
+	f.DelFunc = f.del
+	f.IncrFunc = f.incr
+	f.ExpireFunc = f.setTTL
+	f.TTLFunc = f.ttl
+	return f
+}
+
+func (f *fakeLoginAttemptStore) get(_ context.Context, key string, dest interface{}) error {
+	data, ok := f.values[key]
+	if !ok {
+		return errors.New("key not found")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (f *fakeLoginAttemptStore) set(_ context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	f.values[key] = data
+	f.ttls[key] = expiration
+	return nil
+}
+
+func (f *fakeLoginAttemptStore) del(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.values, key)
+		delete(f.ttls, key)
+	}
+	return nil
+}
+
+func (f *fakeLoginAttemptStore) incr(_ context.Context, key string) (int64, error) {
+	var n int64
+	if data, ok := f.values[key]; ok {
+		if err := json.Unmarshal(data, &n); err != nil {
+			return 0, err
+		}
+	}
+	n++
+	f.values[key], _ = json.Marshal(n)
+	return n, nil
+}
+
+func (f *fakeLoginAttemptStore) setTTL(_ context.Context, key string, expiration time.Duration) error {
+	f.ttls[key] = expiration
+	return nil
+}
+
+func (f *fakeLoginAttemptStore) ttl(_ context.Context, key string) (time.Duration, error) {
+	if _, ok := f.values[key]; !ok {
+		return -2, nil
+	}
+	return f.ttls[key], nil
+}
+
+// expire 模拟键到期
+func (f *fakeLoginAttemptStore) expire(key string) {
+	delete(f.values, key)
+	delete(f.ttls, key)
+}
+
+func TestLoginGuard_ExponentialLockout(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeLoginAttemptStore()
+	cfg := DefaultLoginGuardConfig()
+	cfg.MaxFailures = 3
+	cfg.IPMaxFailures = 0
+	cfg.LockoutBase = time.Minute
+	cfg.LockoutMax = 3 * time.Minute
+	guard := NewLoginGuard(store, cfg)
+	lockKey := guard.key(LoginLockKeyNamespace, loginSubjectAccount, "user:1")
+
+	wantLocks := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	for round, want := range wantLocks {
+		for i := 1; i <= cfg.MaxFailures; i++ {
+			failure, err := guard.RecordFailure(ctx, "user:1", "10.0.0.1")
+			if err != nil {
+				t.Fatalf("RecordFailure() error = %v", err)
+			}
+			if i < cfg.MaxFailures && failure.AccountLock != 0 {
+				t.Fatalf("round %d: locked after %d failures", round, i)
+			}
+			if i == cfg.MaxFailures && failure.AccountLock != want {
+				t.Fatalf("round %d: lock = %s, want %s", round, failure.AccountLock, want)
+			}
+		}
+		remaining, err := guard.Check(ctx, "user:1", "10.0.0.1")
+		if err != nil || remaining != want {
+			t.Fatalf("round %d: Check() = %s, %v, want %s", round, remaining, err, want)
+		}
+		store.expire(lockKey)
+	}
+
+	if remaining, _ := guard.Check(ctx, "user:2", "10.0.0.1"); remaining != 0 {
+		t.Errorf("other account locked for %s", remaining)
+	}
+}
+
+func TestLoginGuard_RecordSuccess(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeLoginAttemptStore()
+	guard := NewLoginGuard(store, DefaultLoginGuardConfig())
+
+	if _, err := guard.RecordFailure(ctx, "user:1", "10.0.0.1"); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+	success, err := guard.RecordSuccess(ctx, "user:1", 1, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("RecordSuccess() error = %v", err)
+	}
+	if success.PriorFailures != 1 || success.NewIP {
+		t.Errorf("first success = %+v, want 1 prior failure and no new ip", success)
+	}
+
+	success, _ = guard.RecordSuccess(ctx, "user:1", 1, "10.0.0.2")
+	if success.PriorFailures != 0 || !success.NewIP {
+		t.Errorf("second success = %+v, want new ip", success)
+	}
+	success, _ = guard.RecordSuccess(ctx, "user:1", 1, "10.0.0.1")
+	if success.NewIP {
+		t.Error("known ip reported as new")
+	}
+
+	// IP 的失败计数不因账号登录成功而清零
+	ipFailures := guard.key(LoginFailuresKeyNamespace, loginSubjectIP, "10.0.0.1")
+	if _, ok := store.values[ipFailures]; !ok {
+		t.Error("ip failures reset by successful login")
+	}
+}
+
+// fakeUserTOTPRepository 内存实现的 repo.UserTOTPRepository
+type fakeUserTOTPRepository struct {
+	*mocks.UserTOTPRepositoryMock
+
+	totps map[int64]*domain.UserTOTP
+}
+
+func newFakeUserTOTPRepository() *fakeUserTOTPRepository {
+	f := &fakeUserTOTPRepository{UserTOTPRepositoryMock: &mocks.UserTOTPRepositoryMock{}, totps: map[int64]*domain.UserTOTP{}}
+	f.GetFunc = f.get
+	f.SavePendingFunc = f.savePending
+	f.EnableFunc = f.enable
+	f.MarkUsedFunc = f.markUsed
+	f.DeleteFunc = f.delete
+	return f
+}
+
+func (f *fakeUserTOTPRepository) get(_ context.Context, userID int64) (*domain.UserTOTP, error) {
+	if t, ok := f.totps[userID]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeUserTOTPRepository) savePending(_ context.Context, userID int64, secret string) (bool, error) {
+	if t, ok := f.totps[userID]; ok && t.IsEnabled() {
+		return false, nil
+	}
+	f.totps[userID] = &domain.UserTOTP{UserID: userID, Secret: secret}
+	return true, nil
+}
+
+func (f *fakeUserTOTPRepository) enable(_ context.Context, userID, step int64, at time.Time) (bool, error) {
+	t, ok := f.totps[userID]
+	if !ok || t.IsEnabled() {
+		return false, nil
+	}
+	t.EnabledAt, t.LastUsedStep = &at, step
+	return true, nil
+}
+
+func (f *fakeUserTOTPRepository) markUsed(_ context.Context, userID, step int64) (bool, error) {
+	t, ok := f.totps[userID]
+	if !ok || t.LastUsedStep >= step {
+		return false, nil
+	}
+	t.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeUserTOTPRepository) delete(_ context.Context, userID int64) error {
+	delete(f.totps, userID)
+	return nil
+}
+
+func TestUserService_LoginLockoutAndTOTP(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newFakeUserRepo()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	_ = userRepo.Create(&domain.User{Username: "alice", Email: "alice@example.com", PasswordHash: string(hash), IsActive: true})
+
+	core, logs := observer.New(zap.WarnLevel)
+	cfg := DefaultLoginGuardConfig()
+	cfg.MaxFailures = 4
+	totpRepo := newFakeUserTOTPRepository()
+	totpCipher, _ := NewTOTPSecretCipher([]byte("0123456789abcdef0123456789abcdef"))
+	svc := NewUserService(userRepo, zap.New(core),
+		WithLoginGuard(NewLoginGuard(newFakeLoginAttemptStore(), cfg)),
+		WithTOTP(totpRepo, "spike-server"),
+		WithTOTPSecretCipher(totpCipher))
+
+	// 启用两步验证
+	enrollment, err := svc.EnrollTOTP(ctx, 1)
+	if err != nil {
+		t.Fatalf("EnrollTOTP() error = %v", err)
+	}
+	// 取当前验证码之前很久的时间步生成错误验证码，避免随机值恰好有效
+	code, _ := totpCode(enrollment.Secret, totpStep(time.Now()))
+	wrongCode, _ := totpCode(enrollment.Secret, totpStep(time.Now())-100)
+	if wrongCode == code {
+		t.Skip("wrong code collides with current code")
+	}
+	if stored := totpRepo.totps[1].Secret; stored == enrollment.Secret {
+		t.Fatal("totp secret stored unencrypted")
+	}
+	if err := svc.EnableTOTP(ctx, 1, wrongCode); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("EnableTOTP(wrong code) error = %v, want ErrInvalidOTP", err)
+	}
+	if err := svc.EnableTOTP(ctx, 1, code); err != nil {
+		t.Fatalf("EnableTOTP() error = %v", err)
+	}
+	if _, err := svc.EnrollTOTP(ctx, 1); !errors.Is(err, ErrTOTPAlreadyEnabled) {
+		t.Fatalf("EnrollTOTP(enabled) error = %v, want ErrTOTPAlreadyEnabled", err)
+	}
+
+	req := &domain.LoginRequest{Username: "alice", Password: "password123"}
+	if _, err := svc.Login(ctx, req, "10.0.0.1"); !errors.Is(err, ErrOTPRequired) {
+		t.Fatalf("Login(no otp) error = %v, want ErrOTPRequired", err)
+	}
+	// 确认绑定时使用过的验证码不能再用于登录
+	req.OTPCode = code
+	if _, err := svc.Login(ctx, req, "10.0.0.1"); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("Login(replayed otp) error = %v, want ErrInvalidOTP", err)
+	}
+	if len(logs.FilterField(zap.String("event", loginAuditInvalidOTP)).All()) != 1 {
+		t.Error("invalid otp not audited")
+	}
+
+	// 验证码错误与密码错误共用失败计数，累计到阈值后锁定，锁定期间正确密码也被拒绝
+	req.Password = "wrong"
+	if _, err := svc.Login(ctx, req, "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login(wrong password) error = %v, want ErrInvalidCredentials", err)
+	}
+	_, err = svc.Login(ctx, req, "10.0.0.1")
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) || locked.RetryAfter != cfg.LockoutBase {
+		t.Fatalf("Login(fourth failure) error = %v, want lock of %s", err, cfg.LockoutBase)
+	}
+	req.Password = "password123"
+	if _, err := svc.Login(ctx, req, "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Login(locked) error = %v, want ErrLoginLocked", err)
+	}
+	for _, event := range []string{loginAuditAccountLocked, loginAuditBlocked} {
+		if len(logs.FilterField(zap.String("event", event)).All()) != 1 {
+			t.Errorf("event %s not audited", event)
+		}
+	}
+}
@@ -1,28 +1,44 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"sort"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
 	"github.com/MorseWayne/spike_shop/internal/repo"
 )
 
 // Mock ProductRepository for testing
 type mockProductRepository struct {
+	*mocks.ProductRepositoryMock
+
 	products map[int64]*domain.Product
 	skuMap   map[string]*domain.Product
 	nextID   int64
 }
 
 func newMockProductRepository() *mockProductRepository {
-	return &mockProductRepository{
-		products: make(map[int64]*domain.Product),
-		skuMap:   make(map[string]*domain.Product),
-		nextID:   1,
+	m := &mockProductRepository{
+		ProductRepositoryMock: &mocks.ProductRepositoryMock{},
+		products:              make(map[int64]*domain.Product),
+		skuMap:                make(map[string]*domain.Product),
+		nextID:                1,
 	}
+	m.CreateFunc = m.create
+	m.GetByIDFunc = m.getByID
+	m.GetBySKUFunc = m.getBySKU
+	m.UpdateFunc = m.update
+	m.DeleteFunc = m.delete
+	m.ListFunc = m.list
+	m.GetByIDsFunc = m.getByIDs
+	m.CountFunc = m.count
+	m.CountByStatusFunc = m.countByStatus
+	return m
 }
 
-func (m *mockProductRepository) Create(product *domain.Product) error {
+func (m *mockProductRepository) create(product *domain.Product) error {
 	if _, exists := m.skuMap[product.SKU]; exists {
 		return errors.New("SKU already exists")
 	}
@@ -36,7 +52,7 @@ func (m *mockProductRepository) Create(product *domain.Product) error {
 	return nil
 }
 
-func (m *mockProductRepository) GetByID(id int64) (*domain.Product, error) {
+func (m *mockProductRepository) getByID(id int64) (*domain.Product, error) {
 	product, exists := m.products[id]
 	if !exists {
 		return nil, nil
@@ -44,7 +60,7 @@ func (m *mockProductRepository) GetByID(id int64) (*domain.Product, error) {
 	return product, nil
 }
 
-func (m *mockProductRepository) GetBySKU(sku string) (*domain.Product, error) {
+func (m *mockProductRepository) getBySKU(sku string) (*domain.Product, error) {
 	product, exists := m.skuMap[sku]
 	if !exists {
 		return nil, nil
@@ -52,7 +68,7 @@ func (m *mockProductRepository) GetBySKU(sku string) (*domain.Product, error) {
 	return product, nil
 }
 
-func (m *mockProductRepository) Update(product *domain.Product) error {
+func (m *mockProductRepository) update(product *domain.Product) error {
 	if _, exists := m.products[product.ID]; !exists {
 		return errors.New("product not found")
 	}
@@ -60,7 +76,7 @@ func (m *mockProductRepository) Update(product *domain.Product) error {
 	return nil
 }
 
-func (m *mockProductRepository) Delete(id int64) error {
+func (m *mockProductRepository) delete(id int64) error {
 	product, exists := m.products[id]
 	if !exists {
 		return errors.New("product not found")
@@ -70,15 +86,26 @@ func (m *mockProductRepository) Delete(id int64) error {
 	return nil
 }
 
-func (m *mockProductRepository) List(req *domain.ProductListRequest) ([]*domain.Product, int64, error) {
+func (m *mockProductRepository) list(req *domain.ProductListRequest) ([]*domain.Product, int64, error) {
 	var result []*domain.Product
 	for _, product := range m.products {
 		result = append(result, product)
 	}
-	return result, int64(len(result)), nil
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	total := int64(len(result))
+	if req.SkipTotal {
+		total = domain.TotalNotCounted
+	}
+	offset := (req.Page - 1) * req.PageSize
+	if offset >= len(result) {
+		return nil, total, nil
+	}
+	end := min(offset+domain.LimitWithLookahead(req.PageSize, req.SkipTotal), len(result))
+	return result[offset:end], total, nil
 }
 
-func (m *mockProductRepository) GetByIDs(ids []int64) ([]*domain.Product, error) {
+func (m *mockProductRepository) getByIDs(ids []int64) ([]*domain.Product, error) {
 	var result []*domain.Product
 	for _, id := range ids {
 		if product, exists := m.products[id]; exists {
@@ -88,11 +115,11 @@ func (m *mockProductRepository) GetByIDs(ids []int64) ([]*domain.Product, error)
 	return result, nil
 }
 
-func (m *mockProductRepository) Count() (int64, error) {
+func (m *mockProductRepository) count() (int64, error) {
 	return int64(len(m.products)), nil
 }
 
-func (m *mockProductRepository) CountByStatus(status domain.ProductStatus) (int64, error) {
+func (m *mockProductRepository) countByStatus(status domain.ProductStatus) (int64, error) {
 	count := int64(0)
 	for _, product := range m.products {
 		if product.Status == status {
@@ -104,20 +131,40 @@ func (m *mockProductRepository) CountByStatus(status domain.ProductStatus) (int6
 
 // Mock InventoryRepository for testing
 type mockInventoryRepository struct {
+	*mocks.InventoryRepositoryMock
+
 	inventories map[int64]*domain.Inventory
 	productMap  map[int64]*domain.Inventory
 	nextID      int64
 }
 
 func newMockInventoryRepository() *mockInventoryRepository {
-	return &mockInventoryRepository{
-		inventories: make(map[int64]*domain.Inventory),
-		productMap:  make(map[int64]*domain.Inventory),
-		nextID:      1,
+	m := &mockInventoryRepository{
+		InventoryRepositoryMock: &mocks.InventoryRepositoryMock{},
+		inventories:             make(map[int64]*domain.Inventory),
+		productMap:              make(map[int64]*domain.Inventory),
+		nextID:                  1,
 	}
+	m.CreateFunc = m.create
+	m.GetByIDFunc = m.getByID
+	m.GetByProductIDFunc = m.getByProductID
+	m.UpdateFunc = m.update
+	m.UpdateWithVersionFunc = m.updateWithVersion
+	m.DeleteFunc = m.delete
+	m.GetByProductIDsFunc = m.getByProductIDs
+	m.BatchUpdateStockFunc = m.batchUpdateStock
+	m.ListFunc = m.list
+	m.GetLowStockProductsFunc = m.getLowStockProducts
+	m.ReserveStockFunc = m.reserveStock
+	m.ReleaseStockFunc = m.releaseStock
+	m.ConsumeStockFunc = m.consumeStock
+	m.AdjustStockFunc = m.adjustStock
+	m.CountFunc = m.count
+	m.GetTotalStockValueFunc = m.getTotalStockValue
+	return m
 }
 
-func (m *mockInventoryRepository) Create(inventory *domain.Inventory) error {
+func (m *mockInventoryRepository) create(ctx context.Context, inventory *domain.Inventory) error {
 	inventory.ID = m.nextID
 	m.nextID++
 
@@ -127,7 +174,7 @@ func (m *mockInventoryRepository) Create(inventory *domain.Inventory) error {
 	return nil
 }
 
-func (m *mockInventoryRepository) GetByID(id int64) (*domain.Inventory, error) {
+func (m *mockInventoryRepository) getByID(ctx context.Context, id int64) (*domain.Inventory, error) {
 	inventory, exists := m.inventories[id]
 	if !exists {
 		return nil, nil
@@ -135,7 +182,7 @@ func (m *mockInventoryRepository) GetByID(id int64) (*domain.Inventory, error) {
 	return inventory, nil
 }
 
-func (m *mockInventoryRepository) GetByProductID(productID int64) (*domain.Inventory, error) {
+func (m *mockInventoryRepository) getByProductID(ctx context.Context, productID int64) (*domain.Inventory, error) {
 	inventory, exists := m.productMap[productID]
 	if !exists {
 		return nil, nil
@@ -143,7 +190,7 @@ func (m *mockInventoryRepository) GetByProductID(productID int64) (*domain.Inven
 	return inventory, nil
 }
 
-func (m *mockInventoryRepository) Update(inventory *domain.Inventory) error {
+func (m *mockInventoryRepository) update(ctx context.Context, inventory *domain.Inventory) error {
 	if _, exists := m.inventories[inventory.ID]; !exists {
 		return errors.New("inventory not found")
 	}
@@ -152,11 +199,11 @@ func (m *mockInventoryRepository) Update(inventory *domain.Inventory) error {
 	return nil
 }
 
-func (m *mockInventoryRepository) UpdateWithVersion(inventory *domain.Inventory) error {
-	return m.Update(inventory)
+func (m *mockInventoryRepository) updateWithVersion(ctx context.Context, inventory *domain.Inventory) error {
+	return m.update(context.Background(), inventory)
 }
 
-func (m *mockInventoryRepository) Delete(id int64) error {
+func (m *mockInventoryRepository) delete(ctx context.Context, id int64) error {
 	inventory, exists := m.inventories[id]
 	if !exists {
 		return errors.New("inventory not found")
@@ -166,7 +213,7 @@ func (m *mockInventoryRepository) Delete(id int64) error {
 	return nil
 }
 
-func (m *mockInventoryRepository) GetByProductIDs(productIDs []int64) ([]*domain.Inventory, error) {
+func (m *mockInventoryRepository) getByProductIDs(ctx context.Context, productIDs []int64) ([]*domain.Inventory, error) {
 	var result []*domain.Inventory
 	for _, productID := range productIDs {
 		if inventory, exists := m.productMap[productID]; exists {
@@ -176,11 +223,33 @@ func (m *mockInventoryRepository) GetByProductIDs(productIDs []int64) ([]*domain
 	return result, nil
 }
 
-func (m *mockInventoryRepository) BatchUpdateStock(updates []repo.StockUpdate) error {
+// BatchUpdateStock 只模拟 adjust 类型：任一项失败时整体不生效，并返回失败项下标
+func (m *mockInventoryRepository) batchUpdateStock(ctx context.Context, updates []repo.StockUpdate) error {
+	stock := make(map[int64]int)
+	for i, update := range updates {
+		if update.Type != "adjust" {
+			continue
+		}
+		inventory, exists := m.productMap[update.ProductID]
+		if !exists {
+			return &repo.StockUpdateError{Index: i, ProductID: update.ProductID, Err: errors.New("inventory not found")}
+		}
+		current, seen := stock[update.ProductID]
+		if !seen {
+			current = inventory.Stock
+		}
+		if current+update.Quantity < 0 {
+			return &repo.StockUpdateError{Index: i, ProductID: update.ProductID, Err: errors.New("adjustment would result in negative stock")}
+		}
+		stock[update.ProductID] = current + update.Quantity
+	}
+	for productID, value := range stock {
+		m.productMap[productID].Stock = value
+	}
 	return nil
 }
 
-func (m *mockInventoryRepository) List(req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
+func (m *mockInventoryRepository) list(ctx context.Context, req *domain.InventoryListRequest) ([]*domain.Inventory, int64, error) {
 	var result []*domain.Inventory
 	for _, inventory := range m.inventories {
 		result = append(result, inventory)
@@ -188,7 +257,7 @@ func (m *mockInventoryRepository) List(req *domain.InventoryListRequest) ([]*dom
 	return result, int64(len(result)), nil
 }
 
-func (m *mockInventoryRepository) GetLowStockProducts() ([]*domain.Inventory, error) {
+func (m *mockInventoryRepository) getLowStockProducts(ctx context.Context) ([]*domain.Inventory, error) {
 	var result []*domain.Inventory
 	for _, inventory := range m.inventories {
 		if inventory.IsLowStock() {
@@ -198,7 +267,7 @@ func (m *mockInventoryRepository) GetLowStockProducts() ([]*domain.Inventory, er
 	return result, nil
 }
 
-func (m *mockInventoryRepository) ReserveStock(productID int64, quantity int) error {
+func (m *mockInventoryRepository) reserveStock(ctx context.Context, productID int64, quantity int) error {
 	inventory, exists := m.productMap[productID]
 	if !exists {
 		return errors.New("inventory not found")
@@ -210,7 +279,7 @@ func (m *mockInventoryRepository) ReserveStock(productID int64, quantity int) er
 	return nil
 }
 
-func (m *mockInventoryRepository) ReleaseStock(productID int64, quantity int) error {
+func (m *mockInventoryRepository) releaseStock(ctx context.Context, productID int64, quantity int) error {
 	inventory, exists := m.productMap[productID]
 	if !exists {
 		return errors.New("inventory not found")
@@ -222,7 +291,7 @@ func (m *mockInventoryRepository) ReleaseStock(productID int64, quantity int) er
 	return nil
 }
 
-func (m *mockInventoryRepository) ConsumeStock(productID int64, quantity int) error {
+func (m *mockInventoryRepository) consumeStock(ctx context.Context, productID int64, quantity int) error {
 	inventory, exists := m.productMap[productID]
 	if !exists {
 		return errors.New("inventory not found")
@@ -236,7 +305,7 @@ func (m *mockInventoryRepository) ConsumeStock(productID int64, quantity int) er
 	return nil
 }
 
-func (m *mockInventoryRepository) AdjustStock(productID int64, quantity int, reason string) error {
+func (m *mockInventoryRepository) adjustStock(ctx context.Context, productID int64, quantity int, reason string) error {
 	inventory, exists := m.productMap[productID]
 	if !exists {
 		return errors.New("inventory not found")
@@ -248,10 +317,10 @@ func (m *mockInventoryRepository) AdjustStock(productID int64, quantity int, rea
 	return nil
 }
 
-func (m *mockInventoryRepository) Count() (int64, error) {
+func (m *mockInventoryRepository) count(ctx context.Context) (int64, error) {
 	return int64(len(m.inventories)), nil
 }
 
-func (m *mockInventoryRepository) GetTotalStockValue() (float64, error) {
+func (m *mockInventoryRepository) getTotalStockValue(ctx context.Context) (float64, error) {
 	return 0, nil
 }
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/notify"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// NotificationService 发送用户通知：按消息指定的渠道路由到已配置的 Notifier，
+// 各渠道按自身的重试策略重试，投递结果按消息与渠道持久化
+type NotificationService struct {
+	dispatcher *notify.Dispatcher
+	deliveries repo.NotificationDeliveryRepository
+	users      repo.UserRepository
+	logger     *zap.Logger
+}
+
+// NewNotificationService 创建通知发送服务
+func NewNotificationService(dispatcher *notify.Dispatcher, deliveries repo.NotificationDeliveryRepository,
+	users repo.UserRepository, logger *zap.Logger) *NotificationService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &NotificationService{
+		dispatcher: dispatcher,
+		deliveries: deliveries,
+		users:      users,
+		logger:     logger,
+	}
+}
+
+// SendNotification 根据通知消息逐个渠道发送。
+// 业务规则：
+// 1. 消息重新投递时跳过已发送的渠道；未配置的渠道与已过期的通知记录为 skipped
+// 2. 渠道按重试策略重试后仍失败记录为 failed，不影响其他渠道，也不再重试整条消息
+// 3. 读取投递记录或收件人失败时返回错误，由消费者重试整条消息
+func (s *NotificationService) SendNotification(ctx context.Context, messageID, traceID string, data *mq.NotificationData) error {
+	if messageID == "" {
+		// 没有消息ID无法按消息去重，按用户与发送时间生成一次性的ID
+		messageID = data.Type + ":" + strconv.FormatInt(data.UserID, 10) + ":" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	delivered, err := s.delivered(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	msg := &notify.Message{
+		ID:       messageID,
+		UserID:   data.UserID,
+		Type:     data.Type,
+		Title:    data.Title,
+		Content:  data.Content,
+		Priority: data.Priority,
+		Data:     data.Data,
+	}
+	expired := data.ExpireAt != nil && time.Now().After(*data.ExpireAt)
+
+	var channels []string
+	for _, channel := range data.Channels {
+		if slices.Contains(channels, channel) || delivered[channel] {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+
+	for _, channel := range channels {
+		delivery := &domain.NotificationDelivery{
+			MessageID: messageID,
+			UserID:    data.UserID,
+			Channel:   channel,
+			Type:      data.Type,
+		}
+
+		switch {
+		case expired:
+			delivery.Status, delivery.LastError = domain.NotificationDeliverySkipped, "notification expired"
+		case !s.dispatcher.Has(channel):
+			delivery.Status, delivery.LastError = domain.NotificationDeliverySkipped, notify.ErrChannelNotConfigured.Error()
+		default:
+			if channel == notify.ChannelEmail && msg.Email == "" {
+				if msg.Email, err = s.recipientEmail(data.UserID); err != nil {
+					return err
+				}
+			}
+			attempts, err := s.dispatcher.Deliver(ctx, channel, msg)
+			delivery.Attempts = attempts
+			if err != nil {
+				delivery.Status, delivery.LastError = domain.NotificationDeliveryFailed, err.Error()
+				s.logger.Warn("通知发送失败",
+					zap.String("message_id", messageID),
+					zap.String("trace_id", traceID),
+					zap.String("channel", channel),
+					logger.UserID(data.UserID),
+					zap.Int("attempts", attempts),
+					zap.Error(err))
+			} else {
+				delivery.Status = domain.NotificationDeliverySent
+			}
+		}
+
+		if err := s.deliveries.Save(ctx, delivery); err != nil {
+			// 已发送但记录失败时，重新投递会重复发送，Webhook 下游可按 Idempotency-Key 去重
+			s.logger.Error("保存通知投递记录失败",
+				zap.String("message_id", messageID),
+				zap.String("channel", channel),
+				zap.String("status", string(delivery.Status)),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("通知处理完成",
+		zap.String("message_id", messageID),
+		zap.String("trace_id", traceID),
+		logger.UserID(data.UserID),
+		zap.String("type", data.Type),
+		zap.Strings("channels", channels))
+	return nil
+}
+
+// delivered 返回消息已发送成功的渠道
+func (s *NotificationService) delivered(ctx context.Context, messageID string) (map[string]bool, error) {
+	deliveries, err := s.deliveries.ListByMessage(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+	delivered := make(map[string]bool, len(deliveries))
+	for _, delivery := range deliveries {
+		if delivery.Status == domain.NotificationDeliverySent {
+			delivered[delivery.Channel] = true
+		}
+	}
+	return delivered, nil
+}
+
+// recipientEmail 查询用户邮箱，用户不存在时返回空邮箱，由邮件渠道记录为不可重试的失败
+func (s *NotificationService) recipientEmail(userID int64) (string, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get notification recipient: %w", err)
+	}
+	if user == nil {
+		return "", nil
+	}
+	return user.Email, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/notify"
+)
+
+// fakeNotificationDeliveries 在内存中按消息与渠道保存投递记录
+type fakeNotificationDeliveries struct {
+	records map[string]*domain.NotificationDelivery // messageID:channel -> 记录
+}
+
+func (f *fakeNotificationDeliveries) ListByMessage(ctx context.Context, messageID string) ([]*domain.NotificationDelivery, error) {
+	var deliveries []*domain.NotificationDelivery
+	for _, delivery := range f.records {
+		if delivery.MessageID == messageID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (f *fakeNotificationDeliveries) Save(ctx context.Context, delivery *domain.NotificationDelivery) error {
+	key := delivery.MessageID + ":" + delivery.Channel
+	if existing, ok := f.records[key]; ok {
+		delivery.Attempts += existing.Attempts
+	}
+	f.records[key] = delivery
+	return nil
+}
+
+// channelNotifier 记录收到的通知，fail 为 true 时返回可重试的错误
+type channelNotifier struct {
+	channel string
+	fail    bool
+	sent    []*notify.Message
+}
+
+func (n *channelNotifier) Channel() string { return n.channel }
+
+func (n *channelNotifier) Send(ctx context.Context, msg *notify.Message) error {
+	if n.fail {
+		return errors.New("smtp unavailable")
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func TestNotificationService_SendNotification(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	_ = users.Create(&domain.User{Username: "alice", Email: "alice@example.com"})
+	user, _ := users.GetByUsername("alice")
+
+	email := &channelNotifier{channel: notify.ChannelEmail, fail: true}
+	push := &channelNotifier{channel: notify.ChannelPush}
+	dispatcher := notify.NewDispatcher()
+	dispatcher.Register(email, notify.RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond})
+	dispatcher.Register(push, notify.RetryPolicy{})
+	deliveries := &fakeNotificationDeliveries{records: make(map[string]*domain.NotificationDelivery)}
+	svc := NewNotificationService(dispatcher, deliveries, users, nil)
+
+	data := &mq.NotificationData{
+		UserID: user.ID, Type: "spike_order_created", Title: "秒杀成功", Content: "请尽快支付",
+		Channels: []string{"push", "email", "sms", "push"},
+	}
+	if err := svc.SendNotification(ctx, "msg-1", "trace-1", data); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+
+	// 按渠道路由：推送成功，邮件重试后失败，短信未配置；重复的渠道只发送一次
+	want := map[string]domain.NotificationDeliveryStatus{
+		"push":  domain.NotificationDeliverySent,
+		"email": domain.NotificationDeliveryFailed,
+		"sms":   domain.NotificationDeliverySkipped,
+	}
+	for channel, status := range want {
+		if got := deliveries.records["msg-1:"+channel]; got == nil || got.Status != status {
+			t.Errorf("%s delivery = %+v, want %s", channel, got, status)
+		}
+	}
+	if got := deliveries.records["msg-1:email"].Attempts; got != 2 {
+		t.Errorf("email attempts = %d, want 2", got)
+	}
+	if len(push.sent) != 1 {
+		t.Fatalf("push sent = %d, want 1", len(push.sent))
+	}
+
+	// 重新投递时跳过已发送的渠道，只重发失败的渠道
+	email.fail = false
+	if err := svc.SendNotification(ctx, "msg-1", "trace-1", data); err != nil {
+		t.Fatalf("SendNotification(redelivered) error = %v", err)
+	}
+	if len(push.sent) != 1 {
+		t.Errorf("push sent = %d after redelivery, want 1", len(push.sent))
+	}
+	if len(email.sent) != 1 || email.sent[0].Email != "alice@example.com" {
+		t.Errorf("email sent = %+v, want one mail to alice", email.sent)
+	}
+	if got := deliveries.records["msg-1:email"]; got.Status != domain.NotificationDeliverySent || got.Attempts != 3 {
+		t.Errorf("email delivery = %+v, want sent after 3 attempts", got)
+	}
+
+	// 已过期的通知不再发送
+	expired := time.Now().Add(-time.Minute)
+	if err := svc.SendNotification(ctx, "msg-2", "", &mq.NotificationData{
+		UserID: user.ID, Channels: []string{"push"}, ExpireAt: &expired,
+	}); err != nil {
+		t.Fatalf("SendNotification(expired) error = %v", err)
+	}
+	if got := deliveries.records["msg-2:push"]; got == nil || got.Status != domain.NotificationDeliverySkipped || len(push.sent) != 1 {
+		t.Errorf("expired delivery = %+v, push sent = %d; want skipped", got, len(push.sent))
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ExpiredOrderReleaser 过期普通订单并释放预留库存（由 OrderService 实现）
+type ExpiredOrderReleaser interface {
+	ExpireOrders(ctx context.Context, limit int) (int, error)
+}
+
+// OrderExpirer 定期把超过支付期限的待支付普通订单标记为已过期并释放预留库存
+type OrderExpirer struct {
+	releaser  ExpiredOrderReleaser
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewOrderExpirer 创建普通订单过期任务，每次扫描最多处理 batchSize 条，处理满一批时立即继续
+func NewOrderExpirer(releaser ExpiredOrderReleaser, interval time.Duration, batchSize int, logger *zap.Logger) *OrderExpirer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &OrderExpirer{
+		releaser:  releaser,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Start 异步启动过期任务，ctx 取消时退出
+func (e *OrderExpirer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		e.logger.Info("普通订单过期任务已启动",
+			zap.Duration("interval", e.interval),
+			zap.Int("batch_size", e.batchSize))
+		for {
+			select {
+			case <-ctx.Done():
+				e.logger.Info("普通订单过期任务已停止")
+				return
+			case <-ticker.C:
+				e.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 处理当前所有已过期的待支付订单，返回标记为已过期的订单数
+func (e *OrderExpirer) RunOnce(ctx context.Context) int {
+	total := 0
+	for ctx.Err() == nil {
+		expired, err := e.releaser.ExpireOrders(ctx, e.batchSize)
+		total += expired
+		if err != nil {
+			e.logger.Warn("处理过期普通订单失败", zap.Int("expired", expired), zap.Error(err))
+			break
+		}
+		// 不满一批说明已处理完，或本批订单已被并发的支付、取消处理
+		if expired < e.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		e.logger.Info("已处理过期普通订单", zap.Int("count", total))
+	}
+	return total
+}
@@ -0,0 +1,589 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// orderPaymentRefPrefix 普通订单支付意图商户单号前缀，商户单号形如 order:42
+const orderPaymentRefPrefix = "order:"
+
+//go:generate moq -rm -out order_service_moq_test.go . OrderProductSource OrderStockStore
+
+// OrderProductSource 批量读取商品快照（由 repo.ProductRepository 实现）
+type OrderProductSource interface {
+	GetByIDs(ids []int64) ([]*domain.Product, error)
+}
+
+// OrderStockStore 普通订单的库存预留、消费与释放（由 repo.InventoryRepository 实现）
+type OrderStockStore interface {
+	ReserveStock(ctx context.Context, productID int64, quantity int) error
+	ReleaseStock(ctx context.Context, productID int64, quantity int) error
+	ConsumeStock(ctx context.Context, productID int64, quantity int) error
+}
+
+// OrderServiceConfig 普通订单配置
+type OrderServiceConfig struct {
+	PaymentTimeout time.Duration // 结算后的支付期限，超时未支付的订单由过期任务释放库存
+	CartMaxItems   int           // 购物车商品种类上限
+}
+
+// DefaultOrderServiceConfig 默认普通订单配置：30 分钟内支付，购物车最多 100 种商品
+func DefaultOrderServiceConfig() *OrderServiceConfig {
+	return &OrderServiceConfig{
+		PaymentTimeout: 30 * time.Minute,
+		CartMaxItems:   100,
+	}
+}
+
+// OrderService 定义购物车与普通订单服务接口
+type OrderService interface {
+	// GetCart 获取购物车及商品当前售价
+	GetCart(ctx context.Context, userID int64) (*domain.Cart, error)
+	// AddCartItem 加入购物车，商品已在购物车中时累加数量；商品种类达到上限时返回 domain.ErrCartFull
+	AddCartItem(ctx context.Context, userID int64, req *domain.AddCartItemRequest) (*domain.Cart, error)
+	// UpdateCartItem 修改购物车商品数量
+	UpdateCartItem(ctx context.Context, userID, productID int64, req *domain.UpdateCartItemRequest) (*domain.Cart, error)
+	// RemoveCartItem 从购物车移除商品
+	RemoveCartItem(ctx context.Context, userID, productID int64) (*domain.Cart, error)
+
+	// Checkout 结算购物车中的商品：按当前售价生成待支付订单并预留库存，结算成功的商品从购物车移除。
+	// 任一商品不可售或库存不足时整单失败，已预留的库存随即释放
+	Checkout(ctx context.Context, userID int64, req *domain.CheckoutRequest) (*domain.Order, error)
+	// GetOrder 获取订单及明细
+	GetOrder(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.Order, error)
+	// ListOrders 分页查询订单，req.UserID 由调用方按身份设置
+	ListOrders(ctx context.Context, req *domain.OrderListRequest) (*domain.OrderListResponse, error)
+	// PayOrder 凭支付凭证一步完成扣款，扣款成功后订单变为已支付并消费预留库存。
+	// 渠道不支持一步扣款或支付方式不是当前渠道时返回 domain.ErrPaymentMethodUnsupported，
+	// 渠道拒绝时返回失败的支付意图与 domain.ErrOrderPaymentDeclined
+	PayOrder(ctx context.Context, orderID, userID int64, isAdmin bool, req *domain.PayOrderRequest) (*domain.Order, *payment.Intent, error)
+	// CancelOrder 取消待支付订单并释放预留库存
+	CancelOrder(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.Order, error)
+	// ExpireOrders 把超过支付期限的待支付订单标记为已过期并释放预留库存，最多处理 limit 个，返回处理数
+	ExpireOrders(ctx context.Context, limit int) (int, error)
+
+	SpikeBackingOrderCreator
+}
+
+// orderService 是OrderService接口的实现
+type orderService struct {
+	orders    repo.OrderRepository
+	carts     repo.CartRepository
+	products  OrderProductSource
+	stock     OrderStockStore
+	provider  payment.Provider
+	currency  string
+	ownership OwnershipPolicy
+	config    *OrderServiceConfig
+	logger    *zap.Logger
+
+	// 订单号生成器，可为空
+	orderNos OrderNoGenerator
+
+	// 商品停售检查，可为空
+	stopSell StopSellChecker
+
+	// 商品规格，可为空，为空时规格活动的明细只记录规格ID
+	variants OrderVariantSource
+}
+
+// OrderVariantSource 读取秒杀活动指定的商品规格，用于订单明细快照（由 repo.ProductVariantRepository 实现）
+type OrderVariantSource interface {
+	GetByID(ctx context.Context, id int64) (*domain.ProductVariant, error)
+}
+
+// OrderServiceOption 普通订单服务的可选配置
+type OrderServiceOption func(*orderService)
+
+// WithOrderNoGenerator 设置订单号生成器，多实例部署时必须与秒杀服务共用同一个生成器；未设置时使用 idgen.Default()
+func WithOrderNoGenerator(gen OrderNoGenerator) OrderServiceOption {
+	return func(s *orderService) {
+		s.orderNos = gen
+	}
+}
+
+// WithOrderStopSell 加入购物车与结算前检查商品停售标记，标记读取失败时拒绝
+func WithOrderStopSell(checker StopSellChecker) OrderServiceOption {
+	return func(s *orderService) {
+		s.stopSell = checker
+	}
+}
+
+// WithOrderVariants 为指定了商品规格的秒杀订单记录规格名称与 SKU
+func WithOrderVariants(variants OrderVariantSource) OrderServiceOption {
+	return func(s *orderService) {
+		s.variants = variants
+	}
+}
+
+// NewOrderService 创建普通订单服务
+func NewOrderService(orders repo.OrderRepository, carts repo.CartRepository, products OrderProductSource, stock OrderStockStore,
+	provider payment.Provider, currency string, ownership OwnershipPolicy, config *OrderServiceConfig, logger *zap.Logger,
+	opts ...OrderServiceOption) OrderService {
+	if config == nil {
+		config = DefaultOrderServiceConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	s := &orderService{
+		orders:    orders,
+		carts:     carts,
+		products:  products,
+		stock:     stock,
+		provider:  provider,
+		currency:  currency,
+		ownership: ownership,
+		config:    config,
+		logger:    logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetCart 获取购物车
+func (s *orderService) GetCart(ctx context.Context, userID int64) (*domain.Cart, error) {
+	items, err := s.carts.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cart items: %w", err)
+	}
+	products, err := s.loadProducts(items)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &domain.Cart{Lines: make([]*domain.CartLine, 0, len(items))}
+	for _, item := range items {
+		cart.AddLine(domain.NewCartLine(item, products[item.ProductID]))
+	}
+	return cart, nil
+}
+
+// AddCartItem 加入购物车
+func (s *orderService) AddCartItem(ctx context.Context, userID int64, req *domain.AddCartItemRequest) (*domain.Cart, error) {
+	if err := s.checkSellable(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.carts.Count(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cart items: %w", err)
+	}
+	// 已在购物车中的商品只累加数量，不占用新的种类
+	if count >= s.config.CartMaxItems {
+		items, err := s.carts.ListByUser(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cart items: %w", err)
+		}
+		if findCartItem(items, req.ProductID) == nil {
+			return nil, domain.ErrCartFull
+		}
+	}
+
+	if _, err := s.carts.Add(ctx, userID, req.ProductID, req.Quantity); err != nil {
+		return nil, fmt.Errorf("failed to add cart item: %w", err)
+	}
+	return s.GetCart(ctx, userID)
+}
+
+// UpdateCartItem 修改购物车商品数量
+func (s *orderService) UpdateCartItem(ctx context.Context, userID, productID int64, req *domain.UpdateCartItemRequest) (*domain.Cart, error) {
+	if err := s.carts.UpdateQuantity(ctx, userID, productID, req.Quantity); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, userID)
+}
+
+// RemoveCartItem 从购物车移除商品
+func (s *orderService) RemoveCartItem(ctx context.Context, userID, productID int64) (*domain.Cart, error) {
+	removed, err := s.carts.Remove(ctx, userID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove cart item: %w", err)
+	}
+	if removed == 0 {
+		return nil, domain.ErrCartItemNotFound
+	}
+	return s.GetCart(ctx, userID)
+}
+
+// Checkout 结算购物车
+func (s *orderService) Checkout(ctx context.Context, userID int64, req *domain.CheckoutRequest) (*domain.Order, error) {
+	items, err := s.carts.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cart items: %w", err)
+	}
+	if len(req.ProductIDs) > 0 {
+		selected := make([]*domain.CartItem, 0, len(req.ProductIDs))
+		for _, productID := range req.ProductIDs {
+			if item := findCartItem(items, productID); item != nil {
+				selected = append(selected, item)
+			}
+		}
+		if len(selected) != len(req.ProductIDs) {
+			return nil, domain.ErrCartItemNotFound
+		}
+		items = selected
+	}
+	if len(items) == 0 {
+		return nil, domain.ErrCartEmpty
+	}
+
+	// 按结算时的售价生成明细，任一商品不可售时整单失败
+	products, err := s.loadProducts(items)
+	if err != nil {
+		return nil, err
+	}
+	orderItems := make([]*domain.OrderItem, 0, len(items))
+	for _, item := range items {
+		product, ok := products[item.ProductID]
+		if !ok || !product.IsAvailable() {
+			return nil, domain.NewInvalidArgumentError(fmt.Sprintf("商品 %d 已下架", item.ProductID))
+		}
+		if err := s.checkStopSell(ctx, item.ProductID); err != nil {
+			return nil, err
+		}
+		orderItems = append(orderItems, domain.NewOrderItem(product, product.Price, item.Quantity))
+	}
+
+	orderNo, err := nextOrderNo(s.orderNos)
+	if err != nil {
+		return nil, err
+	}
+	expireAt := time.Now().Add(s.config.PaymentTimeout)
+	order := &domain.Order{
+		OrderNo:  orderNo,
+		UserID:   userID,
+		Source:   domain.OrderSourceCart,
+		Status:   domain.OrderStatusPending,
+		ExpireAt: &expireAt,
+	}
+	order.SetItems(orderItems)
+
+	// 逐个商品预留库存，失败时归还已预留的部分
+	stockCtx := domain.WithStockReference(ctx, orderNo, "订单结算")
+	for i, item := range orderItems {
+		if err := s.stock.ReserveStock(stockCtx, item.ProductID, item.Quantity); err != nil {
+			s.releaseItems(ctx, order, orderItems[:i], "订单结算失败，归还库存")
+			return nil, fmt.Errorf("failed to reserve stock for product %d: %w", item.ProductID, err)
+		}
+	}
+
+	if _, err := s.orders.Create(ctx, order); err != nil {
+		s.releaseItems(ctx, order, orderItems, "订单写入失败，归还库存")
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	// 订单已生成，购物车清理失败只影响展示，不回滚订单
+	productIDs := make([]int64, len(orderItems))
+	for i, item := range orderItems {
+		productIDs[i] = item.ProductID
+	}
+	if _, err := s.carts.Remove(ctx, userID, productIDs...); err != nil {
+		s.logger.Warn("结算后清理购物车失败", zap.String("order_no", orderNo), logger.UserID(userID), zap.Error(err))
+	}
+
+	s.logger.Info("购物车结算成功",
+		zap.Int64("order_id", order.ID),
+		zap.String("order_no", orderNo),
+		logger.UserID(userID),
+		zap.Float64("total_amount", order.TotalAmount))
+	return order, nil
+}
+
+// GetOrder 获取订单
+func (s *orderService) GetOrder(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.Order, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ownership.Authorize(order.UserID, userID, isAdmin, domain.ErrOrderNotFound); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ListOrders 分页查询订单
+func (s *orderService) ListOrders(ctx context.Context, req *domain.OrderListRequest) (*domain.OrderListResponse, error) {
+	orders, total, err := s.orders.List(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	orders, pageInfo := domain.Paginate(orders, total, req.Page, req.PageSize)
+	return &domain.OrderListResponse{
+		Orders:   orders,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// PayOrder 支付订单
+func (s *orderService) PayOrder(ctx context.Context, orderID, userID int64, isAdmin bool, req *domain.PayOrderRequest) (*domain.Order, *payment.Intent, error) {
+	charger, ok := s.provider.(payment.Charger)
+	if !ok || req.PaymentMethod != s.provider.Name() {
+		return nil, nil, domain.ErrPaymentMethodUnsupported
+	}
+	order, err := s.GetOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !order.CanPay() {
+		return nil, nil, domain.ErrOrderNotPayable
+	}
+
+	intent, err := charger.Charge(ctx, &payment.IntentRequest{
+		Reference: orderPaymentRefPrefix + strconv.FormatInt(order.ID, 10),
+		Amount:    order.TotalAmount,
+		Currency:  s.currency,
+		UserID:    order.UserID,
+	}, req.PaymentInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to charge payment: %w", err)
+	}
+	if intent.Status != payment.IntentStatusSucceeded {
+		s.logger.Info("支付被拒绝", zap.Int64("order_id", order.ID),
+			zap.String("intent_id", intent.ID), zap.String("reason", intent.FailureReason))
+		return nil, intent, domain.ErrOrderPaymentDeclined
+	}
+
+	// 扣款期间订单可能已被取消或过期，此时预留库存已释放，退款后拒绝支付
+	paidAt := time.Now()
+	updated, err := s.orders.Transition(ctx, order.ID, domain.OrderStatusPending, domain.OrderStatusPaid, paidAt, intent.ID)
+	if err != nil || !updated {
+		if _, refundErr := s.provider.Refund(ctx, intent.ID, intent.Amount); refundErr != nil {
+			s.logger.Error("订单状态已变化，退款失败，需人工处理",
+				zap.Int64("order_id", order.ID), zap.String("intent_id", intent.ID), zap.Error(refundErr))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to mark order paid: %w", err)
+		}
+		return nil, nil, domain.ErrOrderNotPayable
+	}
+
+	stockCtx := domain.WithStockReference(ctx, order.OrderNo, "订单支付")
+	for _, item := range order.Items {
+		if err := s.stock.ConsumeStock(stockCtx, item.ProductID, item.Quantity); err != nil {
+			s.logger.Error("订单已支付，消费预留库存失败，需人工对账",
+				zap.String("order_no", order.OrderNo), zap.Int64("product_id", item.ProductID), zap.Error(err))
+		}
+	}
+
+	order.Status = domain.OrderStatusPaid
+	order.PaidAt = &paidAt
+	order.PaymentRef = intent.ID
+	s.logger.Info("订单支付成功",
+		zap.Int64("order_id", order.ID),
+		logger.UserID(order.UserID),
+		zap.String("intent_id", intent.ID))
+	return order, intent, nil
+}
+
+// CancelOrder 取消订单
+func (s *orderService) CancelOrder(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.Order, error) {
+	order, err := s.GetOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelledAt := time.Now()
+	updated, err := s.orders.Transition(ctx, order.ID, domain.OrderStatusPending, domain.OrderStatusCancelled, cancelledAt, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if !updated {
+		return nil, domain.ErrOrderNotCancellable
+	}
+	s.releaseItems(ctx, order, order.Items, "订单取消")
+
+	order.Status = domain.OrderStatusCancelled
+	order.CancelledAt = &cancelledAt
+	s.logger.Info("订单已取消", zap.Int64("order_id", order.ID), logger.UserID(userID))
+	return order, nil
+}
+
+// ExpireOrders 处理过期订单
+func (s *orderService) ExpireOrders(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+	expired, err := s.orders.GetExpiredOrders(ctx, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired orders: %w", err)
+	}
+
+	count := 0
+	for _, candidate := range expired {
+		// 只有抢到状态更新的实例释放库存，与支付、取消并发时不重复释放
+		updated, err := s.orders.Transition(ctx, candidate.ID, domain.OrderStatusPending, domain.OrderStatusExpired, now, "")
+		if err != nil {
+			return count, fmt.Errorf("failed to expire order %d: %w", candidate.ID, err)
+		}
+		if !updated {
+			continue
+		}
+		order, err := s.orders.GetByID(ctx, candidate.ID)
+		if err != nil {
+			return count, fmt.Errorf("failed to get expired order %d: %w", candidate.ID, err)
+		}
+		s.releaseItems(ctx, order, order.Items, "订单超时未支付")
+		count++
+	}
+	return count, nil
+}
+
+// CreateForSpikeOrder 为已支付的秒杀订单创建已支付的普通订单，重复调用返回已创建的订单ID
+func (s *orderService) CreateForSpikeOrder(ctx context.Context, spikeOrder *domain.SpikeOrder, spikeEvent *domain.SpikeEvent) (*domain.Order, error) {
+	productID := spikeEvent.ProductID
+	products, err := s.products.GetByIDs([]int64{productID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	// 商品可能已下架或删除，明细仍按秒杀价记录
+	product := &domain.Product{ID: productID}
+	if len(products) > 0 {
+		product = products[0]
+	}
+
+	orderNo := spikeOrder.OrderNo
+	if orderNo == "" {
+		if orderNo, err = nextOrderNo(s.orderNos); err != nil {
+			return nil, err
+		}
+	}
+	paidAt := time.Now()
+	if spikeOrder.PaidAt != nil {
+		paidAt = *spikeOrder.PaidAt
+	}
+	spikeOrderID := spikeOrder.ID
+	order := &domain.Order{
+		OrderNo:      orderNo,
+		UserID:       spikeOrder.UserID,
+		Source:       domain.OrderSourceSpike,
+		SpikeOrderID: &spikeOrderID,
+		Status:       domain.OrderStatusPaid,
+		PaymentRef:   spikeOrder.PaymentRef,
+		PaidAt:       &paidAt,
+	}
+	item := domain.NewOrderItem(product, spikeOrder.SpikePrice, int(spikeOrder.Quantity))
+	if spikeEvent.VariantID != nil {
+		if err := s.applyVariant(ctx, item, *spikeEvent.VariantID, product.Name); err != nil {
+			return nil, err
+		}
+	}
+	order.SetItems([]*domain.OrderItem{item})
+
+	created, err := s.orders.Create(ctx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order for spike order: %w", err)
+	}
+	if created {
+		s.logger.Info("已为秒杀订单创建普通订单",
+			zap.Int64("spike_order_id", spikeOrder.ID),
+			zap.Int64("order_id", order.ID))
+	}
+	return order, nil
+}
+
+// applyVariant 明细关联秒杀的商品规格，并以规格的名称与 SKU 作为快照；规格已删除时保留商品快照
+func (s *orderService) applyVariant(ctx context.Context, item *domain.OrderItem, variantID int64, productName string) error {
+	item.VariantID = &variantID
+	if s.variants == nil {
+		return nil
+	}
+	variant, err := s.variants.GetByID(ctx, variantID)
+	if errors.Is(err, domain.ErrProductVariantNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get product variant: %w", err)
+	}
+	item.ProductName = variant.DisplayName(productName)
+	item.SKU = variant.SKU
+	return nil
+}
+
+// loadProducts 批量读取购物车商品，返回商品ID到商品的映射；已删除的商品不在映射中
+func (s *orderService) loadProducts(items []*domain.CartItem) (map[int64]*domain.Product, error) {
+	products := make(map[int64]*domain.Product, len(items))
+	if len(items) == 0 {
+		return products, nil
+	}
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	list, err := s.products.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+	for _, product := range list {
+		products[product.ID] = product
+	}
+	return products, nil
+}
+
+// checkSellable 校验商品存在、可售且未停售
+func (s *orderService) checkSellable(ctx context.Context, productID int64) error {
+	products, err := s.products.GetByIDs([]int64{productID})
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if len(products) == 0 {
+		return domain.NewNotFoundError("商品不存在")
+	}
+	if !products[0].IsAvailable() {
+		return domain.NewInvalidArgumentError("商品已下架")
+	}
+	return s.checkStopSell(ctx, productID)
+}
+
+// checkStopSell 商品已停售时返回 domain.ErrProductStopSell
+func (s *orderService) checkStopSell(ctx context.Context, productID int64) error {
+	if s.stopSell == nil {
+		return nil
+	}
+	stopped, err := s.stopSell.IsStopped(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to check stop sell: %w", err)
+	}
+	if stopped {
+		return domain.ErrProductStopSell
+	}
+	return nil
+}
+
+// releaseItems 释放订单明细预留的库存，失败只记录日志，由库存对账修正
+func (s *orderService) releaseItems(ctx context.Context, order *domain.Order, items []*domain.OrderItem, reason string) {
+	stockCtx := domain.WithStockReference(ctx, order.OrderNo, reason)
+	for _, item := range items {
+		if err := s.stock.ReleaseStock(stockCtx, item.ProductID, item.Quantity); err != nil {
+			s.logger.Error("释放订单预留库存失败",
+				zap.String("order_no", order.OrderNo),
+				zap.Int64("product_id", item.ProductID),
+				zap.Int("quantity", item.Quantity),
+				zap.Error(err))
+		}
+	}
+}
+
+// findCartItem 在购物车条目中查找商品
+func findCartItem(items []*domain.CartItem, productID int64) *domain.CartItem {
+	for _, item := range items {
+		if item.ProductID == productID {
+			return item
+		}
+	}
+	return nil
+}
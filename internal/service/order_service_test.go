@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+)
+
+// fakeOrderRepo 内存订单仓储，按秒杀订单ID去重
+type fakeOrderRepo struct {
+	*mocks.OrderRepositoryMock
+
+	orders map[int64]*domain.Order
+	// rejectTransition 模拟订单已被并发处理，状态更新不生效
+	rejectTransition bool
+}
+
+func newFakeOrderRepo() *fakeOrderRepo {
+	r := &fakeOrderRepo{OrderRepositoryMock: &mocks.OrderRepositoryMock{}, orders: make(map[int64]*domain.Order)}
+	r.CreateFunc = r.create
+	r.GetByIDFunc = r.getByID
+	r.TransitionFunc = r.transition
+	return r
+}
+
+func (r *fakeOrderRepo) create(ctx context.Context, order *domain.Order) (bool, error) {
+	for _, existing := range r.orders {
+		if order.SpikeOrderID != nil && existing.SpikeOrderID != nil && *existing.SpikeOrderID == *order.SpikeOrderID {
+			order.ID = existing.ID
+			return false, nil
+		}
+	}
+	order.ID = int64(len(r.orders) + 1)
+	stored := *order
+	r.orders[order.ID] = &stored
+	return true, nil
+}
+
+func (r *fakeOrderRepo) getByID(ctx context.Context, id int64) (*domain.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrderRepo) transition(ctx context.Context, id int64, from, to domain.OrderStatus, at time.Time, paymentRef string) (bool, error) {
+	order, ok := r.orders[id]
+	if !ok || order.Status != from || r.rejectTransition {
+		return false, nil
+	}
+	order.Status = to
+	return true, nil
+}
+
+// fakeCartRepo 内存购物车仓储
+type fakeCartRepo struct {
+	*mocks.CartRepositoryMock
+
+	items []*domain.CartItem
+}
+
+func newFakeCartRepo(items ...*domain.CartItem) *fakeCartRepo {
+	r := &fakeCartRepo{CartRepositoryMock: &mocks.CartRepositoryMock{}, items: items}
+	r.ListByUserFunc = func(ctx context.Context, userID int64) ([]*domain.CartItem, error) {
+		return r.items, nil
+	}
+	r.RemoveFunc = r.remove
+	return r
+}
+
+func (r *fakeCartRepo) remove(ctx context.Context, userID int64, productIDs ...int64) (int64, error) {
+	var kept []*domain.CartItem
+	for _, item := range r.items {
+		if !slices.Contains(productIDs, item.ProductID) {
+			kept = append(kept, item)
+		}
+	}
+	removed := int64(len(r.items) - len(kept))
+	r.items = kept
+	return removed, nil
+}
+
+// newFakeOrderProducts 按ID返回在售商品
+func newFakeOrderProducts(products map[int64]*domain.Product) *OrderProductSourceMock {
+	return &OrderProductSourceMock{
+		GetByIDsFunc: func(ids []int64) ([]*domain.Product, error) {
+			var found []*domain.Product
+			for _, id := range ids {
+				if product, ok := products[id]; ok {
+					found = append(found, product)
+				}
+			}
+			return found, nil
+		},
+	}
+}
+
+// fakeOrderStock 记录库存操作，failReserve 中的商品预留失败
+type fakeOrderStock struct {
+	*OrderStockStoreMock
+
+	failReserve map[int64]bool
+	calls       []string
+}
+
+func newFakeOrderStock(failReserve map[int64]bool) *fakeOrderStock {
+	s := &fakeOrderStock{OrderStockStoreMock: &OrderStockStoreMock{}, failReserve: failReserve}
+	s.ReserveStockFunc = func(ctx context.Context, productID int64, quantity int) error {
+		if s.failReserve[productID] {
+			return domain.NewInsufficientStockError("insufficient stock to reserve")
+		}
+		s.record("reserve", productID)
+		return nil
+	}
+	s.ReleaseStockFunc = func(ctx context.Context, productID int64, quantity int) error {
+		s.record("release", productID)
+		return nil
+	}
+	s.ConsumeStockFunc = func(ctx context.Context, productID int64, quantity int) error {
+		s.record("consume", productID)
+		return nil
+	}
+	return s
+}
+
+func (s *fakeOrderStock) record(op string, productID int64) {
+	s.calls = append(s.calls, op+":"+strconv.FormatInt(productID, 10))
+}
+
+func newTestOrderService(orders *fakeOrderRepo, carts *fakeCartRepo, stock *fakeOrderStock) OrderService {
+	products := newFakeOrderProducts(map[int64]*domain.Product{
+		1: {ID: 1, Name: "键盘", SKU: "KB-1", Price: 199.9, Status: domain.ProductStatusActive},
+		2: {ID: 2, Name: "鼠标", SKU: "MS-1", Price: 49.5, Status: domain.ProductStatusActive},
+	})
+	return NewOrderService(orders, carts, products, stock, payment.NewSandbox("secret"), "CNY",
+		OwnershipPolicy{HideForeign: true}, nil, nil)
+}
+
+func TestOrderService_CheckoutReleasesReservedStockOnFailure(t *testing.T) {
+	orders := newFakeOrderRepo()
+	carts := newFakeCartRepo(&domain.CartItem{UserID: 7, ProductID: 1, Quantity: 1}, &domain.CartItem{UserID: 7, ProductID: 2, Quantity: 2})
+	stock := newFakeOrderStock(map[int64]bool{2: true})
+	svc := newTestOrderService(orders, carts, stock)
+
+	if _, err := svc.Checkout(context.Background(), 7, &domain.CheckoutRequest{}); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("Checkout() error = %v, want insufficient stock", err)
+	}
+	if want := []string{"reserve:1", "release:1"}; !slices.Equal(stock.calls, want) {
+		t.Errorf("stock calls = %v, want %v", stock.calls, want)
+	}
+	if len(orders.orders) != 0 || len(carts.items) != 2 {
+		t.Errorf("orders = %d, cart items = %d, want no order and untouched cart", len(orders.orders), len(carts.items))
+	}
+}
+
+func TestOrderService_CheckoutAndPay(t *testing.T) {
+	orders := newFakeOrderRepo()
+	carts := newFakeCartRepo(&domain.CartItem{UserID: 7, ProductID: 1, Quantity: 1}, &domain.CartItem{UserID: 7, ProductID: 2, Quantity: 2})
+	stock := newFakeOrderStock(nil)
+	svc := newTestOrderService(orders, carts, stock)
+	ctx := context.Background()
+
+	order, err := svc.Checkout(ctx, 7, &domain.CheckoutRequest{ProductIDs: []int64{2}})
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if order.Status != domain.OrderStatusPending || order.TotalAmount != 99 || order.ItemCount != 2 || order.ExpireAt == nil {
+		t.Fatalf("Checkout() order = %+v, want pending order of 99.00 with 2 items", order)
+	}
+	if len(carts.items) != 1 || carts.items[0].ProductID != 1 {
+		t.Errorf("cart items = %v, want only product 1 left", carts.items)
+	}
+
+	// 他人订单按不存在处理
+	if _, _, err := svc.PayOrder(ctx, order.ID, 8, false, &domain.PayOrderRequest{PaymentMethod: payment.SandboxProviderName, PaymentInfo: "tok"}); !errors.Is(err, domain.ErrOrderNotFound) {
+		t.Fatalf("PayOrder() by other user error = %v, want ErrOrderNotFound", err)
+	}
+
+	paid, intent, err := svc.PayOrder(ctx, order.ID, 7, false, &domain.PayOrderRequest{PaymentMethod: payment.SandboxProviderName, PaymentInfo: "tok"})
+	if err != nil {
+		t.Fatalf("PayOrder() error = %v", err)
+	}
+	if paid.Status != domain.OrderStatusPaid || paid.PaymentRef != intent.ID {
+		t.Errorf("PayOrder() order = %+v, want paid with payment ref %s", paid, intent.ID)
+	}
+	if want := []string{"reserve:2", "consume:2"}; !slices.Equal(stock.calls, want) {
+		t.Errorf("stock calls = %v, want %v", stock.calls, want)
+	}
+
+	if _, err := svc.CancelOrder(ctx, order.ID, 7, false); !errors.Is(err, domain.ErrOrderNotCancellable) {
+		t.Errorf("CancelOrder() on paid order error = %v, want ErrOrderNotCancellable", err)
+	}
+}
+
+// recordingSandbox 记录最近一次一步扣款的支付意图ID，沙箱意图ID随机生成，测试无法预知
+type recordingSandbox struct {
+	*payment.Sandbox
+	lastIntentID string
+}
+
+func (r *recordingSandbox) Charge(ctx context.Context, req *payment.IntentRequest, paymentToken string) (*payment.Intent, error) {
+	intent, err := r.Sandbox.Charge(ctx, req, paymentToken)
+	if intent != nil {
+		r.lastIntentID = intent.ID
+	}
+	return intent, err
+}
+
+func TestOrderService_PayRefundsWhenOrderNoLongerPending(t *testing.T) {
+	orders := newFakeOrderRepo()
+	carts := newFakeCartRepo(&domain.CartItem{UserID: 7, ProductID: 1, Quantity: 1})
+	stock := newFakeOrderStock(nil)
+	sandbox := &recordingSandbox{Sandbox: payment.NewSandbox("secret")}
+	svc := NewOrderService(orders, carts, newFakeOrderProducts(map[int64]*domain.Product{1: {ID: 1, Price: 10, Status: domain.ProductStatusActive}}), stock,
+		sandbox, "CNY", OwnershipPolicy{}, nil, nil)
+	ctx := context.Background()
+
+	order, err := svc.Checkout(ctx, 7, &domain.CheckoutRequest{})
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+
+	// 扣款期间订单被取消或过期
+	orders.rejectTransition = true
+	_, _, err = svc.PayOrder(ctx, order.ID, 7, false, &domain.PayOrderRequest{PaymentMethod: payment.SandboxProviderName, PaymentInfo: "tok"})
+	if !errors.Is(err, domain.ErrOrderNotPayable) {
+		t.Fatalf("PayOrder() error = %v, want ErrOrderNotPayable", err)
+	}
+	// 已全额退款的意图不能再次退款
+	if _, err := sandbox.Refund(ctx, sandbox.lastIntentID, 1); !errors.Is(err, payment.ErrInvalidState) {
+		t.Errorf("Refund() after rejected payment error = %v, want intent already refunded", err)
+	}
+	if want := []string{"reserve:1"}; !slices.Equal(stock.calls, want) {
+		t.Errorf("stock calls = %v, want %v", stock.calls, want)
+	}
+}
+
+func TestOrderService_CreateForSpikeOrderIsIdempotent(t *testing.T) {
+	orders := newFakeOrderRepo()
+	svc := newTestOrderService(orders, newFakeCartRepo(), newFakeOrderStock(nil))
+	paidAt := time.Now()
+	spikeOrder := &domain.SpikeOrder{ID: 42, OrderNo: "SO42", UserID: 7, Quantity: 2, SpikePrice: 9.9, PaidAt: &paidAt, PaymentRef: "pi_1"}
+	spikeEvent := &domain.SpikeEvent{ID: 3, ProductID: 1}
+
+	first, err := svc.CreateForSpikeOrder(context.Background(), spikeOrder, spikeEvent)
+	if err != nil {
+		t.Fatalf("CreateForSpikeOrder() error = %v", err)
+	}
+	if first.Status != domain.OrderStatusPaid || first.Source != domain.OrderSourceSpike || first.TotalAmount != 19.8 ||
+		first.OrderNo != "SO42" || first.Items[0].ProductName != "键盘" {
+		t.Fatalf("CreateForSpikeOrder() = %+v, want paid spike order of 19.80 for product 1", first)
+	}
+
+	second, err := svc.CreateForSpikeOrder(context.Background(), spikeOrder, spikeEvent)
+	if err != nil || second.ID != first.ID || len(orders.orders) != 1 {
+		t.Errorf("second CreateForSpikeOrder() = %d, %v, want existing order %d", second.ID, err, first.ID)
+	}
+}
+
+func TestOrderService_CreateForSpikeOrderRecordsVariant(t *testing.T) {
+	variants := newFakeVariantRepo()
+	variants.variants[5] = &domain.ProductVariant{ID: 5, ProductID: 1, SKU: "KB-1-RED", Name: "红轴", Active: true}
+	products := newFakeOrderProducts(map[int64]*domain.Product{1: {ID: 1, Name: "键盘", SKU: "KB-1", Price: 199.9, Status: domain.ProductStatusActive}})
+	svc := NewOrderService(newFakeOrderRepo(), newFakeCartRepo(), products, newFakeOrderStock(nil), payment.NewSandbox("secret"), "CNY",
+		OwnershipPolicy{HideForeign: true}, nil, nil, WithOrderVariants(variants))
+
+	variantID := int64(5)
+	spikeOrder := &domain.SpikeOrder{ID: 43, OrderNo: "SO43", UserID: 7, Quantity: 1, SpikePrice: 99}
+	order, err := svc.CreateForSpikeOrder(context.Background(), spikeOrder, &domain.SpikeEvent{ID: 3, ProductID: 1, VariantID: &variantID})
+	if err != nil {
+		t.Fatalf("CreateForSpikeOrder() error = %v", err)
+	}
+	item := order.Items[0]
+	if item.VariantID == nil || *item.VariantID != 5 || item.ProductName != "键盘 红轴" || item.SKU != "KB-1-RED" {
+		t.Errorf("item = %+v, want variant 5 snapshot", item)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
+)
+
+// OutboxStore 读写发件箱消息（由 repo.OutboxRepository 实现）
+type OutboxStore interface {
+	Create(ctx context.Context, message *domain.OutboxMessage) error
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxMessage, error)
+	MarkPublished(id int64, publishedAt time.Time) error
+	MarkRetry(id int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(id int64, attempts int, lastError string) error
+	DeletePublishedBefore(before time.Time, limit int) (int64, error)
+}
+
+// OutboxPublisher 发布发件箱中的消息（由 mq.SpikePublisher 实现）
+type OutboxPublisher interface {
+	PublishSpikeOrderCreated(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error
+	PublishSpikeOrderCancelled(ctx context.Context, data *mq.SpikeOrderCancelledData, traceID string) error
+}
+
+// OutboxRelayConfig 发件箱中继配置
+type OutboxRelayConfig struct {
+	Interval     time.Duration // 轮询间隔；写入消息后会立即唤醒，轮询只用于重试与兜底
+	BatchSize    int           // 每批领取的消息数
+	Lease        time.Duration // 领取后的租约时长，应大于发布一批消息的耗时
+	MaxAttempts  int           // 最大发布次数，超过后标记为 failed
+	RetryBackoff time.Duration // 首次重试间隔，之后每次翻倍
+	MaxBackoff   time.Duration // 重试间隔上限
+	Retention    time.Duration // 已发布消息的保留时长
+}
+
+// DefaultOutboxRelayConfig 默认发件箱中继配置
+func DefaultOutboxRelayConfig() *OutboxRelayConfig {
+	return &OutboxRelayConfig{
+		Interval:     time.Second,
+		BatchSize:    100,
+		Lease:        30 * time.Second,
+		MaxAttempts:  10,
+		RetryBackoff: time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+// outboxPurgeInterval 清理已发布消息的间隔
+const outboxPurgeInterval = time.Hour
+
+// OutboxRelay 把发件箱中的消息发布到消息队列。
+// 消息与业务数据在同一事务中落库，发布失败按指数退避重试，进程崩溃后由租约到期的其他实例接手，
+// 因此消息至少投递一次；消费者按消息数据中的幂等键去重。
+type OutboxRelay struct {
+	store     OutboxStore
+	publisher OutboxPublisher
+	config    *OutboxRelayConfig
+	wake      chan struct{}
+	logger    *zap.Logger
+}
+
+// NewOutboxRelay 创建发件箱中继，config 为空时使用默认配置
+func NewOutboxRelay(store OutboxStore, publisher OutboxPublisher, config *OutboxRelayConfig, logger *zap.Logger) *OutboxRelay {
+	if config == nil {
+		config = DefaultOutboxRelayConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		config:    config,
+		wake:      make(chan struct{}, 1),
+		logger:    logger,
+	}
+}
+
+// Enqueue 写入待发布消息并唤醒中继
+func (r *OutboxRelay) Enqueue(ctx context.Context, message *domain.OutboxMessage) error {
+	if err := r.store.Create(ctx, message); err != nil {
+		return err
+	}
+	r.Notify()
+	return nil
+}
+
+// Notify 唤醒中继立即发布，已有待处理的唤醒时不重复排队
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start 异步启动发件箱中继，ctx 取消时退出
+func (r *OutboxRelay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.logger.Info("发件箱中继已启动",
+			zap.Duration("interval", r.config.Interval),
+			zap.Int("batch_size", r.config.BatchSize))
+		lastPurge := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				r.logger.Info("发件箱中继已停止")
+				return
+			case <-ticker.C:
+			case <-r.wake:
+			}
+			r.RunOnce(ctx)
+			if r.config.Retention > 0 && time.Since(lastPurge) >= outboxPurgeInterval {
+				r.purge()
+				lastPurge = time.Now()
+			}
+		}
+	}()
+}
+
+// RunOnce 发布当前所有到期的消息，返回发布成功的条数
+func (r *OutboxRelay) RunOnce(ctx context.Context) int {
+	total := 0
+	for ctx.Err() == nil {
+		messages, err := r.store.ClaimDue(time.Now(), r.config.Lease, r.config.BatchSize)
+		if err != nil {
+			r.logger.Warn("领取发件箱消息失败", zap.Error(err))
+			break
+		}
+
+		for _, message := range messages {
+			if ctx.Err() != nil {
+				break
+			}
+			if r.relay(ctx, message) {
+				total++
+			}
+		}
+
+		// 不满一批说明已处理完，失败的消息已顺延到退避时间之后，不会在本轮被重复领取
+		if len(messages) < r.config.BatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		r.logger.Debug("已发布发件箱消息", zap.Int("count", total))
+	}
+	return total
+}
+
+// relay 发布单条消息并记录结果，返回是否发布成功
+func (r *OutboxRelay) relay(ctx context.Context, message *domain.OutboxMessage) bool {
+	logger := r.logger.With(
+		zap.Int64("outbox_id", message.ID),
+		zap.String("message_type", message.MessageType),
+		zap.String("trace_id", message.TraceID))
+
+	err := r.publish(ctx, message)
+	if err == nil {
+		if err := r.store.MarkPublished(message.ID, time.Now()); err != nil {
+			// 租约到期后会被重新发布，由消费者按幂等键去重
+			logger.Warn("标记发件箱消息已发布失败", zap.Error(err))
+		}
+		return true
+	}
+
+	attempts := message.Attempts + 1
+	if attempts >= r.config.MaxAttempts {
+		logger.Error("发件箱消息发布失败且已达最大重试次数，需人工处理",
+			zap.Int("attempts", attempts), zap.Error(err))
+		if markErr := r.store.MarkFailed(message.ID, attempts, err.Error()); markErr != nil {
+			logger.Warn("标记发件箱消息失败状态失败", zap.Error(markErr))
+		}
+		return false
+	}
+
+	nextAttemptAt := time.Now().Add(r.backoff(attempts))
+	logger.Warn("发件箱消息发布失败，稍后重试",
+		zap.Int("attempts", attempts), zap.Time("next_attempt_at", nextAttemptAt), zap.Error(err))
+	if markErr := r.store.MarkRetry(message.ID, attempts, nextAttemptAt, err.Error()); markErr != nil {
+		logger.Warn("记录发件箱消息重试失败", zap.Error(markErr))
+	}
+	return false
+}
+
+// publish 按消息类型解析数据并发布
+func (r *OutboxRelay) publish(ctx context.Context, message *domain.OutboxMessage) error {
+	ctx = tracing.WithTraceID(ctx, message.TraceID)
+	switch mq.MessageType(message.MessageType) {
+	case mq.MessageTypeSpikeOrderCreated:
+		var data mq.SpikeOrderCreatedData
+		if err := json.Unmarshal(message.Payload, &data); err != nil {
+			return fmt.Errorf("failed to unmarshal outbox payload: %w", err)
+		}
+		return r.publisher.PublishSpikeOrderCreated(ctx, &data, message.TraceID)
+	case mq.MessageTypeSpikeOrderCancelled:
+		var data mq.SpikeOrderCancelledData
+		if err := json.Unmarshal(message.Payload, &data); err != nil {
+			return fmt.Errorf("failed to unmarshal outbox payload: %w", err)
+		}
+		return r.publisher.PublishSpikeOrderCancelled(ctx, &data, message.TraceID)
+	default:
+		return fmt.Errorf("unsupported outbox message type: %s", message.MessageType)
+	}
+}
+
+// backoff 返回第 attempts 次失败后的重试间隔
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := r.config.RetryBackoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxBackoff)
+}
+
+// purge 清理超过保留时长的已发布消息
+func (r *OutboxRelay) purge() {
+	before := time.Now().Add(-r.config.Retention)
+	for {
+		deleted, err := r.store.DeletePublishedBefore(before, r.config.BatchSize)
+		if err != nil {
+			r.logger.Warn("清理已发布的发件箱消息失败", zap.Error(err))
+			return
+		}
+		if deleted < int64(r.config.BatchSize) {
+			return
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// memoryOutboxStore 内存中的发件箱，领取时按下次发布时间过滤并顺延租约
+type memoryOutboxStore struct {
+	mu       sync.Mutex
+	messages map[int64]*domain.OutboxMessage
+	nextID   int64
+}
+
+func newMemoryOutboxStore() *memoryOutboxStore {
+	return &memoryOutboxStore{messages: make(map[int64]*domain.OutboxMessage)}
+}
+
+func (s *memoryOutboxStore) Create(ctx context.Context, message *domain.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	message.ID = s.nextID
+	message.Status = domain.OutboxStatusPending
+	copied := *message
+	s.messages[message.ID] = &copied
+	return nil
+}
+
+func (s *memoryOutboxStore) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.messages))
+	for id, message := range s.messages {
+		if message.Status == domain.OutboxStatusPending && !message.NextAttemptAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	claimed := make([]*domain.OutboxMessage, 0, len(ids))
+	for _, id := range ids {
+		s.messages[id].NextAttemptAt = now.Add(lease)
+		copied := *s.messages[id]
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (s *memoryOutboxStore) MarkPublished(id int64, publishedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[id].Status = domain.OutboxStatusPublished
+	s.messages[id].PublishedAt = &publishedAt
+	return nil
+}
+
+func (s *memoryOutboxStore) MarkRetry(id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[id].Attempts = attempts
+	s.messages[id].NextAttemptAt = nextAttemptAt
+	s.messages[id].LastError = lastError
+	return nil
+}
+
+func (s *memoryOutboxStore) MarkFailed(id int64, attempts int, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[id].Status = domain.OutboxStatusFailed
+	s.messages[id].Attempts = attempts
+	s.messages[id].LastError = lastError
+	return nil
+}
+
+func (s *memoryOutboxStore) DeletePublishedBefore(before time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+func (s *memoryOutboxStore) get(id int64) domain.OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.messages[id]
+}
+
+// expireRetries 把等待重试的消息提前到现在，模拟退避时间已过
+func (s *memoryOutboxStore) expireRetries() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, message := range s.messages {
+		message.NextAttemptAt = time.Time{}
+	}
+}
+
+func TestOutboxRelay_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryOutboxStore()
+	producer := NewMockSpikeProducer()
+	config := DefaultOutboxRelayConfig()
+	config.BatchSize = 2
+	config.MaxAttempts = 2
+	relay := NewOutboxRelay(store, producer, config, nil)
+
+	for userID := int64(1); userID <= 3; userID++ {
+		message, err := domain.NewOutboxMessage(string(mq.MessageTypeSpikeOrderCreated),
+			&mq.SpikeOrderCreatedData{SpikeEventID: 7, UserID: userID, Quantity: 1, IdempotencyKey: "k"}, "trace-1")
+		if err != nil {
+			t.Fatalf("NewOutboxMessage() error = %v", err)
+		}
+		if err := relay.Enqueue(context.Background(), message); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	// 发布失败时按退避时间重试，退避期内不会被再次领取
+	producer.SetShouldFail(true)
+	if n := relay.RunOnce(ctx); n != 0 {
+		t.Fatalf("RunOnce() with failing publisher = %d, want 0", n)
+	}
+	if m := store.get(1); m.Status != domain.OutboxStatusPending || m.Attempts != 1 || m.LastError == "" ||
+		!m.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("message after failed publish = %+v, want pending retry", m)
+	}
+	if n := relay.RunOnce(ctx); n != 0 {
+		t.Fatalf("RunOnce() during backoff = %d, want 0", n)
+	}
+
+	// 批量大小为 2 时分两批发布完 3 条消息
+	producer.SetShouldFail(false)
+	store.expireRetries()
+	if n := relay.RunOnce(ctx); n != 3 {
+		t.Fatalf("RunOnce() = %d, want 3", n)
+	}
+	published := producer.GetPublishedMessages()
+	if len(published) != 3 {
+		t.Fatalf("published %d messages, want 3", len(published))
+	}
+	if data := published[0].(*mq.SpikeOrderCreatedData); data.UserID != 1 || data.IdempotencyKey != "k" {
+		t.Errorf("published data = %+v, want user 1 with idempotency key", data)
+	}
+	for id := int64(1); id <= 3; id++ {
+		if m := store.get(id); m.Status != domain.OutboxStatusPublished {
+			t.Errorf("message %d status = %s, want published", id, m.Status)
+		}
+	}
+
+	// 无法识别的消息类型达到最大次数后标记为 failed
+	unknown := &domain.OutboxMessage{MessageType: "unknown", Payload: []byte(`{}`)}
+	_ = relay.Enqueue(context.Background(), unknown)
+	relay.RunOnce(ctx)
+	store.expireRetries()
+	relay.RunOnce(ctx)
+	if m := store.get(unknown.ID); m.Status != domain.OutboxStatusFailed || m.Attempts != 2 {
+		t.Errorf("unknown message = %+v, want failed after 2 attempts", m)
+	}
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := NewOutboxRelay(newMemoryOutboxStore(), NewMockSpikeProducer(), &OutboxRelayConfig{
+		RetryBackoff: time.Second,
+		MaxBackoff:   5 * time.Second,
+	}, nil)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{20, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := relay.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import "github.com/MorseWayne/spike_shop/internal/domain"
+
+// OwnershipPolicy 资源所有权校验策略，统一订单类接口访问他人资源时的行为
+type OwnershipPolicy struct {
+	// HideForeign 为 true 时访问他人资源与资源不存在一样返回 notFound（404），
+	// 避免通过遍历ID探测他人资源是否存在；为 false 时返回 domain.ErrForbidden（403）
+	HideForeign bool `json:"hide_foreign"`
+}
+
+// Authorize 校验资源所有权：管理员可访问任意资源，其他用户只能访问自己的资源。
+// notFound 为该资源的不存在错误，需满足 errors.Is(notFound, domain.ErrNotFound)
+func (p OwnershipPolicy) Authorize(ownerID, userID int64, isAdmin bool, notFound error) error {
+	if isAdmin || ownerID == userID {
+		return nil
+	}
+	if p.HideForeign {
+		return notFound
+	}
+	return domain.ErrForbidden
+}
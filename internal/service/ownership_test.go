@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func TestOwnershipPolicy_Authorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OwnershipPolicy
+		ownerID int64
+		userID  int64
+		isAdmin bool
+		wantErr error
+	}{
+		{name: "owner", ownerID: 1, userID: 1},
+		{name: "admin", ownerID: 1, userID: 2, isAdmin: true},
+		{name: "foreign forbidden", ownerID: 1, userID: 2, wantErr: domain.ErrForbidden},
+		{name: "foreign hidden", policy: OwnershipPolicy{HideForeign: true}, ownerID: 1, userID: 2, wantErr: domain.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Authorize(tt.ownerID, tt.userID, tt.isAdmin, domain.ErrSpikeOrderNotFound)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Authorize() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// PaymentReminderSource 提供即将过期的待支付订单（由 repo.SpikeOrderRepository 实现）
+type PaymentReminderSource interface {
+	GetPendingOrdersExpiringBetween(ctx context.Context, from, to time.Time) ([]*domain.SpikeOrder, error)
+}
+
+// PaymentReminderDeduper 按订单和提醒档位去重（由 cache.SpikeCache 实现）
+type PaymentReminderDeduper interface {
+	MarkPaymentReminderSent(ctx context.Context, orderID int64, offset, ttl time.Duration) (bool, error)
+}
+
+// PaymentReminderConfig 支付提醒配置
+type PaymentReminderConfig struct {
+	Offsets      []time.Duration // 在订单过期前多久发送提醒，每个档位每个订单只提醒一次
+	ScanInterval time.Duration   // 扫描即将过期订单的间隔
+}
+
+// DefaultPaymentReminderConfig 默认支付提醒配置
+func DefaultPaymentReminderConfig() *PaymentReminderConfig {
+	return &PaymentReminderConfig{
+		Offsets:      []time.Duration{10 * time.Minute, 2 * time.Minute},
+		ScanInterval: 30 * time.Second,
+	}
+}
+
+// PaymentReminder 定时扫描待支付订单，在过期前的各个档位发送支付提醒。
+// 每轮只为订单发送其剩余时间所处的最近档位，错过的较早档位不再补发；
+// 去重标记保存在 Redis 中，多实例同时扫描也只会发送一次。
+type PaymentReminder struct {
+	orders   PaymentReminderSource
+	deduper  PaymentReminderDeduper
+	notifier mq.NotificationPublisher
+	config   *PaymentReminderConfig
+	logger   *zap.Logger
+
+	offsets []time.Duration // 升序排列的提醒档位
+}
+
+// NewPaymentReminder 创建支付提醒调度器
+func NewPaymentReminder(orders PaymentReminderSource, deduper PaymentReminderDeduper, notifier mq.NotificationPublisher, config *PaymentReminderConfig, logger *zap.Logger) *PaymentReminder {
+	if config == nil {
+		config = DefaultPaymentReminderConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	offsets := make([]time.Duration, 0, len(config.Offsets))
+	for _, offset := range config.Offsets {
+		if offset > 0 && !slices.Contains(offsets, offset) {
+			offsets = append(offsets, offset)
+		}
+	}
+	slices.Sort(offsets)
+
+	return &PaymentReminder{
+		orders:   orders,
+		deduper:  deduper,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+		offsets:  offsets,
+	}
+}
+
+// Start 异步启动提醒循环，ctx 取消时退出
+func (p *PaymentReminder) Start(ctx context.Context) {
+	if len(p.offsets) == 0 {
+		p.logger.Warn("未配置支付提醒档位，支付提醒未启动")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.config.ScanInterval)
+		defer ticker.Stop()
+
+		p.logger.Info("支付提醒已启动",
+			zap.Durations("offsets", p.offsets),
+			zap.Duration("interval", p.config.ScanInterval))
+		for {
+			select {
+			case <-ctx.Done():
+				p.logger.Info("支付提醒已停止")
+				return
+			case <-ticker.C:
+				p.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 执行一轮扫描，返回本轮发送的提醒数量
+func (p *PaymentReminder) RunOnce(ctx context.Context) int {
+	if len(p.offsets) == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	orders, err := p.orders.GetPendingOrdersExpiringBetween(ctx, now, now.Add(p.offsets[len(p.offsets)-1]))
+	if err != nil {
+		p.logger.Warn("查询即将过期的订单失败", zap.Error(err))
+		return 0
+	}
+
+	sent := 0
+	for _, order := range orders {
+		if ctx.Err() != nil {
+			break
+		}
+		if order.ExpireAt == nil {
+			continue
+		}
+		remaining := order.ExpireAt.Sub(now)
+		offset, ok := p.offsetFor(remaining)
+		if !ok {
+			continue
+		}
+
+		// 去重标记保留到订单过期之后，避免同一档位在后续扫描中重复发送
+		marked, err := p.deduper.MarkPaymentReminderSent(ctx, order.ID, offset, remaining+p.config.ScanInterval)
+		if err != nil {
+			p.logger.Warn("标记支付提醒失败", zap.Int64("spike_order_id", order.ID), zap.Error(err))
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		if err := p.notify(ctx, order, remaining); err != nil {
+			p.logger.Warn("发送支付提醒失败", zap.Int64("spike_order_id", order.ID), zap.Error(err))
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		p.logger.Info("支付提醒发送完成", zap.Int("sent", sent))
+	}
+	return sent
+}
+
+// offsetFor 返回剩余时间所处的最近提醒档位，即不小于剩余时间的最小档位
+func (p *PaymentReminder) offsetFor(remaining time.Duration) (time.Duration, bool) {
+	if remaining <= 0 {
+		return 0, false
+	}
+	for _, offset := range p.offsets {
+		if remaining <= offset {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// notify 发布支付提醒通知
+func (p *PaymentReminder) notify(ctx context.Context, order *domain.SpikeOrder, remaining time.Duration) error {
+	minutes := int64((remaining + time.Minute - 1) / time.Minute)
+	return p.notifier.PublishNotification(ctx, &mq.NotificationData{
+		UserID:  order.UserID,
+		Type:    "spike_order_payment_reminder",
+		Title:   "订单即将过期",
+		Content: fmt.Sprintf("您的秒杀订单将在%d分钟内过期，请尽快完成支付", minutes),
+		Data: map[string]interface{}{
+			"spike_order_id":     order.ID,
+			"expire_at":          order.ExpireAt,
+			"expires_in_seconds": int64(remaining / time.Second),
+		},
+		Priority: "high",
+		Channels: []string{"push"},
+		ExpireAt: order.ExpireAt,
+	}, "")
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// fakeReminderDeduper 在内存中记录已发送的提醒档位
+type fakeReminderDeduper struct {
+	mu   sync.Mutex
+	sent map[[2]int64]bool
+}
+
+func (f *fakeReminderDeduper) MarkPaymentReminderSent(ctx context.Context, orderID int64, offset, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := [2]int64{orderID, int64(offset)}
+	if f.sent[key] {
+		return false, nil
+	}
+	f.sent[key] = true
+	return true, nil
+}
+
+// fakeNotificationPublisher 记录发布的通知
+type fakeNotificationPublisher struct {
+	mu            sync.Mutex
+	notifications []*mq.NotificationData
+}
+
+func (f *fakeNotificationPublisher) PublishNotification(ctx context.Context, data *mq.NotificationData, traceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.notifications = append(f.notifications, data)
+	return nil
+}
+
+func TestPaymentReminder_RunOnce(t *testing.T) {
+	orders := NewMockSpikeOrderRepository()
+	now := time.Now()
+	expireSoon := now.Add(90 * time.Second)
+	expireLater := now.Add(5 * time.Minute)
+	expireFar := now.Add(30 * time.Minute)
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{UserID: 1, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireSoon})
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{UserID: 2, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireLater})
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{UserID: 3, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireFar})
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{UserID: 4, Status: domain.SpikeOrderStatusPaid, ExpireAt: &expireSoon})
+
+	deduper := &fakeReminderDeduper{sent: make(map[[2]int64]bool)}
+	notifier := &fakeNotificationPublisher{}
+	reminder := NewPaymentReminder(orders, deduper, notifier, &PaymentReminderConfig{
+		Offsets:      []time.Duration{2 * time.Minute, 10 * time.Minute},
+		ScanInterval: time.Second,
+	}, nil)
+
+	if sent := reminder.RunOnce(context.Background()); sent != 2 {
+		t.Fatalf("first RunOnce() sent = %d, want 2", sent)
+	}
+	if sent := reminder.RunOnce(context.Background()); sent != 0 {
+		t.Fatalf("second RunOnce() sent = %d, want 0 (deduplicated)", sent)
+	}
+
+	for _, n := range notifier.notifications {
+		if n.UserID != 1 && n.UserID != 2 {
+			t.Errorf("unexpected reminder for user %d", n.UserID)
+		}
+		if _, ok := n.Data["expires_in_seconds"]; !ok {
+			t.Errorf("reminder for user %d missing expires_in_seconds", n.UserID)
+		}
+	}
+	if !deduper.sent[[2]int64{1, int64(2 * time.Minute)}] || !deduper.sent[[2]int64{2, int64(10 * time.Minute)}] {
+		t.Errorf("reminders marked = %v, want order 1 at 2m and order 2 at 10m", deduper.sent)
+	}
+}
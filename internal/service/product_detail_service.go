@@ -0,0 +1,190 @@
+// Package service 提供商品详情页的聚合查询服务。
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// ErrProductNotFound 商品不存在
+var ErrProductNotFound = domain.NewNotFoundError("product not found")
+
+// minProductDetailTTL 聚合缓存的最短有效期，避免活动切换时刻附近频繁回源
+const minProductDetailTTL = time.Second
+
+// ProductSpikeEventSource 商品详情聚合所需的秒杀活动查询
+type ProductSpikeEventSource interface {
+	GetCurrentActiveEventByProductID(ctx context.Context, productID int64) (*domain.SpikeEvent, error)
+	GetByProductID(ctx context.Context, productID int64) ([]*domain.SpikeEvent, error)
+}
+
+// ProductDetailService 定义商品详情聚合服务接口
+type ProductDetailService interface {
+	// GetProductDetail 返回商品、库存可用性与当前或即将开始的秒杀活动，整体缓存
+	GetProductDetail(ctx context.Context, productID int64) (*domain.ProductDetail, error)
+	// Invalidate 清除商品详情缓存，用于活动等不经缓存仓储的数据变更
+	Invalidate(ctx context.Context, productID int64)
+}
+
+// productDetailService 是ProductDetailService接口的实现
+type productDetailService struct {
+	productRepo   repo.ProductRepository
+	inventoryRepo repo.InventoryRepository
+	events        ProductSpikeEventSource
+	cache         cache.Cache
+	ttl           time.Duration
+	logger        *zap.Logger
+}
+
+// NewProductDetailService 创建商品详情聚合服务。
+// 商品与库存变更由缓存仓储清除聚合缓存；活动状态切换（开始、结束、预告可见）
+// 通过将缓存有效期截断到下一个切换时刻保证及时生效。
+func NewProductDetailService(
+	productRepo repo.ProductRepository,
+	inventoryRepo repo.InventoryRepository,
+	events ProductSpikeEventSource,
+	detailCache cache.Cache,
+	ttl time.Duration,
+	logger *zap.Logger,
+) ProductDetailService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &productDetailService{
+		productRepo:   productRepo,
+		inventoryRepo: inventoryRepo,
+		events:        events,
+		cache:         detailCache,
+		ttl:           ttl,
+		logger:        logger,
+	}
+}
+
+// GetProductDetail 获取商品详情聚合数据
+func (s *productDetailService) GetProductDetail(ctx context.Context, productID int64) (*domain.ProductDetail, error) {
+	key := repo.ProductDetailCacheKey(productID)
+	if s.cache != nil && s.ttl > 0 {
+		var detail domain.ProductDetail
+		if err := s.cache.Get(ctx, key, &detail); err == nil {
+			return &detail, nil
+		}
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	detail := &domain.ProductDetail{Product: product}
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory: %w", err)
+	}
+	if inventory != nil {
+		detail.AvailableStock = max(inventory.AvailableStock(), 0)
+		detail.Available = product.IsAvailable() && detail.AvailableStock > 0
+	}
+
+	now := time.Now()
+	nextChange, err := s.attachSpikeEvent(ctx, detail, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil && s.ttl > 0 {
+		ttl := s.ttl
+		if !nextChange.IsZero() {
+			ttl = min(ttl, max(nextChange.Sub(now), minProductDetailTTL))
+		}
+		if err := s.cache.Set(ctx, key, detail, ttl); err != nil {
+			s.logger.Warn("缓存商品详情失败", zap.Int64("product_id", productID), zap.Error(err))
+		}
+	}
+
+	return detail, nil
+}
+
+// attachSpikeEvent 填充进行中或即将开始的秒杀活动，返回最近一次活动状态切换的时间（无切换时为零值）
+func (s *productDetailService) attachSpikeEvent(ctx context.Context, detail *domain.ProductDetail, now time.Time) (time.Time, error) {
+	if s.events == nil {
+		return time.Time{}, nil
+	}
+	productID := detail.Product.ID
+
+	active, err := s.events.GetCurrentActiveEventByProductID(ctx, productID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get active spike event: %w", err)
+	}
+	if active != nil {
+		detail.Spike = &domain.ProductSpikeInfo{
+			Event:          active,
+			State:          domain.ProductSpikeStateActive,
+			RemainingStock: active.GetRemainingStock(),
+		}
+		return active.EndAt, nil
+	}
+
+	events, err := s.events.GetByProductID(ctx, productID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get spike events: %w", err)
+	}
+
+	var upcoming *domain.SpikeEvent
+	var nextChange time.Time
+	for _, event := range events {
+		if event.Status != domain.SpikeEventStatusPending && event.Status != domain.SpikeEventStatusActive {
+			continue
+		}
+		if !event.StartAt.After(now) {
+			continue
+		}
+		if !event.IsVisible() {
+			// 预告开始后活动变为可见
+			nextChange = earliest(nextChange, *event.PreviewStartAt)
+			continue
+		}
+		if upcoming == nil || event.StartAt.Before(upcoming.StartAt) {
+			upcoming = event
+		}
+	}
+
+	if upcoming != nil {
+		detail.Spike = &domain.ProductSpikeInfo{
+			Event:          upcoming,
+			State:          domain.ProductSpikeStateUpcoming,
+			RemainingStock: upcoming.GetRemainingStock(),
+		}
+		nextChange = earliest(nextChange, upcoming.StartAt)
+	}
+
+	return nextChange, nil
+}
+
+// Invalidate 清除商品详情缓存
+func (s *productDetailService) Invalidate(ctx context.Context, productID int64) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Del(ctx, repo.ProductDetailCacheKey(productID)); err != nil {
+		s.logger.Warn("清除商品详情缓存失败", zap.Int64("product_id", productID), zap.Error(err))
+	}
+}
+
+// earliest 返回两个时间中较早的一个，零值视为未设置
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// fakeProductSpikeEvents 按商品返回固定的秒杀活动
+type fakeProductSpikeEvents struct {
+	active *domain.SpikeEvent
+	events []*domain.SpikeEvent
+}
+
+func (f *fakeProductSpikeEvents) GetCurrentActiveEventByProductID(ctx context.Context, productID int64) (*domain.SpikeEvent, error) {
+	return f.active, nil
+}
+
+func (f *fakeProductSpikeEvents) GetByProductID(ctx context.Context, productID int64) ([]*domain.SpikeEvent, error) {
+	return f.events, nil
+}
+
+func TestProductDetailService_GetProductDetail(t *testing.T) {
+	productRepo := newMockProductRepository()
+	inventoryRepo := newMockInventoryRepository()
+	productRepo.products[1] = &domain.Product{ID: 1, Name: "phone", Status: domain.ProductStatusActive}
+	inventory := &domain.Inventory{ID: 1, ProductID: 1, Stock: 10, ReservedStock: 4, MaxStock: 100}
+	inventoryRepo.inventories[1] = inventory
+	inventoryRepo.productMap[1] = inventory
+
+	now := time.Now()
+	hiddenPreview := now.Add(time.Hour)
+	events := &fakeProductSpikeEvents{events: []*domain.SpikeEvent{
+		// 预告未开始，不可见
+		{ID: 3, ProductID: 1, Status: domain.SpikeEventStatusPending, PreviewStartAt: &hiddenPreview, StartAt: now.Add(90 * time.Minute), EndAt: now.Add(2 * time.Hour)},
+		{ID: 2, ProductID: 1, Status: domain.SpikeEventStatusPending, SpikeStock: 50, StartAt: now.Add(3 * time.Hour), EndAt: now.Add(4 * time.Hour)},
+		// 已取消
+		{ID: 1, ProductID: 1, Status: domain.SpikeEventStatusCancelled, StartAt: now.Add(30 * time.Minute), EndAt: now.Add(time.Hour)},
+	}}
+
+	svc := NewProductDetailService(productRepo, inventoryRepo, events, cache.NewMemoryCache(), time.Minute, nil)
+
+	detail, err := svc.GetProductDetail(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetProductDetail() error = %v", err)
+	}
+	if !detail.Available || detail.AvailableStock != 6 {
+		t.Errorf("availability = %v/%d, want true/6", detail.Available, detail.AvailableStock)
+	}
+	if detail.Spike == nil || detail.Spike.Event.ID != 2 || detail.Spike.State != domain.ProductSpikeStateUpcoming {
+		t.Fatalf("spike = %+v, want upcoming event 2", detail.Spike)
+	}
+
+	// 活动开始后，清除缓存即可看到进行中的活动
+	events.active = &domain.SpikeEvent{ID: 2, ProductID: 1, Status: domain.SpikeEventStatusActive, SpikeStock: 50, SoldCount: 20, StartAt: now, EndAt: now.Add(time.Hour)}
+	cached, _ := svc.GetProductDetail(context.Background(), 1)
+	if cached.Spike.State != domain.ProductSpikeStateUpcoming {
+		t.Errorf("cached spike state = %s, want upcoming", cached.Spike.State)
+	}
+
+	svc.Invalidate(context.Background(), 1)
+	detail, err = svc.GetProductDetail(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetProductDetail() error = %v", err)
+	}
+	if detail.Spike.State != domain.ProductSpikeStateActive || detail.Spike.RemainingStock != 30 {
+		t.Errorf("spike = %+v, want active with 30 remaining", detail.Spike)
+	}
+
+	if _, err := svc.GetProductDetail(context.Background(), 999); err != ErrProductNotFound {
+		t.Errorf("GetProductDetail(999) error = %v, want ErrProductNotFound", err)
+	}
+}
@@ -2,7 +2,7 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"fmt"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
@@ -16,15 +16,15 @@ type ProductService interface {
 	GetProduct(id int64) (*domain.Product, error)
 	GetProductBySKU(sku string) (*domain.Product, error)
 	UpdateProduct(id int64, req *domain.UpdateProductRequest) (*domain.Product, error)
-	DeleteProduct(id int64) error
+	DeleteProduct(ctx context.Context, id int64) error
 
 	// 商品查询
 	ListProducts(req *domain.ProductListRequest) (*domain.ProductListResponse, error)
-	GetProductsWithInventory(ids []int64) ([]*domain.ProductWithInventory, error)
+	GetProductsWithInventory(ctx context.Context, ids []int64) ([]*domain.ProductWithInventory, error)
 	SearchProducts(keyword string, page, pageSize int) (*domain.ProductListResponse, error)
 
 	// 商品统计
-	GetProductStats() (*ProductStats, error)
+	GetProductStats(ctx context.Context) (*ProductStats, error)
 }
 
 // ProductStats 商品统计信息
@@ -58,7 +58,16 @@ func (s *productService) CreateProduct(req *domain.CreateProductRequest) (*domai
 		return nil, fmt.Errorf("failed to check SKU uniqueness: %w", err)
 	}
 	if existing != nil {
-		return nil, errors.New("SKU already exists")
+		return nil, domain.NewConflictError("SKU already exists")
+	}
+
+	// 新商品只能直接上架或保存为草稿
+	status := domain.ProductStatusActive
+	if req.Status != nil {
+		if *req.Status != domain.ProductStatusActive && *req.Status != domain.ProductStatusDraft {
+			return nil, fmt.Errorf("%w: initial status must be active or draft", domain.ErrInvalidProductStatus)
+		}
+		status = *req.Status
 	}
 
 	// 创建商品实体
@@ -69,7 +78,7 @@ func (s *productService) CreateProduct(req *domain.CreateProductRequest) (*domai
 		CategoryID:  req.CategoryID,
 		Brand:       req.Brand,
 		SKU:         req.SKU,
-		Status:      domain.ProductStatusActive,
+		Status:      status,
 		Weight:      req.Weight,
 		ImageURL:    req.ImageURL,
 	}
@@ -90,7 +99,7 @@ func (s *productService) GetProduct(id int64) (*domain.Product, error) {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 	if product == nil {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
 	}
 
 	return product, nil
@@ -103,7 +112,7 @@ func (s *productService) GetProductBySKU(sku string) (*domain.Product, error) {
 		return nil, fmt.Errorf("failed to get product by SKU: %w", err)
 	}
 	if product == nil {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
 	}
 
 	return product, nil
@@ -117,7 +126,7 @@ func (s *productService) UpdateProduct(id int64, req *domain.UpdateProductReques
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 	if product == nil {
-		return nil, errors.New("product not found")
+		return nil, ErrProductNotFound
 	}
 
 	// 更新字段
@@ -129,7 +138,7 @@ func (s *productService) UpdateProduct(id int64, req *domain.UpdateProductReques
 	}
 	if req.Price != nil {
 		if *req.Price <= 0 {
-			return nil, errors.New("price must be greater than 0")
+			return nil, domain.NewInvalidArgumentError("price must be greater than 0")
 		}
 		product.Price = *req.Price
 	}
@@ -140,6 +149,12 @@ func (s *productService) UpdateProduct(id int64, req *domain.UpdateProductReques
 		product.Brand = *req.Brand
 	}
 	if req.Status != nil {
+		if !req.Status.IsValid() {
+			return nil, domain.ErrInvalidProductStatus
+		}
+		if !product.Status.CanTransitionTo(*req.Status) {
+			return nil, fmt.Errorf("%w: %s -> %s", domain.ErrProductStatusTransition, product.Status, *req.Status)
+		}
 		product.Status = *req.Status
 	}
 	if req.Weight != nil {
@@ -159,23 +174,23 @@ func (s *productService) UpdateProduct(id int64, req *domain.UpdateProductReques
 }
 
 // DeleteProduct 删除商品
-func (s *productService) DeleteProduct(id int64) error {
+func (s *productService) DeleteProduct(ctx context.Context, id int64) error {
 	// 检查商品是否存在
 	product, err := s.productRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("failed to get product: %w", err)
 	}
 	if product == nil {
-		return errors.New("product not found")
+		return ErrProductNotFound
 	}
 
 	// 检查是否有库存
-	inventory, err := s.inventoryRepo.GetByProductID(id)
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get inventory: %w", err)
 	}
 	if inventory != nil && (inventory.Stock > 0 || inventory.ReservedStock > 0) {
-		return errors.New("cannot delete product with existing stock")
+		return domain.NewConflictError("cannot delete product with existing stock")
 	}
 
 	// 软删除商品
@@ -200,17 +215,19 @@ func (s *productService) ListProducts(req *domain.ProductListRequest) (*domain.P
 	if err != nil {
 		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
+	products, pageInfo := domain.Paginate(products, total, req.Page, req.PageSize)
 
 	return &domain.ProductListResponse{
 		Products: products,
 		Total:    total,
 		Page:     req.Page,
 		PageSize: req.PageSize,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // GetProductsWithInventory 获取带库存信息的商品列表
-func (s *productService) GetProductsWithInventory(ids []int64) ([]*domain.ProductWithInventory, error) {
+func (s *productService) GetProductsWithInventory(ctx context.Context, ids []int64) ([]*domain.ProductWithInventory, error) {
 	// 获取商品信息
 	products, err := s.productRepo.GetByIDs(ids)
 	if err != nil {
@@ -218,7 +235,7 @@ func (s *productService) GetProductsWithInventory(ids []int64) ([]*domain.Produc
 	}
 
 	// 获取库存信息
-	inventories, err := s.inventoryRepo.GetByProductIDs(ids)
+	inventories, err := s.inventoryRepo.GetByProductIDs(ctx, ids)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inventories: %w", err)
 	}
@@ -254,7 +271,7 @@ func (s *productService) SearchProducts(keyword string, page, pageSize int) (*do
 }
 
 // GetProductStats 获取商品统计信息
-func (s *productService) GetProductStats() (*ProductStats, error) {
+func (s *productService) GetProductStats(ctx context.Context) (*ProductStats, error) {
 	// 获取商品总数
 	totalProducts, err := s.productRepo.Count()
 	if err != nil {
@@ -273,7 +290,7 @@ func (s *productService) GetProductStats() (*ProductStats, error) {
 	}
 
 	// 获取总库存价值
-	totalValue, err := s.inventoryRepo.GetTotalStockValue()
+	totalValue, err := s.inventoryRepo.GetTotalStockValue(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total stock value: %w", err)
 	}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
 )
 
 // Test cases for ProductService
@@ -158,6 +162,44 @@ func TestProductService_UpdateProduct(t *testing.T) {
 	}
 }
 
+func TestProductService_UpdateProductStatus(t *testing.T) {
+	productRepo := newMockProductRepository()
+	service := NewProductService(productRepo, newMockInventoryRepository())
+
+	draft := domain.ProductStatusDraft
+	product, err := service.CreateProduct(&domain.CreateProductRequest{Name: "Draft", Price: 1, SKU: "DRAFT-001", Status: &draft})
+	if err != nil {
+		t.Fatalf("CreateProduct(draft) error = %v", err)
+	}
+	if product.Status != domain.ProductStatusDraft {
+		t.Errorf("status = %s, want draft", product.Status)
+	}
+
+	paused := domain.ProductStatusPaused
+	if _, err := service.CreateProduct(&domain.CreateProductRequest{Name: "Paused", Price: 1, SKU: "PAUSED-001", Status: &paused}); !errors.Is(err, domain.ErrInvalidProductStatus) {
+		t.Errorf("CreateProduct(paused) err = %v, want ErrInvalidProductStatus", err)
+	}
+
+	steps := []struct {
+		status  domain.ProductStatus
+		wantErr error
+	}{
+		{domain.ProductStatusActive, nil},
+		{domain.ProductStatusPaused, nil},
+		{"unknown", domain.ErrInvalidProductStatus},
+		{domain.ProductStatusDiscontinued, nil},
+		{domain.ProductStatusActive, domain.ErrProductStatusTransition},
+		{domain.ProductStatusDeleted, nil},
+	}
+	for _, step := range steps {
+		status := step.status
+		_, err := service.UpdateProduct(product.ID, &domain.UpdateProductRequest{Status: &status})
+		if !errors.Is(err, step.wantErr) {
+			t.Errorf("UpdateProduct(status=%s) err = %v, want %v", status, err, step.wantErr)
+		}
+	}
+}
+
 func TestProductService_DeleteProduct(t *testing.T) {
 	productRepo := newMockProductRepository()
 	inventoryRepo := newMockInventoryRepository()
@@ -177,11 +219,17 @@ func TestProductService_DeleteProduct(t *testing.T) {
 	}
 
 	// Test deleting the product
-	err = service.DeleteProduct(createdProduct.ID)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = service.DeleteProduct(ctx, createdProduct.ID)
 	if err != nil {
 		t.Errorf("DeleteProduct() error = %v", err)
 		return
 	}
+	// 库存检查沿用调用方的 ctx，请求取消时能及时中止
+	if calls := inventoryRepo.GetByProductIDCalls(); len(calls) != 1 || calls[0].Ctx != ctx {
+		t.Errorf("GetByProductID calls = %d, want 1 with the caller's context", len(calls))
+	}
 
 	// Verify product is deleted
 	_, err = service.GetProduct(createdProduct.ID)
@@ -198,10 +246,10 @@ func TestProductService_ListProducts(t *testing.T) {
 	// Create test products
 	for i := 1; i <= 3; i++ {
 		req := &domain.CreateProductRequest{
-			Name:        "Test Product " + string(rune(i+'0')),
+			Name:        "Test Product " + strconv.Itoa(i),
 			Description: "Test Description",
 			Price:       float64(i * 100),
-			SKU:         "TEST-00" + string(rune(i+'0')),
+			SKU:         "TEST-00" + strconv.Itoa(i),
 			Brand:       "Test Brand",
 		}
 		_, err := service.CreateProduct(req)
@@ -228,3 +276,43 @@ func TestProductService_ListProducts(t *testing.T) {
 		t.Errorf("ListProducts() total = %d, want 3", result.Total)
 	}
 }
+
+func TestProductService_ListProducts_Pagination(t *testing.T) {
+	productRepo := newMockProductRepository()
+	service := NewProductService(productRepo, newMockInventoryRepository())
+	for i := 1; i <= 5; i++ {
+		product := testutil.NewProductBuilder().WithSKU("PAGE-00" + strconv.Itoa(i)).Build()
+		if err := productRepo.Create(product); err != nil {
+			t.Fatalf("Failed to create test product %d: %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		req        *domain.ProductListRequest
+		wantCount  int
+		wantTotal  int64
+		wantMore   bool
+		wantCursor string
+	}{
+		{"counted first page", &domain.ProductListRequest{Page: 1, PageSize: 2}, 2, 5, true, "2"},
+		{"counted last page", &domain.ProductListRequest{Page: 3, PageSize: 2}, 1, 5, false, ""},
+		{"skip total first page", &domain.ProductListRequest{Page: 1, PageSize: 2, SkipTotal: true}, 2, domain.TotalNotCounted, true, "2"},
+		{"skip total exact last page", &domain.ProductListRequest{Page: 1, PageSize: 5, SkipTotal: true}, 5, domain.TotalNotCounted, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.ListProducts(tt.req)
+			if err != nil {
+				t.Fatalf("ListProducts() error = %v", err)
+			}
+			if len(result.Products) != tt.wantCount || result.Total != tt.wantTotal {
+				t.Errorf("ListProducts() = %d products, total %d; want %d, %d", len(result.Products), result.Total, tt.wantCount, tt.wantTotal)
+			}
+			if result.HasMore != tt.wantMore || result.NextCursor != tt.wantCursor {
+				t.Errorf("PageInfo = %+v, want has_more %v next_cursor %q", result.PageInfo, tt.wantMore, tt.wantCursor)
+			}
+		})
+	}
+}
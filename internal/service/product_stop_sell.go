@@ -0,0 +1,136 @@
+// Package service 提供商品停售开关服务。
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// StopSellChecker 热路径上检查商品是否停售（由 cache.StopSellFlags 实现）
+type StopSellChecker interface {
+	IsStopped(ctx context.Context, productID int64) (bool, error)
+}
+
+// StopSellFlagStore 商品停售标记的读写（由 cache.StopSellFlags 实现）
+type StopSellFlagStore interface {
+	StopSellChecker
+	Stop(ctx context.Context, productID int64, reason string) error
+	Resume(ctx context.Context, productID int64) error
+}
+
+// ProductSource 按ID读取商品（由 repo.ProductRepository 实现）
+type ProductSource interface {
+	GetByID(id int64) (*domain.Product, error)
+}
+
+// StopSellEventSource 查找商品的秒杀活动（由 repo.SpikeEventRepository 实现）
+type StopSellEventSource interface {
+	GetByProductID(ctx context.Context, productID int64) ([]*domain.SpikeEvent, error)
+}
+
+// EventFreezer 暂停与恢复秒杀活动的参与（由 cache.SpikeCache 实现）
+type EventFreezer interface {
+	FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error
+	UnfreezeEvent(ctx context.Context, eventID int64) error
+}
+
+// StopSellService 商品停售开关。
+// 停售标记是唯一的判断依据，预留库存与参与秒杀在热路径上检查它；
+// 同时冻结商品未结束的秒杀活动，使 Lua 预减库存脚本也直接拒绝，恢复销售时解除冻结。
+type StopSellService struct {
+	products ProductSource
+	flags    StopSellFlagStore
+	events   StopSellEventSource
+	freezer  EventFreezer // 可为空，为空时不暂停秒杀活动
+	logger   *zap.Logger
+}
+
+// NewStopSellService 创建商品停售开关服务
+func NewStopSellService(products ProductSource, flags StopSellFlagStore, events StopSellEventSource, logger *zap.Logger) *StopSellService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &StopSellService{
+		products: products,
+		flags:    flags,
+		events:   events,
+		logger:   logger,
+	}
+}
+
+// SetEventFreezer 设置秒杀活动冻结，未设置时停售只阻止预留与参与，不冻结活动库存
+func (s *StopSellService) SetEventFreezer(freezer EventFreezer) {
+	s.freezer = freezer
+}
+
+// SetStopSell 停售或恢复销售商品，返回随之暂停或恢复的秒杀活动
+func (s *StopSellService) SetStopSell(ctx context.Context, productID int64, req *domain.StopSellRequest, operatorID int64) (*domain.StopSellResult, error) {
+	product, err := s.products.GetByID(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	if req.Stopped {
+		err = s.flags.Stop(ctx, productID, req.Reason)
+	} else {
+		err = s.flags.Resume(ctx, productID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	affected := s.toggleEvents(ctx, productID, req.Stopped)
+
+	s.logger.Info("商品停售开关已切换",
+		zap.Int64("product_id", productID),
+		zap.Int64("operator_id", operatorID),
+		zap.Bool("stopped", req.Stopped),
+		zap.String("reason", req.Reason),
+		zap.Int64s("affected_events", affected))
+
+	return &domain.StopSellResult{
+		ProductID:      productID,
+		Stopped:        req.Stopped,
+		AffectedEvents: affected,
+	}, nil
+}
+
+// toggleEvents 冻结或解冻商品未结束的秒杀活动，失败只记录日志（停售标记已生效）
+func (s *StopSellService) toggleEvents(ctx context.Context, productID int64, stop bool) []int64 {
+	affected := []int64{}
+	if s.freezer == nil || s.events == nil {
+		return affected
+	}
+
+	events, err := s.events.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Warn("获取商品秒杀活动失败", zap.Int64("product_id", productID), zap.Error(err))
+		return affected
+	}
+
+	for _, event := range events {
+		if event.Status != domain.SpikeEventStatusPending && event.Status != domain.SpikeEventStatusActive {
+			continue
+		}
+		if stop {
+			// 不设过期时间，直到恢复销售
+			err = s.freezer.FreezeEvent(ctx, event.ID, 0)
+		} else {
+			err = s.freezer.UnfreezeEvent(ctx, event.ID)
+		}
+		if err != nil {
+			s.logger.Warn("切换秒杀活动冻结状态失败", zap.Int64("event_id", event.ID), zap.Bool("stop", stop), zap.Error(err))
+			continue
+		}
+		affected = append(affected, event.ID)
+	}
+	return affected
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeEventFreezer 在内存中记录被冻结的活动
+type fakeEventFreezer map[int64]bool
+
+func (f fakeEventFreezer) FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error {
+	f[eventID] = true
+	return nil
+}
+
+func (f fakeEventFreezer) UnfreezeEvent(ctx context.Context, eventID int64) error {
+	delete(f, eventID)
+	return nil
+}
+
+func TestStopSellService_SetStopSell(t *testing.T) {
+	products := newMockProductRepository()
+	product := testutil.NewProductBuilder().Build()
+	testutil.SeedProducts(t, products, product)
+
+	events := NewMockSpikeEventRepository()
+	active := testutil.NewSpikeEventBuilder().Active().ForProduct(product.ID).Build()
+	pending := testutil.NewSpikeEventBuilder().Pending().ForProduct(product.ID).Build()
+	ended := testutil.NewSpikeEventBuilder().Ended().ForProduct(product.ID).Build()
+	other := testutil.NewSpikeEventBuilder().Active().ForProduct(product.ID + 1).Build()
+	testutil.SeedSpikeEvents(t, events, active, pending, ended, other)
+
+	flags := cache.NewStopSellFlags(cache.NewMemoryCache())
+	freezer := fakeEventFreezer{}
+	svc := NewStopSellService(products, flags, events, nil)
+	svc.SetEventFreezer(freezer)
+	ctx := context.Background()
+
+	result, err := svc.SetStopSell(ctx, product.ID, &domain.StopSellRequest{Stopped: true, Reason: "quality issue"}, 1)
+	if err != nil {
+		t.Fatalf("SetStopSell(stop) error = %v", err)
+	}
+	if len(result.AffectedEvents) != 2 || !freezer[active.ID] || !freezer[pending.ID] || freezer[ended.ID] || freezer[other.ID] {
+		t.Errorf("affected = %v, frozen = %v, want only events %d and %d", result.AffectedEvents, freezer, active.ID, pending.ID)
+	}
+	if stopped, _ := flags.IsStopped(ctx, product.ID); !stopped {
+		t.Error("stop-sell flag not set")
+	}
+
+	if _, err := svc.SetStopSell(ctx, product.ID, &domain.StopSellRequest{Stopped: false}, 1); err != nil {
+		t.Fatalf("SetStopSell(resume) error = %v", err)
+	}
+	if stopped, _ := flags.IsStopped(ctx, product.ID); stopped {
+		t.Error("stop-sell flag not cleared")
+	}
+	if len(freezer) != 0 {
+		t.Errorf("frozen = %v after resume, want none", freezer)
+	}
+
+	if _, err := svc.SetStopSell(ctx, 999, &domain.StopSellRequest{Stopped: true}, 1); !errors.Is(err, ErrProductNotFound) {
+		t.Errorf("missing product: err = %v, want ErrProductNotFound", err)
+	}
+}
+
+func TestInventoryService_ReserveStock_StopSell(t *testing.T) {
+	products := newMockProductRepository()
+	inventories := newMockInventoryRepository()
+	product := testutil.NewProductBuilder().Build()
+	testutil.SeedProducts(t, products, product)
+	testutil.SeedInventories(t, inventories, testutil.NewInventoryBuilder().ForProduct(product.ID).Build())
+
+	flags := cache.NewStopSellFlags(cache.NewMemoryCache())
+	svc := NewInventoryService(inventories, products, WithStopSell(flags))
+	req := &domain.ReserveStockRequest{ProductID: product.ID, Quantity: 1}
+
+	if err := flags.Stop(context.Background(), product.ID, ""); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, err := svc.ReserveStock(context.Background(), req); !errors.Is(err, domain.ErrProductStopSell) {
+		t.Fatalf("ReserveStock() err = %v, want ErrProductStopSell", err)
+	}
+
+	if err := flags.Resume(context.Background(), product.ID); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if _, err := svc.ReserveStock(context.Background(), req); err != nil {
+		t.Errorf("ReserveStock() after resume error = %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// ProductVariantService 定义商品规格（SKU）管理服务接口
+type ProductVariantService interface {
+	// CreateVariant 为商品创建规格，商品不存在时返回 ErrProductNotFound
+	CreateVariant(ctx context.Context, productID int64, req *domain.CreateProductVariantRequest) (*domain.ProductVariant, error)
+	// ListVariants 获取商品的全部规格，商品不存在时返回 ErrProductNotFound
+	ListVariants(ctx context.Context, productID int64) ([]*domain.ProductVariant, error)
+	// UpdateVariant 更新规格的名称、属性、价格与启用状态
+	UpdateVariant(ctx context.Context, productID, variantID int64, req *domain.UpdateProductVariantRequest) (*domain.ProductVariant, error)
+	// AdjustStock 调整规格库存，返回调整后的规格
+	AdjustStock(ctx context.Context, productID, variantID int64, req *domain.AdjustVariantStockRequest) (*domain.ProductVariant, error)
+}
+
+// productVariantService 是ProductVariantService接口的实现
+type productVariantService struct {
+	variants repo.ProductVariantRepository
+	products ProductSource
+	logger   *zap.Logger
+}
+
+// NewProductVariantService 创建商品规格服务
+func NewProductVariantService(variants repo.ProductVariantRepository, products ProductSource, logger *zap.Logger) ProductVariantService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &productVariantService{
+		variants: variants,
+		products: products,
+		logger:   logger,
+	}
+}
+
+// CreateVariant 创建商品规格，新规格默认启用
+func (s *productVariantService) CreateVariant(ctx context.Context, productID int64, req *domain.CreateProductVariantRequest) (*domain.ProductVariant, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+
+	variant := &domain.ProductVariant{
+		ProductID:  productID,
+		SKU:        req.SKU,
+		Name:       req.Name,
+		Attributes: req.Attributes,
+		Price:      req.Price,
+		Stock:      req.Stock,
+		Active:     true,
+	}
+	if err := s.variants.Create(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to create product variant: %w", err)
+	}
+	return variant, nil
+}
+
+// ListVariants 获取商品的全部规格
+func (s *productVariantService) ListVariants(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+
+	variants, err := s.variants.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product variants: %w", err)
+	}
+	if variants == nil {
+		variants = []*domain.ProductVariant{}
+	}
+	return variants, nil
+}
+
+// UpdateVariant 更新商品规格，停用的规格不影响已创建的秒杀活动
+func (s *productVariantService) UpdateVariant(ctx context.Context, productID, variantID int64, req *domain.UpdateProductVariantRequest) (*domain.ProductVariant, error) {
+	variant, err := s.getVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		variant.Name = *req.Name
+	}
+	if req.Attributes != nil {
+		variant.Attributes = *req.Attributes
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.Active != nil {
+		variant.Active = *req.Active
+	}
+	if err := s.variants.Update(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to update product variant: %w", err)
+	}
+	return variant, nil
+}
+
+// AdjustStock 调整商品规格库存
+func (s *productVariantService) AdjustStock(ctx context.Context, productID, variantID int64, req *domain.AdjustVariantStockRequest) (*domain.ProductVariant, error) {
+	if _, err := s.getVariant(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	if err := s.variants.AdjustStock(ctx, variantID, req.Quantity); err != nil {
+		return nil, fmt.Errorf("failed to adjust variant stock: %w", err)
+	}
+	s.logger.Info("调整商品规格库存",
+		zap.Int64("product_id", productID),
+		zap.Int64("variant_id", variantID),
+		zap.Int("quantity", req.Quantity),
+		zap.String("reason", req.Reason))
+
+	return s.getVariant(ctx, productID, variantID)
+}
+
+// getVariant 读取规格并校验其属于指定商品
+func (s *productVariantService) getVariant(ctx context.Context, productID, variantID int64) (*domain.ProductVariant, error) {
+	variant, err := s.variants.GetByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProductID != productID {
+		return nil, domain.ErrProductVariantNotFound
+	}
+	return variant, nil
+}
+
+// checkProduct 校验商品存在
+func (s *productVariantService) checkProduct(productID int64) error {
+	product, err := s.products.GetByID(productID)
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+)
+
+// fakeVariantRepo 内存中的商品规格仓储
+type fakeVariantRepo struct {
+	*mocks.ProductVariantRepositoryMock
+
+	variants map[int64]*domain.ProductVariant
+	nextID   int64
+}
+
+func newFakeVariantRepo() *fakeVariantRepo {
+	r := &fakeVariantRepo{
+		ProductVariantRepositoryMock: &mocks.ProductVariantRepositoryMock{},
+		variants:                     make(map[int64]*domain.ProductVariant),
+		nextID:                       1,
+	}
+	r.CreateFunc = r.create
+	r.GetByIDFunc = r.getByID
+	r.GetByIDsFunc = r.getByIDs
+	r.ListByProductFunc = r.listByProduct
+	r.UpdateFunc = r.update
+	r.AdjustStockFunc = r.adjustStock
+	r.ConsumeStockFunc = r.consumeStock
+	return r
+}
+
+func (r *fakeVariantRepo) create(ctx context.Context, variant *domain.ProductVariant) error {
+	for _, v := range r.variants {
+		if v.SKU == variant.SKU {
+			return domain.NewConflictError("商品规格SKU已存在")
+		}
+	}
+	variant.ID = r.nextID
+	r.nextID++
+	copied := *variant
+	r.variants[variant.ID] = &copied
+	return nil
+}
+
+func (r *fakeVariantRepo) getByID(ctx context.Context, id int64) (*domain.ProductVariant, error) {
+	v, ok := r.variants[id]
+	if !ok {
+		return nil, domain.ErrProductVariantNotFound
+	}
+	copied := *v
+	return &copied, nil
+}
+
+func (r *fakeVariantRepo) getByIDs(ctx context.Context, ids []int64) ([]*domain.ProductVariant, error) {
+	var variants []*domain.ProductVariant
+	for _, id := range ids {
+		if v, err := r.getByID(ctx, id); err == nil {
+			variants = append(variants, v)
+		}
+	}
+	return variants, nil
+}
+
+func (r *fakeVariantRepo) listByProduct(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
+	var variants []*domain.ProductVariant
+	for id := int64(1); id < r.nextID; id++ {
+		if v, ok := r.variants[id]; ok && v.ProductID == productID {
+			copied := *v
+			variants = append(variants, &copied)
+		}
+	}
+	return variants, nil
+}
+
+func (r *fakeVariantRepo) update(ctx context.Context, variant *domain.ProductVariant) error {
+	current, ok := r.variants[variant.ID]
+	if !ok || current.Version != variant.Version {
+		return domain.NewVersionConflictError("product variant version conflict or record not found")
+	}
+	variant.Version++
+	copied := *variant
+	r.variants[variant.ID] = &copied
+	return nil
+}
+
+func (r *fakeVariantRepo) adjustStock(ctx context.Context, id int64, quantity int) error {
+	v, ok := r.variants[id]
+	if !ok || v.Stock+quantity < 0 {
+		return domain.NewInsufficientStockError("stock adjustment would result in negative variant stock")
+	}
+	v.Stock += quantity
+	return nil
+}
+
+func (r *fakeVariantRepo) consumeStock(ctx context.Context, id int64, quantity int) error {
+	v, ok := r.variants[id]
+	if !ok || v.Stock < quantity {
+		return domain.NewInsufficientStockError("insufficient variant stock to consume")
+	}
+	v.Stock -= quantity
+	v.SoldStock += quantity
+	return nil
+}
+
+func newTestVariantService() (ProductVariantService, *fakeVariantRepo) {
+	products := newMockProductRepository()
+	products.products[1] = &domain.Product{ID: 1, Name: "T恤", SKU: "TS", Price: 99, Status: domain.ProductStatusActive}
+	products.products[2] = &domain.Product{ID: 2, Name: "帽子", SKU: "HAT", Price: 49, Status: domain.ProductStatusActive}
+	variants := newFakeVariantRepo()
+	return NewProductVariantService(variants, products, nil), variants
+}
+
+func TestProductVariantService_CreateAndList(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestVariantService()
+
+	red, err := svc.CreateVariant(ctx, 1, &domain.CreateProductVariantRequest{
+		SKU: "TS-RED-XL", Name: "红色 / XL", Attributes: map[string]string{"color": "red", "size": "XL"}, Price: 109, Stock: 10,
+	})
+	if err != nil {
+		t.Fatalf("CreateVariant() error = %v", err)
+	}
+	if red.ID == 0 || red.ProductID != 1 || !red.Active {
+		t.Errorf("CreateVariant() = %+v, want active variant of product 1", red)
+	}
+
+	if _, err := svc.CreateVariant(ctx, 1, &domain.CreateProductVariantRequest{SKU: "TS-RED-XL", Name: "重复", Price: 1}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("CreateVariant() duplicate SKU error = %v, want ErrConflict", err)
+	}
+	if _, err := svc.CreateVariant(ctx, 99, &domain.CreateProductVariantRequest{SKU: "X", Name: "X", Price: 1}); !errors.Is(err, ErrProductNotFound) {
+		t.Errorf("CreateVariant() unknown product error = %v, want ErrProductNotFound", err)
+	}
+
+	variants, err := svc.ListVariants(ctx, 1)
+	if err != nil || len(variants) != 1 || variants[0].SKU != "TS-RED-XL" {
+		t.Errorf("ListVariants(1) = %v, %v, want the created variant", variants, err)
+	}
+	if variants, err := svc.ListVariants(ctx, 2); err != nil || variants == nil || len(variants) != 0 {
+		t.Errorf("ListVariants(2) = %v, %v, want empty list", variants, err)
+	}
+}
+
+func TestProductVariantService_ChecksOwnership(t *testing.T) {
+	ctx := context.Background()
+	svc, variants := newTestVariantService()
+	variant, err := svc.CreateVariant(ctx, 1, &domain.CreateProductVariantRequest{SKU: "TS-BLUE-M", Name: "蓝色 / M", Price: 99, Stock: 5})
+	if err != nil {
+		t.Fatalf("CreateVariant() error = %v", err)
+	}
+
+	// 通过其它商品的路径访问规格按不存在处理
+	inactive := false
+	if _, err := svc.UpdateVariant(ctx, 2, variant.ID, &domain.UpdateProductVariantRequest{Active: &inactive}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("UpdateVariant() via other product error = %v, want ErrNotFound", err)
+	}
+	if _, err := svc.AdjustStock(ctx, 2, variant.ID, &domain.AdjustVariantStockRequest{Quantity: 3}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("AdjustStock() via other product error = %v, want ErrNotFound", err)
+	}
+
+	updated, err := svc.UpdateVariant(ctx, 1, variant.ID, &domain.UpdateProductVariantRequest{Active: &inactive})
+	if err != nil || updated.Active || updated.Version != 1 {
+		t.Errorf("UpdateVariant() = %+v, %v, want inactive variant at version 1", updated, err)
+	}
+
+	adjusted, err := svc.AdjustStock(ctx, 1, variant.ID, &domain.AdjustVariantStockRequest{Quantity: -2})
+	if err != nil || adjusted.Stock != 3 {
+		t.Errorf("AdjustStock(-2) = %+v, %v, want stock 3", adjusted, err)
+	}
+	if _, err := svc.AdjustStock(ctx, 1, variant.ID, &domain.AdjustVariantStockRequest{Quantity: -4}); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Errorf("AdjustStock(-4) error = %v, want ErrInsufficientStock", err)
+	}
+	if variants.variants[variant.ID].Stock != 3 {
+		t.Errorf("stock = %d, want 3", variants.variants[variant.ID].Stock)
+	}
+}
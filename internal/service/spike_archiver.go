@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SpikeArchiveStore 归档早已结束的活动与订单（由 repo.SpikeArchiveRepository 实现）
+type SpikeArchiveStore interface {
+	ArchiveOrders(ctx context.Context, before time.Time, limit int) (int64, error)
+	ArchiveEvents(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// SpikeArchiveConfig 活动与订单归档配置
+type SpikeArchiveConfig struct {
+	Interval  time.Duration // 归档任务执行间隔
+	After     time.Duration // 订单创建、活动结束多久之后归档
+	BatchSize int           // 每个事务归档的最大行数，避免长事务与大范围锁
+}
+
+// DefaultSpikeArchiveConfig 默认归档配置：每小时归档一次 90 天前的数据，每批 500 行
+func DefaultSpikeArchiveConfig() *SpikeArchiveConfig {
+	return &SpikeArchiveConfig{
+		Interval:  time.Hour,
+		After:     90 * 24 * time.Hour,
+		BatchSize: 500,
+	}
+}
+
+// SpikeArchiveStats 归档统计
+type SpikeArchiveStats struct {
+	OrdersArchived int64 `json:"orders_archived"` // 已归档的订单数
+	EventsArchived int64 `json:"events_archived"` // 已归档的活动数
+	Failures       int64 `json:"failures"`        // 归档失败次数
+}
+
+// SpikeArchiver 定时把早已结束的活动与终态订单迁移到归档表，控制主表规模。
+// 先归档订单再归档活动，仍有订单的活动会在订单归档后的下一轮处理
+type SpikeArchiver struct {
+	store  SpikeArchiveStore
+	config *SpikeArchiveConfig
+	logger *zap.Logger
+
+	ordersArchived atomic.Int64
+	eventsArchived atomic.Int64
+	failures       atomic.Int64
+}
+
+// NewSpikeArchiver 创建归档任务
+func NewSpikeArchiver(store SpikeArchiveStore, config *SpikeArchiveConfig, logger *zap.Logger) *SpikeArchiver {
+	if config == nil {
+		config = DefaultSpikeArchiveConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SpikeArchiver{store: store, config: config, logger: logger}
+}
+
+// Stats 返回归档统计
+func (a *SpikeArchiver) Stats() SpikeArchiveStats {
+	return SpikeArchiveStats{
+		OrdersArchived: a.ordersArchived.Load(),
+		EventsArchived: a.eventsArchived.Load(),
+		Failures:       a.failures.Load(),
+	}
+}
+
+// Start 异步启动归档循环，ctx 取消时退出
+func (a *SpikeArchiver) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+
+		a.logger.Info("活动与订单归档任务已启动",
+			zap.Duration("interval", a.config.Interval),
+			zap.Duration("after", a.config.After),
+			zap.Int("batch_size", a.config.BatchSize))
+		for {
+			select {
+			case <-ctx.Done():
+				a.logger.Info("活动与订单归档任务已停止")
+				return
+			case <-ticker.C:
+				if _, _, err := a.RunOnce(ctx); err != nil {
+					a.failures.Add(1)
+					a.logger.Warn("活动与订单归档失败", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce 归档一轮，逐批处理直到没有可归档的数据，返回本轮归档的订单数与活动数
+func (a *SpikeArchiver) RunOnce(ctx context.Context) (orders, events int64, err error) {
+	before := time.Now().Add(-a.config.After)
+
+	orders, err = a.drain(ctx, before, a.store.ArchiveOrders)
+	a.ordersArchived.Add(orders)
+	if err != nil {
+		return orders, 0, fmt.Errorf("failed to archive spike orders: %w", err)
+	}
+	events, err = a.drain(ctx, before, a.store.ArchiveEvents)
+	a.eventsArchived.Add(events)
+	if err != nil {
+		return orders, events, fmt.Errorf("failed to archive spike events: %w", err)
+	}
+
+	if orders > 0 || events > 0 {
+		a.logger.Info("活动与订单已归档",
+			zap.Time("before", before),
+			zap.Int64("orders", orders),
+			zap.Int64("events", events))
+	}
+	return orders, events, nil
+}
+
+// drain 重复执行一批归档，直到某批不足 BatchSize 或 ctx 取消
+func (a *SpikeArchiver) drain(ctx context.Context, before time.Time, archive func(ctx context.Context, before time.Time, limit int) (int64, error)) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := archive(ctx, before, a.config.BatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(a.config.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
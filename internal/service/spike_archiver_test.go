@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubArchiveStore 按剩余数量分批归档，记录调用顺序
+type stubArchiveStore struct {
+	orders, events int64
+	eventsErr      error
+	calls          []string
+}
+
+func (s *stubArchiveStore) ArchiveOrders(ctx context.Context, before time.Time, limit int) (int64, error) {
+	s.calls = append(s.calls, "orders")
+	n := min(s.orders, int64(limit))
+	s.orders -= n
+	return n, nil
+}
+
+func (s *stubArchiveStore) ArchiveEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	s.calls = append(s.calls, "events")
+	if s.eventsErr != nil {
+		return 0, s.eventsErr
+	}
+	n := min(s.events, int64(limit))
+	s.events -= n
+	return n, nil
+}
+
+func TestSpikeArchiver_RunOnce(t *testing.T) {
+	store := &stubArchiveStore{orders: 5, events: 1}
+	archiver := NewSpikeArchiver(store, &SpikeArchiveConfig{Interval: time.Hour, After: time.Hour, BatchSize: 2}, nil)
+
+	orders, events, err := archiver.RunOnce(context.Background())
+	if err != nil || orders != 5 || events != 1 {
+		t.Fatalf("RunOnce() = %d, %d, %v, want 5, 1, nil", orders, events, err)
+	}
+	// 订单分批归档直到不足一批，之后才归档活动
+	want := []string{"orders", "orders", "orders", "events"}
+	if len(store.calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", store.calls, want)
+	}
+	for i := range want {
+		if store.calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", store.calls, want)
+		}
+	}
+
+	store.eventsErr = errors.New("lock wait timeout")
+	if _, _, err := archiver.RunOnce(context.Background()); !errors.Is(err, store.eventsErr) {
+		t.Errorf("RunOnce() error = %v, want wrapped events error", err)
+	}
+	if stats := archiver.Stats(); stats.OrdersArchived != 5 || stats.EventsArchived != 1 {
+		t.Errorf("Stats() = %+v, want 5 orders and 1 event archived", stats)
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// campaignQuotaRetention 专场结束后购买次数计数的保留时间，覆盖专场末尾订单的支付与取消窗口
+const campaignQuotaRetention = 24 * time.Hour
+
+//go:generate moq -rm -out spike_campaign_moq_test.go . CampaignQuotaCache
+
+// CampaignQuotaCache 专场购买次数所需的缓存操作（由 cache.SpikeCache 实现）
+type CampaignQuotaCache interface {
+	ReserveCampaignQuota(ctx context.Context, campaignID, userID, max int64, ttl time.Duration) (bool, error)
+	ReleaseCampaignQuota(ctx context.Context, campaignID, userID int64) error
+}
+
+// CampaignSource 提供秒杀专场（由 repo.SpikeCampaignRepository 实现）
+type CampaignSource interface {
+	GetByID(id int64) (*domain.SpikeCampaign, error)
+}
+
+// CampaignQuota 在参与路径上执行专场内跨活动的单用户购买次数上限。
+// 专场信息在进程内缓存 cacheTTL，避免每次参与都读取数据库
+type CampaignQuota struct {
+	campaigns CampaignSource
+	cache     CampaignQuotaCache
+	cacheTTL  time.Duration
+	logger    *zap.Logger
+
+	mu      sync.Mutex
+	entries map[int64]campaignEntry
+}
+
+type campaignEntry struct {
+	campaign *domain.SpikeCampaign
+	loadedAt time.Time
+}
+
+// NewCampaignQuota 创建专场购买次数控制
+func NewCampaignQuota(campaigns CampaignSource, quotaCache CampaignQuotaCache, logger *zap.Logger) *CampaignQuota {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &CampaignQuota{
+		campaigns: campaigns,
+		cache:     quotaCache,
+		cacheTTL:  30 * time.Second,
+		logger:    logger,
+		entries:   make(map[int64]campaignEntry),
+	}
+}
+
+// Reserve 为用户占用一次专场购买次数。
+// 返回 reserved 表示是否实际占用（活动不属于专场或专场不限购时为 false，无需归还），
+// allowed 为 false 表示已达到上限
+func (q *CampaignQuota) Reserve(ctx context.Context, event *domain.SpikeEvent, userID int64) (reserved, allowed bool, err error) {
+	if event.SpikeCampaignID == nil {
+		return false, true, nil
+	}
+
+	campaign, err := q.campaign(*event.SpikeCampaignID)
+	if err != nil {
+		return false, false, err
+	}
+	if !campaign.HasQuota() {
+		return false, true, nil
+	}
+
+	ttl := time.Until(campaign.EndAt) + campaignQuotaRetention
+	if ttl <= 0 {
+		ttl = campaignQuotaRetention
+	}
+	ok, err := q.cache.ReserveCampaignQuota(ctx, campaign.ID, userID, campaign.MaxPurchasesPerUser, ttl)
+	if err != nil {
+		return false, false, err
+	}
+	return ok, ok, nil
+}
+
+// Release 归还用户在活动所属专场内的一次购买次数，失败只记录日志
+func (q *CampaignQuota) Release(ctx context.Context, event *domain.SpikeEvent, userID int64) {
+	if event.SpikeCampaignID == nil {
+		return
+	}
+	if err := q.cache.ReleaseCampaignQuota(ctx, *event.SpikeCampaignID, userID); err != nil {
+		q.logger.Error("归还专场购买次数失败",
+			zap.Int64("spike_campaign_id", *event.SpikeCampaignID),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
+
+// campaign 读取专场，优先使用进程内缓存
+func (q *CampaignQuota) campaign(id int64) (*domain.SpikeCampaign, error) {
+	q.mu.Lock()
+	entry, ok := q.entries[id]
+	q.mu.Unlock()
+	if ok && time.Since(entry.loadedAt) < q.cacheTTL {
+		return entry.campaign, nil
+	}
+
+	campaign, err := q.campaigns.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike campaign: %w", err)
+	}
+
+	q.mu.Lock()
+	q.entries[id] = campaignEntry{campaign: campaign, loadedAt: time.Now()}
+	q.mu.Unlock()
+	return campaign, nil
+}
+
+// SpikeCampaignService 定义秒杀专场服务接口
+type SpikeCampaignService interface {
+	CreateCampaign(ctx context.Context, req *domain.CreateSpikeCampaignRequest) (*domain.SpikeCampaign, error)
+	GetCampaign(ctx context.Context, id int64) (*domain.SpikeCampaign, error)
+	// AssignEvents 将活动加入专场并刷新活动缓存，使参与路径立即按专场限购
+	AssignEvents(ctx context.Context, id int64, req *domain.AssignSpikeCampaignEventsRequest) (int64, error)
+	// GetCampaignReport 汇总专场内各活动的订单与去重后的下单用户数
+	GetCampaignReport(ctx context.Context, id int64) (*domain.SpikeCampaignReport, error)
+}
+
+// CampaignEventCache 专场服务刷新活动缓存所需的操作（由 cache.SpikeCache 实现）
+type CampaignEventCache interface {
+	CacheEventInfo(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error
+}
+
+// spikeCampaignService 是SpikeCampaignService接口的实现
+type spikeCampaignService struct {
+	campaigns repo.SpikeCampaignRepository
+	events    GuardedEventSource
+	cache     CampaignEventCache
+	cacheTTL  time.Duration
+	logger    *zap.Logger
+}
+
+// NewSpikeCampaignService 创建秒杀专场服务
+func NewSpikeCampaignService(campaigns repo.SpikeCampaignRepository, events GuardedEventSource, eventCache CampaignEventCache, cacheTTL time.Duration, logger *zap.Logger) SpikeCampaignService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeCampaignService{
+		campaigns: campaigns,
+		events:    events,
+		cache:     eventCache,
+		cacheTTL:  cacheTTL,
+		logger:    logger,
+	}
+}
+
+// CreateCampaign 创建秒杀专场
+func (s *spikeCampaignService) CreateCampaign(ctx context.Context, req *domain.CreateSpikeCampaignRequest) (*domain.SpikeCampaign, error) {
+	if !req.EndAt.After(req.StartAt) {
+		return nil, domain.ErrSpikeCampaignInvalidPeriod
+	}
+
+	now := time.Now()
+	campaign := &domain.SpikeCampaign{
+		Name:                req.Name,
+		Description:         req.Description,
+		MaxPurchasesPerUser: req.MaxPurchasesPerUser,
+		StartAt:             req.StartAt,
+		EndAt:               req.EndAt,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	if err := s.campaigns.Create(campaign); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("秒杀专场已创建",
+		zap.Int64("spike_campaign_id", campaign.ID),
+		zap.String("name", campaign.Name),
+		zap.Int64("max_purchases_per_user", campaign.MaxPurchasesPerUser))
+	return campaign, nil
+}
+
+// GetCampaign 获取秒杀专场
+func (s *spikeCampaignService) GetCampaign(ctx context.Context, id int64) (*domain.SpikeCampaign, error) {
+	return s.campaigns.GetByID(id)
+}
+
+// AssignEvents 将活动加入专场，任一活动不存在时不做修改
+func (s *spikeCampaignService) AssignEvents(ctx context.Context, id int64, req *domain.AssignSpikeCampaignEventsRequest) (int64, error) {
+	if _, err := s.campaigns.GetByID(id); err != nil {
+		return 0, err
+	}
+	for _, eventID := range req.EventIDs {
+		if _, err := s.events.GetByID(ctx, eventID); err != nil {
+			return 0, err
+		}
+	}
+
+	assigned, err := s.campaigns.AssignEvents(id, req.EventIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// 参与路径优先读取活动缓存，刷新后专场限购才会对已缓存的活动生效
+	for _, eventID := range req.EventIDs {
+		event, err := s.events.GetByID(ctx, eventID)
+		if err != nil {
+			s.logger.Warn("刷新活动缓存时读取活动失败", zap.Int64("event_id", eventID), zap.Error(err))
+			continue
+		}
+		if err := s.cache.CacheEventInfo(ctx, eventID, event, s.cacheTTL); err != nil {
+			s.logger.Warn("刷新活动缓存失败", zap.Int64("event_id", eventID), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("活动已加入秒杀专场",
+		zap.Int64("spike_campaign_id", id),
+		zap.Int64s("event_ids", req.EventIDs),
+		zap.Int64("assigned", assigned))
+	return assigned, nil
+}
+
+// GetCampaignReport 生成秒杀专场报表
+func (s *spikeCampaignService) GetCampaignReport(ctx context.Context, id int64) (*domain.SpikeCampaignReport, error) {
+	campaign, err := s.campaigns.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.campaigns.GetEventStats(id)
+	if err != nil {
+		return nil, err
+	}
+
+	buyers, err := s.campaigns.CountBuyers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.SpikeCampaignReport{
+		Campaign:     campaign,
+		Events:       stats,
+		UniqueBuyers: buyers,
+	}
+	if report.Events == nil {
+		report.Events = []*domain.SpikeCampaignEventStats{}
+	}
+	for _, e := range stats {
+		report.SpikeStock += e.SpikeStock
+		report.SoldCount += e.SoldCount
+		report.Orders += e.Orders
+		report.PaidOrders += e.PaidOrders
+		report.PaidQuantity += e.PaidQuantity
+		report.Revenue += e.Revenue
+	}
+	return report, nil
+}
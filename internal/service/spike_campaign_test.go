@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeCampaignRepo 在内存中保存专场、活动归属与预置的统计
+type fakeCampaignRepo struct {
+	*mocks.SpikeCampaignRepositoryMock
+
+	campaigns map[int64]*domain.SpikeCampaign
+	assigned  map[int64]int64 // eventID -> campaignID
+	stats     []*domain.SpikeCampaignEventStats
+	buyers    int64
+}
+
+func newFakeCampaignRepo(campaigns ...*domain.SpikeCampaign) *fakeCampaignRepo {
+	f := &fakeCampaignRepo{
+		SpikeCampaignRepositoryMock: &mocks.SpikeCampaignRepositoryMock{},
+		campaigns:                   make(map[int64]*domain.SpikeCampaign),
+		assigned:                    make(map[int64]int64),
+	}
+	f.CreateFunc = f.create
+	f.GetByIDFunc = f.getByID
+	f.AssignEventsFunc = f.assignEvents
+	f.GetEventStatsFunc = f.getEventStats
+	f.CountBuyersFunc = f.countBuyers
+	for _, c := range campaigns {
+		f.campaigns[c.ID] = c
+	}
+	return f
+}
+
+func (f *fakeCampaignRepo) create(campaign *domain.SpikeCampaign) error {
+	campaign.ID = int64(len(f.campaigns) + 1)
+	f.campaigns[campaign.ID] = campaign
+	return nil
+}
+
+func (f *fakeCampaignRepo) getByID(id int64) (*domain.SpikeCampaign, error) {
+	c, ok := f.campaigns[id]
+	if !ok {
+		return nil, domain.ErrSpikeCampaignNotFound
+	}
+	return c, nil
+}
+
+func (f *fakeCampaignRepo) assignEvents(campaignID int64, eventIDs []int64) (int64, error) {
+	var affected int64
+	for _, id := range eventIDs {
+		if f.assigned[id] != campaignID {
+			f.assigned[id] = campaignID
+			affected++
+		}
+	}
+	return affected, nil
+}
+
+func (f *fakeCampaignRepo) getEventStats(campaignID int64) ([]*domain.SpikeCampaignEventStats, error) {
+	return f.stats, nil
+}
+
+func (f *fakeCampaignRepo) countBuyers(campaignID int64) (int64, error) {
+	return f.buyers, nil
+}
+
+// fakeCampaignQuotaCache 在内存中记录专场内各用户的购买次数
+type fakeCampaignQuotaCache struct {
+	*CampaignQuotaCacheMock
+
+	mu        sync.Mutex
+	purchases map[[2]int64]int64 // {campaignID, userID} -> 次数
+}
+
+func newFakeCampaignQuotaCache() *fakeCampaignQuotaCache {
+	f := &fakeCampaignQuotaCache{CampaignQuotaCacheMock: &CampaignQuotaCacheMock{}, purchases: make(map[[2]int64]int64)}
+	f.ReserveCampaignQuotaFunc = f.reserveCampaignQuota
+	f.ReleaseCampaignQuotaFunc = f.releaseCampaignQuota
+	return f
+}
+
+func (f *fakeCampaignQuotaCache) reserveCampaignQuota(ctx context.Context, campaignID, userID, max int64, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := [2]int64{campaignID, userID}
+	if f.purchases[key] >= max {
+		return false, nil
+	}
+	f.purchases[key]++
+	return true, nil
+}
+
+func (f *fakeCampaignQuotaCache) releaseCampaignQuota(ctx context.Context, campaignID, userID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := [2]int64{campaignID, userID}
+	if f.purchases[key]--; f.purchases[key] <= 0 {
+		delete(f.purchases, key)
+	}
+	return nil
+}
+
+func newTestCampaign(id, maxPurchases int64) *domain.SpikeCampaign {
+	now := time.Now()
+	return &domain.SpikeCampaign{
+		ID:                  id,
+		Name:                "双十一专场",
+		MaxPurchasesPerUser: maxPurchases,
+		StartAt:             now.Add(-time.Hour),
+		EndAt:               now.Add(time.Hour),
+	}
+}
+
+func TestCampaignQuota_Reserve(t *testing.T) {
+	quotaCache := newFakeCampaignQuotaCache()
+	quota := NewCampaignQuota(newFakeCampaignRepo(newTestCampaign(1, 1), newTestCampaign(2, 0)), quotaCache, nil)
+	ctx := context.Background()
+
+	first := testutil.NewSpikeEventBuilder().WithID(10).Active().InCampaign(1).Build()
+	second := testutil.NewSpikeEventBuilder().WithID(11).Active().InCampaign(1).Build()
+
+	if reserved, allowed, err := quota.Reserve(ctx, first, 7); err != nil || !reserved || !allowed {
+		t.Fatalf("first Reserve() = %v, %v, %v; want reserved", reserved, allowed, err)
+	}
+	if _, allowed, err := quota.Reserve(ctx, second, 7); err != nil || allowed {
+		t.Fatalf("second Reserve() allowed = %v, err = %v; want quota exceeded across events", allowed, err)
+	}
+	if _, allowed, _ := quota.Reserve(ctx, second, 8); !allowed {
+		t.Error("quota of another user affected")
+	}
+
+	// 归还后可在专场内其他活动再次购买
+	quota.Release(ctx, first, 7)
+	if _, allowed, _ := quota.Reserve(ctx, second, 7); !allowed {
+		t.Error("Reserve() after Release() not allowed")
+	}
+
+	// 不属于专场或专场不限购的活动不占用次数
+	standalone := testutil.NewSpikeEventBuilder().Active().Build()
+	unlimited := testutil.NewSpikeEventBuilder().Active().InCampaign(2).Build()
+	for _, event := range []*domain.SpikeEvent{standalone, unlimited} {
+		if reserved, allowed, err := quota.Reserve(ctx, event, 7); err != nil || reserved || !allowed {
+			t.Errorf("Reserve(campaign %v) = %v, %v, %v; want allowed without reservation", event.SpikeCampaignID, reserved, allowed, err)
+		}
+	}
+
+	missing := testutil.NewSpikeEventBuilder().Active().InCampaign(99).Build()
+	if _, _, err := quota.Reserve(ctx, missing, 7); !errors.Is(err, domain.ErrSpikeCampaignNotFound) {
+		t.Errorf("missing campaign: err = %v, want ErrSpikeCampaignNotFound", err)
+	}
+}
+
+func TestSpikeService_ParticipateSpike_CampaignQuota(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	first := testutil.NewSpikeEventBuilder().Active().WithStock(10).InCampaign(1).Build()
+	second := testutil.NewSpikeEventBuilder().Active().WithStock(10).InCampaign(1).Build()
+	testutil.SeedSpikeEvents(t, events, first, second)
+
+	spikeCache := NewMockSpikeCache()
+	spikeCache.WarmupStock(context.Background(), first.ID, 10, time.Hour)
+	spikeCache.WarmupStock(context.Background(), second.ID, 10, time.Hour)
+
+	svc := NewSpikeService(events, NewMockSpikeOrderRepository(), newMockProductRepository(), newMockInventoryRepository(),
+		newFakeUserRepo(), nil, spikeCache, NewMockSpikeProducer(), NewMockLimiter(true), NewMockLimiter(true),
+		DefaultSpikeServiceConfig(), zap.NewNop())
+	quotaCache := newFakeCampaignQuotaCache()
+	svc.SetCampaignQuota(NewCampaignQuota(newFakeCampaignRepo(newTestCampaign(1, 1)), quotaCache, nil))
+
+	ctx := context.Background()
+	result, err := svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: first.ID, Quantity: 1, IdempotencyKey: "campaign_1",
+	}, 7)
+	if err != nil || !result.Success {
+		t.Fatalf("first participation = %+v, %v; want success", result, err)
+	}
+
+	result, err = svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: second.ID, Quantity: 1, IdempotencyKey: "campaign_2",
+	}, 7)
+	if err != nil {
+		t.Fatalf("ParticipateSpike() error = %v", err)
+	}
+	if result.Success || result.Code != domain.SpikeParticipationCodeCampaignQuota {
+		t.Errorf("second participation = %+v, want code %s", result, domain.SpikeParticipationCodeCampaignQuota)
+	}
+	if info, _ := spikeCache.GetStockInfo(ctx, second.ID); info.Stock != 10 {
+		t.Errorf("stock of second event = %d, want untouched 10", info.Stock)
+	}
+
+	// 重复参与同一活动被去重拒绝时归还占用的次数
+	result, _ = svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: first.ID, Quantity: 1, IdempotencyKey: "campaign_3",
+	}, 8)
+	if !result.Success {
+		t.Fatalf("participation of another user = %+v, want success", result)
+	}
+	result, _ = svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: first.ID, Quantity: 1, IdempotencyKey: "campaign_4",
+	}, 8)
+	if result.Success {
+		t.Fatal("duplicate participation succeeded")
+	}
+	if got := quotaCache.purchases[[2]int64{1, 8}]; got != 1 {
+		t.Errorf("purchases of user 8 = %d, want 1 after failed decrement", got)
+	}
+}
+
+func TestSpikeCampaignService_GetCampaignReport(t *testing.T) {
+	campaigns := newFakeCampaignRepo(newTestCampaign(1, 1))
+	campaigns.stats = []*domain.SpikeCampaignEventStats{
+		{SpikeEventID: 1, SpikeStock: 100, SoldCount: 80, Orders: 80, PaidOrders: 70, PaidQuantity: 70, Revenue: 700, Buyers: 80},
+		{SpikeEventID: 2, SpikeStock: 50, SoldCount: 50, Orders: 50, PaidOrders: 40, PaidQuantity: 40, Revenue: 800, Buyers: 50},
+	}
+	campaigns.buyers = 110
+	svc := NewSpikeCampaignService(campaigns, NewMockSpikeEventRepository(), NewMockSpikeCache(), time.Hour, nil)
+
+	report, err := svc.GetCampaignReport(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetCampaignReport() error = %v", err)
+	}
+	if report.SpikeStock != 150 || report.SoldCount != 130 || report.PaidOrders != 110 || report.Revenue != 1500 {
+		t.Errorf("report totals = %+v", report)
+	}
+	if report.UniqueBuyers != 110 {
+		t.Errorf("unique_buyers = %d, want 110", report.UniqueBuyers)
+	}
+
+	if _, err := svc.GetCampaignReport(context.Background(), 2); !errors.Is(err, domain.ErrSpikeCampaignNotFound) {
+		t.Errorf("missing campaign: err = %v, want ErrSpikeCampaignNotFound", err)
+	}
+}
+
+func TestSpikeCampaignService_AssignEvents(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	event := testutil.NewSpikeEventBuilder().Active().Build()
+	testutil.SeedSpikeEvents(t, events, event)
+
+	svc := NewSpikeCampaignService(newFakeCampaignRepo(newTestCampaign(1, 1)), events, NewMockSpikeCache(), time.Hour, nil)
+	ctx := context.Background()
+
+	if _, err := svc.AssignEvents(ctx, 1, &domain.AssignSpikeCampaignEventsRequest{EventIDs: []int64{event.ID, 999}}); !errors.Is(err, domain.ErrSpikeEventNotFound) {
+		t.Errorf("missing event: err = %v, want ErrSpikeEventNotFound", err)
+	}
+	assigned, err := svc.AssignEvents(ctx, 1, &domain.AssignSpikeCampaignEventsRequest{EventIDs: []int64{event.ID}})
+	if err != nil || assigned != 1 {
+		t.Errorf("AssignEvents() = %d, %v; want 1", assigned, err)
+	}
+	if _, err := svc.AssignEvents(ctx, 2, &domain.AssignSpikeCampaignEventsRequest{EventIDs: []int64{event.ID}}); !errors.Is(err, domain.ErrSpikeCampaignNotFound) {
+		t.Errorf("missing campaign: err = %v, want ErrSpikeCampaignNotFound", err)
+	}
+}
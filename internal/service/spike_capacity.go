@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// 容量规划的经验参数（来自压测与历次活动复盘的运维手册）
+const (
+	capacityAttemptsPerOrder   = 20.0                   // 每个成交订单对应的抢购请求数
+	capacityDefaultSellout     = 10 * time.Second       // 无历史数据时假设的售罄时长
+	capacityOrderLatency       = 50 * time.Millisecond  // 单条订单消息的处理耗时
+	capacityQueryLatency       = 5 * time.Millisecond   // 单次读请求占用数据库连接的时长
+	capacityHeadroom           = 1.5                    // 冗余系数
+	capacityUserRetryPerMinute = 5                      // 每个购买单位允许的每分钟重试次数
+	capacityReadRatio          = 0.1                    // 读请求中穿透到数据库的比例
+	capacityMinDBConns         = 10                     // 数据库连接池下限
+	capacityHistoryLimit       = 10                     // 参考的历史活动数量上限
+	capacityMinWindow          = 100 * time.Millisecond // 历史售罄时长下限，避免除零
+)
+
+// CapacityPlan 秒杀活动容量规划建议
+type CapacityPlan struct {
+	EventID      int64 `json:"event_id"`
+	SpikeStock   int64 `json:"spike_stock"`
+	PerUserLimit int64 `json:"per_user_limit"`
+
+	// 需求预估
+	ExpectedOrders          int64   `json:"expected_orders"`           // 预计成交订单数
+	ExpectedSelloutSeconds  float64 `json:"expected_sellout_seconds"`  // 预计售罄时长
+	PeakOrdersPerSecond     float64 `json:"peak_orders_per_second"`    // 峰值下单速率
+	PeakAttemptsPerSecond   float64 `json:"peak_attempts_per_second"`  // 峰值抢购请求速率
+	HistoricalEventsSampled int     `json:"historical_events_sampled"` // 参考的历史活动数
+	HistoricalOrdersPerSec  float64 `json:"historical_orders_per_sec"` // 历史平均下单速率
+	HistoricalSelloutRatio  float64 `json:"historical_sellout_ratio"`  // 历史平均售出比例
+
+	// 建议配置
+	Limiter      LimiterRecommendation  `json:"limiter"`
+	Consumer     ConsumerRecommendation `json:"consumer"`
+	DatabasePool DBPoolRecommendation   `json:"database_pool"`
+	Notes        []string               `json:"notes,omitempty"`
+}
+
+// LimiterRecommendation 限流器建议
+type LimiterRecommendation struct {
+	GlobalRatePerMinute int64 `json:"global_rate_per_minute"` // 全局令牌桶速率
+	GlobalBurst         int64 `json:"global_burst"`           // 全局令牌桶容量
+	UserRatePerMinute   int64 `json:"user_rate_per_minute"`   // 单用户滑动窗口速率
+}
+
+// ConsumerRecommendation 订单消费者建议
+type ConsumerRecommendation struct {
+	OrderConcurrency int `json:"order_concurrency"` // 订单队列并发消费者数
+	PrefetchCount    int `json:"prefetch_count"`    // 每个消费者的预取数量
+}
+
+// DBPoolRecommendation 数据库连接池建议
+type DBPoolRecommendation struct {
+	MaxOpenConns int `json:"max_open_conns"`
+	MaxIdleConns int `json:"max_idle_conns"`
+}
+
+// capacityHistory 历史活动的汇总数据
+type capacityHistory struct {
+	sampled      int
+	ordersPerSec float64
+	selloutSecs  float64
+	selloutRatio float64
+}
+
+// PlanCapacity 根据活动库存、单用户限购和相似历史活动的成交速率给出容量规划建议
+func (s *SpikeService) PlanCapacity(ctx context.Context, eventID, perUserLimit int64) (*CapacityPlan, error) {
+	if perUserLimit <= 0 {
+		perUserLimit = 1
+	}
+
+	spikeEvent, err := s.spikeEventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike event: %w", err)
+	}
+
+	history := s.collectCapacityHistory(ctx, spikeEvent)
+	return buildCapacityPlan(spikeEvent, perUserLimit, history), nil
+}
+
+// collectCapacityHistory 统计相似历史活动（优先同商品，其次最近结束的活动）的成交速率
+func (s *SpikeService) collectCapacityHistory(ctx context.Context, event *domain.SpikeEvent) *capacityHistory {
+	candidates, err := s.spikeEventRepo.GetByProductID(ctx, event.ProductID)
+	if err != nil {
+		s.logger.Warn("获取同商品历史活动失败", zap.Int64("product_id", event.ProductID), zap.Error(err))
+	}
+
+	similar := filterEndedEvents(candidates, event.ID)
+	if len(similar) == 0 {
+		status := domain.SpikeEventStatusEnded
+		sortBy, sortOrder := "start_at", "desc"
+		recent, _, err := s.spikeEventRepo.List(ctx, &domain.SpikeEventListRequest{
+			Page:      1,
+			PageSize:  capacityHistoryLimit,
+			Status:    &status,
+			SortBy:    &sortBy,
+			SortOrder: &sortOrder,
+		})
+		if err != nil {
+			s.logger.Warn("获取历史活动失败", zap.Error(err))
+		}
+		similar = filterEndedEvents(recent, event.ID)
+	}
+	if len(similar) > capacityHistoryLimit {
+		similar = similar[:capacityHistoryLimit]
+	}
+
+	history := &capacityHistory{}
+	var totalRate, totalSellout, totalRatio float64
+	for _, past := range similar {
+		// 只取订单数与首末下单时间，热门活动的订单明细可能有数十万条
+		orders, err := s.spikeOrderRepo.GetOrderWindowByEvent(ctx, past.ID)
+		if err != nil {
+			s.logger.Warn("统计历史活动订单失败", zap.Int64("event_id", past.ID), zap.Error(err))
+			continue
+		}
+		if orders.Orders == 0 {
+			continue
+		}
+
+		window := orders.LastAt.Sub(orders.FirstAt)
+		if window < capacityMinWindow {
+			window = capacityMinWindow
+		}
+
+		totalRate += float64(orders.Orders) / window.Seconds()
+		totalSellout += window.Seconds()
+		if past.SpikeStock > 0 {
+			totalRatio += float64(past.SoldCount) / float64(past.SpikeStock)
+		}
+		history.sampled++
+	}
+
+	if history.sampled > 0 {
+		n := float64(history.sampled)
+		history.ordersPerSec = totalRate / n
+		history.selloutSecs = totalSellout / n
+		history.selloutRatio = totalRatio / n
+	}
+
+	return history
+}
+
+// filterEndedEvents 过滤出已结束且不是当前活动的历史活动
+func filterEndedEvents(events []*domain.SpikeEvent, excludeID int64) []*domain.SpikeEvent {
+	var result []*domain.SpikeEvent
+	for _, e := range events {
+		if e.ID != excludeID && e.Status == domain.SpikeEventStatusEnded {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// buildCapacityPlan 根据需求预估计算各项配置建议
+func buildCapacityPlan(event *domain.SpikeEvent, perUserLimit int64, history *capacityHistory) *CapacityPlan {
+	plan := &CapacityPlan{
+		EventID:      event.ID,
+		SpikeStock:   event.SpikeStock,
+		PerUserLimit: perUserLimit,
+	}
+
+	// 最坏情况下每个用户只买一件，成交订单数即库存数；限购越高订单越少
+	plan.ExpectedOrders = event.SpikeStock
+	if perUserLimit > 1 {
+		plan.ExpectedOrders = int64(math.Ceil(float64(event.SpikeStock) / float64(perUserLimit)))
+	}
+
+	sellout := capacityDefaultSellout.Seconds()
+	if history != nil && history.sampled > 0 {
+		plan.HistoricalEventsSampled = history.sampled
+		plan.HistoricalOrdersPerSec = history.ordersPerSec
+		plan.HistoricalSelloutRatio = history.selloutRatio
+		sellout = history.selloutSecs
+	} else {
+		plan.Notes = append(plan.Notes, "无相似历史活动，按默认售罄时长估算")
+	}
+
+	// 以历史速率与按售罄时长推算的速率中较大者作为峰值
+	plan.ExpectedSelloutSeconds = sellout
+	plan.PeakOrdersPerSecond = float64(plan.ExpectedOrders) / sellout
+	if history != nil && history.ordersPerSec > plan.PeakOrdersPerSecond {
+		plan.PeakOrdersPerSecond = history.ordersPerSec
+	}
+	plan.PeakAttemptsPerSecond = plan.PeakOrdersPerSecond * capacityAttemptsPerOrder
+
+	peakWithHeadroom := plan.PeakAttemptsPerSecond * capacityHeadroom
+	plan.Limiter = LimiterRecommendation{
+		GlobalRatePerMinute: ceilInt64(peakWithHeadroom * 60),
+		GlobalBurst:         ceilInt64(peakWithHeadroom),
+		UserRatePerMinute:   perUserLimit * capacityUserRetryPerMinute,
+	}
+
+	concurrency := int(math.Ceil(plan.PeakOrdersPerSecond * capacityOrderLatency.Seconds() * capacityHeadroom))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	plan.Consumer = ConsumerRecommendation{
+		OrderConcurrency: concurrency,
+		PrefetchCount:    10,
+	}
+
+	// 每个消费者事务占用一个连接，读请求按穿透比例估算
+	readConns := int(math.Ceil(peakWithHeadroom * capacityReadRatio * capacityQueryLatency.Seconds()))
+	maxOpen := concurrency + readConns + capacityMinDBConns
+	plan.DatabasePool = DBPoolRecommendation{
+		MaxOpenConns: maxOpen,
+		MaxIdleConns: maxOpen / 2,
+	}
+
+	if plan.HistoricalSelloutRatio > 0 && plan.HistoricalSelloutRatio < 0.5 {
+		plan.Notes = append(plan.Notes, "历史活动售出比例偏低，可适当下调限流阈值")
+	}
+
+	return plan
+}
+
+// ceilInt64 向上取整并至少为1
+func ceilInt64(v float64) int64 {
+	n := int64(math.Ceil(v))
+	if n < 1 {
+		return 1
+	}
+	return n
+}
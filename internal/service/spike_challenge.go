@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+// 防刷挑战相关错误
+var (
+	ErrChallengeDisabled    = errors.New("活动未开启防刷挑战")
+	ErrChallengeNotIssuable = errors.New("活动已结束，不能领取挑战")
+	ErrChallengeRateLimited = errors.New("领取挑战过于频繁，请稍后重试")
+	ErrChallengeRequired    = errors.New("请先完成防刷挑战")
+	ErrChallengeInvalid     = errors.New("防刷挑战未通过或已失效，请重新领取")
+)
+
+// SpikeChallengeStore 防刷挑战存储（由 cache.SpikeCache 实现）
+type SpikeChallengeStore interface {
+	SaveChallenge(ctx context.Context, challengeID, value string, ttl time.Duration) error
+	ConsumeChallenge(ctx context.Context, challengeID string) (string, bool, error)
+}
+
+// SetChallenges 启用防刷挑战：开启 challenge_required 的活动只处理携带已完成挑战令牌的参与请求。
+// issueLimiter 限制单用户领取频率，可为空；difficulty 为哈希需要的前导零位数，ttl 为挑战有效期
+func (s *SpikeService) SetChallenges(store SpikeChallengeStore, issueLimiter limiter.Limiter, difficulty int, ttl time.Duration) {
+	s.challengeStore = store
+	s.challengeLimiter = issueLimiter
+	s.challengeDifficulty = difficulty
+	s.challengeTTL = ttl
+}
+
+// challengeRequired 判断活动是否要求完成防刷挑战
+func (s *SpikeService) challengeRequired(event *domain.SpikeEvent) bool {
+	return s.challengeStore != nil && event.ChallengeRequired
+}
+
+// IssueChallenge 为用户签发一次性的工作量证明挑战，挑战与活动和用户绑定，有效期内未使用自动失效
+func (s *SpikeService) IssueChallenge(ctx context.Context, eventID, userID int64) (*domain.SpikeChallenge, error) {
+	if s.challengeStore == nil {
+		return nil, ErrChallengeDisabled
+	}
+
+	if s.challengeLimiter != nil {
+		result, err := s.challengeLimiter.Allow(ctx, keys.Redis("user", userID))
+		if err != nil {
+			return nil, fmt.Errorf("challenge rate limit check failed: %w", err)
+		}
+		if !result.Allowed {
+			return nil, ErrChallengeRateLimited
+		}
+	}
+
+	event, err := s.getSpikeEventWithCache(ctx, eventID)
+	if err != nil {
+		s.logger.Warn("获取秒杀活动失败", zap.Int64("spike_event_id", eventID), zap.Error(err))
+		return nil, domain.ErrSpikeEventNotFound
+	}
+	if !s.challengeRequired(event) {
+		return nil, ErrChallengeDisabled
+	}
+	if event.IsFinished() {
+		return nil, ErrChallengeNotIssuable
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge id: %w", err)
+	}
+	challengeID := hex.EncodeToString(raw)
+	value := fmt.Sprintf("%d:%d:%d", eventID, userID, s.challengeDifficulty)
+	if err := s.challengeStore.SaveChallenge(ctx, challengeID, value, s.challengeTTL); err != nil {
+		return nil, fmt.Errorf("failed to save challenge: %w", err)
+	}
+
+	s.logger.Debug("签发防刷挑战",
+		logger.UserID(userID),
+		zap.Int64("spike_event_id", eventID),
+		zap.Int("difficulty", s.challengeDifficulty))
+
+	return &domain.SpikeChallenge{
+		ChallengeID:  challengeID,
+		SpikeEventID: eventID,
+		Algorithm:    domain.SpikeChallengeAlgorithmSHA256PoW,
+		Difficulty:   s.challengeDifficulty,
+		ExpiresAt:    time.Now().Add(s.challengeTTL),
+	}, nil
+}
+
+// verifyChallenge 校验并消耗挑战令牌 "<challenge_id>.<nonce>"。
+// 挑战先被取出删除再校验，校验失败的挑战同样作废，避免针对同一挑战反复试探
+func (s *SpikeService) verifyChallenge(ctx context.Context, token string, eventID, userID int64) error {
+	if token == "" {
+		return ErrChallengeRequired
+	}
+	challengeID, nonce, ok := strings.Cut(token, ".")
+	if !ok || challengeID == "" || nonce == "" {
+		return ErrChallengeInvalid
+	}
+
+	value, found, err := s.challengeStore.ConsumeChallenge(ctx, challengeID)
+	if err != nil {
+		return fmt.Errorf("failed to consume challenge: %w", err)
+	}
+	if !found {
+		return ErrChallengeInvalid
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return ErrChallengeInvalid
+	}
+	boundEvent, err1 := strconv.ParseInt(parts[0], 10, 64)
+	boundUser, err2 := strconv.ParseInt(parts[1], 10, 64)
+	difficulty, err3 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil || boundEvent != eventID || boundUser != userID {
+		return ErrChallengeInvalid
+	}
+	if leadingZeroBits(sha256.Sum256([]byte(challengeID+nonce))) < difficulty {
+		return ErrChallengeInvalid
+	}
+	return nil
+}
+
+// leadingZeroBits 返回哈希值的前导零位数
+func leadingZeroBits(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeChallengeStore 内存版挑战存储，取出即删除
+type fakeChallengeStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newFakeChallengeStore() *fakeChallengeStore {
+	return &fakeChallengeStore{values: make(map[string]string)}
+}
+
+func (f *fakeChallengeStore) SaveChallenge(_ context.Context, challengeID, value string, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values[challengeID] = value
+	return nil
+}
+
+func (f *fakeChallengeStore) ConsumeChallenge(_ context.Context, challengeID string) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	value, ok := f.values[challengeID]
+	delete(f.values, challengeID)
+	return value, ok, nil
+}
+
+// solveChallenge 暴力搜索满足难度的 nonce，返回挑战令牌
+func solveChallenge(challenge *domain.SpikeChallenge) string {
+	for nonce := 0; ; nonce++ {
+		n := strconv.Itoa(nonce)
+		if leadingZeroBits(sha256.Sum256([]byte(challenge.ChallengeID+n))) >= challenge.Difficulty {
+			return challenge.ChallengeID + "." + n
+		}
+	}
+}
+
+func TestSpikeService_ParticipateSpike_Challenge(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	guarded := testutil.NewSpikeEventBuilder().Active().RequireChallenge().Build()
+	open := testutil.NewSpikeEventBuilder().Active().Build()
+	testutil.SeedSpikeEvents(t, events, guarded, open)
+
+	svc := NewSpikeService(events, NewMockSpikeOrderRepository(), nil, nil, nil, nil, NewMockSpikeCache(), NewMockSpikeProducer(),
+		NewMockLimiter(true), NewMockLimiter(true), DefaultSpikeServiceConfig(), zap.NewNop())
+	svc.SetChallenges(newFakeChallengeStore(), nil, 16, time.Minute)
+	ctx := context.Background()
+	for _, event := range []*domain.SpikeEvent{guarded, open} {
+		if err := svc.WarmupStock(ctx, event.ID, false); err != nil {
+			t.Fatalf("WarmupStock(%d) error = %v", event.ID, err)
+		}
+	}
+
+	participate := func(eventID, userID int64, token string) *domain.SpikeParticipationResponse {
+		t.Helper()
+		result, err := svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+			SpikeEventID:   eventID,
+			Quantity:       1,
+			IdempotencyKey: "challenge-" + strconv.FormatInt(userID, 10) + "-" + token,
+			ChallengeToken: token,
+		}, userID)
+		if err != nil {
+			t.Fatalf("ParticipateSpike() error = %v", err)
+		}
+		return result
+	}
+
+	if _, err := svc.IssueChallenge(ctx, open.ID, 1); !errors.Is(err, ErrChallengeDisabled) {
+		t.Errorf("IssueChallenge(open) error = %v, want ErrChallengeDisabled", err)
+	}
+	if got := participate(open.ID, 1, ""); !got.Success {
+		t.Errorf("event without challenge: %+v, want success", got)
+	}
+	if got := participate(guarded.ID, 1, ""); got.Code != domain.SpikeParticipationCodeChallengeRequired {
+		t.Errorf("missing challenge: code = %q, want %q", got.Code, domain.SpikeParticipationCodeChallengeRequired)
+	}
+
+	// 挑战绑定用户，其他用户使用无效，且校验失败后挑战作废
+	challenge, err := svc.IssueChallenge(ctx, guarded.ID, 1)
+	if err != nil {
+		t.Fatalf("IssueChallenge() error = %v", err)
+	}
+	token := solveChallenge(challenge)
+	if got := participate(guarded.ID, 2, token); got.Code != domain.SpikeParticipationCodeChallengeRequired {
+		t.Errorf("challenge of another user: code = %q, want %q", got.Code, domain.SpikeParticipationCodeChallengeRequired)
+	}
+	if got := participate(guarded.ID, 1, token); got.Code != domain.SpikeParticipationCodeChallengeRequired {
+		t.Errorf("consumed challenge: code = %q, want %q", got.Code, domain.SpikeParticipationCodeChallengeRequired)
+	}
+
+	challenge, err = svc.IssueChallenge(ctx, guarded.ID, 1)
+	if err != nil {
+		t.Fatalf("IssueChallenge() error = %v", err)
+	}
+	if got := participate(guarded.ID, 1, challenge.ChallengeID+".unsolved"); got.Code != domain.SpikeParticipationCodeChallengeRequired {
+		t.Errorf("unsolved challenge: code = %q, want %q", got.Code, domain.SpikeParticipationCodeChallengeRequired)
+	}
+
+	challenge, err = svc.IssueChallenge(ctx, guarded.ID, 1)
+	if err != nil {
+		t.Fatalf("IssueChallenge() error = %v", err)
+	}
+	if got := participate(guarded.ID, 1, solveChallenge(challenge)); !got.Success {
+		t.Errorf("solved challenge: %+v, want success", got)
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// CleanupEventSource 提供指定时间范围内的活动（由 repo.SpikeEventRepository 实现）
+type CleanupEventSource interface {
+	GetEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*domain.SpikeEvent, error)
+}
+
+// CleanupOrderSource 提供活动的订单，用于定位用户参与标记（由 repo.SpikeOrderRepository 实现）
+type CleanupOrderSource interface {
+	GetBySpikeEventID(ctx context.Context, spikeEventID int64) ([]*domain.SpikeOrder, error)
+}
+
+// EventKeyPurger 删除活动的 Redis 键（由 cache.SpikeCache 实现）
+type EventKeyPurger interface {
+	PurgeEvent(ctx context.Context, eventID int64, userIDs []int64) (int64, error)
+}
+
+// EventWaiterReleaser 结束等待活动库存变化的请求（由 StockWatcher 实现）
+type EventWaiterReleaser interface {
+	ReleaseEvent(eventID int64) int
+}
+
+// EventViewEvictor 删除活动的缓存视图（由 PublicSpikeCatalog 实现）
+type EventViewEvictor interface {
+	EvictEvent(ctx context.Context, eventID int64) error
+}
+
+// EventCleanupConfig 活动资源清理配置
+type EventCleanupConfig struct {
+	Interval    time.Duration // 清理任务执行间隔
+	GracePeriod time.Duration // 活动结束后等待多久再清理，应覆盖订单支付期限，避免过期订单归还库存时重建已删除的键
+	Lookback    time.Duration // 只清理结束多久以内的活动，更早的活动视为已清理
+}
+
+// DefaultEventCleanupConfig 默认活动资源清理配置
+func DefaultEventCleanupConfig() *EventCleanupConfig {
+	return &EventCleanupConfig{
+		Interval:    10 * time.Minute,
+		GracePeriod: time.Hour,
+		Lookback:    24 * time.Hour,
+	}
+}
+
+// EventCleanupStats 活动资源清理统计
+type EventCleanupStats struct {
+	EventsCleaned   int64 `json:"events_cleaned"`   // 已清理的活动数
+	KeysReclaimed   int64 `json:"keys_reclaimed"`   // 删除的 Redis 键数
+	WaitersReleased int64 `json:"waiters_released"` // 结束的库存等待请求数
+	Failures        int64 `json:"failures"`         // 清理失败次数
+}
+
+// EventCleanupWorker 定时清理已结束活动的资源：Redis 中的库存、售罄标记、活动信息缓存与用户参与标记，
+// 进程内等待库存变化的请求以及匿名只读视图缓存，避免活动越来越多时 Redis 无限增长。
+// 每个活动在本进程内只清理一次；多实例重复清理只会删除不存在的键，结果相同
+type EventCleanupWorker struct {
+	events  CleanupEventSource
+	orders  CleanupOrderSource
+	keys    EventKeyPurger
+	waiters EventWaiterReleaser // 可为空
+	views   EventViewEvictor    // 可为空
+	config  *EventCleanupConfig
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	cleaned map[int64]time.Time // 已清理的活动及其结束时间，超出回看窗口后移除
+
+	eventsCleaned   atomic.Int64
+	keysReclaimed   atomic.Int64
+	waitersReleased atomic.Int64
+	failures        atomic.Int64
+}
+
+// NewEventCleanupWorker 创建活动资源清理任务
+func NewEventCleanupWorker(events CleanupEventSource, orders CleanupOrderSource, keys EventKeyPurger, config *EventCleanupConfig, logger *zap.Logger) *EventCleanupWorker {
+	if config == nil {
+		config = DefaultEventCleanupConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &EventCleanupWorker{
+		events:  events,
+		orders:  orders,
+		keys:    keys,
+		config:  config,
+		logger:  logger,
+		cleaned: make(map[int64]time.Time),
+	}
+}
+
+// SetWaiterReleaser 设置库存等待请求的释放方，为空时不处理
+func (w *EventCleanupWorker) SetWaiterReleaser(r EventWaiterReleaser) {
+	w.waiters = r
+}
+
+// SetViewEvictor 设置缓存视图的删除方，为空时不处理
+func (w *EventCleanupWorker) SetViewEvictor(e EventViewEvictor) {
+	w.views = e
+}
+
+// Stats 返回清理统计
+func (w *EventCleanupWorker) Stats() EventCleanupStats {
+	return EventCleanupStats{
+		EventsCleaned:   w.eventsCleaned.Load(),
+		KeysReclaimed:   w.keysReclaimed.Load(),
+		WaitersReleased: w.waitersReleased.Load(),
+		Failures:        w.failures.Load(),
+	}
+}
+
+// Start 异步启动清理循环，ctx 取消时退出
+func (w *EventCleanupWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+
+		w.logger.Info("活动资源清理任务已启动",
+			zap.Duration("interval", w.config.Interval),
+			zap.Duration("grace_period", w.config.GracePeriod),
+			zap.Duration("lookback", w.config.Lookback))
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("活动资源清理任务已停止")
+				return
+			case <-ticker.C:
+				if _, err := w.RunOnce(ctx); err != nil {
+					w.logger.Warn("活动资源清理失败", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce 清理一轮，返回本轮清理的活动数
+func (w *EventCleanupWorker) RunOnce(ctx context.Context) (int, error) {
+	until := time.Now().Add(-w.config.GracePeriod)
+	since := until.Add(-w.config.Lookback)
+	events, err := w.events.GetEventsByTimeRange(ctx, since, until)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ended events: %w", err)
+	}
+	w.forgetBefore(since)
+
+	cleaned := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		// 时间范围查询包含与窗口重叠的活动，只清理结束时间已过宽限期的活动
+		if event.EndAt.After(until) || w.isCleaned(event.ID) {
+			continue
+		}
+
+		if err := w.cleanupEvent(ctx, event); err != nil {
+			w.failures.Add(1)
+			w.logger.Warn("清理活动资源失败", zap.Int64("spike_event_id", event.ID), zap.Error(err))
+			continue
+		}
+		w.markCleaned(event)
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// cleanupEvent 清理单个活动的资源
+func (w *EventCleanupWorker) cleanupEvent(ctx context.Context, event *domain.SpikeEvent) error {
+	orders, err := w.orders.GetBySpikeEventID(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get event orders: %w", err)
+	}
+	userIDs := make([]int64, 0, len(orders))
+	seen := make(map[int64]struct{}, len(orders))
+	for _, order := range orders {
+		if _, ok := seen[order.UserID]; ok {
+			continue
+		}
+		seen[order.UserID] = struct{}{}
+		userIDs = append(userIDs, order.UserID)
+	}
+
+	reclaimed, err := w.keys.PurgeEvent(ctx, event.ID, userIDs)
+	if err != nil {
+		return err
+	}
+
+	released := 0
+	if w.waiters != nil {
+		released = w.waiters.ReleaseEvent(event.ID)
+	}
+	if w.views != nil {
+		// 缓存视图有较短的过期时间，删除失败不影响本次清理
+		if err := w.views.EvictEvent(ctx, event.ID); err != nil {
+			w.logger.Warn("删除活动缓存视图失败", zap.Int64("spike_event_id", event.ID), zap.Error(err))
+		}
+	}
+
+	w.eventsCleaned.Add(1)
+	w.keysReclaimed.Add(reclaimed)
+	w.waitersReleased.Add(int64(released))
+	w.logger.Info("活动资源已清理",
+		zap.Int64("spike_event_id", event.ID),
+		zap.Int64("keys_reclaimed", reclaimed),
+		zap.Int("waiters_released", released))
+	return nil
+}
+
+func (w *EventCleanupWorker) isCleaned(eventID int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.cleaned[eventID]
+	return ok
+}
+
+func (w *EventCleanupWorker) markCleaned(event *domain.SpikeEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cleaned[event.ID] = event.EndAt
+}
+
+// forgetBefore 移除结束时间早于回看窗口的清理记录，这些活动不会再被查询到
+func (w *EventCleanupWorker) forgetBefore(since time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for eventID, endAt := range w.cleaned {
+		if endAt.Before(since) {
+			delete(w.cleaned, eventID)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// stubEventOrders 按活动返回固定订单
+type stubEventOrders map[int64][]*domain.SpikeOrder
+
+func (s stubEventOrders) GetBySpikeEventID(ctx context.Context, spikeEventID int64) ([]*domain.SpikeOrder, error) {
+	return s[spikeEventID], nil
+}
+
+// recordingPurger 记录被清理的活动及其用户，每个键按 1 个计数
+type recordingPurger struct {
+	users map[int64][]int64
+}
+
+func (p *recordingPurger) PurgeEvent(ctx context.Context, eventID int64, userIDs []int64) (int64, error) {
+	p.users[eventID] = userIDs
+	return int64(6 + len(userIDs)), nil
+}
+
+// recordingReleaser 记录被结束等待的活动
+type recordingReleaser struct {
+	released []int64
+}
+
+func (r *recordingReleaser) ReleaseEvent(eventID int64) int {
+	r.released = append(r.released, eventID)
+	return 2
+}
+
+// recordingEvictor 记录被删除缓存视图的活动
+type recordingEvictor struct {
+	evicted []int64
+}
+
+func (e *recordingEvictor) EvictEvent(ctx context.Context, eventID int64) error {
+	e.evicted = append(e.evicted, eventID)
+	return nil
+}
+
+func TestEventCleanupWorker_RunOnce(t *testing.T) {
+	now := time.Now()
+	events := stubRangeEvents{
+		{ID: 1, EndAt: now.Add(-2 * time.Hour)},    // 已过宽限期
+		{ID: 2, EndAt: now.Add(-10 * time.Minute)}, // 仍有待支付订单可能归还库存
+	}
+	orders := stubEventOrders{
+		1: {{UserID: 10}, {UserID: 11}, {UserID: 10}},
+	}
+	purger := &recordingPurger{users: make(map[int64][]int64)}
+	releaser := &recordingReleaser{}
+	evictor := &recordingEvictor{}
+	worker := NewEventCleanupWorker(events, orders, purger, &EventCleanupConfig{
+		Interval: time.Minute, GracePeriod: time.Hour, Lookback: 24 * time.Hour,
+	}, nil)
+	worker.SetWaiterReleaser(releaser)
+	worker.SetViewEvictor(evictor)
+
+	cleaned, err := worker.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if cleaned != 1 || len(purger.users) != 1 {
+		t.Fatalf("RunOnce() cleaned %d events %v, want only event 1", cleaned, purger.users)
+	}
+	if users := purger.users[1]; len(users) != 2 || users[0] != 10 || users[1] != 11 {
+		t.Errorf("purged users = %v, want deduplicated [10 11]", users)
+	}
+	if len(releaser.released) != 1 || releaser.released[0] != 1 {
+		t.Errorf("released waiters of %v, want [1]", releaser.released)
+	}
+	if len(evictor.evicted) != 1 || evictor.evicted[0] != 1 {
+		t.Errorf("evicted views of %v, want [1]", evictor.evicted)
+	}
+
+	// 已清理的活动不再重复清理
+	if cleaned, err := worker.RunOnce(context.Background()); err != nil || cleaned != 0 {
+		t.Errorf("RunOnce() again = %d, %v, want 0", cleaned, err)
+	}
+
+	stats := worker.Stats()
+	want := EventCleanupStats{EventsCleaned: 1, KeysReclaimed: 8, WaitersReleased: 2}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
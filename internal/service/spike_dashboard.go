@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+const (
+	// DefaultSpikeDashboardCacheTTL 秒杀总览的默认缓存有效期
+	DefaultSpikeDashboardCacheTTL = 15 * time.Second
+	// SpikeDashboardTopEvents 总览返回的热销活动数
+	SpikeDashboardTopEvents = 10
+
+	spikeDashboardCacheKey = "spike:admin:dashboard"
+)
+
+// SpikeDashboardService 定义管理后台秒杀总览服务接口
+type SpikeDashboardService interface {
+	// GetDashboard 获取跨活动的聚合统计，结果按 ttl 缓存
+	GetDashboard(ctx context.Context) (*domain.SpikeDashboard, error)
+}
+
+// spikeDashboardService 是SpikeDashboardService接口的实现
+type spikeDashboardService struct {
+	repo   repo.SpikeDashboardRepository
+	cache  cache.Cache
+	ttl    time.Duration
+	loads  singleflight.Group
+	logger *zap.Logger
+}
+
+// NewSpikeDashboardService 创建秒杀总览服务。
+// 总览由三条聚合查询组成，缓存未命中时只有一个请求回源，多个管理员同时刷新页面不会放大数据库压力。
+// ttl 不大于0时使用 DefaultSpikeDashboardCacheTTL。
+func NewSpikeDashboardService(dashboardRepo repo.SpikeDashboardRepository, c cache.Cache, ttl time.Duration, logger *zap.Logger) SpikeDashboardService {
+	if c == nil {
+		c = cache.NewMemoryCache()
+	}
+	if ttl <= 0 {
+		ttl = DefaultSpikeDashboardCacheTTL
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeDashboardService{
+		repo:   dashboardRepo,
+		cache:  c,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// GetDashboard 获取秒杀总览
+func (s *spikeDashboardService) GetDashboard(ctx context.Context) (*domain.SpikeDashboard, error) {
+	var dashboard domain.SpikeDashboard
+	if err := s.cache.Get(ctx, spikeDashboardCacheKey, &dashboard); err == nil {
+		return &dashboard, nil
+	}
+
+	v, err, _ := s.loads.Do(spikeDashboardCacheKey, func() (interface{}, error) {
+		dashboard, err := s.build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.cache.Set(ctx, spikeDashboardCacheKey, dashboard, s.ttl); err != nil {
+			s.logger.Warn("缓存秒杀总览失败", zap.Error(err))
+		}
+		return dashboard, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spike dashboard: %w", err)
+	}
+	return v.(*domain.SpikeDashboard), nil
+}
+
+// build 查询聚合数据并计算总量、转化率与售罄率
+func (s *spikeDashboardService) build(ctx context.Context) (*domain.SpikeDashboard, error) {
+	eventCounts, err := s.repo.CountEventsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orderStats, err := s.repo.SumOrdersByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	topEvents, err := s.repo.ListTopEvents(ctx, SpikeDashboardTopEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &domain.SpikeDashboard{
+		EventsByStatus: make(map[domain.SpikeEventStatus]int64),
+		OrdersByStatus: make([]*domain.SpikeDashboardOrderStats, 0, 4),
+		TopEvents:      topEvents,
+		GeneratedAt:    time.Now(),
+	}
+	if dashboard.TopEvents == nil {
+		dashboard.TopEvents = []*domain.SpikeDashboardEvent{}
+	}
+
+	for _, status := range []domain.SpikeEventStatus{
+		domain.SpikeEventStatusDraft,
+		domain.SpikeEventStatusScheduled,
+		domain.SpikeEventStatusPending,
+		domain.SpikeEventStatusActive,
+		domain.SpikeEventStatusPaused,
+		domain.SpikeEventStatusEnded,
+		domain.SpikeEventStatusCancelled,
+	} {
+		dashboard.EventsByStatus[status] = eventCounts[status]
+	}
+	for _, count := range eventCounts {
+		dashboard.TotalEvents += count
+	}
+	dashboard.ActiveEvents = eventCounts[domain.SpikeEventStatusActive]
+
+	byStatus := make(map[domain.SpikeOrderStatus]*domain.SpikeDashboardOrderStats, len(orderStats))
+	for _, stats := range orderStats {
+		byStatus[stats.Status] = stats
+		dashboard.TotalOrders += stats.Orders
+	}
+	for _, status := range []domain.SpikeOrderStatus{
+		domain.SpikeOrderStatusPending,
+		domain.SpikeOrderStatusPaid,
+		domain.SpikeOrderStatusCancelled,
+		domain.SpikeOrderStatusExpired,
+	} {
+		stats := byStatus[status]
+		if stats == nil {
+			stats = &domain.SpikeDashboardOrderStats{Status: status}
+		}
+		dashboard.OrdersByStatus = append(dashboard.OrdersByStatus, stats)
+	}
+
+	if paid := byStatus[domain.SpikeOrderStatusPaid]; paid != nil {
+		dashboard.PaidQuantity = paid.Quantity
+		dashboard.Revenue = paid.Amount
+		dashboard.ConversionRate = percent(paid.Orders, dashboard.TotalOrders)
+	}
+	for _, event := range dashboard.TopEvents {
+		event.SellThrough = percent(event.SoldCount, event.SpikeStock)
+	}
+
+	return dashboard, nil
+}
+
+// percent 返回 part 占 total 的百分比，保留两位小数，total 为0时返回0
+func percent(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// stubDashboardRepo 返回固定聚合结果并统计回源次数的总览仓储桩
+type stubDashboardRepo struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (r *stubDashboardRepo) CountEventsByStatus(ctx context.Context) (map[domain.SpikeEventStatus]int64, error) {
+	r.calls.Add(1)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return map[domain.SpikeEventStatus]int64{
+		domain.SpikeEventStatusActive: 2,
+		domain.SpikeEventStatusEnded:  5,
+	}, nil
+}
+
+func (r *stubDashboardRepo) SumOrdersByStatus(ctx context.Context) ([]*domain.SpikeDashboardOrderStats, error) {
+	return []*domain.SpikeDashboardOrderStats{
+		{Status: domain.SpikeOrderStatusPaid, Orders: 3, Quantity: 4, Amount: 396},
+		{Status: domain.SpikeOrderStatusExpired, Orders: 1, Quantity: 1, Amount: 99},
+	}, nil
+}
+
+func (r *stubDashboardRepo) ListTopEvents(ctx context.Context, limit int) ([]*domain.SpikeDashboardEvent, error) {
+	return []*domain.SpikeDashboardEvent{
+		{SpikeEventID: 1, SpikeStock: 3, SoldCount: 2, PaidOrders: 1, Revenue: 99},
+		{SpikeEventID: 2, SpikeStock: 0, SoldCount: 0},
+	}, nil
+}
+
+func TestSpikeDashboardService_GetDashboard(t *testing.T) {
+	ctx := context.Background()
+	dashboardRepo := &stubDashboardRepo{}
+	svc := NewSpikeDashboardService(dashboardRepo, cache.NewMemoryCache(), time.Minute, nil)
+
+	for range 3 {
+		dashboard, err := svc.GetDashboard(ctx)
+		if err != nil {
+			t.Fatalf("GetDashboard() error = %v", err)
+		}
+		if dashboard.TotalEvents != 7 || dashboard.ActiveEvents != 2 || dashboard.EventsByStatus[domain.SpikeEventStatusPending] != 0 {
+			t.Errorf("events = total %d, active %d, by status %v", dashboard.TotalEvents, dashboard.ActiveEvents, dashboard.EventsByStatus)
+		}
+		if dashboard.TotalOrders != 4 || dashboard.PaidQuantity != 4 || dashboard.Revenue != 396 || dashboard.ConversionRate != 75 {
+			t.Errorf("orders = total %d, paid quantity %d, revenue %v, conversion %v",
+				dashboard.TotalOrders, dashboard.PaidQuantity, dashboard.Revenue, dashboard.ConversionRate)
+		}
+		// 状态分布按固定顺序返回，没有订单的状态补 0
+		if len(dashboard.OrdersByStatus) != 4 || dashboard.OrdersByStatus[0].Status != domain.SpikeOrderStatusPending ||
+			dashboard.OrdersByStatus[0].Orders != 0 || dashboard.OrdersByStatus[1].Orders != 3 {
+			t.Errorf("OrdersByStatus = %+v", dashboard.OrdersByStatus)
+		}
+		if len(dashboard.TopEvents) != 2 || dashboard.TopEvents[0].SellThrough != 66.67 || dashboard.TopEvents[1].SellThrough != 0 {
+			t.Errorf("TopEvents = %+v", dashboard.TopEvents)
+		}
+	}
+	if got := dashboardRepo.calls.Load(); got != 1 {
+		t.Errorf("repo calls = %d, want 1", got)
+	}
+}
+
+func TestSpikeDashboardService_RepoError(t *testing.T) {
+	dbErr := errors.New("db down")
+	svc := NewSpikeDashboardService(&stubDashboardRepo{err: dbErr}, cache.NewMemoryCache(), time.Minute, nil)
+
+	if _, err := svc.GetDashboard(context.Background()); !errors.Is(err, dbErr) {
+		t.Errorf("GetDashboard() error = %v, want %v", err, dbErr)
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/breaker"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// 库存缓存不可用时的降级策略，取值见 SpikeServiceConfig.DegradedMode
+const (
+	DegradedModeOff   = "off"   // 不降级，返回系统繁忙
+	DegradedModeQueue = "queue" // 返回排队提示，客户端稍后重试
+	DegradedModeDB    = "db"    // 在并发上限内以数据库条件更新扣减库存
+)
+
+// degradedRewarmTimeout 缓存恢复后重新预热降级活动的超时时间
+const degradedRewarmTimeout = 30 * time.Second
+
+// degradedState 降级参与期间的进程内状态
+type degradedState struct {
+	// 同时走数据库扣减的请求槽位
+	slots chan struct{}
+
+	mu           sync.Mutex
+	events       map[int64]struct{}           // 降级期间扣减过数据库库存的活动，缓存恢复后强制重新预热
+	participants map[degradedParticipant]bool // 降级期间已参与的用户，Redis 参与标记不可用时防止重复参与
+}
+
+type degradedParticipant struct {
+	eventID int64
+	userID  int64
+}
+
+// SetCacheBreaker 设置库存缓存熔断器：缓存连续失败后熔断，熔断期间按 DegradedMode 降级参与，
+// 熔断恢复后强制重新预热降级期间扣减过库存的活动。为空时缓存失败直接返回系统繁忙
+func (s *SpikeService) SetCacheBreaker(b *breaker.Breaker) {
+	s.cacheBreaker = b
+	s.degraded = &degradedState{
+		slots:        make(chan struct{}, max(s.cfg().DegradedMaxConcurrency, 1)),
+		events:       make(map[int64]struct{}),
+		participants: make(map[degradedParticipant]bool),
+	}
+	b.OnStateChange(func(name string, from, to breaker.State) {
+		s.logger.Warn("库存缓存熔断器状态变化",
+			zap.String("breaker", name),
+			zap.String("from", from.String()),
+			zap.String("to", to.String()))
+		if to == breaker.StateClosed {
+			go s.rewarmDegradedEvents()
+		}
+	})
+}
+
+// cacheOpen 判断库存缓存是否处于熔断中，熔断期间跳过依赖 Redis 的限流与活动缓存
+func (s *SpikeService) cacheOpen() bool {
+	return s.cacheBreaker != nil && s.cacheBreaker.State() == breaker.StateOpen
+}
+
+// guardCache 在库存缓存熔断器保护下执行缓存调用，未设置熔断器时直接执行
+func (s *SpikeService) guardCache(fn func() error) error {
+	if s.cacheBreaker == nil {
+		return fn()
+	}
+	return s.cacheBreaker.Do(fn)
+}
+
+// participateDegraded 库存缓存不可用时的降级参与。
+// queue 模式直接返回排队提示；db 模式在并发上限内以数据库条件更新占用已售数量，再发送标记了已占用的下单消息。
+// 排队、防刷挑战、专场限购与单用户累计件数的状态保存在 Redis 中，这类活动在 db 模式下同样返回排队提示
+func (s *SpikeService) participateDegraded(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64, spikeEvent *domain.SpikeEvent, policy TierPolicy, traceID string, logger *zap.Logger) (*domain.SpikeParticipationResponse, error) {
+	busy := domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeBusy, "当前参与人数较多，正在排队处理，请稍后重试")
+	switch s.cfg().DegradedMode {
+	case DegradedModeDB:
+	case DegradedModeQueue:
+		logger.Warn("库存缓存不可用，返回排队提示")
+		return busy, nil
+	default:
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+	}
+	if s.waitingRoomEnabled(spikeEvent) || s.challengeRequired(spikeEvent) || spikeEvent.SpikeCampaignID != nil ||
+		spikeEvent.UserQuantityLimit() > 0 {
+		logger.Warn("库存缓存不可用且活动依赖缓存状态，返回排队提示")
+		return busy, nil
+	}
+
+	select {
+	case s.degraded.slots <- struct{}{}:
+		defer func() { <-s.degraded.slots }()
+	default:
+		logger.Warn("降级参与并发已满，返回排队提示")
+		return busy, nil
+	}
+
+	// Redis 参与标记不可用：以进程内标记与已落库订单判断重复参与
+	participant := degradedParticipant{eventID: spikeEvent.ID, userID: userID}
+	if !s.degraded.markParticipant(participant) {
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeAlreadyParticipated, "您已参与过该活动"), nil
+	}
+	count, err := s.spikeOrderRepo.CountByUserAndEvent(ctx, userID, spikeEvent.ID)
+	if err != nil {
+		s.degraded.unmarkParticipant(participant)
+		logger.Error("查询用户参与记录失败", zap.Error(err))
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+	}
+	if count > 0 {
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeAlreadyParticipated, "您已参与过该活动"), nil
+	}
+
+	reserved, err := s.spikeEventRepo.AddSoldCount(ctx, spikeEvent.ID, req.Quantity)
+	if err != nil {
+		s.degraded.unmarkParticipant(participant)
+		logger.Error("数据库扣减库存失败", zap.Error(err))
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+	}
+	if !reserved {
+		s.degraded.unmarkParticipant(participant)
+		logger.Info("数据库扣减库存失败，库存不足")
+		return s.degradedStockFailure(ctx, spikeEvent.ID), nil
+	}
+	s.degraded.markEvent(spikeEvent.ID)
+
+	orderData, err := s.newOrderCreatedData(req, userID, spikeEvent, policy)
+	if err == nil {
+		orderData.SoldCountReserved = true
+		err = s.publishOrderCreated(ctx, orderData, traceID)
+	}
+	if err != nil {
+		logger.Error("发送订单创建消息失败", zap.Error(err))
+		s.degraded.unmarkParticipant(participant)
+		if _, releaseErr := s.spikeEventRepo.AddSoldCount(ctx, spikeEvent.ID, -req.Quantity); releaseErr != nil {
+			logger.Error("归还已售数量失败", zap.Error(releaseErr))
+		}
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+	}
+
+	// 降级订单已在数据库占用已售数量，不写参与日志，避免回放时重复累加
+	if s.shareAttribution != nil && req.ShareToken != "" {
+		s.shareAttribution.RecordParticipation(ctx, req.ShareToken, req.SpikeEventID)
+	}
+
+	logger.Warn("降级参与成功", zap.String("order_no", orderData.OrderNo))
+	return &domain.SpikeParticipationResponse{
+		Success: true,
+		Message: "秒杀成功，请尽快完成支付",
+		OrderNo: orderData.OrderNo,
+	}, nil
+}
+
+// degradedStockFailure 数据库扣减失败时区分已售罄与剩余库存不足
+func (s *SpikeService) degradedStockFailure(ctx context.Context, eventID int64) *domain.SpikeParticipationResponse {
+	if event, err := s.spikeEventRepo.GetByID(ctx, eventID); err == nil && event.SoldCount < event.SpikeStock {
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInsufficientStock, "剩余库存不足")
+	}
+	return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeSoldOut, "商品已售罄")
+}
+
+// rewarmDegradedEvents 缓存恢复后以数据库为准强制重新预热降级期间扣减过库存的活动。
+// 先占满降级槽位，等待进行中的数据库扣减完成，避免预热读到的已售数量遗漏这些请求；预热失败的活动留待下次恢复
+func (s *SpikeService) rewarmDegradedEvents() {
+	for range cap(s.degraded.slots) {
+		s.degraded.slots <- struct{}{}
+	}
+	defer func() {
+		for range cap(s.degraded.slots) {
+			<-s.degraded.slots
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), degradedRewarmTimeout)
+	defer cancel()
+
+	for _, eventID := range s.degraded.takeEvents() {
+		if err := s.WarmupStock(ctx, eventID, true); err != nil {
+			s.logger.Error("缓存恢复后重新预热库存失败", zap.Int64("event_id", eventID), zap.Error(err))
+			s.degraded.markEvent(eventID)
+		}
+	}
+}
+
+// markParticipant 标记用户已降级参与，已标记时返回 false
+func (d *degradedState) markParticipant(p degradedParticipant) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.participants[p] {
+		return false
+	}
+	d.participants[p] = true
+	return true
+}
+
+// unmarkParticipant 降级参与失败时撤销标记
+func (d *degradedState) unmarkParticipant(p degradedParticipant) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.participants, p)
+}
+
+// markEvent 记录降级期间扣减过库存的活动
+func (d *degradedState) markEvent(eventID int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events[eventID] = struct{}{}
+}
+
+// takeEvents 取出待重新预热的活动；重新预热后 Redis 参与标记恢复生效，同时清空进程内参与标记
+func (d *degradedState) takeEvents() []int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	eventIDs := make([]int64, 0, len(d.events))
+	for eventID := range d.events {
+		eventIDs = append(eventIDs, eventID)
+	}
+	d.events = make(map[int64]struct{})
+	d.participants = make(map[degradedParticipant]bool)
+	return eventIDs
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/breaker"
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// newDegradedSpikeService 创建库存缓存不可用（GetStockInfo 始终失败）且首次失败即熔断的秒杀服务
+func newDegradedSpikeService(t *testing.T, mode string, events ...*domain.SpikeEvent) (*SpikeService, *MockSpikeEventRepository, *MockSpikeCache, *MockSpikeProducer, *MockLimiter) {
+	t.Helper()
+	eventRepo := NewMockSpikeEventRepository()
+	testutil.SeedSpikeEvents(t, eventRepo, events...)
+
+	spikeCache := NewMockSpikeCache()
+	spikeCache.GetStockInfoFunc = func(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+		return nil, errors.New("redis: connection refused")
+	}
+	producer := NewMockSpikeProducer()
+	globalLimiter := NewMockLimiter(true)
+
+	config := DefaultSpikeServiceConfig()
+	config.DegradedMode = mode
+	config.DegradedMaxConcurrency = 2
+	svc := NewSpikeService(eventRepo, NewMockSpikeOrderRepository(), nil, nil, nil, nil, spikeCache, producer,
+		globalLimiter, NewMockLimiter(true), config, zap.NewNop())
+	svc.SetCacheBreaker(breaker.New("spike_cache", breaker.Config{FailureThreshold: 1, OpenTimeout: time.Hour}))
+	return svc, eventRepo, spikeCache, producer, globalLimiter
+}
+
+func participateDegradedOnce(t *testing.T, svc *SpikeService, eventID, userID, quantity int64) *domain.SpikeParticipationResponse {
+	t.Helper()
+	result, err := svc.ParticipateSpike(context.Background(), &domain.SpikeParticipationRequest{
+		SpikeEventID:   eventID,
+		Quantity:       quantity,
+		IdempotencyKey: fmt.Sprintf("degraded-%d-%d", eventID, userID),
+	}, userID)
+	if err != nil {
+		t.Fatalf("ParticipateSpike() error = %v", err)
+	}
+	return result
+}
+
+func TestSpikeService_ParticipateDegradedDB(t *testing.T) {
+	event := testutil.NewSpikeEventBuilder().Active().WithStock(2).Build()
+	svc, eventRepo, spikeCache, producer, globalLimiter := newDegradedSpikeService(t, DegradedModeDB, event)
+
+	if got := participateDegradedOnce(t, svc, event.ID, 1, 1); !got.Success || got.OrderNo == "" {
+		t.Fatalf("first participation = %+v, want success through database", got)
+	}
+	// 熔断后不再访问缓存与限流器
+	globalLimiter.SetShouldAllow(false)
+
+	if got := participateDegradedOnce(t, svc, event.ID, 1, 1); got.Code != domain.SpikeParticipationCodeAlreadyParticipated {
+		t.Errorf("repeat participation code = %q, want %q", got.Code, domain.SpikeParticipationCodeAlreadyParticipated)
+	}
+	if got := participateDegradedOnce(t, svc, event.ID, 2, 2); got.Code != domain.SpikeParticipationCodeInsufficientStock {
+		t.Errorf("over remaining stock code = %q, want %q", got.Code, domain.SpikeParticipationCodeInsufficientStock)
+	}
+	if got := participateDegradedOnce(t, svc, event.ID, 2, 1); !got.Success {
+		t.Errorf("last unit = %+v, want success", got)
+	}
+	if got := participateDegradedOnce(t, svc, event.ID, 3, 1); got.Code != domain.SpikeParticipationCodeSoldOut {
+		t.Errorf("after sell-out code = %q, want %q", got.Code, domain.SpikeParticipationCodeSoldOut)
+	}
+
+	stored, _ := eventRepo.GetByID(context.Background(), event.ID)
+	if stored.SoldCount != 2 {
+		t.Errorf("sold count = %d, want 2", stored.SoldCount)
+	}
+	if calls := len(spikeCache.GetStockInfoCalls()); calls != 1 {
+		t.Errorf("GetStockInfo calls = %d, want 1 before the breaker opened", calls)
+	}
+	if len(producer.publishedMessages) != 2 {
+		t.Fatalf("published %d messages, want 2", len(producer.publishedMessages))
+	}
+	for _, message := range producer.publishedMessages {
+		if data := message.(*mq.SpikeOrderCreatedData); !data.SoldCountReserved {
+			t.Errorf("message %+v not marked as sold count reserved", data)
+		}
+	}
+}
+
+func TestSpikeService_ParticipateDegradedQueue(t *testing.T) {
+	event := testutil.NewSpikeEventBuilder().Active().WithStock(10).Build()
+	campaignEvent := testutil.NewSpikeEventBuilder().Active().WithStock(10).InCampaign(1).Build()
+
+	svc, eventRepo, _, producer, _ := newDegradedSpikeService(t, DegradedModeQueue, event)
+	got := participateDegradedOnce(t, svc, event.ID, 1, 1)
+	if got.Code != domain.SpikeParticipationCodeBusy || !got.Retryable || got.RetryAfterMs != 2000 {
+		t.Errorf("queue mode = %+v, want retryable busy after 2s", got)
+	}
+	if stored, _ := eventRepo.GetByID(context.Background(), event.ID); stored.SoldCount != 0 || len(producer.publishedMessages) != 0 {
+		t.Errorf("queue mode changed stock: sold = %d, published = %d", stored.SoldCount, len(producer.publishedMessages))
+	}
+
+	// db 模式下专场限购依赖 Redis，同样返回排队提示
+	svc, _, _, producer, _ = newDegradedSpikeService(t, DegradedModeDB, campaignEvent)
+	if got := participateDegradedOnce(t, svc, campaignEvent.ID, 1, 1); got.Code != domain.SpikeParticipationCodeBusy {
+		t.Errorf("campaign event in db mode code = %q, want %q", got.Code, domain.SpikeParticipationCodeBusy)
+	}
+	if len(producer.publishedMessages) != 0 {
+		t.Errorf("campaign event published %d messages, want 0", len(producer.publishedMessages))
+	}
+}
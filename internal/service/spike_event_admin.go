@@ -0,0 +1,578 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+const (
+	// eventExportPageSize 导出活动时每次分页查询的行数
+	eventExportPageSize = 500
+	// eventExportMaxRows 单次导出的最大活动数，超出部分截断
+	eventExportMaxRows = 10000
+	// eventImportMaxStockBuckets 导入活动允许的最大库存分桶数，与 cache.MaxStockBuckets 一致
+	eventImportMaxStockBuckets = 64
+)
+
+// ImportProductSource 校验导入活动引用的商品（由 repo.ProductRepository 实现）
+type ImportProductSource interface {
+	GetByIDs(ids []int64) ([]*domain.Product, error)
+}
+
+// ImportVariantSource 校验导入活动引用的商品规格（由 repo.ProductVariantRepository 实现）
+type ImportVariantSource interface {
+	GetByIDs(ctx context.Context, ids []int64) ([]*domain.ProductVariant, error)
+}
+
+// SpikeEventAdminService 定义秒杀活动批量管理服务接口
+type SpikeEventAdminService interface {
+	// ImportEvents 解析 CSV 或 JSON 数组格式的活动并逐行校验；全部通过且非试运行时在同一事务中创建所有活动，
+	// 任一行不通过时整批不创建。文件本身无法解析时返回 domain.ErrInvalidArgument 分类的错误
+	ImportEvents(ctx context.Context, r io.Reader, format string, dryRun bool) (*domain.SpikeEventImportResult, error)
+	// ExportEvents 按过滤条件导出活动，格式与导入相同；忽略分页参数，超过导出上限时截断并返回 true
+	ExportEvents(ctx context.Context, req *domain.SpikeEventListRequest, format string, w io.Writer) (bool, error)
+}
+
+// spikeEventAdminService 是SpikeEventAdminService接口的实现
+type spikeEventAdminService struct {
+	events   repo.SpikeEventRepository
+	products ImportProductSource
+	logger   *zap.Logger
+
+	// 商品规格，可为空，为空时拒绝指定了 variant_id 的行
+	variants ImportVariantSource
+}
+
+// SpikeEventAdminServiceOption 秒杀活动批量管理服务可选配置
+type SpikeEventAdminServiceOption func(*spikeEventAdminService)
+
+// WithImportVariants 允许导入的活动通过 variant_id 指定秒杀的商品规格
+func WithImportVariants(variants ImportVariantSource) SpikeEventAdminServiceOption {
+	return func(s *spikeEventAdminService) {
+		s.variants = variants
+	}
+}
+
+// NewSpikeEventAdminService 创建秒杀活动批量管理服务
+func NewSpikeEventAdminService(events repo.SpikeEventRepository, products ImportProductSource, logger *zap.Logger, opts ...SpikeEventAdminServiceOption) SpikeEventAdminService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	s := &spikeEventAdminService{
+		events:   events,
+		products: products,
+		logger:   logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ImportEvents 批量导入秒杀活动，导入的活动为待开始状态，到开始时间后由调度器流转；
+// draft 为 true 的行创建为草稿，须排期并审批后才会发布
+func (s *spikeEventAdminService) ImportEvents(ctx context.Context, r io.Reader, format string, dryRun bool) (*domain.SpikeEventImportResult, error) {
+	var (
+		rows    []*domain.CreateSpikeEventRequest
+		rowErrs [][]domain.SpikeEventImportError
+		err     error
+	)
+	switch format {
+	case domain.SpikeEventFileFormatCSV:
+		rows, rowErrs, err = decodeEventImportCSV(r)
+	case domain.SpikeEventFileFormatJSON:
+		rows, err = decodeEventImportJSON(r)
+		rowErrs = make([][]domain.SpikeEventImportError, len(rows))
+	default:
+		return nil, domain.NewInvalidArgumentError("导入格式必须为 json 或 csv")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, domain.NewInvalidArgumentError("导入文件中没有活动")
+	}
+
+	now := time.Now()
+	events := make([]*domain.SpikeEvent, len(rows))
+	for i, row := range rows {
+		var errs []domain.SpikeEventImportError
+		events[i], errs = importedEvent(row, now)
+		rowErrs[i] = append(rowErrs[i], errs...)
+	}
+	if err := s.checkProducts(rows, rowErrs); err != nil {
+		return nil, err
+	}
+	if err := s.checkVariants(ctx, rows, rowErrs); err != nil {
+		return nil, err
+	}
+
+	result := &domain.SpikeEventImportResult{DryRun: dryRun, Total: len(rows)}
+	for i, errs := range rowErrs {
+		if len(errs) == 0 {
+			result.Valid++
+			continue
+		}
+		for _, e := range errs {
+			e.Row = i + 1
+			result.Errors = append(result.Errors, e)
+		}
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if err := s.events.CreateBatch(ctx, events); err != nil {
+		return nil, fmt.Errorf("failed to import spike events: %w", err)
+	}
+	result.Created = len(events)
+	result.EventIDs = make([]int64, len(events))
+	for i, event := range events {
+		result.EventIDs[i] = event.ID
+	}
+	return result, nil
+}
+
+// checkProducts 为引用了不存在或已停售商品的行追加错误
+func (s *spikeEventAdminService) checkProducts(rows []*domain.CreateSpikeEventRequest, rowErrs [][]domain.SpikeEventImportError) error {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, row := range rows {
+		if row.ProductID > 0 && !seen[row.ProductID] {
+			seen[row.ProductID] = true
+			ids = append(ids, row.ProductID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	products, err := s.products.GetByIDs(ids)
+	if err != nil {
+		return fmt.Errorf("failed to get products: %w", err)
+	}
+	available := make(map[int64]bool, len(products))
+	for _, p := range products {
+		available[p.ID] = p.Status != domain.ProductStatusDeleted && p.Status != domain.ProductStatusDiscontinued
+	}
+	for i, row := range rows {
+		if row.ProductID > 0 && !available[row.ProductID] {
+			rowErrs[i] = append(rowErrs[i], domain.SpikeEventImportError{Field: "product_id", Message: "商品不存在或已停产"})
+		}
+	}
+	return nil
+}
+
+// checkVariants 为引用了不存在、已停用或不属于该商品的规格的行追加错误
+func (s *spikeEventAdminService) checkVariants(ctx context.Context, rows []*domain.CreateSpikeEventRequest, rowErrs [][]domain.SpikeEventImportError) error {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, row := range rows {
+		if row.VariantID != nil && *row.VariantID > 0 && !seen[*row.VariantID] {
+			seen[*row.VariantID] = true
+			ids = append(ids, *row.VariantID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	variants := make(map[int64]*domain.ProductVariant, len(ids))
+	if s.variants != nil {
+		found, err := s.variants.GetByIDs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to get product variants: %w", err)
+		}
+		for _, v := range found {
+			variants[v.ID] = v
+		}
+	}
+	for i, row := range rows {
+		if row.VariantID == nil || *row.VariantID <= 0 {
+			continue
+		}
+		v := variants[*row.VariantID]
+		switch {
+		case v == nil || !v.Active:
+			rowErrs[i] = append(rowErrs[i], domain.SpikeEventImportError{Field: "variant_id", Message: "商品规格不存在或已停用"})
+		case v.ProductID != row.ProductID:
+			rowErrs[i] = append(rowErrs[i], domain.SpikeEventImportError{Field: "variant_id", Message: "商品规格不属于该商品"})
+		}
+	}
+	return nil
+}
+
+// importedEvent 校验一行导入数据并转换为待开始的活动，返回该行的所有字段错误
+func importedEvent(row *domain.CreateSpikeEventRequest, now time.Time) (*domain.SpikeEvent, []domain.SpikeEventImportError) {
+	var errs []domain.SpikeEventImportError
+	fail := func(field, message string) {
+		errs = append(errs, domain.SpikeEventImportError{Field: field, Message: message})
+	}
+
+	if row.ProductID <= 0 {
+		fail("product_id", "必须为正整数")
+	}
+	if row.VariantID != nil && *row.VariantID <= 0 {
+		fail("variant_id", "必须为正整数")
+	}
+	if n := utf8.RuneCountInString(row.Name); n == 0 || n > 255 {
+		fail("name", "长度必须在 1 到 255 之间")
+	}
+	if row.SpikePrice <= 0 {
+		fail("spike_price", "必须大于 0")
+	}
+	if row.OriginalPrice <= 0 {
+		fail("original_price", "必须大于 0")
+	} else if row.SpikePrice > row.OriginalPrice {
+		fail("spike_price", "不能高于原价")
+	}
+	if row.SpikeStock <= 0 {
+		fail("spike_stock", "必须大于 0")
+	}
+	if row.MaxPerUser < 0 || row.MaxPerUser > 10 {
+		fail("max_per_user", "取值范围为 0 到 10")
+	}
+	if row.QPSLimit < 0 {
+		fail("qps_limit", "不能为负数")
+	}
+	if row.WaitingRoomRate < 0 {
+		fail("waiting_room_rate", "不能为负数")
+	}
+	if row.StockBuckets < 0 || row.StockBuckets > eventImportMaxStockBuckets {
+		fail("stock_buckets", fmt.Sprintf("取值范围为 0 到 %d", eventImportMaxStockBuckets))
+	}
+
+	startAt, startErr := time.Parse(time.RFC3339, row.StartAt)
+	if startErr != nil {
+		fail("start_at", "必须为 RFC3339 时间")
+	}
+	endAt, endErr := time.Parse(time.RFC3339, row.EndAt)
+	switch {
+	case endErr != nil:
+		fail("end_at", "必须为 RFC3339 时间")
+	case !endAt.After(now):
+		fail("end_at", "必须晚于当前时间")
+	case startErr == nil && !endAt.After(startAt):
+		fail("end_at", "必须晚于开始时间")
+	}
+	var previewStartAt *time.Time
+	if row.PreviewStartAt != nil && *row.PreviewStartAt != "" {
+		t, err := time.Parse(time.RFC3339, *row.PreviewStartAt)
+		switch {
+		case err != nil:
+			fail("preview_start_at", "必须为 RFC3339 时间")
+		case startErr == nil && !t.Before(startAt):
+			fail("preview_start_at", "必须早于开始时间")
+		default:
+			previewStartAt = &t
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	status := domain.SpikeEventStatusPending
+	if row.Draft {
+		status = domain.SpikeEventStatusDraft
+	}
+	return &domain.SpikeEvent{
+		ProductID:         row.ProductID,
+		VariantID:         row.VariantID,
+		Name:              row.Name,
+		Description:       row.Description,
+		SpikePrice:        row.SpikePrice,
+		OriginalPrice:     row.OriginalPrice,
+		SpikeStock:        row.SpikeStock,
+		PreviewStartAt:    previewStartAt,
+		MaxPerUser:        row.MaxPerUser,
+		QPSLimit:          row.QPSLimit,
+		StockBuckets:      row.StockBuckets,
+		ChallengeRequired: row.ChallengeRequired,
+		WaitingRoomRate:   row.WaitingRoomRate,
+		StartAt:           startAt,
+		EndAt:             endAt,
+		Status:            status,
+	}, nil
+}
+
+// decodeEventImportJSON 解析 JSON 数组格式的导入文件
+func decodeEventImportJSON(r io.Reader) ([]*domain.CreateSpikeEventRequest, error) {
+	var rows []*domain.CreateSpikeEventRequest
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rows); err != nil {
+		return nil, domain.NewInvalidArgumentError(fmt.Sprintf("JSON 格式错误: %v", err))
+	}
+	if len(rows) > domain.MaxSpikeEventImportRows {
+		return nil, domain.NewInvalidArgumentError(fmt.Sprintf("单次最多导入 %d 个活动", domain.MaxSpikeEventImportRows))
+	}
+	for i, row := range rows {
+		if row == nil {
+			return nil, domain.NewInvalidArgumentError(fmt.Sprintf("第 %d 个活动不能为 null", i+1))
+		}
+	}
+	return rows, nil
+}
+
+// decodeEventImportCSV 解析带表头的 CSV 导入文件，列名见 domain.SpikeEventImportColumns，
+// 可省略可选列、调整列顺序；数值无法解析的单元格作为该行的字段错误返回
+func decodeEventImportCSV(r io.Reader) ([]*domain.CreateSpikeEventRequest, [][]domain.SpikeEventImportError, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, domain.NewInvalidArgumentError(fmt.Sprintf("CSV 格式错误: %v", err))
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))
+		if !isEventImportColumn(name) {
+			return nil, nil, domain.NewInvalidArgumentError(fmt.Sprintf("未知的列: %s", name))
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"product_id", "name", "spike_price", "original_price", "spike_stock", "start_at", "end_at"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, domain.NewInvalidArgumentError(fmt.Sprintf("缺少必需的列: %s", name))
+		}
+	}
+
+	var (
+		rows    []*domain.CreateSpikeEventRequest
+		rowErrs [][]domain.SpikeEventImportError
+	)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, domain.NewInvalidArgumentError(fmt.Sprintf("CSV 格式错误: %v", err))
+		}
+		if len(rows) == domain.MaxSpikeEventImportRows {
+			return nil, nil, domain.NewInvalidArgumentError(fmt.Sprintf("单次最多导入 %d 个活动", domain.MaxSpikeEventImportRows))
+		}
+
+		row, errs := parseEventImportRecord(record, columns)
+		rows = append(rows, row)
+		rowErrs = append(rowErrs, errs)
+	}
+	return rows, rowErrs, nil
+}
+
+// parseEventImportRecord 将 CSV 的一行转换为创建活动请求，空单元格视为零值
+func parseEventImportRecord(record []string, columns map[string]int) (*domain.CreateSpikeEventRequest, []domain.SpikeEventImportError) {
+	var errs []domain.SpikeEventImportError
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	parseInt := func(name string) int64 {
+		v := cell(name)
+		if v == "" {
+			return 0
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			errs = append(errs, domain.SpikeEventImportError{Field: name, Message: "必须为整数"})
+		}
+		return n
+	}
+	parseFloat := func(name string) float64 {
+		v := cell(name)
+		if v == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			errs = append(errs, domain.SpikeEventImportError{Field: name, Message: "必须为数字"})
+		}
+		return f
+	}
+
+	parseBool := func(name string) bool {
+		v := cell(name)
+		if v == "" {
+			return false
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, domain.SpikeEventImportError{Field: name, Message: "必须为 true 或 false"})
+		}
+		return b
+	}
+
+	row := &domain.CreateSpikeEventRequest{
+		ProductID:         parseInt("product_id"),
+		Name:              cell("name"),
+		Description:       cell("description"),
+		SpikePrice:        parseFloat("spike_price"),
+		OriginalPrice:     parseFloat("original_price"),
+		SpikeStock:        parseInt("spike_stock"),
+		MaxPerUser:        parseInt("max_per_user"),
+		QPSLimit:          parseInt("qps_limit"),
+		StockBuckets:      int(parseInt("stock_buckets")),
+		ChallengeRequired: parseBool("challenge_required"),
+		WaitingRoomRate:   parseInt("waiting_room_rate"),
+		StartAt:           cell("start_at"),
+		EndAt:             cell("end_at"),
+		Draft:             parseBool("draft"),
+	}
+	if v := cell("preview_start_at"); v != "" {
+		row.PreviewStartAt = &v
+	}
+	if cell("variant_id") != "" {
+		variantID := parseInt("variant_id")
+		row.VariantID = &variantID
+	}
+	return row, errs
+}
+
+func isEventImportColumn(name string) bool {
+	for _, column := range domain.SpikeEventImportColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportEvents 分页读取活动并按导入格式写出，CSV 带 UTF-8 BOM 以便 Excel 正确识别中文
+func (s *spikeEventAdminService) ExportEvents(ctx context.Context, req *domain.SpikeEventListRequest, format string, w io.Writer) (bool, error) {
+	if format != domain.SpikeEventFileFormatCSV && format != domain.SpikeEventFileFormatJSON {
+		return false, domain.NewInvalidArgumentError("导出格式必须为 json 或 csv")
+	}
+
+	query := *req
+	query.PageSize = eventExportPageSize
+	query.SkipTotal = true
+	query.After = nil
+	keyset := query.KeysetOrdered()
+	var rows []*domain.CreateSpikeEventRequest
+	truncated := false
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		query.Page = page
+		events, total, err := s.events.List(ctx, &query)
+		if err != nil {
+			return false, fmt.Errorf("failed to list spike events: %w", err)
+		}
+		events, pageInfo := domain.Paginate(events, total, query.Page, query.PageSize)
+
+		for _, event := range events {
+			if len(rows) == eventExportMaxRows {
+				truncated = true
+				break
+			}
+			rows = append(rows, eventExportRow(event))
+		}
+		if truncated || !pageInfo.HasMore {
+			break
+		}
+		// 按创建时间排序时以键集游标翻页，避免深分页的偏移扫描
+		if keyset {
+			cursor := events[len(events)-1].Cursor()
+			query.After = &cursor
+		}
+	}
+
+	if format == domain.SpikeEventFileFormatJSON {
+		if rows == nil {
+			rows = []*domain.CreateSpikeEventRequest{}
+		}
+		return truncated, json.NewEncoder(w).Encode(rows)
+	}
+	return truncated, writeEventExportCSV(w, rows)
+}
+
+// writeEventExportCSV 按 domain.SpikeEventImportColumns 的列顺序写出 CSV
+func writeEventExportCSV(w io.Writer, rows []*domain.CreateSpikeEventRequest) error {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(domain.SpikeEventImportColumns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		previewStartAt := ""
+		if row.PreviewStartAt != nil {
+			previewStartAt = *row.PreviewStartAt
+		}
+		variantID := ""
+		if row.VariantID != nil {
+			variantID = strconv.FormatInt(*row.VariantID, 10)
+		}
+		if err := cw.Write([]string{
+			strconv.FormatInt(row.ProductID, 10),
+			variantID,
+			row.Name,
+			row.Description,
+			formatAmount(row.SpikePrice),
+			formatAmount(row.OriginalPrice),
+			strconv.FormatInt(row.SpikeStock, 10),
+			previewStartAt,
+			strconv.FormatInt(row.MaxPerUser, 10),
+			strconv.FormatInt(row.QPSLimit, 10),
+			strconv.Itoa(row.StockBuckets),
+			strconv.FormatBool(row.ChallengeRequired),
+			strconv.FormatInt(row.WaitingRoomRate, 10),
+			row.StartAt,
+			row.EndAt,
+			strconv.FormatBool(row.Draft),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// eventExportRow 将活动转换为可重新导入的行，时间使用 RFC3339 格式
+func eventExportRow(event *domain.SpikeEvent) *domain.CreateSpikeEventRequest {
+	row := &domain.CreateSpikeEventRequest{
+		ProductID:         event.ProductID,
+		VariantID:         event.VariantID,
+		Name:              event.Name,
+		Description:       event.Description,
+		SpikePrice:        event.SpikePrice,
+		OriginalPrice:     event.OriginalPrice,
+		SpikeStock:        event.SpikeStock,
+		MaxPerUser:        event.MaxPerUser,
+		QPSLimit:          event.QPSLimit,
+		StockBuckets:      event.StockBuckets,
+		ChallengeRequired: event.ChallengeRequired,
+		WaitingRoomRate:   event.WaitingRoomRate,
+		StartAt:           event.StartAt.Format(time.RFC3339),
+		EndAt:             event.EndAt.Format(time.RFC3339),
+		Draft:             event.IsUnpublished(),
+	}
+	if event.PreviewStartAt != nil {
+		v := event.PreviewStartAt.Format(time.RFC3339)
+		row.PreviewStartAt = &v
+	}
+	return row
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeImportProducts 按ID返回预置的商品
+type fakeImportProducts map[int64]domain.ProductStatus
+
+func (f fakeImportProducts) GetByIDs(ids []int64) ([]*domain.Product, error) {
+	var products []*domain.Product
+	for _, id := range ids {
+		if status, ok := f[id]; ok {
+			products = append(products, &domain.Product{ID: id, Status: status})
+		}
+	}
+	return products, nil
+}
+
+func importCSV(start, end time.Time, rows ...string) string {
+	var b strings.Builder
+	b.WriteString("product_id,name,spike_price,original_price,spike_stock,start_at,end_at\n")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%s,%s,%s\n", row, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+func TestSpikeEventAdminService_ImportEvents(t *testing.T) {
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	end := start.Add(time.Hour)
+	products := fakeImportProducts{1: domain.ProductStatusActive, 2: domain.ProductStatusDiscontinued}
+
+	t.Run("dry run validates without creating", func(t *testing.T) {
+		events := NewMockSpikeEventRepository()
+		svc := NewSpikeEventAdminService(events, products, zap.NewNop())
+
+		body := importCSV(start, end, "1,手机,99,199,100", "1,耳机,19.9,49,50")
+		result, err := svc.ImportEvents(context.Background(), strings.NewReader(body), domain.SpikeEventFileFormatCSV, true)
+		if err != nil {
+			t.Fatalf("ImportEvents() error = %v", err)
+		}
+		if result.Total != 2 || result.Valid != 2 || result.Created != 0 || len(result.Errors) != 0 {
+			t.Errorf("result = %+v, want 2 valid and none created", result)
+		}
+		if len(events.events) != 0 {
+			t.Errorf("dry run created %d events", len(events.events))
+		}
+	})
+
+	t.Run("any invalid row rejects the batch", func(t *testing.T) {
+		events := NewMockSpikeEventRepository()
+		svc := NewSpikeEventAdminService(events, products, zap.NewNop())
+
+		body := importCSV(start, end, "1,手机,99,199,100", "2,停产商品,10,20,5", "1,,abc,10,0")
+		result, err := svc.ImportEvents(context.Background(), strings.NewReader(body), domain.SpikeEventFileFormatCSV, false)
+		if err != nil {
+			t.Fatalf("ImportEvents() error = %v", err)
+		}
+		if result.Valid != 1 || result.Created != 0 || len(events.events) != 0 {
+			t.Errorf("valid = %d, created = %d, stored = %d, want 1, 0, 0", result.Valid, result.Created, len(events.events))
+		}
+
+		got := make(map[string]bool)
+		for _, e := range result.Errors {
+			got[fmt.Sprintf("%d:%s", e.Row, e.Field)] = true
+		}
+		for _, want := range []string{"2:product_id", "3:name", "3:spike_price", "3:spike_stock"} {
+			if !got[want] {
+				t.Errorf("Errors = %+v, missing %s", result.Errors, want)
+			}
+		}
+	})
+
+	t.Run("json creates all rows as pending", func(t *testing.T) {
+		events := NewMockSpikeEventRepository()
+		svc := NewSpikeEventAdminService(events, products, zap.NewNop())
+
+		preview := start.Add(-30 * time.Minute).Format(time.RFC3339)
+		rows := []domain.CreateSpikeEventRequest{
+			{ProductID: 1, Name: "手机", SpikePrice: 99, OriginalPrice: 199, SpikeStock: 100, PreviewStartAt: &preview,
+				StockBuckets: 4, StartAt: start.Format(time.RFC3339), EndAt: end.Format(time.RFC3339)},
+			{ProductID: 1, Name: "耳机", SpikePrice: 19.9, OriginalPrice: 49, SpikeStock: 50,
+				StartAt: start.Format(time.RFC3339), EndAt: end.Format(time.RFC3339)},
+		}
+		body, _ := json.Marshal(rows)
+		result, err := svc.ImportEvents(context.Background(), bytes.NewReader(body), domain.SpikeEventFileFormatJSON, false)
+		if err != nil {
+			t.Fatalf("ImportEvents() error = %v", err)
+		}
+		if result.Created != 2 || len(result.EventIDs) != 2 {
+			t.Fatalf("result = %+v, want 2 created", result)
+		}
+		event, _ := events.GetByID(context.Background(), result.EventIDs[0])
+		if event.Status != domain.SpikeEventStatusPending || event.StockBuckets != 4 || event.PreviewStartAt == nil {
+			t.Errorf("event = %+v, want pending with buckets and preview", event)
+		}
+	})
+
+	t.Run("variant must be active and belong to the product", func(t *testing.T) {
+		variants := newFakeVariantRepo()
+		variants.variants[1] = &domain.ProductVariant{ID: 1, ProductID: 1, SKU: "P1-RED", Active: true}
+		variants.variants[2] = &domain.ProductVariant{ID: 2, ProductID: 1, SKU: "P1-BLUE"}
+		variants.variants[3] = &domain.ProductVariant{ID: 3, ProductID: 3, SKU: "P3-RED", Active: true}
+		events := NewMockSpikeEventRepository()
+		svc := NewSpikeEventAdminService(events, products, zap.NewNop(), WithImportVariants(variants))
+
+		var b strings.Builder
+		b.WriteString("product_id,variant_id,name,spike_price,original_price,spike_stock,start_at,end_at\n")
+		for _, row := range []string{"1,1,红色", "1,2,停用规格", "1,3,其它商品的规格", "1,9,不存在的规格", "1,,整体库存"} {
+			fmt.Fprintf(&b, "%s,99,199,10,%s,%s\n", row, start.Format(time.RFC3339), end.Format(time.RFC3339))
+		}
+		result, err := svc.ImportEvents(context.Background(), strings.NewReader(b.String()), domain.SpikeEventFileFormatCSV, true)
+		if err != nil {
+			t.Fatalf("ImportEvents() error = %v", err)
+		}
+		if result.Valid != 2 || len(result.Errors) != 3 {
+			t.Fatalf("result = %+v, want 2 valid and 3 errors", result)
+		}
+		for _, e := range result.Errors {
+			if e.Field != "variant_id" || e.Row < 2 || e.Row > 4 {
+				t.Errorf("error = %+v, want variant_id error on rows 2-4", e)
+			}
+		}
+
+		// 未配置规格来源时拒绝指定了规格的行
+		svc = NewSpikeEventAdminService(events, products, zap.NewNop())
+		result, err = svc.ImportEvents(context.Background(), strings.NewReader(b.String()), domain.SpikeEventFileFormatCSV, true)
+		if err != nil || result.Valid != 1 {
+			t.Errorf("ImportEvents() without variants = %+v, %v, want 1 valid", result, err)
+		}
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		svc := NewSpikeEventAdminService(NewMockSpikeEventRepository(), products, zap.NewNop())
+		for _, tc := range []struct{ format, body string }{
+			{domain.SpikeEventFileFormatJSON, `{"product_id": 1}`},
+			{domain.SpikeEventFileFormatJSON, `[]`},
+			{domain.SpikeEventFileFormatCSV, "product_id,name,unknown\n"},
+			{domain.SpikeEventFileFormatCSV, "product_id,name\n1,手机\n"},
+		} {
+			_, err := svc.ImportEvents(context.Background(), strings.NewReader(tc.body), tc.format, false)
+			if !errors.Is(err, domain.ErrInvalidArgument) {
+				t.Errorf("ImportEvents(%s %q) error = %v, want ErrInvalidArgument", tc.format, tc.body, err)
+			}
+		}
+	})
+}
+
+func TestSpikeEventAdminService_ExportRoundTrip(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	seeded := testutil.NewSpikeEventBuilder().Pending().WithStock(80).WithStockBuckets(2).Build()
+	testutil.SeedSpikeEvents(t, events, seeded)
+	svc := NewSpikeEventAdminService(events, fakeImportProducts{seeded.ProductID: domain.ProductStatusActive}, zap.NewNop())
+
+	for _, format := range []string{domain.SpikeEventFileFormatCSV, domain.SpikeEventFileFormatJSON} {
+		var buf bytes.Buffer
+		truncated, err := svc.ExportEvents(context.Background(), &domain.SpikeEventListRequest{}, format, &buf)
+		if err != nil || truncated {
+			t.Fatalf("ExportEvents(%s) = %v, %v", format, truncated, err)
+		}
+
+		// 导出的文件可以直接重新导入
+		result, err := svc.ImportEvents(context.Background(), &buf, format, true)
+		if err != nil {
+			t.Fatalf("ImportEvents(%s export) error = %v", format, err)
+		}
+		if result.Total != 1 || result.Valid != 1 {
+			t.Errorf("%s round trip result = %+v, want 1 valid", format, result)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Package service 提供秒杀活动状态流转服务。
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// SpikeEventLifecycleService 定义管理员干预活动状态的接口
+type SpikeEventLifecycleService interface {
+	// GetEvent 读取活动当前数据（不经缓存），用于审计记录变更前的状态
+	GetEvent(ctx context.Context, eventID int64) (*domain.SpikeEvent, error)
+	// PauseEvent 暂停未结束的待开始或进行中活动，暂停期间不可参与，状态流转任务也不会自动激活
+	PauseEvent(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error)
+	// ResumeEvent 恢复已暂停的活动：未到开始时间恢复为待开始，否则恢复为进行中
+	ResumeEvent(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error)
+}
+
+// LifecycleEventStore 状态流转所需的活动读写操作（由 repo.SpikeEventRepository 实现）
+type LifecycleEventStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	GetEventsDueForTransition(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error)
+	TransitionStatus(ctx context.Context, id int64, from, to domain.SpikeEventStatus) (bool, error)
+}
+
+// LifecycleCache 状态变更后失效活动缓存（由 cache.SpikeCache 实现）
+type LifecycleCache interface {
+	InvalidateEventInfo(ctx context.Context, eventID int64) error
+}
+
+// LifecyclePublications 查询到发布时间的已审批活动（由 repo.SpikeEventPublicationRepository 实现）
+type LifecyclePublications interface {
+	GetDueEventIDs(ctx context.Context, now time.Time) ([]int64, error)
+}
+
+// SpikeEventLifecycle 活动状态机：定时发布到发布时间的已审批活动、把到开始时间的活动置为进行中、
+// 到结束时间的活动置为已结束，并支持管理员暂停与恢复。状态按当前状态条件更新，多实例同时扫描时同一次流转只有一个实例成功，
+// 成功的实例负责失效活动缓存并广播状态变更通知
+type SpikeEventLifecycle struct {
+	events   LifecycleEventStore
+	cache    LifecycleCache
+	notifier mq.NotificationPublisher
+	interval time.Duration
+	logger   *zap.Logger
+
+	// 活动发布计划，可为空，为空时不自动发布待发布活动
+	publications LifecyclePublications
+}
+
+// NewSpikeEventLifecycle 创建活动状态机，notifier 为空时不广播状态变更通知
+func NewSpikeEventLifecycle(events LifecycleEventStore, eventCache LifecycleCache, notifier mq.NotificationPublisher, interval time.Duration, logger *zap.Logger) *SpikeEventLifecycle {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &SpikeEventLifecycle{
+		events:   events,
+		cache:    eventCache,
+		notifier: notifier,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// SetPublications 设置活动发布计划来源，启用待发布活动的定时发布
+func (l *SpikeEventLifecycle) SetPublications(publications LifecyclePublications) {
+	l.publications = publications
+}
+
+// Start 异步启动状态流转循环，ctx 取消时退出
+func (l *SpikeEventLifecycle) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		l.logger.Info("活动状态流转已启动", zap.Duration("interval", l.interval))
+		for {
+			if _, err := l.RunOnce(ctx); err != nil {
+				l.logger.Warn("扫描待流转活动失败", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				l.logger.Info("活动状态流转已停止")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce 流转所有到期活动的状态，返回本实例完成的流转数；
+// 先发布到期的待发布活动，发布时已到开始时间的活动在同一轮内继续流转为进行中
+func (l *SpikeEventLifecycle) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	transitioned := l.publishDue(ctx, now)
+
+	events, err := l.events.GetEventsDueForTransition(ctx, now)
+	if err != nil {
+		return transitioned, fmt.Errorf("failed to get events due for transition: %w", err)
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		to, ok := event.ScheduledStatus(now)
+		if !ok {
+			continue
+		}
+		changed, err := l.transition(ctx, event, to, "", 0)
+		if err != nil {
+			// 下一轮继续重试
+			l.logger.Warn("活动状态流转失败",
+				zap.Int64("event_id", event.ID),
+				zap.String("to", string(to)),
+				zap.Error(err))
+			continue
+		}
+		if changed {
+			transitioned++
+		}
+	}
+	return transitioned, nil
+}
+
+// publishDue 把到发布时间的已审批活动置为待开始，返回本实例完成的发布数；失败只记录日志，下一轮继续重试
+func (l *SpikeEventLifecycle) publishDue(ctx context.Context, now time.Time) int {
+	if l.publications == nil {
+		return 0
+	}
+	eventIDs, err := l.publications.GetDueEventIDs(ctx, now)
+	if err != nil {
+		l.logger.Warn("扫描待发布活动失败", zap.Error(err))
+		return 0
+	}
+
+	published := 0
+	for _, eventID := range eventIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		event, err := l.events.GetByID(ctx, eventID)
+		if err != nil {
+			l.logger.Warn("读取待发布活动失败", zap.Int64("event_id", eventID), zap.Error(err))
+			continue
+		}
+		if event.Status != domain.SpikeEventStatusScheduled {
+			continue
+		}
+		changed, err := l.transition(ctx, event, domain.SpikeEventStatusPending, "", 0)
+		if err != nil {
+			l.logger.Warn("发布活动失败", zap.Int64("event_id", eventID), zap.Error(err))
+			continue
+		}
+		if changed {
+			published++
+		}
+	}
+	return published
+}
+
+// GetEvent 读取活动
+func (l *SpikeEventLifecycle) GetEvent(ctx context.Context, eventID int64) (*domain.SpikeEvent, error) {
+	return l.events.GetByID(ctx, eventID)
+}
+
+// PauseEvent 暂停活动
+func (l *SpikeEventLifecycle) PauseEvent(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error) {
+	event, err := l.events.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.CanPause(time.Now()) {
+		return nil, domain.ErrSpikeEventNotPausable
+	}
+	return l.changeStatus(ctx, event, domain.SpikeEventStatusPaused, req.Reason, operatorID)
+}
+
+// ResumeEvent 恢复活动
+func (l *SpikeEventLifecycle) ResumeEvent(ctx context.Context, eventID int64, req *domain.SpikeEventStatusChangeRequest, operatorID int64) (*domain.SpikeEvent, error) {
+	event, err := l.events.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	to, ok := event.ResumeStatus(time.Now())
+	if !ok {
+		return nil, domain.ErrSpikeEventNotResumable
+	}
+	return l.changeStatus(ctx, event, to, req.Reason, operatorID)
+}
+
+// changeStatus 执行管理员发起的状态变更，状态已被并发修改时返回 domain.ErrSpikeEventStatusChanged
+func (l *SpikeEventLifecycle) changeStatus(ctx context.Context, event *domain.SpikeEvent, to domain.SpikeEventStatus, reason string, operatorID int64) (*domain.SpikeEvent, error) {
+	changed, err := l.transition(ctx, event, to, reason, operatorID)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, domain.ErrSpikeEventStatusChanged
+	}
+
+	updated := *event
+	updated.Status = to
+	return &updated, nil
+}
+
+// transition 按当前状态条件更新活动状态，返回本实例是否完成了流转；
+// 缓存失效与通知失败只记录日志，数据库中的状态已生效
+func (l *SpikeEventLifecycle) transition(ctx context.Context, event *domain.SpikeEvent, to domain.SpikeEventStatus, reason string, operatorID int64) (bool, error) {
+	from := event.Status
+	changed, err := l.events.TransitionStatus(ctx, event.ID, from, to)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+
+	if err := l.cache.InvalidateEventInfo(ctx, event.ID); err != nil {
+		l.logger.Warn("失效活动缓存失败", zap.Int64("event_id", event.ID), zap.Error(err))
+	}
+
+	l.logger.Info("活动状态已变更",
+		zap.Int64("event_id", event.ID),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+		zap.Int64("operator_id", operatorID),
+		zap.String("reason", reason))
+
+	l.broadcastStatusChange(ctx, event, from, to, reason)
+	return true, nil
+}
+
+// broadcastStatusChange 广播活动状态变更通知（UserID 为 0 表示面向全部关注该活动的用户），失败只记录日志
+func (l *SpikeEventLifecycle) broadcastStatusChange(ctx context.Context, event *domain.SpikeEvent, from, to domain.SpikeEventStatus, reason string) {
+	if l.notifier == nil {
+		return
+	}
+
+	title, content := statusChangeMessage(event, from, to)
+	err := l.notifier.PublishNotification(ctx, &mq.NotificationData{
+		Type:    "spike_event_status_changed",
+		Title:   title,
+		Content: content,
+		Data: map[string]interface{}{
+			"spike_event_id": event.ID,
+			"from_status":    from,
+			"to_status":      to,
+			"reason":         reason,
+		},
+		Priority: "high",
+		Channels: []string{"push"},
+	}, "")
+	if err != nil {
+		l.logger.Warn("广播活动状态变更通知失败", zap.Int64("event_id", event.ID), zap.Error(err))
+	}
+}
+
+// statusChangeMessage 返回状态变更通知的标题与内容
+func statusChangeMessage(event *domain.SpikeEvent, from, to domain.SpikeEventStatus) (string, string) {
+	switch {
+	case from == domain.SpikeEventStatusScheduled:
+		return "秒杀活动已上线", fmt.Sprintf("%s 已上线，%s 开始", event.Name, event.StartAt.Format("2006-01-02 15:04"))
+	case to == domain.SpikeEventStatusPaused:
+		return "秒杀活动已暂停", fmt.Sprintf("%s 暂停参与，恢复后将再次通知", event.Name)
+	case from == domain.SpikeEventStatusPaused && to != domain.SpikeEventStatusEnded:
+		return "秒杀活动已恢复", fmt.Sprintf("%s 已恢复，按原定时间参与", event.Name)
+	case to == domain.SpikeEventStatusActive:
+		return "秒杀活动已开始", fmt.Sprintf("%s 已开始，快来抢购", event.Name)
+	default:
+		return "秒杀活动已结束", fmt.Sprintf("%s 已结束，感谢参与", event.Name)
+	}
+}
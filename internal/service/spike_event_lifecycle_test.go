@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeLifecycleCache 记录被失效的活动缓存
+type fakeLifecycleCache struct {
+	invalidated []int64
+}
+
+func (f *fakeLifecycleCache) InvalidateEventInfo(ctx context.Context, eventID int64) error {
+	f.invalidated = append(f.invalidated, eventID)
+	return nil
+}
+
+func TestSpikeEventLifecycle_RunOnce(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	now := time.Now()
+	starting := testutil.NewSpikeEventBuilder().Pending().Between(now.Add(-time.Minute), now.Add(time.Hour)).Build()
+	ending := testutil.NewSpikeEventBuilder().Active().Between(now.Add(-time.Hour), now.Add(-time.Second)).Build()
+	pausedEnding := testutil.NewSpikeEventBuilder().Between(now.Add(-time.Hour), now.Add(-time.Second)).Paused().Build()
+	pausedRunning := testutil.NewSpikeEventBuilder().Active().Paused().Build()
+	upcoming := testutil.NewSpikeEventBuilder().Pending().Build()
+	cancelled := testutil.NewSpikeEventBuilder().Ended().Cancelled().Build()
+	testutil.SeedSpikeEvents(t, events, starting, ending, pausedEnding, pausedRunning, upcoming, cancelled)
+
+	eventCache := &fakeLifecycleCache{}
+	notifier := &fakeNotificationPublisher{}
+	lifecycle := NewSpikeEventLifecycle(events, eventCache, notifier, time.Second, nil)
+
+	n, err := lifecycle.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("RunOnce() = %d, want 3", n)
+	}
+
+	want := map[*domain.SpikeEvent]domain.SpikeEventStatus{
+		starting:      domain.SpikeEventStatusActive,
+		ending:        domain.SpikeEventStatusEnded,
+		pausedEnding:  domain.SpikeEventStatusEnded,
+		pausedRunning: domain.SpikeEventStatusPaused, // 已暂停的活动不会被自动激活
+		upcoming:      domain.SpikeEventStatusPending,
+		cancelled:     domain.SpikeEventStatusCancelled,
+	}
+	for event, status := range want {
+		if event.Status != status {
+			t.Errorf("event %q status = %s, want %s", event.Name, event.Status, status)
+		}
+	}
+	if len(eventCache.invalidated) != 3 {
+		t.Errorf("invalidated = %v, want 3 events", eventCache.invalidated)
+	}
+	if len(notifier.notifications) != 3 || notifier.notifications[0].Type != "spike_event_status_changed" {
+		t.Errorf("notifications = %+v, want 3 status change broadcasts", notifier.notifications)
+	}
+
+	// 已流转的活动不再重复流转
+	if n, err := lifecycle.RunOnce(context.Background()); err != nil || n != 0 {
+		t.Errorf("second RunOnce() = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestSpikeEventLifecycle_PauseResume(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	active := testutil.NewSpikeEventBuilder().Active().Build()
+	pending := testutil.NewSpikeEventBuilder().Pending().Build()
+	ended := testutil.NewSpikeEventBuilder().Ended().Build()
+	testutil.SeedSpikeEvents(t, events, active, pending, ended)
+
+	notifier := &fakeNotificationPublisher{}
+	lifecycle := NewSpikeEventLifecycle(events, &fakeLifecycleCache{}, notifier, time.Second, nil)
+	ctx := context.Background()
+	req := &domain.SpikeEventStatusChangeRequest{Reason: "库存核对"}
+
+	paused, err := lifecycle.PauseEvent(ctx, active.ID, req, 1)
+	if err != nil {
+		t.Fatalf("PauseEvent() error = %v", err)
+	}
+	if paused.Status != domain.SpikeEventStatusPaused {
+		t.Errorf("paused status = %s, want paused", paused.Status)
+	}
+	if _, err := lifecycle.PauseEvent(ctx, active.ID, req, 1); !errors.Is(err, domain.ErrSpikeEventNotPausable) {
+		t.Errorf("PauseEvent() on paused event error = %v, want ErrSpikeEventNotPausable", err)
+	}
+	resumed, err := lifecycle.ResumeEvent(ctx, active.ID, req, 1)
+	if err != nil {
+		t.Fatalf("ResumeEvent() error = %v", err)
+	}
+	if resumed.Status != domain.SpikeEventStatusActive {
+		t.Errorf("resumed status = %s, want active", resumed.Status)
+	}
+	if _, err := lifecycle.ResumeEvent(ctx, active.ID, req, 1); !errors.Is(err, domain.ErrSpikeEventNotResumable) {
+		t.Errorf("ResumeEvent() on active event error = %v, want ErrSpikeEventNotResumable", err)
+	}
+
+	// 未到开始时间的活动恢复为待开始
+	if _, err := lifecycle.PauseEvent(ctx, pending.ID, req, 1); err != nil {
+		t.Fatalf("PauseEvent() error = %v", err)
+	}
+	if resumed, err := lifecycle.ResumeEvent(ctx, pending.ID, req, 1); err != nil || resumed.Status != domain.SpikeEventStatusPending {
+		t.Errorf("ResumeEvent() = %+v, %v, want pending", resumed, err)
+	}
+
+	if _, err := lifecycle.PauseEvent(ctx, ended.ID, req, 1); !errors.Is(err, domain.ErrSpikeEventNotPausable) {
+		t.Errorf("PauseEvent() on ended event error = %v, want ErrSpikeEventNotPausable", err)
+	}
+	if _, err := lifecycle.PauseEvent(ctx, 999, req, 1); !errors.Is(err, domain.ErrSpikeEventNotFound) {
+		t.Errorf("PauseEvent() on missing event error = %v, want ErrSpikeEventNotFound", err)
+	}
+	if len(notifier.notifications) != 4 || notifier.notifications[1].Title != "秒杀活动已恢复" {
+		t.Errorf("notifications = %+v, want pause and resume broadcasts", notifier.notifications)
+	}
+}
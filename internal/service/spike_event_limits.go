@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+)
+
+// EventRateLimiter 按活动配置的速率对参与请求限流（由 limiter.FixedWindowLimiter 实现，窗口为 1 秒）
+type EventRateLimiter interface {
+	AllowWithRate(ctx context.Context, key string, rate, n int64) (*limiter.LimitResult, error)
+}
+
+// SetEventLimiter 设置活动级限流器，为空时忽略活动配置的 QPS 上限
+func (s *SpikeService) SetEventLimiter(eventLimiter EventRateLimiter) {
+	s.eventLimiter = eventLimiter
+}
+
+// checkEventRateLimit 按活动配置的 QPS 上限限流；超限时返回限流器建议的重试等待时长。
+// 活动未配置上限或未设置限流器时不限流
+func (s *SpikeService) checkEventRateLimit(ctx context.Context, event *domain.SpikeEvent) (time.Duration, error) {
+	if s.eventLimiter == nil || event.QPSLimit <= 0 {
+		return 0, nil
+	}
+
+	result, err := s.eventLimiter.AllowWithRate(ctx, keys.Redis("event", event.ID), event.QPSLimit, 1)
+	if err != nil {
+		return 0, fmt.Errorf("event rate limit check failed: %w", err)
+	}
+	if !result.Allowed {
+		return result.RetryAfter, fmt.Errorf("event rate limit exceeded")
+	}
+	return 0, nil
+}
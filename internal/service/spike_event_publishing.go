@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// SpikeEventPublishingService 定义草稿活动的排期与审批接口。
+// 草稿经一名管理员设定发布时间后进入待发布，另一名管理员审批后由状态流转任务在发布时间自动发布
+type SpikeEventPublishingService interface {
+	// ListDrafts 分页列出草稿与待发布活动，待发布活动附带发布计划；req.Status 只接受草稿或待发布
+	ListDrafts(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventDraftListResponse, error)
+	// Schedule 为草稿设定发布时间并提交审批，活动进入待发布状态
+	Schedule(ctx context.Context, eventID int64, req *domain.ScheduleSpikeEventRequest, operatorID int64) (*domain.SpikeEventDraft, error)
+	// Approve 审批待发布活动的发布计划，审批人不能是设定发布时间的管理员
+	Approve(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error)
+	// Unschedule 撤回待发布活动的发布计划（含已审批的），活动回到草稿状态
+	Unschedule(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error)
+}
+
+// PublishingEventStore 排期与审批所需的活动读写操作（由 repo.SpikeEventRepository 实现）
+type PublishingEventStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	List(ctx context.Context, req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error)
+	TransitionStatus(ctx context.Context, id int64, from, to domain.SpikeEventStatus) (bool, error)
+}
+
+// spikeEventPublishingService 是SpikeEventPublishingService接口的实现。
+// 草稿与待发布之间的流转对用户不可见，不失效缓存也不广播通知；发布由 SpikeEventLifecycle 完成
+type spikeEventPublishingService struct {
+	events       PublishingEventStore
+	publications repo.SpikeEventPublicationRepository
+	logger       *zap.Logger
+}
+
+// NewSpikeEventPublishingService 创建草稿活动排期与审批服务
+func NewSpikeEventPublishingService(events PublishingEventStore, publications repo.SpikeEventPublicationRepository, logger *zap.Logger) SpikeEventPublishingService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeEventPublishingService{
+		events:       events,
+		publications: publications,
+		logger:       logger,
+	}
+}
+
+// ListDrafts 列出未发布的活动
+func (s *spikeEventPublishingService) ListDrafts(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventDraftListResponse, error) {
+	if req.Status != nil && *req.Status != domain.SpikeEventStatusDraft && *req.Status != domain.SpikeEventStatusScheduled {
+		return nil, domain.NewInvalidArgumentError("状态只能为 draft 或 scheduled")
+	}
+	query := *req
+	query.Unpublished = true
+
+	events, total, err := s.events.List(ctx, &query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list draft events: %w", err)
+	}
+	events, pageInfo := domain.PaginateWithCursor(events, total, query.Page, query.PageSize, query.KeysetOrdered(), (*domain.SpikeEvent).Cursor)
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		if event.Status == domain.SpikeEventStatusScheduled {
+			ids = append(ids, event.ID)
+		}
+	}
+	publications, err := s.publications.GetByEventIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publications: %w", err)
+	}
+
+	drafts := make([]*domain.SpikeEventDraft, 0, len(events))
+	for _, event := range events {
+		drafts = append(drafts, &domain.SpikeEventDraft{SpikeEvent: event, Publication: publications[event.ID]})
+	}
+	return &domain.SpikeEventDraftListResponse{
+		Events:   drafts,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// Schedule 设定发布时间：先写入发布计划（覆盖旧计划并清空审批），再把草稿置为待发布
+func (s *spikeEventPublishingService) Schedule(ctx context.Context, eventID int64, req *domain.ScheduleSpikeEventRequest, operatorID int64) (*domain.SpikeEventDraft, error) {
+	publishAt, err := time.Parse(time.RFC3339, req.PublishAt)
+	if err != nil {
+		return nil, domain.NewInvalidArgumentError("publish_at 必须为 RFC3339 时间")
+	}
+
+	event, err := s.events.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != domain.SpikeEventStatusDraft {
+		return nil, domain.ErrSpikeEventNotDraft
+	}
+	if !publishAt.Before(event.EndAt) {
+		return nil, domain.NewInvalidArgumentError("发布时间必须早于活动结束时间")
+	}
+
+	publication := &domain.SpikeEventPublication{
+		SpikeEventID: eventID,
+		PublishAt:    publishAt,
+		ScheduledBy:  operatorID,
+	}
+	if err := s.publications.Upsert(ctx, publication); err != nil {
+		return nil, fmt.Errorf("failed to save publication: %w", err)
+	}
+	if err := s.transition(ctx, event, domain.SpikeEventStatusScheduled); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("活动已排期",
+		zap.Int64("event_id", eventID),
+		zap.Time("publish_at", publishAt),
+		zap.Int64("operator_id", operatorID))
+	return s.draft(ctx, event)
+}
+
+// Approve 审批发布计划，以条件更新保证并发审批时只有一次生效
+func (s *spikeEventPublishingService) Approve(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error) {
+	event, err := s.events.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != domain.SpikeEventStatusScheduled {
+		return nil, domain.ErrSpikeEventNotScheduled
+	}
+
+	publication, err := s.publications.GetByEventID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publication: %w", err)
+	}
+	switch {
+	case publication == nil:
+		return nil, domain.ErrSpikeEventNotScheduled
+	case publication.IsApproved():
+		return nil, domain.ErrSpikeEventAlreadyApproved
+	case publication.ScheduledBy == operatorID:
+		return nil, domain.ErrSpikeEventSelfApproval
+	}
+
+	approved, err := s.publications.Approve(ctx, eventID, operatorID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve publication: %w", err)
+	}
+	if !approved {
+		return nil, domain.ErrSpikeEventStatusChanged
+	}
+
+	s.logger.Info("活动发布计划已审批",
+		zap.Int64("event_id", eventID),
+		zap.Int64("scheduled_by", publication.ScheduledBy),
+		zap.Int64("operator_id", operatorID))
+	return s.draft(ctx, event)
+}
+
+// Unschedule 撤回发布计划：先把活动置回草稿，使发布任务不再选中它，再删除发布计划
+func (s *spikeEventPublishingService) Unschedule(ctx context.Context, eventID, operatorID int64) (*domain.SpikeEventDraft, error) {
+	event, err := s.events.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != domain.SpikeEventStatusScheduled {
+		return nil, domain.ErrSpikeEventNotScheduled
+	}
+	if err := s.transition(ctx, event, domain.SpikeEventStatusDraft); err != nil {
+		return nil, err
+	}
+	if err := s.publications.Delete(ctx, eventID); err != nil {
+		// 活动已回到草稿，残留的发布计划会在下次排期时被覆盖
+		s.logger.Warn("删除发布计划失败", zap.Int64("event_id", eventID), zap.Error(err))
+	}
+
+	s.logger.Info("活动发布计划已撤回", zap.Int64("event_id", eventID), zap.Int64("operator_id", operatorID))
+	return &domain.SpikeEventDraft{SpikeEvent: event}, nil
+}
+
+// transition 按当前状态条件更新活动状态并同步到 event，状态已被并发修改时返回 domain.ErrSpikeEventStatusChanged
+func (s *spikeEventPublishingService) transition(ctx context.Context, event *domain.SpikeEvent, to domain.SpikeEventStatus) error {
+	changed, err := s.events.TransitionStatus(ctx, event.ID, event.Status, to)
+	if err != nil {
+		return fmt.Errorf("failed to transition status: %w", err)
+	}
+	if !changed {
+		return domain.ErrSpikeEventStatusChanged
+	}
+	event.Status = to
+	return nil
+}
+
+// draft 返回附带最新发布计划的活动
+func (s *spikeEventPublishingService) draft(ctx context.Context, event *domain.SpikeEvent) (*domain.SpikeEventDraft, error) {
+	publication, err := s.publications.GetByEventID(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publication: %w", err)
+	}
+	return &domain.SpikeEventDraft{SpikeEvent: event, Publication: publication}, nil
+}
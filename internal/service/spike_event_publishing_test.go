@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakePublicationRepo 发布计划仓储内存实现，到期查询通过活动仓储判断活动仍待发布
+type fakePublicationRepo struct {
+	*mocks.SpikeEventPublicationRepositoryMock
+
+	events       *MockSpikeEventRepository
+	publications map[int64]*domain.SpikeEventPublication
+}
+
+func newFakePublicationRepo(events *MockSpikeEventRepository) *fakePublicationRepo {
+	f := &fakePublicationRepo{
+		SpikeEventPublicationRepositoryMock: &mocks.SpikeEventPublicationRepositoryMock{},
+		events:                              events,
+		publications:                        make(map[int64]*domain.SpikeEventPublication),
+	}
+	f.UpsertFunc = f.upsert
+	f.GetByEventIDFunc = f.getByEventID
+	f.GetByEventIDsFunc = f.getByEventIDs
+	f.ApproveFunc = f.approve
+	f.DeleteFunc = f.delete
+	f.GetDueEventIDsFunc = f.getDueEventIDs
+	return f
+}
+
+func (f *fakePublicationRepo) upsert(ctx context.Context, publication *domain.SpikeEventPublication) error {
+	p := *publication
+	f.publications[p.SpikeEventID] = &p
+	return nil
+}
+
+func (f *fakePublicationRepo) getByEventID(ctx context.Context, eventID int64) (*domain.SpikeEventPublication, error) {
+	return f.publications[eventID], nil
+}
+
+func (f *fakePublicationRepo) getByEventIDs(ctx context.Context, eventIDs []int64) (map[int64]*domain.SpikeEventPublication, error) {
+	result := make(map[int64]*domain.SpikeEventPublication)
+	for _, id := range eventIDs {
+		if p, ok := f.publications[id]; ok {
+			result[id] = p
+		}
+	}
+	return result, nil
+}
+
+func (f *fakePublicationRepo) approve(ctx context.Context, eventID, approverID int64, at time.Time) (bool, error) {
+	p, ok := f.publications[eventID]
+	if !ok || p.ApprovedBy != nil || p.ScheduledBy == approverID {
+		return false, nil
+	}
+	p.ApprovedBy = &approverID
+	p.ApprovedAt = &at
+	return true, nil
+}
+
+func (f *fakePublicationRepo) delete(ctx context.Context, eventID int64) error {
+	delete(f.publications, eventID)
+	return nil
+}
+
+func (f *fakePublicationRepo) getDueEventIDs(ctx context.Context, now time.Time) ([]int64, error) {
+	var ids []int64
+	for id, p := range f.publications {
+		event, err := f.events.GetByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		if event.Status == domain.SpikeEventStatusScheduled && p.IsApproved() && !p.PublishAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func TestSpikeEventPublishing_ScheduleApproveUnschedule(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	draft := testutil.NewSpikeEventBuilder().Pending().Draft().Build()
+	pending := testutil.NewSpikeEventBuilder().Pending().Build()
+	testutil.SeedSpikeEvents(t, events, draft, pending)
+
+	publications := newFakePublicationRepo(events)
+	svc := NewSpikeEventPublishingService(events, publications, nil)
+	ctx := context.Background()
+	publishAt := &domain.ScheduleSpikeEventRequest{PublishAt: time.Now().Add(time.Minute).Format(time.RFC3339)}
+
+	if _, err := svc.Schedule(ctx, pending.ID, publishAt, 1); !errors.Is(err, domain.ErrSpikeEventNotDraft) {
+		t.Errorf("Schedule() on pending event error = %v, want ErrSpikeEventNotDraft", err)
+	}
+	late := &domain.ScheduleSpikeEventRequest{PublishAt: draft.EndAt.Add(time.Minute).Format(time.RFC3339)}
+	if _, err := svc.Schedule(ctx, draft.ID, late, 1); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("Schedule() after end_at error = %v, want ErrInvalidArgument", err)
+	}
+
+	scheduled, err := svc.Schedule(ctx, draft.ID, publishAt, 1)
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if scheduled.Status != domain.SpikeEventStatusScheduled || scheduled.Publication == nil || scheduled.Publication.ScheduledBy != 1 {
+		t.Errorf("Schedule() = %+v, want scheduled by admin 1", scheduled)
+	}
+
+	// 排期人不能审批自己的发布计划
+	if _, err := svc.Approve(ctx, draft.ID, 1); !errors.Is(err, domain.ErrSpikeEventSelfApproval) {
+		t.Errorf("Approve() by scheduler error = %v, want ErrSpikeEventSelfApproval", err)
+	}
+	approved, err := svc.Approve(ctx, draft.ID, 2)
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if !approved.Publication.IsApproved() || *approved.Publication.ApprovedBy != 2 {
+		t.Errorf("Approve() publication = %+v, want approved by admin 2", approved.Publication)
+	}
+	if _, err := svc.Approve(ctx, draft.ID, 3); !errors.Is(err, domain.ErrSpikeEventAlreadyApproved) {
+		t.Errorf("second Approve() error = %v, want ErrSpikeEventAlreadyApproved", err)
+	}
+
+	list, err := svc.ListDrafts(ctx, &domain.SpikeEventListRequest{Page: 1, PageSize: 20})
+	if err != nil {
+		t.Fatalf("ListDrafts() error = %v", err)
+	}
+	if len(list.Events) != 1 || list.Events[0].ID != draft.ID || list.Events[0].Publication == nil {
+		t.Errorf("ListDrafts() = %+v, want only the scheduled event with its publication", list.Events)
+	}
+
+	unscheduled, err := svc.Unschedule(ctx, draft.ID, 2)
+	if err != nil {
+		t.Fatalf("Unschedule() error = %v", err)
+	}
+	if unscheduled.Status != domain.SpikeEventStatusDraft || publications.publications[draft.ID] != nil {
+		t.Errorf("Unschedule() = %+v, want draft without publication", unscheduled)
+	}
+	if _, err := svc.Approve(ctx, draft.ID, 2); !errors.Is(err, domain.ErrSpikeEventNotScheduled) {
+		t.Errorf("Approve() on draft error = %v, want ErrSpikeEventNotScheduled", err)
+	}
+}
+
+func TestSpikeEventLifecycle_PublishesApprovedEvents(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	now := time.Now()
+	started := testutil.NewSpikeEventBuilder().Between(now.Add(-time.Minute), now.Add(time.Hour)).Draft().Build()
+	upcoming := testutil.NewSpikeEventBuilder().Between(now.Add(time.Hour), now.Add(2*time.Hour)).Draft().Build()
+	unapproved := testutil.NewSpikeEventBuilder().Draft().Build()
+	testutil.SeedSpikeEvents(t, events, started, upcoming, unapproved)
+
+	publications := newFakePublicationRepo(events)
+	svc := NewSpikeEventPublishingService(events, publications, nil)
+	ctx := context.Background()
+	due := &domain.ScheduleSpikeEventRequest{PublishAt: now.Add(-time.Second).Format(time.RFC3339)}
+	for _, event := range []*domain.SpikeEvent{started, upcoming, unapproved} {
+		if _, err := svc.Schedule(ctx, event.ID, due, 1); err != nil {
+			t.Fatalf("Schedule() error = %v", err)
+		}
+	}
+	for _, event := range []*domain.SpikeEvent{started, upcoming} {
+		if _, err := svc.Approve(ctx, event.ID, 2); err != nil {
+			t.Fatalf("Approve() error = %v", err)
+		}
+	}
+
+	notifier := &fakeNotificationPublisher{}
+	lifecycle := NewSpikeEventLifecycle(events, &fakeLifecycleCache{}, notifier, time.Second, nil)
+	lifecycle.SetPublications(publications)
+
+	n, err := lifecycle.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	// 已到开始时间的活动发布后在同一轮内继续流转为进行中
+	if n != 3 {
+		t.Errorf("RunOnce() = %d, want 3", n)
+	}
+	want := map[*domain.SpikeEvent]domain.SpikeEventStatus{
+		started:    domain.SpikeEventStatusActive,
+		upcoming:   domain.SpikeEventStatusPending,
+		unapproved: domain.SpikeEventStatusScheduled,
+	}
+	for event, status := range want {
+		if event.Status != status {
+			t.Errorf("event %d status = %s, want %s", event.ID, event.Status, status)
+		}
+	}
+	if len(notifier.notifications) == 0 || notifier.notifications[0].Title != "秒杀活动已上线" {
+		t.Errorf("notifications = %+v, want publish broadcast first", notifier.notifications)
+	}
+}
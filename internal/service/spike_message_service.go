@@ -0,0 +1,902 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// SpikeMessageCache 消息用例所需的缓存操作（由 cache.SpikeCache 实现）
+type SpikeMessageCache interface {
+	RestoreStock(ctx context.Context, eventID, userID, quantity int64) (int64, error)
+	ReleaseCampaignQuota(ctx context.Context, campaignID, userID int64) error
+	SetIdempotencyKey(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+}
+
+// SpikeBackingOrderCreator 为已支付的秒杀订单创建对应的普通订单，重复调用返回同一订单（由 OrderService 实现）
+type SpikeBackingOrderCreator interface {
+	CreateForSpikeOrder(ctx context.Context, spikeOrder *domain.SpikeOrder, spikeEvent *domain.SpikeEvent) (*domain.Order, error)
+}
+
+// SpikeVariantStock 指定了商品规格的活动落库与恢复时变动的规格库存（由 repo.ProductVariantRepository 实现）
+type SpikeVariantStock interface {
+	ConsumeStock(ctx context.Context, id int64, quantity int) error
+	AdjustStock(ctx context.Context, id int64, quantity int) error
+}
+
+// PaymentRefunder 对已扣款的支付意图退款（由 payment.Provider 实现）
+type PaymentRefunder interface {
+	Refund(ctx context.Context, intentID string, amount float64) (*payment.Refund, error)
+}
+
+// SpikeMessageService 秒杀消息对应的业务用例：订单落库、支付确认、过期与取消后的库存恢复。
+// 与消息传输无关，RabbitMQ 与 Redis Streams 消费者都只负责解析消息并调用这里的方法；
+// 返回 *mq.NonRetryableError 表示消息不应重试
+type SpikeMessageService struct {
+	spikeEventRepo repo.SpikeEventRepository
+	spikeOrderRepo repo.SpikeOrderRepository
+	inventoryRepo  repo.InventoryRepository
+	orderEventRepo repo.OrderEventRepository
+	spikeCache     SpikeMessageCache
+	logger         *zap.Logger
+
+	// 数据库连接，可为空，为空时不开启事务
+	db *sql.DB
+
+	// 通知发布，可为空
+	notifier mq.NotificationPublisher
+
+	// 弃单召回跟踪，可为空
+	abandonedRepo      repo.AbandonedCheckoutRepository
+	abandonedPublisher mq.AbandonedCheckoutPublisher
+
+	// 库存不变量检查，可为空
+	invariants *StockInvariantChecker
+
+	// 订单号生成器，可为空
+	orderNos OrderNoGenerator
+
+	// 普通订单创建，可为空
+	orderCreator SpikeBackingOrderCreator
+
+	// 单用户每日秒杀成功次数上限，可为空
+	dailyQuota *DailyQuota
+
+	// 订单过期延时消息，可为空
+	expiryScheduler DelayedExpiryPublisher
+
+	// 商品规格库存，可为空，为空时指定了规格的活动也变动商品库存
+	variantStock SpikeVariantStock
+
+	// 支付退款，可为空，为空时订单取消或过期后收到的支付只记录日志
+	refunder PaymentRefunder
+}
+
+// DelayedExpiryPublisher 发布在订单过期时间投递的过期消息（由 mq.SpikeProducer 实现）
+type DelayedExpiryPublisher interface {
+	PublishSpikeOrderExpiredAt(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error
+}
+
+// NewSpikeMessageService 创建秒杀消息用例服务
+func NewSpikeMessageService(
+	spikeEventRepo repo.SpikeEventRepository,
+	spikeOrderRepo repo.SpikeOrderRepository,
+	inventoryRepo repo.InventoryRepository,
+	orderEventRepo repo.OrderEventRepository,
+	spikeCache SpikeMessageCache,
+	logger *zap.Logger,
+) *SpikeMessageService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SpikeMessageService{
+		spikeEventRepo: spikeEventRepo,
+		spikeOrderRepo: spikeOrderRepo,
+		inventoryRepo:  inventoryRepo,
+		orderEventRepo: orderEventRepo,
+		spikeCache:     spikeCache,
+		logger:         logger,
+	}
+}
+
+// SetTransactionDB 设置数据库连接，订单落库与库存恢复在读已提交事务中执行
+func (s *SpikeMessageService) SetTransactionDB(db *sql.DB) {
+	s.db = db
+}
+
+// SetNotificationPublisher 设置订单状态变化后的通知发布者，未设置时不发送通知
+func (s *SpikeMessageService) SetNotificationPublisher(notifier mq.NotificationPublisher) {
+	s.notifier = notifier
+}
+
+// SetAbandonedCheckoutTracking 设置弃单召回跟踪：订单过期时记录弃单并发布营销事件，
+// 通过召回活动下单时标记转化。publisher 为空时只记录不发布；未设置时不跟踪
+func (s *SpikeMessageService) SetAbandonedCheckoutTracking(abandonedRepo repo.AbandonedCheckoutRepository, publisher mq.AbandonedCheckoutPublisher) {
+	s.abandonedRepo = abandonedRepo
+	s.abandonedPublisher = publisher
+}
+
+// SetOrderNoGenerator 设置订单号生成器，为没有订单号的消息补发订单号；为空时使用 idgen.Default()
+func (s *SpikeMessageService) SetOrderNoGenerator(gen OrderNoGenerator) {
+	s.orderNos = gen
+}
+
+// SetOrderCreator 设置普通订单创建：支付消息未携带普通订单ID时，为秒杀订单创建已支付的普通订单并关联；
+// 未设置时只关联消息携带的普通订单ID
+func (s *SpikeMessageService) SetOrderCreator(creator SpikeBackingOrderCreator) {
+	s.orderCreator = creator
+}
+
+// SetDailyQuota 设置单用户每日秒杀成功次数上限，落库时库存不足归还参与当日的次数；未设置时不归还
+func (s *SpikeMessageService) SetDailyQuota(quota *DailyQuota) {
+	s.dailyQuota = quota
+}
+
+// SetExpiryScheduler 设置订单过期延时消息：订单落库后发布在过期时间投递的过期消息，
+// 订单到期即恢复库存而无需等待过期任务扫描；发布失败只记录日志，由过期任务兜底。未设置时只依赖过期任务
+func (s *SpikeMessageService) SetExpiryScheduler(publisher DelayedExpiryPublisher) {
+	s.expiryScheduler = publisher
+}
+
+// SetVariantStock 设置商品规格库存：指定了规格的活动落库时消费规格库存，过期或取消时恢复规格库存；
+// 未设置时这类活动也变动商品库存
+func (s *SpikeMessageService) SetVariantStock(variantStock SpikeVariantStock) {
+	s.variantStock = variantStock
+}
+
+// SetPaymentRefunder 设置支付退款：订单取消或过期后才收到的支付消息退回已扣款项并记录退款事件；
+// 未设置时只记录错误日志，需人工退款
+func (s *SpikeMessageService) SetPaymentRefunder(refunder PaymentRefunder) {
+	s.refunder = refunder
+}
+
+// SetInvariantChecker 设置库存不变量检查，订单落库与库存恢复提交后检查对应活动；未设置时不检查
+func (s *SpikeMessageService) SetInvariantChecker(checker *StockInvariantChecker) {
+	s.invariants = checker
+}
+
+// CreateSpikeOrderFromMessage 根据下单消息创建秒杀订单
+// 业务规则：
+// 1. 按幂等键去重，重复消息直接成功
+// 2. 活动未进行或数据库库存不足时不重试，库存不足时归还 Redis 库存、专场购买次数与每日秒杀次数，降级参与占用的已售数量在活动未进行时归还
+// 3. 订单落库后记录订单事件、关联召回转化并通知用户
+func (s *SpikeMessageService) CreateSpikeOrderFromMessage(ctx context.Context, messageID, traceID string, data *mq.SpikeOrderCreatedData) error {
+	if duplicate, err := s.claimMessage(ctx, data.IdempotencyKey, messageID); err != nil || duplicate {
+		if duplicate {
+			s.logger.Info("重复消息，跳过处理",
+				logger.IdempotencyKey(data.IdempotencyKey),
+				zap.String("message_id", messageID))
+		}
+		return err
+	}
+
+	var spikeOrder *domain.SpikeOrder
+	err := s.inTx(ctx, func() error {
+		// 验证秒杀活动是否有效
+		spikeEvent, err := s.spikeEventRepo.GetByID(ctx, data.SpikeEventID)
+		if err != nil {
+			return fmt.Errorf("failed to get spike event: %w", err)
+		}
+
+		// 检查库存并更新已售数量；降级参与的消息已原子性占用已售数量
+		if err := s.takeSoldCount(ctx, spikeEvent, data); err != nil {
+			return err
+		}
+		if !data.SoldCountReserved {
+			if err := s.spikeEventRepo.UpdateSoldCount(ctx, spikeEvent.ID, spikeEvent.SoldCount); err != nil {
+				return fmt.Errorf("failed to update sold count: %w", err)
+			}
+		}
+
+		// 创建秒杀订单记录
+		if spikeOrder, err = s.newSpikeOrder(data, spikeEvent); err != nil {
+			return err
+		}
+		if err := s.spikeOrderRepo.Create(ctx, spikeOrder); err != nil {
+			return fmt.Errorf("failed to create spike order: %w", err)
+		}
+		return s.consumeOrderStock(ctx, spikeOrder, spikeEvent, data.ProductID)
+	})
+	if errors.Is(err, domain.ErrSpikeOrderDuplicate) {
+		return s.rejectDuplicateOrder(ctx, data)
+	}
+	if err != nil {
+		return err
+	}
+	s.checkInvariants(ctx, data.SpikeEventID)
+	s.completeOrderCreated(ctx, messageID, traceID, data, spikeOrder)
+	return nil
+}
+
+// rejectDuplicateOrder 用户在只允许参与一次的活动中已有订单（Redis 参与标记丢失或降级参与时多实例并发），
+// 事务已回滚，归还参与时占用的库存后不再重试
+func (s *SpikeMessageService) rejectDuplicateOrder(ctx context.Context, data *mq.SpikeOrderCreatedData) error {
+	s.logger.Warn("用户已在该活动下单，丢弃重复的下单消息",
+		zap.Int64("spike_event_id", data.SpikeEventID),
+		logger.UserID(data.UserID),
+		logger.IdempotencyKey(data.IdempotencyKey))
+
+	if data.SoldCountReserved {
+		s.releaseSoldCount(ctx, data)
+	} else if _, err := s.spikeCache.RestoreStock(ctx, data.SpikeEventID, data.UserID, data.Quantity); err != nil {
+		s.logger.Error("恢复Redis库存失败", zap.Error(err))
+	}
+	return &mq.NonRetryableError{Err: domain.ErrSpikeOrderDuplicate}
+}
+
+// CreateSpikeOrdersFromMessages 批量根据下单消息创建秒杀订单，返回与 messages 一一对应的处理结果。
+// 业务规则与 CreateSpikeOrderFromMessage 相同，库存按批内顺序累计检查；同一活动的已售数量合并为一次更新，
+// 订单一条语句批量插入，均在同一事务中执行。事务失败时释放本批消息的幂等键，每条消息返回可重试的错误，
+// 由消费者逐条重试
+func (s *SpikeMessageService) CreateSpikeOrdersFromMessages(ctx context.Context, messages []*mq.SpikeOrderCreatedMessage) []error {
+	errs := make([]error, len(messages))
+	events := make(map[int64]*domain.SpikeEvent)
+	var (
+		accepted []int // 通过检查待落库的消息下标
+		orders   []*domain.SpikeOrder
+		touched  []int64 // 需要更新已售数量的活动，按首次出现的顺序
+	)
+
+	for i, message := range messages {
+		data := message.Data
+		if duplicate, err := s.claimMessage(ctx, data.IdempotencyKey, message.MessageID); err != nil || duplicate {
+			if duplicate {
+				s.logger.Info("重复消息，跳过处理",
+					logger.IdempotencyKey(data.IdempotencyKey),
+					zap.String("message_id", message.MessageID))
+			}
+			errs[i] = err
+			continue
+		}
+
+		spikeEvent, ok := events[data.SpikeEventID]
+		if !ok {
+			var err error
+			if spikeEvent, err = s.spikeEventRepo.GetByID(ctx, data.SpikeEventID); err != nil {
+				s.releaseClaim(ctx, data.IdempotencyKey)
+				errs[i] = fmt.Errorf("failed to get spike event: %w", err)
+				continue
+			}
+			events[data.SpikeEventID] = spikeEvent
+		}
+
+		soldCount := spikeEvent.SoldCount
+		if err := s.takeSoldCount(ctx, spikeEvent, data); err != nil {
+			errs[i] = err
+			continue
+		}
+		if spikeEvent.SoldCount != soldCount && !slices.Contains(touched, spikeEvent.ID) {
+			touched = append(touched, spikeEvent.ID)
+		}
+
+		spikeOrder, err := s.newSpikeOrder(data, spikeEvent)
+		if err != nil {
+			s.releaseClaim(ctx, data.IdempotencyKey)
+			errs[i] = err
+			continue
+		}
+		accepted = append(accepted, i)
+		orders = append(orders, spikeOrder)
+	}
+	if len(orders) == 0 {
+		return errs
+	}
+
+	err := s.inTx(ctx, func() error {
+		for _, spikeEventID := range touched {
+			if err := s.spikeEventRepo.UpdateSoldCount(ctx, spikeEventID, events[spikeEventID].SoldCount); err != nil {
+				return fmt.Errorf("failed to update sold count: %w", err)
+			}
+		}
+		if err := s.spikeOrderRepo.CreateBatch(ctx, orders); err != nil {
+			return fmt.Errorf("failed to create spike orders: %w", err)
+		}
+		for j, spikeOrder := range orders {
+			data := messages[accepted[j]].Data
+			if err := s.consumeOrderStock(ctx, spikeOrder, events[data.SpikeEventID], data.ProductID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("批量创建秒杀订单失败，逐条重试", zap.Int("count", len(orders)), zap.Error(err))
+		for _, i := range accepted {
+			s.releaseClaim(ctx, messages[i].Data.IdempotencyKey)
+			errs[i] = err
+		}
+		return errs
+	}
+
+	checked := make(map[int64]bool, len(events))
+	for j, i := range accepted {
+		message := messages[i]
+		if !checked[message.Data.SpikeEventID] {
+			checked[message.Data.SpikeEventID] = true
+			s.checkInvariants(ctx, message.Data.SpikeEventID)
+		}
+		s.completeOrderCreated(ctx, message.MessageID, message.TraceID, message.Data, orders[j])
+	}
+	return errs
+}
+
+// takeSoldCount 检查活动进行中且数据库库存充足，并在 spikeEvent 上累加已售数量（降级参与的消息已占用，不再累加）。
+// 检查失败时归还参与占用的资源并返回不可重试的错误
+func (s *SpikeMessageService) takeSoldCount(ctx context.Context, spikeEvent *domain.SpikeEvent, data *mq.SpikeOrderCreatedData) error {
+	if !spikeEvent.IsActive() {
+		if data.SoldCountReserved {
+			s.releaseSoldCount(ctx, data)
+		}
+		return &mq.NonRetryableError{Err: fmt.Errorf("spike event %d is not active", data.SpikeEventID)}
+	}
+	if data.SoldCountReserved {
+		return nil
+	}
+
+	if spikeEvent.SoldCount+data.Quantity > spikeEvent.SpikeStock {
+		s.logger.Warn("库存不足，恢复Redis库存",
+			zap.Int64("spike_event_id", data.SpikeEventID),
+			zap.Int64("sold_count", spikeEvent.SoldCount),
+			zap.Int64("spike_stock", spikeEvent.SpikeStock),
+			zap.Int64("requested_quantity", data.Quantity))
+
+		if _, err := s.spikeCache.RestoreStock(ctx, data.SpikeEventID, data.UserID, data.Quantity); err != nil {
+			s.logger.Error("恢复Redis库存失败", zap.Error(err))
+		}
+		s.releaseCampaignQuota(ctx, spikeEvent, data.UserID)
+		if s.dailyQuota != nil {
+			s.dailyQuota.Release(ctx, data.UserID, data.CreatedAt)
+		}
+
+		return &mq.NonRetryableError{Err: fmt.Errorf("insufficient stock")}
+	}
+
+	spikeEvent.SoldCount += data.Quantity
+	return nil
+}
+
+// newSpikeOrder 根据下单消息构造待支付订单，升级前发出的消息没有订单号，落库时补发
+func (s *SpikeMessageService) newSpikeOrder(data *mq.SpikeOrderCreatedData, spikeEvent *domain.SpikeEvent) (*domain.SpikeOrder, error) {
+	orderNo := data.OrderNo
+	if orderNo == "" {
+		var err error
+		if orderNo, err = nextOrderNo(s.orderNos); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.SpikeOrder{
+		OrderNo:        orderNo,
+		SpikeEventID:   data.SpikeEventID,
+		UserID:         data.UserID,
+		Quantity:       data.Quantity,
+		SpikePrice:     data.SpikePrice,
+		TotalAmount:    data.TotalAmount,
+		Status:         domain.SpikeOrderStatusPending,
+		IdempotencyKey: data.IdempotencyKey,
+		ClientIP:       data.ClientIP,
+		UserAgent:      data.UserAgent,
+		Channel:        domain.SpikeOrderChannel(data.Channel),
+		ExpireAt:       &data.ExpireAt,
+		CreatedAt:      data.CreatedAt,
+		SinglePurchase: spikeEvent.UserQuantityLimit() == 0,
+	}, nil
+}
+
+// consumeOrderStock 消费订单对应的商品库存，变动流水关联订单号；活动指定了商品规格时消费规格库存
+func (s *SpikeMessageService) consumeOrderStock(ctx context.Context, spikeOrder *domain.SpikeOrder, spikeEvent *domain.SpikeEvent, productID int64) error {
+	if spikeEvent.VariantID != nil && s.variantStock != nil {
+		if err := s.variantStock.ConsumeStock(ctx, *spikeEvent.VariantID, int(spikeOrder.Quantity)); err != nil {
+			return fmt.Errorf("failed to consume variant stock: %w", err)
+		}
+		return nil
+	}
+
+	stockCtx := domain.WithStockReference(ctx, spikeOrder.OrderNo, "秒杀下单")
+	if err := s.inventoryRepo.ConsumeStock(stockCtx, productID, int(spikeOrder.Quantity)); err != nil {
+		return fmt.Errorf("failed to consume inventory: %w", err)
+	}
+	return nil
+}
+
+// completeOrderCreated 订单落库提交后标记幂等键完成，记录订单事件、关联召回转化、发布过期消息并通知用户
+func (s *SpikeMessageService) completeOrderCreated(ctx context.Context, messageID, traceID string, data *mq.SpikeOrderCreatedData, spikeOrder *domain.SpikeOrder) {
+	s.markProcessed(ctx, data.IdempotencyKey, messageID)
+
+	s.recordOrderEvent(&domain.OrderEvent{
+		SpikeOrderID: spikeOrder.ID,
+		EventType:    domain.OrderEventCreated,
+		ToStatus:     string(domain.SpikeOrderStatusPending),
+		Actor:        domain.UserActor(data.UserID),
+		TraceID:      traceID,
+	})
+
+	if data.RecoveryCampaignID != "" {
+		s.trackRecovery(data.UserID, data.RecoveryCampaignID, spikeOrder.ID)
+	}
+
+	s.scheduleExpiry(ctx, traceID, spikeOrder, data.ProductID)
+
+	s.notify(ctx, traceID, &mq.NotificationData{
+		UserID:   data.UserID,
+		Type:     "spike_order_created",
+		Title:    "秒杀成功",
+		Content:  "您的秒杀订单已创建，请在有效期内完成支付",
+		Data:     map[string]interface{}{"spike_order_id": spikeOrder.ID, "expire_at": data.ExpireAt},
+		Priority: "high",
+		Channels: []string{"push"},
+	})
+
+	s.logger.Info("秒杀订单创建成功",
+		zap.Int64("spike_order_id", spikeOrder.ID),
+		zap.Int64("spike_event_id", data.SpikeEventID),
+		logger.UserID(data.UserID),
+		logger.IdempotencyKey(data.IdempotencyKey))
+}
+
+// MarkSpikeOrderPaidFromMessage 根据支付消息更新订单支付信息并关联普通订单
+func (s *SpikeMessageService) MarkSpikeOrderPaidFromMessage(ctx context.Context, traceID string, data *mq.SpikeOrderPaidData) error {
+	// 扣款期间订单可能已被取消或过期，预留的库存已经归还，退款而不标记为已支付
+	spikeOrder, err := s.spikeOrderRepo.GetByID(ctx, data.SpikeOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get spike order: %w", err)
+	}
+	if spikeOrder.Status == domain.SpikeOrderStatusCancelled || spikeOrder.Status == domain.SpikeOrderStatusExpired {
+		return s.refundLatePayment(ctx, traceID, spikeOrder, data)
+	}
+
+	err = s.inTx(ctx, func() error {
+		if err := s.spikeOrderRepo.UpdatePaymentInfo(ctx, data.SpikeOrderID, data.PaidAt, data.TransactionID); err != nil {
+			return fmt.Errorf("failed to update spike order payment info: %w", err)
+		}
+		if data.OrderID > 0 {
+			if err := s.spikeOrderRepo.UpdateOrderID(ctx, data.SpikeOrderID, data.OrderID); err != nil {
+				return fmt.Errorf("failed to update order id: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 支付信息更新可重复执行，普通订单按秒杀订单ID去重，创建失败时重试整条消息
+	orderID := data.OrderID
+	if orderID == 0 && s.orderCreator != nil {
+		if orderID, err = s.createBackingOrder(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	s.recordOrderEvent(&domain.OrderEvent{
+		SpikeOrderID: data.SpikeOrderID,
+		EventType:    domain.OrderEventPaid,
+		FromStatus:   string(domain.SpikeOrderStatusPending),
+		ToStatus:     string(domain.SpikeOrderStatusPaid),
+		Actor:        domain.UserActor(data.UserID),
+		Remark:       data.PaymentMethod,
+		TraceID:      traceID,
+	})
+
+	s.notify(ctx, traceID, &mq.NotificationData{
+		UserID:   data.UserID,
+		Type:     "spike_order_paid",
+		Title:    "支付成功",
+		Content:  "您的秒杀订单已支付成功",
+		Data:     map[string]interface{}{"spike_order_id": data.SpikeOrderID},
+		Priority: "normal",
+		Channels: []string{"push"},
+	})
+
+	s.logger.Info("秒杀订单支付处理成功",
+		zap.Int64("spike_order_id", data.SpikeOrderID),
+		zap.Int64("order_id", orderID),
+		logger.UserID(data.UserID),
+		zap.String("payment_method", data.PaymentMethod))
+	return nil
+}
+
+// refundLatePayment 退回订单取消或过期后才到达的支付并记录退款事件，订单保持原状态；
+// 重复消息再次退款时渠道返回 payment.ErrInvalidState（已全额退款）或 payment.ErrRefundExceeds，视为已退款
+func (s *SpikeMessageService) refundLatePayment(ctx context.Context, traceID string, spikeOrder *domain.SpikeOrder, data *mq.SpikeOrderPaidData) error {
+	if s.refunder == nil {
+		s.logger.Error("订单已取消或过期但收到支付，未配置退款，需人工退款",
+			zap.Int64("spike_order_id", spikeOrder.ID),
+			zap.String("status", string(spikeOrder.Status)),
+			zap.String("transaction_id", data.TransactionID),
+			zap.Float64("paid_amount", data.PaidAmount))
+		return nil
+	}
+
+	refund, err := s.refunder.Refund(ctx, data.TransactionID, data.PaidAmount)
+	if errors.Is(err, payment.ErrInvalidState) || errors.Is(err, payment.ErrRefundExceeds) {
+		s.logger.Info("支付已退款，忽略重复的支付消息",
+			zap.Int64("spike_order_id", spikeOrder.ID), zap.String("transaction_id", data.TransactionID))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	s.recordOrderEvent(&domain.OrderEvent{
+		SpikeOrderID: spikeOrder.ID,
+		EventType:    domain.OrderEventRefunded,
+		FromStatus:   string(spikeOrder.Status),
+		ToStatus:     string(spikeOrder.Status),
+		Actor:        domain.ActorSystem,
+		Remark:       data.PaymentMethod + ":" + refund.ID,
+		TraceID:      traceID,
+	})
+
+	s.notify(ctx, traceID, &mq.NotificationData{
+		UserID:   data.UserID,
+		Type:     "spike_order_refunded",
+		Title:    "支付已退款",
+		Content:  "您的秒杀订单已取消或超时，支付款项已原路退回",
+		Data:     map[string]interface{}{"spike_order_id": spikeOrder.ID},
+		Priority: "normal",
+		Channels: []string{"push"},
+	})
+
+	s.logger.Info("订单已取消或过期，支付已退款",
+		zap.Int64("spike_order_id", spikeOrder.ID),
+		zap.String("status", string(spikeOrder.Status)),
+		zap.String("refund_id", refund.ID))
+	return nil
+}
+
+// createBackingOrder 创建秒杀订单对应的普通订单并关联，已关联时直接返回；活动已不存在时不创建，返回 0
+func (s *SpikeMessageService) createBackingOrder(ctx context.Context, data *mq.SpikeOrderPaidData) (int64, error) {
+	spikeOrder, err := s.spikeOrderRepo.GetByID(ctx, data.SpikeOrderID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spike order: %w", err)
+	}
+	if spikeOrder.OrderID != nil {
+		return *spikeOrder.OrderID, nil
+	}
+	spikeEvent, err := s.spikeEventRepo.GetByID(ctx, spikeOrder.SpikeEventID)
+	if errors.Is(err, domain.ErrSpikeEventNotFound) {
+		s.logger.Warn("秒杀活动已不存在，不创建普通订单",
+			zap.Int64("spike_order_id", spikeOrder.ID),
+			zap.Int64("spike_event_id", spikeOrder.SpikeEventID))
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spike event: %w", err)
+	}
+
+	// 读取的订单可能来自尚未同步支付信息的从库，以消息为准
+	paidAt := data.PaidAt
+	spikeOrder.PaidAt = &paidAt
+	spikeOrder.PaymentRef = data.TransactionID
+	order, err := s.orderCreator.CreateForSpikeOrder(ctx, spikeOrder, spikeEvent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create backing order: %w", err)
+	}
+	if err := s.spikeOrderRepo.UpdateOrderID(ctx, spikeOrder.ID, order.ID); err != nil {
+		return 0, fmt.Errorf("failed to update order id: %w", err)
+	}
+	return order.ID, nil
+}
+
+// ExpireSpikeOrderFromMessage 根据过期消息恢复库存并记录弃单；
+// 订单可能在消息发出后已支付、已取消或延长了支付时间，以数据库中的最新状态为准。
+// 延时消息到达时订单通常仍为待支付，先将其标记为已过期，标记失败说明订单已被并发支付或延长
+func (s *SpikeMessageService) ExpireSpikeOrderFromMessage(ctx context.Context, messageID, traceID string, data *mq.SpikeOrderExpiredData) error {
+	spikeOrder, err := s.spikeOrderRepo.GetByID(ctx, data.SpikeOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get spike order: %w", err)
+	}
+	if spikeOrder != nil && (spikeOrder.IsPaid() || spikeOrder.IsCancelled() ||
+		(spikeOrder.IsPending() && spikeOrder.ExpireAt != nil && spikeOrder.ExpireAt.After(data.ExpiredAt))) {
+		s.logger.Info("订单已支付、取消或延长，忽略过期消息",
+			zap.Int64("spike_order_id", data.SpikeOrderID),
+			zap.String("status", string(spikeOrder.Status)))
+		return nil
+	}
+	if spikeOrder != nil && spikeOrder.IsPending() {
+		marked, err := s.spikeOrderRepo.MarkExpired(ctx, spikeOrder.ID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to mark order expired: %w", err)
+		}
+		if !marked {
+			s.logger.Info("订单尚未到期或已被并发处理，忽略过期消息",
+				zap.Int64("spike_order_id", data.SpikeOrderID))
+			return nil
+		}
+	}
+
+	if duplicate, err := s.claimMessage(ctx, data.IdempotencyKey, messageID); err != nil || duplicate {
+		return err
+	}
+
+	if err := s.restoreStock(ctx, messageID, &mq.StockRestoreData{
+		SpikeEventID:   data.SpikeEventID,
+		ProductID:      data.ProductID,
+		UserID:         data.UserID,
+		Quantity:       data.Quantity,
+		Reason:         "order_expired",
+		SourceOrderID:  data.SpikeOrderID,
+		IdempotencyKey: data.IdempotencyKey,
+	}); err != nil {
+		return err
+	}
+
+	s.recordOrderEvent(&domain.OrderEvent{
+		SpikeOrderID: data.SpikeOrderID,
+		EventType:    domain.OrderEventExpired,
+		FromStatus:   string(domain.SpikeOrderStatusPending),
+		ToStatus:     string(domain.SpikeOrderStatusExpired),
+		Actor:        domain.ActorSystem,
+		TraceID:      traceID,
+	})
+
+	s.trackAbandoned(ctx, traceID, data, spikeOrder)
+	return nil
+}
+
+// RestoreStockFromMessage 根据取消或库存恢复消息归还数据库、Redis 库存与专场购买次数，按幂等键去重
+func (s *SpikeMessageService) RestoreStockFromMessage(ctx context.Context, messageID string, data *mq.StockRestoreData) error {
+	if duplicate, err := s.claimMessage(ctx, data.IdempotencyKey, messageID); err != nil || duplicate {
+		return err
+	}
+	return s.restoreStock(ctx, messageID, data)
+}
+
+// restoreStock 库存恢复的通用流程，调用方已完成幂等检查
+func (s *SpikeMessageService) restoreStock(ctx context.Context, messageID string, data *mq.StockRestoreData) error {
+	err := s.inTx(ctx, func() error {
+		// 恢复秒杀活动库存
+		spikeEvent, err := s.spikeEventRepo.GetByID(ctx, data.SpikeEventID)
+		if err != nil {
+			return fmt.Errorf("failed to get spike event: %w", err)
+		}
+
+		if spikeEvent.SoldCount >= data.Quantity {
+			spikeEvent.SoldCount -= data.Quantity
+			if err := s.spikeEventRepo.UpdateSoldCount(ctx, spikeEvent.ID, spikeEvent.SoldCount); err != nil {
+				return fmt.Errorf("failed to update sold count: %w", err)
+			}
+		}
+
+		// 恢复商品库存，变动流水关联来源订单；活动指定了商品规格时恢复规格库存
+		if spikeEvent.VariantID != nil && s.variantStock != nil {
+			if err := s.variantStock.AdjustStock(ctx, *spikeEvent.VariantID, int(data.Quantity)); err != nil {
+				return fmt.Errorf("failed to restore variant stock: %w", err)
+			}
+		} else {
+			var reference string
+			if data.SourceOrderID > 0 {
+				reference = strconv.FormatInt(data.SourceOrderID, 10)
+			}
+			stockCtx := domain.WithStockReference(ctx, reference, data.Reason)
+			if err := s.inventoryRepo.AdjustStock(stockCtx, data.ProductID, int(data.Quantity), data.Reason); err != nil {
+				return fmt.Errorf("failed to restore inventory: %w", err)
+			}
+		}
+
+		// 恢复Redis库存，失败不影响数据库事务，只记录错误
+		restoredStock, err := s.spikeCache.RestoreStock(ctx, data.SpikeEventID, data.UserID, data.Quantity)
+		if err != nil {
+			s.logger.Error("恢复Redis库存失败", zap.Error(err))
+		} else {
+			s.logger.Info("恢复Redis库存成功",
+				zap.Int64("spike_event_id", data.SpikeEventID),
+				zap.Int64("restored_stock", restoredStock))
+		}
+		s.releaseCampaignQuota(ctx, spikeEvent, data.UserID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.checkInvariants(ctx, data.SpikeEventID)
+	s.markProcessed(ctx, data.IdempotencyKey, messageID)
+
+	s.logger.Info("库存恢复处理成功",
+		zap.Int64("spike_event_id", data.SpikeEventID),
+		zap.Int64("product_id", data.ProductID),
+		logger.UserID(data.UserID),
+		zap.Int64("quantity", data.Quantity),
+		zap.String("reason", data.Reason),
+		zap.Int64("source_order_id", data.SourceOrderID))
+	return nil
+}
+
+// inTx 在读已提交事务中执行 fn，fn 返回错误时回滚；未设置数据库时直接执行
+func (s *SpikeMessageService) inTx(ctx context.Context, fn func() error) error {
+	if s.db == nil {
+		return fn()
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// claimMessage 以幂等键占用消息处理权，键已存在时返回 duplicate=true
+func (s *SpikeMessageService) claimMessage(ctx context.Context, idempotencyKey, messageID string) (duplicate bool, err error) {
+	processed := keys.Redis("processed", idempotencyKey)
+	ok, err := s.spikeCache.SetIdempotencyKey(ctx, processed, messageID, 24*time.Hour)
+	if err != nil {
+		return false, fmt.Errorf("failed to check idempotency: %w", err)
+	}
+	return !ok, nil
+}
+
+// releaseClaim 释放 claimMessage 占用的处理权，使消息重试时不被当作重复消息跳过，失败只记录日志
+func (s *SpikeMessageService) releaseClaim(ctx context.Context, idempotencyKey string) {
+	if err := s.spikeCache.DeleteIdempotencyKey(ctx, keys.Redis("processed", idempotencyKey)); err != nil {
+		s.logger.Error("释放幂等键失败", logger.IdempotencyKey(idempotencyKey), zap.Error(err))
+	}
+}
+
+// markProcessed 标记幂等键处理完成，失败只记录日志
+func (s *SpikeMessageService) markProcessed(ctx context.Context, idempotencyKey, messageID string) {
+	completed := keys.Redis("completed", idempotencyKey)
+	if _, err := s.spikeCache.SetIdempotencyKey(ctx, completed, messageID, 24*time.Hour); err != nil {
+		s.logger.Error("标记幂等键处理完成失败", zap.Error(err))
+	}
+}
+
+// releaseCampaignQuota 归还用户在活动所属专场内的一次购买次数，与 Redis 库存恢复一样只记录错误
+func (s *SpikeMessageService) releaseCampaignQuota(ctx context.Context, spikeEvent *domain.SpikeEvent, userID int64) {
+	if spikeEvent.SpikeCampaignID == nil {
+		return
+	}
+	if err := s.spikeCache.ReleaseCampaignQuota(ctx, *spikeEvent.SpikeCampaignID, userID); err != nil {
+		s.logger.Error("归还专场购买次数失败",
+			zap.Int64("spike_campaign_id", *spikeEvent.SpikeCampaignID),
+			logger.UserID(userID),
+			zap.Error(err))
+	}
+}
+
+// scheduleExpiry 发布订单过期延时消息，失败只记录日志，由过期任务兜底
+func (s *SpikeMessageService) scheduleExpiry(ctx context.Context, traceID string, spikeOrder *domain.SpikeOrder, productID int64) {
+	if s.expiryScheduler == nil || spikeOrder.ExpireAt == nil {
+		return
+	}
+	if err := s.expiryScheduler.PublishSpikeOrderExpiredAt(ctx, &mq.SpikeOrderExpiredData{
+		SpikeOrderID:   spikeOrder.ID,
+		SpikeEventID:   spikeOrder.SpikeEventID,
+		UserID:         spikeOrder.UserID,
+		ProductID:      productID,
+		Quantity:       spikeOrder.Quantity,
+		ExpiredAt:      *spikeOrder.ExpireAt,
+		IdempotencyKey: keys.Idempotency("expire", spikeOrder.ID),
+	}, traceID); err != nil {
+		s.logger.Warn("发布订单过期延时消息失败，由过期任务兜底",
+			zap.Int64("spike_order_id", spikeOrder.ID),
+			zap.Error(err))
+	}
+}
+
+// releaseSoldCount 归还降级参与时在数据库中占用的已售数量，失败只记录日志
+func (s *SpikeMessageService) releaseSoldCount(ctx context.Context, data *mq.SpikeOrderCreatedData) {
+	if _, err := s.spikeEventRepo.AddSoldCount(ctx, data.SpikeEventID, -data.Quantity); err != nil {
+		s.logger.Error("归还已售数量失败",
+			zap.Int64("spike_event_id", data.SpikeEventID),
+			logger.IdempotencyKey(data.IdempotencyKey),
+			zap.Error(err))
+	}
+}
+
+// trackAbandoned 记录弃单并发布营销事件，失败只记录日志，不影响过期处理
+func (s *SpikeMessageService) trackAbandoned(ctx context.Context, traceID string, data *mq.SpikeOrderExpiredData, spikeOrder *domain.SpikeOrder) {
+	if s.abandonedRepo == nil {
+		return
+	}
+
+	checkout := &domain.AbandonedCheckout{
+		SpikeOrderID: data.SpikeOrderID,
+		SpikeEventID: data.SpikeEventID,
+		UserID:       data.UserID,
+		ProductID:    data.ProductID,
+		Quantity:     data.Quantity,
+		ExpiredAt:    data.ExpiredAt,
+	}
+	if spikeOrder != nil {
+		checkout.TotalAmount = spikeOrder.TotalAmount
+	}
+	if err := s.abandonedRepo.Create(checkout); err != nil {
+		s.logger.Error("记录弃单失败", zap.Int64("spike_order_id", data.SpikeOrderID), zap.Error(err))
+		return
+	}
+
+	if s.abandonedPublisher == nil {
+		return
+	}
+	err := s.abandonedPublisher.PublishSpikeOrderAbandoned(ctx, &mq.SpikeOrderAbandonedData{
+		AbandonedCheckoutID: checkout.ID,
+		SpikeOrderID:        checkout.SpikeOrderID,
+		SpikeEventID:        checkout.SpikeEventID,
+		UserID:              checkout.UserID,
+		ProductID:           checkout.ProductID,
+		Quantity:            checkout.Quantity,
+		TotalAmount:         checkout.TotalAmount,
+		ExpiredAt:           checkout.ExpiredAt,
+	}, traceID)
+	if err != nil {
+		s.logger.Error("发布弃单事件失败", zap.Int64("spike_order_id", data.SpikeOrderID), zap.Error(err))
+	}
+}
+
+// trackRecovery 将召回活动带来的新订单关联到对应弃单
+func (s *SpikeMessageService) trackRecovery(userID int64, campaignID string, spikeOrderID int64) {
+	if s.abandonedRepo == nil {
+		return
+	}
+
+	recovered, err := s.abandonedRepo.MarkRecovered(userID, campaignID, spikeOrderID, time.Now())
+	if err != nil {
+		s.logger.Error("记录弃单转化失败",
+			zap.Int64("spike_order_id", spikeOrderID),
+			zap.String("campaign_id", campaignID),
+			zap.Error(err))
+		return
+	}
+	if recovered {
+		s.logger.Info("弃单召回转化",
+			zap.Int64("spike_order_id", spikeOrderID),
+			logger.UserID(userID),
+			zap.String("campaign_id", campaignID))
+	}
+}
+
+// notify 发布用户通知并沿用触发消息的追踪ID，失败只记录日志不触发消息重试
+func (s *SpikeMessageService) notify(ctx context.Context, traceID string, data *mq.NotificationData) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.PublishNotification(ctx, data, traceID); err != nil {
+		s.logger.Error("发布通知失败",
+			zap.String("type", data.Type),
+			logger.UserID(data.UserID),
+			zap.String("trace_id", traceID),
+			zap.Error(err))
+	}
+}
+
+// recordOrderEvent 记录订单事件，失败只记录日志不触发消息重试
+func (s *SpikeMessageService) recordOrderEvent(event *domain.OrderEvent) {
+	if s.orderEventRepo == nil {
+		return
+	}
+	if err := s.orderEventRepo.Create(event); err != nil {
+		s.logger.Error("记录订单事件失败",
+			zap.Int64("spike_order_id", event.SpikeOrderID),
+			zap.String("event_type", string(event.EventType)),
+			zap.Error(err))
+	}
+}
+
+// checkInvariants 库存变更提交后检查活动的库存不变量
+func (s *SpikeMessageService) checkInvariants(ctx context.Context, spikeEventID int64) {
+	if s.invariants != nil {
+		s.invariants.CheckEventID(ctx, spikeEventID)
+	}
+}
@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+)
+
+// fakeMessageCache 记录库存归还与幂等键的消息用例缓存
+type fakeMessageCache struct {
+	restored      int64
+	releasedQuota int
+	keys          map[string]bool
+}
+
+func newFakeMessageCache() *fakeMessageCache {
+	return &fakeMessageCache{keys: make(map[string]bool)}
+}
+
+func (c *fakeMessageCache) RestoreStock(ctx context.Context, eventID, userID, quantity int64) (int64, error) {
+	c.restored += quantity
+	return c.restored, nil
+}
+
+func (c *fakeMessageCache) ReleaseCampaignQuota(ctx context.Context, campaignID, userID int64) error {
+	c.releasedQuota++
+	return nil
+}
+
+func (c *fakeMessageCache) SetIdempotencyKey(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if c.keys[key] {
+		return false, nil
+	}
+	c.keys[key] = true
+	return true, nil
+}
+
+func (c *fakeMessageCache) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	delete(c.keys, key)
+	return nil
+}
+
+// messageServiceFixture 消息用例测试依赖
+type messageServiceFixture struct {
+	events      *MockSpikeEventRepository
+	orders      *MockSpikeOrderRepository
+	inventory   *mocks.InventoryRepositoryMock
+	orderEvents *mocks.OrderEventRepositoryMock
+	cache       *fakeMessageCache
+	service     *SpikeMessageService
+}
+
+func newMessageServiceFixture() *messageServiceFixture {
+	f := &messageServiceFixture{
+		events: NewMockSpikeEventRepository(),
+		orders: NewMockSpikeOrderRepository(),
+		inventory: &mocks.InventoryRepositoryMock{
+			ConsumeStockFunc: func(ctx context.Context, productID int64, quantity int) error { return nil },
+			AdjustStockFunc:  func(ctx context.Context, productID int64, quantity int, reason string) error { return nil },
+		},
+		orderEvents: &mocks.OrderEventRepositoryMock{
+			CreateFunc: func(event *domain.OrderEvent) error { return nil },
+		},
+		cache: newFakeMessageCache(),
+	}
+	f.service = NewSpikeMessageService(f.events, f.orders, f.inventory, f.orderEvents, f.cache, nil)
+	return f
+}
+
+func TestSpikeMessageService_CreateSpikeOrderFromMessage(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{
+		ProductID: 3, SpikeStock: 10, SoldCount: 7, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(context.Background(), event)
+
+	data := &mq.SpikeOrderCreatedData{
+		SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 2,
+		IdempotencyKey: "idem-1", ExpireAt: time.Now().Add(15 * time.Minute),
+		ClientIP: "10.0.0.1", UserAgent: "SpikeApp/1.0", Channel: "app",
+	}
+	for range 2 {
+		if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-1", "trace-1", data); err != nil {
+			t.Fatalf("CreateSpikeOrderFromMessage() error = %v", err)
+		}
+	}
+
+	// 重复投递只落库一次
+	if n := len(f.orders.CreateCalls()); n != 1 {
+		t.Fatalf("orders created = %d, want 1", n)
+	}
+	if order := f.orders.CreateCalls()[0].Order; order.Channel != domain.SpikeOrderChannelApp || order.ClientIP != "10.0.0.1" || order.UserAgent != "SpikeApp/1.0" {
+		t.Errorf("created order client = %q %q %q", order.Channel, order.ClientIP, order.UserAgent)
+	}
+	// 升级前的消息没有订单号，落库时补发
+	if order := f.orders.CreateCalls()[0].Order; order.OrderNo == "" {
+		t.Errorf("created order has no order no")
+	}
+	if got, _ := f.events.GetByID(context.Background(), event.ID); got.SoldCount != 9 {
+		t.Errorf("sold count = %d, want 9", got.SoldCount)
+	}
+	if calls := f.inventory.ConsumeStockCalls(); len(calls) != 1 || calls[0].ProductID != 3 || calls[0].Quantity != 2 {
+		t.Errorf("ConsumeStock calls = %+v", calls)
+	}
+	if calls := f.orderEvents.CreateCalls(); len(calls) != 1 || calls[0].Event.EventType != domain.OrderEventCreated || calls[0].Event.TraceID != "trace-1" {
+		t.Errorf("order events = %+v", calls)
+	}
+
+	// 数据库库存不足时归还 Redis 库存并不再重试
+	campaignID := int64(5)
+	event.SpikeCampaignID = &campaignID
+	data.IdempotencyKey = "idem-2"
+	err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-2", "trace-2", data)
+	if !mq.IsNonRetryableError(err) {
+		t.Fatalf("CreateSpikeOrderFromMessage(insufficient) error = %v, want non-retryable", err)
+	}
+	if f.cache.restored != 2 || f.cache.releasedQuota != 1 {
+		t.Errorf("restored = %d, released quota = %d, want 2 and 1", f.cache.restored, f.cache.releasedQuota)
+	}
+}
+
+func TestSpikeMessageService_CreateSpikeOrderWithReservedSoldCount(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{
+		ProductID: 3, SpikeStock: 10, SoldCount: 10, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(ctx, event)
+
+	// 降级参与已占用已售数量：即使已售数量达到总库存也照常落库，且不再累加
+	data := &mq.SpikeOrderCreatedData{
+		SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 2,
+		IdempotencyKey: "reserved-1", ExpireAt: time.Now().Add(15 * time.Minute), SoldCountReserved: true,
+	}
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-1", "trace-1", data); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage() error = %v", err)
+	}
+	if got, _ := f.events.GetByID(ctx, event.ID); got.SoldCount != 10 || len(f.orders.CreateCalls()) != 1 {
+		t.Errorf("sold count = %d, orders = %d, want 10 and 1", got.SoldCount, len(f.orders.CreateCalls()))
+	}
+	if f.cache.restored != 0 {
+		t.Errorf("restored redis stock = %d, want 0", f.cache.restored)
+	}
+
+	// 活动已结束时归还占用的已售数量
+	event.Status = domain.SpikeEventStatusEnded
+	data.IdempotencyKey = "reserved-2"
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-2", "trace-2", data); !mq.IsNonRetryableError(err) {
+		t.Fatalf("CreateSpikeOrderFromMessage(ended) error = %v, want non-retryable", err)
+	}
+	if got, _ := f.events.GetByID(ctx, event.ID); got.SoldCount != 8 {
+		t.Errorf("sold count after release = %d, want 8", got.SoldCount)
+	}
+}
+
+func TestSpikeMessageService_CreateSpikeOrderDuplicateUser(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{
+		ProductID: 3, SpikeStock: 10, SoldCount: 2, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(ctx, event)
+
+	// 模拟用户活动唯一约束：只允许参与一次的活动中同一用户的第二笔订单插入失败
+	create := f.orders.CreateFunc
+	f.orders.CreateFunc = func(ctx context.Context, order *domain.SpikeOrder) error {
+		if existing, _ := f.orders.GetByUserAndEvent(ctx, order.UserID, order.SpikeEventID); existing != nil && order.SinglePurchase {
+			return domain.ErrSpikeOrderDuplicate
+		}
+		return create(ctx, order)
+	}
+
+	// 两个实例降级参与时都没有看到对方的订单，各自占用了已售数量
+	for i, key := range []string{"dup-1", "dup-2"} {
+		data := &mq.SpikeOrderCreatedData{
+			SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 1,
+			IdempotencyKey: key, ExpireAt: time.Now().Add(15 * time.Minute), SoldCountReserved: true,
+		}
+		err := f.service.CreateSpikeOrderFromMessage(ctx, key, "trace", data)
+		if i == 0 && err != nil {
+			t.Fatalf("CreateSpikeOrderFromMessage() error = %v", err)
+		}
+		if i == 1 && (!mq.IsNonRetryableError(err) || !errors.Is(err, domain.ErrSpikeOrderDuplicate)) {
+			t.Fatalf("CreateSpikeOrderFromMessage(duplicate) error = %v, want non-retryable duplicate", err)
+		}
+	}
+	if calls := f.orders.CreateCalls(); !calls[0].Order.SinglePurchase {
+		t.Error("order of single purchase event not marked")
+	}
+	// 重复订单归还降级参与占用的已售数量
+	if got, _ := f.events.GetByID(ctx, event.ID); got.SoldCount != 1 {
+		t.Errorf("sold count = %d, want 1", got.SoldCount)
+	}
+}
+
+func TestSpikeMessageService_CreateSpikeOrdersFromMessages(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{
+		ProductID: 3, SpikeStock: 10, SoldCount: 5, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(ctx, event)
+
+	message := func(id, key string, quantity int64) *mq.SpikeOrderCreatedMessage {
+		return &mq.SpikeOrderCreatedMessage{MessageID: id, TraceID: "trace-" + id, Data: &mq.SpikeOrderCreatedData{
+			SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: quantity,
+			IdempotencyKey: key, ExpireAt: time.Now().Add(15 * time.Minute),
+		}}
+	}
+	// 库存按批内顺序累计检查：前两条共占 4 件，第三条超出剩余库存，重复消息直接成功
+	errs := f.service.CreateSpikeOrdersFromMessages(ctx, []*mq.SpikeOrderCreatedMessage{
+		message("msg-1", "batch-1", 2),
+		message("msg-2", "batch-2", 2),
+		message("msg-3", "batch-3", 2),
+		message("msg-1", "batch-1", 2),
+	})
+	if len(errs) != 4 || errs[0] != nil || errs[1] != nil || !mq.IsNonRetryableError(errs[2]) || errs[3] != nil {
+		t.Fatalf("CreateSpikeOrdersFromMessages() errs = %v", errs)
+	}
+
+	// 已售数量合并为一次更新，订单一次批量插入，库存逐单消费以关联订单号
+	if calls := f.events.UpdateSoldCountCalls(); len(calls) != 1 || calls[0].Count != 9 {
+		t.Errorf("UpdateSoldCount calls = %+v, want one update to 9", calls)
+	}
+	if calls := f.orders.CreateBatchCalls(); len(calls) != 1 || len(calls[0].Orders) != 2 {
+		t.Fatalf("CreateBatch calls = %+v, want one batch of 2", calls)
+	}
+	if calls := f.inventory.ConsumeStockCalls(); len(calls) != 2 {
+		t.Errorf("ConsumeStock calls = %d, want 2", len(calls))
+	}
+	if calls := f.orderEvents.CreateCalls(); len(calls) != 2 || calls[1].Event.TraceID != "trace-msg-2" {
+		t.Errorf("order events = %+v", calls)
+	}
+	if f.cache.restored != 2 {
+		t.Errorf("redis restored = %d, want 2 for the rejected message", f.cache.restored)
+	}
+
+	// 落库失败时释放幂等键，逐条重试不会被当作重复消息跳过
+	f.orders.CreateBatchFunc = func(ctx context.Context, orders []*domain.SpikeOrder) error {
+		return errors.New("deadlock")
+	}
+	event.SpikeStock = 20
+	errs = f.service.CreateSpikeOrdersFromMessages(ctx, []*mq.SpikeOrderCreatedMessage{message("msg-4", "batch-4", 1)})
+	if errs[0] == nil || mq.IsNonRetryableError(errs[0]) {
+		t.Fatalf("CreateSpikeOrdersFromMessages(failed) errs = %v, want retryable", errs)
+	}
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-4", "trace-msg-4", message("msg-4", "batch-4", 1).Data); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage(retry) error = %v", err)
+	}
+	if n := len(f.orders.CreateCalls()); n != 1 {
+		t.Errorf("orders created on retry = %d, want 1", n)
+	}
+}
+
+func TestSpikeMessageService_ExpireSpikeOrderFromMessage(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{ProductID: 3, SpikeStock: 10, SoldCount: 4, Status: domain.SpikeEventStatusActive}
+	_ = f.events.Create(context.Background(), event)
+
+	expiredAt := time.Now()
+	pending := &domain.SpikeOrder{SpikeEventID: event.ID, UserID: 1, Quantity: 2, Status: domain.SpikeOrderStatusPending, ExpireAt: &expiredAt}
+	paid := &domain.SpikeOrder{SpikeEventID: event.ID, UserID: 2, Quantity: 1, Status: domain.SpikeOrderStatusPaid, ExpireAt: &expiredAt}
+	_ = f.orders.Create(context.Background(), pending)
+	_ = f.orders.Create(context.Background(), paid)
+
+	// 已支付订单的过期消息被忽略
+	if err := f.service.ExpireSpikeOrderFromMessage(ctx, "msg-paid", "", &mq.SpikeOrderExpiredData{
+		SpikeOrderID: paid.ID, SpikeEventID: event.ID, UserID: 2, ProductID: 3, Quantity: 1,
+		ExpiredAt: expiredAt, IdempotencyKey: "expire-paid",
+	}); err != nil {
+		t.Fatalf("ExpireSpikeOrderFromMessage(paid) error = %v", err)
+	}
+	if len(f.inventory.AdjustStockCalls()) != 0 {
+		t.Fatal("paid order expiry should not restore stock")
+	}
+
+	if err := f.service.ExpireSpikeOrderFromMessage(ctx, "msg-pending", "", &mq.SpikeOrderExpiredData{
+		SpikeOrderID: pending.ID, SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 2,
+		ExpiredAt: expiredAt, IdempotencyKey: "expire-pending",
+	}); err != nil {
+		t.Fatalf("ExpireSpikeOrderFromMessage(pending) error = %v", err)
+	}
+	if got, _ := f.events.GetByID(context.Background(), event.ID); got.SoldCount != 2 {
+		t.Errorf("sold count = %d, want 2", got.SoldCount)
+	}
+	if calls := f.inventory.AdjustStockCalls(); len(calls) != 1 || calls[0].Reason != "order_expired" {
+		t.Errorf("AdjustStock calls = %+v", calls)
+	}
+	if f.cache.restored != 2 {
+		t.Errorf("redis restored = %d, want 2", f.cache.restored)
+	}
+	if calls := f.orderEvents.CreateCalls(); len(calls) != 1 || calls[0].Event.EventType != domain.OrderEventExpired {
+		t.Errorf("order events = %+v", calls)
+	}
+	// 延时消息先于过期任务到达时由消费者标记订单已过期
+	if got, _ := f.orders.GetByID(ctx, pending.ID); got.Status != domain.SpikeOrderStatusExpired {
+		t.Errorf("order status = %s, want expired", got.Status)
+	}
+}
+
+func TestSpikeMessageService_AbandonedCheckoutTracking(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{
+		ProductID: 3, SpikeStock: 10, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(ctx, event)
+	abandoned := &mocks.AbandonedCheckoutRepositoryMock{
+		CreateFunc: func(checkout *domain.AbandonedCheckout) error {
+			checkout.ID = 11
+			return nil
+		},
+		MarkRecoveredFunc: func(userID int64, campaignID string, recoveredOrderID int64, recoveredAt time.Time) (bool, error) {
+			return true, nil
+		},
+	}
+	publisher := &mocks.SpikePublisherMock{
+		PublishSpikeOrderAbandonedFunc: func(ctx context.Context, data *mq.SpikeOrderAbandonedData, traceID string) error { return nil },
+	}
+	f.service.SetAbandonedCheckoutTracking(abandoned, publisher)
+
+	// 订单过期时记录弃单并发布营销事件
+	expiredAt := time.Now()
+	pending := &domain.SpikeOrder{SpikeEventID: event.ID, UserID: 1, Quantity: 1, TotalAmount: 99, Status: domain.SpikeOrderStatusPending, ExpireAt: &expiredAt}
+	_ = f.orders.Create(ctx, pending)
+	if err := f.service.ExpireSpikeOrderFromMessage(ctx, "msg-expire", "trace-1", &mq.SpikeOrderExpiredData{
+		SpikeOrderID: pending.ID, SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 1,
+		ExpiredAt: expiredAt, IdempotencyKey: "expire-1",
+	}); err != nil {
+		t.Fatalf("ExpireSpikeOrderFromMessage() error = %v", err)
+	}
+	if calls := abandoned.CreateCalls(); len(calls) != 1 || calls[0].Checkout.SpikeOrderID != pending.ID || calls[0].Checkout.TotalAmount != 99 {
+		t.Fatalf("abandoned checkouts = %+v", calls)
+	}
+	if calls := publisher.PublishSpikeOrderAbandonedCalls(); len(calls) != 1 || calls[0].Data.AbandonedCheckoutID != 11 || calls[0].TraceID != "trace-1" {
+		t.Errorf("abandoned events = %+v", calls)
+	}
+
+	// 通过召回活动下单时将新订单归因到该活动的弃单
+	data := &mq.SpikeOrderCreatedData{
+		SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 1,
+		IdempotencyKey: "idem-recovered", ExpireAt: time.Now().Add(15 * time.Minute), RecoveryCampaignID: "spring",
+	}
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-recovered", "", data); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage() error = %v", err)
+	}
+	created := f.orders.CreateCalls()[len(f.orders.CreateCalls())-1].Order
+	calls := abandoned.MarkRecoveredCalls()
+	if len(calls) != 1 || calls[0].UserID != 1 || calls[0].CampaignID != "spring" || calls[0].RecoveredOrderID != created.ID {
+		t.Errorf("MarkRecovered calls = %+v, want attribution of order %d to campaign spring", calls, created.ID)
+	}
+
+	// 不是召回带来的订单不做归因；归因失败不影响订单创建
+	data.IdempotencyKey, data.RecoveryCampaignID = "idem-organic", ""
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-organic", "", data); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage(organic) error = %v", err)
+	}
+	abandoned.MarkRecoveredFunc = func(userID int64, campaignID string, recoveredOrderID int64, recoveredAt time.Time) (bool, error) {
+		return false, errors.New("connection refused")
+	}
+	data.IdempotencyKey, data.RecoveryCampaignID, data.UserID = "idem-failed", "spring", 2
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-failed", "", data); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage(attribution failure) error = %v", err)
+	}
+	if n := len(abandoned.MarkRecoveredCalls()); n != 2 {
+		t.Errorf("MarkRecovered calls = %d, want 2", n)
+	}
+}
+
+// delayedExpiryRecorder 记录发布的订单过期延时消息
+type delayedExpiryRecorder []*mq.SpikeOrderExpiredData
+
+func (r *delayedExpiryRecorder) PublishSpikeOrderExpiredAt(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error {
+	*r = append(*r, data)
+	return nil
+}
+
+func TestSpikeMessageService_MarkSpikeOrderPaidFromMessage(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	sandbox := payment.NewSandbox("secret")
+	f.service.SetPaymentRefunder(sandbox)
+
+	pending := &domain.SpikeOrder{UserID: 1, Status: domain.SpikeOrderStatusPending, TotalAmount: 10}
+	cancelled := &domain.SpikeOrder{UserID: 1, Status: domain.SpikeOrderStatusCancelled, TotalAmount: 10}
+	_ = f.orders.Create(ctx, pending)
+	_ = f.orders.Create(ctx, cancelled)
+	paidMessage := func(order *domain.SpikeOrder) *mq.SpikeOrderPaidData {
+		intent, err := sandbox.Charge(ctx, &payment.IntentRequest{Amount: order.TotalAmount, UserID: order.UserID}, "tok")
+		if err != nil {
+			t.Fatalf("Charge() error = %v", err)
+		}
+		return &mq.SpikeOrderPaidData{SpikeOrderID: order.ID, UserID: order.UserID, PaymentMethod: sandbox.Name(),
+			PaidAmount: intent.Amount, PaidAt: time.Now(), TransactionID: intent.ID}
+	}
+
+	if err := f.service.MarkSpikeOrderPaidFromMessage(ctx, "trace-1", paidMessage(pending)); err != nil {
+		t.Fatalf("MarkSpikeOrderPaidFromMessage(pending) error = %v", err)
+	}
+	if got, _ := f.orders.GetByID(ctx, pending.ID); got.Status != domain.SpikeOrderStatusPaid {
+		t.Errorf("pending order status = %s, want paid", got.Status)
+	}
+
+	// 订单取消后才到达的支付退款并记录退款事件，订单不变为已支付；重复消息不重复退款
+	late := paidMessage(cancelled)
+	for range 2 {
+		if err := f.service.MarkSpikeOrderPaidFromMessage(ctx, "trace-2", late); err != nil {
+			t.Fatalf("MarkSpikeOrderPaidFromMessage(cancelled) error = %v", err)
+		}
+	}
+	if got, _ := f.orders.GetByID(ctx, cancelled.ID); got.Status != domain.SpikeOrderStatusCancelled {
+		t.Errorf("cancelled order status = %s, want cancelled", got.Status)
+	}
+	if n := len(f.orders.UpdatePaymentInfoCalls()); n != 1 {
+		t.Errorf("UpdatePaymentInfo() calls = %d, want 1", n)
+	}
+	var types []domain.OrderEventType
+	for _, call := range f.orderEvents.CreateCalls() {
+		types = append(types, call.Event.EventType)
+	}
+	if want := []domain.OrderEventType{domain.OrderEventPaid, domain.OrderEventRefunded}; !slices.Equal(types, want) {
+		t.Errorf("order events = %v, want %v", types, want)
+	}
+	if event := f.orderEvents.CreateCalls()[1].Event; event.SpikeOrderID != cancelled.ID || event.Actor != domain.ActorSystem {
+		t.Errorf("refund event = %+v", event)
+	}
+	if _, err := sandbox.Refund(ctx, late.TransactionID, 1); !errors.Is(err, payment.ErrInvalidState) {
+		t.Errorf("Refund() after late payment error = %v, want fully refunded", err)
+	}
+}
+
+func TestSpikeMessageService_ScheduleExpiry(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	var scheduled delayedExpiryRecorder
+	f.service.SetExpiryScheduler(&scheduled)
+	event := &domain.SpikeEvent{
+		ProductID: 3, SpikeStock: 10, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(ctx, event)
+
+	expireAt := time.Now().Add(15 * time.Minute)
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-1", "trace-1", &mq.SpikeOrderCreatedData{
+		SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 2, IdempotencyKey: "idem-1", ExpireAt: expireAt,
+	}); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage() error = %v", err)
+	}
+	order := f.orders.CreateCalls()[0].Order
+	if len(scheduled) != 1 || scheduled[0].SpikeOrderID != order.ID || !scheduled[0].ExpiredAt.Equal(expireAt) ||
+		scheduled[0].ProductID != 3 || scheduled[0].IdempotencyKey != keys.Idempotency("expire", order.ID) {
+		t.Fatalf("scheduled expiry = %+v, want order %d expiring at %s", scheduled, order.ID, expireAt)
+	}
+
+	// 消息提前到达时订单尚未到期，不恢复库存
+	if err := f.service.ExpireSpikeOrderFromMessage(ctx, "msg-2", "", scheduled[0]); err != nil {
+		t.Fatalf("ExpireSpikeOrderFromMessage() error = %v", err)
+	}
+	if got, _ := f.orders.GetByID(ctx, order.ID); got.Status != domain.SpikeOrderStatusPending || len(f.inventory.AdjustStockCalls()) != 0 {
+		t.Errorf("order status = %s, stock restores = %d, want pending order untouched", got.Status, len(f.inventory.AdjustStockCalls()))
+	}
+}
+
+func TestSpikeMessageService_RestoreStockFromMessage(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	event := &domain.SpikeEvent{ProductID: 3, SpikeStock: 10, SoldCount: 5}
+	_ = f.events.Create(context.Background(), event)
+
+	data := &mq.StockRestoreData{
+		SpikeEventID: event.ID, ProductID: 3, UserID: 1, Quantity: 3,
+		Reason: "user_cancelled", SourceOrderID: 9, IdempotencyKey: "cancel-9",
+	}
+	for range 2 {
+		if err := f.service.RestoreStockFromMessage(ctx, "msg-9", data); err != nil {
+			t.Fatalf("RestoreStockFromMessage() error = %v", err)
+		}
+	}
+	if got, _ := f.events.GetByID(context.Background(), event.ID); got.SoldCount != 2 {
+		t.Errorf("sold count = %d, want 2 after a single restore", got.SoldCount)
+	}
+
+	// 数据库恢复失败时返回可重试错误
+	f.inventory.AdjustStockFunc = func(ctx context.Context, productID int64, quantity int, reason string) error {
+		return errors.New("db down")
+	}
+	data.IdempotencyKey = "cancel-10"
+	err := f.service.RestoreStockFromMessage(ctx, "msg-10", data)
+	if err == nil || mq.IsNonRetryableError(err) {
+		t.Errorf("RestoreStockFromMessage() error = %v, want retryable error", err)
+	}
+}
+
+func TestSpikeMessageService_VariantStock(t *testing.T) {
+	ctx := context.Background()
+	f := newMessageServiceFixture()
+	variants := newFakeVariantRepo()
+	variants.variants[4] = &domain.ProductVariant{ID: 4, ProductID: 3, Stock: 5, Active: true}
+	f.service.SetVariantStock(variants)
+
+	variantID := int64(4)
+	event := &domain.SpikeEvent{
+		ProductID: 3, VariantID: &variantID, SpikeStock: 10, Status: domain.SpikeEventStatusActive,
+		StartAt: time.Now().Add(-time.Minute), EndAt: time.Now().Add(time.Hour),
+	}
+	_ = f.events.Create(ctx, event)
+
+	// 指定了规格的活动落库时只扣减规格库存
+	created := &mq.SpikeOrderCreatedData{
+		SpikeEventID: event.ID, UserID: 1, ProductID: 3, Quantity: 2,
+		IdempotencyKey: "idem-variant", ExpireAt: time.Now().Add(15 * time.Minute),
+	}
+	if err := f.service.CreateSpikeOrderFromMessage(ctx, "msg-1", "trace-1", created); err != nil {
+		t.Fatalf("CreateSpikeOrderFromMessage() error = %v", err)
+	}
+	if v := variants.variants[4]; v.Stock != 3 || v.SoldStock != 2 || len(f.inventory.ConsumeStockCalls()) != 0 {
+		t.Errorf("variant stock = %d sold %d, product consumes = %d; want 3, 2, 0",
+			v.Stock, v.SoldStock, len(f.inventory.ConsumeStockCalls()))
+	}
+
+	// 取消后恢复规格库存
+	restore := &mq.StockRestoreData{
+		SpikeEventID: event.ID, ProductID: 3, UserID: 1, Quantity: 2,
+		Reason: "user_cancelled", IdempotencyKey: "cancel-variant",
+	}
+	if err := f.service.RestoreStockFromMessage(ctx, "msg-2", restore); err != nil {
+		t.Fatalf("RestoreStockFromMessage() error = %v", err)
+	}
+	if v := variants.variants[4]; v.Stock != 5 || len(f.inventory.AdjustStockCalls()) != 0 {
+		t.Errorf("variant stock = %d, product adjusts = %d; want 5, 0", v.Stock, len(f.inventory.AdjustStockCalls()))
+	}
+}
@@ -2,34 +2,82 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"fmt"
+	"sort"
 	"sync"
 	"time"
 
-	"github.com/redis/go-redis/v9"
-
+	"github.com/MorseWayne/spike_shop/internal/cache"
 	"github.com/MorseWayne/spike_shop/internal/domain"
 	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
 	"github.com/MorseWayne/spike_shop/internal/mq"
 )
 
-// MockSpikeEventRepository 秒杀活动仓储模拟
+// 以下内存实现基于 moq 生成的模拟实现（internal/mocks 与 spike_stock_cache_moq_test.go），
+// 只为测试用到的方法接线；调用未接线的方法会 panic，接口新增方法后重新生成即可编译通过。
+
+// MockSpikeEventRepository 秒杀活动仓储内存实现
 type MockSpikeEventRepository struct {
+	*mocks.SpikeEventRepositoryMock
+
+	mu     sync.RWMutex
 	events map[int64]*domain.SpikeEvent
 	nextID int64
-	mu     sync.RWMutex
 }
 
 func NewMockSpikeEventRepository() *MockSpikeEventRepository {
-	return &MockSpikeEventRepository{
-		events: make(map[int64]*domain.SpikeEvent),
-		nextID: 1,
+	m := &MockSpikeEventRepository{
+		SpikeEventRepositoryMock: &mocks.SpikeEventRepositoryMock{},
+		events:                   make(map[int64]*domain.SpikeEvent),
+		nextID:                   1,
+	}
+	m.CreateFunc = m.create
+	m.CreateBatchFunc = func(ctx context.Context, events []*domain.SpikeEvent) error {
+		for _, event := range events {
+			if err := m.create(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	m.GetByIDFunc = m.getByID
+	m.GetByProductIDFunc = func(ctx context.Context, productID int64) ([]*domain.SpikeEvent, error) {
+		return m.filter(func(e *domain.SpikeEvent) bool { return e.ProductID == productID }), nil
+	}
+	m.ListFunc = m.list
+	m.GetActiveEventsFunc = func(ctx context.Context) ([]*domain.SpikeEvent, error) {
+		return m.filter((*domain.SpikeEvent).IsActive), nil
 	}
+	m.GetPreviewEventsFunc = func(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error) {
+		return m.filter(func(e *domain.SpikeEvent) bool {
+			return e.PreviewStartAt != nil && !e.PreviewStartAt.After(now) && e.StartAt.After(now) &&
+				(e.Status == domain.SpikeEventStatusPending || e.Status == domain.SpikeEventStatusActive)
+		}), nil
+	}
+	m.GetUpcomingEventsFunc = func(ctx context.Context, now, until time.Time) ([]*domain.SpikeEvent, error) {
+		return m.filter(func(e *domain.SpikeEvent) bool {
+			return e.StartAt.After(now) && !e.StartAt.After(until) &&
+				(e.Status == domain.SpikeEventStatusPending || e.Status == domain.SpikeEventStatusActive)
+		}), nil
+	}
+	m.GetEventsDueForTransitionFunc = func(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error) {
+		return m.filter(func(e *domain.SpikeEvent) bool {
+			_, due := e.ScheduledStatus(now)
+			return due
+		}), nil
+	}
+	m.TransitionStatusFunc = m.transitionStatus
+	m.UpdateSoldCountFunc = m.updateSoldCount
+	m.AddSoldCountFunc = m.addSoldCount
+	m.IncreaseStockFunc = m.increaseStock
+	m.CountFunc = func(ctx context.Context) (int64, error) {
+		return int64(len(m.filter(func(*domain.SpikeEvent) bool { return true }))), nil
+	}
+	return m
 }
 
-func (m *MockSpikeEventRepository) Create(event *domain.SpikeEvent) error {
+func (m *MockSpikeEventRepository) create(ctx context.Context, event *domain.SpikeEvent) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -37,93 +85,209 @@ func (m *MockSpikeEventRepository) Create(event *domain.SpikeEvent) error {
 	m.nextID++
 	event.CreatedAt = time.Now()
 	event.UpdatedAt = time.Now()
-
 	m.events[event.ID] = event
 	return nil
 }
 
-func (m *MockSpikeEventRepository) GetByID(id int64) (*domain.SpikeEvent, error) {
+func (m *MockSpikeEventRepository) getByID(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	event, exists := m.events[id]
 	if !exists {
-		return nil, nil
+		return nil, domain.ErrSpikeEventNotFound
 	}
 	return event, nil
 }
 
-func (m *MockSpikeEventRepository) GetByIDWithTx(tx *sql.Tx, id int64) (*domain.SpikeEvent, error) {
-	return m.GetByID(id)
+func (m *MockSpikeEventRepository) updateSoldCount(ctx context.Context, id int64, soldCount int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, exists := m.events[id]
+	if !exists {
+		return domain.ErrSpikeEventNotFound
+	}
+	event.SoldCount = soldCount
+	event.UpdatedAt = time.Now()
+	return nil
 }
 
-func (m *MockSpikeEventRepository) UpdateSoldCount(id int64, soldCount int64) error {
+func (m *MockSpikeEventRepository) addSoldCount(ctx context.Context, id int64, delta int64) (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	event, exists := m.events[id]
 	if !exists {
-		return errors.New("event not found")
+		return false, nil
 	}
+	if sold := event.SoldCount + delta; sold < 0 || sold > event.SpikeStock {
+		return false, nil
+	}
+	event.SoldCount += delta
+	event.UpdatedAt = time.Now()
+	return true, nil
+}
 
-	event.SoldCount = soldCount
+func (m *MockSpikeEventRepository) increaseStock(ctx context.Context, id int64, delta int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, exists := m.events[id]
+	if !exists {
+		return domain.ErrSpikeEventNotFound
+	}
+	if event.Status == domain.SpikeEventStatusEnded || event.Status == domain.SpikeEventStatusCancelled {
+		return domain.ErrSpikeEventNotRestockable
+	}
+	event.SpikeStock += delta
 	event.UpdatedAt = time.Now()
 	return nil
 }
 
-// Count 实现Repository接口要求的方法
-func (m *MockSpikeEventRepository) Count() (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return int64(len(m.events)), nil
+func (m *MockSpikeEventRepository) transitionStatus(ctx context.Context, id int64, from, to domain.SpikeEventStatus) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, exists := m.events[id]
+	if !exists || event.Status != from {
+		return false, nil
+	}
+	event.Status = to
+	event.UpdatedAt = time.Now()
+	return true, nil
 }
 
-func (m *MockSpikeEventRepository) List(req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error) {
+// filter 按ID顺序返回满足条件的活动
+func (m *MockSpikeEventRepository) filter(match func(*domain.SpikeEvent) bool) []*domain.SpikeEvent {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var events []*domain.SpikeEvent
 	for _, event := range m.events {
-		// 简化筛选逻辑
-		if req.Active != nil && *req.Active {
-			if event.IsActive() {
-				events = append(events, event)
-			}
-		} else {
+		if match(event) {
 			events = append(events, event)
 		}
 	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
 
-	total := int64(len(events))
-
-	// 简化分页逻辑
-	start := (req.Page - 1) * req.PageSize
-	end := start + req.PageSize
-	if start >= len(events) {
-		return []*domain.SpikeEvent{}, total, nil
+func (m *MockSpikeEventRepository) list(ctx context.Context, req *domain.SpikeEventListRequest) ([]*domain.SpikeEvent, int64, error) {
+	events := m.filter(func(e *domain.SpikeEvent) bool {
+		if req.Status != nil && e.Status != *req.Status {
+			return false
+		}
+		if req.Unpublished && !e.IsUnpublished() {
+			return false
+		}
+		if !keysetAfter(e.Cursor(), req.After, req.Descending()) {
+			return false
+		}
+		return req.Active == nil || !*req.Active || e.IsActive()
+	})
+	if req.KeysetOrdered() {
+		sortByCursor(events, (*domain.SpikeEvent).Cursor, req.Descending())
 	}
-	if end > len(events) {
-		end = len(events)
+	if req.After != nil {
+		return events[:min(req.PageSize+1, len(events))], domain.TotalNotCounted, nil
 	}
-
-	return events[start:end], total, nil
+	page, total := paginate(events, req.Page, req.PageSize)
+	return page, total, nil
 }
 
-// MockSpikeOrderRepository 秒杀订单仓储模拟
+// MockSpikeOrderRepository 秒杀订单仓储内存实现
 type MockSpikeOrderRepository struct {
-	orders map[int64]*domain.SpikeOrder
-	nextID int64
-	mu     sync.RWMutex
+	*mocks.SpikeOrderRepositoryMock
+
+	mu       sync.RWMutex
+	orders   map[int64]*domain.SpikeOrder
+	extended map[int64]bool
+	nextID   int64
 }
 
 func NewMockSpikeOrderRepository() *MockSpikeOrderRepository {
-	return &MockSpikeOrderRepository{
-		orders: make(map[int64]*domain.SpikeOrder),
-		nextID: 1,
+	m := &MockSpikeOrderRepository{
+		SpikeOrderRepositoryMock: &mocks.SpikeOrderRepositoryMock{},
+		orders:                   make(map[int64]*domain.SpikeOrder),
+		extended:                 make(map[int64]bool),
+		nextID:                   1,
+	}
+	m.CreateFunc = m.create
+	m.CreateBatchFunc = func(ctx context.Context, orders []*domain.SpikeOrder) error {
+		for _, order := range orders {
+			_ = m.create(ctx, order)
+		}
+		return nil
+	}
+	m.GetByIDFunc = m.getByID
+	// GetDetailByID 只返回订单本身，活动与用户由调用方补全
+	m.GetDetailByIDFunc = func(ctx context.Context, id int64) (*domain.SpikeOrderWithDetails, error) {
+		order, err := m.getByID(context.Background(), id)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.SpikeOrderWithDetails{SpikeOrder: order}, nil
 	}
-}
-
-func (m *MockSpikeOrderRepository) Create(order *domain.SpikeOrder) error {
+	m.GetByUserAndEventFunc = func(ctx context.Context, userID, spikeEventID int64) (*domain.SpikeOrder, error) {
+		orders := m.filter(func(o *domain.SpikeOrder) bool { return o.UserID == userID && o.SpikeEventID == spikeEventID })
+		if len(orders) == 0 {
+			return nil, nil
+		}
+		return orders[0], nil
+	}
+	m.CountByUserAndEventFunc = func(ctx context.Context, userID, spikeEventID int64) (int64, error) {
+		return int64(len(m.filter(func(o *domain.SpikeOrder) bool { return o.UserID == userID && o.SpikeEventID == spikeEventID }))), nil
+	}
+	m.GetByIdempotencyKeyFunc = func(ctx context.Context, key string) (*domain.SpikeOrder, error) {
+		return m.first(func(o *domain.SpikeOrder) bool { return o.IdempotencyKey == key }), nil
+	}
+	m.GetByPaymentRefFunc = func(ctx context.Context, paymentRef string) (*domain.SpikeOrder, error) {
+		return m.first(func(o *domain.SpikeOrder) bool { return o.PaymentRef == paymentRef }), nil
+	}
+	m.GetByOrderNoFunc = func(ctx context.Context, orderNo string) (*domain.SpikeOrder, error) {
+		return m.first(func(o *domain.SpikeOrder) bool { return o.OrderNo == orderNo }), nil
+	}
+	m.GetBySpikeEventIDFunc = func(ctx context.Context, spikeEventID int64) ([]*domain.SpikeOrder, error) {
+		return m.filter(func(o *domain.SpikeOrder) bool { return o.SpikeEventID == spikeEventID }), nil
+	}
+	m.UpdateStatusFunc = m.updateStatus
+	m.UpdatePaymentInfoFunc = func(ctx context.Context, id int64, paidAt time.Time, paymentRef string) error {
+		if err := m.updateStatus(ctx, id, domain.SpikeOrderStatusPaid); err != nil {
+			return err
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.orders[id].PaidAt, m.orders[id].PaymentRef = &paidAt, paymentRef
+		return nil
+	}
+	m.ListFunc = m.list
+	m.CountFunc = func(ctx context.Context) (int64, error) {
+		return int64(len(m.filter(func(*domain.SpikeOrder) bool { return true }))), nil
+	}
+	m.CountByStatusFunc = func(ctx context.Context, status domain.SpikeOrderStatus) (int64, error) {
+		return int64(len(m.filter(func(o *domain.SpikeOrder) bool { return o.Status == status }))), nil
+	}
+	m.ExtendExpireAtFunc = m.extendExpireAt
+	m.GetPendingOrdersExpiringBetweenFunc = func(ctx context.Context, from, to time.Time) ([]*domain.SpikeOrder, error) {
+		return m.filter(func(o *domain.SpikeOrder) bool {
+			return o.Status == domain.SpikeOrderStatusPending && o.ExpireAt != nil &&
+				!o.ExpireAt.Before(from) && o.ExpireAt.Before(to)
+		}), nil
+	}
+	m.GetExpiredOrdersFunc = func(ctx context.Context, before time.Time, limit int) ([]*domain.SpikeOrder, error) {
+		orders := m.filter(func(o *domain.SpikeOrder) bool {
+			return o.Status == domain.SpikeOrderStatusPending && o.ExpireAt != nil && o.ExpireAt.Before(before)
+		})
+		return orders[:min(limit, len(orders))], nil
+	}
+	m.MarkExpiredFunc = m.markExpired
+	m.SumQuantityByEventFunc = m.sumQuantityByEvent
+	m.GetOrderWindowByEventFunc = m.getOrderWindowByEvent
+	return m
+}
+
+func (m *MockSpikeOrderRepository) create(ctx context.Context, order *domain.SpikeOrder) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -131,555 +295,425 @@ func (m *MockSpikeOrderRepository) Create(order *domain.SpikeOrder) error {
 	m.nextID++
 	order.CreatedAt = time.Now()
 	order.UpdatedAt = time.Now()
-
 	m.orders[order.ID] = order
 	return nil
 }
 
-// Count 实现Repository接口要求的方法
-func (m *MockSpikeOrderRepository) Count() (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return int64(len(m.orders)), nil
-}
-
-func (m *MockSpikeOrderRepository) GetByID(id int64) (*domain.SpikeOrder, error) {
+func (m *MockSpikeOrderRepository) getByID(ctx context.Context, id int64) (*domain.SpikeOrder, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	order, exists := m.orders[id]
 	if !exists {
-		return nil, nil
+		return nil, domain.ErrSpikeOrderNotFound
 	}
 	return order, nil
 }
 
-func (m *MockSpikeOrderRepository) GetByUserIDAndEventID(userID, eventID int64) (*domain.SpikeOrder, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	for _, order := range m.orders {
-		if order.UserID == userID && order.SpikeEventID == eventID {
-			return order, nil
-		}
-	}
-	return nil, nil
-}
-
-func (m *MockSpikeOrderRepository) GetByUserIDAndEventIDWithTx(tx *sql.Tx, userID, eventID int64) (*domain.SpikeOrder, error) {
-	return m.GetByUserIDAndEventID(userID, eventID)
-}
-
-func (m *MockSpikeOrderRepository) UpdateStatus(id int64, status domain.SpikeOrderStatus) error {
+func (m *MockSpikeOrderRepository) updateStatus(ctx context.Context, id int64, status domain.SpikeOrderStatus) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	order, exists := m.orders[id]
 	if !exists {
-		return errors.New("order not found")
+		return domain.ErrSpikeOrderNotFound
 	}
-
 	order.Status = status
 	order.UpdatedAt = time.Now()
 	return nil
 }
 
-func (m *MockSpikeOrderRepository) List(req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrder, int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *MockSpikeOrderRepository) markExpired(ctx context.Context, id int64, before time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	var orders []*domain.SpikeOrder
-	for _, order := range m.orders {
-		// 简化筛选逻辑
-		if req.UserID != nil && order.UserID != *req.UserID {
-			continue
-		}
-		if req.Status != nil && order.Status != *req.Status {
-			continue
-		}
-		orders = append(orders, order)
+	order, exists := m.orders[id]
+	if !exists || order.Status != domain.SpikeOrderStatusPending || order.ExpireAt == nil || !order.ExpireAt.Before(before) {
+		return false, nil
 	}
+	order.Status = domain.SpikeOrderStatusExpired
+	order.UpdatedAt = time.Now()
+	return true, nil
+}
 
-	total := int64(len(orders))
-
-	// 简化分页逻辑
-	start := (req.Page - 1) * req.PageSize
-	end := start + req.PageSize
-	if start >= len(orders) {
-		return []*domain.SpikeOrder{}, total, nil
-	}
-	if end > len(orders) {
-		end = len(orders)
+// first 返回ID最小的满足条件的订单，不存在时返回 nil
+func (m *MockSpikeOrderRepository) first(match func(*domain.SpikeOrder) bool) *domain.SpikeOrder {
+	if orders := m.filter(match); len(orders) > 0 {
+		return orders[0]
 	}
-
-	return orders[start:end], total, nil
+	return nil
 }
 
-func (m *MockSpikeOrderRepository) CountByStatus(status domain.SpikeOrderStatus) (int64, error) {
+// filter 按ID顺序返回满足条件的订单
+func (m *MockSpikeOrderRepository) filter(match func(*domain.SpikeOrder) bool) []*domain.SpikeOrder {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	count := int64(0)
+	var orders []*domain.SpikeOrder
 	for _, order := range m.orders {
-		if order.Status == status {
-			count++
+		if match(order) {
+			orders = append(orders, order)
 		}
 	}
-	return count, nil
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
 }
 
-// StockDecrementResult 模拟库存扣减结果
-type StockDecrementResult struct {
-	Success        bool   `json:"success"`
-	Message        string `json:"message"`
-	RemainingStock int64  `json:"remaining_stock"`
+func (m *MockSpikeOrderRepository) list(ctx context.Context, req *domain.SpikeOrderListRequest) ([]*domain.SpikeOrder, int64, error) {
+	orders := m.filter(func(o *domain.SpikeOrder) bool {
+		return (req.UserID == nil || o.UserID == *req.UserID) && (req.Status == nil || o.Status == *req.Status) &&
+			(req.SpikeEventID == nil || o.SpikeEventID == *req.SpikeEventID) &&
+			(req.From == nil || !o.CreatedAt.Before(*req.From)) && (req.To == nil || o.CreatedAt.Before(*req.To)) &&
+			keysetAfter(o.Cursor(), req.After, req.Descending())
+	})
+	if req.KeysetOrdered() {
+		sortByCursor(orders, (*domain.SpikeOrder).Cursor, req.Descending())
+	}
+	if req.After != nil {
+		return orders[:min(req.PageSize+1, len(orders))], domain.TotalNotCounted, nil
+	}
+	page, total := paginate(orders, req.Page, req.PageSize)
+	return page, total, nil
 }
 
-// StockRestoreResult 模拟库存恢复结果
-type StockRestoreResult struct {
-	Success        bool   `json:"success"`
-	Message        string `json:"message"`
-	RemainingStock int64  `json:"remaining_stock"`
+func (m *MockSpikeOrderRepository) extendExpireAt(ctx context.Context, id int64, extension time.Duration, event *domain.OrderEvent) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, exists := m.orders[id]
+	if !exists {
+		return time.Time{}, domain.ErrSpikeOrderNotFound
+	}
+	if !order.CanPay() || order.ExpireAt == nil {
+		return time.Time{}, domain.ErrSpikeOrderNotExtendable
+	}
+	if m.extended[id] {
+		return time.Time{}, domain.ErrSpikeOrderAlreadyExtended
+	}
+	m.extended[id] = true
+	expireAt := order.ExpireAt.Add(extension)
+	order.ExpireAt = &expireAt
+	return expireAt, nil
 }
 
-// StockInfo 模拟库存信息
-type StockInfo struct {
-	Stock   int64 `json:"stock"`
-	SoldOut bool  `json:"sold_out"`
-	Exists  bool  `json:"exists"`
+func (m *MockSpikeOrderRepository) sumQuantityByEvent(ctx context.Context, spikeEventID int64, statuses ...domain.SpikeOrderStatus) (int64, error) {
+	total := int64(0)
+	for _, order := range m.filter(func(o *domain.SpikeOrder) bool { return o.SpikeEventID == spikeEventID }) {
+		matched := len(statuses) == 0
+		for _, status := range statuses {
+			if order.Status == status {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			total += order.Quantity
+		}
+	}
+	return total, nil
 }
 
-// Helper函数
-func GetSpikeStockKey(eventID int64) string {
-	return fmt.Sprintf("spike:stock:%d", eventID)
+func (m *MockSpikeOrderRepository) getOrderWindowByEvent(ctx context.Context, spikeEventID int64) (*domain.SpikeOrderWindow, error) {
+	window := &domain.SpikeOrderWindow{}
+	for _, order := range m.filter(func(o *domain.SpikeOrder) bool { return o.SpikeEventID == spikeEventID }) {
+		if window.Orders == 0 || order.CreatedAt.Before(window.FirstAt) {
+			window.FirstAt = order.CreatedAt
+		}
+		if order.CreatedAt.After(window.LastAt) {
+			window.LastAt = order.CreatedAt
+		}
+		window.Orders++
+	}
+	return window, nil
 }
 
-func GetSpikeSoldOutKey(eventID int64) string {
-	return fmt.Sprintf("spike:sold_out:%d", eventID)
+// keysetAfter 判断 c 是否排在游标 after 之后，与 repo 的键集分页条件一致；after 为空时恒为 true
+func keysetAfter(c domain.ListCursor, after *domain.ListCursor, descending bool) bool {
+	if after == nil {
+		return true
+	}
+	if !c.CreatedAt.Equal(after.CreatedAt) {
+		return c.CreatedAt.Before(after.CreatedAt) == descending
+	}
+	return (c.ID < after.ID) == descending && c.ID != after.ID
 }
 
-func GetSpikeUserKey(userID, eventID int64) string {
-	return fmt.Sprintf("spike:user:%d:%d", userID, eventID)
+// sortByCursor 按 (created_at, id) 排序，与 repo 的键集分页顺序一致
+func sortByCursor[T any](items []T, key func(T) domain.ListCursor, descending bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := key(items[i]), key(items[j])
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt) != descending
+		}
+		return (a.ID < b.ID) != descending
+	})
 }
 
-func GetSpikeEventKey(eventID int64) string {
-	return fmt.Sprintf("spike:event:%d", eventID)
+// paginate 简化的分页，page 从 1 开始
+func paginate[T any](items []T, page, pageSize int) ([]T, int64) {
+	total := int64(len(items))
+	start := (page - 1) * pageSize
+	if start < 0 || start >= len(items) {
+		return []T{}, total
+	}
+	end := min(start+pageSize, len(items))
+	return items[start:end], total
 }
 
-// MockSpikeCache 秒杀缓存模拟
+// MockSpikeCache 秒杀缓存内存实现，语义与 cache.SpikeCache 的 Lua 脚本一致
 type MockSpikeCache struct {
-	stockData     map[int64]int64       // eventID -> stock
-	soldOutData   map[int64]bool        // eventID -> soldOut
-	userMarkData  map[string]bool       // userKey -> marked
-	eventData     map[int64]interface{} // eventID -> event data
-	processedData map[string]bool       // messageID -> processed
-	mu            sync.RWMutex
+	*SpikeStockCacheMock
+
+	mu        sync.RWMutex
+	stock     map[int64]int64    // eventID -> stock
+	soldOut   map[int64]bool     // eventID -> soldOut
+	userMarks map[[2]int64]int64 // {userID, eventID} -> 已预减件数
+	events    map[int64]*domain.SpikeEvent
+	tokens    map[int64]map[int64]bool // eventID -> 已发放令牌的用户
+	buckets   map[int64]int            // eventID -> 预热时的库存分桶数，分桶只记录不拆分
 }
 
 func NewMockSpikeCache() *MockSpikeCache {
-	return &MockSpikeCache{
-		stockData:     make(map[int64]int64),
-		soldOutData:   make(map[int64]bool),
-		userMarkData:  make(map[string]bool),
-		eventData:     make(map[int64]interface{}),
-		processedData: make(map[string]bool),
+	m := &MockSpikeCache{
+		SpikeStockCacheMock: &SpikeStockCacheMock{},
+		stock:               make(map[int64]int64),
+		soldOut:             make(map[int64]bool),
+		userMarks:           make(map[[2]int64]int64),
+		events:              make(map[int64]*domain.SpikeEvent),
+		tokens:              make(map[int64]map[int64]bool),
+		buckets:             make(map[int64]int),
+	}
+	m.GetStockInfoFunc = m.getStockInfo
+	m.DecrementStockFunc = m.decrementStock
+	m.RestoreStockFunc = m.restoreStock
+	m.WarmupStockFunc = m.warmupStock
+	m.DecrementStockBucketsFunc = m.decrementStockBuckets
+	m.WarmupStockBucketsFunc = m.warmupStockBuckets
+	m.CacheEventInfoFunc = m.cacheEventInfo
+	m.GetEventInfoFunc = m.getEventInfo
+	m.ReserveParticipationTokenFunc = m.reserveParticipationToken
+	return m
+}
+
+func (m *MockSpikeCache) getStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stock, exists := m.stock[eventID]
+	if !exists {
+		stock = -1
 	}
+	return &cache.StockInfo{Stock: stock, SoldOut: m.soldOut[eventID], Exists: exists}, nil
 }
 
-func (m *MockSpikeCache) DecrementStock(ctx context.Context, eventID, userID, quantity int64, userTTL, stockTTL time.Duration) (*StockDecrementResult, error) {
+func (m *MockSpikeCache) decrementStock(ctx context.Context, eventID, userID, quantity, maxPerUser int64, userTTL, soldOutTTL time.Duration) (*cache.DecrementStockResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	userKey := GetSpikeUserKey(userID, eventID)
-
-	// 检查售罄标记
-	if m.soldOutData[eventID] {
-		return &StockDecrementResult{
-			Success:        false,
-			Message:        "商品已售罄",
-			RemainingStock: 0,
-		}, nil
+	if m.soldOut[eventID] {
+		return &cache.DecrementStockResult{Message: "商品已售罄", Reason: cache.DecrementReasonSoldOut}, nil
 	}
-
-	// 检查用户去重
-	if m.userMarkData[userKey] {
-		return &StockDecrementResult{
-			Success:        false,
-			Message:        "用户已参与该活动",
-			RemainingStock: m.stockData[eventID],
-		}, nil
+	userKey := [2]int64{userID, eventID}
+	purchased := m.userMarks[userKey]
+	if purchased > 0 && maxPerUser <= 0 {
+		return &cache.DecrementStockResult{Message: "用户重复参与", Reason: cache.DecrementReasonDuplicateUser}, nil
 	}
-
-	// 检查库存
-	currentStock := m.stockData[eventID]
-	if currentStock < quantity {
-		m.soldOutData[eventID] = true
-		return &StockDecrementResult{
-			Success:        false,
-			Message:        "库存不足",
-			RemainingStock: 0,
-		}, nil
+	if maxPerUser > 0 && purchased+quantity > maxPerUser {
+		return &cache.DecrementStockResult{Message: "超过单用户购买上限", Reason: cache.DecrementReasonUserLimit}, nil
+	}
+	stock, exists := m.stock[eventID]
+	if !exists {
+		return &cache.DecrementStockResult{Message: "库存信息不存在", Reason: cache.DecrementReasonStockNotFound}, nil
+	}
+	if stock < quantity {
+		return &cache.DecrementStockResult{Message: "库存不足", Reason: cache.DecrementReasonInsufficientStock}, nil
 	}
 
-	// 执行扣减
-	newStock := currentStock - quantity
-	m.stockData[eventID] = newStock
-	m.userMarkData[userKey] = true
-
-	if newStock == 0 {
-		m.soldOutData[eventID] = true
+	stock -= quantity
+	m.stock[eventID] = stock
+	m.userMarks[userKey] += quantity
+	if stock == 0 {
+		m.soldOut[eventID] = true
 	}
+	return &cache.DecrementStockResult{Success: true, RemainingStock: stock, Message: "预减库存成功"}, nil
+}
 
-	return &StockDecrementResult{
-		Success:        true,
-		Message:        "扣减成功",
-		RemainingStock: newStock,
-	}, nil
+func (m *MockSpikeCache) decrementStockBuckets(ctx context.Context, eventID, userID, quantity, maxPerUser int64, buckets int, userTTL, soldOutTTL time.Duration) (*cache.DecrementStockResult, error) {
+	return m.decrementStock(ctx, eventID, userID, quantity, maxPerUser, userTTL, soldOutTTL)
 }
 
-func (m *MockSpikeCache) RestoreStock(ctx context.Context, eventID, userID, quantity int64) (*StockRestoreResult, error) {
+func (m *MockSpikeCache) restoreStock(ctx context.Context, eventID, userID, quantity int64) (int64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	currentStock := m.stockData[eventID]
-	newStock := currentStock + quantity
-	m.stockData[eventID] = newStock
-
-	// 如果恢复库存后不再售罄，移除售罄标记
-	if m.soldOutData[eventID] && newStock > 0 {
-		m.soldOutData[eventID] = false
+	stock := m.stock[eventID] + quantity
+	m.stock[eventID] = stock
+	if stock > 0 {
+		delete(m.soldOut, eventID)
 	}
-
-	userKey := GetSpikeUserKey(userID, eventID)
-	delete(m.userMarkData, userKey)
-
-	return &StockRestoreResult{
-		Success:        true,
-		Message:        "库存恢复成功",
-		RemainingStock: newStock,
-	}, nil
+	userKey := [2]int64{userID, eventID}
+	if m.userMarks[userKey] -= quantity; m.userMarks[userKey] <= 0 {
+		delete(m.userMarks, userKey)
+	}
+	return stock, nil
 }
 
-func (m *MockSpikeCache) GetStockInfo(ctx context.Context, eventID int64) (*StockInfo, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *MockSpikeCache) warmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	return &StockInfo{
-		Stock:   m.stockData[eventID],
-		SoldOut: m.soldOutData[eventID],
-		Exists:  true,
-	}, nil
+	m.stock[eventID] = stock
+	delete(m.soldOut, eventID)
+	delete(m.buckets, eventID)
+	return nil
 }
 
-func (m *MockSpikeCache) WarmupStock(ctx context.Context, eventID, stock int64, ttl time.Duration) error {
+func (m *MockSpikeCache) warmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error {
+	if err := m.warmupStock(ctx, eventID, stock, ttl); err != nil {
+		return err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	m.stockData[eventID] = stock
-	m.soldOutData[eventID] = false
+	m.buckets[eventID] = buckets
 	return nil
 }
 
-func (m *MockSpikeCache) CacheEventInfo(ctx context.Context, eventID int64, event interface{}, ttl time.Duration) error {
+func (m *MockSpikeCache) cacheEventInfo(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error {
+	event, ok := eventData.(*domain.SpikeEvent)
+	if !ok {
+		return errors.New("unsupported event data type")
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	m.eventData[eventID] = event
+	copied := *event
+	m.events[eventID] = &copied
 	return nil
 }
 
-func (m *MockSpikeCache) GetEventInfo(ctx context.Context, eventID int64, dest interface{}) error {
+func (m *MockSpikeCache) getEventInfo(ctx context.Context, eventID int64, dest interface{}) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	data, exists := m.eventData[eventID]
+	event, exists := m.events[eventID]
 	if !exists {
-		return errors.New("not found")
+		return errors.New("event info not found")
 	}
-
-	// 简化实现，直接赋值
-	if event, ok := dest.(*domain.SpikeEvent); ok {
-		if srcEvent, ok := data.(*domain.SpikeEvent); ok {
-			*event = *srcEvent
-		}
+	target, ok := dest.(*domain.SpikeEvent)
+	if !ok {
+		return errors.New("unsupported event data type")
 	}
-
+	*target = *event
 	return nil
 }
 
-func (m *MockSpikeCache) CheckAndSetMessageProcessed(ctx context.Context, messageID, idempotencyKey string, ttl time.Duration) (bool, error) {
+func (m *MockSpikeCache) reserveParticipationToken(ctx context.Context, eventID, userID, maxTokens int64, ttl time.Duration) (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	key := messageID + ":" + idempotencyKey
-	if m.processedData[key] {
+	issued := m.tokens[eventID]
+	if issued == nil {
+		issued = make(map[int64]bool)
+		m.tokens[eventID] = issued
+	}
+	if issued[userID] {
 		return true, nil
 	}
-
-	m.processedData[key] = true
-	return false, nil
+	if int64(len(issued)) >= maxTokens {
+		return false, nil
+	}
+	issued[userID] = true
+	return true, nil
 }
 
-// MockSpikeProducer 秒杀消息生产者模拟
+// MockSpikeProducer 秒杀消息生产者内存实现，记录已发布的消息
 type MockSpikeProducer struct {
+	*mocks.SpikePublisherMock
+
+	mu                sync.Mutex
 	publishedMessages []interface{}
 	shouldFail        bool
-	mu                sync.Mutex
 }
 
 func NewMockSpikeProducer() *MockSpikeProducer {
-	return &MockSpikeProducer{
-		publishedMessages: make([]interface{}, 0),
+	m := &MockSpikeProducer{SpikePublisherMock: &mocks.SpikePublisherMock{}}
+	m.PublishSpikeOrderCreatedFunc = func(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error {
+		return m.record(data)
 	}
-}
-
-func (m *MockSpikeProducer) SetShouldFail(fail bool) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.shouldFail = fail
-}
-
-func (m *MockSpikeProducer) GetPublishedMessages() []interface{} {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	return append([]interface{}{}, m.publishedMessages...)
-}
-
-func (m *MockSpikeProducer) PublishSpikeOrderCreated(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if m.shouldFail {
-		return errors.New("mock publish failed")
+	m.PublishSpikeOrderPaidFunc = func(ctx context.Context, data *mq.SpikeOrderPaidData, traceID string) error {
+		return m.record(data)
 	}
-
-	m.publishedMessages = append(m.publishedMessages, data)
-	return nil
+	m.PublishSpikeOrderExpiredFunc = func(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error {
+		return m.record(data)
+	}
+	m.PublishSpikeOrderCancelledFunc = func(ctx context.Context, data *mq.SpikeOrderCancelledData, traceID string) error {
+		return m.record(data)
+	}
+	m.PublishStockRestoreFunc = func(ctx context.Context, data *mq.StockRestoreData, traceID string) error {
+		return m.record(data)
+	}
+	m.PublishNotificationFunc = func(ctx context.Context, data *mq.NotificationData, traceID string) error {
+		return m.record(data)
+	}
+	m.PublishSpikeOrderAbandonedFunc = func(ctx context.Context, data *mq.SpikeOrderAbandonedData, traceID string) error {
+		return m.record(data)
+	}
+	return m
 }
 
-func (m *MockSpikeProducer) PublishSpikeOrderCancelled(ctx context.Context, data *mq.SpikeOrderCancelledData, traceID string) error {
+func (m *MockSpikeProducer) record(data interface{}) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if m.shouldFail {
 		return errors.New("mock publish failed")
 	}
-
 	m.publishedMessages = append(m.publishedMessages, data)
 	return nil
 }
 
-func (m *MockSpikeProducer) PublishSpikeOrderExpired(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error {
+func (m *MockSpikeProducer) SetShouldFail(fail bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	if m.shouldFail {
-		return errors.New("mock publish failed")
-	}
-
-	m.publishedMessages = append(m.publishedMessages, data)
-	return nil
+	m.shouldFail = fail
 }
 
-func (m *MockSpikeProducer) PublishStockRestore(ctx context.Context, data *mq.StockRestoreData, traceID string) error {
+func (m *MockSpikeProducer) GetPublishedMessages() []interface{} {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	if m.shouldFail {
-		return errors.New("mock publish failed")
-	}
-
-	m.publishedMessages = append(m.publishedMessages, data)
-	return nil
+	return append([]interface{}{}, m.publishedMessages...)
 }
 
-// MockLimiter 限流器模拟
+// MockLimiter 可切换放行/拒绝的限流器
 type MockLimiter struct {
-	shouldAllow bool
+	*mocks.LimiterMock
+
 	mu          sync.Mutex
+	shouldAllow bool
 }
 
 func NewMockLimiter(shouldAllow bool) *MockLimiter {
-	return &MockLimiter{
-		shouldAllow: shouldAllow,
+	m := &MockLimiter{LimiterMock: &mocks.LimiterMock{}, shouldAllow: shouldAllow}
+	m.AllowFunc = func(ctx context.Context, key string) (*limiter.LimitResult, error) {
+		return m.result(), nil
 	}
-}
-
-func (m *MockLimiter) SetShouldAllow(allow bool) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.shouldAllow = allow
-}
-
-func (m *MockLimiter) Allow(ctx context.Context, key string) (*limiter.LimitResult, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	return &limiter.LimitResult{
-		Allowed:       m.shouldAllow,
-		Remaining:     100,
-		RetryAfter:    time.Second,
-		TotalRequests: 1,
-	}, nil
-}
-
-func (m *MockLimiter) AllowN(ctx context.Context, key string, n int64) (*limiter.LimitResult, error) {
-	return m.Allow(ctx, key)
-}
-
-func (m *MockLimiter) Reset(ctx context.Context, key string) error {
-	return nil
-}
-
-func (m *MockLimiter) GetInfo(ctx context.Context, key string) (*limiter.LimitInfo, error) {
-	return &limiter.LimitInfo{
-		Limit:     100,
-		Remaining: 99,
-		Window:    time.Minute,
-		ResetTime: time.Now().Add(time.Minute),
-	}, nil
-}
-
-// MockRedisClient Redis客户端模拟
-type MockRedisClient struct {
-	data map[string]interface{}
-	mu   sync.RWMutex
-}
-
-func NewMockRedisClient() *MockRedisClient {
-	return &MockRedisClient{
-		data: make(map[string]interface{}),
+	m.AllowNFunc = func(ctx context.Context, key string, n int64) (*limiter.LimitResult, error) {
+		return m.result(), nil
 	}
-}
-
-func (m *MockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	cmd := redis.NewStringCmd(ctx, "get", key)
-	if val, exists := m.data[key]; exists {
-		if str, ok := val.(string); ok {
-			cmd.SetVal(str)
-		}
-	} else {
-		cmd.SetErr(redis.Nil)
+	m.ResetFunc = func(ctx context.Context, key string) error { return nil }
+	m.GetInfoFunc = func(ctx context.Context, key string) (*limiter.LimitInfo, error) {
+		return &limiter.LimitInfo{Limit: 100, Remaining: 99, Window: time.Minute, ResetTime: time.Now().Add(time.Minute)}, nil
 	}
-	return cmd
+	return m
 }
 
-func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+func (m *MockLimiter) SetShouldAllow(allow bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	m.data[key] = value
-	cmd := redis.NewStatusCmd(ctx, "set", key, value)
-	cmd.SetVal("OK")
-	return cmd
+	m.shouldAllow = allow
 }
 
-func (m *MockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+func (m *MockLimiter) result() *limiter.LimitResult {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	count := int64(0)
-	for _, key := range keys {
-		if _, exists := m.data[key]; exists {
-			delete(m.data, key)
-			count++
-		}
-	}
-
-	cmd := redis.NewIntCmd(ctx, "del")
-	cmd.SetVal(count)
-	return cmd
-}
-
-func (m *MockRedisClient) Exists(ctx context.Context, keys ...string) *redis.IntCmd {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	count := int64(0)
-	for _, key := range keys {
-		if _, exists := m.data[key]; exists {
-			count++
-		}
-	}
-
-	cmd := redis.NewIntCmd(ctx, "exists")
-	cmd.SetVal(count)
-	return cmd
-}
-
-func (m *MockRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
-	cmd := redis.NewStatusCmd(ctx, "ping")
-	cmd.SetVal("PONG")
-	return cmd
-}
-
-func (m *MockRedisClient) Close() error {
-	return nil
-}
-
-// 实现redis.Cmdable接口的其他必需方法（简化实现）
-func (m *MockRedisClient) Pipeline() redis.Pipeliner { return nil }
-func (m *MockRedisClient) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
-	return nil, nil
-}
-func (m *MockRedisClient) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
-	return nil, nil
-}
-func (m *MockRedisClient) TxPipeline() redis.Pipeliner                        { return nil }
-func (m *MockRedisClient) Command(ctx context.Context) *redis.CommandsInfoCmd { return nil }
-func (m *MockRedisClient) CommandList(ctx context.Context, filter *redis.FilterBy) *redis.StringSliceCmd {
-	return nil
-}
-func (m *MockRedisClient) CommandGetKeys(ctx context.Context, commands ...interface{}) *redis.StringSliceCmd {
-	return nil
-}
-func (m *MockRedisClient) CommandGetKeysAndFlags(ctx context.Context, commands ...interface{}) *redis.KeyFlagsCmd {
-	return nil
-}
-func (m *MockRedisClient) ClientGetName(ctx context.Context) *redis.StringCmd             { return nil }
-func (m *MockRedisClient) Echo(ctx context.Context, message interface{}) *redis.StringCmd { return nil }
-func (m *MockRedisClient) Hello(ctx context.Context, ver int, username, password, clientName string) *redis.MapStringInterfaceCmd {
-	return nil
-}
-func (m *MockRedisClient) Select(ctx context.Context, index int) *redis.StatusCmd { return nil }
-func (m *MockRedisClient) SwapDB(ctx context.Context, index1, index2 int) *redis.StatusCmd {
-	return nil
-}
-func (m *MockRedisClient) ClientID(ctx context.Context) *redis.IntCmd { return nil }
-
-// 其他必需方法的空实现...
-func (m *MockRedisClient) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
-	return m.Set(ctx, key, value, expiration)
-}
-func (m *MockRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
-	cmd := redis.NewBoolCmd(ctx, "setnx", key, value)
-	cmd.SetVal(true)
-	return cmd
-}
-func (m *MockRedisClient) GetEx(ctx context.Context, key string, expiration time.Duration) *redis.StringCmd {
-	return m.Get(ctx, key)
-}
-func (m *MockRedisClient) GetDel(ctx context.Context, key string) *redis.StringCmd {
-	cmd := m.Get(ctx, key)
-	m.Del(ctx, key)
-	return cmd
-}
-
-// 更多方法的空实现，保持简单...
-func (m *MockRedisClient) Append(ctx context.Context, key, value string) *redis.IntCmd { return nil }
-func (m *MockRedisClient) Decr(ctx context.Context, key string) *redis.IntCmd          { return nil }
-func (m *MockRedisClient) DecrBy(ctx context.Context, key string, decrement int64) *redis.IntCmd {
-	return nil
-}
-func (m *MockRedisClient) Incr(ctx context.Context, key string) *redis.IntCmd {
-	return redis.NewIntCmd(ctx, "incr", key)
-}
-func (m *MockRedisClient) IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
-	return redis.NewIntCmd(ctx, "incrby", key, value)
-}
-func (m *MockRedisClient) IncrByFloat(ctx context.Context, key string, value float64) *redis.FloatCmd {
-	return nil
+	return &limiter.LimitResult{Allowed: m.shouldAllow, Remaining: 100, RetryAfter: time.Second, TotalRequests: 1}
 }
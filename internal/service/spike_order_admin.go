@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+const (
+	// orderExportPageSize 导出订单时每次分页查询的行数
+	orderExportPageSize = 500
+	// orderExportMaxRows 单次导出的最大订单数，超出部分截断，避免一次导出拖垮数据库
+	orderExportMaxRows = 100000
+)
+
+// ListSpikeOrders 跨用户分页查询秒杀订单（管理员），按活动、用户、状态与创建时间过滤
+func (s *SpikeService) ListSpikeOrders(ctx context.Context, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error) {
+	orders, total, err := s.spikeOrderRepo.List(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spike orders: %w", err)
+	}
+	orders, pageInfo := domain.PaginateWithCursor(orders, total, req.Page, req.PageSize, req.KeysetOrdered(), (*domain.SpikeOrder).Cursor)
+
+	return &domain.SpikeOrderListResponse{
+		Orders:   orders,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// ExportSpikeOrders 按过滤条件分页读取订单并写出为 CSV（带 UTF-8 BOM 以便 Excel 正确识别中文），
+// 忽略请求中的分页参数；按创建时间排序时以键集游标翻页，超过 orderExportMaxRows 时截断并返回 true
+func (s *SpikeService) ExportSpikeOrders(ctx context.Context, req *domain.SpikeOrderListRequest, w io.Writer) (bool, error) {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return false, err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "spike_event_id", "user_id", "order_id", "quantity", "spike_price",
+		"total_amount", "status", "created_at", "paid_at", "cancelled_at"}); err != nil {
+		return false, err
+	}
+
+	query := *req
+	query.PageSize = orderExportPageSize
+	query.SkipTotal = true
+	query.After = nil
+	keyset := query.KeysetOrdered()
+	written := 0
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		query.Page = page
+		orders, total, err := s.spikeOrderRepo.List(ctx, &query)
+		if err != nil {
+			return false, fmt.Errorf("failed to list spike orders: %w", err)
+		}
+		orders, pageInfo := domain.Paginate(orders, total, query.Page, query.PageSize)
+
+		for _, order := range orders {
+			if written == orderExportMaxRows {
+				cw.Flush()
+				return true, cw.Error()
+			}
+			if err := cw.Write(orderExportRow(order)); err != nil {
+				return false, err
+			}
+			written++
+		}
+		if !pageInfo.HasMore {
+			break
+		}
+		if keyset {
+			cursor := orders[len(orders)-1].Cursor()
+			query.After = &cursor
+		}
+	}
+
+	cw.Flush()
+	return false, cw.Error()
+}
+
+// orderExportRow 返回订单在导出 CSV 中的一行
+func orderExportRow(order *domain.SpikeOrder) []string {
+	orderID := ""
+	if order.OrderID != nil {
+		orderID = strconv.FormatInt(*order.OrderID, 10)
+	}
+	return []string{
+		strconv.FormatInt(order.ID, 10),
+		strconv.FormatInt(order.SpikeEventID, 10),
+		strconv.FormatInt(order.UserID, 10),
+		orderID,
+		strconv.FormatInt(order.Quantity, 10),
+		formatAmount(order.SpikePrice),
+		formatAmount(order.TotalAmount),
+		string(order.Status),
+		order.CreatedAt.Format(reportTimeFormat),
+		formatOptionalTime(order.PaidAt),
+		formatOptionalTime(order.CancelledAt),
+	}
+}
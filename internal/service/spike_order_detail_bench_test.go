@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// detailRoundTrip 模拟一次数据库往返耗时
+const detailRoundTrip = 200 * time.Microsecond
+
+// 以下仓储只实现订单详情用到的查询，其余方法由嵌入的空接口承接，调用即 panic
+
+// slowSpikeOrderRepo 为订单查询增加往返耗时，GetDetailByID 模拟单次 JOIN 查询
+type slowSpikeOrderRepo struct {
+	repo.SpikeOrderRepository
+	orders *MockSpikeOrderRepository
+	events *MockSpikeEventRepository
+	users  *fakeUserRepo
+}
+
+func (r *slowSpikeOrderRepo) GetByID(ctx context.Context, id int64) (*domain.SpikeOrder, error) {
+	time.Sleep(detailRoundTrip)
+	return r.orders.GetByID(context.Background(), id)
+}
+
+func (r *slowSpikeOrderRepo) GetDetailByID(ctx context.Context, id int64) (*domain.SpikeOrderWithDetails, error) {
+	time.Sleep(detailRoundTrip)
+	order, _ := r.orders.GetByID(context.Background(), id)
+	if order == nil {
+		return nil, domain.ErrSpikeOrderNotFound
+	}
+	event, _ := r.events.GetByID(context.Background(), order.SpikeEventID)
+	user, _ := r.users.GetByID(order.UserID)
+	return &domain.SpikeOrderWithDetails{SpikeOrder: order, SpikeEvent: event, User: user}, nil
+}
+
+type slowSpikeEventRepo struct {
+	repo.SpikeEventRepository
+	events *MockSpikeEventRepository
+}
+
+func (r *slowSpikeEventRepo) GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error) {
+	time.Sleep(detailRoundTrip)
+	return r.events.GetByID(context.Background(), id)
+}
+
+type slowUserRepo struct {
+	*fakeUserRepo
+}
+
+func (r *slowUserRepo) GetByID(id int64) (*domain.User, error) {
+	time.Sleep(detailRoundTrip)
+	return r.fakeUserRepo.getByID(id)
+}
+
+// newOrderDetailBenchService 构造带模拟往返耗时的秒杀服务，返回服务与订单ID、用户ID
+func newOrderDetailBenchService(tb testing.TB, join bool) (*SpikeService, int64, int64) {
+	events := NewMockSpikeEventRepository()
+	orders := NewMockSpikeOrderRepository()
+	users := newFakeUserRepo()
+
+	user := testutil.NewUserBuilder().WithUsername("bench").Build()
+	testutil.SeedUsers(tb, users, user)
+	event := testutil.NewSpikeEventBuilder().WithName("Bench Event").Build()
+	testutil.SeedSpikeEvents(tb, events, event)
+	order := testutil.NewSpikeOrderBuilder().ForEvent(event).ForUser(user.ID).Paid().Build()
+	testutil.SeedSpikeOrders(tb, orders, order)
+
+	config := DefaultSpikeServiceConfig()
+	config.OrderDetailJoin = join
+	orderRepo := &slowSpikeOrderRepo{orders: orders, events: events, users: users}
+	svc := NewSpikeService(&slowSpikeEventRepo{events: events}, orderRepo, nil, nil, &slowUserRepo{users}, nil,
+		nil, nil, nil, nil, config, zap.NewNop())
+	return svc, order.ID, user.ID
+}
+
+func benchmarkGetSpikeOrderDetail(b *testing.B, join bool) {
+	svc, orderID, userID := newOrderDetailBenchService(b, join)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		detail, err := svc.GetSpikeOrderDetail(ctx, orderID, userID)
+		if err != nil || detail.SpikeEvent == nil || detail.User == nil {
+			b.Fatalf("GetSpikeOrderDetail() = %+v, %v", detail, err)
+		}
+	}
+}
+
+// BenchmarkGetSpikeOrderDetail_Parallel 订单一次往返 + 活动与用户并发一次往返
+func BenchmarkGetSpikeOrderDetail_Parallel(b *testing.B) {
+	benchmarkGetSpikeOrderDetail(b, false)
+}
+
+// BenchmarkGetSpikeOrderDetail_Join 单次 JOIN 查询
+func BenchmarkGetSpikeOrderDetail_Join(b *testing.B) {
+	benchmarkGetSpikeOrderDetail(b, true)
+}
+
+func TestGetSpikeOrderDetail_JoinMatchesParallel(t *testing.T) {
+	for _, join := range []bool{false, true} {
+		svc, orderID, userID := newOrderDetailBenchService(t, join)
+
+		detail, err := svc.GetSpikeOrderDetail(context.Background(), orderID, userID)
+		if err != nil {
+			t.Fatalf("join=%v GetSpikeOrderDetail() error = %v", join, err)
+		}
+		if detail.SpikeEvent == nil || detail.User == nil || detail.User.ID != userID {
+			t.Errorf("join=%v GetSpikeOrderDetail() = %+v", join, detail)
+		}
+
+		if _, err := svc.GetSpikeOrderDetail(context.Background(), orderID, userID+1); err != domain.ErrForbidden {
+			t.Errorf("join=%v foreign user error = %v, want ErrForbidden", join, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
+)
+
+// ExpiredOrderStore 查询并标记过期的待支付订单（由 repo.SpikeOrderRepository 实现）
+type ExpiredOrderStore interface {
+	GetExpiredOrders(ctx context.Context, before time.Time, limit int) ([]*domain.SpikeOrder, error)
+	MarkExpired(ctx context.Context, id int64, before time.Time) (bool, error)
+}
+
+// ExpiredOrderEventSource 查询订单所属活动（由 repo.SpikeEventRepository 实现）
+type ExpiredOrderEventSource interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+}
+
+// ExpiredOrderPublisher 发布订单过期消息（由 mq.SpikePublisher 实现）
+type ExpiredOrderPublisher interface {
+	PublishSpikeOrderExpired(ctx context.Context, data *mq.SpikeOrderExpiredData, traceID string) error
+}
+
+// SpikeOrderExpirer 定期扫描超过支付期限的待支付订单，发布订单过期消息并将订单标记为已过期，
+// 由消费者恢复库存并记录弃单。
+// 先发布消息再更新状态：更新失败时下一轮扫描会重新发布，消息幂等键按订单固定，消费者只处理一次；
+// 状态只在订单仍为待支付且已过期时更新，不会覆盖同时完成的支付或延长。
+type SpikeOrderExpirer struct {
+	orders    ExpiredOrderStore
+	events    ExpiredOrderEventSource
+	publisher ExpiredOrderPublisher
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewSpikeOrderExpirer 创建订单过期任务，每次扫描最多处理 batchSize 条，处理满一批时立即继续
+func NewSpikeOrderExpirer(orders ExpiredOrderStore, events ExpiredOrderEventSource, publisher ExpiredOrderPublisher, interval time.Duration, batchSize int, logger *zap.Logger) *SpikeOrderExpirer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &SpikeOrderExpirer{
+		orders:    orders,
+		events:    events,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Start 异步启动订单过期任务，ctx 取消时退出
+func (e *SpikeOrderExpirer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		e.logger.Info("订单过期任务已启动",
+			zap.Duration("interval", e.interval),
+			zap.Int("batch_size", e.batchSize))
+		for {
+			select {
+			case <-ctx.Done():
+				e.logger.Info("订单过期任务已停止")
+				return
+			case <-ticker.C:
+				e.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 处理当前所有已过期的待支付订单，返回标记为已过期的订单数
+func (e *SpikeOrderExpirer) RunOnce(ctx context.Context) int {
+	now := time.Now()
+	products := make(map[int64]int64) // 活动ID -> 商品ID
+	total := 0
+	for ctx.Err() == nil {
+		orders, err := e.orders.GetExpiredOrders(ctx, now, e.batchSize)
+		if err != nil {
+			e.logger.Warn("查询过期订单失败", zap.Error(err))
+			break
+		}
+
+		expired := 0
+		for _, order := range orders {
+			if ctx.Err() != nil {
+				break
+			}
+			ok, err := e.expire(ctx, order, now, products)
+			if err != nil {
+				e.logger.Warn("处理过期订单失败", zap.Int64("spike_order_id", order.ID), zap.Error(err))
+				continue
+			}
+			if ok {
+				expired++
+			}
+		}
+		total += expired
+
+		// 不满一批说明已处理完；整批都未能标记时停止，避免反复查询到同一批订单
+		if len(orders) < e.batchSize || expired == 0 {
+			break
+		}
+	}
+
+	if total > 0 {
+		e.logger.Info("已处理过期订单", zap.Int("count", total))
+	}
+	return total
+}
+
+// expire 发布订单过期消息并标记订单已过期，返回订单是否由本次标记
+func (e *SpikeOrderExpirer) expire(ctx context.Context, order *domain.SpikeOrder, now time.Time, products map[int64]int64) (bool, error) {
+	productID, ok := products[order.SpikeEventID]
+	if !ok {
+		event, err := e.events.GetByID(ctx, order.SpikeEventID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get spike event: %w", err)
+		}
+		if event == nil {
+			return false, domain.ErrSpikeEventNotFound
+		}
+		productID = event.ProductID
+		products[order.SpikeEventID] = productID
+	}
+
+	ctx, traceID := tracing.EnsureTraceID(ctx)
+	if err := e.publisher.PublishSpikeOrderExpired(ctx, &mq.SpikeOrderExpiredData{
+		SpikeOrderID:   order.ID,
+		SpikeEventID:   order.SpikeEventID,
+		UserID:         order.UserID,
+		ProductID:      productID,
+		Quantity:       order.Quantity,
+		ExpiredAt:      *order.ExpireAt,
+		IdempotencyKey: keys.Idempotency("expire", order.ID),
+	}, traceID); err != nil {
+		return false, fmt.Errorf("failed to publish order expired message: %w", err)
+	}
+
+	marked, err := e.orders.MarkExpired(ctx, order.ID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark order expired: %w", err)
+	}
+	return marked, nil
+}
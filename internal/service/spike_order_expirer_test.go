@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+func TestSpikeOrderExpirer_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	events := NewMockSpikeEventRepository()
+	event := &domain.SpikeEvent{ProductID: 42, SpikeStock: 10}
+	_ = events.Create(context.Background(), event)
+
+	orders := NewMockSpikeOrderRepository()
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	future := now.Add(10 * time.Minute)
+	for userID := int64(1); userID <= 3; userID++ {
+		_ = orders.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: event.ID, UserID: userID, Quantity: 1,
+			Status: domain.SpikeOrderStatusPending, ExpireAt: &expired})
+	}
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: event.ID, UserID: 4, Quantity: 1,
+		Status: domain.SpikeOrderStatusPending, ExpireAt: &future})
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: event.ID, UserID: 5, Quantity: 1,
+		Status: domain.SpikeOrderStatusPaid, ExpireAt: &expired})
+
+	producer := NewMockSpikeProducer()
+	expirer := NewSpikeOrderExpirer(orders, events, producer, time.Second, 2, nil)
+
+	// 发布失败时不更新订单状态，下一轮重试
+	producer.SetShouldFail(true)
+	if n := expirer.RunOnce(ctx); n != 0 {
+		t.Fatalf("RunOnce() with failing publisher = %d, want 0", n)
+	}
+	if order, _ := orders.GetByID(context.Background(), 1); order.Status != domain.SpikeOrderStatusPending {
+		t.Fatalf("order status = %s after failed publish, want pending", order.Status)
+	}
+
+	// 批量大小为 2 时分两批处理完 3 个过期订单
+	producer.SetShouldFail(false)
+	if n := expirer.RunOnce(ctx); n != 3 {
+		t.Fatalf("RunOnce() = %d, want 3", n)
+	}
+	published := producer.GetPublishedMessages()
+	if len(published) != 3 {
+		t.Fatalf("published %d messages, want 3", len(published))
+	}
+	for _, msg := range published {
+		data := msg.(*mq.SpikeOrderExpiredData)
+		if data.ProductID != 42 || data.Quantity != 1 || data.IdempotencyKey == "" || !data.ExpiredAt.Equal(expired) {
+			t.Errorf("expired message = %+v", data)
+		}
+	}
+	for id := int64(1); id <= 3; id++ {
+		if order, _ := orders.GetByID(context.Background(), id); order.Status != domain.SpikeOrderStatusExpired {
+			t.Errorf("order %d status = %s, want expired", id, order.Status)
+		}
+	}
+	if order, _ := orders.GetByID(context.Background(), 4); order.Status != domain.SpikeOrderStatusPending {
+		t.Errorf("unexpired order status = %s, want pending", order.Status)
+	}
+
+	if n := expirer.RunOnce(ctx); n != 0 {
+		t.Errorf("second RunOnce() = %d, want 0", n)
+	}
+}
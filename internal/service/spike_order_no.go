@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/idgen"
+)
+
+// OrderNoGenerator 生成全局唯一的秒杀订单号（由 idgen.Snowflake 实现）
+type OrderNoGenerator interface {
+	NextOrderNo() (string, error)
+}
+
+// nextOrderNo 生成订单号，未设置生成器时使用 idgen.Default()（节点编号 0，仅适用于单实例部署）
+func nextOrderNo(gen OrderNoGenerator) (string, error) {
+	if gen == nil {
+		gen = idgen.Default()
+	}
+	orderNo, err := gen.NextOrderNo()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate order no: %w", err)
+	}
+	return orderNo, nil
+}
+
+// SetOrderNoGenerator 设置订单号生成器，多实例部署时必须设置为节点编号互不相同的生成器
+func (s *SpikeService) SetOrderNoGenerator(gen OrderNoGenerator) {
+	s.orderNos = gen
+}
+
+// GetSpikeOrderDetailByNo 按订单号获取订单详情，所有权校验同 GetSpikeOrderDetail。
+// 参与成功后订单异步落库，落库前查询返回 domain.ErrSpikeOrderNotFound
+func (s *SpikeService) GetSpikeOrderDetailByNo(ctx context.Context, orderNo string, userID int64) (*domain.SpikeOrderWithDetails, error) {
+	order, err := s.spikeOrderRepo.GetByOrderNo(ctx, orderNo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike order by order no: %w", err)
+	}
+	if order == nil {
+		return nil, domain.ErrSpikeOrderNotFound
+	}
+	return s.GetSpikeOrderDetail(ctx, order.ID, userID)
+}
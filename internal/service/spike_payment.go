@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
+)
+
+// spikeOrderPaymentRefPrefix 支付意图商户单号前缀，商户单号形如 spike_order:42
+const spikeOrderPaymentRefPrefix = "spike_order:"
+
+// PaidOrderPublisher 发布订单支付完成消息（由 mq.SpikePublisher 实现）
+type PaidOrderPublisher interface {
+	PublishSpikeOrderPaid(ctx context.Context, data *mq.SpikeOrderPaidData, traceID string) error
+}
+
+// SpikePaymentService 定义秒杀订单支付服务接口
+type SpikePaymentService interface {
+	// CreatePaymentIntent 为未过期的待支付订单创建支付意图，用户随后在支付渠道完成授权
+	CreatePaymentIntent(ctx context.Context, orderID, userID int64, isAdmin bool) (*payment.Intent, error)
+	// PayOrder 凭客户端取得的支付凭证一步完成扣款，扣款成功后发布订单支付消息，订单由消费者标记为已支付。
+	// 渠道不支持一步扣款或支付方式不是当前渠道时返回 domain.ErrPaymentMethodUnsupported，
+	// 渠道拒绝时返回失败的支付意图与 domain.ErrSpikeOrderPaymentDeclined
+	PayOrder(ctx context.Context, orderID, userID int64, isAdmin bool, req *domain.PaySpikeOrderRequest) (*payment.Intent, error)
+	// HandleWebhook 校验并处理支付渠道回调：授权成功时复核订单后扣款，扣款成功后发布订单支付消息。
+	// 签名无效时返回 payment.ErrInvalidSignature；订单已支付后收到的重复回调直接忽略
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+}
+
+// spikePaymentService 是SpikePaymentService接口的实现
+type spikePaymentService struct {
+	provider       payment.Provider
+	spikeOrderRepo repo.SpikeOrderRepository
+	orderEventRepo repo.OrderEventRepository
+	publisher      PaidOrderPublisher
+	currency       string
+	ownership      OwnershipPolicy
+	logger         *zap.Logger
+}
+
+// NewSpikePaymentService 创建秒杀订单支付服务，orderEventRepo 可为空（不记录发起支付事件）
+func NewSpikePaymentService(provider payment.Provider, spikeOrderRepo repo.SpikeOrderRepository, orderEventRepo repo.OrderEventRepository,
+	publisher PaidOrderPublisher, currency string, ownership OwnershipPolicy, logger *zap.Logger) SpikePaymentService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikePaymentService{
+		provider:       provider,
+		spikeOrderRepo: spikeOrderRepo,
+		orderEventRepo: orderEventRepo,
+		publisher:      publisher,
+		currency:       currency,
+		ownership:      ownership,
+		logger:         logger,
+	}
+}
+
+// CreatePaymentIntent 创建支付意图
+func (s *spikePaymentService) CreatePaymentIntent(ctx context.Context, orderID, userID int64, isAdmin bool) (*payment.Intent, error) {
+	ctx, traceID := tracing.EnsureTraceID(ctx)
+
+	order, err := s.getPayableOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.provider.CreateIntent(ctx, s.intentRequest(order))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	s.recordPaymentStarted(traceID, order, userID, intent)
+	return intent, nil
+}
+
+// PayOrder 一步扣款
+func (s *spikePaymentService) PayOrder(ctx context.Context, orderID, userID int64, isAdmin bool, req *domain.PaySpikeOrderRequest) (*payment.Intent, error) {
+	ctx, traceID := tracing.EnsureTraceID(ctx)
+
+	charger, ok := s.provider.(payment.Charger)
+	if !ok || req.PaymentMethod != s.provider.Name() {
+		return nil, domain.ErrPaymentMethodUnsupported
+	}
+	order, err := s.getPayableOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := charger.Charge(ctx, s.intentRequest(order), req.PaymentInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to charge payment: %w", err)
+	}
+	s.recordPaymentStarted(traceID, order, userID, intent)
+	if intent.Status != payment.IntentStatusSucceeded {
+		s.logger.Info("支付被拒绝", zap.Int64("spike_order_id", order.ID),
+			zap.String("intent_id", intent.ID), zap.String("reason", intent.FailureReason))
+		return intent, domain.ErrSpikeOrderPaymentDeclined
+	}
+
+	if err := s.publishPaid(ctx, traceID, order, intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// getPayableOrder 获取当前用户可支付的订单
+func (s *spikePaymentService) getPayableOrder(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrder, error) {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ownership.Authorize(order.UserID, userID, isAdmin, domain.ErrSpikeOrderNotFound); err != nil {
+		return nil, err
+	}
+	if !order.CanPay() {
+		return nil, domain.ErrSpikeOrderNotPayable
+	}
+	return order, nil
+}
+
+func (s *spikePaymentService) intentRequest(order *domain.SpikeOrder) *payment.IntentRequest {
+	return &payment.IntentRequest{
+		Reference: spikeOrderPaymentRef(order.ID),
+		Amount:    order.TotalAmount,
+		Currency:  s.currency,
+		UserID:    order.UserID,
+	}
+}
+
+// recordPaymentStarted 记录发起支付事件，备注为渠道与支付意图ID，供对账与客服查询
+func (s *spikePaymentService) recordPaymentStarted(traceID string, order *domain.SpikeOrder, userID int64, intent *payment.Intent) {
+	if s.orderEventRepo == nil {
+		return
+	}
+	if err := s.orderEventRepo.Create(&domain.OrderEvent{
+		SpikeOrderID: order.ID,
+		EventType:    domain.OrderEventPaymentStarted,
+		FromStatus:   string(order.Status),
+		ToStatus:     string(order.Status),
+		Actor:        domain.UserActor(userID),
+		Remark:       s.provider.Name() + ":" + intent.ID,
+		TraceID:      traceID,
+	}); err != nil {
+		s.logger.Warn("记录发起支付事件失败", zap.Int64("spike_order_id", order.ID), zap.Error(err))
+	}
+}
+
+// HandleWebhook 处理支付回调
+func (s *spikePaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	ctx, traceID := tracing.EnsureTraceID(ctx)
+
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+	orderID, err := parseSpikeOrderPaymentRef(event.Intent.Reference)
+	if err != nil {
+		// 不是本服务创建的支付意图，确认收到即可，避免渠道反复重试
+		s.logger.Warn("忽略未知商户单号的支付回调", zap.String("event_id", event.ID),
+			zap.String("reference", event.Intent.Reference))
+		return nil
+	}
+	logger := s.logger.With(zap.String("event_id", event.ID), zap.String("intent_id", event.Intent.ID),
+		zap.Int64("spike_order_id", orderID), zap.String("trace_id", traceID))
+
+	switch event.Type {
+	case payment.WebhookPaymentAuthorized:
+		return s.captureAuthorized(ctx, traceID, orderID, event.Intent, logger)
+	case payment.WebhookPaymentSucceeded:
+		return s.markPaid(ctx, traceID, orderID, event.Intent, logger)
+	case payment.WebhookPaymentFailed:
+		logger.Info("支付失败", zap.String("reason", event.Intent.FailureReason))
+		return nil
+	default:
+		logger.Warn("忽略未知类型的支付回调", zap.String("type", string(event.Type)))
+		return nil
+	}
+}
+
+// captureAuthorized 授权成功后复核订单仍可支付且金额一致再扣款；订单已不可支付时不扣款，授权由渠道到期释放
+func (s *spikePaymentService) captureAuthorized(ctx context.Context, traceID string, orderID int64, intent *payment.Intent, logger *zap.Logger) error {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.Status == domain.SpikeOrderStatusPaid {
+		logger.Info("订单已支付，忽略重复的授权回调")
+		return nil
+	}
+	if !order.CanPay() {
+		logger.Warn("订单已不可支付，跳过扣款", zap.String("status", string(order.Status)))
+		return nil
+	}
+	if !sameAmount(order.TotalAmount, intent.Amount) {
+		logger.Warn("支付金额与订单金额不一致，跳过扣款",
+			zap.Float64("order_amount", order.TotalAmount), zap.Float64("intent_amount", intent.Amount))
+		return nil
+	}
+
+	captured, err := s.provider.Capture(ctx, intent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to capture payment: %w", err)
+	}
+	if captured.Status != payment.IntentStatusSucceeded {
+		logger.Info("扣款失败", zap.String("reason", captured.FailureReason))
+		return nil
+	}
+	return s.publishPaid(ctx, traceID, order, captured)
+}
+
+// markPaid 渠道直接通知扣款成功时，订单仍为待支付则发布支付消息
+func (s *spikePaymentService) markPaid(ctx context.Context, traceID string, orderID int64, intent *payment.Intent, logger *zap.Logger) error {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != domain.SpikeOrderStatusPending {
+		logger.Info("订单不是待支付状态，忽略扣款成功回调", zap.String("status", string(order.Status)))
+		return nil
+	}
+	return s.publishPaid(ctx, traceID, order, intent)
+}
+
+func (s *spikePaymentService) publishPaid(ctx context.Context, traceID string, order *domain.SpikeOrder, intent *payment.Intent) error {
+	data := &mq.SpikeOrderPaidData{
+		SpikeOrderID:  order.ID,
+		UserID:        order.UserID,
+		PaymentMethod: s.provider.Name(),
+		PaidAmount:    intent.Amount,
+		PaidAt:        time.Now(),
+		TransactionID: intent.ID,
+	}
+	if order.OrderID != nil {
+		data.OrderID = *order.OrderID
+	}
+	if err := s.publisher.PublishSpikeOrderPaid(ctx, data, traceID); err != nil {
+		return fmt.Errorf("failed to publish spike order paid message: %w", err)
+	}
+	return nil
+}
+
+func (s *spikePaymentService) getOrder(ctx context.Context, orderID int64) (*domain.SpikeOrder, error) {
+	order, err := s.spikeOrderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSpikeOrderNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get spike order: %w", err)
+	}
+	if order == nil {
+		return nil, domain.ErrSpikeOrderNotFound
+	}
+	return order, nil
+}
+
+// sameAmount 按分比较金额
+func sameAmount(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
+
+func spikeOrderPaymentRef(orderID int64) string {
+	return spikeOrderPaymentRefPrefix + strconv.FormatInt(orderID, 10)
+}
+
+func parseSpikeOrderPaymentRef(ref string) (int64, error) {
+	idStr, ok := strings.CutPrefix(ref, spikeOrderPaymentRefPrefix)
+	if !ok {
+		return 0, fmt.Errorf("unknown payment reference %q", ref)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid payment reference %q", ref)
+	}
+	return id, nil
+}
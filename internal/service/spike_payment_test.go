@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/payment"
+)
+
+// payWithSandbox 走完整的沙箱支付流程：创建支付意图、模拟用户授权、投递签名回调
+func payWithSandbox(t *testing.T, svc SpikePaymentService, sandbox *payment.Sandbox, orderID, userID int64) *payment.WebhookEvent {
+	t.Helper()
+	ctx := context.Background()
+	intent, err := svc.CreatePaymentIntent(ctx, orderID, userID, false)
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent() error = %v", err)
+	}
+	event, err := sandbox.Authorize(ctx, intent.ID, userID)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	payload, signature, err := sandbox.SignWebhook(event)
+	if err != nil {
+		t.Fatalf("SignWebhook() error = %v", err)
+	}
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook() error = %v", err)
+	}
+	return event
+}
+
+func TestSpikePaymentService_SandboxFlow(t *testing.T) {
+	expireAt := time.Now().Add(10 * time.Minute)
+	tests := []struct {
+		name     string
+		amount   float64
+		wantPaid bool
+	}{
+		{name: "支付成功", amount: 99.00, wantPaid: true},
+		{name: "授权被拒绝", amount: 99.01},
+		{name: "扣款失败", amount: 99.02},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := NewMockSpikeOrderRepository()
+			order := &domain.SpikeOrder{UserID: 1, TotalAmount: tt.amount, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireAt}
+			_ = orders.Create(context.Background(), order)
+			producer := NewMockSpikeProducer()
+			sandbox := payment.NewSandbox("secret")
+			svc := NewSpikePaymentService(sandbox, orders, nil, producer, "CNY", OwnershipPolicy{}, nil)
+
+			event := payWithSandbox(t, svc, sandbox, order.ID, 1)
+
+			published := producer.GetPublishedMessages()
+			if !tt.wantPaid {
+				if len(published) != 0 {
+					t.Errorf("published %d messages, want none", len(published))
+				}
+				return
+			}
+			if len(published) != 1 {
+				t.Fatalf("published %d messages, want 1", len(published))
+			}
+			paid := published[0].(*mq.SpikeOrderPaidData)
+			if paid.SpikeOrderID != order.ID || paid.UserID != 1 || paid.PaidAmount != tt.amount ||
+				paid.PaymentMethod != payment.SandboxProviderName || paid.TransactionID != event.Intent.ID {
+				t.Errorf("paid message = %+v", paid)
+			}
+		})
+	}
+}
+
+func TestSpikePaymentService_Guards(t *testing.T) {
+	ctx := context.Background()
+	expireAt := time.Now().Add(10 * time.Minute)
+	orders := NewMockSpikeOrderRepository()
+	order := &domain.SpikeOrder{UserID: 1, TotalAmount: 50, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireAt}
+	_ = orders.Create(context.Background(), order)
+	producer := NewMockSpikeProducer()
+	sandbox := payment.NewSandbox("secret")
+	svc := NewSpikePaymentService(sandbox, orders, nil, producer, "CNY", OwnershipPolicy{}, nil)
+
+	if _, err := svc.CreatePaymentIntent(ctx, order.ID, 2, false); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("CreatePaymentIntent() by other user error = %v, want ErrForbidden", err)
+	}
+
+	// 授权后、扣款前订单已过期：不扣款也不发布支付消息
+	intent, _ := svc.CreatePaymentIntent(ctx, order.ID, 1, false)
+	event, _ := sandbox.Authorize(ctx, intent.ID, 1)
+	payload, signature, _ := sandbox.SignWebhook(event)
+	_ = orders.UpdateStatus(context.Background(), order.ID, domain.SpikeOrderStatusExpired)
+	if err := svc.HandleWebhook(ctx, payload, signature); err != nil {
+		t.Fatalf("HandleWebhook() error = %v", err)
+	}
+	if len(producer.GetPublishedMessages()) != 0 {
+		t.Error("expired order must not be marked paid")
+	}
+	if _, err := sandbox.Refund(ctx, intent.ID, 1); !errors.Is(err, payment.ErrInvalidState) {
+		t.Errorf("intent of expired order must not be captured, Refund() error = %v", err)
+	}
+	if _, err := svc.CreatePaymentIntent(ctx, order.ID, 1, false); !errors.Is(err, domain.ErrSpikeOrderNotPayable) {
+		t.Errorf("CreatePaymentIntent() on expired order error = %v, want ErrSpikeOrderNotPayable", err)
+	}
+
+	if err := svc.HandleWebhook(ctx, payload, "bad"); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Errorf("HandleWebhook() with bad signature error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSpikePaymentService_PayOrder(t *testing.T) {
+	ctx := context.Background()
+	expireAt := time.Now().Add(10 * time.Minute)
+	orders := NewMockSpikeOrderRepository()
+	paid := &domain.SpikeOrder{UserID: 1, TotalAmount: 30, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireAt}
+	declined := &domain.SpikeOrder{UserID: 1, TotalAmount: 30.01, Status: domain.SpikeOrderStatusPending, ExpireAt: &expireAt}
+	_ = orders.Create(context.Background(), paid)
+	_ = orders.Create(context.Background(), declined)
+	producer := NewMockSpikeProducer()
+	svc := NewSpikePaymentService(payment.NewSandbox("secret"), orders, nil, producer, "CNY", OwnershipPolicy{}, nil)
+	req := &domain.PaySpikeOrderRequest{PaymentMethod: payment.SandboxProviderName, PaymentInfo: "tok"}
+
+	intent, err := svc.PayOrder(ctx, paid.ID, 1, false, req)
+	if err != nil || intent.Status != payment.IntentStatusSucceeded {
+		t.Fatalf("PayOrder() = %+v, %v", intent, err)
+	}
+	published := producer.GetPublishedMessages()
+	if len(published) != 1 || published[0].(*mq.SpikeOrderPaidData).TransactionID != intent.ID {
+		t.Fatalf("published = %+v, want one paid message for %s", published, intent.ID)
+	}
+
+	intent, err = svc.PayOrder(ctx, declined.ID, 1, false, req)
+	if !errors.Is(err, domain.ErrSpikeOrderPaymentDeclined) || intent == nil || intent.Status != payment.IntentStatusFailed {
+		t.Errorf("PayOrder() declined = %+v, %v, want failed intent and ErrSpikeOrderPaymentDeclined", intent, err)
+	}
+	if _, err := svc.PayOrder(ctx, paid.ID, 1, false, &domain.PaySpikeOrderRequest{PaymentMethod: "card", PaymentInfo: "tok"}); !errors.Is(err, domain.ErrPaymentMethodUnsupported) {
+		t.Errorf("PayOrder() with other method error = %v, want ErrPaymentMethodUnsupported", err)
+	}
+	if _, err := svc.PayOrder(ctx, paid.ID, 2, false, req); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("PayOrder() by other user error = %v, want ErrForbidden", err)
+	}
+	if len(producer.GetPublishedMessages()) != 1 {
+		t.Errorf("published %d messages, want 1", len(producer.GetPublishedMessages()))
+	}
+}
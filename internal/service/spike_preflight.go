@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// PreflightCache 提供库存与活动缓存查询（由 cache.SpikeCache 实现）
+type PreflightCache interface {
+	GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+	GetEventInfo(ctx context.Context, eventID int64, dest interface{}) error
+}
+
+// PreflightConsumerSource 提供 RabbitMQ 与 Redis Streams 消息消费者运行状态（由 mq.SpikeConsumer 实现）
+type PreflightConsumerSource interface {
+	GetConsumerStats() map[string]mq.ConsumerStats
+	GetStreamConsumerStats() map[string]mq.RedisStreamConsumerStats
+}
+
+// PreflightQueueInspector 提供队列深度查询（由 mq.RedisStreamProducer 实现）
+type PreflightQueueInspector interface {
+	GetQueueInfo(ctx context.Context, queueName string) (*mq.QueueInfo, error)
+}
+
+// PreflightDBStats 提供数据库连接池统计（由 *sql.DB 实现）
+type PreflightDBStats interface {
+	Stats() sql.DBStats
+}
+
+// SpikePreflightConfig 上线检查配置
+type SpikePreflightConfig struct {
+	GlobalRateLimit int64         // 全局参与限流：每个窗口允许的请求数
+	UserRateLimit   int64         // 单用户参与限流：每个窗口允许的请求数
+	RateLimitWindow time.Duration // 限流窗口
+	// MaxLimiterHeadroom 全局限流折算 QPS 最多可以是预期 QPS 的多少倍，超过则限流起不到保护下游的作用
+	MaxLimiterHeadroom float64
+	// MaxDBPoolUtilization 数据库连接池使用中连接数占上限的最大比例
+	MaxDBPoolUtilization float64
+}
+
+// DefaultSpikePreflightConfig 默认上线检查配置
+func DefaultSpikePreflightConfig() *SpikePreflightConfig {
+	return &SpikePreflightConfig{
+		GlobalRateLimit:      1000,
+		UserRateLimit:        5,
+		RateLimitWindow:      time.Minute,
+		MaxLimiterHeadroom:   10,
+		MaxDBPoolUtilization: 0.8,
+	}
+}
+
+// SpikePreflight 活动上线前自动检查：库存预热、活动缓存、限流配置、消息消费者、死信队列与数据库连接池，
+// 返回逐项通过/未通过的清单，替代上线前的人工核对
+type SpikePreflight struct {
+	spikeEventRepo repo.SpikeEventRepository
+	orders         SoldQuantitySource
+	cache          PreflightCache
+	config         *SpikePreflightConfig
+	logger         *zap.Logger
+
+	consumers PreflightConsumerSource // 可为空，为空时消费者检查不通过
+	queues    PreflightQueueInspector // 可为空，为空时跳过死信队列检查
+	db        PreflightDBStats        // 可为空，为空时跳过连接池检查
+}
+
+// NewSpikePreflight 创建上线检查
+func NewSpikePreflight(spikeEventRepo repo.SpikeEventRepository, orders SoldQuantitySource, cache PreflightCache, config *SpikePreflightConfig, logger *zap.Logger) *SpikePreflight {
+	if config == nil {
+		config = DefaultSpikePreflightConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SpikePreflight{
+		spikeEventRepo: spikeEventRepo,
+		orders:         orders,
+		cache:          cache,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+// SetConsumers 设置消息消费者状态来源；未设置时视为没有消费者处理订单消息
+func (p *SpikePreflight) SetConsumers(consumers PreflightConsumerSource) {
+	p.consumers = consumers
+}
+
+// SetQueues 设置队列深度查询
+func (p *SpikePreflight) SetQueues(queues PreflightQueueInspector) {
+	p.queues = queues
+}
+
+// SetDBStats 设置数据库连接池统计来源
+func (p *SpikePreflight) SetDBStats(db PreflightDBStats) {
+	p.db = db
+}
+
+// Run 对活动执行全部检查，活动不存在返回 domain.ErrSpikeEventNotFound；单项检查出错记为未通过，不中断其它检查
+func (p *SpikePreflight) Run(ctx context.Context, eventID, expectedQPS int64) (*domain.PreflightReport, error) {
+	event, err := p.spikeEventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike event: %w", err)
+	}
+	if event == nil {
+		return nil, domain.ErrSpikeEventNotFound
+	}
+
+	report := &domain.PreflightReport{
+		SpikeEventID: eventID,
+		ExpectedQPS:  expectedQPS,
+		Passed:       true,
+		CheckedAt:    time.Now(),
+	}
+	report.Checks = []*domain.PreflightCheck{
+		p.checkSchedule(event),
+		p.checkStockWarmed(ctx, event),
+		p.checkEventCached(ctx, event),
+		p.checkLimiterCapacity(expectedQPS),
+		p.checkConsumers(),
+		p.checkDLQ(ctx),
+		p.checkDBPool(),
+	}
+	for _, check := range report.Checks {
+		if check.Status == domain.PreflightCheckFail {
+			report.Passed = false
+		}
+	}
+
+	p.logger.Info("活动上线检查完成",
+		zap.Int64("event_id", eventID),
+		zap.Int64("expected_qps", expectedQPS),
+		zap.Bool("passed", report.Passed))
+	return report, nil
+}
+
+func (p *SpikePreflight) checkSchedule(event *domain.SpikeEvent) *domain.PreflightCheck {
+	if event.IsFinished() {
+		return preflightFail(domain.PreflightEventSchedule, "活动已结束或已取消（状态 %s）", event.Status)
+	}
+	if event.IsActive() {
+		return preflightPass(domain.PreflightEventSchedule, "活动进行中，将于 %s 结束", event.EndAt.Format(time.RFC3339))
+	}
+	return preflightPass(domain.PreflightEventSchedule, "活动将于 %s 开始", event.StartAt.Format(time.RFC3339))
+}
+
+// checkStockWarmed 未开始的活动缓存库存须与数据库剩余库存一致；进行中的活动缓存库存先于订单落库扣减，不得多于剩余库存
+func (p *SpikePreflight) checkStockWarmed(ctx context.Context, event *domain.SpikeEvent) *domain.PreflightCheck {
+	info, err := p.cache.GetStockInfo(ctx, event.ID)
+	if err != nil {
+		return preflightFail(domain.PreflightStockWarmed, "查询缓存库存失败: %v", err)
+	}
+	if !info.Exists {
+		return preflightFail(domain.PreflightStockWarmed, "库存未预热")
+	}
+
+	remaining, _, err := remainingStockFromDB(ctx, p.orders, event)
+	if err != nil {
+		return preflightFail(domain.PreflightStockWarmed, "查询剩余库存失败: %v", err)
+	}
+	if info.Stock > remaining || (!event.IsActive() && info.Stock != remaining) {
+		return preflightFail(domain.PreflightStockWarmed, "缓存库存 %d 与剩余库存 %d 不一致，需强制重新预热", info.Stock, remaining)
+	}
+	return preflightPass(domain.PreflightStockWarmed, "缓存库存 %d，剩余库存 %d", info.Stock, remaining)
+}
+
+func (p *SpikePreflight) checkEventCached(ctx context.Context, event *domain.SpikeEvent) *domain.PreflightCheck {
+	var cached domain.SpikeEvent
+	if err := p.cache.GetEventInfo(ctx, event.ID, &cached); err != nil {
+		return preflightFail(domain.PreflightEventCached, "活动信息未缓存，参与请求将回源数据库: %v", err)
+	}
+	if !cached.StartAt.Equal(event.StartAt) || !cached.EndAt.Equal(event.EndAt) || cached.SpikeStock != event.SpikeStock {
+		return preflightFail(domain.PreflightEventCached, "缓存的活动时间或库存与数据库不一致，需重新预热")
+	}
+	return preflightPass(domain.PreflightEventCached, "活动信息已缓存")
+}
+
+// checkLimiterCapacity 全局限流折算 QPS 不得低于预期 QPS（否则正常流量被拒绝），
+// 也不得超过预期 QPS 的 MaxLimiterHeadroom 倍（否则起不到保护作用）
+func (p *SpikePreflight) checkLimiterCapacity(expectedQPS int64) *domain.PreflightCheck {
+	cfg := p.config
+	if cfg.GlobalRateLimit <= 0 || cfg.UserRateLimit <= 0 || cfg.RateLimitWindow <= 0 {
+		return preflightFail(domain.PreflightLimiterCapacity, "限流配置无效：全局 %d、单用户 %d、窗口 %s",
+			cfg.GlobalRateLimit, cfg.UserRateLimit, cfg.RateLimitWindow)
+	}
+	if cfg.UserRateLimit > cfg.GlobalRateLimit {
+		return preflightFail(domain.PreflightLimiterCapacity, "单用户限流 %d 大于全局限流 %d", cfg.UserRateLimit, cfg.GlobalRateLimit)
+	}
+
+	capacity := float64(cfg.GlobalRateLimit) / cfg.RateLimitWindow.Seconds()
+	if expectedQPS == 0 {
+		return preflightPass(domain.PreflightLimiterCapacity, "全局限流约 %.1f QPS（未提供预期 QPS，未与预期流量比较）", capacity)
+	}
+	if capacity < float64(expectedQPS) {
+		return preflightFail(domain.PreflightLimiterCapacity, "全局限流约 %.1f QPS，低于预期 %d QPS，正常请求会被限流", capacity, expectedQPS)
+	}
+	if cfg.MaxLimiterHeadroom > 0 && capacity > float64(expectedQPS)*cfg.MaxLimiterHeadroom {
+		return preflightFail(domain.PreflightLimiterCapacity, "全局限流约 %.1f QPS，超过预期 %d QPS 的 %.0f 倍，无法保护下游",
+			capacity, expectedQPS, cfg.MaxLimiterHeadroom)
+	}
+	return preflightPass(domain.PreflightLimiterCapacity, "全局限流约 %.1f QPS，预期 %d QPS", capacity, expectedQPS)
+}
+
+func (p *SpikePreflight) checkConsumers() *domain.PreflightCheck {
+	if p.consumers == nil {
+		return preflightFail(domain.PreflightConsumers, "未配置消息消费者，订单消息不会被处理")
+	}
+	running := make(map[string]bool)
+	for name, s := range p.consumers.GetConsumerStats() {
+		running[name] = s.Running
+	}
+	for name, s := range p.consumers.GetStreamConsumerStats() {
+		running[name] = s.IsRunning
+	}
+	if len(running) == 0 {
+		return preflightFail(domain.PreflightConsumers, "没有已启动的消息消费者")
+	}
+
+	var stopped []string
+	for name, ok := range running {
+		if !ok {
+			stopped = append(stopped, name)
+		}
+	}
+	if len(stopped) > 0 {
+		sort.Strings(stopped)
+		return preflightFail(domain.PreflightConsumers, "消费者未运行: %s", strings.Join(stopped, ", "))
+	}
+	return preflightPass(domain.PreflightConsumers, "%d 个消费者运行中", len(running))
+}
+
+func (p *SpikePreflight) checkDLQ(ctx context.Context) *domain.PreflightCheck {
+	if p.queues == nil {
+		return preflightSkip(domain.PreflightDLQEmpty, "未配置队列查询")
+	}
+	info, err := p.queues.GetQueueInfo(ctx, mq.SpikeDLXQueue)
+	if err != nil {
+		return preflightFail(domain.PreflightDLQEmpty, "查询死信队列失败: %v", err)
+	}
+	if info.Messages > 0 {
+		return preflightFail(domain.PreflightDLQEmpty, "死信队列有 %d 条消息，需先排查处理", info.Messages)
+	}
+	return preflightPass(domain.PreflightDLQEmpty, "死信队列为空")
+}
+
+func (p *SpikePreflight) checkDBPool() *domain.PreflightCheck {
+	if p.db == nil {
+		return preflightSkip(domain.PreflightDBPoolHeadroom, "未配置连接池统计")
+	}
+	stats := p.db.Stats()
+	if stats.MaxOpenConnections <= 0 {
+		return preflightPass(domain.PreflightDBPoolHeadroom, "连接数不设上限，使用中 %d", stats.InUse)
+	}
+	utilization := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	if utilization > p.config.MaxDBPoolUtilization {
+		return preflightFail(domain.PreflightDBPoolHeadroom, "使用中连接 %d/%d（%.0f%%），超过 %.0f%%",
+			stats.InUse, stats.MaxOpenConnections, utilization*100, p.config.MaxDBPoolUtilization*100)
+	}
+	return preflightPass(domain.PreflightDBPoolHeadroom, "使用中连接 %d/%d，等待连接 %d 次",
+		stats.InUse, stats.MaxOpenConnections, stats.WaitCount)
+}
+
+func preflightPass(name, format string, args ...any) *domain.PreflightCheck {
+	return &domain.PreflightCheck{Name: name, Status: domain.PreflightCheckPass, Detail: fmt.Sprintf(format, args...)}
+}
+
+func preflightFail(name, format string, args ...any) *domain.PreflightCheck {
+	return &domain.PreflightCheck{Name: name, Status: domain.PreflightCheckFail, Detail: fmt.Sprintf(format, args...)}
+}
+
+func preflightSkip(name, detail string) *domain.PreflightCheck {
+	return &domain.PreflightCheck{Name: name, Status: domain.PreflightCheckSkip, Detail: detail}
+}
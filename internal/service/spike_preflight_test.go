@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+type fakePreflightConsumers map[string]mq.RedisStreamConsumerStats
+
+func (f fakePreflightConsumers) GetConsumerStats() map[string]mq.ConsumerStats {
+	return nil
+}
+
+func (f fakePreflightConsumers) GetStreamConsumerStats() map[string]mq.RedisStreamConsumerStats {
+	return f
+}
+
+type fakePreflightQueues map[string]int
+
+func (f fakePreflightQueues) GetQueueInfo(ctx context.Context, queueName string) (*mq.QueueInfo, error) {
+	return &mq.QueueInfo{Name: queueName, Messages: f[queueName]}, nil
+}
+
+type fakePreflightDB sql.DBStats
+
+func (f fakePreflightDB) Stats() sql.DBStats { return sql.DBStats(f) }
+
+// preflightStatuses 按检查项名称索引结果
+func preflightStatuses(report *domain.PreflightReport) map[string]domain.PreflightCheckStatus {
+	statuses := make(map[string]domain.PreflightCheckStatus, len(report.Checks))
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestSpikePreflight_Run(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	events := NewMockSpikeEventRepository()
+	event := &domain.SpikeEvent{ProductID: 1, SpikeStock: 100, StartAt: now.Add(time.Hour), EndAt: now.Add(2 * time.Hour),
+		Status: domain.SpikeEventStatusPending}
+	_ = events.Create(context.Background(), event)
+	orders := NewMockSpikeOrderRepository()
+	spikeCache := NewMockSpikeCache()
+
+	preflight := NewSpikePreflight(events, orders, spikeCache, &SpikePreflightConfig{
+		GlobalRateLimit: 600, UserRateLimit: 5, RateLimitWindow: time.Minute,
+		MaxLimiterHeadroom: 10, MaxDBPoolUtilization: 0.8,
+	}, nil)
+
+	// 未预热、无消费者：库存、缓存与消费者检查不通过，未配置的队列与连接池检查跳过
+	report, err := preflight.Run(ctx, event.ID, 5)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := map[string]domain.PreflightCheckStatus{
+		domain.PreflightEventSchedule:   domain.PreflightCheckPass,
+		domain.PreflightStockWarmed:     domain.PreflightCheckFail,
+		domain.PreflightEventCached:     domain.PreflightCheckFail,
+		domain.PreflightLimiterCapacity: domain.PreflightCheckPass,
+		domain.PreflightConsumers:       domain.PreflightCheckFail,
+		domain.PreflightDLQEmpty:        domain.PreflightCheckSkip,
+		domain.PreflightDBPoolHeadroom:  domain.PreflightCheckSkip,
+	}
+	if report.Passed {
+		t.Error("Passed = true before warmup, want false")
+	}
+	for name, status := range want {
+		if got := preflightStatuses(report)[name]; got != status {
+			t.Errorf("%s = %s, want %s", name, got, status)
+		}
+	}
+
+	// 预热完成并配置全部来源后通过
+	_ = spikeCache.WarmupStock(ctx, event.ID, event.SpikeStock, time.Hour)
+	_ = spikeCache.CacheEventInfo(ctx, event.ID, event, time.Hour)
+	preflight.SetConsumers(fakePreflightConsumers{"order": {IsRunning: true}})
+	preflight.SetQueues(fakePreflightQueues{})
+	preflight.SetDBStats(fakePreflightDB{MaxOpenConnections: 10, InUse: 2})
+	report, err = preflight.Run(ctx, event.ID, 5)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !report.Passed {
+		t.Errorf("Passed = false after warmup, checks = %+v", report.Checks)
+	}
+
+	// 各项未通过的情形
+	tests := []struct {
+		name        string
+		expectedQPS int64
+		setup       func()
+		check       string
+	}{
+		{name: "预期流量高于限流", expectedQPS: 50, check: domain.PreflightLimiterCapacity},
+		{name: "限流远高于预期流量", expectedQPS: 5, setup: func() { preflight.config.MaxLimiterHeadroom = 1 }, check: domain.PreflightLimiterCapacity},
+		{name: "缓存库存与剩余库存不一致", expectedQPS: 5, setup: func() { _ = spikeCache.WarmupStock(ctx, event.ID, 90, time.Hour) }, check: domain.PreflightStockWarmed},
+		{name: "消费者已停止", expectedQPS: 5, setup: func() { preflight.SetConsumers(fakePreflightConsumers{"order": {}}) }, check: domain.PreflightConsumers},
+		{name: "死信队列非空", expectedQPS: 5, setup: func() { preflight.SetQueues(fakePreflightQueues{mq.SpikeDLXQueue: 3}) }, check: domain.PreflightDLQEmpty},
+		{name: "连接池余量不足", expectedQPS: 5, setup: func() { preflight.SetDBStats(fakePreflightDB{MaxOpenConnections: 10, InUse: 9}) }, check: domain.PreflightDBPoolHeadroom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			report, err := preflight.Run(ctx, event.ID, tt.expectedQPS)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if report.Passed || preflightStatuses(report)[tt.check] != domain.PreflightCheckFail {
+				t.Errorf("%s = %s, passed = %v, want fail", tt.check, preflightStatuses(report)[tt.check], report.Passed)
+			}
+		})
+	}
+
+	if _, err := preflight.Run(ctx, 999, 5); !errors.Is(err, domain.ErrSpikeEventNotFound) {
+		t.Errorf("Run() on missing event error = %v, want ErrSpikeEventNotFound", err)
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+const (
+	// PublicSpikeEventPageSize 匿名活动列表的固定每页大小，限制缓存键数量
+	PublicSpikeEventPageSize = 20
+	// PublicSpikeEventMaxPage 匿名活动列表允许访问的最大页码
+	PublicSpikeEventMaxPage = 10
+	// DefaultPublicSpikeCacheTTL 匿名只读视图的默认缓存有效期
+	DefaultPublicSpikeCacheTTL = 5 * time.Second
+)
+
+// PublicSpikeEventSource 匿名只读视图的回源查询（由 SpikeService 实现）
+type PublicSpikeEventSource interface {
+	GetActiveEvents(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventListResponse, error)
+	GetSpikeEventDetail(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error)
+}
+
+// PublicSpikeCatalog 定义面向匿名用户的秒杀活动只读服务接口
+type PublicSpikeCatalog interface {
+	// ListEvents 获取活跃活动列表，page 超出 PublicSpikeEventMaxPage 时返回空列表
+	ListEvents(ctx context.Context, page int) (*domain.PublicSpikeEventListResponse, error)
+	// GetEvent 获取活动详情，活动不存在或尚未进入预告期时返回 domain.ErrSpikeEventNotFound
+	GetEvent(ctx context.Context, eventID int64) (*domain.PublicSpikeEventDetail, error)
+	// EvictEvent 删除活动详情的缓存视图
+	EvictEvent(ctx context.Context, eventID int64) error
+}
+
+// publicSpikeCatalog 是PublicSpikeCatalog接口的实现
+type publicSpikeCatalog struct {
+	source PublicSpikeEventSource
+	cache  cache.Cache
+	ttl    time.Duration
+	loads  singleflight.Group
+	logger *zap.Logger
+}
+
+// NewPublicSpikeCatalog 创建匿名只读活动服务。
+// 请求只读缓存；缓存未命中时同一键只有一个请求回源并回填，其余并发请求共享该结果，
+// 因此匿名流量对数据库的压力按 ttl 封顶，与请求量无关。ttl 不大于0时使用 DefaultPublicSpikeCacheTTL。
+func NewPublicSpikeCatalog(source PublicSpikeEventSource, c cache.Cache, ttl time.Duration, logger *zap.Logger) PublicSpikeCatalog {
+	if c == nil {
+		c = cache.NewMemoryCache()
+	}
+	if ttl <= 0 {
+		ttl = DefaultPublicSpikeCacheTTL
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &publicSpikeCatalog{
+		source: source,
+		cache:  c,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// publicEventListCacheKey 匿名活动列表缓存键
+func publicEventListCacheKey(page int) string {
+	return fmt.Sprintf("spike:public:events:page:%d", page)
+}
+
+// publicEventDetailCacheKey 匿名活动详情缓存键
+func publicEventDetailCacheKey(eventID int64) string {
+	return fmt.Sprintf("spike:public:event:%d", eventID)
+}
+
+// ListEvents 获取匿名活动列表
+func (s *publicSpikeCatalog) ListEvents(ctx context.Context, page int) (*domain.PublicSpikeEventListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if page > PublicSpikeEventMaxPage {
+		return &domain.PublicSpikeEventListResponse{
+			Events:   []*domain.PublicSpikeEvent{},
+			Page:     page,
+			PageSize: PublicSpikeEventPageSize,
+		}, nil
+	}
+
+	key := publicEventListCacheKey(page)
+	var list domain.PublicSpikeEventListResponse
+	if err := s.cache.Get(ctx, key, &list); err == nil {
+		return &list, nil
+	}
+
+	v, err, _ := s.loads.Do(key, func() (interface{}, error) {
+		res, err := s.source.GetActiveEvents(ctx, &domain.SpikeEventListRequest{
+			Page:      page,
+			PageSize:  PublicSpikeEventPageSize,
+			SkipTotal: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		list := &domain.PublicSpikeEventListResponse{
+			Events:   make([]*domain.PublicSpikeEvent, 0, len(res.Events)),
+			Page:     page,
+			PageSize: PublicSpikeEventPageSize,
+			PageInfo: res.PageInfo,
+		}
+		for _, event := range res.Events {
+			list.Events = append(list.Events, domain.NewPublicSpikeEvent(event))
+		}
+		// 匿名视图按页码缓存，游标使用下一页页码而不是活动列表的键集游标；
+		// 最后一页之后不再提供游标，避免客户端翻到被拒绝的页码
+		if list.HasMore {
+			list.NextCursor = strconv.Itoa(page + 1)
+		}
+		if page >= PublicSpikeEventMaxPage {
+			list.PageInfo = domain.PageInfo{}
+		}
+		s.store(ctx, key, list)
+		return list, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public spike events: %w", err)
+	}
+	return v.(*domain.PublicSpikeEventListResponse), nil
+}
+
+// GetEvent 获取匿名活动详情
+func (s *publicSpikeCatalog) GetEvent(ctx context.Context, eventID int64) (*domain.PublicSpikeEventDetail, error) {
+	key := publicEventDetailCacheKey(eventID)
+	var detail domain.PublicSpikeEventDetail
+	if err := s.cache.Get(ctx, key, &detail); err == nil {
+		return &detail, nil
+	}
+
+	v, err, _ := s.loads.Do(key, func() (interface{}, error) {
+		res, err := s.source.GetSpikeEventDetail(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		detail := domain.NewPublicSpikeEventDetail(res)
+		s.store(ctx, key, detail)
+		return detail, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public spike event: %w", err)
+	}
+	return v.(*domain.PublicSpikeEventDetail), nil
+}
+
+// EvictEvent 删除活动详情缓存
+func (s *publicSpikeCatalog) EvictEvent(ctx context.Context, eventID int64) error {
+	if err := s.cache.Del(ctx, publicEventDetailCacheKey(eventID)); err != nil {
+		return fmt.Errorf("failed to evict public spike event: %w", err)
+	}
+	return nil
+}
+
+// store 回填缓存，失败时仅记录日志，下一个请求会再次回源
+func (s *publicSpikeCatalog) store(ctx context.Context, key string, value interface{}) {
+	if err := s.cache.Set(ctx, key, value, s.ttl); err != nil {
+		s.logger.Warn("缓存匿名活动视图失败", zap.String("key", key), zap.Error(err))
+	}
+}
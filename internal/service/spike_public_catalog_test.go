@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// stubPublicEventSource 统计回源次数的活动查询桩
+type stubPublicEventSource struct {
+	listCalls   atomic.Int32
+	detailCalls atomic.Int32
+}
+
+func (s *stubPublicEventSource) GetActiveEvents(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventListResponse, error) {
+	s.listCalls.Add(1)
+	campaignID := int64(9)
+	return &domain.SpikeEventListResponse{
+		Events: []*domain.SpikeEvent{{
+			ID: 1, Name: "flash", SpikeStock: 100, SoldCount: 40,
+			SpikeCampaignID: &campaignID, Status: domain.SpikeEventStatusActive,
+		}},
+		Total:    domain.TotalNotCounted,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: domain.PageInfo{HasMore: true, NextCursor: "2"},
+	}, nil
+}
+
+func (s *stubPublicEventSource) GetSpikeEventDetail(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error) {
+	s.detailCalls.Add(1)
+	return &domain.SpikeEventWithProduct{
+		SpikeEvent: &domain.SpikeEvent{ID: eventID, Name: "flash", SpikeStock: 100, SoldCount: 75},
+		Product:    &domain.Product{ID: 3, Name: "phone", SKU: "SKU-3"},
+	}, nil
+}
+
+func TestPublicSpikeCatalog_ServesFromCache(t *testing.T) {
+	ctx := context.Background()
+	source := &stubPublicEventSource{}
+	catalog := NewPublicSpikeCatalog(source, cache.NewMemoryCache(), time.Minute, nil)
+
+	for range 3 {
+		list, err := catalog.ListEvents(ctx, 1)
+		if err != nil {
+			t.Fatalf("ListEvents() error = %v", err)
+		}
+		if len(list.Events) != 1 || list.PageSize != PublicSpikeEventPageSize || !list.HasMore {
+			t.Fatalf("ListEvents() = %+v", list)
+		}
+	}
+	if got := source.listCalls.Load(); got != 1 {
+		t.Errorf("list source calls = %d, want 1", got)
+	}
+
+	for range 3 {
+		detail, err := catalog.GetEvent(ctx, 5)
+		if err != nil {
+			t.Fatalf("GetEvent() error = %v", err)
+		}
+		if detail.ProductName != "phone" || detail.StockBucket != 3 || detail.SoldOut {
+			t.Fatalf("GetEvent() = %+v, want product phone, bucket 3, not sold out", detail)
+		}
+	}
+	if got := source.detailCalls.Load(); got != 1 {
+		t.Errorf("detail source calls = %d, want 1", got)
+	}
+}
+
+func TestPublicSpikeCatalog_PageBeyondMax(t *testing.T) {
+	source := &stubPublicEventSource{}
+	catalog := NewPublicSpikeCatalog(source, cache.NewMemoryCache(), time.Minute, nil)
+
+	list, err := catalog.ListEvents(context.Background(), PublicSpikeEventMaxPage+1)
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if len(list.Events) != 0 || list.HasMore || source.listCalls.Load() != 0 {
+		t.Errorf("ListEvents() beyond max page = %+v, source calls %d, want empty without loading", list, source.listCalls.Load())
+	}
+
+	list, _ = catalog.ListEvents(context.Background(), PublicSpikeEventMaxPage)
+	if list.HasMore || list.NextCursor != "" {
+		t.Errorf("last allowed page should not advertise a next cursor, got %+v", list.PageInfo)
+	}
+}
@@ -0,0 +1,484 @@
+// Package service 提供秒杀活动报表的异步生成与下载服务。
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+	"github.com/MorseWayne/spike_shop/internal/storage"
+)
+
+// 报表相关错误
+var (
+	ErrSpikeEventNotFinished = errors.New("spike event not finished")
+	ErrReportNotFound        = errors.New("report not found")
+)
+
+const (
+	reportGenerateTimeout = 5 * time.Minute // 单次报表生成超时
+	reportTopReasons      = 10              // 失败原因 Top N
+	reportTimeFormat      = "2006-01-02 15:04:05"
+)
+
+// SpikeReportService 定义秒杀活动报表服务接口
+type SpikeReportService interface {
+	// RequestReport 查询报表状态：已生成时返回签名下载地址，否则触发异步生成；
+	// regenerate 为 true 时忽略已有报表重新生成
+	RequestReport(ctx context.Context, eventID int64, regenerate bool) (*domain.SpikeReport, error)
+	// OpenReport 校验下载签名并打开报表文件
+	OpenReport(ctx context.Context, key, expires, sig string) (io.ReadCloser, error)
+}
+
+// spikeReportService 是SpikeReportService接口的实现
+type spikeReportService struct {
+	spikeEventRepo repo.SpikeEventRepository
+	spikeOrderRepo repo.SpikeOrderRepository
+	orderEventRepo repo.OrderEventRepository
+	store          storage.Storage
+	signer         *storage.URLSigner
+	logger         *zap.Logger
+
+	// 分享链接归因，可为空，为空时报表不含分享归因段
+	attributionRepo repo.SpikeShareAttributionRepository
+	// 后台任务队列，可为空，为空时每个报表在独立协程中生成
+	jobQueue AdminJobSubmitter
+
+	mu   sync.Mutex
+	jobs map[int64]*domain.SpikeReport // 生成中或失败的报表状态，生成成功后移除
+}
+
+// SpikeReportServiceOption 报表服务可选配置
+type SpikeReportServiceOption func(*spikeReportService)
+
+// WithShareAttribution 报表输出各推广渠道的分享链接访问与参与数
+func WithShareAttribution(attributionRepo repo.SpikeShareAttributionRepository) SpikeReportServiceOption {
+	return func(s *spikeReportService) {
+		s.attributionRepo = attributionRepo
+	}
+}
+
+// WithReportJobQueue 报表在后台任务队列中生成，与其它耗时的管理操作共享并发上限
+func WithReportJobQueue(jobQueue AdminJobSubmitter) SpikeReportServiceOption {
+	return func(s *spikeReportService) {
+		s.jobQueue = jobQueue
+	}
+}
+
+// NewSpikeReportService 创建秒杀活动报表服务实例
+func NewSpikeReportService(
+	spikeEventRepo repo.SpikeEventRepository,
+	spikeOrderRepo repo.SpikeOrderRepository,
+	orderEventRepo repo.OrderEventRepository,
+	store storage.Storage,
+	signer *storage.URLSigner,
+	logger *zap.Logger,
+	opts ...SpikeReportServiceOption,
+) SpikeReportService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	s := &spikeReportService{
+		spikeEventRepo: spikeEventRepo,
+		spikeOrderRepo: spikeOrderRepo,
+		orderEventRepo: orderEventRepo,
+		store:          store,
+		signer:         signer,
+		logger:         logger,
+		jobs:           make(map[int64]*domain.SpikeReport),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// reportKey 返回活动报表在存储中的 key
+func reportKey(eventID int64) string {
+	return fmt.Sprintf("reports/spike_events/%d.csv", eventID)
+}
+
+// RequestReport 查询或触发报表生成
+// 业务规则：
+// 1. 只有已结束的活动才能生成报表，避免统计数据仍在变化
+// 2. 同一活动同时只有一个生成任务
+// 3. 报表文件已存在时直接返回签名下载地址
+// 4. 使用后台任务队列且队列已满时返回 domain.ErrAdminJobQueueFull
+func (s *spikeReportService) RequestReport(ctx context.Context, eventID int64, regenerate bool) (*domain.SpikeReport, error) {
+	event, err := s.spikeEventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("get spike event: %w", err)
+	}
+	if event == nil {
+		return nil, domain.ErrSpikeEventNotFound
+	}
+	if !event.IsFinished() {
+		return nil, ErrSpikeEventNotFinished
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[eventID]; ok && job.Status == domain.SpikeReportStatusGenerating {
+		return copyReport(job), nil
+	}
+
+	if !regenerate {
+		if job, ok := s.jobs[eventID]; ok && job.Status == domain.SpikeReportStatusFailed {
+			// 失败后再次请求即重试
+			regenerate = true
+			s.logger.Info("retrying failed spike report", zap.Int64("event_id", eventID), zap.String("last_error", job.Error))
+		}
+	}
+
+	if !regenerate {
+		exists, err := s.store.Exists(ctx, reportKey(eventID))
+		if err != nil {
+			return nil, fmt.Errorf("check report: %w", err)
+		}
+		if exists {
+			url, expiresAt := s.signer.Sign(reportKey(eventID))
+			return &domain.SpikeReport{
+				SpikeEventID: eventID,
+				Status:       domain.SpikeReportStatusReady,
+				URL:          url,
+				ExpiresAt:    &expiresAt,
+			}, nil
+		}
+	}
+
+	job := &domain.SpikeReport{SpikeEventID: eventID, Status: domain.SpikeReportStatusGenerating}
+	if s.jobQueue == nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), reportGenerateTimeout)
+			defer cancel()
+			_ = s.generate(ctx, event)
+		}()
+	} else {
+		adminJob, err := s.jobQueue.Submit(domain.AdminJobKindReport, fmt.Sprintf("spike_event:%d", eventID), "",
+			func(ctx context.Context, progress AdminJobProgress) (any, error) {
+				return nil, s.generate(ctx, event)
+			})
+		if err != nil {
+			return nil, err
+		}
+		job.JobID = adminJob.ID
+	}
+	s.jobs[eventID] = job
+
+	return copyReport(job), nil
+}
+
+// OpenReport 校验签名并打开报表文件
+func (s *spikeReportService) OpenReport(ctx context.Context, key, expires, sig string) (io.ReadCloser, error) {
+	key, err := s.signer.Verify(key, expires, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := s.store.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open report: %w", err)
+	}
+	return rc, nil
+}
+
+// generate 后台生成报表并写入存储，结束后更新任务状态
+func (s *spikeReportService) generate(ctx context.Context, event *domain.SpikeEvent) error {
+	start := time.Now()
+	err := s.buildAndStore(ctx, event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("failed to generate spike report", zap.Int64("event_id", event.ID), zap.Error(err))
+		failed := &domain.SpikeReport{
+			SpikeEventID: event.ID,
+			Status:       domain.SpikeReportStatusFailed,
+			Error:        err.Error(),
+		}
+		if job, ok := s.jobs[event.ID]; ok {
+			failed.JobID = job.JobID
+		}
+		s.jobs[event.ID] = failed
+		return err
+	}
+
+	delete(s.jobs, event.ID)
+	s.logger.Info("spike report generated", zap.Int64("event_id", event.ID), zap.Duration("elapsed", time.Since(start)))
+	return nil
+}
+
+// buildAndStore 查询订单与事件数据，生成 CSV 并写入存储
+func (s *spikeReportService) buildAndStore(ctx context.Context, event *domain.SpikeEvent) error {
+	orders, err := s.spikeOrderRepo.GetBySpikeEventID(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("get spike orders: %w", err)
+	}
+	events, err := s.orderEventRepo.ListBySpikeEventID(event.ID)
+	if err != nil {
+		return fmt.Errorf("get order events: %w", err)
+	}
+
+	var attributions []*domain.SpikeShareAttribution
+	if s.attributionRepo != nil {
+		attributions, err = s.attributionRepo.ListByEvent(event.ID)
+		if err != nil {
+			return fmt.Errorf("get share attributions: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeSpikeReport(&buf, event, orders, events, attributions, time.Now()); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	if err := s.store.Put(ctx, reportKey(event.ID), &buf); err != nil {
+		return fmt.Errorf("store report: %w", err)
+	}
+	return nil
+}
+
+// copyReport 复制报表状态，避免调用方持有内部指针
+func copyReport(r *domain.SpikeReport) *domain.SpikeReport {
+	c := *r
+	return &c
+}
+
+// writeSpikeReport 以分段 CSV 格式写出活动报表：
+// 概要、状态分布、营收、失败原因 Top N、按分钟的时间线、分享归因、下单渠道分布以及订单明细。
+// 各段以 "section,<名称>" 行开头、空行分隔；文件带 UTF-8 BOM 以便 Excel 正确识别中文。
+func writeSpikeReport(w io.Writer, event *domain.SpikeEvent, orders []*domain.SpikeOrder, events []*domain.OrderEvent,
+	attributions []*domain.SpikeShareAttribution, now time.Time) error {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	var rows [][]string
+	section := func(name string, header ...string) {
+		if len(rows) > 0 {
+			rows = append(rows, []string{})
+		}
+		rows = append(rows, []string{"section", name}, header)
+	}
+
+	// 概要
+	section("summary", "spike_event_id", "name", "product_id", "status", "start_at", "end_at",
+		"spike_stock", "sold_count", "spike_price", "generated_at")
+	rows = append(rows, []string{
+		strconv.FormatInt(event.ID, 10),
+		event.Name,
+		strconv.FormatInt(event.ProductID, 10),
+		string(event.Status),
+		event.StartAt.Format(reportTimeFormat),
+		event.EndAt.Format(reportTimeFormat),
+		strconv.FormatInt(event.SpikeStock, 10),
+		strconv.FormatInt(event.SoldCount, 10),
+		formatAmount(event.SpikePrice),
+		now.Format(reportTimeFormat),
+	})
+
+	// 状态分布与营收
+	type statusTotal struct {
+		orders, quantity int64
+		amount           float64
+	}
+	totals := make(map[domain.SpikeOrderStatus]*statusTotal)
+	for _, order := range orders {
+		t, ok := totals[order.Status]
+		if !ok {
+			t = &statusTotal{}
+			totals[order.Status] = t
+		}
+		t.orders++
+		t.quantity += order.Quantity
+		t.amount += order.TotalAmount
+	}
+
+	section("status_counts", "status", "orders", "quantity", "amount")
+	for _, status := range []domain.SpikeOrderStatus{
+		domain.SpikeOrderStatusPending,
+		domain.SpikeOrderStatusPaid,
+		domain.SpikeOrderStatusCancelled,
+		domain.SpikeOrderStatusExpired,
+	} {
+		t := totals[status]
+		if t == nil {
+			t = &statusTotal{}
+		}
+		rows = append(rows, []string{string(status), strconv.FormatInt(t.orders, 10),
+			strconv.FormatInt(t.quantity, 10), formatAmount(t.amount)})
+	}
+
+	paid := totals[domain.SpikeOrderStatusPaid]
+	if paid == nil {
+		paid = &statusTotal{}
+	}
+	conversion := 0.0
+	if len(orders) > 0 {
+		conversion = float64(paid.orders) / float64(len(orders)) * 100
+	}
+	section("revenue", "total_orders", "paid_orders", "paid_quantity", "revenue", "payment_conversion_pct")
+	rows = append(rows, []string{strconv.Itoa(len(orders)), strconv.FormatInt(paid.orders, 10),
+		strconv.FormatInt(paid.quantity, 10), formatAmount(paid.amount), strconv.FormatFloat(conversion, 'f', 2, 64)})
+
+	// 失败原因：取消与过期事件按备注聚合
+	type reason struct {
+		eventType domain.OrderEventType
+		remark    string
+		count     int64
+	}
+	reasonIndex := make(map[string]*reason)
+	var reasons []*reason
+	for _, e := range events {
+		if e.EventType != domain.OrderEventCancelled && e.EventType != domain.OrderEventExpired {
+			continue
+		}
+		remark := e.Remark
+		if remark == "" {
+			remark = "(none)"
+		}
+		k := string(e.EventType) + "\x00" + remark
+		r, ok := reasonIndex[k]
+		if !ok {
+			r = &reason{eventType: e.EventType, remark: remark}
+			reasonIndex[k] = r
+			reasons = append(reasons, r)
+		}
+		r.count++
+	}
+	sort.SliceStable(reasons, func(i, j int) bool { return reasons[i].count > reasons[j].count })
+	if len(reasons) > reportTopReasons {
+		reasons = reasons[:reportTopReasons]
+	}
+
+	section("top_failure_reasons", "event_type", "reason", "count")
+	for _, r := range reasons {
+		rows = append(rows, []string{string(r.eventType), r.remark, strconv.FormatInt(r.count, 10)})
+	}
+
+	// 时间线：按分钟统计各类订单事件
+	timelineTypes := []domain.OrderEventType{
+		domain.OrderEventCreated,
+		domain.OrderEventPaid,
+		domain.OrderEventCancelled,
+		domain.OrderEventExpired,
+	}
+	buckets := make(map[time.Time]map[domain.OrderEventType]int64)
+	var minutes []time.Time
+	for _, e := range events {
+		minute := e.CreatedAt.Truncate(time.Minute)
+		b, ok := buckets[minute]
+		if !ok {
+			b = make(map[domain.OrderEventType]int64)
+			buckets[minute] = b
+			minutes = append(minutes, minute)
+		}
+		b[e.EventType]++
+	}
+	sort.Slice(minutes, func(i, j int) bool { return minutes[i].Before(minutes[j]) })
+
+	header := []string{"minute"}
+	for _, t := range timelineTypes {
+		header = append(header, string(t))
+	}
+	section("timeline", header...)
+	for _, minute := range minutes {
+		row := []string{minute.Format(reportTimeFormat)}
+		for _, t := range timelineTypes {
+			row = append(row, strconv.FormatInt(buckets[minute][t], 10))
+		}
+		rows = append(rows, row)
+	}
+
+	// 分享归因：按推广渠道统计分享链接的访问与参与
+	section("share_attribution", "campaign", "visits", "participants")
+	for _, a := range attributions {
+		rows = append(rows, []string{a.Campaign, strconv.FormatInt(a.Visits, 10), strconv.FormatInt(a.Participants, 10)})
+	}
+
+	// 下单渠道：历史订单未记录渠道，计入 (unknown)
+	type channelTotal struct {
+		orders, paidOrders int64
+		revenue            float64
+	}
+	channelTotals := make(map[string]*channelTotal)
+	var channels []string
+	for _, order := range orders {
+		channel := string(order.Channel)
+		if channel == "" {
+			channel = "(unknown)"
+		}
+		t, ok := channelTotals[channel]
+		if !ok {
+			t = &channelTotal{}
+			channelTotals[channel] = t
+			channels = append(channels, channel)
+		}
+		t.orders++
+		if order.Status == domain.SpikeOrderStatusPaid {
+			t.paidOrders++
+			t.revenue += order.TotalAmount
+		}
+	}
+	sort.Strings(channels)
+
+	section("channels", "channel", "orders", "paid_orders", "revenue")
+	for _, channel := range channels {
+		t := channelTotals[channel]
+		rows = append(rows, []string{channel, strconv.FormatInt(t.orders, 10),
+			strconv.FormatInt(t.paidOrders, 10), formatAmount(t.revenue)})
+	}
+
+	// 订单明细
+	section("orders", "id", "user_id", "quantity", "spike_price", "total_amount", "status",
+		"created_at", "paid_at", "cancelled_at", "channel", "client_ip", "user_agent")
+	for _, order := range orders {
+		rows = append(rows, []string{
+			strconv.FormatInt(order.ID, 10),
+			strconv.FormatInt(order.UserID, 10),
+			strconv.FormatInt(order.Quantity, 10),
+			formatAmount(order.SpikePrice),
+			formatAmount(order.TotalAmount),
+			string(order.Status),
+			order.CreatedAt.Format(reportTimeFormat),
+			formatOptionalTime(order.PaidAt),
+			formatOptionalTime(order.CancelledAt),
+			string(order.Channel),
+			order.ClientIP,
+			order.UserAgent,
+		})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(reportTimeFormat)
+}
@@ -0,0 +1,140 @@
+// Package service 提供活动进行中补充库存的服务。
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mq"
+)
+
+// SpikeRestockService 定义活动补货服务接口
+type SpikeRestockService interface {
+	// TopUpStock 为未结束的活动增加库存：数据库总库存、Redis 剩余库存同时增加，清除售罄标记并广播补货通知
+	TopUpStock(ctx context.Context, eventID int64, req *domain.SpikeStockTopUpRequest, operatorID int64) (*domain.SpikeStockTopUpResult, error)
+}
+
+// RestockEventStore 补货所需的活动读写操作（由 repo.SpikeEventRepository 实现）
+type RestockEventStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	IncreaseStock(ctx context.Context, id int64, delta int64) error
+}
+
+// RestockCache 补货所需的缓存操作（由 cache.SpikeCache 实现）
+type RestockCache interface {
+	TopUpStock(ctx context.Context, eventID, delta int64) (int64, error)
+	CacheEventInfo(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error
+	AcquireRewarmLock(ctx context.Context, eventID int64, owner string, ttl time.Duration) (bool, error)
+	ReleaseRewarmLock(ctx context.Context, eventID int64, owner string) error
+}
+
+// spikeRestockService 是SpikeRestockService接口的实现
+type spikeRestockService struct {
+	events   RestockEventStore
+	cache    RestockCache
+	notifier mq.NotificationPublisher
+	lockTTL  time.Duration
+	cacheTTL time.Duration
+	logger   *zap.Logger
+}
+
+// NewSpikeRestockService 创建活动补货服务，notifier 为空时不广播补货通知
+func NewSpikeRestockService(events RestockEventStore, stockCache RestockCache, notifier mq.NotificationPublisher, cacheTTL time.Duration, logger *zap.Logger) SpikeRestockService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeRestockService{
+		events:   events,
+		cache:    stockCache,
+		notifier: notifier,
+		lockTTL:  30 * time.Second,
+		cacheTTL: cacheTTL,
+		logger:   logger,
+	}
+}
+
+// TopUpStock 补充库存。
+// 与库存守护共用恢复锁，避免守护按旧的总库存重建库存键覆盖本次补货；
+// Redis 侧的增加在 Lua 脚本中执行，与并发的预减库存互斥。
+func (s *spikeRestockService) TopUpStock(ctx context.Context, eventID int64, req *domain.SpikeStockTopUpRequest, operatorID int64) (*domain.SpikeStockTopUpResult, error) {
+	if req.Delta <= 0 {
+		return nil, fmt.Errorf("invalid stock delta: %d", req.Delta)
+	}
+
+	owner := uuid.New().String()
+	acquired, err := s.cache.AcquireRewarmLock(ctx, eventID, owner, s.lockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, domain.ErrSpikeStockBusy
+	}
+	defer func() {
+		if err := s.cache.ReleaseRewarmLock(context.Background(), eventID, owner); err != nil {
+			s.logger.Warn("释放库存恢复锁失败", zap.Int64("event_id", eventID), zap.Error(err))
+		}
+	}()
+
+	if err := s.events.IncreaseStock(ctx, eventID, req.Delta); err != nil {
+		return nil, err
+	}
+
+	remaining, err := s.cache.TopUpStock(ctx, eventID, req.Delta)
+	if err != nil {
+		// 数据库已生效，重新预热即可按数据库修正 Redis 库存
+		return nil, fmt.Errorf("stock increased in database but failed to update cache, rerun warmup: %w", err)
+	}
+
+	event, err := s.events.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spike event: %w", err)
+	}
+	if err := s.cache.CacheEventInfo(ctx, eventID, event, s.cacheTTL); err != nil {
+		s.logger.Warn("刷新活动缓存失败", zap.Int64("event_id", eventID), zap.Error(err))
+	}
+
+	s.logger.Info("活动库存已补充",
+		zap.Int64("event_id", eventID),
+		zap.Int64("operator_id", operatorID),
+		zap.Int64("delta", req.Delta),
+		zap.Int64("spike_stock", event.SpikeStock),
+		zap.Int64("remaining_stock", remaining),
+		zap.String("reason", req.Reason))
+
+	s.broadcastRestock(ctx, event, req.Delta, remaining)
+
+	return &domain.SpikeStockTopUpResult{
+		SpikeEventID:   eventID,
+		Delta:          req.Delta,
+		SpikeStock:     event.SpikeStock,
+		RemainingStock: remaining,
+	}, nil
+}
+
+// broadcastRestock 广播补货通知（UserID 为 0 表示面向全部关注该活动的用户），失败只记录日志
+func (s *spikeRestockService) broadcastRestock(ctx context.Context, event *domain.SpikeEvent, delta, remaining int64) {
+	if s.notifier == nil {
+		return
+	}
+	err := s.notifier.PublishNotification(ctx, &mq.NotificationData{
+		Type:    "spike_restock",
+		Title:   "秒杀活动补货啦",
+		Content: fmt.Sprintf("%s 新增 %d 件库存，快来抢购", event.Name, delta),
+		Data: map[string]interface{}{
+			"spike_event_id":  event.ID,
+			"delta":           delta,
+			"spike_stock":     event.SpikeStock,
+			"remaining_stock": remaining,
+		},
+		Priority: "high",
+		Channels: []string{"push"},
+	}, "")
+	if err != nil {
+		s.logger.Warn("广播补货通知失败", zap.Int64("event_id", event.ID), zap.Error(err))
+	}
+}
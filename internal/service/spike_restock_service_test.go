@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeRestockCache 在内存中模拟库存键、售罄标记与恢复锁
+type fakeRestockCache struct {
+	mu      sync.Mutex
+	stock   map[int64]int64
+	soldOut map[int64]bool
+	locks   map[int64]string
+	events  map[int64]*domain.SpikeEvent
+}
+
+func newFakeRestockCache() *fakeRestockCache {
+	return &fakeRestockCache{
+		stock:   make(map[int64]int64),
+		soldOut: make(map[int64]bool),
+		locks:   make(map[int64]string),
+		events:  make(map[int64]*domain.SpikeEvent),
+	}
+}
+
+func (f *fakeRestockCache) TopUpStock(ctx context.Context, eventID, delta int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stock, ok := f.stock[eventID]
+	if !ok {
+		return -1, nil
+	}
+	f.stock[eventID] = stock + delta
+	delete(f.soldOut, eventID)
+	return stock + delta, nil
+}
+
+func (f *fakeRestockCache) CacheEventInfo(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[eventID] = eventData.(*domain.SpikeEvent)
+	return nil
+}
+
+func (f *fakeRestockCache) AcquireRewarmLock(ctx context.Context, eventID int64, owner string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, held := f.locks[eventID]; held {
+		return false, nil
+	}
+	f.locks[eventID] = owner
+	return true, nil
+}
+
+func (f *fakeRestockCache) ReleaseRewarmLock(ctx context.Context, eventID int64, owner string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.locks[eventID] == owner {
+		delete(f.locks, eventID)
+	}
+	return nil
+}
+
+func TestSpikeRestockService_TopUpStock(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	event := testutil.NewSpikeEventBuilder().Active().WithStock(100).WithSold(100).Build()
+	testutil.SeedSpikeEvents(t, events, event)
+
+	stockCache := newFakeRestockCache()
+	stockCache.stock[event.ID] = 0
+	stockCache.soldOut[event.ID] = true
+	notifier := &fakeNotificationPublisher{}
+	svc := NewSpikeRestockService(events, stockCache, notifier, time.Hour, nil)
+
+	result, err := svc.TopUpStock(context.Background(), event.ID, &domain.SpikeStockTopUpRequest{Delta: 50}, 1)
+	if err != nil {
+		t.Fatalf("TopUpStock() error = %v", err)
+	}
+	if result.SpikeStock != 150 || result.RemainingStock != 50 {
+		t.Errorf("result = %+v, want spike_stock 150 and remaining_stock 50", result)
+	}
+	if stockCache.soldOut[event.ID] {
+		t.Error("sold out flag not cleared")
+	}
+	if len(stockCache.locks) != 0 {
+		t.Error("rewarm lock not released")
+	}
+	if cached := stockCache.events[event.ID]; cached == nil || cached.SpikeStock != 150 {
+		t.Errorf("cached event = %+v, want spike_stock 150", cached)
+	}
+	if len(notifier.notifications) != 1 || notifier.notifications[0].Type != "spike_restock" {
+		t.Errorf("notifications = %+v, want one spike_restock broadcast", notifier.notifications)
+	}
+}
+
+func TestSpikeRestockService_TopUpStock_NotWarmed(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	event := testutil.NewSpikeEventBuilder().Pending().WithStock(100).Build()
+	testutil.SeedSpikeEvents(t, events, event)
+
+	svc := NewSpikeRestockService(events, newFakeRestockCache(), nil, time.Hour, nil)
+	result, err := svc.TopUpStock(context.Background(), event.ID, &domain.SpikeStockTopUpRequest{Delta: 20}, 1)
+	if err != nil {
+		t.Fatalf("TopUpStock() error = %v", err)
+	}
+	if result.SpikeStock != 120 || result.RemainingStock != -1 {
+		t.Errorf("result = %+v, want spike_stock 120 and remaining_stock -1", result)
+	}
+}
+
+func TestSpikeRestockService_TopUpStock_Rejected(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	ended := testutil.NewSpikeEventBuilder().Ended().WithStock(100).Build()
+	active := testutil.NewSpikeEventBuilder().Active().WithStock(100).Build()
+	testutil.SeedSpikeEvents(t, events, ended, active)
+
+	stockCache := newFakeRestockCache()
+	svc := NewSpikeRestockService(events, stockCache, nil, time.Hour, nil)
+	req := &domain.SpikeStockTopUpRequest{Delta: 10}
+
+	if _, err := svc.TopUpStock(context.Background(), ended.ID, req, 1); !errors.Is(err, domain.ErrSpikeEventNotRestockable) {
+		t.Errorf("ended event: err = %v, want ErrSpikeEventNotRestockable", err)
+	}
+	if _, err := svc.TopUpStock(context.Background(), 999, req, 1); !errors.Is(err, domain.ErrSpikeEventNotFound) {
+		t.Errorf("missing event: err = %v, want ErrSpikeEventNotFound", err)
+	}
+
+	// 库存守护持有恢复锁时拒绝补货，且不修改总库存
+	stockCache.locks[active.ID] = "guardian"
+	if _, err := svc.TopUpStock(context.Background(), active.ID, req, 1); !errors.Is(err, domain.ErrSpikeStockBusy) {
+		t.Errorf("locked event: err = %v, want ErrSpikeStockBusy", err)
+	}
+	if got, _ := events.GetByID(context.Background(), active.ID); got.SpikeStock != 100 {
+		t.Errorf("spike_stock = %d after rejected top up, want 100", got.SpikeStock)
+	}
+}
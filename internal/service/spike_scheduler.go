@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// EventWarmer 负责预热单个秒杀活动的缓存
+type EventWarmer interface {
+	WarmupEvent(ctx context.Context, event *domain.SpikeEvent) error
+}
+
+// PreviewEventSource 提供预告期活动列表，通常由 repo.SpikeEventRepository 实现
+type PreviewEventSource interface {
+	GetPreviewEvents(ctx context.Context, now time.Time) ([]*domain.SpikeEvent, error)
+}
+
+// SpikeScheduler 定时扫描进入预告期的秒杀活动，并在预告开始时预热库存与活动缓存，
+// 避免活动开始瞬间大量请求击穿到数据库
+type SpikeScheduler struct {
+	eventRepo PreviewEventSource
+	warmer    EventWarmer
+	interval  time.Duration
+	logger    *zap.Logger
+
+	// warmed 记录已预热的活动及其开始时间，活动改期后会重新预热
+	mu     sync.Mutex
+	warmed map[int64]time.Time
+
+	// 进行中活动库存键续期，可为空
+	ttlRefresher       *StockTTLRefresher
+	ttlRefreshInterval time.Duration
+	lastTTLRefresh     time.Time
+}
+
+// NewSpikeScheduler 创建秒杀活动调度器
+func NewSpikeScheduler(eventRepo PreviewEventSource, warmer EventWarmer, interval time.Duration, logger *zap.Logger) *SpikeScheduler {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &SpikeScheduler{
+		eventRepo: eventRepo,
+		warmer:    warmer,
+		interval:  interval,
+		logger:    logger,
+		warmed:    make(map[int64]time.Time),
+	}
+}
+
+// Start 异步启动调度循环，ctx 取消时退出
+func (s *SpikeScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.logger.Info("秒杀活动调度器已启动", zap.Duration("interval", s.interval))
+		for {
+			if err := s.RunOnce(ctx); err != nil {
+				s.logger.Warn("扫描预告期活动失败", zap.Error(err))
+			}
+			s.refreshStockTTL(ctx)
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info("秒杀活动调度器已停止")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SetStockTTLRefresher 设置进行中活动的库存键续期器，interval 为续期检查间隔，不大于调度间隔时每轮都检查
+func (s *SpikeScheduler) SetStockTTLRefresher(refresher *StockTTLRefresher, interval time.Duration) {
+	s.ttlRefresher = refresher
+	s.ttlRefreshInterval = interval
+}
+
+// refreshStockTTL 按续期间隔检查进行中活动的库存键 TTL
+func (s *SpikeScheduler) refreshStockTTL(ctx context.Context) {
+	if s.ttlRefresher == nil {
+		return
+	}
+	now := time.Now()
+	if !s.lastTTLRefresh.IsZero() && now.Sub(s.lastTTLRefresh) < s.ttlRefreshInterval {
+		return
+	}
+	s.lastTTLRefresh = now
+
+	if err := s.ttlRefresher.RefreshOnce(ctx); err != nil {
+		s.logger.Warn("续期进行中活动库存键失败", zap.Error(err))
+	}
+}
+
+// RunOnce 执行一次扫描：预热新进入预告期的活动，并清理已开始活动的预热记录
+func (s *SpikeScheduler) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	events, err := s.eventRepo.GetPreviewEvents(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to get preview events: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inPreview := make(map[int64]struct{}, len(events))
+	for _, event := range events {
+		inPreview[event.ID] = struct{}{}
+		if startAt, ok := s.warmed[event.ID]; ok && startAt.Equal(event.StartAt) {
+			continue
+		}
+
+		if err := s.warmer.WarmupEvent(ctx, event); err != nil {
+			// 不记录预热状态，下一轮继续重试
+			s.logger.Warn("预告期活动预热失败", zap.Int64("event_id", event.ID), zap.Error(err))
+			continue
+		}
+		s.warmed[event.ID] = event.StartAt
+		s.logger.Info("预告期活动预热完成",
+			zap.Int64("event_id", event.ID),
+			zap.Time("start_at", event.StartAt))
+	}
+
+	// 已离开预告期（开始、取消或改期）的活动不再需要记录
+	for id := range s.warmed {
+		if _, ok := inPreview[id]; !ok {
+			delete(s.warmed, id)
+		}
+	}
+
+	return nil
+}
@@ -3,19 +3,48 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/MorseWayne/spike_shop/internal/breaker"
 	"github.com/MorseWayne/spike_shop/internal/cache"
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/journal"
+	"github.com/MorseWayne/spike_shop/internal/keys"
 	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
 	"github.com/MorseWayne/spike_shop/internal/mq"
 	"github.com/MorseWayne/spike_shop/internal/repo"
+	"github.com/MorseWayne/spike_shop/internal/tracing"
 )
 
+//go:generate moq -rm -out spike_stock_cache_moq_test.go . SpikeStockCache
+
+// SpikeStockCache 秒杀服务所需的库存与活动缓存操作（由 cache.SpikeCache 实现）
+type SpikeStockCache interface {
+	GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+	DecrementStock(ctx context.Context, eventID, userID, quantity, maxPerUser int64, userTTL, soldOutTTL time.Duration) (*cache.DecrementStockResult, error)
+	DecrementStockBuckets(ctx context.Context, eventID, userID, quantity, maxPerUser int64, buckets int, userTTL, soldOutTTL time.Duration) (*cache.DecrementStockResult, error)
+	RestoreStock(ctx context.Context, eventID, userID, quantity int64) (int64, error)
+	WarmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error
+	WarmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error
+	CacheEventInfo(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error
+	GetEventInfo(ctx context.Context, eventID int64, dest interface{}) error
+	ReserveParticipationToken(ctx context.Context, eventID, userID, maxTokens int64, ttl time.Duration) (bool, error)
+}
+
+// SpikeOutbox 写入需要可靠投递的消息并唤醒发布（由 OutboxRelay 实现）
+type SpikeOutbox interface {
+	Enqueue(ctx context.Context, message *domain.OutboxMessage) error
+	Notify()
+}
+
 // SpikeService 秒杀服务
 type SpikeService struct {
 	// 仓储层
@@ -24,28 +53,84 @@ type SpikeService struct {
 	productRepo    repo.ProductRepository
 	inventoryRepo  repo.InventoryRepository
 	userRepo       repo.UserRepository
+	orderEventRepo repo.OrderEventRepository
 
 	// 缓存层
-	spikeCache *cache.SpikeCache
+	spikeCache SpikeStockCache
 
 	// 消息队列
-	spikeProducer *mq.SpikeProducer
+	spikeProducer mq.SpikePublisher
+
+	// 消息发件箱，可为空，为空时直接发布订单创建与取消消息
+	outbox SpikeOutbox
 
 	// 限流器
 	globalLimiter limiter.Limiter
 	userLimiter   limiter.Limiter
+	tierLimiters  map[domain.UserTier]limiter.Limiter // 按用户等级区分的单用户限流器，可为空
+	eventLimiter  EventRateLimiter                    // 活动级限流器，可为空
+
+	// 库存守护，可为空
+	stockGuardian *StockGuardian
+
+	// 库存不变量检查，可为空
+	invariants *StockInvariantChecker
+
+	// 专场跨活动限购，可为空
+	campaignQuota *CampaignQuota
+
+	// 单用户每日秒杀成功次数上限，可为空
+	dailyQuota *DailyQuota
+
+	// 商品停售标记，可为空
+	stopSell StopSellChecker
+
+	// 参与日志，可为空
+	journal journal.Writer
+
+	// 用户缓存，可为空，为空时直接读取 userRepo
+	userCache *UserCache
+
+	// 参与令牌，tokenSigner 为空表示未启用
+	tokenSigner          *ParticipationTokenSigner
+	tokenLimiter         limiter.Limiter
+	tokenIssueMultiplier int64
+
+	// 防刷挑战，challengeStore 为空表示未启用
+	challengeStore      SpikeChallengeStore
+	challengeLimiter    limiter.Limiter
+	challengeDifficulty int
+	challengeTTL        time.Duration
+
+	// 等候室，可为空，为空时不启用排队模式
+	waitingRoom WaitingRoomStore
+
+	// 分享链接归因，可为空
+	shareAttribution ShareParticipationRecorder
+
+	// 订单号生成器，可为空，为空时使用 idgen.Default()
+	orderNos OrderNoGenerator
+
+	// 只读副本复制延迟，可为空，为空时统计信息不标注数据来源
+	replicationLag ReplicationLagReporter
+
+	// 库存缓存熔断器，可为空，为空时缓存失败直接返回系统繁忙；degraded 随熔断器一起设置
+	cacheBreaker *breaker.Breaker
+	degraded     *degradedState
 
 	// 日志
 	logger *zap.Logger
 
-	// 配置
-	config *SpikeServiceConfig
+	// 配置，热更新时整体替换，读取使用 cfg()
+	config atomic.Pointer[SpikeServiceConfig]
 }
 
 // SpikeServiceConfig 秒杀服务配置
 type SpikeServiceConfig struct {
 	// 订单过期时间
 	OrderExpireTime time.Duration `json:"order_expire_time"`
+	// 用户可申请一次延长支付时间的时长，0 表示不允许延长
+	OrderExtension time.Duration `json:"order_extension"`
 
 	// 限流配置
 	GlobalRateLimit int64         `json:"global_rate_limit"`
@@ -56,30 +141,53 @@ type SpikeServiceConfig struct {
 	StockWarmupEnabled bool          `json:"stock_warmup_enabled"`
 	StockWarmupTime    time.Duration `json:"stock_warmup_time"`
 
-	// 缓存配置
+	// 缓存配置：库存与活动缓存键的过期时间按活动结束时间 + StockTTLBuffer 计算，且不短于 StockCacheTTL
 	StockCacheTTL  time.Duration `json:"stock_cache_ttl"`
+	StockTTLBuffer time.Duration `json:"stock_ttl_buffer"`
 	UserMarkTTL    time.Duration `json:"user_mark_ttl"`
 	IdempotencyTTL time.Duration `json:"idempotency_ttl"`
 
 	// 重试配置
 	MaxRetryAttempts int           `json:"max_retry_attempts"`
 	RetryInterval    time.Duration `json:"retry_interval"`
+
+	// 用户等级权益
+	TierPolicies map[domain.UserTier]TierPolicy `json:"tier_policies"`
+
+	// 预告期活动扫描间隔
+	PreviewScanInterval time.Duration `json:"preview_scan_interval"`
+
+	// 订单所有权校验策略（访问他人订单返回 403 还是 404）
+	Ownership OwnershipPolicy `json:"ownership"`
+
+	// 订单详情使用单次 JOIN 查询；默认先查订单再并发查询活动与用户（可命中用户缓存）
+	OrderDetailJoin bool `json:"order_detail_join"`
+
+	// 库存缓存熔断后的降级策略（DegradedModeOff/Queue/DB），及 db 模式下单实例的数据库扣减并发上限
+	DegradedMode           string `json:"degraded_mode"`
+	DegradedMaxConcurrency int    `json:"degraded_max_concurrency"`
 }
 
 // DefaultSpikeServiceConfig 默认配置
 func DefaultSpikeServiceConfig() *SpikeServiceConfig {
 	return &SpikeServiceConfig{
-		OrderExpireTime:    30 * time.Minute,
-		GlobalRateLimit:    1000,
-		UserRateLimit:      5,
-		RateLimitWindow:    time.Minute,
-		StockWarmupEnabled: true,
-		StockWarmupTime:    5 * time.Minute,
-		StockCacheTTL:      2 * time.Hour,
-		UserMarkTTL:        24 * time.Hour,
-		IdempotencyTTL:     24 * time.Hour,
-		MaxRetryAttempts:   3,
-		RetryInterval:      time.Second,
+		OrderExpireTime:        30 * time.Minute,
+		OrderExtension:         10 * time.Minute,
+		GlobalRateLimit:        1000,
+		UserRateLimit:          5,
+		RateLimitWindow:        time.Minute,
+		StockWarmupEnabled:     true,
+		StockWarmupTime:        5 * time.Minute,
+		StockCacheTTL:          2 * time.Hour,
+		StockTTLBuffer:         30 * time.Minute,
+		UserMarkTTL:            24 * time.Hour,
+		IdempotencyTTL:         24 * time.Hour,
+		MaxRetryAttempts:       3,
+		RetryInterval:          time.Second,
+		TierPolicies:           DefaultTierPolicies(),
+		PreviewScanInterval:    10 * time.Second,
+		DegradedMode:           DegradedModeQueue,
+		DegradedMaxConcurrency: 8,
 	}
 }
 
@@ -90,8 +198,9 @@ func NewSpikeService(
 	productRepo repo.ProductRepository,
 	inventoryRepo repo.InventoryRepository,
 	userRepo repo.UserRepository,
-	spikeCache *cache.SpikeCache,
-	spikeProducer *mq.SpikeProducer,
+	orderEventRepo repo.OrderEventRepository,
+	spikeCache SpikeStockCache,
+	spikeProducer mq.SpikePublisher,
 	globalLimiter limiter.Limiter,
 	userLimiter limiter.Limiter,
 	config *SpikeServiceConfig,
@@ -104,124 +213,302 @@ func NewSpikeService(
 		logger = zap.NewNop()
 	}
 
-	return &SpikeService{
+	s := &SpikeService{
 		spikeEventRepo: spikeEventRepo,
 		spikeOrderRepo: spikeOrderRepo,
 		productRepo:    productRepo,
 		inventoryRepo:  inventoryRepo,
 		userRepo:       userRepo,
+		orderEventRepo: orderEventRepo,
 		spikeCache:     spikeCache,
 		spikeProducer:  spikeProducer,
 		globalLimiter:  globalLimiter,
 		userLimiter:    userLimiter,
-		config:         config,
 		logger:         logger,
 	}
+	s.config.Store(config)
+	return s
 }
 
-// ParticipateSpike 参与秒杀
+// cfg 返回当前生效的配置
+func (s *SpikeService) cfg() *SpikeServiceConfig {
+	return s.config.Load()
+}
+
+// UpdateCacheTTLs 热更新库存缓存与用户参与标记的过期时间，只影响之后写入的缓存键
+func (s *SpikeService) UpdateCacheTTLs(stockCacheTTL, userMarkTTL time.Duration) {
+	next := *s.cfg()
+	next.StockCacheTTL = stockCacheTTL
+	next.UserMarkTTL = userMarkTTL
+	s.config.Store(&next)
+}
+
+// ParticipateSpike 参与秒杀，并按结果记录参与指标
 func (s *SpikeService) ParticipateSpike(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
-	// 生成追踪ID
-	traceID := uuid.New().String()
+	response, err := s.participateSpike(ctx, req, userID)
+	metrics.SpikeParticipations.Inc(participationOutcome(response, err))
+	return response, err
+}
+
+// participationOutcome 把参与结果归并为指标标签，避免原因码过多
+func participationOutcome(response *domain.SpikeParticipationResponse, err error) string {
+	switch {
+	case err != nil || response == nil:
+		return metrics.OutcomeError
+	case response.Success:
+		return metrics.OutcomeSuccess
+	}
+	switch response.Code {
+	case domain.SpikeParticipationCodeSoldOut, domain.SpikeParticipationCodeInsufficientStock:
+		return metrics.OutcomeSoldOut
+	case domain.SpikeParticipationCodeRateLimited:
+		return metrics.OutcomeRateLimited
+	case domain.SpikeParticipationCodeQueued, domain.SpikeParticipationCodeBusy:
+		return metrics.OutcomeQueued
+	case domain.SpikeParticipationCodeInternalError:
+		return metrics.OutcomeError
+	default:
+		return metrics.OutcomeRejected
+	}
+}
+
+func (s *SpikeService) participateSpike(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64) (*domain.SpikeParticipationResponse, error) {
+	// 沿用入站请求的追踪ID，不存在时生成
+	ctx, traceID := tracing.EnsureTraceID(ctx)
 	logger := s.logger.With(
 		zap.String("trace_id", traceID),
-		zap.Int64("user_id", userID),
+		logger.UserID(userID),
 		zap.Int64("spike_event_id", req.SpikeEventID),
 		zap.Int64("quantity", req.Quantity),
-		zap.String("idempotency_key", req.IdempotencyKey),
+		logger.IdempotencyKey(req.IdempotencyKey),
 	)
 
-	logger.Info("开始处理秒杀请求")
+	policy := s.tierPolicy(req.UserTier)
+
+	logger.Info("开始处理秒杀请求", zap.String("user_tier", string(req.UserTier.OrDefault())))
+
+	// 库存缓存熔断期间 Redis 不可用：跳过限流与活动缓存，由降级并发上限控制压力
+	cacheDown := s.cacheOpen()
 
 	// 1. 限流检查
-	if err := s.checkRateLimit(ctx, userID); err != nil {
-		logger.Warn("限流检查失败", zap.Error(err))
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "请求过于频繁，请稍后重试",
-		}, nil
+	if !cacheDown {
+		if retryAfter, err := s.checkRateLimit(ctx, userID, req.UserTier); err != nil {
+			logger.Warn("限流检查失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeRateLimited,
+				"请求过于频繁，请稍后重试").WithRetryAfter(retryAfter), nil
+		}
 	}
 
 	// 2. 参数验证
 	if err := s.validateSpikeRequest(req, userID); err != nil {
 		logger.Warn("参数验证失败", zap.Error(err))
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: err.Error(),
-		}, nil
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInvalidRequest, err.Error()), nil
 	}
 
 	// 3. 获取秒杀活动信息
 	spikeEvent, err := s.getSpikeEventWithCache(ctx, req.SpikeEventID)
 	if err != nil {
 		logger.Error("获取秒杀活动失败", zap.Error(err))
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "秒杀活动不存在或已结束",
-		}, nil
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeEventUnavailable, "秒杀活动不存在或已结束"), nil
 	}
 
-	// 4. 检查活动状态
-	if !spikeEvent.IsActive() {
+	// 4. 检查活动状态（高等级用户可在开始前提前参与），未发布的活动按不存在处理
+	if spikeEvent.IsUnpublished() {
+		logger.Info("秒杀活动未发布")
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeEventUnavailable, "秒杀活动不存在或已结束"), nil
+	}
+	if spikeEvent.Status == domain.SpikeEventStatusPaused {
+		logger.Info("秒杀活动已暂停")
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeEventUnavailable, "秒杀活动已暂停"), nil
+	}
+	if secondsToStart := spikeEvent.SecondsToStart(policy.EarlyAccess); secondsToStart > 0 &&
+		spikeEvent.Status != domain.SpikeEventStatusEnded && spikeEvent.Status != domain.SpikeEventStatusCancelled {
+		logger.Info("秒杀活动未开始", zap.Int64("seconds_to_start", secondsToStart))
+		response := domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeNotStarted, "秒杀活动未开始").
+			WithRetryAfter(time.Duration(secondsToStart) * time.Second)
+		response.SecondsToStart = secondsToStart
+		return response, nil
+	}
+	if !spikeEvent.IsOpenFor(policy.EarlyAccess) {
 		logger.Warn("秒杀活动未开始或已结束")
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "秒杀活动未开始或已结束",
-		}, nil
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeEventUnavailable, "秒杀活动未开始或已结束"), nil
 	}
 
-	// 5. 检查库存和售罄标记
-	stockInfo, err := s.spikeCache.GetStockInfo(ctx, req.SpikeEventID)
-	if err != nil {
+	// 活动级限购与限流，配置随活动信息在预热时写入缓存
+	if limit := spikeEvent.PurchaseLimit(); req.Quantity > limit {
+		logger.Info("超过活动单用户购买上限", zap.Int64("purchase_limit", limit))
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodePurchaseLimit,
+			fmt.Sprintf("本活动每人最多购买%d件", limit)), nil
+	}
+	if !cacheDown {
+		if retryAfter, err := s.checkEventRateLimit(ctx, spikeEvent); err != nil {
+			logger.Warn("活动限流检查失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeRateLimited,
+				"活动太火爆，请稍后重试").WithRetryAfter(retryAfter), nil
+		}
+	}
+
+	// 商品停售时直接拒绝；标记读取失败时放行，由活动冻结兜底
+	if s.productStopped(ctx, spikeEvent.ProductID, logger) {
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeStopSell, "商品已暂停销售"), nil
+	}
+
+	// 开启参与令牌的活动只处理携带有效令牌的请求
+	if s.tokenRequired(spikeEvent) {
+		if err := s.tokenSigner.Verify(req.ParticipationToken, req.SpikeEventID, userID); err != nil {
+			logger.Info("参与令牌校验失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeTokenRequired, err.Error()), nil
+		}
+	}
+
+	if cacheDown {
+		return s.participateDegraded(ctx, req, userID, spikeEvent, policy, traceID, logger)
+	}
+
+	// 排队模式的活动携带排队令牌再次参与：未放行时返回当前位置；已放行的令牌说明入队前已通过防刷挑战，不再重复校验
+	admitted := false
+	if s.waitingRoomEnabled(spikeEvent) && req.QueueToken != "" {
+		ticket, err := s.waitingRoomTicket(ctx, spikeEvent.ID, req.QueueToken, userID)
+		if err != nil {
+			logger.Error("查询排队令牌失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+		}
+		if ticket != nil && ticket.Position > 0 {
+			return queuedResponse(req.QueueToken, ticket.Position, spikeEvent.WaitingRoomRate), nil
+		}
+		admitted = ticket != nil
+	}
+
+	// 要求防刷挑战的活动需携带已完成的挑战令牌，挑战无论校验是否通过都只能使用一次
+	if !admitted && s.challengeRequired(spikeEvent) {
+		if err := s.verifyChallenge(ctx, req.ChallengeToken, req.SpikeEventID, userID); err != nil {
+			if errors.Is(err, ErrChallengeRequired) || errors.Is(err, ErrChallengeInvalid) {
+				logger.Info("防刷挑战校验失败", zap.Error(err))
+				return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeChallengeRequired, err.Error()), nil
+			}
+			logger.Error("防刷挑战校验异常", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+		}
+	}
+
+	// 排队模式的活动：未放行的请求进入等候室，不触达库存与消息队列
+	if s.waitingRoomEnabled(spikeEvent) && !admitted {
+		response, err := s.enterWaitingRoom(ctx, spikeEvent, userID)
+		if err != nil {
+			logger.Error("进入等候室失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+		}
+		logger.Info("进入等候室排队", zap.Int64("position", response.QueueLength))
+		return response, nil
+	}
+
+	// 5. 检查库存和售罄标记，缓存失败或熔断时降级参与
+	var stockInfo *cache.StockInfo
+	if err := s.guardCache(func() (err error) {
+		stockInfo, err = s.spikeCache.GetStockInfo(ctx, req.SpikeEventID)
+		return err
+	}); err != nil {
 		logger.Error("获取库存信息失败", zap.Error(err))
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "系统繁忙，请稍后重试",
-		}, nil
+		if s.cacheBreaker != nil {
+			return s.participateDegraded(ctx, req, userID, spikeEvent, policy, traceID, logger)
+		}
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
 	}
 
 	if stockInfo.SoldOut {
 		logger.Info("商品已售罄")
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "商品已售罄",
-		}, nil
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeSoldOut, "商品已售罄"), nil
 	}
 
-	// 6. Redis原子性预减库存
-	result, err := s.spikeCache.DecrementStock(ctx, req.SpikeEventID, userID, req.Quantity,
-		s.config.UserMarkTTL, s.config.StockCacheTTL)
+	// 6. 专场跨活动限购：在预减库存前占用专场购买次数，预减失败或消息发送失败时归还
+	quotaReserved := false
+	if s.campaignQuota != nil {
+		reserved, allowed, err := s.campaignQuota.Reserve(ctx, spikeEvent, userID)
+		if err != nil {
+			logger.Error("占用专场购买次数失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+		}
+		if !allowed {
+			logger.Info("已达到专场购买次数上限")
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeCampaignQuota, "已达到本专场的购买次数上限"), nil
+		}
+		quotaReserved = reserved
+	}
+	dailyReserved := false
+	participatedAt := time.Now()
+	releaseQuota := func() {
+		if quotaReserved {
+			s.campaignQuota.Release(ctx, spikeEvent, userID)
+		}
+		if dailyReserved {
+			s.dailyQuota.Release(ctx, userID, participatedAt)
+		}
+	}
+
+	// 每日跨活动限购：按参与时间所在自然日占用次数，失败时与专场购买次数一同归还
+	if s.dailyQuota != nil {
+		reserved, allowed, err := s.dailyQuota.Reserve(ctx, userID, participatedAt)
+		if err != nil {
+			releaseQuota()
+			logger.Error("占用每日秒杀次数失败", zap.Error(err))
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+		}
+		if !allowed {
+			releaseQuota()
+			logger.Info("已达到每日秒杀次数上限")
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeDailyQuota, "已达到今日秒杀次数上限，请明天再来"), nil
+		}
+		dailyReserved = reserved
+	}
+
+	// 7. Redis原子性预减库存
+	var result *cache.DecrementStockResult
+	err = s.guardCache(func() (err error) {
+		result, err = s.decrementStock(ctx, spikeEvent, userID, req.Quantity)
+		return err
+	})
 	if err != nil {
+		releaseQuota()
 		logger.Error("预减库存失败", zap.Error(err))
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "系统繁忙，请稍后重试",
-		}, nil
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
 	}
 
 	if !result.Success {
+		releaseQuota()
 		logger.Info("预减库存失败", zap.String("reason", result.Message))
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: result.Message,
-		}, nil
+		// 库存键丢失（如 Redis 故障切换）时交给库存守护恢复，客户端稍后重试
+		if result.Reason == cache.DecrementReasonStockNotFound && s.stockGuardian != nil {
+			s.stockGuardian.ReportMiss(req.SpikeEventID)
+			return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeStockRecovering, "活动库存恢复中，请稍后重试"), nil
+		}
+		return domain.NewSpikeParticipationFailure(decrementFailureCode(result.Reason), result.Message), nil
 	}
 
 	logger.Info("预减库存成功", zap.Int64("remaining_stock", result.RemainingStock))
+	if s.invariants != nil {
+		s.invariants.CheckRemainingStock(ctx, req.SpikeEventID, result.RemainingStock)
+	}
 
-	// 7. 发送异步消息进行DB落库
-	if err := s.sendOrderCreatedMessage(ctx, req, userID, spikeEvent, traceID); err != nil {
+	// 8. 发送异步消息进行DB落库
+	orderData, err := s.sendOrderCreatedMessage(ctx, req, userID, spikeEvent, policy, traceID)
+	if err != nil {
 		logger.Error("发送订单创建消息失败", zap.Error(err))
+		releaseQuota()
 
 		// 恢复Redis库存
 		if _, restoreErr := s.spikeCache.RestoreStock(ctx, req.SpikeEventID, userID, req.Quantity); restoreErr != nil {
 			logger.Error("恢复Redis库存失败", zap.Error(restoreErr))
 		}
 
-		return &domain.SpikeParticipationResponse{
-			Success: false,
-			Message: "系统繁忙，请稍后重试",
-		}, nil
+		return domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeInternalError, "系统繁忙，请稍后重试"), nil
+	}
+
+	// 9. 记录参与日志，消息队列丢失消息时可据此回放重建订单
+	s.appendJournal(ctx, orderData, traceID)
+
+	// 10. 经分享链接到达的参与计入分享归因
+	if s.shareAttribution != nil && req.ShareToken != "" {
+		s.shareAttribution.RecordParticipation(ctx, req.ShareToken, req.SpikeEventID)
 	}
 
 	logger.Info("秒杀请求处理成功")
@@ -229,32 +516,54 @@ func (s *SpikeService) ParticipateSpike(ctx context.Context, req *domain.SpikePa
 	return &domain.SpikeParticipationResponse{
 		Success: true,
 		Message: "秒杀成功，请尽快完成支付",
+		OrderNo: orderData.OrderNo,
 	}, nil
 }
 
-// checkRateLimit 检查限流
-func (s *SpikeService) checkRateLimit(ctx context.Context, userID int64) error {
+// checkRateLimit 检查限流，单用户限流按用户等级选择限流器；超限时返回限流器建议的重试等待时长
+func (s *SpikeService) checkRateLimit(ctx context.Context, userID int64, tier domain.UserTier) (time.Duration, error) {
 	// 检查全局限流
 	globalKey := "global"
 	globalResult, err := s.globalLimiter.Allow(ctx, globalKey)
 	if err != nil {
-		return fmt.Errorf("global rate limit check failed: %w", err)
+		return 0, fmt.Errorf("global rate limit check failed: %w", err)
 	}
 	if !globalResult.Allowed {
-		return fmt.Errorf("global rate limit exceeded")
+		return globalResult.RetryAfter, fmt.Errorf("global rate limit exceeded")
 	}
 
 	// 检查用户限流
-	userKey := fmt.Sprintf("user:%d", userID)
-	userResult, err := s.userLimiter.Allow(ctx, userKey)
+	userKey := keys.Redis("user", userID)
+	userResult, err := s.userLimiterFor(tier).Allow(ctx, userKey)
 	if err != nil {
-		return fmt.Errorf("user rate limit check failed: %w", err)
+		return 0, fmt.Errorf("user rate limit check failed: %w", err)
 	}
 	if !userResult.Allowed {
-		return fmt.Errorf("user rate limit exceeded")
+		return userResult.RetryAfter, fmt.Errorf("user rate limit exceeded")
 	}
 
-	return nil
+	return 0, nil
+}
+
+// decrementFailureCode 将预减库存的失败原因映射为参与失败原因码
+func decrementFailureCode(reason string) string {
+	switch reason {
+	case cache.DecrementReasonSoldOut:
+		return domain.SpikeParticipationCodeSoldOut
+	case cache.DecrementReasonInsufficientStock:
+		return domain.SpikeParticipationCodeInsufficientStock
+	case cache.DecrementReasonDuplicateUser:
+		return domain.SpikeParticipationCodeAlreadyParticipated
+	case cache.DecrementReasonUserLimit:
+		return domain.SpikeParticipationCodePurchaseLimit
+	case cache.DecrementReasonFrozen:
+		return domain.SpikeParticipationCodeStockRecovering
+	case cache.DecrementReasonStockNotFound:
+		// 未配置库存守护时库存键丢失无法自动恢复，视为活动不可参与
+		return domain.SpikeParticipationCodeEventUnavailable
+	default:
+		return domain.SpikeParticipationCodeInternalError
+	}
 }
 
 // validateSpikeRequest 验证秒杀请求
@@ -262,11 +571,14 @@ func (s *SpikeService) validateSpikeRequest(req *domain.SpikeParticipationReques
 	if req.SpikeEventID <= 0 {
 		return fmt.Errorf("无效的秒杀活动ID")
 	}
-	if req.Quantity <= 0 || req.Quantity > 10 {
-		return fmt.Errorf("购买数量必须在1-10之间")
+	if req.Quantity <= 0 || req.Quantity > domain.MaxSpikeQuantity {
+		return fmt.Errorf("购买数量必须在1-%d之间", domain.MaxSpikeQuantity)
+	}
+	if err := keys.ValidateIdempotencyKey(req.IdempotencyKey); err != nil {
+		return err
 	}
-	if req.IdempotencyKey == "" {
-		return fmt.Errorf("幂等键不能为空")
+	if !req.Channel.OrDefault().IsValid() {
+		return fmt.Errorf("无效的下单渠道: %s", req.Channel)
 	}
 	if userID <= 0 {
 		return fmt.Errorf("用户未登录")
@@ -274,8 +586,12 @@ func (s *SpikeService) validateSpikeRequest(req *domain.SpikeParticipationReques
 	return nil
 }
 
-// getSpikeEventWithCache 获取秒杀活动信息（带缓存）
+// getSpikeEventWithCache 获取秒杀活动信息（带缓存），库存缓存熔断期间直接读取数据库
 func (s *SpikeService) getSpikeEventWithCache(ctx context.Context, eventID int64) (*domain.SpikeEvent, error) {
+	if s.cacheOpen() {
+		return s.spikeEventRepo.GetByID(ctx, eventID)
+	}
+
 	// 尝试从缓存获取
 	var spikeEvent domain.SpikeEvent
 	err := s.spikeCache.GetEventInfo(ctx, eventID, &spikeEvent)
@@ -284,13 +600,13 @@ func (s *SpikeService) getSpikeEventWithCache(ctx context.Context, eventID int64
 	}
 
 	// 缓存未命中，从数据库获取
-	event, err := s.spikeEventRepo.GetByID(eventID)
+	event, err := s.spikeEventRepo.GetByID(ctx, eventID)
 	if err != nil {
 		return nil, err
 	}
 
 	// 更新缓存
-	if cacheErr := s.spikeCache.CacheEventInfo(ctx, eventID, event, s.config.StockCacheTTL); cacheErr != nil {
+	if cacheErr := s.spikeCache.CacheEventInfo(ctx, eventID, event, s.stockTTL(event)); cacheErr != nil {
 		s.logger.Warn("缓存秒杀活动信息失败", zap.Error(cacheErr))
 	}
 
@@ -298,10 +614,27 @@ func (s *SpikeService) getSpikeEventWithCache(ctx context.Context, eventID int64
 }
 
 // sendOrderCreatedMessage 发送订单创建消息
-func (s *SpikeService) sendOrderCreatedMessage(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64, spikeEvent *domain.SpikeEvent, traceID string) error {
-	expireAt := time.Now().Add(s.config.OrderExpireTime)
+func (s *SpikeService) sendOrderCreatedMessage(ctx context.Context, req *domain.SpikeParticipationRequest, userID int64, spikeEvent *domain.SpikeEvent, policy TierPolicy, traceID string) (*mq.SpikeOrderCreatedData, error) {
+	data, err := s.newOrderCreatedData(req, userID, spikeEvent, policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publishOrderCreated(ctx, data, traceID); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// newOrderCreatedData 生成订单创建消息，订单号在参与时预先生成
+func (s *SpikeService) newOrderCreatedData(req *domain.SpikeParticipationRequest, userID int64, spikeEvent *domain.SpikeEvent, policy TierPolicy) (*mq.SpikeOrderCreatedData, error) {
+	expireAt := time.Now().Add(s.cfg().OrderExpireTime)
+	orderNo, err := nextOrderNo(s.orderNos)
+	if err != nil {
+		return nil, err
+	}
 
-	data := &mq.SpikeOrderCreatedData{
+	return &mq.SpikeOrderCreatedData{
+		OrderNo:        orderNo,
 		SpikeEventID:   req.SpikeEventID,
 		UserID:         userID,
 		ProductID:      spikeEvent.ProductID,
@@ -311,11 +644,96 @@ func (s *SpikeService) sendOrderCreatedMessage(ctx context.Context, req *domain.
 		IdempotencyKey: req.IdempotencyKey,
 		ExpireAt:       expireAt,
 		CreatedAt:      time.Now(),
-	}
+		UserTier:       string(req.UserTier.OrDefault()),
+		Priority:       policy.MessagePriority,
+
+		RecoveryCampaignID: req.RecoveryCampaignID,
+		ClientIP:           req.ClientIP,
+		UserAgent:          req.UserAgent,
+		Channel:            string(req.Channel.OrDefault()),
+	}, nil
+}
 
+// publishOrderCreated 发布订单创建消息，设置了发件箱时先写入发件箱
+func (s *SpikeService) publishOrderCreated(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) error {
+	if s.outbox != nil {
+		message, err := domain.NewOutboxMessage(string(mq.MessageTypeSpikeOrderCreated), data, traceID)
+		if err != nil {
+			return fmt.Errorf("failed to build outbox message: %w", err)
+		}
+		return s.outbox.Enqueue(ctx, message)
+	}
 	return s.spikeProducer.PublishSpikeOrderCreated(ctx, data, traceID)
 }
 
+// SetOutbox 设置消息发件箱，设置后订单创建与取消消息先落库再由中继发布
+func (s *SpikeService) SetOutbox(outbox SpikeOutbox) {
+	s.outbox = outbox
+}
+
+// appendJournal 追加参与日志。
+// 只记录消息已写入发件箱或被队列接收的参与，发送失败的请求已恢复库存，不应被回放；写日志失败不影响本次请求
+func (s *SpikeService) appendJournal(ctx context.Context, data *mq.SpikeOrderCreatedData, traceID string) {
+	if s.journal == nil {
+		return
+	}
+
+	entry := &journal.Entry{
+		OrderNo:        data.OrderNo,
+		SpikeEventID:   data.SpikeEventID,
+		UserID:         data.UserID,
+		ProductID:      data.ProductID,
+		Quantity:       data.Quantity,
+		SpikePrice:     data.SpikePrice,
+		TotalAmount:    data.TotalAmount,
+		IdempotencyKey: data.IdempotencyKey,
+		ExpireAt:       data.ExpireAt,
+		CreatedAt:      data.CreatedAt,
+		TraceID:        traceID,
+	}
+	if err := s.journal.Append(ctx, entry); err != nil {
+		s.logger.Error("写入参与日志失败",
+			zap.String("trace_id", traceID),
+			zap.Int64("spike_event_id", data.SpikeEventID),
+			logger.IdempotencyKey(data.IdempotencyKey),
+			zap.Error(err))
+	}
+}
+
+// SetJournal 设置参与日志，为空时不记录
+func (s *SpikeService) SetJournal(w journal.Writer) {
+	s.journal = w
+}
+
+// ReplicationLagReporter 报告只读查询可能读到的复制延迟（由 database.DB 实现）
+type ReplicationLagReporter interface {
+	ReplicationLag(ctx context.Context) (lag time.Duration, fromReplica bool)
+}
+
+// SpikeStats.DataSource 的取值
+const (
+	ReadSourcePrimary = "primary"
+	ReadSourceReplica = "replica"
+)
+
+// SetReplicationLag 设置复制延迟来源，统计信息据此标注数据读自主库还是只读副本
+func (s *SpikeService) SetReplicationLag(reporter ReplicationLagReporter) {
+	s.replicationLag = reporter
+}
+
+// SetUserCache 设置用户缓存，订单详情等高频接口通过它读取用户信息
+func (s *SpikeService) SetUserCache(userCache *UserCache) {
+	s.userCache = userCache
+}
+
+// getUser 获取用户信息，启用用户缓存时优先读取缓存
+func (s *SpikeService) getUser(ctx context.Context, userID int64) (*domain.User, error) {
+	if s.userCache != nil {
+		return s.userCache.GetUser(ctx, userID)
+	}
+	return s.userRepo.GetByID(userID)
+}
+
 // GetSpikeEventDetail 获取秒杀活动详情
 func (s *SpikeService) GetSpikeEventDetail(ctx context.Context, eventID int64) (*domain.SpikeEventWithProduct, error) {
 	// 获取秒杀活动
@@ -324,6 +742,11 @@ func (s *SpikeService) GetSpikeEventDetail(ctx context.Context, eventID int64) (
 		return nil, fmt.Errorf("failed to get spike event: %w", err)
 	}
 
+	// 设置了预告时间的活动在预告开始前不对外展示
+	if !spikeEvent.IsVisible() {
+		return nil, domain.ErrSpikeEventNotFound
+	}
+
 	// 获取商品信息
 	product, err := s.productRepo.GetByID(spikeEvent.ProductID)
 	if err != nil {
@@ -342,91 +765,159 @@ func (s *SpikeService) GetSpikeEventDetail(ctx context.Context, eventID int64) (
 		}
 	}
 
-	// 更新实时库存信息
+	// 以 Redis 剩余库存为准修正已售数量，SpikeStock 保持活动总库存
 	if stockInfo.Exists && stockInfo.Stock >= 0 {
-		spikeEvent.SpikeStock = stockInfo.Stock
+		spikeEvent.SoldCount = max(spikeEvent.SpikeStock-stockInfo.Stock, 0)
+	}
+
+	// 倒计时以服务器时间为准，客户端据此校准本地时钟偏差
+	serverTime := time.Now()
+	startsInMs := spikeEvent.StartAt.Sub(serverTime).Milliseconds()
+	if startsInMs < 0 {
+		startsInMs = 0
 	}
 
 	return &domain.SpikeEventWithProduct{
-		SpikeEvent: spikeEvent,
-		Product:    product,
+		SpikeEvent:     spikeEvent,
+		Product:        product,
+		InPreview:      spikeEvent.IsInPreview(),
+		SecondsToStart: spikeEvent.SecondsToStart(0),
+		ServerTime:     serverTime,
+		StartsInMs:     startsInMs,
 	}, nil
 }
 
 // GetUserSpikeOrders 获取用户秒杀订单列表
 func (s *SpikeService) GetUserSpikeOrders(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderListResponse, error) {
 	req.UserID = &userID
-	orders, total, err := s.spikeOrderRepo.List(req)
+	orders, total, err := s.spikeOrderRepo.List(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	orders, pageInfo := domain.PaginateWithCursor(orders, total, req.Page, req.PageSize, req.KeysetOrdered(), (*domain.SpikeOrder).Cursor)
 
 	return &domain.SpikeOrderListResponse{
 		Orders:   orders,
 		Total:    total,
 		Page:     req.Page,
 		PageSize: req.PageSize,
+		PageInfo: pageInfo,
 	}, nil
 }
 
-// GetSpikeOrderDetail 获取秒杀订单详情
-func (s *SpikeService) GetSpikeOrderDetail(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error) {
-	// 获取秒杀订单
-	spikeOrder, err := s.spikeOrderRepo.GetByID(orderID)
+// GetUserSpikeOrdersWithEvents 获取用户秒杀订单列表，并附带各订单所属活动的摘要。
+// 订单与活动通过一次 JOIN 查询取回，客户端无需再逐个查询活动详情
+func (s *SpikeService) GetUserSpikeOrdersWithEvents(ctx context.Context, userID int64, req *domain.SpikeOrderListRequest) (*domain.SpikeOrderWithEventListResponse, error) {
+	orders, total, err := s.spikeOrderRepo.ListWithEvent(ctx, userID, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get spike order: %w", err)
+		return nil, err
 	}
+	orders, pageInfo := domain.PaginateWithCursor(orders, total, req.Page, req.PageSize, req.KeysetOrdered(), (*domain.SpikeOrderWithEvent).Cursor)
+
+	return &domain.SpikeOrderWithEventListResponse{
+		Orders:   orders,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: pageInfo,
+	}, nil
+}
 
-	// 验证订单所有权
-	if spikeOrder.UserID != userID {
-		return nil, fmt.Errorf("订单不属于当前用户")
+// GetSpikeOrderDetail 获取秒杀订单详情
+// 订单加载并校验所有权后，并发查询活动与用户信息；配置 OrderDetailJoin 时改为单次 JOIN 查询
+func (s *SpikeService) GetSpikeOrderDetail(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error) {
+	if s.cfg().OrderDetailJoin {
+		return s.getSpikeOrderDetailJoined(ctx, orderID, userID)
 	}
 
-	// 获取秒杀活动信息
-	spikeEvent, err := s.spikeEventRepo.GetByID(spikeOrder.SpikeEventID)
+	// 获取秒杀订单并验证所有权
+	spikeOrder, err := s.getOwnedSpikeOrder(ctx, orderID, userID, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get spike event: %w", err)
+		return nil, err
 	}
 
-	// 获取用户信息
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+	var spikeEvent *domain.SpikeEvent
+	var user *domain.User
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		// 获取秒杀活动信息
+		event, err := s.spikeEventRepo.GetByID(ctx, spikeOrder.SpikeEventID)
+		if err != nil {
+			return fmt.Errorf("failed to get spike event: %w", err)
+		}
+		spikeEvent = event
+		return nil
+	})
+	g.Go(func() error {
+		// 获取用户信息
+		u, err := s.getUser(gctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		user = u
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
-	return &domain.SpikeOrderWithDetails{
+	detail := &domain.SpikeOrderWithDetails{
 		SpikeOrder: spikeOrder,
 		SpikeEvent: spikeEvent,
 		User:       user,
-	}, nil
+	}
+	setOrderDetailExpiry(detail)
+
+	return detail, nil
 }
 
-// CancelSpikeOrder 取消秒杀订单
-func (s *SpikeService) CancelSpikeOrder(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error {
-	// 获取秒杀订单
-	spikeOrder, err := s.spikeOrderRepo.GetByID(orderID)
+// getSpikeOrderDetailJoined 通过单次 JOIN 查询订单详情
+func (s *SpikeService) getSpikeOrderDetailJoined(ctx context.Context, orderID, userID int64) (*domain.SpikeOrderWithDetails, error) {
+	detail, err := s.spikeOrderRepo.GetDetailByID(ctx, orderID)
 	if err != nil {
-		return fmt.Errorf("failed to get spike order: %w", err)
+		if errors.Is(err, domain.ErrSpikeOrderNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get spike order detail: %w", err)
+	}
+
+	if err := s.cfg().Ownership.Authorize(detail.UserID, userID, false, domain.ErrSpikeOrderNotFound); err != nil {
+		return nil, err
+	}
+	setOrderDetailExpiry(detail)
+
+	return detail, nil
+}
+
+// setOrderDetailExpiry 为待支付订单填充剩余支付秒数
+func setOrderDetailExpiry(detail *domain.SpikeOrderWithDetails) {
+	if detail.IsPending() && detail.ExpireAt != nil {
+		expiresIn := detail.GetRemainingTime()
+		detail.ExpiresInSeconds = &expiresIn
 	}
+}
 
-	// 验证订单所有权
-	if spikeOrder.UserID != userID {
-		return fmt.Errorf("订单不属于当前用户")
+// CancelSpikeOrder 取消秒杀订单
+func (s *SpikeService) CancelSpikeOrder(ctx context.Context, orderID, userID int64, req *domain.CancelSpikeOrderRequest) error {
+	// 获取秒杀订单并验证所有权
+	spikeOrder, err := s.getOwnedSpikeOrder(ctx, orderID, userID, false)
+	if err != nil {
+		return err
 	}
 
 	// 检查订单状态
 	if !spikeOrder.CanCancel() {
-		return fmt.Errorf("订单当前状态不允许取消")
+		return domain.ErrSpikeOrderNotCancellable
 	}
 
 	// 获取秒杀活动信息
-	spikeEvent, err := s.spikeEventRepo.GetByID(spikeOrder.SpikeEventID)
+	spikeEvent, err := s.spikeEventRepo.GetByID(ctx, spikeOrder.SpikeEventID)
 	if err != nil {
 		return fmt.Errorf("failed to get spike event: %w", err)
 	}
 
 	// 发送订单取消消息
-	traceID := uuid.New().String()
+	ctx, traceID := tracing.EnsureTraceID(ctx)
 	data := &mq.SpikeOrderCancelledData{
 		SpikeOrderID:   spikeOrder.ID,
 		SpikeEventID:   spikeOrder.SpikeEventID,
@@ -435,37 +926,225 @@ func (s *SpikeService) CancelSpikeOrder(ctx context.Context, orderID, userID int
 		Quantity:       spikeOrder.Quantity,
 		Reason:         req.Reason,
 		CancelledAt:    time.Now(),
-		IdempotencyKey: fmt.Sprintf("cancel_%d_%d", spikeOrder.ID, time.Now().Unix()),
+		IdempotencyKey: keys.Idempotency("cancel", spikeOrder.ID, time.Now().Unix()),
 	}
 
-	if err := s.spikeProducer.PublishSpikeOrderCancelled(ctx, data, traceID); err != nil {
-		return fmt.Errorf("failed to publish order cancelled message: %w", err)
+	actor := req.Actor
+	if actor == "" {
+		actor = domain.UserActor(userID)
+	}
+	event := &domain.OrderEvent{
+		SpikeOrderID: orderID,
+		EventType:    domain.OrderEventCancelled,
+		FromStatus:   string(spikeOrder.Status),
+		ToStatus:     string(domain.SpikeOrderStatusCancelled),
+		Actor:        actor,
+		Remark:       req.Reason,
+		TraceID:      traceID,
 	}
 
-	// 更新订单状态
-	if err := s.spikeOrderRepo.UpdateStatus(orderID, domain.SpikeOrderStatusCancelled); err != nil {
-		s.logger.Error("更新订单状态失败", zap.Error(err))
-		// 不返回错误，因为消息已经发送，消费者会处理库存恢复
+	if s.outbox != nil {
+		// 订单状态、时间线与取消消息在同一事务中写入，消息由发件箱中继发布
+		message, err := domain.NewOutboxMessage(string(mq.MessageTypeSpikeOrderCancelled), data, traceID)
+		if err != nil {
+			return fmt.Errorf("failed to build outbox message: %w", err)
+		}
+		if err := s.spikeOrderRepo.CancelWithOutbox(ctx, orderID, event, message); err != nil {
+			return err
+		}
+		s.outbox.Notify()
+	} else {
+		if err := s.spikeProducer.PublishSpikeOrderCancelled(ctx, data, traceID); err != nil {
+			return fmt.Errorf("failed to publish order cancelled message: %w", err)
+		}
+
+		// 更新订单状态
+		if err := s.spikeOrderRepo.UpdateStatus(ctx, orderID, domain.SpikeOrderStatusCancelled); err != nil {
+			s.logger.Error("更新订单状态失败", zap.Error(err))
+			// 不返回错误，因为消息已经发送，消费者会处理库存恢复
+		}
+		s.recordOrderEvent(event)
 	}
 
 	s.logger.Info("秒杀订单取消成功",
 		zap.Int64("order_id", orderID),
-		zap.Int64("user_id", userID),
+		logger.UserID(userID),
+		zap.String("actor", actor),
 		zap.String("reason", req.Reason))
 
 	return nil
 }
 
+// ExtendSpikeOrder 延长待支付订单的支付时间，每个订单只能延长一次。
+// 过期时间与时间线在同一事务中更新；已投递的过期消息由消费者按最新过期时间校验后忽略
+func (s *SpikeService) ExtendSpikeOrder(ctx context.Context, orderID, userID int64, actor string) (*domain.ExtendSpikeOrderResponse, error) {
+	if s.cfg().OrderExtension <= 0 {
+		return nil, domain.ErrSpikeOrderNotExtendable
+	}
+
+	spikeOrder, err := s.getOwnedSpikeOrder(ctx, orderID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !spikeOrder.CanPay() {
+		return nil, domain.ErrSpikeOrderNotExtendable
+	}
+
+	if actor == "" {
+		actor = domain.UserActor(userID)
+	}
+	_, traceID := tracing.EnsureTraceID(ctx)
+	expireAt, err := s.spikeOrderRepo.ExtendExpireAt(ctx, orderID, s.cfg().OrderExtension, &domain.OrderEvent{
+		SpikeOrderID: orderID,
+		EventType:    domain.OrderEventExtended,
+		FromStatus:   string(domain.SpikeOrderStatusPending),
+		ToStatus:     string(domain.SpikeOrderStatusPending),
+		Actor:        actor,
+		Remark:       fmt.Sprintf("支付时间延长%s", s.cfg().OrderExtension),
+		TraceID:      traceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("秒杀订单支付时间已延长",
+		zap.Int64("order_id", orderID),
+		logger.UserID(userID),
+		zap.String("actor", actor),
+		zap.Time("expire_at", expireAt))
+
+	return &domain.ExtendSpikeOrderResponse{
+		SpikeOrderID:     orderID,
+		ExpireAt:         expireAt,
+		ExpiresInSeconds: int64(time.Until(expireAt) / time.Second),
+	}, nil
+}
+
+// GetSpikeOrderTimeline 获取秒杀订单时间线
+// 普通用户只能查看自己的订单，管理员（客服）可以查看任意订单
+func (s *SpikeService) GetSpikeOrderTimeline(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrderTimeline, error) {
+	spikeOrder, err := s.getOwnedSpikeOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.orderEventRepo.ListBySpikeOrderID(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order events: %w", err)
+	}
+	if events == nil {
+		events = []*domain.OrderEvent{}
+	}
+
+	timeline := &domain.SpikeOrderTimeline{
+		SpikeOrderID: orderID,
+		Status:       spikeOrder.Status,
+		Events:       events,
+	}
+	if isAdmin {
+		timeline.Client = &domain.SpikeOrderClient{
+			ClientIP:  spikeOrder.ClientIP,
+			UserAgent: spikeOrder.UserAgent,
+			Channel:   spikeOrder.Channel,
+		}
+	}
+	return timeline, nil
+}
+
+// LookupSpikeOrder 按订单号、幂等键或支付流水号精确查找订单（客服使用），返回订单及其时间线
+func (s *SpikeService) LookupSpikeOrder(ctx context.Context, req *domain.SpikeOrderLookupRequest) (*domain.SpikeOrderLookupResponse, error) {
+	var orderID int64
+	if req.OrderNo != "" {
+		order, err := s.spikeOrderRepo.GetByOrderNo(ctx, req.OrderNo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get spike order by order no: %w", err)
+		}
+		if order == nil {
+			return nil, domain.ErrSpikeOrderNotFound
+		}
+		orderID = order.ID
+	}
+	if req.IdempotencyKey != "" {
+		order, err := s.spikeOrderRepo.GetByIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get spike order by idempotency key: %w", err)
+		}
+		if order == nil || (orderID != 0 && order.ID != orderID) {
+			return nil, domain.ErrSpikeOrderNotFound
+		}
+		orderID = order.ID
+	}
+	if req.PaymentRef != "" {
+		order, err := s.spikeOrderRepo.GetByPaymentRef(ctx, req.PaymentRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get spike order by payment ref: %w", err)
+		}
+		if order == nil || (orderID != 0 && order.ID != orderID) {
+			return nil, domain.ErrSpikeOrderNotFound
+		}
+		orderID = order.ID
+	}
+	if orderID == 0 {
+		return nil, domain.ErrSpikeOrderNotFound
+	}
+
+	// 按ID重新读取完整订单（含客户端信息与支付流水号）
+	order, err := s.getOwnedSpikeOrder(ctx, orderID, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	timeline, err := s.GetSpikeOrderTimeline(ctx, orderID, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SpikeOrderLookupResponse{Order: order, Timeline: timeline}, nil
+}
+
+// getOwnedSpikeOrder 获取秒杀订单并按所有权策略校验访问权限，
+// 订单不存在返回 domain.ErrSpikeOrderNotFound，无权访问返回 domain.ErrForbidden 或按策略视为不存在
+func (s *SpikeService) getOwnedSpikeOrder(ctx context.Context, orderID, userID int64, isAdmin bool) (*domain.SpikeOrder, error) {
+	spikeOrder, err := s.spikeOrderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSpikeOrderNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get spike order: %w", err)
+	}
+	if spikeOrder == nil {
+		return nil, domain.ErrSpikeOrderNotFound
+	}
+
+	if err := s.cfg().Ownership.Authorize(spikeOrder.UserID, userID, isAdmin, domain.ErrSpikeOrderNotFound); err != nil {
+		return nil, err
+	}
+	return spikeOrder, nil
+}
+
+// recordOrderEvent 记录订单事件，失败只记录日志不影响主流程
+func (s *SpikeService) recordOrderEvent(event *domain.OrderEvent) {
+	if s.orderEventRepo == nil {
+		return
+	}
+	if err := s.orderEventRepo.Create(event); err != nil {
+		s.logger.Error("记录订单事件失败",
+			zap.Int64("spike_order_id", event.SpikeOrderID),
+			zap.String("event_type", string(event.EventType)),
+			zap.Error(err))
+	}
+}
+
 // GetActiveEvents 获取活跃的秒杀活动列表
 func (s *SpikeService) GetActiveEvents(ctx context.Context, req *domain.SpikeEventListRequest) (*domain.SpikeEventListResponse, error) {
 	// 设置查询条件为活跃状态
 	active := true
 	req.Active = &active
 
-	events, total, err := s.spikeEventRepo.List(req)
+	events, total, err := s.spikeEventRepo.List(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active events: %w", err)
 	}
+	events, pageInfo := domain.PaginateWithCursor(events, total, req.Page, req.PageSize, req.KeysetOrdered(), (*domain.SpikeEvent).Cursor)
 
 	// 更新实时库存信息
 	for _, event := range events {
@@ -480,37 +1159,173 @@ func (s *SpikeService) GetActiveEvents(ctx context.Context, req *domain.SpikeEve
 		Total:    total,
 		Page:     req.Page,
 		PageSize: req.PageSize,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // WarmupStock 预热库存（在秒杀开始前调用）
-func (s *SpikeService) WarmupStock(ctx context.Context, eventID int64) error {
-	spikeEvent, err := s.spikeEventRepo.GetByID(eventID)
+// 业务规则：
+// 1. 剩余库存以数据库为准：总库存减去已售数量与待支付、已支付订单数量中的较大者
+// 2. 活动进行中且库存键已存在时拒绝预热，避免覆盖已扣减的库存；force 为 true 时强制覆盖
+func (s *SpikeService) WarmupStock(ctx context.Context, eventID int64, force bool) error {
+	spikeEvent, err := s.spikeEventRepo.GetByID(ctx, eventID)
 	if err != nil {
 		return fmt.Errorf("failed to get spike event: %w", err)
 	}
 
-	// 预热Redis库存
-	remainingStock := spikeEvent.SpikeStock - spikeEvent.SoldCount
-	if remainingStock > 0 {
-		if err := s.spikeCache.WarmupStock(ctx, eventID, remainingStock, s.config.StockCacheTTL); err != nil {
+	if spikeEvent.IsActive() {
+		stockInfo, err := s.spikeCache.GetStockInfo(ctx, eventID)
+		if err != nil {
+			return fmt.Errorf("failed to get stock info: %w", err)
+		}
+		if stockInfo.Exists {
+			if !force {
+				s.logger.Warn("活动进行中且库存已预热，拒绝重复预热",
+					zap.Int64("event_id", eventID),
+					zap.Int64("cached_stock", stockInfo.Stock))
+				return domain.ErrSpikeStockAlreadyWarmed
+			}
+			s.logger.Warn("强制覆盖进行中活动的库存",
+				zap.Int64("event_id", eventID),
+				zap.Int64("cached_stock", stockInfo.Stock))
+		}
+	}
+
+	// 刷新活动信息缓存，使修改后的限购与限流配置在预热后生效
+	if err := s.spikeCache.CacheEventInfo(ctx, eventID, spikeEvent, s.stockTTL(spikeEvent)); err != nil {
+		s.logger.Warn("缓存秒杀活动信息失败", zap.Int64("event_id", eventID), zap.Error(err))
+	}
+
+	remainingStock, sold, err := remainingStockFromDB(ctx, s.spikeOrderRepo, spikeEvent)
+	if err != nil {
+		return err
+	}
+	// 强制预热时即使已无剩余库存也覆盖旧值
+	if remainingStock > 0 || force {
+		if err := warmupEventStock(ctx, s.spikeCache, spikeEvent, remainingStock, s.stockTTL(spikeEvent)); err != nil {
 			return fmt.Errorf("failed to warmup stock: %w", err)
 		}
 		s.logger.Info("库存预热成功",
 			zap.Int64("event_id", eventID),
-			zap.Int64("stock", remainingStock))
+			zap.Int64("sold", sold),
+			zap.Int64("stock", remainingStock),
+			zap.Bool("force", force))
 	}
 
 	return nil
 }
 
+// WarmupAllStock 预热全部进行中与预告期活动的库存，逐个活动上报进度；
+// 进行中且库存已预热的活动在未强制覆盖时跳过，单个活动失败不影响其余活动
+func (s *SpikeService) WarmupAllStock(ctx context.Context, force bool, progress AdminJobProgress) (*domain.WarmupAllResult, error) {
+	active, err := s.spikeEventRepo.GetActiveEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active events: %w", err)
+	}
+	preview, err := s.spikeEventRepo.GetPreviewEvents(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preview events: %w", err)
+	}
+	events := append(active, preview...)
+
+	result := &domain.WarmupAllResult{Total: len(events)}
+	for i, event := range events {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		switch err := s.WarmupStock(ctx, event.ID, force); {
+		case err == nil:
+			result.Warmed++
+		case errors.Is(err, domain.ErrSpikeStockAlreadyWarmed):
+			result.Skipped++
+		default:
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("event %d: %v", event.ID, err))
+		}
+		if progress != nil {
+			progress(i+1, len(events))
+		}
+	}
+	return result, nil
+}
+
+// SetStockGuardian 设置库存守护，预减库存发现库存键丢失时上报恢复
+func (s *SpikeService) SetStockGuardian(guardian *StockGuardian) {
+	s.stockGuardian = guardian
+}
+
+// SetInvariantChecker 设置库存不变量检查，预减库存后检查 Redis 剩余库存
+func (s *SpikeService) SetInvariantChecker(checker *StockInvariantChecker) {
+	s.invariants = checker
+}
+
+// SetCampaignQuota 设置专场跨活动限购，参与属于限购专场的活动时占用专场购买次数；未设置时不限购
+func (s *SpikeService) SetCampaignQuota(quota *CampaignQuota) {
+	s.campaignQuota = quota
+}
+
+// SetDailyQuota 设置单用户每日秒杀成功次数上限，参与任意活动时占用当日次数；未设置时不限制
+func (s *SpikeService) SetDailyQuota(quota *DailyQuota) {
+	s.dailyQuota = quota
+}
+
+// SetStopSellChecker 设置商品停售标记检查，参与秒杀前检查活动商品是否已停售；未设置时不检查
+func (s *SpikeService) SetStopSellChecker(checker StopSellChecker) {
+	s.stopSell = checker
+}
+
+// productStopped 检查商品是否已停售，读取失败时记录日志并视为未停售
+func (s *SpikeService) productStopped(ctx context.Context, productID int64, logger *zap.Logger) bool {
+	if s.stopSell == nil {
+		return false
+	}
+	stopped, err := s.stopSell.IsStopped(ctx, productID)
+	if err != nil {
+		logger.Warn("检查商品停售标记失败", zap.Int64("product_id", productID), zap.Error(err))
+		return false
+	}
+	if stopped {
+		logger.Info("商品已停售", zap.Int64("product_id", productID))
+	}
+	return stopped
+}
+
+// WarmupEvent 预热活动缓存：活动信息与剩余库存，供预告期调度器在活动开始前调用
+func (s *SpikeService) WarmupEvent(ctx context.Context, event *domain.SpikeEvent) error {
+	ttl := s.stockTTL(event)
+	if err := s.spikeCache.CacheEventInfo(ctx, event.ID, event, ttl); err != nil {
+		return fmt.Errorf("failed to cache event info: %w", err)
+	}
+
+	remainingStock := event.GetRemainingStock()
+	if remainingStock > 0 {
+		if err := warmupEventStock(ctx, s.spikeCache, event, remainingStock, ttl); err != nil {
+			return fmt.Errorf("failed to warmup stock: %w", err)
+		}
+	}
+
+	s.logger.Info("活动缓存预热成功",
+		zap.Int64("event_id", event.ID),
+		zap.Int64("stock", remainingStock),
+		zap.Duration("ttl", ttl))
+	return nil
+}
+
+// stockTTL 计算活动库存与缓存键的过期时间，覆盖到活动结束后 StockTTLBuffer
+func (s *SpikeService) stockTTL(event *domain.SpikeEvent) time.Duration {
+	return event.StockKeyTTL(time.Now(), s.cfg().StockCacheTTL, s.cfg().StockTTLBuffer)
+}
+
 // GetSpikeStats 获取秒杀统计信息
 func (s *SpikeService) GetSpikeStats(ctx context.Context, eventID int64) (*SpikeStats, error) {
 	// 获取秒杀活动
-	spikeEvent, err := s.spikeEventRepo.GetByID(eventID)
+	spikeEvent, err := s.spikeEventRepo.GetByID(ctx, eventID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spike event: %w", err)
 	}
+	if spikeEvent.IsUnpublished() {
+		return nil, domain.ErrSpikeEventNotFound
+	}
 
 	// 获取Redis库存信息
 	stockInfo, err := s.spikeCache.GetStockInfo(ctx, eventID)
@@ -526,7 +1341,7 @@ func (s *SpikeService) GetSpikeStats(ctx context.Context, eventID int64) (*Spike
 		domain.SpikeOrderStatusCancelled,
 		domain.SpikeOrderStatusExpired,
 	} {
-		count, err := s.spikeOrderRepo.CountByStatus(status)
+		count, err := s.spikeOrderRepo.CountByStatus(ctx, status)
 		if err != nil {
 			s.logger.Warn("获取订单统计失败", zap.String("status", string(status)), zap.Error(err))
 		} else {
@@ -551,6 +1366,15 @@ func (s *SpikeService) GetSpikeStats(ctx context.Context, eventID int64) (*Spike
 		stats.SoldOut = stockInfo.SoldOut
 	}
 
+	// 读副本时标注复制延迟，调用方据此判断已售数与订单统计的新鲜度
+	if s.replicationLag != nil {
+		stats.DataSource = ReadSourcePrimary
+		if lag, fromReplica := s.replicationLag.ReplicationLag(ctx); fromReplica {
+			stats.DataSource = ReadSourceReplica
+			stats.ReplicationLagMs = lag.Milliseconds()
+		}
+	}
+
 	return stats, nil
 }
 
@@ -565,4 +1389,8 @@ type SpikeStats struct {
 	IsActive       bool                              `json:"is_active"`
 	StartAt        time.Time                         `json:"start_at"`
 	EndAt          time.Time                         `json:"end_at"`
+	// DataSource 活动与订单统计读自主库（primary）还是只读副本（replica），未启用读写分离时省略
+	DataSource string `json:"data_source,omitempty"`
+	// ReplicationLagMs 读副本时副本的复制延迟（毫秒）
+	ReplicationLagMs int64 `json:"replication_lag_ms,omitempty"`
 }
@@ -1,13 +1,25 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/database"
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
 )
 
 func TestSpikeService_ParticipateSpike(t *testing.T) {
@@ -16,7 +28,7 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 	spikeOrderRepo := NewMockSpikeOrderRepository()
 	productRepo := newMockProductRepository()
 	inventoryRepo := newMockInventoryRepository()
-	userRepo := NewMockUserRepository()
+	userRepo := newFakeUserRepo()
 	spikeCache := NewMockSpikeCache()
 	spikeProducer := NewMockSpikeProducer()
 	globalLimiter := NewMockLimiter(true)
@@ -50,7 +62,7 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 		ReorderPoint: 10,
 		MaxStock:     2000,
 	}
-	inventoryRepo.Create(inventory)
+	inventoryRepo.Create(context.Background(), inventory)
 
 	// 创建测试秒杀活动
 	now := time.Now()
@@ -65,7 +77,7 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 		SoldCount:     0,
 		Status:        domain.SpikeEventStatusActive,
 	}
-	spikeEventRepo.Create(spikeEvent)
+	spikeEventRepo.Create(context.Background(), spikeEvent)
 
 	// 预热缓存库存
 	spikeCache.WarmupStock(context.Background(), spikeEvent.ID, spikeEvent.GetRemainingStock(), time.Hour)
@@ -77,6 +89,7 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 		productRepo,
 		inventoryRepo,
 		userRepo,
+		nil,
 		spikeCache,
 		spikeProducer,
 		globalLimiter,
@@ -86,12 +99,13 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 	)
 
 	tests := []struct {
-		name        string
-		userID      int64
-		request     *domain.SpikeParticipationRequest
-		setupFunc   func()
-		wantErr     bool
-		wantSuccess bool
+		name          string
+		userID        int64
+		request       *domain.SpikeParticipationRequest
+		setupFunc     func()
+		wantErr       bool
+		wantSuccess   bool
+		wantRetryable bool
 	}{
 		{
 			name:   "successful participation",
@@ -121,8 +135,9 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 				globalLimiter.SetShouldAllow(false)
 				userLimiter.SetShouldAllow(true)
 			},
-			wantErr:     false,
-			wantSuccess: false,
+			wantErr:       false,
+			wantSuccess:   false,
+			wantRetryable: true,
 		},
 		{
 			name:   "rate limited - user limiter",
@@ -136,8 +151,9 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 				globalLimiter.SetShouldAllow(true)
 				userLimiter.SetShouldAllow(false)
 			},
-			wantErr:     false,
-			wantSuccess: false,
+			wantErr:       false,
+			wantSuccess:   false,
+			wantRetryable: true,
 		},
 		{
 			name:   "invalid quantity - zero",
@@ -219,10 +235,135 @@ func TestSpikeService_ParticipateSpike(t *testing.T) {
 			if result != nil && result.Success != tt.wantSuccess {
 				t.Errorf("ParticipateSpike() success = %v, want %v", result.Success, tt.wantSuccess)
 			}
+			if result != nil && result.Success && result.OrderNo == "" {
+				t.Errorf("ParticipateSpike() order_no is empty, want pre-generated order no")
+			}
+			if result != nil && !result.Success {
+				if result.Code == "" || result.Retryable != tt.wantRetryable {
+					t.Errorf("ParticipateSpike() code = %q retryable = %v, want retryable %v", result.Code, result.Retryable, tt.wantRetryable)
+				}
+				if tt.wantRetryable && result.RetryAfterMs != time.Second.Milliseconds() {
+					t.Errorf("ParticipateSpike() retry_after_ms = %d, want limiter retry after", result.RetryAfterMs)
+				}
+			}
 		})
 	}
 }
 
+// fakeEventLimiter 按 key 计数，超过调用方给出的速率即拒绝
+type fakeEventLimiter map[string]int64
+
+func (f fakeEventLimiter) AllowWithRate(ctx context.Context, key string, rate, n int64) (*limiter.LimitResult, error) {
+	if f[key]+n > rate {
+		return &limiter.LimitResult{Allowed: false, RetryAfter: 500 * time.Millisecond}, nil
+	}
+	f[key] += n
+	return &limiter.LimitResult{Allowed: true, Remaining: rate - f[key]}, nil
+}
+
+func TestSpikeService_ParticipateSpike_EventLimits(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	limited := testutil.NewSpikeEventBuilder().Active().WithLimits(2, 2).Build()
+	unlimited := testutil.NewSpikeEventBuilder().Active().Build()
+	perUser := testutil.NewSpikeEventBuilder().Active().WithLimits(3, 0).Build()
+	testutil.SeedSpikeEvents(t, events, limited, unlimited, perUser)
+
+	spikeCache := NewMockSpikeCache()
+	svc := NewSpikeService(events, NewMockSpikeOrderRepository(), nil, nil, nil, nil, spikeCache, NewMockSpikeProducer(),
+		NewMockLimiter(true), NewMockLimiter(true), DefaultSpikeServiceConfig(), zap.NewNop())
+	svc.SetEventLimiter(fakeEventLimiter{})
+	ctx := context.Background()
+	for _, event := range []*domain.SpikeEvent{limited, unlimited, perUser} {
+		if err := svc.WarmupStock(ctx, event.ID, false); err != nil {
+			t.Fatalf("WarmupStock(%d) error = %v", event.ID, err)
+		}
+	}
+
+	participate := func(eventID, userID, quantity int64) *domain.SpikeParticipationResponse {
+		t.Helper()
+		result, err := svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+			SpikeEventID:   eventID,
+			Quantity:       quantity,
+			IdempotencyKey: fmt.Sprintf("limit-%d-%d-%d", eventID, userID, quantity),
+		}, userID)
+		if err != nil {
+			t.Fatalf("ParticipateSpike() error = %v", err)
+		}
+		return result
+	}
+
+	if got := participate(limited.ID, 1, 3); got.Code != domain.SpikeParticipationCodePurchaseLimit || got.Retryable {
+		t.Errorf("quantity over event limit: code = %q, retryable = %v, want %q not retryable", got.Code, got.Retryable, domain.SpikeParticipationCodePurchaseLimit)
+	}
+	if got := participate(unlimited.ID, 1, 3); !got.Success {
+		t.Errorf("quantity within default limit: %+v, want success", got)
+	}
+
+	// 活动 QPS 上限为 2，第三个请求被限流
+	for userID := int64(2); userID <= 3; userID++ {
+		if got := participate(limited.ID, userID, 2); !got.Success {
+			t.Fatalf("participation %d within qps limit: %+v, want success", userID, got)
+		}
+	}
+	got := participate(limited.ID, 4, 1)
+	if got.Code != domain.SpikeParticipationCodeRateLimited || !got.Retryable || got.RetryAfterMs != 500 {
+		t.Errorf("participation over qps limit: %+v, want retryable rate_limited after 500ms", got)
+	}
+	if got := participate(unlimited.ID, 4, 1); !got.Success {
+		t.Errorf("event without qps limit: %+v, want success", got)
+	}
+
+	// 配置了单用户上限的活动可多次参与，累计件数不超过上限；未配置的活动只能参与一次
+	for _, quantity := range []int64{2, 1} {
+		if got := participate(perUser.ID, 1, quantity); !got.Success {
+			t.Fatalf("participation of %d within per-user limit: %+v, want success", quantity, got)
+		}
+	}
+	if got := participate(perUser.ID, 1, 2); got.Code != domain.SpikeParticipationCodePurchaseLimit {
+		t.Errorf("cumulative quantity over event limit: %+v, want %q", got, domain.SpikeParticipationCodePurchaseLimit)
+	}
+	if got := participate(unlimited.ID, 4, 2); got.Code != domain.SpikeParticipationCodeAlreadyParticipated {
+		t.Errorf("second participation without per-user limit: %+v, want %q", got, domain.SpikeParticipationCodeAlreadyParticipated)
+	}
+}
+
+func TestSpikeService_StockBuckets(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	bucketed := testutil.NewSpikeEventBuilder().Active().WithStock(100).WithStockBuckets(8).Build()
+	single := testutil.NewSpikeEventBuilder().Active().WithStock(100).Build()
+	testutil.SeedSpikeEvents(t, events, bucketed, single)
+
+	spikeCache := NewMockSpikeCache()
+	svc := NewSpikeService(events, NewMockSpikeOrderRepository(), nil, nil, nil, nil, spikeCache, NewMockSpikeProducer(),
+		NewMockLimiter(true), NewMockLimiter(true), DefaultSpikeServiceConfig(), zap.NewNop())
+	ctx := context.Background()
+	for _, event := range []*domain.SpikeEvent{bucketed, single} {
+		if err := svc.WarmupStock(ctx, event.ID, false); err != nil {
+			t.Fatalf("WarmupStock(%d) error = %v", event.ID, err)
+		}
+		result, err := svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+			SpikeEventID:   event.ID,
+			Quantity:       1,
+			IdempotencyKey: fmt.Sprintf("buckets-%d", event.ID),
+		}, 1)
+		if err != nil || !result.Success {
+			t.Fatalf("ParticipateSpike(%d) = %+v, %v, want success", event.ID, result, err)
+		}
+	}
+
+	warmups := spikeCache.WarmupStockBucketsCalls()
+	if len(warmups) != 1 || warmups[0].EventID != bucketed.ID || warmups[0].Buckets != 8 {
+		t.Errorf("WarmupStockBuckets calls = %+v, want one for event %d with 8 buckets", warmups, bucketed.ID)
+	}
+	decrements := spikeCache.DecrementStockBucketsCalls()
+	if len(decrements) != 1 || decrements[0].EventID != bucketed.ID || decrements[0].Buckets != 8 {
+		t.Errorf("DecrementStockBuckets calls = %+v, want one for event %d with 8 buckets", decrements, bucketed.ID)
+	}
+	if calls := spikeCache.DecrementStockCalls(); len(calls) != 1 || calls[0].EventID != single.ID {
+		t.Errorf("DecrementStock calls = %+v, want one for single-key event %d", calls, single.ID)
+	}
+}
+
 func TestSpikeService_GetSpikeEventDetail(t *testing.T) {
 	// 准备测试数据
 	spikeEventRepo := NewMockSpikeEventRepository()
@@ -254,7 +395,7 @@ func TestSpikeService_GetSpikeEventDetail(t *testing.T) {
 		SoldCount:     10,
 		Status:        domain.SpikeEventStatusActive,
 	}
-	spikeEventRepo.Create(spikeEvent)
+	spikeEventRepo.Create(context.Background(), spikeEvent)
 
 	// 设置缓存数据
 	spikeCache.WarmupStock(context.Background(), spikeEvent.ID, 85, time.Hour) // 缓存中显示85库存
@@ -265,6 +406,7 @@ func TestSpikeService_GetSpikeEventDetail(t *testing.T) {
 		productRepo,
 		nil,
 		nil,
+		nil,
 		spikeCache,
 		nil,
 		nil,
@@ -335,7 +477,7 @@ func TestSpikeService_GetActiveEvents(t *testing.T) {
 		SoldCount:     20,
 		Status:        domain.SpikeEventStatusActive,
 	}
-	spikeEventRepo.Create(activeEvent)
+	spikeEventRepo.Create(context.Background(), activeEvent)
 
 	// 未开始活动
 	pendingEvent := &domain.SpikeEvent{
@@ -349,7 +491,7 @@ func TestSpikeService_GetActiveEvents(t *testing.T) {
 		SoldCount:     0,
 		Status:        domain.SpikeEventStatusPending,
 	}
-	spikeEventRepo.Create(pendingEvent)
+	spikeEventRepo.Create(context.Background(), pendingEvent)
 
 	service := NewSpikeService(
 		spikeEventRepo,
@@ -357,6 +499,7 @@ func TestSpikeService_GetActiveEvents(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 		spikeCache,
 		nil,
 		nil,
@@ -407,7 +550,7 @@ func TestSpikeService_CancelSpikeOrder(t *testing.T) {
 		SoldCount:     20,
 		Status:        domain.SpikeEventStatusActive,
 	}
-	spikeEventRepo.Create(spikeEvent)
+	spikeEventRepo.Create(context.Background(), spikeEvent)
 
 	// 创建测试订单
 	spikeOrder := &domain.SpikeOrder{
@@ -419,7 +562,7 @@ func TestSpikeService_CancelSpikeOrder(t *testing.T) {
 		Status:         domain.SpikeOrderStatusPending,
 		IdempotencyKey: "test_order_1",
 	}
-	spikeOrderRepo.Create(spikeOrder)
+	spikeOrderRepo.Create(context.Background(), spikeOrder)
 
 	service := NewSpikeService(
 		spikeEventRepo,
@@ -428,6 +571,7 @@ func TestSpikeService_CancelSpikeOrder(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 		spikeProducer,
 		nil,
 		nil,
@@ -493,6 +637,132 @@ func TestSpikeService_CancelSpikeOrder(t *testing.T) {
 	}
 }
 
+func TestSpikeService_LookupSpikeOrder(t *testing.T) {
+	orders := NewMockSpikeOrderRepository()
+	paid := &domain.SpikeOrder{OrderNo: "1001", UserID: 1, Status: domain.SpikeOrderStatusPaid, IdempotencyKey: "key-1", PaymentRef: "sb_pi_1"}
+	other := &domain.SpikeOrder{UserID: 2, Status: domain.SpikeOrderStatusPending, IdempotencyKey: "key-2"}
+	_ = orders.Create(context.Background(), paid)
+	_ = orders.Create(context.Background(), other)
+	orderEvents := &mocks.OrderEventRepositoryMock{
+		ListBySpikeOrderIDFunc: func(spikeOrderID int64) ([]*domain.OrderEvent, error) {
+			return []*domain.OrderEvent{{SpikeOrderID: spikeOrderID, EventType: domain.OrderEventCreated}}, nil
+		},
+	}
+	svc := NewSpikeService(nil, orders, nil, nil, nil, orderEvents, nil, nil, nil, nil, DefaultSpikeServiceConfig(), zap.NewNop())
+
+	tests := []struct {
+		name    string
+		req     *domain.SpikeOrderLookupRequest
+		wantID  int64
+		wantErr error
+	}{
+		{name: "按订单号", req: &domain.SpikeOrderLookupRequest{OrderNo: "1001"}, wantID: paid.ID},
+		{name: "按幂等键", req: &domain.SpikeOrderLookupRequest{IdempotencyKey: "key-2"}, wantID: other.ID},
+		{name: "订单号与幂等键指向不同订单", req: &domain.SpikeOrderLookupRequest{OrderNo: "1001", IdempotencyKey: "key-2"}, wantErr: domain.ErrSpikeOrderNotFound},
+		{name: "按支付流水号", req: &domain.SpikeOrderLookupRequest{PaymentRef: "sb_pi_1"}, wantID: paid.ID},
+		{name: "两项指向同一订单", req: &domain.SpikeOrderLookupRequest{IdempotencyKey: "key-1", PaymentRef: "sb_pi_1"}, wantID: paid.ID},
+		{name: "两项指向不同订单", req: &domain.SpikeOrderLookupRequest{IdempotencyKey: "key-2", PaymentRef: "sb_pi_1"}, wantErr: domain.ErrSpikeOrderNotFound},
+		{name: "不存在", req: &domain.SpikeOrderLookupRequest{PaymentRef: "missing"}, wantErr: domain.ErrSpikeOrderNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.LookupSpikeOrder(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("LookupSpikeOrder() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupSpikeOrder() error = %v", err)
+			}
+			if result.Order.ID != tt.wantID || result.Timeline.SpikeOrderID != tt.wantID ||
+				len(result.Timeline.Events) != 1 || result.Timeline.Client == nil {
+				t.Errorf("LookupSpikeOrder() = %+v, want order %d with admin timeline", result, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestSpikeService_ListAndExportSpikeOrders(t *testing.T) {
+	orders := NewMockSpikeOrderRepository()
+	for i := 0; i < 5; i++ {
+		_ = orders.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: 7, UserID: int64(i + 1), Quantity: 1,
+			SpikePrice: 9.9, TotalAmount: 9.9, Status: domain.SpikeOrderStatusPaid})
+	}
+	_ = orders.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: 8, UserID: 1, Quantity: 1, Status: domain.SpikeOrderStatusPending})
+	svc := NewSpikeService(nil, orders, nil, nil, nil, nil, nil, nil, nil, nil, DefaultSpikeServiceConfig(), zap.NewNop())
+
+	eventID := int64(7)
+	list, err := svc.ListSpikeOrders(context.Background(), &domain.SpikeOrderListRequest{Page: 1, PageSize: 2, SpikeEventID: &eventID})
+	if err != nil {
+		t.Fatalf("ListSpikeOrders() error = %v", err)
+	}
+	if list.Total != 5 || len(list.Orders) != 2 || !list.HasMore {
+		t.Errorf("ListSpikeOrders() total = %d, orders = %d, has_more = %v, want 5, 2, true", list.Total, len(list.Orders), list.HasMore)
+	}
+
+	var buf bytes.Buffer
+	truncated, err := svc.ExportSpikeOrders(context.Background(), &domain.SpikeOrderListRequest{Page: 3, PageSize: 1, SpikeEventID: &eventID}, &buf)
+	if err != nil || truncated {
+		t.Fatalf("ExportSpikeOrders() = %v, %v, want false, nil", truncated, err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\uFEFF"))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse exported csv: %v", err)
+	}
+	// 导出忽略分页参数：表头 + 活动 7 的全部 5 个订单
+	if len(records) != 6 || records[0][0] != "id" {
+		t.Fatalf("ExportSpikeOrders() rows = %d, want 6 with header", len(records))
+	}
+	for _, record := range records[1:] {
+		if record[1] != "7" || record[5] != "9.90" || record[7] != string(domain.SpikeOrderStatusPaid) {
+			t.Errorf("ExportSpikeOrders() row = %v, want event 7 paid order", record)
+		}
+	}
+}
+
+func TestSpikeService_ListSpikeOrdersWithCursor(t *testing.T) {
+	orders := NewMockSpikeOrderRepository()
+	base := time.Now().Add(-time.Hour)
+	// 订单 2 与 3 创建时间相同，按 id 决定先后
+	for i, offset := range []time.Duration{0, time.Minute, time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		order := &domain.SpikeOrder{SpikeEventID: 7, UserID: int64(i + 1), Quantity: 1, Status: domain.SpikeOrderStatusPaid}
+		_ = orders.Create(context.Background(), order)
+		order.CreatedAt = base.Add(offset)
+	}
+	svc := NewSpikeService(nil, orders, nil, nil, nil, nil, nil, nil, nil, nil, DefaultSpikeServiceConfig(), zap.NewNop())
+
+	var got []int64
+	req := &domain.SpikeOrderListRequest{Page: 1, PageSize: 2}
+	for range 3 {
+		list, err := svc.ListSpikeOrders(context.Background(), req)
+		if err != nil {
+			t.Fatalf("ListSpikeOrders() error = %v", err)
+		}
+		for _, order := range list.Orders {
+			got = append(got, order.ID)
+		}
+		if !list.HasMore {
+			break
+		}
+		cursor, err := domain.DecodeListCursor(list.NextCursor)
+		if err != nil {
+			t.Fatalf("DecodeListCursor(%q) error = %v", list.NextCursor, err)
+		}
+		req = &domain.SpikeOrderListRequest{Page: 1, PageSize: 2, After: cursor}
+	}
+
+	want := []int64{5, 4, 3, 2, 1}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("paged order ids = %v, want %v", got, want)
+	}
+
+	if _, err := domain.DecodeListCursor("2"); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("DecodeListCursor(page number) error = %v, want ErrInvalidArgument", err)
+	}
+}
+
 func TestSpikeService_GetSpikeStats(t *testing.T) {
 	spikeEventRepo := NewMockSpikeEventRepository()
 	spikeOrderRepo := NewMockSpikeOrderRepository()
@@ -511,7 +781,7 @@ func TestSpikeService_GetSpikeStats(t *testing.T) {
 		SoldCount:     30,
 		Status:        domain.SpikeEventStatusActive,
 	}
-	spikeEventRepo.Create(spikeEvent)
+	spikeEventRepo.Create(context.Background(), spikeEvent)
 
 	// 创建测试订单
 	pendingOrder := &domain.SpikeOrder{
@@ -524,8 +794,8 @@ func TestSpikeService_GetSpikeStats(t *testing.T) {
 		UserID:       2,
 		Status:       domain.SpikeOrderStatusPaid,
 	}
-	spikeOrderRepo.Create(pendingOrder)
-	spikeOrderRepo.Create(paidOrder)
+	spikeOrderRepo.Create(context.Background(), pendingOrder)
+	spikeOrderRepo.Create(context.Background(), paidOrder)
 
 	// 设置缓存库存
 	spikeCache.WarmupStock(context.Background(), spikeEvent.ID, 65, time.Hour)
@@ -536,6 +806,7 @@ func TestSpikeService_GetSpikeStats(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 		spikeCache,
 		nil,
 		nil,
@@ -581,6 +852,58 @@ func TestSpikeService_GetSpikeStats(t *testing.T) {
 	}
 }
 
+// fakeReplicationLag 固定的复制延迟，ctx 允许读副本时报告读副本
+type fakeReplicationLag struct {
+	lag time.Duration
+}
+
+func (f fakeReplicationLag) ReplicationLag(ctx context.Context) (time.Duration, bool) {
+	if !database.ReplicaReadsAllowed(ctx) {
+		return 0, false
+	}
+	return f.lag, true
+}
+
+func TestSpikeService_GetSpikeStatsReportsReplicationLag(t *testing.T) {
+	spikeEventRepo := NewMockSpikeEventRepository()
+	spikeEvent := &domain.SpikeEvent{
+		ProductID:  1,
+		StartAt:    time.Now().Add(-time.Hour),
+		EndAt:      time.Now().Add(time.Hour),
+		SpikeStock: 100,
+		Status:     domain.SpikeEventStatusActive,
+	}
+	spikeEventRepo.Create(context.Background(), spikeEvent)
+
+	service := NewSpikeService(spikeEventRepo, NewMockSpikeOrderRepository(), nil, nil, nil, nil,
+		NewMockSpikeCache(), nil, nil, nil, DefaultSpikeServiceConfig(), zap.NewNop())
+
+	stats, err := service.GetSpikeStats(context.Background(), spikeEvent.ID)
+	if err != nil {
+		t.Fatalf("GetSpikeStats() error = %v", err)
+	}
+	if stats.DataSource != "" {
+		t.Errorf("DataSource = %q, want empty without replicas", stats.DataSource)
+	}
+
+	service.SetReplicationLag(fakeReplicationLag{lag: 1500 * time.Millisecond})
+	stats, err = service.GetSpikeStats(database.WithReplicaReads(context.Background()), spikeEvent.ID)
+	if err != nil {
+		t.Fatalf("GetSpikeStats() error = %v", err)
+	}
+	if stats.DataSource != ReadSourceReplica || stats.ReplicationLagMs != 1500 {
+		t.Errorf("stats = %s/%dms, want replica/1500ms", stats.DataSource, stats.ReplicationLagMs)
+	}
+
+	stats, err = service.GetSpikeStats(context.Background(), spikeEvent.ID)
+	if err != nil {
+		t.Fatalf("GetSpikeStats() error = %v", err)
+	}
+	if stats.DataSource != ReadSourcePrimary || stats.ReplicationLagMs != 0 {
+		t.Errorf("stats = %s/%dms, want primary", stats.DataSource, stats.ReplicationLagMs)
+	}
+}
+
 func TestSpikeService_WarmupStock(t *testing.T) {
 	spikeEventRepo := NewMockSpikeEventRepository()
 	spikeCache := NewMockSpikeCache()
@@ -598,10 +921,11 @@ func TestSpikeService_WarmupStock(t *testing.T) {
 		SoldCount:     10,
 		Status:        domain.SpikeEventStatusPending,
 	}
-	spikeEventRepo.Create(spikeEvent)
+	spikeEventRepo.Create(context.Background(), spikeEvent)
 
 	service := NewSpikeService(
 		spikeEventRepo,
+		NewMockSpikeOrderRepository(),
 		nil,
 		nil,
 		nil,
@@ -614,7 +938,7 @@ func TestSpikeService_WarmupStock(t *testing.T) {
 		logger,
 	)
 
-	err := service.WarmupStock(context.Background(), spikeEvent.ID)
+	err := service.WarmupStock(context.Background(), spikeEvent.ID, false)
 	if err != nil {
 		t.Errorf("WarmupStock() unexpected error = %v", err)
 	}
@@ -635,13 +959,55 @@ func TestSpikeService_WarmupStock(t *testing.T) {
 	}
 }
 
+func TestSpikeService_WarmupStock_ActiveEvent(t *testing.T) {
+	ctx := context.Background()
+	spikeEventRepo := NewMockSpikeEventRepository()
+	spikeOrderRepo := NewMockSpikeOrderRepository()
+	spikeCache := NewMockSpikeCache()
+
+	spikeEvent := &domain.SpikeEvent{
+		ProductID:  1,
+		Name:       "Live Event",
+		StartAt:    time.Now().Add(-time.Minute),
+		EndAt:      time.Now().Add(time.Hour),
+		SpikeStock: 100,
+		SoldCount:  10,
+		Status:     domain.SpikeEventStatusActive,
+	}
+	spikeEventRepo.Create(context.Background(), spikeEvent)
+	// 在途待支付订单与已支付订单共 30 件，多于活动记录的已售数量
+	spikeOrderRepo.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: spikeEvent.ID, UserID: 1, Quantity: 20, Status: domain.SpikeOrderStatusPending})
+	spikeOrderRepo.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: spikeEvent.ID, UserID: 2, Quantity: 10, Status: domain.SpikeOrderStatusPaid})
+	spikeOrderRepo.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: spikeEvent.ID, UserID: 3, Quantity: 5, Status: domain.SpikeOrderStatusCancelled})
+
+	service := NewSpikeService(spikeEventRepo, spikeOrderRepo, nil, nil, nil, nil,
+		spikeCache, nil, nil, nil, DefaultSpikeServiceConfig(), zap.NewNop())
+
+	// 已有库存键时不强制预热会被拒绝，且不修改缓存
+	spikeCache.WarmupStock(ctx, spikeEvent.ID, 3, time.Hour)
+	if err := service.WarmupStock(ctx, spikeEvent.ID, false); !errors.Is(err, domain.ErrSpikeStockAlreadyWarmed) {
+		t.Fatalf("WarmupStock() error = %v, want ErrSpikeStockAlreadyWarmed", err)
+	}
+	if info, _ := spikeCache.GetStockInfo(ctx, spikeEvent.ID); info.Stock != 3 {
+		t.Errorf("WarmupStock() overwrote cached stock to %d", info.Stock)
+	}
+
+	// 强制预热按数据库剩余库存扣除在途订单覆盖
+	if err := service.WarmupStock(ctx, spikeEvent.ID, true); err != nil {
+		t.Fatalf("WarmupStock(force) error = %v", err)
+	}
+	if info, _ := spikeCache.GetStockInfo(ctx, spikeEvent.ID); info.Stock != 70 {
+		t.Errorf("WarmupStock(force) cached stock = %d, want 70", info.Stock)
+	}
+}
+
 // 测试并发安全性
 func TestSpikeService_ConcurrentParticipation(t *testing.T) {
 	spikeEventRepo := NewMockSpikeEventRepository()
 	spikeOrderRepo := NewMockSpikeOrderRepository()
 	productRepo := newMockProductRepository()
 	inventoryRepo := newMockInventoryRepository()
-	userRepo := NewMockUserRepository()
+	userRepo := newFakeUserRepo()
 	spikeCache := NewMockSpikeCache()
 	spikeProducer := NewMockSpikeProducer()
 	globalLimiter := NewMockLimiter(true)
@@ -675,7 +1041,7 @@ func TestSpikeService_ConcurrentParticipation(t *testing.T) {
 		SoldCount:     0,
 		Status:        domain.SpikeEventStatusActive,
 	}
-	spikeEventRepo.Create(spikeEvent)
+	spikeEventRepo.Create(context.Background(), spikeEvent)
 
 	// 只预热10个库存
 	spikeCache.WarmupStock(context.Background(), spikeEvent.ID, 10, time.Hour)
@@ -686,6 +1052,7 @@ func TestSpikeService_ConcurrentParticipation(t *testing.T) {
 		productRepo,
 		inventoryRepo,
 		userRepo,
+		nil,
 		spikeCache,
 		spikeProducer,
 		globalLimiter,
@@ -698,8 +1065,8 @@ func TestSpikeService_ConcurrentParticipation(t *testing.T) {
 	users := make([]*domain.User, 20)
 	for i := 0; i < 20; i++ {
 		user := &domain.User{
-			Username: "user" + string(rune('0'+i)),
-			Email:    "user" + string(rune('0'+i)) + "@example.com",
+			Username: "user" + strconv.Itoa(i),
+			Email:    "user" + strconv.Itoa(i) + "@example.com",
 			Role:     domain.UserRoleUser,
 			IsActive: true,
 		}
@@ -717,7 +1084,7 @@ func TestSpikeService_ConcurrentParticipation(t *testing.T) {
 			req := &domain.SpikeParticipationRequest{
 				SpikeEventID:   spikeEvent.ID,
 				Quantity:       1,
-				IdempotencyKey: "concurrent_test_" + string(rune('0'+userIndex)),
+				IdempotencyKey: keys.Idempotency("concurrent_test", userIndex),
 			}
 
 			result, err := service.ParticipateSpike(context.Background(), req, users[userIndex].ID)
@@ -751,3 +1118,485 @@ func TestSpikeService_ConcurrentParticipation(t *testing.T) {
 
 	t.Logf("Successful participations: %d/20", successCount)
 }
+
+func TestBuildCapacityPlan(t *testing.T) {
+	event := &domain.SpikeEvent{ID: 1, SpikeStock: 1000}
+
+	t.Run("no history uses default sellout", func(t *testing.T) {
+		plan := buildCapacityPlan(event, 1, &capacityHistory{})
+
+		if plan.ExpectedOrders != 1000 {
+			t.Errorf("ExpectedOrders = %d, want 1000", plan.ExpectedOrders)
+		}
+		if plan.PeakOrdersPerSecond != 100 {
+			t.Errorf("PeakOrdersPerSecond = %v, want 100", plan.PeakOrdersPerSecond)
+		}
+		if plan.Limiter.GlobalBurst != 3000 {
+			t.Errorf("Limiter.GlobalBurst = %d, want 3000", plan.Limiter.GlobalBurst)
+		}
+		if plan.Consumer.OrderConcurrency != 8 {
+			t.Errorf("Consumer.OrderConcurrency = %d, want 8", plan.Consumer.OrderConcurrency)
+		}
+		if len(plan.Notes) == 0 {
+			t.Error("Notes should mention missing history")
+		}
+	})
+
+	t.Run("faster history raises peak", func(t *testing.T) {
+		plan := buildCapacityPlan(event, 2, &capacityHistory{
+			sampled:      2,
+			ordersPerSec: 500,
+			selloutSecs:  2,
+			selloutRatio: 1,
+		})
+
+		if plan.ExpectedOrders != 500 {
+			t.Errorf("ExpectedOrders = %d, want 500", plan.ExpectedOrders)
+		}
+		if plan.PeakOrdersPerSecond != 500 {
+			t.Errorf("PeakOrdersPerSecond = %v, want 500", plan.PeakOrdersPerSecond)
+		}
+		if plan.Limiter.UserRatePerMinute != 10 {
+			t.Errorf("Limiter.UserRatePerMinute = %d, want 10", plan.Limiter.UserRatePerMinute)
+		}
+		if plan.DatabasePool.MaxOpenConns <= plan.Consumer.OrderConcurrency {
+			t.Errorf("DatabasePool.MaxOpenConns = %d, should exceed consumer concurrency %d",
+				plan.DatabasePool.MaxOpenConns, plan.Consumer.OrderConcurrency)
+		}
+	})
+}
+
+func TestSpikeService_PlanCapacity_History(t *testing.T) {
+	ctx := context.Background()
+	events := NewMockSpikeEventRepository()
+	past := testutil.NewSpikeEventBuilder().ForProduct(3).WithStock(4).WithSold(4).Ended().Build()
+	current := testutil.NewSpikeEventBuilder().ForProduct(3).WithStock(1000).Pending().Build()
+	testutil.SeedSpikeEvents(t, events, past, current)
+
+	// 历史活动 4 笔订单在 2 秒内成交
+	orders := NewMockSpikeOrderRepository()
+	start := time.Now().Add(-24 * time.Hour)
+	for i := 0; i < 4; i++ {
+		order := &domain.SpikeOrder{SpikeEventID: past.ID, UserID: int64(i + 1), Quantity: 1}
+		testutil.SeedSpikeOrders(t, orders, order)
+		order.CreatedAt = start.Add(time.Duration(i) * 2 * time.Second / 3)
+	}
+
+	svc := NewSpikeService(events, orders, nil, nil, nil, nil, NewMockSpikeCache(), NewMockSpikeProducer(),
+		NewMockLimiter(true), NewMockLimiter(true), DefaultSpikeServiceConfig(), zap.NewNop())
+	plan, err := svc.PlanCapacity(ctx, current.ID, 1)
+	if err != nil {
+		t.Fatalf("PlanCapacity() error = %v", err)
+	}
+
+	if plan.HistoricalEventsSampled != 1 || plan.HistoricalOrdersPerSec != 2 || plan.ExpectedSelloutSeconds != 2 {
+		t.Errorf("history = %d events, %v orders/s, %vs sellout, want 1 event, 2 orders/s, 2s",
+			plan.HistoricalEventsSampled, plan.HistoricalOrdersPerSec, plan.ExpectedSelloutSeconds)
+	}
+	// 只做聚合查询，不加载历史活动的订单明细
+	if n := len(orders.GetBySpikeEventIDCalls()); n != 0 {
+		t.Errorf("GetBySpikeEventID() called %d times, want aggregate query only", n)
+	}
+}
+
+func TestWriteSpikeReport(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	paidAt := start.Add(2 * time.Minute)
+	event := &domain.SpikeEvent{
+		ID: 7, ProductID: 3, Name: "新年秒杀", SpikePrice: 9.9, SpikeStock: 10, SoldCount: 3,
+		StartAt: start, EndAt: start.Add(time.Hour), Status: domain.SpikeEventStatusEnded,
+	}
+	orders := []*domain.SpikeOrder{
+		{ID: 1, UserID: 100, Quantity: 1, SpikePrice: 9.9, TotalAmount: 9.9, Status: domain.SpikeOrderStatusPaid, CreatedAt: start, PaidAt: &paidAt,
+			Channel: domain.SpikeOrderChannelApp, ClientIP: "10.0.0.1", UserAgent: "SpikeApp/1.0"},
+		{ID: 2, UserID: 101, Quantity: 2, SpikePrice: 9.9, TotalAmount: 19.8, Status: domain.SpikeOrderStatusPaid, CreatedAt: start,
+			Channel: domain.SpikeOrderChannelApp},
+		{ID: 3, UserID: 102, Quantity: 1, SpikePrice: 9.9, TotalAmount: 9.9, Status: domain.SpikeOrderStatusCancelled, CreatedAt: start,
+			Channel: domain.SpikeOrderChannelWeb},
+		{ID: 4, UserID: 103, Quantity: 1, SpikePrice: 9.9, TotalAmount: 9.9, Status: domain.SpikeOrderStatusExpired, CreatedAt: start},
+	}
+	events := []*domain.OrderEvent{
+		{SpikeOrderID: 1, EventType: domain.OrderEventCreated, CreatedAt: start},
+		{SpikeOrderID: 3, EventType: domain.OrderEventCreated, CreatedAt: start.Add(10 * time.Second)},
+		{SpikeOrderID: 1, EventType: domain.OrderEventPaid, CreatedAt: paidAt},
+		{SpikeOrderID: 3, EventType: domain.OrderEventCancelled, Remark: "不想要了", CreatedAt: paidAt},
+		{SpikeOrderID: 4, EventType: domain.OrderEventExpired, CreatedAt: start.Add(15 * time.Minute)},
+	}
+
+	attributions := []*domain.SpikeShareAttribution{
+		{SpikeEventID: 7, Campaign: "wechat", Visits: 12, Participants: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSpikeReport(&buf, event, orders, events, attributions, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("writeSpikeReport() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "\uFEFF") {
+		t.Error("writeSpikeReport() missing UTF-8 BOM")
+	}
+
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, "\uFEFF")))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("writeSpikeReport() produced invalid CSV: %v", err)
+	}
+
+	sections := make(map[string][][]string)
+	var current string
+	for _, rec := range records {
+		if len(rec) == 2 && rec[0] == "section" {
+			current = rec[1]
+			continue
+		}
+		sections[current] = append(sections[current], rec)
+	}
+
+	for _, name := range []string{"summary", "status_counts", "revenue", "top_failure_reasons", "timeline", "share_attribution", "channels", "orders"} {
+		if _, ok := sections[name]; !ok {
+			t.Errorf("writeSpikeReport() missing section %q", name)
+		}
+	}
+
+	if revenue := sections["revenue"][1]; revenue[1] != "2" || revenue[3] != "29.70" || revenue[4] != "50.00" {
+		t.Errorf("revenue row = %v, want 2 paid orders, 29.70 revenue, 50.00%% conversion", revenue)
+	}
+	if reasons := sections["top_failure_reasons"]; len(reasons) != 3 || reasons[1][1] != "不想要了" || reasons[2][1] != "(none)" {
+		t.Errorf("top_failure_reasons = %v", reasons)
+	}
+	// 表头 + 3 个有事件的分钟（10:00、10:02、10:15）
+	if timeline := sections["timeline"]; len(timeline) != 4 || timeline[1][1] != "2" {
+		t.Errorf("timeline = %v", timeline)
+	}
+	if share := sections["share_attribution"]; len(share) != 2 || share[1][0] != "wechat" || share[1][1] != "12" || share[1][2] != "2" {
+		t.Errorf("share_attribution = %v", share)
+	}
+	// 未记录渠道的历史订单计入 (unknown)
+	if channels := sections["channels"]; len(channels) != 4 || channels[1][0] != "(unknown)" ||
+		channels[2][0] != "app" || channels[2][2] != "2" || channels[2][3] != "29.70" || channels[3][1] != "1" {
+		t.Errorf("channels = %v", channels)
+	}
+	if got := len(sections["orders"]); got != len(orders)+1 {
+		t.Errorf("orders rows = %d, want %d", got, len(orders)+1)
+	}
+	if first := sections["orders"][1]; first[9] != "app" || first[10] != "10.0.0.1" || first[11] != "SpikeApp/1.0" {
+		t.Errorf("first order row = %v, want client metadata", first)
+	}
+}
+
+type fakeEventWarmer struct {
+	warmed []int64
+	err    error
+}
+
+func (f *fakeEventWarmer) WarmupEvent(ctx context.Context, event *domain.SpikeEvent) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.warmed = append(f.warmed, event.ID)
+	return nil
+}
+
+func TestSpikeScheduler_RunOnce(t *testing.T) {
+	spikeEventRepo := NewMockSpikeEventRepository()
+	now := time.Now()
+	previewStart := now.Add(-time.Minute)
+	futurePreview := now.Add(time.Hour)
+
+	inPreview := &domain.SpikeEvent{
+		Name:           "in preview",
+		PreviewStartAt: &previewStart,
+		StartAt:        now.Add(time.Minute),
+		EndAt:          now.Add(time.Hour),
+		SpikeStock:     10,
+		Status:         domain.SpikeEventStatusPending,
+	}
+	notYet := &domain.SpikeEvent{
+		Name:           "preview not started",
+		PreviewStartAt: &futurePreview,
+		StartAt:        now.Add(2 * time.Hour),
+		EndAt:          now.Add(3 * time.Hour),
+		SpikeStock:     10,
+		Status:         domain.SpikeEventStatusPending,
+	}
+	noPreview := &domain.SpikeEvent{
+		Name:       "no preview",
+		StartAt:    now.Add(time.Minute),
+		EndAt:      now.Add(time.Hour),
+		SpikeStock: 10,
+		Status:     domain.SpikeEventStatusPending,
+	}
+	for _, e := range []*domain.SpikeEvent{inPreview, notYet, noPreview} {
+		spikeEventRepo.Create(context.Background(), e)
+	}
+
+	warmer := &fakeEventWarmer{}
+	scheduler := NewSpikeScheduler(spikeEventRepo, warmer, time.Second, zap.NewNop())
+
+	if err := scheduler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(warmer.warmed) != 1 || warmer.warmed[0] != inPreview.ID {
+		t.Fatalf("warmed = %v, want [%d]", warmer.warmed, inPreview.ID)
+	}
+
+	// 已预热的活动不重复预热
+	if err := scheduler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(warmer.warmed) != 1 {
+		t.Errorf("warmed = %v, want no repeat warmup", warmer.warmed)
+	}
+
+	// 活动改期后重新预热
+	inPreview.StartAt = now.Add(2 * time.Minute)
+	if err := scheduler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(warmer.warmed) != 2 {
+		t.Errorf("warmed = %v, want rewarm after reschedule", warmer.warmed)
+	}
+}
+
+// fakeStockWarmer 按活动总库存写入缓存的预热器
+type fakeStockWarmer struct {
+	events *MockSpikeEventRepository
+	cache  *fakeGuardianCache
+	warmed []int64
+}
+
+func (f *fakeStockWarmer) WarmupStock(ctx context.Context, eventID int64, force bool) error {
+	event, err := f.events.GetByID(context.Background(), eventID)
+	if err != nil {
+		return err
+	}
+	f.warmed = append(f.warmed, eventID)
+	return f.cache.WarmupStock(ctx, eventID, event.GetRemainingStock(), time.Hour)
+}
+
+func TestStockWarmupScheduler_RunOnce(t *testing.T) {
+	spikeEventRepo := NewMockSpikeEventRepository()
+	now := time.Now()
+
+	soon := &domain.SpikeEvent{Name: "soon", SpikeStock: 10, StartAt: now.Add(2 * time.Minute), EndAt: now.Add(time.Hour), Status: domain.SpikeEventStatusPending}
+	later := &domain.SpikeEvent{Name: "later", SpikeStock: 10, StartAt: now.Add(time.Hour), EndAt: now.Add(2 * time.Hour), Status: domain.SpikeEventStatusPending}
+	prewarmed := &domain.SpikeEvent{Name: "prewarmed", SpikeStock: 10, StartAt: now.Add(time.Minute), EndAt: now.Add(time.Hour), Status: domain.SpikeEventStatusPending}
+	locked := &domain.SpikeEvent{Name: "locked", SpikeStock: 10, StartAt: now.Add(3 * time.Minute), EndAt: now.Add(time.Hour), Status: domain.SpikeEventStatusPending}
+	for _, e := range []*domain.SpikeEvent{soon, later, prewarmed, locked} {
+		spikeEventRepo.Create(context.Background(), e)
+	}
+
+	stockCache := newFakeGuardianCache()
+	stockCache.stock[prewarmed.ID] = 3
+	stockCache.locked[locked.ID] = "other-instance"
+	warmer := &fakeStockWarmer{events: spikeEventRepo, cache: stockCache}
+	scheduler := NewStockWarmupScheduler(spikeEventRepo, warmer, stockCache, 5*time.Minute, time.Second, zap.NewNop())
+
+	// 只预热窗口内的活动；已预热的不覆盖，其他实例持锁的跳过
+	if err := scheduler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(warmer.warmed) != 1 || warmer.warmed[0] != soon.ID {
+		t.Fatalf("warmed = %v, want [%d]", warmer.warmed, soon.ID)
+	}
+	if stockCache.stock[soon.ID] != 10 || stockCache.stock[prewarmed.ID] != 3 {
+		t.Errorf("stock = %v, want soon warmed and prewarmed untouched", stockCache.stock)
+	}
+	if len(stockCache.locked) != 1 {
+		t.Errorf("locked = %v, want only the other instance's lock", stockCache.locked)
+	}
+
+	// 锁释放后下一轮补上，已预热的活动不再重复
+	delete(stockCache.locked, locked.ID)
+	if err := scheduler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(warmer.warmed) != 2 || warmer.warmed[1] != locked.ID {
+		t.Errorf("warmed = %v, want locked event warmed once released", warmer.warmed)
+	}
+}
+
+func TestSpikeEvent_PreviewWindow(t *testing.T) {
+	now := time.Now()
+	previewStart := now.Add(-time.Minute)
+	event := &domain.SpikeEvent{
+		PreviewStartAt: &previewStart,
+		StartAt:        now.Add(30 * time.Second),
+		EndAt:          now.Add(time.Hour),
+		Status:         domain.SpikeEventStatusPending,
+	}
+
+	if !event.IsVisible() || !event.IsInPreview() {
+		t.Errorf("event should be visible and in preview")
+	}
+	if got := event.SecondsToStart(0); got < 29 || got > 30 {
+		t.Errorf("SecondsToStart(0) = %d, want ~30", got)
+	}
+	if got := event.SecondsToStart(time.Minute); got != 0 {
+		t.Errorf("SecondsToStart(1m) = %d, want 0 with early access", got)
+	}
+
+	future := now.Add(time.Minute)
+	event.PreviewStartAt = &future
+	if event.IsVisible() || event.IsInPreview() {
+		t.Errorf("event should be hidden before preview starts")
+	}
+}
+
+func TestParticipationTokenSigner(t *testing.T) {
+	signer := NewParticipationTokenSigner("secret")
+	expiresAt := time.Now().Add(time.Hour)
+	token := signer.Sign(1, 42, expiresAt)
+
+	if token != signer.Sign(1, 42, expiresAt) {
+		t.Errorf("Sign() should be deterministic for the same user and event")
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		eventID int64
+		userID  int64
+		wantErr error
+	}{
+		{name: "valid", token: token, eventID: 1, userID: 42},
+		{name: "other user", token: token, eventID: 1, userID: 43, wantErr: ErrParticipationTokenInvalid},
+		{name: "other event", token: token, eventID: 2, userID: 42, wantErr: ErrParticipationTokenInvalid},
+		{name: "tampered", token: token + "x", eventID: 1, userID: 42, wantErr: ErrParticipationTokenInvalid},
+		{name: "empty", token: "", eventID: 1, userID: 42, wantErr: ErrParticipationTokenInvalid},
+		{name: "wrong secret", token: NewParticipationTokenSigner("other").Sign(1, 42, expiresAt), eventID: 1, userID: 42, wantErr: ErrParticipationTokenInvalid},
+		{name: "expired", token: signer.Sign(1, 42, time.Now().Add(-time.Minute)), eventID: 1, userID: 42, wantErr: ErrParticipationTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.Verify(tt.token, tt.eventID, tt.userID); err != tt.wantErr {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeGuardianCache 库存守护测试用的内存缓存
+type fakeGuardianCache struct {
+	stock  map[int64]int64
+	frozen map[int64]bool
+	locked map[int64]string
+}
+
+func newFakeGuardianCache() *fakeGuardianCache {
+	return &fakeGuardianCache{
+		stock:  make(map[int64]int64),
+		frozen: make(map[int64]bool),
+		locked: make(map[int64]string),
+	}
+}
+
+func (f *fakeGuardianCache) GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+	stock, ok := f.stock[eventID]
+	return &cache.StockInfo{Stock: stock, SoldOut: ok && stock <= 0, Exists: ok}, nil
+}
+
+func (f *fakeGuardianCache) WarmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error {
+	f.stock[eventID] = stock
+	return nil
+}
+
+func (f *fakeGuardianCache) WarmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error {
+	return f.WarmupStock(ctx, eventID, stock, ttl)
+}
+
+func (f *fakeGuardianCache) FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error {
+	f.frozen[eventID] = true
+	return nil
+}
+
+func (f *fakeGuardianCache) UnfreezeEvent(ctx context.Context, eventID int64) error {
+	delete(f.frozen, eventID)
+	return nil
+}
+
+func (f *fakeGuardianCache) AcquireRewarmLock(ctx context.Context, eventID int64, owner string, ttl time.Duration) (bool, error) {
+	if _, ok := f.locked[eventID]; ok {
+		return false, nil
+	}
+	f.locked[eventID] = owner
+	return true, nil
+}
+
+func (f *fakeGuardianCache) ReleaseRewarmLock(ctx context.Context, eventID int64, owner string) error {
+	if f.locked[eventID] == owner {
+		delete(f.locked, eventID)
+	}
+	return nil
+}
+
+func TestStockGuardian_CheckAll(t *testing.T) {
+	spikeEventRepo := NewMockSpikeEventRepository()
+	spikeOrderRepo := NewMockSpikeOrderRepository()
+	now := time.Now()
+
+	lost := &domain.SpikeEvent{
+		Name:       "lost stock key",
+		SpikeStock: 100,
+		SoldCount:  10,
+		StartAt:    now.Add(-time.Minute),
+		EndAt:      now.Add(time.Hour),
+		Status:     domain.SpikeEventStatusActive,
+	}
+	healthy := &domain.SpikeEvent{
+		Name:       "healthy",
+		SpikeStock: 50,
+		StartAt:    now.Add(-time.Minute),
+		EndAt:      now.Add(time.Hour),
+		Status:     domain.SpikeEventStatusActive,
+	}
+	spikeEventRepo.Create(context.Background(), lost)
+	spikeEventRepo.Create(context.Background(), healthy)
+
+	// 数据库中已落库 30 件（含尚未同步到 sold_count 的订单），已取消订单不计入
+	spikeOrderRepo.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: lost.ID, Quantity: 20, Status: domain.SpikeOrderStatusPaid})
+	spikeOrderRepo.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: lost.ID, Quantity: 10, Status: domain.SpikeOrderStatusPending})
+	spikeOrderRepo.Create(context.Background(), &domain.SpikeOrder{SpikeEventID: lost.ID, Quantity: 5, Status: domain.SpikeOrderStatusCancelled})
+
+	stockCache := newFakeGuardianCache()
+	stockCache.stock[healthy.ID] = 7
+
+	config := DefaultStockGuardianConfig()
+	config.GracePeriod = 0
+	guardian := NewStockGuardian(spikeEventRepo, spikeOrderRepo, stockCache, config, zap.NewNop())
+
+	guardian.CheckAll(context.Background())
+
+	if got := stockCache.stock[lost.ID]; got != 70 {
+		t.Errorf("recovered stock = %d, want 70", got)
+	}
+	if got := stockCache.stock[healthy.ID]; got != 7 {
+		t.Errorf("healthy stock = %d, want untouched 7", got)
+	}
+	if stockCache.frozen[lost.ID] {
+		t.Errorf("event should be unfrozen after recovery")
+	}
+	if len(stockCache.locked) != 0 {
+		t.Errorf("rewarm lock should be released, got %v", stockCache.locked)
+	}
+}
+
+func TestStockGuardian_RecoverSkipsWhenLocked(t *testing.T) {
+	event := &domain.SpikeEvent{ID: 1, SpikeStock: 10, Status: domain.SpikeEventStatusActive}
+	stockCache := newFakeGuardianCache()
+	stockCache.locked[event.ID] = "other-instance"
+
+	guardian := NewStockGuardian(NewMockSpikeEventRepository(), NewMockSpikeOrderRepository(), stockCache, nil, zap.NewNop())
+	if err := guardian.Recover(context.Background(), event); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if _, ok := stockCache.stock[event.ID]; ok {
+		t.Errorf("Recover() should not rewarm while another instance holds the lock")
+	}
+	if stockCache.frozen[event.ID] {
+		t.Errorf("Recover() should not freeze while another instance holds the lock")
+	}
+}
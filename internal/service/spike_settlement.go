@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// SpikeSettlementService 定义活动结算服务接口
+type SpikeSettlementService interface {
+	// SettleEvent 按结算日汇总活动的收入、退款与手续费并写入结算，重复执行只覆盖待打款的结算
+	SettleEvent(ctx context.Context, spikeEventID int64) ([]*domain.SpikeSettlement, error)
+	// ListSettlements 分页查询结算
+	ListSettlements(ctx context.Context, req *domain.SpikeSettlementListRequest) (*domain.SpikeSettlementListResponse, error)
+	// MarkPaidOut 将待打款的结算标记为已打款
+	MarkPaidOut(ctx context.Context, id, adminID int64, reference string) (*domain.SpikeSettlement, error)
+}
+
+// spikeSettlementService 是SpikeSettlementService接口的实现
+type spikeSettlementService struct {
+	repo    repo.SpikeSettlementRepository
+	feeRate float64
+	logger  *zap.Logger
+}
+
+// NewSpikeSettlementService 创建活动结算服务，feeRate 为平台手续费率（0 ~ 1），按收入扣除退款后的金额计算
+func NewSpikeSettlementService(settlementRepo repo.SpikeSettlementRepository, feeRate float64, logger *zap.Logger) SpikeSettlementService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeSettlementService{
+		repo:    settlementRepo,
+		feeRate: feeRate,
+		logger:  logger,
+	}
+}
+
+// SettleEvent 结算活动
+func (s *spikeSettlementService) SettleEvent(ctx context.Context, spikeEventID int64) ([]*domain.SpikeSettlement, error) {
+	daily, err := s.repo.AggregateDaily(spikeEventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate settlement: %w", err)
+	}
+
+	settlements := make([]*domain.SpikeSettlement, 0, len(daily))
+	for _, d := range daily {
+		settlement := newSettlement(spikeEventID, d, s.feeRate)
+		if err := s.repo.Upsert(settlement); err != nil {
+			return nil, err
+		}
+		settlements = append(settlements, settlement)
+	}
+	return settlements, nil
+}
+
+// ListSettlements 查询结算
+func (s *spikeSettlementService) ListSettlements(ctx context.Context, req *domain.SpikeSettlementListRequest) (*domain.SpikeSettlementListResponse, error) {
+	items, total, err := s.repo.List(req)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*domain.SpikeSettlement{}
+	}
+	items, pageInfo := domain.Paginate(items, total, req.Page, req.PageSize)
+
+	return &domain.SpikeSettlementListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// MarkPaidOut 标记结算已打款，已打款的结算不能重复标记
+func (s *spikeSettlementService) MarkPaidOut(ctx context.Context, id, adminID int64, reference string) (*domain.SpikeSettlement, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.MarkPaidOut(id, domain.AdminActor(adminID), reference, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, domain.ErrSettlementAlreadyPaidOut
+	}
+
+	s.logger.Info("结算已标记打款",
+		zap.Int64("settlement_id", id),
+		zap.Int64("admin_id", adminID),
+		zap.String("payout_reference", reference))
+	return s.repo.GetByID(id)
+}
+
+// newSettlement 由结算日汇总计算手续费与应打款金额，金额保留两位小数
+func newSettlement(spikeEventID int64, d *domain.SpikeSettlementDaily, feeRate float64) *domain.SpikeSettlement {
+	fee := roundCents(math.Max(d.GrossAmount-d.RefundAmount, 0) * feeRate)
+	return &domain.SpikeSettlement{
+		SpikeEventID: spikeEventID,
+		SettleDate:   d.SettleDate,
+		OrderCount:   d.OrderCount,
+		Quantity:     d.Quantity,
+		GrossAmount:  roundCents(d.GrossAmount),
+		RefundAmount: roundCents(d.RefundAmount),
+		FeeAmount:    fee,
+		NetAmount:    roundCents(d.GrossAmount - d.RefundAmount - fee),
+		Status:       domain.SpikeSettlementStatusPending,
+	}
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// SettlementEventSource 提供指定时间范围内的活动（由 repo.SpikeEventRepository 实现）
+type SettlementEventSource interface {
+	GetEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*domain.SpikeEvent, error)
+}
+
+// SettlementWorkerConfig 结算任务配置
+type SettlementWorkerConfig struct {
+	Interval time.Duration // 结算任务执行间隔
+	Delay    time.Duration // 活动结束后等待多久再结算，应覆盖订单支付期限，使待支付订单完成支付或过期
+	Lookback time.Duration // 对结束多久以内的活动重复结算，覆盖迟到的支付与退款
+}
+
+// DefaultSettlementWorkerConfig 默认结算任务配置
+func DefaultSettlementWorkerConfig() *SettlementWorkerConfig {
+	return &SettlementWorkerConfig{
+		Interval: 10 * time.Minute,
+		Delay:    time.Hour,
+		Lookback: 72 * time.Hour,
+	}
+}
+
+// SettlementWorker 定时结算已结束的活动。
+// 结束超过 Delay 且在 Lookback 以内的活动每轮都会重新结算，结算按活动与结算日幂等写入，
+// 多实例同时执行也只会得到相同的结果；已打款的结算不再变更
+type SettlementWorker struct {
+	events  SettlementEventSource
+	settler SpikeSettlementService
+	config  *SettlementWorkerConfig
+	logger  *zap.Logger
+}
+
+// NewSettlementWorker 创建结算任务
+func NewSettlementWorker(events SettlementEventSource, settler SpikeSettlementService, config *SettlementWorkerConfig, logger *zap.Logger) *SettlementWorker {
+	if config == nil {
+		config = DefaultSettlementWorkerConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SettlementWorker{
+		events:  events,
+		settler: settler,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Start 异步启动结算循环，ctx 取消时退出
+func (w *SettlementWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+
+		w.logger.Info("活动结算任务已启动",
+			zap.Duration("interval", w.config.Interval),
+			zap.Duration("delay", w.config.Delay),
+			zap.Duration("lookback", w.config.Lookback))
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("活动结算任务已停止")
+				return
+			case <-ticker.C:
+				if _, err := w.RunOnce(ctx); err != nil {
+					w.logger.Warn("活动结算失败", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce 结算一轮，返回本轮结算的活动数
+func (w *SettlementWorker) RunOnce(ctx context.Context) (int, error) {
+	until := time.Now().Add(-w.config.Delay)
+	events, err := w.events.GetEventsByTimeRange(ctx, until.Add(-w.config.Lookback), until)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ended events: %w", err)
+	}
+
+	settled := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		// 时间范围查询包含与窗口重叠的活动，只结算结束时间已过 Delay 的活动
+		if event.EndAt.After(until) {
+			continue
+		}
+
+		settlements, err := w.settler.SettleEvent(ctx, event.ID)
+		if err != nil {
+			w.logger.Warn("结算活动失败", zap.Int64("spike_event_id", event.ID), zap.Error(err))
+			continue
+		}
+		settled++
+		w.logger.Debug("活动结算完成",
+			zap.Int64("spike_event_id", event.ID),
+			zap.Int("days", len(settlements)))
+	}
+	return settled, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// memSettlementRepo 结算仓储内存实现，按活动与结算日唯一
+type memSettlementRepo struct {
+	daily       map[int64][]*domain.SpikeSettlementDaily
+	settlements []*domain.SpikeSettlement
+}
+
+func (r *memSettlementRepo) AggregateDaily(spikeEventID int64) ([]*domain.SpikeSettlementDaily, error) {
+	return r.daily[spikeEventID], nil
+}
+
+func (r *memSettlementRepo) Upsert(s *domain.SpikeSettlement) error {
+	for _, existing := range r.settlements {
+		if existing.SpikeEventID == s.SpikeEventID && existing.SettleDate.Equal(s.SettleDate) {
+			if existing.Status == domain.SpikeSettlementStatusPending {
+				existing.GrossAmount, existing.RefundAmount = s.GrossAmount, s.RefundAmount
+				existing.FeeAmount, existing.NetAmount = s.FeeAmount, s.NetAmount
+			}
+			return nil
+		}
+	}
+	copied := *s
+	copied.ID = int64(len(r.settlements) + 1)
+	r.settlements = append(r.settlements, &copied)
+	return nil
+}
+
+func (r *memSettlementRepo) GetByID(id int64) (*domain.SpikeSettlement, error) {
+	if id <= 0 || id > int64(len(r.settlements)) {
+		return nil, domain.ErrSettlementNotFound
+	}
+	return r.settlements[id-1], nil
+}
+
+func (r *memSettlementRepo) List(req *domain.SpikeSettlementListRequest) ([]*domain.SpikeSettlement, int64, error) {
+	return r.settlements, int64(len(r.settlements)), nil
+}
+
+func (r *memSettlementRepo) MarkPaidOut(id int64, actor, reference string, paidOutAt time.Time) (bool, error) {
+	s := r.settlements[id-1]
+	if s.Status != domain.SpikeSettlementStatusPending {
+		return false, nil
+	}
+	s.Status, s.PaidOutBy, s.PayoutReference, s.PaidOutAt = domain.SpikeSettlementStatusPaidOut, actor, reference, &paidOutAt
+	return true, nil
+}
+
+func TestSpikeSettlementService_SettleAndPayout(t *testing.T) {
+	ctx := context.Background()
+	day1 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	settlementRepo := &memSettlementRepo{daily: map[int64][]*domain.SpikeSettlementDaily{
+		7: {
+			{SettleDate: day1, OrderCount: 3, Quantity: 4, GrossAmount: 400},
+			{SettleDate: day2, RefundAmount: 100},
+		},
+	}}
+	svc := NewSpikeSettlementService(settlementRepo, 0.025, nil)
+
+	settlements, err := svc.SettleEvent(ctx, 7)
+	if err != nil {
+		t.Fatalf("SettleEvent() error = %v", err)
+	}
+	if len(settlements) != 2 {
+		t.Fatalf("SettleEvent() settlements = %d, want 2", len(settlements))
+	}
+	if s := settlements[0]; s.FeeAmount != 10 || s.NetAmount != 390 {
+		t.Errorf("day1 fee = %v net = %v, want 10 and 390", s.FeeAmount, s.NetAmount)
+	}
+	// 只有退款的结算日不收手续费，应打款为负数，从后续打款中扣回
+	if s := settlements[1]; s.FeeAmount != 0 || s.NetAmount != -100 {
+		t.Errorf("day2 fee = %v net = %v, want 0 and -100", s.FeeAmount, s.NetAmount)
+	}
+
+	paid, err := svc.MarkPaidOut(ctx, 1, 42, "TX-1")
+	if err != nil {
+		t.Fatalf("MarkPaidOut() error = %v", err)
+	}
+	if paid.Status != domain.SpikeSettlementStatusPaidOut || paid.PaidOutBy != domain.AdminActor(42) || paid.PayoutReference != "TX-1" {
+		t.Errorf("MarkPaidOut() = %+v", paid)
+	}
+	if _, err := svc.MarkPaidOut(ctx, 1, 42, "TX-2"); !errors.Is(err, domain.ErrSettlementAlreadyPaidOut) {
+		t.Errorf("MarkPaidOut() twice error = %v, want ErrSettlementAlreadyPaidOut", err)
+	}
+	if _, err := svc.MarkPaidOut(ctx, 99, 42, ""); !errors.Is(err, domain.ErrSettlementNotFound) {
+		t.Errorf("MarkPaidOut(missing) error = %v, want ErrSettlementNotFound", err)
+	}
+
+	// 迟到的支付只改写待打款的结算
+	settlementRepo.daily[7][0].GrossAmount = 500
+	settlementRepo.daily[7][1].GrossAmount = 200
+	if _, err := svc.SettleEvent(ctx, 7); err != nil {
+		t.Fatalf("SettleEvent() again error = %v", err)
+	}
+	if len(settlementRepo.settlements) != 2 {
+		t.Fatalf("re-settle created duplicates: %d rows", len(settlementRepo.settlements))
+	}
+	if got := settlementRepo.settlements[0].GrossAmount; got != 400 {
+		t.Errorf("paid-out settlement gross = %v, want unchanged 400", got)
+	}
+	if got := settlementRepo.settlements[1].NetAmount; got != 97.5 {
+		t.Errorf("pending settlement net = %v, want 97.5", got)
+	}
+}
+
+// recordingSettler 记录被结算的活动
+type recordingSettler struct {
+	SpikeSettlementService
+	settled []int64
+}
+
+func (r *recordingSettler) SettleEvent(ctx context.Context, spikeEventID int64) ([]*domain.SpikeSettlement, error) {
+	r.settled = append(r.settled, spikeEventID)
+	return nil, nil
+}
+
+// stubRangeEvents 固定的时间范围活动列表
+type stubRangeEvents []*domain.SpikeEvent
+
+func (s stubRangeEvents) GetEventsByTimeRange(ctx context.Context, start, end time.Time) ([]*domain.SpikeEvent, error) {
+	return s, nil
+}
+
+func TestSettlementWorker_RunOnce(t *testing.T) {
+	now := time.Now()
+	events := stubRangeEvents{
+		{ID: 1, EndAt: now.Add(-2 * time.Hour)},    // 已过结算等待期
+		{ID: 2, EndAt: now.Add(-10 * time.Minute)}, // 仍在等待待支付订单
+	}
+	settler := &recordingSettler{}
+	worker := NewSettlementWorker(events, settler, &SettlementWorkerConfig{
+		Interval: time.Minute, Delay: time.Hour, Lookback: 72 * time.Hour,
+	}, nil)
+
+	settled, err := worker.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if settled != 1 || len(settler.settled) != 1 || settler.settled[0] != 1 {
+		t.Errorf("RunOnce() settled %d events %v, want only event 1", settled, settler.settled)
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// ErrShareEventFinished 活动已结束或已取消，不能再生成分享链接
+var ErrShareEventFinished = errors.New("活动已结束，不能分享")
+
+// ShareLinkSigner 使用 HMAC-SHA256 签发和校验活动分享令牌。
+// 令牌格式为 "<event_id>.<campaign>.<expires_unix>.<signature>"，campaign 可为空；
+// 令牌不绑定分享者，同一活动与渠道在同一过期时间下得到相同的令牌。
+type ShareLinkSigner struct {
+	secret []byte
+}
+
+// NewShareLinkSigner 创建分享令牌签名器
+func NewShareLinkSigner(secret string) *ShareLinkSigner {
+	return &ShareLinkSigner{secret: []byte(secret)}
+}
+
+// Sign 为活动与推广渠道签发分享令牌
+func (s *ShareLinkSigner) Sign(eventID int64, campaign string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%s.%d", eventID, campaign, expiresAt.Unix())
+	return payload + "." + s.signature(payload)
+}
+
+// Verify 校验分享令牌，通过时返回令牌中的活动、渠道与过期时间
+func (s *ShareLinkSigner) Verify(token string) (*domain.SpikeShareVisit, error) {
+	idx := strings.LastIndex(token, ".")
+	if idx <= 0 {
+		return nil, domain.ErrShareLinkInvalid
+	}
+	payload, sig := token[:idx], token[idx+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.signature(payload))) {
+		return nil, domain.ErrShareLinkInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return nil, domain.ErrShareLinkInvalid
+	}
+	eventID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || eventID <= 0 {
+		return nil, domain.ErrShareLinkInvalid
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, domain.ErrShareLinkInvalid
+	}
+	if time.Now().Unix() > expires {
+		return nil, domain.ErrShareLinkExpired
+	}
+
+	return &domain.SpikeShareVisit{
+		SpikeEventID: eventID,
+		Campaign:     parts[1],
+		ExpiresAt:    time.Unix(expires, 0),
+	}, nil
+}
+
+func (s *ShareLinkSigner) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SpikeShareService 定义活动分享链接服务接口
+type SpikeShareService interface {
+	// CreateLink 为活动生成签名分享链接，有效期不超过活动结束时间
+	CreateLink(ctx context.Context, eventID int64, campaign string) (*domain.SpikeShareLink, error)
+	// ResolveLink 校验分享令牌并记录一次到达
+	ResolveLink(ctx context.Context, token string) (*domain.SpikeShareVisit, error)
+	// RecordParticipation 记录携带分享令牌的成功参与，令牌无效或不属于该活动时忽略
+	RecordParticipation(ctx context.Context, token string, eventID int64)
+	// ListAttribution 按推广渠道列出活动的分享归因统计
+	ListAttribution(ctx context.Context, eventID int64) ([]*domain.SpikeShareAttribution, error)
+}
+
+// spikeShareService 是SpikeShareService接口的实现
+type spikeShareService struct {
+	spikeEventRepo  repo.SpikeEventRepository
+	attributionRepo repo.SpikeShareAttributionRepository
+	signer          *ShareLinkSigner
+	linkBase        string
+	linkTTL         time.Duration
+	logger          *zap.Logger
+}
+
+// NewSpikeShareService 创建活动分享链接服务。
+// linkBase 为落地页路径前缀，生成的链接形如 "<linkBase>/<event_id>?share=<token>"；
+// linkTTL 为链接最长有效期，不大于0时链接在活动结束时过期
+func NewSpikeShareService(
+	spikeEventRepo repo.SpikeEventRepository,
+	attributionRepo repo.SpikeShareAttributionRepository,
+	signer *ShareLinkSigner,
+	linkBase string,
+	linkTTL time.Duration,
+	logger *zap.Logger,
+) SpikeShareService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeShareService{
+		spikeEventRepo:  spikeEventRepo,
+		attributionRepo: attributionRepo,
+		signer:          signer,
+		linkBase:        strings.TrimSuffix(linkBase, "/"),
+		linkTTL:         linkTTL,
+		logger:          logger,
+	}
+}
+
+// CreateLink 生成分享链接
+// 业务规则：
+// 1. 只有已对外展示（预告期开始后）且未结束的活动可以分享
+// 2. 链接过期时间取活动结束时间与最长有效期中较早者，按秒截断
+func (s *spikeShareService) CreateLink(ctx context.Context, eventID int64, campaign string) (*domain.SpikeShareLink, error) {
+	if err := domain.ValidateShareCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	event, err := s.spikeEventRepo.GetByID(ctx, eventID)
+	if err != nil || event == nil || !event.IsVisible() {
+		return nil, domain.ErrSpikeEventNotFound
+	}
+	if event.IsFinished() {
+		return nil, ErrShareEventFinished
+	}
+
+	expiresAt := event.EndAt
+	if s.linkTTL > 0 {
+		if limit := time.Now().Add(s.linkTTL); limit.Before(expiresAt) {
+			expiresAt = limit
+		}
+	}
+	expiresAt = expiresAt.Truncate(time.Second)
+
+	token := s.signer.Sign(eventID, campaign, expiresAt)
+	return &domain.SpikeShareLink{
+		SpikeEventID: eventID,
+		Campaign:     campaign,
+		Token:        token,
+		URL:          fmt.Sprintf("%s/%d?share=%s", s.linkBase, eventID, url.QueryEscape(token)),
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// ResolveLink 校验分享令牌并记录到达；归因记录失败不影响落地
+func (s *spikeShareService) ResolveLink(ctx context.Context, token string) (*domain.SpikeShareVisit, error) {
+	visit, err := s.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attributionRepo.IncrementVisits(visit.SpikeEventID, visit.Campaign); err != nil {
+		s.logger.Warn("记录分享链接访问失败",
+			zap.Int64("spike_event_id", visit.SpikeEventID),
+			zap.String("campaign", visit.Campaign),
+			zap.Error(err))
+	}
+	return visit, nil
+}
+
+// RecordParticipation 记录经分享链接的成功参与
+func (s *spikeShareService) RecordParticipation(ctx context.Context, token string, eventID int64) {
+	visit, err := s.signer.Verify(token)
+	if err != nil || visit.SpikeEventID != eventID {
+		return
+	}
+
+	if err := s.attributionRepo.IncrementParticipants(eventID, visit.Campaign); err != nil {
+		s.logger.Warn("记录分享链接参与失败",
+			zap.Int64("spike_event_id", eventID),
+			zap.String("campaign", visit.Campaign),
+			zap.Error(err))
+	}
+}
+
+// ListAttribution 列出活动的分享归因统计
+func (s *spikeShareService) ListAttribution(ctx context.Context, eventID int64) ([]*domain.SpikeShareAttribution, error) {
+	attributions, err := s.attributionRepo.ListByEvent(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share attribution: %w", err)
+	}
+	return attributions, nil
+}
+
+// ShareParticipationRecorder 记录经分享链接的成功参与（由 SpikeShareService 实现）
+type ShareParticipationRecorder interface {
+	RecordParticipation(ctx context.Context, token string, eventID int64)
+}
+
+// SetShareAttribution 设置分享链接归因，参与请求携带 share_token 且参与成功时记录一次参与
+func (s *SpikeService) SetShareAttribution(recorder ShareParticipationRecorder) {
+	s.shareAttribution = recorder
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// memShareAttributionRepo 分享归因仓储内存实现
+type memShareAttributionRepo struct {
+	counts map[string]*domain.SpikeShareAttribution
+}
+
+func newMemShareAttributionRepo() *memShareAttributionRepo {
+	return &memShareAttributionRepo{counts: make(map[string]*domain.SpikeShareAttribution)}
+}
+
+func (r *memShareAttributionRepo) get(eventID int64, campaign string) *domain.SpikeShareAttribution {
+	key := fmt.Sprintf("%d:%s", eventID, campaign)
+	a, ok := r.counts[key]
+	if !ok {
+		a = &domain.SpikeShareAttribution{SpikeEventID: eventID, Campaign: campaign}
+		r.counts[key] = a
+	}
+	return a
+}
+
+func (r *memShareAttributionRepo) IncrementVisits(eventID int64, campaign string) error {
+	r.get(eventID, campaign).Visits++
+	return nil
+}
+
+func (r *memShareAttributionRepo) IncrementParticipants(eventID int64, campaign string) error {
+	r.get(eventID, campaign).Participants++
+	return nil
+}
+
+func (r *memShareAttributionRepo) ListByEvent(eventID int64) ([]*domain.SpikeShareAttribution, error) {
+	var list []*domain.SpikeShareAttribution
+	for _, a := range r.counts {
+		if a.SpikeEventID == eventID {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func TestShareLinkSigner(t *testing.T) {
+	signer := NewShareLinkSigner("secret")
+	expiresAt := time.Now().Add(time.Hour)
+
+	for _, campaign := range []string{"wechat_moments", ""} {
+		visit, err := signer.Verify(signer.Sign(7, campaign, expiresAt))
+		if err != nil {
+			t.Fatalf("Verify(campaign=%q) error = %v", campaign, err)
+		}
+		if visit.SpikeEventID != 7 || visit.Campaign != campaign || visit.ExpiresAt.Unix() != expiresAt.Unix() {
+			t.Errorf("Verify(campaign=%q) = %+v", campaign, visit)
+		}
+	}
+
+	token := signer.Sign(7, "wechat", expiresAt)
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "tampered campaign", token: strings.Replace(token, "wechat", "weibo", 1), wantErr: domain.ErrShareLinkInvalid},
+		{name: "tampered event", token: "8" + strings.TrimPrefix(token, "7"), wantErr: domain.ErrShareLinkInvalid},
+		{name: "empty", token: "", wantErr: domain.ErrShareLinkInvalid},
+		{name: "wrong secret", token: NewShareLinkSigner("other").Sign(7, "wechat", expiresAt), wantErr: domain.ErrShareLinkInvalid},
+		{name: "expired", token: signer.Sign(7, "wechat", time.Now().Add(-time.Minute)), wantErr: domain.ErrShareLinkExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signer.Verify(tt.token); err != tt.wantErr {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpikeShareService_Attribution(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	eventRepo := NewMockSpikeEventRepository()
+	active := &domain.SpikeEvent{Name: "active", StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour), Status: domain.SpikeEventStatusActive}
+	ended := &domain.SpikeEvent{Name: "ended", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(-time.Hour), Status: domain.SpikeEventStatusEnded}
+	_ = eventRepo.Create(context.Background(), active)
+	_ = eventRepo.Create(context.Background(), ended)
+
+	attributionRepo := newMemShareAttributionRepo()
+	svc := NewSpikeShareService(eventRepo, attributionRepo, NewShareLinkSigner("secret"), "/spike/events/", 24*time.Hour, nil)
+
+	link, err := svc.CreateLink(ctx, active.ID, "wechat")
+	if err != nil {
+		t.Fatalf("CreateLink() error = %v", err)
+	}
+	if !strings.HasPrefix(link.URL, "/spike/events/1?share=") || link.ExpiresAt.After(active.EndAt) {
+		t.Errorf("CreateLink() = %+v, want landing URL and expiry capped at event end", link)
+	}
+
+	if _, err := svc.CreateLink(ctx, ended.ID, ""); !errors.Is(err, ErrShareEventFinished) {
+		t.Errorf("CreateLink(ended) error = %v, want ErrShareEventFinished", err)
+	}
+	if _, err := svc.CreateLink(ctx, active.ID, "bad.campaign"); !errors.Is(err, domain.ErrShareCampaignInvalid) {
+		t.Errorf("CreateLink(bad campaign) error = %v, want ErrShareCampaignInvalid", err)
+	}
+
+	for range 2 {
+		if _, err := svc.ResolveLink(ctx, link.Token); err != nil {
+			t.Fatalf("ResolveLink() error = %v", err)
+		}
+	}
+	svc.RecordParticipation(ctx, link.Token, active.ID)
+	// 令牌不属于该活动时不计入
+	svc.RecordParticipation(ctx, link.Token, ended.ID)
+
+	attributions, err := svc.ListAttribution(ctx, active.ID)
+	if err != nil {
+		t.Fatalf("ListAttribution() error = %v", err)
+	}
+	if len(attributions) != 1 || attributions[0].Visits != 2 || attributions[0].Participants != 1 {
+		t.Errorf("ListAttribution() = %+v, want wechat with 2 visits and 1 participant", attributions)
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// stockWarmer 按活动分桶配置预热库存所需的缓存操作（由 cache.SpikeCache 实现）
+type stockWarmer interface {
+	WarmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error
+	WarmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error
+}
+
+// warmupEventStock 按活动的 stock_buckets 以单键或分桶模式预热库存
+func warmupEventStock(ctx context.Context, c stockWarmer, event *domain.SpikeEvent, stock int64, ttl time.Duration) error {
+	if event.StockBuckets > 1 {
+		return c.WarmupStockBuckets(ctx, event.ID, stock, event.StockBuckets, ttl)
+	}
+	return c.WarmupStock(ctx, event.ID, stock, ttl)
+}
+
+// decrementStock 按活动的 stock_buckets 以单键或分桶模式预减库存，并按活动的单用户累计件数上限去重
+func (s *SpikeService) decrementStock(ctx context.Context, event *domain.SpikeEvent, userID, quantity int64) (*cache.DecrementStockResult, error) {
+	if event.StockBuckets > 1 {
+		return s.spikeCache.DecrementStockBuckets(ctx, event.ID, userID, quantity, event.UserQuantityLimit(),
+			event.StockBuckets, s.cfg().UserMarkTTL, s.stockTTL(event))
+	}
+	return s.spikeCache.DecrementStock(ctx, event.ID, userID, quantity, event.UserQuantityLimit(),
+		s.cfg().UserMarkTTL, s.stockTTL(event))
+}
@@ -0,0 +1,627 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package service
+
+import (
+	"context"
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"sync"
+	"time"
+)
+
+// Ensure, that SpikeStockCacheMock does implement SpikeStockCache.
+// If this is not the case, regenerate this file with moq.
+var _ SpikeStockCache = &SpikeStockCacheMock{}
+
+// SpikeStockCacheMock is a mock implementation of SpikeStockCache.
+//
+//	func TestSomethingThatUsesSpikeStockCache(t *testing.T) {
+//
+//		// make and configure a mocked SpikeStockCache
+//		mockedSpikeStockCache := &SpikeStockCacheMock{
+//			CacheEventInfoFunc: func(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error {
+//				panic("mock out the CacheEventInfo method")
+//			},
+//			DecrementStockFunc: func(ctx context.Context, eventID int64, userID int64, quantity int64, maxPerUser int64, userTTL time.Duration, soldOutTTL time.Duration) (*cache.DecrementStockResult, error) {
+//				panic("mock out the DecrementStock method")
+//			},
+//			DecrementStockBucketsFunc: func(ctx context.Context, eventID int64, userID int64, quantity int64, maxPerUser int64, buckets int, userTTL time.Duration, soldOutTTL time.Duration) (*cache.DecrementStockResult, error) {
+//				panic("mock out the DecrementStockBuckets method")
+//			},
+//			GetEventInfoFunc: func(ctx context.Context, eventID int64, dest interface{}) error {
+//				panic("mock out the GetEventInfo method")
+//			},
+//			GetStockInfoFunc: func(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+//				panic("mock out the GetStockInfo method")
+//			},
+//			ReserveParticipationTokenFunc: func(ctx context.Context, eventID int64, userID int64, maxTokens int64, ttl time.Duration) (bool, error) {
+//				panic("mock out the ReserveParticipationToken method")
+//			},
+//			RestoreStockFunc: func(ctx context.Context, eventID int64, userID int64, quantity int64) (int64, error) {
+//				panic("mock out the RestoreStock method")
+//			},
+//			WarmupStockFunc: func(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error {
+//				panic("mock out the WarmupStock method")
+//			},
+//			WarmupStockBucketsFunc: func(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error {
+//				panic("mock out the WarmupStockBuckets method")
+//			},
+//		}
+//
+//		// use mockedSpikeStockCache in code that requires SpikeStockCache
+//		// and then make assertions.
+//
+//	}
+type SpikeStockCacheMock struct {
+	// CacheEventInfoFunc mocks the CacheEventInfo method.
+	CacheEventInfoFunc func(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error
+
+	// DecrementStockFunc mocks the DecrementStock method.
+	DecrementStockFunc func(ctx context.Context, eventID int64, userID int64, quantity int64, maxPerUser int64, userTTL time.Duration, soldOutTTL time.Duration) (*cache.DecrementStockResult, error)
+
+	// DecrementStockBucketsFunc mocks the DecrementStockBuckets method.
+	DecrementStockBucketsFunc func(ctx context.Context, eventID int64, userID int64, quantity int64, maxPerUser int64, buckets int, userTTL time.Duration, soldOutTTL time.Duration) (*cache.DecrementStockResult, error)
+
+	// GetEventInfoFunc mocks the GetEventInfo method.
+	GetEventInfoFunc func(ctx context.Context, eventID int64, dest interface{}) error
+
+	// GetStockInfoFunc mocks the GetStockInfo method.
+	GetStockInfoFunc func(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+
+	// ReserveParticipationTokenFunc mocks the ReserveParticipationToken method.
+	ReserveParticipationTokenFunc func(ctx context.Context, eventID int64, userID int64, maxTokens int64, ttl time.Duration) (bool, error)
+
+	// RestoreStockFunc mocks the RestoreStock method.
+	RestoreStockFunc func(ctx context.Context, eventID int64, userID int64, quantity int64) (int64, error)
+
+	// WarmupStockFunc mocks the WarmupStock method.
+	WarmupStockFunc func(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error
+
+	// WarmupStockBucketsFunc mocks the WarmupStockBuckets method.
+	WarmupStockBucketsFunc func(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CacheEventInfo holds details about calls to the CacheEventInfo method.
+		CacheEventInfo []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// EventData is the eventData argument value.
+			EventData interface{}
+			// TTL is the ttl argument value.
+			TTL time.Duration
+		}
+		// DecrementStock holds details about calls to the DecrementStock method.
+		DecrementStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// UserID is the userID argument value.
+			UserID int64
+			// Quantity is the quantity argument value.
+			Quantity int64
+			// MaxPerUser is the maxPerUser argument value.
+			MaxPerUser int64
+			// UserTTL is the userTTL argument value.
+			UserTTL time.Duration
+			// SoldOutTTL is the soldOutTTL argument value.
+			SoldOutTTL time.Duration
+		}
+		// DecrementStockBuckets holds details about calls to the DecrementStockBuckets method.
+		DecrementStockBuckets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// UserID is the userID argument value.
+			UserID int64
+			// Quantity is the quantity argument value.
+			Quantity int64
+			// MaxPerUser is the maxPerUser argument value.
+			MaxPerUser int64
+			// Buckets is the buckets argument value.
+			Buckets int
+			// UserTTL is the userTTL argument value.
+			UserTTL time.Duration
+			// SoldOutTTL is the soldOutTTL argument value.
+			SoldOutTTL time.Duration
+		}
+		// GetEventInfo holds details about calls to the GetEventInfo method.
+		GetEventInfo []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// Dest is the dest argument value.
+			Dest interface{}
+		}
+		// GetStockInfo holds details about calls to the GetStockInfo method.
+		GetStockInfo []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+		}
+		// ReserveParticipationToken holds details about calls to the ReserveParticipationToken method.
+		ReserveParticipationToken []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// UserID is the userID argument value.
+			UserID int64
+			// MaxTokens is the maxTokens argument value.
+			MaxTokens int64
+			// TTL is the ttl argument value.
+			TTL time.Duration
+		}
+		// RestoreStock holds details about calls to the RestoreStock method.
+		RestoreStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// UserID is the userID argument value.
+			UserID int64
+			// Quantity is the quantity argument value.
+			Quantity int64
+		}
+		// WarmupStock holds details about calls to the WarmupStock method.
+		WarmupStock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// Stock is the stock argument value.
+			Stock int64
+			// TTL is the ttl argument value.
+			TTL time.Duration
+		}
+		// WarmupStockBuckets holds details about calls to the WarmupStockBuckets method.
+		WarmupStockBuckets []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// EventID is the eventID argument value.
+			EventID int64
+			// Stock is the stock argument value.
+			Stock int64
+			// Buckets is the buckets argument value.
+			Buckets int
+			// TTL is the ttl argument value.
+			TTL time.Duration
+		}
+	}
+	lockCacheEventInfo            sync.RWMutex
+	lockDecrementStock            sync.RWMutex
+	lockDecrementStockBuckets     sync.RWMutex
+	lockGetEventInfo              sync.RWMutex
+	lockGetStockInfo              sync.RWMutex
+	lockReserveParticipationToken sync.RWMutex
+	lockRestoreStock              sync.RWMutex
+	lockWarmupStock               sync.RWMutex
+	lockWarmupStockBuckets        sync.RWMutex
+}
+
+// CacheEventInfo calls CacheEventInfoFunc.
+func (mock *SpikeStockCacheMock) CacheEventInfo(ctx context.Context, eventID int64, eventData interface{}, ttl time.Duration) error {
+	if mock.CacheEventInfoFunc == nil {
+		panic("SpikeStockCacheMock.CacheEventInfoFunc: method is nil but SpikeStockCache.CacheEventInfo was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		EventID   int64
+		EventData interface{}
+		TTL       time.Duration
+	}{
+		Ctx:       ctx,
+		EventID:   eventID,
+		EventData: eventData,
+		TTL:       ttl,
+	}
+	mock.lockCacheEventInfo.Lock()
+	mock.calls.CacheEventInfo = append(mock.calls.CacheEventInfo, callInfo)
+	mock.lockCacheEventInfo.Unlock()
+	return mock.CacheEventInfoFunc(ctx, eventID, eventData, ttl)
+}
+
+// CacheEventInfoCalls gets all the calls that were made to CacheEventInfo.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.CacheEventInfoCalls())
+func (mock *SpikeStockCacheMock) CacheEventInfoCalls() []struct {
+	Ctx       context.Context
+	EventID   int64
+	EventData interface{}
+	TTL       time.Duration
+} {
+	var calls []struct {
+		Ctx       context.Context
+		EventID   int64
+		EventData interface{}
+		TTL       time.Duration
+	}
+	mock.lockCacheEventInfo.RLock()
+	calls = mock.calls.CacheEventInfo
+	mock.lockCacheEventInfo.RUnlock()
+	return calls
+}
+
+// DecrementStock calls DecrementStockFunc.
+func (mock *SpikeStockCacheMock) DecrementStock(ctx context.Context, eventID int64, userID int64, quantity int64, maxPerUser int64, userTTL time.Duration, soldOutTTL time.Duration) (*cache.DecrementStockResult, error) {
+	if mock.DecrementStockFunc == nil {
+		panic("SpikeStockCacheMock.DecrementStockFunc: method is nil but SpikeStockCache.DecrementStock was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		EventID    int64
+		UserID     int64
+		Quantity   int64
+		MaxPerUser int64
+		UserTTL    time.Duration
+		SoldOutTTL time.Duration
+	}{
+		Ctx:        ctx,
+		EventID:    eventID,
+		UserID:     userID,
+		Quantity:   quantity,
+		MaxPerUser: maxPerUser,
+		UserTTL:    userTTL,
+		SoldOutTTL: soldOutTTL,
+	}
+	mock.lockDecrementStock.Lock()
+	mock.calls.DecrementStock = append(mock.calls.DecrementStock, callInfo)
+	mock.lockDecrementStock.Unlock()
+	return mock.DecrementStockFunc(ctx, eventID, userID, quantity, maxPerUser, userTTL, soldOutTTL)
+}
+
+// DecrementStockCalls gets all the calls that were made to DecrementStock.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.DecrementStockCalls())
+func (mock *SpikeStockCacheMock) DecrementStockCalls() []struct {
+	Ctx        context.Context
+	EventID    int64
+	UserID     int64
+	Quantity   int64
+	MaxPerUser int64
+	UserTTL    time.Duration
+	SoldOutTTL time.Duration
+} {
+	var calls []struct {
+		Ctx        context.Context
+		EventID    int64
+		UserID     int64
+		Quantity   int64
+		MaxPerUser int64
+		UserTTL    time.Duration
+		SoldOutTTL time.Duration
+	}
+	mock.lockDecrementStock.RLock()
+	calls = mock.calls.DecrementStock
+	mock.lockDecrementStock.RUnlock()
+	return calls
+}
+
+// DecrementStockBuckets calls DecrementStockBucketsFunc.
+func (mock *SpikeStockCacheMock) DecrementStockBuckets(ctx context.Context, eventID int64, userID int64, quantity int64, maxPerUser int64, buckets int, userTTL time.Duration, soldOutTTL time.Duration) (*cache.DecrementStockResult, error) {
+	if mock.DecrementStockBucketsFunc == nil {
+		panic("SpikeStockCacheMock.DecrementStockBucketsFunc: method is nil but SpikeStockCache.DecrementStockBuckets was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		EventID    int64
+		UserID     int64
+		Quantity   int64
+		MaxPerUser int64
+		Buckets    int
+		UserTTL    time.Duration
+		SoldOutTTL time.Duration
+	}{
+		Ctx:        ctx,
+		EventID:    eventID,
+		UserID:     userID,
+		Quantity:   quantity,
+		MaxPerUser: maxPerUser,
+		Buckets:    buckets,
+		UserTTL:    userTTL,
+		SoldOutTTL: soldOutTTL,
+	}
+	mock.lockDecrementStockBuckets.Lock()
+	mock.calls.DecrementStockBuckets = append(mock.calls.DecrementStockBuckets, callInfo)
+	mock.lockDecrementStockBuckets.Unlock()
+	return mock.DecrementStockBucketsFunc(ctx, eventID, userID, quantity, maxPerUser, buckets, userTTL, soldOutTTL)
+}
+
+// DecrementStockBucketsCalls gets all the calls that were made to DecrementStockBuckets.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.DecrementStockBucketsCalls())
+func (mock *SpikeStockCacheMock) DecrementStockBucketsCalls() []struct {
+	Ctx        context.Context
+	EventID    int64
+	UserID     int64
+	Quantity   int64
+	MaxPerUser int64
+	Buckets    int
+	UserTTL    time.Duration
+	SoldOutTTL time.Duration
+} {
+	var calls []struct {
+		Ctx        context.Context
+		EventID    int64
+		UserID     int64
+		Quantity   int64
+		MaxPerUser int64
+		Buckets    int
+		UserTTL    time.Duration
+		SoldOutTTL time.Duration
+	}
+	mock.lockDecrementStockBuckets.RLock()
+	calls = mock.calls.DecrementStockBuckets
+	mock.lockDecrementStockBuckets.RUnlock()
+	return calls
+}
+
+// GetEventInfo calls GetEventInfoFunc.
+func (mock *SpikeStockCacheMock) GetEventInfo(ctx context.Context, eventID int64, dest interface{}) error {
+	if mock.GetEventInfoFunc == nil {
+		panic("SpikeStockCacheMock.GetEventInfoFunc: method is nil but SpikeStockCache.GetEventInfo was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		EventID int64
+		Dest    interface{}
+	}{
+		Ctx:     ctx,
+		EventID: eventID,
+		Dest:    dest,
+	}
+	mock.lockGetEventInfo.Lock()
+	mock.calls.GetEventInfo = append(mock.calls.GetEventInfo, callInfo)
+	mock.lockGetEventInfo.Unlock()
+	return mock.GetEventInfoFunc(ctx, eventID, dest)
+}
+
+// GetEventInfoCalls gets all the calls that were made to GetEventInfo.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.GetEventInfoCalls())
+func (mock *SpikeStockCacheMock) GetEventInfoCalls() []struct {
+	Ctx     context.Context
+	EventID int64
+	Dest    interface{}
+} {
+	var calls []struct {
+		Ctx     context.Context
+		EventID int64
+		Dest    interface{}
+	}
+	mock.lockGetEventInfo.RLock()
+	calls = mock.calls.GetEventInfo
+	mock.lockGetEventInfo.RUnlock()
+	return calls
+}
+
+// GetStockInfo calls GetStockInfoFunc.
+func (mock *SpikeStockCacheMock) GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+	if mock.GetStockInfoFunc == nil {
+		panic("SpikeStockCacheMock.GetStockInfoFunc: method is nil but SpikeStockCache.GetStockInfo was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		EventID int64
+	}{
+		Ctx:     ctx,
+		EventID: eventID,
+	}
+	mock.lockGetStockInfo.Lock()
+	mock.calls.GetStockInfo = append(mock.calls.GetStockInfo, callInfo)
+	mock.lockGetStockInfo.Unlock()
+	return mock.GetStockInfoFunc(ctx, eventID)
+}
+
+// GetStockInfoCalls gets all the calls that were made to GetStockInfo.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.GetStockInfoCalls())
+func (mock *SpikeStockCacheMock) GetStockInfoCalls() []struct {
+	Ctx     context.Context
+	EventID int64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		EventID int64
+	}
+	mock.lockGetStockInfo.RLock()
+	calls = mock.calls.GetStockInfo
+	mock.lockGetStockInfo.RUnlock()
+	return calls
+}
+
+// ReserveParticipationToken calls ReserveParticipationTokenFunc.
+func (mock *SpikeStockCacheMock) ReserveParticipationToken(ctx context.Context, eventID int64, userID int64, maxTokens int64, ttl time.Duration) (bool, error) {
+	if mock.ReserveParticipationTokenFunc == nil {
+		panic("SpikeStockCacheMock.ReserveParticipationTokenFunc: method is nil but SpikeStockCache.ReserveParticipationToken was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		EventID   int64
+		UserID    int64
+		MaxTokens int64
+		TTL       time.Duration
+	}{
+		Ctx:       ctx,
+		EventID:   eventID,
+		UserID:    userID,
+		MaxTokens: maxTokens,
+		TTL:       ttl,
+	}
+	mock.lockReserveParticipationToken.Lock()
+	mock.calls.ReserveParticipationToken = append(mock.calls.ReserveParticipationToken, callInfo)
+	mock.lockReserveParticipationToken.Unlock()
+	return mock.ReserveParticipationTokenFunc(ctx, eventID, userID, maxTokens, ttl)
+}
+
+// ReserveParticipationTokenCalls gets all the calls that were made to ReserveParticipationToken.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.ReserveParticipationTokenCalls())
+func (mock *SpikeStockCacheMock) ReserveParticipationTokenCalls() []struct {
+	Ctx       context.Context
+	EventID   int64
+	UserID    int64
+	MaxTokens int64
+	TTL       time.Duration
+} {
+	var calls []struct {
+		Ctx       context.Context
+		EventID   int64
+		UserID    int64
+		MaxTokens int64
+		TTL       time.Duration
+	}
+	mock.lockReserveParticipationToken.RLock()
+	calls = mock.calls.ReserveParticipationToken
+	mock.lockReserveParticipationToken.RUnlock()
+	return calls
+}
+
+// RestoreStock calls RestoreStockFunc.
+func (mock *SpikeStockCacheMock) RestoreStock(ctx context.Context, eventID int64, userID int64, quantity int64) (int64, error) {
+	if mock.RestoreStockFunc == nil {
+		panic("SpikeStockCacheMock.RestoreStockFunc: method is nil but SpikeStockCache.RestoreStock was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		EventID  int64
+		UserID   int64
+		Quantity int64
+	}{
+		Ctx:      ctx,
+		EventID:  eventID,
+		UserID:   userID,
+		Quantity: quantity,
+	}
+	mock.lockRestoreStock.Lock()
+	mock.calls.RestoreStock = append(mock.calls.RestoreStock, callInfo)
+	mock.lockRestoreStock.Unlock()
+	return mock.RestoreStockFunc(ctx, eventID, userID, quantity)
+}
+
+// RestoreStockCalls gets all the calls that were made to RestoreStock.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.RestoreStockCalls())
+func (mock *SpikeStockCacheMock) RestoreStockCalls() []struct {
+	Ctx      context.Context
+	EventID  int64
+	UserID   int64
+	Quantity int64
+} {
+	var calls []struct {
+		Ctx      context.Context
+		EventID  int64
+		UserID   int64
+		Quantity int64
+	}
+	mock.lockRestoreStock.RLock()
+	calls = mock.calls.RestoreStock
+	mock.lockRestoreStock.RUnlock()
+	return calls
+}
+
+// WarmupStock calls WarmupStockFunc.
+func (mock *SpikeStockCacheMock) WarmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error {
+	if mock.WarmupStockFunc == nil {
+		panic("SpikeStockCacheMock.WarmupStockFunc: method is nil but SpikeStockCache.WarmupStock was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		EventID int64
+		Stock   int64
+		TTL     time.Duration
+	}{
+		Ctx:     ctx,
+		EventID: eventID,
+		Stock:   stock,
+		TTL:     ttl,
+	}
+	mock.lockWarmupStock.Lock()
+	mock.calls.WarmupStock = append(mock.calls.WarmupStock, callInfo)
+	mock.lockWarmupStock.Unlock()
+	return mock.WarmupStockFunc(ctx, eventID, stock, ttl)
+}
+
+// WarmupStockCalls gets all the calls that were made to WarmupStock.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.WarmupStockCalls())
+func (mock *SpikeStockCacheMock) WarmupStockCalls() []struct {
+	Ctx     context.Context
+	EventID int64
+	Stock   int64
+	TTL     time.Duration
+} {
+	var calls []struct {
+		Ctx     context.Context
+		EventID int64
+		Stock   int64
+		TTL     time.Duration
+	}
+	mock.lockWarmupStock.RLock()
+	calls = mock.calls.WarmupStock
+	mock.lockWarmupStock.RUnlock()
+	return calls
+}
+
+// WarmupStockBuckets calls WarmupStockBucketsFunc.
+func (mock *SpikeStockCacheMock) WarmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error {
+	if mock.WarmupStockBucketsFunc == nil {
+		panic("SpikeStockCacheMock.WarmupStockBucketsFunc: method is nil but SpikeStockCache.WarmupStockBuckets was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		EventID int64
+		Stock   int64
+		Buckets int
+		TTL     time.Duration
+	}{
+		Ctx:     ctx,
+		EventID: eventID,
+		Stock:   stock,
+		Buckets: buckets,
+		TTL:     ttl,
+	}
+	mock.lockWarmupStockBuckets.Lock()
+	mock.calls.WarmupStockBuckets = append(mock.calls.WarmupStockBuckets, callInfo)
+	mock.lockWarmupStockBuckets.Unlock()
+	return mock.WarmupStockBucketsFunc(ctx, eventID, stock, buckets, ttl)
+}
+
+// WarmupStockBucketsCalls gets all the calls that were made to WarmupStockBuckets.
+// Check the length with:
+//
+//	len(mockedSpikeStockCache.WarmupStockBucketsCalls())
+func (mock *SpikeStockCacheMock) WarmupStockBucketsCalls() []struct {
+	Ctx     context.Context
+	EventID int64
+	Stock   int64
+	Buckets int
+	TTL     time.Duration
+} {
+	var calls []struct {
+		Ctx     context.Context
+		EventID int64
+		Stock   int64
+		Buckets int
+		TTL     time.Duration
+	}
+	mock.lockWarmupStockBuckets.RLock()
+	calls = mock.calls.WarmupStockBuckets
+	mock.lockWarmupStockBuckets.RUnlock()
+	return calls
+}
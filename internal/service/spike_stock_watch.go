@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// 库存长轮询的等待时长
+const (
+	DefaultStockWaitTimeout = 25 * time.Second
+	MaxStockWaitTimeout     = 30 * time.Second
+)
+
+// StockChangeSource 库存变更通知（由 cache.StockChangeSubscriber 实现）
+type StockChangeSource interface {
+	Subscribe(ctx context.Context) <-chan cache.StockChange
+}
+
+// StockStatusSource 读取活动当前库存（由 cache.SpikeCache 实现）
+type StockStatusSource interface {
+	GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+}
+
+// StockWatchEventSource 读取活动总库存（由 repo.SpikeEventRepository 实现）
+type StockWatchEventSource interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+}
+
+// StockWatcher 库存长轮询：无法保持 SSE/WebSocket 连接的客户端挂起请求，
+// 直到库存档位或售罄状态变化，超时则返回当前状态。
+// 进程内只订阅一次库存变更频道，收到通知后唤醒等待同一活动的请求。
+type StockWatcher struct {
+	changes StockChangeSource
+	stock   StockStatusSource
+	events  StockWatchEventSource
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	waiters map[int64]map[chan struct{}]struct{}
+}
+
+// NewStockWatcher 创建库存长轮询
+func NewStockWatcher(changes StockChangeSource, stock StockStatusSource, events StockWatchEventSource, logger *zap.Logger) *StockWatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &StockWatcher{
+		changes: changes,
+		stock:   stock,
+		events:  events,
+		logger:  logger,
+		waiters: make(map[int64]map[chan struct{}]struct{}),
+	}
+}
+
+// Start 异步订阅库存变更并分发给等待中的请求，ctx 取消时退出
+func (w *StockWatcher) Start(ctx context.Context) {
+	go func() {
+		w.logger.Info("库存长轮询已启动")
+		for change := range w.changes.Subscribe(ctx) {
+			w.notify(change.EventID)
+		}
+		w.logger.Info("库存长轮询已停止")
+	}()
+}
+
+// Wait 等待活动库存变化。since 为客户端上次看到的状态，为空时以当前状态为准；
+// since 与当前状态不一致时立即返回，否则等到档位或售罄状态变化、超时或 ctx 取消
+func (w *StockWatcher) Wait(ctx context.Context, eventID int64, since *domain.SpikeStockStatus, timeout time.Duration) (*domain.SpikeStockStatus, error) {
+	event, err := w.events.GetByID(ctx, eventID)
+	if err != nil || event == nil || !event.IsVisible() {
+		return nil, domain.ErrSpikeEventNotFound
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultStockWaitTimeout
+	}
+	timeout = min(timeout, MaxStockWaitTimeout)
+
+	// 先登记再读取当前状态，避免读取与登记之间的变更被漏掉
+	wake := w.register(eventID)
+	defer w.unregister(eventID, wake)
+
+	current, err := w.status(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	baseline := since
+	if baseline == nil {
+		baseline = current
+	}
+	if !current.SameState(baseline) {
+		current.Changed = true
+		return current, nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return current, nil
+		case _, ok := <-wake:
+			// 活动资源已清理，立即返回当前状态
+			if !ok {
+				return current, nil
+			}
+			latest, err := w.status(ctx, event)
+			if err != nil {
+				return nil, err
+			}
+			current = latest
+			if !current.SameState(baseline) {
+				current.Changed = true
+				return current, nil
+			}
+		}
+	}
+}
+
+// status 读取活动当前库存状态；库存尚未预热时按数据库已售数量计算档位
+func (w *StockWatcher) status(ctx context.Context, event *domain.SpikeEvent) (*domain.SpikeStockStatus, error) {
+	info, err := w.stock.GetStockInfo(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := info.Stock
+	if !info.Exists {
+		remaining = event.GetRemainingStock()
+	}
+	return &domain.SpikeStockStatus{
+		SpikeEventID:   event.ID,
+		RemainingStock: info.Stock,
+		Bucket:         domain.SpikeStockBucket(remaining, event.SpikeStock),
+		SoldOut:        info.SoldOut,
+	}, nil
+}
+
+func (w *StockWatcher) register(eventID int64) chan struct{} {
+	wake := make(chan struct{}, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.waiters[eventID] == nil {
+		w.waiters[eventID] = make(map[chan struct{}]struct{})
+	}
+	w.waiters[eventID][wake] = struct{}{}
+	return wake
+}
+
+func (w *StockWatcher) unregister(eventID int64, wake chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.waiters[eventID], wake)
+	if len(w.waiters[eventID]) == 0 {
+		delete(w.waiters, eventID)
+	}
+}
+
+// ReleaseEvent 结束等待该活动的全部请求并移除其登记，返回被结束的请求数；
+// 用于活动结束后的资源清理，之后到达的请求仍可正常等待
+func (w *StockWatcher) ReleaseEvent(eventID int64) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	waiters := w.waiters[eventID]
+	for wake := range waiters {
+		close(wake)
+	}
+	delete(w.waiters, eventID)
+	return len(waiters)
+}
+
+// notify 唤醒等待该活动的请求；请求尚未处理上一次唤醒时合并为一次
+func (w *StockWatcher) notify(eventID int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wake := range w.waiters[eventID] {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
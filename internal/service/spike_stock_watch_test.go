@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeStockChanges 由测试写入库存变更通知，同时充当可修改的库存来源
+type fakeStockChanges struct {
+	mu      sync.Mutex
+	stock   map[int64]*cache.StockInfo
+	changes chan cache.StockChange
+}
+
+func newFakeStockChanges() *fakeStockChanges {
+	return &fakeStockChanges{
+		stock:   make(map[int64]*cache.StockInfo),
+		changes: make(chan cache.StockChange, 16),
+	}
+}
+
+func (f *fakeStockChanges) Subscribe(ctx context.Context) <-chan cache.StockChange {
+	return f.changes
+}
+
+func (f *fakeStockChanges) GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info := *f.stock[eventID]
+	return &info, nil
+}
+
+func (f *fakeStockChanges) set(eventID, stock int64) {
+	f.mu.Lock()
+	f.stock[eventID] = &cache.StockInfo{Stock: stock, Exists: true, SoldOut: stock <= 0}
+	f.mu.Unlock()
+	f.changes <- cache.StockChange{EventID: eventID, Stock: stock}
+}
+
+func TestSpikeStockBucket(t *testing.T) {
+	tests := []struct {
+		remaining, total int64
+		want             int
+	}{
+		{0, 100, 0},
+		{-1, 100, 0},
+		{1, 100, 1},
+		{10, 100, 1},
+		{11, 100, 2},
+		{100, 100, 10},
+		{5, 0, 10},
+	}
+	for _, tt := range tests {
+		if got := domain.SpikeStockBucket(tt.remaining, tt.total); got != tt.want {
+			t.Errorf("SpikeStockBucket(%d, %d) = %d, want %d", tt.remaining, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestStockWatcher_Wait(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	event := testutil.NewSpikeEventBuilder().Active().WithStock(100).Build()
+	testutil.SeedSpikeEvents(t, events, event)
+
+	source := newFakeStockChanges()
+	source.stock[event.ID] = &cache.StockInfo{Stock: 95, Exists: true}
+	watcher := NewStockWatcher(source, source, events, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher.Start(ctx)
+
+	// 客户端携带的状态已过期时立即返回
+	status, err := watcher.Wait(ctx, event.ID, &domain.SpikeStockStatus{Bucket: 9}, time.Second)
+	if err != nil || !status.Changed || status.Bucket != 10 {
+		t.Fatalf("Wait(stale) = %+v, %v, want changed bucket 10", status, err)
+	}
+
+	// 同一档位内的变化不唤醒，跨档位后返回
+	done := make(chan *domain.SpikeStockStatus, 1)
+	go func() {
+		status, err := watcher.Wait(ctx, event.ID, &domain.SpikeStockStatus{Bucket: 10}, 5*time.Second)
+		if err != nil {
+			t.Errorf("Wait() error = %v", err)
+		}
+		done <- status
+	}()
+	waitForWaiter(t, watcher, event.ID)
+	source.set(event.ID, 91)
+	source.set(event.ID, 80)
+
+	select {
+	case status := <-done:
+		if status == nil || !status.Changed || status.Bucket != 8 || status.RemainingStock != 80 {
+			t.Errorf("Wait() = %+v, want changed bucket 8 with remaining 80", status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait() did not return after bucket changed")
+	}
+
+	// 超时返回当前状态
+	status, err = watcher.Wait(ctx, event.ID, nil, 20*time.Millisecond)
+	if err != nil || status.Changed || status.Bucket != 8 {
+		t.Errorf("Wait(timeout) = %+v, %v, want unchanged bucket 8", status, err)
+	}
+
+	// 活动资源清理时结束等待中的请求
+	go func() {
+		status, err := watcher.Wait(ctx, event.ID, nil, 5*time.Second)
+		if err != nil {
+			t.Errorf("Wait() error = %v", err)
+		}
+		done <- status
+	}()
+	waitForWaiter(t, watcher, event.ID)
+	if released := watcher.ReleaseEvent(event.ID); released != 1 {
+		t.Errorf("ReleaseEvent() = %d, want 1", released)
+	}
+	select {
+	case status := <-done:
+		if status == nil || status.Changed {
+			t.Errorf("Wait(released) = %+v, want unchanged status", status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait() did not return after event released")
+	}
+
+	if _, err := watcher.Wait(ctx, 999, nil, time.Millisecond); err != domain.ErrSpikeEventNotFound {
+		t.Errorf("Wait(missing) err = %v, want ErrSpikeEventNotFound", err)
+	}
+}
+
+// waitForWaiter 等待请求登记到 watcher，避免通知早于登记
+func waitForWaiter(t *testing.T, w *StockWatcher, eventID int64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		w.mu.Lock()
+		n := len(w.waiters[eventID])
+		w.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("waiter not registered")
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/limiter"
+)
+
+// TierPolicy 用户等级在秒杀中的权益
+type TierPolicy struct {
+	RateLimitMultiplier int64         `json:"rate_limit_multiplier"` // 单用户限流配额倍数，用于构建等级专属限流器
+	EarlyAccess         time.Duration `json:"early_access"`          // 可提前参与的时长
+	MessagePriority     uint8         `json:"message_priority"`      // 订单消息优先级（1-10）
+}
+
+// DefaultTierPolicies 默认等级权益
+func DefaultTierPolicies() map[domain.UserTier]TierPolicy {
+	return map[domain.UserTier]TierPolicy{
+		domain.UserTierRegular:  {RateLimitMultiplier: 1, EarlyAccess: 0, MessagePriority: 5},
+		domain.UserTierSilver:   {RateLimitMultiplier: 2, EarlyAccess: 0, MessagePriority: 6},
+		domain.UserTierGold:     {RateLimitMultiplier: 3, EarlyAccess: 5 * time.Second, MessagePriority: 7},
+		domain.UserTierPlatinum: {RateLimitMultiplier: 5, EarlyAccess: 10 * time.Second, MessagePriority: 8},
+	}
+}
+
+// SetTierLimiters 设置按用户等级区分的单用户限流器，未配置的等级使用默认 userLimiter
+func (s *SpikeService) SetTierLimiters(limiters map[domain.UserTier]limiter.Limiter) {
+	s.tierLimiters = limiters
+}
+
+// tierPolicy 返回用户等级对应的权益，未配置时退回普通会员权益
+func (s *SpikeService) tierPolicy(tier domain.UserTier) TierPolicy {
+	if policy, ok := s.cfg().TierPolicies[tier.OrDefault()]; ok {
+		return policy
+	}
+	if policy, ok := s.cfg().TierPolicies[domain.UserTierRegular]; ok {
+		return policy
+	}
+	return TierPolicy{RateLimitMultiplier: 1}
+}
+
+// userLimiterFor 返回用户等级对应的单用户限流器
+func (s *SpikeService) userLimiterFor(tier domain.UserTier) limiter.Limiter {
+	if l, ok := s.tierLimiters[tier.OrDefault()]; ok && l != nil {
+		return l
+	}
+	return s.userLimiter
+}
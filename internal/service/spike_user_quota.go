@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// dailyQuotaRetention 当日结束后次数计数的保留时间，覆盖跨零点落库时归还次数的窗口
+const dailyQuotaRetention = 24 * time.Hour
+
+// dailyQuotaDateLayout 每日配额的日期格式
+const dailyQuotaDateLayout = "20060102"
+
+//go:generate moq -rm -out spike_user_quota_moq_test.go . DailyQuotaCache
+
+// DailyQuotaCache 每日秒杀成功次数所需的缓存操作（由 cache.SpikeCache 实现）
+type DailyQuotaCache interface {
+	ReserveDailyQuota(ctx context.Context, date string, userID, max int64, ttl time.Duration) (bool, error)
+	ReleaseDailyQuota(ctx context.Context, date string, userID int64) error
+	GetDailyQuotaUsage(ctx context.Context, date string, userID int64) (int64, error)
+}
+
+// DailyQuota 在参与路径上执行单用户跨活动的每日秒杀成功次数上限。
+// 规则持久化在数据库中，进程内整体缓存 cacheTTL，管理员修改后本进程立即生效，其他进程在 cacheTTL 内生效；
+// 次数按服务端时区的自然日计数，订单取消或过期不归还次数
+type DailyQuota struct {
+	rules    repo.SpikeQuotaRepository
+	cache    DailyQuotaCache
+	cacheTTL time.Duration
+	location *time.Location
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	limits   map[int64]int64 // userID -> 每日上限，含默认规则
+	loadedAt time.Time
+}
+
+// NewDailyQuota 创建每日秒杀成功次数控制
+func NewDailyQuota(rules repo.SpikeQuotaRepository, quotaCache DailyQuotaCache, logger *zap.Logger) *DailyQuota {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &DailyQuota{
+		rules:    rules,
+		cache:    quotaCache,
+		cacheTTL: 30 * time.Second,
+		location: time.Local,
+		logger:   logger,
+	}
+}
+
+// Reserve 为用户占用一次 at 所在自然日的秒杀成功次数。
+// 返回 reserved 表示是否实际占用（用户不限制时为 false，无需归还），allowed 为 false 表示已达到上限
+func (q *DailyQuota) Reserve(ctx context.Context, userID int64, at time.Time) (reserved, allowed bool, err error) {
+	limit, _, err := q.limit(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	if limit <= 0 {
+		return false, true, nil
+	}
+
+	ok, err := q.cache.ReserveDailyQuota(ctx, q.date(at), userID, limit, q.ttl(at))
+	if err != nil {
+		return false, false, err
+	}
+	return ok, ok, nil
+}
+
+// Release 归还用户在 at 所在自然日的一次秒杀成功次数，失败只记录日志
+func (q *DailyQuota) Release(ctx context.Context, userID int64, at time.Time) {
+	date := q.date(at)
+	if err := q.cache.ReleaseDailyQuota(ctx, date, userID); err != nil {
+		q.logger.Error("归还每日秒杀次数失败",
+			zap.String("date", date),
+			zap.Int64("user_id", userID),
+			zap.Error(err))
+	}
+}
+
+// Usage 获取用户当日的配额使用情况
+func (q *DailyQuota) Usage(ctx context.Context, userID int64) (*domain.SpikeUserQuotaUsage, error) {
+	limit, override, err := q.limit(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	date := q.date(time.Now())
+	used, err := q.cache.GetDailyQuotaUsage(ctx, date, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &domain.SpikeUserQuotaUsage{
+		UserID:       userID,
+		Date:         date,
+		MaxDailyWins: limit,
+		Override:     override,
+		Used:         used,
+		Remaining:    -1,
+	}
+	if limit > 0 {
+		usage.Remaining = max(limit-used, 0)
+	}
+	return usage, nil
+}
+
+// Invalidate 丢弃进程内缓存的规则，下次参与时重新读取
+func (q *DailyQuota) Invalidate() {
+	q.mu.Lock()
+	q.limits = nil
+	q.mu.Unlock()
+}
+
+// limit 返回用户生效的每日上限，override 表示是否为用户单独设置的规则
+func (q *DailyQuota) limit(ctx context.Context, userID int64) (limit int64, override bool, err error) {
+	q.mu.Lock()
+	limits, loadedAt := q.limits, q.loadedAt
+	q.mu.Unlock()
+
+	if limits == nil || time.Since(loadedAt) >= q.cacheTTL {
+		quotas, err := q.rules.List(ctx)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to list spike user quotas: %w", err)
+		}
+		limits = make(map[int64]int64, len(quotas))
+		for _, quota := range quotas {
+			limits[quota.UserID] = quota.MaxDailyWins
+		}
+
+		q.mu.Lock()
+		q.limits, q.loadedAt = limits, time.Now()
+		q.mu.Unlock()
+	}
+
+	if limit, ok := limits[userID]; ok && userID != domain.SpikeQuotaDefaultUserID {
+		return limit, true, nil
+	}
+	return limits[domain.SpikeQuotaDefaultUserID], false, nil
+}
+
+// date 返回 at 在服务端时区的日期
+func (q *DailyQuota) date(at time.Time) string {
+	return at.In(q.location).Format(dailyQuotaDateLayout)
+}
+
+// ttl 返回 at 所在自然日剩余时间加保留时间
+func (q *DailyQuota) ttl(at time.Time) time.Duration {
+	local := at.In(q.location)
+	endOfDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, q.location)
+	return endOfDay.Sub(local) + dailyQuotaRetention
+}
+
+// SpikeQuotaService 定义秒杀用户每日配额管理接口
+type SpikeQuotaService interface {
+	// GetRules 获取默认规则与按用户覆盖的规则
+	GetRules(ctx context.Context) (*domain.SpikeQuotaRules, error)
+	// SetDefault 设置全体用户的默认每日上限
+	SetDefault(ctx context.Context, req *domain.SetSpikeUserQuotaRequest) (*domain.SpikeQuotaRules, error)
+	// SetUserQuota 为用户单独设置每日上限，覆盖默认上限
+	SetUserQuota(ctx context.Context, userID int64, req *domain.SetSpikeUserQuotaRequest) (*domain.SpikeUserQuotaUsage, error)
+	// DeleteUserQuota 删除用户单独设置的上限，恢复使用默认上限
+	DeleteUserQuota(ctx context.Context, userID int64) error
+	// GetUserUsage 获取用户当日的配额使用情况
+	GetUserUsage(ctx context.Context, userID int64) (*domain.SpikeUserQuotaUsage, error)
+}
+
+// spikeQuotaService 是SpikeQuotaService接口的实现
+type spikeQuotaService struct {
+	rules  repo.SpikeQuotaRepository
+	quota  *DailyQuota
+	logger *zap.Logger
+}
+
+// NewSpikeQuotaService 创建秒杀用户每日配额管理服务，规则修改后使 quota 的进程内缓存失效
+func NewSpikeQuotaService(rules repo.SpikeQuotaRepository, quota *DailyQuota, logger *zap.Logger) SpikeQuotaService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &spikeQuotaService{rules: rules, quota: quota, logger: logger}
+}
+
+// GetRules 获取配额规则，默认规则缺失时按不限制返回
+func (s *spikeQuotaService) GetRules(ctx context.Context) (*domain.SpikeQuotaRules, error) {
+	quotas, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &domain.SpikeQuotaRules{
+		Default:   &domain.SpikeUserQuota{UserID: domain.SpikeQuotaDefaultUserID},
+		Overrides: []*domain.SpikeUserQuota{},
+	}
+	for _, quota := range quotas {
+		if quota.IsDefault() {
+			rules.Default = quota
+			continue
+		}
+		rules.Overrides = append(rules.Overrides, quota)
+	}
+	return rules, nil
+}
+
+// SetDefault 设置默认每日上限
+func (s *spikeQuotaService) SetDefault(ctx context.Context, req *domain.SetSpikeUserQuotaRequest) (*domain.SpikeQuotaRules, error) {
+	if err := s.rules.Upsert(ctx, domain.SpikeQuotaDefaultUserID, req.MaxDailyWins); err != nil {
+		return nil, err
+	}
+	s.quota.Invalidate()
+
+	s.logger.Info("秒杀每日默认配额已更新", zap.Int64("max_daily_wins", req.MaxDailyWins))
+	return s.GetRules(ctx)
+}
+
+// SetUserQuota 为用户单独设置每日上限
+func (s *spikeQuotaService) SetUserQuota(ctx context.Context, userID int64, req *domain.SetSpikeUserQuotaRequest) (*domain.SpikeUserQuotaUsage, error) {
+	if err := s.rules.Upsert(ctx, userID, req.MaxDailyWins); err != nil {
+		return nil, err
+	}
+	s.quota.Invalidate()
+
+	s.logger.Info("用户秒杀每日配额已更新",
+		zap.Int64("user_id", userID),
+		zap.Int64("max_daily_wins", req.MaxDailyWins))
+	return s.quota.Usage(ctx, userID)
+}
+
+// DeleteUserQuota 删除用户单独设置的上限
+func (s *spikeQuotaService) DeleteUserQuota(ctx context.Context, userID int64) error {
+	if err := s.rules.Delete(ctx, userID); err != nil {
+		return err
+	}
+	s.quota.Invalidate()
+
+	s.logger.Info("用户秒杀每日配额已删除", zap.Int64("user_id", userID))
+	return nil
+}
+
+// GetUserUsage 获取用户当日的配额使用情况
+func (s *spikeQuotaService) GetUserUsage(ctx context.Context, userID int64) (*domain.SpikeUserQuotaUsage, error) {
+	return s.quota.Usage(ctx, userID)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeSpikeQuotaRepo 在内存中保存配额规则
+type fakeSpikeQuotaRepo struct {
+	*mocks.SpikeQuotaRepositoryMock
+
+	limits map[int64]int64
+}
+
+func newFakeSpikeQuotaRepo(limits map[int64]int64) *fakeSpikeQuotaRepo {
+	f := &fakeSpikeQuotaRepo{
+		SpikeQuotaRepositoryMock: &mocks.SpikeQuotaRepositoryMock{},
+		limits:                   limits,
+	}
+	f.ListFunc = f.list
+	f.UpsertFunc = f.upsert
+	f.DeleteFunc = f.delete
+	return f
+}
+
+func (f *fakeSpikeQuotaRepo) list(ctx context.Context) ([]*domain.SpikeUserQuota, error) {
+	var quotas []*domain.SpikeUserQuota
+	for userID, limit := range f.limits {
+		quotas = append(quotas, &domain.SpikeUserQuota{UserID: userID, MaxDailyWins: limit})
+	}
+	return quotas, nil
+}
+
+func (f *fakeSpikeQuotaRepo) upsert(ctx context.Context, userID, maxDailyWins int64) error {
+	f.limits[userID] = maxDailyWins
+	return nil
+}
+
+func (f *fakeSpikeQuotaRepo) delete(ctx context.Context, userID int64) error {
+	if _, ok := f.limits[userID]; !ok || userID == domain.SpikeQuotaDefaultUserID {
+		return domain.ErrSpikeUserQuotaNotFound
+	}
+	delete(f.limits, userID)
+	return nil
+}
+
+// fakeDailyQuotaCache 在内存中记录各用户每日的秒杀成功次数
+type fakeDailyQuotaCache struct {
+	*DailyQuotaCacheMock
+
+	mu   sync.Mutex
+	used map[string]int64 // date:userID -> 次数
+}
+
+func newFakeDailyQuotaCache() *fakeDailyQuotaCache {
+	f := &fakeDailyQuotaCache{
+		DailyQuotaCacheMock: &DailyQuotaCacheMock{},
+		used:                make(map[string]int64),
+	}
+	f.ReserveDailyQuotaFunc = f.reserveDailyQuota
+	f.ReleaseDailyQuotaFunc = f.releaseDailyQuota
+	f.GetDailyQuotaUsageFunc = f.getDailyQuotaUsage
+	return f
+}
+
+func dailyQuotaKey(date string, userID int64) string {
+	return date + ":" + strconv.FormatInt(userID, 10)
+}
+
+func (f *fakeDailyQuotaCache) reserveDailyQuota(ctx context.Context, date string, userID, max int64, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := dailyQuotaKey(date, userID)
+	if f.used[key] >= max {
+		return false, nil
+	}
+	f.used[key]++
+	return true, nil
+}
+
+func (f *fakeDailyQuotaCache) releaseDailyQuota(ctx context.Context, date string, userID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := dailyQuotaKey(date, userID)
+	if f.used[key]--; f.used[key] <= 0 {
+		delete(f.used, key)
+	}
+	return nil
+}
+
+func (f *fakeDailyQuotaCache) getDailyQuotaUsage(ctx context.Context, date string, userID int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.used[dailyQuotaKey(date, userID)], nil
+}
+
+func TestDailyQuota_Reserve(t *testing.T) {
+	rules := newFakeSpikeQuotaRepo(map[int64]int64{domain.SpikeQuotaDefaultUserID: 2, 8: 1, 9: 0})
+	quotaCache := newFakeDailyQuotaCache()
+	quota := NewDailyQuota(rules, quotaCache, nil)
+	ctx := context.Background()
+	today := time.Now()
+
+	for i := range 2 {
+		if reserved, allowed, err := quota.Reserve(ctx, 7, today); err != nil || !reserved || !allowed {
+			t.Fatalf("Reserve() #%d = %v, %v, %v; want reserved under default limit", i+1, reserved, allowed, err)
+		}
+	}
+	if _, allowed, _ := quota.Reserve(ctx, 7, today); allowed {
+		t.Fatal("Reserve() over default limit allowed")
+	}
+	// 次数按自然日计数，次日重新计数
+	if _, allowed, _ := quota.Reserve(ctx, 7, today.AddDate(0, 0, 1)); !allowed {
+		t.Error("Reserve() on next day not allowed")
+	}
+
+	// 用户单独设置的规则覆盖默认规则，0 表示该用户不限制
+	quota.Reserve(ctx, 8, today)
+	if _, allowed, _ := quota.Reserve(ctx, 8, today); allowed {
+		t.Error("Reserve() over user override allowed")
+	}
+	if reserved, allowed, err := quota.Reserve(ctx, 9, today); err != nil || reserved || !allowed {
+		t.Errorf("unlimited user Reserve() = %v, %v, %v; want allowed without reservation", reserved, allowed, err)
+	}
+
+	// 归还后可再次参与
+	quota.Release(ctx, 7, today)
+	if _, allowed, _ := quota.Reserve(ctx, 7, today); !allowed {
+		t.Error("Reserve() after Release() not allowed")
+	}
+
+	// 规则在进程内缓存，修改后需使缓存失效
+	if n := len(rules.ListCalls()); n != 1 {
+		t.Errorf("rules loaded %d times, want 1", n)
+	}
+	svc := NewSpikeQuotaService(rules, quota, nil)
+	usage, err := svc.SetUserQuota(ctx, 7, &domain.SetSpikeUserQuotaRequest{MaxDailyWins: 5})
+	if err != nil {
+		t.Fatalf("SetUserQuota() error = %v", err)
+	}
+	if !usage.Override || usage.MaxDailyWins != 5 || usage.Used != 2 || usage.Remaining != 3 {
+		t.Errorf("SetUserQuota() usage = %+v, want override of 5 with 2 used", usage)
+	}
+}
+
+func TestSpikeService_ParticipateSpike_DailyQuota(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	first := testutil.NewSpikeEventBuilder().Active().WithStock(10).Build()
+	second := testutil.NewSpikeEventBuilder().Active().WithStock(10).Build()
+	testutil.SeedSpikeEvents(t, events, first, second)
+
+	spikeCache := NewMockSpikeCache()
+	spikeCache.WarmupStock(context.Background(), first.ID, 10, time.Hour)
+	spikeCache.WarmupStock(context.Background(), second.ID, 10, time.Hour)
+
+	svc := NewSpikeService(events, NewMockSpikeOrderRepository(), newMockProductRepository(), newMockInventoryRepository(),
+		newFakeUserRepo(), nil, spikeCache, NewMockSpikeProducer(), NewMockLimiter(true), NewMockLimiter(true),
+		DefaultSpikeServiceConfig(), zap.NewNop())
+	quotaCache := newFakeDailyQuotaCache()
+	svc.SetDailyQuota(NewDailyQuota(newFakeSpikeQuotaRepo(map[int64]int64{domain.SpikeQuotaDefaultUserID: 1, 8: 5}), quotaCache, nil))
+
+	ctx := context.Background()
+	result, err := svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: first.ID, Quantity: 1, IdempotencyKey: "daily_1",
+	}, 7)
+	if err != nil || !result.Success {
+		t.Fatalf("first participation = %+v, %v; want success", result, err)
+	}
+
+	// 不同活动共享每日次数
+	result, err = svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: second.ID, Quantity: 1, IdempotencyKey: "daily_2",
+	}, 7)
+	if err != nil {
+		t.Fatalf("ParticipateSpike() error = %v", err)
+	}
+	if result.Success || result.Code != domain.SpikeParticipationCodeDailyQuota || result.Retryable {
+		t.Errorf("second participation = %+v, want code %s not retryable", result, domain.SpikeParticipationCodeDailyQuota)
+	}
+	if info, _ := spikeCache.GetStockInfo(ctx, second.ID); info.Stock != 10 {
+		t.Errorf("stock of second event = %d, want untouched 10", info.Stock)
+	}
+
+	// 重复参与被去重拒绝时归还占用的次数
+	result, _ = svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: first.ID, Quantity: 1, IdempotencyKey: "daily_3",
+	}, 8)
+	if !result.Success {
+		t.Fatalf("participation of another user = %+v, want success", result)
+	}
+	result, _ = svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+		SpikeEventID: first.ID, Quantity: 1, IdempotencyKey: "daily_4",
+	}, 8)
+	if result.Success || result.Code == domain.SpikeParticipationCodeDailyQuota {
+		t.Fatalf("duplicate participation = %+v, want rejected by dedup", result)
+	}
+	usage, _ := quotaCache.GetDailyQuotaUsage(ctx, time.Now().Format(dailyQuotaDateLayout), 8)
+	if usage != 1 {
+		t.Errorf("daily wins of user 8 = %d, want 1 after failed decrement", usage)
+	}
+}
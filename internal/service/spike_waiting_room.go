@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// waitingRoomTTLBuffer 排队数据在活动结束后的保留时长，便于用户查询最终状态
+const waitingRoomTTLBuffer = 10 * time.Minute
+
+// ErrQueueTicketNotFound 排队令牌不存在、已过期或不属于当前用户
+var ErrQueueTicketNotFound = errors.New("排队令牌不存在或已过期")
+
+// WaitingRoomStore 等候室排队存储（由 cache.SpikeCache 实现）
+type WaitingRoomStore interface {
+	EnqueueWaitingRoom(ctx context.Context, eventID, userID int64, token string, rate int64, ttl time.Duration) (string, error)
+	GetWaitingRoomTicket(ctx context.Context, eventID int64, token string) (*cache.WaitingRoomTicket, bool, error)
+}
+
+// SetWaitingRoom 启用排队模式：waiting_room_rate 大于 0 的活动，参与请求先进入等候室，
+// 由 WaitingRoomDispatcher 按活动的每秒放行人数依次放行，放行后携带排队令牌再次参与
+func (s *SpikeService) SetWaitingRoom(store WaitingRoomStore) {
+	s.waitingRoom = store
+}
+
+// waitingRoomEnabled 判断活动是否处于排队模式
+func (s *SpikeService) waitingRoomEnabled(event *domain.SpikeEvent) bool {
+	return s.waitingRoom != nil && event.WaitingRoomRate > 0
+}
+
+// waitingRoomTicket 查询属于当前用户的排队票据，不存在或不属于该用户时返回 nil
+func (s *SpikeService) waitingRoomTicket(ctx context.Context, eventID int64, token string, userID int64) (*cache.WaitingRoomTicket, error) {
+	ticket, found, err := s.waitingRoom.GetWaitingRoomTicket(ctx, eventID, token)
+	if err != nil {
+		return nil, err
+	}
+	if !found || ticket.UserID != userID {
+		return nil, nil
+	}
+	return ticket, nil
+}
+
+// enterWaitingRoom 用户进入等候室，返回携带排队令牌与位置的排队响应；用户已在队列中时沿用原令牌
+func (s *SpikeService) enterWaitingRoom(ctx context.Context, event *domain.SpikeEvent, userID int64) (*domain.SpikeParticipationResponse, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate queue token: %w", err)
+	}
+	// 令牌以活动ID开头，查询排队状态时无需额外传入活动ID
+	token := strconv.FormatInt(event.ID, 10) + "." + hex.EncodeToString(raw)
+	ttl := time.Until(event.EndAt) + waitingRoomTTLBuffer
+
+	token, err := s.waitingRoom.EnqueueWaitingRoom(ctx, event.ID, userID, token, event.WaitingRoomRate, ttl)
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := s.waitingRoomTicket(ctx, event.ID, token, userID)
+	if err != nil {
+		return nil, err
+	}
+	var position int64
+	if ticket != nil {
+		position = ticket.Position
+	}
+	return queuedResponse(token, position, event.WaitingRoomRate), nil
+}
+
+// queuedResponse 创建排队响应，建议等待时长按放行速率估算
+func queuedResponse(token string, position, rate int64) *domain.SpikeParticipationResponse {
+	response := domain.NewSpikeParticipationFailure(domain.SpikeParticipationCodeQueued, "排队中，请稍候").
+		WithRetryAfter(estimateQueueWait(position, rate))
+	response.QueueToken = token
+	response.QueueLength = position
+	return response
+}
+
+// estimateQueueWait 按放行速率估算排在 position 位的用户还需等待的时长
+func estimateQueueWait(position, rate int64) time.Duration {
+	if position <= 0 || rate <= 0 {
+		return 0
+	}
+	return time.Duration(position) * time.Second / time.Duration(rate)
+}
+
+// GetQueueStatus 查询用户在等候室中的排队位置与是否已放行
+func (s *SpikeService) GetQueueStatus(ctx context.Context, token string, userID int64) (*domain.SpikeQueueStatus, error) {
+	if s.waitingRoom == nil {
+		return nil, ErrQueueTicketNotFound
+	}
+	eventPart, _, ok := strings.Cut(token, ".")
+	eventID, err := strconv.ParseInt(eventPart, 10, 64)
+	if !ok || err != nil || eventID <= 0 {
+		return nil, ErrQueueTicketNotFound
+	}
+
+	ticket, err := s.waitingRoomTicket(ctx, eventID, token, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue ticket: %w", err)
+	}
+	if ticket == nil {
+		return nil, ErrQueueTicketNotFound
+	}
+
+	status := &domain.SpikeQueueStatus{
+		QueueToken:   token,
+		SpikeEventID: eventID,
+		Position:     ticket.Position,
+		Admitted:     ticket.Position == 0,
+	}
+	if !status.Admitted {
+		if event, err := s.getSpikeEventWithCache(ctx, eventID); err == nil {
+			status.EstimatedWaitSeconds = int64(estimateQueueWait(ticket.Position, event.WaitingRoomRate).Seconds())
+		}
+	}
+	return status, nil
+}
+
+// WaitingRoomAdmitter 推进等候室放行进度（由 cache.SpikeCache 实现）
+type WaitingRoomAdmitter interface {
+	WaitingRoomEvents(ctx context.Context) (map[int64]int64, error)
+	AdmitWaitingRoom(ctx context.Context, eventID, rate int64, now time.Time) (int64, bool, error)
+}
+
+// WaitingRoomDispatcher 定期按各活动的每秒放行人数放行等候室中的用户。
+// 放行进度按距上次放行的时长计算并在 Redis 中原子推进，多个实例同时运行时总放行速率不变
+type WaitingRoomDispatcher struct {
+	admitter WaitingRoomAdmitter
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewWaitingRoomDispatcher 创建等候室放行器
+func NewWaitingRoomDispatcher(admitter WaitingRoomAdmitter, interval time.Duration, logger *zap.Logger) *WaitingRoomDispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &WaitingRoomDispatcher{
+		admitter: admitter,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start 异步启动放行器，ctx 取消时退出
+func (d *WaitingRoomDispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		d.logger.Info("等候室放行器已启动", zap.Duration("interval", d.interval))
+		for {
+			select {
+			case <-ctx.Done():
+				d.logger.Info("等候室放行器已停止")
+				return
+			case <-ticker.C:
+				d.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 对所有排队中的活动执行一次放行
+func (d *WaitingRoomDispatcher) RunOnce(ctx context.Context) {
+	events, err := d.admitter.WaitingRoomEvents(ctx)
+	if err != nil {
+		d.logger.Warn("查询排队活动失败", zap.Error(err))
+		return
+	}
+	now := time.Now()
+	for eventID, rate := range events {
+		waiting, active, err := d.admitter.AdmitWaitingRoom(ctx, eventID, rate, now)
+		if err != nil {
+			d.logger.Warn("等候室放行失败", zap.Int64("spike_event_id", eventID), zap.Error(err))
+			continue
+		}
+		if active && waiting > 0 {
+			d.logger.Debug("等候室放行",
+				zap.Int64("spike_event_id", eventID),
+				zap.Int64("rate", rate),
+				zap.Int64("waiting", waiting))
+		}
+	}
+}
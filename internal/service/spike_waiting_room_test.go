@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeWaitingRoom 内存版等候室，每次放行按 rate 推进已放行序号
+type fakeWaitingRoom struct {
+	mu       sync.Mutex
+	seq      map[int64]int64
+	admitted map[int64]int64
+	rates    map[int64]int64
+	users    map[string]string
+	tickets  map[string][2]int64 // token -> {user_id, seq}
+}
+
+func newFakeWaitingRoom() *fakeWaitingRoom {
+	return &fakeWaitingRoom{
+		seq:      make(map[int64]int64),
+		admitted: make(map[int64]int64),
+		rates:    make(map[int64]int64),
+		users:    make(map[string]string),
+		tickets:  make(map[string][2]int64),
+	}
+}
+
+func (f *fakeWaitingRoom) EnqueueWaitingRoom(_ context.Context, eventID, userID int64, token string, rate int64, _ time.Duration) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rates[eventID] = rate
+	userKey := strconv.FormatInt(eventID, 10) + ":" + strconv.FormatInt(userID, 10)
+	if existing, ok := f.users[userKey]; ok {
+		return existing, nil
+	}
+	f.seq[eventID]++
+	f.users[userKey] = token
+	f.tickets[token] = [2]int64{userID, f.seq[eventID]}
+	return token, nil
+}
+
+func (f *fakeWaitingRoom) GetWaitingRoomTicket(_ context.Context, eventID int64, token string) (*cache.WaitingRoomTicket, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ticket, ok := f.tickets[token]
+	if !ok {
+		return nil, false, nil
+	}
+	return &cache.WaitingRoomTicket{UserID: ticket[0], Position: max(ticket[1]-f.admitted[eventID], 0)}, true, nil
+}
+
+func (f *fakeWaitingRoom) WaitingRoomEvents(_ context.Context) (map[int64]int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	events := make(map[int64]int64, len(f.rates))
+	for eventID, rate := range f.rates {
+		events[eventID] = rate
+	}
+	return events, nil
+}
+
+func (f *fakeWaitingRoom) AdmitWaitingRoom(_ context.Context, eventID, rate int64, _ time.Time) (int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.admitted[eventID] = min(f.admitted[eventID]+rate, f.seq[eventID])
+	return f.seq[eventID] - f.admitted[eventID], true, nil
+}
+
+func TestSpikeService_ParticipateSpike_WaitingRoom(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	queued := testutil.NewSpikeEventBuilder().Active().WithWaitingRoom(1).Build()
+	testutil.SeedSpikeEvents(t, events, queued)
+
+	room := newFakeWaitingRoom()
+	svc := NewSpikeService(events, NewMockSpikeOrderRepository(), nil, nil, nil, nil, NewMockSpikeCache(), NewMockSpikeProducer(),
+		NewMockLimiter(true), NewMockLimiter(true), DefaultSpikeServiceConfig(), zap.NewNop())
+	svc.SetWaitingRoom(room)
+	ctx := context.Background()
+	if err := svc.WarmupStock(ctx, queued.ID, false); err != nil {
+		t.Fatalf("WarmupStock() error = %v", err)
+	}
+
+	participate := func(userID int64, queueToken, key string) *domain.SpikeParticipationResponse {
+		t.Helper()
+		result, err := svc.ParticipateSpike(ctx, &domain.SpikeParticipationRequest{
+			SpikeEventID:   queued.ID,
+			Quantity:       1,
+			IdempotencyKey: key,
+			QueueToken:     queueToken,
+		}, userID)
+		if err != nil {
+			t.Fatalf("ParticipateSpike() error = %v", err)
+		}
+		return result
+	}
+
+	first := participate(1, "", "wr-1")
+	second := participate(2, "", "wr-2")
+	if first.Code != domain.SpikeParticipationCodeQueued || first.QueueToken == "" || first.QueueLength != 1 {
+		t.Fatalf("first participation = %+v, want queued at position 1", first)
+	}
+	if second.QueueLength != 2 || !second.Retryable || second.RetryAfterMs != 2000 {
+		t.Errorf("second participation = %+v, want queued at position 2 retry after 2s", second)
+	}
+	if again := participate(1, "", "wr-1-again"); again.QueueToken != first.QueueToken {
+		t.Errorf("requeue token = %q, want original %q", again.QueueToken, first.QueueToken)
+	}
+
+	// 放行前携带令牌仍在排队
+	if got := participate(1, first.QueueToken, "wr-1-early"); got.Code != domain.SpikeParticipationCodeQueued || got.QueueLength != 1 {
+		t.Errorf("participation before admission = %+v, want queued at position 1", got)
+	}
+	status, err := svc.GetQueueStatus(ctx, first.QueueToken, 1)
+	if err != nil || status.Admitted || status.Position != 1 || status.EstimatedWaitSeconds != 1 {
+		t.Errorf("GetQueueStatus() = %+v, %v, want position 1 not admitted", status, err)
+	}
+	if _, err := svc.GetQueueStatus(ctx, first.QueueToken, 2); !errors.Is(err, ErrQueueTicketNotFound) {
+		t.Errorf("GetQueueStatus(other user) error = %v, want ErrQueueTicketNotFound", err)
+	}
+
+	NewWaitingRoomDispatcher(room, time.Second, zap.NewNop()).RunOnce(ctx)
+
+	if status, err := svc.GetQueueStatus(ctx, first.QueueToken, 1); err != nil || !status.Admitted {
+		t.Errorf("GetQueueStatus() after dispatch = %+v, %v, want admitted", status, err)
+	}
+	// 其他用户的令牌无效，按未携带令牌处理
+	if got := participate(2, first.QueueToken, "wr-2-stolen"); got.Code != domain.SpikeParticipationCodeQueued || got.QueueToken != second.QueueToken {
+		t.Errorf("participation with another user's token = %+v, want own queue position", got)
+	}
+	if got := participate(1, first.QueueToken, "wr-1-admitted"); !got.Success {
+		t.Errorf("participation after admission = %+v, want success", got)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// StockGuardianCache 库存守护所需的缓存操作（由 cache.SpikeCache 实现）
+type StockGuardianCache interface {
+	GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+	WarmupStock(ctx context.Context, eventID int64, stock int64, ttl time.Duration) error
+	WarmupStockBuckets(ctx context.Context, eventID int64, stock int64, buckets int, ttl time.Duration) error
+	FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error
+	UnfreezeEvent(ctx context.Context, eventID int64) error
+	AcquireRewarmLock(ctx context.Context, eventID int64, owner string, ttl time.Duration) (bool, error)
+	ReleaseRewarmLock(ctx context.Context, eventID int64, owner string) error
+}
+
+// GuardedEventSource 提供需要守护的活动（由 repo.SpikeEventRepository 实现）
+type GuardedEventSource interface {
+	GetByID(ctx context.Context, id int64) (*domain.SpikeEvent, error)
+	GetActiveEvents(ctx context.Context) ([]*domain.SpikeEvent, error)
+}
+
+// SoldQuantitySource 提供活动已售数量（由 repo.SpikeOrderRepository 实现）
+type SoldQuantitySource interface {
+	SumQuantityByEvent(ctx context.Context, spikeEventID int64, statuses ...domain.SpikeOrderStatus) (int64, error)
+}
+
+// StockGuardianConfig 库存守护配置
+type StockGuardianConfig struct {
+	CheckInterval  time.Duration // 定时巡检进行中活动库存键的间隔
+	GracePeriod    time.Duration // 冻结后等待在途下单消息落库的时间，之后再以数据库为准计算剩余库存
+	FreezeTTL      time.Duration // 冻结标记的兜底过期时间
+	LockTTL        time.Duration // 恢复锁的过期时间
+	StockCacheTTL  time.Duration // 恢复后库存键的最短过期时间
+	StockTTLBuffer time.Duration // 恢复后库存键在活动结束后额外保留的时间
+}
+
+// DefaultStockGuardianConfig 默认库存守护配置
+func DefaultStockGuardianConfig() *StockGuardianConfig {
+	return &StockGuardianConfig{
+		CheckInterval:  5 * time.Second,
+		GracePeriod:    2 * time.Second,
+		FreezeTTL:      time.Minute,
+		LockTTL:        30 * time.Second,
+		StockCacheTTL:  2 * time.Hour,
+		StockTTLBuffer: 30 * time.Minute,
+	}
+}
+
+// StockGuardian 守护进行中活动的 Redis 库存键。
+// Redis 故障切换可能丢失未同步的库存键，守护器通过以下途径发现丢失：
+// 1) 预减库存时脚本返回库存不存在（ReportMiss）；
+// 2) 定时巡检进行中的活动；
+// 3) 外部故障切换通知（NotifyFailover，如 Sentinel 的 +switch-master 事件）。
+// 发现后在分布式锁保护下冻结活动、等待在途消息落库、按数据库已售数量重建库存，再解除冻结。
+type StockGuardian struct {
+	events GuardedEventSource
+	orders SoldQuantitySource
+	cache  StockGuardianCache
+	config *StockGuardianConfig
+	logger *zap.Logger
+
+	owner    string        // 恢复锁持有者标识，区分多实例
+	misses   chan int64    // 请求路径上报的缺失活动
+	failover chan struct{} // 故障切换通知
+}
+
+// NewStockGuardian 创建库存守护器
+func NewStockGuardian(events GuardedEventSource, orders SoldQuantitySource, stockCache StockGuardianCache, config *StockGuardianConfig, logger *zap.Logger) *StockGuardian {
+	if config == nil {
+		config = DefaultStockGuardianConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &StockGuardian{
+		events:   events,
+		orders:   orders,
+		cache:    stockCache,
+		config:   config,
+		logger:   logger,
+		owner:    uuid.New().String(),
+		misses:   make(chan int64, 64),
+		failover: make(chan struct{}, 1),
+	}
+}
+
+// ReportMiss 上报活动库存键缺失，不阻塞请求路径；队列已满时丢弃，由定时巡检兜底
+func (g *StockGuardian) ReportMiss(eventID int64) {
+	select {
+	case g.misses <- eventID:
+	default:
+	}
+}
+
+// NotifyFailover 通知 Redis 发生了故障切换，触发一次全量巡检
+func (g *StockGuardian) NotifyFailover() {
+	select {
+	case g.failover <- struct{}{}:
+	default:
+	}
+}
+
+// Start 异步启动守护循环，ctx 取消时退出
+func (g *StockGuardian) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(g.config.CheckInterval)
+		defer ticker.Stop()
+
+		g.logger.Info("库存守护已启动", zap.Duration("interval", g.config.CheckInterval))
+		for {
+			select {
+			case <-ctx.Done():
+				g.logger.Info("库存守护已停止")
+				return
+			case eventID := <-g.misses:
+				event, err := g.events.GetByID(ctx, eventID)
+				if err != nil {
+					g.logger.Warn("获取库存缺失的活动失败", zap.Int64("event_id", eventID), zap.Error(err))
+					continue
+				}
+				if err := g.Recover(ctx, event); err != nil {
+					g.logger.Error("恢复活动库存失败", zap.Int64("event_id", eventID), zap.Error(err))
+				}
+			case <-g.failover:
+				g.logger.Warn("收到 Redis 故障切换通知，开始巡检活动库存")
+				g.CheckAll(ctx)
+			case <-ticker.C:
+				g.CheckAll(ctx)
+			}
+		}
+	}()
+}
+
+// CheckAll 巡检所有进行中的活动，库存键缺失时执行恢复
+func (g *StockGuardian) CheckAll(ctx context.Context) {
+	events, err := g.events.GetActiveEvents(ctx)
+	if err != nil {
+		g.logger.Warn("获取进行中活动失败", zap.Error(err))
+		return
+	}
+
+	for _, event := range events {
+		if !event.IsActive() {
+			continue
+		}
+		info, err := g.cache.GetStockInfo(ctx, event.ID)
+		if err != nil {
+			// Redis 不可用时无法判断键是否丢失，等待下一轮
+			g.logger.Warn("巡检库存失败", zap.Int64("event_id", event.ID), zap.Error(err))
+			continue
+		}
+		if info.Exists {
+			continue
+		}
+		if err := g.Recover(ctx, event); err != nil {
+			g.logger.Error("恢复活动库存失败", zap.Int64("event_id", event.ID), zap.Error(err))
+		}
+	}
+}
+
+// Recover 恢复单个活动的库存键：加锁 → 冻结 → 等待在途消息 → 按数据库重建 → 解冻。
+// 其他实例正在恢复或库存键已被重建时直接返回；恢复失败时保持冻结，由冻结TTL兜底并在下一轮重试
+func (g *StockGuardian) Recover(ctx context.Context, event *domain.SpikeEvent) error {
+	acquired, err := g.cache.AcquireRewarmLock(ctx, event.ID, g.owner, g.config.LockTTL)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := g.cache.ReleaseRewarmLock(context.Background(), event.ID, g.owner); err != nil {
+			g.logger.Warn("释放库存恢复锁失败", zap.Int64("event_id", event.ID), zap.Error(err))
+		}
+	}()
+
+	// 加锁后再次确认，避免重复恢复
+	info, err := g.cache.GetStockInfo(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	if info.Exists {
+		return nil
+	}
+
+	// 以加锁后的数据为准，活动进行中补货可能已修改总库存
+	if latest, err := g.events.GetByID(ctx, event.ID); err == nil {
+		event = latest
+	} else {
+		g.logger.Warn("重新读取活动失败，使用巡检时的活动数据", zap.Int64("event_id", event.ID), zap.Error(err))
+	}
+
+	startedAt := time.Now()
+	if err := g.cache.FreezeEvent(ctx, event.ID, g.config.FreezeTTL); err != nil {
+		return err
+	}
+	g.logger.Warn("活动库存键丢失，已冻结参与", zap.Int64("event_id", event.ID))
+
+	// 等待在途下单消息落库，使数据库已售数量尽量完整
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(g.config.GracePeriod):
+	}
+
+	remaining, sold, err := remainingStockFromDB(ctx, g.orders, event)
+	if err != nil {
+		return err
+	}
+
+	ttl := event.StockKeyTTL(time.Now(), g.config.StockCacheTTL, g.config.StockTTLBuffer)
+	if err := warmupEventStock(ctx, g.cache, event, remaining, ttl); err != nil {
+		return err
+	}
+	if err := g.cache.UnfreezeEvent(ctx, event.ID); err != nil {
+		return err
+	}
+
+	g.logger.Warn("活动库存已从数据库恢复，恢复参与",
+		zap.Int64("event_id", event.ID),
+		zap.Int64("spike_stock", event.SpikeStock),
+		zap.Int64("sold", sold),
+		zap.Int64("remaining_stock", remaining),
+		zap.Duration("frozen_for", time.Since(startedAt)))
+	return nil
+}
+
+// remainingStockFromDB 以数据库为准计算活动剩余库存：总库存减去已售数量，
+// 已售数量取活动记录与待支付、已支付订单购买数量之和中的较大者，使在途待支付订单也占用库存
+func remainingStockFromDB(ctx context.Context, orders SoldQuantitySource, event *domain.SpikeEvent) (remaining, sold int64, err error) {
+	sold, err = orders.SumQuantityByEvent(ctx, event.ID, domain.SpikeOrderStatusPending, domain.SpikeOrderStatusPaid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get sold quantity: %w", err)
+	}
+	if event.SoldCount > sold {
+		sold = event.SoldCount
+	}
+	return max(event.SpikeStock-sold, 0), sold, nil
+}
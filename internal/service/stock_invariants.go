@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// 库存不变量名称，同时作为违反计数的指标标签
+const (
+	// InvariantRedisStockNonNegative Redis 剩余库存在脚本执行后不为负
+	InvariantRedisStockNonNegative = "redis_stock_non_negative"
+	// InvariantSoldWithinStock 数据库已售数量不超过活动库存
+	InvariantSoldWithinStock = "sold_within_stock"
+	// InvariantReservedNonNegative 商品预留库存不为负
+	InvariantReservedNonNegative = "reserved_stock_non_negative"
+)
+
+// InvariantViolation 一次不变量违反
+type InvariantViolation struct {
+	Invariant  string    `json:"invariant"`
+	EventID    int64     `json:"spike_event_id"`
+	ProductID  int64     `json:"product_id,omitempty"`
+	Value      int64     `json:"value"` // 实际值
+	Limit      int64     `json:"limit"` // 允许的边界（下限或上限）
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// InvariantCache 不变量检查所需的缓存操作（由 cache.SpikeCache 实现）
+type InvariantCache interface {
+	GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+	FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error
+}
+
+// InventorySource 提供商品库存（由 repo.InventoryRepository 实现）
+type InventorySource interface {
+	GetByProductID(ctx context.Context, productID int64) (*domain.Inventory, error)
+}
+
+// StockInvariantConfig 库存不变量检查配置
+type StockInvariantConfig struct {
+	Interval          time.Duration // 对账任务的检查间隔
+	FreezeOnViolation bool          // 违反时冻结活动
+	FreezeTTL         time.Duration // 冻结标记的过期时间
+}
+
+// DefaultStockInvariantConfig 默认库存不变量检查配置：只告警不冻结
+func DefaultStockInvariantConfig() *StockInvariantConfig {
+	return &StockInvariantConfig{
+		Interval:  time.Minute,
+		FreezeTTL: 10 * time.Minute,
+	}
+}
+
+// StockInvariantChecker 检查秒杀库存不变量。
+// 对账任务定期检查所有进行中的活动，库存变更路径（预减库存、订单落库、库存恢复）在变更后即时检查；
+// 发现违反时累加违反计数、输出告警日志，并按配置冻结活动。
+type StockInvariantChecker struct {
+	events    GuardedEventSource
+	cache     InvariantCache
+	inventory InventorySource // 可为空，为空时不检查预留库存
+	config    *StockInvariantConfig
+	logger    *zap.Logger
+
+	mu         sync.Mutex
+	violations map[string]int64
+}
+
+// NewStockInvariantChecker 创建库存不变量检查器
+func NewStockInvariantChecker(events GuardedEventSource, stockCache InvariantCache, inventory InventorySource, config *StockInvariantConfig, logger *zap.Logger) *StockInvariantChecker {
+	if config == nil {
+		config = DefaultStockInvariantConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &StockInvariantChecker{
+		events:     events,
+		cache:      stockCache,
+		inventory:  inventory,
+		config:     config,
+		logger:     logger,
+		violations: make(map[string]int64),
+	}
+}
+
+// Start 异步启动对账任务，ctx 取消时退出
+func (c *StockInvariantChecker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+
+		c.logger.Info("库存不变量对账已启动", zap.Duration("interval", c.config.Interval))
+		for {
+			select {
+			case <-ctx.Done():
+				c.logger.Info("库存不变量对账已停止")
+				return
+			case <-ticker.C:
+				c.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 检查所有进行中的活动，返回发现的违反
+func (c *StockInvariantChecker) RunOnce(ctx context.Context) []*InvariantViolation {
+	events, err := c.events.GetActiveEvents(ctx)
+	if err != nil {
+		c.logger.Warn("获取进行中活动失败", zap.Error(err))
+		return nil
+	}
+
+	var found []*InvariantViolation
+	for _, event := range events {
+		found = append(found, c.CheckEvent(ctx, event)...)
+	}
+	return found
+}
+
+// CheckEvent 检查单个活动的全部不变量
+func (c *StockInvariantChecker) CheckEvent(ctx context.Context, event *domain.SpikeEvent) []*InvariantViolation {
+	var found []*InvariantViolation
+
+	if info, err := c.cache.GetStockInfo(ctx, event.ID); err != nil {
+		c.logger.Warn("检查库存不变量时读取 Redis 库存失败", zap.Int64("event_id", event.ID), zap.Error(err))
+	} else if info.Exists && info.Stock < 0 {
+		found = append(found, c.newViolation(InvariantRedisStockNonNegative, event, info.Stock, 0))
+	}
+
+	if event.SoldCount > event.SpikeStock {
+		found = append(found, c.newViolation(InvariantSoldWithinStock, event, event.SoldCount, event.SpikeStock))
+	}
+
+	if c.inventory != nil {
+		inv, err := c.inventory.GetByProductID(ctx, event.ProductID)
+		if err != nil {
+			c.logger.Debug("检查库存不变量时读取商品库存失败", zap.Int64("product_id", event.ProductID), zap.Error(err))
+		} else if inv.ReservedStock < 0 {
+			found = append(found, c.newViolation(InvariantReservedNonNegative, event, int64(inv.ReservedStock), 0))
+		}
+	}
+
+	c.report(ctx, found)
+	return found
+}
+
+// CheckEventID 按活动ID重新读取活动后检查，供库存变更路径（如消息消费者）在变更后调用
+func (c *StockInvariantChecker) CheckEventID(ctx context.Context, eventID int64) {
+	event, err := c.events.GetByID(ctx, eventID)
+	if err != nil {
+		c.logger.Warn("检查库存不变量时读取活动失败", zap.Int64("event_id", eventID), zap.Error(err))
+		return
+	}
+	c.CheckEvent(ctx, event)
+}
+
+// CheckRemainingStock 检查脚本执行后返回的 Redis 剩余库存，不访问存储，可在请求路径上调用
+func (c *StockInvariantChecker) CheckRemainingStock(ctx context.Context, eventID, remaining int64) {
+	if remaining >= 0 {
+		return
+	}
+	c.report(ctx, []*InvariantViolation{
+		c.newViolation(InvariantRedisStockNonNegative, &domain.SpikeEvent{ID: eventID}, remaining, 0),
+	})
+}
+
+// Violations 返回各不变量自启动以来的违反次数
+func (c *StockInvariantChecker) Violations() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]int64, len(c.violations))
+	for name, count := range c.violations {
+		counts[name] = count
+	}
+	return counts
+}
+
+func (c *StockInvariantChecker) newViolation(invariant string, event *domain.SpikeEvent, value, limit int64) *InvariantViolation {
+	return &InvariantViolation{
+		Invariant:  invariant,
+		EventID:    event.ID,
+		ProductID:  event.ProductID,
+		Value:      value,
+		Limit:      limit,
+		DetectedAt: time.Now(),
+	}
+}
+
+// report 累加违反计数、输出告警日志，并按配置冻结活动
+func (c *StockInvariantChecker) report(ctx context.Context, found []*InvariantViolation) {
+	frozen := make(map[int64]bool)
+	for _, v := range found {
+		c.mu.Lock()
+		c.violations[v.Invariant]++
+		c.mu.Unlock()
+		c.logger.Error("库存不变量被破坏",
+			zap.Bool("alert", true),
+			zap.String("invariant", v.Invariant),
+			zap.Int64("event_id", v.EventID),
+			zap.Int64("product_id", v.ProductID),
+			zap.Int64("value", v.Value),
+			zap.Int64("limit", v.Limit))
+
+		if !c.config.FreezeOnViolation || frozen[v.EventID] {
+			continue
+		}
+		frozen[v.EventID] = true
+		if err := c.cache.FreezeEvent(ctx, v.EventID, c.config.FreezeTTL); err != nil {
+			c.logger.Error("冻结违反库存不变量的活动失败", zap.Int64("event_id", v.EventID), zap.Error(err))
+			continue
+		}
+		c.logger.Warn("已冻结违反库存不变量的活动", zap.Int64("event_id", v.EventID), zap.Duration("ttl", c.config.FreezeTTL))
+	}
+}
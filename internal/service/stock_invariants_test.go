@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// fakeInvariantCache 在内存中记录库存键与冻结的活动
+type fakeInvariantCache struct {
+	mu     sync.Mutex
+	stock  map[int64]int64
+	frozen map[int64]bool
+}
+
+func (f *fakeInvariantCache) GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stock, ok := f.stock[eventID]
+	if !ok {
+		return &cache.StockInfo{Stock: -1}, nil
+	}
+	return &cache.StockInfo{Stock: stock, Exists: true, SoldOut: stock <= 0}, nil
+}
+
+func (f *fakeInvariantCache) FreezeEvent(ctx context.Context, eventID int64, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frozen[eventID] = true
+	return nil
+}
+
+// fakeInventorySource 按商品ID返回预置的库存
+type fakeInventorySource map[int64]*domain.Inventory
+
+func (f fakeInventorySource) GetByProductID(ctx context.Context, productID int64) (*domain.Inventory, error) {
+	inv, ok := f[productID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return inv, nil
+}
+
+func TestStockInvariantChecker_RunOnce(t *testing.T) {
+	events := NewMockSpikeEventRepository()
+	healthy := testutil.NewSpikeEventBuilder().Active().ForProduct(1).WithStock(100).WithSold(40).Build()
+	oversold := testutil.NewSpikeEventBuilder().Active().ForProduct(2).WithStock(100).WithSold(103).Build()
+	negative := testutil.NewSpikeEventBuilder().Active().ForProduct(3).WithStock(100).WithSold(100).Build()
+	testutil.SeedSpikeEvents(t, events, healthy, oversold, negative)
+
+	stockCache := &fakeInvariantCache{
+		stock:  map[int64]int64{healthy.ID: 60, oversold.ID: 0, negative.ID: -2},
+		frozen: make(map[int64]bool),
+	}
+	inventory := fakeInventorySource{
+		1: testutil.NewInventoryBuilder().ForProduct(1).Build(),
+		3: testutil.NewInventoryBuilder().ForProduct(3).WithReserved(-1).Build(),
+	}
+	checker := NewStockInvariantChecker(events, stockCache, inventory, &StockInvariantConfig{
+		Interval:          time.Minute,
+		FreezeOnViolation: true,
+		FreezeTTL:         time.Minute,
+	}, nil)
+
+	found := checker.RunOnce(context.Background())
+	if len(found) != 3 {
+		t.Fatalf("RunOnce() found %d violations, want 3: %+v", len(found), found)
+	}
+
+	want := map[string]int64{
+		InvariantSoldWithinStock:       1,
+		InvariantRedisStockNonNegative: 1,
+		InvariantReservedNonNegative:   1,
+	}
+	got := checker.Violations()
+	for name, count := range want {
+		if got[name] != count {
+			t.Errorf("Violations()[%s] = %d, want %d", name, got[name], count)
+		}
+	}
+
+	if stockCache.frozen[healthy.ID] || !stockCache.frozen[oversold.ID] || !stockCache.frozen[negative.ID] {
+		t.Errorf("frozen = %v, want only events %d and %d", stockCache.frozen, oversold.ID, negative.ID)
+	}
+}
+
+func TestStockInvariantChecker_CheckRemainingStock(t *testing.T) {
+	stockCache := &fakeInvariantCache{stock: map[int64]int64{}, frozen: make(map[int64]bool)}
+	checker := NewStockInvariantChecker(NewMockSpikeEventRepository(), stockCache, nil, nil, nil)
+
+	checker.CheckRemainingStock(context.Background(), 1, 0)
+	if len(checker.Violations()) != 0 {
+		t.Fatalf("zero remaining stock reported as violation: %v", checker.Violations())
+	}
+
+	checker.CheckRemainingStock(context.Background(), 1, -1)
+	if got := checker.Violations()[InvariantRedisStockNonNegative]; got != 1 {
+		t.Errorf("violations = %d, want 1", got)
+	}
+	// 默认配置只告警不冻结
+	if stockCache.frozen[1] {
+		t.Error("event frozen with default config")
+	}
+}
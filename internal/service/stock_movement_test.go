@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// sourceRecordingInventoryRepo 记录库存仓储收到的库存变动来源
+type sourceRecordingInventoryRepo struct {
+	*mockInventoryRepository
+	sources []domain.StockMovementSource
+}
+
+func (r *sourceRecordingInventoryRepo) ReserveStock(ctx context.Context, productID int64, quantity int) error {
+	r.sources = append(r.sources, domain.StockMovementSourceFrom(ctx))
+	return r.mockInventoryRepository.ReserveStock(ctx, productID, quantity)
+}
+
+func (r *sourceRecordingInventoryRepo) ConsumeStock(ctx context.Context, productID int64, quantity int) error {
+	r.sources = append(r.sources, domain.StockMovementSourceFrom(ctx))
+	return r.mockInventoryRepository.ConsumeStock(ctx, productID, quantity)
+}
+
+// fakeStockMovementRepo 记录查询请求并返回固定流水
+type fakeStockMovementRepo struct {
+	req       *domain.StockMovementListRequest
+	movements []*domain.StockMovement
+}
+
+func (f *fakeStockMovementRepo) List(ctx context.Context, req *domain.StockMovementListRequest) ([]*domain.StockMovement, int64, error) {
+	f.req = req
+	return f.movements, int64(len(f.movements)), nil
+}
+
+func TestInventoryService_StockMovementSourceFollowsReservation(t *testing.T) {
+	productRepo := newMockProductRepository()
+	productRepo.products[1] = &domain.Product{ID: 1, Name: "Test Product", Status: domain.ProductStatusActive}
+	inventoryRepo := &sourceRecordingInventoryRepo{mockInventoryRepository: newMockInventoryRepository()}
+	inventory := &domain.Inventory{ID: 1, ProductID: 1, Stock: 100, MaxStock: 1000}
+	inventoryRepo.inventories[1] = inventory
+	inventoryRepo.productMap[1] = inventory
+
+	svc := NewInventoryService(inventoryRepo, productRepo, WithReservations(newFakeReservationRepo(), 15*time.Minute, time.Hour))
+	ctx := domain.WithStockOperator(context.Background(), domain.UserActor(9))
+
+	reservation, err := svc.ReserveStock(ctx, &domain.ReserveStockRequest{ProductID: 1, Quantity: 3})
+	if err != nil {
+		t.Fatalf("ReserveStock() error = %v", err)
+	}
+	if err := svc.ConsumeStock(ctx, &domain.ConsumeStockRequest{ReservationID: reservation.ReservationID}); err != nil {
+		t.Fatalf("ConsumeStock() error = %v", err)
+	}
+
+	if len(inventoryRepo.sources) != 2 {
+		t.Fatalf("sources = %+v, want reserve and consume", inventoryRepo.sources)
+	}
+	for _, source := range inventoryRepo.sources {
+		if source.Operator != "user:9" || source.Reference != reservation.ReservationID || source.Reason == "" {
+			t.Errorf("source = %+v, want operator user:9 and reference %s", source, reservation.ReservationID)
+		}
+	}
+}
+
+func TestInventoryService_ListStockMovements(t *testing.T) {
+	inventoryRepo := newMockInventoryRepository()
+	inventoryRepo.inventories[5] = &domain.Inventory{ID: 5, ProductID: 42}
+	movements := &fakeStockMovementRepo{movements: []*domain.StockMovement{
+		{ID: 2, ProductID: 42, Type: domain.StockMovementConsume, Quantity: 1},
+		{ID: 1, ProductID: 42, Type: domain.StockMovementReserve, Quantity: 1},
+	}}
+	svc := NewInventoryService(inventoryRepo, newMockProductRepository(), WithStockMovements(movements))
+
+	result, err := svc.ListStockMovements(context.Background(), 5, &domain.StockMovementListRequest{PageSize: 500})
+	if err != nil {
+		t.Fatalf("ListStockMovements() error = %v", err)
+	}
+	if movements.req.ProductID != 42 || movements.req.Page != 1 || movements.req.PageSize != 100 {
+		t.Errorf("repo request = %+v, want product 42 with normalized paging", movements.req)
+	}
+	if len(result.Items) != 2 || result.Total != 2 {
+		t.Errorf("result = %+v", result)
+	}
+
+	if _, err := svc.ListStockMovements(context.Background(), 6, &domain.StockMovementListRequest{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("unknown inventory: err = %v, want not found", err)
+	}
+}
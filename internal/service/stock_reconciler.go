@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/metrics"
+)
+
+// 库存偏差类型，同时作为指标标签
+const (
+	// DiscrepancySoldCount 活动记录的已售数量与待支付、已支付订单购买数量之和不一致
+	DiscrepancySoldCount = "sold_count"
+	// DiscrepancyRedisStock Redis 剩余库存与按数据库计算的剩余库存不一致
+	DiscrepancyRedisStock = "redis_stock"
+)
+
+// ErrStockReconcileBusy 活动库存正在被其他实例恢复或对账
+var ErrStockReconcileBusy = errors.New("stock reconciliation already in progress for event")
+
+// ReconcileEventStore 对账所需的活动操作（由 repo.SpikeEventRepository 实现）
+type ReconcileEventStore interface {
+	GuardedEventSource
+	UpdateSoldCount(ctx context.Context, id int64, count int64) error
+}
+
+// StockReconcilerConfig 库存对账配置
+type StockReconcilerConfig struct {
+	Interval       time.Duration // 定时对账间隔
+	Repair         bool          // 定时对账发现偏差时是否自动修复；管理员触发时按请求参数决定
+	Tolerance      int64         // 允许的偏差绝对值，超过才记为偏差，用于忽略在途消息造成的短暂差异
+	GracePeriod    time.Duration // 修复前冻结活动并等待在途消息落库的时间
+	FreezeTTL      time.Duration // 冻结标记的兜底过期时间
+	LockTTL        time.Duration // 对账锁的过期时间，与库存守护共用同一把锁
+	StockCacheTTL  time.Duration // 修复后库存键的最短过期时间
+	StockTTLBuffer time.Duration // 修复后库存键在活动结束后额外保留的时间
+}
+
+// DefaultStockReconcilerConfig 默认库存对账配置：只记录不修复
+func DefaultStockReconcilerConfig() *StockReconcilerConfig {
+	return &StockReconcilerConfig{
+		Interval:       5 * time.Minute,
+		GracePeriod:    3 * time.Second,
+		FreezeTTL:      time.Minute,
+		LockTTL:        30 * time.Second,
+		StockCacheTTL:  2 * time.Hour,
+		StockTTLBuffer: 30 * time.Minute,
+	}
+}
+
+// StockDiscrepancy 一次库存偏差
+type StockDiscrepancy struct {
+	EventID  int64  `json:"spike_event_id"`
+	Kind     string `json:"kind"`     // sold_count 或 redis_stock
+	Expected int64  `json:"expected"` // 以订单为准的期望值
+	Actual   int64  `json:"actual"`   // 发现偏差时的实际值
+	Repaired bool   `json:"repaired"`
+	// Settled 冻结等待在途消息后偏差自行消失，未做修改
+	Settled bool   `json:"settled,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// StockReconcileReport 一轮对账的结果
+type StockReconcileReport struct {
+	StartedAt     time.Time           `json:"started_at"`
+	FinishedAt    time.Time           `json:"finished_at"`
+	Repair        bool                `json:"repair"`
+	Checked       int                 `json:"checked"` // 检查的活动数
+	Skipped       int                 `json:"skipped"` // 库存未预热或读取失败而跳过的活动数
+	Discrepancies []*StockDiscrepancy `json:"discrepancies"`
+}
+
+// StockReconciler 定期比对 Redis 库存、活动已售数量与订单，记录偏差并按配置修复。
+// 在途下单消息会使 Redis 库存暂时低于按数据库计算的值，修复前先冻结活动等待消息落库后重新比对；
+// 库存键缺失由 StockGuardian 负责恢复，对账跳过
+type StockReconciler struct {
+	events ReconcileEventStore
+	orders SoldQuantitySource
+	cache  StockGuardianCache
+	config *StockReconcilerConfig
+	logger *zap.Logger
+
+	owner string // 对账锁持有者标识，区分多实例
+}
+
+// NewStockReconciler 创建库存对账器
+func NewStockReconciler(events ReconcileEventStore, orders SoldQuantitySource, stockCache StockGuardianCache, config *StockReconcilerConfig, logger *zap.Logger) *StockReconciler {
+	if config == nil {
+		config = DefaultStockReconcilerConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &StockReconciler{
+		events: events,
+		orders: orders,
+		cache:  stockCache,
+		config: config,
+		logger: logger,
+		owner:  uuid.New().String(),
+	}
+}
+
+// Start 异步启动定时对账，ctx 取消时退出
+func (r *StockReconciler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.logger.Info("库存对账已启动",
+			zap.Duration("interval", r.config.Interval),
+			zap.Bool("repair", r.config.Repair))
+		for {
+			select {
+			case <-ctx.Done():
+				r.logger.Info("库存对账已停止")
+				return
+			case <-ticker.C:
+				r.RunOnce(ctx, r.config.Repair)
+			}
+		}
+	}()
+}
+
+// RunOnce 对账所有进行中的活动，repair 为 true 时修复发现的偏差
+func (r *StockReconciler) RunOnce(ctx context.Context, repair bool) (*StockReconcileReport, error) {
+	report := &StockReconcileReport{StartedAt: time.Now(), Repair: repair}
+	events, err := r.events.GetActiveEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active events: %w", err)
+	}
+
+	for _, event := range events {
+		if !event.IsActive() {
+			continue
+		}
+		r.reconcile(ctx, event, repair, report)
+	}
+	report.FinishedAt = time.Now()
+	r.logReport(report)
+	return report, nil
+}
+
+// ReconcileEvent 对账单个活动（管理员触发），活动不存在时返回 domain.ErrSpikeEventNotFound
+func (r *StockReconciler) ReconcileEvent(ctx context.Context, eventID int64, repair bool) (*StockReconcileReport, error) {
+	event, err := r.events.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSpikeEventNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get spike event: %w", err)
+	}
+
+	report := &StockReconcileReport{StartedAt: time.Now(), Repair: repair}
+	r.reconcile(ctx, event, repair, report)
+	report.FinishedAt = time.Now()
+	r.logReport(report)
+	return report, nil
+}
+
+// reconcile 比对单个活动并把结果追加到 report
+func (r *StockReconciler) reconcile(ctx context.Context, event *domain.SpikeEvent, repair bool, report *StockReconcileReport) {
+	found, ok := r.detect(ctx, event)
+	if !ok {
+		report.Skipped++
+		return
+	}
+	report.Checked++
+	if len(found) == 0 {
+		return
+	}
+
+	for _, d := range found {
+		metrics.StockReconcileDiscrepancies.Inc(d.Kind)
+		r.logger.Warn("发现库存偏差",
+			zap.Int64("event_id", d.EventID),
+			zap.String("kind", d.Kind),
+			zap.Int64("expected", d.Expected),
+			zap.Int64("actual", d.Actual))
+	}
+
+	if repair {
+		r.repair(ctx, event.ID, found)
+	}
+	report.Discrepancies = append(report.Discrepancies, found...)
+}
+
+// detect 比对活动已售数量、订单与 Redis 库存，返回超过容差的偏差；库存未预热或读取失败时返回 false
+func (r *StockReconciler) detect(ctx context.Context, event *domain.SpikeEvent) ([]*StockDiscrepancy, bool) {
+	info, err := r.cache.GetStockInfo(ctx, event.ID)
+	if err != nil {
+		r.logger.Warn("对账时读取 Redis 库存失败", zap.Int64("event_id", event.ID), zap.Error(err))
+		return nil, false
+	}
+	if !info.Exists {
+		return nil, false
+	}
+
+	orderSold, err := r.orders.SumQuantityByEvent(ctx, event.ID, domain.SpikeOrderStatusPending, domain.SpikeOrderStatusPaid)
+	if err != nil {
+		r.logger.Warn("对账时统计订单购买数量失败", zap.Int64("event_id", event.ID), zap.Error(err))
+		return nil, false
+	}
+
+	var found []*StockDiscrepancy
+	if r.exceeds(event.SoldCount - orderSold) {
+		found = append(found, &StockDiscrepancy{
+			EventID: event.ID, Kind: DiscrepancySoldCount, Expected: orderSold, Actual: event.SoldCount,
+		})
+	}
+	expected := max(event.SpikeStock-max(event.SoldCount, orderSold), 0)
+	if r.exceeds(info.Stock - expected) {
+		found = append(found, &StockDiscrepancy{
+			EventID: event.ID, Kind: DiscrepancyRedisStock, Expected: expected, Actual: info.Stock,
+		})
+	}
+	return found, true
+}
+
+// exceeds 偏差是否超过容差
+func (r *StockReconciler) exceeds(diff int64) bool {
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > r.config.Tolerance
+}
+
+// repair 在对账锁保护下冻结活动、等待在途消息落库，重新比对后修复仍然存在的偏差，再解除冻结。
+// 已售数量以订单为准，Redis 库存按修复后的已售数量重建
+func (r *StockReconciler) repair(ctx context.Context, eventID int64, found []*StockDiscrepancy) {
+	fail := func(err error) {
+		for _, d := range found {
+			d.Error = err.Error()
+			metrics.StockReconcileRepairs.Inc(d.Kind, metrics.ResultError)
+		}
+		r.logger.Error("修复库存偏差失败", zap.Int64("event_id", eventID), zap.Error(err))
+	}
+
+	acquired, err := r.cache.AcquireRewarmLock(ctx, eventID, r.owner, r.config.LockTTL)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if !acquired {
+		fail(ErrStockReconcileBusy)
+		return
+	}
+	defer func() {
+		if err := r.cache.ReleaseRewarmLock(context.Background(), eventID, r.owner); err != nil {
+			r.logger.Warn("释放库存对账锁失败", zap.Int64("event_id", eventID), zap.Error(err))
+		}
+	}()
+
+	if err := r.cache.FreezeEvent(ctx, eventID, r.config.FreezeTTL); err != nil {
+		fail(err)
+		return
+	}
+	// 解冻失败时由冻结TTL兜底
+	defer func() {
+		if err := r.cache.UnfreezeEvent(context.Background(), eventID); err != nil {
+			r.logger.Error("对账后解冻活动失败", zap.Int64("event_id", eventID), zap.Error(err))
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		fail(ctx.Err())
+		return
+	case <-time.After(r.config.GracePeriod):
+	}
+
+	// 冻结期间不会再有预减库存，以重新读取的数据为准
+	event, err := r.events.GetByID(ctx, eventID)
+	if err != nil {
+		fail(fmt.Errorf("failed to get spike event: %w", err))
+		return
+	}
+	latest, ok := r.detect(ctx, event)
+	if !ok {
+		fail(errors.New("stock key missing or unreadable after freeze"))
+		return
+	}
+	remaining := make(map[string]*StockDiscrepancy, len(latest))
+	for _, d := range latest {
+		remaining[d.Kind] = d
+	}
+
+	sold := event.SoldCount
+	if d := remaining[DiscrepancySoldCount]; d != nil {
+		if err := r.events.UpdateSoldCount(ctx, eventID, d.Expected); err != nil {
+			fail(fmt.Errorf("failed to update sold count: %w", err))
+			return
+		}
+		sold = d.Expected
+	}
+	if d := remaining[DiscrepancyRedisStock]; d != nil || remaining[DiscrepancySoldCount] != nil {
+		stock := max(event.SpikeStock-sold, 0)
+		ttl := event.StockKeyTTL(time.Now(), r.config.StockCacheTTL, r.config.StockTTLBuffer)
+		if err := warmupEventStock(ctx, r.cache, event, stock, ttl); err != nil {
+			fail(fmt.Errorf("failed to reset redis stock: %w", err))
+			return
+		}
+	}
+
+	for _, d := range found {
+		if remaining[d.Kind] == nil {
+			d.Settled = true
+			continue
+		}
+		d.Repaired = true
+		metrics.StockReconcileRepairs.Inc(d.Kind, metrics.ResultOK)
+		r.logger.Warn("已修复库存偏差",
+			zap.Int64("event_id", eventID),
+			zap.String("kind", d.Kind),
+			zap.Int64("expected", remaining[d.Kind].Expected),
+			zap.Int64("actual", remaining[d.Kind].Actual))
+	}
+}
+
+// logReport 输出对账汇总
+func (r *StockReconciler) logReport(report *StockReconcileReport) {
+	if len(report.Discrepancies) == 0 {
+		r.logger.Debug("库存对账完成，未发现偏差", zap.Int("checked", report.Checked), zap.Int("skipped", report.Skipped))
+		return
+	}
+	r.logger.Warn("库存对账完成",
+		zap.Bool("alert", true),
+		zap.Int("checked", report.Checked),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("discrepancies", len(report.Discrepancies)),
+		zap.Bool("repair", report.Repair))
+}
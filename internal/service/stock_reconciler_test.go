@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+// seedReconcileFixture 准备一个已售数量与 Redis 库存都有偏差的活动、一个一致的活动和一个库存未预热的活动
+func seedReconcileFixture(t *testing.T) (*MockSpikeEventRepository, *MockSpikeOrderRepository, *fakeGuardianCache, [3]*domain.SpikeEvent) {
+	t.Helper()
+	events := NewMockSpikeEventRepository()
+	orders := NewMockSpikeOrderRepository()
+	drifted := testutil.NewSpikeEventBuilder().Active().WithStock(100).WithSold(40).Build()
+	healthy := testutil.NewSpikeEventBuilder().Active().WithStock(50).WithSold(10).Build()
+	cold := testutil.NewSpikeEventBuilder().Active().WithStock(20).Build()
+	testutil.SeedSpikeEvents(t, events, drifted, healthy, cold)
+
+	// 已取消订单不计入已售
+	testutil.SeedSpikeOrders(t, orders,
+		testutil.NewSpikeOrderBuilder().ForEvent(drifted).ForUser(1).WithQuantity(20).Paid().Build(),
+		testutil.NewSpikeOrderBuilder().ForEvent(drifted).ForUser(2).WithQuantity(10).Pending().Build(),
+		testutil.NewSpikeOrderBuilder().ForEvent(drifted).ForUser(3).WithQuantity(5).Cancelled().Build(),
+		testutil.NewSpikeOrderBuilder().ForEvent(healthy).ForUser(1).WithQuantity(10).Paid().Build(),
+	)
+
+	stockCache := newFakeGuardianCache()
+	stockCache.stock[drifted.ID] = 55
+	stockCache.stock[healthy.ID] = 40
+	return events, orders, stockCache, [3]*domain.SpikeEvent{drifted, healthy, cold}
+}
+
+func TestStockReconciler_RunOnce_DetectOnly(t *testing.T) {
+	events, orders, stockCache, seeded := seedReconcileFixture(t)
+	drifted := seeded[0]
+
+	reconciler := NewStockReconciler(events, orders, stockCache, nil, zap.NewNop())
+	report, err := reconciler.RunOnce(context.Background(), false)
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if report.Checked != 2 || report.Skipped != 1 {
+		t.Errorf("Checked, Skipped = %d, %d, want 2, 1", report.Checked, report.Skipped)
+	}
+
+	want := map[string][2]int64{
+		DiscrepancySoldCount:  {30, 40},
+		DiscrepancyRedisStock: {60, 55},
+	}
+	if len(report.Discrepancies) != len(want) {
+		t.Fatalf("Discrepancies = %+v, want %d", report.Discrepancies, len(want))
+	}
+	for _, d := range report.Discrepancies {
+		if d.EventID != drifted.ID {
+			t.Errorf("discrepancy for event %d, want %d", d.EventID, drifted.ID)
+		}
+		if got := [2]int64{d.Expected, d.Actual}; got != want[d.Kind] {
+			t.Errorf("%s expected/actual = %v, want %v", d.Kind, got, want[d.Kind])
+		}
+		if d.Repaired {
+			t.Errorf("%s repaired without repair flag", d.Kind)
+		}
+	}
+
+	event, _ := events.GetByID(context.Background(), drifted.ID)
+	if event.SoldCount != 40 || stockCache.stock[drifted.ID] != 55 {
+		t.Errorf("sold_count, stock = %d, %d, want untouched 40, 55", event.SoldCount, stockCache.stock[drifted.ID])
+	}
+}
+
+func TestStockReconciler_ReconcileEvent_Repair(t *testing.T) {
+	events, orders, stockCache, seeded := seedReconcileFixture(t)
+	drifted := seeded[0]
+
+	config := DefaultStockReconcilerConfig()
+	config.GracePeriod = 0
+	reconciler := NewStockReconciler(events, orders, stockCache, config, zap.NewNop())
+	report, err := reconciler.ReconcileEvent(context.Background(), drifted.ID, true)
+	if err != nil {
+		t.Fatalf("ReconcileEvent() error = %v", err)
+	}
+	for _, d := range report.Discrepancies {
+		if !d.Repaired || d.Error != "" {
+			t.Errorf("%s repaired = %v, error = %q, want repaired", d.Kind, d.Repaired, d.Error)
+		}
+	}
+
+	event, _ := events.GetByID(context.Background(), drifted.ID)
+	if event.SoldCount != 30 {
+		t.Errorf("sold_count = %d, want 30", event.SoldCount)
+	}
+	if got := stockCache.stock[drifted.ID]; got != 70 {
+		t.Errorf("redis stock = %d, want 70", got)
+	}
+	if stockCache.frozen[drifted.ID] || len(stockCache.locked) != 0 {
+		t.Errorf("frozen = %v, locked = %v, want released", stockCache.frozen, stockCache.locked)
+	}
+}
+
+func TestStockReconciler_RepairSkipsWhenLocked(t *testing.T) {
+	events, orders, stockCache, seeded := seedReconcileFixture(t)
+	drifted := seeded[0]
+	stockCache.locked[drifted.ID] = "other-instance"
+
+	reconciler := NewStockReconciler(events, orders, stockCache, nil, zap.NewNop())
+	report, err := reconciler.ReconcileEvent(context.Background(), drifted.ID, true)
+	if err != nil {
+		t.Fatalf("ReconcileEvent() error = %v", err)
+	}
+	for _, d := range report.Discrepancies {
+		if d.Repaired || d.Error != ErrStockReconcileBusy.Error() {
+			t.Errorf("%s repaired = %v, error = %q, want busy", d.Kind, d.Repaired, d.Error)
+		}
+	}
+	if stockCache.stock[drifted.ID] != 55 || stockCache.frozen[drifted.ID] {
+		t.Errorf("stock = %d, frozen = %v, want untouched", stockCache.stock[drifted.ID], stockCache.frozen[drifted.ID])
+	}
+}
+
+func TestStockReconciler_Tolerance(t *testing.T) {
+	events, orders, stockCache, seeded := seedReconcileFixture(t)
+
+	config := DefaultStockReconcilerConfig()
+	config.Tolerance = 10
+	reconciler := NewStockReconciler(events, orders, stockCache, config, zap.NewNop())
+	report, err := reconciler.ReconcileEvent(context.Background(), seeded[0].ID, false)
+	if err != nil {
+		t.Fatalf("ReconcileEvent() error = %v", err)
+	}
+	if len(report.Discrepancies) != 0 {
+		t.Errorf("Discrepancies = %+v, want none within tolerance", report.Discrepancies)
+	}
+
+	if _, err := reconciler.ReconcileEvent(context.Background(), 999, false); !errors.Is(err, domain.ErrSpikeEventNotFound) {
+		t.Errorf("ReconcileEvent(missing) error = %v, want ErrSpikeEventNotFound", err)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// StockTTLCache 库存键过期时间的读取与续期（由 cache.SpikeCache 实现）
+type StockTTLCache interface {
+	GetStockTTL(ctx context.Context, eventID int64) (time.Duration, bool, error)
+	ExtendStockTTL(ctx context.Context, eventID int64, ttl time.Duration) error
+}
+
+// ActiveEventSource 提供进行中的活动列表（由 repo.SpikeEventRepository 实现）
+type ActiveEventSource interface {
+	GetActiveEvents(ctx context.Context) ([]*domain.SpikeEvent, error)
+}
+
+// StockTTLRefresher 为进行中的活动续期库存键，避免持续时间超过预热 TTL 的活动在进行中丢失库存键。
+// 库存键剩余 TTL 不足以覆盖活动剩余时长时输出告警日志并立即续期；
+// 剩余 TTL 不足以覆盖剩余时长 + buffer/2 时续期到活动结束后 buffer。库存键不存在时交给库存守护恢复。
+type StockTTLRefresher struct {
+	events ActiveEventSource
+	cache  StockTTLCache
+	minTTL time.Duration
+	buffer time.Duration
+	logger *zap.Logger
+
+	alerts   atomic.Int64 // 累计告警次数
+	extended atomic.Int64 // 累计续期次数
+}
+
+// NewStockTTLRefresher 创建库存键续期器，minTTL 与 buffer 应与预热时使用的配置一致
+func NewStockTTLRefresher(events ActiveEventSource, cache StockTTLCache, minTTL, buffer time.Duration, logger *zap.Logger) *StockTTLRefresher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &StockTTLRefresher{
+		events: events,
+		cache:  cache,
+		minTTL: minTTL,
+		buffer: buffer,
+		logger: logger,
+	}
+}
+
+// RefreshOnce 检查并续期所有进行中活动的库存键
+func (r *StockTTLRefresher) RefreshOnce(ctx context.Context) error {
+	events, err := r.events.GetActiveEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active events: %w", err)
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		ttl, exists, err := r.cache.GetStockTTL(ctx, event.ID)
+		if err != nil {
+			r.logger.Warn("获取库存键过期时间失败", zap.Int64("event_id", event.ID), zap.Error(err))
+			continue
+		}
+		// 库存键不存在或未设置过期时间时无需续期
+		if !exists || ttl < 0 {
+			continue
+		}
+
+		remaining := event.EndAt.Sub(now)
+		if ttl < remaining {
+			r.alerts.Add(1)
+			r.logger.Error("库存键过期时间短于活动剩余时长",
+				zap.Bool("alert", true),
+				zap.Int64("event_id", event.ID),
+				zap.Duration("ttl", ttl),
+				zap.Duration("remaining", remaining))
+		}
+		if ttl >= remaining+r.buffer/2 {
+			continue
+		}
+
+		target := event.StockKeyTTL(now, r.minTTL, r.buffer)
+		if err := r.cache.ExtendStockTTL(ctx, event.ID, target); err != nil {
+			r.logger.Error("续期库存键失败", zap.Int64("event_id", event.ID), zap.Error(err))
+			continue
+		}
+		r.extended.Add(1)
+		r.logger.Info("已续期库存键",
+			zap.Int64("event_id", event.ID),
+			zap.Duration("old_ttl", ttl),
+			zap.Duration("ttl", target))
+	}
+	return nil
+}
+
+// Alerts 返回累计告警次数
+func (r *StockTTLRefresher) Alerts() int64 {
+	return r.alerts.Load()
+}
+
+// Extended 返回累计续期次数
+func (r *StockTTLRefresher) Extended() int64 {
+	return r.extended.Load()
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// stubStockTTLCache 记录续期结果的库存键 TTL 桩
+type stubStockTTLCache struct {
+	ttls     map[int64]time.Duration
+	extended map[int64]time.Duration
+}
+
+func (c *stubStockTTLCache) GetStockTTL(ctx context.Context, eventID int64) (time.Duration, bool, error) {
+	ttl, ok := c.ttls[eventID]
+	return ttl, ok, nil
+}
+
+func (c *stubStockTTLCache) ExtendStockTTL(ctx context.Context, eventID int64, ttl time.Duration) error {
+	c.extended[eventID] = ttl
+	return nil
+}
+
+// stubActiveEvents 固定的进行中活动列表
+type stubActiveEvents []*domain.SpikeEvent
+
+func (s stubActiveEvents) GetActiveEvents(ctx context.Context) ([]*domain.SpikeEvent, error) {
+	return s, nil
+}
+
+func TestSpikeEvent_StockKeyTTL(t *testing.T) {
+	now := time.Now()
+	short := &domain.SpikeEvent{EndAt: now.Add(time.Hour)}
+	long := &domain.SpikeEvent{EndAt: now.Add(6 * time.Hour)}
+
+	if got := short.StockKeyTTL(now, 2*time.Hour, 30*time.Minute); got != 2*time.Hour {
+		t.Errorf("short event StockKeyTTL() = %s, want minimum 2h", got)
+	}
+	if got := long.StockKeyTTL(now, 2*time.Hour, 30*time.Minute); got != 6*time.Hour+30*time.Minute {
+		t.Errorf("long event StockKeyTTL() = %s, want 6h30m", got)
+	}
+}
+
+func TestStockTTLRefresher_RefreshOnce(t *testing.T) {
+	now := time.Now()
+	events := stubActiveEvents{
+		{ID: 1, EndAt: now.Add(6 * time.Hour)},  // TTL 短于剩余时长：告警并续期
+		{ID: 2, EndAt: now.Add(time.Hour)},      // TTL 充足：不处理
+		{ID: 3, EndAt: now.Add(3 * time.Hour)},  // TTL 覆盖剩余时长但缓冲不足：静默续期
+		{ID: 4, EndAt: now.Add(6 * time.Hour)},  // 库存键不存在：交给库存守护
+		{ID: 5, EndAt: now.Add(10 * time.Hour)}, // 库存键无过期时间：不处理
+	}
+	stub := &stubStockTTLCache{
+		ttls: map[int64]time.Duration{
+			1: 90 * time.Minute,
+			2: 2 * time.Hour,
+			3: 3*time.Hour + 5*time.Minute,
+			5: -1,
+		},
+		extended: make(map[int64]time.Duration),
+	}
+
+	refresher := NewStockTTLRefresher(events, stub, 2*time.Hour, 30*time.Minute, nil)
+	if err := refresher.RefreshOnce(context.Background()); err != nil {
+		t.Fatalf("RefreshOnce() error = %v", err)
+	}
+
+	if refresher.Alerts() != 1 {
+		t.Errorf("Alerts() = %d, want 1", refresher.Alerts())
+	}
+	if len(stub.extended) != 2 {
+		t.Fatalf("extended events = %v, want events 1 and 3", stub.extended)
+	}
+	for _, id := range []int64{1, 3} {
+		want := events[id-1].EndAt.Sub(now) + 30*time.Minute
+		if got := stub.extended[id]; got < want-time.Second || got > want {
+			t.Errorf("event %d extended to %s, want about %s", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// UpcomingEventSource 提供即将开始的活动（由 repo.SpikeEventRepository 实现）
+type UpcomingEventSource interface {
+	GetUpcomingEvents(ctx context.Context, now, until time.Time) ([]*domain.SpikeEvent, error)
+}
+
+// StockWarmer 按数据库剩余库存预热单个活动（由 SpikeService 实现）
+type StockWarmer interface {
+	WarmupStock(ctx context.Context, eventID int64, force bool) error
+}
+
+// StockWarmupCache 定时预热所需的缓存操作（由 cache.SpikeCache 实现）
+type StockWarmupCache interface {
+	GetStockInfo(ctx context.Context, eventID int64) (*cache.StockInfo, error)
+	AcquireRewarmLock(ctx context.Context, eventID int64, owner string, ttl time.Duration) (bool, error)
+	ReleaseRewarmLock(ctx context.Context, eventID int64, owner string) error
+}
+
+// stockWarmupLockTTL 预热锁的过期时间，持锁实例崩溃时由过期释放
+const stockWarmupLockTTL = 30 * time.Second
+
+// StockWarmupScheduler 在活动开始前 leadTime 内自动预热 Redis 库存。
+// 多实例部署时复用库存恢复锁，同一活动同一时刻只有一个实例写入库存键；
+// 加锁后库存键已存在（其他实例或预告期调度器已预热）时跳过，避免覆盖
+type StockWarmupScheduler struct {
+	events   UpcomingEventSource
+	warmer   StockWarmer
+	cache    StockWarmupCache
+	leadTime time.Duration
+	interval time.Duration
+	owner    string // 预热锁持有者标识，区分多实例
+	logger   *zap.Logger
+
+	// warmed 记录已预热的活动及其开始时间，活动改期后会重新预热
+	mu     sync.Mutex
+	warmed map[int64]time.Time
+}
+
+// NewStockWarmupScheduler 创建库存定时预热调度器，leadTime 为活动开始前提前预热的时间
+func NewStockWarmupScheduler(events UpcomingEventSource, warmer StockWarmer, stockCache StockWarmupCache,
+	leadTime, interval time.Duration, logger *zap.Logger) *StockWarmupScheduler {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &StockWarmupScheduler{
+		events:   events,
+		warmer:   warmer,
+		cache:    stockCache,
+		leadTime: leadTime,
+		interval: interval,
+		owner:    uuid.New().String(),
+		logger:   logger,
+		warmed:   make(map[int64]time.Time),
+	}
+}
+
+// Start 异步启动调度循环，ctx 取消时退出
+func (s *StockWarmupScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.logger.Info("库存定时预热已启动",
+			zap.Duration("lead_time", s.leadTime),
+			zap.Duration("interval", s.interval))
+		for {
+			if err := s.RunOnce(ctx); err != nil {
+				s.logger.Warn("扫描即将开始的活动失败", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				s.logger.Info("库存定时预热已停止")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce 执行一次扫描：预热 leadTime 内即将开始的活动库存，并清理已开始活动的预热记录
+func (s *StockWarmupScheduler) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	events, err := s.events.GetUpcomingEvents(ctx, now, now.Add(s.leadTime))
+	if err != nil {
+		return fmt.Errorf("failed to get upcoming events: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upcoming := make(map[int64]struct{}, len(events))
+	for _, event := range events {
+		upcoming[event.ID] = struct{}{}
+		if startAt, ok := s.warmed[event.ID]; ok && startAt.Equal(event.StartAt) {
+			continue
+		}
+
+		done, err := s.warmup(ctx, event)
+		if err != nil {
+			// 不记录预热状态，下一轮继续重试
+			s.logger.Warn("活动库存定时预热失败", zap.Int64("event_id", event.ID), zap.Error(err))
+			continue
+		}
+		if done {
+			s.warmed[event.ID] = event.StartAt
+		}
+	}
+
+	// 已开始、取消或改期到窗口外的活动不再需要记录
+	for id := range s.warmed {
+		if _, ok := upcoming[id]; !ok {
+			delete(s.warmed, id)
+		}
+	}
+
+	return nil
+}
+
+// warmup 在预热锁保护下预热单个活动，返回库存键是否已就绪；
+// 其他实例持锁时返回 false，下一轮再确认
+func (s *StockWarmupScheduler) warmup(ctx context.Context, event *domain.SpikeEvent) (bool, error) {
+	acquired, err := s.cache.AcquireRewarmLock(ctx, event.ID, s.owner, stockWarmupLockTTL)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		if err := s.cache.ReleaseRewarmLock(context.Background(), event.ID, s.owner); err != nil {
+			s.logger.Warn("释放库存预热锁失败", zap.Int64("event_id", event.ID), zap.Error(err))
+		}
+	}()
+
+	// 加锁后再确认，库存键已存在说明已被预热，不能覆盖
+	info, err := s.cache.GetStockInfo(ctx, event.ID)
+	if err != nil {
+		return false, err
+	}
+	if info.Exists {
+		return true, nil
+	}
+
+	if err := s.warmer.WarmupStock(ctx, event.ID, false); err != nil {
+		return false, err
+	}
+	s.logger.Info("活动库存定时预热完成",
+		zap.Int64("event_id", event.ID),
+		zap.Time("start_at", event.StartAt))
+	return true, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TOTP 参数与主流验证器（Google Authenticator 等）的默认值一致：HMAC-SHA1、30 秒时间步、6 位验证码
+const (
+	totpPeriod     = 30 * time.Second
+	totpDigits     = 6
+	totpSecretSize = 20 // 密钥字节数，RFC 4226 建议不少于 160 位
+	totpSkewSteps  = 1  // 允许前后各一个时间步的时钟偏差
+)
+
+// totpEncoding 无填充的 Base32，验证器手动输入密钥时不接受 '='
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateTOTPSecret 生成随机的 Base32 共享密钥
+func generateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// totpStep 返回时刻所在的时间步
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod/time.Second)
+}
+
+// totpCode 按 RFC 6238 计算时间步对应的验证码
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode totp secret: %w", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// 动态截断：取摘要末字节低 4 位作为偏移，读出 31 位整数
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod), nil
+}
+
+// verifyTOTP 校验验证码，返回匹配的时间步；只接受晚于 lastStep 的时间步，已使用过的验证码不能重放
+func verifyTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := totpStep(now)
+	for step := current - totpSkewSteps; step <= current+totpSkewSteps; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := totpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpURL 构造验证器识别的 otpauth:// 地址
+func totpURL(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(int(totpPeriod/time.Second)))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// totpSealedPrefix 加密保存的密钥前缀，不带前缀的为加密启用前写入的明文密钥
+const totpSealedPrefix = "v1:"
+
+// TOTPSecretCipher 以 AES-256-GCM 加密保存的 TOTP 共享密钥，数据库泄露时密钥不能直接用于生成验证码。
+// 用户ID作为附加数据参与认证，密文不能挪用到其他用户
+type TOTPSecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewTOTPSecretCipher 使用 32 字节密钥创建 TOTP 密钥加密器
+func NewTOTPSecretCipher(key []byte) (*TOTPSecretCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("totp encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create totp cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create totp cipher: %w", err)
+	}
+	return &TOTPSecretCipher{aead: aead}, nil
+}
+
+// seal 加密密钥，未配置加密器时原样返回
+func (c *TOTPSecretCipher) seal(userID int64, secret string) (string, error) {
+	if c == nil {
+		return secret, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate totp nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(secret), totpAdditionalData(userID))
+	return totpSealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// open 解密密钥，明文保存的旧密钥原样返回
+func (c *TOTPSecretCipher) open(userID int64, stored string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, totpSealedPrefix)
+	if !ok {
+		return stored, nil
+	}
+	if c == nil {
+		return "", errors.New("totp secret is encrypted but no encryption key is configured")
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted totp secret")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	secret, err := c.aead.Open(nil, nonce, ciphertext, totpAdditionalData(userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// totpAdditionalData 加密时绑定的用户ID
+func totpAdditionalData(userID int64) []byte {
+	return []byte(strconv.FormatInt(userID, 10))
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 测试向量，密钥为 ASCII "12345678901234567890"，取 8 位结果的后 6 位
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := totpCode(secret, totpStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("totpCode(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generateTOTPSecret() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	step := totpStep(now)
+	previous, _ := totpCode(secret, step-1)
+	tooOld, _ := totpCode(secret, step-2)
+
+	if got, ok := verifyTOTP(secret, previous, now, 0); !ok || got != step-1 {
+		t.Errorf("verifyTOTP(previous step) = %d, %v, want %d, true", got, ok, step-1)
+	}
+	if _, ok := verifyTOTP(secret, tooOld, now, 0); ok {
+		t.Error("verifyTOTP accepted a code outside the skew window")
+	}
+	if _, ok := verifyTOTP(secret, previous, now, step-1); ok {
+		t.Error("verifyTOTP accepted a replayed code")
+	}
+	if _, ok := verifyTOTP(secret, "12345", now, 0); ok {
+		t.Error("verifyTOTP accepted a short code")
+	}
+
+	url := totpURL("spike-server", "alice", secret)
+	if !strings.HasPrefix(url, "otpauth://totp/spike-server:alice?") || !strings.Contains(url, "secret="+secret) {
+		t.Errorf("totpURL() = %s", url)
+	}
+}
+
+func TestTOTPSecretCipher(t *testing.T) {
+	c, err := NewTOTPSecretCipher([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewTOTPSecretCipher() error = %v", err)
+	}
+	secret, _ := generateTOTPSecret()
+
+	sealed, err := c.seal(1, secret)
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	if !strings.HasPrefix(sealed, totpSealedPrefix) || strings.Contains(sealed, secret) || len(sealed) > 128 {
+		t.Fatalf("sealed = %q, want prefixed ciphertext within column width", sealed)
+	}
+	if opened, err := c.open(1, sealed); err != nil || opened != secret {
+		t.Errorf("open() = %q, %v, want %q", opened, err, secret)
+	}
+	// 密文绑定用户，挪用到其他用户时解密失败
+	if _, err := c.open(2, sealed); err == nil {
+		t.Error("open() with another user succeeded")
+	}
+	// 加密启用前写入的明文密钥原样读取
+	if opened, err := c.open(1, secret); err != nil || opened != secret {
+		t.Errorf("open(plaintext) = %q, %v", opened, err)
+	}
+	// 未配置密钥时不能读取密文
+	var none *TOTPSecretCipher
+	if _, err := none.open(1, sealed); err == nil {
+		t.Error("open() without key succeeded")
+	}
+	if _, err := NewTOTPSecretCipher([]byte("short")); err == nil {
+		t.Error("NewTOTPSecretCipher(short key) succeeded")
+	}
+}
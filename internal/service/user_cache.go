@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/repo"
+)
+
+// UserCache 为高频接口提供按用户ID的短TTL用户缓存，减少每个请求回源数据库。
+// 缓存内容经 JSON 序列化，不包含密码哈希，因此只能用于展示和所有权校验，
+// 不能用于登录校验或回写数据库；角色、状态、等级变更时由 UserService 主动失效。
+type UserCache struct {
+	userRepo repo.UserRepository
+	cache    cache.Cache
+	ttl      time.Duration
+}
+
+// NewUserCache 创建用户缓存，cache 为空或 ttl 不大于0时直接读取数据库
+func NewUserCache(userRepo repo.UserRepository, c cache.Cache, ttl time.Duration) *UserCache {
+	uc := &UserCache{userRepo: userRepo}
+	if c != nil && ttl > 0 {
+		uc.cache = c
+		uc.ttl = ttl
+	}
+	return uc
+}
+
+// userCacheKey 用户缓存键
+func userCacheKey(userID int64) string {
+	return fmt.Sprintf("user:profile:%d", userID)
+}
+
+// GetUser 获取用户，优先读取缓存，用户不存在时返回 nil
+func (c *UserCache) GetUser(ctx context.Context, userID int64) (*domain.User, error) {
+	if c.cache != nil {
+		var user domain.User
+		if err := c.cache.Get(ctx, userCacheKey(userID), &user); err == nil {
+			return &user, nil
+		}
+	}
+
+	user, err := c.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	// 不缓存不存在的用户，避免新注册用户在 TTL 内查不到
+	if user != nil && c.cache != nil {
+		_ = c.cache.Set(ctx, userCacheKey(userID), user, c.ttl)
+	}
+	return user, nil
+}
+
+// Invalidate 失效用户缓存，失败时最多滞后一个 TTL
+func (c *UserCache) Invalidate(ctx context.Context, userID int64) {
+	if c.cache == nil {
+		return
+	}
+	_ = c.cache.Del(ctx, userCacheKey(userID))
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/cache"
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/testutil"
+)
+
+func TestUserCache_InvalidatedOnRoleChange(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newFakeUserRepo()
+	testutil.SeedUsers(t, userRepo, testutil.NewUserBuilder().WithUsername("alice").Build())
+
+	userCache := NewUserCache(userRepo, cache.NewMemoryCache(), time.Minute)
+	userService := NewUserService(userRepo, zap.NewNop(), WithUserCacheInvalidation(userCache))
+
+	user, err := userCache.GetUser(ctx, 1)
+	if err != nil || user == nil {
+		t.Fatalf("GetUser() = %v, %v", user, err)
+	}
+
+	// 绕过服务直接改库时，缓存在 TTL 内保持旧值
+	userRepo.users["alice"].IsActive = false
+	user, _ = userCache.GetUser(ctx, 1)
+	if !user.IsActive {
+		t.Fatal("GetUser() should be served from cache")
+	}
+	if user.PasswordHash != "" {
+		t.Error("cached user should not contain password hash")
+	}
+
+	// 经用户服务变更角色后缓存失效
+	if err := userService.UpdateUserRole(1, domain.UserRoleAdmin); err != nil {
+		t.Fatalf("UpdateUserRole() error = %v", err)
+	}
+	user, _ = userCache.GetUser(ctx, 1)
+	if user.Role != domain.UserRoleAdmin || user.IsActive {
+		t.Errorf("GetUser() after role change = role %s active %v, want admin inactive", user.Role, user.IsActive)
+	}
+}
@@ -3,49 +3,101 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
 	"github.com/MorseWayne/spike_shop/internal/repo"
 )
 
 // 定义业务错误
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserExists         = errors.New("user already exists")
+	ErrUserNotFound       = domain.NewNotFoundError("user not found")
+	ErrUserExists         = domain.NewConflictError("user already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserInactive       = errors.New("user is inactive")
+	ErrOTPRequired        = errors.New("otp code required")
+	ErrInvalidOTP         = errors.New("invalid otp code")
 )
 
 // UserService 定义用户服务接口
 type UserService interface {
 	Register(req *domain.RegisterRequest) (*domain.User, error)
-	Login(req *domain.LoginRequest) (*domain.User, error)
+	// Login 校验登录名与密码，已启用两步验证时同时校验验证码；clientIP 用于失败锁定与可疑登录审计
+	Login(ctx context.Context, req *domain.LoginRequest, clientIP string) (*domain.User, error)
 	GetUserByID(id int64) (*domain.User, error)
 	GetUserByUsername(username string) (*domain.User, error)
 	// 管理员专用方法
 	ListUsers(page, pageSize int) (*domain.UserListResponse, error)
 	UpdateUserRole(userID int64, role domain.UserRole) error
 	UpdateUserStatus(userID int64, isActive bool) error
+	UpdateUserTier(userID int64, tier domain.UserTier) error
+	// 两步验证：申请绑定返回密钥，提交验证码确认后启用；关闭同样需要当前验证码
+	EnrollTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollment, error)
+	EnableTOTP(ctx context.Context, userID int64, code string) error
+	DisableTOTP(ctx context.Context, userID int64, code string) error
 }
 
 // userService 是 UserService 接口的实现
 type userService struct {
-	userRepo repo.UserRepository
-	logger   *zap.Logger
+	userRepo   repo.UserRepository
+	userCache  *UserCache              // 可选，角色、状态、等级变更时失效
+	loginGuard *LoginGuard             // 可选，登录失败计数与锁定
+	totpRepo   repo.UserTOTPRepository // 可选，为空时不支持两步验证
+	totpIssuer string                  // 验证器中显示的服务名
+	totpCipher *TOTPSecretCipher       // 可为空，为空时明文保存两步验证密钥
+	logger     *zap.Logger
+}
+
+// UserServiceOption 用户服务可选配置
+type UserServiceOption func(*userService)
+
+// WithUserCacheInvalidation 角色、状态、等级变更后失效对应用户的缓存
+func WithUserCacheInvalidation(userCache *UserCache) UserServiceOption {
+	return func(s *userService) {
+		s.userCache = userCache
+	}
+}
+
+// WithLoginGuard 启用登录失败锁定与可疑登录审计
+func WithLoginGuard(guard *LoginGuard) UserServiceOption {
+	return func(s *userService) {
+		s.loginGuard = guard
+	}
+}
+
+// WithTOTP 启用 TOTP 两步验证，issuer 为验证器中显示的服务名
+func WithTOTP(totpRepo repo.UserTOTPRepository, issuer string) UserServiceOption {
+	return func(s *userService) {
+		s.totpRepo = totpRepo
+		s.totpIssuer = issuer
+	}
+}
+
+// WithTOTPSecretCipher 加密保存两步验证密钥，加密前写入的明文密钥仍可读取
+func WithTOTPSecretCipher(c *TOTPSecretCipher) UserServiceOption {
+	return func(s *userService) {
+		s.totpCipher = c
+	}
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(userRepo repo.UserRepository, logger *zap.Logger) UserService {
-	return &userService{
+func NewUserService(userRepo repo.UserRepository, logger *zap.Logger, opts ...UserServiceOption) UserService {
+	s := &userService{
 		userRepo: userRepo,
 		logger:   logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Register 用户注册
@@ -101,7 +153,7 @@ func (s *userService) Register(req *domain.RegisterRequest) (*domain.User, error
 	}
 
 	s.logger.Info("user registered successfully",
-		zap.Int64("user_id", user.ID),
+		logger.UserID(user.ID),
 		zap.String("username", user.Username),
 	)
 
@@ -113,7 +165,9 @@ func (s *userService) Register(req *domain.RegisterRequest) (*domain.User, error
 // 1. 支持用户名或邮箱登录
 // 2. 验证密码正确性
 // 3. 检查用户是否处于活跃状态
-func (s *userService) Login(req *domain.LoginRequest) (*domain.User, error) {
+// 4. 账号或来源 IP 失败次数过多时临时锁定，锁定期间不校验密码
+// 5. 已启用两步验证的账号需同时提交有效的验证码
+func (s *userService) Login(ctx context.Context, req *domain.LoginRequest, clientIP string) (*domain.User, error) {
 	// 尝试通过用户名查找用户
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
@@ -130,9 +184,18 @@ func (s *userService) Login(req *domain.LoginRequest) (*domain.User, error) {
 		}
 	}
 
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	account := loginAccount(req.Username, userID)
+	if err := s.checkLoginLock(ctx, account, req.Username, userID, clientIP); err != nil {
+		return nil, err
+	}
+
 	// 用户不存在
 	if user == nil {
-		return nil, ErrUserNotFound
+		return nil, s.recordLoginFailure(ctx, account, req.Username, 0, clientIP, ErrUserNotFound)
 	}
 
 	// 检查用户是否活跃
@@ -146,20 +209,102 @@ func (s *userService) Login(req *domain.LoginRequest) (*domain.User, error) {
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
 		if err == bcrypt.ErrMismatchedHashAndPassword {
-			return nil, ErrInvalidCredentials
+			return nil, s.recordLoginFailure(ctx, account, req.Username, user.ID, clientIP, ErrInvalidCredentials)
 		}
 		s.logger.Error("failed to compare password", zap.Error(err))
 		return nil, fmt.Errorf("compare password: %w", err)
 	}
 
+	// 密码正确后再校验验证码，验证码错误同样计入失败次数
+	if err := s.verifyLoginOTP(ctx, user, req.OTPCode, clientIP); err != nil {
+		return nil, err
+	}
+	s.recordLoginSuccess(ctx, account, user, clientIP)
+
 	s.logger.Info("user logged in successfully",
-		zap.Int64("user_id", user.ID),
+		logger.UserID(user.ID),
 		zap.String("username", user.Username),
 	)
 
 	return user, nil
 }
 
+// checkLoginLock 账号或来源 IP 处于锁定期时返回 LoginLockedError；
+// 锁定状态读取失败时放行，Redis 故障不应阻断所有登录
+func (s *userService) checkLoginLock(ctx context.Context, account, username string, userID int64, clientIP string) error {
+	if s.loginGuard == nil {
+		return nil
+	}
+	remaining, err := s.loginGuard.Check(ctx, account, clientIP)
+	if err != nil {
+		s.logger.Warn("failed to check login lock", zap.Error(err))
+		return nil
+	}
+	if remaining <= 0 {
+		return nil
+	}
+	s.auditLogin(loginAuditBlocked, username, userID, clientIP, zap.Duration("retry_after", remaining))
+	return &LoginLockedError{RetryAfter: remaining}
+}
+
+// recordLoginFailure 记录失败登录，本次失败触发锁定时返回 LoginLockedError，否则返回 cause
+func (s *userService) recordLoginFailure(ctx context.Context, account, username string, userID int64, clientIP string, cause error) error {
+	if s.loginGuard == nil {
+		return cause
+	}
+	failure, err := s.loginGuard.RecordFailure(ctx, account, clientIP)
+	if err != nil {
+		s.logger.Warn("failed to record login failure", zap.Error(err))
+		return cause
+	}
+
+	var lock time.Duration
+	if failure.AccountLock > 0 {
+		s.auditLogin(loginAuditAccountLocked, username, userID, clientIP,
+			zap.Int64("failures", failure.Failures), zap.Duration("lock", failure.AccountLock))
+		lock = failure.AccountLock
+	}
+	if failure.IPLock > 0 {
+		s.auditLogin(loginAuditIPLocked, username, userID, clientIP,
+			zap.Int64("failures", failure.IPFailures), zap.Duration("lock", failure.IPLock))
+		lock = max(lock, failure.IPLock)
+	}
+	if lock > 0 {
+		return &LoginLockedError{RetryAfter: lock}
+	}
+	return cause
+}
+
+// recordLoginSuccess 清零失败计数，成功前有失败记录或来自陌生 IP 时记录审计日志
+func (s *userService) recordLoginSuccess(ctx context.Context, account string, user *domain.User, clientIP string) {
+	if s.loginGuard == nil {
+		return
+	}
+	success, err := s.loginGuard.RecordSuccess(ctx, account, user.ID, clientIP)
+	if err != nil {
+		s.logger.Warn("failed to record login success", logger.UserID(user.ID), zap.Error(err))
+		return
+	}
+	if success.PriorFailures > 0 {
+		s.auditLogin(loginAuditSuccessAfterFailures, user.Username, user.ID, clientIP,
+			zap.Int64("failures", success.PriorFailures))
+	}
+	if success.NewIP {
+		s.auditLogin(loginAuditNewIP, user.Username, user.ID, clientIP)
+	}
+}
+
+// auditLogin 记录可疑登录的审计日志
+func (s *userService) auditLogin(event, username string, userID int64, clientIP string, fields ...zap.Field) {
+	s.logger.Warn("suspicious login", append([]zap.Field{
+		zap.Bool("audit", true),
+		zap.String("event", event),
+		zap.String("username", username),
+		logger.UserID(userID),
+		zap.String("client_ip", clientIP),
+	}, fields...)...)
+}
+
 // GetUserByID 根据ID获取用户
 func (s *userService) GetUserByID(id int64) (*domain.User, error) {
 	user, err := s.userRepo.GetByID(id)
@@ -215,11 +360,13 @@ func (s *userService) ListUsers(page, pageSize int) (*domain.UserListResponse, e
 		user.PasswordHash = ""
 	}
 
+	users, pageInfo := domain.Paginate(users, total, page, pageSize)
 	return &domain.UserListResponse{
 		Users:    users,
 		Total:    total,
 		Page:     page,
 		PageSize: pageSize,
+		PageInfo: pageInfo,
 	}, nil
 }
 
@@ -233,7 +380,7 @@ func (s *userService) UpdateUserRole(userID int64, role domain.UserRole) error {
 	// 检查用户是否存在
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		s.logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		s.logger.Error("failed to get user", logger.UserID(userID), zap.Error(err))
 		return fmt.Errorf("get user: %w", err)
 	}
 	if user == nil {
@@ -243,15 +390,16 @@ func (s *userService) UpdateUserRole(userID int64, role domain.UserRole) error {
 	// 更新角色
 	if err := s.userRepo.UpdateUserRole(userID, role); err != nil {
 		s.logger.Error("failed to update user role",
-			zap.Int64("user_id", userID),
+			logger.UserID(userID),
 			zap.String("role", string(role)),
 			zap.Error(err),
 		)
 		return fmt.Errorf("update user role: %w", err)
 	}
+	s.invalidateUserCache(userID)
 
 	s.logger.Info("user role updated",
-		zap.Int64("user_id", userID),
+		logger.UserID(userID),
 		zap.String("username", user.Username),
 		zap.String("old_role", string(user.Role)),
 		zap.String("new_role", string(role)),
@@ -260,12 +408,49 @@ func (s *userService) UpdateUserRole(userID int64, role domain.UserRole) error {
 	return nil
 }
 
+// UpdateUserTier 更新用户等级（管理员专用）
+// 等级写入访问令牌，用户重新登录后生效
+func (s *userService) UpdateUserTier(userID int64, tier domain.UserTier) error {
+	if !tier.IsValid() {
+		return fmt.Errorf("invalid tier: %s", tier)
+	}
+
+	// 检查用户是否存在
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		s.logger.Error("failed to get user", logger.UserID(userID), zap.Error(err))
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.userRepo.UpdateUserTier(userID, tier); err != nil {
+		s.logger.Error("failed to update user tier",
+			logger.UserID(userID),
+			zap.String("tier", string(tier)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("update user tier: %w", err)
+	}
+	s.invalidateUserCache(userID)
+
+	s.logger.Info("user tier updated",
+		logger.UserID(userID),
+		zap.String("username", user.Username),
+		zap.String("old_tier", string(user.Tier.OrDefault())),
+		zap.String("new_tier", string(tier)),
+	)
+
+	return nil
+}
+
 // UpdateUserStatus 更新用户状态（管理员专用）
 func (s *userService) UpdateUserStatus(userID int64, isActive bool) error {
 	// 检查用户是否存在
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		s.logger.Error("failed to get user", zap.Int64("user_id", userID), zap.Error(err))
+		s.logger.Error("failed to get user", logger.UserID(userID), zap.Error(err))
 		return fmt.Errorf("get user: %w", err)
 	}
 	if user == nil {
@@ -275,12 +460,13 @@ func (s *userService) UpdateUserStatus(userID int64, isActive bool) error {
 	// 更新状态
 	if err := s.userRepo.UpdateUserStatus(userID, isActive); err != nil {
 		s.logger.Error("failed to update user status",
-			zap.Int64("user_id", userID),
+			logger.UserID(userID),
 			zap.Bool("is_active", isActive),
 			zap.Error(err),
 		)
 		return fmt.Errorf("update user status: %w", err)
 	}
+	s.invalidateUserCache(userID)
 
 	action := "deactivated"
 	if isActive {
@@ -288,10 +474,17 @@ func (s *userService) UpdateUserStatus(userID int64, isActive bool) error {
 	}
 
 	s.logger.Info("user status updated",
-		zap.Int64("user_id", userID),
+		logger.UserID(userID),
 		zap.String("username", user.Username),
 		zap.String("action", action),
 	)
 
 	return nil
 }
+
+// invalidateUserCache 用户角色、状态或等级变更后失效缓存
+func (s *userService) invalidateUserCache(userID int64) {
+	if s.userCache != nil {
+		s.userCache.Invalidate(context.Background(), userID)
+	}
+}
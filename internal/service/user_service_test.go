@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -8,44 +9,59 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/mocks"
 )
 
-// MockUserRepository 是用于测试的用户仓储模拟实现
-type MockUserRepository struct {
+// fakeUserRepo 内存中的用户仓储，按用户名和邮箱索引
+type fakeUserRepo struct {
+	*mocks.UserRepositoryMock
+
 	users  map[string]*domain.User // username -> user
 	emails map[string]*domain.User // email -> user
 	nextID int64
 }
 
-func NewMockUserRepository() *MockUserRepository {
-	return &MockUserRepository{
-		users:  make(map[string]*domain.User),
-		emails: make(map[string]*domain.User),
-		nextID: 1,
-	}
+func newFakeUserRepo() *fakeUserRepo {
+	r := &fakeUserRepo{
+		UserRepositoryMock: &mocks.UserRepositoryMock{},
+		users:              make(map[string]*domain.User),
+		emails:             make(map[string]*domain.User),
+		nextID:             1,
+	}
+	r.CreateFunc = r.create
+	r.GetByIDFunc = r.getByID
+	r.GetByUsernameFunc = r.getByUsername
+	r.GetByEmailFunc = r.getByEmail
+	r.UpdateFunc = func(user *domain.User) error { return nil }
+	r.DeleteFunc = func(id int64) error { return nil }
+	r.ListUsersFunc = r.listUsers
+	r.UpdateUserRoleFunc = r.updateUserRole
+	r.UpdateUserStatusFunc = r.updateUserStatus
+	r.UpdateUserTierFunc = r.updateUserTier
+	return r
 }
 
-func (m *MockUserRepository) Create(user *domain.User) error {
+func (r *fakeUserRepo) create(user *domain.User) error {
 	// 检查用户名是否已存在
-	if _, exists := m.users[user.Username]; exists {
+	if _, exists := r.users[user.Username]; exists {
 		return errors.New("username already exists")
 	}
 
 	// 检查邮箱是否已存在
-	if _, exists := m.emails[user.Email]; exists {
+	if _, exists := r.emails[user.Email]; exists {
 		return errors.New("email already exists")
 	}
 
-	user.ID = m.nextID
-	m.nextID++
+	user.ID = r.nextID
+	r.nextID++
 
-	m.users[user.Username] = user
-	m.emails[user.Email] = user
+	r.users[user.Username] = user
+	r.emails[user.Email] = user
 	return nil
 }
 
-func (m *MockUserRepository) GetByID(id int64) (*domain.User, error) {
-	for _, user := range m.users {
+func (r *fakeUserRepo) getByID(id int64) (*domain.User, error) {
+	for _, user := range r.users {
 		if user.ID == id {
 			return user, nil
 		}
@@ -53,35 +69,17 @@ func (m *MockUserRepository) GetByID(id int64) (*domain.User, error) {
 	return nil, nil
 }
 
-func (m *MockUserRepository) GetByUsername(username string) (*domain.User, error) {
-	user, exists := m.users[username]
-	if !exists {
-		return nil, nil
-	}
-	return user, nil
+func (r *fakeUserRepo) getByUsername(username string) (*domain.User, error) {
+	return r.users[username], nil
 }
 
-func (m *MockUserRepository) GetByEmail(email string) (*domain.User, error) {
-	user, exists := m.emails[email]
-	if !exists {
-		return nil, nil
-	}
-	return user, nil
+func (r *fakeUserRepo) getByEmail(email string) (*domain.User, error) {
+	return r.emails[email], nil
 }
 
-func (m *MockUserRepository) Update(user *domain.User) error {
-	// 简化实现，实际项目中需要更复杂的逻辑
-	return nil
-}
-
-func (m *MockUserRepository) Delete(id int64) error {
-	// 简化实现
-	return nil
-}
-
-func (m *MockUserRepository) ListUsers(offset, limit int) ([]*domain.User, int64, error) {
+func (r *fakeUserRepo) listUsers(offset, limit int) ([]*domain.User, int64, error) {
 	var users []*domain.User
-	for _, user := range m.users {
+	for _, user := range r.users {
 		users = append(users, user)
 	}
 
@@ -100,28 +98,35 @@ func (m *MockUserRepository) ListUsers(offset, limit int) ([]*domain.User, int64
 	return users[start:end], total, nil
 }
 
-func (m *MockUserRepository) UpdateUserRole(userID int64, role domain.UserRole) error {
-	for _, user := range m.users {
-		if user.ID == userID {
-			user.Role = role
-			return nil
-		}
+func (r *fakeUserRepo) updateUserRole(userID int64, role domain.UserRole) error {
+	user, _ := r.getByID(userID)
+	if user == nil {
+		return errors.New("user not found")
 	}
-	return errors.New("user not found")
+	user.Role = role
+	return nil
 }
 
-func (m *MockUserRepository) UpdateUserStatus(userID int64, isActive bool) error {
-	for _, user := range m.users {
-		if user.ID == userID {
-			user.IsActive = isActive
-			return nil
-		}
+func (r *fakeUserRepo) updateUserStatus(userID int64, isActive bool) error {
+	user, _ := r.getByID(userID)
+	if user == nil {
+		return errors.New("user not found")
 	}
-	return errors.New("user not found")
+	user.IsActive = isActive
+	return nil
+}
+
+func (r *fakeUserRepo) updateUserTier(userID int64, tier domain.UserTier) error {
+	user, _ := r.getByID(userID)
+	if user == nil {
+		return domain.NewNotFoundError("user not found")
+	}
+	user.Tier = tier
+	return nil
 }
 
 func createTestUserService() UserService {
-	mockRepo := NewMockUserRepository()
+	mockRepo := newFakeUserRepo()
 	logger := zap.NewNop()
 	return NewUserService(mockRepo, logger)
 }
@@ -248,7 +253,7 @@ func TestUserService_Login_Success(t *testing.T) {
 		Password: "password123",
 	}
 
-	user, err := userService.Login(loginReq)
+	user, err := userService.Login(context.Background(), loginReq, "")
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
@@ -259,7 +264,7 @@ func TestUserService_Login_Success(t *testing.T) {
 
 	// 测试邮箱登录
 	loginReq.Username = "test@example.com"
-	user, err = userService.Login(loginReq)
+	user, err = userService.Login(context.Background(), loginReq, "")
 	if err != nil {
 		t.Fatalf("Email login failed: %v", err)
 	}
@@ -302,7 +307,7 @@ func TestUserService_Login_InvalidCredentials(t *testing.T) {
 				Password: tc.password,
 			}
 
-			_, err := userService.Login(loginReq)
+			_, err := userService.Login(context.Background(), loginReq, "")
 			if err == nil {
 				t.Error("Expected login to fail")
 			}
@@ -316,7 +321,7 @@ func TestUserService_Login_InvalidCredentials(t *testing.T) {
 
 func TestUserService_Login_InactiveUser(t *testing.T) {
 	// 这个测试需要直接操作mock仓储来设置用户为非活跃状态
-	mockRepo := NewMockUserRepository()
+	mockRepo := newFakeUserRepo()
 	logger := zap.NewNop()
 	userService := NewUserService(mockRepo, logger)
 
@@ -341,7 +346,7 @@ func TestUserService_Login_InactiveUser(t *testing.T) {
 		Password: "password123",
 	}
 
-	_, err = userService.Login(loginReq)
+	_, err = userService.Login(context.Background(), loginReq, "")
 	if err == nil {
 		t.Error("Expected login to fail for inactive user")
 	}
@@ -386,3 +391,31 @@ func TestUserService_GetUserByID(t *testing.T) {
 		t.Errorf("Expected ErrUserNotFound, got %v", err)
 	}
 }
+
+func TestUserService_UpdateUserTier(t *testing.T) {
+	userService := createTestUserService()
+
+	user, err := userService.Register(&domain.RegisterRequest{
+		Username: "tieruser",
+		Email:    "tier@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := userService.UpdateUserTier(user.ID, domain.UserTierGold); err != nil {
+		t.Fatalf("UpdateUserTier failed: %v", err)
+	}
+	got, _ := userService.GetUserByID(user.ID)
+	if got.Tier != domain.UserTierGold {
+		t.Errorf("Expected tier gold, got %s", got.Tier)
+	}
+
+	if err := userService.UpdateUserTier(user.ID, domain.UserTier("diamond")); err == nil {
+		t.Error("Expected error for invalid tier")
+	}
+	if err := userService.UpdateUserTier(999, domain.UserTierGold); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
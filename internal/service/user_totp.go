@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/logger"
+)
+
+// 两步验证业务错误
+var (
+	ErrTOTPUnavailable    = errors.New("two-factor authentication is not available")
+	ErrTOTPAlreadyEnabled = domain.NewConflictError("two-factor authentication already enabled")
+	ErrTOTPNotEnrolled    = domain.NewConflictError("two-factor authentication not enrolled")
+	ErrTOTPNotEnabled     = domain.NewConflictError("two-factor authentication not enabled")
+)
+
+// EnrollTOTP 生成新的待确认密钥，覆盖尚未确认的旧密钥；已启用时需先关闭
+func (s *userService) EnrollTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollment, error) {
+	if s.totpRepo == nil {
+		return nil, ErrTOTPUnavailable
+	}
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.totpCipher.seal(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.totpRepo.SavePending(ctx, userID, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save totp secret: %w", err)
+	}
+	if !saved {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	s.logger.Info("two-factor authentication enrollment started", logger.UserID(userID))
+	return &domain.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURL: totpURL(s.totpIssuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTOTP 用验证器生成的验证码确认绑定，确认后登录需要验证码
+func (s *userService) EnableTOTP(ctx context.Context, userID int64, code string) error {
+	user, totp, err := s.userTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if totp == nil {
+		return ErrTOTPNotEnrolled
+	}
+	if totp.IsEnabled() {
+		return ErrTOTPAlreadyEnabled
+	}
+
+	step, err := s.checkTOTPCode(ctx, user, totp, code, "")
+	if err != nil {
+		return err
+	}
+	enabled, err := s.totpRepo.Enable(ctx, userID, step, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if !enabled {
+		return ErrTOTPAlreadyEnabled
+	}
+
+	s.logger.Info("two-factor authentication enabled",
+		zap.Bool("audit", true),
+		logger.UserID(userID),
+		zap.String("username", user.Username))
+	return nil
+}
+
+// DisableTOTP 校验当前验证码后删除绑定，避免仅凭被盗的访问令牌关闭两步验证
+func (s *userService) DisableTOTP(ctx context.Context, userID int64, code string) error {
+	user, totp, err := s.userTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !totp.IsEnabled() {
+		return ErrTOTPNotEnabled
+	}
+
+	if err := s.useTOTPCode(ctx, user, totp, code, ""); err != nil {
+		return err
+	}
+	if err := s.totpRepo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+
+	s.logger.Info("two-factor authentication disabled",
+		zap.Bool("audit", true),
+		logger.UserID(userID),
+		zap.String("username", user.Username))
+	return nil
+}
+
+// verifyLoginOTP 账号已启用两步验证时校验登录请求中的验证码
+func (s *userService) verifyLoginOTP(ctx context.Context, user *domain.User, code, clientIP string) error {
+	if s.totpRepo == nil {
+		return nil
+	}
+	totp, err := s.getTOTP(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !totp.IsEnabled() {
+		return nil
+	}
+	if code == "" {
+		return ErrOTPRequired
+	}
+	return s.useTOTPCode(ctx, user, totp, code, clientIP)
+}
+
+// userTOTP 获取用户及其两步验证绑定，绑定不存在时返回 nil
+func (s *userService) userTOTP(ctx context.Context, userID int64) (*domain.User, *domain.UserTOTP, error) {
+	if s.totpRepo == nil {
+		return nil, nil, ErrTOTPUnavailable
+	}
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	totp, err := s.getTOTP(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, totp, nil
+}
+
+// getTOTP 获取两步验证绑定并解密密钥，绑定不存在时返回 nil
+func (s *userService) getTOTP(ctx context.Context, userID int64) (*domain.UserTOTP, error) {
+	totp, err := s.totpRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+	if totp == nil {
+		return nil, nil
+	}
+	if totp.Secret, err = s.totpCipher.open(userID, totp.Secret); err != nil {
+		return nil, err
+	}
+	return totp, nil
+}
+
+// useTOTPCode 校验验证码并记录使用的时间步，并发提交同一验证码时只有一次通过
+func (s *userService) useTOTPCode(ctx context.Context, user *domain.User, totp *domain.UserTOTP, code, clientIP string) error {
+	step, err := s.checkTOTPCode(ctx, user, totp, code, clientIP)
+	if err != nil {
+		return err
+	}
+	used, err := s.totpRepo.MarkUsed(ctx, user.ID, step)
+	if err != nil {
+		return fmt.Errorf("failed to mark totp used: %w", err)
+	}
+	if !used {
+		return ErrInvalidOTP
+	}
+	return nil
+}
+
+// checkTOTPCode 校验验证码，返回匹配的时间步；验证码错误与密码错误共用失败计数，
+// 6 位验证码无法在锁定阈值内被穷举
+func (s *userService) checkTOTPCode(ctx context.Context, user *domain.User, totp *domain.UserTOTP, code, clientIP string) (int64, error) {
+	account := loginAccount(user.Username, user.ID)
+	if err := s.checkLoginLock(ctx, account, user.Username, user.ID, clientIP); err != nil {
+		return 0, err
+	}
+	step, ok := verifyTOTP(totp.Secret, code, time.Now(), totp.LastUsedStep)
+	if !ok {
+		// 确认绑定时输错验证码属于正常操作，只审计已启用绑定的错误验证码
+		if totp.IsEnabled() {
+			s.auditLogin(loginAuditInvalidOTP, user.Username, user.ID, clientIP)
+		}
+		return 0, s.recordLoginFailure(ctx, account, user.Username, user.ID, clientIP, ErrInvalidOTP)
+	}
+	return step, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 签名校验错误
+var (
+	ErrInvalidSignature = errors.New("storage: invalid signature")
+	ErrURLExpired       = errors.New("storage: url expired")
+)
+
+// URLSigner 为存储对象生成并校验有时效的下载链接
+type URLSigner struct {
+	secret   []byte
+	basePath string // 下载接口路径，如 /api/v1/files/download
+	ttl      time.Duration
+}
+
+// NewURLSigner 创建下载链接签名器
+func NewURLSigner(secret, basePath string, ttl time.Duration) *URLSigner {
+	return &URLSigner{secret: []byte(secret), basePath: basePath, ttl: ttl}
+}
+
+// Sign 生成对象下载链接及其过期时间
+func (s *URLSigner) Sign(key string) (string, time.Time) {
+	expiresAt := time.Now().Add(s.ttl).Truncate(time.Second)
+	q := url.Values{}
+	q.Set("key", key)
+	q.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	q.Set("sig", s.signature(key, expiresAt.Unix()))
+	return s.basePath + "?" + q.Encode(), expiresAt
+}
+
+// Verify 校验下载参数，通过时返回对象 key
+func (s *URLSigner) Verify(key, expires, sig string) (string, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || key == "" {
+		return "", ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(key, exp))) {
+		return "", ErrInvalidSignature
+	}
+	if time.Now().Unix() > exp {
+		return "", ErrURLExpired
+	}
+	return key, nil
+}
+
+func (s *URLSigner) signature(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
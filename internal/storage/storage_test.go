@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStorage_PutOpen(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if ok, _ := store.Exists(ctx, "reports/1.csv"); ok {
+		t.Fatal("Exists() = true before Put")
+	}
+	if err := store.Put(ctx, "reports/1.csv", strings.NewReader("a,b\n")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if ok, err := store.Exists(ctx, "reports/1.csv"); !ok || err != nil {
+		t.Fatalf("Exists() = %v, %v, want true", ok, err)
+	}
+
+	rc, err := store.Open(ctx, "reports/1.csv")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	if string(data) != "a,b\n" {
+		t.Errorf("Open() content = %q", data)
+	}
+
+	if _, err := store.Open(ctx, "reports/2.csv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() missing error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStorage_InvalidKey(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+
+	for _, key := range []string{"", "../etc/passwd", "reports/../../x"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Errorf("Put(%q) error = nil, want invalid key", key)
+		}
+	}
+}
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner("secret", "/api/v1/reports/download", time.Minute)
+
+	raw, expiresAt := signer.Sign("reports/1.csv")
+	if !strings.HasPrefix(raw, "/api/v1/reports/download?") {
+		t.Fatalf("Sign() url = %q", raw)
+	}
+	u, _ := url.Parse(raw)
+	q := u.Query()
+	if q.Get("expires") != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Errorf("Sign() expires = %s, want %d", q.Get("expires"), expiresAt.Unix())
+	}
+
+	if key, err := signer.Verify(q.Get("key"), q.Get("expires"), q.Get("sig")); err != nil || key != "reports/1.csv" {
+		t.Errorf("Verify() = %q, %v", key, err)
+	}
+	if _, err := signer.Verify("reports/2.csv", q.Get("expires"), q.Get("sig")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() tampered key error = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := NewURLSigner("other", "", time.Minute).Verify(q.Get("key"), q.Get("expires"), q.Get("sig")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() wrong secret error = %v, want ErrInvalidSignature", err)
+	}
+
+	expired := NewURLSigner("secret", "", -time.Minute)
+	raw, _ = expired.Sign("reports/1.csv")
+	u, _ = url.Parse(raw)
+	q = u.Query()
+	if _, err := expired.Verify(q.Get("key"), q.Get("expires"), q.Get("sig")); !errors.Is(err, ErrURLExpired) {
+		t.Errorf("Verify() expired error = %v, want ErrURLExpired", err)
+	}
+}
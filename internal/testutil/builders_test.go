@@ -0,0 +1,64 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+func TestSpikeEventBuilder(t *testing.T) {
+	b := NewSpikeEventBuilder().Active().WithStock(10)
+	event := b.Build()
+	if !event.IsActive() || event.SpikeStock != 10 {
+		t.Fatalf("Active().WithStock(10) = %+v", event)
+	}
+
+	if event := NewSpikeEventBuilder().StartsIn(time.Hour).WithPreview(time.Hour).Build(); !event.IsInPreview() {
+		t.Error("StartsIn().WithPreview() should be in preview")
+	}
+	if event := NewSpikeEventBuilder().Ended().Build(); event.IsActive() {
+		t.Error("Ended() should not be active")
+	}
+
+	// 每次 Build 返回独立副本
+	event.Name = "changed"
+	if b.Build().Name == "changed" {
+		t.Error("Build() should return an independent copy")
+	}
+}
+
+func TestSpikeOrderBuilder(t *testing.T) {
+	event := NewSpikeEventBuilder().WithID(7).WithPrices(50, 100).Build()
+	order := NewSpikeOrderBuilder().ForEvent(event).ForUser(3).WithQuantity(2).Paid().Build()
+
+	if order.SpikeEventID != 7 || order.UserID != 3 || order.TotalAmount != 100 {
+		t.Errorf("order = %+v, want event 7 user 3 total 100", order)
+	}
+	if order.Status != domain.SpikeOrderStatusPaid || order.PaidAt == nil {
+		t.Errorf("Paid() status = %s paid_at = %v", order.Status, order.PaidAt)
+	}
+	if order.IdempotencyKey == "" {
+		t.Error("Build() should fill a default idempotency key")
+	}
+}
+
+type recordingUserRepo struct{ users []*domain.User }
+
+func (r *recordingUserRepo) Create(user *domain.User) error {
+	user.ID = int64(len(r.users) + 1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func TestSeedUsers(t *testing.T) {
+	repo := &recordingUserRepo{}
+	alice := NewUserBuilder().WithUsername("alice").Build()
+	admin := NewUserBuilder().WithUsername("root").Admin().Build()
+
+	SeedUsers(t, repo, alice, admin)
+
+	if alice.ID != 1 || admin.ID != 2 || !admin.IsAdmin() {
+		t.Errorf("seeded users = %+v, %+v", alice, admin)
+	}
+}
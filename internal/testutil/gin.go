@@ -0,0 +1,66 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/MorseWayne/spike_shop/internal/resp"
+)
+
+// 测试引擎为每个请求注入的链路标识
+const (
+	TestRequestID = "req-test"
+	TestTraceID   = "trace-test"
+)
+
+// NewGinEngine 创建测试模式的 gin 引擎，代替请求ID与认证中间件为每个请求注入 request_id、trace_id；
+// userID 不为 0 时同时注入 user_id，等价于已登录的普通用户
+func NewGinEngine(userID int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("request_id", TestRequestID)
+		c.Set("trace_id", TestTraceID)
+		if userID != 0 {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	return engine
+}
+
+// DoJSON 向 handler 发送请求并返回响应；body 不为空时编码为 JSON 请求体
+func DoJSON(tb testing.TB, handler http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	tb.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			tb.Fatalf("failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// DecodeResponse 解析统一响应结构，响应体不是合法 JSON 时测试失败
+func DecodeResponse[T any](tb testing.TB, w *httptest.ResponseRecorder) resp.Response[T] {
+	tb.Helper()
+	var body resp.Response[T]
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		tb.Fatalf("failed to unmarshal response %q: %v", w.Body.String(), err)
+	}
+	return body
+}
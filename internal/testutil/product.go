@@ -0,0 +1,103 @@
+package testutil
+
+import (
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// ProductBuilder 商品构造器，默认是在售商品
+type ProductBuilder struct {
+	product domain.Product
+}
+
+// NewProductBuilder 创建商品构造器
+func NewProductBuilder() *ProductBuilder {
+	now := time.Now()
+	return &ProductBuilder{product: domain.Product{
+		Name:      "Test Product",
+		Price:     199,
+		SKU:       "TEST-SKU-001",
+		Status:    domain.ProductStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+}
+
+// WithID 设置商品ID（写入模拟仓储时会被仓储分配的ID覆盖）
+func (b *ProductBuilder) WithID(id int64) *ProductBuilder {
+	b.product.ID = id
+	return b
+}
+
+// WithName 设置商品名称
+func (b *ProductBuilder) WithName(name string) *ProductBuilder {
+	b.product.Name = name
+	return b
+}
+
+// WithSKU 设置 SKU
+func (b *ProductBuilder) WithSKU(sku string) *ProductBuilder {
+	b.product.SKU = sku
+	return b
+}
+
+// WithPrice 设置价格
+func (b *ProductBuilder) WithPrice(price float64) *ProductBuilder {
+	b.product.Price = price
+	return b
+}
+
+// Inactive 暂停销售
+func (b *ProductBuilder) Inactive() *ProductBuilder {
+	b.product.Status = domain.ProductStatusInactive
+	return b
+}
+
+// Build 返回构造的商品，每次调用返回独立副本
+func (b *ProductBuilder) Build() *domain.Product {
+	product := b.product
+	return &product
+}
+
+// InventoryBuilder 库存构造器，默认商品 1 可用库存 100
+type InventoryBuilder struct {
+	inventory domain.Inventory
+}
+
+// NewInventoryBuilder 创建库存构造器
+func NewInventoryBuilder() *InventoryBuilder {
+	now := time.Now()
+	return &InventoryBuilder{inventory: domain.Inventory{
+		ProductID: 1,
+		Stock:     100,
+		MaxStock:  1000,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+}
+
+// ForProduct 设置所属商品
+func (b *InventoryBuilder) ForProduct(productID int64) *InventoryBuilder {
+	b.inventory.ProductID = productID
+	return b
+}
+
+// WithStock 设置可用库存
+func (b *InventoryBuilder) WithStock(stock int) *InventoryBuilder {
+	b.inventory.Stock = stock
+	return b
+}
+
+// WithReserved 设置预留库存
+func (b *InventoryBuilder) WithReserved(reserved int) *InventoryBuilder {
+	b.inventory.ReservedStock = reserved
+	return b
+}
+
+// Build 返回构造的库存，每次调用返回独立副本
+func (b *InventoryBuilder) Build() *domain.Inventory {
+	inventory := b.inventory
+	return &inventory
+}
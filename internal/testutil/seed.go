@@ -0,0 +1,85 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// 以下接口只包含填充所需的 Create 方法，仓储实现与各包内的模拟仓储都满足
+
+// SpikeEventCreator 可写入秒杀活动的仓储
+type SpikeEventCreator interface {
+	Create(ctx context.Context, event *domain.SpikeEvent) error
+}
+
+// SpikeOrderCreator 可写入秒杀订单的仓储
+type SpikeOrderCreator interface {
+	Create(ctx context.Context, order *domain.SpikeOrder) error
+}
+
+// UserCreator 可写入用户的仓储
+type UserCreator interface {
+	Create(user *domain.User) error
+}
+
+// ProductCreator 可写入商品的仓储
+type ProductCreator interface {
+	Create(product *domain.Product) error
+}
+
+// InventoryCreator 可写入库存的仓储
+type InventoryCreator interface {
+	Create(ctx context.Context, inventory *domain.Inventory) error
+}
+
+// SeedSpikeEvents 依次写入活动，失败时终止测试；写入后对象上是仓储分配的ID
+func SeedSpikeEvents(tb testing.TB, repo SpikeEventCreator, events ...*domain.SpikeEvent) {
+	tb.Helper()
+	for _, event := range events {
+		if err := repo.Create(context.Background(), event); err != nil {
+			tb.Fatalf("seed spike event %q: %v", event.Name, err)
+		}
+	}
+}
+
+// SeedSpikeOrders 依次写入订单，失败时终止测试
+func SeedSpikeOrders(tb testing.TB, repo SpikeOrderCreator, orders ...*domain.SpikeOrder) {
+	tb.Helper()
+	for _, order := range orders {
+		if err := repo.Create(context.Background(), order); err != nil {
+			tb.Fatalf("seed spike order for event %d user %d: %v", order.SpikeEventID, order.UserID, err)
+		}
+	}
+}
+
+// SeedUsers 依次写入用户，失败时终止测试
+func SeedUsers(tb testing.TB, repo UserCreator, users ...*domain.User) {
+	tb.Helper()
+	for _, user := range users {
+		if err := repo.Create(user); err != nil {
+			tb.Fatalf("seed user %q: %v", user.Username, err)
+		}
+	}
+}
+
+// SeedProducts 依次写入商品，失败时终止测试
+func SeedProducts(tb testing.TB, repo ProductCreator, products ...*domain.Product) {
+	tb.Helper()
+	for _, product := range products {
+		if err := repo.Create(product); err != nil {
+			tb.Fatalf("seed product %q: %v", product.Name, err)
+		}
+	}
+}
+
+// SeedInventories 依次写入库存，失败时终止测试
+func SeedInventories(tb testing.TB, repo InventoryCreator, inventories ...*domain.Inventory) {
+	tb.Helper()
+	for _, inventory := range inventories {
+		if err := repo.Create(context.Background(), inventory); err != nil {
+			tb.Fatalf("seed inventory for product %d: %v", inventory.ProductID, err)
+		}
+	}
+}
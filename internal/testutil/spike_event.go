@@ -0,0 +1,185 @@
+// Package testutil 提供测试用的领域对象构造器与模拟仓储填充辅助函数，
+// 构造器默认生成"可直接用于正常流程"的对象，测试只需声明与用例相关的差异字段。
+package testutil
+
+import (
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// SpikeEventBuilder 秒杀活动构造器，默认是一个已开始 1 小时、还剩 1 小时、库存 100 的进行中活动
+type SpikeEventBuilder struct {
+	event domain.SpikeEvent
+}
+
+// NewSpikeEventBuilder 创建秒杀活动构造器
+func NewSpikeEventBuilder() *SpikeEventBuilder {
+	now := time.Now()
+	return &SpikeEventBuilder{event: domain.SpikeEvent{
+		ProductID:     1,
+		Name:          "Test Spike Event",
+		SpikePrice:    99,
+		OriginalPrice: 199,
+		SpikeStock:    100,
+		StartAt:       now.Add(-time.Hour),
+		EndAt:         now.Add(time.Hour),
+		Status:        domain.SpikeEventStatusActive,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}}
+}
+
+// WithID 设置活动ID（写入模拟仓储时会被仓储分配的ID覆盖）
+func (b *SpikeEventBuilder) WithID(id int64) *SpikeEventBuilder {
+	b.event.ID = id
+	return b
+}
+
+// WithName 设置活动名称
+func (b *SpikeEventBuilder) WithName(name string) *SpikeEventBuilder {
+	b.event.Name = name
+	return b
+}
+
+// ForProduct 设置关联商品
+func (b *SpikeEventBuilder) ForProduct(productID int64) *SpikeEventBuilder {
+	b.event.ProductID = productID
+	return b
+}
+
+// WithStock 设置秒杀库存
+func (b *SpikeEventBuilder) WithStock(stock int64) *SpikeEventBuilder {
+	b.event.SpikeStock = stock
+	return b
+}
+
+// WithSold 设置已售数量
+func (b *SpikeEventBuilder) WithSold(sold int64) *SpikeEventBuilder {
+	b.event.SoldCount = sold
+	return b
+}
+
+// SoldOut 已售数量等于库存
+func (b *SpikeEventBuilder) SoldOut() *SpikeEventBuilder {
+	b.event.SoldCount = b.event.SpikeStock
+	return b
+}
+
+// WithPrices 设置秒杀价与原价
+func (b *SpikeEventBuilder) WithPrices(spikePrice, originalPrice float64) *SpikeEventBuilder {
+	b.event.SpikePrice = spikePrice
+	b.event.OriginalPrice = originalPrice
+	return b
+}
+
+// WithLimits 设置单用户购买上限与参与请求每秒上限
+func (b *SpikeEventBuilder) WithLimits(maxPerUser, qpsLimit int64) *SpikeEventBuilder {
+	b.event.MaxPerUser = maxPerUser
+	b.event.QPSLimit = qpsLimit
+	return b
+}
+
+// WithStockBuckets 设置 Redis 库存分桶数
+func (b *SpikeEventBuilder) WithStockBuckets(buckets int) *SpikeEventBuilder {
+	b.event.StockBuckets = buckets
+	return b
+}
+
+// RequireChallenge 要求参与请求通过防刷挑战
+func (b *SpikeEventBuilder) RequireChallenge() *SpikeEventBuilder {
+	b.event.ChallengeRequired = true
+	return b
+}
+
+// WithWaitingRoom 开启排队模式，每秒放行 rate 人
+func (b *SpikeEventBuilder) WithWaitingRoom(rate int64) *SpikeEventBuilder {
+	b.event.WaitingRoomRate = rate
+	return b
+}
+
+// Between 设置活动起止时间
+func (b *SpikeEventBuilder) Between(startAt, endAt time.Time) *SpikeEventBuilder {
+	b.event.StartAt = startAt
+	b.event.EndAt = endAt
+	return b
+}
+
+// Active 进行中：已开始 1 小时，1 小时后结束
+func (b *SpikeEventBuilder) Active() *SpikeEventBuilder {
+	now := time.Now()
+	b.event.Status = domain.SpikeEventStatusActive
+	b.event.StartAt = now.Add(-time.Hour)
+	b.event.EndAt = now.Add(time.Hour)
+	return b
+}
+
+// StartsIn 已发布但尚未开始，d 后开始，持续 1 小时
+func (b *SpikeEventBuilder) StartsIn(d time.Duration) *SpikeEventBuilder {
+	now := time.Now()
+	b.event.Status = domain.SpikeEventStatusActive
+	b.event.StartAt = now.Add(d)
+	b.event.EndAt = now.Add(d + time.Hour)
+	return b
+}
+
+// Pending 待开始状态，1 小时后开始
+func (b *SpikeEventBuilder) Pending() *SpikeEventBuilder {
+	b.StartsIn(time.Hour)
+	b.event.Status = domain.SpikeEventStatusPending
+	return b
+}
+
+// Ended 已结束：1 小时前结束
+func (b *SpikeEventBuilder) Ended() *SpikeEventBuilder {
+	now := time.Now()
+	b.event.Status = domain.SpikeEventStatusEnded
+	b.event.StartAt = now.Add(-2 * time.Hour)
+	b.event.EndAt = now.Add(-time.Hour)
+	return b
+}
+
+// Paused 已暂停，保留已设置的起止时间
+func (b *SpikeEventBuilder) Paused() *SpikeEventBuilder {
+	b.event.Status = domain.SpikeEventStatusPaused
+	return b
+}
+
+// Draft 草稿，保留已设置的起止时间
+func (b *SpikeEventBuilder) Draft() *SpikeEventBuilder {
+	b.event.Status = domain.SpikeEventStatusDraft
+	return b
+}
+
+// Cancelled 已取消
+func (b *SpikeEventBuilder) Cancelled() *SpikeEventBuilder {
+	b.event.Status = domain.SpikeEventStatusCancelled
+	return b
+}
+
+// WithPreview 设置预告开始时间（相对活动开始时间提前 lead）
+func (b *SpikeEventBuilder) WithPreview(lead time.Duration) *SpikeEventBuilder {
+	previewAt := b.event.StartAt.Add(-lead)
+	b.event.PreviewStartAt = &previewAt
+	return b
+}
+
+// InCampaign 设置所属秒杀专场
+func (b *SpikeEventBuilder) InCampaign(campaignID int64) *SpikeEventBuilder {
+	b.event.SpikeCampaignID = &campaignID
+	return b
+}
+
+// Build 返回构造的活动，每次调用返回独立副本
+func (b *SpikeEventBuilder) Build() *domain.SpikeEvent {
+	event := b.event
+	if b.event.PreviewStartAt != nil {
+		previewAt := *b.event.PreviewStartAt
+		event.PreviewStartAt = &previewAt
+	}
+	if b.event.SpikeCampaignID != nil {
+		campaignID := *b.event.SpikeCampaignID
+		event.SpikeCampaignID = &campaignID
+	}
+	return &event
+}
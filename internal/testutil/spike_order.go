@@ -0,0 +1,125 @@
+package testutil
+
+import (
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+	"github.com/MorseWayne/spike_shop/internal/keys"
+)
+
+// SpikeOrderBuilder 秒杀订单构造器，默认是活动 1、用户 1 购买 1 件、15 分钟后过期的待支付订单
+type SpikeOrderBuilder struct {
+	order domain.SpikeOrder
+}
+
+// NewSpikeOrderBuilder 创建秒杀订单构造器
+func NewSpikeOrderBuilder() *SpikeOrderBuilder {
+	now := time.Now()
+	expireAt := now.Add(15 * time.Minute)
+	return &SpikeOrderBuilder{order: domain.SpikeOrder{
+		SpikeEventID: 1,
+		UserID:       1,
+		Quantity:     1,
+		SpikePrice:   99,
+		TotalAmount:  99,
+		Status:       domain.SpikeOrderStatusPending,
+		ExpireAt:     &expireAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}}
+}
+
+// WithID 设置订单ID（写入模拟仓储时会被仓储分配的ID覆盖）
+func (b *SpikeOrderBuilder) WithID(id int64) *SpikeOrderBuilder {
+	b.order.ID = id
+	return b
+}
+
+// ForEvent 设置所属活动，并沿用活动的秒杀价
+func (b *SpikeOrderBuilder) ForEvent(event *domain.SpikeEvent) *SpikeOrderBuilder {
+	b.order.SpikeEventID = event.ID
+	b.order.SpikePrice = event.SpikePrice
+	b.order.TotalAmount = event.SpikePrice * float64(b.order.Quantity)
+	return b
+}
+
+// ForEventID 只设置所属活动ID
+func (b *SpikeOrderBuilder) ForEventID(eventID int64) *SpikeOrderBuilder {
+	b.order.SpikeEventID = eventID
+	return b
+}
+
+// ForUser 设置下单用户
+func (b *SpikeOrderBuilder) ForUser(userID int64) *SpikeOrderBuilder {
+	b.order.UserID = userID
+	return b
+}
+
+// WithQuantity 设置购买数量并重新计算总金额
+func (b *SpikeOrderBuilder) WithQuantity(quantity int64) *SpikeOrderBuilder {
+	b.order.Quantity = quantity
+	b.order.TotalAmount = b.order.SpikePrice * float64(quantity)
+	return b
+}
+
+// WithIdempotencyKey 设置幂等键，未设置时 Build 按活动与用户生成
+func (b *SpikeOrderBuilder) WithIdempotencyKey(key string) *SpikeOrderBuilder {
+	b.order.IdempotencyKey = key
+	return b
+}
+
+// ExpiresIn 设置支付过期时间，d 为负数表示已过期
+func (b *SpikeOrderBuilder) ExpiresIn(d time.Duration) *SpikeOrderBuilder {
+	expireAt := time.Now().Add(d)
+	b.order.ExpireAt = &expireAt
+	return b
+}
+
+// Pending 待支付
+func (b *SpikeOrderBuilder) Pending() *SpikeOrderBuilder {
+	b.order.Status = domain.SpikeOrderStatusPending
+	return b
+}
+
+// Paid 已支付
+func (b *SpikeOrderBuilder) Paid() *SpikeOrderBuilder {
+	paidAt := time.Now()
+	b.order.Status = domain.SpikeOrderStatusPaid
+	b.order.PaidAt = &paidAt
+	return b
+}
+
+// Cancelled 已取消
+func (b *SpikeOrderBuilder) Cancelled() *SpikeOrderBuilder {
+	cancelledAt := time.Now()
+	b.order.Status = domain.SpikeOrderStatusCancelled
+	b.order.CancelledAt = &cancelledAt
+	return b
+}
+
+// Expired 已过期
+func (b *SpikeOrderBuilder) Expired() *SpikeOrderBuilder {
+	b.ExpiresIn(-time.Minute)
+	b.order.Status = domain.SpikeOrderStatusExpired
+	return b
+}
+
+// Build 返回构造的订单，每次调用返回独立副本
+func (b *SpikeOrderBuilder) Build() *domain.SpikeOrder {
+	order := b.order
+	if order.IdempotencyKey == "" {
+		order.IdempotencyKey = keys.Idempotency("test", order.SpikeEventID, order.UserID)
+	}
+	order.ExpireAt = cloneTime(b.order.ExpireAt)
+	order.PaidAt = cloneTime(b.order.PaidAt)
+	order.CancelledAt = cloneTime(b.order.CancelledAt)
+	return &order
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
@@ -0,0 +1,64 @@
+package testutil
+
+import (
+	"time"
+
+	"github.com/MorseWayne/spike_shop/internal/domain"
+)
+
+// UserBuilder 用户构造器，默认是已激活的普通会员
+type UserBuilder struct {
+	user domain.User
+}
+
+// NewUserBuilder 创建用户构造器
+func NewUserBuilder() *UserBuilder {
+	now := time.Now()
+	return &UserBuilder{user: domain.User{
+		Username:     "testuser",
+		Email:        "testuser@example.com",
+		PasswordHash: "hash",
+		Role:         domain.UserRoleUser,
+		Tier:         domain.UserTierRegular,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}}
+}
+
+// WithID 设置用户ID（写入模拟仓储时会被仓储分配的ID覆盖）
+func (b *UserBuilder) WithID(id int64) *UserBuilder {
+	b.user.ID = id
+	return b
+}
+
+// WithUsername 设置用户名，邮箱随之变为 <username>@example.com
+func (b *UserBuilder) WithUsername(username string) *UserBuilder {
+	b.user.Username = username
+	b.user.Email = username + "@example.com"
+	return b
+}
+
+// WithTier 设置会员等级
+func (b *UserBuilder) WithTier(tier domain.UserTier) *UserBuilder {
+	b.user.Tier = tier
+	return b
+}
+
+// Admin 管理员
+func (b *UserBuilder) Admin() *UserBuilder {
+	b.user.Role = domain.UserRoleAdmin
+	return b
+}
+
+// Inactive 已禁用
+func (b *UserBuilder) Inactive() *UserBuilder {
+	b.user.IsActive = false
+	return b
+}
+
+// Build 返回构造的用户，每次调用返回独立副本
+func (b *UserBuilder) Build() *domain.User {
+	user := b.user
+	return &user
+}
@@ -0,0 +1,83 @@
+// Package tracing 负责链路追踪ID在 HTTP 请求、服务层与消息队列之间的传递。
+// 追踪ID优先取自上游的 W3C traceparent，其次 X-Trace-ID，最后回退到请求ID，
+// 保证同一次用户操作产生的日志、订单事件和消息可以按同一个ID关联。
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTraceID     = "X-Trace-ID"
+
+	// AMQPHeaderTraceID 消息头中的追踪ID键，与 SpikeProducer 写入的保持一致
+	AMQPHeaderTraceID = "trace-id"
+)
+
+type contextKey struct{}
+
+// WithTraceID 将追踪ID写入上下文，空ID不写入
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, traceID)
+}
+
+// TraceIDFromContext 从上下文中读取追踪ID（可能为空）
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(contextKey{}).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// EnsureTraceID 返回上下文中的追踪ID，不存在时生成新ID并写回上下文
+func EnsureTraceID(ctx context.Context) (context.Context, string) {
+	if id := TraceIDFromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := uuid.New().String()
+	return WithTraceID(ctx, id), id
+}
+
+// FromHeaders 从入站请求头中提取追踪ID，均不存在时返回 fallback
+func FromHeaders(h http.Header, fallback string) string {
+	if id, ok := ParseTraceparent(h.Get(HeaderTraceparent)); ok {
+		return id
+	}
+	if id := strings.TrimSpace(h.Get(HeaderTraceID)); id != "" && len(id) <= 128 {
+		return id
+	}
+	return fallback
+}
+
+// ParseTraceparent 解析 W3C traceparent（version-traceid-parentid-flags），返回其中的 trace-id
+func ParseTraceparent(v string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", false
+	}
+	traceID := strings.ToLower(parts[1])
+	if !isHex(traceID) || strings.Trim(traceID, "0") == "" {
+		return "", false
+	}
+	return traceID, true
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+		wantOK bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"uppercase normalized", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"all zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", false},
+		{"short trace id", "00-4bf92f35-00f067aa0ba902b7-01", "", false},
+		{"not hex", "00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTraceparent(tt.header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseTraceparent(%q) = (%q, %v), want (%q, %v)", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFromHeaders(t *testing.T) {
+	h := http.Header{}
+	if got := FromHeaders(h, "req-1"); got != "req-1" {
+		t.Errorf("FromHeaders() without headers = %q, want fallback", got)
+	}
+
+	h.Set(HeaderTraceID, "upstream-trace")
+	if got := FromHeaders(h, "req-1"); got != "upstream-trace" {
+		t.Errorf("FromHeaders() = %q, want X-Trace-ID value", got)
+	}
+
+	h.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got := FromHeaders(h, "req-1"); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("FromHeaders() = %q, want traceparent trace-id", got)
+	}
+}
+
+func TestEnsureTraceID(t *testing.T) {
+	ctx, id := EnsureTraceID(context.Background())
+	if id == "" || TraceIDFromContext(ctx) != id {
+		t.Fatalf("EnsureTraceID() should generate and store an ID, got %q", id)
+	}
+
+	_, again := EnsureTraceID(ctx)
+	if again != id {
+		t.Errorf("EnsureTraceID() = %q, want existing %q", again, id)
+	}
+}
@@ -0,0 +1,2 @@
+-- 删除秒杀订单事件表
+DROP TABLE IF EXISTS `order_events`;
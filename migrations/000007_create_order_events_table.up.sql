@@ -0,0 +1,17 @@
+-- 秒杀订单事件表迁移
+-- 记录订单每一次状态流转，用于客服排查订单时间线
+
+CREATE TABLE IF NOT EXISTS `order_events` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '事件ID',
+  `spike_order_id` bigint unsigned NOT NULL COMMENT '秒杀订单ID',
+  `event_type` enum('created', 'payment_started', 'paid', 'cancelled', 'expired', 'refunded') NOT NULL COMMENT '事件类型',
+  `from_status` varchar(20) NOT NULL DEFAULT '' COMMENT '变更前状态',
+  `to_status` varchar(20) NOT NULL DEFAULT '' COMMENT '变更后状态',
+  `actor` varchar(64) NOT NULL COMMENT '操作者(user:<id>/admin:<id>/system)',
+  `remark` varchar(255) NOT NULL DEFAULT '' COMMENT '备注(如取消原因)',
+  `trace_id` varchar(64) NOT NULL DEFAULT '' COMMENT '链路追踪ID',
+  `created_at` timestamp(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '发生时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_spike_order_id_created_at` (`spike_order_id`, `created_at`),
+  CONSTRAINT `fk_order_events_spike_order_id` FOREIGN KEY (`spike_order_id`) REFERENCES `spike_orders` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀订单事件表';
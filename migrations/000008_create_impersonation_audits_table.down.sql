@@ -0,0 +1,2 @@
+-- 删除客服代操作审计表
+DROP TABLE IF EXISTS `impersonation_audits`;
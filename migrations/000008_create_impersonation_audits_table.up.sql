@@ -0,0 +1,17 @@
+-- 客服代操作审计表迁移
+-- 记录管理员（客服）每一次申请代操作令牌的行为，便于事后追溯
+
+CREATE TABLE IF NOT EXISTS `impersonation_audits` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '审计记录ID',
+  `admin_id` bigint unsigned NOT NULL COMMENT '发起代操作的管理员ID',
+  `target_user_id` bigint unsigned NOT NULL COMMENT '被代操作的用户ID',
+  `scope` varchar(32) NOT NULL COMMENT '代操作权限范围',
+  `reason` varchar(255) NOT NULL COMMENT '代操作原因(如工单号)',
+  `client_ip` varchar(64) NOT NULL DEFAULT '' COMMENT '申请来源IP',
+  `expires_at` timestamp NOT NULL COMMENT '令牌过期时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '申请时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_admin_id` (`admin_id`),
+  KEY `idx_target_user_id` (`target_user_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='客服代操作审计表';
@@ -0,0 +1,2 @@
+-- 删除数据回填进度表
+DROP TABLE IF EXISTS `backfill_progress`;
@@ -0,0 +1,14 @@
+-- 数据回填进度表迁移
+-- 记录 cmd/backfill 各任务的处理游标，支持中断后续跑
+
+CREATE TABLE IF NOT EXISTS `backfill_progress` (
+  `job_name` varchar(100) NOT NULL COMMENT '回填任务名称',
+  `last_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '已处理到的最大主键ID',
+  `processed_count` bigint unsigned NOT NULL DEFAULT 0 COMMENT '累计处理行数',
+  `status` enum('running', 'completed', 'failed') NOT NULL DEFAULT 'running' COMMENT '任务状态',
+  `last_error` varchar(1024) NOT NULL DEFAULT '' COMMENT '最近一次错误信息',
+  `started_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '首次开始时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最近更新时间',
+  `completed_at` timestamp NULL DEFAULT NULL COMMENT '完成时间',
+  PRIMARY KEY (`job_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据回填进度表';
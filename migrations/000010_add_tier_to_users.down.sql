@@ -0,0 +1,5 @@
+-- 回滚用户等级字段
+
+ALTER TABLE `users`
+  DROP KEY `idx_tier`,
+  DROP COLUMN `tier`;
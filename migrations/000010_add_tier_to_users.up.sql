@@ -0,0 +1,6 @@
+-- 用户等级（会员体系）
+-- 等级影响秒杀限流配额、提前参与时间窗口以及订单消息优先级
+
+ALTER TABLE `users`
+  ADD COLUMN `tier` enum('regular', 'silver', 'gold', 'platinum') NOT NULL DEFAULT 'regular' COMMENT '用户等级' AFTER `role`,
+  ADD KEY `idx_tier` (`tier`);
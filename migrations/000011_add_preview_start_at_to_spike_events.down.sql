@@ -0,0 +1,5 @@
+-- 回滚秒杀活动预告窗口
+
+ALTER TABLE `spike_events`
+  DROP KEY `idx_preview_start_at`,
+  DROP COLUMN `preview_start_at`;
@@ -0,0 +1,6 @@
+-- 秒杀活动预告窗口
+-- 预告开始后活动详情可见（倒计时），但要到 start_at 才能参与；调度器在预告开始时预热缓存
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `preview_start_at` timestamp NULL DEFAULT NULL COMMENT '预告开始时间，为空表示无预告期' AFTER `sold_count`,
+  ADD KEY `idx_preview_start_at` (`preview_start_at`);
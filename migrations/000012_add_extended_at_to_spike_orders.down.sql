@@ -0,0 +1,9 @@
+-- 回滚秒杀订单延长支付时间
+
+DELETE FROM `order_events` WHERE `event_type` = 'extended';
+
+ALTER TABLE `order_events`
+  MODIFY COLUMN `event_type` enum('created', 'payment_started', 'paid', 'cancelled', 'expired', 'refunded') NOT NULL COMMENT '事件类型';
+
+ALTER TABLE `spike_orders`
+  DROP COLUMN `extended_at`;
@@ -0,0 +1,8 @@
+-- 秒杀订单一次性延长支付时间
+-- extended_at 非空表示订单已使用过延长机会；延长记录同时写入订单时间线
+
+ALTER TABLE `spike_orders`
+  ADD COLUMN `extended_at` timestamp NULL DEFAULT NULL COMMENT '延长支付时间的时间，为空表示未延长' AFTER `expire_at`;
+
+ALTER TABLE `order_events`
+  MODIFY COLUMN `event_type` enum('created', 'payment_started', 'paid', 'cancelled', 'expired', 'refunded', 'extended') NOT NULL COMMENT '事件类型';
@@ -0,0 +1,2 @@
+-- 删除弃单召回表
+DROP TABLE IF EXISTS `abandoned_checkouts`;
@@ -0,0 +1,24 @@
+-- 弃单（待支付订单过期未支付）记录
+-- 供营销系统发起召回活动（优惠券 + 通知），并记录召回后的转化订单
+
+CREATE TABLE IF NOT EXISTS `abandoned_checkouts` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '弃单ID',
+  `spike_order_id` bigint unsigned NOT NULL COMMENT '过期的秒杀订单ID',
+  `spike_event_id` bigint unsigned NOT NULL COMMENT '秒杀活动ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `quantity` bigint NOT NULL COMMENT '购买数量',
+  `total_amount` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT '订单金额',
+  `expired_at` timestamp NOT NULL COMMENT '过期时间',
+  `campaign_id` varchar(64) NOT NULL DEFAULT '' COMMENT '召回活动ID，为空表示尚未召回',
+  `coupon_code` varchar(64) NOT NULL DEFAULT '' COMMENT '召回发放的优惠券码',
+  `contacted_at` timestamp NULL DEFAULT NULL COMMENT '召回通知发送时间',
+  `recovered_order_id` bigint unsigned DEFAULT NULL COMMENT '召回后转化的秒杀订单ID',
+  `recovered_at` timestamp NULL DEFAULT NULL COMMENT '转化时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_spike_order_id` (`spike_order_id`),
+  KEY `idx_spike_event_id_expired_at` (`spike_event_id`, `expired_at`),
+  KEY `idx_user_id_campaign_id` (`user_id`, `campaign_id`),
+  KEY `idx_campaign_id` (`campaign_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='弃单召回表';
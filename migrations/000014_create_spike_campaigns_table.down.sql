@@ -0,0 +1,7 @@
+-- 回滚秒杀专场
+
+ALTER TABLE `spike_events`
+  DROP KEY `idx_spike_campaign_id`,
+  DROP COLUMN `spike_campaign_id`;
+
+DROP TABLE IF EXISTS `spike_campaigns`;
@@ -0,0 +1,18 @@
+-- 秒杀专场：将多个秒杀活动归为一组，支持专场维度报表与跨活动的单用户购买次数上限
+
+CREATE TABLE IF NOT EXISTS `spike_campaigns` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '专场ID',
+  `name` varchar(255) NOT NULL COMMENT '专场名称',
+  `description` text COMMENT '专场描述',
+  `max_purchases_per_user` int NOT NULL DEFAULT '0' COMMENT '单用户在专场内所有活动中的最大购买次数，0 表示不限制',
+  `start_at` timestamp NOT NULL COMMENT '专场开始时间',
+  `end_at` timestamp NOT NULL COMMENT '专场结束时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_start_at_end_at` (`start_at`, `end_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀专场表';
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `spike_campaign_id` bigint unsigned DEFAULT NULL COMMENT '所属秒杀专场ID，为空表示不属于任何专场' AFTER `preview_start_at`,
+  ADD KEY `idx_spike_campaign_id` (`spike_campaign_id`);
@@ -0,0 +1,2 @@
+-- 删除库存预留记录表
+DROP TABLE IF EXISTS `inventory_reservations`;
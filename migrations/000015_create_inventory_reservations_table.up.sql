@@ -0,0 +1,18 @@
+-- 库存预留记录
+-- 每次预留生成一条带过期时间的记录，调用方凭预留ID消费或释放；
+-- 过期未处理的预留（如结算流程崩溃）由释放任务归还到可用库存
+
+CREATE TABLE IF NOT EXISTS `inventory_reservations` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `reservation_id` varchar(36) NOT NULL COMMENT '预留ID，返回给调用方用于消费或释放',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `quantity` int NOT NULL COMMENT '预留数量',
+  `status` enum('reserved','consumed','released','expired') NOT NULL DEFAULT 'reserved' COMMENT '预留状态',
+  `expires_at` timestamp NOT NULL COMMENT '过期时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_reservation_id` (`reservation_id`),
+  KEY `idx_status_expires_at` (`status`, `expires_at`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='库存预留记录表';
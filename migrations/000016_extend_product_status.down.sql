@@ -0,0 +1,6 @@
+-- 回滚商品生命周期状态，新增状态的商品归为 inactive
+
+UPDATE `products` SET `status` = 'inactive' WHERE `status` IN ('draft', 'paused', 'discontinued');
+
+ALTER TABLE `products`
+  MODIFY COLUMN `status` enum('active', 'inactive', 'deleted') NOT NULL DEFAULT 'active' COMMENT '商品状态';
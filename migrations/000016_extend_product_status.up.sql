@@ -0,0 +1,5 @@
+-- 商品生命周期状态
+-- 新增 draft（草稿）、paused（临时暂停）、discontinued（已停产）
+
+ALTER TABLE `products`
+  MODIFY COLUMN `status` enum('draft', 'active', 'inactive', 'paused', 'discontinued', 'deleted') NOT NULL DEFAULT 'active' COMMENT '商品状态';
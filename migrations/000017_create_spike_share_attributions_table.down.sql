@@ -0,0 +1,3 @@
+-- 回滚秒杀分享链接归因
+
+DROP TABLE IF EXISTS `spike_share_attributions`;
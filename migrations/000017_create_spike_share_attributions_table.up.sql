@@ -0,0 +1,11 @@
+-- 分享链接归因：按活动与推广渠道统计经签名分享链接到达的访问数与参与数
+
+CREATE TABLE IF NOT EXISTS `spike_share_attributions` (
+  `spike_event_id` bigint unsigned NOT NULL COMMENT '秒杀活动ID',
+  `campaign` varchar(64) NOT NULL DEFAULT '' COMMENT '推广渠道标识，为空表示未指定',
+  `visits` bigint unsigned NOT NULL DEFAULT '0' COMMENT '经分享链接到达的访问数',
+  `participants` bigint unsigned NOT NULL DEFAULT '0' COMMENT '携带分享链接成功参与秒杀的次数',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`spike_event_id`, `campaign`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀分享链接归因表';
@@ -0,0 +1,3 @@
+-- 回滚秒杀活动结算
+
+DROP TABLE IF EXISTS `spike_settlements`;
@@ -0,0 +1,24 @@
+-- 活动结算：活动结束后按活动与结算日汇总已支付订单收入、退款与平台手续费。
+-- 收入按支付日期、退款按退款事件日期归入结算日，每个活动每天一行；
+-- 结算任务重复执行时只覆盖待打款的行，已打款的行不再变更
+
+CREATE TABLE IF NOT EXISTS `spike_settlements` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '结算ID',
+  `spike_event_id` bigint unsigned NOT NULL COMMENT '秒杀活动ID',
+  `settle_date` date NOT NULL COMMENT '结算日',
+  `order_count` bigint unsigned NOT NULL DEFAULT '0' COMMENT '已支付订单数',
+  `quantity` bigint unsigned NOT NULL DEFAULT '0' COMMENT '已支付商品件数',
+  `gross_amount` decimal(14,2) NOT NULL DEFAULT '0.00' COMMENT '已支付订单金额',
+  `refund_amount` decimal(14,2) NOT NULL DEFAULT '0.00' COMMENT '退款金额',
+  `fee_amount` decimal(14,2) NOT NULL DEFAULT '0.00' COMMENT '平台手续费 = (收入 - 退款) × 费率',
+  `net_amount` decimal(14,2) NOT NULL DEFAULT '0.00' COMMENT '应打款金额 = 收入 - 退款 - 手续费',
+  `status` enum('pending', 'paid_out') NOT NULL DEFAULT 'pending' COMMENT '打款状态',
+  `paid_out_at` timestamp NULL DEFAULT NULL COMMENT '打款时间',
+  `paid_out_by` varchar(64) NOT NULL DEFAULT '' COMMENT '打款操作者(admin:<id>)',
+  `payout_reference` varchar(128) NOT NULL DEFAULT '' COMMENT '打款流水号',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_spike_event_id_settle_date` (`spike_event_id`, `settle_date`),
+  KEY `idx_status_settle_date` (`status`, `settle_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀活动结算表';
@@ -0,0 +1,7 @@
+-- 回滚秒杀订单客户端信息
+
+ALTER TABLE `spike_orders`
+  DROP KEY `idx_spike_event_channel`,
+  DROP COLUMN `channel`,
+  DROP COLUMN `user_agent`,
+  DROP COLUMN `client_ip`;
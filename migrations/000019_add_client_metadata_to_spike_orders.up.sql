@@ -0,0 +1,8 @@
+-- 秒杀订单客户端信息
+-- 参与时记录客户端 IP、User-Agent 与下单渠道，用于管理端订单查看与渠道分析；历史订单为空字符串
+
+ALTER TABLE `spike_orders`
+  ADD COLUMN `client_ip` varchar(45) NOT NULL DEFAULT '' COMMENT '客户端IP' AFTER `idempotency_key`,
+  ADD COLUMN `user_agent` varchar(512) NOT NULL DEFAULT '' COMMENT '客户端User-Agent' AFTER `client_ip`,
+  ADD COLUMN `channel` varchar(16) NOT NULL DEFAULT '' COMMENT '下单渠道：app/web/api' AFTER `user_agent`,
+  ADD KEY `idx_spike_event_channel` (`spike_event_id`, `channel`);
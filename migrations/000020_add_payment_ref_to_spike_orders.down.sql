@@ -0,0 +1,5 @@
+-- 回滚秒杀订单支付流水号
+
+ALTER TABLE `spike_orders`
+  DROP KEY `idx_payment_ref`,
+  DROP COLUMN `payment_ref`;
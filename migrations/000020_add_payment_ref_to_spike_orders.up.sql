@@ -0,0 +1,6 @@
+-- 秒杀订单支付流水号
+-- 支付成功时记录支付渠道的交易ID，客服可按支付流水号精确查找订单；历史订单为空字符串
+
+ALTER TABLE `spike_orders`
+  ADD COLUMN `payment_ref` varchar(64) NOT NULL DEFAULT '' COMMENT '支付渠道交易ID' AFTER `paid_at`,
+  ADD KEY `idx_payment_ref` (`payment_ref`);
@@ -0,0 +1,3 @@
+-- 回滚消息发件箱表
+
+DROP TABLE IF EXISTS `outbox_messages`;
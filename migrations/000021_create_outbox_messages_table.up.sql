@@ -0,0 +1,18 @@
+-- 事务性发件箱：需要可靠投递的消息先与业务数据一起落库，由中继任务发布到消息队列。
+-- 发布失败按退避时间重试，超过最大次数后标记为 failed 等待人工处理；已发布的消息保留一段时间后清理
+
+CREATE TABLE IF NOT EXISTS `outbox_messages` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '消息ID',
+  `message_type` varchar(64) NOT NULL COMMENT '消息类型，如 spike_order_created',
+  `payload` json NOT NULL COMMENT '消息数据',
+  `trace_id` varchar(64) NOT NULL DEFAULT '' COMMENT '追踪ID',
+  `status` enum('pending', 'published', 'failed') NOT NULL DEFAULT 'pending' COMMENT '发布状态',
+  `attempts` int unsigned NOT NULL DEFAULT '0' COMMENT '已尝试发布次数',
+  `next_attempt_at` timestamp(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '下次发布时间，中继领取时顺延作为租约',
+  `last_error` varchar(512) NOT NULL DEFAULT '' COMMENT '最近一次发布失败原因',
+  `published_at` timestamp NULL DEFAULT NULL COMMENT '发布时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_next_attempt_at` (`status`, `next_attempt_at`),
+  KEY `idx_status_published_at` (`status`, `published_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='消息发件箱表';
@@ -0,0 +1,6 @@
+-- 回滚秒杀活动暂停状态，已暂停的活动恢复为进行中，由状态流转任务按时间结束
+
+UPDATE `spike_events` SET `status` = 'active' WHERE `status` = 'paused';
+
+ALTER TABLE `spike_events`
+  MODIFY COLUMN `status` enum('pending', 'active', 'ended', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT '活动状态';
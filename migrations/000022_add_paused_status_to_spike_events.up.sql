@@ -0,0 +1,5 @@
+-- 秒杀活动暂停状态
+-- 管理员可暂停待开始或进行中的活动，恢复前不可参与；状态流转任务不会自动激活已暂停的活动
+
+ALTER TABLE `spike_events`
+  MODIFY COLUMN `status` enum('pending', 'active', 'paused', 'ended', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT '活动状态';
@@ -0,0 +1,5 @@
+-- 回滚秒杀活动级限购与限流
+
+ALTER TABLE `spike_events`
+  DROP COLUMN `qps_limit`,
+  DROP COLUMN `max_per_user`;
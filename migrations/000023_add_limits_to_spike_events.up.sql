@@ -0,0 +1,6 @@
+-- 秒杀活动级限购与限流
+-- max_per_user 限制单用户在活动中最多购买的件数，qps_limit 限制活动参与请求的每秒上限；0 表示使用全局默认
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `max_per_user` int NOT NULL DEFAULT '0' COMMENT '单用户最多购买件数，0 表示使用默认上限' AFTER `spike_campaign_id`,
+  ADD COLUMN `qps_limit` int NOT NULL DEFAULT '0' COMMENT '参与请求每秒上限，0 表示不单独限流' AFTER `max_per_user`;
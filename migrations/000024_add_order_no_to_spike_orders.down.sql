@@ -0,0 +1,5 @@
+-- 回滚秒杀订单号
+
+ALTER TABLE `spike_orders`
+  DROP KEY `uk_order_no`,
+  DROP COLUMN `order_no`;
@@ -0,0 +1,11 @@
+-- 秒杀订单号
+-- 参与成功时由雪花算法预先生成并返回给用户，不暴露订单量，分库分表后仍然唯一；
+-- 历史订单回填为 L + 自增ID
+
+ALTER TABLE `spike_orders`
+  ADD COLUMN `order_no` varchar(32) NOT NULL DEFAULT '' COMMENT '订单号' AFTER `id`;
+
+UPDATE `spike_orders` SET `order_no` = CONCAT('L', `id`) WHERE `order_no` = '';
+
+ALTER TABLE `spike_orders`
+  ADD UNIQUE KEY `uk_order_no` (`order_no`);
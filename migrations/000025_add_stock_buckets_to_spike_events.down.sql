@@ -0,0 +1,4 @@
+-- 回滚秒杀活动库存分桶
+
+ALTER TABLE `spike_events`
+  DROP COLUMN `stock_buckets`;
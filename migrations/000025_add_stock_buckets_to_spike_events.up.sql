@@ -0,0 +1,5 @@
+-- 秒杀活动库存分桶
+-- stock_buckets 大于 1 时 Redis 库存拆分为多个子键以分散热点；0 或 1 表示单键库存
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `stock_buckets` int NOT NULL DEFAULT '0' COMMENT 'Redis 库存分桶数，0 或 1 表示单键库存' AFTER `qps_limit`;
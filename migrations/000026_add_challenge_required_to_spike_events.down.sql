@@ -0,0 +1,4 @@
+-- 回滚秒杀活动防刷挑战
+
+ALTER TABLE `spike_events`
+  DROP COLUMN `challenge_required`;
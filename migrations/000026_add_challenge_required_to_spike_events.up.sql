@@ -0,0 +1,5 @@
+-- 秒杀活动防刷挑战
+-- challenge_required 为 1 时参与请求必须携带并消耗一个有效的工作量证明挑战
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `challenge_required` tinyint(1) NOT NULL DEFAULT '0' COMMENT '参与是否需要通过防刷挑战' AFTER `stock_buckets`;
@@ -0,0 +1,4 @@
+-- 回滚秒杀活动排队模式
+
+ALTER TABLE `spike_events`
+  DROP COLUMN `waiting_room_rate`;
@@ -0,0 +1,5 @@
+-- 秒杀活动排队模式
+-- waiting_room_rate 大于 0 时参与请求先进入虚拟等候室，按每秒放行人数依次放行
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `waiting_room_rate` int NOT NULL DEFAULT '0' COMMENT '排队模式每秒放行人数，0 表示不排队' AFTER `challenge_required`;
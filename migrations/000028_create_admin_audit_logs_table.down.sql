@@ -0,0 +1,2 @@
+-- 删除管理员操作审计表
+DROP TABLE IF EXISTS `admin_audit_logs`;
@@ -0,0 +1,20 @@
+-- 管理员操作审计表迁移
+-- 记录管理员每一次写操作（库存调整、预热、活动变更等）的操作者、对象与变更前后数据，便于事后追溯
+
+CREATE TABLE IF NOT EXISTS `admin_audit_logs` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '审计记录ID',
+  `actor` varchar(64) NOT NULL COMMENT '操作者(如 admin:1)',
+  `action` varchar(255) NOT NULL COMMENT '操作(请求方法与路由模板)',
+  `entity_type` varchar(64) NOT NULL DEFAULT '' COMMENT '操作对象类型',
+  `entity_id` varchar(64) NOT NULL DEFAULT '' COMMENT '操作对象ID',
+  `before_data` json NULL COMMENT '变更前数据',
+  `after_data` json NULL COMMENT '变更后数据，未提供时为脱敏后的请求体',
+  `status_code` int NOT NULL DEFAULT 0 COMMENT '响应状态码',
+  `request_id` varchar(64) NOT NULL DEFAULT '' COMMENT '请求ID',
+  `client_ip` varchar(64) NOT NULL DEFAULT '' COMMENT '来源IP',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_entity` (`entity_type`, `entity_id`, `created_at`),
+  KEY `idx_actor_created_at` (`actor`, `created_at`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理员操作审计表';
@@ -0,0 +1,2 @@
+-- 删除库存变动流水表
+DROP TABLE IF EXISTS `stock_movements`;
@@ -0,0 +1,17 @@
+-- 库存变动流水
+-- 每次预留、释放、消费与调整库存都与库存变更在同一事务中写入一条流水，
+-- 记录数量、原因、操作者与关联单据，用于追溯库存变化
+
+CREATE TABLE IF NOT EXISTS `stock_movements` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '流水ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `type` enum('reserve','release','consume','adjust') NOT NULL COMMENT '变动类型',
+  `quantity` int NOT NULL COMMENT '变动数量，调整为带符号的调整量',
+  `reason` varchar(255) NOT NULL DEFAULT '' COMMENT '变动原因',
+  `operator` varchar(64) NOT NULL DEFAULT 'system' COMMENT '操作者(如 admin:1、user:2、system)',
+  `reference` varchar(64) NOT NULL DEFAULT '' COMMENT '关联单据(如秒杀订单号、库存预留ID)',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '变动时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id_created_at` (`product_id`, `created_at`),
+  KEY `idx_reference` (`reference`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='库存变动流水表';
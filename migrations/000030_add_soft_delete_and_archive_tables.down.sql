@@ -0,0 +1,13 @@
+-- 回滚秒杀活动与订单软删除及归档
+
+DROP TABLE IF EXISTS `order_events_archive`;
+DROP TABLE IF EXISTS `spike_orders_archive`;
+DROP TABLE IF EXISTS `spike_events_archive`;
+
+ALTER TABLE `spike_orders`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
+
+ALTER TABLE `spike_events`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
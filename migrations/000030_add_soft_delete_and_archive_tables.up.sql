@@ -0,0 +1,28 @@
+-- 秒杀活动与订单软删除及归档
+-- deleted_at 非空表示已软删除，查询默认过滤；归档任务把早已结束的活动与终态订单
+-- （连同订单事件）迁移到 *_archive 表后从主表删除，保留历史的同时控制主表规模。
+-- 归档以 INSERT ... SELECT * 按列顺序复制，之后给主表加列时必须同样修改对应的归档表
+
+ALTER TABLE `spike_events`
+  ADD COLUMN `deleted_at` timestamp NULL DEFAULT NULL COMMENT '软删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+ALTER TABLE `spike_orders`
+  ADD COLUMN `deleted_at` timestamp NULL DEFAULT NULL COMMENT '软删除时间' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+-- CREATE TABLE ... LIKE 复制列与索引，不复制外键，归档数据不依赖主表
+CREATE TABLE IF NOT EXISTS `spike_events_archive` LIKE `spike_events`;
+ALTER TABLE `spike_events_archive`
+  ADD COLUMN `archived_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档时间',
+  COMMENT='秒杀活动归档表';
+
+CREATE TABLE IF NOT EXISTS `spike_orders_archive` LIKE `spike_orders`;
+ALTER TABLE `spike_orders_archive`
+  ADD COLUMN `archived_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档时间',
+  COMMENT='秒杀订单归档表';
+
+CREATE TABLE IF NOT EXISTS `order_events_archive` LIKE `order_events`;
+ALTER TABLE `order_events_archive`
+  ADD COLUMN `archived_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档时间',
+  COMMENT='秒杀订单事件归档表';
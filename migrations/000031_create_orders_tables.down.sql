@@ -0,0 +1,5 @@
+-- 回滚普通订单模块
+
+DROP TABLE IF EXISTS `order_items`;
+DROP TABLE IF EXISTS `orders`;
+DROP TABLE IF EXISTS `cart_items`;
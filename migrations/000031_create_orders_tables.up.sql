@@ -0,0 +1,52 @@
+-- 普通订单模块
+-- 购物车结算生成待支付的普通订单，支付成功后消费预留库存；
+-- 秒杀订单支付后由消费者创建对应的已支付普通订单（source=spike），spike_orders.order_id 指向该订单
+
+CREATE TABLE IF NOT EXISTS `cart_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '购物车条目ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `quantity` int unsigned NOT NULL COMMENT '数量',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_product` (`user_id`, `product_id`),
+  CONSTRAINT `fk_cart_items_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_cart_items_product_id` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='购物车表';
+
+CREATE TABLE IF NOT EXISTS `orders` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订单ID',
+  `order_no` varchar(32) NOT NULL COMMENT '订单号',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `source` enum('cart', 'spike') NOT NULL DEFAULT 'cart' COMMENT '订单来源',
+  `spike_order_id` bigint unsigned NULL COMMENT '来源秒杀订单ID，秒杀订单归档后仍保留',
+  `status` enum('pending', 'paid', 'cancelled', 'expired') NOT NULL DEFAULT 'pending' COMMENT '订单状态',
+  `total_amount` decimal(10,2) NOT NULL COMMENT '订单总金额',
+  `item_count` int unsigned NOT NULL COMMENT '商品总件数',
+  `payment_ref` varchar(64) NOT NULL DEFAULT '' COMMENT '支付渠道交易ID',
+  `expire_at` timestamp NULL COMMENT '支付期限，秒杀来源的订单为空',
+  `paid_at` timestamp NULL COMMENT '支付完成时间',
+  `cancelled_at` timestamp NULL COMMENT '取消或过期时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_order_no` (`order_no`),
+  UNIQUE KEY `uk_spike_order_id` (`spike_order_id`) COMMENT '每个秒杀订单只创建一个普通订单',
+  KEY `idx_user_id_created_at` (`user_id`, `created_at`),
+  KEY `idx_status_expire_at` (`status`, `expire_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='普通订单表';
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订单明细ID',
+  `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `product_name` varchar(255) NOT NULL COMMENT '下单时的商品名称',
+  `sku` varchar(100) NOT NULL DEFAULT '' COMMENT '下单时的商品SKU',
+  `unit_price` decimal(10,2) NOT NULL COMMENT '成交单价',
+  `quantity` int unsigned NOT NULL COMMENT '数量',
+  `subtotal` decimal(10,2) NOT NULL COMMENT '小计',
+  PRIMARY KEY (`id`),
+  KEY `idx_order_id` (`order_id`),
+  CONSTRAINT `fk_order_items_order_id` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='普通订单明细表';
@@ -0,0 +1,3 @@
+-- 回滚秒杀用户每日配额
+
+DROP TABLE IF EXISTS `spike_user_quotas`;
@@ -0,0 +1,13 @@
+-- 单用户每日秒杀成功次数上限
+-- user_id 为 0 的规则是全体用户的默认上限，其余规则覆盖对应用户的默认上限；max_daily_wins 为 0 表示不限制
+
+CREATE TABLE IF NOT EXISTS `spike_user_quotas` (
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID，0 表示全体用户的默认规则',
+  `max_daily_wins` int unsigned NOT NULL DEFAULT '0' COMMENT '每个自然日最多秒杀成功次数，0 表示不限制',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀用户每日配额表';
+
+-- 默认不限制，由管理员通过接口调整
+INSERT IGNORE INTO `spike_user_quotas` (`user_id`, `max_daily_wins`) VALUES (0, 0);
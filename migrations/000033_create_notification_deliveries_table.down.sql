@@ -0,0 +1,3 @@
+-- 回滚用户通知投递记录
+
+DROP TABLE IF EXISTS `notification_deliveries`;
@@ -0,0 +1,18 @@
+-- 用户通知投递记录
+-- 每条通知消息在每个渠道上一条记录，消息重新投递时跳过已发送的渠道
+
+CREATE TABLE IF NOT EXISTS `notification_deliveries` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `message_id` varchar(64) NOT NULL COMMENT '通知消息ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `channel` varchar(16) NOT NULL COMMENT '渠道：email, sms, push',
+  `type` varchar(64) NOT NULL DEFAULT '' COMMENT '通知类型',
+  `status` varchar(16) NOT NULL COMMENT '投递状态：sent, failed, skipped',
+  `attempts` int unsigned NOT NULL DEFAULT '0' COMMENT '累计发送次数',
+  `last_error` varchar(255) NOT NULL DEFAULT '' COMMENT '最后一次失败原因',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_message_channel` (`message_id`, `channel`) COMMENT '同一消息同一渠道只记录一次',
+  KEY `idx_user_created` (`user_id`, `created_at`) COMMENT '按用户查询投递记录'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户通知投递记录表';
@@ -0,0 +1,10 @@
+-- 回滚秒杀订单按件数限购
+-- 同一用户在同一活动下已有多笔订单时恢复唯一约束会失败，需先清理重复订单
+
+ALTER TABLE `spike_orders_archive`
+  DROP KEY `idx_user_spike_event`,
+  ADD UNIQUE KEY `uk_user_spike_event` (`user_id`, `spike_event_id`) COMMENT '用户活动去重约束';
+
+ALTER TABLE `spike_orders`
+  DROP KEY `idx_user_spike_event`,
+  ADD UNIQUE KEY `uk_user_spike_event` (`user_id`, `spike_event_id`) COMMENT '用户活动去重约束';
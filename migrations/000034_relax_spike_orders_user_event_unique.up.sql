@@ -0,0 +1,11 @@
+-- 秒杀订单按件数限购
+-- 设置了单用户购买上限的活动允许同一用户多次下单，累计件数由 Redis 预减库存脚本校验，
+-- 用户活动唯一约束改为普通索引；归档表按 LIKE 复制了同一约束，同样需要放开
+
+ALTER TABLE `spike_orders`
+  DROP KEY `uk_user_spike_event`,
+  ADD KEY `idx_user_spike_event` (`user_id`, `spike_event_id`);
+
+ALTER TABLE `spike_orders_archive`
+  DROP KEY `uk_user_spike_event`,
+  ADD KEY `idx_user_spike_event` (`user_id`, `spike_event_id`);
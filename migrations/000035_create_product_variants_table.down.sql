@@ -0,0 +1,14 @@
+-- 回滚商品规格
+
+ALTER TABLE `order_items`
+  DROP COLUMN `variant_id`;
+
+ALTER TABLE `spike_events_archive`
+  DROP COLUMN `variant_id`;
+
+ALTER TABLE `spike_events`
+  DROP FOREIGN KEY `fk_spike_events_variant_id`,
+  DROP KEY `idx_variant_id`,
+  DROP COLUMN `variant_id`;
+
+DROP TABLE IF EXISTS `product_variants`;
@@ -0,0 +1,36 @@
+-- 商品规格（SKU）
+-- 同一商品下按尺码、颜色等属性区分的规格，各自独立定价并独立管理库存；
+-- 秒杀活动可指定 variant_id 只售卖某一规格，下单时扣减该规格的库存，普通订单明细记录成交的规格
+
+CREATE TABLE IF NOT EXISTS `product_variants` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '规格ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `sku` varchar(100) NOT NULL COMMENT '规格SKU，全局唯一',
+  `name` varchar(255) NOT NULL COMMENT '规格名称，如 红色 / XL',
+  `attributes` json NULL COMMENT '规格属性，如 {"color":"red","size":"XL"}',
+  `price` decimal(10,2) NOT NULL COMMENT '规格价格',
+  `stock` int NOT NULL DEFAULT '0' COMMENT '当前可售库存',
+  `sold_stock` int NOT NULL DEFAULT '0' COMMENT '已售库存',
+  `active` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否启用，停用的规格不能被新的秒杀活动引用',
+  `version` int NOT NULL DEFAULT '0' COMMENT '乐观锁版本号',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_sku` (`sku`),
+  KEY `idx_product_id` (`product_id`),
+  CONSTRAINT `fk_product_variants_product_id` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `chk_product_variants_stock` CHECK (`stock` >= 0),
+  CONSTRAINT `chk_product_variants_price` CHECK (`price` > 0)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='商品规格表';
+
+-- 归档以 SELECT * 按列顺序复制，归档表在同一位置加列
+ALTER TABLE `spike_events`
+  ADD COLUMN `variant_id` bigint unsigned NULL DEFAULT NULL COMMENT '秒杀的商品规格ID，为空表示整个商品' AFTER `product_id`,
+  ADD KEY `idx_variant_id` (`variant_id`),
+  ADD CONSTRAINT `fk_spike_events_variant_id` FOREIGN KEY (`variant_id`) REFERENCES `product_variants` (`id`);
+
+ALTER TABLE `spike_events_archive`
+  ADD COLUMN `variant_id` bigint unsigned NULL DEFAULT NULL COMMENT '秒杀的商品规格ID，为空表示整个商品' AFTER `product_id`;
+
+ALTER TABLE `order_items`
+  ADD COLUMN `variant_id` bigint unsigned NULL DEFAULT NULL COMMENT '成交的商品规格ID，为空表示未区分规格' AFTER `product_id`;
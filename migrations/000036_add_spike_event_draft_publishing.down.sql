@@ -0,0 +1,12 @@
+-- 回滚秒杀活动草稿与定时发布，未发布的活动置为已取消
+
+DROP TABLE IF EXISTS `spike_event_publications`;
+
+UPDATE `spike_events` SET `status` = 'cancelled' WHERE `status` IN ('draft', 'scheduled');
+UPDATE `spike_events_archive` SET `status` = 'cancelled' WHERE `status` IN ('draft', 'scheduled');
+
+ALTER TABLE `spike_events_archive`
+  MODIFY COLUMN `status` enum('pending', 'active', 'paused', 'ended', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT '活动状态';
+
+ALTER TABLE `spike_events`
+  MODIFY COLUMN `status` enum('pending', 'active', 'paused', 'ended', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT '活动状态';
@@ -0,0 +1,22 @@
+-- 秒杀活动草稿与定时发布
+-- 草稿活动只对管理员可见；管理员设定发布时间后进入待发布状态，须由另一名管理员审批，
+-- 状态流转任务在发布时间到达后把已审批的活动置为待开始，之后按原有时间规则流转
+
+ALTER TABLE `spike_events`
+  MODIFY COLUMN `status` enum('draft', 'scheduled', 'pending', 'active', 'paused', 'ended', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT '活动状态';
+
+ALTER TABLE `spike_events_archive`
+  MODIFY COLUMN `status` enum('draft', 'scheduled', 'pending', 'active', 'paused', 'ended', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT '活动状态';
+
+CREATE TABLE IF NOT EXISTS `spike_event_publications` (
+  `spike_event_id` bigint unsigned NOT NULL COMMENT '秒杀活动ID',
+  `publish_at` timestamp NOT NULL COMMENT '计划发布时间',
+  `scheduled_by` bigint unsigned NOT NULL COMMENT '设定发布时间的管理员ID',
+  `approved_by` bigint unsigned NULL DEFAULT NULL COMMENT '审批的管理员ID，未审批为空',
+  `approved_at` timestamp NULL DEFAULT NULL COMMENT '审批时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`spike_event_id`),
+  KEY `idx_publish_at` (`publish_at`),
+  CONSTRAINT `fk_spike_event_publications_event_id` FOREIGN KEY (`spike_event_id`) REFERENCES `spike_events` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='秒杀活动发布计划表';
@@ -0,0 +1,5 @@
+-- 回滚秒杀订单键集分页索引
+
+ALTER TABLE `spike_orders`
+  DROP KEY `idx_event_created_id`,
+  DROP KEY `idx_user_created_id`;
@@ -0,0 +1,7 @@
+-- 秒杀订单键集分页索引
+-- 订单列表的游标分页按 (created_at, id) 排序并从游标位置向后扫描；
+-- 按用户或活动过滤时，组合索引使扫描直接从游标位置开始，不再随翻页深度变慢
+
+ALTER TABLE `spike_orders`
+  ADD KEY `idx_user_created_id` (`user_id`, `created_at`, `id`),
+  ADD KEY `idx_event_created_id` (`spike_event_id`, `created_at`, `id`);
@@ -0,0 +1,3 @@
+-- 回滚用户 TOTP 两步验证，已启用的账号恢复为仅密码登录
+
+DROP TABLE IF EXISTS `user_totp`;
@@ -0,0 +1,14 @@
+-- 用户 TOTP 两步验证
+-- 申请绑定时写入待确认的密钥，用户提交验证码确认后记录启用时间；
+-- last_used_step 记录最近一次验证通过的时间步，防止验证码在有效期内被重放
+
+CREATE TABLE IF NOT EXISTS `user_totp` (
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `secret` varchar(64) NOT NULL COMMENT 'Base32 编码的共享密钥',
+  `enabled_at` timestamp NULL DEFAULT NULL COMMENT '启用时间，为空表示待确认',
+  `last_used_step` bigint NOT NULL DEFAULT 0 COMMENT '最近一次验证通过的时间步',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`user_id`),
+  CONSTRAINT `fk_user_totp_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户两步验证表';
@@ -0,0 +1,8 @@
+-- 回滚只允许参与一次的活动的用户活动唯一约束
+
+ALTER TABLE `spike_orders`
+  DROP KEY `uk_user_spike_event_single`,
+  DROP COLUMN `single_purchase`;
+
+ALTER TABLE `spike_orders_archive`
+  DROP COLUMN `single_purchase`;
@@ -0,0 +1,25 @@
+-- 只允许参与一次的活动恢复数据库层的用户活动唯一约束
+-- 000034 为按件数限购放开了 uk_user_spike_event，降级参与（Redis 不可用）时只能靠进程内标记与订单计数去重，
+-- 多实例并发时可能重复下单。未配置 max_per_user 的活动落库时 single_purchase 写 1，由唯一约束兜底；
+-- 允许多次购买的活动写 NULL，唯一索引不约束 NULL。
+-- 归档以 INSERT ... SELECT * 按列顺序复制，归档表在相同位置加列，但不加唯一约束
+
+ALTER TABLE `spike_orders`
+  ADD COLUMN `single_purchase` tinyint unsigned NULL DEFAULT NULL COMMENT '只允许参与一次的活动为 1，否则为 NULL' AFTER `quantity`;
+
+ALTER TABLE `spike_orders_archive`
+  ADD COLUMN `single_purchase` tinyint unsigned NULL DEFAULT NULL COMMENT '只允许参与一次的活动为 1，否则为 NULL' AFTER `quantity`;
+
+-- 回填：只标记每个用户在只允许参与一次的活动中最早的一笔订单，放开约束期间产生的重复订单保持 NULL，不阻塞迁移
+UPDATE `spike_orders` o
+  JOIN (
+    SELECT MIN(so.`id`) AS `id`
+    FROM `spike_orders` so
+    JOIN `spike_events` e ON e.`id` = so.`spike_event_id`
+    WHERE e.`max_per_user` = 0
+    GROUP BY so.`user_id`, so.`spike_event_id`
+  ) f ON f.`id` = o.`id`
+SET o.`single_purchase` = 1;
+
+ALTER TABLE `spike_orders`
+  ADD UNIQUE KEY `uk_user_spike_event_single` (`user_id`, `spike_event_id`, `single_purchase`) COMMENT '只允许参与一次的活动的用户去重约束';
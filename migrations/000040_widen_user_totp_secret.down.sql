@@ -0,0 +1,5 @@
+-- 回滚两步验证密钥列宽
+-- 已加密保存的密钥超出原列宽，回滚前需删除这些绑定
+
+ALTER TABLE `user_totp`
+  MODIFY COLUMN `secret` varchar(64) NOT NULL COMMENT 'Base32 编码的共享密钥';
@@ -0,0 +1,5 @@
+-- 两步验证密钥加密保存
+-- 配置 TOTP_ENCRYPTION_KEY 后密钥以 AES-256-GCM 加密，保存为 v1: 前缀加 Base64 编码的随机数与密文，超出原列宽
+
+ALTER TABLE `user_totp`
+  MODIFY COLUMN `secret` varchar(128) NOT NULL COMMENT 'Base32 编码的共享密钥，加密保存时为 v1: 前缀的密文';